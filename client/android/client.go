@@ -78,9 +78,11 @@ func NewClient(cfgFile, deviceName string, uiVersion string, tunAdapter TunAdapt
 
 // Run start the internal client. It is a blocker function
 func (c *Client) Run(urlOpener URLOpener, dns *DNSList, dnsReadyListener DnsReadyListener) error {
-	cfg, err := internal.UpdateOrCreateConfig(internal.ConfigInput{
+	cfgInput := internal.ConfigInput{
 		ConfigPath: c.cfgFile,
-	})
+	}
+	applyManagedPolicy(&cfgInput)
+	cfg, err := internal.UpdateOrCreateConfig(cfgInput)
 	if err != nil {
 		return err
 	}
@@ -113,9 +115,11 @@ func (c *Client) Run(urlOpener URLOpener, dns *DNSList, dnsReadyListener DnsRead
 // RunWithoutLogin we apply this type of run function when the backed has been started without UI (i.e. after reboot).
 // In this case make no sense handle registration steps.
 func (c *Client) RunWithoutLogin(dns *DNSList, dnsReadyListener DnsReadyListener) error {
-	cfg, err := internal.UpdateOrCreateConfig(internal.ConfigInput{
+	cfgInput := internal.ConfigInput{
 		ConfigPath: c.cfgFile,
-	})
+	}
+	applyManagedPolicy(&cfgInput)
+	cfg, err := internal.UpdateOrCreateConfig(cfgInput)
 	if err != nil {
 		return err
 	}
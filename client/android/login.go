@@ -46,6 +46,7 @@ func NewAuth(cfgPath string, mgmURL string) (*Auth, error) {
 	inputCfg := internal.ConfigInput{
 		ManagementURL: mgmURL,
 	}
+	applyManagedPolicy(&inputCfg)
 
 	cfg, err := internal.CreateInMemoryConfig(inputCfg)
 	if err != nil {
@@ -169,8 +170,11 @@ func (a *Auth) login(urlOpener URLOpener) error {
 		return fmt.Errorf("backoff cycle failed: %v", err)
 	}
 
+	// with a managed setup key the peer is registered without the interactive login
+	setupKey := readManagedPolicy().SetupKey
+
 	jwtToken := ""
-	if needsLogin {
+	if needsLogin && setupKey == "" {
 		tokenInfo, err := a.foregroundGetTokenInfo(urlOpener)
 		if err != nil {
 			return fmt.Errorf("interactive sso login failed: %v", err)
@@ -179,7 +183,7 @@ func (a *Auth) login(urlOpener URLOpener) error {
 	}
 
 	err = a.withBackOff(a.ctx, func() error {
-		err := internal.Login(a.ctx, a.config, "", jwtToken)
+		err := internal.Login(a.ctx, a.config, setupKey, jwtToken)
 		if s, ok := gstatus.FromError(err); ok && (s.Code() == codes.InvalidArgument || s.Code() == codes.PermissionDenied) {
 			return nil
 		}
@@ -0,0 +1,73 @@
+package android
+
+import (
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/client/internal"
+	"github.com/netbirdio/netbird/client/internal/mdm"
+)
+
+// ManagedConfig export the managed configuration of the app for gomobile. The app reads it from the
+// RestrictionsManager and applies it before running the client, the managed values take precedence over the
+// preferences of the user.
+type ManagedConfig struct {
+	policy mdm.Policy
+}
+
+// NewManagedConfig create an empty ManagedConfig instance
+func NewManagedConfig() *ManagedConfig {
+	return &ManagedConfig{}
+}
+
+// SetManagementURL set the managed management URL
+func (m *ManagedConfig) SetManagementURL(url string) {
+	m.policy.ManagementURL = url
+}
+
+// SetSetupKey set the setup key the peer is registered with without user interaction
+func (m *ManagedConfig) SetSetupKey(setupKey string) {
+	m.policy.SetupKey = setupKey
+}
+
+// SetDisableDNS set whether the client configures the DNS of the device
+func (m *ManagedConfig) SetDisableDNS(disable bool) {
+	m.policy.DisableDNS = &disable
+}
+
+// AddDisabledUIAction disallow a UI action, like "settings" or "disconnect"
+func (m *ManagedConfig) AddDisabledUIAction(action string) {
+	m.policy.DisabledUIActions = append(m.policy.DisabledUIActions, action)
+}
+
+// Apply validate the managed configuration and make it effective on the next run of the client
+func (m *ManagedConfig) Apply() error {
+	policy := m.policy
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	mdm.SetPolicy(&policy)
+	return nil
+}
+
+// IsUIActionDisabled returns true if the managed configuration disallows the UI action
+func IsUIActionDisabled(action string) bool {
+	return readManagedPolicy().UIActionDisabled(action)
+}
+
+func readManagedPolicy() *mdm.Policy {
+	policy, err := mdm.Read()
+	if err != nil {
+		log.Errorf("failed to read the managed configuration, running unmanaged: %v", err)
+		return &mdm.Policy{}
+	}
+	return policy
+}
+
+// applyManagedPolicy overrides the config input with the managed configuration
+func applyManagedPolicy(input *internal.ConfigInput) {
+	if overridden := readManagedPolicy().Apply(input); len(overridden) > 0 {
+		log.Warnf("ignoring the values of the settings %s, they're managed by the device policy", strings.Join(overridden, ", "))
+	}
+}
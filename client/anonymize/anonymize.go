@@ -14,9 +14,24 @@ import (
 
 const anonTLD = ".domain"
 
+var (
+	ipv4Regex   = regexp.MustCompile(`\b(?:[0-9]{1,3}\.){3}[0-9]{1,3}\b`)
+	ipv6Regex   = regexp.MustCompile(`\b([0-9a-fA-F:]+:+[0-9a-fA-F]{0,4})(?:%[0-9a-zA-Z]+)?(?:\/[0-9]{1,3})?(?::[0-9]{1,5})?\b`)
+	emailRegex  = regexp.MustCompile(`\b[a-zA-Z0-9._%+-]+@((?:[a-zA-Z0-9-]+\.)+[a-zA-Z]{2,63})\b`)
+	domainRegex = regexp.MustCompile(`\b(?:[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\.)+[a-z]{2,63}\b\.?`)
+)
+
+// fileExtensions are the suffixes of file names that look like domains, they aren't anonymized by AnonymizeDomains
+var fileExtensions = []string{
+	"go", "log", "txt", "json", "yaml", "yml", "toml", "conf", "sock", "zip", "gz", "exe", "sh", "ps1",
+	"prof", "pem", "key", "crt", "db", "sqlite", "err", "out", "md", "html", "js", "proto", "so", "dll",
+	"dylib", "plist", "service", "pid", "tmp",
+}
+
 type Anonymizer struct {
 	ipAnonymizer     map[netip.Addr]netip.Addr
 	domainAnonymizer map[string]string
+	emailAnonymizer  map[string]string
 	currentAnonIPv4  netip.Addr
 	currentAnonIPv6  netip.Addr
 	startAnonIPv4    netip.Addr
@@ -34,6 +49,7 @@ func NewAnonymizer(startIPv4, startIPv6 netip.Addr) *Anonymizer {
 	return &Anonymizer{
 		ipAnonymizer:     map[netip.Addr]netip.Addr{},
 		domainAnonymizer: map[string]string{},
+		emailAnonymizer:  map[string]string{},
 		currentAnonIPv4:  startIPv4,
 		currentAnonIPv6:  startIPv6,
 		startAnonIPv4:    startIPv4,
@@ -152,11 +168,7 @@ func (a *Anonymizer) AnonymizeURI(uri string) string {
 }
 
 func (a *Anonymizer) AnonymizeString(str string) string {
-	ipv4Regex := regexp.MustCompile(`\b(?:[0-9]{1,3}\.){3}[0-9]{1,3}\b`)
-	ipv6Regex := regexp.MustCompile(`\b([0-9a-fA-F:]+:+[0-9a-fA-F]{0,4})(?:%[0-9a-zA-Z]+)?(?:\/[0-9]{1,3})?(?::[0-9]{1,5})?\b`)
-
-	str = ipv4Regex.ReplaceAllStringFunc(str, a.AnonymizeIPString)
-	str = ipv6Regex.ReplaceAllStringFunc(str, a.AnonymizeIPString)
+	str = a.AnonymizeIPs(str)
 
 	for domain, anonDomain := range a.domainAnonymizer {
 		str = strings.ReplaceAll(str, domain, anonDomain)
@@ -168,6 +180,52 @@ func (a *Anonymizer) AnonymizeString(str string) string {
 	return str
 }
 
+// AnonymizeIPs finds and anonymizes the IPv4 and IPv6 addresses in the text.
+func (a *Anonymizer) AnonymizeIPs(text string) string {
+	text = ipv4Regex.ReplaceAllStringFunc(text, a.AnonymizeIPString)
+	return ipv6Regex.ReplaceAllStringFunc(text, a.AnonymizeIPString)
+}
+
+// AnonymizeDomains finds and anonymizes the domain names in the text. Unlike AnonymizeString, it doesn't rely on the
+// domains seen before, names ending in common file extensions like config.json are left unchanged.
+func (a *Anonymizer) AnonymizeDomains(text string) string {
+	return domainRegex.ReplaceAllStringFunc(text, func(domain string) string {
+		tld := domain[strings.LastIndex(strings.TrimSuffix(domain, "."), ".")+1:]
+		if slices.Contains(fileExtensions, strings.TrimSuffix(tld, ".")) {
+			return domain
+		}
+		return a.AnonymizeDomain(domain)
+	})
+}
+
+// AnonymizeEmail replaces the local part of the email address with a random string and anonymizes its domain.
+// Reoccurring addresses are replaced with the same anonymized address.
+func (a *Anonymizer) AnonymizeEmail(email string) string {
+	key := strings.ToLower(email)
+	if anonymized, ok := a.emailAnonymizer[key]; ok {
+		return anonymized
+	}
+
+	at := strings.LastIndex(email, "@")
+	if at < 0 || strings.HasSuffix(email, anonTLD) {
+		return email
+	}
+
+	anonymized := "user-" + generateRandomString(5) + "@" + a.AnonymizeDomain(email[at+1:])
+	a.emailAnonymizer[key] = anonymized
+	return anonymized
+}
+
+// AnonymizeEmails finds and anonymizes the email addresses in the text.
+func (a *Anonymizer) AnonymizeEmails(text string) string {
+	return emailRegex.ReplaceAllStringFunc(text, a.AnonymizeEmail)
+}
+
+// Len returns the number of addresses, domains and emails the anonymizer memorized.
+func (a *Anonymizer) Len() int {
+	return len(a.ipAnonymizer) + len(a.domainAnonymizer) + len(a.emailAnonymizer)
+}
+
 // AnonymizeSchemeURI finds and anonymizes URIs with ws, wss, rel, rels, stun, stuns, turn, and turns schemes.
 func (a *Anonymizer) AnonymizeSchemeURI(text string) string {
 	re := regexp.MustCompile(`(?i)\b(wss?://|rels?://|stuns?:|turns?:|https?://)\S+\b`)
@@ -295,3 +295,49 @@ func TestAnonymizeString_IPAddresses(t *testing.T) {
 		})
 	}
 }
+
+func TestAnonymizeDomains(t *testing.T) {
+	anonymizer := anonymize.NewAnonymizer(anonymize.DefaultAddresses())
+	tests := []struct {
+		name   string
+		input  string
+		expect string
+	}{
+		{
+			name:   "Domain",
+			input:  "resolving peer.example.com failed",
+			expect: `resolving peer\.anon-[a-zA-Z0-9]+\.domain failed`,
+		},
+		{
+			name:   "NetBird domain",
+			input:  "connected to api.netbird.io",
+			expect: `connected to api\.netbird\.io`,
+		},
+		{
+			name:   "File names",
+			input:  "client/internal/engine.go:123 failed to read config.json",
+			expect: `client/internal/engine\.go:123 failed to read config\.json`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result := anonymizer.AnonymizeDomains(tc.input)
+			assert.Regexp(t, "^"+tc.expect+"$", result)
+		})
+	}
+}
+
+func TestAnonymizeEmails(t *testing.T) {
+	anonymizer := anonymize.NewAnonymizer(anonymize.DefaultAddresses())
+
+	result := anonymizer.AnonymizeEmails("user jane.doe@example.com logged in as Jane.Doe@example.com")
+	assert.Regexp(t, `^user (user-[a-zA-Z0-9]+@anon-[a-zA-Z0-9]+\.domain) logged in as (user-[a-zA-Z0-9]+@anon-[a-zA-Z0-9]+\.domain)$`, result)
+	assert.NotContains(t, result, "example.com")
+
+	matches := regexp.MustCompile(`user-[a-zA-Z0-9]+@\S+`).FindAllString(result, -1)
+	require.Len(t, matches, 2)
+	assert.Equal(t, matches[0], matches[1], "reoccurring emails should be replaced with the same email")
+
+	assert.Equal(t, result, anonymizer.AnonymizeEmails(result), "anonymized emails should not be anonymized again")
+}
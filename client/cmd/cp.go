@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/status"
+
+	"github.com/netbirdio/netbird/client/proto"
+)
+
+// cpChunkSize is the size of the content parts streamed to the daemon
+const cpChunkSize = 32 << 10
+
+var cpCmd = &cobra.Command{
+	Use:   "cp <file> <peer>:<path>",
+	Short: "Copy a file to a peer",
+	Long: "Copies a file to a peer over the NetBird network. The peer receives it into the directory set with " +
+		"--file-transfer-dir, if an access policy allows this peer to reach TCP port 22113 of it. The path is relative " +
+		"to that directory, the file keeps its name if the path is empty or ends with a slash. An interrupted copy of " +
+		"the same file resumes where it stopped when the command is run again.",
+	Example: "  netbird cp report.pdf peer.netbird.cloud:\n  netbird cp backup.tar.gz 100.64.0.7:backups/",
+	Args:    cobra.ExactArgs(2),
+	RunE:    copyFile,
+}
+
+func copyFile(cmd *cobra.Command, args []string) error {
+	peerName, dstPath, ok := strings.Cut(args[1], ":")
+	if !ok || peerName == "" {
+		return fmt.Errorf("invalid destination %q, expected <peer>:<path>", args[1])
+	}
+	if dstPath == "" || strings.HasSuffix(dstPath, "/") {
+		dstPath = path.Join(dstPath, filepath.Base(args[0]))
+	}
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%s is not a regular file", args[0])
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return fmt.Errorf("hash %s: %w", args[0], err)
+	}
+
+	conn, err := getClient(cmd)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	stream, err := proto.NewDaemonServiceClient(conn).SendFile(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("failed to send file: %v", status.Convert(err).Message())
+	}
+
+	err = stream.Send(&proto.SendFileRequest{
+		Peer:   peerName,
+		Path:   dstPath,
+		Size:   info.Size(),
+		Sha256: hex.EncodeToString(hash.Sum(nil)),
+		Mode:   uint32(info.Mode().Perm()),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send file: %v", status.Convert(err).Message())
+	}
+
+	resp, err := stream.Recv()
+	if err != nil {
+		return fmt.Errorf("failed to send file: %v", status.Convert(err).Message())
+	}
+	if resp.GetOffset() > 0 {
+		cmd.PrintErrf("Resuming %s at %s\n", args[0], formatBytes(resp.GetOffset()))
+	}
+
+	if err := streamFile(cmd, stream, f, resp.GetOffset(), info.Size()); err != nil {
+		return err
+	}
+
+	if err := stream.CloseSend(); err != nil {
+		return fmt.Errorf("failed to send file: %v", status.Convert(err).Message())
+	}
+	resp, err = stream.Recv()
+	if err != nil {
+		return fmt.Errorf("failed to send file: %v", status.Convert(err).Message())
+	}
+	if !resp.GetDone() {
+		return errors.New("failed to send file: the daemon didn't confirm the transfer")
+	}
+
+	cmd.Printf("Copied %s to %s:%s\n", args[0], peerName, dstPath)
+	return nil
+}
+
+// streamFile sends the content of the file from the offset on and prints the progress
+func streamFile(cmd *cobra.Command, stream proto.DaemonService_SendFileClient, f *os.File, offset, size int64) error {
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	buf := make([]byte, cpChunkSize)
+	sent := offset
+	var lastProgress time.Time
+	for {
+		n, err := f.Read(buf)
+		if n > 0 {
+			if err := stream.Send(&proto.SendFileRequest{Data: buf[:n]}); err != nil {
+				if errors.Is(err, io.EOF) {
+					// the daemon ended the stream, the error is returned by Recv
+					_, err = stream.Recv()
+				}
+				return fmt.Errorf("failed to send file: %v", status.Convert(err).Message())
+			}
+			sent += int64(n)
+		}
+
+		if time.Since(lastProgress) > 200*time.Millisecond || sent == size {
+			cmd.PrintErrf("\r%s / %s (%d%%)", formatBytes(sent), formatBytes(size), percentOf(sent, size))
+			lastProgress = time.Now()
+		}
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("read file: %w", err)
+		}
+	}
+	cmd.PrintErrln()
+
+	if sent != size {
+		return fmt.Errorf("file changed while sending it, sent %d of %d bytes", sent, size)
+	}
+	return nil
+}
+
+func percentOf(n, total int64) int64 {
+	if total == 0 {
+		return 100
+	}
+	return n * 100 / total
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
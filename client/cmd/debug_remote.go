@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/status"
+
+	"github.com/netbirdio/netbird/client/proto"
+)
+
+var debugRemoteCmd = &cobra.Command{
+	Use:   "remote",
+	Short: "Manage the debug bundles requested by the management service",
+	Long: "Lists, approves and declines the debug bundles your administrator requested from this peer.\n" +
+		"Requests wait for approval unless the client was started with --remote-debug-bundles=allow or deny.",
+}
+
+var debugRemoteListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the debug bundle requests waiting for approval",
+	Args:  cobra.NoArgs,
+	RunE:  listRemoteDebugBundles,
+}
+
+var debugRemoteApproveCmd = &cobra.Command{
+	Use:     "approve <id>",
+	Short:   "Create an anonymized debug bundle and upload it to the management service",
+	Example: "  netbird debug remote approve cvb4gc3m0v1s73b0c3a0",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return respondRemoteDebugBundle(cmd, args[0], true)
+	},
+}
+
+var debugRemoteDeclineCmd = &cobra.Command{
+	Use:     "decline <id>",
+	Short:   "Decline a debug bundle request",
+	Example: "  netbird debug remote decline cvb4gc3m0v1s73b0c3a0",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return respondRemoteDebugBundle(cmd, args[0], false)
+	},
+}
+
+func init() {
+	debugCmd.AddCommand(debugRemoteCmd)
+	debugRemoteCmd.AddCommand(debugRemoteListCmd)
+	debugRemoteCmd.AddCommand(debugRemoteApproveCmd)
+	debugRemoteCmd.AddCommand(debugRemoteDeclineCmd)
+}
+
+func listRemoteDebugBundles(cmd *cobra.Command, _ []string) error {
+	conn, err := getClient(cmd)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	client := proto.NewDaemonServiceClient(conn)
+	resp, err := client.ListRemoteDebugBundles(cmd.Context(), &proto.ListRemoteDebugBundlesRequest{})
+	if err != nil {
+		return fmt.Errorf("failed to list debug bundle requests: %v", status.Convert(err).Message())
+	}
+
+	if len(resp.GetBundles()) == 0 {
+		cmd.Println("No debug bundle requests waiting for approval")
+		return nil
+	}
+
+	for _, bundle := range resp.GetBundles() {
+		expiresIn := time.Until(bundle.GetExpiresAt().AsTime()).Round(time.Second)
+		cmd.Printf("%s (expires in %s)\n", bundle.GetId(), expiresIn)
+	}
+	return nil
+}
+
+func respondRemoteDebugBundle(cmd *cobra.Command, id string, approve bool) error {
+	conn, err := getClient(cmd)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	client := proto.NewDaemonServiceClient(conn)
+	_, err = client.RespondRemoteDebugBundle(cmd.Context(), &proto.RespondRemoteDebugBundleRequest{
+		Id:      id,
+		Approve: approve,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to respond to debug bundle request: %v", status.Convert(err).Message())
+	}
+
+	if approve {
+		cmd.Printf("Debug bundle %s approved, it will be uploaded in the background\n", id)
+	} else {
+		cmd.Printf("Debug bundle %s declined\n", id)
+	}
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
+	"github.com/netbirdio/netbird/client/internal"
 	"github.com/netbirdio/netbird/client/proto"
 )
 
@@ -38,7 +39,11 @@ var downCmd = &cobra.Command{
 
 		daemonClient := proto.NewDaemonServiceClient(conn)
 
-		if _, err := daemonClient.Down(ctx, &proto.DownRequest{}); err != nil {
+		// a routing peer may drain the routed flows before going down
+		downCtx, downCancel := context.WithTimeout(context.Background(), time.Second*7+internal.MaxRouteDrainPeriod)
+		defer downCancel()
+
+		if _, err := daemonClient.Down(downCtx, &proto.DownRequest{}); err != nil {
 			log.Errorf("call service down method: %v", err)
 			return err
 		}
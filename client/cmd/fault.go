@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/netbirdio/netbird/client/proto"
+	"github.com/netbirdio/netbird/util/faultinject"
+)
+
+var faultCmd = &cobra.Command{
+	Use:   "fault",
+	Short: "Inject faults into the client to test its recovery",
+	Long: "Injects faults into the running client to reproduce the failures of the signal, management and relay\n" +
+		"connections and of the firewall. The daemon must be built with the faultinject tag.\n" +
+		"Available faults: " + faultNames(),
+}
+
+var faultSetCmd = &cobra.Command{
+	Use:   "set <fault>",
+	Short: "Inject a fault",
+	Example: `
+  netbird debug fault set signal-drop --probability 0.5
+  netbird debug fault set management-sync-delay --delay 30s
+  netbird debug fault set relay-kill --count 1
+  netbird debug fault set firewall-error`,
+	Args: cobra.ExactArgs(1),
+	RunE: setFault,
+}
+
+var faultClearCmd = &cobra.Command{
+	Use:     "clear [fault]",
+	Short:   "Stop injecting a fault, or all the faults",
+	Example: "  netbird debug fault clear relay-kill",
+	Args:    cobra.MaximumNArgs(1),
+	RunE:    clearFault,
+}
+
+var faultListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the injected faults",
+	Args:  cobra.NoArgs,
+	RunE:  listFaults,
+}
+
+func init() {
+	debugCmd.AddCommand(faultCmd)
+	faultCmd.AddCommand(faultSetCmd, faultClearCmd, faultListCmd)
+
+	faultSetCmd.Flags().Float64("probability", 0, "Chance the fault is injected each time, 0 injects it every time")
+	faultSetCmd.Flags().Duration("delay", 0, "Time the delayed calls wait for, required by management-sync-delay")
+	faultSetCmd.Flags().Int32("count", 0, "Number of times the fault is injected before it's cleared, 0 injects it until it's cleared")
+}
+
+func faultNames() string {
+	names := make([]string, 0, len(faultinject.Faults))
+	for _, f := range faultinject.Faults {
+		names = append(names, string(f))
+	}
+	return strings.Join(names, ", ")
+}
+
+func setFault(cmd *cobra.Command, args []string) error {
+	probability, _ := cmd.Flags().GetFloat64("probability")
+	delay, _ := cmd.Flags().GetDuration("delay")
+	count, _ := cmd.Flags().GetInt32("count")
+
+	return sendFaultRequest(cmd, &proto.SetFaultRequest{
+		Fault:       args[0],
+		Probability: probability,
+		Delay:       durationpb.New(delay),
+		Count:       count,
+	})
+}
+
+func clearFault(cmd *cobra.Command, args []string) error {
+	req := &proto.SetFaultRequest{Clear: true}
+	if len(args) > 0 {
+		req.Fault = args[0]
+	}
+	return sendFaultRequest(cmd, req)
+}
+
+func sendFaultRequest(cmd *cobra.Command, req *proto.SetFaultRequest) error {
+	conn, err := getClient(cmd)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	client := proto.NewDaemonServiceClient(conn)
+	if _, err := client.SetFault(cmd.Context(), req); err != nil {
+		return fmt.Errorf("failed to set fault: %v", status.Convert(err).Message())
+	}
+
+	switch {
+	case req.GetClear() && req.GetFault() == "":
+		cmd.Println("Cleared all the faults")
+	case req.GetClear():
+		cmd.Printf("Cleared fault %s\n", req.GetFault())
+	default:
+		cmd.Printf("Injecting fault %s\n", req.GetFault())
+	}
+	return nil
+}
+
+func listFaults(cmd *cobra.Command, _ []string) error {
+	conn, err := getClient(cmd)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	client := proto.NewDaemonServiceClient(conn)
+	resp, err := client.ListFaults(cmd.Context(), &proto.ListFaultsRequest{})
+	if err != nil {
+		return fmt.Errorf("failed to list faults: %v", status.Convert(err).Message())
+	}
+
+	if !resp.GetEnabled() {
+		cmd.Println("Fault injection is not compiled into the daemon, build it with the faultinject tag")
+		return nil
+	}
+	if len(resp.GetFaults()) == 0 {
+		cmd.Println("No faults injected")
+		return nil
+	}
+
+	for _, f := range resp.GetFaults() {
+		cmd.Printf("%s: probability %v, delay %s, count %d, injected %d times\n",
+			f.GetFault(), f.GetProbability(), f.GetDelay().AsDuration(), f.GetCount(), f.GetInjected())
+	}
+	return nil
+}
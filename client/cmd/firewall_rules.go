@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/status"
+
+	"github.com/netbirdio/netbird/client/proto"
+)
+
+var firewallRulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "Dump the firewall rules and the drift from the applied ones",
+	Long: "Lists the ACL and NAT rules the client applied next to the rules present in the firewall.\n" +
+		"Rules marked with - were applied but are missing from the firewall, rules marked with + are present\n" +
+		"in a chain of the client but weren't applied by it.",
+	Example: `
+  netbird debug rules
+  netbird debug rules --drift`,
+	Args: cobra.NoArgs,
+	RunE: dumpFirewallRules,
+}
+
+func init() {
+	debugCmd.AddCommand(firewallRulesCmd)
+
+	firewallRulesCmd.Flags().Bool("drift", false, "Only list the missing and unexpected rules")
+}
+
+func dumpFirewallRules(cmd *cobra.Command, _ []string) error {
+	driftOnly, _ := cmd.Flags().GetBool("drift")
+
+	conn, err := getClient(cmd)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	client := proto.NewDaemonServiceClient(conn)
+	resp, err := client.DebugFirewallRules(cmd.Context(), &proto.DebugFirewallRulesRequest{})
+	if err != nil {
+		return fmt.Errorf("failed to dump firewall rules: %v", status.Convert(err).Message())
+	}
+
+	drifted := 0
+	for _, dump := range resp.GetDumps() {
+		drifted += printRulesDump(cmd, dump, driftOnly)
+	}
+
+	if drifted == 0 {
+		cmd.Println("\033[32mThe firewall matches the applied rules\033[0m")
+	} else {
+		cmd.Printf("\033[31mFound drift in %d chains\033[0m\n", drifted)
+	}
+	return nil
+}
+
+// printRulesDump prints the rule sets of the dump and returns the number of sets with drift
+func printRulesDump(cmd *cobra.Command, dump *proto.FirewallRulesDump, driftOnly bool) int {
+	cmd.Printf("Backend: %s\n", dump.GetBackend())
+
+	drifted := 0
+	for _, set := range dump.GetRuleSets() {
+		hasDrift := len(set.GetMissing()) > 0 || len(set.GetUnexpected()) > 0
+		if hasDrift {
+			drifted++
+		}
+		if driftOnly && !hasDrift {
+			continue
+		}
+
+		name := set.GetChain()
+		if set.GetTable() != "" {
+			name = set.GetTable() + " " + name
+		}
+		cmd.Printf("\n  %s (%d applied, %d present)\n", name, len(set.GetApplied()), len(set.GetPresent()))
+
+		unexpected := make(map[string]int, len(set.GetUnexpected()))
+		for _, rule := range set.GetUnexpected() {
+			unexpected[rule]++
+		}
+
+		for _, rule := range set.GetPresent() {
+			if unexpected[rule] > 0 {
+				unexpected[rule]--
+				cmd.Printf("  \033[33m+ %s\033[0m\n", rule)
+			} else if !driftOnly {
+				cmd.Printf("    %s\n", rule)
+			}
+		}
+		for _, rule := range set.GetMissing() {
+			cmd.Printf("  \033[31m- %s\033[0m\n", rule)
+		}
+	}
+	cmd.Println()
+
+	return drifted
+}
@@ -157,6 +157,9 @@ func foregroundLogin(ctx context.Context, cmd *cobra.Command, config *internal.C
 	}
 
 	jwtToken := ""
+	if setupKey == "" && needsLogin && config.RouterMode {
+		return internal.ErrInteractiveLoginDisabled
+	}
 	if setupKey == "" && needsLogin {
 		tokenInfo, err := foregroundGetTokenInfo(ctx, cmd, config)
 		if err != nil {
@@ -16,7 +16,7 @@ var networksCMD = &cobra.Command{
 	Use:     "networks",
 	Aliases: []string{"routes"},
 	Short:   "Manage networks",
-	Long:    `Commands to list, select, or deselect networks. Replaces the "routes" command.`,
+	Long:    `Commands to list, select, deselect, or approve networks. Replaces the "routes" command.`,
 }
 
 var routesListCmd = &cobra.Command{
@@ -29,23 +29,41 @@ var routesListCmd = &cobra.Command{
 }
 
 var routesSelectCmd = &cobra.Command{
-	Use:     "select network...|all",
+	Use:     "select network...|all|auto",
 	Short:   "Select network",
-	Long:    "Select a list of networks by identifiers or 'all' to clear all selections and to accept all (including new) networks.\nDefault mode is replace, use -a to append to already selected networks.",
-	Example: "  netbird networks select all\n  netbird networks select route1 route2\n  netbird routes select -a route3",
+	Long:    "Select a list of networks by identifiers or 'all' to clear all selections and to accept all (including new) networks.\nDefault mode is replace, use -a to append to already selected networks.\nUse 'auto' to use the exit node with the lowest latency and loss, selecting an exit node turns it off.",
+	Example: "  netbird networks select all\n  netbird networks select route1 route2\n  netbird routes select -a route3\n  netbird networks select auto",
 	Args:    cobra.MinimumNArgs(1),
 	RunE:    networksSelect,
 }
 
 var routesDeselectCmd = &cobra.Command{
-	Use:     "deselect network...|all",
+	Use:     "deselect network...|all|auto",
 	Short:   "Deselect networks",
-	Long:    "Deselect previously selected networks by identifiers or 'all' to disable accepting any networks.",
-	Example: "  netbird networks deselect all\n  netbird networks deselect route1 route2",
+	Long:    "Deselect previously selected networks by identifiers or 'all' to disable accepting any networks.\nUse 'auto' to turn the automatic exit node selection off.",
+	Example: "  netbird networks deselect all\n  netbird networks deselect route1 route2\n  netbird networks deselect auto",
 	Args:    cobra.MinimumNArgs(1),
 	RunE:    networksDeselect,
 }
 
+var routesApproveCmd = &cobra.Command{
+	Use:     "approve network...",
+	Short:   "Approve networks",
+	Long:    "Approve high-risk networks, like default routes, waiting for approval. Requires the client to run with --require-route-approval.",
+	Example: "  netbird networks approve exit-node",
+	Args:    cobra.MinimumNArgs(1),
+	RunE:    networksApprove,
+}
+
+var routesRevokeCmd = &cobra.Command{
+	Use:     "revoke network...",
+	Short:   "Revoke network approvals",
+	Long:    "Revoke the approval of high-risk networks, their routes wait for a new approval.",
+	Example: "  netbird networks revoke exit-node",
+	Args:    cobra.MinimumNArgs(1),
+	RunE:    networksRevoke,
+}
+
 func init() {
 	routesSelectCmd.PersistentFlags().BoolVarP(&appendFlag, "append", "a", false, "Append to current network selection instead of replacing")
 }
@@ -78,6 +96,9 @@ func printNetworks(cmd *cobra.Command, resp *proto.ListNetworksResponse) {
 	for _, route := range resp.Routes {
 		printNetwork(cmd, route)
 	}
+	if resp.GetExitNodeAuto() {
+		cmd.Println("\nExit node: auto, the exit node with the lowest latency and loss is selected.")
+	}
 }
 
 func printNetwork(cmd *cobra.Command, route *proto.Network) {
@@ -92,6 +113,9 @@ func printNetwork(cmd *cobra.Command, route *proto.Network) {
 }
 
 func getSelectedStatus(route *proto.Network) string {
+	if route.GetPendingApproval() {
+		return "Pending Approval"
+	}
 	if route.GetSelected() {
 		return "Selected"
 	}
@@ -132,9 +156,13 @@ func networksSelect(cmd *cobra.Command, args []string) error {
 		NetworkIDs: args,
 	}
 
-	if len(args) == 1 && args[0] == "all" {
+	switch {
+	case len(args) == 1 && args[0] == "all":
 		req.All = true
-	} else if appendFlag {
+	case len(args) == 1 && args[0] == "auto":
+		req.NetworkIDs = nil
+		req.ExitNodeAuto = true
+	case appendFlag:
 		req.Append = true
 	}
 
@@ -159,8 +187,12 @@ func networksDeselect(cmd *cobra.Command, args []string) error {
 		NetworkIDs: args,
 	}
 
-	if len(args) == 1 && args[0] == "all" {
+	switch {
+	case len(args) == 1 && args[0] == "all":
 		req.All = true
+	case len(args) == 1 && args[0] == "auto":
+		req.NetworkIDs = nil
+		req.ExitNodeAuto = true
 	}
 
 	if _, err := client.DeselectNetworks(cmd.Context(), req); err != nil {
@@ -171,3 +203,41 @@ func networksDeselect(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+func networksApprove(cmd *cobra.Command, args []string) error {
+	conn, err := getClient(cmd)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	client := proto.NewDaemonServiceClient(conn)
+	if _, err := client.ApproveNetworks(cmd.Context(), &proto.ApproveNetworksRequest{NetworkIDs: args}); err != nil {
+		return fmt.Errorf("failed to approve networks: %v", status.Convert(err).Message())
+	}
+
+	cmd.Println("Networks approved successfully.")
+
+	return nil
+}
+
+func networksRevoke(cmd *cobra.Command, args []string) error {
+	conn, err := getClient(cmd)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	client := proto.NewDaemonServiceClient(conn)
+	req := &proto.ApproveNetworksRequest{
+		NetworkIDs: args,
+		Revoke:     true,
+	}
+	if _, err := client.ApproveNetworks(cmd.Context(), req); err != nil {
+		return fmt.Errorf("failed to revoke network approvals: %v", status.Convert(err).Message())
+	}
+
+	cmd.Println("Network approvals revoked successfully.")
+
+	return nil
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/status"
+
+	"github.com/netbirdio/netbird/client/proto"
+)
+
+const profileFlag = "profile"
+
+var (
+	profileName          string
+	profileInterfaceName string
+	profileWireguardPort uint16
+)
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage the profiles",
+	Long: "Lists, adds and removes the profiles. Each profile runs a daemon of its own next to the default one, with its " +
+		"own account, WireGuard interface, routes, DNS and firewall rules.\n" +
+		"Select the profile of the other commands with --profile, e.g. netbird up --profile lab.",
+}
+
+var profileListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List the profiles",
+	Args:    cobra.NoArgs,
+	RunE:    listProfiles,
+}
+
+var profileAddCmd = &cobra.Command{
+	Use:     "add <name>",
+	Short:   "Add a profile and start its daemon",
+	Example: "  netbird profile add lab\n  netbird up --profile lab --management-url https://lab.example.com",
+	Args:    cobra.ExactArgs(1),
+	RunE:    addProfile,
+}
+
+var profileRemoveCmd = &cobra.Command{
+	Use:     "remove <name>",
+	Aliases: []string{"rm"},
+	Short:   "Stop the daemon of a profile and remove its config and state",
+	Args:    cobra.ExactArgs(1),
+	RunE:    removeProfile,
+}
+
+func init() {
+	rootCmd.AddCommand(profileCmd)
+	profileCmd.AddCommand(profileListCmd, profileAddCmd, profileRemoveCmd)
+
+	rootCmd.PersistentFlags().StringVar(&profileName, profileFlag, "", "Profile whose daemon serves the command, the default daemon serves it if empty")
+	rootCmd.PersistentPreRunE = resolveProfile
+
+	profileAddCmd.Flags().StringVar(&profileInterfaceName, interfaceNameFlag, "", "Wireguard interface name of the profile (default is derived from the position of the profile, e.g. wt1)")
+	profileAddCmd.Flags().Uint16Var(&profileWireguardPort, wireguardPortFlag, 0, "Wireguard interface listening port of the profile (default is derived from the position of the profile, e.g. 51821)")
+}
+
+// resolveProfile points the daemon address to the daemon of the profile selected with --profile, the default daemon
+// knows the addresses of the daemons of the profiles
+func resolveProfile(cmd *cobra.Command, _ []string) error {
+	if profileName == "" {
+		return nil
+	}
+	if cmd.Parent() == serviceCmd || cmd.Parent() == profileCmd {
+		return fmt.Errorf("--%s isn't supported by the %s commands", profileFlag, cmd.Parent().Name())
+	}
+
+	SetFlagsFromEnvVars(rootCmd)
+
+	conn, err := DialClientGRPCServer(cmd.Context(), daemonAddr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to daemon error: %v", err)
+	}
+	defer conn.Close()
+
+	resp, err := proto.NewDaemonServiceClient(conn).ListProfiles(cmd.Context(), &proto.ListProfilesRequest{})
+	if err != nil {
+		return fmt.Errorf("failed to list profiles: %v", status.Convert(err).Message())
+	}
+	for _, p := range resp.GetProfiles() {
+		if p.GetName() == profileName {
+			daemonAddr = p.GetDaemonAddr()
+			return nil
+		}
+	}
+	return fmt.Errorf("profile %s not found, add it with: netbird profile add %s", profileName, profileName)
+}
+
+func listProfiles(cmd *cobra.Command, _ []string) error {
+	conn, err := getClient(cmd)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	resp, err := proto.NewDaemonServiceClient(conn).ListProfiles(cmd.Context(), &proto.ListProfilesRequest{})
+	if err != nil {
+		return fmt.Errorf("failed to list profiles: %v", status.Convert(err).Message())
+	}
+
+	if len(resp.GetProfiles()) == 0 {
+		cmd.Println("No profiles added")
+		return nil
+	}
+
+	for _, p := range resp.GetProfiles() {
+		printProfile(cmd, p)
+	}
+	return nil
+}
+
+func printProfile(cmd *cobra.Command, p *proto.Profile) {
+	state := "Stopped"
+	if p.GetRunning() {
+		state = "Running"
+	}
+	cmd.Printf("\n  - Name: %s\n    Interface: %s\n    WireGuard port: %d\n    Daemon: %s\n    Config: %s\n    Status: %s\n",
+		p.GetName(), p.GetInterfaceName(), p.GetWireguardPort(), p.GetDaemonAddr(), p.GetConfigPath(), state)
+}
+
+func addProfile(cmd *cobra.Command, args []string) error {
+	conn, err := getClient(cmd)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	resp, err := proto.NewDaemonServiceClient(conn).AddProfile(cmd.Context(), &proto.AddProfileRequest{
+		Name:          args[0],
+		InterfaceName: profileInterfaceName,
+		WireguardPort: int64(profileWireguardPort),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add profile: %v", status.Convert(err).Message())
+	}
+
+	cmd.Printf("Profile %s added", args[0])
+	printProfile(cmd, resp.GetProfile())
+	cmd.Printf("\nConnect it with: netbird up --%s %s\n", profileFlag, args[0])
+	return nil
+}
+
+func removeProfile(cmd *cobra.Command, args []string) error {
+	conn, err := getClient(cmd)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := proto.NewDaemonServiceClient(conn).RemoveProfile(cmd.Context(), &proto.RemoveProfileRequest{Name: args[0]}); err != nil {
+		return fmt.Errorf("failed to remove profile: %v", status.Convert(err).Message())
+	}
+
+	cmd.Printf("Profile %s removed\n", args[0])
+	return nil
+}
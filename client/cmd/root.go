@@ -39,6 +39,12 @@ const (
 	dnsRouteIntervalFlag    = "dns-router-interval"
 	systemInfoFlag          = "system-info"
 	blockLANAccessFlag      = "block-lan-access"
+	remoteDebugBundlesFlag  = "remote-debug-bundles"
+	crashReportURLFlag      = "crash-report-url"
+	staticMeshFileFlag      = "static-mesh-file"
+	apiGatewayAddrFlag      = "api-gateway-addr"
+	apiGatewayTokenFileFlag = "api-gateway-token-file"
+	metricsAddrFlag         = "metrics-addr"
 )
 
 var (
@@ -75,6 +81,12 @@ var (
 	debugSystemInfoFlag     bool
 	dnsRouteInterval        time.Duration
 	blockLANAccess          bool
+	remoteDebugBundles      string
+	crashReportURL          string
+	staticMeshFile          string
+	apiGatewayAddr          string
+	apiGatewayTokenFile     string
+	metricsAddr             string
 
 	rootCmd = &cobra.Command{
 		Use:          "netbird",
@@ -124,6 +136,9 @@ func init() {
 	}
 
 	rootCmd.PersistentFlags().StringVar(&daemonAddr, "daemon-addr", defaultDaemonAddr, "Daemon service address to serve CLI requests [unix|tcp]://[path|host:port]")
+	rootCmd.PersistentFlags().StringVar(&apiGatewayAddr, apiGatewayAddrFlag, "", "Loopback address to serve the daemon API over REST and WebSocket, e.g. 127.0.0.1:41732. Disabled if empty")
+	rootCmd.PersistentFlags().StringVar(&apiGatewayTokenFile, apiGatewayTokenFileFlag, "", "Path of the token file of the API gateway, generated if it doesn't exist (default is next to the config file)")
+	rootCmd.PersistentFlags().StringVar(&metricsAddr, metricsAddrFlag, "", "Address to serve the daemon metrics in the Prometheus format under /metrics, e.g. 127.0.0.1:9090. Disabled if empty")
 	rootCmd.PersistentFlags().StringVarP(&managementURL, "management-url", "m", "", fmt.Sprintf("Management Service URL [http|https]://[host]:[port] (default \"%s\")", internal.DefaultManagementURL))
 	rootCmd.PersistentFlags().StringVar(&adminURL, "admin-url", "", fmt.Sprintf("Admin Panel URL [http|https]://[host]:[port] (default \"%s\")", internal.DefaultAdminURL))
 	rootCmd.PersistentFlags().StringVarP(&serviceName, "service", "s", defaultServiceName, "Netbird system service name")
@@ -144,6 +159,7 @@ func init() {
 	rootCmd.AddCommand(loginCmd)
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(sshCmd)
+	rootCmd.AddCommand(cpCmd)
 	rootCmd.AddCommand(networksCMD)
 	rootCmd.AddCommand(forwardingRulesCmd)
 	rootCmd.AddCommand(debugCmd)
@@ -153,6 +169,7 @@ func init() {
 
 	networksCMD.AddCommand(routesListCmd)
 	networksCMD.AddCommand(routesSelectCmd, routesDeselectCmd)
+	networksCMD.AddCommand(routesApproveCmd, routesRevokeCmd)
 
 	forwardingRulesCmd.AddCommand(forwardingRulesListCmd)
 
@@ -11,6 +11,8 @@ import (
 
 	"github.com/netbirdio/netbird/client/internal"
 	"github.com/netbirdio/netbird/client/server"
+	"github.com/netbirdio/netbird/client/server/gateway"
+	"github.com/netbirdio/netbird/client/server/metrics"
 )
 
 type program struct {
@@ -19,6 +21,8 @@ type program struct {
 	serv             *grpc.Server
 	serverInstance   *server.Server
 	serverInstanceMu sync.Mutex
+	apiGateway       *gateway.Gateway
+	metricsServer    *metrics.Server
 }
 
 func newProgram(ctx context.Context, cancel context.CancelFunc) *program {
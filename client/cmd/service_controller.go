@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -14,8 +15,11 @@ import (
 	"github.com/spf13/cobra"
 	"google.golang.org/grpc"
 
+	"github.com/netbirdio/netbird/client/internal/profiles"
 	"github.com/netbirdio/netbird/client/proto"
 	"github.com/netbirdio/netbird/client/server"
+	"github.com/netbirdio/netbird/client/server/gateway"
+	"github.com/netbirdio/netbird/client/server/metrics"
 	"github.com/netbirdio/netbird/util"
 )
 
@@ -23,7 +27,17 @@ func (p *program) Start(svc service.Service) error {
 	// Start should not block. Do the actual work async.
 	log.Info("starting Netbird service") //nolint
 	// in any case, even if configuration does not exists we run daemon to serve CLI gRPC API.
-	p.serv = grpc.NewServer()
+	p.serv = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(server.UnaryErrorCodeInterceptor),
+		grpc.ChainStreamInterceptor(server.StreamErrorCodeInterceptor),
+	)
+
+	// the metrics server sets the global meter provider, it's started before the engine records the metrics
+	if metricsAddr != "" {
+		if err := p.startMetrics(); err != nil {
+			log.Errorf("failed to start the metrics server: %v", err)
+		}
+	}
 
 	split := strings.Split(daemonAddr, "://")
 	switch split[0] {
@@ -59,10 +73,21 @@ func (p *program) Start(svc service.Service) error {
 		if err := serverInstance.Start(); err != nil {
 			log.Fatalf("failed to start daemon: %v", err)
 		}
+		// the daemons of the profiles are started by the default daemon
+		if profiles.Current() == "" {
+			if err := serverInstance.StartProfiles(daemonAddr, logLevel); err != nil {
+				log.Errorf("failed to start the profiles: %v", err)
+			}
+		}
 		proto.RegisterDaemonServiceServer(p.serv, serverInstance)
 
 		p.serverInstanceMu.Lock()
 		p.serverInstance = serverInstance
+		if apiGatewayAddr != "" {
+			if err := p.startAPIGateway(serverInstance); err != nil {
+				log.Errorf("failed to start the API gateway: %v", err)
+			}
+		}
 		p.serverInstanceMu.Unlock()
 
 		log.Printf("started daemon server: %v", split[1])
@@ -76,12 +101,24 @@ func (p *program) Start(svc service.Service) error {
 func (p *program) Stop(srv service.Service) error {
 	p.serverInstanceMu.Lock()
 	if p.serverInstance != nil {
+		p.serverInstance.StopProfiles()
+
 		in := new(proto.DownRequest)
 		_, err := p.serverInstance.Down(p.ctx, in)
 		if err != nil {
 			log.Errorf("failed to stop daemon: %v", err)
 		}
 	}
+	if p.apiGateway != nil {
+		if err := p.apiGateway.Stop(); err != nil {
+			log.Errorf("failed to stop the API gateway: %v", err)
+		}
+	}
+	if p.metricsServer != nil {
+		if err := p.metricsServer.Stop(); err != nil {
+			log.Errorf("failed to stop the metrics server: %v", err)
+		}
+	}
 	p.serverInstanceMu.Unlock()
 
 	p.cancel()
@@ -95,6 +132,44 @@ func (p *program) Stop(srv service.Service) error {
 	return nil
 }
 
+// startAPIGateway serves the daemon API over REST and WebSocket for the tooling that doesn't use gRPC
+func (p *program) startAPIGateway(daemon proto.DaemonServiceServer) error {
+	tokenFile := apiGatewayTokenFile
+	if tokenFile == "" {
+		tokenFile = filepath.Join(filepath.Dir(configPath), gateway.TokenFileName)
+	}
+
+	token, err := gateway.LoadOrCreateToken(tokenFile)
+	if err != nil {
+		return fmt.Errorf("load token: %w", err)
+	}
+
+	apiGateway, err := gateway.New(daemon, apiGatewayAddr, token)
+	if err != nil {
+		return err
+	}
+	if err := apiGateway.Start(); err != nil {
+		return err
+	}
+
+	p.apiGateway = apiGateway
+	return nil
+}
+
+// startMetrics serves the metrics of the daemon in the Prometheus format
+func (p *program) startMetrics() error {
+	metricsServer, err := metrics.New(metricsAddr)
+	if err != nil {
+		return err
+	}
+	if err := metricsServer.Start(); err != nil {
+		return err
+	}
+
+	p.metricsServer = metricsServer
+	return nil
+}
+
 var runCmd = &cobra.Command{
 	Use:   "run",
 	Short: "runs Netbird as service",
@@ -43,6 +43,18 @@ var installCmd = &cobra.Command{
 			svcConfig.Arguments = append(svcConfig.Arguments, "--log-file", logFile)
 		}
 
+		if apiGatewayAddr != "" {
+			svcConfig.Arguments = append(svcConfig.Arguments, "--"+apiGatewayAddrFlag, apiGatewayAddr)
+		}
+
+		if apiGatewayTokenFile != "" {
+			svcConfig.Arguments = append(svcConfig.Arguments, "--"+apiGatewayTokenFileFlag, apiGatewayTokenFile)
+		}
+
+		if metricsAddr != "" {
+			svcConfig.Arguments = append(svcConfig.Arguments, "--"+metricsAddrFlag, metricsAddr)
+		}
+
 		if runtime.GOOS == "linux" {
 			// Respected only by systemd systems
 			svcConfig.Dependencies = []string{"After=network.target syslog.target"}
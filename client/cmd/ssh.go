@@ -4,22 +4,29 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
+	"net/netip"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
 	"github.com/netbirdio/netbird/client/internal"
+	"github.com/netbirdio/netbird/client/proto"
 	nbssh "github.com/netbirdio/netbird/client/ssh"
 	"github.com/netbirdio/netbird/util"
 )
 
 var (
-	port int
-	user = "root"
-	host string
+	port         int
+	user         = "root"
+	host         string
+	jumpHost     string
+	identityFile string
 )
 
 var sshCmd = &cobra.Command{
@@ -40,6 +47,10 @@ var sshCmd = &cobra.Command{
 		return nil
 	},
 	Short: "connect to a remote SSH server",
+	Long: "Connects to the embedded SSH server of a peer. A host of a network routed by a peer, which can't run NetBird, " +
+		"is reached through the embedded SSH server of its routing peer as a jump host, if a route access policy allows " +
+		"this peer to reach it. The host authenticates with its own keys or password, the routing peer logs the jump.",
+	Example: "  netbird ssh peer.netbird.cloud\n  netbird ssh admin@10.1.2.3\n  netbird ssh --jump router.netbird.cloud -p 2222 admin@10.1.2.3",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		SetFlagsFromEnvVars(rootCmd)
 		SetFlagsFromEnvVars(cmd)
@@ -89,7 +100,18 @@ var sshCmd = &cobra.Command{
 }
 
 func runSSH(ctx context.Context, addr string, pemKey []byte, cmd *cobra.Command) error {
-	c, err := nbssh.DialWithKey(fmt.Sprintf("%s:%d", addr, port), user, pemKey)
+	jump := jumpHost
+	if jump == "" {
+		jump = routingPeerOf(cmd, addr)
+	}
+
+	var c *nbssh.Client
+	var err error
+	if jump != "" {
+		c, err = dialThroughJumpHost(cmd, jump, addr, pemKey)
+	} else {
+		c, err = nbssh.DialWithKey(fmt.Sprintf("%s:%d", addr, port), user, pemKey)
+	}
 	if err != nil {
 		cmd.Printf("Error: %v\n", err)
 		cmd.Printf("Couldn't connect. Please check the connection status or if the ssh server is enabled on the other peer" +
@@ -113,6 +135,97 @@ func runSSH(ctx context.Context, addr string, pemKey []byte, cmd *cobra.Command)
 	return nil
 }
 
+// dialThroughJumpHost connects to a host of a network routed by the jump host through the embedded SSH server of the
+// jump host. The SSH port of the host defaults to 22.
+func dialThroughJumpHost(cmd *cobra.Command, jump, addr string, pemKey []byte) (*nbssh.Client, error) {
+	targetIP, err := resolveJumpTarget(addr)
+	if err != nil {
+		return nil, err
+	}
+	targetPort := 22
+	if cmd.Flag("port").Changed {
+		targetPort = port
+	}
+
+	config, err := nbssh.TargetClientConfig(user, identityFile, nbssh.DefaultKnownHostsFile())
+	if err != nil {
+		return nil, err
+	}
+
+	via, err := nbssh.DialWithKey(net.JoinHostPort(jump, strconv.Itoa(nbssh.DefaultSSHPort)), user, pemKey)
+	if err != nil {
+		return nil, fmt.Errorf("connect to jump host %s: %w", jump, err)
+	}
+
+	c, err := via.Jump(net.JoinHostPort(targetIP.String(), strconv.Itoa(targetPort)), config)
+	if err != nil {
+		if closeErr := via.Close(); closeErr != nil {
+			log.Debugf("failed to close the connection to jump host %s: %v", jump, closeErr)
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+// resolveJumpTarget resolves the host of the routed network locally, the jump host accepts IP addresses only to
+// match them against the access policies
+func resolveJumpTarget(addr string) (netip.Addr, error) {
+	if ip, err := netip.ParseAddr(addr); err == nil {
+		return ip, nil
+	}
+
+	ips, err := net.DefaultResolver.LookupNetIP(context.Background(), "ip", addr)
+	if err != nil || len(ips) == 0 {
+		return netip.Addr{}, fmt.Errorf("resolve %s: %w", addr, err)
+	}
+	return ips[0].Unmap(), nil
+}
+
+// routingPeerOf returns the NetBird IP of the peer routing the network of the host, empty if the host is a peer, isn't
+// routed or the daemon can't tell
+func routingPeerOf(cmd *cobra.Command, addr string) string {
+	ip, err := netip.ParseAddr(addr)
+	if err != nil {
+		return ""
+	}
+
+	conn, err := getClient(cmd)
+	if err != nil {
+		log.Debugf("failed to get the routing peer of %s: %v", addr, err)
+		return ""
+	}
+	defer conn.Close()
+
+	resp, err := proto.NewDaemonServiceClient(conn).Status(cmd.Context(), &proto.StatusRequest{GetFullPeerStatus: true})
+	if err != nil {
+		log.Debugf("failed to get the routing peer of %s: %v", addr, err)
+		return ""
+	}
+
+	var routingPeer string
+	bits := -1
+	for _, p := range resp.GetFullStatus().GetPeers() {
+		peerIP := strings.Split(p.GetIP(), "/")[0]
+		if peerIP == ip.String() {
+			return ""
+		}
+		for _, network := range p.GetNetworks() {
+			prefix, err := netip.ParsePrefix(network)
+			if err != nil || !prefix.Contains(ip) || prefix.Bits() <= bits {
+				continue
+			}
+			routingPeer, bits = peerIP, prefix.Bits()
+		}
+	}
+
+	if routingPeer != "" {
+		cmd.Printf("Connecting to %s through routing peer %s\n", addr, routingPeer)
+	}
+	return routingPeer
+}
+
 func init() {
-	sshCmd.PersistentFlags().IntVarP(&port, "port", "p", nbssh.DefaultSSHPort, "Sets remote SSH port. Defaults to "+fmt.Sprint(nbssh.DefaultSSHPort))
+	sshCmd.PersistentFlags().IntVarP(&port, "port", "p", nbssh.DefaultSSHPort, "Sets remote SSH port. Defaults to "+fmt.Sprint(nbssh.DefaultSSHPort)+", or 22 for a host of a routed network")
+	sshCmd.PersistentFlags().StringVarP(&jumpHost, "jump", "J", "", "Connects through the embedded SSH server of this routing peer, like ssh -J. Defaults to the routing peer of the network of the host if the host isn't a peer")
+	sshCmd.PersistentFlags().StringVarP(&identityFile, "identity", "i", "", "Private key to log in to a host of a routed network with. The SSH agent and the default keys of ~/.ssh are tried as well")
 }
@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"net"
 	"net/netip"
+	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 	"google.golang.org/grpc/status"
 
 	"github.com/netbirdio/netbird/client/internal"
@@ -87,6 +89,7 @@ func statusFunc(cmd *cobra.Command, args []string) error {
 	}
 
 	var outputInformationHolder = nbstatus.ConvertToStatusOutputOverview(resp, anonymizeFlag, statusFilter, prefixNamesFilter, prefixNamesFilterMap, ipsFilterMap)
+	outputInformationHolder.Colors = colorOutput(cmd)
 	var statusOutputString string
 	switch {
 	case detailFlag:
@@ -108,6 +111,15 @@ func statusFunc(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// colorOutput reports whether the output goes to a terminal accepting colors, see https://no-color.org
+func colorOutput(cmd *cobra.Command) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	f, ok := cmd.OutOrStdout().(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
 func getStatus(ctx context.Context) (*proto.StatusResponse, error) {
 	conn, err := DialClientGRPCServer(ctx, daemonAddr)
 	if err != nil {
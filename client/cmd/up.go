@@ -31,13 +31,77 @@ const (
 )
 
 const (
-	dnsLabelsFlag = "extra-dns-labels"
+	dnsLabelsFlag  = "extra-dns-labels"
+	uplinksFlag    = "uplinks"
+	routerModeFlag = "router-mode"
+	routerLANsFlag = "router-lans"
+
+	routeLearningFlag        = "learn-routes"
+	routeLearningFiltersFlag = "learn-routes-filter"
+	routeDrainPeriodFlag     = "route-drain-period"
+
+	splitTunnelAppsFlag  = "split-tunnel-apps"
+	splitTunnelUsersFlag = "split-tunnel-users"
+
+	fwmarkFlag         = "fwmark"
+	routingTableIDFlag = "routing-table-id"
+
+	overlayOnlyServicesFlag = "overlay-only-services"
+
+	requireRouteApprovalFlag = "require-route-approval"
+
+	exitNodeStickyGraceFlag = "exit-node-sticky-grace"
+
+	dnsHostsFileFlag = "dns-hosts-file"
+
+	maxICENegotiationsFlag = "max-ice-negotiations"
+
+	lanGatewayVIPFlag       = "lan-gateway-vip"
+	lanGatewayInterfaceFlag = "lan-gateway-interface"
+	lanGatewayPriorityFlag  = "lan-gateway-priority"
+	lanGatewayPeersFlag     = "lan-gateway-peers"
+
+	fileTransferDirFlag = "file-transfer-dir"
+
+	remoteActionsFlag = "remote-actions"
 )
 
 var (
 	foregroundMode     bool
 	dnsLabels          []string
 	dnsLabelsValidated domain.List
+	uplinks            []string
+	routerMode         bool
+	routerLANs         []string
+
+	routeLearning        string
+	routeLearningFilters []string
+	routeDrainPeriod     time.Duration
+
+	splitTunnelApps  []string
+	splitTunnelUsers []string
+
+	fwmark         uint32
+	routingTableID uint32
+
+	overlayOnlyServices []string
+
+	requireRouteApproval bool
+
+	exitNodeStickyGrace time.Duration
+
+	dnsHostsFile bool
+
+	maxICENegotiations int
+
+	lanGatewayVIP       string
+	lanGatewayInterface string
+	lanGatewayPriority  int
+	lanGatewayPeers     []string
+
+	fileTransferDir string
+
+	remoteActions []string
 
 	upCmd = &cobra.Command{
 		Use:   "up",
@@ -57,6 +121,18 @@ func init() {
 	upCmd.PersistentFlags().StringSliceVar(&extraIFaceBlackList, extraIFaceBlackListFlag, nil, "Extra list of default interfaces to ignore for listening")
 	upCmd.PersistentFlags().DurationVar(&dnsRouteInterval, dnsRouteIntervalFlag, time.Minute, "DNS route update interval")
 	upCmd.PersistentFlags().BoolVar(&blockLANAccess, blockLANAccessFlag, false, "Block access to local networks (LAN) when using this peer as a router or exit node")
+	upCmd.PersistentFlags().StringVar(&remoteDebugBundles, remoteDebugBundlesFlag, internal.RemoteDebugBundlesAsk,
+		`Sets how debug bundles requested by the management service are handled: `+
+			`"ask" waits for the approval of the local user, "allow" uploads them and "deny" declines them.`,
+	)
+	upCmd.PersistentFlags().StringVar(&crashReportURL, crashReportURLFlag, "",
+		`Submits anonymized crash reports to the endpoint, reports are only saved locally by default. `+
+			`An empty string "" disables the submission.`,
+	)
+	upCmd.PersistentFlags().StringVar(&staticMeshFile, staticMeshFileFlag, "",
+		`Runs the client with the peers defined in the file, without the management and signal services. `+
+			`An empty string "" disables the static mesh mode.`,
+	)
 
 	upCmd.PersistentFlags().StringSliceVar(&dnsLabels, dnsLabelsFlag, nil,
 		`Sets DNS labels`+
@@ -65,6 +141,113 @@ func init() {
 			`E.g. --extra-dns-labels vpc1 or --extra-dns-labels vpc1,mgmt1 `+
 			`or --extra-dns-labels ""`,
 	)
+	upCmd.PersistentFlags().StringSliceVar(&uplinks, uplinksFlag, nil,
+		`Sets the uplink interfaces of a peer with several internet connections in the order of preference. `+
+			`The first healthy uplink carries the control plane and the tunnels, the next ones take over when it fails. `+
+			`An empty string "" clears the previous configuration. `+
+			`E.g. --uplinks eth0,eth1 or --uplinks ""`,
+	)
+	upCmd.PersistentFlags().BoolVar(&routerMode, routerModeFlag, false,
+		`Runs the client as a gateway appliance: the interactive login is disabled, IP forwarding is enabled, `+
+			`conntrack and the socket buffers are tuned for routing and a read-only status page is served locally. `+
+			`E.g. --router-mode=true to enable or --router-mode=false to disable.`,
+	)
+	upCmd.PersistentFlags().StringSliceVar(&routerLANs, routerLANsFlag, nil,
+		`Sets the LAN prefixes behind the appliance advertised in router mode. `+
+			`An empty string "" clears the previous configuration. `+
+			`E.g. --router-lans 192.168.1.0/24,10.10.0.0/16 or --router-lans ""`,
+	)
+	upCmd.PersistentFlags().StringVar(&routeLearning, routeLearningFlag, "",
+		`Learns the internal prefixes of the site from its routers and advertises them as dynamic routes. `+
+			`"kernel" reads the routes installed by a routing daemon like FRR or BIRD, "frr" reads the BGP routes from FRR. `+
+			`An empty string "" disables the route learning.`,
+	)
+	upCmd.PersistentFlags().StringSliceVar(&routeLearningFilters, routeLearningFiltersFlag, nil,
+		`Selects the learned routes advertised to management. The first matching filter decides, `+
+			`a filter starting with "!" rejects the prefixes and "le" limits the prefix length. `+
+			`An empty string "" clears the previous configuration. `+
+			`E.g. --learn-routes-filter '!10.99.0.0/16,10.0.0.0/8 le 24' or --learn-routes-filter ""`,
+	)
+	upCmd.PersistentFlags().DurationVar(&routeDrainPeriod, routeDrainPeriodFlag, 0,
+		`Sets the time the flows routed by this peer have to finish when it stops. The connected peers are asked to `+
+			`switch to another routing peer first and the new flows are dropped. The stop timeout of the service `+
+			`manager must be longer than the period. 0 removes the routing rules immediately. E.g. --route-drain-period 30s`,
+	)
+	upCmd.PersistentFlags().StringSliceVar(&splitTunnelApps, splitTunnelAppsFlag, nil,
+		`Routes only the traffic of the processes of these binaries through the NetBird routes and exit nodes, `+
+			`the other processes keep using the local network. Requires absolute paths. Supported on Linux and Windows. `+
+			`An empty string "" clears the previous configuration. `+
+			`E.g. --split-tunnel-apps /usr/bin/ssh,/opt/corp/bin/tool or --split-tunnel-apps ""`,
+	)
+	upCmd.PersistentFlags().StringSliceVar(&splitTunnelUsers, splitTunnelUsersFlag, nil,
+		`Routes only the traffic of the processes of these users through the NetBird routes and exit nodes, `+
+			`combined with the split tunnel apps. Supported on Linux and Windows. `+
+			`An empty string "" clears the previous configuration. `+
+			`E.g. --split-tunnel-users alice,bob or --split-tunnel-users ""`,
+	)
+	upCmd.PersistentFlags().Uint32Var(&fwmark, fwmarkFlag, 0,
+		`Sets the base of the fwmarks used by the client on Linux, the following 0x20 marks are used too. `+
+			`0 selects a base not matched by the ip rules of other VPNs and of the host policy routing. E.g. --fwmark 0x2bd00`,
+	)
+	upCmd.PersistentFlags().Uint32Var(&routingTableID, routingTableIDFlag, 0,
+		`Sets the ID of the routing table of the NetBird routes on Linux. `+
+			`0 selects a table not used by other VPNs and by the host policy routing. E.g. --routing-table-id 7200`,
+	)
+	upCmd.PersistentFlags().StringSliceVar(&overlayOnlyServices, overlayOnlyServicesFlag, nil,
+		`Drops the inbound traffic to these local services on the physical interfaces, they're reachable only over `+
+			`NetBird and locally. Services are protocol/port or protocol/start-end. Supported on Linux and Windows. `+
+			`An empty string "" clears the previous configuration. `+
+			`E.g. --overlay-only-services tcp/22,tcp/3389 or --overlay-only-services ""`,
+	)
+	upCmd.PersistentFlags().BoolVar(&requireRouteApproval, requireRouteApprovalFlag, false,
+		`Holds back the default routes and the routes larger than a /8 pushed by management until they're approved `+
+			`with "netbird networks approve" or in the UI. Protects against a hostile exit node pushed to a shared account. `+
+			`E.g. --require-route-approval=true to enable or --require-route-approval=false to disable.`,
+	)
+	upCmd.PersistentFlags().DurationVar(&exitNodeStickyGrace, exitNodeStickyGraceFlag, 0,
+		`Keeps the established connections on the previous exit node for this long after the automatic exit node `+
+			`selection switched to another one, so long-lived downloads and SSH sessions aren't broken. `+
+			`Supported on Linux. 0 switches them right away. E.g. --exit-node-sticky-grace 10m`,
+	)
+	upCmd.PersistentFlags().BoolVar(&dnsHostsFile, dnsHostsFileFlag, false,
+		`Writes the records of the peers to the hosts file and keeps them updated instead of taking over the system DNS, `+
+			`for the hosts where NetBird can't manage the DNS like containers. The match domains and the nameservers `+
+			`aren't applied in this mode. E.g. --dns-hosts-file=true to enable or --dns-hosts-file=false to disable.`,
+	)
+	upCmd.PersistentFlags().IntVar(&maxICENegotiations, maxICENegotiationsFlag, 0,
+		`Caps the ICE negotiations running at once, the routing peers and the exit nodes are negotiated first. `+
+			`Lower it on the hosts of large networks to bound the CPU and the signal traffic after a wake from sleep. `+
+			`0 applies the default of 64. E.g. --max-ice-negotiations 16`,
+	)
+	upCmd.PersistentFlags().StringVar(&lanGatewayVIP, lanGatewayVIPFlag, "",
+		`Shares a virtual gateway IP with its prefix on the LAN with the other routing peers of the site, like VRRP. `+
+			`The healthy peer with the highest priority holds it and a backup takes it over within seconds if it fails, `+
+			`so the LAN hosts can use it as the next hop of the NetBird networks. Supported on Linux. `+
+			`An empty string "" disables it. E.g. --lan-gateway-vip 192.168.1.1/24`,
+	)
+	upCmd.PersistentFlags().StringVar(&lanGatewayInterface, lanGatewayInterfaceFlag, "",
+		`Sets the LAN interface the virtual gateway IP is assigned to. E.g. --lan-gateway-interface eth1`,
+	)
+	upCmd.PersistentFlags().IntVar(&lanGatewayPriority, lanGatewayPriorityFlag, 0,
+		`Sets the priority of the peer for the virtual gateway IP from 1 to 254, the healthy peer with the highest one `+
+			`holds it. 0 applies the default of 100. E.g. --lan-gateway-priority 200`,
+	)
+	upCmd.PersistentFlags().StringSliceVar(&lanGatewayPeers, lanGatewayPeersFlag, nil,
+		`Sets the NetBird IPs of the other routing peers sharing the virtual gateway IP. `+
+			`An empty string "" clears the previous configuration. `+
+			`E.g. --lan-gateway-peers 100.64.0.2,100.64.0.3 or --lan-gateway-peers ""`,
+	)
+	upCmd.PersistentFlags().StringVar(&fileTransferDir, fileTransferDirFlag, "",
+		`Receives the files the peers send with netbird cp into this absolute directory. `+
+			`The access policies decide which peers can send files, they need to reach TCP port 22113 of this peer. `+
+			`An empty string "" disables receiving. E.g. --file-transfer-dir /srv/netbird-inbox`,
+	)
+	upCmd.PersistentFlags().StringSliceVar(&remoteActions, remoteActionsFlag, nil,
+		`Sets the actions the management service may run on the peer: restart, reauth, rotate_key and collect_status. `+
+			`The other actions are declined, none is allowed by default. `+
+			`An empty string "" clears the previous configuration. `+
+			`E.g. --remote-actions restart,collect_status or --remote-actions ""`,
+	)
 }
 
 func upFunc(cmd *cobra.Command, args []string) error {
@@ -120,6 +303,19 @@ func runInForegroundMode(ctx context.Context, cmd *cobra.Command) error {
 		CustomDNSAddress:    customDNSAddressConverted,
 		ExtraIFaceBlackList: extraIFaceBlackList,
 		DNSLabels:           dnsLabelsValidated,
+		Uplinks:             uplinks,
+		RouterLANs:          routerLANs,
+
+		RouteLearningFilters: routeLearningFilters,
+
+		SplitTunnelApps:  splitTunnelApps,
+		SplitTunnelUsers: splitTunnelUsers,
+
+		OverlayOnlyServices: overlayOnlyServices,
+
+		RemoteActions: remoteActions,
+
+		LANGatewayPeers: lanGatewayPeers,
 	}
 
 	if cmd.Flag(enableRosenpassFlag).Changed {
@@ -187,6 +383,71 @@ func runInForegroundMode(ctx context.Context, cmd *cobra.Command) error {
 		ic.BlockLANAccess = &blockLANAccess
 	}
 
+	if cmd.Flag(remoteDebugBundlesFlag).Changed {
+		ic.RemoteDebugBundles = &remoteDebugBundles
+	}
+
+	if cmd.Flag(crashReportURLFlag).Changed {
+		ic.CrashReportURL = &crashReportURL
+	}
+
+	if cmd.Flag(staticMeshFileFlag).Changed {
+		ic.StaticMeshFile = &staticMeshFile
+	}
+
+	if cmd.Flag(routerModeFlag).Changed {
+		ic.RouterMode = &routerMode
+	}
+
+	if cmd.Flag(routeLearningFlag).Changed {
+		ic.RouteLearning = &routeLearning
+	}
+
+	if cmd.Flag(routeDrainPeriodFlag).Changed {
+		ic.RouteDrainPeriod = &routeDrainPeriod
+	}
+
+	if cmd.Flag(fwmarkFlag).Changed {
+		ic.Fwmark = &fwmark
+	}
+
+	if cmd.Flag(routingTableIDFlag).Changed {
+		tableID := int(routingTableID)
+		ic.RoutingTableID = &tableID
+	}
+
+	if cmd.Flag(requireRouteApprovalFlag).Changed {
+		ic.RequireRouteApproval = &requireRouteApproval
+	}
+
+	if cmd.Flag(exitNodeStickyGraceFlag).Changed {
+		ic.ExitNodeStickyGrace = &exitNodeStickyGrace
+	}
+
+	if cmd.Flag(dnsHostsFileFlag).Changed {
+		ic.DNSHostsFile = &dnsHostsFile
+	}
+
+	if cmd.Flag(maxICENegotiationsFlag).Changed {
+		ic.MaxICENegotiations = &maxICENegotiations
+	}
+
+	if cmd.Flag(lanGatewayVIPFlag).Changed {
+		ic.LANGatewayVIP = &lanGatewayVIP
+	}
+
+	if cmd.Flag(lanGatewayInterfaceFlag).Changed {
+		ic.LANGatewayInterface = &lanGatewayInterface
+	}
+
+	if cmd.Flag(lanGatewayPriorityFlag).Changed {
+		ic.LANGatewayPriority = &lanGatewayPriority
+	}
+
+	if cmd.Flag(fileTransferDirFlag).Changed {
+		ic.FileTransferDir = &fileTransferDir
+	}
+
 	providedSetupKey, err := getSetupKey()
 	if err != nil {
 		return err
@@ -199,9 +460,11 @@ func runInForegroundMode(ctx context.Context, cmd *cobra.Command) error {
 
 	config, _ = internal.UpdateOldManagementURL(ctx, config, configPath)
 
-	err = foregroundLogin(ctx, cmd, config, providedSetupKey)
-	if err != nil {
-		return fmt.Errorf("foreground login failed: %v", err)
+	if config.StaticMeshFile == "" {
+		err = foregroundLogin(ctx, cmd, config, providedSetupKey)
+		if err != nil {
+			return fmt.Errorf("foreground login failed: %v", err)
+		}
 	}
 
 	var cancel context.CancelFunc
@@ -264,6 +527,27 @@ func runInDaemonMode(ctx context.Context, cmd *cobra.Command) error {
 		ExtraIFaceBlacklist:  extraIFaceBlackList,
 		DnsLabels:            dnsLabels,
 		CleanDNSLabels:       dnsLabels != nil && len(dnsLabels) == 0,
+		Uplinks:              uplinks,
+		CleanUplinks:         uplinks != nil && len(uplinks) == 0,
+		RouterLans:           routerLANs,
+		CleanRouterLANs:      routerLANs != nil && len(routerLANs) == 0,
+
+		RouteLearningFilters:      routeLearningFilters,
+		CleanRouteLearningFilters: routeLearningFilters != nil && len(routeLearningFilters) == 0,
+
+		SplitTunnelApps:       splitTunnelApps,
+		CleanSplitTunnelApps:  splitTunnelApps != nil && len(splitTunnelApps) == 0,
+		SplitTunnelUsers:      splitTunnelUsers,
+		CleanSplitTunnelUsers: splitTunnelUsers != nil && len(splitTunnelUsers) == 0,
+
+		OverlayOnlyServices:      overlayOnlyServices,
+		CleanOverlayOnlyServices: overlayOnlyServices != nil && len(overlayOnlyServices) == 0,
+
+		RemoteActions:      remoteActions,
+		CleanRemoteActions: remoteActions != nil && len(remoteActions) == 0,
+
+		LanGatewayPeers:      lanGatewayPeers,
+		CleanLanGatewayPeers: lanGatewayPeers != nil && len(lanGatewayPeers) == 0,
 	}
 
 	if rootCmd.PersistentFlags().Changed(preSharedKeyFlag) {
@@ -323,6 +607,72 @@ func runInDaemonMode(ctx context.Context, cmd *cobra.Command) error {
 		loginRequest.BlockLanAccess = &blockLANAccess
 	}
 
+	if cmd.Flag(remoteDebugBundlesFlag).Changed {
+		loginRequest.RemoteDebugBundles = remoteDebugBundles
+	}
+
+	if cmd.Flag(crashReportURLFlag).Changed {
+		loginRequest.CrashReportUrl = &crashReportURL
+	}
+
+	if cmd.Flag(staticMeshFileFlag).Changed {
+		loginRequest.StaticMeshFile = &staticMeshFile
+	}
+
+	if cmd.Flag(routerModeFlag).Changed {
+		loginRequest.RouterMode = &routerMode
+	}
+
+	if cmd.Flag(routeLearningFlag).Changed {
+		loginRequest.RouteLearning = &routeLearning
+	}
+
+	if cmd.Flag(routeDrainPeriodFlag).Changed {
+		loginRequest.RouteDrainPeriod = durationpb.New(routeDrainPeriod)
+	}
+
+	if cmd.Flag(fwmarkFlag).Changed {
+		loginRequest.Fwmark = &fwmark
+	}
+
+	if cmd.Flag(routingTableIDFlag).Changed {
+		loginRequest.RoutingTableId = &routingTableID
+	}
+
+	if cmd.Flag(requireRouteApprovalFlag).Changed {
+		loginRequest.RequireRouteApproval = &requireRouteApproval
+	}
+
+	if cmd.Flag(exitNodeStickyGraceFlag).Changed {
+		loginRequest.ExitNodeStickyGrace = durationpb.New(exitNodeStickyGrace)
+	}
+
+	if cmd.Flag(dnsHostsFileFlag).Changed {
+		loginRequest.DnsHostsFile = &dnsHostsFile
+	}
+
+	if cmd.Flag(maxICENegotiationsFlag).Changed {
+		n := int64(maxICENegotiations)
+		loginRequest.MaxIceNegotiations = &n
+	}
+
+	if cmd.Flag(lanGatewayVIPFlag).Changed {
+		loginRequest.LanGatewayVip = &lanGatewayVIP
+	}
+
+	if cmd.Flag(lanGatewayInterfaceFlag).Changed {
+		loginRequest.LanGatewayInterface = &lanGatewayInterface
+	}
+
+	if cmd.Flag(lanGatewayPriorityFlag).Changed {
+		priority := int64(lanGatewayPriority)
+		loginRequest.LanGatewayPriority = &priority
+	}
+
+	if cmd.Flag(fileTransferDirFlag).Changed {
+		loginRequest.FileTransferDir = &fileTransferDir
+	}
+
 	var loginErr error
 
 	var loginResp *proto.LoginResponse
@@ -0,0 +1,196 @@
+//go:build !android
+
+package firewall
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/google/nftables"
+	log "github.com/sirupsen/logrus"
+
+	firewall "github.com/netbirdio/netbird/client/firewall/manager"
+)
+
+// legacyTablesPath lists the x_tables registered in the kernel, reading it doesn't load the table modules
+const legacyTablesPath = "/proc/net/ip_tables_names"
+
+// managedTables are the iptables tables the firewall managers install rules into
+var managedTables = []string{"filter", "nat", "mangle"}
+
+// resolveBackend returns the firewall type installing the rules into the backend active for the managed tables.
+// Hosts may have both iptables-legacy and iptables-nft tables, e.g. Debian or Proxmox where some software still uses
+// iptables-legacy. The kernel evaluates both backends, so the rules have to go into the one holding the rules of the
+// host. It refuses with a firewall.BackendConflictError if no firewall type installs into the active backend.
+func resolveBackend(fwType FWType) (FWType, error) {
+	if fwType == UNKNOWN {
+		return fwType, nil
+	}
+
+	iptablesBackend, iptablesErr := iptablesBackend()
+
+	fwTypes := make(map[firewall.Backend]FWType)
+	var candidates []firewall.Backend
+	add := func(t FWType, backend firewall.Backend) {
+		if _, ok := fwTypes[backend]; !ok {
+			fwTypes[backend] = t
+			candidates = append(candidates, backend)
+		}
+	}
+
+	switch fwType {
+	case IPTABLES:
+		if iptablesErr != nil {
+			return UNKNOWN, fmt.Errorf("get iptables backend: %w", iptablesErr)
+		}
+		add(IPTABLES, iptablesBackend)
+		if isNftablesAvailable() {
+			add(NFTABLES, firewall.BackendNft)
+		}
+	case NFTABLES:
+		add(NFTABLES, firewall.BackendNft)
+		if iptablesErr == nil {
+			add(IPTABLES, iptablesBackend)
+		}
+	}
+
+	usage := make(map[string]firewall.TableUsage, len(managedTables))
+	for _, table := range managedTables {
+		usage[table] = firewall.TableUsage{}
+	}
+	if err := addLegacyUsage(usage, iptablesBackend); err != nil {
+		log.Warnf("failed to read the iptables-legacy tables: %v", err)
+	}
+	if err := addNftUsage(usage); err != nil {
+		log.Warnf("failed to read the iptables-nft tables: %v", err)
+	}
+
+	backend, err := firewall.ResolveBackend(usage, candidates)
+	if err != nil {
+		return UNKNOWN, err
+	}
+
+	if backend != candidates[0] {
+		log.Infof("the rules of the host are in %s, installing the firewall rules into it instead of %s",
+			backend, candidates[0])
+	}
+	return fwTypes[backend], nil
+}
+
+// iptablesBackend returns the backend the iptables command installs the rules into
+func iptablesBackend() (firewall.Backend, error) {
+	out, err := exec.Command("iptables", "--version").Output()
+	if err != nil {
+		return "", fmt.Errorf("get iptables version: %w", err)
+	}
+	// e.g. "iptables v1.8.9 (nf_tables)", older versions don't print the mode and only support legacy
+	if strings.Contains(string(out), "(nf_tables)") {
+		return firewall.BackendNft, nil
+	}
+	return firewall.BackendLegacy, nil
+}
+
+func isNftablesAvailable() bool {
+	if os.Getenv(SKIP_NFTABLES_ENV) == "true" {
+		return false
+	}
+	nf := nftables.Conn{}
+	_, err := nf.ListChains()
+	return err == nil
+}
+
+// addLegacyUsage counts the rules of the managed tables registered in the kernel x_tables
+func addLegacyUsage(usage map[string]firewall.TableUsage, iptablesBackend firewall.Backend) error {
+	registered, err := os.ReadFile(legacyTablesPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read %s: %w", legacyTablesPath, err)
+	}
+
+	saveCmd := "iptables-legacy-save"
+	if _, err := exec.LookPath(saveCmd); err != nil {
+		if iptablesBackend != firewall.BackendLegacy {
+			log.Debugf("%s not found, skipping the legacy tables %q", saveCmd, strings.Fields(string(registered)))
+			return nil
+		}
+		saveCmd = "iptables-save"
+	}
+
+	for _, table := range strings.Fields(string(registered)) {
+		if !slices.Contains(managedTables, table) {
+			continue
+		}
+
+		out, err := exec.Command(saveCmd, "-t", table).Output()
+		if err != nil {
+			return fmt.Errorf("%s -t %s: %w", saveCmd, table, err)
+		}
+
+		u := usage[table]
+		u.Legacy = countSavedRules(string(out))
+		usage[table] = u
+	}
+
+	return nil
+}
+
+// countSavedRules counts the rules and the non-accept chain policies of an iptables-save output
+func countSavedRules(out string) int {
+	var rules int
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "-A "):
+			rules++
+		case strings.HasPrefix(line, ":"):
+			// the chain lines are ":<chain> <policy> [<packets>:<bytes>]", the policy of user chains is "-"
+			fields := strings.Fields(line)
+			if len(fields) > 1 && fields[1] != "ACCEPT" && fields[1] != "-" {
+				rules++
+			}
+		}
+	}
+	return rules
+}
+
+// addNftUsage counts the rules and the drop policies of the chains of the ip family tables named after the managed
+// tables, the ones iptables-nft uses
+func addNftUsage(usage map[string]firewall.TableUsage) error {
+	if os.Getenv(SKIP_NFTABLES_ENV) == "true" {
+		return nil
+	}
+
+	nf := &nftables.Conn{}
+	chains, err := nf.ListChains()
+	if err != nil {
+		return fmt.Errorf("list chains: %w", err)
+	}
+
+	for _, chain := range chains {
+		if chain.Table.Family != nftables.TableFamilyIPv4 || !slices.Contains(managedTables, chain.Table.Name) {
+			continue
+		}
+
+		rules, err := nf.GetRules(chain.Table, chain)
+		if err != nil {
+			return fmt.Errorf("get rules of chain %s/%s: %w", chain.Table.Name, chain.Name, err)
+		}
+
+		u := usage[chain.Table.Name]
+		u.Nft += len(rules)
+		if chain.Policy != nil && *chain.Policy == nftables.ChainPolicyDrop {
+			u.Nft++
+		}
+		usage[chain.Table.Name] = u
+	}
+
+	return nil
+}
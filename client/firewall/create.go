@@ -15,7 +15,7 @@ import (
 )
 
 // NewFirewall creates a firewall manager instance
-func NewFirewall(iface IFaceMapper, _ *statemanager.Manager, flowLogger nftypes.FlowLogger, disableServerRoutes bool) (firewall.Manager, error) {
+func NewFirewall(iface IFaceMapper, stateManager *statemanager.Manager, flowLogger nftypes.FlowLogger, disableServerRoutes bool) (firewall.Manager, error) {
 	if !iface.IsUserspaceBind() {
 		return nil, fmt.Errorf("not implemented for this OS: %s", runtime.GOOS)
 	}
@@ -25,6 +25,9 @@ func NewFirewall(iface IFaceMapper, _ *statemanager.Manager, flowLogger nftypes.
 	if err != nil {
 		return nil, err
 	}
+	if err := fm.Init(stateManager); err != nil {
+		log.Warnf("failed to init userspace firewall: %v", err)
+	}
 	err = fm.AllowNetbird()
 	if err != nil {
 		log.Warnf("failed to allow netbird interface traffic: %v", err)
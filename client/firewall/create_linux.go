@@ -16,6 +16,7 @@ import (
 	nbnftables "github.com/netbirdio/netbird/client/firewall/nftables"
 	"github.com/netbirdio/netbird/client/firewall/uspfilter"
 	nftypes "github.com/netbirdio/netbird/client/internal/netflow/types"
+	"github.com/netbirdio/netbird/client/internal/profiles"
 	"github.com/netbirdio/netbird/client/internal/statemanager"
 )
 
@@ -54,7 +55,7 @@ func NewFirewall(iface IFaceMapper, stateManager *statemanager.Manager, flowLogg
 func createNativeFirewall(iface IFaceMapper, stateManager *statemanager.Manager, routes bool) (firewall.Manager, error) {
 	fm, err := createFW(iface)
 	if err != nil {
-		return nil, fmt.Errorf("create firewall: %s", err)
+		return nil, fmt.Errorf("create firewall: %w", err)
 	}
 
 	if err = fm.Init(stateManager); err != nil {
@@ -65,8 +66,17 @@ func createNativeFirewall(iface IFaceMapper, stateManager *statemanager.Manager,
 }
 
 func createFW(iface IFaceMapper) (firewall.Manager, error) {
-	switch check() {
+	fwType, err := resolveBackend(check())
+	if err != nil {
+		return nil, fmt.Errorf("resolve firewall backend: %w", err)
+	}
+
+	switch fwType {
 	case IPTABLES:
+		// the chains of the iptables manager are shared by all daemons, the nftables manager uses a table per profile
+		if profile := profiles.Current(); profile != "" {
+			return nil, fmt.Errorf("the iptables firewall manages the rules of the default profile only, profile %s needs nftables", profile)
+		}
 		log.Info("creating an iptables firewall manager")
 		return nbiptables.Create(iface)
 	case NFTABLES:
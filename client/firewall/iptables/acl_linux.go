@@ -4,12 +4,15 @@ import (
 	"fmt"
 	"net"
 	"slices"
+	"strings"
 
 	"github.com/coreos/go-iptables/iptables"
 	"github.com/google/uuid"
+	"github.com/hashicorp/go-multierror"
 	"github.com/nadoo/ipset"
 	log "github.com/sirupsen/logrus"
 
+	nberrors "github.com/netbirdio/netbird/client/errors"
 	firewall "github.com/netbirdio/netbird/client/firewall/manager"
 	"github.com/netbirdio/netbird/client/internal/statemanager"
 	nbnet "github.com/netbirdio/netbird/util/net"
@@ -35,6 +38,10 @@ type aclManager struct {
 	entries         aclEntries
 	optionalEntries map[string][]entry
 	ipsetStore      *ipsetStore
+	// peerRules are the peer rules added to the chains by their specs, the rules of an ipset are added once
+	peerRules map[string]*Rule
+	// overlayOnlyEntries are the INPUT rules dropping the traffic to the overlay-only services
+	overlayOnlyEntries [][]string
 
 	stateManager *statemanager.Manager
 }
@@ -46,6 +53,7 @@ func newAclManager(iptablesClient *iptables.IPTables, wgIface iFaceMapper) (*acl
 		entries:         make(map[string][][]string),
 		optionalEntries: make(map[string][]entry),
 		ipsetStore:      newIpsetStore(),
+		peerRules:       make(map[string]*Rule),
 	}
 
 	if err := ipset.Init(); err != nil {
@@ -152,6 +160,7 @@ func (m *aclManager) AddPeerFiltering(
 		ip:          ip.String(),
 		chain:       chain,
 	}
+	m.peerRules[strings.Join(specs, " ")] = rule
 
 	m.updateState()
 
@@ -192,6 +201,7 @@ func (m *aclManager) DeletePeerRule(rule firewall.Rule) error {
 	if err := m.iptablesClient.Delete(tableName, r.chain, r.specs...); err != nil {
 		return fmt.Errorf("failed to delete rule: %s, %v: %w", r.chain, r.specs, err)
 	}
+	delete(m.peerRules, strings.Join(r.specs, " "))
 
 	if r.mangleSpecs != nil {
 		if err := m.iptablesClient.Delete(tableMangle, chainRTPRE, r.mangleSpecs...); err != nil {
@@ -204,6 +214,38 @@ func (m *aclManager) DeletePeerRule(rule firewall.Rule) error {
 	return nil
 }
 
+// SetOverlayOnlyServices drops the traffic to the services received on other interfaces than the NetBird interface,
+// the locally originated traffic is allowed
+func (m *aclManager) SetOverlayOnlyServices(services []firewall.InboundService) error {
+	var merr *multierror.Error
+	for _, spec := range m.overlayOnlyEntries {
+		if err := m.iptablesClient.DeleteIfExists(tableName, "INPUT", spec...); err != nil {
+			merr = multierror.Append(merr, fmt.Errorf("delete overlay-only rule %v: %w", spec, err))
+		}
+		m.entries["INPUT"] = slices.DeleteFunc(m.entries["INPUT"], func(entry []string) bool {
+			return slices.Equal(entry, spec)
+		})
+	}
+	m.overlayOnlyEntries = nil
+
+	for _, service := range services {
+		spec := []string{"!", "-i", m.wgIface.Name(), "-p", string(service.Protocol)}
+		spec = append(spec, applyPort("--dport", &service.Port)...)
+		spec = append(spec, "-m", "addrtype", "!", "--src-type", "LOCAL", "-j", "DROP")
+
+		if err := m.iptablesClient.InsertUnique(tableName, "INPUT", 1, spec...); err != nil {
+			merr = multierror.Append(merr, fmt.Errorf("add overlay-only rule for %s: %w", service, err))
+			continue
+		}
+		m.overlayOnlyEntries = append(m.overlayOnlyEntries, spec)
+		m.appendToEntries("INPUT", spec)
+	}
+
+	m.updateState()
+
+	return nberrors.FormatErrorOrNil(merr)
+}
+
 func (m *aclManager) Reset() error {
 	if err := m.cleanChains(); err != nil {
 		return fmt.Errorf("clean chains: %w", err)
@@ -265,6 +307,8 @@ func (m *aclManager) cleanChains() error {
 		}
 		m.ipsetStore.deleteIpset(ipsetName)
 	}
+	clear(m.peerRules)
+	m.overlayOnlyEntries = nil
 
 	return nil
 }
@@ -0,0 +1,238 @@
+package iptables
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	firewall "github.com/netbirdio/netbird/client/firewall/manager"
+)
+
+// chainRef identifies a chain of a table
+type chainRef struct {
+	table string
+	chain string
+}
+
+// netbirdChains are the chains owned by the manager, all their rules are expected to be applied by it
+var netbirdChains = []chainRef{
+	{tableFilter, chainNameInputRules},
+	{tableFilter, chainRTFWDIN},
+	{tableFilter, chainRTFWDOUT},
+	{tableMangle, chainRTPRE},
+	{tableNat, chainRTNAT},
+	{tableNat, chainRTRDR},
+}
+
+// DumpRules returns the rules the manager applied and the rules present in iptables. The chains of other software,
+// e.g. INPUT and FORWARD, are listed with the rules that refer to the netbird interface or chains only.
+func (m *Manager) DumpRules() (*firewall.RulesDump, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	applied := m.aclMgr.appliedRules()
+	for ref, specs := range m.router.appliedRules() {
+		applied[ref] = append(applied[ref], specs...)
+	}
+	for _, ref := range netbirdChains {
+		if _, ok := applied[ref]; !ok {
+			applied[ref] = nil
+		}
+	}
+
+	dump := &firewall.RulesDump{Backend: "iptables"}
+	for ref, specs := range applied {
+		set, err := m.dumpChain(ref, specs)
+		if err != nil {
+			return nil, err
+		}
+		dump.RuleSets = append(dump.RuleSets, set)
+	}
+
+	sort.Slice(dump.RuleSets, func(i, j int) bool {
+		if dump.RuleSets[i].Table != dump.RuleSets[j].Table {
+			return dump.RuleSets[i].Table < dump.RuleSets[j].Table
+		}
+		return dump.RuleSets[i].Chain < dump.RuleSets[j].Chain
+	})
+
+	return dump, nil
+}
+
+func (m *Manager) dumpChain(ref chainRef, specs [][]string) (*firewall.RuleSet, error) {
+	set := &firewall.RuleSet{Table: ref.table, Chain: ref.chain}
+	for _, spec := range specs {
+		set.Applied = append(set.Applied, strings.Join(spec, " "))
+	}
+	sort.Strings(set.Applied)
+
+	exists, err := m.ipv4Client.ChainExists(ref.table, ref.chain)
+	if err != nil {
+		return nil, fmt.Errorf("check chain %s in table %s: %w", ref.chain, ref.table, err)
+	}
+	if !exists {
+		set.Missing = set.Applied
+		return set, nil
+	}
+
+	listed, err := m.ipv4Client.List(ref.table, ref.chain)
+	if err != nil {
+		return nil, fmt.Errorf("list rules of chain %s in table %s: %w", ref.chain, ref.table, err)
+	}
+
+	owned := slices.Contains(netbirdChains, ref)
+	for _, line := range listed {
+		// the rules are listed as "-A <chain> <spec>", the chain itself as "-N <chain>" or "-P <chain> <policy>"
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != "-A" {
+			continue
+		}
+		spec := fields[2:]
+		if !owned && !m.refersToNetbird(spec) {
+			continue
+		}
+		set.Present = append(set.Present, strings.Join(spec, " "))
+	}
+
+	set.DiffRules(func(applied, present string) bool {
+		return specMatches(strings.Fields(applied), strings.Fields(present))
+	})
+
+	return set, nil
+}
+
+// refersToNetbird returns true if the rule matches the netbird interface or jumps to a netbird chain
+func (m *Manager) refersToNetbird(spec []string) bool {
+	for _, field := range spec {
+		if field == m.wgIface.Name() || strings.HasPrefix(field, "NETBIRD-") {
+			return true
+		}
+	}
+	return false
+}
+
+// specMatches returns true if the rule listed by iptables is the rule of the spec. iptables lists the options of a rule
+// in its own order, adds the implicit match modules and the default masks, so the options are compared as sets.
+func specMatches(spec, listed []string) bool {
+	return slices.Equal(normalizeSpec(spec), normalizeSpec(listed))
+}
+
+// specOption is an option of a rule spec with its arguments
+type specOption struct {
+	negate bool
+	name   string
+	args   []string
+}
+
+// normalizeSpec returns the sorted options of a rule spec with their arguments. The match modules are left out as
+// they are implied by the options that follow them.
+func normalizeSpec(spec []string) []string {
+	var options []*specOption
+	negate := false
+	for _, field := range spec {
+		switch {
+		case field == "!":
+			negate = true
+		case strings.HasPrefix(field, "-"):
+			options = append(options, &specOption{negate: negate, name: field})
+			negate = false
+		case len(options) > 0:
+			last := options[len(options)-1]
+			last.args = append(last.args, field)
+		}
+	}
+
+	normalized := make([]string, 0, len(options))
+	for _, option := range options {
+		switch option.name {
+		case "-m":
+			continue
+		case "-p":
+			if len(option.args) == 1 && option.args[0] == "all" {
+				continue
+			}
+		case "--set":
+			option.name = "--match-set"
+		case "-s", "-d":
+			if len(option.args) == 1 && !strings.Contains(option.args[0], "/") {
+				option.args = []string{option.args[0] + "/32"}
+			}
+		case "--set-mark", "--set-xmark":
+			option.name = "--set-xmark"
+			if len(option.args) == 1 && !strings.Contains(option.args[0], "/") {
+				option.args = []string{option.args[0] + "/0xffffffff"}
+			}
+		}
+
+		fields := append([]string{option.name}, option.args...)
+		if option.negate {
+			fields = append([]string{"!"}, fields...)
+		}
+		normalized = append(normalized, strings.Join(fields, " "))
+	}
+
+	sort.Strings(normalized)
+	return normalized
+}
+
+// appliedRules returns the specs of the rules the acl manager added by their chain
+func (m *aclManager) appliedRules() map[chainRef][][]string {
+	applied := make(map[chainRef][][]string)
+	for chain, specs := range m.entries {
+		table := tableName
+		if chain == chainPREROUTING {
+			table = tableMangle
+		}
+		ref := chainRef{table, chain}
+		applied[ref] = append(applied[ref], specs...)
+	}
+
+	for _, rule := range m.peerRules {
+		ref := chainRef{tableName, rule.chain}
+		applied[ref] = append(applied[ref], rule.specs)
+		if rule.mangleSpecs != nil {
+			ref = chainRef{tableMangle, chainRTPRE}
+			applied[ref] = append(applied[ref], rule.mangleSpecs)
+		}
+	}
+
+	return applied
+}
+
+// appliedRules returns the specs of the rules the router added by their chain
+func (r *router) appliedRules() map[chainRef][][]string {
+	applied := make(map[chainRef][][]string)
+	for key, spec := range r.rules {
+		ref := routeRuleChain(key)
+		applied[ref] = append(applied[ref], spec)
+	}
+	return applied
+}
+
+// routeRuleChain returns the chain the router adds the rule of the key to
+func routeRuleChain(key string) chainRef {
+	switch {
+	case key == jumpNatPost:
+		return chainRef{tableNat, chainPOSTROUTING}
+	case key == jumpManglePre:
+		return chainRef{tableMangle, chainPREROUTING}
+	case key == jumpNatPre:
+		return chainRef{tableNat, chainPREROUTING}
+	case strings.HasPrefix(key, "static-nat-"):
+		return chainRef{tableNat, chainRTNAT}
+	case strings.HasPrefix(key, "established-"):
+		return chainRef{tableFilter, strings.TrimPrefix(key, "established-")}
+	case strings.HasPrefix(key, firewall.NatFormatPrefix):
+		return chainRef{tableMangle, chainRTPRE}
+	case strings.HasSuffix(key, dnatSuffix):
+		return chainRef{tableNat, chainRTRDR}
+	case strings.HasSuffix(key, snatSuffix):
+		return chainRef{tableNat, chainRTNAT}
+	case strings.HasSuffix(key, fwdSuffix):
+		return chainRef{tableFilter, chainRTFWDOUT}
+	default:
+		// route filtering and legacy forwarding rules
+		return chainRef{tableFilter, chainRTFWDIN}
+	}
+}
@@ -0,0 +1,60 @@
+package iptables
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpecMatches(t *testing.T) {
+	tests := []struct {
+		name   string
+		spec   string
+		listed string
+		match  bool
+	}{
+		{
+			name:   "peer rule",
+			spec:   "-s 100.64.0.5 -p tcp --dport 22 -j ACCEPT",
+			listed: "-s 100.64.0.5/32 -p tcp -m tcp --dport 22 -j ACCEPT",
+			match:  true,
+		},
+		{
+			name:   "ipset rule",
+			spec:   "-m set --set nb0000001-dport src -p udp --dport 53 -j DROP",
+			listed: "-p udp -m set --match-set nb0000001-dport src -m udp --dport 53 -j DROP",
+			match:  true,
+		},
+		{
+			name:   "mangle rule",
+			spec:   "-s 100.64.0.5 -i wt0 -m addrtype --dst-type LOCAL -j MARK --set-xmark 0x1bd20",
+			listed: "-s 100.64.0.5/32 -i wt0 -m addrtype --dst-type LOCAL -j MARK --set-xmark 0x1bd20/0xffffffff",
+			match:  true,
+		},
+		{
+			name:   "nat marking rule",
+			spec:   "! -i wt0 -m conntrack --ctstate NEW -s 10.0.0.0/8 -d 100.64.0.0/10 -j MARK --set-mark 0x1bd11",
+			listed: "-s 10.0.0.0/8 -d 100.64.0.0/10 ! -i wt0 -m conntrack --ctstate NEW -j MARK --set-xmark 0x1bd11/0xffffffff",
+			match:  true,
+		},
+		{
+			name:   "different port",
+			spec:   "-s 100.64.0.5 -p tcp --dport 22 -j ACCEPT",
+			listed: "-s 100.64.0.5/32 -p tcp -m tcp --dport 2222 -j ACCEPT",
+			match:  false,
+		},
+		{
+			name:   "negation",
+			spec:   "-i wt0 -j DROP",
+			listed: "! -i wt0 -j DROP",
+			match:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.match, specMatches(strings.Fields(tt.spec), strings.Fields(tt.listed)))
+		})
+	}
+}
@@ -211,6 +211,14 @@ func (m *Manager) AllowNetbird() error {
 	return nil
 }
 
+// SetOverlayOnlyServices restricts the services to the NetBird interface
+func (m *Manager) SetOverlayOnlyServices(services []firewall.InboundService) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.aclMgr.SetOverlayOnlyServices(services)
+}
+
 // Flush doesn't need to be implemented for this manager
 func (m *Manager) Flush() error { return nil }
 
@@ -0,0 +1,108 @@
+package manager
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/netbirdio/netbird/util/errcode"
+)
+
+// Backend is the kernel backend the iptables rules of a table are installed into
+type Backend string
+
+const (
+	// BackendLegacy are the x_tables of the kernel, managed by iptables-legacy
+	BackendLegacy Backend = "iptables-legacy"
+	// BackendNft are the nftables tables named after the iptables ones, managed by iptables-nft and nftables
+	BackendNft Backend = "iptables-nft"
+)
+
+// TableUsage is the number of the rules of a table in both backends. A chain policy other than accept counts as a
+// rule since it filters the traffic as well.
+type TableUsage struct {
+	Legacy int
+	Nft    int
+}
+
+// Active returns the backend holding the rules of the table. It returns false if the rules are split across both
+// backends, and an empty backend if the table isn't in use.
+func (u TableUsage) Active() (Backend, bool) {
+	switch {
+	case u.Legacy > 0 && u.Nft > 0:
+		return "", false
+	case u.Legacy > 0:
+		return BackendLegacy, true
+	case u.Nft > 0:
+		return BackendNft, true
+	default:
+		return "", true
+	}
+}
+
+// BackendConflictError is returned when the rules of a table live in another backend than the one the firewall
+// manager would install its rules into. The kernel evaluates both backends, rules installed into the inactive one
+// don't override the ones of the active backend.
+type BackendConflictError struct {
+	Table string
+	// Active is the backend holding the rules of the table, it is empty if both backends hold rules
+	Active Backend
+	// Backend is the backend the firewall manager would install the rules into
+	Backend Backend
+}
+
+func (e *BackendConflictError) Error() string {
+	if e.Active == "" {
+		return fmt.Sprintf("the %s table has rules in both %s and %s, remove the rules of the unused backend",
+			e.Table, BackendLegacy, BackendNft)
+	}
+	return fmt.Sprintf("the %s table is managed by %s, refusing to install the rules into the inactive %s backend",
+		e.Table, e.Active, e.Backend)
+}
+
+// ErrorCode returns the code the conflict is reported with
+func (e *BackendConflictError) ErrorCode() errcode.Code {
+	return errcode.FirewallBackendConflict
+}
+
+// ResolveBackend returns the first of the candidate backends, in the order of preference, that holds the rules of
+// every table in use. The tables not in use don't constrain the choice.
+func ResolveBackend(usage map[string]TableUsage, candidates []Backend) (Backend, error) {
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("no firewall backend available")
+	}
+
+	tables := make([]string, 0, len(usage))
+	for table := range usage {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	active := make(map[string]Backend, len(tables))
+	for _, table := range tables {
+		backend, ok := usage[table].Active()
+		if !ok {
+			return "", &BackendConflictError{Table: table, Backend: candidates[0]}
+		}
+		if backend != "" {
+			active[table] = backend
+		}
+	}
+
+	conflictOf := func(candidate Backend) *BackendConflictError {
+		for _, table := range tables {
+			if backend, ok := active[table]; ok && backend != candidate {
+				return &BackendConflictError{Table: table, Active: backend, Backend: candidate}
+			}
+		}
+		return nil
+	}
+
+	for _, candidate := range candidates {
+		if conflictOf(candidate) == nil {
+			return candidate, nil
+		}
+	}
+
+	// report the conflict of the preferred backend
+	return "", conflictOf(candidates[0])
+}
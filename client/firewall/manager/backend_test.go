@@ -0,0 +1,74 @@
+package manager_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/client/firewall/manager"
+)
+
+func TestResolveBackend(t *testing.T) {
+	both := []manager.Backend{manager.BackendNft, manager.BackendLegacy}
+
+	tests := []struct {
+		name       string
+		usage      map[string]manager.TableUsage
+		candidates []manager.Backend
+		expected   manager.Backend
+		conflict   *manager.BackendConflictError
+	}{
+		{
+			name:       "no tables in use keeps the preferred backend",
+			usage:      map[string]manager.TableUsage{"filter": {}, "nat": {}},
+			candidates: both,
+			expected:   manager.BackendNft,
+		},
+		{
+			name:       "legacy tables in use select the legacy backend",
+			usage:      map[string]manager.TableUsage{"filter": {Legacy: 12}, "nat": {}},
+			candidates: both,
+			expected:   manager.BackendLegacy,
+		},
+		{
+			name:       "empty legacy tables don't constrain the choice",
+			usage:      map[string]manager.TableUsage{"filter": {Nft: 3}, "mangle": {}},
+			candidates: both,
+			expected:   manager.BackendNft,
+		},
+		{
+			name:       "the active backend isn't available",
+			usage:      map[string]manager.TableUsage{"filter": {Legacy: 4}},
+			candidates: []manager.Backend{manager.BackendNft},
+			conflict:   &manager.BackendConflictError{Table: "filter", Active: manager.BackendLegacy, Backend: manager.BackendNft},
+		},
+		{
+			name:       "tables split across the backends",
+			usage:      map[string]manager.TableUsage{"filter": {Legacy: 4}, "nat": {Nft: 2}},
+			candidates: both,
+			conflict:   &manager.BackendConflictError{Table: "filter", Active: manager.BackendLegacy, Backend: manager.BackendNft},
+		},
+		{
+			name:       "a table with rules in both backends",
+			usage:      map[string]manager.TableUsage{"filter": {Legacy: 1, Nft: 1}},
+			candidates: both,
+			conflict:   &manager.BackendConflictError{Table: "filter", Backend: manager.BackendNft},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend, err := manager.ResolveBackend(tt.usage, tt.candidates)
+			if tt.conflict == nil {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, backend)
+				return
+			}
+
+			var conflict *manager.BackendConflictError
+			require.ErrorAs(t, err, &conflict)
+			assert.Equal(t, tt.conflict, conflict)
+		})
+	}
+}
@@ -16,6 +16,7 @@ import (
 
 const (
 	ForwardingFormatPrefix = "netbird-fwd-"
+	NatFormatPrefix        = "netbird-nat-"
 	ForwardingFormat       = "netbird-fwd-%s-%t"
 	PreroutingFormat       = "netbird-prerouting-%s-%t"
 	NatFormat              = "netbird-nat-%s-%t"
@@ -119,6 +120,9 @@ type Manager interface {
 
 	// DeleteDNATRule deletes a DNAT rule
 	DeleteDNATRule(Rule) error
+
+	// DumpRules returns the rules the manager applied and the rules present in the firewall
+	DumpRules() (*RulesDump, error)
 }
 
 func GenKey(format string, pair RouterPair) string {
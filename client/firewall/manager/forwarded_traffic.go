@@ -0,0 +1,19 @@
+package manager
+
+import "net/netip"
+
+// ForwardedTraffic counts the bytes a routing peer forwarded for a peer
+type ForwardedTraffic struct {
+	// RxBytes were sent to the peer
+	RxBytes uint64
+	// TxBytes were sent by the peer
+	TxBytes uint64
+}
+
+// TrafficAccountingManager is implemented by the firewall managers counting the routed traffic per peer. It lets the
+// routing peers, e.g. the exit nodes, report the traffic they forwarded for each peer.
+type TrafficAccountingManager interface {
+	// ForwardedTraffic returns the bytes forwarded for the peers by their NetBird IP address since the firewall was
+	// initialized
+	ForwardedTraffic() (map[netip.Addr]ForwardedTraffic, error)
+}
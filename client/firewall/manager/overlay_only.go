@@ -0,0 +1,76 @@
+package manager
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// InboundService is a local service restricted to the NetBird interface in the overlay-only mode
+type InboundService struct {
+	Protocol Protocol
+	Port     Port
+}
+
+// ParseInboundService parses a service in the protocol/port or the protocol/start-end format, e.g. tcp/22 or
+// udp/60000-61000
+func ParseInboundService(s string) (InboundService, error) {
+	proto, ports, found := strings.Cut(strings.ToLower(strings.TrimSpace(s)), "/")
+	if !found {
+		return InboundService{}, fmt.Errorf("invalid service %q, expected protocol/port", s)
+	}
+
+	protocol := Protocol(proto)
+	if protocol != ProtocolTCP && protocol != ProtocolUDP {
+		return InboundService{}, fmt.Errorf("invalid protocol %q of service %q, expected tcp or udp", proto, s)
+	}
+
+	var values []int
+	for _, p := range strings.SplitN(ports, "-", 2) {
+		port, err := strconv.Atoi(p)
+		if err != nil {
+			return InboundService{}, fmt.Errorf("invalid port %q of service %q", p, s)
+		}
+		values = append(values, port)
+	}
+	if len(values) == 2 && values[1] < values[0] {
+		return InboundService{}, fmt.Errorf("invalid port range of service %q, the end is smaller than the start", s)
+	}
+
+	port, err := NewPort(values...)
+	if err != nil {
+		return InboundService{}, fmt.Errorf("service %q: %w", s, err)
+	}
+
+	return InboundService{Protocol: protocol, Port: *port}, nil
+}
+
+// ParseInboundServices parses the services of the overlay-only mode
+func ParseInboundServices(services []string) ([]InboundService, error) {
+	parsed := make([]InboundService, 0, len(services))
+	for _, s := range services {
+		service, err := ParseInboundService(s)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, service)
+	}
+	return parsed, nil
+}
+
+// String returns the service in the protocol/port format
+func (s InboundService) String() string {
+	if s.Port.IsRange && len(s.Port.Values) == 2 {
+		return fmt.Sprintf("%s/%d-%d", s.Protocol, s.Port.Values[0], s.Port.Values[1])
+	}
+	return fmt.Sprintf("%s/%s", s.Protocol, s.Port.String())
+}
+
+// OverlayOnlyManager is implemented by the firewall managers able to restrict the local services to the NetBird
+// interface. It hardens hosts exposing e.g. SSH or RDP solely through NetBird.
+type OverlayOnlyManager interface {
+	// SetOverlayOnlyServices drops the inbound IPv4 traffic to the services received on the physical interfaces,
+	// the services are reachable only over the NetBird interface and locally. It replaces the services set before,
+	// no services removes the restriction.
+	SetOverlayOnlyServices(services []InboundService) error
+}
@@ -0,0 +1,53 @@
+package manager_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/client/firewall/manager"
+)
+
+func TestParseInboundService(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected manager.InboundService
+		wantErr  bool
+	}{
+		{
+			input:    "tcp/22",
+			expected: manager.InboundService{Protocol: manager.ProtocolTCP, Port: manager.Port{Values: []uint16{22}}},
+		},
+		{
+			input:    " UDP/60000-61000 ",
+			expected: manager.InboundService{Protocol: manager.ProtocolUDP, Port: manager.Port{IsRange: true, Values: []uint16{60000, 61000}}},
+		},
+		{input: "22", wantErr: true},
+		{input: "icmp/22", wantErr: true},
+		{input: "tcp/ssh", wantErr: true},
+		{input: "tcp/0", wantErr: true},
+		{input: "tcp/70000", wantErr: true},
+		{input: "tcp/3390-3389", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			service, err := manager.ParseInboundService(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, service)
+		})
+	}
+}
+
+func TestInboundService_String(t *testing.T) {
+	for _, s := range []string{"tcp/22", "udp/60000-61000"} {
+		service, err := manager.ParseInboundService(s)
+		require.NoError(t, err)
+		assert.Equal(t, s, service.String())
+	}
+}
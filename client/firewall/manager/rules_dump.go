@@ -0,0 +1,58 @@
+package manager
+
+// RuleSet are the rules of a firewall chain, the rules the manager applied and the rules read back from the firewall
+type RuleSet struct {
+	// Table of the chain, it is empty for the userspace filter
+	Table string
+	Chain string
+	// Applied are the rules the manager believes it applied to the chain
+	Applied []string
+	// Present are the rules found in the chain of the firewall
+	Present []string
+	// Missing are the applied rules that aren't present in the firewall
+	Missing []string
+	// Unexpected are the rules present in the chain that the manager didn't apply
+	Unexpected []string
+}
+
+// HasDrift returns true if the firewall doesn't match the rules the manager applied
+func (s *RuleSet) HasDrift() bool {
+	return len(s.Missing) > 0 || len(s.Unexpected) > 0
+}
+
+// RulesDump are the rules of a firewall backend
+type RulesDump struct {
+	// Backend is the firewall the rules are applied to, e.g. iptables, nftables or userspace
+	Backend  string
+	RuleSets []*RuleSet
+	// Native is the dump of the native firewall the userspace filter leaves the routing to, if any
+	Native *RulesDump
+}
+
+// DiffRules sets the missing and unexpected rules of the set. Every applied rule is matched to one present rule,
+// match returns true if the present rule is the firewall representation of the applied one.
+func (s *RuleSet) DiffRules(match func(applied, present string) bool) {
+	matched := make([]bool, len(s.Present))
+
+	s.Missing = nil
+	for _, applied := range s.Applied {
+		found := false
+		for i, present := range s.Present {
+			if !matched[i] && match(applied, present) {
+				matched[i] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			s.Missing = append(s.Missing, applied)
+		}
+	}
+
+	s.Unexpected = nil
+	for i, present := range s.Present {
+		if !matched[i] {
+			s.Unexpected = append(s.Unexpected, present)
+		}
+	}
+}
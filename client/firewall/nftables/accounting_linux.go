@@ -0,0 +1,128 @@
+package nftables
+
+import (
+	"fmt"
+	"net/netip"
+
+	"github.com/google/nftables"
+	"github.com/google/nftables/expr"
+	"golang.org/x/sys/unix"
+
+	firewall "github.com/netbirdio/netbird/client/firewall/manager"
+)
+
+const (
+	chainNameRoutingAcct = "netbird-rt-acct"
+
+	// setNameForwardedRx counts the routed traffic per destination peer, setNameForwardedTx per source peer
+	setNameForwardedRx = "nb-fwd-rx"
+	setNameForwardedTx = "nb-fwd-tx"
+
+	// ipv4SrcOffset and ipv4DstOffset are the offsets of the addresses in the IPv4 header
+	ipv4SrcOffset = 12
+	ipv4DstOffset = 16
+)
+
+// createAccounting counts the routed traffic of the peers. The chain runs after the filter chains so the dropped
+// packets aren't counted, each peer address gets an element with a counter in the sets.
+func (r *router) createAccounting() error {
+	r.forwardedRx = &nftables.Set{
+		Name:    setNameForwardedRx,
+		Table:   r.workTable,
+		KeyType: nftables.TypeIPAddr,
+		Dynamic: true,
+		Counter: true,
+	}
+	r.forwardedTx = &nftables.Set{
+		Name:    setNameForwardedTx,
+		Table:   r.workTable,
+		KeyType: nftables.TypeIPAddr,
+		Dynamic: true,
+		Counter: true,
+	}
+	for _, set := range []*nftables.Set{r.forwardedRx, r.forwardedTx} {
+		if err := r.conn.AddSet(set, nil); err != nil {
+			return fmt.Errorf("create set %s: %w", set.Name, err)
+		}
+	}
+
+	prio := *nftables.ChainPriorityFilter + 1
+	polAccept := nftables.ChainPolicyAccept
+	r.chains[chainNameRoutingAcct] = r.conn.AddChain(&nftables.Chain{
+		Name:     chainNameRoutingAcct,
+		Table:    r.workTable,
+		Hooknum:  nftables.ChainHookForward,
+		Priority: &prio,
+		Type:     nftables.ChainTypeFilter,
+		Policy:   &polAccept,
+	})
+
+	r.conn.AddRule(&nftables.Rule{
+		Table: r.workTable,
+		Chain: r.chains[chainNameRoutingAcct],
+		Exprs: r.accountingExprs(expr.MetaKeyIIFNAME, ipv4SrcOffset, r.forwardedTx),
+	})
+	r.conn.AddRule(&nftables.Rule{
+		Table: r.workTable,
+		Chain: r.chains[chainNameRoutingAcct],
+		Exprs: r.accountingExprs(expr.MetaKeyOIFNAME, ipv4DstOffset, r.forwardedRx),
+	})
+
+	return nil
+}
+
+// accountingExprs adds the peer address at the offset of the packets of the WireGuard interface to the set, the
+// counter of the element counts the packets
+func (r *router) accountingExprs(ifaceKey expr.MetaKey, addrOffset uint32, set *nftables.Set) []expr.Any {
+	return []expr.Any{
+		&expr.Meta{Key: ifaceKey, Register: 1},
+		&expr.Cmp{
+			Op:       expr.CmpOpEq,
+			Register: 1,
+			Data:     ifname(r.wgIface.Name()),
+		},
+		&expr.Payload{
+			DestRegister: 1,
+			Base:         expr.PayloadBaseNetworkHeader,
+			Offset:       addrOffset,
+			Len:          4,
+		},
+		&expr.Dynset{
+			SrcRegKey: 1,
+			SetName:   set.Name,
+			SetID:     set.ID,
+			Operation: unix.NFT_DYNSET_OP_ADD,
+		},
+	}
+}
+
+// ForwardedTraffic returns the bytes of the routed traffic per peer counted since the table was created
+func (r *router) ForwardedTraffic() (map[netip.Addr]firewall.ForwardedTraffic, error) {
+	if r.forwardedRx == nil || r.forwardedTx == nil {
+		return nil, fmt.Errorf("the routed traffic isn't counted")
+	}
+
+	traffic := make(map[netip.Addr]firewall.ForwardedTraffic)
+	for _, set := range []*nftables.Set{r.forwardedRx, r.forwardedTx} {
+		elements, err := r.conn.GetSetElements(set)
+		if err != nil {
+			return nil, fmt.Errorf("get elements of set %s: %w", set.Name, err)
+		}
+
+		for _, element := range elements {
+			addr, ok := netip.AddrFromSlice(element.Key)
+			if !ok || element.Counter == nil {
+				continue
+			}
+			t := traffic[addr]
+			if set == r.forwardedRx {
+				t.RxBytes = element.Counter.Bytes
+			} else {
+				t.TxBytes = element.Counter.Bytes
+			}
+			traffic[addr] = t
+		}
+	}
+
+	return traffic, nil
+}
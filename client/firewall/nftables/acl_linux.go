@@ -28,6 +28,7 @@ const (
 	chainNameInputFilter   = "netbird-acl-input-filter"
 	chainNameForwardFilter = "netbird-acl-forward-filter"
 	chainNamePrerouting    = "netbird-rt-prerouting"
+	chainNameOverlayOnly   = "netbird-overlay-only"
 
 	allowNetbirdInputRuleID = "allow Netbird incoming traffic"
 )
@@ -44,9 +45,10 @@ type AclManager struct {
 	wgIface            iFaceMapper
 	routingFwChainName string
 
-	workTable       *nftables.Table
-	chainInputRules *nftables.Chain
-	chainPrerouting *nftables.Chain
+	workTable        *nftables.Table
+	chainInputRules  *nftables.Chain
+	chainPrerouting  *nftables.Chain
+	chainOverlayOnly *nftables.Chain
 
 	ipsetStore *ipsetStore
 	rules      map[string]*Rule
@@ -92,6 +94,11 @@ func (m *AclManager) AddPeerFiltering(
 	action firewall.Action,
 	ipsetName string,
 ) ([]firewall.Rule, error) {
+	// the rules match the IPv4 header, an address without an IPv4 form would match any source
+	if ip.To4() == nil {
+		return nil, fmt.Errorf("unsupported peer IP %s, only IPv4 addresses can be filtered", ip)
+	}
+
 	var ipset *nftables.Set
 	if ipsetName != "" {
 		var err error
@@ -419,6 +426,66 @@ func (m *AclManager) createPreroutingRule(expressions []expr.Any, userData []byt
 	})
 }
 
+// SetOverlayOnlyServices drops the traffic to the services received on other interfaces than the NetBird interface
+// and the loopback interface
+func (m *AclManager) SetOverlayOnlyServices(services []firewall.InboundService) error {
+	if m.chainOverlayOnly == nil {
+		if len(services) == 0 {
+			return nil
+		}
+		m.chainOverlayOnly = m.createFilterChainWithHook(chainNameOverlayOnly, nftables.ChainHookInput)
+	} else {
+		m.rConn.FlushChain(m.chainOverlayOnly)
+	}
+
+	for _, service := range services {
+		protoData, err := protoToInt(service.Protocol)
+		if err != nil {
+			return fmt.Errorf("convert protocol to number: %v", err)
+		}
+
+		expressions := []expr.Any{
+			&expr.Meta{Key: expr.MetaKeyIIFNAME, Register: 1},
+			&expr.Cmp{
+				Op:       expr.CmpOpNeq,
+				Register: 1,
+				Data:     ifname(m.wgIface.Name()),
+			},
+			&expr.Cmp{
+				Op:       expr.CmpOpNeq,
+				Register: 1,
+				Data:     ifname("lo"),
+			},
+			&expr.Payload{
+				DestRegister: 1,
+				Base:         expr.PayloadBaseNetworkHeader,
+				Offset:       uint32(9),
+				Len:          uint32(1),
+			},
+			&expr.Cmp{
+				Register: 1,
+				Op:       expr.CmpOpEq,
+				Data:     []byte{protoData},
+			},
+		}
+		expressions = append(expressions, applyPort(&service.Port, false)...)
+		expressions = append(expressions, &expr.Verdict{Kind: expr.VerdictDrop})
+
+		m.rConn.AddRule(&nftables.Rule{
+			Table:    m.workTable,
+			Chain:    m.chainOverlayOnly,
+			Exprs:    expressions,
+			UserData: []byte("overlay-only " + service.String()),
+		})
+	}
+
+	if err := m.rConn.Flush(); err != nil {
+		return fmt.Errorf(flushError, err)
+	}
+
+	return nil
+}
+
 func (m *AclManager) createDefaultChains() (err error) {
 	// chainNameInputRules
 	chain := m.createChain(chainNameInputRules)
@@ -0,0 +1,171 @@
+package nftables
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/google/nftables"
+	"github.com/google/nftables/expr"
+
+	firewall "github.com/netbirdio/netbird/client/firewall/manager"
+)
+
+// DumpRules returns the rules the manager applied and the rules present in the chains of the netbird table and in
+// the other chains it applied rules to. The rules are identified by their user data. The rules without user data are
+// the default rules of the chains, they aren't tracked by the manager and are listed as present only.
+func (m *Manager) DumpRules() (*firewall.RulesDump, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	applied := make(map[chainRef][]*nftables.Rule)
+	add := func(rule *nftables.Rule) {
+		if rule == nil || rule.Table == nil || rule.Chain == nil {
+			return
+		}
+		ref := chainRef{table: rule.Table.Name, chain: rule.Chain.Name}
+		applied[ref] = append(applied[ref], rule)
+	}
+	for _, rule := range m.aclManager.rules {
+		add(rule.nftRule)
+		add(rule.mangleRule)
+	}
+	for _, rule := range m.router.rules {
+		add(rule)
+	}
+
+	chains, err := m.rConn.ListChainsOfTableFamily(nftables.TableFamilyIPv4)
+	if err != nil {
+		return nil, fmt.Errorf("list chains: %w", err)
+	}
+
+	dump := &firewall.RulesDump{Backend: "nftables"}
+	seen := make(map[chainRef]struct{})
+	for _, chain := range chains {
+		ref := chainRef{table: chain.Table.Name, chain: chain.Name}
+		if _, ok := applied[ref]; !ok && chain.Table.Name != workTableName() {
+			continue
+		}
+		seen[ref] = struct{}{}
+
+		set, err := m.dumpChain(chain, applied[ref])
+		if err != nil {
+			return nil, err
+		}
+		dump.RuleSets = append(dump.RuleSets, set)
+	}
+
+	// rules applied to chains that don't exist anymore
+	for ref, rules := range applied {
+		if _, ok := seen[ref]; ok {
+			continue
+		}
+		set := &firewall.RuleSet{Table: ref.table, Chain: ref.chain}
+		for _, rule := range rules {
+			set.Applied = append(set.Applied, string(rule.UserData))
+		}
+		set.Missing = set.Applied
+		dump.RuleSets = append(dump.RuleSets, set)
+	}
+
+	sort.Slice(dump.RuleSets, func(i, j int) bool {
+		if dump.RuleSets[i].Table != dump.RuleSets[j].Table {
+			return dump.RuleSets[i].Table < dump.RuleSets[j].Table
+		}
+		return dump.RuleSets[i].Chain < dump.RuleSets[j].Chain
+	})
+
+	return dump, nil
+}
+
+// chainRef identifies a chain of a table
+type chainRef struct {
+	table string
+	chain string
+}
+
+func (m *Manager) dumpChain(chain *nftables.Chain, applied []*nftables.Rule) (*firewall.RuleSet, error) {
+	rules, err := m.rConn.GetRules(chain.Table, chain)
+	if err != nil {
+		return nil, fmt.Errorf("get rules of chain %s: %w", chain.Name, err)
+	}
+
+	set := &firewall.RuleSet{Table: chain.Table.Name, Chain: chain.Name}
+	for _, rule := range applied {
+		set.Applied = append(set.Applied, string(rule.UserData))
+	}
+
+	var defaultRules []string
+	for _, rule := range rules {
+		if len(rule.UserData) == 0 {
+			defaultRules = append(defaultRules, fmt.Sprintf("handle %d: %s", rule.Handle, describeExprs(rule.Exprs)))
+			continue
+		}
+		set.Present = append(set.Present, string(rule.UserData))
+	}
+
+	sort.Strings(set.Applied)
+	sort.Strings(set.Present)
+	set.DiffRules(func(applied, present string) bool {
+		return applied == present
+	})
+	set.Present = append(set.Present, defaultRules...)
+
+	return set, nil
+}
+
+// describeExprs returns a short description of the matches and the verdict of a rule
+func describeExprs(exprs []expr.Any) string {
+	var parts []string
+	for _, e := range exprs {
+		switch e := e.(type) {
+		case *expr.Cmp:
+			parts = append(parts, describeData(e.Data))
+		case *expr.Lookup:
+			parts = append(parts, "@"+e.SetName)
+		case *expr.Masq:
+			parts = append(parts, "masquerade")
+		case *expr.NAT:
+			if e.Type == expr.NATTypeDestNAT {
+				parts = append(parts, "dnat")
+			} else {
+				parts = append(parts, "snat")
+			}
+		case *expr.Verdict:
+			switch e.Kind {
+			case expr.VerdictAccept:
+				parts = append(parts, "accept")
+			case expr.VerdictDrop:
+				parts = append(parts, "drop")
+			case expr.VerdictReturn:
+				parts = append(parts, "return")
+			case expr.VerdictJump:
+				parts = append(parts, "jump "+e.Chain)
+			case expr.VerdictGoto:
+				parts = append(parts, "goto "+e.Chain)
+			}
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// describeData returns the compared data as an interface name, an IPv4 address or hex
+func describeData(data []byte) string {
+	if name := strings.TrimRight(string(data), "\x00"); len(data) == 16 && name != "" && isPrintable(name) {
+		return fmt.Sprintf("%q", name)
+	}
+	if len(data) == 4 {
+		return net.IP(data).String()
+	}
+	return fmt.Sprintf("0x%x", data)
+}
+
+func isPrintable(s string) bool {
+	for _, c := range s {
+		if c < 0x20 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -15,11 +15,13 @@ import (
 
 	firewall "github.com/netbirdio/netbird/client/firewall/manager"
 	"github.com/netbirdio/netbird/client/iface/wgaddr"
+	"github.com/netbirdio/netbird/client/internal/profiles"
 	"github.com/netbirdio/netbird/client/internal/statemanager"
 )
 
 const (
-	// tableNameNetbird is the name of the table that is used for filtering by the Netbird client
+	// tableNameNetbird is the name of the table that is used for filtering by the Netbird client, the daemons of the
+	// profiles suffix it with the name of the profile
 	tableNameNetbird = "netbird"
 
 	tableNameFilter = "filter"
@@ -50,7 +52,7 @@ func Create(wgIface iFaceMapper) (*Manager, error) {
 		wgIface: wgIface,
 	}
 
-	workTable := &nftables.Table{Name: tableNameNetbird, Family: nftables.TableFamilyIPv4}
+	workTable := &nftables.Table{Name: workTableName(), Family: nftables.TableFamilyIPv4}
 
 	var err error
 	m.router, err = newRouter(workTable, wgIface)
@@ -237,6 +239,22 @@ func (m *Manager) AllowNetbird() error {
 	return nil
 }
 
+// SetOverlayOnlyServices restricts the services to the NetBird interface
+func (m *Manager) SetOverlayOnlyServices(services []firewall.InboundService) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.aclManager.SetOverlayOnlyServices(services)
+}
+
+// ForwardedTraffic returns the bytes of the routed traffic per peer
+func (m *Manager) ForwardedTraffic() (map[netip.Addr]firewall.ForwardedTraffic, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.router.ForwardedTraffic()
+}
+
 // SetLegacyManagement sets the route manager to use legacy management
 func (m *Manager) SetLegacyManagement(isLegacy bool) error {
 	return firewall.SetLegacyManagement(m.router, isLegacy)
@@ -312,7 +330,7 @@ func (m *Manager) cleanupNetbirdTables() error {
 	}
 
 	for _, t := range tables {
-		if t.Name == tableNameNetbird {
+		if t.Name == workTableName() {
 			m.rConn.DelTable(t)
 		}
 	}
@@ -366,16 +384,21 @@ func (m *Manager) createWorkTable() (*nftables.Table, error) {
 	}
 
 	for _, t := range tables {
-		if t.Name == tableNameNetbird {
+		if t.Name == workTableName() {
 			m.rConn.DelTable(t)
 		}
 	}
 
-	table := m.rConn.AddTable(&nftables.Table{Name: tableNameNetbird, Family: nftables.TableFamilyIPv4})
+	table := m.rConn.AddTable(&nftables.Table{Name: workTableName(), Family: nftables.TableFamilyIPv4})
 	err = m.rConn.Flush()
 	return table, err
 }
 
+// workTableName returns the name of the table of the daemon, the daemons of the profiles use a table of their own
+func workTableName() string {
+	return tableNameNetbird + profiles.Suffix()
+}
+
 func (m *Manager) applyAllowNetbirdRules(chain *nftables.Chain) {
 	rule := &nftables.Rule{
 		Table: chain.Table,
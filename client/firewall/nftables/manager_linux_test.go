@@ -77,6 +77,9 @@ func TestNftablesManager(t *testing.T) {
 	rule, err := manager.AddPeerFiltering(nil, ip, fw.ProtocolTCP, nil, &fw.Port{Values: []uint16{53}}, fw.ActionDrop, "")
 	require.NoError(t, err, "failed to add rule")
 
+	_, err = manager.AddPeerFiltering(nil, net.ParseIP("fd00::1"), fw.ProtocolALL, nil, nil, fw.ActionAccept, "")
+	require.Error(t, err, "a rule of an IPv6 peer would match any source")
+
 	err = manager.Flush()
 	require.NoError(t, err, "failed to flush")
 
@@ -55,6 +55,9 @@ type router struct {
 	// rules is useful to avoid duplicates and to get missing attributes that we don't have when adding new rules
 	rules        map[string]*nftables.Rule
 	ipsetCounter *refcounter.Counter[string, []netip.Prefix, *nftables.Set]
+	// forwardedRx and forwardedTx count the routed traffic per peer
+	forwardedRx *nftables.Set
+	forwardedTx *nftables.Set
 
 	wgIface          iFaceMapper
 	ipFwdState       *ipfwdstate.IPForwardingState
@@ -211,6 +214,10 @@ func (r *router) createContainers() error {
 		return fmt.Errorf("add single nat rule: %v", err)
 	}
 
+	if err := r.createAccounting(); err != nil {
+		log.Errorf("failed to count the routed traffic: %s", err)
+	}
+
 	if err := r.acceptForwardRules(); err != nil {
 		log.Errorf("failed to add accept rules for the forward chain: %s", err)
 	}
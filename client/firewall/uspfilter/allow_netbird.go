@@ -4,11 +4,13 @@ package uspfilter
 
 import (
 	"context"
+	"errors"
 	"net/netip"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 
+	firewall "github.com/netbirdio/netbird/client/firewall/manager"
 	"github.com/netbirdio/netbird/client/internal/statemanager"
 )
 
@@ -57,3 +59,14 @@ func (m *Manager) AllowNetbird() error {
 	}
 	return nil
 }
+
+// SetOverlayOnlyServices restricts the services to the NetBird interface, using the native firewall
+func (m *Manager) SetOverlayOnlyServices(services []firewall.InboundService) error {
+	if fm, ok := m.nativeFirewall.(firewall.OverlayOnlyManager); ok {
+		return fm.SetOverlayOnlyServices(services)
+	}
+	if len(services) == 0 {
+		return nil
+	}
+	return errors.New("the overlay-only mode requires a native firewall")
+}
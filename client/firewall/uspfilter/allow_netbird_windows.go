@@ -2,14 +2,20 @@ package uspfilter
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"net/netip"
 	"os/exec"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/hashicorp/go-multierror"
 	log "github.com/sirupsen/logrus"
 
+	nberrors "github.com/netbirdio/netbird/client/errors"
+	firewall "github.com/netbirdio/netbird/client/firewall/manager"
 	"github.com/netbirdio/netbird/client/firewall/uspfilter/conntrack"
 	"github.com/netbirdio/netbird/client/internal/statemanager"
 )
@@ -17,9 +23,10 @@ import (
 type action string
 
 const (
-	addRule          action = "add"
-	deleteRule       action = "delete"
-	firewallRuleName        = "Netbird"
+	addRule                     action = "add"
+	deleteRule                  action = "delete"
+	firewallRuleName                   = "Netbird"
+	overlayOnlyFirewallRuleName        = "Netbird overlay-only"
 )
 
 // Reset firewall to the default state
@@ -49,6 +56,13 @@ func (m *Manager) Close(*statemanager.Manager) error {
 		fwder.Stop()
 	}
 
+	if m.nativeForwarding.Load() {
+		m.nativeForwarding.Store(false)
+		if err := m.nativeForwarder.DisableRouting(); err != nil {
+			log.Errorf("failed to disable native forwarding: %v", err)
+		}
+	}
+
 	if m.logger != nil {
 		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 		defer cancel()
@@ -61,6 +75,12 @@ func (m *Manager) Close(*statemanager.Manager) error {
 		return nil
 	}
 
+	if isFirewallRuleActive(overlayOnlyFirewallRuleName) {
+		if err := manageFirewallRule(overlayOnlyFirewallRuleName, deleteRule); err != nil {
+			log.Errorf("failed to remove the overlay-only windows firewall rules: %v", err)
+		}
+	}
+
 	if !isFirewallRuleActive(firewallRuleName) {
 		return nil
 	}
@@ -91,6 +111,73 @@ func (m *Manager) AllowNetbird() error {
 	)
 }
 
+// SetOverlayOnlyServices blocks the inbound traffic to the services on all local addresses but the NetBird address.
+// The block rules take precedence over the allow rules of the Windows firewall. Netsh deletes all the rules sharing
+// the name, so the rules of all services are replaced at once.
+func (m *Manager) SetOverlayOnlyServices(services []firewall.InboundService) error {
+	if !isWindowsFirewallReachable() {
+		if len(services) == 0 {
+			return nil
+		}
+		return errors.New("the overlay-only mode requires the windows firewall")
+	}
+
+	if isFirewallRuleActive(overlayOnlyFirewallRuleName) {
+		if err := manageFirewallRule(overlayOnlyFirewallRuleName, deleteRule); err != nil {
+			return fmt.Errorf("remove overlay-only rules: %w", err)
+		}
+	}
+
+	localIPs, err := excludeAddress(m.wgIface.Address().IP)
+	if err != nil {
+		return err
+	}
+
+	var merr *multierror.Error
+	for _, service := range services {
+		if err := manageFirewallRule(overlayOnlyFirewallRuleName,
+			addRule,
+			"dir=in",
+			"enable=yes",
+			"action=block",
+			"profile=any",
+			"protocol="+strings.ToUpper(string(service.Protocol)),
+			"localport="+netshPort(service.Port),
+			"localip="+localIPs,
+		); err != nil {
+			merr = multierror.Append(merr, fmt.Errorf("add overlay-only rule for %s: %w", service, err))
+		}
+	}
+
+	return nberrors.FormatErrorOrNil(merr)
+}
+
+// netshPort returns the port in the netsh format, a range is start-end and a list is comma separated
+func netshPort(port firewall.Port) string {
+	if port.IsRange && len(port.Values) == 2 {
+		return fmt.Sprintf("%d-%d", port.Values[0], port.Values[1])
+	}
+	return port.String()
+}
+
+// excludeAddress returns the netsh address ranges covering all addresses but the IPv4 address
+func excludeAddress(ip net.IP) (string, error) {
+	addr, ok := netip.AddrFromSlice(ip.To4())
+	if !ok {
+		return "", fmt.Errorf("invalid NetBird address %s", ip)
+	}
+
+	var ranges []string
+	if prev := addr.Prev(); prev.IsValid() {
+		ranges = append(ranges, "0.0.0.0-"+prev.String())
+	}
+	if next := addr.Next(); next.IsValid() {
+		ranges = append(ranges, next.String()+"-255.255.255.255")
+	}
+	ranges = append(ranges, "::-ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff")
+	return strings.Join(ranges, ","), nil
+}
+
 func manageFirewallRule(ruleName string, action action, extraArgs ...string) error {
 
 	args := []string{"advfirewall", "firewall", string(action), "rule", "name=" + ruleName}
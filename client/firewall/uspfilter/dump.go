@@ -0,0 +1,113 @@
+package uspfilter
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+
+	firewall "github.com/netbirdio/netbird/client/firewall/manager"
+)
+
+// DumpRules returns the rules of the userspace filter and of the native firewall it uses, if any.
+// The userspace filter evaluates its rules in-process, the applied rules are the present ones.
+func (m *Manager) DumpRules() (*firewall.RulesDump, error) {
+	m.mutex.RLock()
+	dump := &firewall.RulesDump{
+		Backend: "userspace",
+		RuleSets: []*firewall.RuleSet{
+			peerRuleSet("incoming", m.incomingRules),
+			peerRuleSet("outgoing", m.outgoingRules),
+			routeRuleSet(m.routeRules),
+		},
+	}
+	m.mutex.RUnlock()
+
+	if m.nativeFirewall != nil {
+		native, err := m.nativeFirewall.DumpRules()
+		if err != nil {
+			return nil, fmt.Errorf("dump native firewall rules: %w", err)
+		}
+		dump.Native = native
+	}
+
+	return dump, nil
+}
+
+func peerRuleSet(chain string, rules map[netip.Addr]RuleSet) *firewall.RuleSet {
+	set := &firewall.RuleSet{Chain: chain}
+	for _, ruleSet := range rules {
+		for _, rule := range ruleSet {
+			set.Applied = append(set.Applied, describePeerRule(rule))
+		}
+	}
+	sort.Strings(set.Applied)
+	set.Present = set.Applied
+
+	return set
+}
+
+func routeRuleSet(rules RouteRules) *firewall.RuleSet {
+	set := &firewall.RuleSet{Chain: "routing"}
+	// the route rules are evaluated in order, they are kept sorted
+	for _, rule := range rules {
+		set.Applied = append(set.Applied, describeRouteRule(rule))
+	}
+	set.Present = set.Applied
+
+	return set
+}
+
+func describePeerRule(rule PeerRule) string {
+	parts := []string{rule.id + ":"}
+	if rule.matchByIP {
+		parts = append(parts, "src "+rule.ip.String())
+	}
+	if rule.protoLayer != layerTypeAll {
+		parts = append(parts, strings.ToLower(rule.protoLayer.String()))
+	}
+	parts = append(parts, describePorts(rule.sPort, rule.dPort)...)
+
+	switch {
+	case rule.udpHook != nil:
+		parts = append(parts, "hook")
+	case rule.drop:
+		parts = append(parts, "drop")
+	default:
+		parts = append(parts, "accept")
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func describeRouteRule(rule RouteRule) string {
+	sources := make([]string, 0, len(rule.sources))
+	for _, source := range rule.sources {
+		sources = append(sources, source.String())
+	}
+
+	parts := []string{rule.id + ":", "src " + strings.Join(sources, ","), "dst " + rule.destination.String()}
+	if rule.proto != firewall.ProtocolALL {
+		parts = append(parts, string(rule.proto))
+	}
+	parts = append(parts, describePorts(rule.srcPort, rule.dstPort)...)
+
+	if rule.action == firewall.ActionDrop {
+		parts = append(parts, "drop")
+	} else {
+		parts = append(parts, "accept")
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func describePorts(sPort, dPort *firewall.Port) []string {
+	var parts []string
+	if sPort != nil {
+		parts = append(parts, "sport "+sPort.String())
+	}
+	if dPort != nil {
+		parts = append(parts, "dport "+dPort.String())
+	}
+	return parts
+}
@@ -0,0 +1,73 @@
+package uspfilter
+
+import (
+	"errors"
+	"net/netip"
+	"sync"
+	"sync/atomic"
+
+	firewall "github.com/netbirdio/netbird/client/firewall/manager"
+)
+
+// forwardedTraffic counts the bytes of the routed traffic per peer
+type forwardedTraffic struct {
+	// peers maps the NetBird IP address of a peer to its *peerTraffic
+	peers sync.Map
+}
+
+type peerTraffic struct {
+	rx atomic.Uint64
+	tx atomic.Uint64
+}
+
+func (t *forwardedTraffic) get(peer netip.Addr) *peerTraffic {
+	if counters, ok := t.peers.Load(peer); ok {
+		return counters.(*peerTraffic)
+	}
+	counters, _ := t.peers.LoadOrStore(peer, &peerTraffic{})
+	return counters.(*peerTraffic)
+}
+
+// addRx counts a packet forwarded to the peer
+func (t *forwardedTraffic) addRx(peer netip.Addr, size int) {
+	t.get(peer).rx.Add(uint64(size))
+}
+
+// addTx counts a packet forwarded for the peer
+func (t *forwardedTraffic) addTx(peer netip.Addr, size int) {
+	t.get(peer).tx.Add(uint64(size))
+}
+
+func (t *forwardedTraffic) snapshot() map[netip.Addr]firewall.ForwardedTraffic {
+	traffic := make(map[netip.Addr]firewall.ForwardedTraffic)
+	t.peers.Range(func(key, value any) bool {
+		counters := value.(*peerTraffic)
+		traffic[key.(netip.Addr)] = firewall.ForwardedTraffic{
+			RxBytes: counters.rx.Load(),
+			TxBytes: counters.tx.Load(),
+		}
+		return true
+	})
+	return traffic
+}
+
+// ForwardedTraffic returns the bytes of the routed traffic per peer. The native firewall counts the traffic if it
+// forwards it.
+func (m *Manager) ForwardedTraffic() (map[netip.Addr]firewall.ForwardedTraffic, error) {
+	if m.nativeRouter.Load() {
+		if fm, ok := m.nativeFirewall.(firewall.TrafficAccountingManager); ok {
+			return fm.ForwardedTraffic()
+		}
+		return nil, errors.New("the native firewall doesn't count the routed traffic")
+	}
+
+	return m.forwardedTraffic.snapshot(), nil
+}
+
+// countForwardedReply counts the packets the forwarder sends to the peers on behalf of the routed networks
+func (m *Manager) countForwardedReply(src, dst netip.Addr, size int) {
+	if m.localipmanager.IsLocalIP(src) {
+		return
+	}
+	m.forwardedTraffic.addRx(dst, size)
+}
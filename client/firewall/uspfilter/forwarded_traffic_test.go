@@ -0,0 +1,26 @@
+package uspfilter
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	firewall "github.com/netbirdio/netbird/client/firewall/manager"
+)
+
+func TestForwardedTraffic(t *testing.T) {
+	var traffic forwardedTraffic
+	peer1 := netip.MustParseAddr("100.64.0.1")
+	peer2 := netip.MustParseAddr("100.64.0.2")
+
+	traffic.addTx(peer1, 100)
+	traffic.addRx(peer1, 1500)
+	traffic.addRx(peer1, 500)
+	traffic.addTx(peer2, 60)
+
+	assert.Equal(t, map[netip.Addr]firewall.ForwardedTraffic{
+		peer1: {RxBytes: 2000, TxBytes: 100},
+		peer2: {TxBytes: 60},
+	}, traffic.snapshot())
+}
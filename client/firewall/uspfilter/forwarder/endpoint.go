@@ -2,6 +2,7 @@ package forwarder
 
 import (
 	"fmt"
+	"net/netip"
 
 	wgdevice "golang.zx2c4.com/wireguard/device"
 	"gvisor.dev/gvisor/pkg/tcpip"
@@ -17,6 +18,8 @@ type endpoint struct {
 	dispatcher stack.NetworkDispatcher
 	device     *wgdevice.Device
 	mtu        uint32
+	// onOutbound is called for the packets sent through WireGuard, nil if they aren't counted
+	onOutbound func(src, dst netip.Addr, size int)
 }
 
 func (e *endpoint) Attach(dispatcher stack.NetworkDispatcher) {
@@ -55,12 +58,20 @@ func (e *endpoint) WritePackets(pkts stack.PacketBufferList) (int, tcpip.Error)
 
 		// Send the packet through WireGuard
 		address := netHeader.DestinationAddress()
-		err := e.device.CreateOutboundPacket(data.AsSlice(), address.AsSlice())
+		packet := data.AsSlice()
+		err := e.device.CreateOutboundPacket(packet, address.AsSlice())
 		if err != nil {
 			e.logger.Error("CreateOutboundPacket: %v", err)
 			continue
 		}
 		written++
+
+		if e.onOutbound != nil {
+			source := netHeader.SourceAddress()
+			src, _ := netip.AddrFromSlice(source.AsSlice())
+			dst, _ := netip.AddrFromSlice(address.AsSlice())
+			e.onOutbound(src, dst, len(packet))
+		}
 	}
 
 	return written, nil
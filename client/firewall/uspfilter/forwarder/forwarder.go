@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"net/netip"
 	"runtime"
 
 	log "github.com/sirupsen/logrus"
@@ -40,7 +41,9 @@ type Forwarder struct {
 	netstack     bool
 }
 
-func New(iface common.IFaceMapper, logger *nblog.Logger, flowLogger nftypes.FlowLogger, netstack bool) (*Forwarder, error) {
+// New creates a Forwarder injecting the packets it sends into the WireGuard device of the interface. onOutbound is
+// called for each of them if set.
+func New(iface common.IFaceMapper, logger *nblog.Logger, flowLogger nftypes.FlowLogger, netstack bool, onOutbound func(src, dst netip.Addr, size int)) (*Forwarder, error) {
 	s := stack.New(stack.Options{
 		NetworkProtocols: []stack.NetworkProtocolFactory{ipv4.NewProtocol},
 		TransportProtocols: []stack.TransportProtocolFactory{
@@ -57,9 +60,10 @@ func New(iface common.IFaceMapper, logger *nblog.Logger, flowLogger nftypes.Flow
 	}
 	nicID := tcpip.NICID(1)
 	endpoint := &endpoint{
-		logger: logger,
-		device: iface.GetWGDevice(),
-		mtu:    uint32(mtu),
+		logger:     logger,
+		device:     iface.GetWGDevice(),
+		mtu:        uint32(mtu),
+		onOutbound: onOutbound,
 	}
 
 	if err := s.CreateNIC(nicID, endpoint); err != nil {
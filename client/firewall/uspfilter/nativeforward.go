@@ -0,0 +1,11 @@
+//go:build !windows
+
+package uspfilter
+
+import (
+	"github.com/netbirdio/netbird/client/firewall/uspfilter/common"
+)
+
+func newNativeForwarder(common.IFaceMapper) nativeForwarder {
+	return nil
+}
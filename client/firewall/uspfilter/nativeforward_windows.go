@@ -0,0 +1,34 @@
+package uspfilter
+
+import (
+	"net/netip"
+	"os"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/client/firewall/uspfilter/common"
+	"github.com/netbirdio/netbird/client/firewall/winnat"
+)
+
+// newNativeForwarder returns the WinNAT router, the routed traffic allowed by the route ACLs is forwarded and
+// masqueraded by the Windows stack instead of the userspace forwarder
+func newNativeForwarder(iface common.IFaceMapper) nativeForwarder {
+	if val := os.Getenv(EnvDisableWinNAT); val != "" {
+		disabled, err := strconv.ParseBool(val)
+		if err != nil {
+			log.Warnf("failed to parse %s: %v", EnvDisableWinNAT, err)
+		}
+		if disabled {
+			log.Info("WinNAT routing is disabled")
+			return nil
+		}
+	}
+
+	network, err := netip.ParsePrefix(iface.Address().Network.String())
+	if err != nil {
+		log.Errorf("failed to parse wireguard network: %v", err)
+		return nil
+	}
+	return winnat.Create(network)
+}
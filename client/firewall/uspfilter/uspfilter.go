@@ -42,6 +42,9 @@ const (
 	// EnvEnableNetstackLocalForwarding enables forwarding of local traffic to the native stack when running netstack
 	// Leaving this on by default introduces a security risk as sockets on listening on localhost only will be accessible
 	EnvEnableNetstackLocalForwarding = "NB_ENABLE_NETSTACK_LOCAL_FORWARDING"
+
+	// EnvDisableWinNAT disables the routing with the Windows stack and WinNAT, the userspace router is used instead.
+	EnvDisableWinNAT = "NB_DISABLE_WINNAT"
 )
 
 var errNatNotSupported = errors.New("nat not supported with userspace firewall")
@@ -64,6 +67,15 @@ func (r RouteRules) Sort() {
 	})
 }
 
+// nativeForwarder forwards the routed traffic with the stack of the OS after the route ACLs were applied in userspace
+type nativeForwarder interface {
+	Init(stateManager *statemanager.Manager) error
+	EnableRouting() error
+	DisableRouting() error
+	AddNatRule(pair firewall.RouterPair) error
+	RemoveNatRule(pair firewall.RouterPair) error
+}
+
 // Manager userspace firewall manager
 type Manager struct {
 	// outgoingRules is used for hooks only
@@ -84,6 +96,9 @@ type Manager struct {
 	routingEnabled atomic.Bool
 	// indicates whether we leave forwarding and filtering to the native firewall
 	nativeRouter atomic.Bool
+	// indicates whether we leave forwarding to the native stack after filtering the routed traffic
+	nativeForwarding atomic.Bool
+	nativeForwarder  nativeForwarder
 	// indicates whether we track outbound connections
 	stateful bool
 	// indicates whether wireguards runs in netstack mode
@@ -99,6 +114,8 @@ type Manager struct {
 	forwarder   atomic.Pointer[forwarder.Forwarder]
 	logger      *nblog.Logger
 	flowLogger  nftypes.FlowLogger
+
+	forwardedTraffic forwardedTraffic
 }
 
 // decoder for packages
@@ -182,6 +199,10 @@ func create(iface common.IFaceMapper, nativeFirewall firewall.Manager, disableSe
 	}
 	m.routingEnabled.Store(false)
 
+	if nativeFirewall == nil && !m.netstack {
+		m.nativeForwarder = newNativeForwarder(iface)
+	}
+
 	if err := m.localipmanager.UpdateLocalIPs(iface); err != nil {
 		return nil, fmt.Errorf("update local IPs: %w", err)
 	}
@@ -282,6 +303,12 @@ func (m *Manager) determineRouting() error {
 
 		log.Info("native routing is enabled")
 
+	case m.nativeForwarder != nil && m.enableNativeForwarding():
+		m.routingEnabled.Store(true)
+		m.nativeRouter.Store(false)
+
+		log.Info("native forwarding is enabled")
+
 	default:
 		m.routingEnabled.Store(true)
 		m.nativeRouter.Store(false)
@@ -289,13 +316,27 @@ func (m *Manager) determineRouting() error {
 		log.Info("userspace routing enabled by default")
 	}
 
-	if m.routingEnabled.Load() && !m.nativeRouter.Load() {
+	if m.routingEnabled.Load() && !m.nativeRouter.Load() && !m.nativeForwarding.Load() {
 		return m.initForwarder()
 	}
 
 	return nil
 }
 
+// enableNativeForwarding enables the forwarding with the native stack, the userspace router is used if it fails
+func (m *Manager) enableNativeForwarding() bool {
+	if err := m.nativeForwarder.EnableRouting(); err != nil {
+		log.Warnf("failed to enable native forwarding, using the userspace router: %v", err)
+		if err := m.nativeForwarder.DisableRouting(); err != nil {
+			log.Errorf("failed to disable native forwarding: %v", err)
+		}
+		return false
+	}
+
+	m.nativeForwarding.Store(true)
+	return true
+}
+
 // initForwarder initializes the forwarder, it disables routing on errors
 func (m *Manager) initForwarder() error {
 	if m.forwarder.Load() != nil {
@@ -309,7 +350,7 @@ func (m *Manager) initForwarder() error {
 		return errors.New("forwarding not supported")
 	}
 
-	forwarder, err := forwarder.New(m.wgIface, m.logger, m.flowLogger, m.netstack)
+	forwarder, err := forwarder.New(m.wgIface, m.logger, m.flowLogger, m.netstack, m.countForwardedReply)
 	if err != nil {
 		m.routingEnabled.Store(false)
 		return fmt.Errorf("create forwarder: %w", err)
@@ -322,7 +363,10 @@ func (m *Manager) initForwarder() error {
 	return nil
 }
 
-func (m *Manager) Init(*statemanager.Manager) error {
+func (m *Manager) Init(stateManager *statemanager.Manager) error {
+	if m.nativeForwarder != nil {
+		return m.nativeForwarder.Init(stateManager)
+	}
 	return nil
 }
 
@@ -335,6 +379,10 @@ func (m *Manager) AddNatRule(pair firewall.RouterPair) error {
 		return m.nativeFirewall.AddNatRule(pair)
 	}
 
+	if m.nativeForwarding.Load() {
+		return m.nativeForwarder.AddNatRule(pair)
+	}
+
 	// userspace routed packets are always SNATed to the inbound direction
 	// TODO: implement outbound SNAT
 	return nil
@@ -345,6 +393,10 @@ func (m *Manager) RemoveNatRule(pair firewall.RouterPair) error {
 	if m.nativeRouter.Load() && m.nativeFirewall != nil {
 		return m.nativeFirewall.RemoveNatRule(pair)
 	}
+
+	if m.nativeForwarding.Load() {
+		return m.nativeForwarder.RemoveNatRule(pair)
+	}
 	return nil
 }
 
@@ -550,6 +602,11 @@ func (m *Manager) processOutgoingHooks(packetData []byte, size int) bool {
 		m.trackOutbound(d, srcIP, dstIP, size)
 	}
 
+	// the native stack sends the replies of the routed networks to the peers through the interface
+	if m.nativeForwarding.Load() && !m.localipmanager.IsLocalIP(srcIP) {
+		m.forwardedTraffic.addRx(dstIP, size)
+	}
+
 	return false
 }
 
@@ -756,7 +813,8 @@ func (m *Manager) handleRoutedTraffic(d *decoder, srcIP, dstIP netip.Addr, packe
 	proto, pnum := getProtocolFromPacket(d)
 	srcPort, dstPort := getPortsFromPacket(d)
 
-	if ruleID, pass := m.routeACLsPass(srcIP, dstIP, proto, srcPort, dstPort); !pass {
+	ruleID, pass := m.routeACLsPass(srcIP, dstIP, proto, srcPort, dstPort)
+	if !pass {
 		m.logger.Trace("Dropping routed packet (ACL denied): rule_id=%s proto=%v src=%s:%d dst=%s:%d",
 			ruleID, pnum, srcIP, srcPort, dstIP, dstPort)
 
@@ -777,6 +835,14 @@ func (m *Manager) handleRoutedTraffic(d *decoder, srcIP, dstIP netip.Addr, packe
 		return true
 	}
 
+	m.forwardedTraffic.addTx(srcIP, size)
+
+	// Pass to the native stack if it forwards the packets that passed the route ACLs
+	if m.nativeForwarding.Load() {
+		m.trackInbound(d, srcIP, dstIP, ruleID, size)
+		return false
+	}
+
 	// Let forwarder handle the packet if it passed route ACLs
 	fwd := m.forwarder.Load()
 	if fwd == nil {
@@ -1075,6 +1141,12 @@ func (m *Manager) DisableRouting() error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
+	if m.nativeForwarding.Load() {
+		m.routingEnabled.Store(false)
+		m.nativeForwarding.Store(false)
+		return m.nativeForwarder.DisableRouting()
+	}
+
 	fwder := m.forwarder.Load()
 	if fwder == nil {
 		return nil
@@ -0,0 +1,174 @@
+package winnat
+
+import (
+	"fmt"
+	"net/netip"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+	log "github.com/sirupsen/logrus"
+
+	nberrors "github.com/netbirdio/netbird/client/errors"
+	firewall "github.com/netbirdio/netbird/client/firewall/manager"
+	"github.com/netbirdio/netbird/client/internal/statemanager"
+	"github.com/netbirdio/netbird/route"
+)
+
+// Manager forwards the routed traffic with the Windows stack. The traffic of the WireGuard network is masqueraded by
+// a WinNAT instance while there are routes with masquerading. WinNAT can't select the traffic by destination, the
+// routes without masquerading are masqueraded too while there is one with it.
+type Manager struct {
+	mu           sync.Mutex
+	network      netip.Prefix
+	stateManager *statemanager.Manager
+
+	routing bool
+	// forwardingIfaces are the LUIDs of the interfaces the forwarding was enabled on
+	forwardingIfaces []uint64
+	// masqueraded are the routes with masquerading
+	masqueraded map[route.ID]struct{}
+	natCreated  bool
+}
+
+// Create returns a manager routing the traffic of the WireGuard network
+func Create(network netip.Prefix) *Manager {
+	return &Manager{
+		network:     network.Masked(),
+		masqueraded: make(map[route.ID]struct{}),
+	}
+}
+
+// Init registers the state of the manager to clean up the NAT and the forwarding after a crash
+func (m *Manager) Init(stateManager *statemanager.Manager) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.stateManager = stateManager
+	if stateManager != nil {
+		stateManager.RegisterState(&ShutdownState{})
+	}
+	return nil
+}
+
+// EnableRouting checks that WinNAT is available without a conflicting NAT network and enables the forwarding
+func (m *Manager) EnableRouting() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.routing {
+		return nil
+	}
+
+	nats, err := listNats()
+	if err != nil {
+		return fmt.Errorf("WinNAT is not available: %w", err)
+	}
+	if err := checkConflicts(nats, m.network); err != nil {
+		return err
+	}
+	for _, nat := range nats {
+		if nat.Name == NatName {
+			// left over by a previous run that didn't shut down cleanly
+			if err := removeNat(); err != nil {
+				return err
+			}
+		}
+	}
+
+	luids, err := enableForwarding()
+	m.forwardingIfaces = luids
+	m.routing = true
+	m.updateState()
+	if err != nil {
+		return fmt.Errorf("enable forwarding: %w", err)
+	}
+
+	log.Infof("enabled routing with WinNAT for %s", m.network)
+	return nil
+}
+
+// DisableRouting removes the NAT and restores the forwarding of the interfaces
+func (m *Manager) DisableRouting() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.routing {
+		return nil
+	}
+
+	var merr *multierror.Error
+	if m.natCreated {
+		if err := removeNat(); err != nil {
+			merr = multierror.Append(merr, err)
+		} else {
+			m.natCreated = false
+		}
+	}
+	if err := restoreForwarding(m.forwardingIfaces); err != nil {
+		merr = multierror.Append(merr, fmt.Errorf("restore forwarding: %w", err))
+	}
+
+	m.forwardingIfaces = nil
+	m.masqueraded = make(map[route.ID]struct{})
+	m.routing = false
+	m.updateState()
+
+	return nberrors.FormatErrorOrNil(merr)
+}
+
+// AddNatRule creates the NAT with the first route with masquerading
+func (m *Manager) AddNatRule(pair firewall.RouterPair) error {
+	if !pair.Masquerade {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.masqueraded[pair.ID] = struct{}{}
+	if m.natCreated {
+		return nil
+	}
+
+	if err := createNat(m.network); err != nil {
+		delete(m.masqueraded, pair.ID)
+		return err
+	}
+	m.natCreated = true
+	m.updateState()
+
+	return nil
+}
+
+// RemoveNatRule removes the NAT with the last route with masquerading
+func (m *Manager) RemoveNatRule(pair firewall.RouterPair) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.masqueraded, pair.ID)
+	if len(m.masqueraded) > 0 || !m.natCreated {
+		return nil
+	}
+
+	if err := removeNat(); err != nil {
+		return err
+	}
+	m.natCreated = false
+	m.updateState()
+
+	return nil
+}
+
+func (m *Manager) updateState() {
+	if m.stateManager == nil {
+		return
+	}
+
+	state := &ShutdownState{
+		NatCreated:           m.natCreated,
+		ForwardingInterfaces: m.forwardingIfaces,
+	}
+	if err := m.stateManager.UpdateState(state); err != nil {
+		log.Errorf("failed to update the WinNAT state: %v", err)
+	}
+}
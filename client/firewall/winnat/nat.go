@@ -0,0 +1,66 @@
+// Package winnat routes the traffic of the peers through the network stack of Windows: the IP forwarding between the
+// interfaces and a WinNAT instance masquerading the WireGuard network. Windows routing peers don't need an Internet
+// Connection Sharing setup with it.
+package winnat
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+)
+
+// NatName is the name of the WinNAT instance of the client
+const NatName = "NetBird"
+
+// ErrNatConflict is returned if a NAT network of another application, like Hyper-V or a container runtime, overlaps
+// with the WireGuard network
+var ErrNatConflict = errors.New("conflicting NAT network")
+
+// netNat is a WinNAT instance as returned by Get-NetNat
+type netNat struct {
+	Name                             string
+	InternalIPInterfaceAddressPrefix string
+}
+
+// parseNetNats parses the JSON output of Get-NetNat, which is an object for a single instance and empty without any
+func parseNetNats(data []byte) ([]netNat, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	if data[0] == '{' {
+		var nat netNat
+		if err := json.Unmarshal(data, &nat); err != nil {
+			return nil, fmt.Errorf("parse NAT networks: %w", err)
+		}
+		return []netNat{nat}, nil
+	}
+
+	var nats []netNat
+	if err := json.Unmarshal(data, &nats); err != nil {
+		return nil, fmt.Errorf("parse NAT networks: %w", err)
+	}
+	return nats, nil
+}
+
+// checkConflicts returns an error if a NAT network of another application overlaps with the network
+func checkConflicts(nats []netNat, network netip.Prefix) error {
+	for _, nat := range nats {
+		if nat.Name == NatName {
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(nat.InternalIPInterfaceAddressPrefix)
+		if err != nil {
+			continue
+		}
+		if prefix.Overlaps(network) {
+			return fmt.Errorf("%w: %s with the internal prefix %s overlaps with %s, it's likely a Hyper-V or container network",
+				ErrNatConflict, nat.Name, prefix, network)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package winnat
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNetNats(t *testing.T) {
+	nats, err := parseNetNats([]byte(""))
+	require.NoError(t, err)
+	assert.Empty(t, nats)
+
+	nats, err = parseNetNats([]byte(`{"Name": "DockerNAT", "InternalIPInterfaceAddressPrefix": "172.16.0.0/12", "Active": true}`))
+	require.NoError(t, err)
+	assert.Equal(t, []netNat{{Name: "DockerNAT", InternalIPInterfaceAddressPrefix: "172.16.0.0/12"}}, nats)
+
+	nats, err = parseNetNats([]byte(`[
+  {"Name": "DockerNAT", "InternalIPInterfaceAddressPrefix": "172.16.0.0/12"},
+  {"Name": "NetBird", "InternalIPInterfaceAddressPrefix": "100.64.0.0/10"}
+]`))
+	require.NoError(t, err)
+	assert.Len(t, nats, 2)
+
+	_, err = parseNetNats([]byte("Get-NetNat : Invalid class"))
+	assert.Error(t, err)
+}
+
+func TestCheckConflicts(t *testing.T) {
+	network := netip.MustParsePrefix("100.90.0.0/16")
+
+	nats := []netNat{
+		{Name: "DockerNAT", InternalIPInterfaceAddressPrefix: "172.16.0.0/12"},
+		{Name: NatName, InternalIPInterfaceAddressPrefix: "100.90.0.0/16"},
+	}
+	assert.NoError(t, checkConflicts(nats, network), "the own NAT network and the disjoint ones shouldn't conflict")
+
+	nats = append(nats, netNat{Name: "HyperVNAT", InternalIPInterfaceAddressPrefix: "100.64.0.0/10"})
+	assert.ErrorIs(t, checkConflicts(nats, network), ErrNatConflict)
+}
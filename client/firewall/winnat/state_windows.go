@@ -0,0 +1,32 @@
+package winnat
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-multierror"
+
+	nberrors "github.com/netbirdio/netbird/client/errors"
+)
+
+// ShutdownState is the NAT and forwarding state to restore if the client didn't shut down cleanly
+type ShutdownState struct {
+	NatCreated           bool     `json:"nat_created,omitempty"`
+	ForwardingInterfaces []uint64 `json:"forwarding_interfaces,omitempty"`
+}
+
+func (s *ShutdownState) Name() string {
+	return "winnat_state"
+}
+
+func (s *ShutdownState) Cleanup() error {
+	var merr *multierror.Error
+	if s.NatCreated {
+		if err := removeNat(); err != nil {
+			merr = multierror.Append(merr, err)
+		}
+	}
+	if err := restoreForwarding(s.ForwardingInterfaces); err != nil {
+		merr = multierror.Append(merr, fmt.Errorf("restore forwarding: %w", err))
+	}
+	return nberrors.FormatErrorOrNil(merr)
+}
@@ -0,0 +1,121 @@
+package winnat
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/hashicorp/go-multierror"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sys/windows"
+	"golang.zx2c4.com/wireguard/windows/tunnel/winipcfg"
+
+	nberrors "github.com/netbirdio/netbird/client/errors"
+)
+
+// listNats returns the WinNAT instances, it fails if WinNAT isn't available on the system
+func listNats() ([]netNat, error) {
+	out, err := powershell("Get-NetNat | Select-Object Name, InternalIPInterfaceAddressPrefix | ConvertTo-Json")
+	if err != nil {
+		return nil, fmt.Errorf("list NAT networks: %w", err)
+	}
+	return parseNetNats(out)
+}
+
+// createNat creates the WinNAT instance masquerading the traffic of the network
+func createNat(network netip.Prefix) error {
+	script := fmt.Sprintf("New-NetNat -Name '%s' -InternalIPInterfaceAddressPrefix '%s' | Out-Null", NatName, network.Masked())
+	if _, err := powershell(script); err != nil {
+		return fmt.Errorf("create NAT network %s: %w", network, err)
+	}
+	log.Infof("created the WinNAT instance %s for %s", NatName, network)
+	return nil
+}
+
+// removeNat removes the WinNAT instance of the client if it exists
+func removeNat() error {
+	script := fmt.Sprintf("Get-NetNat -Name '%s' -ErrorAction SilentlyContinue | Remove-NetNat -Confirm:$false", NatName)
+	if _, err := powershell(script); err != nil {
+		return fmt.Errorf("remove NAT network: %w", err)
+	}
+	log.Infof("removed the WinNAT instance %s", NatName)
+	return nil
+}
+
+// enableForwarding enables the IPv4 forwarding on all interfaces and returns the LUIDs of the interfaces it was
+// disabled on. Windows forwards a packet only if the forwarding is enabled on the interface it was received on, the
+// replies from the routed networks are received on the physical interfaces.
+func enableForwarding() ([]uint64, error) {
+	rows, err := winipcfg.GetIPInterfaceTable(windows.AF_INET)
+	if err != nil {
+		return nil, fmt.Errorf("get IP interfaces: %w", err)
+	}
+
+	var enabled []uint64
+	var merr *multierror.Error
+	for i := range rows {
+		row := &rows[i]
+		if row.ForwardingEnabled {
+			continue
+		}
+
+		row.ForwardingEnabled = true
+		// the site prefix length must be zero for IPv4 interfaces when setting the row
+		row.SitePrefixLength = 0
+		if err := row.Set(); err != nil {
+			merr = multierror.Append(merr, fmt.Errorf("enable forwarding on interface %d: %w", row.InterfaceIndex, err))
+			continue
+		}
+		enabled = append(enabled, uint64(row.InterfaceLUID))
+	}
+
+	log.Debugf("enabled IPv4 forwarding on %d interfaces", len(enabled))
+	return enabled, nberrors.FormatErrorOrNil(merr)
+}
+
+// restoreForwarding disables the forwarding on the interfaces it was enabled on
+func restoreForwarding(luids []uint64) error {
+	var merr *multierror.Error
+	for _, luid := range luids {
+		row, err := winipcfg.LUID(luid).IPInterface(windows.AF_INET)
+		if err != nil {
+			// the interface is gone, there is nothing to restore
+			log.Debugf("failed to get interface %d: %v", luid, err)
+			continue
+		}
+
+		row.ForwardingEnabled = false
+		row.SitePrefixLength = 0
+		if err := row.Set(); err != nil {
+			merr = multierror.Append(merr, fmt.Errorf("disable forwarding on interface %d: %w", row.InterfaceIndex, err))
+		}
+	}
+	return nberrors.FormatErrorOrNil(merr)
+}
+
+func powershell(script string) ([]byte, error) {
+	cmd := exec.Command(powershellPath(), "-NoProfile", "-NonInteractive", "-Command", script)
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+func powershellPath() string {
+	if path, err := exec.LookPath("powershell.exe"); err == nil {
+		return path
+	}
+	return filepath.Join(os.Getenv("SystemRoot"), "System32", "WindowsPowerShell", "v1.0", "powershell.exe")
+}
@@ -201,6 +201,48 @@ func (c *KernelConfigurer) configure(config wgtypes.Config) error {
 func (c *KernelConfigurer) Close() {
 }
 
+// CheckDevice returns an error if the WireGuard device doesn't exist anymore
+func (c *KernelConfigurer) CheckDevice() error {
+	wg, err := wgctrl.New()
+	if err != nil {
+		return fmt.Errorf("wgctl: %w", err)
+	}
+	defer func() {
+		if err := wg.Close(); err != nil {
+			log.Errorf("Got error while closing wgctl: %v", err)
+		}
+	}()
+
+	if _, err := wg.Device(c.deviceName); err != nil {
+		return fmt.Errorf("get device %s: %w", c.deviceName, err)
+	}
+	return nil
+}
+
+// ListPeers returns the public keys of the peers configured on the WireGuard device
+func (c *KernelConfigurer) ListPeers() ([]string, error) {
+	wg, err := wgctrl.New()
+	if err != nil {
+		return nil, fmt.Errorf("wgctl: %w", err)
+	}
+	defer func() {
+		if err := wg.Close(); err != nil {
+			log.Errorf("Got error while closing wgctl: %v", err)
+		}
+	}()
+
+	wgDevice, err := wg.Device(c.deviceName)
+	if err != nil {
+		return nil, fmt.Errorf("get device %s: %w", c.deviceName, err)
+	}
+
+	peers := make([]string, 0, len(wgDevice.Peers))
+	for _, peer := range wgDevice.Peers {
+		peers = append(peers, peer.PublicKey.String())
+	}
+	return peers, nil
+}
+
 func (c *KernelConfigurer) GetStats(peerKey string) (WGStats, error) {
 	peer, err := c.getPeer(c.deviceName, peerKey)
 	if err != nil {
@@ -217,6 +217,24 @@ func (t *WGUSPConfigurer) Close() {
 	}
 }
 
+// CheckDevice returns an error if the WireGuard device doesn't answer the UAPI requests anymore
+func (t *WGUSPConfigurer) CheckDevice() error {
+	if _, err := t.device.IpcGet(); err != nil {
+		return fmt.Errorf("ipc get: %w", err)
+	}
+	return nil
+}
+
+// ListPeers returns the public keys of the peers configured on the WireGuard device
+func (t *WGUSPConfigurer) ListPeers() ([]string, error) {
+	ipc, err := t.device.IpcGet()
+	if err != nil {
+		return nil, fmt.Errorf("ipc get: %w", err)
+	}
+
+	return findPeerKeys(ipc)
+}
+
 func (t *WGUSPConfigurer) GetStats(peerKey string) (WGStats, error) {
 	ipc, err := t.device.IpcGet()
 	if err != nil {
@@ -257,6 +275,27 @@ func (t *WGUSPConfigurer) GetStats(peerKey string) (WGStats, error) {
 	}, nil
 }
 
+// findPeerKeys returns the public keys of the peers of the UAPI output
+func findPeerKeys(ipcInput string) ([]string, error) {
+	var peers []string
+	for _, line := range strings.Split(ipcInput, "\n") {
+		hexKey, ok := strings.CutPrefix(strings.TrimSpace(line), "public_key=")
+		if !ok {
+			continue
+		}
+		key, err := hex.DecodeString(hexKey)
+		if err != nil {
+			return nil, fmt.Errorf("decode public key: %w", err)
+		}
+		peerKey, err := wgtypes.NewKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("parse public key: %w", err)
+		}
+		peers = append(peers, peerKey.String())
+	}
+	return peers, nil
+}
+
 func findPeerInfo(ipcInput string, peerKey string, searchConfigKeys []string) (map[string]string, error) {
 	peerKeyParsed, err := wgtypes.ParseKey(peerKey)
 	if err != nil {
@@ -357,7 +396,7 @@ func toWgUserspaceString(wgCfg wgtypes.Config) string {
 
 func getFwmark() int {
 	if nbnet.AdvancedRouting() {
-		return nbnet.NetbirdFwmark
+		return int(nbnet.NetbirdFwmark)
 	}
 	return 0
 }
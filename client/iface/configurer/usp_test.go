@@ -102,3 +102,23 @@ func Test_findPeerInfo(t *testing.T) {
 		})
 	}
 }
+
+func Test_findPeerKeys(t *testing.T) {
+	keys, err := findPeerKeys(ipcFixture)
+	require.NoError(t, err)
+
+	var hexKeys []string
+	for _, key := range keys {
+		parsed, err := wgtypes.ParseKey(key)
+		require.NoError(t, err)
+		hexKeys = append(hexKeys, hex.EncodeToString(parsed[:]))
+	}
+	assert.Equal(t, []string{
+		"b85996fecc9c7f1fc6d2572a76eda11d59bcd20be8e543b15ce4bd85a8e75a33",
+		"58402e695ba1772b1cc9309755f043251ea77fdcf10fbe63989ceb7e19321376",
+		"662e14fd594556f522604703340351258903b64f35553763f19426ab2a515c58",
+	}, hexKeys)
+
+	_, err = findPeerKeys("public_key=zz")
+	assert.Error(t, err)
+}
@@ -33,6 +33,9 @@ type TunKernelDevice struct {
 	udpMux     *bind.UniversalUDPMuxDefault
 
 	filterFn bind.FilterFn
+
+	// reuse keeps the interface released by the previous engine instead of recreating it
+	reuse bool
 }
 
 func NewKernelDevice(name string, address wgaddr.Address, wgPort int, key string, mtu int, transportNet transport.Net) *TunKernelDevice {
@@ -52,7 +55,11 @@ func NewKernelDevice(name string, address wgaddr.Address, wgPort int, key string
 func (t *TunKernelDevice) Create() (WGConfigurer, error) {
 	link := newWGLink(t.name)
 
-	if err := link.recreate(); err != nil {
+	if t.reuse {
+		if err := link.reuse(); err != nil {
+			return nil, fmt.Errorf("reuse: %w", err)
+		}
+	} else if err := link.recreate(); err != nil {
 		return nil, fmt.Errorf("recreate: %w", err)
 	}
 
@@ -62,6 +69,13 @@ func (t *TunKernelDevice) Create() (WGConfigurer, error) {
 		return nil, fmt.Errorf("assign addr: %w", err)
 	}
 
+	// assigning the address brings the link up, the peers of a reused interface must wait for the firewall
+	if t.reuse {
+		if err := link.down(); err != nil {
+			return nil, fmt.Errorf("set down: %w", err)
+		}
+	}
+
 	// TODO: do a MTU discovery
 	log.Debugf("setting MTU: %d interface: %s", t.mtu, t.name)
 
@@ -147,6 +161,46 @@ func (t *TunKernelDevice) Close() error {
 	return closErr
 }
 
+// Reuse makes Create keep the existing interface with its WireGuard peers and their sessions. The address, the MTU,
+// the key and the listen port are applied to it.
+func (t *TunKernelDevice) Reuse() {
+	t.reuse = true
+}
+
+// Release closes the resources of the device but keeps the interface with its WireGuard peers for the next device
+// reusing it. The interface is set down so no traffic passes until the next device brings it up with its firewall.
+func (t *TunKernelDevice) Release() error {
+	if t.link == nil {
+		return nil
+	}
+
+	t.ctxCancel()
+
+	var closErr error
+	if err := t.link.down(); err != nil {
+		log.Debugf("failed to set link down: %s", err)
+		closErr = err
+	}
+
+	if t.udpMux != nil {
+		if err := t.udpMux.Close(); err != nil {
+			log.Debugf("failed to close udp mux: %s", err)
+			closErr = err
+		}
+		t.udpMux = nil
+	}
+
+	if t.udpMuxConn != nil {
+		if err := t.udpMuxConn.Close(); err != nil {
+			log.Debugf("failed to close udp mux connection: %s", err)
+			closErr = err
+		}
+		t.udpMuxConn = nil
+	}
+
+	return closErr
+}
+
 func (t *TunKernelDevice) WgAddress() wgaddr.Address {
 	return t.address
 }
@@ -5,6 +5,7 @@ package device
 
 import (
 	"fmt"
+	"net/netip"
 
 	log "github.com/sirupsen/logrus"
 	"golang.zx2c4.com/wireguard/device"
@@ -32,7 +33,8 @@ type TunNetstackDevice struct {
 	udpMux         *bind.UniversalUDPMuxDefault
 	configurer     WGConfigurer
 
-	net *netstack.Net
+	net    *netstack.Net
+	routes *nbnetstack.RouteTable
 }
 
 func NewNetstackDevice(name string, address wgaddr.Address, wgPort int, key string, mtu int, iceBind *bind.ICEBind, listenAddress string) *TunNetstackDevice {
@@ -44,6 +46,7 @@ func NewNetstackDevice(name string, address wgaddr.Address, wgPort int, key stri
 		mtu:           mtu,
 		listenAddress: listenAddress,
 		iceBind:       iceBind,
+		routes:        nbnetstack.NewRouteTable(networkPrefix(address)),
 	}
 }
 
@@ -53,7 +56,7 @@ func (t *TunNetstackDevice) Create() (WGConfigurer, error) {
 	// TODO: get from service listener runtime IP
 	dnsAddr := nbnet.GetLastIPFromNetwork(t.address.Network, 1)
 	log.Debugf("netstack using address: %s", t.address.IP)
-	t.nsTun = nbnetstack.NewNetStackTun(t.listenAddress, t.address.IP, dnsAddr, t.mtu)
+	t.nsTun = nbnetstack.NewNetStackTun(t.listenAddress, t.address.IP, dnsAddr, t.mtu, t.routes)
 	log.Debugf("netstack using dns address: %s", dnsAddr)
 	tunIface, net, err := t.nsTun.Create()
 	if err != nil {
@@ -137,3 +140,21 @@ func (t *TunNetstackDevice) Device() *device.Device {
 func (t *TunNetstackDevice) GetNet() *netstack.Net {
 	return t.net
 }
+
+// RouteTable returns the prefixes routed through the netstack
+func (t *TunNetstackDevice) RouteTable() *nbnetstack.RouteTable {
+	return t.routes
+}
+
+func networkPrefix(address wgaddr.Address) netip.Prefix {
+	if address.Network == nil {
+		return netip.Prefix{}
+	}
+
+	addr, ok := netip.AddrFromSlice(address.Network.IP)
+	if !ok {
+		return netip.Prefix{}
+	}
+	ones, _ := address.Network.Mask.Size()
+	return netip.PrefixFrom(addr.Unmap(), ones)
+}
@@ -17,4 +17,6 @@ type WGConfigurer interface {
 	RemoveAllowedIP(peerKey string, allowedIP string) error
 	Close()
 	GetStats(peerKey string) (configurer.WGStats, error)
+	CheckDevice() error
+	ListPeers() ([]string, error)
 }
@@ -41,6 +41,15 @@ func (l *wgLink) recreate() error {
 	return nil
 }
 
+// reuse keeps the existing interface with its WireGuard peers, it creates the interface if it doesn't exist
+func (l *wgLink) reuse() error {
+	if err := l.link.Reuse(); err != nil {
+		return fmt.Errorf("reuse: %w", err)
+	}
+
+	return nil
+}
+
 func (l *wgLink) setMTU(mtu int) error {
 	if err := l.link.SetMTU(mtu); err != nil {
 		return fmt.Errorf("set mtu: %w", err)
@@ -57,6 +66,14 @@ func (l *wgLink) up() error {
 	return nil
 }
 
+func (l *wgLink) down() error {
+	if err := l.link.Down(); err != nil {
+		return fmt.Errorf("down: %w", err)
+	}
+
+	return nil
+}
+
 func (l *wgLink) assignAddr(address wgaddr.Address) error {
 	link, err := freebsd.LinkByName(l.name)
 	if err != nil {
@@ -73,6 +73,25 @@ func (l *wgLink) recreate() error {
 	return nil
 }
 
+// reuse keeps the existing interface with its WireGuard peers, it creates the interface if it doesn't exist
+func (l *wgLink) reuse() error {
+	link, err := netlink.LinkByName(l.attrs.Name)
+	if err != nil {
+		if _, ok := err.(netlink.LinkNotFoundError); ok {
+			return l.recreate()
+		}
+		return fmt.Errorf("link by name: %w", err)
+	}
+
+	if link.Type() != l.Type() {
+		log.Infof("interface %s isn't a WireGuard interface, recreating it", l.attrs.Name)
+		return l.recreate()
+	}
+
+	log.Infof("reusing the WireGuard interface %s", l.attrs.Name)
+	return nil
+}
+
 func (l *wgLink) setMTU(mtu int) error {
 	if err := netlink.LinkSetMTU(l, mtu); err != nil {
 		log.Errorf("error setting MTU on interface: %s", l.attrs.Name)
@@ -92,6 +111,14 @@ func (l *wgLink) up() error {
 	return nil
 }
 
+func (l *wgLink) down() error {
+	if err := netlink.LinkSetDown(l); err != nil {
+		return fmt.Errorf("link set down: %w", err)
+	}
+
+	return nil
+}
+
 func (l *wgLink) assignAddr(address wgaddr.Address) error {
 	//delete existing addresses
 	list, err := netlink.AddrList(l, 0)
@@ -64,6 +64,20 @@ func (l *Link) Recreate() error {
 	return l.Add()
 }
 
+// Reuse creates a new network interface unless it exists already
+func (l *Link) Reuse() error {
+	ok, err := l.isExist()
+	if err != nil {
+		return fmt.Errorf("is exist: %w", err)
+	}
+
+	if ok {
+		return nil
+	}
+
+	return l.Add()
+}
+
 // Add creates a new network interface.
 func (l *Link) Add() error {
 	parsedName, err := l.create(wgIFGroup)
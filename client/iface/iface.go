@@ -19,6 +19,7 @@ import (
 	"github.com/netbirdio/netbird/client/iface/bind"
 	"github.com/netbirdio/netbird/client/iface/configurer"
 	"github.com/netbirdio/netbird/client/iface/device"
+	nbnetstack "github.com/netbirdio/netbird/client/iface/netstack"
 	"github.com/netbirdio/netbird/client/iface/wgaddr"
 	"github.com/netbirdio/netbird/client/iface/wgproxy"
 )
@@ -29,6 +30,11 @@ const (
 	WgInterfaceDefault = configurer.WgInterfaceDefault
 )
 
+// releaser is a device whose interface can outlive it
+type releaser interface {
+	Release() error
+}
+
 type wgProxyFactory interface {
 	GetProxy() wgproxy.Proxy
 	Free() error
@@ -43,6 +49,8 @@ type WGIFaceOpts struct {
 	MobileArgs   *device.MobileIFaceArguments
 	TransportNet transport.Net
 	FilterFn     bind.FilterFn
+	// ReuseInterface takes over the kernel interface released by the previous engine with its WireGuard peers
+	ReuseInterface bool
 }
 
 // WGIface represents an interface instance
@@ -217,6 +225,65 @@ func (w *WGIface) GetStats(peerKey string) (configurer.WGStats, error) {
 	return w.configurer.GetStats(peerKey)
 }
 
+// CheckDevice returns an error if the tunnel interface disappeared or the WireGuard device doesn't respond
+func (w *WGIface) CheckDevice() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.configurer == nil {
+		return fmt.Errorf("interface %s not created", w.Name())
+	}
+
+	if !nbnetstack.IsEnabled() {
+		if _, err := net.InterfaceByName(w.Name()); err != nil {
+			return fmt.Errorf("interface %s: %w", w.Name(), err)
+		}
+	}
+
+	return w.configurer.CheckDevice()
+}
+
+// ListPeers returns the public keys of the peers configured on the WireGuard device
+func (w *WGIface) ListPeers() ([]string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.configurer == nil {
+		return nil, fmt.Errorf("interface %s not created", w.Name())
+	}
+	return w.configurer.ListPeers()
+}
+
+// Releasable checks if the interface can be released instead of closed
+func (w *WGIface) Releasable() bool {
+	_, ok := w.tun.(releaser)
+	return ok
+}
+
+// Release closes the interface but keeps the kernel WireGuard device with its peers and their sessions, an interface
+// created with ReuseInterface takes it over. Close removes the released device if no interface takes it over.
+func (w *WGIface) Release() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	tun, ok := w.tun.(releaser)
+	if !ok {
+		return fmt.Errorf("interface %s can't be released", w.Name())
+	}
+
+	var result *multierror.Error
+
+	if err := w.wgProxyFactory.Free(); err != nil {
+		result = multierror.Append(result, fmt.Errorf("failed to free WireGuard proxy: %w", err))
+	}
+
+	if err := tun.Release(); err != nil {
+		result = multierror.Append(result, fmt.Errorf("failed to release wireguard interface %s: %w", w.Name(), err))
+	}
+
+	return errors.FormatErrorOrNil(result)
+}
+
 func (w *WGIface) waitUntilRemoved() error {
 	maxWaitTime := 5 * time.Second
 	timeout := time.NewTimer(maxWaitTime)
@@ -252,6 +319,18 @@ func (w *WGIface) GetNet() *netstack.Net {
 	return w.tun.GetNet()
 }
 
+// GetNetstackRoutes returns the route table of the netstack device, nil if the interface doesn't run in netstack mode
+func (w *WGIface) GetNetstackRoutes() *nbnetstack.RouteTable {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	ns, ok := w.tun.(interface{ RouteTable() *nbnetstack.RouteTable })
+	if !ok {
+		return nil
+	}
+	return ns.RouteTable()
+}
+
 func prefixesToIPNets(prefixes []netip.Prefix) []net.IPNet {
 	ipNets := make([]net.IPNet, len(prefixes))
 	for i, prefix := range prefixes {
@@ -2,7 +2,9 @@ package netstack
 
 import (
 	"context"
+	"fmt"
 	"net"
+	"net/netip"
 
 	log "github.com/sirupsen/logrus"
 	"golang.zx2c4.com/wireguard/tun/netstack"
@@ -13,20 +15,62 @@ type Dialer interface {
 }
 
 type NSDialer struct {
-	net *netstack.Net
+	net    *netstack.Net
+	routes *RouteTable
+	direct net.Dialer
 }
 
-func NewNSDialer(net *netstack.Net) *NSDialer {
+// NewNSDialer returns a dialer that connects to the routed destinations through the netstack and to the other
+// destinations through the host network. All destinations go through the netstack if routes is nil.
+func NewNSDialer(net *netstack.Net, routes *RouteTable) *NSDialer {
 	return &NSDialer{
-		net: net,
+		net:    net,
+		routes: routes,
 	}
 }
 
 func (d *NSDialer) Dial(ctx context.Context, network, addr string) (net.Conn, error) {
-	log.Debugf("dialing %s %s", network, addr)
-	conn, err := d.net.Dial(network, addr)
+	target, routed, err := d.resolve(ctx, addr)
+	if err != nil {
+		log.Debugf("failed to resolve %s: %s", addr, err)
+		return nil, err
+	}
+
+	if !routed {
+		log.Debugf("dialing %s %s through the host network", network, addr)
+		return d.direct.DialContext(ctx, network, addr)
+	}
+
+	log.Debugf("dialing %s %s", network, target)
+	conn, err := d.net.DialContext(ctx, network, target)
 	if err != nil {
 		log.Debugf("failed to deal connection: %s", err)
 	}
 	return conn, err
 }
+
+// resolve returns the address to dial through the netstack and whether the destination is routed through it
+func (d *NSDialer) resolve(ctx context.Context, addr string) (string, bool, error) {
+	if d.routes == nil {
+		return addr, true, nil
+	}
+
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", false, fmt.Errorf("split host port: %w", err)
+	}
+
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+		if err != nil {
+			return "", false, fmt.Errorf("lookup %s: %w", host, err)
+		}
+		if len(ips) == 0 {
+			return "", false, fmt.Errorf("lookup %s: no addresses", host)
+		}
+		ip = ips[0]
+	}
+
+	return net.JoinHostPort(ip.Unmap().String(), port), d.routes.IsRouted(ip), nil
+}
@@ -0,0 +1,194 @@
+package netstack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// EnvPortForwards configures the local ports forwarded to destinations behind the tunnel, as a comma separated
+// list of [listen-host:]listen-port:target-host:target-port. It makes the routes usable for applications without
+// SOCKS5 support.
+const EnvPortForwards = "NB_NETSTACK_PORT_FORWARDS"
+
+// PortForward forwards the TCP connections of a local port to a target address
+type PortForward struct {
+	ListenAddr string
+	TargetAddr string
+}
+
+// PortForwards returns the port forwards configured by the environment
+func PortForwards() ([]PortForward, error) {
+	return parsePortForwards(os.Getenv(EnvPortForwards))
+}
+
+func parsePortForwards(value string) ([]PortForward, error) {
+	var forwards []PortForward
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		forward, err := parsePortForward(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port forward %q: %w", entry, err)
+		}
+		forwards = append(forwards, forward)
+	}
+	return forwards, nil
+}
+
+func parsePortForward(entry string) (PortForward, error) {
+	i := strings.LastIndex(entry, ":")
+	if i == -1 {
+		return PortForward{}, errors.New("missing target port")
+	}
+	rest, targetPort := entry[:i], entry[i+1:]
+
+	var targetHost string
+	if strings.HasSuffix(rest, "]") {
+		j := strings.LastIndex(rest, "[")
+		if j < 1 || rest[j-1] != ':' {
+			return PortForward{}, errors.New("invalid target host")
+		}
+		targetHost, rest = rest[j+1:len(rest)-1], rest[:j-1]
+	} else {
+		j := strings.LastIndex(rest, ":")
+		if j == -1 {
+			return PortForward{}, errors.New("expected [listen-host:]listen-port:target-host:target-port")
+		}
+		targetHost, rest = rest[j+1:], rest[:j]
+	}
+
+	// the forwards listen on the loopback address unless a listen host is given
+	listenHost, listenPort := "127.0.0.1", rest
+	if j := strings.LastIndex(rest, ":"); j != -1 {
+		listenHost, listenPort = strings.Trim(rest[:j], "[]"), rest[j+1:]
+	}
+
+	for _, port := range []string{listenPort, targetPort} {
+		p, err := strconv.Atoi(port)
+		if err != nil || p < 1 || p > 65535 {
+			return PortForward{}, fmt.Errorf("invalid port %q", port)
+		}
+	}
+	if targetHost == "" {
+		return PortForward{}, errors.New("missing target host")
+	}
+
+	return PortForward{
+		ListenAddr: net.JoinHostPort(listenHost, listenPort),
+		TargetAddr: net.JoinHostPort(targetHost, targetPort),
+	}, nil
+}
+
+// Forwarder serves the port forwards through the dialer of the netstack
+type Forwarder struct {
+	dialer Dialer
+
+	mu        sync.Mutex
+	listeners []net.Listener
+	ctx       context.Context
+	cancel    context.CancelFunc
+}
+
+func NewForwarder(dialer Dialer) *Forwarder {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Forwarder{
+		dialer: dialer,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Start listens on the local ports of the forwards
+func (f *Forwarder) Start(forwards []PortForward) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, forward := range forwards {
+		listener, err := net.Listen("tcp", forward.ListenAddr)
+		if err != nil {
+			return fmt.Errorf("listen on %s: %w", forward.ListenAddr, err)
+		}
+		f.listeners = append(f.listeners, listener)
+
+		log.Infof("forwarding %s to %s", forward.ListenAddr, forward.TargetAddr)
+		go f.serve(listener, forward.TargetAddr)
+	}
+	return nil
+}
+
+func (f *Forwarder) serve(listener net.Listener, target string) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if f.ctx.Err() == nil {
+				log.Errorf("failed to accept connection on %s: %s", listener.Addr(), err)
+			}
+			return
+		}
+
+		go f.forward(conn, target)
+	}
+}
+
+func (f *Forwarder) forward(conn net.Conn, target string) {
+	defer func() {
+		if err := conn.Close(); err != nil {
+			log.Debugf("failed to close forwarded connection: %s", err)
+		}
+	}()
+
+	targetConn, err := f.dialer.Dial(f.ctx, "tcp", target)
+	if err != nil {
+		log.Debugf("failed to dial %s: %s", target, err)
+		return
+	}
+	defer func() {
+		if err := targetConn.Close(); err != nil {
+			log.Debugf("failed to close target connection: %s", err)
+		}
+	}()
+
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(targetConn, conn)
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(conn, targetConn)
+		done <- struct{}{}
+	}()
+
+	select {
+	case <-done:
+	case <-f.ctx.Done():
+	}
+}
+
+// Close stops listening on the local ports and closes the forwarded connections
+func (f *Forwarder) Close() error {
+	f.cancel()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var merr error
+	for _, listener := range f.listeners {
+		if err := listener.Close(); err != nil {
+			merr = errors.Join(merr, err)
+		}
+	}
+	f.listeners = nil
+	return merr
+}
@@ -0,0 +1,73 @@
+package netstack
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePortForwards(t *testing.T) {
+	forwards, err := parsePortForwards("8080:10.0.0.5:80, 0.0.0.0:2222:db.netbird.cloud:22,5353:[fd00::1]:53")
+	require.NoError(t, err)
+	assert.Equal(t, []PortForward{
+		{ListenAddr: "127.0.0.1:8080", TargetAddr: "10.0.0.5:80"},
+		{ListenAddr: "0.0.0.0:2222", TargetAddr: "db.netbird.cloud:22"},
+		{ListenAddr: "127.0.0.1:5353", TargetAddr: "[fd00::1]:53"},
+	}, forwards)
+
+	forwards, err = parsePortForwards("")
+	require.NoError(t, err)
+	assert.Empty(t, forwards)
+
+	for _, value := range []string{"8080", "8080:10.0.0.5", "8080::80", "0:10.0.0.5:80", "8080:10.0.0.5:http"} {
+		_, err := parsePortForwards(value)
+		assert.Error(t, err, value)
+	}
+}
+
+type hostDialer struct{}
+
+func (hostDialer) Dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	var d net.Dialer
+	return d.DialContext(ctx, network, addr)
+}
+
+func TestForwarder(t *testing.T) {
+	target, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer target.Close()
+
+	go func() {
+		conn, err := target.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = io.Copy(conn, conn)
+	}()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	listenAddr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	forwarder := NewForwarder(hostDialer{})
+	defer forwarder.Close()
+	require.NoError(t, forwarder.Start([]PortForward{{ListenAddr: listenAddr, TargetAddr: target.Addr().String()}}))
+
+	conn, err := net.Dial("tcp", listenAddr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("ping"))
+	require.NoError(t, err)
+
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(conn, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(buf))
+}
@@ -0,0 +1,69 @@
+package netstack
+
+import (
+	"net/netip"
+	"sync"
+)
+
+// RouteTable holds the prefixes reachable through the netstack: the NetBird network and the routes and exit nodes
+// selected by the route manager. Netstack mode has no kernel routing table, the dialer of the proxy and the port
+// forwards use this table to decide whether a destination goes through the tunnel or the host network.
+type RouteTable struct {
+	mu       sync.RWMutex
+	network  netip.Prefix
+	prefixes map[netip.Prefix]struct{}
+}
+
+// NewRouteTable returns a route table that always routes the NetBird network through the netstack
+func NewRouteTable(network netip.Prefix) *RouteTable {
+	return &RouteTable{
+		network:  network.Masked(),
+		prefixes: make(map[netip.Prefix]struct{}),
+	}
+}
+
+// AddRoute routes the prefix through the netstack
+func (t *RouteTable) AddRoute(prefix netip.Prefix) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.prefixes[prefix.Masked()] = struct{}{}
+}
+
+// RemoveRoute stops routing the prefix through the netstack
+func (t *RouteTable) RemoveRoute(prefix netip.Prefix) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.prefixes, prefix.Masked())
+}
+
+// IsRouted returns true if the address belongs to the NetBird network or to one of the routed prefixes
+func (t *RouteTable) IsRouted(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if t.network.IsValid() && t.network.Contains(addr) {
+		return true
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	for prefix := range t.prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Prefixes returns the routed prefixes, without the NetBird network
+func (t *RouteTable) Prefixes() []netip.Prefix {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	prefixes := make([]netip.Prefix, 0, len(t.prefixes))
+	for prefix := range t.prefixes {
+		prefixes = append(prefixes, prefix)
+	}
+	return prefixes
+}
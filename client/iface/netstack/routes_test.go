@@ -0,0 +1,27 @@
+package netstack
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouteTable(t *testing.T) {
+	table := NewRouteTable(netip.MustParsePrefix("100.64.0.0/10"))
+
+	assert.True(t, table.IsRouted(netip.MustParseAddr("100.64.0.5")), "the NetBird network should always be routed")
+	assert.False(t, table.IsRouted(netip.MustParseAddr("10.0.0.5")))
+
+	table.AddRoute(netip.MustParsePrefix("10.0.0.1/16"))
+	assert.True(t, table.IsRouted(netip.MustParseAddr("10.0.0.5")))
+	assert.True(t, table.IsRouted(netip.MustParseAddr("::ffff:10.0.0.5")))
+	assert.Equal(t, []netip.Prefix{netip.MustParsePrefix("10.0.0.0/16")}, table.Prefixes())
+
+	table.RemoveRoute(netip.MustParsePrefix("10.0.0.0/16"))
+	assert.False(t, table.IsRouted(netip.MustParseAddr("10.0.0.5")))
+
+	table.AddRoute(netip.MustParsePrefix("0.0.0.0/0"))
+	assert.True(t, table.IsRouted(netip.MustParseAddr("1.1.1.1")), "an exit node should route all the traffic")
+	assert.False(t, table.IsRouted(netip.MustParseAddr("2001:db8::1")))
+}
@@ -20,16 +20,20 @@ type NetStackTun struct { //nolint:revive
 	mtu           int
 	listenAddress string
 
-	proxy  *Proxy
-	tundev tun.Device
+	routes *RouteTable
+
+	proxy     *Proxy
+	forwarder *Forwarder
+	tundev    tun.Device
 }
 
-func NewNetStackTun(listenAddress string, address net.IP, dnsAddress net.IP, mtu int) *NetStackTun {
+func NewNetStackTun(listenAddress string, address net.IP, dnsAddress net.IP, mtu int, routes *RouteTable) *NetStackTun {
 	return &NetStackTun{
 		address:       address,
 		dnsAddress:    dnsAddress,
 		mtu:           mtu,
 		listenAddress: listenAddress,
+		routes:        routes,
 	}
 }
 
@@ -53,6 +57,12 @@ func (t *NetStackTun) Create() (tun.Device, *netstack.Net, error) {
 	}
 	t.tundev = nsTunDev
 
+	dialer := NewNSDialer(tunNet, t.routes)
+	if err := t.startForwarder(dialer); err != nil {
+		_ = t.tundev.Close()
+		return nil, nil, err
+	}
+
 	skipProxy, err := strconv.ParseBool(os.Getenv(EnvSkipProxy))
 	if err != nil {
 		log.Errorf("failed to parse %s: %s", EnvSkipProxy, err)
@@ -61,10 +71,9 @@ func (t *NetStackTun) Create() (tun.Device, *netstack.Net, error) {
 		return nsTunDev, tunNet, nil
 	}
 
-	dialer := NewNSDialer(tunNet)
 	t.proxy, err = NewSocks5(dialer)
 	if err != nil {
-		_ = t.tundev.Close()
+		_ = t.Close()
 		return nil, nil, err
 	}
 
@@ -78,8 +87,34 @@ func (t *NetStackTun) Create() (tun.Device, *netstack.Net, error) {
 	return nsTunDev, tunNet, nil
 }
 
+func (t *NetStackTun) startForwarder(dialer Dialer) error {
+	forwards, err := PortForwards()
+	if err != nil {
+		return err
+	}
+	if len(forwards) == 0 {
+		return nil
+	}
+
+	t.forwarder = NewForwarder(dialer)
+	if err := t.forwarder.Start(forwards); err != nil {
+		_ = t.forwarder.Close()
+		t.forwarder = nil
+		return fmt.Errorf("start port forwards: %w", err)
+	}
+	return nil
+}
+
 func (t *NetStackTun) Close() error {
 	var err error
+	if t.forwarder != nil {
+		fErr := t.forwarder.Close()
+		if fErr != nil {
+			log.Errorf("failed to close port forwards: %s", fErr)
+			err = fErr
+		}
+	}
+
 	if t.proxy != nil {
 		pErr := t.proxy.Close()
 		if pErr != nil {
@@ -0,0 +1,60 @@
+package acl
+
+import (
+	"net/netip"
+	"slices"
+
+	mgmProto "github.com/netbirdio/netbird/management/proto"
+)
+
+// RouteAllowed reports if the route firewall rules let the peer with the NetBird IP src reach the TCP port of a host
+// of the routed networks. The drop rules take precedence, the rules of the dynamic routes are ignored as the
+// destination can't be matched to their domains.
+func RouteAllowed(rules []*mgmProto.RouteFirewallRule, src netip.Addr, dst netip.AddrPort) bool {
+	allowed := false
+	for _, rule := range rules {
+		if rule.GetIsDynamic() || !routeRuleMatches(rule, src, dst) {
+			continue
+		}
+		switch rule.GetAction() {
+		case mgmProto.RuleAction_DROP:
+			return false
+		case mgmProto.RuleAction_ACCEPT:
+			allowed = true
+		}
+	}
+	return allowed
+}
+
+func routeRuleMatches(rule *mgmProto.RouteFirewallRule, src netip.Addr, dst netip.AddrPort) bool {
+	if rule.GetProtocol() != mgmProto.RuleProtocol_ALL && rule.GetProtocol() != mgmProto.RuleProtocol_TCP {
+		return false
+	}
+
+	destination, err := netip.ParsePrefix(rule.GetDestination())
+	if err != nil || !destination.Contains(dst.Addr()) {
+		return false
+	}
+
+	if !slices.ContainsFunc(rule.GetSourceRanges(), func(sourceRange string) bool {
+		source, err := netip.ParsePrefix(sourceRange)
+		return err == nil && source.Contains(src)
+	}) {
+		return false
+	}
+
+	return portInfoMatches(rule.GetPortInfo(), dst.Port())
+}
+
+func portInfoMatches(portInfo *mgmProto.PortInfo, port uint16) bool {
+	switch {
+	case portInfo == nil:
+		return true
+	case portInfo.GetPort() != 0:
+		return portInfo.GetPort() == uint32(port)
+	case portInfo.GetRange() != nil:
+		return uint32(port) >= portInfo.GetRange().GetStart() && uint32(port) <= portInfo.GetRange().GetEnd()
+	default:
+		return true
+	}
+}
@@ -0,0 +1,63 @@
+package acl
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	mgmProto "github.com/netbirdio/netbird/management/proto"
+)
+
+func TestRouteAllowed(t *testing.T) {
+	src := netip.MustParseAddr("100.64.0.10")
+	rules := []*mgmProto.RouteFirewallRule{
+		{
+			SourceRanges: []string{"100.64.0.0/24"},
+			Action:       mgmProto.RuleAction_ACCEPT,
+			Destination:  "10.1.2.0/24",
+			Protocol:     mgmProto.RuleProtocol_TCP,
+			PortInfo:     &mgmProto.PortInfo{PortSelection: &mgmProto.PortInfo_Port{Port: 22}},
+		},
+		{
+			SourceRanges: []string{"100.64.0.10/32"},
+			Action:       mgmProto.RuleAction_ACCEPT,
+			Destination:  "10.1.3.0/24",
+			Protocol:     mgmProto.RuleProtocol_ALL,
+		},
+		{
+			SourceRanges: []string{"100.64.0.0/24"},
+			Action:       mgmProto.RuleAction_DROP,
+			Destination:  "10.1.3.5/32",
+			Protocol:     mgmProto.RuleProtocol_ALL,
+		},
+		{
+			SourceRanges: []string{"100.64.0.0/24"},
+			Action:       mgmProto.RuleAction_ACCEPT,
+			Destination:  "0.0.0.0/0",
+			Protocol:     mgmProto.RuleProtocol_ALL,
+			IsDynamic:    true,
+			Domains:      []string{"example.com"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		src     netip.Addr
+		dst     string
+		allowed bool
+	}{
+		{name: "allowed port", src: src, dst: "10.1.2.3:22", allowed: true},
+		{name: "other port", src: src, dst: "10.1.2.3:2222", allowed: false},
+		{name: "other source", src: netip.MustParseAddr("100.64.1.10"), dst: "10.1.2.3:22", allowed: false},
+		{name: "all protocols", src: src, dst: "10.1.3.4:2222", allowed: true},
+		{name: "dropped host", src: src, dst: "10.1.3.5:22", allowed: false},
+		{name: "dynamic route", src: src, dst: "192.0.2.1:22", allowed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.allowed, RouteAllowed(rules, tt.src, netip.MustParseAddrPort(tt.dst)))
+		})
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"net/netip"
 	"net/url"
 	"os"
+	"path/filepath"
 	"reflect"
 	"runtime"
 	"slices"
@@ -17,12 +19,19 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	firewallManager "github.com/netbirdio/netbird/client/firewall/manager"
 	"github.com/netbirdio/netbird/client/iface"
+	"github.com/netbirdio/netbird/client/internal/langateway"
+	"github.com/netbirdio/netbird/client/internal/routelearn"
 	"github.com/netbirdio/netbird/client/internal/routemanager/dynamic"
+	"github.com/netbirdio/netbird/client/internal/routemanager/splittunnel"
+	"github.com/netbirdio/netbird/client/internal/routermode"
 	"github.com/netbirdio/netbird/client/ssh"
 	mgm "github.com/netbirdio/netbird/management/client"
 	"github.com/netbirdio/netbird/management/domain"
+	mgmProto "github.com/netbirdio/netbird/management/proto"
 	"github.com/netbirdio/netbird/util"
+	nbnet "github.com/netbirdio/netbird/util/net"
 )
 
 const (
@@ -38,6 +47,26 @@ const (
 	DefaultAdminURL = "https://app.netbird.io:443"
 )
 
+const (
+	// RemoteDebugBundlesAsk makes the client ask the local user before uploading the debug bundles requested by the
+	// management service, it's the default
+	RemoteDebugBundlesAsk = "ask"
+	// RemoteDebugBundlesAllow makes the client upload the requested debug bundles without asking
+	RemoteDebugBundlesAllow = "allow"
+	// RemoteDebugBundlesDeny makes the client decline the requested debug bundles
+	RemoteDebugBundlesDeny = "deny"
+)
+
+// Client settings the management service distributes defaults for, the values set locally take precedence
+const (
+	SettingDNS          = "dns"
+	SettingClientRoutes = "clientRoutes"
+	SettingFirewall     = "firewall"
+)
+
+// MaxRouteDrainPeriod is the longest time a routing peer waits for the established flows on shutdown
+const MaxRouteDrainPeriod = 5 * time.Minute
+
 var defaultInterfaceBlacklist = []string{
 	iface.WgInterfaceDefault, "wt", "utun", "tun0", "zt", "ZeroTier", "wg", "ts",
 	"Tailscale", "tailscale", "docker", "veth", "br-", "lo",
@@ -73,7 +102,75 @@ type ConfigInput struct {
 
 	DisableNotifications *bool
 
+	// RemoteDebugBundles is one of RemoteDebugBundlesAsk, RemoteDebugBundlesAllow or RemoteDebugBundlesDeny
+	RemoteDebugBundles *string
+
+	// CrashReportURL is the endpoint crash reports are submitted to, an empty value disables the submission
+	CrashReportURL *string
+
 	DNSLabels domain.List
+
+	// Uplinks are the uplink interfaces in the order of preference, an empty list disables the uplink tracking
+	Uplinks []string
+
+	// StaticMeshFile is the path of the static mesh file, an empty value disables the static mesh mode
+	StaticMeshFile *string
+
+	// RouterMode runs the client as a gateway appliance
+	RouterMode *bool
+
+	// RouterLANs are the LAN prefixes advertised in router mode, an empty list advertises none
+	RouterLANs []string
+
+	// RouteLearning is the source of the learned routes, an empty value disables the route learning
+	RouteLearning *string
+
+	// RouteLearningFilters select the learned routes advertised to management, an empty list accepts all
+	RouteLearningFilters []string
+
+	// RouteDrainPeriod is the time the routed flows have to finish on shutdown, zero disables the draining
+	RouteDrainPeriod *time.Duration
+
+	// SplitTunnelApps are the binaries whose traffic is routed through the NetBird routes, an empty list clears them
+	SplitTunnelApps []string
+
+	// SplitTunnelUsers are the users whose traffic is routed through the NetBird routes, an empty list clears them
+	SplitTunnelUsers []string
+
+	// Fwmark is the base of the fwmarks used on Linux, zero selects a base not conflicting with other VPNs
+	Fwmark *uint32
+
+	// RoutingTableID is the ID of the routing table of the NetBird routes on Linux, zero selects a table not
+	// conflicting with other VPNs
+	RoutingTableID *int
+
+	// OverlayOnlyServices are the local services reachable only over NetBird, an empty list clears them
+	OverlayOnlyServices []string
+
+	// RequireRouteApproval holds back the high-risk routes until the local user approves them
+	RequireRouteApproval *bool
+
+	// ExitNodeStickyGrace keeps the established flows on the previous exit node after an automatic switch, 0 disables it
+	ExitNodeStickyGrace *time.Duration
+
+	// DNSHostsFile writes the peer records to the hosts file instead of taking over the system DNS
+	DNSHostsFile *bool
+
+	// MaxICENegotiations caps the concurrent ICE negotiations, 0 applies the default
+	MaxICENegotiations *int
+
+	// LANGatewayVIP is the virtual gateway IP shared on the LAN with its prefix, an empty value disables it
+	LANGatewayVIP       *string
+	LANGatewayInterface *string
+	LANGatewayPriority  *int
+	// LANGatewayPeers are the NetBird IPs of the routing peers sharing the virtual gateway IP
+	LANGatewayPeers []string
+
+	// FileTransferDir is the directory the files of the peers are received into, an empty value disables it
+	FileTransferDir *string
+
+	// RemoteActions are the peer actions the management service may run, an empty list declines all of them
+	RemoteActions []string
 }
 
 // Config Configuration type
@@ -97,12 +194,102 @@ type Config struct {
 	DisableDNS          bool
 	DisableFirewall     bool
 
+	// LocalSettings are the client settings set locally, with the flags, the UI or the device policy. The defaults
+	// distributed by the management service apply to the other ones.
+	LocalSettings []string
+
 	BlockLANAccess bool
 
 	DisableNotifications *bool
 
+	// RemoteDebugBundles sets how the debug bundles requested by the management service are handled, the local user
+	// is asked if it's empty
+	RemoteDebugBundles string
+
+	// CrashReportURL is the endpoint the crash reports are submitted to, they're only saved locally if it's empty
+	CrashReportURL string
+
 	DNSLabels domain.List
 
+	// Uplinks are the uplink interfaces of a peer with several internet connections in the order of preference.
+	// The first healthy one carries the control plane and the tunnels, the next ones take over when it fails.
+	Uplinks []string
+
+	// StaticMeshFile is the path of a file defining the peers of the network. If it's set the client runs without
+	// the management and signal services and configures the peers with their static endpoints.
+	StaticMeshFile string
+
+	// RouterMode runs the client as a gateway appliance: the interactive login is disabled, IP forwarding is
+	// enabled, conntrack and the socket buffers are tuned for routing and a read-only status page is served.
+	RouterMode bool
+
+	// RouterLANs are the LAN prefixes behind the appliance advertised to the management service in router mode
+	RouterLANs []string
+
+	// RouteLearning is the source of the routes learned from the routers of the site, like BGP routes installed by
+	// FRR. The learned routes are advertised to the management service as dynamic routes. Empty if disabled.
+	RouteLearning string
+
+	// RouteLearningFilters select the learned routes advertised to the management service
+	RouteLearningFilters []string
+
+	// RouteDrainPeriod is the time the established flows through the routed networks have to finish when the client
+	// stops. The connected peers are asked to switch to another routing peer of the HA group first and the new flows
+	// are dropped. Zero removes the routing rules immediately.
+	RouteDrainPeriod time.Duration
+
+	// SplitTunnelApps and SplitTunnelUsers restrict the routes and exit nodes to the processes of the binaries and
+	// of the users. The traffic of the other processes keeps using the local network. Empty to route every process.
+	SplitTunnelApps  []string
+	SplitTunnelUsers []string
+
+	// Fwmark is the base of the fwmarks used on Linux and RoutingTableID the ID of the routing table of the NetBird
+	// routes. Zero selects values not matched by the ip rules and the routes of other VPNs and of the host policy
+	// routing, sharing them loops the traffic.
+	Fwmark         uint32
+	RoutingTableID int
+
+	// OverlayOnlyServices are the local services, like tcp/22 or tcp/3389, whose inbound traffic is dropped on the
+	// physical interfaces. They're reachable only over NetBird and locally.
+	OverlayOnlyServices []string
+
+	// RequireRouteApproval holds back the default routes and the very large routes pushed by the management service
+	// until the local user approves them, a compromised management can't redirect the traffic through a hostile
+	// exit node of a shared account.
+	RequireRouteApproval bool
+
+	// ExitNodeStickyGrace is the time the established flows stay on the previous exit node after the automatic
+	// exit node selection switched to another one, long-lived downloads and SSH sessions aren't broken by the switch.
+	// The flows switch right away if it's 0.
+	ExitNodeStickyGrace time.Duration
+
+	// DNSHostsFile writes the A and AAAA records of the peers to the hosts file and keeps them updated, instead of
+	// pointing the system DNS to NetBird. It's for the hosts where the system DNS can't be taken over, like containers
+	// or hosts with conflicting resolvers. The records are removed from the hosts file when NetBird stops.
+	DNSHostsFile bool
+
+	// MaxICENegotiations is the number of the ICE negotiations running at once, the negotiations of the routing peers
+	// and the exit nodes are started first. It bounds the CPU and the signal traffic when all the peers of a large
+	// network reconnect, e.g. after a wake from sleep. The default applies if it's 0.
+	MaxICENegotiations int
+
+	// LANGatewayVIP is a virtual gateway IP with its prefix, like 192.168.1.1/24, shared on LANGatewayInterface with
+	// the routing peers of LANGatewayPeers. The healthy peer with the highest LANGatewayPriority holds it and a
+	// backup takes it over within seconds if it fails, so the LAN hosts keep a single next hop for the NetBird
+	// networks. Empty if disabled.
+	LANGatewayVIP       string
+	LANGatewayInterface string
+	LANGatewayPriority  int
+	LANGatewayPeers     []string
+
+	// FileTransferDir is the directory the files sent by the peers with netbird cp are received into, the access
+	// policies decide which peers can send them. Receiving is disabled if it's empty.
+	FileTransferDir string
+
+	// RemoteActions are the actions requested by the management service the peer runs, e.g. restart or rotate_key.
+	// The other actions are declined, the management service can't run any action if it's empty.
+	RemoteActions []string
+
 	// SSHKey is a private SSH key in a PEM format
 	SSHKey string
 
@@ -469,6 +656,21 @@ func (config *Config) apply(input ConfigInput) (updated bool, err error) {
 		updated = true
 	}
 
+	localSettings := []struct {
+		name  string
+		input *bool
+	}{
+		{SettingDNS, input.DisableDNS},
+		{SettingClientRoutes, input.DisableClientRoutes},
+		{SettingFirewall, input.DisableFirewall},
+	}
+	for _, setting := range localSettings {
+		if setting.input != nil && !slices.Contains(config.LocalSettings, setting.name) {
+			config.LocalSettings = append(config.LocalSettings, setting.name)
+			updated = true
+		}
+	}
+
 	if input.BlockLANAccess != nil && *input.BlockLANAccess != config.BlockLANAccess {
 		if *input.BlockLANAccess {
 			log.Infof("blocking LAN access")
@@ -489,6 +691,40 @@ func (config *Config) apply(input ConfigInput) (updated bool, err error) {
 		updated = true
 	}
 
+	if input.RemoteDebugBundles != nil && *input.RemoteDebugBundles != config.RemoteDebugBundles {
+		switch *input.RemoteDebugBundles {
+		case RemoteDebugBundlesAsk, RemoteDebugBundlesAllow, RemoteDebugBundlesDeny:
+		default:
+			return false, fmt.Errorf("invalid remote debug bundles mode %q", *input.RemoteDebugBundles)
+		}
+		log.Infof("setting remote debug bundles to %s", *input.RemoteDebugBundles)
+		config.RemoteDebugBundles = *input.RemoteDebugBundles
+		updated = true
+	}
+
+	if input.CrashReportURL != nil && *input.CrashReportURL != config.CrashReportURL {
+		if *input.CrashReportURL != "" {
+			if _, err := parseURL("Crash report URL", *input.CrashReportURL); err != nil {
+				return false, err
+			}
+			log.Infof("submitting crash reports to %s", *input.CrashReportURL)
+		} else {
+			log.Infof("disabling crash report submission")
+		}
+		config.CrashReportURL = *input.CrashReportURL
+		updated = true
+	}
+
+	if input.StaticMeshFile != nil && *input.StaticMeshFile != config.StaticMeshFile {
+		if *input.StaticMeshFile != "" {
+			log.Infof("running in static mesh mode with the peers of %s", *input.StaticMeshFile)
+		} else {
+			log.Infof("disabling static mesh mode")
+		}
+		config.StaticMeshFile = *input.StaticMeshFile
+		updated = true
+	}
+
 	if config.DisableNotifications == nil {
 		disabled := true
 		config.DisableNotifications = &disabled
@@ -524,9 +760,223 @@ func (config *Config) apply(input ConfigInput) (updated bool, err error) {
 		updated = true
 	}
 
+	if input.Uplinks != nil && !slices.Equal(config.Uplinks, input.Uplinks) {
+		log.Infof("updating uplinks [ %s ] (old value: [ %s ])",
+			strings.Join(input.Uplinks, " "),
+			strings.Join(config.Uplinks, " "))
+		config.Uplinks = input.Uplinks
+		updated = true
+	}
+
+	if input.RouterMode != nil && *input.RouterMode != config.RouterMode {
+		if *input.RouterMode {
+			log.Infof("enabling router mode")
+		} else {
+			log.Infof("disabling router mode")
+		}
+		config.RouterMode = *input.RouterMode
+		updated = true
+	}
+
+	if input.RouterLANs != nil && !slices.Equal(config.RouterLANs, input.RouterLANs) {
+		if err := routermode.ValidateLANs(input.RouterLANs); err != nil {
+			return false, fmt.Errorf("router LANs: %w", err)
+		}
+		log.Infof("updating router LANs [ %s ] (old value: [ %s ])",
+			strings.Join(input.RouterLANs, " "),
+			strings.Join(config.RouterLANs, " "))
+		config.RouterLANs = input.RouterLANs
+		updated = true
+	}
+
+	if input.RouteLearning != nil && *input.RouteLearning != config.RouteLearning {
+		if err := routelearn.ValidateSource(*input.RouteLearning); err != nil {
+			return false, err
+		}
+		log.Infof("updating route learning source to %q (old value %q)", *input.RouteLearning, config.RouteLearning)
+		config.RouteLearning = *input.RouteLearning
+		updated = true
+	}
+
+	if input.RouteLearningFilters != nil && !slices.Equal(config.RouteLearningFilters, input.RouteLearningFilters) {
+		if _, err := routelearn.ParseFilter(input.RouteLearningFilters); err != nil {
+			return false, err
+		}
+		log.Infof("updating route learning filters [ %s ] (old value: [ %s ])",
+			strings.Join(input.RouteLearningFilters, ", "),
+			strings.Join(config.RouteLearningFilters, ", "))
+		config.RouteLearningFilters = input.RouteLearningFilters
+		updated = true
+	}
+
+	if input.RouteDrainPeriod != nil && *input.RouteDrainPeriod != config.RouteDrainPeriod {
+		if *input.RouteDrainPeriod < 0 || *input.RouteDrainPeriod > MaxRouteDrainPeriod {
+			return false, fmt.Errorf("route drain period %s is out of range [0, %s]", *input.RouteDrainPeriod, MaxRouteDrainPeriod)
+		}
+		log.Infof("updating route drain period to %s (old value %s)", *input.RouteDrainPeriod, config.RouteDrainPeriod)
+		config.RouteDrainPeriod = *input.RouteDrainPeriod
+		updated = true
+	}
+
+	if input.SplitTunnelApps != nil && !slices.Equal(config.SplitTunnelApps, input.SplitTunnelApps) {
+		if err := (splittunnel.Config{Apps: input.SplitTunnelApps}).Validate(); err != nil {
+			return false, err
+		}
+		log.Infof("updating split tunnel apps [ %s ] (old value: [ %s ])",
+			strings.Join(input.SplitTunnelApps, ", "),
+			strings.Join(config.SplitTunnelApps, ", "))
+		config.SplitTunnelApps = input.SplitTunnelApps
+		updated = true
+	}
+
+	if input.SplitTunnelUsers != nil && !slices.Equal(config.SplitTunnelUsers, input.SplitTunnelUsers) {
+		if err := (splittunnel.Config{Users: input.SplitTunnelUsers}).Validate(); err != nil {
+			return false, err
+		}
+		log.Infof("updating split tunnel users [ %s ] (old value: [ %s ])",
+			strings.Join(input.SplitTunnelUsers, ", "),
+			strings.Join(config.SplitTunnelUsers, ", "))
+		config.SplitTunnelUsers = input.SplitTunnelUsers
+		updated = true
+	}
+
+	if input.Fwmark != nil && *input.Fwmark != config.Fwmark {
+		if err := nbnet.ValidateFwmark(*input.Fwmark); err != nil {
+			return false, err
+		}
+		log.Infof("updating fwmark to %#x (old value %#x)", *input.Fwmark, config.Fwmark)
+		config.Fwmark = *input.Fwmark
+		updated = true
+	}
+
+	if input.RoutingTableID != nil && *input.RoutingTableID != config.RoutingTableID {
+		if err := nbnet.ValidateRoutingTableID(*input.RoutingTableID); err != nil {
+			return false, err
+		}
+		log.Infof("updating routing table ID to %d (old value %d)", *input.RoutingTableID, config.RoutingTableID)
+		config.RoutingTableID = *input.RoutingTableID
+		updated = true
+	}
+
+	if input.OverlayOnlyServices != nil && !slices.Equal(config.OverlayOnlyServices, input.OverlayOnlyServices) {
+		if _, err := firewallManager.ParseInboundServices(input.OverlayOnlyServices); err != nil {
+			return false, err
+		}
+		log.Infof("updating overlay-only services [ %s ] (old value: [ %s ])",
+			strings.Join(input.OverlayOnlyServices, ", "),
+			strings.Join(config.OverlayOnlyServices, ", "))
+		config.OverlayOnlyServices = input.OverlayOnlyServices
+		updated = true
+	}
+
+	if input.RequireRouteApproval != nil && *input.RequireRouteApproval != config.RequireRouteApproval {
+		if *input.RequireRouteApproval {
+			log.Infof("requiring approval of the high-risk routes")
+		} else {
+			log.Infof("accepting the high-risk routes without approval")
+		}
+		config.RequireRouteApproval = *input.RequireRouteApproval
+		updated = true
+	}
+
+	if input.ExitNodeStickyGrace != nil && *input.ExitNodeStickyGrace != config.ExitNodeStickyGrace {
+		log.Infof("updating exit node sticky grace period to %s (old value %s)",
+			input.ExitNodeStickyGrace.String(), config.ExitNodeStickyGrace.String())
+		config.ExitNodeStickyGrace = *input.ExitNodeStickyGrace
+		updated = true
+	}
+
+	if input.DNSHostsFile != nil && *input.DNSHostsFile != config.DNSHostsFile {
+		if *input.DNSHostsFile {
+			log.Infof("writing the peer records to the hosts file instead of the system DNS")
+		} else {
+			log.Infof("pointing the system DNS to NetBird")
+		}
+		config.DNSHostsFile = *input.DNSHostsFile
+		updated = true
+	}
+
+	if input.MaxICENegotiations != nil && *input.MaxICENegotiations != config.MaxICENegotiations {
+		log.Infof("updating the max concurrent ICE negotiations to %d (old value %d)",
+			*input.MaxICENegotiations, config.MaxICENegotiations)
+		config.MaxICENegotiations = *input.MaxICENegotiations
+		updated = true
+	}
+
+	if input.LANGatewayVIP != nil && *input.LANGatewayVIP != config.LANGatewayVIP {
+		if *input.LANGatewayVIP != "" {
+			if _, err := netip.ParsePrefix(*input.LANGatewayVIP); err != nil {
+				return false, fmt.Errorf("LAN gateway virtual IP: %w", err)
+			}
+		}
+		log.Infof("updating LAN gateway virtual IP to %q (old value %q)", *input.LANGatewayVIP, config.LANGatewayVIP)
+		config.LANGatewayVIP = *input.LANGatewayVIP
+		updated = true
+	}
+
+	if input.LANGatewayInterface != nil && *input.LANGatewayInterface != config.LANGatewayInterface {
+		log.Infof("updating LAN gateway interface to %q (old value %q)", *input.LANGatewayInterface, config.LANGatewayInterface)
+		config.LANGatewayInterface = *input.LANGatewayInterface
+		updated = true
+	}
+
+	if input.LANGatewayPriority != nil && *input.LANGatewayPriority != config.LANGatewayPriority {
+		// zero applies the default priority
+		if *input.LANGatewayPriority != 0 && (*input.LANGatewayPriority < langateway.MinPriority || *input.LANGatewayPriority > langateway.MaxPriority) {
+			return false, fmt.Errorf("LAN gateway priority %d is out of range [%d, %d]",
+				*input.LANGatewayPriority, langateway.MinPriority, langateway.MaxPriority)
+		}
+		log.Infof("updating LAN gateway priority to %d (old value %d)", *input.LANGatewayPriority, config.LANGatewayPriority)
+		config.LANGatewayPriority = *input.LANGatewayPriority
+		updated = true
+	}
+
+	if input.LANGatewayPeers != nil && !slices.Equal(config.LANGatewayPeers, input.LANGatewayPeers) {
+		for _, peer := range input.LANGatewayPeers {
+			if _, err := netip.ParseAddr(peer); err != nil {
+				return false, fmt.Errorf("LAN gateway peer: %w", err)
+			}
+		}
+		log.Infof("updating LAN gateway peers [ %s ] (old value: [ %s ])",
+			strings.Join(input.LANGatewayPeers, " "),
+			strings.Join(config.LANGatewayPeers, " "))
+		config.LANGatewayPeers = input.LANGatewayPeers
+		updated = true
+	}
+
+	if input.FileTransferDir != nil && *input.FileTransferDir != config.FileTransferDir {
+		if *input.FileTransferDir != "" && !filepath.IsAbs(*input.FileTransferDir) {
+			return false, fmt.Errorf("file transfer directory %q is not an absolute path", *input.FileTransferDir)
+		}
+		log.Infof("updating file transfer directory to %q (old value %q)", *input.FileTransferDir, config.FileTransferDir)
+		config.FileTransferDir = *input.FileTransferDir
+		updated = true
+	}
+
+	if input.RemoteActions != nil && !slices.Equal(config.RemoteActions, input.RemoteActions) {
+		for _, action := range input.RemoteActions {
+			switch action {
+			case mgmProto.PeerActionRestart, mgmProto.PeerActionReauth, mgmProto.PeerActionRotateKey, mgmProto.PeerActionCollectStatus:
+			default:
+				return false, fmt.Errorf("invalid remote action %q", action)
+			}
+		}
+		log.Infof("allowing the remote actions [ %s ] (old value: [ %s ])",
+			strings.Join(input.RemoteActions, " "),
+			strings.Join(config.RemoteActions, " "))
+		config.RemoteActions = input.RemoteActions
+		updated = true
+	}
+
 	return updated, nil
 }
 
+// isLocalSetting checks if the client setting was set locally. A disabled setting is, the settings are enabled by
+// default and the configs written before the local settings were recorded don't list it.
+func (config *Config) isLocalSetting(setting string, disabled bool) bool {
+	return disabled || slices.Contains(config.LocalSettings, setting)
+}
+
 // parseURL parses and validates a service URL
 func parseURL(serviceName, serviceURL string) (*url.URL, error) {
 	parsedMgmtURL, err := url.ParseRequestURI(serviceURL)
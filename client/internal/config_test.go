@@ -105,6 +105,22 @@ func TestExtraIFaceBlackList(t *testing.T) {
 	assert.Contains(t, readConf.(*Config).IFaceBlackList, "eth1")
 }
 
+func TestLocalSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	config, err := UpdateOrCreateConfig(ConfigInput{ConfigPath: path})
+	require.NoError(t, err)
+	assert.Empty(t, config.LocalSettings)
+
+	enabled := false
+	config, err = UpdateOrCreateConfig(ConfigInput{ConfigPath: path, DisableDNS: &enabled})
+	require.NoError(t, err)
+	assert.Equal(t, []string{SettingDNS}, config.LocalSettings, "a setting set to its default value was set locally too")
+
+	config, err = UpdateOrCreateConfig(ConfigInput{ConfigPath: path, DisableDNS: &enabled, DisableFirewall: &enabled})
+	require.NoError(t, err)
+	assert.Equal(t, []string{SettingDNS, SettingFirewall}, config.LocalSettings)
+}
+
 func TestHiddenPreSharedKey(t *testing.T) {
 	hidden := "**********"
 	samplePreSharedKey := "mysecretpresharedkey"
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/netip"
+	"net/url"
 	"runtime"
 	"runtime/debug"
 	"strings"
@@ -17,12 +19,16 @@ import (
 	"google.golang.org/grpc/codes"
 	gstatus "google.golang.org/grpc/status"
 
+	firewallManager "github.com/netbirdio/netbird/client/firewall/manager"
 	"github.com/netbirdio/netbird/client/iface"
 	"github.com/netbirdio/netbird/client/iface/device"
 	"github.com/netbirdio/netbird/client/internal/dns"
+	"github.com/netbirdio/netbird/client/internal/langateway"
 	"github.com/netbirdio/netbird/client/internal/listener"
 	"github.com/netbirdio/netbird/client/internal/peer"
+	"github.com/netbirdio/netbird/client/internal/routemanager/splittunnel"
 	"github.com/netbirdio/netbird/client/internal/stdnet"
+	"github.com/netbirdio/netbird/client/internal/watchdog"
 	cProto "github.com/netbirdio/netbird/client/proto"
 	"github.com/netbirdio/netbird/client/ssh"
 	"github.com/netbirdio/netbird/client/system"
@@ -32,6 +38,7 @@ import (
 	relayClient "github.com/netbirdio/netbird/relay/client"
 	signal "github.com/netbirdio/netbird/signal/client"
 	"github.com/netbirdio/netbird/util"
+	nbgrpc "github.com/netbirdio/netbird/util/grpc"
 	nbnet "github.com/netbirdio/netbird/util/net"
 	"github.com/netbirdio/netbird/version"
 )
@@ -43,7 +50,12 @@ type ConnectClient struct {
 	engine         *Engine
 	engineMutex    sync.Mutex
 
-	persistNetworkMap bool
+	persistNetworkMap  bool
+	debugBundleHandler DebugBundleHandler
+	peerActionHandler  PeerActionHandler
+	watchdog           *watchdog.Watchdog
+	// releasedInterface is the interface handed over by the stopped engine until the next engine takes it over
+	releasedInterface WGIface
 }
 
 func NewConnectClient(
@@ -62,6 +74,8 @@ func NewConnectClient(
 
 // Run with main logic.
 func (c *ConnectClient) Run(runningChan chan struct{}) error {
+	// the interface and the dns server of the mobile clients are managed by the OS, only the daemon supervises them
+	c.watchdog = watchdog.New(c.statusRecorder)
 	return c.run(MobileDependency{}, runningChan)
 }
 
@@ -121,6 +135,8 @@ func (c *ConnectClient) run(mobileDependency MobileDependency, runningChan chan
 
 	log.Infof("starting NetBird client version %s on %s/%s", version.NetbirdVersion(), runtime.GOOS, runtime.GOARCH)
 
+	// the fwmarks are set on the sockets to the management and signal services, they're selected before connecting
+	nbnet.ConfigurePolicyRouting(c.config.Fwmark, c.config.RoutingTableID)
 	nbnet.Init()
 
 	backOff := &backoff.ExponentialBackOff{
@@ -158,6 +174,14 @@ func (c *ConnectClient) run(mobileDependency MobileDependency, runningChan chan
 		return err
 	}
 
+	// the client certificate is issued by the management service on login if it enables mutual TLS, it is kept
+	// across the reconnections and renewed before it expires
+	clientCert := nbgrpc.NewClientCertificate()
+
+	if c.config.StaticMeshFile != "" {
+		return c.runStaticMesh(mobileDependency, runningChan, myPrivateKey)
+	}
+
 	defer c.statusRecorder.ClientStop()
 	operation := func() error {
 		// if context cancelled we not start new backoff cycle
@@ -175,8 +199,15 @@ func (c *ConnectClient) run(mobileDependency MobileDependency, runningChan chan
 			cancel()
 		}()
 
+		// the key is parsed for every connection, a rotate key action of the management service replaces it
+		myPrivateKey, err := wgtypes.ParseKey(c.config.PrivateKey)
+		if err != nil {
+			log.Errorf("failed parsing Wireguard key %s: [%s]", c.config.PrivateKey, err.Error())
+			return backoff.Permanent(wrapErr(err))
+		}
+
 		log.Debugf("connecting to the Management service %s", c.config.ManagementURL.Host)
-		mgmClient, err := mgm.NewClient(engineCtx, c.config.ManagementURL.Host, myPrivateKey, mgmTlsEnabled)
+		mgmClient, err := mgm.NewClientWithCertificate(engineCtx, c.config.ManagementURL.Host, myPrivateKey, mgmTlsEnabled, clientCert)
 		if err != nil {
 			return wrapErr(gstatus.Errorf(codes.FailedPrecondition, "failed connecting to Management Service : %s", err))
 		}
@@ -201,6 +232,18 @@ func (c *ConnectClient) run(mobileDependency MobileDependency, runningChan chan
 			}
 			return wrapErr(err)
 		}
+
+		if len(loginResp.GetClientCertificate()) > 0 {
+			// the connection was established before the certificate was issued, a new one presents it
+			if err = mgmClient.Close(); err != nil {
+				log.Warnf("failed to close the Management service client %v", err)
+			}
+			mgmClient, err = mgm.NewClientWithCertificate(engineCtx, c.config.ManagementURL.Host, myPrivateKey, mgmTlsEnabled, clientCert)
+			if err != nil {
+				return wrapErr(gstatus.Errorf(codes.FailedPrecondition, "failed connecting to Management Service : %s", err))
+			}
+			mgmClient.SetConnStateListener(mgmNotifier)
+		}
 		c.statusRecorder.MarkManagementConnected()
 
 		localPeerState := peer.LocalPeerState{
@@ -225,7 +268,7 @@ func (c *ConnectClient) run(mobileDependency MobileDependency, runningChan chan
 		}()
 
 		// with the global Netbird config in hand connect (just a connection, no stream yet) Signal
-		signalClient, err := connectToSignal(engineCtx, loginResp.GetNetbirdConfig(), myPrivateKey)
+		signalClient, err := connectToSignal(engineCtx, loginResp.GetNetbirdConfig(), myPrivateKey, clientCert)
 		if err != nil {
 			log.Error(err)
 			return wrapErr(err)
@@ -244,6 +287,10 @@ func (c *ConnectClient) run(mobileDependency MobileDependency, runningChan chan
 
 		relayURLs, token := parseRelayInfo(loginResp)
 		relayManager := relayClient.NewManager(engineCtx, relayURLs, myPrivateKey.PublicKey().String())
+		if loginResp.GetPeerConfig().GetPortPolicy().GetTcp443Only() {
+			log.Infof("the port policy limits the connections to TCP port 443, the peers connect through the relay over WebSocket")
+			relayManager.UseWebSocketOnly()
+		}
 		c.statusRecorder.SetRelayMgr(relayManager)
 		if len(relayURLs) > 0 {
 			if token != nil {
@@ -265,18 +312,24 @@ func (c *ConnectClient) run(mobileDependency MobileDependency, runningChan chan
 			log.Error(err)
 			return wrapErr(err)
 		}
+		engineConfig.UplinkTargets = uplinkTargets(c.config.ManagementURL, loginResp.GetNetbirdConfig().GetSignal().GetUri(), relayURLs)
 
 		checks := loginResp.GetChecks()
 
 		c.engineMutex.Lock()
 		c.engine = NewEngine(engineCtx, cancel, signalClient, mgmClient, relayManager, engineConfig, mobileDependency, c.statusRecorder, checks)
 		c.engine.SetNetworkMapPersistence(c.persistNetworkMap)
+		c.engine.SetDebugBundleHandler(c.debugBundleHandler)
+		c.engine.SetPeerActionHandler(c.peerActionHandler)
+		c.engine.SetWatchdog(c.watchdog)
+		c.engine.SetReuseInterface(c.releasedInterface != nil)
 		c.engineMutex.Unlock()
 
 		if err := c.engine.Start(); err != nil {
 			log.Errorf("error while starting Netbird Connection Engine: %s", err)
 			return wrapErr(err)
 		}
+		c.releasedInterface = nil
 
 		log.Infof("Netbird engine started, the IP is: %s", peerConfig.GetAddress())
 		state.Set(StatusConnected)
@@ -295,6 +348,7 @@ func (c *ConnectClient) run(mobileDependency MobileDependency, runningChan chan
 			if err := c.engine.Stop(); err != nil {
 				log.Errorf("Failed to stop engine: %v", err)
 			}
+			c.releasedInterface = c.engine.ReleasedInterface()
 			c.engine = nil
 		}
 		c.engineMutex.Unlock()
@@ -313,6 +367,7 @@ func (c *ConnectClient) run(mobileDependency MobileDependency, runningChan chan
 
 	c.statusRecorder.ClientStart()
 	err = backoff.Retry(operation, backOff)
+	c.removeReleasedInterface()
 	if err != nil {
 		log.Debugf("exiting client retry loop due to unrecoverable error: %s", err)
 		if s, ok := gstatus.FromError(err); ok && (s.Code() == codes.PermissionDenied) {
@@ -324,6 +379,19 @@ func (c *ConnectClient) run(mobileDependency MobileDependency, runningChan chan
 	return nil
 }
 
+// removeReleasedInterface removes the interface handed over by the last engine if no engine took it over
+func (c *ConnectClient) removeReleasedInterface() {
+	if c.releasedInterface == nil {
+		return
+	}
+
+	log.Infof("removing the released interface %s", c.releasedInterface.Name())
+	if err := c.releasedInterface.Close(); err != nil {
+		log.Errorf("failed to remove the released interface %s: %v", c.releasedInterface.Name(), err)
+	}
+	c.releasedInterface = nil
+}
+
 func parseRelayInfo(loginResp *mgmProto.LoginResponse) ([]string, *hmac.Token) {
 	relayCfg := loginResp.GetNetbirdConfig().GetRelay()
 	if relayCfg == nil {
@@ -379,6 +447,22 @@ func (c *ConnectClient) Stop() error {
 	return nil
 }
 
+// Drain moves the routes of a routing peer to other routing peers and waits for the established flows before the
+// shutdown
+func (c *ConnectClient) Drain(ctx context.Context) {
+	if c == nil {
+		return
+	}
+	c.engineMutex.Lock()
+	engine := c.engine
+	c.engineMutex.Unlock()
+
+	if engine == nil {
+		return
+	}
+	engine.Drain(ctx)
+}
+
 // SetNetworkMapPersistence enables or disables network map persistence.
 // When enabled, the last received network map will be stored and can be retrieved
 // through the Engine's getLatestNetworkMap method. When disabled, any stored
@@ -394,6 +478,30 @@ func (c *ConnectClient) SetNetworkMapPersistence(enabled bool) {
 	}
 }
 
+// SetDebugBundleHandler sets the handler of the debug bundles requested by the management service
+func (c *ConnectClient) SetDebugBundleHandler(handler DebugBundleHandler) {
+	c.engineMutex.Lock()
+	c.debugBundleHandler = handler
+	c.engineMutex.Unlock()
+
+	engine := c.Engine()
+	if engine != nil {
+		engine.SetDebugBundleHandler(handler)
+	}
+}
+
+// SetPeerActionHandler sets the handler of the peer actions requested by the management service that need the daemon
+func (c *ConnectClient) SetPeerActionHandler(handler PeerActionHandler) {
+	c.engineMutex.Lock()
+	c.peerActionHandler = handler
+	c.engineMutex.Unlock()
+
+	engine := c.Engine()
+	if engine != nil {
+		engine.SetPeerActionHandler(handler)
+	}
+}
+
 // createEngineConfig converts configuration received from Management Service to EngineConfig
 func createEngineConfig(key wgtypes.Key, config *Config, peerConfig *mgmProto.PeerConfig) (*EngineConfig, error) {
 	nm := false
@@ -422,8 +530,44 @@ func createEngineConfig(key wgtypes.Key, config *Config, peerConfig *mgmProto.Pe
 		DisableFirewall:     config.DisableFirewall,
 
 		BlockLANAccess: config.BlockLANAccess,
+
+		Uplinks: config.Uplinks,
+
+		RouterMode: config.RouterMode,
+		RouterLANs: config.RouterLANs,
+
+		RouteLearning:        config.RouteLearning,
+		RouteLearningFilters: config.RouteLearningFilters,
+
+		RouteDrainPeriod: config.RouteDrainPeriod,
+
+		RequireRouteApproval: config.RequireRouteApproval,
+		ExitNodeStickyGrace:  config.ExitNodeStickyGrace,
+		DNSHostsFile:         config.DNSHostsFile,
+		MaxICENegotiations:   config.MaxICENegotiations,
+
+		FileTransferDir: config.FileTransferDir,
+
+		RemoteActions: config.RemoteActions,
+
+		SplitTunnel: splittunnel.Config{
+			Apps:  config.SplitTunnelApps,
+			Users: config.SplitTunnelUsers,
+		},
 	}
 
+	overlayOnlyServices, err := firewallManager.ParseInboundServices(config.OverlayOnlyServices)
+	if err != nil {
+		return nil, fmt.Errorf("parse overlay-only services: %w", err)
+	}
+	engineConf.OverlayOnlyServices = overlayOnlyServices
+
+	lanGateway, err := lanGatewayConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("LAN gateway: %w", err)
+	}
+	engineConf.LANGateway = lanGateway
+
 	if config.PreSharedKey != "" {
 		preSharedKey, err := wgtypes.ParseKey(config.PreSharedKey)
 		if err != nil {
@@ -432,6 +576,19 @@ func createEngineConfig(key wgtypes.Key, config *Config, peerConfig *mgmProto.Pe
 		engineConf.PreSharedKey = &preSharedKey
 	}
 
+	applyClientSettings(engineConf, config, peerConfig.GetClientSettings())
+
+	engineConf.PortPolicy = peerConfig.GetPortPolicy()
+	if policy := peerConfig.GetPortPolicy(); policy.GetWgPortRangeStart() != 0 {
+		port, err := freePortInRange(config.WgPort, int(policy.GetWgPortRangeStart()), int(policy.GetWgPortRangeEnd()))
+		if err != nil {
+			return nil, err
+		}
+		log.Infof("using %d as wireguard port pinned by the port policy %d-%d", port, policy.GetWgPortRangeStart(), policy.GetWgPortRangeEnd())
+		engineConf.WgPort = port
+		return engineConf, nil
+	}
+
 	port, err := freePort(config.WgPort)
 	if err != nil {
 		return nil, err
@@ -444,8 +601,62 @@ func createEngineConfig(key wgtypes.Key, config *Config, peerConfig *mgmProto.Pe
 	return engineConf, nil
 }
 
+// lanGatewayConfig returns the config of the virtual LAN gateway, nil if it's disabled
+func lanGatewayConfig(config *Config) (*langateway.Config, error) {
+	if config.LANGatewayVIP == "" {
+		return nil, nil
+	}
+
+	vip, err := netip.ParsePrefix(config.LANGatewayVIP)
+	if err != nil {
+		return nil, fmt.Errorf("parse virtual IP: %w", err)
+	}
+	lanGateway := &langateway.Config{
+		VirtualIP: vip,
+		Interface: config.LANGatewayInterface,
+		Priority:  config.LANGatewayPriority,
+	}
+	if lanGateway.Priority == 0 {
+		lanGateway.Priority = langateway.DefaultPriority
+	}
+	for _, p := range config.LANGatewayPeers {
+		peerIP, err := netip.ParseAddr(p)
+		if err != nil {
+			return nil, fmt.Errorf("parse peer: %w", err)
+		}
+		lanGateway.Peers = append(lanGateway.Peers, peerIP)
+	}
+
+	if err := lanGateway.Validate(); err != nil {
+		return nil, err
+	}
+	return lanGateway, nil
+}
+
+// uplinkTargets returns the host:port addresses of the management, signal and relay services
+func uplinkTargets(mgmURL *url.URL, signalURI string, relayURLs []string) []string {
+	targets := []string{mgmURL.Host, signalURI}
+	for _, relayURL := range relayURLs {
+		u, err := url.Parse(relayURL)
+		if err != nil {
+			log.Warnf("failed to parse relay URL %s: %v", relayURL, err)
+			continue
+		}
+
+		port := u.Port()
+		if port == "" {
+			port = "443"
+			if u.Scheme == "rel" {
+				port = "80"
+			}
+		}
+		targets = append(targets, net.JoinHostPort(u.Hostname(), port))
+	}
+	return targets
+}
+
 // connectToSignal creates Signal Service client and established a connection
-func connectToSignal(ctx context.Context, wtConfig *mgmProto.NetbirdConfig, ourPrivateKey wgtypes.Key) (*signal.GrpcClient, error) {
+func connectToSignal(ctx context.Context, wtConfig *mgmProto.NetbirdConfig, ourPrivateKey wgtypes.Key, clientCert *nbgrpc.ClientCertificate) (*signal.GrpcClient, error) {
 	var sigTLSEnabled bool
 	if wtConfig.Signal.Protocol == mgmProto.HostConfig_HTTPS {
 		sigTLSEnabled = true
@@ -453,7 +664,7 @@ func connectToSignal(ctx context.Context, wtConfig *mgmProto.NetbirdConfig, ourP
 		sigTLSEnabled = false
 	}
 
-	signalClient, err := signal.NewClient(ctx, wtConfig.Signal.Uri, ourPrivateKey, sigTLSEnabled)
+	signalClient, err := signal.NewClientWithCertificate(ctx, wtConfig.Signal.Uri, ourPrivateKey, sigTLSEnabled, clientCert)
 	if err != nil {
 		log.Errorf("error while connecting to the Signal Exchange Service %s: %s", wtConfig.Signal.Uri, err)
 		return nil, gstatus.Errorf(codes.FailedPrecondition, "failed connecting to Signal Service : %s", err)
@@ -480,6 +691,7 @@ func loginToManagement(ctx context.Context, client mgm.Client, pubSSHKey []byte,
 		config.DisableDNS,
 		config.DisableFirewall,
 	)
+	sysInfo.SetRouterMode(config.RouterMode, config.RouterLANs)
 	loginResp, err := client.Login(*serverPublicKey, sysInfo, pubSSHKey, config.DNSLabels)
 	if err != nil {
 		return nil, err
@@ -530,6 +742,46 @@ func freePort(initPort int) (int, error) {
 	return udpAddr.Port, nil
 }
 
+// applyClientSettings applies the defaults of the client settings of the account to the settings not set locally
+func applyClientSettings(engineConf *EngineConfig, config *Config, settings *mgmProto.ClientSettings) {
+	engineConf.ClientSettings = settings
+	if settings == nil {
+		return
+	}
+
+	if !config.isLocalSetting(SettingDNS, config.DisableDNS) {
+		engineConf.DisableDNS = !settings.GetDnsManagementEnabled()
+	}
+	if !config.isLocalSetting(SettingClientRoutes, config.DisableClientRoutes) {
+		engineConf.DisableClientRoutes = !settings.GetAcceptRoutes()
+	}
+	if !config.isLocalSetting(SettingFirewall, config.DisableFirewall) {
+		engineConf.DisableFirewall = !settings.GetFirewallEnabled()
+	}
+}
+
+// freePortInRange returns the first available port of the range, starting with the configured port if it's part of it.
+// The range is pinned by the management, the system isn't asked for another port.
+func freePortInRange(initPort, start, end int) (int, error) {
+	ports := make([]int, 0, end-start+2)
+	if initPort >= start && initPort <= end {
+		ports = append(ports, initPort)
+	}
+	for port := start; port <= end; port++ {
+		ports = append(ports, port)
+	}
+
+	for _, port := range ports {
+		conn, err := net.ListenUDP("udp", &net.UDPAddr{Port: port})
+		if err != nil {
+			continue
+		}
+		closeConnWithLog(conn)
+		return port, nil
+	}
+	return 0, fmt.Errorf("no free port in the range %d-%d pinned by the port policy", start, end)
+}
+
 func closeConnWithLog(conn *net.UDPConn) {
 	startClosing := time.Now()
 	err := conn.Close()
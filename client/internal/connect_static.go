@@ -0,0 +1,92 @@
+package internal
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+
+	"github.com/netbirdio/netbird/client/iface/device"
+	"github.com/netbirdio/netbird/client/internal/peer"
+	"github.com/netbirdio/netbird/client/internal/staticmesh"
+	relayClient "github.com/netbirdio/netbird/relay/client"
+)
+
+// runStaticMesh runs the engine with the peers of the static mesh file, without the management and signal services
+func (c *ConnectClient) runStaticMesh(mobileDependency MobileDependency, runningChan chan struct{}, myPrivateKey wgtypes.Key) error {
+	state := CtxGetState(c.ctx)
+	wrapErr := state.Wrap
+
+	mesh, err := staticmesh.Load(c.config.StaticMeshFile)
+	if err != nil {
+		return wrapErr(err)
+	}
+
+	endpoints, err := mesh.Endpoints()
+	if err != nil {
+		return wrapErr(err)
+	}
+
+	state.Set(StatusConnecting)
+
+	engineCtx, cancel := context.WithCancel(c.ctx)
+	defer cancel()
+
+	c.statusRecorder.ClientStart()
+	defer c.statusRecorder.ClientStop()
+
+	peerConfig := mesh.PeerConfig()
+	c.statusRecorder.UpdateLocalPeerState(peer.LocalPeerState{
+		IP:              peerConfig.GetAddress(),
+		PubKey:          myPrivateKey.PublicKey().String(),
+		KernelInterface: device.WireGuardModuleIsLoaded(),
+		FQDN:            peerConfig.GetFqdn(),
+	})
+	defer c.statusRecorder.CleanLocalPeerState()
+
+	engineConfig, err := createEngineConfig(myPrivateKey, c.config, peerConfig)
+	if err != nil {
+		return wrapErr(err)
+	}
+	engineConfig.StaticMesh = true
+	engineConfig.StaticEndpoints = endpoints
+
+	mgmClient := staticmesh.NewManagementClient(mesh, myPrivateKey.PublicKey())
+	signalClient := staticmesh.NewSignalClient()
+	relayManager := relayClient.NewManager(engineCtx, nil, myPrivateKey.PublicKey().String())
+	c.statusRecorder.SetRelayMgr(relayManager)
+
+	c.engineMutex.Lock()
+	c.engine = NewEngine(engineCtx, cancel, signalClient, mgmClient, relayManager, engineConfig, mobileDependency, c.statusRecorder, nil)
+	c.engine.SetNetworkMapPersistence(c.persistNetworkMap)
+	c.engineMutex.Unlock()
+
+	if err := c.engine.Start(); err != nil {
+		log.Errorf("error while starting Netbird Connection Engine: %s", err)
+		return wrapErr(err)
+	}
+
+	log.Infof("Netbird engine started in static mesh mode with %d peers, the IP is: %s", len(mesh.Peers), peerConfig.GetAddress())
+	state.Set(StatusConnected)
+
+	if runningChan != nil {
+		select {
+		case runningChan <- struct{}{}:
+		default:
+		}
+	}
+
+	<-engineCtx.Done()
+	c.engineMutex.Lock()
+	if c.engine != nil && c.engine.wgInterface != nil {
+		if err := c.engine.Stop(); err != nil {
+			log.Errorf("Failed to stop engine: %v", err)
+		}
+		c.engine = nil
+	}
+	c.engineMutex.Unlock()
+	c.statusRecorder.ClientTeardown()
+
+	log.Info("stopped NetBird client")
+	return nil
+}
@@ -3,6 +3,10 @@ package internal
 import (
 	"net"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	mgmProto "github.com/netbirdio/netbird/management/proto"
 )
 
 func Test_freePort(t *testing.T) {
@@ -59,3 +63,66 @@ func Test_freePort(t *testing.T) {
 
 	}
 }
+
+func Test_freePortInRange(t *testing.T) {
+	c1, err := net.ListenUDP("udp", &net.UDPAddr{Port: 51840})
+	if err != nil {
+		t.Fatalf("listen error = %v", err)
+	}
+	defer func(c1 *net.UDPConn) {
+		_ = c1.Close()
+	}(c1)
+
+	tests := []struct {
+		name    string
+		port    int
+		start   int
+		end     int
+		want    int
+		wantErr bool
+	}{
+		{name: "configured port in range", port: 51842, start: 51840, end: 51845, want: 51842},
+		{name: "configured port out of range", port: 51820, start: 51841, end: 51845, want: 51841},
+		{name: "first port in use", port: 0, start: 51840, end: 51845, want: 51841},
+		{name: "fixed port in use", port: 0, start: 51840, end: 51840, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := freePortInRange(tt.port, tt.start, tt.end)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got port %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got an error while getting free port: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got port %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_applyClientSettings(t *testing.T) {
+	settings := &mgmProto.ClientSettings{DnsManagementEnabled: false, AcceptRoutes: false, FirewallEnabled: true}
+
+	engineConf := &EngineConfig{}
+	applyClientSettings(engineConf, &Config{}, nil)
+	assert.Equal(t, &EngineConfig{}, engineConf, "the settings should be kept without defaults")
+
+	engineConf = &EngineConfig{}
+	applyClientSettings(engineConf, &Config{}, settings)
+	assert.True(t, engineConf.DisableDNS)
+	assert.True(t, engineConf.DisableClientRoutes)
+	assert.False(t, engineConf.DisableFirewall)
+
+	config := &Config{DisableFirewall: true, LocalSettings: []string{SettingDNS}}
+	engineConf = &EngineConfig{DisableFirewall: true}
+	applyClientSettings(engineConf, config, settings)
+	assert.False(t, engineConf.DisableDNS, "the DNS setting was set locally")
+	assert.True(t, engineConf.DisableClientRoutes)
+	assert.True(t, engineConf.DisableFirewall, "a disabled setting was set locally")
+}
@@ -0,0 +1,180 @@
+package connectivity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"time"
+
+	"github.com/pion/stun/v2"
+	log "github.com/sirupsen/logrus"
+
+	nbnet "github.com/netbirdio/netbird/util/net"
+)
+
+// NAT types reported to the management service
+const (
+	// NATNone is a public address, the peer isn't behind a NAT
+	NATNone = "none"
+	// NATEndpointIndependent maps the peer to the same public address for all destinations, the hole punching
+	// usually succeeds
+	NATEndpointIndependent = "endpoint-independent"
+	// NATEndpointDependent maps the peer to a public address per destination, the connections to peers behind
+	// such NATs usually fall back to relay
+	NATEndpointDependent = "endpoint-dependent"
+	// NATUDPBlocked is reported when no STUN server answered
+	NATUDPBlocked = "udp-blocked"
+	// NATUnknown is reported when the mapping couldn't be compared, e.g. with a single STUN server
+	NATUnknown = "unknown"
+)
+
+const (
+	bindingAttempts = 3
+	bindingTimeout  = 700 * time.Millisecond
+)
+
+// ClassifyNAT classifies the NAT of the peer by comparing the public addresses the STUN servers see for requests
+// sent from a single socket. Servers sharing an IP address with different ports don't tell an address-dependent
+// mapping from an endpoint-independent one, it is reported as endpoint-independent. The public address seen by the
+// first answering server is returned with the type.
+func ClassifyNAT(ctx context.Context, stuns []*stun.URI) (string, netip.AddrPort) {
+	servers := stunServers(stuns)
+	if len(servers) == 0 {
+		return NATUnknown, netip.AddrPort{}
+	}
+
+	conn, err := nbnet.NewListener().ListenPacket(ctx, "udp4", "")
+	if err != nil {
+		log.Debugf("failed to listen for the NAT classification: %v", err)
+		return NATUnknown, netip.AddrPort{}
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			log.Debugf("failed to close the NAT classification socket: %v", err)
+		}
+	}()
+
+	var mapped []netip.AddrPort
+	for _, server := range servers {
+		if ctx.Err() != nil {
+			break
+		}
+		addr, err := bindingRequest(ctx, conn, server)
+		if err != nil {
+			log.Debugf("stun binding request to %s failed: %v", server, err)
+			continue
+		}
+		mapped = append(mapped, addr)
+	}
+
+	return classify(mapped, isLocalAddress), firstOrZero(mapped)
+}
+
+// classify returns the NAT type of the public addresses seen by the STUN servers
+func classify(mapped []netip.AddrPort, isLocal func(netip.Addr) bool) string {
+	switch {
+	case len(mapped) == 0:
+		return NATUDPBlocked
+	case isLocal(mapped[0].Addr()):
+		return NATNone
+	case len(mapped) == 1:
+		return NATUnknown
+	}
+
+	for _, addr := range mapped[1:] {
+		if addr != mapped[0] {
+			return NATEndpointDependent
+		}
+	}
+	return NATEndpointIndependent
+}
+
+// stunServers resolves the distinct UDP STUN servers
+func stunServers(stuns []*stun.URI) []*net.UDPAddr {
+	seen := make(map[string]struct{})
+	var servers []*net.UDPAddr
+	for _, uri := range stuns {
+		if uri.Proto != stun.ProtoTypeUDP {
+			continue
+		}
+		addr, err := net.ResolveUDPAddr("udp4", net.JoinHostPort(uri.Host, fmt.Sprint(uri.Port)))
+		if err != nil {
+			log.Debugf("failed to resolve the STUN server %s: %v", uri, err)
+			continue
+		}
+		if _, ok := seen[addr.String()]; ok {
+			continue
+		}
+		seen[addr.String()] = struct{}{}
+		servers = append(servers, addr)
+	}
+	return servers
+}
+
+// bindingRequest sends a STUN binding request to the server and returns the public address in the response
+func bindingRequest(ctx context.Context, conn net.PacketConn, server *net.UDPAddr) (netip.AddrPort, error) {
+	request := stun.MustBuild(stun.TransactionID, stun.BindingRequest)
+	buf := make([]byte, 1500)
+
+	for attempt := 0; attempt < bindingAttempts && ctx.Err() == nil; attempt++ {
+		if _, err := conn.WriteTo(request.Raw, server); err != nil {
+			return netip.AddrPort{}, fmt.Errorf("send: %w", err)
+		}
+		if err := conn.SetReadDeadline(time.Now().Add(bindingTimeout)); err != nil {
+			return netip.AddrPort{}, fmt.Errorf("set deadline: %w", err)
+		}
+
+		for {
+			n, _, err := conn.ReadFrom(buf)
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				break
+			}
+			if err != nil {
+				return netip.AddrPort{}, fmt.Errorf("receive: %w", err)
+			}
+
+			response := &stun.Message{Raw: append([]byte(nil), buf[:n]...)}
+			if err := response.Decode(); err != nil || response.TransactionID != request.TransactionID {
+				continue
+			}
+
+			var xorAddr stun.XORMappedAddress
+			if err := xorAddr.GetFrom(response); err != nil {
+				return netip.AddrPort{}, fmt.Errorf("get xor addr: %w", err)
+			}
+			addr, ok := netip.AddrFromSlice(xorAddr.IP)
+			if !ok {
+				return netip.AddrPort{}, fmt.Errorf("invalid mapped address %s", xorAddr.IP)
+			}
+			return netip.AddrPortFrom(addr.Unmap(), uint16(xorAddr.Port)), nil
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return netip.AddrPort{}, err
+	}
+	return netip.AddrPort{}, errors.New("no response")
+}
+
+func isLocalAddress(addr netip.Addr) bool {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return false
+	}
+	for _, a := range addrs {
+		if prefix, err := netip.ParsePrefix(a.String()); err == nil && prefix.Addr().Unmap() == addr {
+			return true
+		}
+	}
+	return false
+}
+
+func firstOrZero(addrs []netip.AddrPort) netip.AddrPort {
+	if len(addrs) == 0 {
+		return netip.AddrPort{}
+	}
+	return addrs[0]
+}
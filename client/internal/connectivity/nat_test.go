@@ -0,0 +1,38 @@
+package connectivity
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	local := netip.MustParseAddr("192.168.1.10")
+	isLocal := func(addr netip.Addr) bool {
+		return addr == local
+	}
+
+	tests := []struct {
+		name     string
+		mapped   []string
+		expected string
+	}{
+		{name: "no answer", expected: NATUDPBlocked},
+		{name: "public address", mapped: []string{"192.168.1.10:51820", "192.168.1.10:51820"}, expected: NATNone},
+		{name: "single server", mapped: []string{"203.0.113.1:40000"}, expected: NATUnknown},
+		{name: "same mapping", mapped: []string{"203.0.113.1:40000", "203.0.113.1:40000"}, expected: NATEndpointIndependent},
+		{name: "port per destination", mapped: []string{"203.0.113.1:40000", "203.0.113.1:40001"}, expected: NATEndpointDependent},
+		{name: "address per destination", mapped: []string{"203.0.113.1:40000", "203.0.113.2:40000"}, expected: NATEndpointDependent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mapped []netip.AddrPort
+			for _, m := range tt.mapped {
+				mapped = append(mapped, netip.MustParseAddrPort(m))
+			}
+			assert.Equal(t, tt.expected, classify(mapped, isLocal))
+		})
+	}
+}
@@ -0,0 +1,256 @@
+// Package connectivity reports the connectivity of the peer to the management service: the NAT type, the STUN and
+// TURN reachability, the share of the connections falling back to relay, the health of the client routes, the
+// durations and outcomes of the peer connection setups, the traffic the peer routed for the other peers and the
+// reachability of the networks it routes.
+package connectivity
+
+import (
+	"context"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pion/stun/v2"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	gstatus "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	firewall "github.com/netbirdio/netbird/client/firewall/manager"
+	"github.com/netbirdio/netbird/client/internal/peer"
+	"github.com/netbirdio/netbird/client/internal/peer/setup"
+	"github.com/netbirdio/netbird/client/internal/relay"
+	mgm "github.com/netbirdio/netbird/management/client"
+	mgmProto "github.com/netbirdio/netbird/management/proto"
+	"github.com/netbirdio/netbird/route"
+)
+
+const (
+	// ReportInterval is the interval the connectivity is reported at
+	ReportInterval = 15 * time.Minute
+	// initialDelay leaves the peer connections time to be established before the first report
+	initialDelay = 2 * time.Minute
+	// minReportInterval limits the reports sent early because a routed network turned unreachable or reachable again
+	minReportInterval = time.Minute
+)
+
+// Servers returns the STUN and TURN servers of the peer
+type Servers func() (stuns, turns []*stun.URI)
+
+// Routes returns the client routes of the peer
+type Routes func() route.HAMap
+
+// ForwardedTraffic returns the bytes the peer routed per peer address since the counters started
+type ForwardedTraffic func() (map[netip.Addr]firewall.ForwardedTraffic, error)
+
+// Reporter reports the connectivity of the peer periodically
+type Reporter struct {
+	client         mgm.Client
+	servers        Servers
+	routes         Routes
+	forwarded      ForwardedTraffic
+	statusRecorder *peer.Status
+
+	// lastForwarded is the routed traffic at the previous report
+	lastForwarded map[netip.Addr]firewall.ForwardedTraffic
+}
+
+// NewReporter creates a Reporter, Start reports the connectivity. forwarded is nil if the routed traffic isn't
+// counted.
+func NewReporter(client mgm.Client, servers Servers, routes Routes, forwarded ForwardedTraffic, statusRecorder *peer.Status) *Reporter {
+	return &Reporter{
+		client:         client,
+		servers:        servers,
+		routes:         routes,
+		forwarded:      forwarded,
+		statusRecorder: statusRecorder,
+	}
+}
+
+// Start reports the connectivity every ReportInterval until the context is done. A routed network turning unreachable
+// or reachable again is reported early, at most once per minReportInterval. The reporting stops when the management
+// service doesn't support it.
+func (r *Reporter) Start(ctx context.Context) {
+	go func() {
+		next := time.Now().Add(initialDelay)
+		timer := time.NewTimer(initialDelay)
+		defer timer.Stop()
+
+		// lastReport delays the early reports after the start as well
+		lastReport := time.Now()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-r.statusRecorder.RouteReachabilityChanged():
+				early := lastReport.Add(minReportInterval)
+				if early.Before(next) {
+					next = early
+					timer.Reset(time.Until(next))
+				}
+				continue
+			case <-timer.C:
+			}
+
+			err := r.client.ReportConnectivity(r.Report(ctx))
+			if gstatus.Code(err) == codes.Unimplemented {
+				log.Debugf("the management service doesn't support the connectivity reports")
+				return
+			}
+			if err != nil {
+				log.Debugf("failed to report the connectivity: %v", err)
+			}
+
+			lastReport = time.Now()
+			next = lastReport.Add(ReportInterval)
+			timer.Reset(ReportInterval)
+		}
+	}()
+}
+
+// Report probes the STUN and TURN servers, classifies the NAT, counts the direct and the relayed connections, checks
+// the routing peers of the client routes, aggregates the connection setups and the routed traffic since the previous
+// report and adds the latest reachability checks of the routed networks
+func (r *Reporter) Report(ctx context.Context) *mgmProto.ConnectivityReport {
+	stuns, turns := r.servers()
+
+	report := &mgmProto.ConnectivityReport{
+		Stuns: toReachability(relay.ProbeAll(ctx, relay.ProbeSTUN, stuns)),
+		Turns: toReachability(relay.ProbeAll(ctx, relay.ProbeTURN, turns)),
+	}
+
+	natType, mapped := ClassifyNAT(ctx, stuns)
+	report.NatType = natType
+	if mapped.IsValid() {
+		report.MappedAddress = mapped.String()
+	}
+
+	for _, state := range r.statusRecorder.GetFullStatus().Peers {
+		if state.ConnStatus != peer.StatusConnected {
+			continue
+		}
+		report.ConnectedPeers++
+		if state.Relayed {
+			report.RelayedPeers++
+		}
+	}
+
+	report.Routes = RouteHealth(r.routes(), func(peerKey string) bool {
+		state, err := r.statusRecorder.GetPeer(peerKey)
+		return err == nil && state.ConnStatus == peer.StatusConnected
+	})
+
+	report.ConnectionSetups = toConnectionSetups(r.statusRecorder.TakeSetupStats())
+	report.ForwardedTraffic = r.forwardedTraffic()
+	report.RoutedNetworks = RoutedNetworks(r.statusRecorder.GetLocalPeerState().RoutesReachability)
+
+	return report
+}
+
+// forwardedTraffic returns the traffic routed for the peers since the previous report
+func (r *Reporter) forwardedTraffic() []*mgmProto.ForwardedTraffic {
+	if r.forwarded == nil {
+		return nil
+	}
+
+	current, err := r.forwarded()
+	if err != nil {
+		log.Debugf("failed to get the routed traffic: %v", err)
+		return nil
+	}
+
+	traffic := trafficSince(r.lastForwarded, current)
+	r.lastForwarded = current
+	return traffic
+}
+
+// trafficSince returns the traffic counted between the readings of the counters sorted by peer address. A counter
+// lower than at the previous reading was reset and counts from zero.
+func trafficSince(previous, current map[netip.Addr]firewall.ForwardedTraffic) []*mgmProto.ForwardedTraffic {
+	since := func(previous, current uint64) uint64 {
+		if current < previous {
+			return current
+		}
+		return current - previous
+	}
+
+	addrs := make([]netip.Addr, 0, len(current))
+	for addr := range current {
+		addrs = append(addrs, addr)
+	}
+	slices.SortFunc(addrs, netip.Addr.Compare)
+
+	var traffic []*mgmProto.ForwardedTraffic
+	for _, addr := range addrs {
+		c, p := current[addr], previous[addr]
+		rx, tx := since(p.RxBytes, c.RxBytes), since(p.TxBytes, c.TxBytes)
+		if rx == 0 && tx == 0 {
+			continue
+		}
+		traffic = append(traffic, &mgmProto.ForwardedTraffic{PeerIp: addr.String(), RxBytes: rx, TxBytes: tx})
+	}
+	return traffic
+}
+
+// RouteHealth returns the health of the routes, a route is healthy when a routing peer of its high availability group
+// is connected. The routes are sorted by ID.
+func RouteHealth(routes route.HAMap, connected func(peerKey string) bool) []*mgmProto.RouteHealth {
+	var health []*mgmProto.RouteHealth
+	for _, haRoutes := range routes {
+		healthy := slices.ContainsFunc(haRoutes, func(r *route.Route) bool { return connected(r.Peer) })
+		for _, r := range haRoutes {
+			health = append(health, &mgmProto.RouteHealth{Id: string(r.ID), Healthy: healthy})
+		}
+	}
+	slices.SortFunc(health, func(a, b *mgmProto.RouteHealth) int { return strings.Compare(a.Id, b.Id) })
+	return health
+}
+
+// RoutedNetworks returns the reachability of the routed networks sorted by network
+func RoutedNetworks(reachability map[string]peer.RouteReachability) []*mgmProto.NetworkReachability {
+	networks := make([]*mgmProto.NetworkReachability, 0, len(reachability))
+	for network, r := range reachability {
+		n := &mgmProto.NetworkReachability{
+			RouteId:   r.RouteID,
+			Network:   network,
+			Reachable: r.Reachable,
+			Error:     r.Error,
+		}
+		if r.Target.IsValid() {
+			n.Target = r.Target.String()
+		}
+		if r.Latency > 0 {
+			n.Latency = durationpb.New(r.Latency)
+		}
+		networks = append(networks, n)
+	}
+	slices.SortFunc(networks, func(a, b *mgmProto.NetworkReachability) int { return strings.Compare(a.Network, b.Network) })
+	return networks
+}
+
+func toConnectionSetups(stats []setup.Stats) []*mgmProto.ConnectionSetupStats {
+	setups := make([]*mgmProto.ConnectionSetupStats, 0, len(stats))
+	for _, s := range stats {
+		setups = append(setups, &mgmProto.ConnectionSetupStats{
+			Phase:     string(s.Phase),
+			Successes: uint32(s.Successes),
+			Failures:  uint32(s.Failures),
+			Median:    durationpb.New(s.Median),
+			P95:       durationpb.New(s.P95),
+		})
+	}
+	return setups
+}
+
+func toReachability(results []relay.ProbeResult) []*mgmProto.ServerReachability {
+	reachability := make([]*mgmProto.ServerReachability, 0, len(results))
+	for _, result := range results {
+		r := &mgmProto.ServerReachability{Uri: result.URI, Reachable: result.Err == nil}
+		if result.Err != nil {
+			r.Error = result.Err.Error()
+		}
+		reachability = append(reachability, r)
+	}
+	return reachability
+}
@@ -0,0 +1,76 @@
+package connectivity
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	firewall "github.com/netbirdio/netbird/client/firewall/manager"
+	"github.com/netbirdio/netbird/client/internal/peer"
+	mgmProto "github.com/netbirdio/netbird/management/proto"
+	"github.com/netbirdio/netbird/route"
+)
+
+func TestRouteHealth(t *testing.T) {
+	routes := route.HAMap{
+		"corp|10.0.0.0/8": {
+			{ID: "corp-1", Peer: "peer1"},
+			{ID: "corp-2", Peer: "peer2"},
+		},
+		"exit|0.0.0.0/0": {
+			{ID: "exit", Peer: "peer3"},
+		},
+	}
+	connected := func(peerKey string) bool {
+		return peerKey == "peer2"
+	}
+
+	expected := []*mgmProto.RouteHealth{
+		{Id: "corp-1", Healthy: true},
+		{Id: "corp-2", Healthy: true},
+		{Id: "exit", Healthy: false},
+	}
+	assert.Equal(t, expected, RouteHealth(routes, connected), "a connected routing peer should keep the whole group healthy")
+	assert.Empty(t, RouteHealth(nil, connected))
+}
+
+func TestTrafficSince(t *testing.T) {
+	peer1 := netip.MustParseAddr("100.64.0.1")
+	peer2 := netip.MustParseAddr("100.64.0.2")
+	peer3 := netip.MustParseAddr("100.64.0.10")
+
+	previous := map[netip.Addr]firewall.ForwardedTraffic{
+		peer1: {RxBytes: 1000, TxBytes: 100},
+		peer2: {RxBytes: 500, TxBytes: 50},
+		peer3: {RxBytes: 5000, TxBytes: 500},
+	}
+	current := map[netip.Addr]firewall.ForwardedTraffic{
+		peer3: {RxBytes: 200, TxBytes: 20},
+		peer1: {RxBytes: 1500, TxBytes: 100},
+		peer2: {RxBytes: 500, TxBytes: 50},
+	}
+
+	expected := []*mgmProto.ForwardedTraffic{
+		{PeerIp: "100.64.0.1", RxBytes: 500},
+		{PeerIp: "100.64.0.10", RxBytes: 200, TxBytes: 20},
+	}
+	assert.Equal(t, expected, trafficSince(previous, current), "the idle peers should be left out and the reset counters count from zero")
+	assert.Len(t, trafficSince(nil, current), 3)
+}
+
+func TestRoutedNetworks(t *testing.T) {
+	reachability := map[string]peer.RouteReachability{
+		"192.168.0.0/24": {RouteID: "office", Error: "no route to 192.168.0.1: route not found", Target: netip.MustParseAddr("192.168.0.1")},
+		"10.0.0.0/8":     {RouteID: "corp", Reachable: true, Target: netip.MustParseAddr("10.0.0.1"), Latency: 3 * time.Millisecond},
+	}
+
+	expected := []*mgmProto.NetworkReachability{
+		{RouteId: "corp", Network: "10.0.0.0/8", Reachable: true, Target: "10.0.0.1", Latency: durationpb.New(3 * time.Millisecond)},
+		{RouteId: "office", Network: "192.168.0.0/24", Target: "192.168.0.1", Error: "no route to 192.168.0.1: route not found"},
+	}
+	assert.Equal(t, expected, RoutedNetworks(reachability))
+	assert.Empty(t, RoutedNetworks(nil))
+}
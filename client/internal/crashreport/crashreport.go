@@ -0,0 +1,230 @@
+// Package crashreport captures the fatal crashes of the client and turns them into reports, saved locally and
+// optionally submitted to an endpoint configured by the operator.
+package crashreport
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/xid"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/client/anonymize"
+	"github.com/netbirdio/netbird/version"
+)
+
+const (
+	// crashOutputFile receives the crash output of the running process
+	crashOutputFile = "crash.out"
+	// headerPrefix starts the header line written to the crash output before the crash
+	headerPrefix = "netbird "
+	reportPrefix = "crash-"
+	reportSuffix = ".json"
+
+	// maxReports is the number of reports kept in the directory, the oldest ones are removed
+	maxReports = 10
+	// maxCrashOutput limits the crash output read into a report, the goroutine stacks of a busy client can be large
+	maxCrashOutput = 4 << 20
+
+	submitTimeout = 30 * time.Second
+)
+
+// Report of a crash of the client
+type Report struct {
+	ID string `json:"id"`
+	// Time is the time the crash output was last written
+	Time      time.Time `json:"time"`
+	Version   string    `json:"version"`
+	OS        string    `json:"os"`
+	Arch      string    `json:"arch"`
+	GoVersion string    `json:"go_version"`
+	// Panic is the first line of the crash output, e.g. the panic message
+	Panic string `json:"panic"`
+	// Stacks are the anonymized goroutine stacks of the crash output
+	Stacks string `json:"stacks"`
+	// Config is the anonymized configuration summary of the client
+	Config map[string]string `json:"config,omitempty"`
+}
+
+// Reporter captures the crashes of the process in its directory
+type Reporter struct {
+	dir string
+}
+
+// NewReporter returns a reporter saving the crash output and the reports in the directory
+func NewReporter(dir string) *Reporter {
+	return &Reporter{dir: dir}
+}
+
+// Install makes the runtime write the output of fatal crashes, like unrecovered panics in any goroutine, to the
+// crash output file. Collect must be called before, the crash output of the previous run is overwritten.
+func (r *Reporter) Install() error {
+	if err := os.MkdirAll(r.dir, 0700); err != nil {
+		return fmt.Errorf("create crash directory: %w", err)
+	}
+
+	f, err := os.OpenFile(filepath.Join(r.dir, crashOutputFile), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("open crash output: %w", err)
+	}
+	defer func() {
+		// the runtime keeps a duplicate of the descriptor
+		if err := f.Close(); err != nil {
+			log.Debugf("failed to close crash output: %v", err)
+		}
+	}()
+
+	header := fmt.Sprintf("%s%s %s/%s %s\n", headerPrefix, version.NetbirdVersion(), runtime.GOOS, runtime.GOARCH, runtime.Version())
+	if _, err := f.WriteString(header); err != nil {
+		return fmt.Errorf("write crash output header: %w", err)
+	}
+
+	if err := debug.SetCrashOutput(f, debug.CrashOptions{}); err != nil {
+		return fmt.Errorf("set crash output: %w", err)
+	}
+	return nil
+}
+
+// Collect returns the report of the crash of the previous run, or nil if it didn't crash. The report isn't saved.
+func (r *Reporter) Collect() (*Report, error) {
+	path := filepath.Join(r.dir, crashOutputFile)
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open crash output: %w", err)
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			log.Debugf("failed to close crash output: %v", err)
+		}
+	}()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("stat crash output: %w", err)
+	}
+
+	output, err := io.ReadAll(io.LimitReader(f, maxCrashOutput))
+	if err != nil {
+		return nil, fmt.Errorf("read crash output: %w", err)
+	}
+
+	report := &Report{
+		ID:   xid.New().String(),
+		Time: info.ModTime().UTC(),
+	}
+
+	header, stacks, _ := bytes.Cut(output, []byte("\n"))
+	if !bytes.HasPrefix(header, []byte(headerPrefix)) {
+		// written by an older client without the header
+		stacks = output
+	} else if fields := strings.Fields(strings.TrimPrefix(string(header), headerPrefix)); len(fields) == 3 {
+		report.Version = fields[0]
+		report.OS, report.Arch, _ = strings.Cut(fields[1], "/")
+		report.GoVersion = fields[2]
+	}
+
+	if len(bytes.TrimSpace(stacks)) == 0 {
+		return nil, nil
+	}
+
+	anonymizer := anonymize.NewAnonymizer(anonymize.DefaultAddresses())
+	var anonymized strings.Builder
+	scanner := bufio.NewScanner(bytes.NewReader(stacks))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxCrashOutput)
+	for scanner.Scan() {
+		line := anonymizer.AnonymizeString(scanner.Text())
+		if report.Panic == "" && strings.TrimSpace(line) != "" {
+			report.Panic = line
+		}
+		anonymized.WriteString(line + "\n")
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scan crash output: %w", err)
+	}
+	report.Stacks = anonymized.String()
+
+	return report, nil
+}
+
+// Save writes the report to the directory and removes the oldest reports above the limit
+func (r *Reporter) Save(report *Report) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal report: %w", err)
+	}
+
+	path := filepath.Join(r.dir, reportPrefix+report.ID+reportSuffix)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("write report: %w", err)
+	}
+
+	reports, err := r.ReportFiles()
+	if err != nil {
+		return fmt.Errorf("list reports: %w", err)
+	}
+	for len(reports) > maxReports {
+		if err := os.Remove(reports[0]); err != nil {
+			log.Warnf("failed to remove crash report %s: %v", reports[0], err)
+		}
+		reports = reports[1:]
+	}
+	return nil
+}
+
+// ReportFiles returns the paths of the saved reports, the oldest first
+func (r *Reporter) ReportFiles() ([]string, error) {
+	// report IDs are xids, they sort by creation time
+	reports, err := filepath.Glob(filepath.Join(r.dir, reportPrefix+"*"+reportSuffix))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(reports)
+	return reports, nil
+}
+
+// Submit posts the report as JSON to the endpoint
+func Submit(ctx context.Context, endpoint string, report *Report) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("marshal report: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, submitTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.Debugf("failed to close response body: %v", err)
+		}
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+	return nil
+}
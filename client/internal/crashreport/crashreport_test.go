@@ -0,0 +1,63 @@
+package crashreport
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCrashOutput = `netbird 0.36.0 linux/amd64 go1.23.4
+panic: dial 1.2.3.4:51820 failed
+
+goroutine 1 [running]:
+github.com/netbirdio/netbird/client/internal.(*Engine).Start(0xc000123400)
+	/src/client/internal/engine.go:321 +0x1d
+`
+
+func TestReporter_Collect(t *testing.T) {
+	dir := t.TempDir()
+	reporter := NewReporter(dir)
+
+	report, err := reporter.Collect()
+	require.NoError(t, err)
+	assert.Nil(t, report, "no report is expected without crash output")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, crashOutputFile), []byte("netbird 0.36.0 linux/amd64 go1.23.4\n"), 0600))
+	report, err = reporter.Collect()
+	require.NoError(t, err)
+	assert.Nil(t, report, "no report is expected for a run that didn't crash")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, crashOutputFile), []byte(testCrashOutput), 0600))
+	report, err = reporter.Collect()
+	require.NoError(t, err)
+	require.NotNil(t, report)
+
+	assert.Equal(t, "0.36.0", report.Version)
+	assert.Equal(t, "linux", report.OS)
+	assert.Equal(t, "amd64", report.Arch)
+	assert.Equal(t, "go1.23.4", report.GoVersion)
+	assert.Equal(t, "panic: dial 198.51.100.0:51820 failed", report.Panic)
+	assert.Contains(t, report.Stacks, "/src/client/internal/engine.go:321 +0x1d")
+	assert.NotContains(t, report.Stacks, "1.2.3.4")
+}
+
+func TestReporter_Save(t *testing.T) {
+	dir := t.TempDir()
+	reporter := NewReporter(dir)
+
+	var ids []string
+	for i := 0; i < maxReports+2; i++ {
+		report := &Report{ID: fmt.Sprintf("%02d", i), Panic: "panic"}
+		ids = append(ids, report.ID)
+		require.NoError(t, reporter.Save(report))
+	}
+
+	reports, err := reporter.ReportFiles()
+	require.NoError(t, err)
+	require.Len(t, reports, maxReports)
+	assert.Equal(t, filepath.Join(dir, reportPrefix+ids[2]+reportSuffix), reports[0], "the oldest reports should be removed")
+}
@@ -5,13 +5,29 @@ package dns
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/godbus/dbus/v5"
 	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/client/internal/statemanager"
 )
 
-const dbusDefaultFlag = 0
+const (
+	dbusDefaultFlag = 0
+
+	dbusInterface               = "org.freedesktop.DBus"
+	dbusNameOwnerChangedMember  = "NameOwnerChanged"
+	logindManagerInterface      = "org.freedesktop.login1.Manager"
+	logindPrepareForSleepMember = "PrepareForSleep"
+	logindPrepareForSleepSignal = logindManagerInterface + "." + logindPrepareForSleepMember
+	dbusNameOwnerChangedSignal  = dbusInterface + "." + dbusNameOwnerChangedMember
+
+	// dnsReapplyDelay gives the DNS service time to settle after a resume or a restart before the configuration is
+	// applied again, it also merges the signals arriving together
+	dnsReapplyDelay = 2 * time.Second
+)
 
 func isDbusListenerRunning(dest string, path dbus.ObjectPath) bool {
 	obj, closeConn, err := getDbusObject(dest, path)
@@ -48,3 +64,156 @@ func getDbusObject(dest string, path dbus.ObjectPath) (dbus.BusObject, func(), e
 
 	return obj, closeFunc, nil
 }
+
+// watchDbusSignals calls onSignal for the signals matching one of the matches until the returned connection is closed
+func watchDbusSignals(matches [][]dbus.MatchOption, onSignal func(*dbus.Signal)) (*dbus.Conn, error) {
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		return nil, fmt.Errorf("connect dbus: %w", err)
+	}
+
+	for _, match := range matches {
+		if err := conn.AddMatchSignal(match...); err != nil {
+			if closeErr := conn.Close(); closeErr != nil {
+				log.Warnf("got an error closing dbus connection, err: %s", closeErr)
+			}
+			return nil, fmt.Errorf("add signal match: %w", err)
+		}
+	}
+
+	signals := make(chan *dbus.Signal, 16)
+	conn.Signal(signals)
+	go func() {
+		// the channel is closed with the connection
+		for signal := range signals {
+			onSignal(signal)
+		}
+	}()
+
+	return conn, nil
+}
+
+// isResumeOrRestartSignal returns true if the signal reports that the system resumed from sleep or that the D-Bus
+// service dest (re)started, in both cases the DNS settings of the interface may be gone
+func isResumeOrRestartSignal(signal *dbus.Signal, dest string) bool {
+	switch signal.Name {
+	case logindPrepareForSleepSignal:
+		sleeping, ok := signalArg[bool](signal, 0)
+		return ok && !sleeping
+	case dbusNameOwnerChangedSignal:
+		name, ok := signalArg[string](signal, 0)
+		newOwner, newOk := signalArg[string](signal, 2)
+		return ok && newOk && name == dest && newOwner != ""
+	default:
+		return false
+	}
+}
+
+func signalArg[T any](signal *dbus.Signal, idx int) (T, bool) {
+	var zero T
+	if len(signal.Body) <= idx {
+		return zero, false
+	}
+	value, ok := signal.Body[idx].(T)
+	return value, ok
+}
+
+// dnsReapplier applies the last host DNS configuration again when the system resumed from sleep or when the DNS
+// service restarted. Both can reset the DNS settings of the interface, e.g. resolved forgets the link settings on a
+// restart and some VPN clients revert the links on resume.
+type dnsReapplier struct {
+	mu           sync.Mutex
+	dest         string
+	apply        func(HostDNSConfig, *statemanager.Manager) error
+	config       *HostDNSConfig
+	stateManager *statemanager.Manager
+	conn         *dbus.Conn
+	timer        *time.Timer
+}
+
+func newDNSReapplier(dest string, apply func(HostDNSConfig, *statemanager.Manager) error) *dnsReapplier {
+	return &dnsReapplier{
+		dest:  dest,
+		apply: apply,
+	}
+}
+
+// update stores the applied configuration, the signals are watched from the first update until stop
+func (r *dnsReapplier) update(config HostDNSConfig, stateManager *statemanager.Manager) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.config = &config
+	r.stateManager = stateManager
+	if r.conn != nil {
+		return
+	}
+
+	conn, err := watchDbusSignals([][]dbus.MatchOption{
+		{
+			dbus.WithMatchInterface(logindManagerInterface),
+			dbus.WithMatchMember(logindPrepareForSleepMember),
+		},
+		{
+			dbus.WithMatchSender(dbusInterface),
+			dbus.WithMatchInterface(dbusInterface),
+			dbus.WithMatchMember(dbusNameOwnerChangedMember),
+			dbus.WithMatchArg(0, r.dest),
+		},
+	}, r.onSignal)
+	if err != nil {
+		log.Warnf("failed to watch for resume and restarts of %s, the DNS settings won't be restored: %v", r.dest, err)
+		return
+	}
+	r.conn = conn
+}
+
+// stop stops watching the signals and forgets the configuration
+func (r *dnsReapplier) stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.config = nil
+	if r.timer != nil {
+		r.timer.Stop()
+		r.timer = nil
+	}
+	if r.conn != nil {
+		if err := r.conn.Close(); err != nil {
+			log.Warnf("got an error closing dbus connection, err: %s", err)
+		}
+		r.conn = nil
+	}
+}
+
+func (r *dnsReapplier) onSignal(signal *dbus.Signal) {
+	if !isResumeOrRestartSignal(signal, r.dest) {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.config == nil {
+		return
+	}
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+	r.timer = time.AfterFunc(dnsReapplyDelay, r.reapply)
+}
+
+func (r *dnsReapplier) reapply() {
+	r.mu.Lock()
+	config, stateManager := r.config, r.stateManager
+	r.mu.Unlock()
+
+	if config == nil {
+		return
+	}
+
+	log.Infof("applying the DNS configuration again after resume or restart of %s", r.dest)
+	if err := r.apply(*config, stateManager); err != nil {
+		log.Errorf("failed to apply the DNS configuration again: %v", err)
+	}
+}
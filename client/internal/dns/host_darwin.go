@@ -14,6 +14,7 @@ import (
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/netbirdio/netbird/client/internal/profiles"
 	"github.com/netbirdio/netbird/client/internal/statemanager"
 )
 
@@ -85,7 +86,7 @@ func (s *systemConfigurator) applyDNSConfig(config HostDNSConfig, stateManager *
 		searchDomains = append(searchDomains, dConf.Domain)
 	}
 
-	matchKey := getKeyWithInput(netbirdDNSStateKeyFormat, matchSuffix)
+	matchKey := netbirdDNSStateKey(matchSuffix)
 	if len(matchDomains) != 0 {
 		err = s.addMatchDomains(matchKey, strings.Join(matchDomains, " "), config.ServerIP, config.ServerPort)
 	} else {
@@ -96,7 +97,7 @@ func (s *systemConfigurator) applyDNSConfig(config HostDNSConfig, stateManager *
 		return fmt.Errorf("add match domains: %w", err)
 	}
 
-	searchKey := getKeyWithInput(netbirdDNSStateKeyFormat, searchSuffix)
+	searchKey := netbirdDNSStateKey(searchSuffix)
 	if len(searchDomains) != 0 {
 		err = s.addSearchDomains(searchKey, strings.Join(searchDomains, " "), config.ServerIP, config.ServerPort)
 	} else {
@@ -142,7 +143,7 @@ func (s *systemConfigurator) restoreHostDNS() error {
 func (s *systemConfigurator) getRemovableKeysWithDefaults() []string {
 	if len(s.createdKeys) == 0 {
 		// return defaults for startup calls
-		return []string{getKeyWithInput(netbirdDNSStateKeyFormat, searchSuffix), getKeyWithInput(netbirdDNSStateKeyFormat, matchSuffix)}
+		return []string{netbirdDNSStateKey(searchSuffix), netbirdDNSStateKey(matchSuffix)}
 	}
 
 	keys := make([]string, 0, len(s.createdKeys))
@@ -170,7 +171,7 @@ func (s *systemConfigurator) addLocalDNS() error {
 		log.Errorf("Unable to get system DNS configuration")
 		return err
 	}
-	localKey := getKeyWithInput(netbirdDNSStateKeyFormat, localSuffix)
+	localKey := netbirdDNSStateKey(localSuffix)
 	if s.systemDNSSettings.ServerIP != "" && len(s.systemDNSSettings.Domains) != 0 {
 		err := s.addSearchDomains(localKey, strings.Join(s.systemDNSSettings.Domains, " "), s.systemDNSSettings.ServerIP, s.systemDNSSettings.ServerPort)
 		if err != nil {
@@ -355,6 +356,12 @@ func getKeyWithInput(format, key string) string {
 	return fmt.Sprintf(format, key)
 }
 
+// netbirdDNSStateKey returns the key of the NetBird DNS settings, the daemons of the profiles suffix the keys with the
+// name of the profile
+func netbirdDNSStateKey(suffix string) string {
+	return getKeyWithInput(netbirdDNSStateKeyFormat, suffix+profiles.Suffix())
+}
+
 func buildAddCommandLine(key, value string) string {
 	return buildCommandLine("d.add", key, value)
 }
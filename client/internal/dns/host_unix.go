@@ -11,6 +11,8 @@ import (
 	"strings"
 
 	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/client/internal/profiles"
 )
 
 const (
@@ -19,6 +21,7 @@ const (
 	networkManager
 	systemdManager
 	resolvConfManager
+	networkManagerDnsmasqManager
 )
 
 type osManagerType int
@@ -35,6 +38,8 @@ func (t osManagerType) String() string {
 		return "systemd"
 	case resolvConfManager:
 		return "resolvconf"
+	case networkManagerDnsmasqManager:
+		return "networkManagerDnsmasq"
 	default:
 		return "unknown"
 	}
@@ -52,6 +57,12 @@ func newHostManager(wgInterface string) (hostManager, error) {
 	}
 
 	log.Infof("System DNS manager discovered: %s", osManager)
+	// resolv.conf has room for the resolver of one daemon, it's configured by the default profile
+	if profile := profiles.Current(); profile != "" && (osManager == fileManager || osManager == netbirdManager) {
+		log.Warnf("the %s DNS manager is used by the default profile, not configuring the system DNS of profile %s", osManager, profile)
+		return &noopHostConfigurator{}, nil
+	}
+
 	mgr, err := newHostManagerFromType(wgInterface, osManager)
 	// need to explicitly return nil mgr on error to avoid returning a non-nil interface containing a nil value
 	if err != nil {
@@ -69,6 +80,8 @@ func newHostManagerFromType(wgInterface string, osManager osManagerType) (restor
 		return newSystemdDbusConfigurator(wgInterface)
 	case resolvConfManager:
 		return newResolvConfConfigurator(wgInterface)
+	case networkManagerDnsmasqManager:
+		return newNetworkManagerDnsmasqConfigurator(wgInterface)
 	default:
 		return newFileConfigurator()
 	}
@@ -97,8 +110,10 @@ func getOSDNSManagerType() (osManagerType, error) {
 		if strings.Contains(text, fileGeneratedResolvConfContentHeader) {
 			return netbirdManager, nil
 		}
-		if strings.Contains(text, "NetworkManager") && isDbusListenerRunning(networkManagerDest, networkManagerDbusObjectNode) && isNetworkManagerSupported() {
-			return networkManager, nil
+		if strings.Contains(text, "NetworkManager") && isDbusListenerRunning(networkManagerDest, networkManagerDbusObjectNode) {
+			if managerType, ok := getNetworkManagerDNSManagerType(); ok {
+				return managerType, nil
+			}
 		}
 		if strings.Contains(text, "systemd-resolved") && isSystemdResolvedRunning() {
 			if checkStub() {
@@ -12,6 +12,7 @@ import (
 	"golang.org/x/sys/windows/registry"
 
 	nberrors "github.com/netbirdio/netbird/client/errors"
+	"github.com/netbirdio/netbird/client/internal/profiles"
 	"github.com/netbirdio/netbird/client/internal/statemanager"
 )
 
@@ -22,6 +23,7 @@ var (
 	refreshPolicyExFn = userenv.NewProc("RefreshPolicyEx")
 )
 
+// the daemons of the profiles suffix the NRPT rules with the name of the profile
 const (
 	dnsPolicyConfigMatchPath    = `SYSTEM\CurrentControlSet\Services\Dnscache\Parameters\DnsPolicyConfig\NetBird-Match`
 	gpoDnsPolicyRoot            = `SOFTWARE\Policies\Microsoft\Windows NT\DNSClient\DnsPolicyConfig`
@@ -132,7 +134,7 @@ func (r *registryConfigurator) addDNSMatchPolicy(domains []string, ip string) er
 	// if the gpo key is present, we need to put our DNS settings there, otherwise our config might be ignored
 	// see https://learn.microsoft.com/en-us/openspecs/windows_protocols/ms-gpnrpt/8cc31cb9-20cb-4140-9e85-3e08703b4745
 	if r.gpo {
-		if err := r.configureDNSPolicy(gpoDnsPolicyConfigMatchPath, domains, ip); err != nil {
+		if err := r.configureDNSPolicy(gpoDnsPolicyConfigMatchPath+profiles.Suffix(), domains, ip); err != nil {
 			return fmt.Errorf("configure GPO DNS policy: %w", err)
 		}
 
@@ -140,7 +142,7 @@ func (r *registryConfigurator) addDNSMatchPolicy(domains []string, ip string) er
 			log.Warnf("failed to refresh group policy: %v", err)
 		}
 	} else {
-		if err := r.configureDNSPolicy(dnsPolicyConfigMatchPath, domains, ip); err != nil {
+		if err := r.configureDNSPolicy(dnsPolicyConfigMatchPath+profiles.Suffix(), domains, ip); err != nil {
 			return fmt.Errorf("configure local DNS policy: %w", err)
 		}
 	}
@@ -241,11 +243,11 @@ func (r *registryConfigurator) restoreHostDNS() error {
 
 func (r *registryConfigurator) removeDNSMatchPolicies() error {
 	var merr *multierror.Error
-	if err := removeRegistryKeyFromDNSPolicyConfig(dnsPolicyConfigMatchPath); err != nil {
+	if err := removeRegistryKeyFromDNSPolicyConfig(dnsPolicyConfigMatchPath + profiles.Suffix()); err != nil {
 		merr = multierror.Append(merr, fmt.Errorf("remove local registry key: %w", err))
 	}
 
-	if err := removeRegistryKeyFromDNSPolicyConfig(gpoDnsPolicyConfigMatchPath); err != nil {
+	if err := removeRegistryKeyFromDNSPolicyConfig(gpoDnsPolicyConfigMatchPath + profiles.Suffix()); err != nil {
 		merr = multierror.Append(merr, fmt.Errorf("remove GPO registry key: %w", err))
 	}
 
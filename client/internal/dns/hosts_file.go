@@ -0,0 +1,141 @@
+package dns
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/miekg/dns"
+	log "github.com/sirupsen/logrus"
+
+	nbdns "github.com/netbirdio/netbird/dns"
+)
+
+const (
+	hostsFileBlockStart = "# NetBird hosts start, managed by NetBird, don't edit"
+	hostsFileBlockEnd   = "# NetBird hosts end"
+)
+
+// hostsFile materializes the A and AAAA records of the custom zones in the hosts file of the system. It's used when
+// NetBird can't take over the system DNS, the records are kept between the NetBird markers and removed on stop.
+type hostsFile struct {
+	path string
+	// content is the last written NetBird block, the file isn't written if it's unchanged
+	content string
+}
+
+func newHostsFile(path string) *hostsFile {
+	return &hostsFile{path: path}
+}
+
+// update replaces the NetBird records of the hosts file with the records of the zones
+func (h *hostsFile) update(zones []nbdns.CustomZone) error {
+	block := hostsFileBlock(zones)
+	if block == h.content {
+		return nil
+	}
+
+	if err := h.write(block); err != nil {
+		return err
+	}
+	h.content = block
+	log.Debugf("updated the NetBird records in %s", h.path)
+	return nil
+}
+
+// cleanup removes the NetBird records from the hosts file, including the ones left by a crash
+func (h *hostsFile) cleanup() error {
+	if err := h.write(""); err != nil {
+		return err
+	}
+	h.content = ""
+	return nil
+}
+
+func (h *hostsFile) write(block string) error {
+	stat, err := os.Stat(h.path)
+	if err != nil {
+		return fmt.Errorf("stat %s: %w", h.path, err)
+	}
+
+	content, err := os.ReadFile(h.path)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", h.path, err)
+	}
+
+	updated := replaceHostsFileBlock(content, block)
+	if bytes.Equal(content, updated) {
+		return nil
+	}
+
+	// the file is written in place, in containers the hosts file is often a bind mount that can't be replaced
+	if err := os.WriteFile(h.path, updated, stat.Mode()); err != nil {
+		return fmt.Errorf("write %s: %w", h.path, err)
+	}
+	return nil
+}
+
+// hostsFileBlock returns the hosts file lines of the A and AAAA records between the NetBird markers, one line per
+// address with all its names
+func hostsFileBlock(zones []nbdns.CustomZone) string {
+	names := make(map[string][]string)
+	var addresses []string
+	for _, zone := range zones {
+		for _, record := range zone.Records {
+			if record.Type != int(dns.TypeA) && record.Type != int(dns.TypeAAAA) {
+				continue
+			}
+
+			name := strings.TrimSuffix(record.Name, ".")
+			if _, ok := names[record.RData]; !ok {
+				addresses = append(addresses, record.RData)
+			}
+			if !slices.Contains(names[record.RData], name) {
+				names[record.RData] = append(names[record.RData], name)
+			}
+		}
+	}
+
+	if len(addresses) == 0 {
+		return ""
+	}
+
+	var block strings.Builder
+	block.WriteString(hostsFileBlockStart + "\n")
+	for _, address := range addresses {
+		block.WriteString(address + "\t" + strings.Join(names[address], " ") + "\n")
+	}
+	block.WriteString(hostsFileBlockEnd + "\n")
+	return block.String()
+}
+
+// replaceHostsFileBlock replaces the NetBird block of the hosts file content, the block is appended if there's none
+func replaceHostsFileBlock(content []byte, block string) []byte {
+	var kept bytes.Buffer
+	var inBlock bool
+	for _, line := range strings.SplitAfter(string(content), "\n") {
+		switch strings.TrimSpace(line) {
+		case hostsFileBlockStart:
+			inBlock = true
+			continue
+		case hostsFileBlockEnd:
+			inBlock = false
+			continue
+		}
+		if !inBlock {
+			kept.WriteString(line)
+		}
+	}
+
+	if block == "" {
+		return kept.Bytes()
+	}
+
+	if kept.Len() > 0 && !bytes.HasSuffix(kept.Bytes(), []byte("\n")) {
+		kept.WriteString("\n")
+	}
+	kept.WriteString(block)
+	return kept.Bytes()
+}
@@ -0,0 +1,7 @@
+//go:build !windows
+
+package dns
+
+func hostsFilePath() string {
+	return "/etc/hosts"
+}
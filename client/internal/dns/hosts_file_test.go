@@ -0,0 +1,56 @@
+package dns
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	nbdns "github.com/netbirdio/netbird/dns"
+)
+
+func TestHostsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hosts")
+	original := "127.0.0.1\tlocalhost\n::1\tlocalhost"
+	require.NoError(t, os.WriteFile(path, []byte(original), 0644))
+
+	zones := []nbdns.CustomZone{
+		{
+			Domain: "netbird.cloud.",
+			Records: []nbdns.SimpleRecord{
+				{Name: "peer-a.netbird.cloud.", Type: int(dns.TypeA), Class: nbdns.DefaultClass, TTL: 300, RData: "100.64.0.1"},
+				{Name: "peer-a.netbird.cloud.", Type: int(dns.TypeAAAA), Class: nbdns.DefaultClass, TTL: 300, RData: "fd00::1"},
+				{Name: "alias.netbird.cloud.", Type: int(dns.TypeA), Class: nbdns.DefaultClass, TTL: 300, RData: "100.64.0.1"},
+				{Name: "peer-b.netbird.cloud.", Type: int(dns.TypeA), Class: nbdns.DefaultClass, TTL: 300, RData: "100.64.0.2"},
+				{Name: "web.netbird.cloud.", Type: int(dns.TypeCNAME), Class: nbdns.DefaultClass, TTL: 300, RData: "peer-b.netbird.cloud."},
+			},
+		},
+	}
+
+	h := newHostsFile(path)
+	require.NoError(t, h.update(zones))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, original+"\n"+hostsFileBlockStart+"\n"+
+		"100.64.0.1\tpeer-a.netbird.cloud alias.netbird.cloud\n"+
+		"fd00::1\tpeer-a.netbird.cloud\n"+
+		"100.64.0.2\tpeer-b.netbird.cloud\n"+
+		hostsFileBlockEnd+"\n", string(content))
+
+	zones[0].Records = zones[0].Records[3:4]
+	require.NoError(t, newHostsFile(path).update(zones), "the block of a previous run should be replaced")
+
+	content, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, original+"\n"+hostsFileBlockStart+"\n100.64.0.2\tpeer-b.netbird.cloud\n"+hostsFileBlockEnd+"\n",
+		string(content))
+
+	require.NoError(t, h.cleanup())
+	content, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, original+"\n", string(content), "only the NetBird records should be removed")
+}
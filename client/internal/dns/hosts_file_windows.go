@@ -0,0 +1,10 @@
+package dns
+
+import (
+	"os"
+	"path/filepath"
+)
+
+func hostsFilePath() string {
+	return filepath.Join(os.Getenv("SystemRoot"), "System32", "drivers", "etc", "hosts")
+}
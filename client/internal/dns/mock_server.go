@@ -15,6 +15,8 @@ type MockServer struct {
 	UpdateDNSServerFunc   func(serial uint64, update nbdns.Config) error
 	RegisterHandlerFunc   func([]string, dns.Handler, int)
 	DeregisterHandlerFunc func([]string, int)
+	CheckServiceFunc      func() error
+	RestartServiceFunc    func() error
 }
 
 func (m *MockServer) RegisterHandler(domains []string, handler dns.Handler, priority int) {
@@ -68,3 +70,19 @@ func (m *MockServer) SearchDomains() []string {
 // ProbeAvailability mocks implementation of ProbeAvailability from the Server interface
 func (m *MockServer) ProbeAvailability() {
 }
+
+// CheckService mocks implementation of CheckService from the Server interface
+func (m *MockServer) CheckService() error {
+	if m.CheckServiceFunc != nil {
+		return m.CheckServiceFunc()
+	}
+	return nil
+}
+
+// RestartService mocks implementation of RestartService from the Server interface
+func (m *MockServer) RestartService() error {
+	if m.RestartServiceFunc != nil {
+		return m.RestartServiceFunc()
+	}
+	return nil
+}
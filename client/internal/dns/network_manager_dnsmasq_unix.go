@@ -0,0 +1,161 @@
+//go:build (linux && !android) || freebsd
+
+package dns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/client/internal/statemanager"
+)
+
+const (
+	networkManagerDnsmasqConfigFile = "/etc/NetworkManager/dnsmasq.d/netbird.conf"
+	networkManagerDbusReloadMethod  = networkManagerDest + ".Reload"
+	// networkManagerReloadDNSFull restarts the DNS plugin, dnsmasq reads its configuration directory only on start
+	networkManagerReloadDNSFull uint32 = 0x04
+
+	networkManagerDnsmasqConfigHeader = "# Generated by NetBird, don't edit"
+)
+
+// networkManagerDnsmasqConfigurator configures the dnsmasq instance network manager runs as its DNS plugin through
+// the configuration directory of the plugin. It's used with the network manager versions that can't reapply the DNS
+// settings of the NetBird device, instead of rewriting resolv.conf behind the back of network manager.
+type networkManagerDnsmasqConfigurator struct {
+	configPath string
+	ifaceName  string
+}
+
+func newNetworkManagerDnsmasqConfigurator(wgInterface string) (*networkManagerDnsmasqConfigurator, error) {
+	return &networkManagerDnsmasqConfigurator{
+		configPath: networkManagerDnsmasqConfigFile,
+		ifaceName:  wgInterface,
+	}, nil
+}
+
+func (n *networkManagerDnsmasqConfigurator) supportCustomPort() bool {
+	return true
+}
+
+func (n *networkManagerDnsmasqConfigurator) applyDNSConfig(config HostDNSConfig, stateManager *statemanager.Manager) error {
+	serverIP, err := netip.ParseAddr(config.ServerIP)
+	if err != nil {
+		return fmt.Errorf("unable to parse ip address, error: %w", err)
+	}
+
+	content := networkManagerDnsmasqConfig(config, netip.AddrPortFrom(serverIP.Unmap(), uint16(config.ServerPort)))
+	current, err := os.ReadFile(n.configPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("read %s: %w", n.configPath, err)
+	}
+	if string(current) == content {
+		return nil
+	}
+
+	state := &ShutdownState{
+		ManagerType: networkManagerDnsmasqManager,
+		WgIface:     n.ifaceName,
+	}
+	if err := stateManager.UpdateState(state); err != nil {
+		log.Errorf("failed to update shutdown state: %s", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(n.configPath), 0755); err != nil {
+		return fmt.Errorf("create dir %s: %w", filepath.Dir(n.configPath), err)
+	}
+	if err := os.WriteFile(n.configPath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("write %s: %w", n.configPath, err)
+	}
+
+	if config.RouteAll {
+		log.Infof("configured %s:%d as main DNS forwarder for this peer", config.ServerIP, config.ServerPort)
+	}
+	if searchDomains := countSearchDomains(config); searchDomains > 0 {
+		log.Infof("%d search domains are resolved but not added to the search list, the dnsmasq plugin of network manager doesn't support it", searchDomains)
+	}
+
+	if err := reloadNetworkManagerDNS(); err != nil {
+		return fmt.Errorf("reload network manager dns: %w", err)
+	}
+	return nil
+}
+
+func (n *networkManagerDnsmasqConfigurator) restoreHostDNS() error {
+	if err := os.Remove(n.configPath); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("remove %s: %w", n.configPath, err)
+	}
+
+	if err := reloadNetworkManagerDNS(); err != nil {
+		return fmt.Errorf("reload network manager dns: %w", err)
+	}
+	return nil
+}
+
+func (n *networkManagerDnsmasqConfigurator) string() string {
+	return "network-manager-dnsmasq"
+}
+
+func (n *networkManagerDnsmasqConfigurator) restoreUncleanShutdownDNS(*netip.Addr) error {
+	if err := n.restoreHostDNS(); err != nil {
+		return fmt.Errorf("restoring dns via network-manager dnsmasq: %w", err)
+	}
+	return nil
+}
+
+// networkManagerDnsmasqConfig returns the dnsmasq configuration forwarding the domains to the NetBird resolver. If
+// all queries are routed to NetBird, the /#/ domain replaces the upstream servers network manager passes to dnsmasq.
+func networkManagerDnsmasqConfig(config HostDNSConfig, server netip.AddrPort) string {
+	address := server.Addr().String() + "#" + strconv.Itoa(int(server.Port()))
+
+	var content strings.Builder
+	content.WriteString(networkManagerDnsmasqConfigHeader + "\n")
+	for _, dConf := range config.Domains {
+		domain := strings.TrimSuffix(dConf.Domain, ".")
+		if dConf.Disabled || domain == "" {
+			continue
+		}
+		content.WriteString("server=/" + domain + "/" + address + "\n")
+	}
+	if config.RouteAll {
+		content.WriteString("server=/#/" + address + "\n")
+	}
+	return content.String()
+}
+
+func countSearchDomains(config HostDNSConfig) int {
+	var count int
+	for _, dConf := range config.Domains {
+		if !dConf.Disabled && !dConf.MatchOnly {
+			count++
+		}
+	}
+	return count
+}
+
+func reloadNetworkManagerDNS() error {
+	obj, closeConn, err := getDbusObject(networkManagerDest, networkManagerDbusObjectNode)
+	if err != nil {
+		return fmt.Errorf("get nm dbus: %w", err)
+	}
+	defer closeConn()
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+
+	if err := obj.CallWithContext(ctx, networkManagerDbusReloadMethod, dbusDefaultFlag, networkManagerReloadDNSFull).Store(); err != nil {
+		return fmt.Errorf("calling Reload method with context, err: %w", err)
+	}
+	return nil
+}
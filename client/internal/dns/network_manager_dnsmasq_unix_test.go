@@ -0,0 +1,75 @@
+//go:build (linux && !android) || freebsd
+
+package dns
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetworkManagerDnsmasqConfig(t *testing.T) {
+	server := netip.MustParseAddrPort("100.64.0.1:5053")
+
+	config := HostDNSConfig{
+		Domains: []DomainConfig{
+			{Domain: "netbird.cloud"},
+			{Domain: "corp.example.com", MatchOnly: true},
+			{Domain: "disabled.example.com", Disabled: true},
+		},
+	}
+	assert.Equal(t, "# Generated by NetBird, don't edit\n"+
+		"server=/netbird.cloud/100.64.0.1#5053\n"+
+		"server=/corp.example.com/100.64.0.1#5053\n",
+		networkManagerDnsmasqConfig(config, server))
+
+	config.RouteAll = true
+	assert.Equal(t, "# Generated by NetBird, don't edit\n"+
+		"server=/netbird.cloud/100.64.0.1#5053\n"+
+		"server=/corp.example.com/100.64.0.1#5053\n"+
+		"server=/#/100.64.0.1#5053\n",
+		networkManagerDnsmasqConfig(config, server), "all other domains should be routed to NetBird")
+}
+
+func TestIsResumeOrRestartSignal(t *testing.T) {
+	tests := []struct {
+		name   string
+		signal *dbus.Signal
+		want   bool
+	}{
+		{
+			name:   "resumed",
+			signal: &dbus.Signal{Name: logindPrepareForSleepSignal, Body: []any{false}},
+			want:   true,
+		},
+		{
+			name:   "going to sleep",
+			signal: &dbus.Signal{Name: logindPrepareForSleepSignal, Body: []any{true}},
+		},
+		{
+			name:   "service started",
+			signal: &dbus.Signal{Name: dbusNameOwnerChangedSignal, Body: []any{systemdResolvedDest, "", ":1.42"}},
+			want:   true,
+		},
+		{
+			name:   "service stopped",
+			signal: &dbus.Signal{Name: dbusNameOwnerChangedSignal, Body: []any{systemdResolvedDest, ":1.42", ""}},
+		},
+		{
+			name:   "other service started",
+			signal: &dbus.Signal{Name: dbusNameOwnerChangedSignal, Body: []any{networkManagerDest, "", ":1.43"}},
+		},
+		{
+			name:   "invalid body",
+			signal: &dbus.Signal{Name: logindPrepareForSleepSignal},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isResumeOrRestartSignal(tt.signal, systemdResolvedDest))
+		})
+	}
+}
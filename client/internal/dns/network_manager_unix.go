@@ -55,6 +55,7 @@ type networkManagerDbusConfigurator struct {
 	dbusLinkObject dbus.ObjectPath
 	routingAll     bool
 	ifaceName      string
+	reapplier      *dnsReapplier
 }
 
 // the types below are based on dbus specification, each field is mapped to a dbus type
@@ -80,23 +81,35 @@ func (s networkManagerConnSettings) cleanDeprecatedSettings() {
 }
 
 func newNetworkManagerDbusConfigurator(wgInterface string) (*networkManagerDbusConfigurator, error) {
+	linkObject, err := getNetworkManagerDeviceObject(wgInterface)
+	if err != nil {
+		return nil, err
+	}
+
+	configurator := &networkManagerDbusConfigurator{
+		dbusLinkObject: linkObject,
+		ifaceName:      wgInterface,
+	}
+	configurator.reapplier = newDNSReapplier(networkManagerDest, configurator.reapplyDNSConfig)
+
+	return configurator, nil
+}
+
+func getNetworkManagerDeviceObject(wgInterface string) (dbus.ObjectPath, error) {
 	obj, closeConn, err := getDbusObject(networkManagerDest, networkManagerDbusObjectNode)
 	if err != nil {
-		return nil, fmt.Errorf("get nm dbus: %w", err)
+		return "", fmt.Errorf("get nm dbus: %w", err)
 	}
 	defer closeConn()
 	var s string
 	err = obj.Call(networkManagerDbusGetDeviceByIPIfaceMethod, dbusDefaultFlag, wgInterface).Store(&s)
 	if err != nil {
-		return nil, fmt.Errorf("call: %w", err)
+		return "", fmt.Errorf("call: %w", err)
 	}
 
 	log.Debugf("got network manager dbus Link Object: %s from net interface %s", s, wgInterface)
 
-	return &networkManagerDbusConfigurator{
-		dbusLinkObject: dbus.ObjectPath(s),
-		ifaceName:      wgInterface,
-	}, nil
+	return dbus.ObjectPath(s), nil
 }
 
 func (n *networkManagerDbusConfigurator) supportCustomPort() bool {
@@ -167,10 +180,26 @@ func (n *networkManagerDbusConfigurator) applyDNSConfig(config HostDNSConfig, st
 	if err != nil {
 		return fmt.Errorf("reapplying the connection with new settings, error: %w", err)
 	}
+
+	n.reapplier.update(config, stateManager)
 	return nil
 }
 
+// reapplyDNSConfig looks the device up again before applying the configuration, its object path changes when
+// network manager restarts
+func (n *networkManagerDbusConfigurator) reapplyDNSConfig(config HostDNSConfig, stateManager *statemanager.Manager) error {
+	linkObject, err := getNetworkManagerDeviceObject(n.ifaceName)
+	if err != nil {
+		return fmt.Errorf("get device: %w", err)
+	}
+	n.dbusLinkObject = linkObject
+
+	return n.applyDNSConfig(config, stateManager)
+}
+
 func (n *networkManagerDbusConfigurator) restoreHostDNS() error {
+	n.reapplier.stop()
+
 	// once the interface is gone network manager cleans all config associated with it
 	if err := n.deleteConnectionSettings(); err != nil {
 		return fmt.Errorf("delete connection settings: %w", err)
@@ -257,6 +286,28 @@ func (n *networkManagerDbusConfigurator) restoreUncleanShutdownDNS(*netip.Addr)
 	return nil
 }
 
+// getNetworkManagerDNSManagerType returns how the DNS is configured if network manager owns resolv.conf. With the
+// systemd-resolved backend the link is configured in resolved directly, the dnsmasq plugin is configured through its
+// configuration directory if the device settings can't be reapplied.
+func getNetworkManagerDNSManagerType() (osManagerType, bool) {
+	var mode string
+	if err := getNetworkManagerDNSProperty(networkManagerDbusDNSManagerModeProperty, &mode); err != nil {
+		log.Error(err)
+		return 0, false
+	}
+
+	switch {
+	case mode == "systemd-resolved" && isSystemdResolvedRunning() && checkStub():
+		return systemdManager, true
+	case isNetworkManagerSupported():
+		return networkManager, true
+	case mode == "dnsmasq":
+		return networkManagerDnsmasqManager, true
+	default:
+		return 0, false
+	}
+}
+
 func isNetworkManagerSupported() bool {
 	return isNetworkManagerSupportedVersion() && isNetworkManagerSupportedMode()
 }
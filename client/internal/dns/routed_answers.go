@@ -0,0 +1,60 @@
+package dns
+
+import (
+	"net/netip"
+
+	"github.com/miekg/dns"
+	log "github.com/sirupsen/logrus"
+
+	nbdns "github.com/netbirdio/netbird/dns"
+)
+
+// adaptRoutedAnswers reorders the A and AAAA records of the response so the addresses reachable through a route
+// come first. With the only mode the unrouted addresses are dropped, unless none of the addresses is routed so the
+// name still resolves. The other records, like the CNAME chain, are kept ahead of the addresses.
+func adaptRoutedAnswers(rm *dns.Msg, mode nbdns.RoutedAnswers, isRouted func(netip.Addr) bool) {
+	if rm == nil || len(rm.Answer) < 2 {
+		return
+	}
+
+	var others, routed, unrouted []dns.RR
+	for _, rr := range rm.Answer {
+		addr, ok := answerAddr(rr)
+		switch {
+		case !ok:
+			others = append(others, rr)
+		case isRouted(addr):
+			routed = append(routed, rr)
+		default:
+			unrouted = append(unrouted, rr)
+		}
+	}
+
+	if len(routed) == 0 || len(unrouted) == 0 {
+		return
+	}
+
+	answer := make([]dns.RR, 0, len(rm.Answer))
+	answer = append(answer, others...)
+	answer = append(answer, routed...)
+	if mode != nbdns.RoutedAnswersOnly {
+		answer = append(answer, unrouted...)
+	}
+
+	log.Tracef("adapted the answers for question domain=%s with %d routed and %d unrouted addresses, mode=%s",
+		rm.Question[0].Name, len(routed), len(unrouted), mode)
+
+	rm.Answer = answer
+}
+
+func answerAddr(rr dns.RR) (netip.Addr, bool) {
+	switch r := rr.(type) {
+	case *dns.A:
+		addr, ok := netip.AddrFromSlice(r.A)
+		return addr.Unmap(), ok
+	case *dns.AAAA:
+		return netip.AddrFromSlice(r.AAAA)
+	default:
+		return netip.Addr{}, false
+	}
+}
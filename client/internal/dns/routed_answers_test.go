@@ -0,0 +1,82 @@
+package dns
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	nbdns "github.com/netbirdio/netbird/dns"
+)
+
+func TestAdaptRoutedAnswers(t *testing.T) {
+	routes := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("fd00::/8")}
+	isRouted := func(addr netip.Addr) bool {
+		for _, route := range routes {
+			if route.Contains(addr) {
+				return true
+			}
+		}
+		return false
+	}
+
+	testCases := []struct {
+		name     string
+		mode     nbdns.RoutedAnswers
+		answer   []string
+		expected []string
+	}{
+		{
+			name:     "prefer moves the routed addresses first",
+			mode:     nbdns.RoutedAnswersPrefer,
+			answer:   []string{"app.example.com. 60 IN A 203.0.113.1", "app.example.com. 60 IN A 10.1.0.1", "app.example.com. 60 IN A 198.51.100.1", "app.example.com. 60 IN A 10.2.0.1"},
+			expected: []string{"app.example.com. 60 IN A 10.1.0.1", "app.example.com. 60 IN A 10.2.0.1", "app.example.com. 60 IN A 203.0.113.1", "app.example.com. 60 IN A 198.51.100.1"},
+		},
+		{
+			name:     "only drops the unrouted addresses",
+			mode:     nbdns.RoutedAnswersOnly,
+			answer:   []string{"app.example.com. 60 IN AAAA 2001:db8::1", "app.example.com. 60 IN AAAA fd00::1"},
+			expected: []string{"app.example.com. 60 IN AAAA fd00::1"},
+		},
+		{
+			name:     "only keeps the answers without routed address",
+			mode:     nbdns.RoutedAnswersOnly,
+			answer:   []string{"app.example.com. 60 IN A 203.0.113.1", "app.example.com. 60 IN A 198.51.100.1"},
+			expected: []string{"app.example.com. 60 IN A 203.0.113.1", "app.example.com. 60 IN A 198.51.100.1"},
+		},
+		{
+			name: "the cname chain stays first",
+			mode: nbdns.RoutedAnswersOnly,
+			answer: []string{"app.example.com. 60 IN CNAME lb.example.com.", "lb.example.com. 60 IN A 203.0.113.1",
+				"lb.example.com. 60 IN A 10.1.0.1"},
+			expected: []string{"app.example.com. 60 IN CNAME lb.example.com.", "lb.example.com. 60 IN A 10.1.0.1"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			rm := new(dns.Msg).SetQuestion("app.example.com.", dns.TypeA)
+			for _, record := range testCase.answer {
+				rr, err := dns.NewRR(record)
+				require.NoError(t, err)
+				rm.Answer = append(rm.Answer, rr)
+			}
+
+			adaptRoutedAnswers(rm, testCase.mode, isRouted)
+
+			var answer []string
+			for _, rr := range rm.Answer {
+				answer = append(answer, rr.String())
+			}
+			var expected []string
+			for _, record := range testCase.expected {
+				rr, err := dns.NewRR(record)
+				require.NoError(t, err)
+				expected = append(expected, rr.String())
+			}
+			assert.Equal(t, expected, answer)
+		})
+	}
+}
@@ -41,6 +41,8 @@ type Server interface {
 	OnUpdatedHostDNSServer(strings []string)
 	SearchDomains() []string
 	ProbeAvailability()
+	CheckService() error
+	RestartService() error
 }
 
 type handlerID string
@@ -66,6 +68,14 @@ type DefaultServer struct {
 	currentConfig      HostDNSConfig
 	handlerChain       *HandlerChain
 
+	// serviceEnabled is set if the management service enabled the dns service of the peer
+	serviceEnabled bool
+
+	// hostsFile is set in the hosts file mode, the records are written to the hosts file instead of pointing the
+	// system DNS to the service
+	hostsFile     *hostsFile
+	hostsFileMode bool
+
 	// permanent related properties
 	permanent      bool
 	hostsDNSHolder *hostsDNSHolder
@@ -101,6 +111,7 @@ func NewDefaultServer(
 	statusRecorder *peer.Status,
 	stateManager *statemanager.Manager,
 	disableSys bool,
+	hostsFileMode bool,
 ) (*DefaultServer, error) {
 	var addrPort *netip.AddrPort
 	if customAddress != "" {
@@ -118,7 +129,9 @@ func NewDefaultServer(
 		dnsService = newServiceViaListener(wgInterface, addrPort)
 	}
 
-	return newDefaultServer(ctx, wgInterface, dnsService, statusRecorder, stateManager, disableSys), nil
+	ds := newDefaultServer(ctx, wgInterface, dnsService, statusRecorder, stateManager, disableSys)
+	ds.hostsFileMode = hostsFileMode
+	return ds, nil
 }
 
 // NewDefaultServerPermanentUpstream returns a new dns server. It optimized for mobile systems
@@ -247,6 +260,17 @@ func (s *DefaultServer) Initialize() (err error) {
 
 	s.stateManager.RegisterState(&ShutdownState{})
 
+	if s.hostsFileMode {
+		log.Infof("hosts file DNS mode, not setting up host manager, writing the records to %s", hostsFilePath())
+		s.hostsFile = newHostsFile(hostsFilePath())
+		// remove the records left by an unclean shutdown
+		if err := s.hostsFile.cleanup(); err != nil {
+			log.Warnf("failed to clean up the hosts file: %v", err)
+		}
+		s.hostManager = &noopHostConfigurator{}
+		return nil
+	}
+
 	// use noop host manager if requested or running in netstack mode.
 	// Netstack mode currently doesn't have a way to receive DNS requests.
 	// TODO: Use listener on localhost in netstack mode when running as root.
@@ -285,6 +309,12 @@ func (s *DefaultServer) Stop() {
 		}
 	}
 
+	if s.hostsFile != nil {
+		if err := s.hostsFile.cleanup(); err != nil {
+			log.Errorf("failed to remove the records from the hosts file: %v", err)
+		}
+	}
+
 	s.service.Stop()
 }
 
@@ -386,9 +416,55 @@ func (s *DefaultServer) ProbeAvailability() {
 	wg.Wait()
 }
 
+// CheckService returns an error if the dns service should serve the dns requests but stopped
+func (s *DefaultServer) CheckService() error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if !s.serviceEnabled && !s.permanent {
+		return nil
+	}
+
+	if !s.service.Running() {
+		return errors.New("dns service stopped")
+	}
+	return nil
+}
+
+// RestartService starts the stopped dns service again and points the host to it if its address changed
+func (s *DefaultServer) RestartService() error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	ip, port := s.service.RuntimeIP(), s.service.RuntimePort()
+	s.service.Stop()
+	if err := s.service.Listen(); err != nil {
+		return fmt.Errorf("service listen: %w", err)
+	}
+
+	if s.hostManager == nil || (s.service.RuntimeIP() == ip && s.service.RuntimePort() == port) {
+		return nil
+	}
+
+	log.Infof("dns service moved from %s:%d to %s:%d, updating the host dns settings",
+		ip, port, s.service.RuntimeIP(), s.service.RuntimePort())
+	s.currentConfig.ServerIP = s.service.RuntimeIP()
+	s.currentConfig.ServerPort = s.service.RuntimePort()
+
+	hostUpdate := s.currentConfig
+	if s.service.RuntimePort() != defaultPort && !s.hostManager.supportCustomPort() {
+		hostUpdate.RouteAll = false
+	}
+	if err := s.hostManager.applyDNSConfig(hostUpdate, s.stateManager); err != nil {
+		return fmt.Errorf("apply host dns config: %w", err)
+	}
+	return nil
+}
+
 func (s *DefaultServer) applyConfiguration(update nbdns.Config) error {
 	// is the service should be Disabled, we stop the listener or fake resolver
 	// and proceed with a regular update to clean up the handlers and records
+	s.serviceEnabled = update.ServiceEnable
 	if update.ServiceEnable {
 		_ = s.service.Listen()
 	} else if !s.permanent {
@@ -411,6 +487,16 @@ func (s *DefaultServer) applyConfiguration(update nbdns.Config) error {
 	// register local records
 	s.updateLocalResolver(localRecordsByDomain)
 
+	if s.hostsFile != nil {
+		var zones []nbdns.CustomZone
+		if update.ServiceEnable {
+			zones = update.CustomZones
+		}
+		if err := s.hostsFile.update(zones); err != nil {
+			log.Errorf("failed to update the hosts file: %v", err)
+		}
+	}
+
 	s.currentConfig = dnsConfigToHostDNSConfig(update, s.service.RuntimeIP(), s.service.RuntimePort())
 
 	hostUpdate := s.currentConfig
@@ -568,6 +654,8 @@ func (s *DefaultServer) createHandlersForDomainGroup(domainGroup nsGroupsByDomai
 			handler.upstreamServers = append(handler.upstreamServers, getNSHostPort(ns))
 		}
 
+		handler.routedAnswers = nsGroup.RoutedAnswers
+
 		if len(handler.upstreamServers) == 0 {
 			handler.stop()
 			log.Errorf("received a nameserver group with an invalid nameserver list")
@@ -11,6 +11,7 @@ const (
 type service interface {
 	Listen() error
 	Stop()
+	Running() bool
 	RegisterMux(domain string, handler dns.Handler)
 	DeregisterMux(key string)
 	RuntimePort() int
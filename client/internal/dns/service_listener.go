@@ -41,16 +41,20 @@ func newServiceViaListener(wgIface WGIface, customAddr *netip.AddrPort) *service
 		wgInterface: wgIface,
 		dnsMux:      mux,
 		customAddr:  customAddr,
-		server: &dns.Server{
-			Net:     "udp",
-			Handler: mux,
-			UDPSize: 65535,
-		},
+		server:      newDNSServer(mux),
 	}
 
 	return s
 }
 
+func newDNSServer(mux *dns.ServeMux) *dns.Server {
+	return &dns.Server{
+		Net:     "udp",
+		Handler: mux,
+		UDPSize: 65535,
+	}
+}
+
 func (s *serviceViaListener) Listen() error {
 	s.listenerFlagLock.Lock()
 	defer s.listenerFlagLock.Unlock()
@@ -65,13 +69,16 @@ func (s *serviceViaListener) Listen() error {
 		log.Errorf("failed to eval runtime address: %s", err)
 		return fmt.Errorf("eval listen address: %w", err)
 	}
+	// a dns server that returned with an error can't be started again
+	s.server = newDNSServer(s.dnsMux)
 	s.server.Addr = fmt.Sprintf("%s:%d", s.listenIP, s.listenPort)
 	log.Debugf("starting dns on %s", s.server.Addr)
+	server := s.server
 	go func() {
 		s.setListenerStatus(true)
 		defer s.setListenerStatus(false)
 
-		err := s.server.ListenAndServe()
+		err := server.ListenAndServe()
 		if err != nil {
 			log.Errorf("dns server running with %d port returned an error: %v. Will not retry", s.listenPort, err)
 		}
@@ -104,6 +111,14 @@ func (s *serviceViaListener) Stop() {
 	}
 }
 
+// Running returns true if the listener serves the dns requests
+func (s *serviceViaListener) Running() bool {
+	s.listenerFlagLock.Lock()
+	defer s.listenerFlagLock.Unlock()
+
+	return s.listenerIsRunning
+}
+
 func (s *serviceViaListener) RegisterMux(pattern string, handler dns.Handler) {
 	log.Debugf("registering dns handler for pattern: %s", pattern)
 	s.dnsMux.Handle(pattern, handler)
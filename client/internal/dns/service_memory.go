@@ -68,6 +68,14 @@ func (s *ServiceViaMemory) Stop() {
 	s.listenerIsRunning = false
 }
 
+// Running returns true if the packet hook serves the dns requests
+func (s *ServiceViaMemory) Running() bool {
+	s.listenerFlagLock.Lock()
+	defer s.listenerFlagLock.Unlock()
+
+	return s.listenerIsRunning
+}
+
 func (s *ServiceViaMemory) RegisterMux(pattern string, handler dns.Handler) {
 	s.dnsMux.Handle(pattern, handler)
 }
@@ -29,6 +29,7 @@ const (
 	systemdDbusLinkInterface               = "org.freedesktop.resolve1.Link"
 	systemdDbusRevertMethodSuffix          = systemdDbusLinkInterface + ".Revert"
 	systemdDbusSetDNSMethodSuffix          = systemdDbusLinkInterface + ".SetDNS"
+	systemdDbusSetDNSExMethodSuffix        = systemdDbusLinkInterface + ".SetDNSEx"
 	systemdDbusSetDefaultRouteMethodSuffix = systemdDbusLinkInterface + ".SetDefaultRoute"
 	systemdDbusSetDomainsMethodSuffix      = systemdDbusLinkInterface + ".SetDomains"
 	systemdDbusResolvConfModeForeign       = "foreign"
@@ -39,6 +40,7 @@ const (
 type systemdDbusConfigurator struct {
 	dbusLinkObject dbus.ObjectPath
 	ifaceName      string
+	reapplier      *dnsReapplier
 }
 
 // the types below are based on dbus specification, each field is mapped to a dbus type
@@ -50,6 +52,14 @@ type systemdDbusDNSInput struct {
 	Address []byte
 }
 
+// systemdDbusDNSExInput maps to a (iayqs) dbus input for SetDNSEx method, it adds the port and the server name
+type systemdDbusDNSExInput struct {
+	Family  int32
+	Address []byte
+	Port    uint16
+	Name    string
+}
+
 // systemdDbusLinkDomainsInput maps to a (sb) dbus input for SetDomains method
 type systemdDbusLinkDomainsInput struct {
 	Domain    string
@@ -76,10 +86,13 @@ func newSystemdDbusConfigurator(wgInterface string) (*systemdDbusConfigurator, e
 
 	log.Debugf("got dbus Link interface: %s from net interface %s and index %d", s, iface.Name, iface.Index)
 
-	return &systemdDbusConfigurator{
+	configurator := &systemdDbusConfigurator{
 		dbusLinkObject: dbus.ObjectPath(s),
 		ifaceName:      wgInterface,
-	}, nil
+	}
+	configurator.reapplier = newDNSReapplier(systemdResolvedDest, configurator.applyDNSConfig)
+
+	return configurator, nil
 }
 
 func (s *systemdDbusConfigurator) supportCustomPort() bool {
@@ -91,12 +104,7 @@ func (s *systemdDbusConfigurator) applyDNSConfig(config HostDNSConfig, stateMana
 	if err != nil {
 		return fmt.Errorf("unable to parse ip address, error: %w", err)
 	}
-	ipAs4 := parsedIP.As4()
-	defaultLinkInput := systemdDbusDNSInput{
-		Family:  unix.AF_INET,
-		Address: ipAs4[:],
-	}
-	err = s.callLinkMethod(systemdDbusSetDNSMethodSuffix, []systemdDbusDNSInput{defaultLinkInput})
+	err = s.setLinkDNS(parsedIP.Unmap(), config.ServerPort)
 	if err != nil {
 		return fmt.Errorf("setting the interface DNS server %s:%d failed with error: %w", config.ServerIP, config.ServerPort, err)
 	}
@@ -151,9 +159,42 @@ func (s *systemdDbusConfigurator) applyDNSConfig(config HostDNSConfig, stateMana
 	if err != nil {
 		log.Error(err)
 	}
+
+	s.reapplier.update(config, stateManager)
 	return nil
 }
 
+// setLinkDNS sets the DNS server of the link. SetDNSEx is required for a custom port, older resolved versions without
+// it only support the default port.
+func (s *systemdDbusConfigurator) setLinkDNS(ip netip.Addr, port int) error {
+	family := int32(unix.AF_INET)
+	if ip.Is6() {
+		family = unix.AF_INET6
+	}
+
+	err := s.callLinkMethod(systemdDbusSetDNSExMethodSuffix, []systemdDbusDNSExInput{{
+		Family:  family,
+		Address: ip.AsSlice(),
+		Port:    uint16(port),
+	}})
+	if err == nil {
+		return nil
+	}
+
+	var dbusErr dbus.Error
+	if !errors.As(err, &dbusErr) || dbusErr.Name != dbus.ErrMsgUnknownMethod.Name {
+		return err
+	}
+	if port != defaultPort {
+		return fmt.Errorf("resolved doesn't support the custom port %d: %w", port, err)
+	}
+
+	return s.callLinkMethod(systemdDbusSetDNSMethodSuffix, []systemdDbusDNSInput{{
+		Family:  family,
+		Address: ip.AsSlice(),
+	}})
+}
+
 func (s *systemdDbusConfigurator) string() string {
 	return "dbus"
 }
@@ -167,6 +208,8 @@ func (s *systemdDbusConfigurator) setDomainsForInterface(domainsInput []systemdD
 }
 
 func (s *systemdDbusConfigurator) restoreHostDNS() error {
+	s.reapplier.stop()
+
 	log.Infof("reverting link settings and flushing cache")
 	if !isDbusListenerRunning(systemdResolvedDest, s.dbusLinkObject) {
 		return nil
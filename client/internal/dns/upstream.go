@@ -20,6 +20,8 @@ import (
 
 	"github.com/netbirdio/netbird/client/internal/peer"
 	"github.com/netbirdio/netbird/client/proto"
+	nbdns "github.com/netbirdio/netbird/dns"
+	"github.com/netbirdio/netbird/util/errcode"
 )
 
 const (
@@ -53,6 +55,7 @@ type upstreamResolverBase struct {
 	mutex            sync.Mutex
 	reactivatePeriod time.Duration
 	upstreamTimeout  time.Duration
+	routedAnswers    nbdns.RoutedAnswers
 
 	deactivate     func(error)
 	reactivate     func()
@@ -146,6 +149,10 @@ func (u *upstreamResolverBase) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 		u.successCount.Add(1)
 		log.Tracef("took %s to query the upstream %s for question domain=%s", t, upstream, r.Question[0].Name)
 
+		if u.routedAnswers != nbdns.RoutedAnswersDisabled {
+			adaptRoutedAnswers(rm, u.routedAnswers, u.statusRecorder.IsRouted)
+		}
+
 		if err = w.WriteMsg(rm); err != nil {
 			log.Errorf("failed to write DNS response for question domain=%s: %s", r.Question[0].Name, err)
 		}
@@ -188,9 +195,10 @@ func (u *upstreamResolverBase) checkUpstreamFails(err error) {
 		return
 	}
 
-	u.statusRecorder.PublishEvent(
+	u.statusRecorder.PublishErrorEvent(
 		proto.SystemEvent_WARNING,
 		proto.SystemEvent_DNS,
+		errcode.DNSUpstreamUnavailable,
 		"All upstream servers failed (fail count exceeded)",
 		"Unable to reach one or more DNS servers. This might affect your ability to connect to some services.",
 		map[string]string{"upstreams": strings.Join(u.upstreamServers, ", ")},
@@ -249,9 +257,10 @@ func (u *upstreamResolverBase) probeAvailability() {
 			return
 		}
 
-		u.statusRecorder.PublishEvent(
+		u.statusRecorder.PublishErrorEvent(
 			proto.SystemEvent_WARNING,
 			proto.SystemEvent_DNS,
+			errcode.DNSUpstreamUnavailable,
 			"All upstream servers failed (probe failed)",
 			"Unable to reach one or more DNS servers. This might affect your ability to connect to some services.",
 			map[string]string{"upstreams": strings.Join(u.upstreamServers, ", ")},
@@ -10,11 +10,12 @@ import (
 
 	nberrors "github.com/netbirdio/netbird/client/errors"
 	firewall "github.com/netbirdio/netbird/client/firewall/manager"
+	nbdns "github.com/netbirdio/netbird/dns"
 )
 
 const (
 	// ListenPort is the port that the DNS forwarder listens on. It has been used by the client peers also
-	ListenPort = 5353
+	ListenPort = nbdns.ForwarderDNSPort
 	dnsTTL     = 60 //seconds
 )
 
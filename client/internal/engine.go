@@ -2,6 +2,7 @@ package internal
 
 import (
 	"context"
+	"crypto/ed25519"
 	"errors"
 	"fmt"
 	"math/rand"
@@ -11,8 +12,10 @@ import (
 	"runtime"
 	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/hashicorp/go-multierror"
@@ -31,21 +34,32 @@ import (
 	"github.com/netbirdio/netbird/client/iface/device"
 	nbnetstack "github.com/netbirdio/netbird/client/iface/netstack"
 	"github.com/netbirdio/netbird/client/internal/acl"
+	"github.com/netbirdio/netbird/client/internal/connectivity"
 	"github.com/netbirdio/netbird/client/internal/dns"
 	"github.com/netbirdio/netbird/client/internal/dnsfwd"
+	"github.com/netbirdio/netbird/client/internal/filetransfer"
 	"github.com/netbirdio/netbird/client/internal/ingressgw"
+	"github.com/netbirdio/netbird/client/internal/langateway"
 	"github.com/netbirdio/netbird/client/internal/netflow"
 	nftypes "github.com/netbirdio/netbird/client/internal/netflow/types"
 	"github.com/netbirdio/netbird/client/internal/networkmonitor"
 	"github.com/netbirdio/netbird/client/internal/peer"
 	"github.com/netbirdio/netbird/client/internal/peer/guard"
 	icemaker "github.com/netbirdio/netbird/client/internal/peer/ice"
+	"github.com/netbirdio/netbird/client/internal/peer/limiter"
 	"github.com/netbirdio/netbird/client/internal/peerstore"
+	"github.com/netbirdio/netbird/client/internal/probe"
 	"github.com/netbirdio/netbird/client/internal/relay"
 	"github.com/netbirdio/netbird/client/internal/rosenpass"
+	"github.com/netbirdio/netbird/client/internal/routelearn"
 	"github.com/netbirdio/netbird/client/internal/routemanager"
+	"github.com/netbirdio/netbird/client/internal/routemanager/splittunnel"
 	"github.com/netbirdio/netbird/client/internal/routemanager/systemops"
+	"github.com/netbirdio/netbird/client/internal/routermode"
+	"github.com/netbirdio/netbird/client/internal/sleep"
 	"github.com/netbirdio/netbird/client/internal/statemanager"
+	"github.com/netbirdio/netbird/client/internal/uplink"
+	"github.com/netbirdio/netbird/client/internal/watchdog"
 	cProto "github.com/netbirdio/netbird/client/proto"
 	"github.com/netbirdio/netbird/management/domain"
 	semaphoregroup "github.com/netbirdio/netbird/util/semaphore-group"
@@ -61,6 +75,8 @@ import (
 	signal "github.com/netbirdio/netbird/signal/client"
 	sProto "github.com/netbirdio/netbird/signal/proto"
 	"github.com/netbirdio/netbird/util"
+	"github.com/netbirdio/netbird/util/errcode"
+	"github.com/netbirdio/netbird/util/faultinject"
 	nbnet "github.com/netbirdio/netbird/util/net"
 )
 
@@ -76,6 +92,13 @@ const (
 
 var ErrResetConnection = fmt.Errorf("reset connection")
 
+// ErrDebugBundleDeclined is returned by a DebugBundleHandler that declined the request of the management service
+var ErrDebugBundleDeclined = errors.New("debug bundle declined")
+
+// DebugBundleHandler collects the debug bundle requested by the management service and uploads it to the upload URL
+// of the request. The engine reports the requests the handler returned an error for.
+type DebugBundleHandler func(ctx context.Context, request *mgmProto.DebugBundleRequest) error
+
 // EngineConfig is a config for the Engine
 type EngineConfig struct {
 	WgPort      int
@@ -122,6 +145,63 @@ type EngineConfig struct {
 	DisableFirewall     bool
 
 	BlockLANAccess bool
+
+	// Uplinks are the uplink interfaces in the order of preference, the uplink tracking is disabled if empty
+	Uplinks []string
+	// UplinkTargets are the host:port addresses of the control plane services probed through the uplinks
+	UplinkTargets []string
+
+	// StaticMesh configures the peers with their endpoints directly, the connections aren't negotiated
+	StaticMesh bool
+	// StaticEndpoints are the endpoints of the peers of the static mesh by public key
+	StaticEndpoints map[string]*net.UDPAddr
+
+	// RouterMode runs the peer as a gateway appliance, the host is tuned for routing
+	RouterMode bool
+	// RouterLANs are the LAN prefixes advertised in router mode
+	RouterLANs []string
+
+	// RouteLearning is the source of the routes learned from the routers of the site, empty if disabled
+	RouteLearning string
+	// RouteLearningFilters select the learned routes advertised to management
+	RouteLearningFilters []string
+
+	// RouteDrainPeriod is the time the established flows through the routed networks have to finish on shutdown
+	RouteDrainPeriod time.Duration
+
+	// SplitTunnel selects the processes routed through the client routes, every process if empty
+	SplitTunnel splittunnel.Config
+
+	// PortPolicy pins the WireGuard listen port and constrains the transports, sent by the Management service at login
+	PortPolicy *mgmProto.PortPolicy
+
+	// ClientSettings are the defaults of the client settings of the account sent by the Management service at login,
+	// they're applied to DisableDNS, DisableClientRoutes and DisableFirewall unless set locally
+	ClientSettings *mgmProto.ClientSettings
+
+	// OverlayOnlyServices are the local services whose inbound traffic is dropped on the physical interfaces
+	OverlayOnlyServices []firewallManager.InboundService
+
+	// RequireRouteApproval holds back the high-risk routes until the local user approves them
+	RequireRouteApproval bool
+
+	// ExitNodeStickyGrace keeps the established flows on the previous exit node after an automatic switch
+	ExitNodeStickyGrace time.Duration
+
+	// DNSHostsFile writes the peer records to the hosts file instead of taking over the system DNS
+	DNSHostsFile bool
+
+	// MaxICENegotiations caps the concurrent ICE negotiations, the default applies when 0
+	MaxICENegotiations int
+
+	// LANGateway shares a virtual gateway IP on the LAN with the other routing peers, disabled if nil
+	LANGateway *langateway.Config
+
+	// FileTransferDir is the directory the files of the peers are received into, receiving is disabled if empty
+	FileTransferDir string
+
+	// RemoteActions are the peer actions of the management service the engine runs, it declines the others
+	RemoteActions []string
 }
 
 // Engine is a mechanism responsible for reacting on Signal and Management stream events and managing connections to the remote peers.
@@ -164,11 +244,30 @@ type Engine struct {
 
 	// networkSerial is the latest CurrentSerial (state ID) of the network sent by the Management service
 	networkSerial uint64
+	// connectionTuning overrides the timers of the peer connections, sent by the Management service
+	connectionTuning *mgmProto.ConnectionTuning
 
 	networkMonitor *networkmonitor.NetworkMonitor
+	// sleepDetector reports when the system goes to sleep and wakes up
+	sleepDetector *sleep.Detector
+	// sleeping is set between the sleep and the wake of the system, the network changes meanwhile are ignored
+	sleeping atomic.Bool
+	// uplinkTracker selects the uplink of the control plane and the tunnels on peers with several uplinks
+	uplinkTracker *uplink.Tracker
+	// lanGateway holds the virtual LAN gateway IP shared with the other routing peers while it's the master
+	lanGateway *langateway.Gateway
+	// fileTransfer receives the files sent by the peers with netbird cp
+	fileTransfer *filetransfer.Server
+
+	// routerTuning holds the host settings changed in router mode
+	routerTuning *routermode.Tuning
+	// routeLearner learns the routes of the site advertised to management
+	routeLearner *routelearn.Learner
 
 	sshServerFunc func(hostKeyPEM []byte, addr string) (nbssh.Server, error)
 	sshServer     nbssh.Server
+	// sshJump authorizes the jumps through the SSH server to the hosts of the routed networks
+	sshJump *sshJumpPolicy
 
 	statusRecorder *peer.Status
 
@@ -192,6 +291,41 @@ type Engine struct {
 	latestNetworkMap  *mgmProto.NetworkMap
 	connSemaphore     *semaphoregroup.SemaphoreGroup
 	flowManager       nftypes.FlowManager
+
+	// iceLimiter caps the concurrent ICE negotiations, the routing peers and exit nodes first
+	iceLimiter *limiter.Limiter
+
+	// debugBundleHandler collects the debug bundles requested by the management service
+	debugBundleHandler DebugBundleHandler
+
+	// peerActionHandler runs the parts of the peer actions requested by the management service that need the daemon
+	peerActionHandler PeerActionHandler
+	// peerActionsKey is the public key the peer actions of the management service are verified with
+	peerActionsKey ed25519.PublicKey
+	// seenPeerActions are the IDs of the verified peer actions until they expire, an action is run once
+	seenPeerActions map[string]time.Time
+
+	// watchdog recovers the interface, the dns server and the firewall if they wedge, it outlives the engine
+	watchdog *watchdog.Watchdog
+
+	// handOverInterface keeps the kernel interface with the WireGuard peers on stop, the next engine takes it over
+	handOverInterface bool
+	// releasedInterface is the interface the stopped engine handed over
+	releasedInterface WGIface
+	// reuseInterface takes over the interface handed over by the previous engine, its peers missing from the first
+	// network map are removed
+	reuseInterface bool
+
+	// loginExpiryTimer restarts the engine when the login of the peer expires
+	loginExpiryTimer *time.Timer
+	// loginExpiryNoticeTimer notifies the user before the login of the peer expires
+	loginExpiryNoticeTimer *time.Timer
+	// loginExpiryNotified is the login expiration time the user was last notified about
+	loginExpiryNotified time.Time
+	// loginExpiredNotified indicates that the user was notified about the access limited by the expired login
+	loginExpiredNotified bool
+	// failedPostureChecks are the posture checks the peer fails the user was notified about
+	failedPostureChecks []string
 }
 
 // Peer is an instance of the Connection Peer
@@ -234,6 +368,7 @@ func NewEngine(
 		statusRecorder: statusRecorder,
 		checks:         checks,
 		connSemaphore:  semaphoregroup.NewSemaphoreGroup(connInitLimit),
+		iceLimiter:     limiter.New(config.MaxICENegotiations),
 	}
 	if runtime.GOOS == "ios" {
 		if !fileExists(mobileDep.StateFilePath) {
@@ -249,6 +384,7 @@ func NewEngine(
 	if path := statemanager.GetDefaultStatePath(); path != "" {
 		engine.stateManager = statemanager.New(path)
 	}
+	engine.sshJump = &sshJumpPolicy{engine: engine}
 
 	return engine
 }
@@ -262,12 +398,39 @@ func (e *Engine) Stop() error {
 	e.syncMsgMux.Lock()
 	defer e.syncMsgMux.Unlock()
 
+	e.stopLoginExpiryTimers()
+
 	// stopping network monitor first to avoid starting the engine again
 	if e.networkMonitor != nil {
 		e.networkMonitor.Stop()
 	}
 	log.Info("Network monitor: stopped")
 
+	if e.sleepDetector != nil {
+		e.sleepDetector.Stop()
+	}
+
+	if e.uplinkTracker != nil {
+		e.uplinkTracker.Stop()
+	}
+
+	if e.lanGateway != nil {
+		e.lanGateway.Stop()
+	}
+
+	if e.fileTransfer != nil {
+		e.fileTransfer.Stop()
+	}
+
+	if e.routeLearner != nil {
+		e.routeLearner.Stop()
+	}
+
+	if err := e.routerTuning.Restore(); err != nil {
+		log.Warnf("failed to restore the router mode tuning: %v", err)
+	}
+	e.routerTuning = nil
+
 	// stop/restore DNS first so dbus and friends don't complain because of a missing interface
 	e.stopDNSServer()
 
@@ -394,6 +557,10 @@ func (e *Engine) Start() error {
 		PeerStore:           e.peerStore,
 		DisableClientRoutes: e.config.DisableClientRoutes,
 		DisableServerRoutes: e.config.DisableServerRoutes,
+		SplitTunnel:         e.config.SplitTunnel,
+
+		RequireRouteApproval: e.config.RequireRouteApproval,
+		ExitNodeStickyGrace:  e.config.ExitNodeStickyGrace,
 	})
 	beforePeerHook, afterPeerHook, err := e.routeManager.Init()
 	if err != nil {
@@ -445,11 +612,21 @@ func (e *Engine) Start() error {
 	e.srWatcher = guard.NewSRWatcher(e.signal, e.relayManager, e.mobileDep.IFaceDiscover, iceCfg)
 	e.srWatcher.Start()
 
+	e.startUplinkTracker()
+	e.startRouterMode()
+	e.startRouteLearning()
+	e.startLANGateway()
+	e.startFileTransfer()
+
 	e.receiveSignalEvents()
 	e.receiveManagementEvents()
+	e.startConnectivityReporter()
+	e.startPeerHealthProbes()
+	e.startWatchdog()
 
 	// starting network monitor at the very last to avoid disruptions
 	e.startNetworkMonitor()
+	e.startSleepDetector()
 
 	return nil
 }
@@ -464,6 +641,7 @@ func (e *Engine) createFirewall() error {
 	e.firewall, err = firewall.NewFirewall(e.wgInterface, e.stateManager, e.flowManager.GetLogger(), e.config.DisableServerRoutes)
 	if err != nil || e.firewall == nil {
 		log.Errorf("failed creating firewall manager: %s", err)
+		e.publishFirewallError(err)
 		return nil
 	}
 
@@ -474,6 +652,34 @@ func (e *Engine) createFirewall() error {
 	return nil
 }
 
+// publishFirewallError tells the user the firewall couldn't be created, either since it refused to install the rules
+// into the inactive iptables backend or since no firewall backend works on the host
+func (e *Engine) publishFirewallError(err error) {
+	if err == nil {
+		return
+	}
+
+	var conflict *firewallManager.BackendConflictError
+	if !errors.As(err, &conflict) {
+		e.statusRecorder.PublishErrorEvent(
+			cProto.SystemEvent_ERROR, cProto.SystemEvent_SYSTEM,
+			errcode.FirewallBackendUnavailable,
+			err.Error(),
+			"The firewall rules weren't applied since no firewall backend works on this host.",
+			nil,
+		)
+		return
+	}
+
+	e.statusRecorder.PublishErrorEvent(
+		cProto.SystemEvent_ERROR, cProto.SystemEvent_SYSTEM,
+		conflict.ErrorCode(),
+		conflict.Error(),
+		"The firewall rules weren't applied since the host mixes iptables-legacy and iptables-nft rules.",
+		map[string]string{"table": conflict.Table, "active": string(conflict.Active), "backend": string(conflict.Backend)},
+	)
+}
+
 func (e *Engine) initFirewall() error {
 	if e.firewall.IsServerRouteSupported() {
 		if err := e.routeManager.EnableServerRouter(e.firewall); err != nil {
@@ -486,6 +692,10 @@ func (e *Engine) initFirewall() error {
 		e.blockLanAccess()
 	}
 
+	if len(e.config.OverlayOnlyServices) > 0 {
+		e.restrictToOverlay()
+	}
+
 	if e.rpManager == nil || !e.config.RosenpassEnabled {
 		return nil
 	}
@@ -542,6 +752,24 @@ func (e *Engine) blockLanAccess() {
 	}
 }
 
+// restrictToOverlay makes the overlay-only services reachable solely over the NetBird interface. The rules are torn
+// down with the firewall manager on engine down.
+func (e *Engine) restrictToOverlay() {
+	fm, ok := e.firewall.(firewallManager.OverlayOnlyManager)
+	if !ok {
+		log.Warnf("the firewall doesn't support the overlay-only mode, the services %v stay reachable on all interfaces",
+			e.config.OverlayOnlyServices)
+		return
+	}
+
+	if err := fm.SetOverlayOnlyServices(e.config.OverlayOnlyServices); err != nil {
+		log.Errorf("failed to restrict the services to the overlay: %v", err)
+		return
+	}
+
+	log.Infof("services %v are reachable only over NetBird", e.config.OverlayOnlyServices)
+}
+
 // modifyPeers updates peers that have been modified (e.g. IP address has been changed).
 // It closes the existing connection, removes it from the peerConns map, and creates a new one.
 func (e *Engine) modifyPeers(peersUpdate []*mgmProto.RemotePeerConfig) error {
@@ -629,13 +857,27 @@ func (e *Engine) removePeer(peerKey string) error {
 	}()
 
 	conn, exists := e.peerStore.Remove(peerKey)
-	if exists {
+	if !exists {
+		return nil
+	}
+	if e.handOverInterface {
+		conn.CloseKeepingWgPeer()
+	} else {
 		conn.Close()
 	}
 	return nil
 }
 
 func (e *Engine) handleSync(update *mgmProto.SyncResponse) error {
+	if delay := faultinject.Delay(faultinject.ManagementSyncDelay); delay > 0 {
+		log.Debugf("delaying the management sync by %s", delay)
+		select {
+		case <-e.ctx.Done():
+			return e.ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+
 	e.syncMsgMux.Lock()
 	defer e.syncMsgMux.Unlock()
 
@@ -666,6 +908,8 @@ func (e *Engine) handleSync(update *mgmProto.SyncResponse) error {
 			return fmt.Errorf("handle the flow configuration: %w", err)
 		}
 
+		e.peerActionsKey = wCfg.GetPeerActionsKey()
+
 		// todo update signal
 	}
 
@@ -673,6 +917,23 @@ func (e *Engine) handleSync(update *mgmProto.SyncResponse) error {
 		return err
 	}
 
+	if request := update.GetProbeRequest(); request != nil {
+		go e.runProbe(request)
+	}
+
+	if request := update.GetDebugBundleRequest(); request != nil {
+		go e.runDebugBundleRequest(e.debugBundleHandler, request)
+	}
+
+	if action := update.GetPeerAction(); action != nil {
+		if err := e.verifyPeerAction(action); err != nil {
+			log.Warnf("declining peer action %s of the management service: %v", action.GetId(), err)
+			go e.reportPeerActionStatus(&mgmProto.PeerActionStatus{Id: action.GetId(), Declined: true, Error: err.Error()})
+		} else {
+			go e.runPeerAction(e.peerActionHandler, action)
+		}
+	}
+
 	nm := update.GetNetworkMap()
 	if nm == nil {
 		return nil
@@ -694,6 +955,37 @@ func (e *Engine) handleSync(update *mgmProto.SyncResponse) error {
 	return nil
 }
 
+// runProbe runs the probe requested by the management service and reports its result
+func (e *Engine) runProbe(request *mgmProto.ProbeRequest) {
+	log.Debugf("running probe %s of %s", request.GetId(), request.GetTarget())
+
+	result := probe.Run(e.ctx, request)
+	if err := e.mgmClient.ReportProbeResult(result); err != nil {
+		log.Warnf("failed to report the result of probe %s: %v", request.GetId(), err)
+	}
+}
+
+// runDebugBundleRequest passes the debug bundle request of the management service to the handler and reports the
+// requests the handler declined or failed to upload a bundle for
+func (e *Engine) runDebugBundleRequest(handler DebugBundleHandler, request *mgmProto.DebugBundleRequest) {
+	log.Infof("management requested debug bundle %s", request.GetId())
+
+	bundleStatus := &mgmProto.DebugBundleStatus{Id: request.GetId()}
+	if handler == nil {
+		bundleStatus.Declined = true
+		bundleStatus.Error = "remote debug bundles are not supported by the client"
+	} else if err := handler(e.ctx, request); err == nil {
+		return
+	} else {
+		bundleStatus.Declined = errors.Is(err, ErrDebugBundleDeclined)
+		bundleStatus.Error = err.Error()
+	}
+
+	if err := e.mgmClient.ReportDebugBundleStatus(bundleStatus); err != nil {
+		log.Warnf("failed to report the status of debug bundle %s: %v", request.GetId(), err)
+	}
+}
+
 func (e *Engine) handleRelayUpdate(update *mgmProto.RelayConfig) error {
 	if update != nil {
 		// when we receive token we expect valid address list too
@@ -753,7 +1045,12 @@ func (e *Engine) updateChecksIfNew(checks []*mgmProto.Checks) error {
 	}
 	e.checks = checks
 
-	info, err := system.GetInfoWithChecks(e.ctx, checks)
+	return e.syncMeta()
+}
+
+// syncMeta sends the system info with the checks, the flags and the advertised routes to management
+func (e *Engine) syncMeta() error {
+	info, err := system.GetInfoWithChecks(e.ctx, e.checks)
 	if err != nil {
 		log.Warnf("failed to get system info with checks: %v", err)
 		info = system.GetInfo(e.ctx)
@@ -767,6 +1064,7 @@ func (e *Engine) updateChecksIfNew(checks []*mgmProto.Checks) error {
 		e.config.DisableDNS,
 		e.config.DisableFirewall,
 	)
+	e.setAdvertisedRoutes(info)
 
 	if err := e.mgmClient.SyncMeta(info); err != nil {
 		log.Errorf("could not sync meta: error %s", err)
@@ -804,6 +1102,7 @@ func (e *Engine) updateSSH(sshConf *mgmProto.SSHConfig) error {
 				if err != nil {
 					return fmt.Errorf("create ssh server: %w", err)
 				}
+				e.sshServer.SetJumpPolicy(e.sshJump)
 				go func() {
 					// blocking
 					err = e.sshServer.Start()
@@ -855,6 +1154,10 @@ func (e *Engine) updateConfig(conf *mgmProto.PeerConfig) error {
 		}
 	}
 
+	e.statusRecorder.SetDisabledNotifications(conf.GetDisabledNotifications())
+	e.updateLoginExpiry(conf)
+	e.updateFailedPostureChecks(conf.GetFailedPostureChecks())
+
 	state := e.statusRecorder.GetLocalPeerState()
 	state.IP = e.config.WgAddr
 	state.PubKey = e.config.WgPrivateKey.PublicKey().String()
@@ -866,6 +1169,134 @@ func (e *Engine) updateConfig(conf *mgmProto.PeerConfig) error {
 	return nil
 }
 
+// updateLoginExpiry schedules the notification of the user before the login expiration sent by the management service
+// and the restart of the engine at the expiration. The login of the restarted engine is rejected once expired, which
+// requires the user to log in again. In the grace period the management service limits the access of the expired peer
+// to the remediation policies instead, so the engine keeps running.
+func (e *Engine) updateLoginExpiry(conf *mgmProto.PeerConfig) {
+	e.stopLoginExpiryTimers()
+
+	if conf.GetLoginExpired() {
+		if !e.loginExpiredNotified {
+			e.loginExpiredNotified = true
+			e.statusRecorder.PublishErrorNotification(
+				mgmProto.NotificationSessionExpiry,
+				"",
+				cProto.SystemEvent_CRITICAL,
+				cProto.SystemEvent_AUTHENTICATION,
+				errcode.LoginExpired,
+				"peer login expired",
+				"Your NetBird login has expired and the access is limited. Log in again to restore it.",
+				nil,
+			)
+		}
+		return
+	}
+	e.loginExpiredNotified = false
+
+	if conf.GetLoginExpiresAt() == nil {
+		e.statusRecorder.ClearNotifications(mgmProto.NotificationSessionExpiry)
+		return
+	}
+
+	expiresAt := conf.GetLoginExpiresAt().AsTime()
+	e.clearLoginExpiryNotifications(expiresAt)
+	log.Debugf("peer login expires in %s", time.Until(expiresAt))
+
+	if notice := conf.GetLoginExpirationNotification(); notice != nil && !e.loginExpiryNotified.Equal(expiresAt) {
+		e.loginExpiryNoticeTimer = time.AfterFunc(time.Until(expiresAt.Add(-notice.AsDuration())), func() {
+			e.notifyLoginExpiring(expiresAt)
+		})
+	}
+
+	if conf.GetLoginExpirationGrace() {
+		return
+	}
+
+	e.loginExpiryTimer = time.AfterFunc(time.Until(expiresAt), func() {
+		log.Infof("peer login expired, restarting engine")
+		e.restartEngine()
+	})
+}
+
+func (e *Engine) notifyLoginExpiring(expiresAt time.Time) {
+	e.syncMsgMux.Lock()
+	defer e.syncMsgMux.Unlock()
+
+	if e.ctx.Err() != nil {
+		return
+	}
+
+	e.loginExpiryNotified = expiresAt
+	e.statusRecorder.PublishNotification(
+		mgmProto.NotificationSessionExpiry,
+		expiresAt.Format(time.RFC3339),
+		cProto.SystemEvent_WARNING,
+		cProto.SystemEvent_AUTHENTICATION,
+		"peer login expiring",
+		fmt.Sprintf("Your NetBird login expires in %s. Log in again to stay connected.", time.Until(expiresAt).Round(time.Minute)),
+		map[string]string{"expires_at": expiresAt.Format(time.RFC3339)},
+	)
+}
+
+// clearLoginExpiryNotifications removes the notifications about the logins expiring at another time, the user logged
+// in again. The notifications are kept by the status recorder across the engine restarts.
+func (e *Engine) clearLoginExpiryNotifications(expiresAt time.Time) {
+	current := expiresAt.Format(time.RFC3339)
+	for _, notification := range e.statusRecorder.GetNotifications() {
+		if notification.GetNotification() != mgmProto.NotificationSessionExpiry {
+			continue
+		}
+		if subject := notification.GetMetadata()["expires_at"]; subject != current {
+			e.statusRecorder.ClearNotification(mgmProto.NotificationSessionExpiry, subject)
+		}
+	}
+}
+
+// updateFailedPostureChecks notifies the user about the posture checks the peer fails. The management service
+// withholds the access granted by the policies requiring them until they're remediated.
+func (e *Engine) updateFailedPostureChecks(failed []string) {
+	if slices.Equal(e.failedPostureChecks, failed) {
+		return
+	}
+
+	for _, name := range e.failedPostureChecks {
+		if !slices.Contains(failed, name) {
+			log.Infof("the peer passes the posture check %s again", name)
+			e.statusRecorder.ClearNotification(mgmProto.NotificationPostureCheck, name)
+		}
+	}
+
+	for _, name := range failed {
+		if slices.Contains(e.failedPostureChecks, name) {
+			continue
+		}
+		log.Warnf("the peer fails the posture check %s, the access of the policies requiring it is withheld", name)
+		e.statusRecorder.PublishNotification(
+			mgmProto.NotificationPostureCheck,
+			name,
+			cProto.SystemEvent_WARNING,
+			cProto.SystemEvent_SYSTEM,
+			"posture check failed",
+			fmt.Sprintf("This device doesn't pass the posture check %s, part of the network access is blocked until it's fixed.", name),
+			map[string]string{"posture_check": name},
+		)
+	}
+
+	e.failedPostureChecks = slices.Clone(failed)
+}
+
+func (e *Engine) stopLoginExpiryTimers() {
+	if e.loginExpiryTimer != nil {
+		e.loginExpiryTimer.Stop()
+		e.loginExpiryTimer = nil
+	}
+	if e.loginExpiryNoticeTimer != nil {
+		e.loginExpiryNoticeTimer.Stop()
+		e.loginExpiryNoticeTimer = nil
+	}
+}
+
 // receiveManagementEvents connects to the Management Service event stream to receive updates from the management service
 // E.g. when a new peer has been registered and we are allowed to connect to it.
 func (e *Engine) receiveManagementEvents() {
@@ -884,6 +1315,7 @@ func (e *Engine) receiveManagementEvents() {
 			e.config.DisableDNS,
 			e.config.DisableFirewall,
 		)
+		e.setAdvertisedRoutes(info)
 
 		// err = e.mgmClient.Sync(info, e.handleSync)
 		err = e.mgmClient.Sync(e.ctx, info, e.handleSync)
@@ -952,6 +1384,26 @@ func (e *Engine) updateNetworkMap(networkMap *mgmProto.NetworkMap) error {
 		return nil
 	}
 
+	if networkMap.GetPeerConfig() != nil {
+		if err := e.updateConnectionTuning(networkMap.GetPeerConfig().GetConnectionTuning()); err != nil {
+			return err
+		}
+
+		// the listen port and the relay transports are selected at login, the engine is recreated to apply the change
+		if !proto.Equal(e.config.PortPolicy, networkMap.GetPeerConfig().GetPortPolicy()) {
+			log.Infof("port policy changed, restarting engine")
+			go e.hotRestartEngine()
+			return nil
+		}
+
+		// the DNS, the routes and the firewall are set up at start, the engine is recreated to apply the defaults
+		if !proto.Equal(e.config.ClientSettings, networkMap.GetPeerConfig().GetClientSettings()) {
+			log.Infof("client settings of the account changed, restarting engine")
+			go e.hotRestartEngine()
+			return nil
+		}
+	}
+
 	if e.firewall != nil {
 		if localipfw, ok := e.firewall.(localIpUpdater); ok {
 			if err := localipfw.UpdateLocalIPs(); err != nil {
@@ -960,20 +1412,26 @@ func (e *Engine) updateNetworkMap(networkMap *mgmProto.NetworkMap) error {
 		}
 	}
 
-	// DNS forwarder
+	// DNS forwarder, it serves the domains of the routes and of the nameserver groups the peer forwards
 	dnsRouteFeatureFlag := toDNSFeatureFlag(networkMap)
-	dnsRouteDomains := toRouteDomains(e.config.WgPrivateKey.PublicKey().String(), networkMap.GetRoutes())
-	e.updateDNSForwarder(dnsRouteFeatureFlag, dnsRouteDomains)
+	var dnsForwarderDomains []string
+	if dnsRouteFeatureFlag {
+		dnsForwarderDomains = toRouteDomains(e.config.WgPrivateKey.PublicKey().String(), networkMap.GetRoutes())
+	}
+	dnsForwarderDomains = append(dnsForwarderDomains, toForwarderNSDomains(networkMap.GetDNSConfig())...)
+	e.updateDNSForwarder(dnsRouteFeatureFlag || len(dnsForwarderDomains) > 0, dnsForwarderDomains)
 
 	routes := toRoutes(networkMap.GetRoutes())
 	if err := e.routeManager.UpdateRoutes(serial, routes, dnsRouteFeatureFlag); err != nil {
 		log.Errorf("failed to update clientRoutes, err: %v", err)
 	}
+	e.iceLimiter.Update(len(networkMap.GetRemotePeers()), e.routingPeers())
 
 	// acls might need routing to be enabled, so we apply after routes
 	if e.acl != nil {
 		e.acl.ApplyFiltering(networkMap)
 	}
+	e.sshJump.updateRules(networkMap.GetRoutesFirewallRules())
 
 	// Ingress forward rules
 	if err := e.updateForwardRules(networkMap.GetForwardingRules()); err != nil {
@@ -1022,6 +1480,10 @@ func (e *Engine) updateNetworkMap(networkMap *mgmProto.NetworkMap) error {
 		}
 	}
 
+	if e.reuseInterface {
+		e.removeStaleWgPeers()
+	}
+
 	protoDNSConfig := networkMap.GetDNSConfig()
 	if protoDNSConfig == nil {
 		protoDNSConfig = &mgmProto.DNSConfig{}
@@ -1040,6 +1502,18 @@ func (e *Engine) updateNetworkMap(networkMap *mgmProto.NetworkMap) error {
 	return nil
 }
 
+// updateConnectionTuning applies the timers of the peer connections sent by the Management service. The timers of the
+// established connections can't be changed, the connections are removed to be recreated with the new timers.
+func (e *Engine) updateConnectionTuning(tuning *mgmProto.ConnectionTuning) error {
+	if proto.Equal(e.connectionTuning, tuning) {
+		return nil
+	}
+
+	log.Infof("connection tuning changed, recreating the peer connections")
+	e.connectionTuning = tuning
+	return e.removeAllPeers()
+}
+
 func toDNSFeatureFlag(networkMap *mgmProto.NetworkMap) bool {
 	if networkMap.PeerConfig != nil {
 		return networkMap.PeerConfig.RoutingPeerDnsResolutionEnabled
@@ -1096,6 +1570,17 @@ func toRouteDomains(myPubKey string, protoRoutes []*mgmProto.Route) []string {
 	return dnsRoutes
 }
 
+// toForwarderNSDomains returns the domains of the nameserver groups the peer serves to the other peers
+func toForwarderNSDomains(protoDNSConfig *mgmProto.DNSConfig) []string {
+	var domains []string
+	for _, nsGroup := range protoDNSConfig.GetNameServerGroups() {
+		if nsGroup.GetForwarder() {
+			domains = append(domains, nsGroup.GetDomains()...)
+		}
+	}
+	return domains
+}
+
 func toDNSConfig(protoDNSConfig *mgmProto.DNSConfig, network *net.IPNet) nbdns.Config {
 	dnsUpdate := nbdns.Config{
 		ServiceEnable:    protoDNSConfig.GetServiceEnable(),
@@ -1125,6 +1610,7 @@ func toDNSConfig(protoDNSConfig *mgmProto.DNSConfig, network *net.IPNet) nbdns.C
 			Primary:              nsGroup.GetPrimary(),
 			Domains:              nsGroup.GetDomains(),
 			SearchDomainsEnabled: nsGroup.GetSearchDomainsEnabled(),
+			RoutedAnswers:        nbdns.RoutedAnswers(nsGroup.GetRoutedAnswers()),
 		}
 		for _, ns := range nsGroup.GetNameServers() {
 			dnsNS := nbdns.NameServer{
@@ -1245,22 +1731,36 @@ func (e *Engine) createPeerConn(pubKey string, allowedIPs []netip.Prefix) (*peer
 
 	// randomize connection timeout
 	timeout := time.Duration(rand.Intn(PeerConnectionTimeoutMax-PeerConnectionTimeoutMin)+PeerConnectionTimeoutMin) * time.Millisecond
+	tuning := e.connectionTuning
+	if maxInterval := tuning.GetHandshakeRetryMaxInterval().AsDuration(); maxInterval > 0 {
+		timeout = maxInterval
+	}
+
 	config := peer.ConnConfig{
-		Key:             pubKey,
-		LocalKey:        e.config.WgPrivateKey.PublicKey().String(),
-		Timeout:         timeout,
-		WgConfig:        wgConfig,
-		LocalWgPort:     e.config.WgPort,
-		RosenpassPubKey: e.getRosenpassPubKey(),
-		RosenpassAddr:   e.getRosenpassAddr(),
+		Key:                   pubKey,
+		LocalKey:              e.config.WgPrivateKey.PublicKey().String(),
+		Timeout:               timeout,
+		HandshakeRetryTimeout: tuning.GetHandshakeRetryTimeout().AsDuration(),
+		WgConfig:              wgConfig,
+		WgKeepAlive:           tuning.GetWgKeepalive().AsDuration(),
+		LocalWgPort:           e.config.WgPort,
+		RosenpassPubKey:       e.getRosenpassPubKey(),
+		RosenpassAddr:         e.getRosenpassAddr(),
 		ICEConfig: icemaker.Config{
 			StunTurn:             &e.stunTurn,
-			InterfaceBlackList:   e.config.IFaceBlackList,
+			InterfaceBlackList:   e.iceInterfaceBlackList(),
 			DisableIPv6Discovery: e.config.DisableIPv6Discovery,
 			UDPMux:               e.udpMux.UDPMuxDefault,
 			UDPMuxSrflx:          e.udpMux,
 			NATExternalIPs:       e.parseNATExternalIPMappings(),
+			KeepAlive:            tuning.GetIceKeepalive().AsDuration(),
+			DisconnectedTimeout:  tuning.GetIceDisconnectedTimeout().AsDuration(),
+			FailedTimeout:        tuning.GetIceFailedTimeout().AsDuration(),
 		},
+		Static:         e.config.StaticMesh,
+		StaticEndpoint: e.config.StaticEndpoints[pubKey],
+		RelayOnly:      e.config.PortPolicy.GetTcp443Only(),
+		ICELimiter:     e.iceLimiter,
 	}
 
 	peerConn, err := peer.NewConn(e.ctx, config, e.statusRecorder, e.signaler, e.mobileDep.IFaceDiscover, e.relayManager, e.srWatcher, e.connSemaphore)
@@ -1281,6 +1781,11 @@ func (e *Engine) receiveSignalEvents() {
 	go func() {
 		// connect to a stream of messages coming from the signal server
 		err := e.signal.Receive(e.ctx, func(msg *sProto.Message) error {
+			if faultinject.Hit(faultinject.SignalDrop) {
+				log.Debugf("dropped the signal message from %s", msg.Key)
+				return nil
+			}
+
 			e.syncMsgMux.Lock()
 			defer e.syncMsgMux.Unlock()
 
@@ -1345,6 +1850,11 @@ func (e *Engine) receiveSignalEvents() {
 
 				go conn.OnRemoteCandidate(candidate, e.routeManager.GetClientRoutes())
 			case sProto.Body_MODE:
+			case sProto.Body_GO_AWAY:
+				log.Infof("peer %s is shutting down, moving its routes to other routing peers", msg.Key)
+				if err := e.statusRecorder.MarkPeerDraining(msg.Key); err != nil {
+					log.Debugf("failed to mark peer %s as draining: %v", msg.Key, err)
+				}
 			}
 
 			return nil
@@ -1414,8 +1924,19 @@ func (e *Engine) parseNATExternalIPMappings() []string {
 }
 
 func (e *Engine) close() {
-	log.Debugf("removing Netbird interface %s", e.config.WgIfaceName)
+	if e.wgInterface != nil && e.handOverInterface {
+		log.Debugf("releasing Netbird interface %s for the next engine", e.config.WgIfaceName)
+		if err := e.wgInterface.Release(); err != nil {
+			log.Errorf("failed releasing Netbird interface %s, removing it: %v", e.config.WgIfaceName, err)
+			e.handOverInterface = false
+		} else {
+			e.releasedInterface = e.wgInterface
+			e.wgInterface = nil
+		}
+	}
+
 	if e.wgInterface != nil {
+		log.Debugf("removing Netbird interface %s", e.config.WgIfaceName)
 		if err := e.wgInterface.Close(); err != nil {
 			log.Errorf("failed closing Netbird interface %s %v", e.config.WgIfaceName, err)
 		}
@@ -1452,6 +1973,7 @@ func (e *Engine) readInitialSettings() ([]*route.Route, *nbdns.Config, error) {
 		e.config.DisableDNS,
 		e.config.DisableFirewall,
 	)
+	e.setAdvertisedRoutes(info)
 
 	netMap, err := e.mgmClient.GetNetworkMap(info)
 	if err != nil {
@@ -1476,6 +1998,8 @@ func (e *Engine) newWgIface() (*iface.WGIface, error) {
 		MTU:          iface.DefaultMTU,
 		TransportNet: transportNet,
 		FilterFn:     e.addrViaRoutes,
+
+		ReuseInterface: e.reuseInterface,
 	}
 
 	switch runtime.GOOS {
@@ -1535,7 +2059,7 @@ func (e *Engine) newDnsServer() ([]*route.Route, dns.Server, error) {
 		return nil, dnsServer, nil
 
 	default:
-		dnsServer, err := dns.NewDefaultServer(e.ctx, e.wgInterface, e.config.CustomDNSAddress, e.statusRecorder, e.stateManager, e.config.DisableDNS)
+		dnsServer, err := dns.NewDefaultServer(e.ctx, e.wgInterface, e.config.CustomDNSAddress, e.statusRecorder, e.stateManager, e.config.DisableDNS, e.config.DNSHostsFile)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -1643,8 +2167,43 @@ func (e *Engine) probeTURNs() []relay.ProbeResult {
 	return relay.ProbeAll(e.ctx, relay.ProbeTURN, turns)
 }
 
+// startConnectivityReporter reports the NAT type, the STUN and TURN reachability, the relayed connections, the
+// health of the client routes and the routed traffic to the management service periodically
+func (e *Engine) startConnectivityReporter() {
+	servers := func() ([]*stun.URI, []*stun.URI) {
+		e.syncMsgMux.Lock()
+		defer e.syncMsgMux.Unlock()
+		return slices.Clone(e.STUNs), slices.Clone(e.TURNs)
+	}
+	routes := func() route.HAMap {
+		if e.routeManager == nil {
+			return nil
+		}
+		return e.routeManager.GetClientRoutes()
+	}
+	forwarded := func() (map[netip.Addr]firewallManager.ForwardedTraffic, error) {
+		fm, ok := e.firewall.(firewallManager.TrafficAccountingManager)
+		if !ok {
+			return nil, errors.New("the firewall doesn't count the routed traffic")
+		}
+		return fm.ForwardedTraffic()
+	}
+	connectivity.NewReporter(e.mgmClient, servers, routes, forwarded, e.statusRecorder).Start(e.ctx)
+}
+
 // restartEngine restarts the engine by cancelling the client context
 func (e *Engine) restartEngine() {
+	e.restart(false)
+}
+
+// hotRestartEngine restarts the engine but hands the kernel interface over to the next engine if possible. The peers
+// stay on the WireGuard device with their sessions while the engine is recreated, the peers connected directly
+// resume without a new handshake. The interface is set down meanwhile.
+func (e *Engine) hotRestartEngine() {
+	e.restart(true)
+}
+
+func (e *Engine) restart(handOver bool) {
 	e.syncMsgMux.Lock()
 	defer e.syncMsgMux.Unlock()
 
@@ -1652,6 +2211,11 @@ func (e *Engine) restartEngine() {
 		return
 	}
 
+	if handOver && e.canHandOverInterface() {
+		log.Info("restarting engine, handing the interface over to the next engine")
+		e.handOverInterface = true
+	}
+
 	log.Info("restarting engine")
 	CtxGetState(e.ctx).Set(StatusConnecting)
 	_ = CtxGetState(e.ctx).Wrap(ErrResetConnection)
@@ -1659,6 +2223,50 @@ func (e *Engine) restartEngine() {
 	e.clientCancel()
 }
 
+// canHandOverInterface checks if the interface can outlive the engine. Rosenpass keeps the preshared keys of the
+// peers in the manager of the engine, its peers can't keep their sessions.
+func (e *Engine) canHandOverInterface() bool {
+	return e.wgInterface != nil && e.wgInterface.Releasable() && !e.config.RosenpassEnabled
+}
+
+// SetReuseInterface makes the engine take over the interface handed over by the previous engine
+func (e *Engine) SetReuseInterface(reuse bool) {
+	e.syncMsgMux.Lock()
+	defer e.syncMsgMux.Unlock()
+
+	e.reuseInterface = reuse
+}
+
+// ReleasedInterface returns the interface the stopped engine handed over to the next engine, nil if it removed it
+func (e *Engine) ReleasedInterface() WGIface {
+	e.syncMsgMux.Lock()
+	defer e.syncMsgMux.Unlock()
+
+	return e.releasedInterface
+}
+
+// removeStaleWgPeers removes the peers of the interface handed over by the previous engine that aren't peers of the
+// network map anymore
+func (e *Engine) removeStaleWgPeers() {
+	e.reuseInterface = false
+
+	wgPeers, err := e.wgInterface.ListPeers()
+	if err != nil {
+		log.Warnf("failed to list the peers of the reused interface: %v", err)
+		return
+	}
+
+	for _, peerKey := range wgPeers {
+		if _, ok := e.peerStore.PeerConn(peerKey); ok {
+			continue
+		}
+		log.Debugf("removing stale peer %s from the reused interface", peerKey)
+		if err := e.wgInterface.RemovePeer(peerKey); err != nil {
+			log.Warnf("failed to remove stale peer %s: %v", peerKey, err)
+		}
+	}
+}
+
 func (e *Engine) startNetworkMonitor() {
 	if !e.config.NetworkMonitor {
 		log.Infof("Network monitor is disabled, not starting")
@@ -1676,11 +2284,440 @@ func (e *Engine) startNetworkMonitor() {
 			return
 		}
 
+		if e.sleeping.Load() {
+			log.Infof("Network monitor: detected network change while the system sleeps, restarting engine on wake")
+			return
+		}
+
 		log.Infof("Network monitor: detected network change, restarting engine")
-		e.restartEngine()
+		e.hotRestartEngine()
+	}()
+}
+
+// startSleepDetector pauses the keepalives of the peers while the system sleeps and restarts the engine on wake, the
+// connections are established again right away instead of after the timeouts of the stale ones
+func (e *Engine) startSleepDetector() {
+	if runtime.GOOS == "android" || runtime.GOOS == "ios" {
+		return
+	}
+
+	e.sleepDetector = sleep.New()
+	if err := e.sleepDetector.Start(e.onSleepEvent); err != nil {
+		log.Errorf("failed to start the sleep detector: %v", err)
+		e.sleepDetector = nil
+	}
+}
+
+func (e *Engine) onSleepEvent(event sleep.Event) {
+	if e.ctx.Err() != nil {
+		return
+	}
+
+	switch event {
+	case sleep.EventSleep:
+		log.Infof("the system goes to sleep, pausing the keepalives of the peers")
+		e.sleeping.Store(true)
+		e.setKeepAlivePaused(true)
+	case sleep.EventWake:
+		log.Infof("the system woke up, restarting engine")
+		go func() {
+			if e.sleeping.Swap(false) {
+				e.setKeepAlivePaused(false)
+			}
+			e.hotRestartEngine()
+		}()
+	}
+}
+
+func (e *Engine) setKeepAlivePaused(paused bool) {
+	for _, peerKey := range e.peerStore.PeersPubKey() {
+		conn, ok := e.peerStore.PeerConn(peerKey)
+		if !ok {
+			continue
+		}
+		if err := conn.SetKeepAlivePaused(paused); err != nil {
+			log.Debugf("failed to update the keepalive of peer %s: %v", peerKey, err)
+		}
+	}
+}
+
+// startUplinkTracker starts tracking the uplinks if several are configured
+func (e *Engine) startUplinkTracker() {
+	if len(e.config.Uplinks) == 0 {
+		return
+	}
+
+	log.Infof("tracking uplinks [ %s ] in the order of preference", strings.Join(e.config.Uplinks, " "))
+	e.uplinkTracker = uplink.NewTracker(e.config.Uplinks, e.config.UplinkTargets, e.onUplinkSwitch)
+	e.uplinkTracker.Start(e.ctx)
+}
+
+// startWatchdog supervises the interface, the dns server and the firewall. The wedged dns server is restarted, the
+// engine is recreated for a removed interface since it creates the interface again. Missing firewall rules are
+// applied again by a new engine taking the interface over.
+func (e *Engine) startWatchdog() {
+	if e.watchdog == nil {
+		return
+	}
+
+	checks := []watchdog.Check{
+		{Name: "interface", Probe: e.wgInterface.CheckDevice, Recover: e.recreateEngine},
+		{Name: "dns server", Probe: e.dnsServer.CheckService, Recover: e.dnsServer.RestartService},
+	}
+	if !e.config.DisableFirewall {
+		checks = append(checks, watchdog.Check{Name: "firewall", Probe: e.checkFirewall, Recover: e.recreateEngineHot})
+	}
+	go e.watchdog.Run(e.ctx, checks)
+}
+
+// checkFirewall returns an error if the firewall manager couldn't be created or rules it applied are missing
+func (e *Engine) checkFirewall() error {
+	e.syncMsgMux.Lock()
+	fw := e.firewall
+	e.syncMsgMux.Unlock()
+
+	if fw == nil {
+		return errors.New("firewall manager not created")
+	}
+
+	dump, err := fw.DumpRules()
+	if err != nil {
+		return fmt.Errorf("dump rules: %w", err)
+	}
+
+	for dump != nil {
+		for _, set := range dump.RuleSets {
+			if len(set.Missing) > 0 {
+				return fmt.Errorf("%d rules missing from %s chain %s", len(set.Missing), dump.Backend, set.Chain)
+			}
+		}
+		dump = dump.Native
+	}
+	return nil
+}
+
+func (e *Engine) recreateEngine() error {
+	e.restartEngine()
+	return nil
+}
+
+func (e *Engine) recreateEngineHot() error {
+	e.hotRestartEngine()
+	return nil
+}
+
+// startRouterMode tunes the host for routing if the peer runs as a gateway appliance
+func (e *Engine) startRouterMode() {
+	if !e.config.RouterMode {
+		return
+	}
+
+	if e.config.DisableServerRoutes {
+		log.Warnf("router mode is enabled but the server routes are disabled, the peer won't route the traffic of its LANs")
+	}
+	log.Infof("router mode: advertising LANs [ %s ]", strings.Join(e.config.RouterLANs, " "))
+
+	tuning, err := routermode.Enable()
+	if err != nil {
+		log.Warnf("failed to tune the host for router mode: %v", err)
+	}
+	e.routerTuning = tuning
+}
+
+// Drain prepares the shutdown of a routing peer: the connected peers are asked to move the routes to other routing
+// peers and the new flows are dropped. The established flows have the drain period to finish before the routing
+// rules are removed by Stop.
+func (e *Engine) Drain(ctx context.Context) {
+	if e.config.RouteDrainPeriod <= 0 {
+		return
+	}
+
+	if !e.startDrain() {
+		return
+	}
+
+	log.Infof("draining the routed flows for %s", e.config.RouteDrainPeriod)
+	timer := time.NewTimer(e.config.RouteDrainPeriod)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		log.Info("drain period elapsed")
+	case <-ctx.Done():
+		log.Infof("drain interrupted: %v", ctx.Err())
+	}
+}
+
+// startDrain drops the new routed flows and signals the shutdown to the peers, it returns false if the peer doesn't
+// route any network
+func (e *Engine) startDrain() bool {
+	e.syncMsgMux.Lock()
+	defer e.syncMsgMux.Unlock()
+
+	// the LAN hosts move to a backup of the virtual gateway while the peers move to another routing peer
+	if e.lanGateway != nil {
+		e.lanGateway.Release()
+	}
+
+	if e.routeManager == nil {
+		return false
+	}
+
+	routing, err := e.routeManager.DrainServerRoutes()
+	if err != nil {
+		log.Warnf("failed to drop the new routed flows: %v", err)
+	}
+	if !routing {
+		return false
+	}
+
+	for _, peerKey := range e.peerStore.PeersPubKey() {
+		if err := e.signaler.SignalGoAway(peerKey); err != nil {
+			log.Debugf("failed to signal the shutdown to peer %s: %v", peerKey, err)
+		}
+	}
+	return true
+}
+
+// startRouteLearning learns the routes of the site from its routers if enabled
+func (e *Engine) startRouteLearning() {
+	if e.config.RouteLearning == "" {
+		return
+	}
+
+	source, err := routelearn.NewSource(e.config.RouteLearning)
+	if err != nil {
+		log.Errorf("failed to start the route learning: %v", err)
+		return
+	}
+	filter, err := routelearn.ParseFilter(e.config.RouteLearningFilters)
+	if err != nil {
+		log.Errorf("failed to start the route learning: %v", err)
+		return
+	}
+
+	var exclude []netip.Prefix
+	if network, err := netip.ParsePrefix(e.wgInterface.Address().Network.String()); err == nil {
+		exclude = append(exclude, network)
+	}
+
+	log.Infof("learning the routes from %s", e.config.RouteLearning)
+	e.routeLearner = routelearn.NewLearner(source, filter, exclude, e.onLearnedRoutes)
+	e.routeLearner.Start(e.ctx)
+}
+
+// onLearnedRoutes advertises the changed learned routes to management
+func (e *Engine) onLearnedRoutes([]netip.Prefix) {
+	// the learner must not wait for the engine lock, the engine waits for the learner when it stops
+	go func() {
+		e.syncMsgMux.Lock()
+		defer e.syncMsgMux.Unlock()
+
+		if e.ctx.Err() != nil {
+			return
+		}
+		if err := e.syncMeta(); err != nil {
+			log.Warnf("failed to advertise the learned routes: %v", err)
+		}
 	}()
 }
 
+// setAdvertisedRoutes sets the router mode LANs and the learned routes advertised to management
+func (e *Engine) setAdvertisedRoutes(info *system.Info) {
+	info.SetRouterMode(e.config.RouterMode, e.config.RouterLANs)
+	if e.routeLearner == nil {
+		return
+	}
+
+	routes := slices.Clone(info.AdvertisedRoutes)
+	for _, prefix := range e.routeLearner.Routes() {
+		if !slices.Contains(routes, prefix.String()) {
+			routes = append(routes, prefix.String())
+		}
+	}
+	info.AdvertisedRoutes = routes
+}
+
+// startLANGateway shares the virtual LAN gateway IP with the other routing peers if configured
+func (e *Engine) startLANGateway() {
+	if e.config.LANGateway == nil {
+		return
+	}
+
+	localIP, ok := netip.AddrFromSlice(e.wgInterface.Address().IP)
+	if !ok {
+		log.Errorf("failed to start the LAN gateway: invalid NetBird IP %s", e.wgInterface.Address().IP)
+		return
+	}
+
+	if e.firewall != nil {
+		port := firewallManager.Port{Values: []uint16{langateway.Port}}
+		for _, peerIP := range e.config.LANGateway.Peers {
+			// this rule is static and will be torn down on engine down by the firewall manager
+			if _, err := e.firewall.AddPeerFiltering(
+				nil,
+				peerIP.AsSlice(),
+				firewallManager.ProtocolUDP,
+				nil,
+				&port,
+				firewallManager.ActionAccept,
+				"",
+			); err != nil {
+				log.Errorf("failed to allow the LAN gateway adverts of peer %s: %v", peerIP, err)
+			}
+		}
+	}
+
+	gateway := langateway.New(*e.config.LANGateway, localIP.Unmap(), e.lanGatewayHealth, e.onLANGatewayStateChange)
+	if err := gateway.Start(e.ctx); err != nil {
+		log.Errorf("failed to start the LAN gateway: %v", err)
+		return
+	}
+	e.lanGateway = gateway
+}
+
+// lanGatewayHealth fails while the peer has lost the control plane, a backup with a working overlay takes the virtual
+// gateway over
+func (e *Engine) lanGatewayHealth() error {
+	if !e.statusRecorder.GetManagementState().Connected && !e.statusRecorder.GetSignalState().Connected {
+		return errors.New("management and signal are disconnected")
+	}
+	return nil
+}
+
+// onLANGatewayStateChange publishes the takeovers and the losses of the virtual LAN gateway
+func (e *Engine) onLANGatewayStateChange(from, to langateway.State) {
+	vip := e.config.LANGateway.VirtualIP.String()
+	meta := map[string]string{"virtual_ip": vip, "from": from.String(), "to": to.String()}
+
+	switch {
+	case to == langateway.StateMaster:
+		e.statusRecorder.PublishEvent(
+			cProto.SystemEvent_INFO,
+			cProto.SystemEvent_NETWORK,
+			"LAN gateway taken over",
+			fmt.Sprintf("This peer holds the virtual LAN gateway %s.", vip),
+			meta,
+		)
+	case from == langateway.StateMaster:
+		e.statusRecorder.PublishEvent(
+			cProto.SystemEvent_WARNING,
+			cProto.SystemEvent_NETWORK,
+			"LAN gateway handed over",
+			fmt.Sprintf("This peer no longer holds the virtual LAN gateway %s, it's %s now.", vip, to),
+			meta,
+		)
+	}
+}
+
+// startFileTransfer receives the files of the peers into the file transfer directory if configured. No firewall rule is
+// added for the port, the access policies decide which peers can send files.
+func (e *Engine) startFileTransfer() {
+	if e.config.FileTransferDir == "" {
+		return
+	}
+
+	localIP, ok := netip.AddrFromSlice(e.wgInterface.Address().IP)
+	if !ok {
+		log.Errorf("failed to start receiving files: invalid NetBird IP %s", e.wgInterface.Address().IP)
+		return
+	}
+
+	server := filetransfer.NewServer(e.config.FileTransferDir, e.fileTransferPeer, e.onFileReceived)
+	if err := server.Start(localIP.Unmap()); err != nil {
+		log.Errorf("failed to start receiving files: %v", err)
+		return
+	}
+	e.fileTransfer = server
+}
+
+// fileTransferPeer returns the FQDN of the peer with the NetBird IP
+func (e *Engine) fileTransferPeer(ip netip.Addr) (string, bool) {
+	return e.statusRecorder.PeerByIP(ip.String())
+}
+
+// onFileReceived publishes the files received from the peers
+func (e *Engine) onFileReceived(peer, path string, size int64) {
+	e.statusRecorder.PublishEvent(
+		cProto.SystemEvent_INFO,
+		cProto.SystemEvent_SYSTEM,
+		"File received",
+		fmt.Sprintf("Received %s from peer %s.", path, peer),
+		map[string]string{"peer": peer, "path": path, "size": strconv.FormatInt(size, 10)},
+	)
+}
+
+// onUplinkSwitch moves the tunnels to the new active uplink
+func (e *Engine) onUplinkSwitch(from, to string) {
+	if from != "" {
+		e.statusRecorder.PublishEvent(
+			cProto.SystemEvent_WARNING,
+			cProto.SystemEvent_NETWORK,
+			"uplink switched",
+			fmt.Sprintf("The traffic moved from uplink %s to %s.", from, to),
+			map[string]string{"from": from, "to": to},
+		)
+	}
+
+	// the tracker must not wait for the engine lock, the engine waits for the tracker when it stops
+	go func() {
+		e.syncMsgMux.Lock()
+		defer e.syncMsgMux.Unlock()
+
+		if e.ctx.Err() != nil {
+			return
+		}
+		e.recreatePeerConns()
+	}()
+}
+
+// iceInterfaceBlackList returns the interfaces ignored by the candidate gathering, including the inactive uplinks
+func (e *Engine) iceInterfaceBlackList() []string {
+	if e.uplinkTracker == nil {
+		return e.config.IFaceBlackList
+	}
+	return append(slices.Clone(e.config.IFaceBlackList), e.uplinkTracker.ExcludedInterfaces()...)
+}
+
+// recreatePeerConns replaces the peer connections with new ones gathering the candidates again, the peers stay in
+// the status recorder
+func (e *Engine) recreatePeerConns() {
+	for _, peerKey := range e.peerStore.PeersPubKey() {
+		oldConn, ok := e.peerStore.Remove(peerKey)
+		if !ok {
+			continue
+		}
+		allowedIPs := oldConn.WgConfig().AllowedIps
+		oldConn.Close()
+
+		conn, err := e.createPeerConn(peerKey, allowedIPs)
+		if err != nil {
+			log.Errorf("failed to recreate the connection of peer %s: %v", peerKey, err)
+			continue
+		}
+		e.peerStore.AddPeerConn(peerKey, conn)
+
+		if e.beforePeerHook != nil && e.afterPeerHook != nil {
+			conn.AddBeforeAddPeerHook(e.beforePeerHook)
+			conn.AddAfterRemovePeerHook(e.afterPeerHook)
+		}
+		conn.Open()
+	}
+}
+
+// routingPeers returns the peers routing the client routes, including the exit nodes
+func (e *Engine) routingPeers() []string {
+	var peers []string
+	for _, routes := range e.routeManager.GetClientRoutes() {
+		for _, r := range routes {
+			if r != nil && !slices.Contains(peers, r.Peer) {
+				peers = append(peers, r.Peer)
+			}
+		}
+	}
+	return peers
+}
+
 func (e *Engine) addrViaRoutes(addr netip.Addr) (bool, netip.Prefix, error) {
 	var vpnRoutes []netip.Prefix
 	for _, routes := range e.routeManager.GetClientRoutes() {
@@ -1727,6 +2764,30 @@ func (e *Engine) SetNetworkMapPersistence(enabled bool) {
 	}
 }
 
+// SetDebugBundleHandler sets the handler of the debug bundles requested by the management service
+func (e *Engine) SetDebugBundleHandler(handler DebugBundleHandler) {
+	e.syncMsgMux.Lock()
+	defer e.syncMsgMux.Unlock()
+
+	e.debugBundleHandler = handler
+}
+
+// SetPeerActionHandler sets the handler of the peer actions requested by the management service that need the daemon
+func (e *Engine) SetPeerActionHandler(handler PeerActionHandler) {
+	e.syncMsgMux.Lock()
+	defer e.syncMsgMux.Unlock()
+
+	e.peerActionHandler = handler
+}
+
+// SetWatchdog sets the watchdog supervising the components of the engine
+func (e *Engine) SetWatchdog(w *watchdog.Watchdog) {
+	e.syncMsgMux.Lock()
+	defer e.syncMsgMux.Unlock()
+
+	e.watchdog = w
+}
+
 // GetLatestNetworkMap returns the stored network map if persistence is enabled
 func (e *Engine) GetLatestNetworkMap() (*mgmProto.NetworkMap, error) {
 	e.syncMsgMux.Lock()
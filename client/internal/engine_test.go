@@ -94,6 +94,10 @@ type MockWGIface struct {
 	GetDeviceFunc              func() *device.FilteredDevice
 	GetWGDeviceFunc            func() *wgdevice.Device
 	GetStatsFunc               func(peerKey string) (configurer.WGStats, error)
+	CheckDeviceFunc            func() error
+	ListPeersFunc              func() ([]string, error)
+	ReleasableFunc             func() bool
+	ReleaseFunc                func() error
 	GetInterfaceGUIDStringFunc func() (string, error)
 	GetProxyFunc               func() wgproxy.Proxy
 	GetNetFunc                 func() *netstack.Net
@@ -175,6 +179,22 @@ func (m *MockWGIface) GetStats(peerKey string) (configurer.WGStats, error) {
 	return m.GetStatsFunc(peerKey)
 }
 
+func (m *MockWGIface) CheckDevice() error {
+	return m.CheckDeviceFunc()
+}
+
+func (m *MockWGIface) ListPeers() ([]string, error) {
+	return m.ListPeersFunc()
+}
+
+func (m *MockWGIface) Releasable() bool {
+	return m.ReleasableFunc()
+}
+
+func (m *MockWGIface) Release() error {
+	return m.ReleaseFunc()
+}
+
 func (m *MockWGIface) GetProxy() wgproxy.Proxy {
 	return m.GetProxyFunc()
 }
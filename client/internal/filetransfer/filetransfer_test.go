@@ -0,0 +1,100 @@
+package filetransfer
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T) (*Server, string, chan string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	received := make(chan string, 1)
+	server := NewServer(dir, func(ip netip.Addr) (string, bool) {
+		return "peer.netbird.cloud", ip.IsLoopback()
+	}, func(_, path string, _ int64) {
+		received <- path
+	})
+	require.NoError(t, server.Start(netip.MustParseAddr("127.0.0.1")))
+	t.Cleanup(server.Stop)
+	return server, dir, received
+}
+
+func testHeader(path string, content []byte) Header {
+	hash := sha256.Sum256(content)
+	return Header{Path: path, Size: int64(len(content)), SHA256: hex.EncodeToString(hash[:]), Mode: 0o644}
+}
+
+func TestTransfer_Resume(t *testing.T) {
+	_, dir, received := newTestServer(t)
+	content := bytes.Repeat([]byte("netbird"), 10000)
+	header := testHeader("drop/report.txt", content)
+	localhost := netip.MustParseAddr("127.0.0.1")
+
+	// the first transfer stops halfway
+	upload, err := Dial(context.Background(), localhost, header)
+	require.NoError(t, err)
+	assert.Zero(t, upload.Offset)
+	_, err = upload.Write(content[:len(content)/2])
+	require.NoError(t, err)
+	require.NoError(t, upload.Close())
+
+	// the second one resumes where the receiver stopped
+	require.Eventually(t, func() bool {
+		upload, err = Dial(context.Background(), localhost, header)
+		require.NoError(t, err)
+		if upload.Offset == int64(len(content)/2) {
+			return true
+		}
+		_ = upload.Close()
+		return false
+	}, 5*time.Second, 100*time.Millisecond)
+	_, err = upload.Write(content[upload.Offset:])
+	require.NoError(t, err)
+	require.NoError(t, upload.Finish())
+	require.NoError(t, upload.Close())
+
+	path := <-received
+	assert.Equal(t, filepath.Join(dir, "drop", "report.txt"), path)
+	got, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, content, got)
+}
+
+func TestTransfer_PathEscape(t *testing.T) {
+	server, dir, _ := newTestServer(t)
+
+	path, err := server.destination("../../etc/passwd")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "etc", "passwd"), path, "the path should stay in the file transfer directory")
+
+	_, err = server.destination("/")
+	assert.Error(t, err)
+
+	_, err = server.destination(partialDir + "/x")
+	assert.Error(t, err)
+}
+
+func TestTransfer_HashMismatch(t *testing.T) {
+	_, _, _ = newTestServer(t)
+	content := []byte("netbird")
+	header := testHeader("file.txt", content)
+
+	upload, err := Dial(context.Background(), netip.MustParseAddr("127.0.0.1"), header)
+	require.NoError(t, err)
+	defer upload.Close()
+
+	_, err = upload.Write([]byte("NETBIRD"))
+	require.NoError(t, err)
+	assert.Error(t, upload.Finish(), "the receiver should reject content not matching the hash")
+}
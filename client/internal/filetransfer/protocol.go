@@ -0,0 +1,84 @@
+// Package filetransfer copies files between the daemons of two peers over the overlay, like netbird cp file
+// peer:path. The receiving daemon accepts the files of the peers its access policies let reach Port into its file
+// transfer directory. An interrupted transfer of the same content resumes where it stopped.
+package filetransfer
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// Port is the TCP port the daemons receive the files on over the NetBird interface
+	Port = 22113
+
+	// maxMessageLen bounds the header and the replies, the content follows the header unframed
+	maxMessageLen = 64 << 10
+)
+
+// Header describes the file sent to the peer
+type Header struct {
+	// Path is the destination of the file relative to the file transfer directory of the peer
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+	// SHA256 is the hex encoded hash of the content, it identifies the partial file a transfer resumes
+	SHA256 string `json:"sha256"`
+	Mode   uint32 `json:"mode"`
+}
+
+// Reply is sent by the receiver once after the header, with the offset the content resumes at, and once after the
+// content
+type Reply struct {
+	Offset int64  `json:"offset,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+func (h Header) validate() error {
+	if h.Path == "" {
+		return errors.New("empty path")
+	}
+	if h.Size < 0 {
+		return fmt.Errorf("invalid size %d", h.Size)
+	}
+	if len(h.SHA256) != 64 {
+		return errors.New("invalid content hash")
+	}
+	return nil
+}
+
+// writeMessage writes the JSON message prefixed by its length
+func writeMessage(w io.Writer, msg any) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshal message: %w", err)
+	}
+	buf := make([]byte, 4+len(data))
+	binary.BigEndian.PutUint32(buf, uint32(len(data)))
+	copy(buf[4:], data)
+	if _, err := w.Write(buf); err != nil {
+		return fmt.Errorf("write message: %w", err)
+	}
+	return nil
+}
+
+func readMessage(r io.Reader, msg any) error {
+	var lenBuf [4]byte
+	if _, err := io.ReadFull(r, lenBuf[:]); err != nil {
+		return fmt.Errorf("read message: %w", err)
+	}
+	n := binary.BigEndian.Uint32(lenBuf[:])
+	if n > maxMessageLen {
+		return fmt.Errorf("message of %d bytes is too large", n)
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return fmt.Errorf("read message: %w", err)
+	}
+	if err := json.Unmarshal(data, msg); err != nil {
+		return fmt.Errorf("unmarshal message: %w", err)
+	}
+	return nil
+}
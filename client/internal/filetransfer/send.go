@@ -0,0 +1,102 @@
+package filetransfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"time"
+)
+
+const dialTimeout = 10 * time.Second
+
+// Upload is a file transfer to a peer, the content is written from Offset on
+type Upload struct {
+	conn   net.Conn
+	header Header
+	// Offset is the position the content resumes at, the receiver has the content before it from a previous transfer
+	Offset int64
+	sent   int64
+}
+
+// Dial starts the transfer of the file described by the header to the peer with the NetBird IP
+func Dial(ctx context.Context, peerIP netip.Addr, header Header) (*Upload, error) {
+	if err := header.validate(); err != nil {
+		return nil, err
+	}
+
+	dialer := net.Dialer{Timeout: dialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", netip.AddrPortFrom(peerIP, Port).String())
+	if err != nil {
+		return nil, fmt.Errorf("connect to peer: %w", err)
+	}
+
+	upload, err := start(conn, header)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return upload, nil
+}
+
+func start(conn net.Conn, header Header) (*Upload, error) {
+	if err := conn.SetDeadline(time.Now().Add(idleTimeout)); err != nil {
+		return nil, err
+	}
+	if err := writeMessage(conn, header); err != nil {
+		return nil, err
+	}
+
+	var reply Reply
+	if err := readMessage(conn, &reply); err != nil {
+		return nil, err
+	}
+	if reply.Error != "" {
+		return nil, fmt.Errorf("peer refused the file: %s", reply.Error)
+	}
+	if reply.Offset < 0 || reply.Offset > header.Size {
+		return nil, fmt.Errorf("invalid resume offset %d", reply.Offset)
+	}
+	return &Upload{conn: conn, header: header, Offset: reply.Offset}, nil
+}
+
+// Write sends the next part of the content
+func (u *Upload) Write(p []byte) (int, error) {
+	if u.Offset+u.sent+int64(len(p)) > u.header.Size {
+		return 0, errors.New("content exceeds the file size")
+	}
+	if err := u.conn.SetDeadline(time.Now().Add(idleTimeout)); err != nil {
+		return 0, err
+	}
+	n, err := u.conn.Write(p)
+	u.sent += int64(n)
+	return n, err
+}
+
+// Finish waits for the peer to verify and store the file
+func (u *Upload) Finish() error {
+	if u.Offset+u.sent != u.header.Size {
+		return fmt.Errorf("sent %d of %d bytes", u.Offset+u.sent, u.header.Size)
+	}
+	if err := u.conn.SetDeadline(time.Now().Add(idleTimeout)); err != nil {
+		return err
+	}
+
+	var reply Reply
+	if err := readMessage(u.conn, &reply); err != nil {
+		return err
+	}
+	if reply.Error != "" {
+		return fmt.Errorf("peer failed to store the file: %s", reply.Error)
+	}
+	return nil
+}
+
+// Close closes the connection to the peer, an unfinished transfer resumes on the next one
+func (u *Upload) Close() error {
+	return u.conn.Close()
+}
+
+var _ io.WriteCloser = (*Upload)(nil)
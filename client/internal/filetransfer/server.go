@@ -0,0 +1,251 @@
+package filetransfer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// partialDir holds the content of the interrupted transfers in the file transfer directory, by content hash
+	partialDir = ".netbird-partial"
+	// idleTimeout closes the transfers stalled for this long, the sender resumes them
+	idleTimeout = time.Minute
+)
+
+// PeerLookup returns the name of the peer with the NetBird IP, false for the addresses that aren't peers
+type PeerLookup func(ip netip.Addr) (string, bool)
+
+// ReceiveFunc is called for each received file with the name of the peer and the path of the file
+type ReceiveFunc func(peer, path string, size int64)
+
+// Server receives the files of the peers into its directory
+type Server struct {
+	dir       string
+	lookup    PeerLookup
+	onReceive ReceiveFunc
+
+	listener net.Listener
+	wg       sync.WaitGroup
+}
+
+// NewServer creates a Server receiving the files into dir
+func NewServer(dir string, lookup PeerLookup, onReceive ReceiveFunc) *Server {
+	return &Server{dir: dir, lookup: lookup, onReceive: onReceive}
+}
+
+// Start listens for the transfers of the peers on the NetBird IP
+func (s *Server) Start(localIP netip.Addr) error {
+	if err := os.MkdirAll(filepath.Join(s.dir, partialDir), 0o750); err != nil {
+		return fmt.Errorf("create file transfer directory: %w", err)
+	}
+
+	listener, err := net.Listen("tcp", netip.AddrPortFrom(localIP, Port).String())
+	if err != nil {
+		return fmt.Errorf("listen for file transfers: %w", err)
+	}
+	s.listener = listener
+
+	s.wg.Add(1)
+	go s.serve()
+
+	log.Infof("receiving files of the peers into %s on %s", s.dir, listener.Addr())
+	return nil
+}
+
+// Stop stops receiving files, the running transfers are resumed by the senders
+func (s *Server) Stop() {
+	if s.listener == nil {
+		return
+	}
+	if err := s.listener.Close(); err != nil {
+		log.Debugf("failed to close the file transfer listener: %v", err)
+	}
+	s.wg.Wait()
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+
+	var conns sync.WaitGroup
+	defer conns.Wait()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Errorf("failed to accept file transfer: %v", err)
+			}
+			return
+		}
+
+		conns.Add(1)
+		go func() {
+			defer conns.Done()
+			s.handle(conn)
+		}()
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer func() {
+		if err := conn.Close(); err != nil {
+			log.Debugf("failed to close file transfer connection: %v", err)
+		}
+	}()
+
+	remote, err := netip.ParseAddrPort(conn.RemoteAddr().String())
+	if err != nil {
+		return
+	}
+	// the source address is authenticated by WireGuard, the access policies let the peer reach the port
+	peer, ok := s.lookup(remote.Addr().Unmap())
+	if !ok {
+		log.Warnf("rejected file transfer from %s: not a peer", remote.Addr())
+		return
+	}
+
+	if err := conn.SetDeadline(time.Now().Add(idleTimeout)); err != nil {
+		return
+	}
+	var header Header
+	if err := readMessage(conn, &header); err != nil {
+		log.Debugf("failed to read file transfer header of peer %s: %v", peer, err)
+		return
+	}
+
+	path, err := s.receive(conn, header)
+	if err != nil {
+		log.Warnf("failed to receive file %s of peer %s: %v", header.Path, peer, err)
+		_ = writeMessage(conn, Reply{Error: err.Error()})
+		return
+	}
+	if err := writeMessage(conn, Reply{}); err != nil {
+		log.Debugf("failed to confirm file %s of peer %s: %v", path, peer, err)
+	}
+
+	log.Infof("received file %s of %d bytes from peer %s", path, header.Size, peer)
+	if s.onReceive != nil {
+		s.onReceive(peer, path, header.Size)
+	}
+}
+
+// receive writes the content to the partial file of its hash from the offset of a previous transfer, then moves it to
+// the destination once the hash matches
+func (s *Server) receive(conn net.Conn, header Header) (string, error) {
+	if err := header.validate(); err != nil {
+		return "", err
+	}
+	if _, err := hex.DecodeString(header.SHA256); err != nil {
+		return "", errors.New("invalid content hash")
+	}
+	path, err := s.destination(header.Path)
+	if err != nil {
+		return "", err
+	}
+
+	partial := filepath.Join(s.dir, partialDir, strings.ToLower(header.SHA256))
+	f, err := os.OpenFile(partial, os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return "", fmt.Errorf("open partial file: %w", err)
+	}
+	defer func() {
+		if err := f.Close(); err != nil && !errors.Is(err, os.ErrClosed) {
+			log.Debugf("failed to close partial file: %v", err)
+		}
+	}()
+
+	offset, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return "", fmt.Errorf("seek partial file: %w", err)
+	}
+	if offset > header.Size {
+		if err := f.Truncate(0); err != nil {
+			return "", fmt.Errorf("truncate partial file: %w", err)
+		}
+		offset = 0
+	}
+	if err := writeMessage(conn, Reply{Offset: offset}); err != nil {
+		return "", err
+	}
+
+	if _, err := io.CopyN(f, &deadlineReader{conn: conn}, header.Size-offset); err != nil {
+		return "", fmt.Errorf("receive content: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("close partial file: %w", err)
+	}
+
+	if err := verify(partial, header.SHA256); err != nil {
+		if rmErr := os.Remove(partial); rmErr != nil {
+			log.Debugf("failed to remove corrupt partial file: %v", rmErr)
+		}
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return "", fmt.Errorf("create directory: %w", err)
+	}
+	if err := os.Chmod(partial, os.FileMode(header.Mode).Perm()|0o600); err != nil {
+		log.Debugf("failed to set the mode of %s: %v", path, err)
+	}
+	if err := os.Rename(partial, path); err != nil {
+		return "", fmt.Errorf("move to %s: %w", path, err)
+	}
+	return path, nil
+}
+
+// destination returns the path of the file in the file transfer directory, the paths can't leave it
+func (s *Server) destination(path string) (string, error) {
+	rel := filepath.Clean(string(filepath.Separator) + filepath.FromSlash(path))
+	if rel == string(filepath.Separator) {
+		return "", errors.New("path is the file transfer directory")
+	}
+	dst := filepath.Join(s.dir, rel)
+	if first := strings.Split(strings.TrimPrefix(rel, string(filepath.Separator)), string(filepath.Separator))[0]; first == partialDir {
+		return "", fmt.Errorf("path %s is reserved", path)
+	}
+	return dst, nil
+}
+
+func verify(path, want string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open partial file: %w", err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return fmt.Errorf("hash partial file: %w", err)
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(got, want) {
+		return errors.New("content hash mismatch")
+	}
+	return nil
+}
+
+// deadlineReader extends the deadline of the connection on each read, so only the stalled transfers time out
+type deadlineReader struct {
+	conn net.Conn
+}
+
+func (r *deadlineReader) Read(p []byte) (int, error) {
+	if err := r.conn.SetDeadline(time.Now().Add(idleTimeout)); err != nil {
+		return 0, err
+	}
+	return r.conn.Read(p)
+}
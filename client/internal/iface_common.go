@@ -37,4 +37,8 @@ type wgIfaceBase interface {
 	GetWGDevice() *wgdevice.Device
 	GetStats(peerKey string) (configurer.WGStats, error)
 	GetNet() *netstack.Net
+	CheckDevice() error
+	ListPeers() ([]string, error)
+	Releasable() bool
+	Release() error
 }
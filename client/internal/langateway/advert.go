@@ -0,0 +1,55 @@
+package langateway
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+const (
+	advertVersion = 0x11
+	advertLen     = 8
+
+	flagMaster = 1 << 0
+)
+
+// advert is sent by the master every advertInterval, a priority of zero releases the virtual IP
+//
+//	0        1          2             3       4..8
+//	version  priority   prefix bits   flags   virtual IP
+type advert struct {
+	virtualIP netip.Prefix
+	priority  int
+	master    bool
+}
+
+func (a advert) marshal() []byte {
+	data := make([]byte, advertLen)
+	data[0] = advertVersion
+	data[1] = byte(a.priority)
+	data[2] = byte(a.virtualIP.Bits())
+	if a.master {
+		data[3] |= flagMaster
+	}
+	ip := a.virtualIP.Addr().As4()
+	copy(data[4:], ip[:])
+	return data
+}
+
+func unmarshalAdvert(data []byte) (advert, error) {
+	if len(data) != advertLen {
+		return advert{}, fmt.Errorf("invalid advert length %d", len(data))
+	}
+	if data[0] != advertVersion {
+		return advert{}, fmt.Errorf("unsupported advert version %#x", data[0])
+	}
+
+	if data[2] > 32 {
+		return advert{}, fmt.Errorf("invalid virtual IP prefix length %d", data[2])
+	}
+
+	return advert{
+		virtualIP: netip.PrefixFrom(netip.AddrFrom4([4]byte(data[4:8])), int(data[2])),
+		priority:  int(data[1]),
+		master:    data[3]&flagMaster != 0,
+	}, nil
+}
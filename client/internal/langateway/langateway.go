@@ -0,0 +1,414 @@
+// Package langateway lets the routing peers of a site share a virtual gateway IP on the LAN, like VRRP or keepalived.
+// The peers exchange adverts over the overlay, the healthy peer with the highest priority holds the virtual IP and a
+// backup takes it over when the adverts of the master stop. The LAN hosts keep using the virtual IP as the next hop
+// of the NetBird networks, so the LAN side of the path is redundant like the overlay side.
+package langateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Port is the UDP port the adverts are exchanged on over the NetBird interface
+	Port = 22112
+
+	// DefaultPriority is the priority of the peers without a configured one
+	DefaultPriority = 100
+	MinPriority     = 1
+	MaxPriority     = 254
+
+	advertInterval = time.Second
+	// masterDownAdverts is the number of adverts a backup misses before it takes the virtual IP over
+	masterDownAdverts = 3
+	tickInterval      = advertInterval / 4
+)
+
+// State of the peer for the virtual IP
+type State int
+
+const (
+	// StateBackup waits for the adverts of the master and takes over if they stop
+	StateBackup State = iota
+	// StateMaster holds the virtual IP
+	StateMaster
+	// StateFault is unhealthy or released, it doesn't take the virtual IP over
+	StateFault
+)
+
+func (s State) String() string {
+	switch s {
+	case StateBackup:
+		return "backup"
+	case StateMaster:
+		return "master"
+	case StateFault:
+		return "fault"
+	default:
+		return "unknown"
+	}
+}
+
+// Config of the virtual LAN gateway
+type Config struct {
+	// VirtualIP is the gateway address shared on the LAN with its prefix, e.g. 192.168.1.1/24
+	VirtualIP netip.Prefix
+	// Interface is the LAN interface the virtual IP is assigned to
+	Interface string
+	// Priority of the peer, the healthy peer with the highest priority holds the virtual IP
+	Priority int
+	// Peers are the NetBird IPs of the other routing peers sharing the virtual IP
+	Peers []netip.Addr
+}
+
+// Validate checks that the virtual IP can be shared
+func (c Config) Validate() error {
+	if !c.VirtualIP.IsValid() || !c.VirtualIP.Addr().Is4() {
+		return fmt.Errorf("virtual IP %s must be an IPv4 address with its prefix, e.g. 192.168.1.1/24", c.VirtualIP)
+	}
+	if c.Interface == "" {
+		return errors.New("the LAN interface of the virtual IP is required")
+	}
+	if c.Priority < MinPriority || c.Priority > MaxPriority {
+		return fmt.Errorf("priority %d is out of range [%d, %d]", c.Priority, MinPriority, MaxPriority)
+	}
+	if len(c.Peers) == 0 {
+		return errors.New("the NetBird IPs of the peers sharing the virtual IP are required")
+	}
+	return nil
+}
+
+// HealthFunc returns an error if the peer can't forward the traffic of the LAN to the overlay
+type HealthFunc func() error
+
+// StateChangeFunc is called when the state of the peer changes
+type StateChangeFunc func(from, to State)
+
+// virtualIP assigns the virtual IP to the LAN interface
+type virtualIP interface {
+	add() error
+	remove() error
+	// announce tells the LAN hosts the virtual IP moved to the interface
+	announce() error
+	// linkUp returns an error if the LAN interface is down
+	linkUp() error
+}
+
+// Gateway holds the virtual IP while it's the master of the peers sharing it
+type Gateway struct {
+	config   Config
+	localIP  netip.Addr
+	health   HealthFunc
+	onChange StateChangeFunc
+	vip      virtualIP
+	// send delivers an advert to a peer
+	send func(data []byte, peer netip.Addr) error
+
+	conn *net.UDPConn
+
+	mu         sync.Mutex
+	state      State
+	released   bool
+	masterDown time.Time
+	lastAdvert time.Time
+	master     netip.Addr
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New creates the virtual LAN gateway of the peer with the NetBird IP localIP
+func New(config Config, localIP netip.Addr, health HealthFunc, onChange StateChangeFunc) *Gateway {
+	g := &Gateway{
+		config:   config,
+		localIP:  localIP,
+		health:   health,
+		onChange: onChange,
+		vip:      newVirtualIP(config.Interface, config.VirtualIP),
+		state:    StateBackup,
+	}
+	g.send = g.writeAdvert
+	return g
+}
+
+// Start listens for the adverts of the peers and starts as a backup, the virtual IP is taken over if no master
+// advertises it
+func (g *Gateway) Start(ctx context.Context) error {
+	conn, err := net.ListenUDP("udp", net.UDPAddrFromAddrPort(netip.AddrPortFrom(g.localIP, Port)))
+	if err != nil {
+		return fmt.Errorf("listen for adverts: %w", err)
+	}
+	g.conn = conn
+
+	g.mu.Lock()
+	g.masterDown = time.Now().Add(g.masterDownInterval())
+	g.mu.Unlock()
+
+	ctx, g.cancel = context.WithCancel(ctx)
+	g.done = make(chan struct{})
+
+	go g.receive()
+	go func() {
+		defer close(g.done)
+		g.run(ctx)
+	}()
+
+	log.Infof("sharing the LAN gateway %s on %s with priority %d", g.config.VirtualIP, g.config.Interface, g.config.Priority)
+	return nil
+}
+
+// Stop releases the virtual IP to the backups and stops the adverts
+func (g *Gateway) Stop() {
+	if g.cancel == nil {
+		return
+	}
+	g.Release()
+	g.cancel()
+	<-g.done
+
+	if err := g.conn.Close(); err != nil {
+		log.Debugf("failed to close the advert socket: %v", err)
+	}
+}
+
+// Release hands the virtual IP over to the backups and doesn't take it back, e.g. while the routing peer drains
+func (g *Gateway) Release() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.released {
+		return
+	}
+	g.released = true
+	g.toFault("released")
+}
+
+// State returns the state of the peer for the virtual IP
+func (g *Gateway) State() State {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.state
+}
+
+// Master returns the NetBird IP of the master, the local IP if the peer is the master
+func (g *Gateway) Master() netip.Addr {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.state == StateMaster {
+		return g.localIP
+	}
+	return g.master
+}
+
+func (g *Gateway) run(ctx context.Context) {
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			g.tick(time.Now())
+		}
+	}
+}
+
+// tick checks the health of the peer, takes the virtual IP over if the master is down and sends the adverts
+func (g *Gateway) tick(now time.Time) {
+	healthErr := g.checkHealth()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	switch {
+	case g.released:
+		return
+	case healthErr != nil:
+		if g.state != StateFault {
+			g.toFault(healthErr.Error())
+		}
+		return
+	case g.state == StateFault:
+		log.Infof("the LAN gateway is healthy again, waiting for the adverts of the master")
+		g.masterDown = now.Add(g.masterDownInterval())
+		g.setState(StateBackup)
+	}
+
+	if g.state == StateBackup && !now.Before(g.masterDown) {
+		g.toMaster()
+	}
+	if g.state == StateMaster && now.Sub(g.lastAdvert) >= advertInterval {
+		g.sendAdvert(g.config.Priority, true)
+		g.lastAdvert = now
+	}
+}
+
+func (g *Gateway) checkHealth() error {
+	if err := g.vip.linkUp(); err != nil {
+		return err
+	}
+	if g.health != nil {
+		return g.health()
+	}
+	return nil
+}
+
+// toMaster assigns the virtual IP and announces it on the LAN
+func (g *Gateway) toMaster() {
+	if err := g.vip.add(); err != nil {
+		log.Errorf("failed to assign the LAN gateway %s: %v", g.config.VirtualIP, err)
+		g.masterDown = time.Now().Add(g.masterDownInterval())
+		return
+	}
+	if err := g.vip.announce(); err != nil {
+		log.Warnf("failed to announce the LAN gateway %s: %v", g.config.VirtualIP, err)
+	}
+
+	log.Infof("took the LAN gateway %s over", g.config.VirtualIP)
+	g.master = g.localIP
+	g.setState(StateMaster)
+	g.sendAdvert(g.config.Priority, true)
+	g.lastAdvert = time.Now()
+}
+
+// toBackup removes the virtual IP, the master advertised it with a higher priority
+func (g *Gateway) toBackup(master netip.Addr) {
+	if err := g.vip.remove(); err != nil {
+		log.Errorf("failed to remove the LAN gateway %s: %v", g.config.VirtualIP, err)
+	}
+
+	log.Infof("peer %s took the LAN gateway %s over", master, g.config.VirtualIP)
+	g.master = master
+	g.masterDown = time.Now().Add(g.masterDownInterval())
+	g.setState(StateBackup)
+}
+
+// toFault hands the virtual IP over to the backups with an advert of priority zero
+func (g *Gateway) toFault(reason string) {
+	if g.state == StateMaster {
+		g.sendAdvert(0, true)
+		if err := g.vip.remove(); err != nil {
+			log.Errorf("failed to remove the LAN gateway %s: %v", g.config.VirtualIP, err)
+		}
+	}
+
+	log.Infof("not holding the LAN gateway %s: %s", g.config.VirtualIP, reason)
+	g.setState(StateFault)
+}
+
+func (g *Gateway) setState(state State) {
+	if g.state == state {
+		return
+	}
+	from := g.state
+	g.state = state
+	if g.onChange != nil {
+		g.onChange(from, state)
+	}
+}
+
+// masterDownInterval is the time a backup waits for the adverts of the master, the backups with a higher priority
+// take over first
+func (g *Gateway) masterDownInterval() time.Duration {
+	return masterDownAdverts*advertInterval + g.skew()
+}
+
+func (g *Gateway) skew() time.Duration {
+	return time.Duration(256-g.config.Priority) * advertInterval / 256
+}
+
+// outranks reports if the advert of the peer takes precedence over the local priority, the higher NetBird IP wins
+// the ties
+func (g *Gateway) outranks(priority int, from netip.Addr) bool {
+	return priority > g.config.Priority || (priority == g.config.Priority && from.Compare(g.localIP) > 0)
+}
+
+func (g *Gateway) receive() {
+	buf := make([]byte, 64)
+	for {
+		n, addr, err := g.conn.ReadFromUDPAddrPort(buf)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Debugf("failed to read the LAN gateway advert: %v", err)
+				continue
+			}
+			return
+		}
+
+		from := addr.Addr().Unmap()
+		if !slices.Contains(g.config.Peers, from) {
+			log.Debugf("dropping the LAN gateway advert of unknown peer %s", from)
+			continue
+		}
+
+		adv, err := unmarshalAdvert(buf[:n])
+		if err != nil {
+			log.Debugf("dropping the LAN gateway advert of peer %s: %v", from, err)
+			continue
+		}
+		if adv.virtualIP != g.config.VirtualIP {
+			log.Warnf("peer %s advertises the LAN gateway %s instead of %s, check the configuration", from, adv.virtualIP, g.config.VirtualIP)
+			continue
+		}
+
+		g.handleAdvert(from, adv, time.Now())
+	}
+}
+
+func (g *Gateway) handleAdvert(from netip.Addr, adv advert, now time.Time) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !adv.master {
+		return
+	}
+
+	switch g.state {
+	case StateMaster:
+		if adv.priority > 0 && g.outranks(adv.priority, from) {
+			g.toBackup(from)
+			return
+		}
+		// the lower ranked master yields on the advert
+		g.sendAdvert(g.config.Priority, true)
+		g.lastAdvert = now
+	case StateBackup:
+		switch {
+		case adv.priority == 0:
+			// the master released the virtual IP, the backups take over after their skew
+			g.masterDown = now.Add(g.skew())
+		case g.outranks(adv.priority, from):
+			g.master = from
+			g.masterDown = now.Add(g.masterDownInterval())
+		}
+	case StateFault:
+		if adv.priority > 0 {
+			g.master = from
+		}
+	}
+}
+
+func (g *Gateway) sendAdvert(priority int, master bool) {
+	data := advert{virtualIP: g.config.VirtualIP, priority: priority, master: master}.marshal()
+	for _, peer := range g.config.Peers {
+		if err := g.send(data, peer); err != nil {
+			log.Debugf("failed to send the LAN gateway advert to peer %s: %v", peer, err)
+		}
+	}
+}
+
+func (g *Gateway) writeAdvert(data []byte, peer netip.Addr) error {
+	_, err := g.conn.WriteToUDPAddrPort(data, netip.AddrPortFrom(peer, Port))
+	return err
+}
@@ -0,0 +1,185 @@
+package langateway
+
+import (
+	"errors"
+	"net/netip"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeVIP struct {
+	assigned  bool
+	announced int
+	down      bool
+}
+
+func (f *fakeVIP) add() error {
+	f.assigned = true
+	return nil
+}
+
+func (f *fakeVIP) remove() error {
+	f.assigned = false
+	return nil
+}
+
+func (f *fakeVIP) announce() error {
+	f.announced++
+	return nil
+}
+
+func (f *fakeVIP) linkUp() error {
+	if f.down {
+		return errors.New("link is down")
+	}
+	return nil
+}
+
+type sentAdvert struct {
+	peer netip.Addr
+	adv  advert
+}
+
+func newTestGateway(t *testing.T, priority int, localIP string) (*Gateway, *fakeVIP, *[]sentAdvert) {
+	t.Helper()
+
+	config := Config{
+		VirtualIP: netip.MustParsePrefix("192.168.1.1/24"),
+		Interface: "eth1",
+		Priority:  priority,
+		Peers:     []netip.Addr{netip.MustParseAddr("100.64.0.2")},
+	}
+	require.NoError(t, config.Validate())
+
+	g := New(config, netip.MustParseAddr(localIP), nil, nil)
+	vip := &fakeVIP{}
+	g.vip = vip
+
+	var mu sync.Mutex
+	var sent []sentAdvert
+	g.send = func(data []byte, peer netip.Addr) error {
+		adv, err := unmarshalAdvert(data)
+		require.NoError(t, err)
+		mu.Lock()
+		sent = append(sent, sentAdvert{peer: peer, adv: adv})
+		mu.Unlock()
+		return nil
+	}
+	return g, vip, &sent
+}
+
+func TestAdvert_Marshal(t *testing.T) {
+	adv := advert{virtualIP: netip.MustParsePrefix("10.0.0.1/16"), priority: 150, master: true}
+
+	got, err := unmarshalAdvert(adv.marshal())
+	require.NoError(t, err)
+	assert.Equal(t, adv, got)
+
+	_, err = unmarshalAdvert([]byte{advertVersion, 1, 2})
+	assert.Error(t, err, "short adverts should be rejected")
+
+	data := adv.marshal()
+	data[2] = 33
+	_, err = unmarshalAdvert(data)
+	assert.Error(t, err, "invalid prefix lengths should be rejected")
+}
+
+func TestGateway_TakeOverAndYield(t *testing.T) {
+	g, vip, sent := newTestGateway(t, DefaultPriority, "100.64.0.1")
+	peer := netip.MustParseAddr("100.64.0.2")
+	now := time.Now()
+	g.masterDown = now.Add(g.masterDownInterval())
+
+	g.tick(now)
+	assert.Equal(t, StateBackup, g.State(), "the backup should wait for the adverts of a master")
+
+	g.tick(now.Add(g.masterDownInterval()))
+	assert.Equal(t, StateMaster, g.State())
+	assert.True(t, vip.assigned)
+	assert.Equal(t, 1, vip.announced)
+	require.NotEmpty(t, *sent)
+	assert.Equal(t, peer, (*sent)[0].peer)
+	assert.Equal(t, DefaultPriority, (*sent)[0].adv.priority)
+
+	// a peer with a lower priority yields to the master
+	g.handleAdvert(peer, advert{virtualIP: g.config.VirtualIP, priority: DefaultPriority - 1, master: true}, now)
+	assert.Equal(t, StateMaster, g.State())
+
+	// the peer with a higher priority preempts the master
+	g.handleAdvert(peer, advert{virtualIP: g.config.VirtualIP, priority: DefaultPriority + 1, master: true}, now)
+	assert.Equal(t, StateBackup, g.State())
+	assert.False(t, vip.assigned)
+	assert.Equal(t, peer, g.Master())
+}
+
+func TestGateway_TieBreak(t *testing.T) {
+	g, _, _ := newTestGateway(t, DefaultPriority, "100.64.0.1")
+
+	assert.True(t, g.outranks(DefaultPriority, netip.MustParseAddr("100.64.0.2")), "the higher IP should win the tie")
+	assert.False(t, g.outranks(DefaultPriority, netip.MustParseAddr("100.64.0.0")))
+}
+
+func TestGateway_ReleasedMaster(t *testing.T) {
+	g, _, _ := newTestGateway(t, DefaultPriority, "100.64.0.1")
+	peer := netip.MustParseAddr("100.64.0.2")
+	now := time.Now()
+	g.masterDown = now.Add(g.masterDownInterval())
+
+	g.handleAdvert(peer, advert{virtualIP: g.config.VirtualIP, priority: DefaultPriority + 1, master: true}, now)
+	assert.Equal(t, peer, g.Master())
+
+	// the master releases the virtual IP, the backup takes over after its skew instead of the master down interval
+	g.handleAdvert(peer, advert{virtualIP: g.config.VirtualIP, priority: 0, master: true}, now)
+	g.tick(now.Add(g.skew()))
+	assert.Equal(t, StateMaster, g.State())
+}
+
+func TestGateway_Fault(t *testing.T) {
+	g, vip, sent := newTestGateway(t, DefaultPriority, "100.64.0.1")
+	now := time.Now()
+
+	g.tick(now)
+	require.Equal(t, StateMaster, g.State())
+
+	vip.down = true
+	g.tick(now)
+	assert.Equal(t, StateFault, g.State())
+	assert.False(t, vip.assigned)
+	last := (*sent)[len(*sent)-1]
+	assert.Equal(t, 0, last.adv.priority, "the master should release the virtual IP with priority zero")
+
+	vip.down = false
+	g.tick(now)
+	assert.Equal(t, StateBackup, g.State(), "a recovered peer should wait for the adverts of a master")
+
+	g.Release()
+	g.tick(now.Add(time.Hour))
+	assert.Equal(t, StateFault, g.State(), "a released peer should not take the virtual IP over")
+	assert.False(t, vip.assigned)
+}
+
+func TestConfig_Validate(t *testing.T) {
+	valid := Config{
+		VirtualIP: netip.MustParsePrefix("192.168.1.1/24"),
+		Interface: "eth1",
+		Priority:  DefaultPriority,
+		Peers:     []netip.Addr{netip.MustParseAddr("100.64.0.2")},
+	}
+	assert.NoError(t, valid.Validate())
+
+	v6 := valid
+	v6.VirtualIP = netip.MustParsePrefix("fd00::1/64")
+	assert.Error(t, v6.Validate())
+
+	noPeers := valid
+	noPeers.Peers = nil
+	assert.Error(t, noPeers.Validate())
+
+	priority := valid
+	priority.Priority = 255
+	assert.Error(t, priority.Validate())
+}
@@ -0,0 +1,140 @@
+//go:build !android
+
+package langateway
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	etherTypeARP  = 0x0806
+	arpFrameLen   = 42
+	arpOpRequest  = 1
+	arpHWEthernet = 1
+)
+
+// linkVIP assigns the virtual IP to the LAN interface with netlink and announces it with gratuitous ARP
+type linkVIP struct {
+	ifaceName string
+	prefix    netip.Prefix
+}
+
+func newVirtualIP(ifaceName string, prefix netip.Prefix) virtualIP {
+	return &linkVIP{ifaceName: ifaceName, prefix: prefix}
+}
+
+func (v *linkVIP) addr() *netlink.Addr {
+	return &netlink.Addr{
+		IPNet: &net.IPNet{
+			IP:   v.prefix.Addr().AsSlice(),
+			Mask: net.CIDRMask(v.prefix.Bits(), 32),
+		},
+		Label: v.ifaceName,
+	}
+}
+
+func (v *linkVIP) add() error {
+	link, err := netlink.LinkByName(v.ifaceName)
+	if err != nil {
+		return fmt.Errorf("get link %s: %w", v.ifaceName, err)
+	}
+	if err := netlink.AddrReplace(link, v.addr()); err != nil {
+		return fmt.Errorf("add address %s to %s: %w", v.prefix, v.ifaceName, err)
+	}
+	return nil
+}
+
+func (v *linkVIP) remove() error {
+	link, err := netlink.LinkByName(v.ifaceName)
+	if err != nil {
+		return fmt.Errorf("get link %s: %w", v.ifaceName, err)
+	}
+	if err := netlink.AddrDel(link, v.addr()); err != nil && !errors.Is(err, unix.EADDRNOTAVAIL) {
+		return fmt.Errorf("remove address %s from %s: %w", v.prefix, v.ifaceName, err)
+	}
+	return nil
+}
+
+func (v *linkVIP) linkUp() error {
+	link, err := netlink.LinkByName(v.ifaceName)
+	if err != nil {
+		return fmt.Errorf("get link %s: %w", v.ifaceName, err)
+	}
+	attrs := link.Attrs()
+	if attrs.Flags&net.FlagUp == 0 {
+		return fmt.Errorf("link %s is down", v.ifaceName)
+	}
+	if attrs.OperState == netlink.OperDown || attrs.OperState == netlink.OperLowerLayerDown {
+		return fmt.Errorf("link %s has no carrier", v.ifaceName)
+	}
+	return nil
+}
+
+// announce broadcasts a gratuitous ARP request, so the LAN hosts and switches learn the MAC address of the new master
+func (v *linkVIP) announce() error {
+	iface, err := net.InterfaceByName(v.ifaceName)
+	if err != nil {
+		return fmt.Errorf("get interface %s: %w", v.ifaceName, err)
+	}
+	if len(iface.HardwareAddr) != 6 {
+		return fmt.Errorf("interface %s has no ethernet address", v.ifaceName)
+	}
+
+	fd, err := unix.Socket(unix.AF_PACKET, unix.SOCK_RAW, int(htons(etherTypeARP)))
+	if err != nil {
+		return fmt.Errorf("create packet socket: %w", err)
+	}
+	defer func() {
+		if err := unix.Close(fd); err != nil {
+			log.Debugf("failed to close the packet socket: %v", err)
+		}
+	}()
+
+	broadcast := [8]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	sa := &unix.SockaddrLinklayer{
+		Protocol: htons(etherTypeARP),
+		Ifindex:  iface.Index,
+		Halen:    6,
+		Addr:     broadcast,
+	}
+	if err := unix.Sendto(fd, gratuitousARP(iface.HardwareAddr, v.prefix.Addr()), 0, sa); err != nil {
+		return os.NewSyscallError("sendto", err)
+	}
+	return nil
+}
+
+// gratuitousARP builds an ARP request for the virtual IP from the virtual IP
+func gratuitousARP(mac net.HardwareAddr, ip netip.Addr) []byte {
+	frame := make([]byte, arpFrameLen)
+	ip4 := ip.As4()
+
+	// ethernet header
+	copy(frame[0:6], []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+	copy(frame[6:12], mac)
+	binary.BigEndian.PutUint16(frame[12:14], etherTypeARP)
+
+	// arp payload
+	binary.BigEndian.PutUint16(frame[14:16], arpHWEthernet)
+	binary.BigEndian.PutUint16(frame[16:18], unix.ETH_P_IP)
+	frame[18] = 6
+	frame[19] = 4
+	binary.BigEndian.PutUint16(frame[20:22], arpOpRequest)
+	copy(frame[22:28], mac)
+	copy(frame[28:32], ip4[:])
+	// the target hardware address stays zero
+	copy(frame[38:42], ip4[:])
+	return frame
+}
+
+func htons(v uint16) uint16 {
+	return v<<8 | v>>8
+}
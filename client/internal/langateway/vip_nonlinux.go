@@ -0,0 +1,36 @@
+//go:build !linux || android
+
+package langateway
+
+import (
+	"fmt"
+	"net/netip"
+	"runtime"
+)
+
+// unsupportedVIP keeps the peer in the fault state, the virtual IP is supported on Linux only
+type unsupportedVIP struct{}
+
+func newVirtualIP(string, netip.Prefix) virtualIP {
+	return unsupportedVIP{}
+}
+
+func (unsupportedVIP) add() error {
+	return errUnsupported()
+}
+
+func (unsupportedVIP) remove() error {
+	return nil
+}
+
+func (unsupportedVIP) announce() error {
+	return errUnsupported()
+}
+
+func (unsupportedVIP) linkUp() error {
+	return errUnsupported()
+}
+
+func errUnsupported() error {
+	return fmt.Errorf("the LAN gateway is not supported on %s", runtime.GOOS)
+}
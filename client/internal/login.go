@@ -2,6 +2,7 @@ package internal
 
 import (
 	"context"
+	"errors"
 	"net/url"
 
 	"github.com/google/uuid"
@@ -14,8 +15,12 @@ import (
 	"github.com/netbirdio/netbird/client/system"
 	mgm "github.com/netbirdio/netbird/management/client"
 	mgmProto "github.com/netbirdio/netbird/management/proto"
+	"github.com/netbirdio/netbird/util/errcode"
 )
 
+// ErrInteractiveLoginDisabled is returned when a peer in router mode needs a login without a setup key
+var ErrInteractiveLoginDisabled = errors.New("interactive login is disabled in router mode, log in with a setup key")
+
 // IsLoginRequired check that the server is support SSO or not
 func IsLoginRequired(ctx context.Context, config *Config) (bool, error) {
 	mgmURL := config.ManagementURL
@@ -95,7 +100,7 @@ func getMgmClient(ctx context.Context, privateKey string, mgmURL *url.URL) (*mgm
 	mgmClient, err := mgm.NewClient(ctx, mgmURL.Host, myPrivateKey, mgmTlsEnabled)
 	if err != nil {
 		log.Errorf("failed connecting to the Management service %s %v", mgmURL.String(), err)
-		return nil, err
+		return nil, errcode.Wrap(errcode.ManagementUnavailable, err)
 	}
 	return mgmClient, err
 }
@@ -117,6 +122,7 @@ func doMgmLogin(ctx context.Context, mgmClient *mgm.GrpcClient, pubSSHKey []byte
 		config.DisableDNS,
 		config.DisableFirewall,
 	)
+	sysInfo.SetRouterMode(config.RouterMode, config.RouterLANs)
 	_, err = mgmClient.Login(*serverKey, sysInfo, pubSSHKey, config.DNSLabels)
 	return serverKey, err
 }
@@ -140,6 +146,7 @@ func registerPeer(ctx context.Context, serverPublicKey wgtypes.Key, client *mgm.
 		config.DisableDNS,
 		config.DisableFirewall,
 	)
+	info.SetRouterMode(config.RouterMode, config.RouterLANs)
 	loginResp, err := client.Register(serverPublicKey, validSetupKey.String(), jwtToken, info, pubSSHKey, config.DNSLabels)
 	if err != nil {
 		log.Errorf("failed registering peer %v,%s", err, validSetupKey.String())
@@ -0,0 +1,35 @@
+package mdm
+
+import (
+	"github.com/netbirdio/netbird/client/internal"
+)
+
+// Apply overrides the settings of the config input with the managed settings. It returns the managed settings
+// the input had a different value for, the value of the user is discarded.
+func (p *Policy) Apply(input *internal.ConfigInput) []string {
+	var overridden []string
+
+	if p.ManagementURL != "" {
+		if input.ManagementURL != "" && input.ManagementURL != p.ManagementURL {
+			overridden = append(overridden, SettingManagementURL)
+		}
+		input.ManagementURL = p.ManagementURL
+	}
+
+	if p.DisableDNS != nil {
+		if input.DisableDNS != nil && *input.DisableDNS != *p.DisableDNS {
+			overridden = append(overridden, SettingDisableDNS)
+		}
+		disableDNS := *p.DisableDNS
+		input.DisableDNS = &disableDNS
+	}
+
+	if p.CustomDNSAddress != "" {
+		if len(input.CustomDNSAddress) > 0 && string(input.CustomDNSAddress) != p.CustomDNSAddress {
+			overridden = append(overridden, SettingCustomDNSAddress)
+		}
+		input.CustomDNSAddress = []byte(p.CustomDNSAddress)
+	}
+
+	return overridden
+}
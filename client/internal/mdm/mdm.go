@@ -0,0 +1,116 @@
+// Package mdm reads the managed configuration pushed to the device by a mobile device management (MDM) solution:
+// the registry policies on Windows, the configuration profiles on macOS, the managed configuration of the app on
+// Android and a policy file on Linux and FreeBSD. The managed settings take precedence over the settings of the user.
+package mdm
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"slices"
+)
+
+// UI actions the policy can disallow
+const (
+	UIActionConnect          = "connect"
+	UIActionDisconnect       = "disconnect"
+	UIActionSettings         = "settings"
+	UIActionAdvancedSettings = "advanced-settings"
+	UIActionDebugBundle      = "debug-bundle"
+	UIActionNetworks         = "networks"
+	UIActionExitNode         = "exit-node"
+	UIActionQuit             = "quit"
+)
+
+// Names of the managed settings reported to the UI
+const (
+	SettingManagementURL    = "managementUrl"
+	SettingSetupKey         = "setupKey"
+	SettingDisableDNS       = "disableDns"
+	SettingCustomDNSAddress = "customDnsAddress"
+)
+
+// Policy is the managed configuration of the client, the settings without a value are left to the user
+type Policy struct {
+	ManagementURL string
+	// SetupKey registers the peer without user interaction
+	SetupKey string
+	// DisabledUIActions are the actions hidden from the user in the UI
+	DisabledUIActions []string
+	// DisableDNS stops the client from configuring the DNS of the system
+	DisableDNS *bool
+	// CustomDNSAddress is the ip:port address the local DNS resolver listens on
+	CustomDNSAddress string
+}
+
+// Read returns the managed policy of the device, the policy is empty if the device isn't managed
+func Read() (*Policy, error) {
+	policy, err := readPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid managed policy: %w", err)
+	}
+	return policy, nil
+}
+
+// parsePolicy parses the JSON encoding of the policy used by the policy file and the configuration profiles
+func parsePolicy(data []byte) (*Policy, error) {
+	policy := &Policy{}
+	if err := json.Unmarshal(data, policy); err != nil {
+		return nil, fmt.Errorf("parse managed policy: %w", err)
+	}
+	return policy, nil
+}
+
+// Validate checks the values of the managed settings
+func (p *Policy) Validate() error {
+	if p.ManagementURL != "" {
+		u, err := url.ParseRequestURI(p.ManagementURL)
+		if err != nil {
+			return fmt.Errorf("invalid management URL %q: %w", p.ManagementURL, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("invalid management URL %q: the scheme must be http or https", p.ManagementURL)
+		}
+	}
+
+	if p.CustomDNSAddress != "" {
+		if _, err := netip.ParseAddrPort(p.CustomDNSAddress); err != nil {
+			return fmt.Errorf("invalid custom DNS address %q: %w", p.CustomDNSAddress, err)
+		}
+	}
+
+	return nil
+}
+
+// IsEmpty returns true if the policy doesn't manage any setting
+func (p *Policy) IsEmpty() bool {
+	return len(p.ManagedSettings()) == 0 && len(p.DisabledUIActions) == 0
+}
+
+// ManagedSettings returns the names of the settings enforced by the policy
+func (p *Policy) ManagedSettings() []string {
+	var settings []string
+	if p.ManagementURL != "" {
+		settings = append(settings, SettingManagementURL)
+	}
+	if p.SetupKey != "" {
+		settings = append(settings, SettingSetupKey)
+	}
+	if p.DisableDNS != nil {
+		settings = append(settings, SettingDisableDNS)
+	}
+	if p.CustomDNSAddress != "" {
+		settings = append(settings, SettingCustomDNSAddress)
+	}
+	return settings
+}
+
+// UIActionDisabled returns true if the policy disallows the UI action
+func (p *Policy) UIActionDisabled(action string) bool {
+	return slices.Contains(p.DisabledUIActions, action)
+}
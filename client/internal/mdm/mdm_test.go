@@ -0,0 +1,80 @@
+package mdm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/client/internal"
+)
+
+func TestParsePolicy(t *testing.T) {
+	policy, err := parsePolicy([]byte(`{
+  "ManagementURL": "https://netbird.example.com:443",
+  "SetupKey": "A2C8E62B-38F5-4553-B31E-DD66C696CEBB",
+  "DisabledUIActions": ["quit", "advanced-settings"],
+  "DisableDNS": true
+}`))
+	require.NoError(t, err)
+	require.NoError(t, policy.Validate())
+
+	assert.Equal(t, "https://netbird.example.com:443", policy.ManagementURL)
+	assert.True(t, policy.UIActionDisabled(UIActionQuit))
+	assert.False(t, policy.UIActionDisabled(UIActionDisconnect))
+	assert.Equal(t, []string{SettingManagementURL, SettingSetupKey, SettingDisableDNS}, policy.ManagedSettings())
+	assert.False(t, policy.IsEmpty())
+
+	empty, err := parsePolicy([]byte(`{}`))
+	require.NoError(t, err)
+	assert.True(t, empty.IsEmpty())
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy Policy
+		valid  bool
+	}{
+		{name: "empty", policy: Policy{}, valid: true},
+		{name: "management URL", policy: Policy{ManagementURL: "https://netbird.example.com"}, valid: true},
+		{name: "management URL without scheme", policy: Policy{ManagementURL: "netbird.example.com"}},
+		{name: "management URL with unsupported scheme", policy: Policy{ManagementURL: "ftp://netbird.example.com"}},
+		{name: "custom DNS address", policy: Policy{CustomDNSAddress: "127.0.0.153:53"}, valid: true},
+		{name: "custom DNS address without port", policy: Policy{CustomDNSAddress: "127.0.0.153"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestPolicy_Apply(t *testing.T) {
+	disableDNS := true
+	policy := &Policy{
+		ManagementURL: "https://netbird.example.com:443",
+		DisableDNS:    &disableDNS,
+	}
+
+	userDisableDNS := false
+	input := internal.ConfigInput{
+		ManagementURL:    "https://other.example.com:443",
+		DisableDNS:       &userDisableDNS,
+		CustomDNSAddress: []byte("127.0.0.1:5053"),
+	}
+
+	overridden := policy.Apply(&input)
+	assert.Equal(t, []string{SettingManagementURL, SettingDisableDNS}, overridden)
+	assert.Equal(t, "https://netbird.example.com:443", input.ManagementURL)
+	assert.True(t, *input.DisableDNS)
+	assert.Equal(t, "127.0.0.1:5053", string(input.CustomDNSAddress), "the unmanaged settings should be kept")
+
+	assert.Empty(t, policy.Apply(&input), "applying the policy again shouldn't override anything")
+}
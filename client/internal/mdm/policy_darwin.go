@@ -0,0 +1,26 @@
+//go:build !ios
+
+package mdm
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// policyFile is where macOS installs the configuration profiles with the io.netbird.client preferences payload
+const policyFile = "/Library/Managed Preferences/io.netbird.client.plist"
+
+func readPolicy() (*Policy, error) {
+	if _, err := os.Stat(policyFile); errors.Is(err, os.ErrNotExist) {
+		return &Policy{}, nil
+	}
+
+	// plutil reads both the XML and the binary property lists
+	out, err := exec.Command("plutil", "-convert", "json", "-o", "-", policyFile).Output()
+	if err != nil {
+		return nil, fmt.Errorf("convert %s: %w", policyFile, err)
+	}
+	return parsePolicy(out)
+}
@@ -0,0 +1,31 @@
+//go:build android || ios
+
+package mdm
+
+import (
+	"slices"
+	"sync"
+)
+
+var (
+	mobilePolicyMu sync.Mutex
+	mobilePolicy   = &Policy{}
+)
+
+// SetPolicy sets the managed configuration the app received from the platform, like the restrictions of the
+// Android RestrictionsManager
+func SetPolicy(policy *Policy) {
+	mobilePolicyMu.Lock()
+	defer mobilePolicyMu.Unlock()
+
+	mobilePolicy = policy
+}
+
+func readPolicy() (*Policy, error) {
+	mobilePolicyMu.Lock()
+	defer mobilePolicyMu.Unlock()
+
+	policy := *mobilePolicy
+	policy.DisabledUIActions = slices.Clone(policy.DisabledUIActions)
+	return &policy, nil
+}
@@ -0,0 +1,23 @@
+//go:build (linux && !android) || freebsd
+
+package mdm
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// policyFile is the JSON policy file deployed by the device management agents
+const policyFile = "/etc/netbird/managed.json"
+
+func readPolicy() (*Policy, error) {
+	data, err := os.ReadFile(policyFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Policy{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", policyFile, err)
+	}
+	return parsePolicy(data)
+}
@@ -0,0 +1,61 @@
+package mdm
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// policyKey is the registry key of the policies, set by group policies or the MDM with the ADMX backed policies
+const policyKey = `SOFTWARE\Policies\NetBird`
+
+func readPolicy() (*Policy, error) {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, policyKey, registry.QUERY_VALUE)
+	if errors.Is(err, registry.ErrNotExist) {
+		return &Policy{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open registry key %s: %w", policyKey, err)
+	}
+	defer key.Close()
+
+	policy := &Policy{}
+	if policy.ManagementURL, err = stringValue(key, "ManagementURL"); err != nil {
+		return nil, err
+	}
+	if policy.SetupKey, err = stringValue(key, "SetupKey"); err != nil {
+		return nil, err
+	}
+	if policy.CustomDNSAddress, err = stringValue(key, "CustomDNSAddress"); err != nil {
+		return nil, err
+	}
+
+	actions, _, err := key.GetStringsValue("DisabledUIActions")
+	if err != nil && !errors.Is(err, registry.ErrNotExist) {
+		return nil, fmt.Errorf("read registry value DisabledUIActions: %w", err)
+	}
+	policy.DisabledUIActions = actions
+
+	disableDNS, _, err := key.GetIntegerValue("DisableDNS")
+	if err != nil && !errors.Is(err, registry.ErrNotExist) {
+		return nil, fmt.Errorf("read registry value DisableDNS: %w", err)
+	}
+	if err == nil {
+		disabled := disableDNS != 0
+		policy.DisableDNS = &disabled
+	}
+
+	return policy, nil
+}
+
+func stringValue(key registry.Key, name string) (string, error) {
+	value, _, err := key.GetStringValue(name)
+	if errors.Is(err, registry.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("read registry value %s: %w", name, err)
+	}
+	return value, nil
+}
@@ -19,6 +19,8 @@ import (
 	"github.com/netbirdio/netbird/client/iface/wgproxy"
 	"github.com/netbirdio/netbird/client/internal/peer/guard"
 	icemaker "github.com/netbirdio/netbird/client/internal/peer/ice"
+	"github.com/netbirdio/netbird/client/internal/peer/limiter"
+	"github.com/netbirdio/netbird/client/internal/peer/setup"
 	"github.com/netbirdio/netbird/client/internal/stdnet"
 	relayClient "github.com/netbirdio/netbird/relay/client"
 	"github.com/netbirdio/netbird/route"
@@ -45,6 +47,8 @@ func (cp ConnPriority) String() string {
 
 const (
 	defaultWgKeepAlive = 25 * time.Second
+	// handshakePollInterval is the interval the first WireGuard handshake of a new connection is looked for at
+	handshakePollInterval = 100 * time.Millisecond
 
 	connPriorityNone    ConnPriority = 0
 	connPriorityRelay   ConnPriority = 1
@@ -68,8 +72,13 @@ type ConnConfig struct {
 	LocalKey string
 
 	Timeout time.Duration
+	// HandshakeRetryTimeout is the period after which no more offers are sent until the next connection change,
+	// the default applies when 0
+	HandshakeRetryTimeout time.Duration
 
 	WgConfig WgConfig
+	// WgKeepAlive is the persistent keepalive interval of the WireGuard peer, the default applies when 0
+	WgKeepAlive time.Duration
 
 	LocalWgPort int
 
@@ -80,6 +89,19 @@ type ConnConfig struct {
 
 	// ICEConfig ICE protocol configuration
 	ICEConfig icemaker.Config
+
+	// Static configures the WireGuard peer with StaticEndpoint directly, the connection isn't negotiated with ICE or
+	// the relay
+	Static bool
+	// StaticEndpoint is the endpoint of the remote peer in the static mode, the remote peer has to connect first if
+	// it's nil
+	StaticEndpoint *net.UDPAddr
+
+	// RelayOnly skips the ICE negotiation, the connection is established through the relay only
+	RelayOnly bool
+
+	// ICELimiter caps the concurrent ICE negotiations and spreads the reconnections, unlimited when nil
+	ICELimiter *limiter.Limiter
 }
 
 type Conn struct {
@@ -154,14 +176,16 @@ func NewConn(engineCtx context.Context, config ConnConfig, statusRecorder *Statu
 	}
 	conn.workerICE = workerICE
 
-	conn.handshaker = NewHandshaker(ctx, connLog, config, signaler, conn.workerICE, conn.workerRelay)
+	conn.handshaker = NewHandshaker(ctx, connLog, config, signaler, conn.workerICE, conn.workerRelay, statusRecorder)
 
 	conn.handshaker.AddOnNewOfferListener(conn.workerRelay.OnNewOffer)
-	if os.Getenv("NB_FORCE_RELAY") != "true" {
+	if os.Getenv("NB_FORCE_RELAY") != "true" && !config.RelayOnly {
 		conn.handshaker.AddOnNewOfferListener(conn.workerICE.OnNewOffer)
 	}
 
-	conn.guard = guard.NewGuard(connLog, ctrl, conn.isConnectedOnAllWay, config.Timeout, srWatcher)
+	conn.guard = guard.NewGuard(connLog, ctrl, conn.isConnectedOnAllWay, config.Timeout, config.HandshakeRetryTimeout, srWatcher, func() time.Duration {
+		return config.ICELimiter.ReconnectDelay(config.Key)
+	})
 
 	go conn.handshaker.Listen()
 
@@ -170,8 +194,9 @@ func NewConn(engineCtx context.Context, config ConnConfig, statusRecorder *Statu
 }
 
 // Open opens connection to the remote peer
-// It will try to establish a connection using ICE and in parallel with relay. The higher priority connection type will
-// be used.
+// It will try to establish a connection using ICE and in parallel with relay. The relay connection carries the traffic
+// as soon as it's ready and the direct connection takes over once ICE nominated a pair, the relay is kept as standby.
+// Without relay ICE nominates the first working pair to shorten the connection setup.
 func (conn *Conn) Open() {
 	conn.semaphore.Add(conn.ctx)
 	conn.log.Debugf("open connection to peer")
@@ -192,9 +217,40 @@ func (conn *Conn) Open() {
 		conn.log.Warnf("error while updating the state err: %v", err)
 	}
 
+	if conn.config.Static {
+		conn.configureStatic()
+		conn.semaphore.Done(conn.ctx)
+		return
+	}
+
 	go conn.startHandshakeAndReconnect(conn.ctx)
 }
 
+// configureStatic configures the WireGuard peer with the static endpoint. The peer is considered connected once it's
+// configured, WireGuard keeps the tunnel up with the persistent keepalive.
+func (conn *Conn) configureStatic() {
+	ep := conn.config.StaticEndpoint
+	if ep != nil {
+		if err := conn.runBeforeAddPeerHooks(ep.IP); err != nil {
+			conn.log.Errorf("Before add peer hook failed: %v", err)
+		}
+	}
+
+	if err := conn.configureWGEndpoint(ep); err != nil {
+		conn.log.Errorf("failed to configure the static WireGuard peer: %v", err)
+		return
+	}
+
+	var endpoint string
+	if ep != nil {
+		endpoint = ep.String()
+	}
+	conn.currentConnPriority = connPriorityICEP2P
+	conn.statusICE.Set(StatusConnected)
+	conn.updateIceState(ICEConnInfo{RemoteIceCandidateEndpoint: endpoint})
+	conn.doOnConnected(nil, "")
+}
+
 func (conn *Conn) startHandshakeAndReconnect(ctx context.Context) {
 	defer conn.semaphore.Done(conn.ctx)
 	conn.waitInitialRandomSleepTime(ctx)
@@ -211,6 +267,16 @@ func (conn *Conn) startHandshakeAndReconnect(ctx context.Context) {
 
 // Close closes this peer Conn issuing a close event to the Conn closeCh
 func (conn *Conn) Close() {
+	conn.close(true)
+}
+
+// CloseKeepingWgPeer closes this peer Conn but keeps the peer on the WireGuard interface with its session, the
+// interface is handed over to the next engine
+func (conn *Conn) CloseKeepingWgPeer() {
+	conn.close(false)
+}
+
+func (conn *Conn) close(removeWgPeer bool) {
 	conn.mu.Lock()
 	defer conn.wgWatcherWg.Wait()
 	defer conn.mu.Unlock()
@@ -243,8 +309,10 @@ func (conn *Conn) Close() {
 		conn.wgProxyICE = nil
 	}
 
-	if err := conn.removeWgPeer(); err != nil {
-		conn.log.Errorf("failed to remove wg endpoint: %v", err)
+	if removeWgPeer {
+		if err := conn.removeWgPeer(); err != nil {
+			conn.log.Errorf("failed to remove wg endpoint: %v", err)
+		}
 	}
 
 	conn.freeUpConnID()
@@ -333,7 +401,7 @@ func (conn *Conn) onICEConnectionIsReady(priority ConnPriority, iceConnInfo ICEC
 		return
 	}
 
-	conn.log.Infof("set ICE to active connection")
+	conn.log.Infof("set ICE to active connection%s", sinceConfirmation(iceConnInfo.confirmedAt))
 	conn.dumpState.P2PConnected()
 
 	var (
@@ -379,6 +447,9 @@ func (conn *Conn) onICEConnectionIsReady(priority ConnPriority, iceConnInfo ICEC
 		conn.handleConfigurationFailure(err, wgProxy)
 		return
 	}
+	if conn.currentConnPriority == connPriorityNone {
+		conn.measureHandshake()
+	}
 	wgConfigWorkaround()
 	conn.currentConnPriority = priority
 	conn.statusICE.Set(StatusConnected)
@@ -485,6 +556,9 @@ func (conn *Conn) onRelayConnectionIsReady(rci RelayConnInfo) {
 		conn.log.Errorf("Failed to update WireGuard peer configuration: %v", err)
 		return
 	}
+	if conn.currentConnPriority == connPriorityNone {
+		conn.measureHandshake()
+	}
 
 	conn.wgWatcherWg.Add(1)
 	go func() {
@@ -497,7 +571,7 @@ func (conn *Conn) onRelayConnectionIsReady(rci RelayConnInfo) {
 	conn.statusRelay.Set(StatusConnected)
 	conn.setRelayedProxy(wgProxy)
 	conn.updateRelayStatus(rci.relayedConn.RemoteAddr().String(), rci.rosenpassPubKey)
-	conn.log.Infof("start to communicate with peer via relay")
+	conn.log.Infof("start to communicate with peer via relay%s", sinceConfirmation(rci.confirmedAt))
 	conn.doOnConnected(rci.rosenpassPubKey, rci.rosenpassAddr)
 }
 
@@ -560,12 +634,83 @@ func (conn *Conn) configureWGEndpoint(addr *net.UDPAddr) error {
 	return conn.config.WgConfig.WgInterface.UpdatePeer(
 		conn.config.WgConfig.RemoteKey,
 		conn.config.WgConfig.AllowedIps,
-		defaultWgKeepAlive,
+		conn.wgKeepAlive(),
 		addr,
 		conn.config.WgConfig.PreSharedKey,
 	)
 }
 
+// SetKeepAlivePaused turns the persistent keepalive of the configured WireGuard peer off or back on. The keepalives
+// are paused while the system sleeps, the endpoint of the peer is kept.
+func (conn *Conn) SetKeepAlivePaused(paused bool) error {
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+
+	if conn.currentConnPriority == connPriorityNone {
+		return nil
+	}
+
+	var keepAlive time.Duration
+	if !paused {
+		keepAlive = conn.wgKeepAlive()
+	}
+	return conn.config.WgConfig.WgInterface.UpdatePeer(
+		conn.config.WgConfig.RemoteKey,
+		conn.config.WgConfig.AllowedIps,
+		keepAlive,
+		nil,
+		conn.config.WgConfig.PreSharedKey,
+	)
+}
+
+func (conn *Conn) wgKeepAlive() time.Duration {
+	if conn.config.WgKeepAlive > 0 {
+		return conn.config.WgKeepAlive
+	}
+	return defaultWgKeepAlive
+}
+
+// measureHandshake records the time from the configuration of the first endpoint of the peer to the first WireGuard
+// handshake. A session still valid from an earlier connection isn't measured.
+func (conn *Conn) measureHandshake() {
+	configuredAt := time.Now()
+	stats, err := conn.config.WgConfig.WgInterface.GetStats(conn.config.Key)
+	if err != nil {
+		conn.log.Debugf("failed to read the WireGuard stats: %v", err)
+		return
+	}
+	if time.Since(stats.LastHandshake) < wgHandshakePeriod {
+		return
+	}
+	previous := stats.LastHandshake
+
+	go func() {
+		ticker := time.NewTicker(handshakePollInterval)
+		defer ticker.Stop()
+		timeout := time.NewTimer(wgHandshakeOvertime)
+		defer timeout.Stop()
+
+		for {
+			select {
+			case <-conn.ctx.Done():
+				return
+			case <-timeout.C:
+				conn.log.Debugf("no WireGuard handshake within %s", wgHandshakeOvertime)
+				conn.statusRecorder.RecordSetupPhase(conn.config.Key, setup.PhaseHandshake, wgHandshakeOvertime, setup.OutcomeFailure)
+				return
+			case <-ticker.C:
+			}
+
+			stats, err := conn.config.WgConfig.WgInterface.GetStats(conn.config.Key)
+			if err != nil || stats.LastHandshake.Equal(previous) {
+				continue
+			}
+			conn.statusRecorder.RecordSetupPhase(conn.config.Key, setup.PhaseHandshake, time.Since(configuredAt), setup.OutcomeSuccess)
+			return
+		}
+	}()
+}
+
 func (conn *Conn) updateRelayStatus(relayServerAddr string, rosenpassPubKey []byte) {
 	peerState := State{
 		PubKey:             conn.config.Key,
@@ -796,3 +941,12 @@ func isRosenpassEnabled(remoteRosenpassPubKey []byte) bool {
 func wgConfigWorkaround() {
 	time.Sleep(100 * time.Millisecond)
 }
+
+// sinceConfirmation returns the time elapsed since the offer exchange completed for the logs, the path that wins the race
+// between the relay and ICE is the first one logged
+func sinceConfirmation(confirmedAt time.Time) string {
+	if confirmedAt.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf(", %s after the handshake", time.Since(confirmedAt).Round(time.Millisecond))
+}
@@ -22,31 +22,46 @@ type isConnectedFunc func() bool
 // - ICE connection disconnected
 // - Relayed connection disconnected
 // - ICE candidate changes
+// The offers are throttled per peer and the reconnections after a network change are delayed by reconnectDelay, so
+// the peers of a large network don't reconnect all at once.
 type Guard struct {
 	Reconnect               chan struct{}
 	log                     *log.Entry
 	isController            bool
 	isConnectedOnAllWay     isConnectedFunc
 	timeout                 time.Duration
+	maxElapsedTime          time.Duration
 	srWatcher               *SRWatcher
+	reconnectDelay          func() time.Duration
+	offers                  offerThrottle
 	relayedConnDisconnected chan struct{}
 	iCEConnDisconnected     chan struct{}
 }
 
-func NewGuard(log *log.Entry, isController bool, isConnectedFn isConnectedFunc, timeout time.Duration, srWatcher *SRWatcher) *Guard {
+// NewGuard creates a guard sending offers at intervals growing up to the timeout, for the max elapsed time or
+// reconnectMaxElapsedTime when 0. The reconnections after a network change wait for reconnectDelay, if it's set.
+func NewGuard(log *log.Entry, isController bool, isConnectedFn isConnectedFunc, timeout, maxElapsedTime time.Duration, srWatcher *SRWatcher, reconnectDelay func() time.Duration) *Guard {
+	if maxElapsedTime <= 0 {
+		maxElapsedTime = reconnectMaxElapsedTime
+	}
+
 	return &Guard{
 		Reconnect:               make(chan struct{}, 1),
 		log:                     log,
 		isController:            isController,
 		isConnectedOnAllWay:     isConnectedFn,
 		timeout:                 timeout,
+		maxElapsedTime:          maxElapsedTime,
 		srWatcher:               srWatcher,
+		reconnectDelay:          reconnectDelay,
 		relayedConnDisconnected: make(chan struct{}, 1),
 		iCEConnDisconnected:     make(chan struct{}, 1),
 	}
 }
 
 func (g *Guard) Start(ctx context.Context) {
+	defer g.offers.stop()
+
 	if g.isController {
 		g.reconnectLoopWithRetry(ctx)
 	} else {
@@ -68,7 +83,7 @@ func (g *Guard) SetICEConnDisconnected() {
 	}
 }
 
-// reconnectLoopWithRetry periodically check (max 30 min) the connection status.
+// reconnectLoopWithRetry periodically check (max 30 min by default) the connection status.
 // Try to send offer while the P2P is not established or while the Relay is not connected if is it supported
 func (g *Guard) reconnectLoopWithRetry(ctx context.Context) {
 	waitForInitialConnectionTry(ctx)
@@ -80,6 +95,8 @@ func (g *Guard) reconnectLoopWithRetry(ctx context.Context) {
 	defer ticker.Stop()
 
 	tickerChannel := ticker.C
+	// delayedReset resets the ticker once the reconnection delay after a network change elapsed
+	var delayedReset <-chan time.Time
 
 	g.log.Infof("start reconnect loop...")
 	for {
@@ -109,11 +126,24 @@ func (g *Guard) reconnectLoopWithRetry(ctx context.Context) {
 			tickerChannel = ticker.C
 
 		case <-srReconnectedChan:
+			if delay := g.networkChangeDelay(); delay > 0 {
+				g.log.Debugf("has network changes, reset reconnection ticker in %s", delay)
+				ticker.Stop()
+				tickerChannel = nil
+				delayedReset = time.After(delay)
+				continue
+			}
 			g.log.Debugf("has network changes, reset reconnection ticker")
 			ticker.Stop()
 			ticker = g.prepareExponentTicker(ctx)
 			tickerChannel = ticker.C
 
+		case <-delayedReset:
+			delayedReset = nil
+			ticker.Stop()
+			ticker = g.prepareExponentTicker(ctx)
+			tickerChannel = ticker.C
+
 		case <-ctx.Done():
 			g.log.Debugf("context is done, stop reconnect loop")
 			return
@@ -129,6 +159,9 @@ func (g *Guard) listenForDisconnectEvents(ctx context.Context) {
 	srReconnectedChan := g.srWatcher.NewListener()
 	defer g.srWatcher.RemoveListener(srReconnectedChan)
 
+	// delayedOffer sends the offer once the reconnection delay after a network change elapsed
+	var delayedOffer <-chan time.Time
+
 	g.log.Infof("start listen for reconnect events...")
 	for {
 		select {
@@ -139,6 +172,14 @@ func (g *Guard) listenForDisconnectEvents(ctx context.Context) {
 			g.log.Debugf("ICE state changed, try to send new offer")
 			g.triggerOfferSending()
 		case <-srReconnectedChan:
+			if delay := g.networkChangeDelay(); delay > 0 {
+				g.log.Debugf("has network changes, send offer in %s", delay)
+				delayedOffer = time.After(delay)
+				continue
+			}
+			g.triggerOfferSending()
+		case <-delayedOffer:
+			delayedOffer = nil
 			g.triggerOfferSending()
 		case <-ctx.Done():
 			g.log.Debugf("context is done, stop reconnect loop")
@@ -153,7 +194,7 @@ func (g *Guard) prepareExponentTicker(ctx context.Context) *backoff.Ticker {
 		RandomizationFactor: 0.1,
 		Multiplier:          2,
 		MaxInterval:         g.timeout,
-		MaxElapsedTime:      reconnectMaxElapsedTime,
+		MaxElapsedTime:      g.maxElapsedTime,
 		Stop:                backoff.Stop,
 		Clock:               backoff.SystemClock,
 	}, ctx)
@@ -164,13 +205,27 @@ func (g *Guard) prepareExponentTicker(ctx context.Context) *backoff.Ticker {
 	return ticker
 }
 
+// triggerOfferSending asks for an offer, the offers exceeding the budget of the peer are delayed
 func (g *Guard) triggerOfferSending() {
+	if delay := g.offers.trigger(g.sendReconnect); delay > 0 {
+		g.log.Debugf("too many offers, delaying the next one by %s", delay.Round(time.Millisecond))
+	}
+}
+
+func (g *Guard) sendReconnect() {
 	select {
 	case g.Reconnect <- struct{}{}:
 	default:
 	}
 }
 
+func (g *Guard) networkChangeDelay() time.Duration {
+	if g.reconnectDelay == nil {
+		return 0
+	}
+	return g.reconnectDelay()
+}
+
 // Give chance to the peer to establish the initial connection.
 // With it, we can decrease to send necessary offer
 func waitForInitialConnectionTry(ctx context.Context) {
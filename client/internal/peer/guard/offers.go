@@ -0,0 +1,74 @@
+package guard
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// offerBurst is the number of the offers sent right away within the offerWindow
+	offerBurst = 5
+	// offerWindow is the period the offers of a peer are counted in, a flapping connection resetting the backoff
+	// sends an offer per offerWindow/offerBurst at most
+	offerWindow = time.Minute
+)
+
+// offerThrottle bounds the offers sent to a peer. The triggers exceeding the budget are delayed, the triggers arriving
+// while an offer is delayed are merged into it.
+type offerThrottle struct {
+	mu      sync.Mutex
+	sent    []time.Time
+	delayed *time.Timer
+}
+
+// trigger calls send right away or once the budget allows it and returns the delay
+func (t *offerThrottle) trigger(send func()) time.Duration {
+	t.mu.Lock()
+	if t.delayed != nil {
+		t.mu.Unlock()
+		return 0
+	}
+
+	delay := t.reserve(time.Now())
+	if delay > 0 {
+		t.delayed = time.AfterFunc(delay, func() {
+			t.mu.Lock()
+			t.delayed = nil
+			t.mu.Unlock()
+			send()
+		})
+		t.mu.Unlock()
+		return delay
+	}
+	t.mu.Unlock()
+
+	send()
+	return 0
+}
+
+// stop cancels the delayed offer
+func (t *offerThrottle) stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.delayed != nil {
+		t.delayed.Stop()
+		t.delayed = nil
+	}
+}
+
+// reserve counts an offer at the earliest time the budget allows it and returns the delay until then
+func (t *offerThrottle) reserve(now time.Time) time.Duration {
+	var expired int
+	for expired < len(t.sent) && now.Sub(t.sent[expired]) >= offerWindow {
+		expired++
+	}
+	t.sent = t.sent[expired:]
+
+	at := now
+	if len(t.sent) >= offerBurst {
+		at = t.sent[len(t.sent)-offerBurst].Add(offerWindow)
+	}
+	t.sent = append(t.sent, at)
+	return at.Sub(now)
+}
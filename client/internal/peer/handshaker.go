@@ -4,9 +4,12 @@ import (
 	"context"
 	"errors"
 	"sync"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/netbirdio/netbird/client/internal/peer/setup"
+
 	"github.com/netbirdio/netbird/version"
 )
 
@@ -39,6 +42,9 @@ type OfferAnswer struct {
 
 	// relay server address
 	RelaySrvAddress string
+
+	// confirmedAt is the time the offer exchange completed, the relay and ICE workers race from then on
+	confirmedAt time.Time
 }
 
 type Handshaker struct {
@@ -49,15 +55,19 @@ type Handshaker struct {
 	signaler            *Signaler
 	ice                 *WorkerICE
 	relay               *WorkerRelay
+	statusRecorder      *Status
 	onNewOfferListeners []func(*OfferAnswer)
 
+	// offerSentAt is the time the first offer without confirmation was sent, the signal exchange is measured from it
+	offerSentAt time.Time
+
 	// remoteOffersCh is a channel used to wait for remote credentials to proceed with the connection
 	remoteOffersCh chan OfferAnswer
 	// remoteAnswerCh is a channel used to wait for remote credentials answer (confirmation of our offer) to proceed with the connection
 	remoteAnswerCh chan OfferAnswer
 }
 
-func NewHandshaker(ctx context.Context, log *log.Entry, config ConnConfig, signaler *Signaler, ice *WorkerICE, relay *WorkerRelay) *Handshaker {
+func NewHandshaker(ctx context.Context, log *log.Entry, config ConnConfig, signaler *Signaler, ice *WorkerICE, relay *WorkerRelay, statusRecorder *Status) *Handshaker {
 	return &Handshaker{
 		ctx:            ctx,
 		log:            log,
@@ -65,6 +75,7 @@ func NewHandshaker(ctx context.Context, log *log.Entry, config ConnConfig, signa
 		signaler:       signaler,
 		ice:            ice,
 		relay:          relay,
+		statusRecorder: statusRecorder,
 		remoteOffersCh: make(chan OfferAnswer),
 		remoteAnswerCh: make(chan OfferAnswer),
 	}
@@ -89,6 +100,8 @@ func (h *Handshaker) Listen() {
 		}
 
 		h.log.Infof("received connection confirmation, running version %s and with remote WireGuard listen port %d", remoteOfferAnswer.Version, remoteOfferAnswer.WgListenPort)
+		remoteOfferAnswer.confirmedAt = time.Now()
+		h.recordSignalExchange(remoteOfferAnswer.confirmedAt)
 		for _, listener := range h.onNewOfferListeners {
 			go listener(remoteOfferAnswer)
 		}
@@ -163,7 +176,29 @@ func (h *Handshaker) sendOffer() error {
 		offer.RelaySrvAddress = addr
 	}
 
-	return h.signaler.SignalOffer(offer, h.config.Key)
+	if err := h.signaler.SignalOffer(offer, h.config.Key); err != nil {
+		h.statusRecorder.RecordSetupPhase(h.config.Key, setup.PhaseSignal, 0, setup.OutcomeFailure)
+		return err
+	}
+
+	if h.offerSentAt.IsZero() {
+		h.offerSentAt = time.Now()
+	}
+	return nil
+}
+
+// recordSignalExchange records the time from the first offer to the confirmation, the exchanges started by the remote
+// peer aren't measured
+func (h *Handshaker) recordSignalExchange(confirmedAt time.Time) {
+	h.mu.Lock()
+	offerSentAt := h.offerSentAt
+	h.offerSentAt = time.Time{}
+	h.mu.Unlock()
+
+	if offerSentAt.IsZero() {
+		return
+	}
+	h.statusRecorder.RecordSetupPhase(h.config.Key, setup.PhaseSignal, confirmedAt.Sub(offerSentAt), setup.OutcomeSuccess)
 }
 
 func (h *Handshaker) sendAnswer() error {
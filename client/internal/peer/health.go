@@ -0,0 +1,90 @@
+package peer
+
+import (
+	"time"
+)
+
+// HealthGrade is the grade of the health score of a connected peer
+type HealthGrade string
+
+const (
+	HealthGood     HealthGrade = "good"
+	HealthDegraded HealthGrade = "degraded"
+	HealthPoor     HealthGrade = "poor"
+)
+
+const (
+	// healthGoodScore and healthDegradedScore are the lowest scores of the good and the degraded grades
+	healthGoodScore     = 80
+	healthDegradedScore = 50
+
+	// handshakeRekeyAge is the age WireGuard renews the handshake of an active tunnel at, with some tolerance. A tunnel
+	// without a handshake for handshakeRejectAge doesn't pass traffic anymore.
+	handshakeRekeyAge   = 135 * time.Second
+	handshakeRejectAge  = 180 * time.Second
+	maxHandshakePenalty = 60
+
+	// rttGood is the round trip time without penalty, each rttStep above it costs a point
+	rttGood        = 50 * time.Millisecond
+	rttStep        = 10 * time.Millisecond
+	maxRTTPenalty  = 30
+	lossPenalty    = 200
+	maxLossPenalty = 50
+)
+
+// Health is the health of the connection to a peer
+type Health struct {
+	// Score ranges from 0 to 100, 100 is a fresh handshake with a low round trip time and no loss
+	Score int
+	Grade HealthGrade
+}
+
+// PeerHealth scores the connection from the age of the last WireGuard handshake, the round trip time and the loss of
+// the probes. The latency of the connection is used if the peer doesn't answer the probes.
+func PeerHealth(handshakeAge, rtt time.Duration, loss float64) Health {
+	score := 100
+
+	switch {
+	case handshakeAge > handshakeRejectAge:
+		score -= maxHandshakePenalty
+	case handshakeAge > handshakeRekeyAge:
+		score -= int((handshakeAge - handshakeRekeyAge) * maxHandshakePenalty / (handshakeRejectAge - handshakeRekeyAge))
+	}
+
+	if rtt > rttGood {
+		score -= min(int((rtt-rttGood)/rttStep), maxRTTPenalty)
+	}
+
+	if loss > 0 {
+		score -= min(int(loss*lossPenalty), maxLossPenalty)
+	}
+
+	score = max(score, 0)
+	return Health{Score: score, Grade: gradeOf(score)}
+}
+
+func gradeOf(score int) HealthGrade {
+	switch {
+	case score >= healthGoodScore:
+		return HealthGood
+	case score >= healthDegradedScore:
+		return HealthDegraded
+	default:
+		return HealthPoor
+	}
+}
+
+// Health returns the health of the connection at the time, the zero Health if the peer isn't connected
+func (s State) Health(now time.Time) Health {
+	if s.ConnStatus != StatusConnected {
+		return Health{}
+	}
+
+	// a peer without a handshake gets the full penalty, the age of the zero time saturates
+	handshakeAge := now.Sub(s.LastWireguardHandshake)
+	rtt, loss := s.ProbeRTT, s.ProbeLoss
+	if rtt == 0 && loss < 1 {
+		rtt = s.Latency
+	}
+	return PeerHealth(handshakeAge, rtt, loss)
+}
@@ -0,0 +1,54 @@
+package peer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeerHealth(t *testing.T) {
+	tests := []struct {
+		name         string
+		handshakeAge time.Duration
+		rtt          time.Duration
+		loss         float64
+		score        int
+		grade        HealthGrade
+	}{
+		{name: "fresh and fast", handshakeAge: 10 * time.Second, rtt: 20 * time.Millisecond, score: 100, grade: HealthGood},
+		{name: "slow", handshakeAge: 10 * time.Second, rtt: 250 * time.Millisecond, score: 80, grade: HealthGood},
+		{name: "very slow", handshakeAge: 10 * time.Second, rtt: 2 * time.Second, score: 70, grade: HealthDegraded},
+		{name: "lossy", handshakeAge: 10 * time.Second, rtt: 20 * time.Millisecond, loss: 1.0 / 3, score: 50, grade: HealthDegraded},
+		{name: "handshake overdue", handshakeAge: 150 * time.Second, rtt: 20 * time.Millisecond, score: 80, grade: HealthGood},
+		{name: "stale handshake", handshakeAge: 10 * time.Minute, rtt: 20 * time.Millisecond, score: 40, grade: HealthPoor},
+		{name: "everything bad", handshakeAge: 10 * time.Minute, rtt: time.Second, loss: 1, score: 0, grade: HealthPoor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			health := PeerHealth(tt.handshakeAge, tt.rtt, tt.loss)
+			assert.Equal(t, tt.score, health.Score)
+			assert.Equal(t, tt.grade, health.Grade)
+		})
+	}
+}
+
+func TestState_Health(t *testing.T) {
+	now := time.Now()
+	state := State{
+		ConnStatus:             StatusConnected,
+		LastWireguardHandshake: now.Add(-time.Minute),
+		Latency:                150 * time.Millisecond,
+	}
+	assert.Equal(t, 90, state.Health(now).Score, "the connection latency should be used without probe replies")
+
+	state.ProbeRTT = 30 * time.Millisecond
+	assert.Equal(t, 100, state.Health(now).Score, "the probe round trip time should take precedence")
+
+	state.ConnStatus = StatusDisconnected
+	assert.Equal(t, Health{}, state.Health(now), "peers not connected should have no health")
+
+	state = State{ConnStatus: StatusConnected}
+	assert.Equal(t, 40, state.Health(now).Score, "peers without a handshake should get the full penalty")
+}
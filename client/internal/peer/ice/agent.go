@@ -18,17 +18,20 @@ const (
 
 	iceKeepAliveDefault           = 4 * time.Second
 	iceDisconnectedTimeoutDefault = 6 * time.Second
+	iceFailedTimeoutDefault       = 6 * time.Second
 	// iceRelayAcceptanceMinWaitDefault is the same as in the Pion ICE package
 	iceRelayAcceptanceMinWaitDefault = 2 * time.Second
 )
 
-var (
-	failedTimeout = 6 * time.Second
-)
-
 func NewAgent(iFaceDiscover stdnet.ExternalIFaceDiscover, config Config, candidateTypes []ice.CandidateType, ufrag string, pwd string) (*ice.Agent, error) {
-	iceKeepAlive := iceKeepAlive()
-	iceDisconnectedTimeout := iceDisconnectedTimeout()
+	return ice.NewAgent(newAgentConfig(iFaceDiscover, config, candidateTypes, ufrag, pwd))
+}
+
+// newAgentConfig returns the configuration of the Pion ICE agent
+func newAgentConfig(iFaceDiscover stdnet.ExternalIFaceDiscover, config Config, candidateTypes []ice.CandidateType, ufrag string, pwd string) *ice.AgentConfig {
+	iceKeepAlive := iceKeepAlive(durationOrDefault(config.KeepAlive, iceKeepAliveDefault))
+	iceDisconnectedTimeout := iceDisconnectedTimeout(durationOrDefault(config.DisconnectedTimeout, iceDisconnectedTimeoutDefault))
+	failedTimeout := durationOrDefault(config.FailedTimeout, iceFailedTimeoutDefault)
 	iceRelayAcceptanceMinWait := iceRelayAcceptanceMinWait()
 
 	transportNet, err := newStdNet(iFaceDiscover, config.InterfaceBlackList)
@@ -58,11 +61,25 @@ func NewAgent(iFaceDiscover stdnet.ExternalIFaceDiscover, config Config, candida
 		LoggerFactory:          fac,
 	}
 
+	if config.FastNomination {
+		var noWait time.Duration
+		agentConfig.SrflxAcceptanceMinWait = &noWait
+		agentConfig.PrflxAcceptanceMinWait = &noWait
+	}
+
 	if config.DisableIPv6Discovery {
 		agentConfig.NetworkTypes = []ice.NetworkType{ice.NetworkTypeUDP4}
 	}
 
-	return ice.NewAgent(agentConfig)
+	return agentConfig
+}
+
+// durationOrDefault returns the duration, or the default if it is not set
+func durationOrDefault(d, defaultDuration time.Duration) time.Duration {
+	if d <= 0 {
+		return defaultDuration
+	}
+	return d
 }
 
 func GenerateICECredentials() (string, string, error) {
@@ -0,0 +1,28 @@
+package ice
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/ice/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAgentConfig_FastNomination(t *testing.T) {
+	candidateTypes := []ice.CandidateType{ice.CandidateTypeHost, ice.CandidateTypeServerReflexive, ice.CandidateTypeRelay}
+
+	config := newAgentConfig(nil, Config{StunTurn: &StunTurn{}}, candidateTypes, "ufrag", "pwd")
+	assert.Nil(t, config.SrflxAcceptanceMinWait, "the server reflexive pairs should wait for better candidates by default")
+	assert.Nil(t, config.PrflxAcceptanceMinWait, "the peer reflexive pairs should wait for better candidates by default")
+
+	config = newAgentConfig(nil, Config{StunTurn: &StunTurn{}, FastNomination: true}, candidateTypes, "ufrag", "pwd")
+	require.NotNil(t, config.SrflxAcceptanceMinWait)
+	require.NotNil(t, config.PrflxAcceptanceMinWait)
+	assert.Zero(t, *config.SrflxAcceptanceMinWait, "the first working server reflexive pair should be nominated right away")
+	assert.Zero(t, *config.PrflxAcceptanceMinWait, "the first working peer reflexive pair should be nominated right away")
+	assert.Nil(t, config.HostAcceptanceMinWait, "the host pairs are nominated right away by default")
+
+	require.NotNil(t, config.RelayAcceptanceMinWait)
+	assert.Greater(t, *config.RelayAcceptanceMinWait, time.Duration(0), "the direct pairs should win the nomination over the TURN relay pairs")
+}
@@ -1,6 +1,8 @@
 package ice
 
 import (
+	"time"
+
 	"github.com/pion/ice/v3"
 )
 
@@ -17,4 +19,15 @@ type Config struct {
 	UDPMuxSrflx ice.UniversalUDPMux
 
 	NATExternalIPs []string
+
+	// KeepAlive, DisconnectedTimeout and FailedTimeout override the default timers of the ICE agent when not 0.
+	// The environment variables take precedence.
+	KeepAlive           time.Duration
+	DisconnectedTimeout time.Duration
+	FailedTimeout       time.Duration
+
+	// FastNomination nominates the first working server reflexive or peer reflexive pair instead of waiting for
+	// better candidates, it's used when no relay connection carries the traffic until the nomination. The TURN relay
+	// pairs keep their acceptance wait.
+	FastNomination bool
 }
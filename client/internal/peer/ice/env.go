@@ -15,7 +15,7 @@ const (
 	envICEDisconnectedTimeoutSec    = "NB_ICE_DISCONNECTED_TIMEOUT_SEC"
 	envICERelayAcceptanceMinWaitSec = "NB_ICE_RELAY_ACCEPTANCE_MIN_WAIT_SEC"
 
-	msgWarnInvalidValue = "invalid value %s set for %s, using default %v"
+	msgWarnInvalidValue = "invalid value %s set for %s, using %v"
 )
 
 func hasICEForceRelayConn() bool {
@@ -23,33 +23,35 @@ func hasICEForceRelayConn() bool {
 	return strings.ToLower(disconnectedTimeoutEnv) == "true"
 }
 
-func iceKeepAlive() time.Duration {
+// iceKeepAlive returns the keep alive interval set by the environment, or the configured one
+func iceKeepAlive(configured time.Duration) time.Duration {
 	keepAliveEnv := os.Getenv(envICEKeepAliveIntervalSec)
 	if keepAliveEnv == "" {
-		return iceKeepAliveDefault
+		return configured
 	}
 
 	log.Infof("setting ICE keep alive interval to %s seconds", keepAliveEnv)
 	keepAliveEnvSec, err := strconv.Atoi(keepAliveEnv)
 	if err != nil {
-		log.Warnf(msgWarnInvalidValue, keepAliveEnv, envICEKeepAliveIntervalSec, iceKeepAliveDefault)
-		return iceKeepAliveDefault
+		log.Warnf(msgWarnInvalidValue, keepAliveEnv, envICEKeepAliveIntervalSec, configured)
+		return configured
 	}
 
 	return time.Duration(keepAliveEnvSec) * time.Second
 }
 
-func iceDisconnectedTimeout() time.Duration {
+// iceDisconnectedTimeout returns the disconnected timeout set by the environment, or the configured one
+func iceDisconnectedTimeout(configured time.Duration) time.Duration {
 	disconnectedTimeoutEnv := os.Getenv(envICEDisconnectedTimeoutSec)
 	if disconnectedTimeoutEnv == "" {
-		return iceDisconnectedTimeoutDefault
+		return configured
 	}
 
 	log.Infof("setting ICE disconnected timeout to %s seconds", disconnectedTimeoutEnv)
 	disconnectedTimeoutSec, err := strconv.Atoi(disconnectedTimeoutEnv)
 	if err != nil {
-		log.Warnf(msgWarnInvalidValue, disconnectedTimeoutEnv, envICEDisconnectedTimeoutSec, iceDisconnectedTimeoutDefault)
-		return iceDisconnectedTimeoutDefault
+		log.Warnf(msgWarnInvalidValue, disconnectedTimeoutEnv, envICEDisconnectedTimeoutSec, configured)
+		return configured
 	}
 
 	return time.Duration(disconnectedTimeoutSec) * time.Second
@@ -0,0 +1,191 @@
+// Package limiter bounds the work of the peer connections in large networks. It caps the concurrent ICE negotiations
+// and spreads the reconnections after a network change or a wake from sleep. The prioritized peers, the routing peers
+// and the exit nodes, are admitted first and reconnect without delay.
+package limiter
+
+import (
+	"context"
+	"math/rand"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultLimit is the number of the concurrent ICE negotiations when no limit is configured
+	DefaultLimit = 64
+
+	// spreadPerPeer is the reconnection window added per peer of the network, the reconnections of 5000 peers are
+	// spread over 25 seconds
+	spreadPerPeer = 5 * time.Millisecond
+	// maxSpread limits the reconnection window
+	maxSpread = 30 * time.Second
+)
+
+type waiter struct {
+	peerKey string
+	ready   chan struct{}
+}
+
+// Limiter admits the ICE negotiations up to the limit, the prioritized peers first. A nil Limiter doesn't limit.
+type Limiter struct {
+	mu     sync.Mutex
+	limit  int
+	active int
+	// high and normal are the negotiations waiting for a slot in their order of arrival
+	high        []*waiter
+	normal      []*waiter
+	prioritized map[string]struct{}
+	peers       int
+}
+
+// New creates a Limiter admitting limit negotiations at once, DefaultLimit applies when limit isn't positive
+func New(limit int) *Limiter {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	return &Limiter{
+		limit:       limit,
+		prioritized: make(map[string]struct{}),
+	}
+}
+
+// Update sets the number of the peers of the network and the peers reconnected first
+func (l *Limiter) Update(peers int, prioritized []string) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.peers = peers
+	l.prioritized = make(map[string]struct{}, len(prioritized))
+	for _, peerKey := range prioritized {
+		l.prioritized[peerKey] = struct{}{}
+	}
+
+	// the waiting negotiations of the peers prioritized meanwhile move ahead
+	var normal []*waiter
+	for _, w := range l.normal {
+		if _, ok := l.prioritized[w.peerKey]; ok {
+			l.high = append(l.high, w)
+			continue
+		}
+		normal = append(normal, w)
+	}
+	l.normal = normal
+}
+
+// IsPrioritized returns true if the peer is reconnected first
+func (l *Limiter) IsPrioritized(peerKey string) bool {
+	if l == nil {
+		return false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, ok := l.prioritized[peerKey]
+	return ok
+}
+
+// ReconnectDelay returns the random delay of the reconnection of the peer after a network change, it grows with the
+// number of the peers of the network. The prioritized peers reconnect right away.
+func (l *Limiter) ReconnectDelay(peerKey string) time.Duration {
+	if l == nil {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.prioritized[peerKey]; ok {
+		return 0
+	}
+	spread := min(time.Duration(l.peers)*spreadPerPeer, maxSpread)
+	if spread <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(spread)))
+}
+
+// Acquire waits for a negotiation slot for the peer and returns the function releasing it, it fails when the context
+// is done first
+func (l *Limiter) Acquire(ctx context.Context, peerKey string) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	l.mu.Lock()
+	if l.active < l.limit {
+		l.active++
+		l.mu.Unlock()
+		return l.releaseFunc(), nil
+	}
+
+	w := &waiter{peerKey: peerKey, ready: make(chan struct{})}
+	if _, ok := l.prioritized[peerKey]; ok {
+		l.high = append(l.high, w)
+	} else {
+		l.normal = append(l.normal, w)
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return l.releaseFunc(), nil
+	case <-ctx.Done():
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		// the slot was handed over meanwhile
+		l.releaseLocked()
+	default:
+		l.high = slices.DeleteFunc(l.high, func(e *waiter) bool { return e == w })
+		l.normal = slices.DeleteFunc(l.normal, func(e *waiter) bool { return e == w })
+	}
+	return nil, ctx.Err()
+}
+
+// Waiting returns the number of the negotiations waiting for a slot
+func (l *Limiter) Waiting() int {
+	if l == nil {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.high) + len(l.normal)
+}
+
+func (l *Limiter) releaseFunc() func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			l.releaseLocked()
+		})
+	}
+}
+
+// releaseLocked hands the slot over to the next waiting negotiation
+func (l *Limiter) releaseLocked() {
+	var next *waiter
+	switch {
+	case len(l.high) > 0:
+		next, l.high = l.high[0], l.high[1:]
+	case len(l.normal) > 0:
+		next, l.normal = l.normal[0], l.normal[1:]
+	default:
+		l.active--
+		return
+	}
+	close(next.ready)
+}
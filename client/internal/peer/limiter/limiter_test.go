@@ -0,0 +1,84 @@
+package limiter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter_Acquire(t *testing.T) {
+	l := New(1)
+	l.Update(3, []string{"router"})
+
+	release, err := l.Acquire(context.Background(), "first")
+	require.NoError(t, err)
+
+	admitted := make(chan string, 2)
+	for i, peerKey := range []string{"ordinary", "router"} {
+		go func() {
+			release, err := l.Acquire(context.Background(), peerKey)
+			if err != nil {
+				return
+			}
+			admitted <- peerKey
+			release()
+		}()
+		require.Eventually(t, func() bool { return l.Waiting() == i+1 }, time.Second, time.Millisecond)
+	}
+
+	release()
+	release()
+
+	assert.Equal(t, "router", <-admitted, "the prioritized peer should be admitted first")
+	assert.Equal(t, "ordinary", <-admitted)
+	assert.Eventually(t, func() bool {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		return l.active == 0
+	}, time.Second, time.Millisecond)
+
+	_, err = l.Acquire(context.Background(), "last")
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = l.Acquire(ctx, "over")
+	assert.Error(t, err, "a repeated release shouldn't free another slot")
+}
+
+func TestLimiter_AcquireCancelled(t *testing.T) {
+	l := New(1)
+
+	release, err := l.Acquire(context.Background(), "first")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = l.Acquire(ctx, "second")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 0, l.Waiting())
+
+	release()
+	_, err = l.Acquire(context.Background(), "third")
+	assert.NoError(t, err, "the slot should be free after the release")
+}
+
+func TestLimiter_ReconnectDelay(t *testing.T) {
+	l := New(0)
+	assert.Equal(t, DefaultLimit, l.limit)
+	assert.Zero(t, l.ReconnectDelay("ordinary"), "a limiter without peers shouldn't delay")
+
+	l.Update(100_000, []string{"router"})
+	assert.Zero(t, l.ReconnectDelay("router"))
+	for i := 0; i < 100; i++ {
+		assert.Less(t, l.ReconnectDelay("ordinary"), maxSpread)
+	}
+
+	var nilLimiter *Limiter
+	release, err := nilLimiter.Acquire(context.Background(), "peer")
+	require.NoError(t, err)
+	release()
+	assert.Zero(t, nilLimiter.ReconnectDelay("peer"))
+}
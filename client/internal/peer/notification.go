@@ -0,0 +1,109 @@
+package peer
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/netbirdio/netbird/client/proto"
+	"github.com/netbirdio/netbird/util/errcode"
+)
+
+// SetDisabledNotifications sets the notification categories disabled by the management service. The events of a
+// disabled category are published without a user message, the UIs don't show them.
+func (d *Status) SetDisabledNotifications(categories []string) {
+	d.eventMux.Lock()
+	defer d.eventMux.Unlock()
+
+	d.disabledNotifications = slices.Clone(categories)
+	for key, event := range d.notifications {
+		if slices.Contains(d.disabledNotifications, event.GetNotification()) {
+			delete(d.notifications, key)
+		}
+	}
+}
+
+// PublishNotification publishes an event the user must act on. It replaces the previous notification of the category
+// about the subject, e.g. the ID of a route, until it's cleared.
+func (d *Status) PublishNotification(
+	notification string,
+	subject string,
+	severity proto.SystemEvent_Severity,
+	category proto.SystemEvent_Category,
+	msg string,
+	userMsg string,
+	metadata map[string]string,
+) {
+	d.publishNotification(notification, subject, newEvent(severity, category, msg, userMsg, metadata))
+}
+
+// PublishErrorNotification publishes a notification reporting the error with the code
+func (d *Status) PublishErrorNotification(
+	notification string,
+	subject string,
+	severity proto.SystemEvent_Severity,
+	category proto.SystemEvent_Category,
+	code errcode.Code,
+	msg string,
+	userMsg string,
+	metadata map[string]string,
+) {
+	event := newEvent(severity, category, msg, userMsg, metadata)
+	event.ErrorCode = string(code)
+	d.publishNotification(notification, subject, event)
+}
+
+func (d *Status) publishNotification(notification, subject string, event *proto.SystemEvent) {
+	event.Notification = notification
+
+	d.eventMux.Lock()
+	defer d.eventMux.Unlock()
+
+	if slices.Contains(d.disabledNotifications, notification) {
+		event.UserMessage = ""
+		event.Notification = ""
+	} else {
+		d.notifications[notificationKey(notification, subject)] = event
+	}
+
+	d.publish(event)
+}
+
+// ClearNotification removes the notification of the category about the subject once the user acted on it or it's
+// resolved otherwise
+func (d *Status) ClearNotification(notification string, subject string) {
+	d.eventMux.Lock()
+	defer d.eventMux.Unlock()
+
+	delete(d.notifications, notificationKey(notification, subject))
+}
+
+// ClearNotifications removes all the notifications of the category
+func (d *Status) ClearNotifications(notification string) {
+	d.eventMux.Lock()
+	defer d.eventMux.Unlock()
+
+	for key, event := range d.notifications {
+		if event.GetNotification() == notification {
+			delete(d.notifications, key)
+		}
+	}
+}
+
+// GetNotifications returns the notifications the user must act on, oldest first
+func (d *Status) GetNotifications() []*proto.SystemEvent {
+	d.eventMux.RLock()
+	defer d.eventMux.RUnlock()
+
+	notifications := make([]*proto.SystemEvent, 0, len(d.notifications))
+	for _, event := range d.notifications {
+		notifications = append(notifications, event)
+	}
+	sort.Slice(notifications, func(i, j int) bool {
+		return notifications[i].GetTimestamp().AsTime().Before(notifications[j].GetTimestamp().AsTime())
+	})
+	return notifications
+}
+
+func notificationKey(notification, subject string) string {
+	return notification + "/" + subject
+}
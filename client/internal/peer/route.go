@@ -79,3 +79,19 @@ func (r *routeIDLookup) Lookup(ip netip.Addr) (string, bool) {
 
 	return resourceID, isExitNode
 }
+
+// IsRouted returns true if the IP address is reachable through a client route, the exit node routes are excluded as
+// they cover every address
+func (r *routeIDLookup) IsRouted(ip netip.Addr) bool {
+	if _, ok := r.resolvedIPs.Load(ip); ok {
+		return true
+	}
+
+	var routed bool
+	r.remoteMap.Range(func(key, _ interface{}) bool {
+		pref := key.(netip.Prefix)
+		routed = pref.Bits() > 0 && pref.Contains(ip)
+		return !routed
+	})
+	return routed
+}
@@ -0,0 +1,214 @@
+// Package setup measures the setup of the peer connections: the signal exchange, the gathering of the local ICE
+// candidates, the ICE nomination and the first WireGuard handshake. The durations and the outcomes of the phases are
+// exported as metrics and aggregated for the connectivity reports, so the regressions of the NAT traversal show up
+// across the releases.
+package setup
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Phase is a phase of the connection setup
+type Phase string
+
+const (
+	// PhaseSignal is the exchange of the offer and the answer through the signal service
+	PhaseSignal Phase = "signal"
+	// PhaseGathering is the gathering of the local ICE candidates
+	PhaseGathering Phase = "gathering"
+	// PhaseNomination is the ICE connectivity checks until a candidate pair is nominated
+	PhaseNomination Phase = "nomination"
+	// PhaseHandshake is the first WireGuard handshake after the endpoint of the peer was configured
+	PhaseHandshake Phase = "handshake"
+)
+
+// Phases are the phases in the order of the setup
+var Phases = []Phase{PhaseSignal, PhaseGathering, PhaseNomination, PhaseHandshake}
+
+// Outcome is the outcome of a phase
+type Outcome string
+
+const (
+	OutcomeSuccess Outcome = "success"
+	OutcomeFailure Outcome = "failure"
+)
+
+// maxSamples limits the durations kept per phase between two snapshots
+const maxSamples = 512
+
+// Result is the latest result of a phase of a peer
+type Result struct {
+	Duration time.Duration
+	Outcome  Outcome
+	At       time.Time
+}
+
+// Stats aggregate the results of a phase
+type Stats struct {
+	Phase     Phase
+	Successes int
+	Failures  int
+	// Median and P95 are the percentiles of the durations of the successful phases
+	Median time.Duration
+	P95    time.Duration
+}
+
+// SuccessRate returns the share of the successful phases, 0 without results
+func (s Stats) SuccessRate() float64 {
+	if s.Successes+s.Failures == 0 {
+		return 0
+	}
+	return float64(s.Successes) / float64(s.Successes+s.Failures)
+}
+
+type window struct {
+	successes int
+	failures  int
+	durations []time.Duration
+}
+
+func (w *window) stats(phase Phase) Stats {
+	durations := slices.Clone(w.durations)
+	slices.Sort(durations)
+	return Stats{
+		Phase:     phase,
+		Successes: w.successes,
+		Failures:  w.failures,
+		Median:    percentile(durations, 0.5),
+		P95:       percentile(durations, 0.95),
+	}
+}
+
+// Recorder records the phases of the connection setups of the peers
+type Recorder struct {
+	mu sync.Mutex
+	// peers are the latest results of the phases per peer
+	peers map[string]map[Phase]Result
+	// windows are the results since the previous snapshot
+	windows map[Phase]*window
+
+	durations metric.Float64Histogram
+	outcomes  metric.Int64Counter
+}
+
+// NewRecorder creates a Recorder exporting the metrics with the global meter provider, the metrics are dropped until
+// the daemon serves them
+func NewRecorder() *Recorder {
+	r := &Recorder{
+		peers:   make(map[string]map[Phase]Result),
+		windows: make(map[Phase]*window),
+	}
+
+	meter := otel.Meter(reflect.TypeOf(Result{}).PkgPath())
+
+	var err error
+	r.durations, err = meter.Float64Histogram("peer_connection_setup_phase_duration_milliseconds",
+		metric.WithExplicitBucketBoundaries(10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000),
+		metric.WithDescription("Duration of the successful phases of the peer connection setups"),
+	)
+	if err != nil {
+		log.Warnf("failed to create the connection setup duration metric: %v", err)
+	}
+
+	r.outcomes, err = meter.Int64Counter("peer_connection_setup_phases_total",
+		metric.WithDescription("Number of the peer connection setup phases by outcome"),
+	)
+	if err != nil {
+		log.Warnf("failed to create the connection setup outcome metric: %v", err)
+	}
+
+	return r
+}
+
+// Record records the result of a phase of the connection setup of the peer, the duration of the failed phases isn't
+// aggregated
+func (r *Recorder) Record(peerKey string, phase Phase, duration time.Duration, outcome Outcome) {
+	attrs := metric.WithAttributes(attribute.String("phase", string(phase)), attribute.String("outcome", string(outcome)))
+	if r.outcomes != nil {
+		r.outcomes.Add(context.Background(), 1, attrs)
+	}
+	if r.durations != nil && outcome == OutcomeSuccess {
+		r.durations.Record(context.Background(), float64(duration)/float64(time.Millisecond), attrs)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	results, ok := r.peers[peerKey]
+	if !ok {
+		results = make(map[Phase]Result)
+		r.peers[peerKey] = results
+	}
+	results[phase] = Result{Duration: duration, Outcome: outcome, At: time.Now()}
+
+	w, ok := r.windows[phase]
+	if !ok {
+		w = &window{}
+		r.windows[phase] = w
+	}
+	if outcome != OutcomeSuccess {
+		w.failures++
+		return
+	}
+	w.successes++
+	if len(w.durations) < maxSamples {
+		w.durations = append(w.durations, duration)
+	}
+}
+
+// Peer returns the latest results of the phases of the peer
+func (r *Recorder) Peer(peerKey string) map[Phase]Result {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	results := make(map[Phase]Result, len(r.peers[peerKey]))
+	for phase, result := range r.peers[peerKey] {
+		results[phase] = result
+	}
+	return results
+}
+
+// RemovePeer removes the results of the peer
+func (r *Recorder) RemovePeer(peerKey string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.peers, peerKey)
+}
+
+// Snapshot returns the stats of the phases recorded since the previous snapshot in the order of the setup, the phases
+// without results are left out
+func (r *Recorder) Snapshot() []Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var stats []Stats
+	for _, phase := range Phases {
+		w, ok := r.windows[phase]
+		if !ok {
+			continue
+		}
+		stats = append(stats, w.stats(phase))
+	}
+	r.windows = make(map[Phase]*window)
+	return stats
+}
+
+// percentile returns the nearest-rank percentile of the sorted durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(p*float64(len(sorted))+0.5) - 1
+	rank = max(0, min(rank, len(sorted)-1))
+	return sorted[rank]
+}
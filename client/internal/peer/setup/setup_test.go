@@ -0,0 +1,41 @@
+package setup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder_Snapshot(t *testing.T) {
+	r := NewRecorder()
+
+	for i := 1; i <= 10; i++ {
+		r.Record("peer-a", PhaseNomination, time.Duration(i)*100*time.Millisecond, OutcomeSuccess)
+	}
+	r.Record("peer-b", PhaseNomination, 5*time.Second, OutcomeFailure)
+	r.Record("peer-a", PhaseSignal, 40*time.Millisecond, OutcomeSuccess)
+
+	stats := r.Snapshot()
+	require.Len(t, stats, 2)
+
+	assert.Equal(t, PhaseSignal, stats[0].Phase, "the phases should be in the order of the setup")
+	assert.Equal(t, 40*time.Millisecond, stats[0].Median)
+
+	nomination := stats[1]
+	assert.Equal(t, 10, nomination.Successes)
+	assert.Equal(t, 1, nomination.Failures)
+	assert.InDelta(t, 10.0/11, nomination.SuccessRate(), 0.001)
+	assert.Equal(t, 500*time.Millisecond, nomination.Median, "the failed phases shouldn't count in the durations")
+	assert.Equal(t, time.Second, nomination.P95)
+
+	assert.Empty(t, r.Snapshot(), "the snapshot should reset the stats")
+
+	results := r.Peer("peer-a")
+	assert.Equal(t, time.Second, results[PhaseNomination].Duration, "the peer should keep the latest result")
+	assert.Equal(t, OutcomeFailure, r.Peer("peer-b")[PhaseNomination].Outcome)
+
+	r.RemovePeer("peer-a")
+	assert.Empty(t, r.Peer("peer-a"))
+}
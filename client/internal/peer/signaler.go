@@ -2,10 +2,13 @@ package peer
 
 import (
 	"github.com/pion/ice/v3"
+	log "github.com/sirupsen/logrus"
 	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
 
 	signal "github.com/netbirdio/netbird/signal/client"
 	sProto "github.com/netbirdio/netbird/signal/proto"
+	"github.com/netbirdio/netbird/util/faultinject"
+	"github.com/netbirdio/netbird/version"
 )
 
 type Signaler struct {
@@ -29,7 +32,7 @@ func (s *Signaler) SignalAnswer(offer OfferAnswer, remoteKey string) error {
 }
 
 func (s *Signaler) SignalICECandidate(candidate ice.Candidate, remoteKey string) error {
-	return s.signal.Send(&sProto.Message{
+	return s.send(&sProto.Message{
 		Key:       s.wgPrivateKey.PublicKey().String(),
 		RemoteKey: remoteKey,
 		Body: &sProto.Body{
@@ -39,6 +42,27 @@ func (s *Signaler) SignalICECandidate(candidate ice.Candidate, remoteKey string)
 	})
 }
 
+// SignalGoAway tells the remote peer that this peer is shutting down
+func (s *Signaler) SignalGoAway(remoteKey string) error {
+	return s.send(&sProto.Message{
+		Key:       s.wgPrivateKey.PublicKey().String(),
+		RemoteKey: remoteKey,
+		Body: &sProto.Body{
+			Type:           sProto.Body_GO_AWAY,
+			NetBirdVersion: version.NetbirdVersion(),
+		},
+	})
+}
+
+// send sends the message to the remote peer, the message is dropped while the signal-drop fault is injected
+func (s *Signaler) send(msg *sProto.Message) error {
+	if faultinject.Hit(faultinject.SignalDrop) {
+		log.Debugf("dropped the signal message to %s", msg.RemoteKey)
+		return nil
+	}
+	return s.signal.Send(msg)
+}
+
 func (s *Signaler) Ready() bool {
 	return s.signal.Ready()
 }
@@ -61,7 +85,7 @@ func (s *Signaler) signalOfferAnswer(offerAnswer OfferAnswer, remoteKey string,
 		return err
 	}
 
-	err = s.signal.Send(msg)
+	err = s.send(msg)
 	if err != nil {
 		return err
 	}
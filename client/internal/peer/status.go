@@ -17,10 +17,12 @@ import (
 	firewall "github.com/netbirdio/netbird/client/firewall/manager"
 	"github.com/netbirdio/netbird/client/iface/configurer"
 	"github.com/netbirdio/netbird/client/internal/ingressgw"
+	"github.com/netbirdio/netbird/client/internal/peer/setup"
 	"github.com/netbirdio/netbird/client/internal/relay"
 	"github.com/netbirdio/netbird/client/proto"
 	"github.com/netbirdio/netbird/management/domain"
 	relayClient "github.com/netbirdio/netbird/relay/client"
+	"github.com/netbirdio/netbird/util/errcode"
 )
 
 const eventQueueSize = 10
@@ -52,7 +54,10 @@ type State struct {
 	BytesTx                    int64
 	BytesRx                    int64
 	Latency                    time.Duration
+	ProbeRTT                   time.Duration
+	ProbeLoss                  float64
 	RosenpassEnabled           bool
+	Draining                   bool
 	routes                     map[string]struct{}
 }
 
@@ -94,14 +99,29 @@ type LocalPeerState struct {
 	KernelInterface bool
 	FQDN            string
 	Routes          map[string]struct{}
+	// RoutesReachability holds the latest reachability checks of the routed networks by route
+	RoutesReachability map[string]RouteReachability
 }
 
 // Clone returns a copy of the LocalPeerState
 func (l LocalPeerState) Clone() LocalPeerState {
 	l.Routes = maps.Clone(l.Routes)
+	l.RoutesReachability = maps.Clone(l.RoutesReachability)
 	return l
 }
 
+// RouteReachability is the result of checking whether the local peer reaches a network it routes for the other peers
+type RouteReachability struct {
+	RouteID   string
+	Reachable bool
+	// Target is the address probed in the network
+	Target  netip.Addr
+	Latency time.Duration
+	// Error describes why the network isn't reachable
+	Error     string
+	CheckedAt time.Time
+}
+
 // SignalState contains the latest state of a signal connection
 type SignalState struct {
 	URL       string
@@ -122,6 +142,15 @@ type RosenpassState struct {
 	Permissive bool
 }
 
+// ExitNodeState is the exit node chosen by the automatic exit node selection
+type ExitNodeState struct {
+	// Auto is set if the automatic exit node selection is on
+	Auto    bool
+	NetID   string
+	Latency time.Duration
+	Loss    float64
+}
+
 // NSGroupState represents the status of a DNS server group, including associated domains,
 // whether it's enabled, and the last error message encountered during probing.
 type NSGroupState struct {
@@ -142,6 +171,7 @@ type FullStatus struct {
 	Relays               []relay.ProbeResult
 	NSGroupStates        []NSGroupState
 	NumOfForwardingRules int
+	ExitNodeState        ExitNodeState
 }
 
 // Status holds a state of peers, signal, management connections and relays
@@ -163,6 +193,10 @@ type Status struct {
 	rosenpassPermissive   bool
 	nsGroupStates         []NSGroupState
 	resolvedDomainsStates map[domain.Domain]ResolvedDomainInfo
+	exitNodeState         ExitNodeState
+
+	// routeReachabilityChanged is signaled when a routed network turns unreachable or reachable again
+	routeReachabilityChanged chan struct{}
 
 	// To reduce the number of notification invocation this bool will be true when need to call the notification
 	// Some Peer actions mostly used by in a batch when the network map has been synchronized. In these type of events
@@ -175,9 +209,17 @@ type Status struct {
 	eventStreams map[string]chan *proto.SystemEvent
 	eventQueue   *EventQueue
 
+	// notifications are the latest notification of each category and subject, the UIs started later list them
+	notifications map[string]*proto.SystemEvent
+	// disabledNotifications are the notification categories the management service disabled
+	disabledNotifications []string
+
 	ingressGwMgr *ingressgw.Manager
 
 	routeIDLookup routeIDLookup
+
+	// setup records the phases of the connection setups of the peers
+	setup *setup.Recorder
 }
 
 // NewRecorder returns a new Status instance
@@ -187,10 +229,14 @@ func NewRecorder(mgmAddress string) *Status {
 		changeNotify:          make(map[string]chan struct{}),
 		eventStreams:          make(map[string]chan *proto.SystemEvent),
 		eventQueue:            NewEventQueue(eventQueueSize),
+		notifications:         make(map[string]*proto.SystemEvent),
 		offlinePeers:          make([]State, 0),
 		notifier:              newNotifier(),
 		mgmAddress:            mgmAddress,
 		resolvedDomainsStates: map[domain.Domain]ResolvedDomainInfo{},
+		setup:                 setup.NewRecorder(),
+
+		routeReachabilityChanged: make(chan struct{}, 1),
 	}
 }
 
@@ -271,10 +317,26 @@ func (d *Status) RemovePeer(peerPubKey string) error {
 	}
 
 	delete(d.peers, peerPubKey)
+	d.setup.RemovePeer(peerPubKey)
 	d.peerListChangedForNotification = true
 	return nil
 }
 
+// RecordSetupPhase records the result of a phase of the connection setup of the peer
+func (d *Status) RecordSetupPhase(peerPubKey string, phase setup.Phase, duration time.Duration, outcome setup.Outcome) {
+	d.setup.Record(peerPubKey, phase, duration, outcome)
+}
+
+// GetSetupPhases returns the latest results of the connection setup phases of the peer
+func (d *Status) GetSetupPhases(peerPubKey string) map[setup.Phase]setup.Result {
+	return d.setup.Peer(peerPubKey)
+}
+
+// TakeSetupStats returns the stats of the connection setup phases recorded since the previous call
+func (d *Status) TakeSetupStats() []setup.Stats {
+	return d.setup.Snapshot()
+}
+
 // UpdatePeerState updates peer status
 func (d *Status) UpdatePeerState(receivedState State) error {
 	d.mux.Lock()
@@ -367,6 +429,14 @@ func (d *Status) CheckRoutes(ip netip.Addr) ([]byte, bool) {
 	return []byte(resId), isExitNode
 }
 
+// IsRouted returns true if the address is reachable through a client route other than an exit node
+func (d *Status) IsRouted(ip netip.Addr) bool {
+	if d == nil {
+		return false
+	}
+	return d.routeIDLookup.IsRouted(ip.Unmap())
+}
+
 func (d *Status) UpdatePeerICEState(receivedState State) error {
 	d.mux.Lock()
 	defer d.mux.Unlock()
@@ -446,6 +516,11 @@ func (d *Status) UpdatePeerRelayedStateToDisconnected(receivedState State) error
 	peerState.ConnStatusUpdate = receivedState.ConnStatusUpdate
 	peerState.RelayServerAddress = ""
 
+	// a peer reconnecting after a shutdown routes again
+	if peerState.ConnStatus == StatusDisconnected {
+		peerState.Draining = false
+	}
+
 	d.peers[receivedState.PubKey] = peerState
 
 	if skipNotification {
@@ -476,6 +551,11 @@ func (d *Status) UpdatePeerICEStateToDisconnected(receivedState State) error {
 	peerState.LocalIceCandidateEndpoint = receivedState.LocalIceCandidateEndpoint
 	peerState.RemoteIceCandidateEndpoint = receivedState.RemoteIceCandidateEndpoint
 
+	// a peer reconnecting after a shutdown routes again
+	if peerState.ConnStatus == StatusDisconnected {
+		peerState.Draining = false
+	}
+
 	d.peers[receivedState.PubKey] = peerState
 
 	if skipNotification {
@@ -487,6 +567,27 @@ func (d *Status) UpdatePeerICEStateToDisconnected(receivedState State) error {
 	return nil
 }
 
+// MarkPeerDraining marks the peer as shutting down, the route selection moves its routes to other routing peers
+func (d *Status) MarkPeerDraining(pubKey string) error {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	peerState, ok := d.peers[pubKey]
+	if !ok {
+		return errors.New("peer doesn't exist")
+	}
+	if peerState.Draining {
+		return nil
+	}
+
+	peerState.Draining = true
+	d.peers[pubKey] = peerState
+
+	d.notifyPeerStateChangeListeners(pubKey)
+	d.notifyPeerListChanged()
+	return nil
+}
+
 // UpdateWireGuardPeerState updates the WireGuard bits of the peer state
 func (d *Status) UpdateWireGuardPeerState(pubKey string, wgStats configurer.WGStats) error {
 	d.mux.Lock()
@@ -608,6 +709,7 @@ func (d *Status) RemoveLocalPeerStateRoute(route string) {
 	}
 
 	delete(d.localPeer.Routes, route)
+	delete(d.localPeer.RoutesReachability, route)
 }
 
 // CleanLocalPeerStateRoutes cleans all routes from the local peer state
@@ -616,6 +718,39 @@ func (d *Status) CleanLocalPeerStateRoutes() {
 	defer d.mux.Unlock()
 
 	d.localPeer.Routes = map[string]struct{}{}
+	d.localPeer.RoutesReachability = nil
+}
+
+// UpdateLocalPeerStateRouteReachability records the reachability of a network the local peer routes. The routes
+// removed in the meantime are ignored.
+func (d *Status) UpdateLocalPeerStateRouteReachability(route string, reachability RouteReachability) {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	if _, ok := d.localPeer.Routes[route]; !ok {
+		return
+	}
+
+	if d.localPeer.RoutesReachability == nil {
+		d.localPeer.RoutesReachability = map[string]RouteReachability{}
+	}
+
+	previous, checked := d.localPeer.RoutesReachability[route]
+	d.localPeer.RoutesReachability[route] = reachability
+
+	// a network reachable from the start isn't news
+	if checked && previous.Reachable == reachability.Reachable || !checked && reachability.Reachable {
+		return
+	}
+	select {
+	case d.routeReachabilityChanged <- struct{}{}:
+	default:
+	}
+}
+
+// RouteReachabilityChanged returns a channel signaled when a routed network turns unreachable or reachable again
+func (d *Status) RouteReachabilityChanged() <-chan struct{} {
+	return d.routeReachabilityChanged
 }
 
 // CleanLocalPeerState cleans local peer status
@@ -741,6 +876,20 @@ func (d *Status) GetRosenpassState() RosenpassState {
 	}
 }
 
+// UpdateExitNodeState sets the exit node chosen by the automatic exit node selection
+func (d *Status) UpdateExitNodeState(state ExitNodeState) {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	d.exitNodeState = state
+}
+
+// GetExitNodeState returns the exit node chosen by the automatic exit node selection
+func (d *Status) GetExitNodeState() ExitNodeState {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	return d.exitNodeState
+}
+
 func (d *Status) GetManagementState() ManagementState {
 	d.mux.Lock()
 	defer d.mux.Unlock()
@@ -767,6 +916,20 @@ func (d *Status) UpdateLatency(pubKey string, latency time.Duration) error {
 	return nil
 }
 
+// UpdatePeerProbe updates the round trip time and the loss measured by the health probes of the peer
+func (d *Status) UpdatePeerProbe(pubKey string, rtt time.Duration, loss float64) error {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	peerState, ok := d.peers[pubKey]
+	if !ok {
+		return errors.New("peer doesn't exist")
+	}
+	peerState.ProbeRTT = rtt
+	peerState.ProbeLoss = loss
+	d.peers[pubKey] = peerState
+	return nil
+}
+
 // IsLoginRequired determines if a peer's login has expired.
 func (d *Status) IsLoginRequired() bool {
 	d.mux.Lock()
@@ -864,6 +1027,7 @@ func (d *Status) GetFullStatus() FullStatus {
 	defer d.mux.Unlock()
 
 	fullStatus.LocalPeerState = d.localPeer
+	fullStatus.ExitNodeState = d.exitNodeState
 
 	for _, status := range d.peers {
 		fullStatus.Peers = append(fullStatus.Peers, status)
@@ -933,7 +1097,32 @@ func (d *Status) PublishEvent(
 	userMsg string,
 	metadata map[string]string,
 ) {
-	event := &proto.SystemEvent{
+	d.publishEvent(newEvent(severity, category, msg, userMsg, metadata))
+}
+
+// PublishErrorEvent publishes an event reporting the error with the code, so the UIs can react to it without parsing
+// the message
+func (d *Status) PublishErrorEvent(
+	severity proto.SystemEvent_Severity,
+	category proto.SystemEvent_Category,
+	code errcode.Code,
+	msg string,
+	userMsg string,
+	metadata map[string]string,
+) {
+	event := newEvent(severity, category, msg, userMsg, metadata)
+	event.ErrorCode = string(code)
+	d.publishEvent(event)
+}
+
+func newEvent(
+	severity proto.SystemEvent_Severity,
+	category proto.SystemEvent_Category,
+	msg string,
+	userMsg string,
+	metadata map[string]string,
+) *proto.SystemEvent {
+	return &proto.SystemEvent{
 		Id:          uuid.New().String(),
 		Severity:    severity,
 		Category:    category,
@@ -942,10 +1131,17 @@ func (d *Status) PublishEvent(
 		Metadata:    metadata,
 		Timestamp:   timestamppb.Now(),
 	}
+}
 
+func (d *Status) publishEvent(event *proto.SystemEvent) {
 	d.eventMux.Lock()
 	defer d.eventMux.Unlock()
 
+	d.publish(event)
+}
+
+// publish adds the event to the queue and distributes it to the subscribers, the event mutex must be held
+func (d *Status) publish(event *proto.SystemEvent) {
 	d.eventQueue.Add(event)
 
 	for _, stream := range d.eventStreams {
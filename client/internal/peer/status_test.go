@@ -6,6 +6,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/netbirdio/netbird/client/proto"
+	"github.com/netbirdio/netbird/util/errcode"
 )
 
 func TestAddPeer(t *testing.T) {
@@ -152,6 +155,45 @@ func TestCleanLocalPeerState(t *testing.T) {
 	assert.Equal(t, emptyLocalPeerState, status.localPeer, "local peer status should be empty")
 }
 
+func TestUpdateLocalPeerStateRouteReachability(t *testing.T) {
+	status := NewRecorder("https://mgm")
+	changed := func() bool {
+		select {
+		case <-status.RouteReachabilityChanged():
+			return true
+		default:
+			return false
+		}
+	}
+
+	status.UpdateLocalPeerStateRouteReachability("10.0.0.0/24", RouteReachability{Reachable: false})
+	assert.Empty(t, status.GetLocalPeerState().RoutesReachability, "routes not added should be ignored")
+	assert.False(t, changed())
+
+	status.AddLocalPeerStateRoute("10.0.0.0/24", "")
+	status.AddLocalPeerStateRoute("10.0.1.0/24", "")
+
+	status.UpdateLocalPeerStateRouteReachability("10.0.0.0/24", RouteReachability{Reachable: true})
+	assert.False(t, changed(), "a network reachable from the start shouldn't signal")
+
+	status.UpdateLocalPeerStateRouteReachability("10.0.1.0/24", RouteReachability{Error: "no route"})
+	assert.True(t, changed(), "an unreachable network should signal")
+
+	status.UpdateLocalPeerStateRouteReachability("10.0.1.0/24", RouteReachability{Error: "no route"})
+	assert.False(t, changed(), "an unchanged network shouldn't signal")
+
+	status.UpdateLocalPeerStateRouteReachability("10.0.0.0/24", RouteReachability{Error: "no reply"})
+	assert.True(t, changed(), "a network turning unreachable should signal")
+
+	status.RemoveLocalPeerStateRoute("10.0.0.0/24")
+	reachability := status.GetLocalPeerState().RoutesReachability
+	assert.Len(t, reachability, 1)
+	assert.Equal(t, "no route", reachability["10.0.1.0/24"].Error)
+
+	status.CleanLocalPeerStateRoutes()
+	assert.Empty(t, status.GetLocalPeerState().RoutesReachability)
+}
+
 func TestUpdateSignalState(t *testing.T) {
 	url := "https://signal"
 	var tests = []struct {
@@ -241,3 +283,57 @@ func TestGetFullStatus(t *testing.T) {
 	assert.Equal(t, signalState, fullStatus.SignalState, "signal status should be equal")
 	assert.ElementsMatch(t, []State{peerState1, peerState2}, fullStatus.Peers, "peers states should match")
 }
+
+func TestNotifications(t *testing.T) {
+	status := NewRecorder("https://mgm")
+	sub := status.SubscribeToEvents()
+	defer status.UnsubscribeFromEvents(sub)
+
+	status.PublishNotification("exit_node", "route1", proto.SystemEvent_WARNING, proto.SystemEvent_NETWORK, "lost", "Exit node lost.", nil)
+	status.PublishNotification("exit_node", "route1", proto.SystemEvent_WARNING, proto.SystemEvent_NETWORK, "lost", "Exit node lost again.", nil)
+	status.PublishNotification("route_approval", "route2", proto.SystemEvent_WARNING, proto.SystemEvent_NETWORK, "pending", "Approve it.", nil)
+
+	notifications := status.GetNotifications()
+	if assert.Len(t, notifications, 2, "a notification should replace the previous one of the subject") {
+		assert.Equal(t, "Exit node lost again.", notifications[0].GetUserMessage())
+		assert.Equal(t, "route_approval", notifications[1].GetNotification())
+	}
+
+	status.ClearNotification("exit_node", "route1")
+	assert.Len(t, status.GetNotifications(), 1)
+
+	status.SetDisabledNotifications([]string{"route_approval", "exit_node"})
+	assert.Empty(t, status.GetNotifications(), "the notifications of a disabled category should be removed")
+
+	status.PublishNotification("exit_node", "route1", proto.SystemEvent_WARNING, proto.SystemEvent_NETWORK, "lost", "Exit node lost.", nil)
+	assert.Empty(t, status.GetNotifications())
+
+	var last *proto.SystemEvent
+	for len(sub.Events()) > 0 {
+		last = <-sub.Events()
+	}
+	if assert.NotNil(t, last) {
+		assert.Equal(t, "lost", last.GetMessage(), "the event of a disabled notification should still be published")
+		assert.Empty(t, last.GetUserMessage())
+		assert.Empty(t, last.GetNotification())
+	}
+}
+
+func TestErrorEvents(t *testing.T) {
+	status := NewRecorder("https://mgm")
+	sub := status.SubscribeToEvents()
+	defer status.UnsubscribeFromEvents(sub)
+
+	status.PublishErrorEvent(proto.SystemEvent_WARNING, proto.SystemEvent_NETWORK, errcode.RouteConflict, "overlap", "The network overlaps.", nil)
+	event := <-sub.Events()
+	assert.Equal(t, string(errcode.RouteConflict), event.GetErrorCode())
+
+	status.PublishErrorNotification("session_expiry", "", proto.SystemEvent_CRITICAL, proto.SystemEvent_AUTHENTICATION, errcode.LoginExpired, "expired", "Log in again.", nil)
+	event = <-sub.Events()
+	assert.Equal(t, string(errcode.LoginExpired), event.GetErrorCode())
+	assert.Equal(t, "session_expiry", event.GetNotification())
+
+	status.PublishEvent(proto.SystemEvent_INFO, proto.SystemEvent_SYSTEM, "updated", "", nil)
+	event = <-sub.Events()
+	assert.Empty(t, event.GetErrorCode(), "an event reporting no error should have no code")
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/netbirdio/netbird/client/iface"
 	"github.com/netbirdio/netbird/client/iface/bind"
 	icemaker "github.com/netbirdio/netbird/client/internal/peer/ice"
+	"github.com/netbirdio/netbird/client/internal/peer/setup"
 	"github.com/netbirdio/netbird/client/internal/stdnet"
 	"github.com/netbirdio/netbird/route"
 )
@@ -29,6 +30,8 @@ type ICEConnInfo struct {
 	LocalIceCandidateEndpoint  string
 	Relayed                    bool
 	RelayedOnLocal             bool
+	// confirmedAt is the time the offer exchange completed
+	confirmedAt time.Time
 }
 
 type WorkerICE struct {
@@ -79,6 +82,15 @@ func NewWorkerICE(ctx context.Context, log *log.Entry, config ConnConfig, conn *
 
 func (w *WorkerICE) OnNewOffer(remoteOfferAnswer *OfferAnswer) {
 	w.log.Debugf("OnNewOffer for ICE")
+
+	// the slot is held until the negotiation completes, the concurrent negotiations of large networks are capped
+	release, err := w.config.ICELimiter.Acquire(w.ctx, w.config.Key)
+	if err != nil {
+		w.log.Debugf("stop waiting for an ICE negotiation slot: %s", err)
+		return
+	}
+	defer release()
+
 	w.muxAgent.Lock()
 
 	if w.agent != nil {
@@ -87,8 +99,13 @@ func (w *WorkerICE) OnNewOffer(remoteOfferAnswer *OfferAnswer) {
 		return
 	}
 
+	// with a relay connection to the peer the relay carries the first packets while ICE looks for the best direct
+	// pair. Without it the first working direct pair is nominated right away, the TURN relay pairs keep their
+	// acceptance wait so a direct pair wins the nomination when one works
+	relayCovered := w.hasRelayOnLocally && remoteOfferAnswer.RelaySrvAddress != ""
+
 	var preferredCandidateTypes []ice.CandidateType
-	if w.hasRelayOnLocally && remoteOfferAnswer.RelaySrvAddress != "" {
+	if relayCovered {
 		preferredCandidateTypes = icemaker.CandidateTypesP2P()
 	} else {
 		preferredCandidateTypes = icemaker.CandidateTypes()
@@ -96,7 +113,7 @@ func (w *WorkerICE) OnNewOffer(remoteOfferAnswer *OfferAnswer) {
 
 	w.log.Debugf("recreate ICE agent")
 	agentCtx, agentCancel := context.WithCancel(w.ctx)
-	agent, err := w.reCreateAgent(agentCancel, preferredCandidateTypes)
+	agent, err := w.reCreateAgent(agentCancel, preferredCandidateTypes, !relayCovered)
 	if err != nil {
 		w.log.Errorf("failed to recreate ICE Agent: %s", err)
 		w.muxAgent.Unlock()
@@ -109,6 +126,7 @@ func (w *WorkerICE) OnNewOffer(remoteOfferAnswer *OfferAnswer) {
 	err = w.agent.GatherCandidates()
 	if err != nil {
 		w.log.Debugf("failed to gather candidates: %s", err)
+		w.statusRecorder.RecordSetupPhase(w.config.Key, setup.PhaseGathering, 0, setup.OutcomeFailure)
 		return
 	}
 
@@ -116,12 +134,18 @@ func (w *WorkerICE) OnNewOffer(remoteOfferAnswer *OfferAnswer) {
 	// but it won't release if ICE Agent went into Disconnected or Failed state,
 	// so we have to cancel it with the provided context once agent detected a broken connection
 	w.log.Debugf("turn agent dial")
+	dialStarted := time.Now()
 	remoteConn, err := w.turnAgentDial(agentCtx, remoteOfferAnswer)
 	if err != nil {
 		w.log.Debugf("failed to dial the remote peer: %s", err)
+		// the dial is cancelled without failure when the connection is closed
+		if w.ctx.Err() == nil {
+			w.statusRecorder.RecordSetupPhase(w.config.Key, setup.PhaseNomination, time.Since(dialStarted), setup.OutcomeFailure)
+		}
 		return
 	}
 	w.log.Debugf("agent dial succeeded")
+	w.statusRecorder.RecordSetupPhase(w.config.Key, setup.PhaseNomination, time.Since(dialStarted), setup.OutcomeSuccess)
 
 	pair, err := w.agent.GetSelectedCandidatePair()
 	if err != nil {
@@ -149,6 +173,7 @@ func (w *WorkerICE) OnNewOffer(remoteOfferAnswer *OfferAnswer) {
 		RemoteIceCandidateEndpoint: fmt.Sprintf("%s:%d", pair.Remote.Address(), pair.Remote.Port()),
 		Relayed:                    isRelayed(pair),
 		RelayedOnLocal:             isRelayCandidate(pair.Local),
+		confirmedAt:                remoteOfferAnswer.confirmedAt,
 	}
 	w.log.Debugf("on ICE conn is ready to use")
 	go w.conn.onICEConnectionIsReady(selectedPriority(pair), ci)
@@ -194,15 +219,23 @@ func (w *WorkerICE) Close() {
 	}
 }
 
-func (w *WorkerICE) reCreateAgent(agentCancel context.CancelFunc, candidates []ice.CandidateType) (*ice.Agent, error) {
+func (w *WorkerICE) reCreateAgent(agentCancel context.CancelFunc, candidates []ice.CandidateType, fastNomination bool) (*ice.Agent, error) {
 	w.sentExtraSrflx = false
 
-	agent, err := icemaker.NewAgent(w.iFaceDiscover, w.config.ICEConfig, candidates, w.localUfrag, w.localPwd)
+	iceConfig := w.config.ICEConfig
+	iceConfig.FastNomination = fastNomination
+	agent, err := icemaker.NewAgent(w.iFaceDiscover, iceConfig, candidates, w.localUfrag, w.localPwd)
 	if err != nil {
 		return nil, fmt.Errorf("create agent: %w", err)
 	}
 
-	err = agent.OnCandidate(w.onICECandidate)
+	gatheringStarted := time.Now()
+	err = agent.OnCandidate(func(candidate ice.Candidate) {
+		if candidate == nil {
+			w.statusRecorder.RecordSetupPhase(w.config.Key, setup.PhaseGathering, time.Since(gatheringStarted), setup.OutcomeSuccess)
+		}
+		w.onICECandidate(candidate)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,133 @@
+package internal
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+
+	mgmProto "github.com/netbirdio/netbird/management/proto"
+)
+
+// PeerActionHandler runs the parts of the peer actions requested by the management service that need the daemon
+type PeerActionHandler interface {
+	// CollectStatus returns the status output reported for the collect status action
+	CollectStatus() string
+	// SavePrivateKey replaces the WireGuard private key of the config with the key of the rotate key action. The next
+	// engine uses the new key even if persisting it fails, the management service only knows the new key anymore.
+	SavePrivateKey(key wgtypes.Key) error
+}
+
+// verifyPeerAction checks the signature of the action for the key of the peer, its expiry and that it wasn't run
+// before. The management service signs the actions with the key of the NetbirdConfig.
+func (e *Engine) verifyPeerAction(action *mgmProto.PeerAction) error {
+	if len(e.peerActionsKey) != ed25519.PublicKeySize {
+		return errors.New("the management service didn't send a key to verify the peer actions")
+	}
+
+	data := mgmProto.PeerActionSignedData(e.config.WgPrivateKey.PublicKey().String(), action)
+	if !ed25519.Verify(e.peerActionsKey, data, action.GetSignature()) {
+		return errors.New("invalid signature")
+	}
+
+	now := time.Now()
+	expiresAt := action.GetExpiresAt().AsTime()
+	if now.After(expiresAt) {
+		return fmt.Errorf("the action expired at %s", expiresAt.Format(time.RFC3339))
+	}
+
+	for id, seenExpiresAt := range e.seenPeerActions {
+		if now.After(seenExpiresAt) {
+			delete(e.seenPeerActions, id)
+		}
+	}
+	if _, ok := e.seenPeerActions[action.GetId()]; ok {
+		return errors.New("the action was run before")
+	}
+	if e.seenPeerActions == nil {
+		e.seenPeerActions = make(map[string]time.Time)
+	}
+	e.seenPeerActions[action.GetId()] = expiresAt
+
+	return nil
+}
+
+// runPeerAction runs the verified action if the client allows it and reports its status. The restarts are reported
+// before they're run, the engine reporting them is stopped then.
+func (e *Engine) runPeerAction(handler PeerActionHandler, action *mgmProto.PeerAction) {
+	actionStatus := &mgmProto.PeerActionStatus{Id: action.GetId()}
+
+	if !slices.Contains(e.config.RemoteActions, action.GetAction()) {
+		log.Infof("declining peer action %s of the management service: %s isn't allowed", action.GetId(), action.GetAction())
+		actionStatus.Declined = true
+		actionStatus.Error = fmt.Sprintf("remote action %s is not allowed by the client", action.GetAction())
+		e.reportPeerActionStatus(actionStatus)
+		return
+	}
+
+	log.Infof("running peer action %s of the management service: %s", action.GetId(), action.GetAction())
+
+	switch action.GetAction() {
+	case mgmProto.PeerActionRestart:
+		e.reportPeerActionStatus(actionStatus)
+		e.restartEngine()
+	case mgmProto.PeerActionReauth:
+		// the next engine logs in to the management service again, the interface and the peer sessions are kept
+		e.reportPeerActionStatus(actionStatus)
+		e.hotRestartEngine()
+	case mgmProto.PeerActionCollectStatus:
+		if handler == nil {
+			actionStatus.Error = "collecting the status is not supported by the client"
+		} else {
+			actionStatus.Output = handler.CollectStatus()
+		}
+		e.reportPeerActionStatus(actionStatus)
+	case mgmProto.PeerActionRotateKey:
+		e.rotatePrivateKey(handler, actionStatus)
+	default:
+		actionStatus.Declined = true
+		actionStatus.Error = fmt.Sprintf("unknown remote action %s", action.GetAction())
+		e.reportPeerActionStatus(actionStatus)
+	}
+}
+
+// rotatePrivateKey replaces the WireGuard key of the peer. The management service switches to the new key when the
+// status is reported, the key is saved only if it accepted it and the next engine logs in with it.
+func (e *Engine) rotatePrivateKey(handler PeerActionHandler, actionStatus *mgmProto.PeerActionStatus) {
+	if handler == nil {
+		actionStatus.Error = "rotating the key is not supported by the client"
+		e.reportPeerActionStatus(actionStatus)
+		return
+	}
+
+	key, err := wgtypes.GeneratePrivateKey()
+	if err != nil {
+		actionStatus.Error = fmt.Sprintf("generate key: %v", err)
+		e.reportPeerActionStatus(actionStatus)
+		return
+	}
+
+	actionStatus.NewKey = key.PublicKey().String()
+	if err := e.mgmClient.ReportPeerActionStatus(actionStatus); err != nil {
+		log.Errorf("keeping the WireGuard key, the management service didn't accept the new key of peer action %s: %v",
+			actionStatus.GetId(), err)
+		return
+	}
+
+	if err := handler.SavePrivateKey(key); err != nil {
+		log.Errorf("failed to persist the rotated WireGuard key, it's lost on the next start of the client: %v", err)
+	}
+
+	log.Infof("rotated the WireGuard key, the new public key is %s", key.PublicKey().String())
+	e.restartEngine()
+}
+
+func (e *Engine) reportPeerActionStatus(actionStatus *mgmProto.PeerActionStatus) {
+	if err := e.mgmClient.ReportPeerActionStatus(actionStatus); err != nil {
+		log.Warnf("failed to report the status of peer action %s: %v", actionStatus.GetId(), err)
+	}
+}
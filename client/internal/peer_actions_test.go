@@ -0,0 +1,49 @@
+package internal
+
+import (
+	"crypto/ed25519"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	mgmProto "github.com/netbirdio/netbird/management/proto"
+)
+
+func TestEngine_VerifyPeerAction(t *testing.T) {
+	publicKey, signingKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	wgKey, err := wgtypes.GeneratePrivateKey()
+	require.NoError(t, err)
+
+	sign := func(peerKey string, action *mgmProto.PeerAction) *mgmProto.PeerAction {
+		action.Signature = ed25519.Sign(signingKey, mgmProto.PeerActionSignedData(peerKey, action))
+		return action
+	}
+	newAction := func(id string, expiresAt time.Time) *mgmProto.PeerAction {
+		return &mgmProto.PeerAction{Id: id, Action: mgmProto.PeerActionRestart, ExpiresAt: timestamppb.New(expiresAt)}
+	}
+
+	e := &Engine{config: &EngineConfig{WgPrivateKey: wgKey}}
+	peerKey := wgKey.PublicKey().String()
+	expiresAt := time.Now().Add(time.Minute)
+
+	assert.Error(t, e.verifyPeerAction(sign(peerKey, newAction("1", expiresAt))), "actions shouldn't run without a key")
+
+	e.peerActionsKey = publicKey
+	assert.Error(t, e.verifyPeerAction(sign("other peer", newAction("1", expiresAt))),
+		"an action signed for another peer shouldn't run")
+	assert.Error(t, e.verifyPeerAction(sign(peerKey, newAction("1", time.Now().Add(-time.Second)))),
+		"an expired action shouldn't run")
+
+	tampered := sign(peerKey, newAction("1", expiresAt))
+	tampered.Action = mgmProto.PeerActionRotateKey
+	assert.Error(t, e.verifyPeerAction(tampered), "a tampered action shouldn't run")
+
+	action := sign(peerKey, newAction("1", expiresAt))
+	require.NoError(t, e.verifyPeerAction(action))
+	assert.Error(t, e.verifyPeerAction(action), "a replayed action shouldn't run")
+}
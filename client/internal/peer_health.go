@@ -0,0 +1,124 @@
+package internal
+
+import (
+	"context"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/client/internal/peer"
+	"github.com/netbirdio/netbird/client/internal/probe"
+)
+
+const (
+	// peerHealthInterval is the interval of the health probes of the connected peers
+	peerHealthInterval = 30 * time.Second
+	// peerHealthProbes is the number of pings sent to each peer per round
+	peerHealthProbes = 3
+	// peerHealthProbeTimeout is the time a ping waits for the reply before it's counted as lost
+	peerHealthProbeTimeout = time.Second
+	// peerHealthConcurrency bounds the peers probed at once
+	peerHealthConcurrency = 16
+)
+
+type peerProbeResult struct {
+	pubKey  string
+	total   time.Duration
+	replies int
+}
+
+// startPeerHealthProbes pings the connected peers through the tunnels periodically, the round trip time and the loss
+// feed the health scores of the peers
+func (e *Engine) startPeerHealthProbes() {
+	go func() {
+		ticker := time.NewTicker(peerHealthInterval)
+		defer ticker.Stop()
+
+		// answered holds the peers which replied to a ping before, the others likely don't allow the pings
+		answered := make(map[string]bool)
+		for {
+			select {
+			case <-e.ctx.Done():
+				return
+			case <-ticker.C:
+				answered = e.probePeerHealth(answered)
+			}
+		}
+	}()
+}
+
+func (e *Engine) probePeerHealth(answered map[string]bool) map[string]bool {
+	var peers []peer.State
+	for _, state := range e.statusRecorder.GetFullStatus().Peers {
+		if state.ConnStatus == peer.StatusConnected {
+			peers = append(peers, state)
+		}
+	}
+
+	results := make([]peerProbeResult, len(peers))
+	sem := make(chan struct{}, peerHealthConcurrency)
+	var wg sync.WaitGroup
+	for i, state := range peers {
+		address, err := netip.ParseAddr(strings.Split(state.IP, "/")[0])
+		if err != nil {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = pingPeer(e.ctx, state.PubKey, address)
+		}()
+	}
+	wg.Wait()
+
+	if e.ctx.Err() != nil {
+		return answered
+	}
+
+	stillAnswered := make(map[string]bool, len(results))
+	for _, result := range results {
+		if result.pubKey == "" {
+			continue
+		}
+
+		var rtt time.Duration
+		var loss float64
+		switch {
+		case result.replies > 0:
+			rtt = result.total / time.Duration(result.replies)
+			loss = float64(peerHealthProbes-result.replies) / peerHealthProbes
+			stillAnswered[result.pubKey] = true
+		case answered[result.pubKey]:
+			// the peer replied before, the pings are lost
+			loss = 1
+			stillAnswered[result.pubKey] = true
+		}
+
+		if err := e.statusRecorder.UpdatePeerProbe(result.pubKey, rtt, loss); err != nil {
+			log.Debugf("failed to update the health probe of peer %s: %v", result.pubKey, err)
+		}
+	}
+	return stillAnswered
+}
+
+func pingPeer(ctx context.Context, pubKey string, address netip.Addr) peerProbeResult {
+	result := peerProbeResult{pubKey: pubKey}
+	for i := 0; i < peerHealthProbes && ctx.Err() == nil; i++ {
+		probeCtx, cancel := context.WithTimeout(ctx, peerHealthProbeTimeout)
+		latency, err := probe.Ping(probeCtx, address)
+		cancel()
+		if err != nil {
+			log.Tracef("health probe of peer %s failed: %v", address, err)
+			continue
+		}
+		result.total += latency
+		result.replies++
+	}
+	return result
+}
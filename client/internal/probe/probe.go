@@ -0,0 +1,200 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"strconv"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	mgmProto "github.com/netbirdio/netbird/management/proto"
+)
+
+const (
+	// defaultTimeout is used when the request doesn't set a timeout
+	defaultTimeout = 5 * time.Second
+	// maxTimeout limits the timeout requested by the management service
+	maxTimeout = 30 * time.Second
+)
+
+// Run executes the probe requested by the management service and returns its result. The URL is requested with an
+// HTTP GET when the request sets one, a TCP connection is established to the target when the request sets a port,
+// otherwise the target is pinged with an ICMP echo request.
+func Run(ctx context.Context, request *mgmProto.ProbeRequest) *mgmProto.ProbeResult {
+	result := &mgmProto.ProbeResult{Id: request.GetId()}
+
+	timeout := request.GetTimeout().AsDuration()
+	if timeout <= 0 || timeout > maxTimeout {
+		timeout = defaultTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if request.GetUrl() != "" {
+		latency, statusCode, err := httpGet(ctx, request.GetUrl())
+		result.HttpStatus = uint32(statusCode)
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		result.Reachable = true
+		result.Latency = durationpb.New(latency)
+		return result
+	}
+
+	address, err := netip.ParseAddr(request.GetTarget())
+	if err != nil {
+		result.Error = fmt.Sprintf("invalid target %s", request.GetTarget())
+		return result
+	}
+
+	var latency time.Duration
+	if port := request.GetPort(); port > 0 {
+		latency, err = dialTCP(ctx, address, port)
+	} else {
+		latency, err = Ping(ctx, address)
+	}
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Reachable = true
+	result.Latency = durationpb.New(latency)
+	return result
+}
+
+// httpGet requests the URL and returns the time to the response headers. A server error status fails the probe.
+func httpGet(ctx context.Context, url string) (time.Duration, int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid url: %w", err)
+	}
+	req.Header.Set("User-Agent", "netbird-probe")
+
+	client := &http.Client{
+		// the response to the URL is checked, not the one of the redirect target
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	latency := time.Since(start)
+	_ = resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return 0, resp.StatusCode, fmt.Errorf("server responded with %s", resp.Status)
+	}
+
+	return latency, resp.StatusCode, nil
+}
+
+func dialTCP(ctx context.Context, address netip.Addr, port uint32) (time.Duration, error) {
+	if port > 65535 {
+		return 0, fmt.Errorf("invalid port %d", port)
+	}
+
+	var dialer net.Dialer
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(address.String(), strconv.Itoa(int(port))))
+	if err != nil {
+		return 0, err
+	}
+	latency := time.Since(start)
+
+	_ = conn.Close()
+	return latency, nil
+}
+
+// Ping sends an ICMP echo request with a raw socket, which requires privileges, or an unprivileged datagram socket
+// as a fallback and waits for the reply
+func Ping(ctx context.Context, address netip.Addr) (time.Duration, error) {
+	network, unprivileged, echoType, protocol := "ip4:icmp", "udp4", icmp.Type(ipv4.ICMPTypeEcho), 1
+	if address.Is6() && !address.Is4In6() {
+		network, unprivileged, echoType, protocol = "ip6:ipv6-icmp", "udp6", ipv6.ICMPTypeEchoRequest, 58
+	}
+	address = address.Unmap()
+
+	var dst net.Addr = &net.IPAddr{IP: address.AsSlice()}
+	conn, err := icmp.ListenPacket(network, "")
+	if err != nil {
+		conn, err = icmp.ListenPacket(unprivileged, "")
+		if err != nil {
+			return 0, fmt.Errorf("open ICMP socket: %w", err)
+		}
+		dst = &net.UDPAddr{IP: address.AsSlice()}
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err = conn.SetDeadline(deadline); err != nil {
+			return 0, fmt.Errorf("set ICMP deadline: %w", err)
+		}
+	}
+
+	id := os.Getpid() & 0xffff
+	request, err := (&icmp.Message{
+		Type: echoType,
+		Body: &icmp.Echo{ID: id, Seq: 1, Data: []byte("netbird-probe")},
+	}).Marshal(nil)
+	if err != nil {
+		return 0, fmt.Errorf("marshal ICMP echo request: %w", err)
+	}
+
+	start := time.Now()
+	if _, err = conn.WriteTo(request, dst); err != nil {
+		return 0, fmt.Errorf("send ICMP echo request: %w", err)
+	}
+
+	buf := make([]byte, 1500)
+	for {
+		n, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				return 0, errors.New("no reply within the timeout")
+			}
+			return 0, fmt.Errorf("read ICMP reply: %w", err)
+		}
+
+		if !sameHost(from, address) {
+			continue
+		}
+
+		reply, err := icmp.ParseMessage(protocol, buf[:n])
+		if err != nil {
+			continue
+		}
+		if reply.Type == ipv4.ICMPTypeEchoReply || reply.Type == ipv6.ICMPTypeEchoReply {
+			return time.Since(start), nil
+		}
+	}
+}
+
+func sameHost(from net.Addr, address netip.Addr) bool {
+	switch addr := from.(type) {
+	case *net.IPAddr:
+		ip, ok := netip.AddrFromSlice(addr.IP)
+		return ok && ip.Unmap() == address
+	case *net.UDPAddr:
+		ip, ok := netip.AddrFromSlice(addr.IP)
+		return ok && ip.Unmap() == address
+	default:
+		return false
+	}
+}
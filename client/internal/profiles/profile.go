@@ -0,0 +1,194 @@
+// Package profiles lets the daemon run the daemons of additional profiles next to the default one, e.g. a corporate
+// account on wt0 and a lab account on wt1. Each profile has its own config, state, WireGuard interface, routes, DNS
+// and firewall rules, the daemon of a profile serves the CLI on an address of its own.
+package profiles
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/netbirdio/netbird/client/configs"
+	"github.com/netbirdio/netbird/util"
+	nbnet "github.com/netbirdio/netbird/util/net"
+)
+
+const (
+	// EnvProfile is set to the name of the profile for the daemons of the profiles, it's empty for the default daemon
+	EnvProfile = "NB_DAEMON_PROFILE"
+
+	// MaxProfiles is the number of profiles the default daemon runs at most
+	MaxProfiles = 16
+
+	storeFileName = "profiles.json"
+	profilesDir   = "profiles"
+)
+
+var nameRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,19}$`)
+
+// Profile is a NetBird account the daemon of the profile connects to next to the default one
+type Profile struct {
+	Name string
+	// Index is the position of the profile, starting at 1. The fwmarks, the routing table and the default WireGuard
+	// interface and port are derived from it.
+	Index          int
+	InterfaceName  string
+	WgPort         int
+	DaemonAddr     string
+	ConfigPath     string
+	LogFile        string
+	StateDir       string
+	Fwmark         uint32
+	RoutingTableID int
+}
+
+// Current returns the profile the daemon serves, empty for the default daemon
+func Current() string {
+	return os.Getenv(EnvProfile)
+}
+
+// Suffix returns the suffix distinguishing the system resources of the daemon of the profile, like the DNS settings,
+// from the ones of the default daemon. It's empty for the default daemon.
+func Suffix() string {
+	if profile := Current(); profile != "" {
+		return "-" + profile
+	}
+	return ""
+}
+
+// ValidateName checks that the name is usable in the paths and the system resources of the profile
+func ValidateName(name string) error {
+	if !nameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q: use up to 20 lowercase letters, digits, dashes and underscores", name)
+	}
+	return nil
+}
+
+// New creates the nth profile, the paths are derived from the ones of the default daemon
+func New(name string, index int, configPath, daemonAddr, logFile string) (*Profile, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+
+	addr, err := profileDaemonAddr(daemonAddr, name, index)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Profile{
+		Name:           name,
+		Index:          index,
+		DaemonAddr:     addr,
+		ConfigPath:     filepath.Join(filepath.Dir(configPath), profilesDir, name, "config.json"),
+		LogFile:        profileLogFile(logFile, name),
+		StateDir:       filepath.Join(configs.StateDir, profilesDir, name),
+		Fwmark:         nbnet.ProfileFwmark(index),
+		RoutingTableID: nbnet.ProfileRoutingTableID(index),
+	}, nil
+}
+
+// profileDaemonAddr places the unix socket of the profile next to the default one, the TCP address uses the port of
+// the default daemon offset by the index
+func profileDaemonAddr(daemonAddr, name string, index int) (string, error) {
+	scheme, addr, ok := strings.Cut(daemonAddr, "://")
+	if !ok {
+		return "", fmt.Errorf("invalid daemon address %q", daemonAddr)
+	}
+
+	switch scheme {
+	case "unix":
+		ext := filepath.Ext(addr)
+		return fmt.Sprintf("unix://%s-%s%s", strings.TrimSuffix(addr, ext), name, ext), nil
+	case "tcp":
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return "", fmt.Errorf("invalid daemon address %q: %w", daemonAddr, err)
+		}
+		p, err := strconv.Atoi(port)
+		if err != nil {
+			return "", fmt.Errorf("invalid daemon address %q: %w", daemonAddr, err)
+		}
+		return "tcp://" + net.JoinHostPort(host, strconv.Itoa(p+index)), nil
+	default:
+		return "", fmt.Errorf("unsupported daemon address protocol: %v", scheme)
+	}
+}
+
+// profileLogFile suffixes the log file with the name of the profile, the console and syslog are shared
+func profileLogFile(logFile, name string) string {
+	if logFile == "console" || logFile == "syslog" || logFile == "" {
+		return logFile
+	}
+	ext := filepath.Ext(logFile)
+	return fmt.Sprintf("%s-%s%s", strings.TrimSuffix(logFile, ext), name, ext)
+}
+
+// Env returns the environment of the daemon of the profile. The flags of the daemon are set by the environment
+// variables, they take precedence over the variables of the default daemon the environment is inherited from.
+func (p *Profile) Env() []string {
+	return append(os.Environ(),
+		EnvProfile+"="+p.Name,
+		"NB_CONFIG="+p.ConfigPath,
+		"NB_DAEMON_ADDR="+p.DaemonAddr,
+		"NB_LOG_FILE="+p.LogFile,
+		"NB_STATE_DIR="+p.StateDir,
+		// the state file of the default daemon is set by the variable
+		"NB_DNS_STATE_FILE=",
+		// the API gateway and the metrics are served by the default daemon only
+		"NB_API_GATEWAY_ADDR=",
+		"NB_METRICS_ADDR=",
+	)
+}
+
+// Store persists the profiles next to the config of the default daemon
+type Store struct {
+	path string
+}
+
+// NewStore creates a Store of the profiles of the default daemon using the config at configPath
+func NewStore(configPath string) *Store {
+	return &Store{path: filepath.Join(filepath.Dir(configPath), storeFileName)}
+}
+
+// Load returns the stored profiles, none if the file doesn't exist
+func (s *Store) Load() ([]*Profile, error) {
+	if _, err := os.Stat(s.path); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	var profiles []*Profile
+	if _, err := util.ReadJson(s.path, &profiles); err != nil {
+		return nil, fmt.Errorf("read profiles: %w", err)
+	}
+	return profiles, nil
+}
+
+// Save replaces the stored profiles
+func (s *Store) Save(ctx context.Context, profiles []*Profile) error {
+	if profiles == nil {
+		profiles = []*Profile{}
+	}
+	if err := util.WriteJsonWithRestrictedPermission(ctx, s.path, profiles); err != nil {
+		return fmt.Errorf("write profiles: %w", err)
+	}
+	return nil
+}
+
+// NextIndex returns the lowest index not used by the profiles
+func NextIndex(profiles []*Profile) (int, error) {
+	used := make(map[int]struct{}, len(profiles))
+	for _, p := range profiles {
+		used[p.Index] = struct{}{}
+	}
+	for i := 1; i <= MaxProfiles; i++ {
+		if _, ok := used[i]; !ok {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("up to %d profiles are supported", MaxProfiles)
+}
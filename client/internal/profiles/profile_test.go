@@ -0,0 +1,70 @@
+package profiles
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	nbnet "github.com/netbirdio/netbird/util/net"
+)
+
+func TestNew(t *testing.T) {
+	p, err := New("lab", 2, "/etc/netbird/config.json", "unix:///var/run/netbird.sock", "/var/log/netbird/client.log")
+	require.NoError(t, err)
+
+	assert.Equal(t, "unix:///var/run/netbird-lab.sock", p.DaemonAddr)
+	assert.Equal(t, filepath.Join("/etc/netbird", "profiles", "lab", "config.json"), p.ConfigPath)
+	assert.Equal(t, "/var/log/netbird/client-lab.log", p.LogFile)
+	assert.Equal(t, nbnet.ProfileFwmark(2), p.Fwmark)
+	assert.Equal(t, nbnet.ProfileRoutingTableID(2), p.RoutingTableID)
+	assert.NotEqual(t, p.Fwmark, nbnet.ProfileFwmark(1), "the profiles must not share the fwmarks")
+
+	p, err = New("lab", 2, "/etc/netbird/config.json", "tcp://127.0.0.1:41731", "console")
+	require.NoError(t, err)
+	assert.Equal(t, "tcp://127.0.0.1:41733", p.DaemonAddr)
+	assert.Equal(t, "console", p.LogFile)
+
+	_, err = New("Lab/../x", 1, "/etc/netbird/config.json", "unix:///var/run/netbird.sock", "console")
+	assert.Error(t, err)
+
+	_, err = New("lab", 1, "/etc/netbird/config.json", "udp://127.0.0.1:41731", "console")
+	assert.Error(t, err)
+}
+
+func TestNextIndex(t *testing.T) {
+	index, err := NextIndex(nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, index)
+
+	index, err = NextIndex([]*Profile{{Index: 1}, {Index: 3}})
+	require.NoError(t, err)
+	assert.Equal(t, 2, index)
+
+	profiles := make([]*Profile, 0, MaxProfiles)
+	for i := 1; i <= MaxProfiles; i++ {
+		profiles = append(profiles, &Profile{Index: i})
+	}
+	_, err = NextIndex(profiles)
+	assert.Error(t, err)
+}
+
+func TestStore(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "config.json"))
+
+	profiles, err := store.Load()
+	require.NoError(t, err)
+	assert.Empty(t, profiles)
+
+	p, err := New("lab", 1, "/etc/netbird/config.json", "unix:///var/run/netbird.sock", "console")
+	require.NoError(t, err)
+	p.InterfaceName = "wt1"
+	require.NoError(t, store.Save(context.Background(), []*Profile{p}))
+
+	profiles, err = store.Load()
+	require.NoError(t, err)
+	require.Len(t, profiles, 1)
+	assert.Equal(t, p, profiles[0])
+}
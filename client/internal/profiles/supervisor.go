@@ -0,0 +1,176 @@
+package profiles
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// stopTimeout is the time the daemon of a profile gets to shut down before it's killed
+	stopTimeout = 10 * time.Second
+
+	minRestartDelay = time.Second
+	maxRestartDelay = time.Minute
+	// stableRunTime resets the restart delay if the daemon ran that long
+	stableRunTime = time.Minute
+)
+
+// Supervisor runs the daemons of the profiles as child processes of the default daemon and restarts them if they exit
+type Supervisor struct {
+	executable string
+	logLevel   string
+
+	mu      sync.Mutex
+	daemons map[string]*daemon
+}
+
+type daemon struct {
+	cancel  context.CancelFunc
+	done    chan struct{}
+	mu      sync.Mutex
+	running bool
+}
+
+// NewSupervisor creates a Supervisor running the daemons with the executable of the default daemon
+func NewSupervisor(logLevel string) (*Supervisor, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("get executable: %w", err)
+	}
+
+	return &Supervisor{
+		executable: executable,
+		logLevel:   logLevel,
+		daemons:    make(map[string]*daemon),
+	}, nil
+}
+
+// Start runs the daemon of the profile until Stop is called or the context is done
+func (s *Supervisor) Start(ctx context.Context, profile *Profile) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.daemons[profile.Name]; ok {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	d := &daemon{cancel: cancel, done: make(chan struct{})}
+	s.daemons[profile.Name] = d
+
+	go s.supervise(ctx, profile, d)
+}
+
+func (s *Supervisor) supervise(ctx context.Context, profile *Profile, d *daemon) {
+	defer close(d.done)
+
+	delay := minRestartDelay
+	for {
+		started := time.Now()
+		err := s.run(ctx, profile, d)
+		if ctx.Err() != nil {
+			log.Infof("stopped the daemon of profile %s", profile.Name)
+			return
+		}
+		log.Warnf("the daemon of profile %s exited: %v, restarting in %s", profile.Name, err, delay)
+
+		if time.Since(started) > stableRunTime {
+			delay = minRestartDelay
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxRestartDelay)
+	}
+}
+
+func (s *Supervisor) run(ctx context.Context, profile *Profile, d *daemon) error {
+	cmd := exec.CommandContext(ctx, s.executable, "service", "run", "--log-level", s.logLevel)
+	cmd.Env = profile.Env()
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	// the daemon restores the system on interrupt, it's killed if it doesn't exit in time. Windows doesn't support
+	// the interrupt, the daemon is brought down over its API before.
+	cmd.Cancel = func() error {
+		if runtime.GOOS == "windows" {
+			return cmd.Process.Kill()
+		}
+		return cmd.Process.Signal(os.Interrupt)
+	}
+	cmd.WaitDelay = stopTimeout
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("start: %w", err)
+	}
+	log.Infof("started the daemon of profile %s on %s, pid %d", profile.Name, profile.InterfaceName, cmd.Process.Pid)
+
+	d.setRunning(true)
+	defer d.setRunning(false)
+
+	return cmd.Wait()
+}
+
+// Stop stops the daemon of the profile and waits for it to exit
+func (s *Supervisor) Stop(name string) {
+	s.mu.Lock()
+	d, ok := s.daemons[name]
+	delete(s.daemons, name)
+	s.mu.Unlock()
+
+	if !ok {
+		return
+	}
+	d.cancel()
+	<-d.done
+}
+
+// StopAll stops the daemons of all profiles
+func (s *Supervisor) StopAll() {
+	s.mu.Lock()
+	names := make([]string, 0, len(s.daemons))
+	for name := range s.daemons {
+		names = append(names, name)
+	}
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, name := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.Stop(name)
+		}()
+	}
+	wg.Wait()
+}
+
+// Running reports if the daemon of the profile is running
+func (s *Supervisor) Running(name string) bool {
+	s.mu.Lock()
+	d, ok := s.daemons[name]
+	s.mu.Unlock()
+
+	return ok && d.isRunning()
+}
+
+func (d *daemon) setRunning(running bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.running = running
+}
+
+func (d *daemon) isRunning() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.running
+}
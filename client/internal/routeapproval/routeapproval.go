@@ -0,0 +1,209 @@
+// Package routeapproval holds back the high-risk routes pushed by the management service until the local user
+// approves them, protecting the users of shared accounts from a compromised management pushing a hostile exit node.
+package routeapproval
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/netbirdio/netbird/client/errors"
+	"github.com/netbirdio/netbird/route"
+)
+
+const (
+	// LargePrefixBitsIPv4 is the prefix length of the IPv4 routes requiring approval and all shorter ones
+	LargePrefixBitsIPv4 = 7
+	// LargePrefixBitsIPv6 is the prefix length of the IPv6 routes requiring approval and all shorter ones
+	LargePrefixBitsIPv6 = 31
+)
+
+// IsHighRisk returns true for the default routes and the routes larger than a /8 for IPv4 or a /32 for IPv6, they
+// redirect most of the traffic of the peer. The domain routes only cover the resolved addresses of their domains.
+func IsHighRisk(r *route.Route) bool {
+	if r.IsDynamic() {
+		return false
+	}
+	if r.Network.Addr().Is4() {
+		return r.Network.Bits() <= LargePrefixBitsIPv4
+	}
+	return r.Network.Bits() <= LargePrefixBitsIPv6
+}
+
+// RouteApprover filters the high-risk routes the local user didn't approve. An approval covers a network through a
+// routing peer, the same network through another routing peer needs a new approval.
+type RouteApprover struct {
+	mu sync.RWMutex
+	// approved are the routing peers approved per network
+	approved map[route.HAUniqueID]map[string]struct{}
+	// pending are the routes waiting for approval in the latest update
+	pending []*route.Route
+}
+
+func NewRouteApprover() *RouteApprover {
+	return &RouteApprover{
+		approved: map[route.HAUniqueID]map[string]struct{}{},
+	}
+}
+
+// FilterApproved removes the high-risk routes the local user didn't approve from the provided map, they're pending
+// until approved. It returns the pending routes that weren't pending before.
+func (ra *RouteApprover) FilterApproved(routes route.HAMap) (route.HAMap, []*route.Route) {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+
+	filtered := route.HAMap{}
+	var pending, added []*route.Route
+	for id, rt := range routes {
+		var approved []*route.Route
+		for _, r := range rt {
+			if !IsHighRisk(r) || ra.isApproved(id, r.Peer) {
+				approved = append(approved, r)
+				continue
+			}
+
+			pending = append(pending, r)
+			if !slices.ContainsFunc(ra.pending, func(p *route.Route) bool { return isSameApproval(p, r) }) {
+				added = append(added, r)
+			}
+		}
+		if len(approved) > 0 {
+			filtered[id] = approved
+		}
+	}
+
+	sortRoutes(pending)
+	sortRoutes(added)
+	ra.pending = pending
+
+	return filtered, added
+}
+
+// Pending returns the routes waiting for approval
+func (ra *RouteApprover) Pending() []*route.Route {
+	ra.mu.RLock()
+	defer ra.mu.RUnlock()
+
+	return slices.Clone(ra.pending)
+}
+
+// IsPending checks if routes of the network are waiting for approval
+func (ra *RouteApprover) IsPending(netID route.NetID) bool {
+	ra.mu.RLock()
+	defer ra.mu.RUnlock()
+
+	return slices.ContainsFunc(ra.pending, func(r *route.Route) bool { return r.NetID == netID })
+}
+
+// ApproveRoutes approves the pending routes of the networks
+func (ra *RouteApprover) ApproveRoutes(netIDs []route.NetID) error {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+
+	var err *multierror.Error
+	for _, netID := range netIDs {
+		found := false
+		for _, r := range ra.pending {
+			if r.NetID != netID {
+				continue
+			}
+			found = true
+
+			id := r.GetHAUniqueID()
+			if ra.approved[id] == nil {
+				ra.approved[id] = map[string]struct{}{}
+			}
+			ra.approved[id][r.Peer] = struct{}{}
+		}
+		if !found {
+			err = multierror.Append(err, fmt.Errorf("network '%s' has no routes waiting for approval", netID))
+		}
+	}
+
+	return errors.FormatErrorOrNil(err)
+}
+
+// RevokeRoutes removes the approvals of the networks, their high-risk routes wait for a new approval
+func (ra *RouteApprover) RevokeRoutes(netIDs []route.NetID) {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+
+	for id := range ra.approved {
+		if slices.Contains(netIDs, id.NetID()) {
+			delete(ra.approved, id)
+		}
+	}
+}
+
+func (ra *RouteApprover) isApproved(id route.HAUniqueID, peer string) bool {
+	_, ok := ra.approved[id][peer]
+	return ok
+}
+
+func isSameApproval(a, b *route.Route) bool {
+	return a.GetHAUniqueID() == b.GetHAUniqueID() && a.Peer == b.Peer
+}
+
+func sortRoutes(routes []*route.Route) {
+	slices.SortFunc(routes, func(a, b *route.Route) int {
+		if c := strings.Compare(string(a.NetID), string(b.NetID)); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Peer, b.Peer)
+	})
+}
+
+// MarshalJSON implements the json.Marshaler interface, only the approvals are persisted
+func (ra *RouteApprover) MarshalJSON() ([]byte, error) {
+	ra.mu.RLock()
+	defer ra.mu.RUnlock()
+
+	approved := make(map[route.HAUniqueID][]string, len(ra.approved))
+	for id, peers := range ra.approved {
+		for peer := range peers {
+			approved[id] = append(approved[id], peer)
+		}
+		slices.Sort(approved[id])
+	}
+
+	return json.Marshal(struct {
+		Approved map[route.HAUniqueID][]string `json:"approved"`
+	}{
+		Approved: approved,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface
+// If the JSON is empty or null, it will initialize like a NewRouteApprover.
+func (ra *RouteApprover) UnmarshalJSON(data []byte) error {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+
+	ra.approved = map[route.HAUniqueID]map[string]struct{}{}
+
+	// Check for null or empty JSON
+	if len(data) == 0 || string(data) == "null" {
+		return nil
+	}
+
+	var temp struct {
+		Approved map[route.HAUniqueID][]string `json:"approved"`
+	}
+
+	if err := json.Unmarshal(data, &temp); err != nil {
+		return err
+	}
+
+	for id, peers := range temp.Approved {
+		ra.approved[id] = map[string]struct{}{}
+		for _, peer := range peers {
+			ra.approved[id][peer] = struct{}{}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package routeapproval_test
+
+import (
+	"encoding/json"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/client/internal/routeapproval"
+	"github.com/netbirdio/netbird/management/domain"
+	"github.com/netbirdio/netbird/route"
+)
+
+func newRoute(netID, network, peer string) *route.Route {
+	return &route.Route{
+		NetID:       route.NetID(netID),
+		Network:     netip.MustParsePrefix(network),
+		NetworkType: route.IPv4Network,
+		Peer:        peer,
+	}
+}
+
+func toHAMap(routes ...*route.Route) route.HAMap {
+	haMap := route.HAMap{}
+	for _, r := range routes {
+		haMap[r.GetHAUniqueID()] = append(haMap[r.GetHAUniqueID()], r)
+	}
+	return haMap
+}
+
+func TestIsHighRisk(t *testing.T) {
+	assert.True(t, routeapproval.IsHighRisk(newRoute("exit", "0.0.0.0/0", "peer1")))
+	assert.True(t, routeapproval.IsHighRisk(newRoute("half", "0.0.0.0/1", "peer1")))
+	assert.True(t, routeapproval.IsHighRisk(newRoute("exit6", "::/0", "peer1")))
+	assert.False(t, routeapproval.IsHighRisk(newRoute("corp", "10.0.0.0/8", "peer1")))
+	assert.False(t, routeapproval.IsHighRisk(newRoute("site6", "2001:db8::/32", "peer1")))
+
+	domains, err := domain.FromStringList([]string{"example.com"})
+	require.NoError(t, err)
+	assert.False(t, routeapproval.IsHighRisk(&route.Route{
+		NetID:       "domain",
+		Network:     netip.MustParsePrefix("192.0.2.0/32"),
+		NetworkType: route.DomainNetwork,
+		Domains:     domains,
+	}))
+}
+
+func TestRouteApprover_FilterApproved(t *testing.T) {
+	ra := routeapproval.NewRouteApprover()
+
+	exit1 := newRoute("exit", "0.0.0.0/0", "peer1")
+	exit2 := newRoute("exit", "0.0.0.0/0", "peer2")
+	corp := newRoute("corp", "10.0.0.0/8", "peer1")
+
+	filtered, added := ra.FilterApproved(toHAMap(exit1, corp))
+	assert.Equal(t, toHAMap(corp), filtered, "only the low-risk routes should be installed")
+	assert.Equal(t, []*route.Route{exit1}, added)
+	assert.True(t, ra.IsPending("exit"))
+	assert.False(t, ra.IsPending("corp"))
+
+	_, added = ra.FilterApproved(toHAMap(exit1, corp))
+	assert.Empty(t, added, "the routes already pending shouldn't be reported again")
+
+	require.NoError(t, ra.ApproveRoutes([]route.NetID{"exit"}))
+	assert.Error(t, ra.ApproveRoutes([]route.NetID{"corp"}), "only the pending routes can be approved")
+
+	filtered, added = ra.FilterApproved(toHAMap(exit1, exit2, corp))
+	assert.Equal(t, toHAMap(exit1, corp), filtered, "the approval shouldn't cover another routing peer")
+	assert.Equal(t, []*route.Route{exit2}, added)
+
+	ra.RevokeRoutes([]route.NetID{"exit"})
+	filtered, _ = ra.FilterApproved(toHAMap(exit1, exit2, corp))
+	assert.Equal(t, toHAMap(corp), filtered)
+	assert.Equal(t, []*route.Route{exit1, exit2}, ra.Pending())
+}
+
+func TestRouteApprover_MarshalJSON(t *testing.T) {
+	ra := routeapproval.NewRouteApprover()
+	exit := newRoute("exit", "0.0.0.0/0", "peer1")
+
+	ra.FilterApproved(toHAMap(exit))
+	require.NoError(t, ra.ApproveRoutes([]route.NetID{"exit"}))
+
+	data, err := json.Marshal(ra)
+	require.NoError(t, err)
+
+	restored := routeapproval.NewRouteApprover()
+	require.NoError(t, json.Unmarshal(data, restored))
+
+	filtered, added := restored.FilterApproved(toHAMap(exit))
+	assert.Equal(t, toHAMap(exit), filtered)
+	assert.Empty(t, added)
+
+	empty := &routeapproval.RouteApprover{}
+	require.NoError(t, empty.UnmarshalJSON([]byte("null")))
+	filtered, _ = empty.FilterApproved(toHAMap(exit))
+	assert.Empty(t, filtered)
+}
@@ -0,0 +1,91 @@
+package routelearn
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// filterRule matches a prefix and the subnets of the prefix up to a maximum length
+type filterRule struct {
+	prefix netip.Prefix
+	maxLen int
+	deny   bool
+}
+
+func (r filterRule) match(p netip.Prefix) bool {
+	return r.prefix.Addr().Is4() == p.Addr().Is4() &&
+		p.Bits() >= r.prefix.Bits() &&
+		p.Bits() <= r.maxLen &&
+		r.prefix.Contains(p.Addr())
+}
+
+// Filter selects the learned prefixes advertised to management. The rules are evaluated in order and the first
+// matching rule decides, the prefixes not matching any rule are rejected. An empty filter accepts every prefix.
+type Filter struct {
+	rules []filterRule
+}
+
+// ParseFilter parses the filter rules. A rule is a prefix accepting the prefix and its subnets, like "10.0.0.0/8",
+// optionally limited to a maximum prefix length, like "10.0.0.0/8 le 24". A rule starting with "!" rejects the
+// matching prefixes, like "!10.99.0.0/16".
+func ParseFilter(rules []string) (*Filter, error) {
+	f := &Filter{}
+	for _, rule := range rules {
+		r, err := parseRule(rule)
+		if err != nil {
+			return nil, fmt.Errorf("invalid route filter %q: %w", rule, err)
+		}
+		f.rules = append(f.rules, r)
+	}
+	return f, nil
+}
+
+func parseRule(rule string) (filterRule, error) {
+	var r filterRule
+
+	rule = strings.TrimSpace(rule)
+	if strings.HasPrefix(rule, "!") {
+		r.deny = true
+		rule = strings.TrimSpace(rule[1:])
+	}
+
+	fields := strings.Fields(rule)
+	if len(fields) != 1 && (len(fields) != 3 || fields[1] != "le") {
+		return r, fmt.Errorf("expected [!]prefix [le length]")
+	}
+
+	prefix, err := netip.ParsePrefix(fields[0])
+	if err != nil {
+		return r, err
+	}
+	if prefix != prefix.Masked() {
+		return r, fmt.Errorf("the prefix has host bits set, use %s", prefix.Masked())
+	}
+	r.prefix = prefix
+	r.maxLen = prefix.Addr().BitLen()
+
+	if len(fields) == 3 {
+		maxLen, err := strconv.Atoi(fields[2])
+		if err != nil || maxLen < prefix.Bits() || maxLen > prefix.Addr().BitLen() {
+			return r, fmt.Errorf("invalid length %q", fields[2])
+		}
+		r.maxLen = maxLen
+	}
+
+	return r, nil
+}
+
+// Allow returns true if the prefix passes the filter
+func (f *Filter) Allow(prefix netip.Prefix) bool {
+	if f == nil || len(f.rules) == 0 {
+		return true
+	}
+	for _, r := range f.rules {
+		if r.match(prefix) {
+			return !r.deny
+		}
+	}
+	return false
+}
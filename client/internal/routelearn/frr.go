@@ -0,0 +1,73 @@
+package routelearn
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"os/exec"
+)
+
+// frrRoute is an entry of the output of "show ip route bgp json"
+type frrRoute struct {
+	Prefix   string `json:"prefix"`
+	Selected bool   `json:"selected"`
+}
+
+// frrSource reads the BGP routes selected by FRR
+type frrSource struct {
+	vtysh func(ctx context.Context, command string) ([]byte, error)
+}
+
+func newFRRSource() *frrSource {
+	return &frrSource{vtysh: runVtysh}
+}
+
+func runVtysh(ctx context.Context, command string) ([]byte, error) {
+	out, err := exec.CommandContext(ctx, "vtysh", "-c", command).Output()
+	if err != nil {
+		return nil, fmt.Errorf("run vtysh %q: %w", command, err)
+	}
+	return out, nil
+}
+
+// Routes returns the selected IPv4 and IPv6 BGP routes
+func (s *frrSource) Routes(ctx context.Context) ([]netip.Prefix, error) {
+	var routes []netip.Prefix
+	for _, command := range []string{"show ip route bgp json", "show ipv6 route bgp json"} {
+		out, err := s.vtysh(ctx, command)
+		if err != nil {
+			return nil, err
+		}
+		prefixes, err := parseFRRRoutes(out)
+		if err != nil {
+			return nil, fmt.Errorf("parse %q: %w", command, err)
+		}
+		routes = append(routes, prefixes...)
+	}
+	return routes, nil
+}
+
+// parseFRRRoutes parses the routes keyed by prefix, each prefix has an entry per path
+func parseFRRRoutes(data []byte) ([]netip.Prefix, error) {
+	var table map[string][]frrRoute
+	if err := json.Unmarshal(data, &table); err != nil {
+		return nil, err
+	}
+
+	var routes []netip.Prefix
+	for key, paths := range table {
+		for _, path := range paths {
+			if !path.Selected {
+				continue
+			}
+			prefix, err := netip.ParsePrefix(key)
+			if err != nil {
+				return nil, fmt.Errorf("invalid prefix %q: %w", key, err)
+			}
+			routes = append(routes, prefix)
+			break
+		}
+	}
+	return routes, nil
+}
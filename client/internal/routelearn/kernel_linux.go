@@ -0,0 +1,52 @@
+//go:build !android
+
+package routelearn
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"syscall"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// routingProtocols are the protocols of the routes installed by the routing daemons, the static and connected
+// routes of the host are never learned
+var routingProtocols = map[netlink.RouteProtocol]struct{}{
+	unix.RTPROT_BGP:   {},
+	unix.RTPROT_ZEBRA: {},
+	unix.RTPROT_BIRD:  {},
+}
+
+// kernelSource reads the routes installed in the main table by a routing daemon like FRR or BIRD
+type kernelSource struct{}
+
+func newKernelSource() *kernelSource {
+	return &kernelSource{}
+}
+
+// Routes returns the IPv4 and IPv6 routes of the main table installed by a routing daemon
+func (s *kernelSource) Routes(context.Context) ([]netip.Prefix, error) {
+	var routes []netip.Prefix
+	for _, family := range []int{netlink.FAMILY_V4, netlink.FAMILY_V6} {
+		list, err := netlink.RouteListFiltered(family, &netlink.Route{Table: syscall.RT_TABLE_MAIN}, netlink.RT_FILTER_TABLE)
+		if err != nil {
+			return nil, fmt.Errorf("list routes: %w", err)
+		}
+
+		for _, route := range list {
+			if _, ok := routingProtocols[route.Protocol]; !ok || route.Dst == nil {
+				continue
+			}
+			addr, ok := netip.AddrFromSlice(route.Dst.IP)
+			if !ok {
+				continue
+			}
+			ones, _ := route.Dst.Mask.Size()
+			routes = append(routes, netip.PrefixFrom(addr.Unmap(), ones))
+		}
+	}
+	return routes, nil
+}
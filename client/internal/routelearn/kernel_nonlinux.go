@@ -0,0 +1,22 @@
+//go:build !linux || android
+
+package routelearn
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"runtime"
+)
+
+// kernelSource isn't implemented outside of Linux
+type kernelSource struct{}
+
+func newKernelSource() *kernelSource {
+	return &kernelSource{}
+}
+
+// Routes returns an error as the kernel routes can't be learned on this platform
+func (s *kernelSource) Routes(context.Context) ([]netip.Prefix, error) {
+	return nil, fmt.Errorf("learning the kernel routes is not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,185 @@
+// Package routelearn learns the internal prefixes of a site from the dynamic routing of its routers, like BGP, so a
+// routing peer advertises them to management as dynamic routes instead of maintaining the static route entries by
+// hand. The routes are read from the kernel routing table populated by a routing daemon or from FRR directly.
+package routelearn
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"slices"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// SourceKernel learns the routes installed in the kernel routing table by a routing daemon
+	SourceKernel = "kernel"
+	// SourceFRR learns the BGP routes from FRR through vtysh
+	SourceFRR = "frr"
+
+	pollInterval = 30 * time.Second
+	pollTimeout  = 10 * time.Second
+
+	// maxRoutes caps the number of advertised routes, it protects management from a full table leaked by a router
+	maxRoutes = 1024
+)
+
+// Source returns the routes learned from the routers of the site
+type Source interface {
+	Routes(ctx context.Context) ([]netip.Prefix, error)
+}
+
+// ChangeFunc is called with the accepted routes when they change
+type ChangeFunc func(routes []netip.Prefix)
+
+// ValidateSource checks that the source name is supported, an empty name disables the route learning
+func ValidateSource(name string) error {
+	switch name {
+	case "", SourceKernel, SourceFRR:
+		return nil
+	default:
+		return fmt.Errorf("unsupported route learning source %q, supported: %s, %s", name, SourceKernel, SourceFRR)
+	}
+}
+
+// NewSource returns the route source by name
+func NewSource(name string) (Source, error) {
+	switch name {
+	case SourceKernel:
+		return newKernelSource(), nil
+	case SourceFRR:
+		return newFRRSource(), nil
+	default:
+		return nil, ValidateSource(name)
+	}
+}
+
+// Learner polls a source and keeps the routes passing the filter
+type Learner struct {
+	mu     sync.RWMutex
+	routes []netip.Prefix
+
+	source   Source
+	filter   *Filter
+	exclude  []netip.Prefix
+	onChange ChangeFunc
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewLearner returns a learner of the routes of the source. The default routes and the routes overlapping the
+// excluded prefixes, like the NetBird network, are never accepted.
+func NewLearner(source Source, filter *Filter, exclude []netip.Prefix, onChange ChangeFunc) *Learner {
+	return &Learner{
+		source:   source,
+		filter:   filter,
+		exclude:  exclude,
+		onChange: onChange,
+	}
+}
+
+// Start polls the source periodically until the context is done or the learner is stopped
+func (l *Learner) Start(ctx context.Context) {
+	ctx, l.cancel = context.WithCancel(ctx)
+	l.done = make(chan struct{})
+
+	go func() {
+		defer close(l.done)
+
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+
+		for {
+			l.poll(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops the polling
+func (l *Learner) Stop() {
+	if l.cancel == nil {
+		return
+	}
+	l.cancel()
+	<-l.done
+}
+
+// Routes returns the accepted routes
+func (l *Learner) Routes() []netip.Prefix {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return slices.Clone(l.routes)
+}
+
+func (l *Learner) poll(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, pollTimeout)
+	defer cancel()
+
+	learned, err := l.source.Routes(ctx)
+	if err != nil {
+		// keep the routes of the last successful poll, a restarting routing daemon shouldn't withdraw them
+		log.Warnf("failed to learn the routes: %v", err)
+		return
+	}
+
+	routes := l.accept(learned)
+
+	l.mu.Lock()
+	if slices.Equal(l.routes, routes) {
+		l.mu.Unlock()
+		return
+	}
+	l.routes = routes
+	l.mu.Unlock()
+
+	log.Infof("learned %d routes", len(routes))
+	if l.onChange != nil {
+		l.onChange(slices.Clone(routes))
+	}
+}
+
+// accept returns the sorted and deduplicated routes passing the filter
+func (l *Learner) accept(learned []netip.Prefix) []netip.Prefix {
+	var routes []netip.Prefix
+	for _, prefix := range learned {
+		prefix = prefix.Masked()
+		if prefix.Bits() == 0 || l.excluded(prefix) || !l.filter.Allow(prefix) {
+			continue
+		}
+		routes = append(routes, prefix)
+	}
+
+	slices.SortFunc(routes, func(a, b netip.Prefix) int {
+		if c := a.Addr().Compare(b.Addr()); c != 0 {
+			return c
+		}
+		return a.Bits() - b.Bits()
+	})
+	routes = slices.Compact(routes)
+
+	if len(routes) > maxRoutes {
+		log.Warnf("learned %d routes, only the first %d are advertised, use the route learning filters to select "+
+			"the internal prefixes", len(routes), maxRoutes)
+		routes = routes[:maxRoutes]
+	}
+	return routes
+}
+
+func (l *Learner) excluded(prefix netip.Prefix) bool {
+	for _, e := range l.exclude {
+		if e.Overlaps(prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package routelearn
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterAllow(t *testing.T) {
+	filter, err := ParseFilter([]string{"!10.99.0.0/16", "10.0.0.0/8 le 24", "fd00::/8"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		prefix string
+		allow  bool
+	}{
+		{"10.0.0.0/8", true},
+		{"10.1.2.0/24", true},
+		{"10.1.2.128/25", false},
+		{"10.99.1.0/24", false},
+		{"192.168.0.0/24", false},
+		{"fd00:1::/64", true},
+		{"2001:db8::/32", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			assert.Equal(t, tt.allow, filter.Allow(netip.MustParsePrefix(tt.prefix)))
+		})
+	}
+
+	empty, err := ParseFilter(nil)
+	require.NoError(t, err)
+	assert.True(t, empty.Allow(netip.MustParsePrefix("192.168.0.0/24")), "an empty filter should accept every prefix")
+}
+
+func TestParseFilterInvalid(t *testing.T) {
+	for _, rule := range []string{"10.0.0.1/8", "10.0.0.0/8 le 4", "10.0.0.0/8 le 33", "10.0.0.0/8 ge 24", "invalid"} {
+		_, err := ParseFilter([]string{rule})
+		assert.Error(t, err, rule)
+	}
+}
+
+func TestParseFRRRoutes(t *testing.T) {
+	data := []byte(`{
+		"10.1.0.0/16": [{"prefix": "10.1.0.0/16", "protocol": "bgp", "selected": true}],
+		"10.2.0.0/16": [{"prefix": "10.2.0.0/16", "protocol": "bgp"}],
+		"10.3.0.0/16": [{"prefix": "10.3.0.0/16", "protocol": "bgp"}, {"prefix": "10.3.0.0/16", "protocol": "bgp", "selected": true}]
+	}`)
+
+	routes, err := parseFRRRoutes(data)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []netip.Prefix{
+		netip.MustParsePrefix("10.1.0.0/16"),
+		netip.MustParsePrefix("10.3.0.0/16"),
+	}, routes)
+}
+
+type staticSource struct {
+	routes []netip.Prefix
+	err    error
+}
+
+func (s *staticSource) Routes(context.Context) ([]netip.Prefix, error) {
+	return s.routes, s.err
+}
+
+func TestLearnerPoll(t *testing.T) {
+	source := &staticSource{routes: []netip.Prefix{
+		netip.MustParsePrefix("10.2.0.0/16"),
+		netip.MustParsePrefix("0.0.0.0/0"),
+		netip.MustParsePrefix("100.64.1.0/24"),
+		netip.MustParsePrefix("10.1.0.0/16"),
+		netip.MustParsePrefix("10.1.0.0/16"),
+	}}
+
+	var changes [][]netip.Prefix
+	l := NewLearner(source, nil, []netip.Prefix{netip.MustParsePrefix("100.64.0.0/10")}, func(routes []netip.Prefix) {
+		changes = append(changes, routes)
+	})
+
+	l.poll(context.Background())
+	expected := []netip.Prefix{netip.MustParsePrefix("10.1.0.0/16"), netip.MustParsePrefix("10.2.0.0/16")}
+	assert.Equal(t, expected, l.Routes())
+	require.Len(t, changes, 1)
+
+	l.poll(context.Background())
+	assert.Len(t, changes, 1, "unchanged routes should not be reported")
+
+	source.err = errors.New("vtysh not running")
+	l.poll(context.Background())
+	assert.Equal(t, expected, l.Routes(), "a failed poll should keep the routes")
+	assert.Len(t, changes, 1)
+
+	source.err = nil
+	source.routes = source.routes[:1]
+	l.poll(context.Background())
+	assert.Equal(t, []netip.Prefix{netip.MustParsePrefix("10.2.0.0/16")}, l.Routes())
+	assert.Len(t, changes, 2)
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/netbirdio/netbird/client/internal/routemanager/refcounter"
 	"github.com/netbirdio/netbird/client/internal/routemanager/static"
 	"github.com/netbirdio/netbird/client/proto"
+	mgmProto "github.com/netbirdio/netbird/management/proto"
 	"github.com/netbirdio/netbird/route"
 )
 
@@ -42,6 +43,8 @@ type routerPeerStatus struct {
 	connected bool
 	relayed   bool
 	latency   time.Duration
+	// draining is set when the routing peer is shutting down and asked to be replaced
+	draining bool
 }
 
 type routesUpdate struct {
@@ -70,6 +73,10 @@ type clientNetwork struct {
 	currentChosen       *route.Route
 	handler             RouteHandler
 	updateSerial        uint64
+	// exitNode is set if the network routes the default route
+	exitNode bool
+	// stopped is closed when the watcher removed the routes of the stopped network
+	stopped chan struct{}
 }
 
 func newClientNetworkWatcher(
@@ -95,6 +102,8 @@ func newClientNetworkWatcher(
 		routePeersNotifiers: make(map[string]chan struct{}),
 		routeUpdate:         make(chan routesUpdate),
 		peerStateUpdate:     make(chan struct{}),
+		exitNode:            !rt.IsDynamic() && rt.Network.Bits() == 0,
+		stopped:             make(chan struct{}),
 		handler: handlerFromRoute(
 			rt,
 			routeRefCounter,
@@ -122,6 +131,7 @@ func (c *clientNetwork) getRouterPeerStatuses() map[route.ID]routerPeerStatus {
 			connected: peerStatus.ConnStatus == peer.StatusConnected,
 			relayed:   peerStatus.Relayed,
 			latency:   peerStatus.Latency,
+			draining:  peerStatus.Draining,
 		}
 	}
 	return routePeerStatuses
@@ -133,6 +143,7 @@ func (c *clientNetwork) getRouterPeerStatuses() map[route.ID]routerPeerStatus {
 //
 // It follows these prioritization rules:
 // * Connected peers: Only routes with connected peers are considered.
+// * Draining peers: Routes of draining peers are only considered if no other connected peer remains.
 // * Metric: Routes with lower metrics (better) are prioritized.
 // * Non-relayed: Routes without relays are preferred.
 // * Latency: Routes with lower latency are prioritized.
@@ -150,10 +161,12 @@ func (c *clientNetwork) getBestRouteFromStatuses(routePeerStatuses map[route.ID]
 		currID = c.currentChosen.ID
 	}
 
+	skipDraining := hasActiveRouterPeer(routePeerStatuses)
+
 	for _, r := range c.routes {
 		tempScore := float64(0)
 		peerStatus, found := routePeerStatuses[r.ID]
-		if !found || !peerStatus.connected {
+		if !found || !peerStatus.connected || (skipDraining && peerStatus.draining) {
 			continue
 		}
 
@@ -223,6 +236,16 @@ func (c *clientNetwork) getBestRouteFromStatuses(routePeerStatuses map[route.ID]
 	return chosen
 }
 
+// hasActiveRouterPeer returns true if a routing peer is connected and not draining
+func hasActiveRouterPeer(routePeerStatuses map[route.ID]routerPeerStatus) bool {
+	for _, status := range routePeerStatuses {
+		if status.connected && !status.draining {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *clientNetwork) watchPeerStatusChanges(ctx context.Context, peerKey string, peerStateUpdate chan struct{}, closer chan struct{}) {
 	for {
 		select {
@@ -357,6 +380,7 @@ func (c *clientNetwork) connectEvent() {
 		meta["id"] = string(c.currentChosen.NetID)
 		meta["peer"] = c.currentChosen.Peer
 	}
+	c.statusRecorder.ClearNotification(mgmProto.NotificationExitNode, c.handler.String())
 	c.statusRecorder.PublishEvent(
 		proto.SystemEvent_INFO,
 		proto.SystemEvent_NETWORK,
@@ -382,6 +406,8 @@ func (c *clientNetwork) disconnectEvent(rsn reason) {
 	var severity proto.SystemEvent_Severity
 	var message string
 	var userMessage string
+	// notify keeps the event as an active notification until the exit node is connected again
+	var notify bool
 	meta := make(map[string]string)
 
 	if c.currentChosen != nil {
@@ -401,12 +427,28 @@ func (c *clientNetwork) disconnectEvent(rsn reason) {
 		severity = proto.SystemEvent_WARNING
 		message = "Default route disconnected due to peer unreachability"
 		userMessage = "Exit node connection lost. Your internet access might be affected."
+		notify = true
 	default:
 		severity = proto.SystemEvent_ERROR
 		message = "Default route disconnected for unknown reasons"
 		userMessage = "Exit node disconnected for unknown reasons."
+		notify = true
+	}
+
+	if notify {
+		c.statusRecorder.PublishNotification(
+			mgmProto.NotificationExitNode,
+			c.handler.String(),
+			severity,
+			proto.SystemEvent_NETWORK,
+			message,
+			userMessage,
+			meta,
+		)
+		return
 	}
 
+	c.statusRecorder.ClearNotification(mgmProto.NotificationExitNode, c.handler.String())
 	c.statusRecorder.PublishEvent(
 		severity,
 		proto.SystemEvent_NETWORK,
@@ -461,6 +503,7 @@ func (c *clientNetwork) peersStateAndUpdateWatcher() {
 			if err := c.removeRouteFromPeerAndSystem(reasonShutdown); err != nil {
 				log.Errorf("Failed to remove routes for [%v]: %v", c.handler, err)
 			}
+			close(c.stopped)
 			return
 		case <-c.peerStateUpdate:
 			err := c.recalculateRouteAndUpdatePeerAndSystem(reasonPeerUpdate)
@@ -0,0 +1,146 @@
+package routemanager
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/client/proto"
+	"github.com/netbirdio/netbird/route"
+	"github.com/netbirdio/netbird/util/errcode"
+)
+
+// localSubnet is the subnet of an address of a local interface
+type localSubnet struct {
+	prefix netip.Prefix
+	intf   string
+}
+
+// subnetConflict is a routed network colliding with a local subnet. The hosts of the overlapping range are reachable
+// either through the routing peer or on the local network, depending on which route is more specific.
+type subnetConflict struct {
+	route  *route.Route
+	subnet localSubnet
+}
+
+func (c subnetConflict) key() string {
+	return string(c.route.GetHAUniqueID()) + "/" + c.subnet.prefix.String()
+}
+
+// getLocalSubnets returns the subnets of the local interfaces except the WireGuard interface, the loopback and the
+// link-local subnets
+func getLocalSubnets(wgIntfName string) ([]localSubnet, error) {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return nil, fmt.Errorf("list interfaces: %w", err)
+	}
+
+	var subnets []localSubnet
+	for _, intf := range interfaces {
+		if intf.Name == wgIntfName || intf.Flags&net.FlagUp == 0 || intf.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+
+		addrs, err := intf.Addrs()
+		if err != nil {
+			log.Debugf("failed to get addresses of interface %s: %v", intf.Name, err)
+			continue
+		}
+
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok {
+				continue
+			}
+			ip, ok := netip.AddrFromSlice(ipNet.IP)
+			if !ok || ip.IsLinkLocalUnicast() {
+				continue
+			}
+			ones, _ := ipNet.Mask.Size()
+			subnets = append(subnets, localSubnet{
+				prefix: netip.PrefixFrom(ip.Unmap(), ones).Masked(),
+				intf:   intf.Name,
+			})
+		}
+	}
+
+	return subnets, nil
+}
+
+// findSubnetConflicts returns the routed networks overlapping a local subnet. The default routes and the dynamic
+// routes aren't checked, an exit node is expected to cover the local subnets.
+func findSubnetConflicts(networks route.HAMap, subnets []localSubnet) []subnetConflict {
+	var conflicts []subnetConflict
+	for _, routes := range networks {
+		if len(routes) == 0 {
+			continue
+		}
+		r := routes[0]
+		if r.IsDynamic() || r.Network.Bits() == 0 {
+			continue
+		}
+
+		for _, subnet := range subnets {
+			if r.Network.Overlaps(subnet.prefix) {
+				conflicts = append(conflicts, subnetConflict{route: r, subnet: subnet})
+			}
+		}
+	}
+
+	slices.SortFunc(conflicts, func(a, b subnetConflict) int {
+		return strings.Compare(a.key(), b.key())
+	})
+
+	return conflicts
+}
+
+// reportSubnetConflicts warns about the routed networks colliding with a local subnet. A conflict is reported once
+// until it is resolved, by a change of the routes or of the local network.
+func (m *DefaultManager) reportSubnetConflicts(networks route.HAMap) {
+	var wgIntfName string
+	if m.wgInterface != nil {
+		wgIntfName = m.wgInterface.Name()
+	}
+
+	subnets, err := getLocalSubnets(wgIntfName)
+	if err != nil {
+		log.Debugf("failed to check the routes for local subnet conflicts: %v", err)
+		return
+	}
+
+	reported := make(map[string]struct{})
+	for _, conflict := range findSubnetConflicts(networks, subnets) {
+		key := conflict.key()
+		reported[key] = struct{}{}
+		if _, ok := m.subnetConflicts[key]; ok {
+			continue
+		}
+
+		r := conflict.route
+		log.Warnf("network %s (%s) overlaps the local subnet %s of interface %s", r.NetID, r.Network, conflict.subnet.prefix, conflict.subnet.intf)
+		if m.statusRecorder == nil {
+			continue
+		}
+		m.statusRecorder.PublishErrorEvent(
+			proto.SystemEvent_WARNING,
+			proto.SystemEvent_NETWORK,
+			errcode.RouteConflict,
+			"Routed network overlaps local subnet",
+			fmt.Sprintf("The network %s (%s) overlaps the local subnet %s of interface %s. Some hosts may be unreachable "+
+				"either through NetBird or on the local network. Ask your administrator to change the route or deselect "+
+				"the network with 'netbird networks deselect %s'.", r.NetID, r.Network, conflict.subnet.prefix, conflict.subnet.intf, r.NetID),
+			map[string]string{
+				"id":        string(r.NetID),
+				"network":   r.Network.String(),
+				"subnet":    conflict.subnet.prefix.String(),
+				"interface": conflict.subnet.intf,
+			},
+		)
+	}
+
+	m.subnetConflicts = reported
+}
@@ -0,0 +1,44 @@
+package routemanager
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/management/domain"
+	"github.com/netbirdio/netbird/route"
+)
+
+func TestFindSubnetConflicts(t *testing.T) {
+	office := &route.Route{NetID: "office", Network: netip.MustParsePrefix("192.168.1.0/24")}
+	lab := &route.Route{NetID: "lab", Network: netip.MustParsePrefix("10.20.0.0/16")}
+	datacenter := &route.Route{NetID: "datacenter", Network: netip.MustParsePrefix("172.16.0.0/12")}
+	exit := &route.Route{NetID: "exit", Network: netip.MustParsePrefix("0.0.0.0/0")}
+	dynamic := &route.Route{
+		NetID:       "dynamic",
+		Network:     netip.MustParsePrefix("192.0.2.0/32"),
+		NetworkType: route.DomainNetwork,
+		Domains:     domain.List{"example.com"},
+	}
+
+	networks := route.HAMap{}
+	for _, r := range []*route.Route{office, lab, datacenter, exit, dynamic} {
+		networks[r.GetHAUniqueID()] = []*route.Route{r}
+	}
+
+	subnets := []localSubnet{
+		{prefix: netip.MustParsePrefix("192.168.0.0/16"), intf: "eth0"},
+		{prefix: netip.MustParsePrefix("172.17.0.0/16"), intf: "docker0"},
+		{prefix: netip.MustParsePrefix("192.0.2.0/24"), intf: "eth1"},
+	}
+
+	conflicts := findSubnetConflicts(networks, subnets)
+	require.Len(t, conflicts, 2, "only the network routes overlapping a local subnet should conflict")
+
+	assert.Equal(t, datacenter, conflicts[0].route, "a routed network containing a local subnet should conflict")
+	assert.Equal(t, "docker0", conflicts[0].subnet.intf)
+	assert.Equal(t, office, conflicts[1].route, "a routed network within a local subnet should conflict")
+	assert.Equal(t, "eth0", conflicts[1].subnet.intf)
+}
@@ -0,0 +1,181 @@
+package routemanager
+
+import (
+	"context"
+	"net/netip"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/client/internal/peer"
+	"github.com/netbirdio/netbird/client/internal/probe"
+	"github.com/netbirdio/netbird/client/internal/routemanager/exitnode"
+	"github.com/netbirdio/netbird/route"
+)
+
+const (
+	// exitNodeProbes is the number of pings sent to each routing peer of an exit node per measurement round
+	exitNodeProbes = 5
+	// exitNodeProbeTimeout is the time a ping waits for the reply before it's counted as lost
+	exitNodeProbeTimeout = time.Second
+	// exitNodeStopTimeout limits the wait for the routes of the previous exit node to be removed
+	exitNodeStopTimeout = 5 * time.Second
+	// unknownLatency is used for the connected routing peers without a measured latency
+	unknownLatency = 999 * time.Millisecond
+)
+
+// measureExitNode pings the routing peers of the exit node network through the tunnel and returns the measurement of
+// the best one. The routing peers not answering the pings are measured by the latency of the peer connection.
+func (m *DefaultManager) measureExitNode(ctx context.Context, candidate exitnode.Candidate) exitnode.Measurement {
+	best := exitnode.Measurement{Loss: 1}
+	for _, peerKey := range candidate.Peers {
+		state, err := m.statusRecorder.GetPeer(peerKey)
+		if err != nil || state.ConnStatus != peer.StatusConnected {
+			continue
+		}
+
+		measurement := measureRoutingPeer(ctx, state)
+		if !best.Reachable() || measurement.Score() < best.Score() {
+			best = measurement
+		}
+	}
+	return best
+}
+
+func measureRoutingPeer(ctx context.Context, state peer.State) exitnode.Measurement {
+	connLatency := state.Latency
+	if connLatency == 0 {
+		connLatency = unknownLatency
+	}
+
+	address, err := netip.ParseAddr(state.IP)
+	if err != nil {
+		log.Debugf("measuring exit node peer %s by the connection latency, invalid IP %q", state.FQDN, state.IP)
+		return exitnode.Measurement{Latency: connLatency}
+	}
+
+	var total time.Duration
+	var replies int
+	for i := 0; i < exitNodeProbes && ctx.Err() == nil; i++ {
+		probeCtx, cancel := context.WithTimeout(ctx, exitNodeProbeTimeout)
+		latency, err := probe.Ping(probeCtx, address)
+		cancel()
+		if err != nil {
+			log.Tracef("ping to exit node peer %s failed: %v", state.FQDN, err)
+			continue
+		}
+		total += latency
+		replies++
+	}
+
+	if replies == 0 {
+		// the peer is connected, it likely doesn't allow the pings
+		return exitnode.Measurement{Latency: connLatency}
+	}
+
+	return exitnode.Measurement{
+		Latency: total / time.Duration(replies),
+		Loss:    float64(exitNodeProbes-replies) / exitNodeProbes,
+	}
+}
+
+// onExitNodeRound reports the chosen exit node and applies the selection if another one was chosen
+func (m *DefaultManager) onExitNodeRound(choice exitnode.Choice, switched bool) {
+	if !m.routeSelector.IsExitNodeAuto() {
+		return
+	}
+
+	m.updateExitNodeState(choice)
+
+	if switched {
+		m.pinExitNodeFlows()
+		m.TriggerSelection(m.GetClientRoutes())
+	}
+}
+
+// initPinner pins the destinations through the allowed IPs of the routing peers, so they're removed with the routes
+func (m *DefaultManager) initPinner() *exitnode.Pinner {
+	return exitnode.NewPinner(
+		m.exitNodeStickyGrace,
+		func(prefix netip.Prefix, peerKey string) error {
+			_, err := m.allowedIPsRefCounter.Increment(prefix, peerKey)
+			return err
+		},
+		func(prefix netip.Prefix) error {
+			_, err := m.allowedIPsRefCounter.Decrement(prefix)
+			return err
+		},
+	)
+}
+
+// pinExitNodeFlows keeps the destinations of the established flows on the routing peer of the previous exit node
+func (m *DefaultManager) pinExitNodeFlows() {
+	if m.exitNodePinner == nil {
+		return
+	}
+
+	peerKey := m.exitNodePeer()
+	if peerKey == "" {
+		return
+	}
+
+	address := m.wgInterface.Address()
+	source, ok := netip.AddrFromSlice(address.IP)
+	if !ok {
+		return
+	}
+	network, err := netip.ParsePrefix(address.Network.String())
+	if err != nil {
+		log.Debugf("failed to parse the network %s: %v", address.Network, err)
+		return
+	}
+
+	destinations, err := establishedDestinations(source.Unmap(), network.Masked())
+	if err != nil {
+		log.Warnf("failed to list the established flows through the exit node: %v", err)
+		return
+	}
+
+	if pinned := m.exitNodePinner.Pin(peerKey, destinations); pinned > 0 {
+		log.Infof("keeping %d destinations on the previous exit node for %s", pinned, m.exitNodeStickyGrace)
+	}
+}
+
+// exitNodePeer returns the routing peer the default route is currently routed through
+func (m *DefaultManager) exitNodePeer() string {
+	for _, state := range m.statusRecorder.GetFullStatus().Peers {
+		for network := range state.GetRoutes() {
+			prefix, err := netip.ParsePrefix(network)
+			if err == nil && prefix.Bits() == 0 {
+				return state.PubKey
+			}
+		}
+	}
+	return ""
+}
+
+// filterExitNodes keeps the exit node chosen automatically, if the automatic selection is on
+func (m *DefaultManager) filterExitNodes(networks route.HAMap) route.HAMap {
+	if m.exitNodeSelector == nil {
+		return networks
+	}
+
+	if !m.routeSelector.IsExitNodeAuto() {
+		m.exitNodeSelector.Reset()
+		m.statusRecorder.UpdateExitNodeState(peer.ExitNodeState{})
+		return networks
+	}
+
+	networks = m.exitNodeSelector.Filter(networks)
+	m.updateExitNodeState(m.exitNodeSelector.Current())
+	return networks
+}
+
+func (m *DefaultManager) updateExitNodeState(choice exitnode.Choice) {
+	m.statusRecorder.UpdateExitNodeState(peer.ExitNodeState{
+		Auto:    true,
+		NetID:   string(choice.NetID),
+		Latency: choice.Latency,
+		Loss:    choice.Loss,
+	})
+}
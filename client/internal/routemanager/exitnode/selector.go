@@ -0,0 +1,314 @@
+package exitnode
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/exp/maps"
+
+	"github.com/netbirdio/netbird/route"
+)
+
+const (
+	// Interval is the time between the measurement rounds
+	Interval = 30 * time.Second
+	// switchRounds is the number of consecutive rounds a better exit node must win before it's chosen
+	switchRounds = 3
+	// minImprovement is the smallest score improvement worth switching the exit node for
+	minImprovement = 20 * time.Millisecond
+	// relativeImprovement is the smallest improvement relative to the score of the current exit node
+	relativeImprovement = 0.2
+	// lossPenalty is the latency added to the score for the full loss, 10% loss weighs like 100ms
+	lossPenalty = time.Second
+	// smoothing is the weight of the latest measurement in the smoothed measurement
+	smoothing = 0.5
+)
+
+// Measurement is the round-trip time and loss measured to an exit node
+type Measurement struct {
+	Latency time.Duration
+	// Loss is the ratio of the probes without reply, 1 if the exit node is unreachable
+	Loss float64
+}
+
+// Score returns the latency penalized by the loss, lower is better
+func (m Measurement) Score() time.Duration {
+	return m.Latency + time.Duration(m.Loss*float64(lossPenalty))
+}
+
+// Reachable returns false if no probe got a reply
+func (m Measurement) Reachable() bool {
+	return m.Loss < 1
+}
+
+// Candidate is an exit node network with its routing peers
+type Candidate struct {
+	NetID route.NetID
+	// Peers are the WireGuard public keys of the routing peers of the network
+	Peers []string
+}
+
+// MeasureFunc measures an exit node network, the measurement of its best routing peer is used
+type MeasureFunc func(ctx context.Context, candidate Candidate) Measurement
+
+// Choice is the exit node chosen by the selector
+type Choice struct {
+	NetID route.NetID
+	// Measurement is the smoothed measurement of the exit node, it's zero until the first round completed
+	Measurement
+}
+
+// RoundFunc is called after each measurement round, switched is set if another exit node was chosen
+type RoundFunc func(choice Choice, switched bool)
+
+// Selector chooses the exit node with the lowest latency and loss among the available exit node networks. It
+// switches to a better exit node only if it's better by a margin for several rounds, an unreachable exit node is
+// replaced right away.
+type Selector struct {
+	mu         sync.Mutex
+	measure    MeasureFunc
+	onRound    RoundFunc
+	candidates map[route.NetID]Candidate
+	// measurements are the smoothed measurements of the candidates
+	measurements map[route.NetID]Measurement
+	current      route.NetID
+	// measured is false until the current exit node was chosen by a measurement
+	measured bool
+	// better is the exit node winning against the current one for betterRounds consecutive rounds
+	better       route.NetID
+	betterRounds int
+	trigger      chan struct{}
+}
+
+// NewSelector creates a selector measuring the exit nodes with the measure function
+func NewSelector(measure MeasureFunc, onRound RoundFunc) *Selector {
+	return &Selector{
+		measure:      measure,
+		onRound:      onRound,
+		candidates:   make(map[route.NetID]Candidate),
+		measurements: make(map[route.NetID]Measurement),
+		trigger:      make(chan struct{}, 1),
+	}
+}
+
+// IsExitNode returns true if the routes of the network route the default route
+func IsExitNode(routes []*route.Route) bool {
+	for _, r := range routes {
+		if !r.IsDynamic() && r.Network.Bits() == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Start runs the measurement rounds until the context is done
+func (s *Selector) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-s.trigger:
+			}
+			s.round(ctx)
+		}
+	}()
+}
+
+// Filter updates the candidates from the exit node networks and removes the exit node networks except the chosen
+// one. Until the candidates are measured, the first network by its ID is chosen.
+func (s *Selector) Filter(networks route.HAMap) route.HAMap {
+	candidates := make(map[route.NetID]Candidate)
+	for id, routes := range networks {
+		if !IsExitNode(routes) {
+			continue
+		}
+		candidate := candidates[id.NetID()]
+		candidate.NetID = id.NetID()
+		for _, r := range routes {
+			if !slices.Contains(candidate.Peers, r.Peer) {
+				candidate.Peers = append(candidate.Peers, r.Peer)
+			}
+		}
+		candidates[id.NetID()] = candidate
+	}
+
+	s.mu.Lock()
+	s.candidates = candidates
+	for id := range s.measurements {
+		if _, ok := candidates[id]; !ok {
+			delete(s.measurements, id)
+		}
+	}
+	if _, ok := candidates[s.current]; !ok {
+		s.chooseInitial()
+	}
+	current := s.current
+	s.mu.Unlock()
+
+	filtered := make(route.HAMap, len(networks))
+	for id, routes := range networks {
+		if IsExitNode(routes) && id.NetID() != current {
+			continue
+		}
+		filtered[id] = routes
+	}
+	return filtered
+}
+
+// Reset removes the candidates and their measurements, the selector doesn't measure until the next Filter
+func (s *Selector) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.candidates = make(map[route.NetID]Candidate)
+	s.measurements = make(map[route.NetID]Measurement)
+	s.current = ""
+	s.measured = false
+	s.better, s.betterRounds = "", 0
+}
+
+// Current returns the chosen exit node
+func (s *Selector) Current() Choice {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return Choice{NetID: s.current, Measurement: s.measurements[s.current]}
+}
+
+// chooseInitial replaces the current exit node that isn't available anymore with the best measured one, or the
+// first one by its ID before the measurement
+func (s *Selector) chooseInitial() {
+	s.better, s.betterRounds = "", 0
+	s.current = s.best()
+	s.measured = s.current != ""
+	if s.current != "" || len(s.candidates) == 0 {
+		return
+	}
+
+	ids := maps.Keys(s.candidates)
+	slices.Sort(ids)
+	s.current = ids[0]
+
+	select {
+	case s.trigger <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Selector) round(ctx context.Context) {
+	s.mu.Lock()
+	candidates := maps.Values(s.candidates)
+	s.mu.Unlock()
+
+	if len(candidates) == 0 {
+		return
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	results := make(map[route.NetID]Measurement, len(candidates))
+	for _, candidate := range candidates {
+		wg.Add(1)
+		go func(candidate Candidate) {
+			defer wg.Done()
+			measurement := s.measure(ctx, candidate)
+			mu.Lock()
+			results[candidate.NetID] = measurement
+			mu.Unlock()
+		}(candidate)
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	s.mu.Lock()
+	switched := s.update(results)
+	choice := Choice{NetID: s.current, Measurement: s.measurements[s.current]}
+	s.mu.Unlock()
+
+	if s.onRound != nil {
+		s.onRound(choice, switched)
+	}
+}
+
+// update smooths the measurements of the round and returns true if another exit node was chosen
+func (s *Selector) update(results map[route.NetID]Measurement) bool {
+	for id, measurement := range results {
+		if _, ok := s.candidates[id]; !ok {
+			continue
+		}
+		previous, ok := s.measurements[id]
+		if !ok || !measurement.Reachable() || !previous.Reachable() {
+			s.measurements[id] = measurement
+			continue
+		}
+		s.measurements[id] = Measurement{
+			Latency: time.Duration(smoothing*float64(measurement.Latency) + (1-smoothing)*float64(previous.Latency)),
+			Loss:    smoothing*measurement.Loss + (1-smoothing)*previous.Loss,
+		}
+	}
+
+	best := s.best()
+	if best == "" || best == s.current {
+		s.better, s.betterRounds = "", 0
+		s.measured = s.measured || best != ""
+		return false
+	}
+
+	current, ok := s.measurements[s.current]
+	if s.measured && ok && current.Reachable() {
+		if !improves(current.Score(), s.measurements[best].Score()) {
+			s.better, s.betterRounds = "", 0
+			return false
+		}
+		if s.better != best {
+			s.better, s.betterRounds = best, 0
+		}
+		s.betterRounds++
+		if s.betterRounds < switchRounds {
+			return false
+		}
+	}
+
+	log.Infof("switching the exit node from %s to %s: latency %s, loss %.0f%%",
+		s.current, best, s.measurements[best].Latency, s.measurements[best].Loss*100)
+
+	s.current = best
+	s.measured = true
+	s.better, s.betterRounds = "", 0
+	return true
+}
+
+// best returns the reachable candidate with the lowest score, the lowest ID on equal scores
+func (s *Selector) best() route.NetID {
+	var best route.NetID
+	for id, measurement := range s.measurements {
+		if !measurement.Reachable() {
+			continue
+		}
+		if best == "" {
+			best = id
+			continue
+		}
+		score, bestScore := measurement.Score(), s.measurements[best].Score()
+		if score < bestScore || (score == bestScore && id < best) {
+			best = id
+		}
+	}
+	return best
+}
+
+func improves(current, candidate time.Duration) bool {
+	improvement := current - candidate
+	return improvement >= minImprovement && float64(improvement) >= relativeImprovement*float64(current)
+}
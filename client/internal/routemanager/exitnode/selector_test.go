@@ -0,0 +1,116 @@
+package exitnode
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/route"
+)
+
+func newRoute(netID, network, peer string) *route.Route {
+	return &route.Route{
+		ID:      route.ID(netID + "-" + peer),
+		NetID:   route.NetID(netID),
+		Network: netip.MustParsePrefix(network),
+		Peer:    peer,
+	}
+}
+
+func toHAMap(routes ...*route.Route) route.HAMap {
+	networks := make(route.HAMap)
+	for _, r := range routes {
+		networks[r.GetHAUniqueID()] = append(networks[r.GetHAUniqueID()], r)
+	}
+	return networks
+}
+
+func netIDs(networks route.HAMap) []route.NetID {
+	var ids []route.NetID
+	for id := range networks {
+		ids = append(ids, id.NetID())
+	}
+	return ids
+}
+
+func TestSelector_Filter(t *testing.T) {
+	s := NewSelector(nil, nil)
+
+	networks := toHAMap(
+		newRoute("exit-b", "0.0.0.0/0", "peer-b"),
+		newRoute("exit-a", "0.0.0.0/0", "peer-a1"),
+		newRoute("exit-a", "0.0.0.0/0", "peer-a2"),
+		newRoute("lan", "10.0.0.0/24", "peer-a1"),
+	)
+
+	filtered := s.Filter(networks)
+	assert.ElementsMatch(t, []route.NetID{"exit-a", "lan"}, netIDs(filtered),
+		"the first exit node should be chosen until they're measured")
+	assert.ElementsMatch(t, []string{"peer-a1", "peer-a2"}, s.candidates["exit-a"].Peers)
+
+	require.True(t, s.update(map[route.NetID]Measurement{
+		"exit-a": {Latency: 80 * time.Millisecond},
+		"exit-b": {Latency: 30 * time.Millisecond},
+	}), "the first measurement should choose the best exit node right away")
+	assert.ElementsMatch(t, []route.NetID{"exit-b", "lan"}, netIDs(s.Filter(networks)))
+
+	delete(networks, newRoute("exit-b", "0.0.0.0/0", "peer-b").GetHAUniqueID())
+	assert.ElementsMatch(t, []route.NetID{"exit-a", "lan"}, netIDs(s.Filter(networks)),
+		"a removed exit node should be replaced by the best measured one")
+	assert.Equal(t, route.NetID("exit-a"), s.Current().NetID)
+}
+
+func TestSelector_Hysteresis(t *testing.T) {
+	s := NewSelector(nil, nil)
+	s.Filter(toHAMap(
+		newRoute("exit-a", "0.0.0.0/0", "peer-a"),
+		newRoute("exit-b", "0.0.0.0/0", "peer-b"),
+	))
+
+	require.False(t, s.update(map[route.NetID]Measurement{
+		"exit-a": {Latency: 50 * time.Millisecond},
+		"exit-b": {Latency: 60 * time.Millisecond},
+	}))
+	require.Equal(t, route.NetID("exit-a"), s.Current().NetID)
+
+	// exit-b is slightly better, not worth switching
+	for i := 0; i < 5; i++ {
+		assert.False(t, s.update(map[route.NetID]Measurement{
+			"exit-a": {Latency: 50 * time.Millisecond},
+			"exit-b": {Latency: 45 * time.Millisecond},
+		}))
+	}
+	assert.Equal(t, route.NetID("exit-a"), s.Current().NetID)
+
+	// exit-a degrades, exit-b has to stay better for several rounds
+	degraded := map[route.NetID]Measurement{
+		"exit-a": {Latency: 50 * time.Millisecond, Loss: 0.2},
+		"exit-b": {Latency: 45 * time.Millisecond},
+	}
+	var switched bool
+	rounds := 0
+	for !switched && rounds < 10 {
+		switched = s.update(degraded)
+		rounds++
+	}
+	assert.True(t, switched)
+	assert.Equal(t, switchRounds, rounds)
+	assert.Equal(t, route.NetID("exit-b"), s.Current().NetID)
+
+	// an unreachable exit node is replaced right away
+	assert.True(t, s.update(map[route.NetID]Measurement{
+		"exit-a": {Latency: 100 * time.Millisecond},
+		"exit-b": {Loss: 1},
+	}))
+	assert.Equal(t, route.NetID("exit-a"), s.Current().NetID)
+
+	// no exit node is reachable, the current one is kept
+	assert.False(t, s.update(map[route.NetID]Measurement{
+		"exit-a": {Loss: 1},
+		"exit-b": {Loss: 1},
+	}))
+	assert.Equal(t, route.NetID("exit-a"), s.Current().NetID)
+}
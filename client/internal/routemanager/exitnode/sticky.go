@@ -0,0 +1,97 @@
+package exitnode
+
+import (
+	"net/netip"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// maxPinnedDestinations limits the number of destinations kept on the previous exit nodes
+const maxPinnedDestinations = 1024
+
+// PinFunc routes the destination prefix through the routing peer
+type PinFunc func(prefix netip.Prefix, peerKey string) error
+
+// UnpinFunc removes the destination prefix added by the PinFunc
+type UnpinFunc func(prefix netip.Prefix) error
+
+// Pinner keeps the destinations of the established flows on the previous exit node for a grace period after the
+// automatic selection switched the exit node. The destinations are routed as host prefixes that are more specific
+// than the default route of the new exit node.
+type Pinner struct {
+	mu     sync.Mutex
+	grace  time.Duration
+	pin    PinFunc
+	unpin  UnpinFunc
+	timers map[netip.Prefix]*time.Timer
+}
+
+// NewPinner creates a pinner keeping the destinations for the grace period
+func NewPinner(grace time.Duration, pin PinFunc, unpin UnpinFunc) *Pinner {
+	return &Pinner{
+		grace:  grace,
+		pin:    pin,
+		unpin:  unpin,
+		timers: make(map[netip.Prefix]*time.Timer),
+	}
+}
+
+// Pin routes the destinations through the routing peer until the grace period elapsed and returns the number of the
+// pinned destinations. The destinations pinned by an earlier switch stay on their exit node.
+func (p *Pinner) Pin(peerKey string, destinations []netip.Addr) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var pinned int
+	for _, destination := range destinations {
+		if len(p.timers) >= maxPinnedDestinations {
+			log.Warnf("not keeping more than %d destinations on the previous exit node", maxPinnedDestinations)
+			break
+		}
+
+		prefix := netip.PrefixFrom(destination.Unmap(), destination.Unmap().BitLen())
+		if _, ok := p.timers[prefix]; ok {
+			continue
+		}
+		if err := p.pin(prefix, peerKey); err != nil {
+			log.Errorf("failed to keep %s on the previous exit node: %v", prefix, err)
+			continue
+		}
+
+		p.timers[prefix] = time.AfterFunc(p.grace, func() {
+			p.expire(prefix)
+		})
+		pinned++
+	}
+	return pinned
+}
+
+// Stop removes all pinned destinations
+func (p *Pinner) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for prefix, timer := range p.timers {
+		timer.Stop()
+		p.remove(prefix)
+	}
+}
+
+func (p *Pinner) expire(prefix netip.Prefix) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.timers[prefix]; !ok {
+		return
+	}
+	p.remove(prefix)
+}
+
+func (p *Pinner) remove(prefix netip.Prefix) {
+	delete(p.timers, prefix)
+	if err := p.unpin(prefix); err != nil {
+		log.Errorf("failed to remove %s from the previous exit node: %v", prefix, err)
+	}
+}
@@ -0,0 +1,57 @@
+package exitnode
+
+import (
+	"net/netip"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPinner(t *testing.T) {
+	var mu sync.Mutex
+	pinned := make(map[netip.Prefix]string)
+	get := func() map[netip.Prefix]string {
+		mu.Lock()
+		defer mu.Unlock()
+		result := make(map[netip.Prefix]string, len(pinned))
+		for prefix, peerKey := range pinned {
+			result[prefix] = peerKey
+		}
+		return result
+	}
+
+	p := NewPinner(100*time.Millisecond,
+		func(prefix netip.Prefix, peerKey string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			pinned[prefix] = peerKey
+			return nil
+		},
+		func(prefix netip.Prefix) error {
+			mu.Lock()
+			defer mu.Unlock()
+			delete(pinned, prefix)
+			return nil
+		},
+	)
+
+	first := netip.MustParseAddr("192.0.2.1")
+	second := netip.MustParseAddr("2001:db8::1")
+	assert.Equal(t, 2, p.Pin("peer-a", []netip.Addr{first, second}))
+	assert.Equal(t, map[netip.Prefix]string{
+		netip.MustParsePrefix("192.0.2.1/32"):    "peer-a",
+		netip.MustParsePrefix("2001:db8::1/128"): "peer-a",
+	}, get())
+
+	assert.Equal(t, 0, p.Pin("peer-b", []netip.Addr{first}), "a pinned destination should stay on its exit node")
+	assert.Equal(t, "peer-a", get()[netip.MustParsePrefix("192.0.2.1/32")])
+
+	assert.Eventually(t, func() bool { return len(get()) == 0 }, time.Second, 10*time.Millisecond,
+		"the destinations should be removed after the grace period")
+
+	assert.Equal(t, 1, p.Pin("peer-b", []netip.Addr{first}))
+	p.Stop()
+	assert.Empty(t, get(), "stop should remove the pinned destinations")
+}
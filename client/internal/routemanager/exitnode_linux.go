@@ -0,0 +1,49 @@
+//go:build linux && !android
+
+package routemanager
+
+import (
+	"fmt"
+	"net/netip"
+
+	log "github.com/sirupsen/logrus"
+	nfct "github.com/ti-mo/conntrack"
+)
+
+// establishedDestinations returns the destinations of the flows originated from the source address that saw a reply,
+// the destinations within the excluded network are skipped
+func establishedDestinations(source netip.Addr, exclude netip.Prefix) ([]netip.Addr, error) {
+	conn, err := nfct.Dial(nil)
+	if err != nil {
+		return nil, fmt.Errorf("dial conntrack: %w", err)
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			log.Debugf("failed to close conntrack connection: %v", err)
+		}
+	}()
+
+	flows, err := conn.Dump(nil)
+	if err != nil {
+		return nil, fmt.Errorf("dump conntrack: %w", err)
+	}
+
+	seen := make(map[netip.Addr]struct{})
+	var destinations []netip.Addr
+	for _, flow := range flows {
+		if !flow.Status.SeenReply() || flow.TupleOrig.IP.SourceAddress.Unmap() != source {
+			continue
+		}
+
+		destination := flow.TupleOrig.IP.DestinationAddress.Unmap()
+		if exclude.Contains(destination) {
+			continue
+		}
+		if _, ok := seen[destination]; ok {
+			continue
+		}
+		seen[destination] = struct{}{}
+		destinations = append(destinations, destination)
+	}
+	return destinations, nil
+}
@@ -0,0 +1,10 @@
+//go:build !linux || android
+
+package routemanager
+
+import "net/netip"
+
+// establishedDestinations isn't supported on this platform, the flows switch to the new exit node right away
+func establishedDestinations(netip.Addr, netip.Prefix) ([]netip.Addr, error) {
+	return nil, nil
+}
@@ -21,13 +21,18 @@ import (
 	"github.com/netbirdio/netbird/client/internal/listener"
 	"github.com/netbirdio/netbird/client/internal/peer"
 	"github.com/netbirdio/netbird/client/internal/peerstore"
+	"github.com/netbirdio/netbird/client/internal/routeapproval"
+	"github.com/netbirdio/netbird/client/internal/routemanager/exitnode"
 	"github.com/netbirdio/netbird/client/internal/routemanager/iface"
 	"github.com/netbirdio/netbird/client/internal/routemanager/notifier"
 	"github.com/netbirdio/netbird/client/internal/routemanager/refcounter"
+	"github.com/netbirdio/netbird/client/internal/routemanager/splittunnel"
 	"github.com/netbirdio/netbird/client/internal/routemanager/systemops"
 	"github.com/netbirdio/netbird/client/internal/routemanager/vars"
 	"github.com/netbirdio/netbird/client/internal/routeselector"
 	"github.com/netbirdio/netbird/client/internal/statemanager"
+	"github.com/netbirdio/netbird/client/proto"
+	mgmProto "github.com/netbirdio/netbird/management/proto"
 	relayClient "github.com/netbirdio/netbird/relay/client"
 	"github.com/netbirdio/netbird/route"
 	nbnet "github.com/netbirdio/netbird/util/net"
@@ -40,11 +45,13 @@ type Manager interface {
 	UpdateRoutes(updateSerial uint64, newRoutes []*route.Route, useNewDNSRoute bool) error
 	TriggerSelection(route.HAMap)
 	GetRouteSelector() *routeselector.RouteSelector
+	GetRouteApprover() *routeapproval.RouteApprover
 	GetClientRoutes() route.HAMap
 	GetClientRoutesWithNetID() map[route.NetID][]*route.Route
 	SetRouteChangeListener(listener listener.NetworkChangeListener)
 	InitialRouteRange() []string
 	EnableServerRouter(firewall firewall.Manager) error
+	DrainServerRoutes() (bool, error)
 	Stop(stateManager *statemanager.Manager)
 }
 
@@ -61,15 +68,29 @@ type ManagerConfig struct {
 	PeerStore           *peerstore.Store
 	DisableClientRoutes bool
 	DisableServerRoutes bool
+	SplitTunnel         splittunnel.Config
+	// RequireRouteApproval holds back the high-risk routes until the local user approves them
+	RequireRouteApproval bool
+	// ExitNodeStickyGrace keeps the established flows on the previous exit node for this long after the automatic
+	// selection switched the exit node, 0 switches them right away
+	ExitNodeStickyGrace time.Duration
 }
 
 // DefaultManager is the default instance of a route manager
 type DefaultManager struct {
-	ctx                  context.Context
-	stop                 context.CancelFunc
-	mux                  sync.Mutex
-	clientNetworks       map[route.HAUniqueID]*clientNetwork
-	routeSelector        *routeselector.RouteSelector
+	ctx            context.Context
+	stop           context.CancelFunc
+	mux            sync.Mutex
+	clientNetworks map[route.HAUniqueID]*clientNetwork
+	routeSelector  *routeselector.RouteSelector
+	// exitNodeSelector chooses the exit node in the automatic exit node selection
+	exitNodeSelector *exitnode.Selector
+	// exitNodePinner is nil if the established flows switch to the new exit node right away
+	exitNodePinner      *exitnode.Pinner
+	exitNodeStickyGrace time.Duration
+	// routeApprover is nil if the routes don't require approval
+	routeApprover        *routeapproval.RouteApprover
+	requireRouteApproval bool
 	serverRouter         *serverRouter
 	sysOps               *systemops.SysOps
 	statusRecorder       *peer.Status
@@ -88,6 +109,9 @@ type DefaultManager struct {
 	useNewDNSRoute      bool
 	disableClientRoutes bool
 	disableServerRoutes bool
+	splitTunnel         *splittunnel.Manager
+	// subnetConflicts are the keys of the reported conflicts of the routed networks with the local subnets
+	subnetConflicts map[string]struct{}
 }
 
 func NewManager(config ManagerConfig) *DefaultManager {
@@ -111,10 +135,17 @@ func NewManager(config ManagerConfig) *DefaultManager {
 		peerStore:           config.PeerStore,
 		disableClientRoutes: config.DisableClientRoutes,
 		disableServerRoutes: config.DisableServerRoutes,
+
+		requireRouteApproval: config.RequireRouteApproval,
+		exitNodeStickyGrace:  config.ExitNodeStickyGrace,
+	}
+
+	if config.SplitTunnel.Enabled() {
+		dm.splitTunnel = splittunnel.New(config.SplitTunnel, config.WGInterface)
+		sysOps.SetSplitTunnel(true)
 	}
 
-	useNoop := netstack.IsEnabled() || config.DisableClientRoutes
-	dm.setupRefCounters(useNoop)
+	dm.setupRefCounters(config.DisableClientRoutes)
 
 	// don't proceed with client routes if it is disabled
 	if config.DisableClientRoutes {
@@ -138,6 +169,24 @@ func (m *DefaultManager) setupRefCounters(useNoop bool) {
 		},
 	)
 
+	if netstack.IsEnabled() && !useNoop {
+		// there is no routing table to program in netstack mode, the routes are handled inside the netstack
+		if routes := m.netstackRoutes(); routes == nil {
+			useNoop = true
+		} else {
+			m.routeRefCounter = refcounter.New(
+				func(prefix netip.Prefix, _ struct{}) (struct{}, error) {
+					routes.AddRoute(prefix)
+					return struct{}{}, nil
+				},
+				func(prefix netip.Prefix, _ struct{}) error {
+					routes.RemoveRoute(prefix)
+					return nil
+				},
+			)
+		}
+	}
+
 	if useNoop {
 		m.routeRefCounter = refcounter.New(
 			func(netip.Prefix, struct{}) (struct{}, error) {
@@ -166,9 +215,26 @@ func (m *DefaultManager) setupRefCounters(useNoop bool) {
 	)
 }
 
+// netstackRoutes returns the route table of the netstack device, nil if the interface has none
+func (m *DefaultManager) netstackRoutes() *netstack.RouteTable {
+	ns, ok := m.wgInterface.(interface{ GetNetstackRoutes() *netstack.RouteTable })
+	if !ok {
+		return nil
+	}
+	return ns.GetNetstackRoutes()
+}
+
 // Init sets up the routing
 func (m *DefaultManager) Init() (nbnet.AddHookFunc, nbnet.RemoveHookFunc, error) {
 	m.routeSelector = m.initSelector()
+	if m.requireRouteApproval {
+		m.routeApprover = m.initApprover()
+	}
+	m.exitNodeSelector = exitnode.NewSelector(m.measureExitNode, m.onExitNodeRound)
+	m.exitNodeSelector.Start(m.ctx)
+	if m.exitNodeStickyGrace > 0 {
+		m.exitNodePinner = m.initPinner()
+	}
 
 	if nbnet.CustomRoutingDisabled() || m.disableClientRoutes {
 		return nil, nil, nil
@@ -190,6 +256,12 @@ func (m *DefaultManager) Init() (nbnet.AddHookFunc, nbnet.RemoveHookFunc, error)
 		return nil, nil, fmt.Errorf("setup routing: %w", err)
 	}
 
+	if m.splitTunnel != nil {
+		if err := m.splitTunnel.Start(m.ctx); err != nil {
+			return nil, nil, fmt.Errorf("start split tunneling: %w", err)
+		}
+	}
+
 	log.Info("Routing setup complete")
 	return beforePeerHook, afterPeerHook, nil
 }
@@ -215,6 +287,27 @@ func (m *DefaultManager) initSelector() *routeselector.RouteSelector {
 	return routeselector.NewRouteSelector()
 }
 
+func (m *DefaultManager) initApprover() *routeapproval.RouteApprover {
+	var state *ApprovalState
+	m.stateManager.RegisterState(state)
+
+	// restore the approvals if they exist
+	if err := m.stateManager.LoadState(state); err != nil {
+		log.Warnf("failed to load state: %v", err)
+		return routeapproval.NewRouteApprover()
+	}
+
+	if state := m.stateManager.GetState(state); state != nil {
+		if approver, ok := state.(*ApprovalState); ok {
+			return (*routeapproval.RouteApprover)(approver)
+		}
+
+		log.Warnf("failed to convert state with type %T to ApprovalState", state)
+	}
+
+	return routeapproval.NewRouteApprover()
+}
+
 func (m *DefaultManager) EnableServerRouter(firewall firewall.Manager) error {
 	if m.disableServerRoutes {
 		log.Info("server routes are disabled")
@@ -233,6 +326,15 @@ func (m *DefaultManager) EnableServerRouter(firewall firewall.Manager) error {
 	return nil
 }
 
+// DrainServerRoutes stops accepting new flows through the routed networks, the established flows continue until
+// Stop removes the routing rules. It returns false if the peer doesn't route any network.
+func (m *DefaultManager) DrainServerRoutes() (bool, error) {
+	if m.serverRouter == nil {
+		return false, nil
+	}
+	return m.serverRouter.drain()
+}
+
 // Stop stops the manager watchers and clean firewall rules
 func (m *DefaultManager) Stop(stateManager *statemanager.Manager) {
 	m.stop()
@@ -240,6 +342,10 @@ func (m *DefaultManager) Stop(stateManager *statemanager.Manager) {
 		m.serverRouter.cleanUp()
 	}
 
+	if m.exitNodePinner != nil {
+		m.exitNodePinner.Stop()
+	}
+
 	if m.routeRefCounter != nil {
 		if err := m.routeRefCounter.Flush(); err != nil {
 			log.Errorf("Error flushing route ref counter: %v", err)
@@ -251,6 +357,12 @@ func (m *DefaultManager) Stop(stateManager *statemanager.Manager) {
 		}
 	}
 
+	if m.splitTunnel != nil {
+		if err := m.splitTunnel.Stop(); err != nil {
+			log.Errorf("Error stopping split tunneling: %v", err)
+		}
+	}
+
 	if !nbnet.CustomRoutingDisabled() && !m.disableClientRoutes {
 		if err := m.sysOps.CleanupRouting(stateManager); err != nil {
 			log.Errorf("Error cleaning up routing: %v", err)
@@ -282,7 +394,8 @@ func (m *DefaultManager) UpdateRoutes(updateSerial uint64, newRoutes []*route.Ro
 	newServerRoutesMap, newClientRoutesIDMap := m.classifyRoutes(newRoutes)
 
 	if !m.disableClientRoutes {
-		filteredClientRoutes := m.routeSelector.FilterSelected(newClientRoutesIDMap)
+		filteredClientRoutes := m.filterRoutes(newClientRoutesIDMap)
+		m.reportSubnetConflicts(filteredClientRoutes)
 		m.updateClientNetworks(updateSerial, filteredClientRoutes)
 		m.notifier.OnNewRoutes(filteredClientRoutes)
 	}
@@ -314,6 +427,11 @@ func (m *DefaultManager) GetRouteSelector() *routeselector.RouteSelector {
 	return m.routeSelector
 }
 
+// GetRouteApprover returns the route approver, it is nil if the routes don't require approval
+func (m *DefaultManager) GetRouteApprover() *routeapproval.RouteApprover {
+	return m.routeApprover
+}
+
 // GetClientRoutes returns most recent list of clientRoutes received from the Management Service
 func (m *DefaultManager) GetClientRoutes() route.HAMap {
 	m.mux.Lock()
@@ -339,7 +457,7 @@ func (m *DefaultManager) TriggerSelection(networks route.HAMap) {
 	m.mux.Lock()
 	defer m.mux.Unlock()
 
-	networks = m.routeSelector.FilterSelected(networks)
+	networks = m.filterRoutes(networks)
 
 	m.notifier.OnNewRoutes(networks)
 
@@ -371,15 +489,63 @@ func (m *DefaultManager) TriggerSelection(networks route.HAMap) {
 	if err := m.stateManager.UpdateState((*SelectorState)(m.routeSelector)); err != nil {
 		log.Errorf("failed to update state: %v", err)
 	}
+	if m.routeApprover != nil {
+		if err := m.stateManager.UpdateState((*ApprovalState)(m.routeApprover)); err != nil {
+			log.Errorf("failed to update state: %v", err)
+		}
+	}
 }
 
-// stopObsoleteClients stops the client network watcher for the networks that are not in the new list
+// filterRoutes removes the deselected routes and the high-risk routes waiting for the approval of the local user.
+// In the automatic exit node selection only the chosen exit node is kept.
+func (m *DefaultManager) filterRoutes(networks route.HAMap) route.HAMap {
+	networks = m.routeSelector.FilterSelected(networks)
+	if m.routeApprover == nil {
+		return m.filterExitNodes(networks)
+	}
+
+	networks, pending := m.routeApprover.FilterApproved(networks)
+	for _, r := range pending {
+		log.Warnf("network %s (%s) through peer %s requires approval", r.NetID, r.Network, r.Peer)
+		m.statusRecorder.PublishNotification(
+			mgmProto.NotificationRouteApproval,
+			string(r.NetID),
+			proto.SystemEvent_WARNING,
+			proto.SystemEvent_NETWORK,
+			"Network requires approval",
+			fmt.Sprintf("The network %s routes %s through another peer. Run 'netbird networks approve %s' to use it.",
+				r.NetID, r.Network, r.NetID),
+			map[string]string{
+				"id":       string(r.NetID),
+				"network":  r.Network.String(),
+				"peer":     r.Peer,
+				"approval": "pending",
+			},
+		)
+	}
+	return m.filterExitNodes(networks)
+}
+
+// stopObsoleteClients stops the client network watcher for the networks that are not in the new list.
+// It waits for the routes of the stopped exit nodes to be removed, the next exit node routes the same default route.
 func (m *DefaultManager) stopObsoleteClients(networks route.HAMap) {
+	var exitNodes []*clientNetwork
 	for id, client := range m.clientNetworks {
 		if _, ok := networks[id]; !ok {
 			log.Debugf("Stopping client network watcher, %s", id)
 			client.cancel()
 			delete(m.clientNetworks, id)
+			if client.exitNode {
+				exitNodes = append(exitNodes, client)
+			}
+		}
+	}
+
+	for _, client := range exitNodes {
+		select {
+		case <-client.stopped:
+		case <-time.After(exitNodeStopTimeout):
+			log.Warnf("timed out waiting for the routes of exit node [%v] to be removed", client.handler)
 		}
 	}
 }
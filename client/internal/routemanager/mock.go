@@ -6,6 +6,7 @@ import (
 	firewall "github.com/netbirdio/netbird/client/firewall/manager"
 	"github.com/netbirdio/netbird/client/iface"
 	"github.com/netbirdio/netbird/client/internal/listener"
+	"github.com/netbirdio/netbird/client/internal/routeapproval"
 	"github.com/netbirdio/netbird/client/internal/routeselector"
 	"github.com/netbirdio/netbird/client/internal/statemanager"
 	"github.com/netbirdio/netbird/route"
@@ -17,6 +18,7 @@ type MockManager struct {
 	UpdateRoutesFunc             func(updateSerial uint64, newRoutes []*route.Route) error
 	TriggerSelectionFunc         func(haMap route.HAMap)
 	GetRouteSelectorFunc         func() *routeselector.RouteSelector
+	GetRouteApproverFunc         func() *routeapproval.RouteApprover
 	GetClientRoutesFunc          func() route.HAMap
 	GetClientRoutesWithNetIDFunc func() map[route.NetID][]*route.Route
 	StopFunc                     func(manager *statemanager.Manager)
@@ -53,6 +55,14 @@ func (m *MockManager) GetRouteSelector() *routeselector.RouteSelector {
 	return nil
 }
 
+// GetRouteApprover mock implementation of GetRouteApprover from Manager interface
+func (m *MockManager) GetRouteApprover() *routeapproval.RouteApprover {
+	if m.GetRouteApproverFunc != nil {
+		return m.GetRouteApproverFunc()
+	}
+	return nil
+}
+
 // GetClientRoutes mock implementation of GetClientRoutes from Manager interface
 func (m *MockManager) GetClientRoutes() route.HAMap {
 	if m.GetClientRoutesFunc != nil {
@@ -82,6 +92,11 @@ func (m *MockManager) EnableServerRouter(firewall firewall.Manager) error {
 	panic("implement me")
 }
 
+// DrainServerRoutes mock implementation of DrainServerRoutes from Manager interface
+func (m *MockManager) DrainServerRoutes() (bool, error) {
+	return false, nil
+}
+
 // Stop mock implementation of Stop from Manager interface
 func (m *MockManager) Stop(stateManager *statemanager.Manager) {
 	if m.StopFunc != nil {
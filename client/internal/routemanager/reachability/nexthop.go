@@ -0,0 +1,13 @@
+//go:build !ios && !android
+
+package reachability
+
+import (
+	"net/netip"
+
+	"github.com/netbirdio/netbird/client/internal/routemanager/systemops"
+)
+
+func systemNextHop(ip netip.Addr) (systemops.Nexthop, error) {
+	return systemops.GetNextHop(ip)
+}
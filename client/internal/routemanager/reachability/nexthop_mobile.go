@@ -0,0 +1,14 @@
+//go:build ios || android
+
+package reachability
+
+import (
+	"errors"
+	"net/netip"
+
+	"github.com/netbirdio/netbird/client/internal/routemanager/systemops"
+)
+
+func systemNextHop(netip.Addr) (systemops.Nexthop, error) {
+	return systemops.Nexthop{}, errors.New("route lookup not supported on mobile platforms")
+}
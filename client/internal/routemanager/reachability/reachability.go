@@ -0,0 +1,112 @@
+// Package reachability checks whether a routing peer reaches the networks it routes for the other peers.
+package reachability
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"time"
+
+	"github.com/netbirdio/netbird/client/internal/probe"
+)
+
+// probeTimeout is the time a ping waits for the reply
+const probeTimeout = 2 * time.Second
+
+var (
+	nextHop     = systemNextHop
+	ping        = probe.Ping
+	isLocalAddr = interfaceHasAddr
+)
+
+// Result is the outcome of a check
+type Result struct {
+	Reachable bool
+	// Target is the address probed in the network
+	Target netip.Addr
+	// Latency is the round trip time to the target, zero if it didn't reply
+	Latency time.Duration
+	// Err describes why the network isn't reachable
+	Err error
+}
+
+// Target returns the address probed in the network: the address of a host route or the first host of the network
+func Target(network netip.Prefix) netip.Addr {
+	network = network.Masked()
+	if network.IsSingleIP() {
+		return network.Addr()
+	}
+	return network.Addr().Next()
+}
+
+// Check checks whether the network is reachable outside of the NetBird interface. The route to the network must lead
+// through an interface that is up and the target or the gateway of the route must reply to a ping. The directly
+// connected networks don't need a reply, their hosts often don't answer pings.
+func Check(ctx context.Context, network netip.Prefix, wgIfaceName string) Result {
+	target := Target(network)
+	result := Result{Target: target}
+
+	hop, err := nextHop(target)
+	if err != nil {
+		result.Err = fmt.Errorf("no route to %s: %w", target, err)
+		return result
+	}
+	if hop.Intf != nil {
+		if hop.Intf.Name == wgIfaceName {
+			result.Err = fmt.Errorf("the route to %s leads back into the NetBird network", target)
+			return result
+		}
+		if hop.Intf.Flags&net.FlagUp == 0 {
+			result.Err = fmt.Errorf("interface %s of the route to %s is down", hop.Intf.Name, target)
+			return result
+		}
+	}
+
+	latency, err := pingWithTimeout(ctx, target)
+	if err == nil {
+		result.Reachable = true
+		result.Latency = latency
+		return result
+	}
+
+	gateway := hop.IP
+	if !gateway.IsValid() || isLocalAddr(hop.Intf, gateway) {
+		// the network is directly connected to the interface
+		result.Reachable = true
+		return result
+	}
+
+	if _, gwErr := pingWithTimeout(ctx, gateway); gwErr != nil {
+		result.Err = fmt.Errorf("neither %s nor the gateway %s replied: %w", target, gateway, err)
+		return result
+	}
+	result.Reachable = true
+	return result
+}
+
+func pingWithTimeout(ctx context.Context, address netip.Addr) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	return ping(ctx, address)
+}
+
+func interfaceHasAddr(intf *net.Interface, addr netip.Addr) bool {
+	if intf == nil {
+		return false
+	}
+	addrs, err := intf.Addrs()
+	if err != nil {
+		return false
+	}
+	for _, a := range addrs {
+		ipNet, ok := a.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		if ip, ok := netip.AddrFromSlice(ipNet.IP); ok && ip.Unmap() == addr.Unmap() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package reachability
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/netbirdio/netbird/client/internal/probe"
+	"github.com/netbirdio/netbird/client/internal/routemanager/systemops"
+)
+
+func TestTarget(t *testing.T) {
+	assert.Equal(t, netip.MustParseAddr("10.0.0.1"), Target(netip.MustParsePrefix("10.0.0.0/24")))
+	assert.Equal(t, netip.MustParseAddr("10.0.0.1"), Target(netip.MustParsePrefix("10.0.0.77/24")), "the prefix should be masked")
+	assert.Equal(t, netip.MustParseAddr("192.168.1.10"), Target(netip.MustParsePrefix("192.168.1.10/32")))
+	assert.Equal(t, netip.MustParseAddr("fd00::1"), Target(netip.MustParsePrefix("fd00::/64")))
+}
+
+func TestCheck(t *testing.T) {
+	eth0 := &net.Interface{Name: "eth0", Flags: net.FlagUp}
+	gateway := netip.MustParseAddr("192.168.1.1")
+	local := netip.MustParseAddr("192.168.1.20")
+	errNoReply := errors.New("no reply within the timeout")
+
+	tests := []struct {
+		name      string
+		hop       systemops.Nexthop
+		hopErr    error
+		replies   map[netip.Addr]bool
+		reachable bool
+		latency   time.Duration
+	}{
+		{name: "target replies", hop: systemops.Nexthop{IP: gateway, Intf: eth0}, replies: map[netip.Addr]bool{netip.MustParseAddr("10.0.0.1"): true}, reachable: true, latency: 5 * time.Millisecond},
+		{name: "gateway replies", hop: systemops.Nexthop{IP: gateway, Intf: eth0}, replies: map[netip.Addr]bool{gateway: true}, reachable: true},
+		{name: "nothing replies", hop: systemops.Nexthop{IP: gateway, Intf: eth0}},
+		{name: "directly connected", hop: systemops.Nexthop{IP: local, Intf: eth0}, reachable: true},
+		{name: "no route", hopErr: errors.New("route not found")},
+		{name: "route into the tunnel", hop: systemops.Nexthop{Intf: &net.Interface{Name: "wt0", Flags: net.FlagUp}}, replies: map[netip.Addr]bool{netip.MustParseAddr("10.0.0.1"): true}},
+		{name: "interface down", hop: systemops.Nexthop{IP: gateway, Intf: &net.Interface{Name: "eth1"}}, replies: map[netip.Addr]bool{gateway: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nextHop = func(netip.Addr) (systemops.Nexthop, error) { return tt.hop, tt.hopErr }
+			ping = func(_ context.Context, address netip.Addr) (time.Duration, error) {
+				if tt.replies[address] {
+					return 5 * time.Millisecond, nil
+				}
+				return 0, errNoReply
+			}
+			isLocalAddr = func(_ *net.Interface, addr netip.Addr) bool { return addr == local }
+			t.Cleanup(func() {
+				nextHop, ping, isLocalAddr = systemNextHop, probe.Ping, interfaceHasAddr
+			})
+
+			result := Check(context.Background(), netip.MustParsePrefix("10.0.0.0/24"), "wt0")
+			assert.Equal(t, tt.reachable, result.Reachable)
+			assert.Equal(t, tt.reachable, result.Err == nil, "the error should describe the unreachable networks only: %v", result.Err)
+			assert.Equal(t, tt.latency, result.Latency)
+			assert.Equal(t, netip.MustParseAddr("10.0.0.1"), result.Target)
+		})
+	}
+}
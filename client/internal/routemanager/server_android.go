@@ -18,6 +18,10 @@ type serverRouter struct {
 func (r serverRouter) cleanUp() {
 }
 
+func (r serverRouter) drain() (bool, error) {
+	return false, nil
+}
+
 func (r serverRouter) updateRoutes(map[route.ID]*route.Route) error {
 	return nil
 }
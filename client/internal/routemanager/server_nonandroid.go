@@ -6,14 +6,29 @@ import (
 	"context"
 	"fmt"
 	"net/netip"
+	"runtime"
 	"sync"
+	"time"
 
+	"github.com/hashicorp/go-multierror"
 	log "github.com/sirupsen/logrus"
 
+	nberrors "github.com/netbirdio/netbird/client/errors"
 	firewall "github.com/netbirdio/netbird/client/firewall/manager"
 	"github.com/netbirdio/netbird/client/internal/peer"
 	"github.com/netbirdio/netbird/client/internal/routemanager/iface"
+	"github.com/netbirdio/netbird/client/internal/routemanager/reachability"
 	"github.com/netbirdio/netbird/route"
+	"github.com/netbirdio/netbird/util/faultinject"
+)
+
+const (
+	// reachabilityInitialDelay leaves the routing rules time to settle before the first reachability check
+	reachabilityInitialDelay = 10 * time.Second
+	// reachabilityInterval is the interval the routed networks are checked at
+	reachabilityInterval = time.Minute
+	// reachabilityConcurrency bounds the networks checked at once
+	reachabilityConcurrency = 8
 )
 
 type serverRouter struct {
@@ -23,19 +38,35 @@ type serverRouter struct {
 	firewall       firewall.Manager
 	wgInterface    iface.WGIface
 	statusRecorder *peer.Status
+
+	// draining is set when the router stops accepting new flows before shutting down
+	draining   bool
+	drainRules []firewall.Rule
 }
 
 func newServerRouter(ctx context.Context, wgInterface iface.WGIface, firewall firewall.Manager, statusRecorder *peer.Status) (*serverRouter, error) {
-	return &serverRouter{
+	router := &serverRouter{
 		ctx:            ctx,
 		routes:         make(map[route.ID]*route.Route),
 		firewall:       firewall,
 		wgInterface:    wgInterface,
 		statusRecorder: statusRecorder,
-	}, nil
+	}
+
+	// the routes of the system can't be looked up on iOS
+	if runtime.GOOS != "ios" {
+		go router.monitorReachability()
+	}
+
+	return router, nil
 }
 
 func (m *serverRouter) updateRoutes(routesMap map[route.ID]*route.Route) error {
+	if m.isDraining() {
+		log.Debugf("not updating the server routes while draining")
+		return nil
+	}
+
 	serverRoutesToRemove := make([]route.ID, 0)
 
 	for routeID := range m.routes {
@@ -98,6 +129,10 @@ func (m *serverRouter) removeFromServerNetwork(route *route.Route) error {
 		return fmt.Errorf("parse prefix: %w", err)
 	}
 
+	if err := faultinject.Err(faultinject.FirewallError, "remove nat rule"); err != nil {
+		return fmt.Errorf("remove routing rules: %w", err)
+	}
+
 	err = m.firewall.RemoveNatRule(routerPair)
 	if err != nil {
 		return fmt.Errorf("remove routing rules: %w", err)
@@ -128,6 +163,10 @@ func (m *serverRouter) addToServerNetwork(route *route.Route) error {
 		return fmt.Errorf("parse prefix: %w", err)
 	}
 
+	if err := faultinject.Err(faultinject.FirewallError, "add nat rule"); err != nil {
+		return fmt.Errorf("insert routing rules: %w", err)
+	}
+
 	err = m.firewall.AddNatRule(routerPair)
 	if err != nil {
 		return fmt.Errorf("insert routing rules: %w", err)
@@ -145,9 +184,65 @@ func (m *serverRouter) addToServerNetwork(route *route.Route) error {
 	return nil
 }
 
+// drain drops the new flows through the routed networks while the established ones continue until the cleanup.
+// It returns false if the peer doesn't route any network.
+func (m *serverRouter) drain() (bool, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	if len(m.routes) == 0 || m.draining {
+		return false, nil
+	}
+	m.draining = true
+
+	destinations := make(map[netip.Prefix]struct{})
+	for _, r := range m.routes {
+		routerPair, err := routeToRouterPair(r)
+		if err != nil {
+			log.Errorf("Failed to convert route to router pair: %v", err)
+			continue
+		}
+		destinations[routerPair.Destination] = struct{}{}
+	}
+
+	var merr *multierror.Error
+	for destination := range destinations {
+		rule, err := m.firewall.AddRouteFiltering(
+			nil,
+			[]netip.Prefix{getDefaultPrefix(destination)},
+			destination,
+			firewall.ProtocolALL,
+			nil,
+			nil,
+			firewall.ActionDrop,
+		)
+		if err != nil {
+			merr = multierror.Append(merr, fmt.Errorf("drop new flows to %s: %w", destination, err))
+			continue
+		}
+		m.drainRules = append(m.drainRules, rule)
+	}
+
+	return true, nberrors.FormatErrorOrNil(merr)
+}
+
+func (m *serverRouter) isDraining() bool {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	return m.draining
+}
+
 func (m *serverRouter) cleanUp() {
 	m.mux.Lock()
 	defer m.mux.Unlock()
+
+	for _, rule := range m.drainRules {
+		if err := m.firewall.DeleteRouteRule(rule); err != nil {
+			log.Errorf("Failed to remove drain rule: %v", err)
+		}
+	}
+	m.drainRules = nil
+
 	for _, r := range m.routes {
 		routerPair, err := routeToRouterPair(r)
 		if err != nil {
@@ -165,6 +260,71 @@ func (m *serverRouter) cleanUp() {
 	m.statusRecorder.CleanLocalPeerStateRoutes()
 }
 
+// monitorReachability checks periodically whether the peer reaches the networks it routes, the results are recorded in
+// the local peer state until the context is done
+func (m *serverRouter) monitorReachability() {
+	timer := time.NewTimer(reachabilityInitialDelay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		m.checkReachability()
+		timer.Reset(reachabilityInterval)
+	}
+}
+
+func (m *serverRouter) checkReachability() {
+	m.mux.Lock()
+	if m.draining {
+		m.mux.Unlock()
+		return
+	}
+	routes := make([]*route.Route, 0, len(m.routes))
+	for _, r := range m.routes {
+		// the domains of the dynamic routes are resolved by the peers using them and the exit nodes route everything
+		if r.IsDynamic() || r.Network.Bits() == 0 {
+			continue
+		}
+		routes = append(routes, r)
+	}
+	m.mux.Unlock()
+
+	sem := make(chan struct{}, reachabilityConcurrency)
+	var wg sync.WaitGroup
+	for _, r := range routes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			result := reachability.Check(m.ctx, r.Network, m.wgInterface.Name())
+			if m.ctx.Err() != nil {
+				return
+			}
+
+			state := peer.RouteReachability{
+				RouteID:   string(r.ID),
+				Reachable: result.Reachable,
+				Target:    result.Target,
+				Latency:   result.Latency,
+				CheckedAt: time.Now(),
+			}
+			if result.Err != nil {
+				log.Debugf("routed network %s of route %s is unreachable: %v", r.Network, r.ID, result.Err)
+				state.Error = result.Err.Error()
+			}
+			m.statusRecorder.UpdateLocalPeerStateRouteReachability(r.Network.String(), state)
+		}()
+	}
+	wg.Wait()
+}
+
 func routeToRouterPair(route *route.Route) (firewall.RouterPair, error) {
 	// TODO: add ipv6
 	source := getDefaultPrefix(route.Network)
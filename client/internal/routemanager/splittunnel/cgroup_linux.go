@@ -0,0 +1,167 @@
+//go:build !android
+
+package splittunnel
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+
+	nberrors "github.com/netbirdio/netbird/client/errors"
+)
+
+const (
+	cgroupRoot = "/sys/fs/cgroup"
+	cgroupName = "netbird-split"
+	// cgroupLevel is the depth of the cgroup in the hierarchy, matched by the nftables socket expression
+	cgroupLevel = 1
+)
+
+// cgroup is the cgroup v2 holding the processes of the selected apps
+type cgroup struct {
+	path string
+	id   uint64
+	apps map[string]struct{}
+	// origins are the cgroups of the moved processes, the processes are moved back when the cgroup is removed
+	origins map[int]string
+}
+
+func newCgroup(apps []string) (*cgroup, error) {
+	if _, err := os.Stat(filepath.Join(cgroupRoot, "cgroup.controllers")); err != nil {
+		return nil, fmt.Errorf("cgroup v2 is not mounted at %s: %w", cgroupRoot, err)
+	}
+
+	path := filepath.Join(cgroupRoot, cgroupName)
+	if err := os.Mkdir(path, 0755); err != nil && !errors.Is(err, fs.ErrExist) {
+		return nil, fmt.Errorf("create %s: %w", path, err)
+	}
+
+	var stat unix.Stat_t
+	if err := unix.Stat(path, &stat); err != nil {
+		return nil, fmt.Errorf("stat %s: %w", path, err)
+	}
+
+	c := &cgroup{
+		path:    path,
+		id:      stat.Ino,
+		apps:    make(map[string]struct{}),
+		origins: make(map[int]string),
+	}
+	for _, app := range apps {
+		// the exe link of the processes points to the resolved binary
+		if resolved, err := filepath.EvalSymlinks(app); err == nil {
+			app = resolved
+		}
+		c.apps[app] = struct{}{}
+	}
+	return c, nil
+}
+
+// scan moves the processes of the selected apps to the cgroup, their child processes inherit the cgroup
+func (c *cgroup) scan() {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		log.Warnf("failed to list the processes: %v", err)
+		return
+	}
+
+	running := make(map[int]struct{})
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		running[pid] = struct{}{}
+
+		exe, err := os.Readlink(fmt.Sprintf("/proc/%d/exe", pid))
+		if err != nil {
+			// kernel threads and processes that exited in the meantime
+			continue
+		}
+		if _, ok := c.apps[strings.TrimSuffix(exe, " (deleted)")]; !ok {
+			continue
+		}
+
+		data, err := os.ReadFile(fmt.Sprintf("/proc/%d/cgroup", pid))
+		if err != nil {
+			continue
+		}
+		origin, ok := parseCgroup(data)
+		if !ok || origin == "/"+cgroupName {
+			continue
+		}
+
+		if err := c.add(pid); err != nil {
+			log.Warnf("failed to move process %d of %s to the split tunnel cgroup: %v", pid, exe, err)
+			continue
+		}
+		c.origins[pid] = origin
+		log.Debugf("moved process %d of %s to the split tunnel cgroup", pid, exe)
+	}
+
+	for pid := range c.origins {
+		if _, ok := running[pid]; !ok {
+			delete(c.origins, pid)
+		}
+	}
+}
+
+func (c *cgroup) add(pid int) error {
+	return writeProcs(c.path, pid)
+}
+
+// remove moves the processes back to their cgroups, the child processes to the root cgroup, and removes the cgroup
+func (c *cgroup) remove() error {
+	data, err := os.ReadFile(filepath.Join(c.path, "cgroup.procs"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("read processes: %w", err)
+	}
+
+	var merr *multierror.Error
+	for _, field := range strings.Fields(string(data)) {
+		pid, err := strconv.Atoi(field)
+		if err != nil {
+			continue
+		}
+
+		target := cgroupRoot
+		if origin, ok := c.origins[pid]; ok {
+			target = filepath.Join(cgroupRoot, origin)
+		}
+		if err := writeProcs(target, pid); err != nil {
+			if err := writeProcs(cgroupRoot, pid); err != nil && !errors.Is(err, unix.ESRCH) {
+				merr = multierror.Append(merr, fmt.Errorf("move process %d: %w", pid, err))
+			}
+		}
+	}
+
+	if err := os.Remove(c.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		merr = multierror.Append(merr, fmt.Errorf("remove %s: %w", c.path, err))
+	}
+	return nberrors.FormatErrorOrNil(merr)
+}
+
+func writeProcs(path string, pid int) error {
+	return os.WriteFile(filepath.Join(path, "cgroup.procs"), []byte(strconv.Itoa(pid)), 0644)
+}
+
+// parseCgroup returns the cgroup v2 path from the content of /proc/<pid>/cgroup
+func parseCgroup(data []byte) (string, bool) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if path, ok := strings.CutPrefix(scanner.Text(), "0::"); ok {
+			return path, true
+		}
+	}
+	return "", false
+}
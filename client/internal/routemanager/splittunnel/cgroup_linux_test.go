@@ -0,0 +1,22 @@
+//go:build !android
+
+package splittunnel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCgroup(t *testing.T) {
+	path, ok := parseCgroup([]byte("0::/user.slice/user-1000.slice/session-2.scope\n"))
+	assert.True(t, ok)
+	assert.Equal(t, "/user.slice/user-1000.slice/session-2.scope", path)
+
+	path, ok = parseCgroup([]byte("12:pids:/user.slice\n1:name=systemd:/user.slice\n0::/netbird-split\n"))
+	assert.True(t, ok)
+	assert.Equal(t, "/netbird-split", path)
+
+	_, ok = parseCgroup([]byte("12:pids:/user.slice\n1:name=systemd:/user.slice\n"))
+	assert.False(t, ok)
+}
@@ -0,0 +1,144 @@
+//go:build !android
+
+package splittunnel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/user"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	log "github.com/sirupsen/logrus"
+
+	nberrors "github.com/netbirdio/netbird/client/errors"
+	"github.com/netbirdio/netbird/client/internal/routemanager/iface"
+	nbnet "github.com/netbirdio/netbird/util/net"
+)
+
+// scanInterval is the interval of the scans for new processes of the selected apps. The sockets keep the cgroup of
+// the process at creation time, the connections opened before the process is moved to the cgroup aren't routed.
+const scanInterval = 2 * time.Second
+
+// Manager steers the traffic of the selected processes through the NetBird routes
+type Manager struct {
+	config  Config
+	wgIface iface.WGIface
+
+	cgroup *cgroup
+	rules  *rules
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New returns a split tunneling manager for the config
+func New(config Config, wgIface iface.WGIface) *Manager {
+	return &Manager{
+		config:  config,
+		wgIface: wgIface,
+	}
+}
+
+// Start marks the traffic of the selected processes, the routing rules directing the marked traffic to the NetBird
+// routing table are added by the system ops
+func (m *Manager) Start(ctx context.Context) (err error) {
+	if !nbnet.AdvancedRouting() {
+		return errors.New("split tunneling requires the advanced routing, it isn't available with the legacy routing")
+	}
+
+	uids, err := lookupUIDs(m.config.Users)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			if cleanErr := m.Stop(); cleanErr != nil {
+				log.Errorf("failed to clean up split tunneling: %v", cleanErr)
+			}
+		}
+	}()
+
+	var cgroupID uint64
+	if len(m.config.Apps) > 0 {
+		if m.cgroup, err = newCgroup(m.config.Apps); err != nil {
+			return fmt.Errorf("create cgroup: %w", err)
+		}
+		cgroupID = m.cgroup.id
+	}
+
+	if m.rules, err = addRules(m.wgIface.Name(), uids, cgroupID); err != nil {
+		return fmt.Errorf("add nftables rules: %w", err)
+	}
+
+	if m.cgroup != nil {
+		ctx, m.cancel = context.WithCancel(ctx)
+		m.done = make(chan struct{})
+		go m.watchProcesses(ctx)
+	}
+
+	log.Infof("split tunneling enabled for apps %v and users %v", m.config.Apps, m.config.Users)
+	return nil
+}
+
+// Stop removes the marking rules and moves the selected processes back to their cgroups
+func (m *Manager) Stop() error {
+	if m.cancel != nil {
+		m.cancel()
+		<-m.done
+		m.cancel = nil
+	}
+
+	var merr *multierror.Error
+	if m.rules != nil {
+		if err := m.rules.remove(); err != nil {
+			merr = multierror.Append(merr, fmt.Errorf("remove nftables rules: %w", err))
+		}
+		m.rules = nil
+	}
+	if m.cgroup != nil {
+		if err := m.cgroup.remove(); err != nil {
+			merr = multierror.Append(merr, fmt.Errorf("remove cgroup: %w", err))
+		}
+		m.cgroup = nil
+	}
+	return nberrors.FormatErrorOrNil(merr)
+}
+
+func (m *Manager) watchProcesses(ctx context.Context) {
+	defer close(m.done)
+
+	ticker := time.NewTicker(scanInterval)
+	defer ticker.Stop()
+
+	for {
+		m.cgroup.scan()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func lookupUIDs(users []string) ([]uint32, error) {
+	var uids []uint32
+	for _, name := range users {
+		u, err := user.Lookup(name)
+		if err != nil {
+			if u, err = user.LookupId(name); err != nil {
+				return nil, fmt.Errorf("lookup user %s: %w", name, err)
+			}
+		}
+		uid, err := strconv.ParseUint(u.Uid, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("parse uid of user %s: %w", name, err)
+		}
+		uids = append(uids, uint32(uid))
+	}
+	return uids, nil
+}
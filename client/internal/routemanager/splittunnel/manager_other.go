@@ -0,0 +1,31 @@
+//go:build (!linux && !windows) || android
+
+package splittunnel
+
+import (
+	"context"
+	"runtime"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/client/internal/routemanager/iface"
+)
+
+// Manager is a no-op, split tunneling isn't supported on this platform
+type Manager struct{}
+
+// New returns a split tunneling manager for the config
+func New(Config, iface.WGIface) *Manager {
+	return &Manager{}
+}
+
+// Start logs that the routes apply to every process
+func (m *Manager) Start(context.Context) error {
+	log.Warnf("split tunneling is not supported on %s, the routes apply to every process", runtime.GOOS)
+	return nil
+}
+
+// Stop does nothing
+func (m *Manager) Stop() error {
+	return nil
+}
@@ -0,0 +1,219 @@
+package splittunnel
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"unsafe"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sys/windows"
+	"golang.zx2c4.com/wireguard/windows/tunnel/winipcfg"
+
+	"github.com/netbirdio/netbird/client/internal/routemanager/iface"
+)
+
+// Manager restricts the NetBird interface to the selected processes. The routing table can't be selected per process
+// without a driver, the connections of the other processes to the routed networks are blocked instead of leaving
+// through the local network. The connections to the NetBird peers are allowed for every process.
+type Manager struct {
+	config  Config
+	wgIface iface.WGIface
+
+	// engine is the handle of a dynamic WFP session, the filters are removed when the session is closed
+	engine uintptr
+}
+
+// New returns a split tunneling manager for the config
+func New(config Config, wgIface iface.WGIface) *Manager {
+	return &Manager{
+		config:  config,
+		wgIface: wgIface,
+	}
+}
+
+// Start adds the WFP filters
+func (m *Manager) Start(context.Context) (err error) {
+	intf := m.wgIface.ToInterface()
+	if intf == nil {
+		return errors.New("interface not found")
+	}
+	luid, err := winipcfg.LUIDFromIndex(uint32(intf.Index))
+	if err != nil {
+		return fmt.Errorf("get interface luid: %w", err)
+	}
+
+	session := fwpmSession0{
+		displayData: displayData("NetBird split tunneling"),
+		flags:       fwpmSessionFlagDynamic,
+	}
+	if m.engine, err = fwpmEngineOpen0(&session); err != nil {
+		return fmt.Errorf("open WFP engine: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if closeErr := m.Stop(); closeErr != nil {
+				log.Errorf("failed to clean up split tunneling: %v", closeErr)
+			}
+		}
+	}()
+
+	if err := fwpmTransactionBegin0(m.engine); err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	if err := m.addFilters(uint64(luid)); err != nil {
+		if abortErr := fwpmTransactionAbort0(m.engine); abortErr != nil {
+			log.Errorf("failed to abort the WFP transaction: %v", abortErr)
+		}
+		return err
+	}
+	if err := fwpmTransactionCommit0(m.engine); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	log.Infof("split tunneling enabled for apps %v and users %v", m.config.Apps, m.config.Users)
+	return nil
+}
+
+// Stop closes the WFP session, which removes the filters
+func (m *Manager) Stop() error {
+	if m.engine == 0 {
+		return nil
+	}
+	err := fwpmEngineClose0(m.engine)
+	m.engine = 0
+	if err != nil {
+		return fmt.Errorf("close WFP engine: %w", err)
+	}
+	return nil
+}
+
+func (m *Manager) addFilters(luid uint64) error {
+	subLayerKey, err := windows.GenerateGUID()
+	if err != nil {
+		return fmt.Errorf("generate sublayer key: %w", err)
+	}
+	subLayer := fwpmSublayer0{
+		subLayerKey: subLayerKey,
+		displayData: displayData("NetBird split tunneling"),
+		weight:      0xffff,
+	}
+	if err := fwpmSubLayerAdd0(m.engine, &subLayer); err != nil {
+		return fmt.Errorf("add sublayer: %w", err)
+	}
+
+	intfCondition := fwpmFilterCondition0{
+		fieldKey:       fwpmConditionIPLocalInterface,
+		matchType:      fwpMatchEqual,
+		conditionValue: fwpValue0{valueType: fwpUint64, value: uintptr(unsafe.Pointer(&luid))},
+	}
+
+	// the daemon keeps using the routes, like for the routed nameservers
+	self, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("get executable: %w", err)
+	}
+
+	appConditions := []fwpmFilterCondition0{intfCondition}
+	for _, app := range append(slices.Clone(m.config.Apps), self) {
+		appID, err := fwpmGetAppIDFromFileName0(app)
+		if err != nil {
+			return fmt.Errorf("get app id of %s: %w", app, err)
+		}
+		defer fwpmFreeMemory0(&appID)
+
+		// the conditions on the same field match any of the values
+		appConditions = append(appConditions, fwpmFilterCondition0{
+			fieldKey:       fwpmConditionAleAppID,
+			matchType:      fwpMatchEqual,
+			conditionValue: fwpValue0{valueType: fwpByteBlobType, value: uintptr(unsafe.Pointer(appID))},
+		})
+	}
+	selected := [][]fwpmFilterCondition0{appConditions}
+
+	if len(m.config.Users) > 0 {
+		sd, err := usersSecurityDescriptor(m.config.Users)
+		if err != nil {
+			return err
+		}
+		sdBlob := &fwpByteBlob{size: sd.Length(), data: (*uint8)(unsafe.Pointer(sd))}
+		selected = append(selected, []fwpmFilterCondition0{
+			intfCondition,
+			{
+				fieldKey:       fwpmConditionAleUserID,
+				matchType:      fwpMatchEqual,
+				conditionValue: fwpValue0{valueType: fwpSecurityDescriptor, value: uintptr(unsafe.Pointer(sdBlob))},
+			},
+		})
+	}
+
+	for _, layer := range []windows.GUID{fwpmLayerAleAuthConnectV4, fwpmLayerAleAuthConnectV6} {
+		for _, conditions := range selected {
+			if err := m.addFilter(layer, subLayerKey, 15, fwpActionPermit, conditions); err != nil {
+				return fmt.Errorf("add permit filter: %w", err)
+			}
+		}
+		if err := m.addFilter(layer, subLayerKey, 0, fwpActionBlock, []fwpmFilterCondition0{intfCondition}); err != nil {
+			return fmt.Errorf("add block filter: %w", err)
+		}
+	}
+
+	if network := m.wgIface.Address().Network; network != nil && network.IP.To4() != nil {
+		addrMask := &fwpV4AddrAndMask{
+			addr: binary.BigEndian.Uint32(network.IP.To4()),
+			mask: binary.BigEndian.Uint32(network.Mask),
+		}
+		conditions := []fwpmFilterCondition0{
+			intfCondition,
+			{
+				fieldKey:       fwpmConditionIPRemoteAddress,
+				matchType:      fwpMatchEqual,
+				conditionValue: fwpValue0{valueType: fwpV4AddrMask, value: uintptr(unsafe.Pointer(addrMask))},
+			},
+		}
+		if err := m.addFilter(fwpmLayerAleAuthConnectV4, subLayerKey, 14, fwpActionPermit, conditions); err != nil {
+			return fmt.Errorf("add peers filter: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (m *Manager) addFilter(layer, subLayer windows.GUID, weight uint8, action uint32, conditions []fwpmFilterCondition0) error {
+	filter := fwpmFilter0{
+		displayData:         displayData("NetBird split tunneling"),
+		layerKey:            layer,
+		subLayerKey:         subLayer,
+		weight:              fwpValue0{valueType: fwpUint8, value: uintptr(weight)},
+		numFilterConditions: uint32(len(conditions)),
+		filterCondition:     &conditions[0],
+		action:              fwpmAction0{actionType: action},
+	}
+	return fwpmFilterAdd0(m.engine, &filter)
+}
+
+// usersSecurityDescriptor returns a security descriptor granting the users, it matches the connections of their
+// processes
+func usersSecurityDescriptor(users []string) (*windows.SECURITY_DESCRIPTOR, error) {
+	var aces strings.Builder
+	for _, user := range users {
+		sid, _, _, err := windows.LookupSID("", user)
+		if err != nil {
+			if sid, err = windows.StringToSid(user); err != nil {
+				return nil, fmt.Errorf("lookup user %s: %w", user, err)
+			}
+		}
+		// CC is FWP_ACTRL_MATCH_FILTER
+		fmt.Fprintf(&aces, "(A;;CC;;;%s)", sid.String())
+	}
+
+	sd, err := windows.SecurityDescriptorFromString("O:SYG:SYD:" + aces.String())
+	if err != nil {
+		return nil, fmt.Errorf("create security descriptor: %w", err)
+	}
+	return sd, nil
+}
@@ -0,0 +1,182 @@
+//go:build !android
+
+package splittunnel
+
+import (
+	"fmt"
+
+	"github.com/google/nftables"
+	"github.com/google/nftables/binaryutil"
+	"github.com/google/nftables/expr"
+
+	nbnet "github.com/netbirdio/netbird/util/net"
+)
+
+const (
+	tableName          = "netbird-split"
+	chainNameOutput    = "output"
+	chainNamePreroute  = "prerouting"
+	chainNamePostroute = "postrouting"
+)
+
+// rules mark the traffic of the selected processes and their connections. The marked traffic leaving through the
+// NetBird interface is masqueraded, the source address was selected by the main routing table before the mark was
+// set. The replies get the mark of the connection for the reverse path filter.
+type rules struct {
+	conn  *nftables.Conn
+	table *nftables.Table
+}
+
+func addRules(wgIfaceName string, uids []uint32, cgroupID uint64) (*rules, error) {
+	r := &rules{
+		conn: &nftables.Conn{},
+		table: &nftables.Table{
+			Name:   tableName,
+			Family: nftables.TableFamilyINet,
+		},
+	}
+
+	if err := r.removeStale(); err != nil {
+		return nil, err
+	}
+
+	r.conn.AddTable(r.table)
+
+	output := r.conn.AddChain(&nftables.Chain{
+		Name:     chainNameOutput,
+		Table:    r.table,
+		Hooknum:  nftables.ChainHookOutput,
+		Priority: nftables.ChainPriorityMangle,
+		Type:     nftables.ChainTypeRoute,
+	})
+	for _, uid := range uids {
+		r.addMarkRule(output, []expr.Any{
+			&expr.Meta{Key: expr.MetaKeySKUID, Register: 1},
+			&expr.Cmp{
+				Op:       expr.CmpOpEq,
+				Register: 1,
+				Data:     binaryutil.NativeEndian.PutUint32(uid),
+			},
+		})
+	}
+	if cgroupID != 0 {
+		r.addMarkRule(output, []expr.Any{
+			&expr.Socket{Key: expr.SocketKeyCgroupv2, Level: cgroupLevel, Register: 1},
+			&expr.Cmp{
+				Op:       expr.CmpOpEq,
+				Register: 1,
+				Data:     binaryutil.NativeEndian.PutUint64(cgroupID),
+			},
+		})
+	}
+
+	prerouting := r.conn.AddChain(&nftables.Chain{
+		Name:     chainNamePreroute,
+		Table:    r.table,
+		Hooknum:  nftables.ChainHookPrerouting,
+		Priority: nftables.ChainPriorityMangle,
+		Type:     nftables.ChainTypeFilter,
+	})
+	r.conn.AddRule(&nftables.Rule{
+		Table: r.table,
+		Chain: prerouting,
+		Exprs: []expr.Any{
+			&expr.Ct{Key: expr.CtKeyMARK, Register: 1},
+			&expr.Cmp{
+				Op:       expr.CmpOpEq,
+				Register: 1,
+				Data:     binaryutil.NativeEndian.PutUint32(nbnet.SplitTunnelFwmark),
+			},
+			&expr.Meta{Key: expr.MetaKeyMARK, SourceRegister: true, Register: 1},
+		},
+	})
+
+	postrouting := r.conn.AddChain(&nftables.Chain{
+		Name:     chainNamePostroute,
+		Table:    r.table,
+		Hooknum:  nftables.ChainHookPostrouting,
+		Priority: nftables.ChainPriorityNATSource,
+		Type:     nftables.ChainTypeNAT,
+	})
+	r.conn.AddRule(&nftables.Rule{
+		Table: r.table,
+		Chain: postrouting,
+		Exprs: []expr.Any{
+			&expr.Meta{Key: expr.MetaKeyMARK, Register: 1},
+			&expr.Cmp{
+				Op:       expr.CmpOpEq,
+				Register: 1,
+				Data:     binaryutil.NativeEndian.PutUint32(nbnet.SplitTunnelFwmark),
+			},
+			&expr.Meta{Key: expr.MetaKeyOIFNAME, Register: 1},
+			&expr.Cmp{
+				Op:       expr.CmpOpEq,
+				Register: 1,
+				Data:     ifname(wgIfaceName),
+			},
+			&expr.Masq{},
+		},
+	})
+
+	if err := r.conn.Flush(); err != nil {
+		return nil, fmt.Errorf("flush: %w", err)
+	}
+	return r, nil
+}
+
+// addMarkRule marks the unmarked packets matching the expressions and their connection, the packets marked by
+// NetBird itself keep their mark
+func (r *rules) addMarkRule(chain *nftables.Chain, match []expr.Any) {
+	exprs := []expr.Any{
+		&expr.Meta{Key: expr.MetaKeyMARK, Register: 1},
+		&expr.Cmp{
+			Op:       expr.CmpOpEq,
+			Register: 1,
+			Data:     binaryutil.NativeEndian.PutUint32(0),
+		},
+	}
+	exprs = append(exprs, match...)
+	exprs = append(exprs,
+		&expr.Immediate{
+			Register: 1,
+			Data:     binaryutil.NativeEndian.PutUint32(nbnet.SplitTunnelFwmark),
+		},
+		&expr.Meta{Key: expr.MetaKeyMARK, SourceRegister: true, Register: 1},
+		&expr.Ct{Key: expr.CtKeyMARK, SourceRegister: true, Register: 1},
+	)
+
+	r.conn.AddRule(&nftables.Rule{
+		Table: r.table,
+		Chain: chain,
+		Exprs: exprs,
+	})
+}
+
+// removeStale removes the table left by a previous run that didn't stop cleanly
+func (r *rules) removeStale() error {
+	tables, err := r.conn.ListTablesOfFamily(nftables.TableFamilyINet)
+	if err != nil {
+		return fmt.Errorf("list tables: %w", err)
+	}
+	for _, table := range tables {
+		if table.Name == tableName {
+			r.conn.DelTable(table)
+			return r.conn.Flush()
+		}
+	}
+	return nil
+}
+
+func (r *rules) remove() error {
+	r.conn.DelTable(r.table)
+	if err := r.conn.Flush(); err != nil {
+		return fmt.Errorf("flush: %w", err)
+	}
+	return nil
+}
+
+func ifname(n string) []byte {
+	b := make([]byte, 16)
+	copy(b, n+"\x00")
+	return b
+}
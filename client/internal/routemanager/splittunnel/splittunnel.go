@@ -0,0 +1,42 @@
+// Package splittunnel restricts the NetBird routes and exit nodes to the traffic of selected processes, so only the
+// tooling that needs the routed networks goes through the tunnel and everything else keeps using the local network.
+// The traffic to the NetBird peers isn't affected.
+//
+// On Linux the selected processes are moved to a dedicated cgroup and their traffic is marked with nftables, the
+// marked traffic is routed through the NetBird routing table. On Windows the connections of the other processes
+// through the NetBird interface are blocked with WFP filters matching the application IDs of the selected binaries.
+package splittunnel
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Config selects the processes whose traffic is routed through the NetBird routes
+type Config struct {
+	// Apps are the absolute paths of the binaries, the child processes of a selected process are selected too
+	Apps []string
+	// Users are the names or IDs of the users, every process of the users is selected
+	Users []string
+}
+
+// Enabled returns true if any process is selected
+func (c Config) Enabled() bool {
+	return len(c.Apps) > 0 || len(c.Users) > 0
+}
+
+// Validate checks the selected apps and users
+func (c Config) Validate() error {
+	for _, app := range c.Apps {
+		if !filepath.IsAbs(app) {
+			return fmt.Errorf("split tunnel app %q is not an absolute path", app)
+		}
+	}
+	for _, user := range c.Users {
+		if strings.TrimSpace(user) == "" {
+			return fmt.Errorf("split tunnel user is empty")
+		}
+	}
+	return nil
+}
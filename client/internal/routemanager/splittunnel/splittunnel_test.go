@@ -0,0 +1,59 @@
+package splittunnel
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigValidate(t *testing.T) {
+	absApp, err := filepath.Abs("corp-tool")
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name    string
+		config  Config
+		enabled bool
+		wantErr bool
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:    "absolute app path",
+			config:  Config{Apps: []string{absApp}},
+			enabled: true,
+		},
+		{
+			name:    "relative app path",
+			config:  Config{Apps: []string{"corp-tool"}},
+			enabled: true,
+			wantErr: true,
+		},
+		{
+			name:    "user",
+			config:  Config{Users: []string{"dev"}},
+			enabled: true,
+		},
+		{
+			name:    "empty user",
+			config:  Config{Users: []string{" "}},
+			enabled: true,
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.enabled, testCase.config.Enabled())
+
+			err := testCase.config.Validate()
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
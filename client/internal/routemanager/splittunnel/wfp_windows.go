@@ -0,0 +1,204 @@
+package splittunnel
+
+import (
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// Windows Filtering Platform types and functions from fwpmtypes.h and fwpmu.h, the layout matches the 64-bit
+// Windows builds
+
+const (
+	fwpUint8              = 1
+	fwpUint64             = 4
+	fwpByteBlobType       = 12
+	fwpSecurityDescriptor = 14
+	fwpV4AddrMask         = 0x100
+
+	fwpMatchEqual = 0
+
+	fwpActionBlock  = 0x1 | 0x1000
+	fwpActionPermit = 0x2 | 0x1000
+
+	fwpmSessionFlagDynamic = 0x1
+
+	rpcCAuthnWinNT = 10
+)
+
+var (
+	fwpmLayerAleAuthConnectV4 = windows.GUID{
+		Data1: 0xc38d57d1, Data2: 0x05a7, Data3: 0x4c33,
+		Data4: [8]byte{0x90, 0x4f, 0x7f, 0xbc, 0xee, 0xe6, 0x0e, 0x82},
+	}
+	fwpmLayerAleAuthConnectV6 = windows.GUID{
+		Data1: 0x4a72393b, Data2: 0x319f, Data3: 0x44bc,
+		Data4: [8]byte{0x84, 0xc3, 0xba, 0x54, 0xdc, 0xb3, 0xb6, 0xb4},
+	}
+	fwpmConditionIPLocalInterface = windows.GUID{
+		Data1: 0x4cd62a49, Data2: 0x59c3, Data3: 0x4969,
+		Data4: [8]byte{0xb7, 0xf3, 0xbd, 0xa5, 0xd3, 0x28, 0x90, 0xa4},
+	}
+	fwpmConditionIPRemoteAddress = windows.GUID{
+		Data1: 0xb235ae9a, Data2: 0x1d64, Data3: 0x49b8,
+		Data4: [8]byte{0xa4, 0x4c, 0x5f, 0xf3, 0xd9, 0x09, 0x50, 0x45},
+	}
+	fwpmConditionAleAppID = windows.GUID{
+		Data1: 0xd78e1e87, Data2: 0x8644, Data3: 0x4ea5,
+		Data4: [8]byte{0x94, 0x37, 0xd8, 0x09, 0xec, 0xef, 0xc9, 0x71},
+	}
+	fwpmConditionAleUserID = windows.GUID{
+		Data1: 0xaf043a0a, Data2: 0xb34d, Data3: 0x4f86,
+		Data4: [8]byte{0x97, 0x9c, 0xc9, 0x03, 0x71, 0xaf, 0x6e, 0x66},
+	}
+)
+
+type fwpByteBlob struct {
+	size uint32
+	data *uint8
+}
+
+type fwpValue0 struct {
+	valueType uint32
+	value     uintptr
+}
+
+type fwpV4AddrAndMask struct {
+	addr uint32
+	mask uint32
+}
+
+type fwpmDisplayData0 struct {
+	name        *uint16
+	description *uint16
+}
+
+type fwpmFilterCondition0 struct {
+	fieldKey       windows.GUID
+	matchType      uint32
+	conditionValue fwpValue0
+}
+
+type fwpmAction0 struct {
+	actionType uint32
+	filterType windows.GUID
+}
+
+type fwpmFilter0 struct {
+	filterKey           windows.GUID
+	displayData         fwpmDisplayData0
+	flags               uint32
+	providerKey         *windows.GUID
+	providerData        fwpByteBlob
+	layerKey            windows.GUID
+	subLayerKey         windows.GUID
+	weight              fwpValue0
+	numFilterConditions uint32
+	filterCondition     *fwpmFilterCondition0
+	action              fwpmAction0
+	_                   [4]byte
+	providerContextKey  windows.GUID
+	reserved            *windows.GUID
+	filterID            uint64
+	effectiveWeight     fwpValue0
+}
+
+type fwpmSession0 struct {
+	sessionKey           windows.GUID
+	displayData          fwpmDisplayData0
+	flags                uint32
+	txnWaitTimeoutInMSec uint32
+	processID            uint32
+	sid                  *windows.SID
+	username             *uint16
+	kernelMode           int32
+}
+
+type fwpmSublayer0 struct {
+	subLayerKey  windows.GUID
+	displayData  fwpmDisplayData0
+	flags        uint32
+	providerKey  *windows.GUID
+	providerData fwpByteBlob
+	weight       uint16
+}
+
+var (
+	modfwpuclnt = windows.NewLazySystemDLL("fwpuclnt.dll")
+
+	procFwpmEngineOpen0           = modfwpuclnt.NewProc("FwpmEngineOpen0")
+	procFwpmEngineClose0          = modfwpuclnt.NewProc("FwpmEngineClose0")
+	procFwpmSubLayerAdd0          = modfwpuclnt.NewProc("FwpmSubLayerAdd0")
+	procFwpmFilterAdd0            = modfwpuclnt.NewProc("FwpmFilterAdd0")
+	procFwpmGetAppIdFromFileName0 = modfwpuclnt.NewProc("FwpmGetAppIdFromFileName0")
+	procFwpmFreeMemory0           = modfwpuclnt.NewProc("FwpmFreeMemory0")
+	procFwpmTransactionBegin0     = modfwpuclnt.NewProc("FwpmTransactionBegin0")
+	procFwpmTransactionCommit0    = modfwpuclnt.NewProc("FwpmTransactionCommit0")
+	procFwpmTransactionAbort0     = modfwpuclnt.NewProc("FwpmTransactionAbort0")
+)
+
+// wfpCall calls a WFP function, the functions return a DWORD error code
+func wfpCall(proc *windows.LazyProc, args ...uintptr) error {
+	if err := proc.Find(); err != nil {
+		return err
+	}
+	ret, _, _ := proc.Call(args...)
+	if ret != 0 {
+		return fmt.Errorf("%s: %w", proc.Name, windows.Errno(ret))
+	}
+	return nil
+}
+
+func fwpmEngineOpen0(session *fwpmSession0) (uintptr, error) {
+	var engine uintptr
+	err := wfpCall(procFwpmEngineOpen0, 0, rpcCAuthnWinNT, 0, uintptr(unsafe.Pointer(session)), uintptr(unsafe.Pointer(&engine)))
+	return engine, err
+}
+
+func fwpmEngineClose0(engine uintptr) error {
+	return wfpCall(procFwpmEngineClose0, engine)
+}
+
+func fwpmSubLayerAdd0(engine uintptr, subLayer *fwpmSublayer0) error {
+	return wfpCall(procFwpmSubLayerAdd0, engine, uintptr(unsafe.Pointer(subLayer)), 0)
+}
+
+func fwpmFilterAdd0(engine uintptr, filter *fwpmFilter0) error {
+	var id uint64
+	return wfpCall(procFwpmFilterAdd0, engine, uintptr(unsafe.Pointer(filter)), 0, uintptr(unsafe.Pointer(&id)))
+}
+
+func fwpmTransactionBegin0(engine uintptr) error {
+	return wfpCall(procFwpmTransactionBegin0, engine, 0)
+}
+
+func fwpmTransactionCommit0(engine uintptr) error {
+	return wfpCall(procFwpmTransactionCommit0, engine)
+}
+
+func fwpmTransactionAbort0(engine uintptr) error {
+	return wfpCall(procFwpmTransactionAbort0, engine)
+}
+
+// fwpmGetAppIDFromFileName0 returns the application ID of a binary, it must be freed with fwpmFreeMemory0
+func fwpmGetAppIDFromFileName0(path string) (*fwpByteBlob, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, err
+	}
+	var appID *fwpByteBlob
+	if err := wfpCall(procFwpmGetAppIdFromFileName0, uintptr(unsafe.Pointer(pathPtr)), uintptr(unsafe.Pointer(&appID))); err != nil {
+		return nil, err
+	}
+	return appID, nil
+}
+
+func fwpmFreeMemory0(appID **fwpByteBlob) {
+	_, _, _ = procFwpmFreeMemory0.Call(uintptr(unsafe.Pointer(appID)))
+}
+
+func displayData(name string) fwpmDisplayData0 {
+	namePtr, _ := windows.UTF16PtrFromString(name)
+	return fwpmDisplayData0{name: namePtr, description: namePtr}
+}
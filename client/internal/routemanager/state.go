@@ -1,6 +1,7 @@
 package routemanager
 
 import (
+	"github.com/netbirdio/netbird/client/internal/routeapproval"
 	"github.com/netbirdio/netbird/client/internal/routeselector"
 )
 
@@ -17,3 +18,17 @@ func (s *SelectorState) MarshalJSON() ([]byte, error) {
 func (s *SelectorState) UnmarshalJSON(data []byte) error {
 	return (*routeselector.RouteSelector)(s).UnmarshalJSON(data)
 }
+
+type ApprovalState routeapproval.RouteApprover
+
+func (s *ApprovalState) Name() string {
+	return "routeapproval_state"
+}
+
+func (s *ApprovalState) MarshalJSON() ([]byte, error) {
+	return (*routeapproval.RouteApprover)(s).MarshalJSON()
+}
+
+func (s *ApprovalState) UnmarshalJSON(data []byte) error {
+	return (*routeapproval.RouteApprover)(s).UnmarshalJSON(data)
+}
@@ -63,9 +63,23 @@ func Setup(wgIface iface.WGIface) (map[string]int, error) {
 	return keys, nberrors.FormatErrorOrNil(result)
 }
 
+// Get returns the value of a sysctl configuration
+func Get(key string) (int, error) {
+	currentValue, err := os.ReadFile(sysctlPath(key))
+	if err != nil {
+		return -1, fmt.Errorf("read sysctl %s: %w", key, err)
+	}
+
+	value, err := strconv.Atoi(strings.TrimSpace(string(currentValue)))
+	if err != nil {
+		return -1, fmt.Errorf("convert sysctl %s value to int: %w", key, err)
+	}
+	return value, nil
+}
+
 // Set sets a sysctl configuration, if onlyIfOne is true it will only set the new value if it's set to 1
 func Set(key string, desiredValue int, onlyIfOne bool) (int, error) {
-	path := fmt.Sprintf("/proc/sys/%s", strings.ReplaceAll(key, ".", "/"))
+	path := sysctlPath(key)
 	currentValue, err := os.ReadFile(path)
 	if err != nil {
 		return -1, fmt.Errorf("read sysctl %s: %w", key, err)
@@ -89,6 +103,10 @@ func Set(key string, desiredValue int, onlyIfOne bool) (int, error) {
 	return currentV, nil
 }
 
+func sysctlPath(key string) string {
+	return fmt.Sprintf("/proc/sys/%s", strings.ReplaceAll(key, ".", "/"))
+}
+
 // Cleanup resets sysctl settings to their original values.
 func Cleanup(originalSettings map[string]int) error {
 	var result *multierror.Error
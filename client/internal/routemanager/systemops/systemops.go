@@ -28,6 +28,9 @@ type SysOps struct {
 	mu sync.Mutex
 	// notifier is used to notify the system of route changes (also used on mobile)
 	notifier *notifier.Notifier
+	// splitTunnel routes only the traffic of the processes selected for split tunneling through the VPN table
+	//nolint
+	splitTunnel bool
 }
 
 func NewSysOps(wgInterface iface.WGIface, notifier *notifier.Notifier) *SysOps {
@@ -36,3 +39,8 @@ func NewSysOps(wgInterface iface.WGIface, notifier *notifier.Notifier) *SysOps {
 		notifier:    notifier,
 	}
 }
+
+// SetSplitTunnel enables the split tunneling routing rules, it must be called before SetupRouting
+func (r *SysOps) SetSplitTunnel(enabled bool) {
+	r.splitTunnel = enabled
+}
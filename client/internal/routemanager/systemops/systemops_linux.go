@@ -23,8 +23,6 @@ import (
 )
 
 const (
-	// NetbirdVPNTableID is the ID of the custom routing table used by Netbird.
-	NetbirdVPNTableID = 0x1BD0
 	// NetbirdVPNTableName is the name of the custom routing table used by Netbird.
 	NetbirdVPNTableName = "netbird"
 
@@ -53,12 +51,20 @@ type ruleParams struct {
 	description    string
 }
 
-func getSetupRules() []ruleParams {
+func getSetupRules(splitTunnel bool) []ruleParams {
+	if splitTunnel {
+		return []ruleParams{
+			{100, -1, syscall.RT_TABLE_MAIN, netlink.FAMILY_V4, false, 0, "rule with suppress prefixlen v4"},
+			{100, -1, syscall.RT_TABLE_MAIN, netlink.FAMILY_V6, false, 0, "rule with suppress prefixlen v6"},
+			{110, int(nbnet.SplitTunnelFwmark), nbnet.RoutingTableID, netlink.FAMILY_V4, false, -1, "rule v4 netbird split tunnel"},
+			{110, int(nbnet.SplitTunnelFwmark), nbnet.RoutingTableID, netlink.FAMILY_V6, false, -1, "rule v6 netbird split tunnel"},
+		}
+	}
 	return []ruleParams{
 		{100, -1, syscall.RT_TABLE_MAIN, netlink.FAMILY_V4, false, 0, "rule with suppress prefixlen v4"},
 		{100, -1, syscall.RT_TABLE_MAIN, netlink.FAMILY_V6, false, 0, "rule with suppress prefixlen v6"},
-		{110, nbnet.NetbirdFwmark, NetbirdVPNTableID, netlink.FAMILY_V4, true, -1, "rule v4 netbird"},
-		{110, nbnet.NetbirdFwmark, NetbirdVPNTableID, netlink.FAMILY_V6, true, -1, "rule v6 netbird"},
+		{110, int(nbnet.NetbirdFwmark), nbnet.RoutingTableID, netlink.FAMILY_V4, true, -1, "rule v4 netbird"},
+		{110, int(nbnet.NetbirdFwmark), nbnet.RoutingTableID, netlink.FAMILY_V6, true, -1, "rule v6 netbird"},
 	}
 }
 
@@ -69,9 +75,10 @@ func getSetupRules() []ruleParams {
 // potential routes received and configured for the VPN.  This rule is skipped for the default route and routes
 // that are not in the main table.
 //
-// Rule 2 (VPN Traffic Routing): Directs all remaining traffic to the 'NetbirdVPNTableID' custom routing table.
+// Rule 2 (VPN Traffic Routing): Directs all remaining traffic to the NetBird custom routing table.
 // This table is where a default route or other specific routes received from the management server are configured,
-// enabling VPN connectivity.
+// enabling VPN connectivity. With split tunneling only the traffic marked with the split tunnel fwmark is directed
+// to the table.
 func (r *SysOps) SetupRouting(initAddresses []net.IP, stateManager *statemanager.Manager) (_ nbnet.AddHookFunc, _ nbnet.RemoveHookFunc, err error) {
 	if !nbnet.AdvancedRouting() {
 		log.Infof("Using legacy routing setup")
@@ -86,7 +93,7 @@ func (r *SysOps) SetupRouting(initAddresses []net.IP, stateManager *statemanager
 		}
 	}()
 
-	rules := getSetupRules()
+	rules := getSetupRules(r.splitTunnel)
 	for _, rule := range rules {
 		if err := addRule(rule); err != nil {
 			return nil, nil, fmt.Errorf("%s: %w", rule.description, err)
@@ -117,14 +124,15 @@ func (r *SysOps) CleanupRouting(stateManager *statemanager.Manager) error {
 
 	var result *multierror.Error
 
-	if err := flushRoutes(NetbirdVPNTableID, netlink.FAMILY_V4); err != nil {
+	if err := flushRoutes(nbnet.RoutingTableID, netlink.FAMILY_V4); err != nil {
 		result = multierror.Append(result, fmt.Errorf("flush routes v4: %w", err))
 	}
-	if err := flushRoutes(NetbirdVPNTableID, netlink.FAMILY_V6); err != nil {
+	if err := flushRoutes(nbnet.RoutingTableID, netlink.FAMILY_V6); err != nil {
 		result = multierror.Append(result, fmt.Errorf("flush routes v6: %w", err))
 	}
 
-	rules := getSetupRules()
+	// remove the rules of both modes, the split tunneling setting might have changed since the rules were added
+	rules := append(getSetupRules(false), getSetupRules(true)...)
 	for _, rule := range rules {
 		if err := removeRule(rule); err != nil {
 			result = multierror.Append(result, fmt.Errorf("%s: %w", rule.description, err))
@@ -161,11 +169,11 @@ func (r *SysOps) AddVPNRoute(prefix netip.Prefix, intf *net.Interface) error {
 
 	// TODO remove this once we have ipv6 support
 	if prefix == vars.Defaultv4 {
-		if err := addUnreachableRoute(vars.Defaultv6, NetbirdVPNTableID); err != nil {
+		if err := addUnreachableRoute(vars.Defaultv6, nbnet.RoutingTableID); err != nil {
 			return fmt.Errorf("add blackhole: %w", err)
 		}
 	}
-	if err := addRoute(prefix, Nexthop{netip.Addr{}, intf}, NetbirdVPNTableID); err != nil {
+	if err := addRoute(prefix, Nexthop{netip.Addr{}, intf}, nbnet.RoutingTableID); err != nil {
 		return fmt.Errorf("add route: %w", err)
 	}
 	return nil
@@ -178,11 +186,11 @@ func (r *SysOps) RemoveVPNRoute(prefix netip.Prefix, intf *net.Interface) error
 
 	// TODO remove this once we have ipv6 support
 	if prefix == vars.Defaultv4 {
-		if err := removeUnreachableRoute(vars.Defaultv6, NetbirdVPNTableID); err != nil {
+		if err := removeUnreachableRoute(vars.Defaultv6, nbnet.RoutingTableID); err != nil {
 			return fmt.Errorf("remove unreachable route: %w", err)
 		}
 	}
-	if err := removeRoute(prefix, Nexthop{netip.Addr{}, intf}, NetbirdVPNTableID); err != nil {
+	if err := removeRoute(prefix, Nexthop{netip.Addr{}, intf}, nbnet.RoutingTableID); err != nil {
 		return fmt.Errorf("remove route: %w", err)
 	}
 	return nil
@@ -397,9 +405,9 @@ func addRoutingTableName() error {
 		}
 	}()
 
-	exists, err := entryExists(file, NetbirdVPNTableID)
+	exists, err := entryExists(file, nbnet.RoutingTableID)
 	if err != nil {
-		return fmt.Errorf("verify entry %d, %s: %w", NetbirdVPNTableID, NetbirdVPNTableName, err)
+		return fmt.Errorf("verify entry %d, %s: %w", nbnet.RoutingTableID, NetbirdVPNTableName, err)
 	}
 	if exists {
 		return nil
@@ -414,7 +422,7 @@ func addRoutingTableName() error {
 		return fmt.Errorf("open rt_tables for appending: %w", err)
 	}
 
-	if _, err := file.WriteString(fmt.Sprintf("\n%d\t%s\n", NetbirdVPNTableID, NetbirdVPNTableName)); err != nil {
+	if _, err := file.WriteString(fmt.Sprintf("\n%d\t%s\n", nbnet.RoutingTableID, NetbirdVPNTableName)); err != nil {
 		return fmt.Errorf("append entry to rt_tables: %w", err)
 	}
 
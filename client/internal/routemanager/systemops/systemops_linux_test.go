@@ -16,6 +16,7 @@ import (
 	"github.com/vishvananda/netlink"
 
 	"github.com/netbirdio/netbird/client/internal/routemanager/vars"
+	nbnet "github.com/netbirdio/netbird/util/net"
 )
 
 var expectedVPNint = "wgtest0"
@@ -48,7 +49,7 @@ func TestEntryExists(t *testing.T) {
 
 	content := []string{
 		"1000 reserved",
-		fmt.Sprintf("%d %s", NetbirdVPNTableID, NetbirdVPNTableName),
+		fmt.Sprintf("%d %s", nbnet.RoutingTableID, NetbirdVPNTableName),
 		"9999 other_table",
 	}
 	require.NoError(t, os.WriteFile(tempFilePath, []byte(strings.Join(content, "\n")), 0644))
@@ -0,0 +1,23 @@
+// Package routermode prepares the host for the router mode of the client, in which a Raspberry Pi or a VM runs as
+// the gateway appliance of the LANs behind it: IP forwarding is enabled and the connection tracking and the socket
+// buffers are sized for the traffic of a whole network instead of a single host.
+package routermode
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+// ValidateLANs checks that the advertised LANs are valid prefixes
+func ValidateLANs(lans []string) error {
+	for _, lan := range lans {
+		prefix, err := netip.ParsePrefix(lan)
+		if err != nil {
+			return fmt.Errorf("invalid LAN %q: %w", lan, err)
+		}
+		if prefix != prefix.Masked() {
+			return fmt.Errorf("invalid LAN %q: the prefix has host bits set, use %s", lan, prefix.Masked())
+		}
+	}
+	return nil
+}
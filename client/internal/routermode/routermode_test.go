@@ -0,0 +1,14 @@
+package routermode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateLANs(t *testing.T) {
+	assert.NoError(t, ValidateLANs(nil))
+	assert.NoError(t, ValidateLANs([]string{"192.168.1.0/24", "fd00:1::/64"}))
+	assert.Error(t, ValidateLANs([]string{"192.168.1.0"}), "a LAN without prefix length should be rejected")
+	assert.Error(t, ValidateLANs([]string{"192.168.1.1/24"}), "a LAN with host bits should be rejected")
+}
@@ -0,0 +1,87 @@
+//go:build !android
+
+package routermode
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-multierror"
+	log "github.com/sirupsen/logrus"
+
+	nberrors "github.com/netbirdio/netbird/client/errors"
+	"github.com/netbirdio/netbird/client/internal/routemanager/sysctl"
+	"github.com/netbirdio/netbird/client/internal/routemanager/systemops"
+)
+
+const (
+	conntrackMaxPath = "net.netfilter.nf_conntrack_max"
+	rmemMaxPath      = "net.core.rmem_max"
+	wmemMaxPath      = "net.core.wmem_max"
+
+	// conntrackMax is the minimum number of tracked connections in router mode
+	conntrackMax = 262144
+	// socketBufferSize is the minimum size of the socket buffers in router mode, it matches the buffer size
+	// requested by the WireGuard sockets
+	socketBufferSize = 7 * 1024 * 1024
+)
+
+// Tuning holds the host settings changed for the router mode
+type Tuning struct {
+	// original are the values of the settings before the tuning
+	original map[string]int
+}
+
+// Enable enables IP forwarding and raises the conntrack and socket buffer limits, the limits already above the
+// router mode values are kept
+func Enable() (*Tuning, error) {
+	if err := systemops.EnableIPForwarding(); err != nil {
+		return nil, fmt.Errorf("enable IP forwarding: %w", err)
+	}
+	log.Info("router mode: IP forwarding enabled")
+
+	t := &Tuning{original: map[string]int{}}
+	var result *multierror.Error
+
+	for key, value := range map[string]int{
+		conntrackMaxPath: conntrackMax,
+		rmemMaxPath:      socketBufferSize,
+		wmemMaxPath:      socketBufferSize,
+	} {
+		if err := t.raise(key, value); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
+	return t, nberrors.FormatErrorOrNil(result)
+}
+
+// raise sets the sysctl to the value if it's lower
+func (t *Tuning) raise(key string, value int) error {
+	current, err := sysctl.Get(key)
+	if err != nil {
+		return err
+	}
+	if current >= value {
+		return nil
+	}
+
+	original, err := sysctl.Set(key, value, false)
+	if err != nil {
+		return err
+	}
+	t.original[key] = original
+	log.Infof("router mode: raised %s from %d to %d", key, original, value)
+
+	return nil
+}
+
+// Restore resets the limits raised by Enable, IP forwarding stays enabled as other routes may rely on it
+func (t *Tuning) Restore() error {
+	if t == nil {
+		return nil
+	}
+
+	err := sysctl.Cleanup(t.original)
+	t.original = map[string]int{}
+	return err
+}
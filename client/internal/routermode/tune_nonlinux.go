@@ -0,0 +1,30 @@
+//go:build !linux || android
+
+package routermode
+
+import (
+	"fmt"
+	"runtime"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/client/internal/routemanager/systemops"
+)
+
+// Tuning holds the host settings changed for the router mode
+type Tuning struct{}
+
+// Enable enables IP forwarding, the conntrack and socket buffer tuning is only implemented on Linux
+func Enable() (*Tuning, error) {
+	if err := systemops.EnableIPForwarding(); err != nil {
+		return nil, fmt.Errorf("enable IP forwarding: %w", err)
+	}
+	log.Infof("router mode: conntrack and socket buffer tuning is not implemented on %s", runtime.GOOS)
+
+	return &Tuning{}, nil
+}
+
+// Restore is a no-op as nothing was tuned
+func (t *Tuning) Restore() error {
+	return nil
+}
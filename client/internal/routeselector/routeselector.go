@@ -10,6 +10,7 @@ import (
 	"golang.org/x/exp/maps"
 
 	"github.com/netbirdio/netbird/client/errors"
+	"github.com/netbirdio/netbird/client/internal/routemanager/exitnode"
 	route "github.com/netbirdio/netbird/route"
 )
 
@@ -17,6 +18,8 @@ type RouteSelector struct {
 	mu             sync.RWMutex
 	selectedRoutes map[route.NetID]struct{}
 	selectAll      bool
+	// exitNodeAuto selects the exit node with the lowest latency and loss instead of the selected ones
+	exitNodeAuto bool
 }
 
 func NewRouteSelector() *RouteSelector {
@@ -92,9 +95,26 @@ func (rs *RouteSelector) DeselectAllRoutes() {
 	defer rs.mu.Unlock()
 
 	rs.selectAll = false
+	rs.exitNodeAuto = false
 	rs.selectedRoutes = map[route.NetID]struct{}{}
 }
 
+// SetExitNodeAuto turns the automatic selection of the exit node on or off
+func (rs *RouteSelector) SetExitNodeAuto(enabled bool) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	rs.exitNodeAuto = enabled
+}
+
+// IsExitNodeAuto returns true if the exit node is selected automatically
+func (rs *RouteSelector) IsExitNodeAuto() bool {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	return rs.exitNodeAuto
+}
+
 // IsSelected checks if a specific route is selected.
 func (rs *RouteSelector) IsSelected(routeID route.NetID) bool {
 	rs.mu.RLock()
@@ -108,6 +128,7 @@ func (rs *RouteSelector) IsSelected(routeID route.NetID) bool {
 }
 
 // FilterSelected removes unselected routes from the provided map.
+// The exit nodes are kept in the automatic exit node selection, the route manager chooses one of them.
 func (rs *RouteSelector) FilterSelected(routes route.HAMap) route.HAMap {
 	rs.mu.RLock()
 	defer rs.mu.RUnlock()
@@ -118,7 +139,7 @@ func (rs *RouteSelector) FilterSelected(routes route.HAMap) route.HAMap {
 
 	filtered := route.HAMap{}
 	for id, rt := range routes {
-		if rs.IsSelected(id.NetID()) {
+		if rs.IsSelected(id.NetID()) || (rs.exitNodeAuto && exitnode.IsExitNode(rt)) {
 			filtered[id] = rt
 		}
 	}
@@ -133,9 +154,11 @@ func (rs *RouteSelector) MarshalJSON() ([]byte, error) {
 	return json.Marshal(struct {
 		SelectedRoutes map[route.NetID]struct{} `json:"selected_routes"`
 		SelectAll      bool                     `json:"select_all"`
+		ExitNodeAuto   bool                     `json:"exit_node_auto,omitempty"`
 	}{
 		SelectAll:      rs.selectAll,
 		SelectedRoutes: rs.selectedRoutes,
+		ExitNodeAuto:   rs.exitNodeAuto,
 	})
 }
 
@@ -155,6 +178,7 @@ func (rs *RouteSelector) UnmarshalJSON(data []byte) error {
 	var temp struct {
 		SelectedRoutes map[route.NetID]struct{} `json:"selected_routes"`
 		SelectAll      bool                     `json:"select_all"`
+		ExitNodeAuto   bool                     `json:"exit_node_auto"`
 	}
 
 	if err := json.Unmarshal(data, &temp); err != nil {
@@ -163,6 +187,7 @@ func (rs *RouteSelector) UnmarshalJSON(data []byte) error {
 
 	rs.selectedRoutes = temp.SelectedRoutes
 	rs.selectAll = temp.SelectAll
+	rs.exitNodeAuto = temp.ExitNodeAuto
 
 	if rs.selectedRoutes == nil {
 		rs.selectedRoutes = map[route.NetID]struct{}{}
@@ -1,6 +1,8 @@
 package routeselector_test
 
 import (
+	"encoding/json"
+	"net/netip"
 	"slices"
 	"testing"
 
@@ -274,6 +276,32 @@ func TestRouteSelector_FilterSelected(t *testing.T) {
 	}, filtered)
 }
 
+func TestRouteSelector_FilterSelectedExitNodeAuto(t *testing.T) {
+	rs := routeselector.NewRouteSelector()
+
+	err := rs.SelectRoutes([]route.NetID{"route1"}, false, []route.NetID{"route1", "exit1", "exit2"})
+	require.NoError(t, err)
+	rs.SetExitNodeAuto(true)
+
+	routes := route.HAMap{
+		"route1|10.0.0.0/8": {{NetID: "route1", Network: netip.MustParsePrefix("10.0.0.0/8")}},
+		"exit1|0.0.0.0/0":   {{NetID: "exit1", Network: netip.MustParsePrefix("0.0.0.0/0")}},
+		"exit2|0.0.0.0/0":   {{NetID: "exit2", Network: netip.MustParsePrefix("0.0.0.0/0")}},
+	}
+
+	assert.Equal(t, routes, rs.FilterSelected(routes), "the exit nodes should be kept for the automatic selection")
+
+	data, err := json.Marshal(rs)
+	require.NoError(t, err)
+	restored := routeselector.NewRouteSelector()
+	require.NoError(t, json.Unmarshal(data, restored))
+	assert.True(t, restored.IsExitNodeAuto())
+
+	rs.DeselectAllRoutes()
+	assert.False(t, rs.IsExitNodeAuto())
+	assert.Empty(t, rs.FilterSelected(routes))
+}
+
 func TestRouteSelector_NewRoutesBehavior(t *testing.T) {
 	initialRoutes := []route.NetID{"route1", "route2", "route3"}
 	newRoutes := []route.NetID{"route1", "route2", "route3", "route4", "route5"}
@@ -0,0 +1,60 @@
+//go:build !android
+
+package sleep
+
+import (
+	"fmt"
+
+	"github.com/godbus/dbus/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	logindManagerInterface      = "org.freedesktop.login1.Manager"
+	logindPrepareForSleepMember = "PrepareForSleep"
+)
+
+// listen reports the PrepareForSleep signals of logind, its argument is true before the sleep and false after the wake
+func listen(onEvent func(Event)) (func(), error) {
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		return nil, fmt.Errorf("connect dbus: %w", err)
+	}
+
+	if err := conn.AddMatchSignal(
+		dbus.WithMatchInterface(logindManagerInterface),
+		dbus.WithMatchMember(logindPrepareForSleepMember),
+	); err != nil {
+		if closeErr := conn.Close(); closeErr != nil {
+			log.Warnf("got an error closing dbus connection, err: %s", closeErr)
+		}
+		return nil, fmt.Errorf("add signal match: %w", err)
+	}
+
+	signals := make(chan *dbus.Signal, 16)
+	conn.Signal(signals)
+	go func() {
+		// the channel is closed with the connection
+		for signal := range signals {
+			if len(signal.Body) == 0 {
+				continue
+			}
+			sleeping, ok := signal.Body[0].(bool)
+			if !ok {
+				continue
+			}
+			if sleeping {
+				onEvent(EventSleep)
+			} else {
+				onEvent(EventWake)
+			}
+		}
+	}()
+
+	log.Debugf("listening for the sleep signals of logind")
+	return func() {
+		if err := conn.Close(); err != nil {
+			log.Debugf("failed to close the dbus connection: %v", err)
+		}
+	}, nil
+}
@@ -0,0 +1,7 @@
+//go:build (!linux && !windows) || android
+
+package sleep
+
+func listen(func(Event)) (func(), error) {
+	return nil, errNoNativeEvents
+}
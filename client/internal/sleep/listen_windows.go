@@ -0,0 +1,85 @@
+package sleep
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sys/windows"
+)
+
+const (
+	deviceNotifyCallback = 2
+
+	// pbtAPMSuspend is sent before the system suspends, it waits for the callbacks up to two seconds
+	pbtAPMSuspend = 0x4
+	// pbtAPMResumeAutomatic is sent on every resume, the resume triggered by the user is reported in addition
+	pbtAPMResumeAutomatic = 0x12
+)
+
+var (
+	modpowrprof                                  = windows.NewLazySystemDLL("powrprof.dll")
+	procPowerRegisterSuspendResumeNotification   = modpowrprof.NewProc("PowerRegisterSuspendResumeNotification")
+	procPowerUnregisterSuspendResumeNotification = modpowrprof.NewProc("PowerUnregisterSuspendResumeNotification")
+
+	// the callbacks created by windows.NewCallback are never freed, a single one dispatches to the listeners
+	powerCallback = sync.OnceValue(func() uintptr { return windows.NewCallback(onPowerEvent) })
+	listeners     sync.Map
+	lastListener  atomic.Uintptr
+)
+
+// deviceNotifySubscribeParameters is DEVICE_NOTIFY_SUBSCRIBE_PARAMETERS
+type deviceNotifySubscribeParameters struct {
+	callback uintptr
+	context  uintptr
+}
+
+// listen registers for the suspend and resume notifications of the system
+func listen(onEvent func(Event)) (func(), error) {
+	if err := procPowerRegisterSuspendResumeNotification.Find(); err != nil {
+		return nil, fmt.Errorf("find power notification api: %w", err)
+	}
+
+	id := lastListener.Add(1)
+	listeners.Store(id, onEvent)
+
+	params := &deviceNotifySubscribeParameters{
+		callback: powerCallback(),
+		context:  id,
+	}
+	var handle uintptr
+	if r1, _, _ := procPowerRegisterSuspendResumeNotification.Call(
+		deviceNotifyCallback,
+		uintptr(unsafe.Pointer(params)),
+		uintptr(unsafe.Pointer(&handle)),
+	); r1 != 0 {
+		listeners.Delete(id)
+		return nil, fmt.Errorf("register for suspend and resume notifications: %w", windows.Errno(r1))
+	}
+
+	log.Debugf("listening for the suspend and resume notifications")
+	return func() {
+		if r1, _, _ := procPowerUnregisterSuspendResumeNotification.Call(handle); r1 != 0 {
+			log.Debugf("failed to unregister the suspend and resume notifications: %v", windows.Errno(r1))
+		}
+		listeners.Delete(id)
+	}, nil
+}
+
+func onPowerEvent(context, eventType, _ uintptr) uintptr {
+	value, ok := listeners.Load(context)
+	if !ok {
+		return 0
+	}
+	onEvent := value.(func(Event))
+
+	switch eventType {
+	case pbtAPMSuspend:
+		onEvent(EventSleep)
+	case pbtAPMResumeAutomatic:
+		onEvent(EventWake)
+	}
+	return 0
+}
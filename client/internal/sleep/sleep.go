@@ -0,0 +1,129 @@
+// Package sleep detects when the system goes to sleep and when it wakes up. The native power events are used where
+// the daemon can receive them: logind on Linux and the suspend/resume notifications on Windows. Elsewhere, e.g. on
+// macOS where IOKit would need cgo, and when the native events are unavailable, the wake is detected from the wall
+// clock moving ahead of the monotonic clock, which stops while the system sleeps. No sleep event is reported then.
+package sleep
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Event is a power state change of the system
+type Event int
+
+const (
+	// EventSleep is reported before the system goes to sleep
+	EventSleep Event = iota
+	// EventWake is reported after the system woke up
+	EventWake
+)
+
+func (e Event) String() string {
+	switch e {
+	case EventSleep:
+		return "sleep"
+	case EventWake:
+		return "wake"
+	default:
+		return "unknown"
+	}
+}
+
+const (
+	clockCheckInterval = 5 * time.Second
+	// clockJumpThreshold is the gap between the wall clock and the monotonic clock taken for a sleep, smaller gaps
+	// are clock adjustments
+	clockJumpThreshold = 10 * time.Second
+)
+
+var errNoNativeEvents = errors.New("no native power events on this platform")
+
+var listenFn = listen
+
+// Detector reports the sleep and wake events of the system
+type Detector struct {
+	mu     sync.Mutex
+	stop   func()
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New creates a new sleep detector
+func New() *Detector {
+	return &Detector{}
+}
+
+// Start reports the events to onEvent until Stop is called. onEvent is called from the goroutine of the event source
+// and should return quickly, the system may wait for it before going to sleep.
+func (d *Detector) Start(onEvent func(Event)) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.stop != nil || d.cancel != nil {
+		return errors.New("sleep detector already started")
+	}
+
+	stop, err := listenFn(onEvent)
+	if err == nil {
+		d.stop = stop
+		return nil
+	}
+	if !errors.Is(err, errNoNativeEvents) {
+		log.Warnf("failed to listen for the power events of the system, detecting the wake from the clock: %v", err)
+	}
+
+	var ctx context.Context
+	ctx, d.cancel = context.WithCancel(context.Background())
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		watchClock(ctx, onEvent)
+	}()
+	return nil
+}
+
+// Stop stops reporting the events
+func (d *Detector) Stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.stop != nil {
+		d.stop()
+		d.stop = nil
+	}
+	if d.cancel != nil {
+		d.cancel()
+		d.wg.Wait()
+		d.cancel = nil
+	}
+}
+
+// watchClock reports a wake when the wall clock moved ahead of the monotonic clock since the last check
+func watchClock(ctx context.Context, onEvent func(Event)) {
+	ticker := time.NewTicker(clockCheckInterval)
+	defer ticker.Stop()
+
+	last := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if slept := clockGap(last, now); slept > clockJumpThreshold {
+				log.Infof("the system woke up after sleeping for about %s", slept.Round(time.Second))
+				onEvent(EventWake)
+			}
+			last = now
+		}
+	}
+}
+
+// clockGap returns how much more the wall clock advanced than the monotonic clock between last and now
+func clockGap(last, now time.Time) time.Duration {
+	return now.Round(0).Sub(last.Round(0)) - now.Sub(last)
+}
@@ -0,0 +1,52 @@
+package sleep
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetector_Start(t *testing.T) {
+	var report func(Event)
+	var stopped bool
+	listenFn = func(onEvent func(Event)) (func(), error) {
+		report = onEvent
+		return func() { stopped = true }, nil
+	}
+	defer func() { listenFn = listen }()
+
+	var events []Event
+	d := New()
+	require.NoError(t, d.Start(func(e Event) { events = append(events, e) }))
+	assert.Error(t, d.Start(func(Event) {}), "a second start should fail")
+
+	report(EventSleep)
+	report(EventWake)
+	assert.Equal(t, []Event{EventSleep, EventWake}, events)
+
+	d.Stop()
+	assert.True(t, stopped, "the native listener should be stopped")
+	d.Stop()
+}
+
+func TestDetector_StartWithoutNativeEvents(t *testing.T) {
+	listenFn = func(func(Event)) (func(), error) {
+		return nil, errors.New("no system bus")
+	}
+	defer func() { listenFn = listen }()
+
+	d := New()
+	require.NoError(t, d.Start(func(Event) {}), "the detector should fall back to the clock")
+	d.Stop()
+	require.NoError(t, d.Start(func(Event) {}), "the detector should start again after a stop")
+	d.Stop()
+}
+
+func TestClockGap(t *testing.T) {
+	last := time.Now()
+	assert.Zero(t, clockGap(last, last.Add(clockCheckInterval)), "the clocks advance together while awake")
+	assert.Zero(t, clockGap(last.Round(0), last.Add(time.Hour).Round(0)), "the times without monotonic clock can't tell")
+}
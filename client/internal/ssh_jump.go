@@ -0,0 +1,77 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/netbirdio/netbird/client/internal/acl"
+	cProto "github.com/netbirdio/netbird/client/proto"
+	nbssh "github.com/netbirdio/netbird/client/ssh"
+	mgmProto "github.com/netbirdio/netbird/management/proto"
+)
+
+// sshJumpPolicy lets the peers jump through the embedded SSH server of a routing peer to the hosts of its routed
+// networks, as far as the access policies of the routes allow the peers to reach them
+type sshJumpPolicy struct {
+	engine *Engine
+
+	mu    sync.Mutex
+	rules []*mgmProto.RouteFirewallRule
+}
+
+// updateRules replaces the route firewall rules of the latest network map
+func (p *sshJumpPolicy) updateRules(rules []*mgmProto.RouteFirewallRule) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.rules = rules
+}
+
+// Authorize checks that the SSH key belongs to the peer connected from src and that a route access policy lets the
+// peer reach dst
+func (p *sshJumpPolicy) Authorize(peerKey string, src netip.Addr, dst netip.AddrPort) error {
+	peerIP, ok := p.engine.peerStore.AllowedIP(peerKey)
+	if !ok {
+		return errors.New("unknown peer")
+	}
+	// the NetBird IP of the connection is authenticated by WireGuard, the SSH key must be the one of the same peer
+	if peerIP != src {
+		return fmt.Errorf("the key of peer %s is used from %s", peerIP, src)
+	}
+
+	p.mu.Lock()
+	rules := p.rules
+	p.mu.Unlock()
+
+	if !acl.RouteAllowed(rules, src, dst) {
+		return fmt.Errorf("no route access policy allows %s to reach %s", src, dst)
+	}
+	return nil
+}
+
+// Audit publishes the finished jump to the events of the daemon
+func (p *sshJumpPolicy) Audit(audit nbssh.JumpAudit) {
+	fqdn, _ := p.engine.statusRecorder.PeerByIP(audit.Source.String())
+	if fqdn == "" {
+		fqdn = audit.Source.String()
+	}
+
+	p.engine.statusRecorder.PublishEvent(
+		cProto.SystemEvent_INFO,
+		cProto.SystemEvent_SYSTEM,
+		"SSH jump",
+		fmt.Sprintf("User %s of peer %s jumped to %s for %s.", audit.User, fqdn, audit.Dest, audit.Duration.Round(time.Second)),
+		map[string]string{
+			"peer":        fqdn,
+			"source":      audit.Source.String(),
+			"user":        audit.User,
+			"destination": audit.Dest.String(),
+			"duration":    audit.Duration.Round(time.Second).String(),
+			"bytes_in":    fmt.Sprint(audit.BytesIn),
+			"bytes_out":   fmt.Sprint(audit.BytesOut),
+		},
+	)
+}
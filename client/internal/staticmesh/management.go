@@ -0,0 +1,95 @@
+package staticmesh
+
+import (
+	"context"
+	"errors"
+
+	log "github.com/sirupsen/logrus"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+
+	"github.com/netbirdio/netbird/client/system"
+	mgm "github.com/netbirdio/netbird/management/client"
+	"github.com/netbirdio/netbird/management/domain"
+	mgmProto "github.com/netbirdio/netbird/management/proto"
+)
+
+var errNoManagement = errors.New("not supported in static mesh mode")
+
+var _ mgm.Client = (*ManagementClient)(nil)
+
+// ManagementClient serves the network map of the mesh in place of the management service
+type ManagementClient struct {
+	mesh     *Mesh
+	localKey string
+}
+
+// NewManagementClient returns a client serving the network map of the mesh to the local peer
+func NewManagementClient(mesh *Mesh, localKey wgtypes.Key) *ManagementClient {
+	return &ManagementClient{
+		mesh:     mesh,
+		localKey: localKey.String(),
+	}
+}
+
+func (c *ManagementClient) Close() error {
+	return nil
+}
+
+// Sync sends the network map of the mesh once and blocks until the context is done
+func (c *ManagementClient) Sync(ctx context.Context, _ *system.Info, msgHandler func(msg *mgmProto.SyncResponse) error) error {
+	if err := msgHandler(&mgmProto.SyncResponse{NetworkMap: c.mesh.NetworkMap(c.localKey)}); err != nil {
+		return err
+	}
+
+	<-ctx.Done()
+	return nil
+}
+
+func (c *ManagementClient) GetServerPublicKey() (*wgtypes.Key, error) {
+	return nil, errNoManagement
+}
+
+func (c *ManagementClient) Register(wgtypes.Key, string, string, *system.Info, []byte, domain.List) (*mgmProto.LoginResponse, error) {
+	return nil, errNoManagement
+}
+
+func (c *ManagementClient) Login(wgtypes.Key, *system.Info, []byte, domain.List) (*mgmProto.LoginResponse, error) {
+	return nil, errNoManagement
+}
+
+func (c *ManagementClient) GetDeviceAuthorizationFlow(wgtypes.Key) (*mgmProto.DeviceAuthorizationFlow, error) {
+	return nil, errNoManagement
+}
+
+func (c *ManagementClient) GetPKCEAuthorizationFlow(wgtypes.Key) (*mgmProto.PKCEAuthorizationFlow, error) {
+	return nil, errNoManagement
+}
+
+func (c *ManagementClient) GetNetworkMap(*system.Info) (*mgmProto.NetworkMap, error) {
+	return c.mesh.NetworkMap(c.localKey), nil
+}
+
+func (c *ManagementClient) IsHealthy() bool {
+	return true
+}
+
+func (c *ManagementClient) SyncMeta(*system.Info) error {
+	return nil
+}
+
+func (c *ManagementClient) ReportProbeResult(result *mgmProto.ProbeResult) error {
+	log.Debugf("dropping the result of probe %s, there is no management service", result.GetId())
+	return nil
+}
+
+func (c *ManagementClient) ReportDebugBundleStatus(*mgmProto.DebugBundleStatus) error {
+	return errNoManagement
+}
+
+func (c *ManagementClient) ReportConnectivity(*mgmProto.ConnectivityReport) error {
+	return nil
+}
+
+func (c *ManagementClient) ReportPeerActionStatus(*mgmProto.PeerActionStatus) error {
+	return errNoManagement
+}
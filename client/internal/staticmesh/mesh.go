@@ -0,0 +1,195 @@
+// Package staticmesh runs the client with the peers defined in a local file instead of the management and signal
+// services, for air-gapped networks and appliances with a static configuration.
+package staticmesh
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+
+	mgmProto "github.com/netbirdio/netbird/management/proto"
+	"github.com/netbirdio/netbird/route"
+	"github.com/netbirdio/netbird/util"
+)
+
+// meshSerial is the serial of the network map of the mesh, the file is only read when the client starts
+const meshSerial = 1
+
+// Mesh is the declarative definition of the network of the local peer
+type Mesh struct {
+	// Address is the address of the local peer with the prefix of the mesh network, e.g. 100.64.0.1/16
+	Address string
+	// FQDN is the domain name of the local peer, optional
+	FQDN string
+	// Peers are the remote peers of the mesh
+	Peers []Peer
+	// Routes are the networks routed through the peers of the mesh
+	Routes []Route
+}
+
+// Peer is a remote peer of the mesh
+type Peer struct {
+	// PublicKey is the WireGuard public key of the peer
+	PublicKey string
+	// Endpoint is the host:port address the WireGuard interface of the peer listens on. The peers without an endpoint
+	// are reached once they connect to the local peer.
+	Endpoint string
+	// AllowedIPs are the prefixes routed to the peer, the first one being the address of the peer in the mesh
+	AllowedIPs []string
+	// FQDN is the domain name of the peer, optional
+	FQDN string
+}
+
+// Route is a network routed through a peer of the mesh
+type Route struct {
+	// Network is the prefix of the routed network
+	Network string
+	// Peer is the public key of the routing peer, the local peer routes the network if it's empty
+	Peer       string
+	Masquerade bool
+	Metric     int
+}
+
+// Load reads and validates the mesh file
+func Load(path string) (*Mesh, error) {
+	mesh := &Mesh{}
+	if _, err := util.ReadJson(path, mesh); err != nil {
+		return nil, fmt.Errorf("read mesh file %s: %w", path, err)
+	}
+
+	if err := mesh.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid mesh file %s: %w", path, err)
+	}
+	return mesh, nil
+}
+
+// Validate checks the addresses, keys and references of the mesh
+func (m *Mesh) Validate() error {
+	if _, err := netip.ParsePrefix(m.Address); err != nil {
+		return fmt.Errorf("invalid address %q: %w", m.Address, err)
+	}
+
+	keys := make(map[string]struct{}, len(m.Peers))
+	for _, peer := range m.Peers {
+		if _, err := wgtypes.ParseKey(peer.PublicKey); err != nil {
+			return fmt.Errorf("invalid public key %q: %w", peer.PublicKey, err)
+		}
+		if _, ok := keys[peer.PublicKey]; ok {
+			return fmt.Errorf("duplicate peer %s", peer.PublicKey)
+		}
+		keys[peer.PublicKey] = struct{}{}
+
+		if len(peer.AllowedIPs) == 0 {
+			return fmt.Errorf("peer %s has no allowed IPs", peer.PublicKey)
+		}
+		for _, allowedIP := range peer.AllowedIPs {
+			if _, err := netip.ParsePrefix(allowedIP); err != nil {
+				return fmt.Errorf("invalid allowed IP %q of peer %s: %w", allowedIP, peer.PublicKey, err)
+			}
+		}
+
+		if peer.Endpoint != "" {
+			if _, _, err := net.SplitHostPort(peer.Endpoint); err != nil {
+				return fmt.Errorf("invalid endpoint %q of peer %s: %w", peer.Endpoint, peer.PublicKey, err)
+			}
+		}
+	}
+
+	for _, r := range m.Routes {
+		if _, _, err := route.ParseNetwork(r.Network); err != nil {
+			return fmt.Errorf("invalid route network %q: %w", r.Network, err)
+		}
+		if _, ok := keys[r.Peer]; r.Peer != "" && !ok {
+			return fmt.Errorf("route %s references the unknown peer %s", r.Network, r.Peer)
+		}
+	}
+
+	return nil
+}
+
+// PeerConfig returns the configuration of the local peer
+func (m *Mesh) PeerConfig() *mgmProto.PeerConfig {
+	return &mgmProto.PeerConfig{
+		Address: m.Address,
+		Fqdn:    m.FQDN,
+	}
+}
+
+// Endpoints resolves the endpoints of the peers by public key
+func (m *Mesh) Endpoints() (map[string]*net.UDPAddr, error) {
+	endpoints := make(map[string]*net.UDPAddr)
+	for _, peer := range m.Peers {
+		if peer.Endpoint == "" {
+			continue
+		}
+		addr, err := net.ResolveUDPAddr("udp", peer.Endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("resolve endpoint %s of peer %s: %w", peer.Endpoint, peer.PublicKey, err)
+		}
+		endpoints[peer.PublicKey] = addr
+	}
+	return endpoints, nil
+}
+
+// NetworkMap returns the network map of the mesh for the local peer. The traffic between the peers of the mesh and
+// to the networks routed by the local peer is allowed.
+func (m *Mesh) NetworkMap(localKey string) *mgmProto.NetworkMap {
+	networkMap := &mgmProto.NetworkMap{
+		Serial:     meshSerial,
+		PeerConfig: m.PeerConfig(),
+		DNSConfig:  &mgmProto.DNSConfig{},
+	}
+
+	var sourceRanges []string
+	for _, peer := range m.Peers {
+		networkMap.RemotePeers = append(networkMap.RemotePeers, &mgmProto.RemotePeerConfig{
+			WgPubKey:   peer.PublicKey,
+			AllowedIps: peer.AllowedIPs,
+			Fqdn:       peer.FQDN,
+		})
+
+		peerIP := netip.MustParsePrefix(peer.AllowedIPs[0]).Addr()
+		sourceRanges = append(sourceRanges, netip.PrefixFrom(peerIP, peerIP.BitLen()).String())
+		for _, direction := range []mgmProto.RuleDirection{mgmProto.RuleDirection_IN, mgmProto.RuleDirection_OUT} {
+			networkMap.FirewallRules = append(networkMap.FirewallRules, &mgmProto.FirewallRule{
+				PeerIP:    peerIP.String(),
+				Direction: direction,
+				Action:    mgmProto.RuleAction_ACCEPT,
+				Protocol:  mgmProto.RuleProtocol_ALL,
+			})
+		}
+	}
+	networkMap.RemotePeersIsEmpty = len(networkMap.RemotePeers) == 0
+	networkMap.FirewallRulesIsEmpty = len(networkMap.FirewallRules) == 0
+
+	for i, r := range m.Routes {
+		networkType, _, _ := route.ParseNetwork(r.Network)
+		peer := r.Peer
+		if peer == "" {
+			peer = localKey
+		}
+		networkMap.Routes = append(networkMap.Routes, &mgmProto.Route{
+			ID:          fmt.Sprintf("static-%d", i),
+			Network:     r.Network,
+			NetworkType: int64(networkType),
+			Peer:        peer,
+			Metric:      int64(r.Metric),
+			Masquerade:  r.Masquerade,
+			NetID:       r.Network,
+		})
+
+		if peer == localKey && len(sourceRanges) > 0 {
+			networkMap.RoutesFirewallRules = append(networkMap.RoutesFirewallRules, &mgmProto.RouteFirewallRule{
+				SourceRanges: sourceRanges,
+				Action:       mgmProto.RuleAction_ACCEPT,
+				Destination:  r.Network,
+				Protocol:     mgmProto.RuleProtocol_ALL,
+			})
+		}
+	}
+	networkMap.RoutesFirewallRulesIsEmpty = len(networkMap.RoutesFirewallRules) == 0
+
+	return networkMap
+}
@@ -0,0 +1,100 @@
+package staticmesh
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mgmProto "github.com/netbirdio/netbird/management/proto"
+)
+
+const (
+	localKey = "RlSy2vzoG2HyMBTUImXOiVhCBiiBa5qD5xzMxkiFDW4="
+	peerAKey = "Zx8zaFrQmZqNBk6Kt54jBWy6P2UZ/FSr8wSCU1FTn0E="
+	peerBKey = "5ISTpC+NEsRWfhI1xXA82BKhr2A3yFb1ZVE1oMcu2Hs="
+)
+
+const testMesh = `{
+  "Address": "100.90.0.1/16",
+  "Peers": [
+    {"PublicKey": "` + peerAKey + `", "Endpoint": "192.0.2.10:51820", "AllowedIPs": ["100.90.0.2/32", "10.10.0.0/24"], "FQDN": "lab-a"},
+    {"PublicKey": "` + peerBKey + `", "AllowedIPs": ["100.90.0.3/32"]}
+  ],
+  "Routes": [
+    {"Network": "10.10.0.0/24", "Peer": "` + peerAKey + `"},
+    {"Network": "172.16.0.0/16", "Masquerade": true}
+  ]
+}`
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mesh.json")
+	require.NoError(t, os.WriteFile(path, []byte(testMesh), 0600))
+
+	mesh, err := Load(path)
+	require.NoError(t, err)
+	require.Len(t, mesh.Peers, 2)
+
+	endpoints, err := mesh.Endpoints()
+	require.NoError(t, err)
+	require.Len(t, endpoints, 1)
+	assert.Equal(t, "192.0.2.10:51820", endpoints[peerAKey].String())
+}
+
+func TestMesh_Validate(t *testing.T) {
+	peer := Peer{PublicKey: peerAKey, AllowedIPs: []string{"100.90.0.2/32"}}
+
+	tests := []struct {
+		name  string
+		mesh  Mesh
+		valid bool
+	}{
+		{name: "valid", mesh: Mesh{Address: "100.90.0.1/16", Peers: []Peer{peer}}, valid: true},
+		{name: "invalid address", mesh: Mesh{Address: "100.90.0.1", Peers: []Peer{peer}}},
+		{name: "invalid key", mesh: Mesh{Address: "100.90.0.1/16", Peers: []Peer{{PublicKey: "key", AllowedIPs: []string{"100.90.0.2/32"}}}}},
+		{name: "duplicate peer", mesh: Mesh{Address: "100.90.0.1/16", Peers: []Peer{peer, peer}}},
+		{name: "no allowed IPs", mesh: Mesh{Address: "100.90.0.1/16", Peers: []Peer{{PublicKey: peerAKey}}}},
+		{name: "invalid endpoint", mesh: Mesh{Address: "100.90.0.1/16", Peers: []Peer{{PublicKey: peerAKey, AllowedIPs: []string{"100.90.0.2/32"}, Endpoint: "192.0.2.10"}}}},
+		{name: "unknown route peer", mesh: Mesh{Address: "100.90.0.1/16", Peers: []Peer{peer}, Routes: []Route{{Network: "10.0.0.0/8", Peer: peerBKey}}}},
+		{name: "domain route", mesh: Mesh{Address: "100.90.0.1/16", Peers: []Peer{peer}, Routes: []Route{{Network: "example.com"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.mesh.Validate()
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestMesh_NetworkMap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mesh.json")
+	require.NoError(t, os.WriteFile(path, []byte(testMesh), 0600))
+	mesh, err := Load(path)
+	require.NoError(t, err)
+
+	networkMap := mesh.NetworkMap(localKey)
+
+	assert.Equal(t, "100.90.0.1/16", networkMap.GetPeerConfig().GetAddress())
+	require.Len(t, networkMap.GetRemotePeers(), 2)
+	assert.Equal(t, []string{"100.90.0.2/32", "10.10.0.0/24"}, networkMap.GetRemotePeers()[0].GetAllowedIps())
+	assert.Equal(t, "lab-a", networkMap.GetRemotePeers()[0].GetFqdn())
+
+	require.Len(t, networkMap.GetFirewallRules(), 4)
+	assert.Equal(t, "100.90.0.2", networkMap.GetFirewallRules()[0].GetPeerIP())
+	assert.Equal(t, mgmProto.RuleAction_ACCEPT, networkMap.GetFirewallRules()[0].GetAction())
+
+	require.Len(t, networkMap.GetRoutes(), 2)
+	assert.Equal(t, peerAKey, networkMap.GetRoutes()[0].GetPeer())
+	assert.Equal(t, localKey, networkMap.GetRoutes()[1].GetPeer())
+
+	require.Len(t, networkMap.GetRoutesFirewallRules(), 1, "only the routes of the local peer need firewall rules")
+	assert.Equal(t, "172.16.0.0/16", networkMap.GetRoutesFirewallRules()[0].GetDestination())
+	assert.Equal(t, []string{"100.90.0.2/32", "100.90.0.3/32"}, networkMap.GetRoutesFirewallRules()[0].GetSourceRanges())
+}
@@ -0,0 +1,57 @@
+package staticmesh
+
+import (
+	"context"
+
+	signal "github.com/netbirdio/netbird/signal/client"
+	sProto "github.com/netbirdio/netbird/signal/proto"
+)
+
+var _ signal.Client = (*SignalClient)(nil)
+
+// SignalClient replaces the signal service in the static mesh mode. The connections to the peers aren't negotiated,
+// the messages are dropped.
+type SignalClient struct{}
+
+// NewSignalClient returns a signal client dropping the messages
+func NewSignalClient() *SignalClient {
+	return &SignalClient{}
+}
+
+func (c *SignalClient) Close() error {
+	return nil
+}
+
+func (c *SignalClient) StreamConnected() bool {
+	return true
+}
+
+func (c *SignalClient) GetStatus() signal.Status {
+	return signal.StreamConnected
+}
+
+// Receive blocks until the context is done, no messages are received
+func (c *SignalClient) Receive(ctx context.Context, _ func(msg *sProto.Message) error) error {
+	<-ctx.Done()
+	return nil
+}
+
+func (c *SignalClient) Ready() bool {
+	return true
+}
+
+func (c *SignalClient) IsHealthy() bool {
+	return true
+}
+
+func (c *SignalClient) WaitStreamConnected() {}
+
+func (c *SignalClient) SendToStream(*sProto.EncryptedMessage) error {
+	return nil
+}
+
+func (c *SignalClient) Send(*sProto.Message) error {
+	return nil
+}
+
+func (c *SignalClient) SetOnReconnectedListener(func()) {}
@@ -0,0 +1,167 @@
+//go:build !android
+
+package uplink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"sync"
+	"syscall"
+
+	"github.com/hashicorp/go-multierror"
+	log "github.com/sirupsen/logrus"
+	"github.com/vishvananda/netlink"
+
+	nberrors "github.com/netbirdio/netbird/client/errors"
+)
+
+// pinner routes the control plane targets through the default gateway of the active uplink with host routes in
+// the main table. The sockets of the control plane are marked to use the main table.
+type pinner struct {
+	mu     sync.Mutex
+	routes []*netlink.Route
+}
+
+func newPinner() *pinner {
+	return &pinner{}
+}
+
+// pin routes the targets through the uplink, replacing the routes through the previous one
+func (p *pinner) pin(ctx context.Context, ifaceName string, targets []string) error {
+	link, err := netlink.LinkByName(ifaceName)
+	if err != nil {
+		return fmt.Errorf("get link %s: %w", ifaceName, err)
+	}
+
+	gateways, err := defaultGateways(link.Attrs().Index)
+	if err != nil {
+		return fmt.Errorf("get default gateways: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var merr *multierror.Error
+	var routes []*netlink.Route
+	for _, ip := range resolveTargets(ctx, targets) {
+		family := netlink.FAMILY_V4
+		if ip.Is6() {
+			family = netlink.FAMILY_V6
+		}
+		gw, ok := gateways[family]
+		if !ok {
+			log.Debugf("no default route through uplink %s for %s", ifaceName, ip)
+			continue
+		}
+
+		route := &netlink.Route{
+			LinkIndex: link.Attrs().Index,
+			Dst:       &net.IPNet{IP: ip.AsSlice(), Mask: net.CIDRMask(ip.BitLen(), ip.BitLen())},
+			Gw:        gw,
+			Family:    family,
+			Table:     syscall.RT_TABLE_MAIN,
+		}
+		if err := netlink.RouteReplace(route); err != nil {
+			merr = multierror.Append(merr, fmt.Errorf("replace route to %s: %w", ip, err))
+			continue
+		}
+		routes = append(routes, route)
+	}
+
+	for _, old := range p.routes {
+		if containsDst(routes, old) {
+			continue
+		}
+		if err := deleteRoute(old); err != nil {
+			merr = multierror.Append(merr, err)
+		}
+	}
+	p.routes = routes
+
+	return nberrors.FormatErrorOrNil(merr)
+}
+
+// unpin removes the routes of the targets
+func (p *pinner) unpin() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var merr *multierror.Error
+	for _, route := range p.routes {
+		if err := deleteRoute(route); err != nil {
+			merr = multierror.Append(merr, err)
+		}
+	}
+	p.routes = nil
+
+	return nberrors.FormatErrorOrNil(merr)
+}
+
+func deleteRoute(route *netlink.Route) error {
+	if err := netlink.RouteDel(route); err != nil && !errors.Is(err, syscall.ESRCH) {
+		return fmt.Errorf("delete route to %s: %w", route.Dst, err)
+	}
+	return nil
+}
+
+// defaultGateways returns the gateways of the default routes through the link by address family, the gateway is
+// nil for point-to-point links
+func defaultGateways(linkIndex int) (map[int]net.IP, error) {
+	filter := &netlink.Route{LinkIndex: linkIndex, Table: syscall.RT_TABLE_MAIN}
+	routes, err := netlink.RouteListFiltered(netlink.FAMILY_ALL, filter, netlink.RT_FILTER_OIF|netlink.RT_FILTER_TABLE)
+	if err != nil {
+		return nil, err
+	}
+
+	gateways := make(map[int]net.IP)
+	for _, route := range routes {
+		if route.Dst != nil {
+			if ones, _ := route.Dst.Mask.Size(); ones != 0 {
+				continue
+			}
+		}
+		if _, ok := gateways[route.Family]; !ok {
+			gateways[route.Family] = route.Gw
+		}
+	}
+	return gateways, nil
+}
+
+// resolveTargets returns the IP addresses of the host:port targets
+func resolveTargets(ctx context.Context, targets []string) []netip.Addr {
+	var ips []netip.Addr
+	for _, target := range targets {
+		host, _, err := net.SplitHostPort(target)
+		if err != nil {
+			log.Debugf("invalid uplink target %s: %v", target, err)
+			continue
+		}
+
+		if ip, err := netip.ParseAddr(host); err == nil {
+			ips = append(ips, ip.Unmap())
+			continue
+		}
+
+		resolved, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+		if err != nil {
+			log.Debugf("failed to resolve uplink target %s: %v", host, err)
+			continue
+		}
+		for _, ip := range resolved {
+			ips = append(ips, ip.Unmap())
+		}
+	}
+	return ips
+}
+
+func containsDst(routes []*netlink.Route, route *netlink.Route) bool {
+	for _, r := range routes {
+		if r.Dst.String() == route.Dst.String() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+//go:build !linux || android
+
+package uplink
+
+import (
+	"context"
+	"runtime"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// pinner is a no-op, the control plane follows the routing of the system
+type pinner struct{}
+
+func newPinner() *pinner {
+	return &pinner{}
+}
+
+func (p *pinner) pin(_ context.Context, ifaceName string, _ []string) error {
+	log.Debugf("routing the control plane through uplink %s is not supported on %s", ifaceName, runtime.GOOS)
+	return nil
+}
+
+func (p *pinner) unpin() error {
+	return nil
+}
@@ -0,0 +1,43 @@
+//go:build !android
+
+package uplink
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+
+	nbnet "github.com/netbirdio/netbird/util/net"
+)
+
+// probeTCP connects to the target through the interface. The socket is marked to bypass the routes of the tunnel.
+func probeTCP(ctx context.Context, ifaceName, target string) error {
+	dialer := &net.Dialer{
+		Control: func(_, _ string, c syscall.RawConn) error {
+			var sockErr error
+			err := c.Control(func(fd uintptr) {
+				if sockErr = syscall.SetsockoptString(int(fd), syscall.SOL_SOCKET, syscall.SO_BINDTODEVICE, ifaceName); sockErr != nil {
+					sockErr = fmt.Errorf("bind to device: %w", sockErr)
+					return
+				}
+				sockErr = nbnet.SetSocketOpt(int(fd))
+			})
+			if err != nil {
+				return fmt.Errorf("control: %w", err)
+			}
+			return sockErr
+		},
+	}
+
+	conn, err := dialer.DialContext(ctx, "tcp", target)
+	if err != nil {
+		return err
+	}
+	if err := conn.Close(); err != nil {
+		log.Debugf("failed to close uplink probe connection: %v", err)
+	}
+	return nil
+}
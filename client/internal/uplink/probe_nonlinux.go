@@ -0,0 +1,51 @@
+//go:build !linux || android
+
+package uplink
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// probeTCP connects to the target from the address of the interface. Depending on the routing of the system the
+// connection may still leave through another interface.
+func probeTCP(ctx context.Context, ifaceName, target string) error {
+	localIP, err := interfaceIPv4(ifaceName)
+	if err != nil {
+		return err
+	}
+
+	dialer := &net.Dialer{
+		LocalAddr: &net.TCPAddr{IP: localIP},
+	}
+	conn, err := dialer.DialContext(ctx, "tcp4", target)
+	if err != nil {
+		return err
+	}
+	if err := conn.Close(); err != nil {
+		log.Debugf("failed to close uplink probe connection: %v", err)
+	}
+	return nil
+}
+
+// interfaceIPv4 returns the first IPv4 address of the interface
+func interfaceIPv4(ifaceName string) (net.IP, error) {
+	iface, err := net.InterfaceByName(ifaceName)
+	if err != nil {
+		return nil, fmt.Errorf("get interface: %w", err)
+	}
+
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, fmt.Errorf("get interface addresses: %w", err)
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.To4() != nil {
+			return ipNet.IP, nil
+		}
+	}
+	return nil, fmt.Errorf("no IPv4 address on interface %s", ifaceName)
+}
@@ -0,0 +1,265 @@
+// Package uplink tracks the health of the uplinks of a peer with several internet connections, like a routing peer
+// with two ISPs, and selects the preferred one for the control plane and the tunnels with automatic failover.
+package uplink
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	probeInterval = 10 * time.Second
+	probeTimeout  = 5 * time.Second
+
+	// failureThreshold is the number of consecutive failed probes after which a healthy uplink becomes unhealthy
+	failureThreshold = 3
+	// recoveryThreshold is the number of consecutive successful probes after which an unhealthy uplink becomes
+	// healthy again, it prevents flapping between the uplinks
+	recoveryThreshold = 3
+)
+
+// Uplink is the state of an uplink interface
+type Uplink struct {
+	Name    string
+	Healthy bool
+	Active  bool
+	// Latency is the duration of the last successful probe
+	Latency   time.Duration
+	LastCheck time.Time
+	// Error of the last failed probe
+	Error string
+}
+
+type uplinkState struct {
+	Uplink
+	failures  int
+	successes int
+}
+
+// SwitchFunc is called when the active uplink changes
+type SwitchFunc func(from, to string)
+
+// probeFunc checks that the target is reachable through the interface
+type probeFunc func(ctx context.Context, ifaceName, target string) error
+
+// routePinner routes the control plane targets through an uplink
+type routePinner interface {
+	pin(ctx context.Context, ifaceName string, targets []string) error
+	unpin() error
+}
+
+// Tracker probes the uplinks and keeps the first healthy one in the order of preference active.
+// The control plane targets are routed through the active uplink where supported.
+type Tracker struct {
+	mu      sync.RWMutex
+	uplinks []*uplinkState
+	active  string
+	targets []string
+
+	onSwitch SwitchFunc
+	probe    probeFunc
+	pinner   routePinner
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewTracker returns a tracker of the uplink interfaces in the order of preference. The targets are the host:port
+// addresses of the control plane services, they're probed through each uplink.
+func NewTracker(interfaces []string, targets []string, onSwitch SwitchFunc) *Tracker {
+	t := &Tracker{
+		targets:  targets,
+		onSwitch: onSwitch,
+		probe:    probeTCP,
+		pinner:   newPinner(),
+	}
+	for _, name := range interfaces {
+		// the uplinks are considered healthy until the probes tell otherwise
+		t.uplinks = append(t.uplinks, &uplinkState{Uplink: Uplink{Name: name, Healthy: true}})
+	}
+	return t
+}
+
+// Start probes the uplinks periodically until the context is done or the tracker is stopped
+func (t *Tracker) Start(ctx context.Context) {
+	ctx, t.cancel = context.WithCancel(ctx)
+	t.done = make(chan struct{})
+
+	go func() {
+		defer close(t.done)
+
+		ticker := time.NewTicker(probeInterval)
+		defer ticker.Stop()
+
+		for {
+			t.check(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops the probes and removes the routes of the control plane targets
+func (t *Tracker) Stop() {
+	if t.cancel == nil {
+		return
+	}
+	t.cancel()
+	<-t.done
+
+	if err := t.pinner.unpin(); err != nil {
+		log.Warnf("failed to remove the uplink routes: %v", err)
+	}
+}
+
+// Active returns the name of the active uplink, empty before the first check
+func (t *Tracker) Active() string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.active
+}
+
+// Uplinks returns the state of the uplinks in the order of preference
+func (t *Tracker) Uplinks() []Uplink {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	uplinks := make([]Uplink, 0, len(t.uplinks))
+	for _, u := range t.uplinks {
+		uplink := u.Uplink
+		uplink.Active = u.Name == t.active
+		uplinks = append(uplinks, uplink)
+	}
+	return uplinks
+}
+
+// ExcludedInterfaces returns the uplinks that aren't active, the tunnels shouldn't gather candidates on them
+func (t *Tracker) ExcludedInterfaces() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.active == "" {
+		return nil
+	}
+
+	var excluded []string
+	for _, u := range t.uplinks {
+		if u.Name != t.active {
+			excluded = append(excluded, u.Name)
+		}
+	}
+	return excluded
+}
+
+// check probes all uplinks and switches to the preferred healthy one
+func (t *Tracker) check(ctx context.Context) {
+	var wg sync.WaitGroup
+	results := make([]probeResult, len(t.uplinks))
+	for i, u := range t.uplinks {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			results[i] = t.probeUplink(ctx, name)
+		}(i, u.Name)
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	t.mu.Lock()
+	for i, u := range t.uplinks {
+		u.update(results[i])
+	}
+	from := t.active
+	to := t.selectUplink()
+	if to != "" {
+		// without a healthy uplink the previous one stays active, there is nothing better to switch to
+		t.active = to
+	}
+	t.mu.Unlock()
+
+	if to == "" || to == from {
+		return
+	}
+
+	log.Infof("switching the active uplink from %q to %q", from, to)
+	if err := t.pinner.pin(ctx, to, t.targets); err != nil {
+		log.Warnf("failed to route the control plane through uplink %s: %v", to, err)
+	}
+
+	if t.onSwitch != nil {
+		t.onSwitch(from, to)
+	}
+}
+
+type probeResult struct {
+	latency time.Duration
+	err     error
+}
+
+// probeUplink succeeds if any of the targets is reachable through the uplink
+func (t *Tracker) probeUplink(ctx context.Context, name string) probeResult {
+	var lastErr error
+	for _, target := range t.targets {
+		probeCtx, cancel := context.WithTimeout(ctx, probeTimeout)
+		start := time.Now()
+		err := t.probe(probeCtx, name, target)
+		cancel()
+		if err == nil {
+			return probeResult{latency: time.Since(start)}
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = errors.New("no probe targets")
+	}
+	return probeResult{err: lastErr}
+}
+
+// update applies the result of a probe with the hysteresis of the thresholds
+func (u *uplinkState) update(result probeResult) {
+	u.LastCheck = time.Now()
+
+	if result.err != nil {
+		u.Error = result.err.Error()
+		u.successes = 0
+		u.failures++
+		if u.Healthy && u.failures >= failureThreshold {
+			log.Warnf("uplink %s is unhealthy: %v", u.Name, result.err)
+			u.Healthy = false
+		}
+		return
+	}
+
+	u.Error = ""
+	u.Latency = result.latency
+	u.failures = 0
+	u.successes++
+	if !u.Healthy && u.successes >= recoveryThreshold {
+		log.Infof("uplink %s is healthy again", u.Name)
+		u.Healthy = true
+	}
+}
+
+// selectUplink returns the first healthy uplink in the order of preference
+func (t *Tracker) selectUplink() string {
+	idx := slices.IndexFunc(t.uplinks, func(u *uplinkState) bool {
+		return u.Healthy
+	})
+	if idx < 0 {
+		return ""
+	}
+	return t.uplinks[idx].Name
+}
@@ -0,0 +1,94 @@
+package uplink
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeProbe struct {
+	mu   sync.Mutex
+	down map[string]bool
+}
+
+func (f *fakeProbe) setDown(name string, down bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.down[name] = down
+}
+
+func (f *fakeProbe) probe(_ context.Context, ifaceName, _ string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.down[ifaceName] {
+		return errors.New("unreachable")
+	}
+	return nil
+}
+
+type fakePinner struct {
+	active string
+}
+
+func (f *fakePinner) pin(_ context.Context, ifaceName string, _ []string) error {
+	f.active = ifaceName
+	return nil
+}
+
+func (f *fakePinner) unpin() error {
+	f.active = ""
+	return nil
+}
+
+func TestTracker_Failover(t *testing.T) {
+	ctx := context.Background()
+	probe := &fakeProbe{down: map[string]bool{}}
+
+	var switches [][2]string
+	tracker := NewTracker([]string{"eth0", "eth1"}, []string{"192.0.2.1:443"}, func(from, to string) {
+		switches = append(switches, [2]string{from, to})
+	})
+	pinner := &fakePinner{}
+	tracker.probe = probe.probe
+	tracker.pinner = pinner
+
+	tracker.check(ctx)
+	assert.Equal(t, "eth0", tracker.Active())
+	assert.Equal(t, []string{"eth1"}, tracker.ExcludedInterfaces())
+
+	probe.setDown("eth0", true)
+	for i := 0; i < failureThreshold-1; i++ {
+		tracker.check(ctx)
+	}
+	assert.Equal(t, "eth0", tracker.Active(), "the uplink should stay active below the failure threshold")
+
+	tracker.check(ctx)
+	assert.Equal(t, "eth1", tracker.Active())
+	assert.Equal(t, "eth1", pinner.active)
+	assert.Equal(t, []string{"eth0"}, tracker.ExcludedInterfaces())
+
+	uplinks := tracker.Uplinks()
+	assert.False(t, uplinks[0].Healthy)
+	assert.Equal(t, "unreachable", uplinks[0].Error)
+	assert.True(t, uplinks[1].Active)
+
+	probe.setDown("eth1", true)
+	for i := 0; i < failureThreshold; i++ {
+		tracker.check(ctx)
+	}
+	assert.Equal(t, "eth1", tracker.Active(), "the last uplink should stay active without a healthy one")
+
+	probe.setDown("eth0", false)
+	for i := 0; i < recoveryThreshold-1; i++ {
+		tracker.check(ctx)
+	}
+	assert.Equal(t, "eth1", tracker.Active(), "the preferred uplink should be back after the recovery threshold")
+
+	tracker.check(ctx)
+	assert.Equal(t, "eth0", tracker.Active())
+
+	assert.Equal(t, [][2]string{{"", "eth0"}, {"eth0", "eth1"}, {"eth1", "eth0"}}, switches)
+}
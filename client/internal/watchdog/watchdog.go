@@ -0,0 +1,176 @@
+// Package watchdog supervises the components of the engine that can wedge without the engine noticing, like the
+// tunnel interface removed by a driver reset, a stopped DNS listener or firewall rules flushed by another tool, and
+// recovers them with bounded retries instead of requiring a restart of the service.
+package watchdog
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/client/proto"
+)
+
+const (
+	checkInterval = 30 * time.Second
+
+	// maxAttempts is the number of recoveries of a failing check after which the watchdog gives up
+	maxAttempts = 3
+	// stablePeriod is the time a recovered check has to pass before its attempts are reset, it bounds the recoveries
+	// of a component that keeps failing shortly after each recovery
+	stablePeriod = 10 * time.Minute
+)
+
+// Check is a component supervised by the watchdog
+type Check struct {
+	// Name identifies the component, the recovery attempts are kept per name across the runs of the watchdog
+	Name string
+	// Probe returns an error if the component is wedged
+	Probe func() error
+	// Recover repairs the component
+	Recover func() error
+}
+
+// EventPublisher publishes the recoveries to the status subscribers
+type EventPublisher interface {
+	PublishEvent(severity proto.SystemEvent_Severity, category proto.SystemEvent_Category, msg string, userMsg string, metadata map[string]string)
+}
+
+type checkState struct {
+	failing      bool
+	gaveUp       bool
+	attempts     int
+	nextAttempt  time.Time
+	healthySince time.Time
+}
+
+// Watchdog runs the checks periodically and recovers the failing components. It outlives the engine: a recovery may
+// recreate the engine, which runs the checks again with the attempts of the previous run.
+type Watchdog struct {
+	mu     sync.Mutex
+	states map[string]*checkState
+
+	publisher EventPublisher
+	now       func() time.Time
+}
+
+// New returns a watchdog publishing its recoveries to the publisher
+func New(publisher EventPublisher) *Watchdog {
+	return &Watchdog{
+		states:    make(map[string]*checkState),
+		publisher: publisher,
+		now:       time.Now,
+	}
+}
+
+// Run runs the checks periodically until the context is done
+func (w *Watchdog) Run(ctx context.Context, checks []Check) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.check(ctx, checks)
+		}
+	}
+}
+
+// check probes the components and recovers the failing ones
+func (w *Watchdog) check(ctx context.Context, checks []Check) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, check := range checks {
+		// a recovery may have stopped the engine the checks belong to
+		if ctx.Err() != nil {
+			return
+		}
+		w.checkComponent(check)
+	}
+}
+
+func (w *Watchdog) checkComponent(check Check) {
+	state, ok := w.states[check.Name]
+	if !ok {
+		state = &checkState{}
+		w.states[check.Name] = state
+	}
+	now := w.now()
+
+	err := check.Probe()
+	if err == nil {
+		w.onHealthy(check.Name, state, now)
+		return
+	}
+
+	if !state.failing {
+		log.Warnf("watchdog: %s is wedged: %v", check.Name, err)
+	}
+	state.failing = true
+	state.healthySince = time.Time{}
+
+	if state.attempts >= maxAttempts {
+		if !state.gaveUp {
+			state.gaveUp = true
+			log.Errorf("watchdog: giving up recovering %s after %d attempts: %v", check.Name, state.attempts, err)
+			w.publish(proto.SystemEvent_CRITICAL,
+				fmt.Sprintf("%s recovery failed: %v", check.Name, err),
+				fmt.Sprintf("NetBird couldn't recover the %s, please restart the NetBird service.", check.Name),
+				check.Name, state.attempts)
+		}
+		return
+	}
+
+	if now.Before(state.nextAttempt) {
+		return
+	}
+
+	state.attempts++
+	// the attempts back off so a slow driver or service gets the time to come back
+	state.nextAttempt = now.Add(checkInterval << (state.attempts - 1))
+
+	log.Infof("watchdog: recovering %s, attempt %d of %d", check.Name, state.attempts, maxAttempts)
+	w.publish(proto.SystemEvent_WARNING,
+		fmt.Sprintf("%s is wedged: %v", check.Name, err),
+		fmt.Sprintf("NetBird is recovering the %s.", check.Name),
+		check.Name, state.attempts)
+
+	if err := check.Recover(); err != nil {
+		log.Errorf("watchdog: failed to recover %s: %v", check.Name, err)
+	}
+}
+
+func (w *Watchdog) onHealthy(name string, state *checkState, now time.Time) {
+	if state.failing {
+		state.failing = false
+		state.gaveUp = false
+		state.healthySince = now
+
+		log.Infof("watchdog: %s recovered", name)
+		w.publish(proto.SystemEvent_INFO, fmt.Sprintf("%s recovered", name), fmt.Sprintf("NetBird recovered the %s.", name),
+			name, state.attempts)
+		return
+	}
+
+	if state.attempts > 0 && now.Sub(state.healthySince) >= stablePeriod {
+		state.attempts = 0
+		state.nextAttempt = time.Time{}
+	}
+}
+
+func (w *Watchdog) publish(severity proto.SystemEvent_Severity, msg, userMsg, name string, attempts int) {
+	if w.publisher == nil {
+		return
+	}
+	w.publisher.PublishEvent(severity, proto.SystemEvent_SYSTEM, msg, userMsg, map[string]string{
+		"watchdog": name,
+		"attempts": strconv.Itoa(attempts),
+	})
+}
@@ -0,0 +1,138 @@
+package watchdog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/netbirdio/netbird/client/proto"
+)
+
+type fakePublisher struct {
+	severities []proto.SystemEvent_Severity
+}
+
+func (f *fakePublisher) PublishEvent(severity proto.SystemEvent_Severity, _ proto.SystemEvent_Category, _, _ string, _ map[string]string) {
+	f.severities = append(f.severities, severity)
+}
+
+type fakeComponent struct {
+	wedged     bool
+	recoveries int
+	// fixes is set if a recovery repairs the component
+	fixes bool
+}
+
+func (f *fakeComponent) check() Check {
+	return Check{
+		Name: "component",
+		Probe: func() error {
+			if f.wedged {
+				return errors.New("wedged")
+			}
+			return nil
+		},
+		Recover: func() error {
+			f.recoveries++
+			if f.fixes {
+				f.wedged = false
+			}
+			return nil
+		},
+	}
+}
+
+func newTestWatchdog(now *time.Time) (*Watchdog, *fakePublisher) {
+	publisher := &fakePublisher{}
+	w := New(publisher)
+	w.now = func() time.Time { return *now }
+	return w, publisher
+}
+
+func TestWatchdog_Recover(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	w, publisher := newTestWatchdog(&now)
+	component := &fakeComponent{fixes: true}
+	checks := []Check{component.check()}
+
+	w.check(ctx, checks)
+	assert.Equal(t, 0, component.recoveries, "a healthy component shouldn't be recovered")
+
+	component.wedged = true
+	w.check(ctx, checks)
+	assert.Equal(t, 1, component.recoveries)
+
+	now = now.Add(checkInterval)
+	w.check(ctx, checks)
+	assert.Equal(t, 1, component.recoveries)
+	assert.Equal(t, []proto.SystemEvent_Severity{proto.SystemEvent_WARNING, proto.SystemEvent_INFO}, publisher.severities)
+	assert.Equal(t, 1, w.states["component"].attempts, "the attempts should be kept until the component is stable")
+
+	now = now.Add(stablePeriod)
+	w.check(ctx, checks)
+	assert.Equal(t, 0, w.states["component"].attempts)
+}
+
+func TestWatchdog_GiveUp(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	w, publisher := newTestWatchdog(&now)
+	component := &fakeComponent{wedged: true}
+	checks := []Check{component.check()}
+
+	for i := 0; i < 20; i++ {
+		w.check(ctx, checks)
+		now = now.Add(checkInterval)
+	}
+	assert.Equal(t, maxAttempts, component.recoveries, "the recoveries should be bounded")
+	assert.Equal(t, proto.SystemEvent_CRITICAL, publisher.severities[len(publisher.severities)-1])
+	assert.Len(t, publisher.severities, maxAttempts+1, "giving up should be published once")
+
+	component.wedged = false
+	w.check(ctx, checks)
+	assert.Equal(t, proto.SystemEvent_INFO, publisher.severities[len(publisher.severities)-1])
+}
+
+func TestWatchdog_Backoff(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	w, _ := newTestWatchdog(&now)
+	component := &fakeComponent{wedged: true}
+	checks := []Check{component.check()}
+
+	w.check(ctx, checks)
+	now = now.Add(checkInterval)
+	w.check(ctx, checks)
+	assert.Equal(t, 2, component.recoveries)
+
+	now = now.Add(checkInterval)
+	w.check(ctx, checks)
+	assert.Equal(t, 2, component.recoveries, "the second recovery should back off")
+
+	now = now.Add(checkInterval)
+	w.check(ctx, checks)
+	assert.Equal(t, 3, component.recoveries)
+}
+
+func TestWatchdog_StoppedEngine(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	now := time.Now()
+	w, _ := newTestWatchdog(&now)
+
+	first := &fakeComponent{wedged: true}
+	second := &fakeComponent{wedged: true}
+	checks := []Check{
+		{Name: "first", Probe: first.check().Probe, Recover: func() error {
+			cancel()
+			return nil
+		}},
+		{Name: "second", Probe: second.check().Probe, Recover: second.check().Recover},
+	}
+
+	w.check(ctx, checks)
+	assert.Equal(t, 0, second.recoveries, "the checks of a stopped engine shouldn't run")
+}
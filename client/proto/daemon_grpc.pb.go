@@ -55,6 +55,28 @@ type DaemonServiceClient interface {
 	TracePacket(ctx context.Context, in *TracePacketRequest, opts ...grpc.CallOption) (*TracePacketResponse, error)
 	SubscribeEvents(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (DaemonService_SubscribeEventsClient, error)
 	GetEvents(ctx context.Context, in *GetEventsRequest, opts ...grpc.CallOption) (*GetEventsResponse, error)
+	// DebugFirewallRules returns the firewall rules the daemon applied and the rules present in the firewall
+	DebugFirewallRules(ctx context.Context, in *DebugFirewallRulesRequest, opts ...grpc.CallOption) (*DebugFirewallRulesResponse, error)
+	// ListRemoteDebugBundles lists the debug bundles requested by the management service waiting for approval
+	ListRemoteDebugBundles(ctx context.Context, in *ListRemoteDebugBundlesRequest, opts ...grpc.CallOption) (*ListRemoteDebugBundlesResponse, error)
+	// RespondRemoteDebugBundle approves or declines a debug bundle requested by the management service
+	RespondRemoteDebugBundle(ctx context.Context, in *RespondRemoteDebugBundleRequest, opts ...grpc.CallOption) (*RespondRemoteDebugBundleResponse, error)
+	// ApproveNetworks approves or revokes the high-risk networks waiting for the approval of the local user
+	ApproveNetworks(ctx context.Context, in *ApproveNetworksRequest, opts ...grpc.CallOption) (*ApproveNetworksResponse, error)
+	// ListNotifications lists the notifications the user must act on, like a login expiring or a failed posture check
+	ListNotifications(ctx context.Context, in *ListNotificationsRequest, opts ...grpc.CallOption) (*ListNotificationsResponse, error)
+	// ListProfiles lists the profiles running a daemon of their own next to the default one
+	ListProfiles(ctx context.Context, in *ListProfilesRequest, opts ...grpc.CallOption) (*ListProfilesResponse, error)
+	// AddProfile adds a profile and starts its daemon
+	AddProfile(ctx context.Context, in *AddProfileRequest, opts ...grpc.CallOption) (*AddProfileResponse, error)
+	// RemoveProfile stops the daemon of a profile and removes its config and state
+	RemoveProfile(ctx context.Context, in *RemoveProfileRequest, opts ...grpc.CallOption) (*RemoveProfileResponse, error)
+	// SendFile sends a file streamed by the CLI to the daemon of a peer, the first response has the offset it resumes at
+	SendFile(ctx context.Context, opts ...grpc.CallOption) (DaemonService_SendFileClient, error)
+	// SetFault sets or clears a fault injected into the client, the daemon must be built with the faultinject tag
+	SetFault(ctx context.Context, in *SetFaultRequest, opts ...grpc.CallOption) (*SetFaultResponse, error)
+	// ListFaults lists the faults injected into the client
+	ListFaults(ctx context.Context, in *ListFaultsRequest, opts ...grpc.CallOption) (*ListFaultsResponse, error)
 }
 
 type daemonServiceClient struct {
@@ -268,6 +290,127 @@ func (c *daemonServiceClient) GetEvents(ctx context.Context, in *GetEventsReques
 	return out, nil
 }
 
+func (c *daemonServiceClient) DebugFirewallRules(ctx context.Context, in *DebugFirewallRulesRequest, opts ...grpc.CallOption) (*DebugFirewallRulesResponse, error) {
+	out := new(DebugFirewallRulesResponse)
+	err := c.cc.Invoke(ctx, "/daemon.DaemonService/DebugFirewallRules", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonServiceClient) ListRemoteDebugBundles(ctx context.Context, in *ListRemoteDebugBundlesRequest, opts ...grpc.CallOption) (*ListRemoteDebugBundlesResponse, error) {
+	out := new(ListRemoteDebugBundlesResponse)
+	err := c.cc.Invoke(ctx, "/daemon.DaemonService/ListRemoteDebugBundles", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonServiceClient) RespondRemoteDebugBundle(ctx context.Context, in *RespondRemoteDebugBundleRequest, opts ...grpc.CallOption) (*RespondRemoteDebugBundleResponse, error) {
+	out := new(RespondRemoteDebugBundleResponse)
+	err := c.cc.Invoke(ctx, "/daemon.DaemonService/RespondRemoteDebugBundle", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonServiceClient) ApproveNetworks(ctx context.Context, in *ApproveNetworksRequest, opts ...grpc.CallOption) (*ApproveNetworksResponse, error) {
+	out := new(ApproveNetworksResponse)
+	err := c.cc.Invoke(ctx, "/daemon.DaemonService/ApproveNetworks", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonServiceClient) ListNotifications(ctx context.Context, in *ListNotificationsRequest, opts ...grpc.CallOption) (*ListNotificationsResponse, error) {
+	out := new(ListNotificationsResponse)
+	err := c.cc.Invoke(ctx, "/daemon.DaemonService/ListNotifications", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonServiceClient) ListProfiles(ctx context.Context, in *ListProfilesRequest, opts ...grpc.CallOption) (*ListProfilesResponse, error) {
+	out := new(ListProfilesResponse)
+	err := c.cc.Invoke(ctx, "/daemon.DaemonService/ListProfiles", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonServiceClient) AddProfile(ctx context.Context, in *AddProfileRequest, opts ...grpc.CallOption) (*AddProfileResponse, error) {
+	out := new(AddProfileResponse)
+	err := c.cc.Invoke(ctx, "/daemon.DaemonService/AddProfile", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonServiceClient) RemoveProfile(ctx context.Context, in *RemoveProfileRequest, opts ...grpc.CallOption) (*RemoveProfileResponse, error) {
+	out := new(RemoveProfileResponse)
+	err := c.cc.Invoke(ctx, "/daemon.DaemonService/RemoveProfile", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonServiceClient) SendFile(ctx context.Context, opts ...grpc.CallOption) (DaemonService_SendFileClient, error) {
+	stream, err := c.cc.NewStream(ctx, &DaemonService_ServiceDesc.Streams[1], "/daemon.DaemonService/SendFile", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &daemonServiceSendFileClient{stream}
+	return x, nil
+}
+
+type DaemonService_SendFileClient interface {
+	Send(*SendFileRequest) error
+	Recv() (*SendFileResponse, error)
+	grpc.ClientStream
+}
+
+type daemonServiceSendFileClient struct {
+	grpc.ClientStream
+}
+
+func (x *daemonServiceSendFileClient) Send(m *SendFileRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *daemonServiceSendFileClient) Recv() (*SendFileResponse, error) {
+	m := new(SendFileResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *daemonServiceClient) SetFault(ctx context.Context, in *SetFaultRequest, opts ...grpc.CallOption) (*SetFaultResponse, error) {
+	out := new(SetFaultResponse)
+	err := c.cc.Invoke(ctx, "/daemon.DaemonService/SetFault", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonServiceClient) ListFaults(ctx context.Context, in *ListFaultsRequest, opts ...grpc.CallOption) (*ListFaultsResponse, error) {
+	out := new(ListFaultsResponse)
+	err := c.cc.Invoke(ctx, "/daemon.DaemonService/ListFaults", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // DaemonServiceServer is the server API for DaemonService service.
 // All implementations must embed UnimplementedDaemonServiceServer
 // for forward compatibility
@@ -309,6 +452,28 @@ type DaemonServiceServer interface {
 	TracePacket(context.Context, *TracePacketRequest) (*TracePacketResponse, error)
 	SubscribeEvents(*SubscribeRequest, DaemonService_SubscribeEventsServer) error
 	GetEvents(context.Context, *GetEventsRequest) (*GetEventsResponse, error)
+	// DebugFirewallRules returns the firewall rules the daemon applied and the rules present in the firewall
+	DebugFirewallRules(context.Context, *DebugFirewallRulesRequest) (*DebugFirewallRulesResponse, error)
+	// ListRemoteDebugBundles lists the debug bundles requested by the management service waiting for approval
+	ListRemoteDebugBundles(context.Context, *ListRemoteDebugBundlesRequest) (*ListRemoteDebugBundlesResponse, error)
+	// RespondRemoteDebugBundle approves or declines a debug bundle requested by the management service
+	RespondRemoteDebugBundle(context.Context, *RespondRemoteDebugBundleRequest) (*RespondRemoteDebugBundleResponse, error)
+	// ApproveNetworks approves or revokes the high-risk networks waiting for the approval of the local user
+	ApproveNetworks(context.Context, *ApproveNetworksRequest) (*ApproveNetworksResponse, error)
+	// ListNotifications lists the notifications the user must act on, like a login expiring or a failed posture check
+	ListNotifications(context.Context, *ListNotificationsRequest) (*ListNotificationsResponse, error)
+	// ListProfiles lists the profiles running a daemon of their own next to the default one
+	ListProfiles(context.Context, *ListProfilesRequest) (*ListProfilesResponse, error)
+	// AddProfile adds a profile and starts its daemon
+	AddProfile(context.Context, *AddProfileRequest) (*AddProfileResponse, error)
+	// RemoveProfile stops the daemon of a profile and removes its config and state
+	RemoveProfile(context.Context, *RemoveProfileRequest) (*RemoveProfileResponse, error)
+	// SendFile sends a file streamed by the CLI to the daemon of a peer, the first response has the offset it resumes at
+	SendFile(DaemonService_SendFileServer) error
+	// SetFault sets or clears a fault injected into the client, the daemon must be built with the faultinject tag
+	SetFault(context.Context, *SetFaultRequest) (*SetFaultResponse, error)
+	// ListFaults lists the faults injected into the client
+	ListFaults(context.Context, *ListFaultsRequest) (*ListFaultsResponse, error)
 	mustEmbedUnimplementedDaemonServiceServer()
 }
 
@@ -376,6 +541,39 @@ func (UnimplementedDaemonServiceServer) SubscribeEvents(*SubscribeRequest, Daemo
 func (UnimplementedDaemonServiceServer) GetEvents(context.Context, *GetEventsRequest) (*GetEventsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetEvents not implemented")
 }
+func (UnimplementedDaemonServiceServer) DebugFirewallRules(context.Context, *DebugFirewallRulesRequest) (*DebugFirewallRulesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DebugFirewallRules not implemented")
+}
+func (UnimplementedDaemonServiceServer) ListRemoteDebugBundles(context.Context, *ListRemoteDebugBundlesRequest) (*ListRemoteDebugBundlesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRemoteDebugBundles not implemented")
+}
+func (UnimplementedDaemonServiceServer) RespondRemoteDebugBundle(context.Context, *RespondRemoteDebugBundleRequest) (*RespondRemoteDebugBundleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RespondRemoteDebugBundle not implemented")
+}
+func (UnimplementedDaemonServiceServer) ApproveNetworks(context.Context, *ApproveNetworksRequest) (*ApproveNetworksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ApproveNetworks not implemented")
+}
+func (UnimplementedDaemonServiceServer) ListNotifications(context.Context, *ListNotificationsRequest) (*ListNotificationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListNotifications not implemented")
+}
+func (UnimplementedDaemonServiceServer) ListProfiles(context.Context, *ListProfilesRequest) (*ListProfilesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListProfiles not implemented")
+}
+func (UnimplementedDaemonServiceServer) AddProfile(context.Context, *AddProfileRequest) (*AddProfileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddProfile not implemented")
+}
+func (UnimplementedDaemonServiceServer) RemoveProfile(context.Context, *RemoveProfileRequest) (*RemoveProfileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveProfile not implemented")
+}
+func (UnimplementedDaemonServiceServer) SendFile(DaemonService_SendFileServer) error {
+	return status.Errorf(codes.Unimplemented, "method SendFile not implemented")
+}
+func (UnimplementedDaemonServiceServer) SetFault(context.Context, *SetFaultRequest) (*SetFaultResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetFault not implemented")
+}
+func (UnimplementedDaemonServiceServer) ListFaults(context.Context, *ListFaultsRequest) (*ListFaultsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFaults not implemented")
+}
 func (UnimplementedDaemonServiceServer) mustEmbedUnimplementedDaemonServiceServer() {}
 
 // UnsafeDaemonServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -752,6 +950,212 @@ func _DaemonService_GetEvents_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DaemonService_DebugFirewallRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DebugFirewallRulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).DebugFirewallRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/daemon.DaemonService/DebugFirewallRules",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).DebugFirewallRules(ctx, req.(*DebugFirewallRulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DaemonService_ListRemoteDebugBundles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRemoteDebugBundlesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).ListRemoteDebugBundles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/daemon.DaemonService/ListRemoteDebugBundles",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).ListRemoteDebugBundles(ctx, req.(*ListRemoteDebugBundlesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DaemonService_RespondRemoteDebugBundle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RespondRemoteDebugBundleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).RespondRemoteDebugBundle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/daemon.DaemonService/RespondRemoteDebugBundle",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).RespondRemoteDebugBundle(ctx, req.(*RespondRemoteDebugBundleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DaemonService_ApproveNetworks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApproveNetworksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).ApproveNetworks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/daemon.DaemonService/ApproveNetworks",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).ApproveNetworks(ctx, req.(*ApproveNetworksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DaemonService_ListNotifications_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListNotificationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).ListNotifications(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/daemon.DaemonService/ListNotifications",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).ListNotifications(ctx, req.(*ListNotificationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DaemonService_ListProfiles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListProfilesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).ListProfiles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/daemon.DaemonService/ListProfiles",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).ListProfiles(ctx, req.(*ListProfilesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DaemonService_AddProfile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddProfileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).AddProfile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/daemon.DaemonService/AddProfile",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).AddProfile(ctx, req.(*AddProfileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DaemonService_RemoveProfile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveProfileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).RemoveProfile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/daemon.DaemonService/RemoveProfile",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).RemoveProfile(ctx, req.(*RemoveProfileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DaemonService_SendFile_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(DaemonServiceServer).SendFile(&daemonServiceSendFileServer{stream})
+}
+
+type DaemonService_SendFileServer interface {
+	Send(*SendFileResponse) error
+	Recv() (*SendFileRequest, error)
+	grpc.ServerStream
+}
+
+type daemonServiceSendFileServer struct {
+	grpc.ServerStream
+}
+
+func (x *daemonServiceSendFileServer) Send(m *SendFileResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *daemonServiceSendFileServer) Recv() (*SendFileRequest, error) {
+	m := new(SendFileRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _DaemonService_SetFault_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetFaultRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).SetFault(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/daemon.DaemonService/SetFault",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).SetFault(ctx, req.(*SetFaultRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DaemonService_ListFaults_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFaultsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).ListFaults(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/daemon.DaemonService/ListFaults",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).ListFaults(ctx, req.(*ListFaultsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // DaemonService_ServiceDesc is the grpc.ServiceDesc for DaemonService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -835,6 +1239,46 @@ var DaemonService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetEvents",
 			Handler:    _DaemonService_GetEvents_Handler,
 		},
+		{
+			MethodName: "DebugFirewallRules",
+			Handler:    _DaemonService_DebugFirewallRules_Handler,
+		},
+		{
+			MethodName: "ListRemoteDebugBundles",
+			Handler:    _DaemonService_ListRemoteDebugBundles_Handler,
+		},
+		{
+			MethodName: "RespondRemoteDebugBundle",
+			Handler:    _DaemonService_RespondRemoteDebugBundle_Handler,
+		},
+		{
+			MethodName: "ApproveNetworks",
+			Handler:    _DaemonService_ApproveNetworks_Handler,
+		},
+		{
+			MethodName: "ListNotifications",
+			Handler:    _DaemonService_ListNotifications_Handler,
+		},
+		{
+			MethodName: "ListProfiles",
+			Handler:    _DaemonService_ListProfiles_Handler,
+		},
+		{
+			MethodName: "AddProfile",
+			Handler:    _DaemonService_AddProfile_Handler,
+		},
+		{
+			MethodName: "RemoveProfile",
+			Handler:    _DaemonService_RemoveProfile_Handler,
+		},
+		{
+			MethodName: "SetFault",
+			Handler:    _DaemonService_SetFault_Handler,
+		},
+		{
+			MethodName: "ListFaults",
+			Handler:    _DaemonService_ListFaults_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -842,6 +1286,12 @@ var DaemonService_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _DaemonService_SubscribeEvents_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "SendFile",
+			Handler:       _DaemonService_SendFile_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "daemon.proto",
 }
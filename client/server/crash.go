@@ -0,0 +1,98 @@
+package server
+
+import (
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/client/anonymize"
+	"github.com/netbirdio/netbird/client/internal/crashreport"
+	"github.com/netbirdio/netbird/client/proto"
+)
+
+// crashDir is the directory of the crash reports in the state directory
+const crashDir = "crashes"
+
+// collectCrashReport returns the report of the crash of the previous daemon run, if any, and captures the crashes of
+// this run
+func (s *Server) collectCrashReport() *crashreport.Report {
+	report, err := s.crashReporter.Collect()
+	if err != nil {
+		log.Warnf("failed to collect crash report: %v", err)
+	}
+
+	if err := s.crashReporter.Install(); err != nil {
+		log.Warnf("failed to capture crashes: %v", err)
+	}
+
+	return report
+}
+
+// handleCrashReport saves the crash report with the configuration summary and submits it if the user opted in
+func (s *Server) handleCrashReport(report *crashreport.Report) {
+	if report == nil {
+		return
+	}
+
+	var endpoint string
+	if s.config != nil {
+		report.Config = s.crashConfigSummary()
+		endpoint = s.config.CrashReportURL
+	}
+
+	if err := s.crashReporter.Save(report); err != nil {
+		log.Errorf("failed to save crash report: %v", err)
+	}
+	log.Warnf("the previous run of the daemon crashed: %s, saved crash report %s", report.Panic, report.ID)
+
+	s.statusRecorder.PublishEvent(
+		proto.SystemEvent_ERROR,
+		proto.SystemEvent_SYSTEM,
+		"NetBird crashed",
+		"The NetBird service crashed and was restarted. A crash report was saved.",
+		map[string]string{"crash_report_id": report.ID},
+	)
+
+	if endpoint == "" {
+		return
+	}
+
+	go func() {
+		if err := crashreport.Submit(s.rootCtx, endpoint, report); err != nil {
+			log.Warnf("failed to submit crash report %s: %v", report.ID, err)
+			return
+		}
+		log.Infof("submitted crash report %s", report.ID)
+	}()
+}
+
+// crashConfigSummary returns the anonymized summary of the configuration, secrets like keys aren't included
+func (s *Server) crashConfigSummary() map[string]string {
+	anonymizer := anonymize.NewAnonymizer(anonymize.DefaultAddresses())
+
+	summary := map[string]string{
+		"WgIface":             s.config.WgIface,
+		"WgPort":              strconv.Itoa(s.config.WgPort),
+		"RosenpassEnabled":    strconv.FormatBool(s.config.RosenpassEnabled),
+		"RosenpassPermissive": strconv.FormatBool(s.config.RosenpassPermissive),
+		"DisableAutoConnect":  strconv.FormatBool(s.config.DisableAutoConnect),
+		"DisableClientRoutes": strconv.FormatBool(s.config.DisableClientRoutes),
+		"DisableServerRoutes": strconv.FormatBool(s.config.DisableServerRoutes),
+		"DisableDNS":          strconv.FormatBool(s.config.DisableDNS),
+		"DisableFirewall":     strconv.FormatBool(s.config.DisableFirewall),
+		"BlockLANAccess":      strconv.FormatBool(s.config.BlockLANAccess),
+		"DNSRouteInterval":    s.config.DNSRouteInterval.String(),
+		"NATExternalIPs":      strconv.Itoa(len(s.config.NATExternalIPs)),
+	}
+	if s.config.ManagementURL != nil {
+		summary["ManagementURL"] = anonymizer.AnonymizeURI(s.config.ManagementURL.String())
+	}
+	if s.config.NetworkMonitor != nil {
+		summary["NetworkMonitor"] = strconv.FormatBool(*s.config.NetworkMonitor)
+	}
+	if s.config.ServerSSHAllowed != nil {
+		summary["ServerSSHAllowed"] = strconv.FormatBool(*s.config.ServerSSHAllowed)
+	}
+
+	return summary
+}
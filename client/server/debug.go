@@ -47,6 +47,7 @@ nftables.txt: Anonymized nftables rules with packet counters, if --system-info f
 config.txt: Anonymized configuration information of the NetBird client.
 network_map.json: Anonymized network map containing peer configurations, routes, DNS settings, and firewall rules.
 state.json: Anonymized client state dump containing netbird states.
+crash_reports: Anonymized reports of the recent crashes of the NetBird client, with goroutine stacks and a configuration summary.
 mutex.prof: Mutex profiling information.
 goroutine.prof: Goroutine profiling information.
 block.prof: Block profiling information.
@@ -65,6 +66,8 @@ Reoccuring IP addresses are replaced with the same anonymized address.
 
 Note: The anonymized IP addresses in the status file do not match those in the log and routes files. However, the anonymized IP addresses are consistent within the status file and across the routes and log files.
 
+Log files written with the NB_LOG_SCRUB environment variable set were already scrubbed at write time. Their anonymized values do not match those in the other files.
+
 Domains
 All domain names (except for the netbird domains) are replaced with randomly generated strings ending in ".domain". Anonymized domains are consistent across all files in the bundle.
 Reoccuring domain names are replaced with the same anonymized domain.
@@ -216,6 +219,10 @@ func (s *Server) createArchive(bundlePath *os.File, req *proto.DebugBundleReques
 		log.Errorf("Failed to add corrupted state files to debug bundle: %v", err)
 	}
 
+	if err := s.addCrashReports(archive); err != nil {
+		log.Errorf("Failed to add crash reports to debug bundle: %v", err)
+	}
+
 	if s.logFile != "console" {
 		if err := s.addLogfile(req, anonymizer, archive); err != nil {
 			return fmt.Errorf("add log file: %w", err)
@@ -480,6 +487,30 @@ func (s *Server) addCorruptedStateFiles(archive *zip.Writer) error {
 	return nil
 }
 
+// addCrashReports adds the saved crash reports, they're anonymized when they're collected
+func (s *Server) addCrashReports(archive *zip.Writer) error {
+	reports, err := s.crashReporter.ReportFiles()
+	if err != nil {
+		return fmt.Errorf("find crash reports: %w", err)
+	}
+
+	for _, report := range reports {
+		data, err := os.ReadFile(report)
+		if err != nil {
+			log.Warnf("Failed to read crash report %s: %v", report, err)
+			continue
+		}
+
+		fileName := filepath.Base(report)
+		if err := addFileToZip(archive, bytes.NewReader(data), "crash_reports/"+fileName); err != nil {
+			log.Warnf("Failed to add crash report %s to zip: %v", fileName, err)
+			continue
+		}
+	}
+
+	return nil
+}
+
 func (s *Server) addLogfile(req *proto.DebugBundleRequest, anonymizer *anonymize.Anonymizer, archive *zip.Writer) error {
 	logDir := filepath.Dir(s.logFile)
 
@@ -0,0 +1,21 @@
+package server
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	"github.com/netbirdio/netbird/util/errcode"
+)
+
+// UnaryErrorCodeInterceptor returns the errors of the daemon calls as gRPC statuses carrying their code, see the
+// errcode package
+func UnaryErrorCodeInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	resp, err := handler(ctx, req)
+	return resp, errcode.ToGRPC(err)
+}
+
+// StreamErrorCodeInterceptor returns the errors of the daemon streams as gRPC statuses carrying their code
+func StreamErrorCodeInterceptor(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return errcode.ToGRPC(handler(srv, ss))
+}
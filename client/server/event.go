@@ -34,3 +34,8 @@ func (s *Server) GetEvents(context.Context, *proto.GetEventsRequest) (*proto.Get
 	events := s.statusRecorder.GetEventHistory()
 	return &proto.GetEventsResponse{Events: events}, nil
 }
+
+// ListNotifications returns the active notifications, the events the user didn't resolve yet
+func (s *Server) ListNotifications(context.Context, *proto.ListNotificationsRequest) (*proto.ListNotificationsResponse, error) {
+	return &proto.ListNotificationsResponse{Notifications: s.statusRecorder.GetNotifications()}, nil
+}
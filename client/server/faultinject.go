@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	gstatus "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/netbirdio/netbird/client/proto"
+	"github.com/netbirdio/netbird/util/faultinject"
+)
+
+// SetFault sets or clears a fault injected into the client, the faults are injected by the daemons built with the
+// faultinject tag only
+func (s *Server) SetFault(_ context.Context, req *proto.SetFaultRequest) (*proto.SetFaultResponse, error) {
+	if !faultinject.Enabled {
+		return nil, gstatus.Error(codes.Unimplemented, faultinject.ErrNotCompiled.Error())
+	}
+
+	if req.GetClear() && req.GetFault() == "" {
+		faultinject.ClearAll()
+		return &proto.SetFaultResponse{}, nil
+	}
+
+	fault, err := faultinject.ParseFault(req.GetFault())
+	if err != nil {
+		return nil, gstatus.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if req.GetClear() {
+		faultinject.Clear(fault)
+		return &proto.SetFaultResponse{}, nil
+	}
+
+	config := faultinject.Config{
+		Probability: req.GetProbability(),
+		Delay:       req.GetDelay().AsDuration(),
+		Count:       int(req.GetCount()),
+	}
+	if err := faultinject.Set(fault, config); err != nil {
+		return nil, gstatus.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return &proto.SetFaultResponse{}, nil
+}
+
+// ListFaults lists the faults injected into the client
+func (s *Server) ListFaults(context.Context, *proto.ListFaultsRequest) (*proto.ListFaultsResponse, error) {
+	resp := &proto.ListFaultsResponse{Enabled: faultinject.Enabled}
+	for _, state := range faultinject.List() {
+		resp.Faults = append(resp.Faults, &proto.FaultState{
+			Fault:       string(state.Fault),
+			Probability: state.Config.Probability,
+			Delay:       durationpb.New(state.Config.Delay),
+			Count:       int32(state.Config.Count),
+			Injected:    int32(state.Injected),
+		})
+	}
+	return resp, nil
+}
@@ -0,0 +1,27 @@
+//go:build !faultinject
+
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	gstatus "google.golang.org/grpc/status"
+
+	"github.com/netbirdio/netbird/client/proto"
+)
+
+func TestSetFaultNotCompiled(t *testing.T) {
+	s := &Server{}
+
+	_, err := s.SetFault(context.Background(), &proto.SetFaultRequest{Fault: "relay-kill"})
+	assert.Equal(t, codes.Unimplemented, gstatus.Code(err))
+
+	resp, err := s.ListFaults(context.Background(), &proto.ListFaultsRequest{})
+	require.NoError(t, err)
+	assert.False(t, resp.GetEnabled())
+	assert.Empty(t, resp.GetFaults())
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	gstatus "google.golang.org/grpc/status"
+
+	"github.com/netbirdio/netbird/client/internal/filetransfer"
+	"github.com/netbirdio/netbird/client/proto"
+)
+
+// SendFile forwards the file streamed by the CLI to the daemon of the peer. The first request describes the file, the
+// following ones carry the content from the offset of the first response on.
+func (s *Server) SendFile(stream proto.DaemonService_SendFileServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	peerIP, err := s.fileTransferPeerIP(req.GetPeer())
+	if err != nil {
+		return gstatus.Error(codes.NotFound, err.Error())
+	}
+
+	header := filetransfer.Header{
+		Path:   req.GetPath(),
+		Size:   req.GetSize(),
+		SHA256: req.GetSha256(),
+		Mode:   req.GetMode(),
+	}
+	upload, err := filetransfer.Dial(stream.Context(), peerIP, header)
+	if err != nil {
+		return gstatus.Errorf(codes.Unavailable, "send file to peer %s: %v", req.GetPeer(), err)
+	}
+	defer func() {
+		if err := upload.Close(); err != nil {
+			log.Debugf("failed to close file transfer to peer %s: %v", peerIP, err)
+		}
+	}()
+
+	if err := stream.Send(&proto.SendFileResponse{Offset: upload.Offset}); err != nil {
+		return err
+	}
+
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := upload.Write(req.GetData()); err != nil {
+			return gstatus.Errorf(codes.Aborted, "send file to peer %s: %v", peerIP, err)
+		}
+	}
+
+	if err := upload.Finish(); err != nil {
+		return gstatus.Errorf(codes.Aborted, "send file to peer %s: %v", peerIP, err)
+	}
+	log.Infof("sent file %s of %d bytes to peer %s", header.Path, header.Size, peerIP)
+
+	return stream.Send(&proto.SendFileResponse{Offset: header.Size, Done: true})
+}
+
+// fileTransferPeerIP returns the NetBird IP of the peer with the IP, the FQDN or the host name of its FQDN
+func (s *Server) fileTransferPeerIP(name string) (netip.Addr, error) {
+	name = strings.TrimSuffix(strings.ToLower(name), ".")
+	if name == "" {
+		return netip.Addr{}, errors.New("no peer given")
+	}
+
+	for _, p := range s.statusRecorder.GetFullStatus().Peers {
+		fqdn := strings.TrimSuffix(strings.ToLower(p.FQDN), ".")
+		ip := strings.Split(p.IP, "/")[0]
+		if ip != name && fqdn != name && strings.Split(fqdn, ".")[0] != name {
+			continue
+		}
+
+		addr, err := netip.ParseAddr(ip)
+		if err != nil {
+			return netip.Addr{}, fmt.Errorf("invalid IP of peer %s: %w", name, err)
+		}
+		return addr, nil
+	}
+	return netip.Addr{}, fmt.Errorf("peer %s not found", name)
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	firewall "github.com/netbirdio/netbird/client/firewall/manager"
+	"github.com/netbirdio/netbird/client/proto"
+)
+
+// DebugFirewallRules returns the rules the firewall managers applied and the rules present in the firewalls
+func (s *Server) DebugFirewallRules(context.Context, *proto.DebugFirewallRulesRequest) (*proto.DebugFirewallRulesResponse, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.connectClient == nil {
+		return nil, fmt.Errorf("connect client not initialized")
+	}
+	engine := s.connectClient.Engine()
+	if engine == nil {
+		return nil, fmt.Errorf("engine not initialized")
+	}
+
+	fwManager := engine.GetFirewallManager()
+	if fwManager == nil {
+		return nil, fmt.Errorf("firewall manager not initialized")
+	}
+
+	dump, err := fwManager.DumpRules()
+	if err != nil {
+		return nil, fmt.Errorf("dump firewall rules: %w", err)
+	}
+
+	resp := &proto.DebugFirewallRulesResponse{}
+	for ; dump != nil; dump = dump.Native {
+		resp.Dumps = append(resp.Dumps, rulesDumpToProto(dump))
+	}
+
+	return resp, nil
+}
+
+func rulesDumpToProto(dump *firewall.RulesDump) *proto.FirewallRulesDump {
+	pbDump := &proto.FirewallRulesDump{
+		Backend:  dump.Backend,
+		RuleSets: make([]*proto.FirewallRuleSet, 0, len(dump.RuleSets)),
+	}
+	for _, set := range dump.RuleSets {
+		pbDump.RuleSets = append(pbDump.RuleSets, &proto.FirewallRuleSet{
+			Table:      set.Table,
+			Chain:      set.Chain,
+			Applied:    set.Applied,
+			Present:    set.Present,
+			Missing:    set.Missing,
+			Unexpected: set.Unexpected,
+		})
+	}
+	return pbDump
+}
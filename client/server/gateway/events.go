@@ -0,0 +1,61 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/coder/websocket"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+
+	"github.com/netbirdio/netbird/client/proto"
+)
+
+const streamWriteTimeout = 10 * time.Second
+
+// streamEvents upgrades the request to a WebSocket and sends the system events of the daemon as JSON messages
+func (g *Gateway) streamEvents(w http.ResponseWriter, r *http.Request) {
+	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{
+		// the clients are authenticated by the token, the origin of the UIs loaded from files doesn't match the host
+		InsecureSkipVerify: true,
+	})
+	if err != nil {
+		log.Debugf("failed to accept event stream: %v", err)
+		return
+	}
+	defer conn.CloseNow()
+
+	// the stream is write only, reading is only needed to handle the control frames and to notice the client leaving
+	ctx := conn.CloseRead(r.Context())
+
+	stream := &eventStream{ctx: ctx, conn: conn}
+	if err := g.daemon.SubscribeEvents(&proto.SubscribeRequest{}, stream); err != nil {
+		log.Debugf("event stream stopped: %v", err)
+		return
+	}
+	_ = conn.Close(websocket.StatusNormalClosure, "")
+}
+
+// eventStream sends the events of the daemon subscription to the WebSocket
+type eventStream struct {
+	// grpc.ServerStream is only embedded to satisfy the interface, the daemon uses Send and Context only
+	grpc.ServerStream
+	ctx  context.Context
+	conn *websocket.Conn
+}
+
+func (s *eventStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *eventStream) Send(event *proto.SystemEvent) error {
+	data, err := marshaler.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, streamWriteTimeout)
+	defer cancel()
+	return s.conn.Write(ctx, websocket.MessageText, data)
+}
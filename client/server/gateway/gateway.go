@@ -0,0 +1,278 @@
+// Package gateway exposes the daemon API over HTTP on the loopback interface for the tooling that can't use gRPC,
+// like Electron UIs, scripts and MDM agents. The requests and responses are the JSON encoding of the daemon messages
+// and the system events are streamed over a WebSocket. Every request is authenticated with a bearer token.
+package gateway
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	protobuf "google.golang.org/protobuf/proto"
+
+	"github.com/netbirdio/netbird/client/proto"
+	"github.com/netbirdio/netbird/util/errcode"
+)
+
+const (
+	// TokenFileName is the name of the token file in the config directory
+	TokenFileName = "api-token"
+
+	apiPrefix      = "/api/v1"
+	maxRequestBody = 1 << 20
+	tokenLength    = 32
+)
+
+var (
+	marshaler   = protojson.MarshalOptions{EmitUnpopulated: true}
+	unmarshaler = protojson.UnmarshalOptions{DiscardUnknown: true}
+)
+
+// Gateway serves the REST and WebSocket API of the daemon
+type Gateway struct {
+	daemon proto.DaemonServiceServer
+	token  string
+	server *http.Server
+}
+
+// New returns a gateway of the daemon listening on addr, which must be a loopback address
+func New(daemon proto.DaemonServiceServer, addr, token string) (*Gateway, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gateway address %s: %w", addr, err)
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return nil, fmt.Errorf("gateway address %s is not a loopback address", addr)
+	}
+	if token == "" {
+		return nil, errors.New("gateway token is empty")
+	}
+
+	g := &Gateway{
+		daemon: daemon,
+		token:  token,
+	}
+	g.server = &http.Server{
+		Addr:              addr,
+		Handler:           g.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return g, nil
+}
+
+// Start listens on the gateway address and serves the requests in the background
+func (g *Gateway) Start() error {
+	listener, err := net.Listen("tcp", g.server.Addr)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", g.server.Addr, err)
+	}
+
+	go func() {
+		if err := g.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Errorf("failed to serve the API gateway: %v", err)
+		}
+	}()
+
+	log.Infof("started the API gateway on %s", listener.Addr())
+	return nil
+}
+
+// Stop closes the listener and the open connections, including the event streams
+func (g *Gateway) Stop() error {
+	return g.server.Close()
+}
+
+func (g *Gateway) handler() http.Handler {
+	router := mux.NewRouter()
+	router.Use(g.authenticate)
+
+	api := router.PathPrefix(apiPrefix).Subrouter()
+	api.HandleFunc("/login", unary(g.daemon.Login)).Methods(http.MethodPost)
+	api.HandleFunc("/login/wait", unary(g.daemon.WaitSSOLogin)).Methods(http.MethodPost)
+	api.HandleFunc("/up", unary(g.daemon.Up)).Methods(http.MethodPost)
+	api.HandleFunc("/down", unary(g.daemon.Down)).Methods(http.MethodPost)
+	api.HandleFunc("/status", g.getStatus).Methods(http.MethodGet)
+	api.HandleFunc("/config", unary(g.daemon.GetConfig)).Methods(http.MethodGet)
+	api.HandleFunc("/networks", unary(g.daemon.ListNetworks)).Methods(http.MethodGet)
+	api.HandleFunc("/networks/select", unary(g.daemon.SelectNetworks)).Methods(http.MethodPost)
+	api.HandleFunc("/networks/deselect", unary(g.daemon.DeselectNetworks)).Methods(http.MethodPost)
+	api.HandleFunc("/forwarding-rules", unary(g.daemon.ForwardingRules)).Methods(http.MethodGet)
+	api.HandleFunc("/events", unary(g.daemon.GetEvents)).Methods(http.MethodGet)
+	api.HandleFunc("/notifications", unary(g.daemon.ListNotifications)).Methods(http.MethodGet)
+	api.HandleFunc("/profiles", unary(g.daemon.ListProfiles)).Methods(http.MethodGet)
+	api.HandleFunc("/events/stream", g.streamEvents).Methods(http.MethodGet)
+
+	return router
+}
+
+// authenticate rejects the requests without the gateway token. The token is read from the Authorization header,
+// or from the token query parameter for the WebSocket clients of browsers which can't set headers.
+func (g *Gateway) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok && r.URL.Path == apiPrefix+"/events/stream" {
+			token = r.URL.Query().Get("token")
+		}
+
+		if subtle.ConstantTimeCompare([]byte(token), []byte(g.token)) != 1 {
+			writeError(w, status.Error(codes.Unauthenticated, "invalid or missing token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// getStatus returns the status of the daemon, the full peer status is returned with the full query parameter
+func (g *Gateway) getStatus(w http.ResponseWriter, r *http.Request) {
+	req := &proto.StatusRequest{}
+	if full := r.URL.Query().Get("full"); full != "" {
+		var err error
+		if req.GetFullPeerStatus, err = strconv.ParseBool(full); err != nil {
+			writeError(w, status.Errorf(codes.InvalidArgument, "invalid full parameter: %v", err))
+			return
+		}
+	}
+
+	resp, err := g.daemon.Status(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeMessage(w, resp)
+}
+
+// unary returns a handler calling a unary method of the daemon with the JSON request body, which is optional
+func unary[Req any, Resp protobuf.Message, ReqPtr interface {
+	*Req
+	protobuf.Message
+}](call func(context.Context, ReqPtr) (Resp, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := ReqPtr(new(Req))
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBody))
+		if err != nil {
+			writeError(w, status.Errorf(codes.InvalidArgument, "failed to read request body: %v", err))
+			return
+		}
+		if len(body) > 0 {
+			if err := unmarshaler.Unmarshal(body, req); err != nil {
+				writeError(w, status.Errorf(codes.InvalidArgument, "invalid request body: %v", err))
+				return
+			}
+		}
+
+		resp, err := call(r.Context(), req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeMessage(w, resp)
+	}
+}
+
+func writeMessage(w http.ResponseWriter, msg protobuf.Message) {
+	data, err := marshaler.Marshal(msg)
+	if err != nil {
+		writeError(w, status.Errorf(codes.Internal, "failed to encode response: %v", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(data); err != nil {
+		log.Debugf("failed to write the API gateway response: %v", err)
+	}
+}
+
+type errorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// ErrorCode is the stable code of the error, see the errcode package
+	ErrorCode string `json:"error_code"`
+}
+
+// writeError writes the gRPC status of the error with the matching HTTP status code
+func writeError(w http.ResponseWriter, err error) {
+	st := status.Convert(errcode.ToGRPC(err))
+
+	data, _ := json.Marshal(errorResponse{Code: st.Code().String(), Message: st.Message(), ErrorCode: string(errcode.Of(st.Err()))})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus(st.Code()))
+	if _, err := w.Write(data); err != nil {
+		log.Debugf("failed to write the API gateway error: %v", err)
+	}
+}
+
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.FailedPrecondition:
+		return http.StatusPreconditionFailed
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Canceled:
+		return 499
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// LoadOrCreateToken reads the gateway token from the file, a new random token is written to it if it doesn't exist.
+// The file is only readable by the owner, the local users allowed to read it can control the daemon.
+func LoadOrCreateToken(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		if token := strings.TrimSpace(string(data)); token != "" {
+			return token, nil
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("read token file: %w", err)
+	}
+
+	buf := make([]byte, tokenLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate token: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("create token directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("write token file: %w", err)
+	}
+	return token, nil
+}
@@ -0,0 +1,150 @@
+package gateway
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/coder/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/netbirdio/netbird/client/proto"
+)
+
+const testToken = "secret"
+
+type fakeDaemon struct {
+	proto.UnimplementedDaemonServiceServer
+	selected []string
+}
+
+func (d *fakeDaemon) Status(_ context.Context, req *proto.StatusRequest) (*proto.StatusResponse, error) {
+	resp := &proto.StatusResponse{Status: "Connected"}
+	if req.GetGetFullPeerStatus() {
+		resp.FullStatus = &proto.FullStatus{}
+	}
+	return resp, nil
+}
+
+func (d *fakeDaemon) SelectNetworks(_ context.Context, req *proto.SelectNetworksRequest) (*proto.SelectNetworksResponse, error) {
+	if len(req.GetNetworkIDs()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no networks")
+	}
+	d.selected = req.GetNetworkIDs()
+	return &proto.SelectNetworksResponse{}, nil
+}
+
+func (d *fakeDaemon) SubscribeEvents(_ *proto.SubscribeRequest, stream proto.DaemonService_SubscribeEventsServer) error {
+	return stream.Send(&proto.SystemEvent{Id: "1", UserMessage: "connected"})
+}
+
+func newTestGateway(t *testing.T, daemon proto.DaemonServiceServer) *httptest.Server {
+	t.Helper()
+
+	g, err := New(daemon, "127.0.0.1:0", testToken)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(g.handler())
+	t.Cleanup(server.Close)
+	return server
+}
+
+func doRequest(t *testing.T, method, url, token, body string) (*http.Response, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(data)
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(&fakeDaemon{}, "0.0.0.0:8080", testToken)
+	assert.Error(t, err, "the gateway should only listen on loopback addresses")
+
+	_, err = New(&fakeDaemon{}, "127.0.0.1:8080", "")
+	assert.Error(t, err, "the gateway should require a token")
+
+	_, err = New(&fakeDaemon{}, "localhost:8080", testToken)
+	assert.NoError(t, err)
+}
+
+func TestGateway_Authentication(t *testing.T) {
+	server := newTestGateway(t, &fakeDaemon{})
+
+	resp, _ := doRequest(t, http.MethodGet, server.URL+"/api/v1/status", "", "")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp, _ = doRequest(t, http.MethodGet, server.URL+"/api/v1/status", "wrong", "")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp, body := doRequest(t, http.MethodGet, server.URL+"/api/v1/status?full=true", testToken, "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, body, `"status":"Connected"`)
+	assert.Contains(t, body, `"fullStatus":{`)
+}
+
+func TestGateway_Unary(t *testing.T) {
+	daemon := &fakeDaemon{}
+	server := newTestGateway(t, daemon)
+
+	resp, _ := doRequest(t, http.MethodPost, server.URL+"/api/v1/networks/select", testToken, `{"networkIDs":["net1","net2"]}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{"net1", "net2"}, daemon.selected)
+
+	resp, body := doRequest(t, http.MethodPost, server.URL+"/api/v1/networks/select", testToken, "")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.JSONEq(t, `{"code":"InvalidArgument","message":"no networks","error_code":"INVALID_ARGUMENT"}`, body)
+
+	resp, _ = doRequest(t, http.MethodPost, server.URL+"/api/v1/networks/select", testToken, `{"networkIDs":`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, _ = doRequest(t, http.MethodPost, server.URL+"/api/v1/up", testToken, "")
+	assert.Equal(t, http.StatusNotImplemented, resp.StatusCode)
+}
+
+func TestGateway_StreamEvents(t *testing.T) {
+	server := newTestGateway(t, &fakeDaemon{})
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/v1/events/stream?token=" + testToken
+
+	conn, _, err := websocket.Dial(context.Background(), url, nil)
+	require.NoError(t, err)
+	defer conn.CloseNow()
+
+	_, data, err := conn.Read(context.Background())
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"userMessage":"connected"`)
+}
+
+func TestLoadOrCreateToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), TokenFileName)
+
+	token, err := LoadOrCreateToken(path)
+	require.NoError(t, err)
+	assert.Len(t, token, tokenLength*2)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	loaded, err := LoadOrCreateToken(path)
+	require.NoError(t, err)
+	assert.Equal(t, token, loaded, "the existing token should be kept")
+}
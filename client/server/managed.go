@@ -0,0 +1,23 @@
+package server
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/client/internal/mdm"
+)
+
+// loadManagedPolicy reads the device management policy, the policy is re-read on every start and login to pick up
+// the changes pushed by the MDM. The client runs unmanaged if the policy can't be read.
+func (s *Server) loadManagedPolicy() {
+	policy, err := mdm.Read()
+	if err != nil {
+		log.Errorf("failed to read the managed policy, running unmanaged: %v", err)
+		policy = &mdm.Policy{}
+	}
+
+	if !policy.IsEmpty() {
+		log.Infof("managed policy loaded, managed settings: %v, disabled UI actions: %v",
+			policy.ManagedSettings(), policy.DisabledUIActions)
+	}
+	s.managedPolicy = policy
+}
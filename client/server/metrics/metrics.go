@@ -0,0 +1,86 @@
+// Package metrics serves the metrics of the daemon in the Prometheus format, e.g. the durations and the outcomes of the
+// peer connection setups. The metrics are recorded with the global meter provider, which is set when the server is
+// created.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	prometheus2 "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/sdk/metric"
+)
+
+const endpoint = "/metrics"
+
+// Server serves the metrics endpoint of the daemon
+type Server struct {
+	provider *metric.MeterProvider
+	server   *http.Server
+}
+
+// New returns a metrics server listening on addr and sets the global meter provider
+func New(addr string) (*Server, error) {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		return nil, fmt.Errorf("invalid metrics address %s: %w", addr, err)
+	}
+
+	exporter, err := prometheus.New()
+	if err != nil {
+		return nil, fmt.Errorf("create exporter: %w", err)
+	}
+
+	provider := metric.NewMeterProvider(metric.WithReader(exporter))
+	otel.SetMeterProvider(provider)
+
+	router := http.NewServeMux()
+	router.Handle(endpoint, promhttp.HandlerFor(prometheus2.DefaultGatherer, promhttp.HandlerOpts{EnableOpenMetrics: true}))
+
+	return &Server{
+		provider: provider,
+		server: &http.Server{
+			Addr:              addr,
+			Handler:           router,
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+	}, nil
+}
+
+// Start listens on the metrics address and serves the requests in the background
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", s.server.Addr, err)
+	}
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Errorf("failed to serve the metrics: %v", err)
+		}
+	}()
+
+	log.Infof("serving the metrics on %s%s", listener.Addr(), endpoint)
+	return nil
+}
+
+// Stop closes the listener and shuts the meter provider down
+func (s *Server) Stop() error {
+	if err := s.server.Close(); err != nil {
+		return fmt.Errorf("http server: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.provider.Shutdown(ctx); err != nil {
+		return fmt.Errorf("meter provider: %w", err)
+	}
+	return nil
+}
@@ -10,16 +10,19 @@ import (
 
 	"golang.org/x/exp/maps"
 
+	"github.com/netbirdio/netbird/client/internal/routemanager/exitnode"
 	"github.com/netbirdio/netbird/client/proto"
 	"github.com/netbirdio/netbird/management/domain"
+	mgmProto "github.com/netbirdio/netbird/management/proto"
 	"github.com/netbirdio/netbird/route"
 )
 
 type selectRoute struct {
-	NetID    route.NetID
-	Network  netip.Prefix
-	Domains  domain.List
-	Selected bool
+	NetID           route.NetID
+	Network         netip.Prefix
+	Domains         domain.List
+	Selected        bool
+	PendingApproval bool
 }
 
 // ListNetworks returns a list of all available networks.
@@ -43,6 +46,7 @@ func (s *Server) ListNetworks(context.Context, *proto.ListNetworksRequest) (*pro
 
 	routesMap := routeMgr.GetClientRoutesWithNetID()
 	routeSelector := routeMgr.GetRouteSelector()
+	routeApprover := routeMgr.GetRouteApprover()
 
 	var routes []*selectRoute
 	for id, rt := range routesMap {
@@ -55,6 +59,9 @@ func (s *Server) ListNetworks(context.Context, *proto.ListNetworksRequest) (*pro
 			Domains:  rt[0].Domains,
 			Selected: routeSelector.IsSelected(id),
 		}
+		if routeApprover != nil {
+			route.PendingApproval = routeApprover.IsPending(id)
+		}
 		routes = append(routes, route)
 	}
 
@@ -77,11 +84,12 @@ func (s *Server) ListNetworks(context.Context, *proto.ListNetworksRequest) (*pro
 	var pbRoutes []*proto.Network
 	for _, route := range routes {
 		pbRoute := &proto.Network{
-			ID:          string(route.NetID),
-			Range:       route.Network.String(),
-			Domains:     route.Domains.ToSafeStringList(),
-			ResolvedIPs: map[string]*proto.IPList{},
-			Selected:    route.Selected,
+			ID:              string(route.NetID),
+			Range:           route.Network.String(),
+			Domains:         route.Domains.ToSafeStringList(),
+			ResolvedIPs:     map[string]*proto.IPList{},
+			Selected:        route.Selected,
+			PendingApproval: route.PendingApproval,
 		}
 
 		// Group resolved IPs by their parent domain
@@ -109,7 +117,8 @@ func (s *Server) ListNetworks(context.Context, *proto.ListNetworksRequest) (*pro
 	}
 
 	return &proto.ListNetworksResponse{
-		Routes: pbRoutes,
+		Routes:       pbRoutes,
+		ExitNodeAuto: routeSelector.IsExitNodeAuto(),
 	}, nil
 }
 
@@ -133,15 +142,26 @@ func (s *Server) SelectNetworks(_ context.Context, req *proto.SelectNetworksRequ
 	}
 
 	routeSelector := routeManager.GetRouteSelector()
-	if req.GetAll() {
+	routesMap := routeManager.GetClientRoutesWithNetID()
+	switch {
+	case req.GetAll():
 		routeSelector.SelectAllRoutes()
-	} else {
+	case req.GetExitNodeAuto() && len(req.GetNetworkIDs()) == 0:
+		// only the automatic exit node selection is turned on
+	default:
 		routes := toNetIDs(req.GetNetworkIDs())
-		netIdRoutes := maps.Keys(routeManager.GetClientRoutesWithNetID())
+		netIdRoutes := maps.Keys(routesMap)
 		if err := routeSelector.SelectRoutes(routes, req.GetAppend(), netIdRoutes); err != nil {
 			return nil, fmt.Errorf("select routes: %w", err)
 		}
 	}
+
+	if req.GetExitNodeAuto() {
+		routeSelector.SetExitNodeAuto(true)
+	} else if selectsExitNode(req.GetNetworkIDs(), routesMap) {
+		// the user picked an exit node
+		routeSelector.SetExitNodeAuto(false)
+	}
 	routeManager.TriggerSelection(routeManager.GetClientRoutes())
 
 	s.statusRecorder.PublishEvent(
@@ -150,9 +170,10 @@ func (s *Server) SelectNetworks(_ context.Context, req *proto.SelectNetworksRequ
 		"Network selection changed",
 		"",
 		map[string]string{
-			"networks": strings.Join(req.GetNetworkIDs(), ", "),
-			"append":   fmt.Sprint(req.GetAppend()),
-			"all":      fmt.Sprint(req.GetAll()),
+			"networks":       strings.Join(req.GetNetworkIDs(), ", "),
+			"append":         fmt.Sprint(req.GetAppend()),
+			"all":            fmt.Sprint(req.GetAll()),
+			"exit_node_auto": fmt.Sprint(req.GetExitNodeAuto()),
 		},
 	)
 
@@ -179,15 +200,22 @@ func (s *Server) DeselectNetworks(_ context.Context, req *proto.SelectNetworksRe
 	}
 
 	routeSelector := routeManager.GetRouteSelector()
-	if req.GetAll() {
+	switch {
+	case req.GetAll():
 		routeSelector.DeselectAllRoutes()
-	} else {
+	case req.GetExitNodeAuto() && len(req.GetNetworkIDs()) == 0:
+		// only the automatic exit node selection is turned off
+	default:
 		routes := toNetIDs(req.GetNetworkIDs())
 		netIdRoutes := maps.Keys(routeManager.GetClientRoutesWithNetID())
 		if err := routeSelector.DeselectRoutes(routes, netIdRoutes); err != nil {
 			return nil, fmt.Errorf("deselect routes: %w", err)
 		}
 	}
+
+	if req.GetExitNodeAuto() {
+		routeSelector.SetExitNodeAuto(false)
+	}
 	routeManager.TriggerSelection(routeManager.GetClientRoutes())
 
 	s.statusRecorder.PublishEvent(
@@ -196,15 +224,77 @@ func (s *Server) DeselectNetworks(_ context.Context, req *proto.SelectNetworksRe
 		"Network deselection changed",
 		"",
 		map[string]string{
-			"networks": strings.Join(req.GetNetworkIDs(), ", "),
-			"append":   fmt.Sprint(req.GetAppend()),
-			"all":      fmt.Sprint(req.GetAll()),
+			"networks":       strings.Join(req.GetNetworkIDs(), ", "),
+			"append":         fmt.Sprint(req.GetAppend()),
+			"all":            fmt.Sprint(req.GetAll()),
+			"exit_node_auto": fmt.Sprint(req.GetExitNodeAuto()),
 		},
 	)
 
 	return &proto.SelectNetworksResponse{}, nil
 }
 
+// ApproveNetworks approves or revokes the high-risk networks waiting for the approval of the local user.
+func (s *Server) ApproveNetworks(_ context.Context, req *proto.ApproveNetworksRequest) (*proto.ApproveNetworksResponse, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.connectClient == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+
+	engine := s.connectClient.Engine()
+	if engine == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+
+	routeManager := engine.GetRouteManager()
+	if routeManager == nil {
+		return nil, fmt.Errorf("no route manager")
+	}
+
+	routeApprover := routeManager.GetRouteApprover()
+	if routeApprover == nil {
+		return nil, fmt.Errorf("route approval is not enabled")
+	}
+
+	routes := toNetIDs(req.GetNetworkIDs())
+	if req.GetRevoke() {
+		routeApprover.RevokeRoutes(routes)
+	} else {
+		if err := routeApprover.ApproveRoutes(routes); err != nil {
+			return nil, fmt.Errorf("approve routes: %w", err)
+		}
+		for _, netID := range req.GetNetworkIDs() {
+			s.statusRecorder.ClearNotification(mgmProto.NotificationRouteApproval, netID)
+		}
+	}
+	routeManager.TriggerSelection(routeManager.GetClientRoutes())
+
+	s.statusRecorder.PublishEvent(
+		proto.SystemEvent_INFO,
+		proto.SystemEvent_NETWORK,
+		"Network approval changed",
+		"",
+		map[string]string{
+			"networks": strings.Join(req.GetNetworkIDs(), ", "),
+			"revoke":   fmt.Sprint(req.GetRevoke()),
+		},
+	)
+
+	return &proto.ApproveNetworksResponse{}, nil
+}
+
+// selectsExitNode returns true if one of the networks is an exit node
+func selectsExitNode(networkIDs []string, routesMap map[route.NetID][]*route.Route) bool {
+	for _, id := range networkIDs {
+		if exitnode.IsExitNode(routesMap[route.NetID(id)]) {
+			return true
+		}
+	}
+	return false
+}
+
 func toNetIDs(routes []string) []route.NetID {
 	var netIDs []route.NetID
 	for _, rt := range routes {
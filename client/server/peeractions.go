@@ -0,0 +1,34 @@
+//go:build !android && !ios
+
+package server
+
+import (
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+
+	"github.com/netbirdio/netbird/client/internal"
+)
+
+// peerActionHandler runs the parts of the peer actions requested by the management service that need the daemon,
+// for the config of a client connection
+type peerActionHandler struct {
+	server *Server
+	config *internal.Config
+}
+
+func (s *Server) newPeerActionHandler(config *internal.Config) internal.PeerActionHandler {
+	return &peerActionHandler{server: s, config: config}
+}
+
+// CollectStatus returns the anonymized status output, like the one of the remote debug bundles
+func (h *peerActionHandler) CollectStatus() string {
+	return h.server.remoteDebugBundleStatus()
+}
+
+// SavePrivateKey replaces the private key of the config and writes the config out
+func (h *peerActionHandler) SavePrivateKey(key wgtypes.Key) error {
+	h.server.mutex.Lock()
+	defer h.server.mutex.Unlock()
+
+	h.config.PrivateKey = key.String()
+	return internal.WriteOutConfig(h.server.latestConfigInput.ConfigPath, h.config)
+}
@@ -0,0 +1,12 @@
+//go:build android || ios
+
+package server
+
+import (
+	"github.com/netbirdio/netbird/client/internal"
+)
+
+// newPeerActionHandler returns no handler on mobile platforms, the actions needing the daemon fail there
+func (s *Server) newPeerActionHandler(*internal.Config) internal.PeerActionHandler {
+	return nil
+}
@@ -0,0 +1,255 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	gstatus "google.golang.org/grpc/status"
+
+	"github.com/netbirdio/netbird/client/iface"
+	"github.com/netbirdio/netbird/client/internal"
+	"github.com/netbirdio/netbird/client/internal/profiles"
+	"github.com/netbirdio/netbird/client/proto"
+)
+
+// profileDownTimeout is the time the daemon of a profile gets to bring the connection down before it's stopped
+const profileDownTimeout = 30 * time.Second
+
+// StartProfiles starts the daemons of the stored profiles. It's called by the default daemon only, daemonAddr and
+// logLevel are the ones of the default daemon the daemons of the profiles derive theirs from.
+func (s *Server) StartProfiles(daemonAddr, logLevel string) error {
+	store := profiles.NewStore(s.latestConfigInput.ConfigPath)
+	stored, err := store.Load()
+	if err != nil {
+		return err
+	}
+
+	supervisor, err := profiles.NewSupervisor(logLevel)
+	if err != nil {
+		return err
+	}
+
+	s.profilesMu.Lock()
+	defer s.profilesMu.Unlock()
+
+	s.profileStore = store
+	s.profileSupervisor = supervisor
+	s.profileDaemonAddr = daemonAddr
+	s.profiles = stored
+
+	for _, p := range s.profiles {
+		s.profileSupervisor.Start(s.rootCtx, p)
+	}
+	return nil
+}
+
+// StopProfiles brings the connections of the profiles down and stops their daemons
+func (s *Server) StopProfiles() {
+	s.profilesMu.Lock()
+	defer s.profilesMu.Unlock()
+
+	if s.profileSupervisor == nil {
+		return
+	}
+
+	for _, p := range s.profiles {
+		downProfile(p)
+	}
+	s.profileSupervisor.StopAll()
+}
+
+// ListProfiles lists the profiles running a daemon of their own next to the default one
+func (s *Server) ListProfiles(context.Context, *proto.ListProfilesRequest) (*proto.ListProfilesResponse, error) {
+	s.profilesMu.Lock()
+	defer s.profilesMu.Unlock()
+
+	if s.profileSupervisor == nil {
+		return nil, errProfilesUnavailable()
+	}
+
+	resp := &proto.ListProfilesResponse{}
+	for _, p := range s.profiles {
+		resp.Profiles = append(resp.Profiles, s.toProtoProfile(p))
+	}
+	return resp, nil
+}
+
+// AddProfile adds a profile and starts its daemon
+func (s *Server) AddProfile(ctx context.Context, req *proto.AddProfileRequest) (*proto.AddProfileResponse, error) {
+	s.profilesMu.Lock()
+	defer s.profilesMu.Unlock()
+
+	if s.profileSupervisor == nil {
+		return nil, errProfilesUnavailable()
+	}
+	if err := profiles.ValidateName(req.GetName()); err != nil {
+		return nil, gstatus.Error(codes.InvalidArgument, err.Error())
+	}
+	if s.findProfile(req.GetName()) != nil {
+		return nil, gstatus.Errorf(codes.AlreadyExists, "profile %s already exists", req.GetName())
+	}
+
+	index, err := profiles.NextIndex(s.profiles)
+	if err != nil {
+		return nil, gstatus.Error(codes.ResourceExhausted, err.Error())
+	}
+	p, err := profiles.New(req.GetName(), index, s.latestConfigInput.ConfigPath, s.profileDaemonAddr, s.logFile)
+	if err != nil {
+		return nil, gstatus.Error(codes.InvalidArgument, err.Error())
+	}
+
+	p.InterfaceName = req.GetInterfaceName()
+	if p.InterfaceName == "" {
+		p.InterfaceName = profileInterfaceName(index)
+	}
+	p.WgPort = int(req.GetWireguardPort())
+	if p.WgPort == 0 {
+		p.WgPort = iface.DefaultWgPort + index
+	}
+	if err := s.checkProfileConflicts(p); err != nil {
+		return nil, gstatus.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// the daemon of the profile starts with the interface, the port and the policy routing of the profile
+	if _, err := internal.UpdateOrCreateConfig(internal.ConfigInput{
+		ConfigPath:     p.ConfigPath,
+		InterfaceName:  &p.InterfaceName,
+		WireguardPort:  &p.WgPort,
+		Fwmark:         &p.Fwmark,
+		RoutingTableID: &p.RoutingTableID,
+	}); err != nil {
+		return nil, gstatus.Errorf(codes.Internal, "create config of profile %s: %v", p.Name, err)
+	}
+
+	if err := s.profileStore.Save(ctx, append(slices.Clone(s.profiles), p)); err != nil {
+		return nil, gstatus.Error(codes.Internal, err.Error())
+	}
+	s.profiles = append(s.profiles, p)
+
+	s.profileSupervisor.Start(s.rootCtx, p)
+	log.Infof("added profile %s on interface %s", p.Name, p.InterfaceName)
+
+	return &proto.AddProfileResponse{Profile: s.toProtoProfile(p)}, nil
+}
+
+// RemoveProfile stops the daemon of a profile and removes its config and state
+func (s *Server) RemoveProfile(ctx context.Context, req *proto.RemoveProfileRequest) (*proto.RemoveProfileResponse, error) {
+	s.profilesMu.Lock()
+	defer s.profilesMu.Unlock()
+
+	if s.profileSupervisor == nil {
+		return nil, errProfilesUnavailable()
+	}
+	p := s.findProfile(req.GetName())
+	if p == nil {
+		return nil, gstatus.Errorf(codes.NotFound, "profile %s not found", req.GetName())
+	}
+
+	remaining := slices.DeleteFunc(slices.Clone(s.profiles), func(other *profiles.Profile) bool {
+		return other.Name == p.Name
+	})
+	if err := s.profileStore.Save(ctx, remaining); err != nil {
+		return nil, gstatus.Error(codes.Internal, err.Error())
+	}
+	s.profiles = remaining
+
+	downProfile(p)
+	s.profileSupervisor.Stop(p.Name)
+
+	for _, dir := range []string{filepath.Dir(p.ConfigPath), p.StateDir} {
+		if err := os.RemoveAll(dir); err != nil {
+			log.Warnf("failed to remove %s of profile %s: %v", dir, p.Name, err)
+		}
+	}
+	log.Infof("removed profile %s", p.Name)
+
+	return &proto.RemoveProfileResponse{}, nil
+}
+
+func (s *Server) findProfile(name string) *profiles.Profile {
+	for _, p := range s.profiles {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// checkProfileConflicts checks that the interface and the port of the profile aren't used by the default daemon or
+// another profile
+func (s *Server) checkProfileConflicts(p *profiles.Profile) error {
+	s.mutex.Lock()
+	config := s.config
+	s.mutex.Unlock()
+
+	if config != nil && (config.WgIface == p.InterfaceName || config.WgPort == p.WgPort) {
+		return fmt.Errorf("interface %s or port %d is used by the default profile", p.InterfaceName, p.WgPort)
+	}
+	for _, other := range s.profiles {
+		if other.InterfaceName == p.InterfaceName || other.WgPort == p.WgPort {
+			return fmt.Errorf("interface %s or port %d is used by profile %s", p.InterfaceName, p.WgPort, other.Name)
+		}
+	}
+	return nil
+}
+
+func (s *Server) toProtoProfile(p *profiles.Profile) *proto.Profile {
+	return &proto.Profile{
+		Name:          p.Name,
+		InterfaceName: p.InterfaceName,
+		WireguardPort: int64(p.WgPort),
+		DaemonAddr:    p.DaemonAddr,
+		ConfigPath:    p.ConfigPath,
+		Running:       s.profileSupervisor.Running(p.Name),
+	}
+}
+
+// profileInterfaceName returns the default interface name of the nth profile, following the default one
+func profileInterfaceName(index int) string {
+	if runtime.GOOS == "darwin" {
+		return fmt.Sprintf("utun%d", 100+index)
+	}
+	return fmt.Sprintf("wt%d", index)
+}
+
+// downProfile brings the connection of the profile down over the API of its daemon, so the daemon restores the
+// system before it's stopped. It fails if the daemon isn't up, the daemon is stopped anyway.
+func downProfile(p *profiles.Profile) {
+	ctx, cancel := context.WithTimeout(context.Background(), profileDownTimeout)
+	defer cancel()
+
+	conn, err := grpc.NewClient(
+		strings.TrimPrefix(p.DaemonAddr, "tcp://"),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		log.Debugf("failed to connect to the daemon of profile %s: %v", p.Name, err)
+		return
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			log.Debugf("failed to close the connection to the daemon of profile %s: %v", p.Name, err)
+		}
+	}()
+
+	if _, err := proto.NewDaemonServiceClient(conn).Down(ctx, &proto.DownRequest{}); err != nil {
+		log.Debugf("failed to bring profile %s down: %v", p.Name, gstatus.Convert(err).Message())
+	}
+}
+
+func errProfilesUnavailable() error {
+	if profile := profiles.Current(); profile != "" {
+		return gstatus.Errorf(codes.FailedPrecondition, "this daemon runs profile %s, the profiles are managed by the default daemon", profile)
+	}
+	return gstatus.Error(codes.Unavailable, "the profiles aren't started")
+}
@@ -0,0 +1,217 @@
+//go:build !android && !ios
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	gstatus "google.golang.org/grpc/status"
+
+	"github.com/netbirdio/netbird/client/internal"
+	"github.com/netbirdio/netbird/client/proto"
+	nbstatus "github.com/netbirdio/netbird/client/status"
+	mgmProto "github.com/netbirdio/netbird/management/proto"
+	"github.com/netbirdio/netbird/version"
+)
+
+// remoteDebugBundle is a debug bundle requested by the management service waiting for the approval of the local user
+type remoteDebugBundle struct {
+	request *mgmProto.DebugBundleRequest
+	consent chan bool
+}
+
+// ListRemoteDebugBundles lists the debug bundles requested by the management service waiting for approval
+func (s *Server) ListRemoteDebugBundles(context.Context, *proto.ListRemoteDebugBundlesRequest) (*proto.ListRemoteDebugBundlesResponse, error) {
+	s.remoteDebugBundlesMu.Lock()
+	defer s.remoteDebugBundlesMu.Unlock()
+
+	resp := &proto.ListRemoteDebugBundlesResponse{}
+	for _, bundle := range s.remoteDebugBundles {
+		resp.Bundles = append(resp.Bundles, &proto.RemoteDebugBundle{
+			Id:        bundle.request.GetId(),
+			ExpiresAt: bundle.request.GetExpiresAt(),
+		})
+	}
+	sort.Slice(resp.Bundles, func(i, j int) bool {
+		return resp.Bundles[i].GetExpiresAt().AsTime().Before(resp.Bundles[j].GetExpiresAt().AsTime())
+	})
+
+	return resp, nil
+}
+
+// RespondRemoteDebugBundle approves or declines a debug bundle requested by the management service
+func (s *Server) RespondRemoteDebugBundle(_ context.Context, req *proto.RespondRemoteDebugBundleRequest) (*proto.RespondRemoteDebugBundleResponse, error) {
+	s.remoteDebugBundlesMu.Lock()
+	defer s.remoteDebugBundlesMu.Unlock()
+
+	bundle, ok := s.remoteDebugBundles[req.GetId()]
+	if !ok {
+		return nil, gstatus.Errorf(codes.NotFound, "debug bundle request %s not found", req.GetId())
+	}
+	delete(s.remoteDebugBundles, req.GetId())
+
+	// the channel is buffered and only written once, the request is removed from the pending ones above
+	bundle.consent <- req.GetApprove()
+
+	return &proto.RespondRemoteDebugBundleResponse{}, nil
+}
+
+// handleRemoteDebugBundle collects and uploads the debug bundle requested by the management service if the client
+// configuration or the local user allows it
+func (s *Server) handleRemoteDebugBundle(ctx context.Context, request *mgmProto.DebugBundleRequest) error {
+	s.mutex.Lock()
+	var mode string
+	if s.config != nil {
+		mode = s.config.RemoteDebugBundles
+	}
+	s.mutex.Unlock()
+
+	switch mode {
+	case internal.RemoteDebugBundlesDeny:
+		return fmt.Errorf("%w: remote debug bundles are disabled on the peer", internal.ErrDebugBundleDeclined)
+	case internal.RemoteDebugBundlesAllow:
+	default:
+		if err := s.awaitRemoteDebugBundleConsent(ctx, request); err != nil {
+			return err
+		}
+	}
+
+	if err := s.uploadRemoteDebugBundle(ctx, request); err != nil {
+		return fmt.Errorf("upload debug bundle: %w", err)
+	}
+
+	log.Infof("uploaded debug bundle %s", request.GetId())
+	return nil
+}
+
+// awaitRemoteDebugBundleConsent notifies the local user about the request and waits until the user approves or
+// declines it, or the upload URL expires
+func (s *Server) awaitRemoteDebugBundleConsent(ctx context.Context, request *mgmProto.DebugBundleRequest) error {
+	bundle := &remoteDebugBundle{
+		request: request,
+		consent: make(chan bool, 1),
+	}
+
+	s.remoteDebugBundlesMu.Lock()
+	if s.remoteDebugBundles == nil {
+		s.remoteDebugBundles = make(map[string]*remoteDebugBundle)
+	}
+	s.remoteDebugBundles[request.GetId()] = bundle
+	s.remoteDebugBundlesMu.Unlock()
+
+	defer func() {
+		s.remoteDebugBundlesMu.Lock()
+		delete(s.remoteDebugBundles, request.GetId())
+		s.remoteDebugBundlesMu.Unlock()
+	}()
+
+	s.statusRecorder.PublishEvent(
+		proto.SystemEvent_WARNING,
+		proto.SystemEvent_SYSTEM,
+		"Debug bundle requested by management",
+		fmt.Sprintf("Your administrator requested a debug bundle. Run 'netbird debug remote approve %s' to upload it.", request.GetId()),
+		map[string]string{"debug_bundle_id": request.GetId()},
+	)
+
+	timer := time.NewTimer(time.Until(request.GetExpiresAt().AsTime()))
+	defer timer.Stop()
+
+	select {
+	case approved := <-bundle.consent:
+		if !approved {
+			return fmt.Errorf("%w: declined by the local user", internal.ErrDebugBundleDeclined)
+		}
+		return nil
+	case <-timer.C:
+		return fmt.Errorf("%w: not approved before the request expired", internal.ErrDebugBundleDeclined)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// uploadRemoteDebugBundle creates an anonymized debug bundle and uploads it to the URL of the request
+func (s *Server) uploadRemoteDebugBundle(ctx context.Context, request *mgmProto.DebugBundleRequest) error {
+	bundleFile, err := os.CreateTemp("", "netbird.remote-debug.*.zip")
+	if err != nil {
+		return fmt.Errorf("create zip file: %w", err)
+	}
+	defer func() {
+		if closeErr := bundleFile.Close(); closeErr != nil {
+			log.Debugf("failed to close zip file: %v", closeErr)
+		}
+		if removeErr := os.Remove(bundleFile.Name()); removeErr != nil {
+			log.Errorf("Failed to remove zip file: %v", removeErr)
+		}
+	}()
+
+	s.mutex.Lock()
+	mgmURL := s.config.ManagementURL
+	err = s.createArchive(bundleFile, &proto.DebugBundleRequest{
+		Anonymize:  true,
+		Status:     s.remoteDebugBundleStatus(),
+		SystemInfo: true,
+	})
+	s.mutex.Unlock()
+	if err != nil {
+		return fmt.Errorf("create archive: %w", err)
+	}
+
+	uploadURL, err := url.Parse(request.GetUploadUrl())
+	if err != nil {
+		return fmt.Errorf("parse upload URL: %w", err)
+	}
+	uploadURL = mgmURL.ResolveReference(uploadURL)
+
+	info, err := bundleFile.Stat()
+	if err != nil {
+		return fmt.Errorf("stat zip file: %w", err)
+	}
+	if _, err := bundleFile.Seek(0, 0); err != nil {
+		return fmt.Errorf("rewind zip file: %w", err)
+	}
+
+	ctx, cancel := context.WithDeadline(ctx, request.GetExpiresAt().AsTime())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, uploadURL.String(), bundleFile)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.ContentLength = info.Size()
+	req.Header.Set("Content-Type", "application/zip")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.Debugf("failed to close response body: %v", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+	return nil
+}
+
+// remoteDebugBundleStatus returns the anonymized status output of the bundle, the CLI passes it in for local bundles
+func (s *Server) remoteDebugBundleStatus() string {
+	pbFullStatus := toProtoFullStatus(s.statusRecorder.GetFullStatus())
+	pbFullStatus.Events = s.statusRecorder.GetEventHistory()
+
+	statusResp := &proto.StatusResponse{
+		FullStatus:    pbFullStatus,
+		DaemonVersion: version.NetbirdVersion(),
+	}
+	return nbstatus.ParseToFullDetailSummary(nbstatus.ConvertToStatusOutputOverview(statusResp, true, "", nil, nil, nil))
+}
@@ -0,0 +1,18 @@
+//go:build android || ios
+
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/netbirdio/netbird/client/internal"
+	mgmProto "github.com/netbirdio/netbird/management/proto"
+)
+
+// remoteDebugBundle isn't used on mobile platforms, debug bundles can't be collected by the daemon there
+type remoteDebugBundle struct{}
+
+func (s *Server) handleRemoteDebugBundle(context.Context, *mgmProto.DebugBundleRequest) error {
+	return fmt.Errorf("%w: debug bundles aren't supported on this platform", internal.ErrDebugBundleDeclined)
+}
@@ -0,0 +1,33 @@
+package server
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/client/internal"
+	"github.com/netbirdio/netbird/client/server/statuspage"
+)
+
+// updateStatusPage serves the read-only status page while the client runs in router mode
+func (s *Server) updateStatusPage(config *internal.Config) {
+	s.statusPageMu.Lock()
+	defer s.statusPageMu.Unlock()
+
+	if config.RouterMode == (s.statusPage != nil) {
+		return
+	}
+
+	if !config.RouterMode {
+		if err := s.statusPage.Stop(); err != nil {
+			log.Warnf("failed to stop the router status page: %v", err)
+		}
+		s.statusPage = nil
+		return
+	}
+
+	page := statuspage.New(s, statuspage.Addr())
+	if err := page.Start(); err != nil {
+		log.Errorf("failed to start the router status page: %v", err)
+		return
+	}
+	s.statusPage = page
+}
@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -20,13 +22,20 @@ import (
 	gstatus "google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/netbirdio/netbird/client/configs"
 	"github.com/netbirdio/netbird/client/internal/auth"
+	"github.com/netbirdio/netbird/client/internal/crashreport"
+	"github.com/netbirdio/netbird/client/internal/mdm"
 	"github.com/netbirdio/netbird/client/system"
 	"github.com/netbirdio/netbird/management/domain"
 
 	"github.com/netbirdio/netbird/client/internal"
 	"github.com/netbirdio/netbird/client/internal/peer"
+	"github.com/netbirdio/netbird/client/internal/profiles"
 	"github.com/netbirdio/netbird/client/proto"
+	"github.com/netbirdio/netbird/client/server/statuspage"
+	"github.com/netbirdio/netbird/util/errcode"
+	"github.com/netbirdio/netbird/util/pqtls"
 	"github.com/netbirdio/netbird/version"
 )
 
@@ -66,6 +75,26 @@ type Server struct {
 
 	lastProbe         time.Time
 	persistNetworkMap bool
+
+	crashReporter *crashreport.Reporter
+
+	// managedPolicy is the device management policy, its settings take precedence over the settings of the user
+	managedPolicy *mdm.Policy
+
+	statusPageMu sync.Mutex
+	// statusPage is the read-only status page served in router mode
+	statusPage *statuspage.Page
+
+	remoteDebugBundlesMu sync.Mutex
+	// remoteDebugBundles are the debug bundles requested by the management service waiting for approval
+	remoteDebugBundles map[string]*remoteDebugBundle
+
+	profilesMu sync.Mutex
+	// profileSupervisor runs the daemons of the profiles, it's nil unless StartProfiles is called by the default daemon
+	profileSupervisor *profiles.Supervisor
+	profileStore      *profiles.Store
+	profiles          []*profiles.Profile
+	profileDaemonAddr string
 }
 
 type oauthAuthFlow struct {
@@ -85,6 +114,8 @@ func New(ctx context.Context, configPath, logFile string) *Server {
 		logFile:           logFile,
 		persistNetworkMap: true,
 		statusRecorder:    peer.NewRecorder(""),
+		crashReporter:     crashreport.NewReporter(filepath.Join(configs.StateDir, crashDir)),
+		managedPolicy:     &mdm.Policy{},
 	}
 }
 
@@ -97,6 +128,10 @@ func (s *Server) Start() error {
 		log.Warnf("failed to redirect stderr: %v", err)
 	}
 
+	crashReport := s.collectCrashReport()
+	// the report is handled once the configuration is loaded, it's part of the report
+	defer s.handleCrashReport(crashReport)
+
 	if err := restoreResidualState(s.rootCtx); err != nil {
 		log.Warnf(errRestoreResidualState, err)
 	}
@@ -116,6 +151,9 @@ func (s *Server) Start() error {
 	ctx, cancel := context.WithCancel(s.rootCtx)
 	s.actCancel = cancel
 
+	s.loadManagedPolicy()
+	s.managedPolicy.Apply(&s.latestConfigInput)
+
 	// if configuration exists, we just start connections. if is new config we skip and set status NeedsLogin
 	// on failure we return error to retry
 	config, err := internal.UpdateConfig(s.latestConfigInput)
@@ -125,8 +163,12 @@ func (s *Server) Start() error {
 			log.Warnf("unable to create configuration file: %v", err)
 			return err
 		}
-		state.Set(internal.StatusNeedsLogin)
-		return nil
+		// with a managed setup key the new peer is registered without waiting for the user to log in
+		if s.managedPolicy.SetupKey == "" {
+			state.Set(internal.StatusNeedsLogin)
+			return nil
+		}
+		config = s.config
 	} else if err != nil {
 		log.Warnf("unable to create configuration file: %v", err)
 		return err
@@ -136,6 +178,7 @@ func (s *Server) Start() error {
 	config, _ = internal.UpdateOldManagementURL(ctx, config, s.latestConfigInput.ConfigPath)
 
 	s.config = config
+	s.updateStatusPage(config)
 
 	s.statusRecorder.UpdateManagementAddress(config.ManagementURL.String())
 	s.statusRecorder.UpdateRosenpass(config.RosenpassEnabled, config.RosenpassPermissive)
@@ -149,7 +192,17 @@ func (s *Server) Start() error {
 		return nil
 	}
 
-	go s.connectWithRetryRuns(ctx, config, s.statusRecorder, nil)
+	setupKey := s.managedPolicy.SetupKey
+	go func() {
+		if setupKey != "" {
+			if loginStatus, err := s.loginAttempt(ctx, setupKey, ""); err != nil {
+				log.Errorf("failed to log in with the managed setup key: %v", err)
+				state.Set(loginStatus)
+				return
+			}
+		}
+		s.connectWithRetryRuns(ctx, config, s.statusRecorder, nil)
+	}()
 
 	return nil
 }
@@ -190,6 +243,8 @@ func (s *Server) connectWithRetryRuns(ctx context.Context, config *internal.Conf
 		log.Tracef("running client connection")
 		s.connectClient = internal.NewConnectClient(ctx, config, statusRecorder)
 		s.connectClient.SetNetworkMapPersistence(s.persistNetworkMap)
+		s.connectClient.SetDebugBundleHandler(s.handleRemoteDebugBundle)
+		s.connectClient.SetPeerActionHandler(s.newPeerActionHandler(config))
 
 		err := s.connectClient.Run(runningChan)
 		if err != nil {
@@ -412,11 +467,167 @@ func (s *Server) Login(callerCtx context.Context, msg *proto.LoginRequest) (*pro
 		s.latestConfigInput.DNSLabels = dnsLabels
 	}
 
+	if msg.CleanUplinks {
+		inputConfig.Uplinks = make([]string, 0)
+		s.latestConfigInput.Uplinks = nil
+	} else if msg.Uplinks != nil {
+		inputConfig.Uplinks = msg.Uplinks
+		s.latestConfigInput.Uplinks = msg.Uplinks
+	}
+
 	if msg.DisableNotifications != nil {
 		inputConfig.DisableNotifications = msg.DisableNotifications
 		s.latestConfigInput.DisableNotifications = msg.DisableNotifications
 	}
 
+	if msg.CrashReportUrl != nil {
+		inputConfig.CrashReportURL = msg.CrashReportUrl
+		s.latestConfigInput.CrashReportURL = msg.CrashReportUrl
+	}
+
+	if msg.StaticMeshFile != nil {
+		inputConfig.StaticMeshFile = msg.StaticMeshFile
+		s.latestConfigInput.StaticMeshFile = msg.StaticMeshFile
+	}
+
+	if msg.RouterMode != nil {
+		inputConfig.RouterMode = msg.RouterMode
+		s.latestConfigInput.RouterMode = msg.RouterMode
+	}
+
+	if msg.CleanRouterLANs {
+		inputConfig.RouterLANs = make([]string, 0)
+		s.latestConfigInput.RouterLANs = nil
+	} else if msg.RouterLans != nil {
+		inputConfig.RouterLANs = msg.RouterLans
+		s.latestConfigInput.RouterLANs = msg.RouterLans
+	}
+
+	if msg.RouteLearning != nil {
+		inputConfig.RouteLearning = msg.RouteLearning
+		s.latestConfigInput.RouteLearning = msg.RouteLearning
+	}
+
+	if msg.CleanRouteLearningFilters {
+		inputConfig.RouteLearningFilters = make([]string, 0)
+		s.latestConfigInput.RouteLearningFilters = nil
+	} else if msg.RouteLearningFilters != nil {
+		inputConfig.RouteLearningFilters = msg.RouteLearningFilters
+		s.latestConfigInput.RouteLearningFilters = msg.RouteLearningFilters
+	}
+
+	if msg.RouteDrainPeriod != nil {
+		period := msg.RouteDrainPeriod.AsDuration()
+		inputConfig.RouteDrainPeriod = &period
+		s.latestConfigInput.RouteDrainPeriod = &period
+	}
+
+	if msg.CleanSplitTunnelApps {
+		inputConfig.SplitTunnelApps = make([]string, 0)
+		s.latestConfigInput.SplitTunnelApps = nil
+	} else if msg.SplitTunnelApps != nil {
+		inputConfig.SplitTunnelApps = msg.SplitTunnelApps
+		s.latestConfigInput.SplitTunnelApps = msg.SplitTunnelApps
+	}
+
+	if msg.CleanSplitTunnelUsers {
+		inputConfig.SplitTunnelUsers = make([]string, 0)
+		s.latestConfigInput.SplitTunnelUsers = nil
+	} else if msg.SplitTunnelUsers != nil {
+		inputConfig.SplitTunnelUsers = msg.SplitTunnelUsers
+		s.latestConfigInput.SplitTunnelUsers = msg.SplitTunnelUsers
+	}
+
+	if msg.CleanOverlayOnlyServices {
+		inputConfig.OverlayOnlyServices = make([]string, 0)
+		s.latestConfigInput.OverlayOnlyServices = nil
+	} else if msg.OverlayOnlyServices != nil {
+		inputConfig.OverlayOnlyServices = msg.OverlayOnlyServices
+		s.latestConfigInput.OverlayOnlyServices = msg.OverlayOnlyServices
+	}
+
+	if msg.Fwmark != nil {
+		inputConfig.Fwmark = msg.Fwmark
+		s.latestConfigInput.Fwmark = msg.Fwmark
+	}
+
+	if msg.RoutingTableId != nil {
+		tableID := int(msg.GetRoutingTableId())
+		inputConfig.RoutingTableID = &tableID
+		s.latestConfigInput.RoutingTableID = &tableID
+	}
+
+	if msg.RequireRouteApproval != nil {
+		inputConfig.RequireRouteApproval = msg.RequireRouteApproval
+		s.latestConfigInput.RequireRouteApproval = msg.RequireRouteApproval
+	}
+
+	if msg.ExitNodeStickyGrace != nil {
+		grace := msg.ExitNodeStickyGrace.AsDuration()
+		inputConfig.ExitNodeStickyGrace = &grace
+		s.latestConfigInput.ExitNodeStickyGrace = &grace
+	}
+
+	if msg.DnsHostsFile != nil {
+		inputConfig.DNSHostsFile = msg.DnsHostsFile
+		s.latestConfigInput.DNSHostsFile = msg.DnsHostsFile
+	}
+
+	if msg.MaxIceNegotiations != nil {
+		n := int(*msg.MaxIceNegotiations)
+		inputConfig.MaxICENegotiations = &n
+		s.latestConfigInput.MaxICENegotiations = &n
+	}
+
+	if msg.LanGatewayVip != nil {
+		inputConfig.LANGatewayVIP = msg.LanGatewayVip
+		s.latestConfigInput.LANGatewayVIP = msg.LanGatewayVip
+	}
+
+	if msg.LanGatewayInterface != nil {
+		inputConfig.LANGatewayInterface = msg.LanGatewayInterface
+		s.latestConfigInput.LANGatewayInterface = msg.LanGatewayInterface
+	}
+
+	if msg.LanGatewayPriority != nil {
+		priority := int(*msg.LanGatewayPriority)
+		inputConfig.LANGatewayPriority = &priority
+		s.latestConfigInput.LANGatewayPriority = &priority
+	}
+
+	if msg.CleanLanGatewayPeers {
+		inputConfig.LANGatewayPeers = make([]string, 0)
+		s.latestConfigInput.LANGatewayPeers = nil
+	} else if msg.LanGatewayPeers != nil {
+		inputConfig.LANGatewayPeers = msg.LanGatewayPeers
+		s.latestConfigInput.LANGatewayPeers = msg.LanGatewayPeers
+	}
+
+	if msg.FileTransferDir != nil {
+		inputConfig.FileTransferDir = msg.FileTransferDir
+		s.latestConfigInput.FileTransferDir = msg.FileTransferDir
+	}
+
+	if msg.CleanRemoteActions {
+		inputConfig.RemoteActions = make([]string, 0)
+		s.latestConfigInput.RemoteActions = nil
+	} else if msg.RemoteActions != nil {
+		inputConfig.RemoteActions = msg.RemoteActions
+		s.latestConfigInput.RemoteActions = msg.RemoteActions
+	}
+
+	if remoteDebugBundles := msg.GetRemoteDebugBundles(); remoteDebugBundles != "" {
+		inputConfig.RemoteDebugBundles = &remoteDebugBundles
+		s.latestConfigInput.RemoteDebugBundles = &remoteDebugBundles
+	}
+
+	s.loadManagedPolicy()
+	if overridden := s.managedPolicy.Apply(&inputConfig); len(overridden) > 0 {
+		log.Warnf("ignoring the values of the settings %s, they're managed by the device policy", strings.Join(overridden, ", "))
+	}
+	s.managedPolicy.Apply(&s.latestConfigInput)
+	managedSetupKey := s.managedPolicy.SetupKey
+
 	s.mutex.Unlock()
 
 	if msg.OptionalPreSharedKey != nil {
@@ -438,6 +649,14 @@ func (s *Server) Login(callerCtx context.Context, msg *proto.LoginRequest) (*pro
 	s.config = config
 	s.mutex.Unlock()
 
+	s.updateStatusPage(config)
+
+	if config.StaticMeshFile != "" {
+		log.Infof("static mesh mode, skipping the login to the management service")
+		state.Set(internal.StatusIdle)
+		return &proto.LoginResponse{}, nil
+	}
+
 	if _, err := s.loginAttempt(ctx, "", ""); err == nil {
 		state.Set(internal.StatusIdle)
 		return &proto.LoginResponse{}, nil
@@ -445,7 +664,17 @@ func (s *Server) Login(callerCtx context.Context, msg *proto.LoginRequest) (*pro
 
 	state.Set(internal.StatusConnecting)
 
-	if msg.SetupKey == "" {
+	setupKey := msg.SetupKey
+	if managedSetupKey != "" {
+		setupKey = managedSetupKey
+	}
+
+	if setupKey == "" && config.RouterMode {
+		state.Set(internal.StatusLoginFailed)
+		return nil, errcode.Status(codes.FailedPrecondition, errcode.LoginRequired, internal.ErrInteractiveLoginDisabled.Error()).Err()
+	}
+
+	if setupKey == "" {
 		oAuthFlow, err := auth.NewOAuthFlow(ctx, config, msg.IsLinuxDesktopClient)
 		if err != nil {
 			state.Set(internal.StatusLoginFailed)
@@ -491,7 +720,7 @@ func (s *Server) Login(callerCtx context.Context, msg *proto.LoginRequest) (*pro
 		}, nil
 	}
 
-	if loginStatus, err := s.loginAttempt(ctx, msg.SetupKey, ""); err != nil {
+	if loginStatus, err := s.loginAttempt(ctx, setupKey, ""); err != nil {
 		state.Set(loginStatus)
 		return nil, err
 	}
@@ -617,7 +846,7 @@ func (s *Server) Up(callerCtx context.Context, _ *proto.UpRequest) (*proto.UpRes
 	s.actCancel = cancel
 
 	if s.config == nil {
-		return nil, fmt.Errorf("config is not defined, please call login command first")
+		return nil, errcode.Errorf(errcode.LoginRequired, "config is not defined, please call login command first")
 	}
 
 	s.statusRecorder.UpdateManagementAddress(s.config.ManagementURL.String())
@@ -644,6 +873,12 @@ func (s *Server) Up(callerCtx context.Context, _ *proto.UpRequest) (*proto.UpRes
 
 // Down engine work in the daemon.
 func (s *Server) Down(ctx context.Context, _ *proto.DownRequest) (*proto.DownResponse, error) {
+	// the routed flows are drained before taking the lock, the status stays available meanwhile
+	s.mutex.Lock()
+	connectClient := s.connectClient
+	s.mutex.Unlock()
+	connectClient.Drain(ctx)
+
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
@@ -762,6 +997,8 @@ func (s *Server) GetConfig(_ context.Context, _ *proto.GetConfigRequest) (*proto
 		RosenpassEnabled:     s.config.RosenpassEnabled,
 		RosenpassPermissive:  s.config.RosenpassPermissive,
 		DisableNotifications: disableNotifications,
+		DisabledUIActions:    s.managedPolicy.DisabledUIActions,
+		ManagedSettings:      s.managedPolicy.ManagedSettings(),
 	}, nil
 }
 
@@ -786,12 +1023,14 @@ func toProtoFullStatus(fullStatus peer.FullStatus) *proto.FullStatus {
 
 	pbFullStatus.ManagementState.URL = fullStatus.ManagementState.URL
 	pbFullStatus.ManagementState.Connected = fullStatus.ManagementState.Connected
+	pbFullStatus.ManagementState.KeyExchange = pqtls.KeyExchange(fullStatus.ManagementState.URL)
 	if err := fullStatus.ManagementState.Error; err != nil {
 		pbFullStatus.ManagementState.Error = err.Error()
 	}
 
 	pbFullStatus.SignalState.URL = fullStatus.SignalState.URL
 	pbFullStatus.SignalState.Connected = fullStatus.SignalState.Connected
+	pbFullStatus.SignalState.KeyExchange = pqtls.KeyExchange(fullStatus.SignalState.URL)
 	if err := fullStatus.SignalState.Error; err != nil {
 		pbFullStatus.SignalState.Error = err.Error()
 	}
@@ -805,6 +1044,15 @@ func toProtoFullStatus(fullStatus peer.FullStatus) *proto.FullStatus {
 	pbFullStatus.LocalPeerState.Networks = maps.Keys(fullStatus.LocalPeerState.Routes)
 	pbFullStatus.NumberOfForwardingRules = int32(fullStatus.NumOfForwardingRules)
 
+	if exitNode := fullStatus.ExitNodeState; exitNode.Auto {
+		pbFullStatus.ExitNode = &proto.ExitNodeState{
+			Auto:      true,
+			NetworkID: exitNode.NetID,
+			Latency:   durationpb.New(exitNode.Latency),
+			Loss:      float32(exitNode.Loss),
+		}
+	}
+
 	for _, peerState := range fullStatus.Peers {
 		pbPeerState := &proto.PeerState{
 			IP:                         peerState.IP,
@@ -824,14 +1072,21 @@ func toProtoFullStatus(fullStatus peer.FullStatus) *proto.FullStatus {
 			RosenpassEnabled:           peerState.RosenpassEnabled,
 			Networks:                   maps.Keys(peerState.GetRoutes()),
 			Latency:                    durationpb.New(peerState.Latency),
+			ProbeRtt:                   durationpb.New(peerState.ProbeRTT),
+			ProbeLoss:                  float32(peerState.ProbeLoss),
+		}
+		if health := peerState.Health(time.Now()); health.Grade != "" {
+			pbPeerState.HealthScore = int32(health.Score)
+			pbPeerState.HealthGrade = string(health.Grade)
 		}
 		pbFullStatus.Peers = append(pbFullStatus.Peers, pbPeerState)
 	}
 
 	for _, relayState := range fullStatus.Relays {
 		pbRelayState := &proto.RelayState{
-			URI:       relayState.URI,
-			Available: relayState.Err == nil,
+			URI:         relayState.URI,
+			Available:   relayState.Err == nil,
+			KeyExchange: pqtls.KeyExchange(relayState.URI),
 		}
 		if err := relayState.Err; err != nil {
 			pbRelayState.Error = err.Error()
@@ -0,0 +1,14 @@
+package server
+
+import (
+	"github.com/netbirdio/netbird/client/firewall/winnat"
+	"github.com/netbirdio/netbird/client/internal/dns"
+	"github.com/netbirdio/netbird/client/internal/routemanager/systemops"
+	"github.com/netbirdio/netbird/client/internal/statemanager"
+)
+
+func registerStates(mgr *statemanager.Manager) {
+	mgr.RegisterState(&dns.ShutdownState{})
+	mgr.RegisterState(&systemops.ShutdownState{})
+	mgr.RegisterState(&winnat.ShutdownState{})
+}
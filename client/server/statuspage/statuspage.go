@@ -0,0 +1,151 @@
+// Package statuspage serves the read-only status page of a client running in router mode. It shows the connection to
+// the control plane and the state of the peers for the operators of gateway appliances without a shell on the device.
+// The page doesn't accept any change, the same status is available as JSON for monitoring.
+package statuspage
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/netbirdio/netbird/client/proto"
+)
+
+const (
+	// EnvAddr overrides the listen address of the status page
+	EnvAddr = "NB_ROUTER_STATUS_ADDR"
+	// DefaultAddr is the listen address of the status page, it's only reachable from the appliance by default
+	DefaultAddr = "127.0.0.1:8091"
+
+	statusTimeout = 5 * time.Second
+)
+
+//go:embed status.html
+var pageTemplate string
+
+var tmpl = template.Must(template.New("status").Funcs(template.FuncMap{
+	"since": since,
+}).Parse(pageTemplate))
+
+// StatusProvider returns the status of the daemon
+type StatusProvider interface {
+	Status(ctx context.Context, req *proto.StatusRequest) (*proto.StatusResponse, error)
+}
+
+// Page serves the status page
+type Page struct {
+	provider StatusProvider
+	server   *http.Server
+}
+
+// Addr returns the listen address of the status page from the environment
+func Addr() string {
+	if addr := os.Getenv(EnvAddr); addr != "" {
+		return addr
+	}
+	return DefaultAddr
+}
+
+// New returns a status page listening on addr
+func New(provider StatusProvider, addr string) *Page {
+	p := &Page{
+		provider: provider,
+	}
+	p.server = &http.Server{
+		Addr:              addr,
+		Handler:           p.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return p
+}
+
+// Start listens on the status page address and serves the requests in the background
+func (p *Page) Start() error {
+	listener, err := net.Listen("tcp", p.server.Addr)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", p.server.Addr, err)
+	}
+
+	go func() {
+		if err := p.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Errorf("failed to serve the status page: %v", err)
+		}
+	}()
+
+	log.Infof("started the router status page on http://%s", listener.Addr())
+	return nil
+}
+
+// Stop closes the listener and the open connections
+func (p *Page) Stop() error {
+	return p.server.Close()
+}
+
+func (p *Page) handler() http.Handler {
+	router := mux.NewRouter()
+	router.HandleFunc("/", p.getPage).Methods(http.MethodGet)
+	router.HandleFunc("/status.json", p.getJSON).Methods(http.MethodGet)
+	return router
+}
+
+func (p *Page) status(r *http.Request) (*proto.StatusResponse, error) {
+	ctx, cancel := context.WithTimeout(r.Context(), statusTimeout)
+	defer cancel()
+
+	return p.provider.Status(ctx, &proto.StatusRequest{GetFullPeerStatus: true})
+}
+
+func (p *Page) getPage(w http.ResponseWriter, r *http.Request) {
+	status, err := p.status(r)
+	if err != nil {
+		log.Debugf("failed to get the status for the status page: %v", err)
+		http.Error(w, "failed to get the status", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := tmpl.Execute(w, status); err != nil {
+		log.Debugf("failed to render the status page: %v", err)
+	}
+}
+
+func (p *Page) getJSON(w http.ResponseWriter, r *http.Request) {
+	status, err := p.status(r)
+	if err != nil {
+		log.Debugf("failed to get the status for the status page: %v", err)
+		http.Error(w, "failed to get the status", http.StatusServiceUnavailable)
+		return
+	}
+
+	data, err := protojson.Marshal(status)
+	if err != nil {
+		http.Error(w, "failed to encode the status", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if _, err := w.Write(data); err != nil {
+		log.Debugf("failed to write the status: %v", err)
+	}
+}
+
+// since formats the time elapsed since the handshake of a peer
+func since(state *proto.PeerState) string {
+	handshake := state.GetLastWireguardHandshake()
+	if handshake == nil || handshake.AsTime().IsZero() || handshake.AsTime().Unix() <= 0 {
+		return "-"
+	}
+	return time.Since(handshake.AsTime()).Truncate(time.Second).String() + " ago"
+}
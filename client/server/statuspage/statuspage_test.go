@@ -0,0 +1,73 @@
+package statuspage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/client/proto"
+)
+
+type fakeProvider struct {
+	resp *proto.StatusResponse
+}
+
+func (p *fakeProvider) Status(context.Context, *proto.StatusRequest) (*proto.StatusResponse, error) {
+	return p.resp, nil
+}
+
+func get(t *testing.T, url string) (*http.Response, string) {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(data)
+}
+
+func TestPage(t *testing.T) {
+	provider := &fakeProvider{resp: &proto.StatusResponse{
+		Status:        "Connected",
+		DaemonVersion: "0.40.0",
+		FullStatus: &proto.FullStatus{
+			ManagementState: &proto.ManagementState{Connected: true},
+			LocalPeerState:  &proto.LocalPeerState{IP: "100.64.0.1/16", Networks: []string{"192.168.1.0/24"}},
+			Peers: []*proto.PeerState{
+				{Fqdn: "laptop.netbird.cloud", IP: "100.64.0.2", ConnStatus: "Connected", Relayed: true},
+			},
+		},
+	}}
+	server := httptest.NewServer(New(provider, "127.0.0.1:0").handler())
+	defer server.Close()
+
+	resp, body := get(t, server.URL+"/")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, body, "192.168.1.0/24")
+	assert.Contains(t, body, "laptop.netbird.cloud")
+	assert.Contains(t, body, "relayed")
+	assert.Contains(t, body, `<span class="ko">disconnected</span>`, "the signal state is missing")
+
+	resp, body = get(t, server.URL+"/status.json")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, body, `"daemonVersion":"0.40.0"`)
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/", nil)
+	require.NoError(t, err)
+	postResp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer postResp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, postResp.StatusCode, "the page should be read-only")
+
+	provider.resp = &proto.StatusResponse{Status: "Idle"}
+	resp, body = get(t, server.URL+"/")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, body, "Status: Idle")
+}
@@ -13,11 +13,19 @@ import (
 // Client wraps crypto/ssh Client to simplify usage
 type Client struct {
 	client *ssh.Client
+	// via is the jump host the client is connected through, nil if it's connected directly
+	via *Client
 }
 
 // Close closes the wrapped SSH Client
 func (c *Client) Close() error {
-	return c.client.Close()
+	err := c.client.Close()
+	if c.via != nil {
+		if viaErr := c.via.Close(); err == nil {
+			err = viaErr
+		}
+	}
+	return err
 }
 
 // OpenTerminal starts an interactive terminal session with the remote SSH server
@@ -0,0 +1,183 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gliderlabs/ssh"
+	log "github.com/sirupsen/logrus"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// jumpDialTimeout is the time the jump host gets to connect to the host of the routed network
+const jumpDialTimeout = 10 * time.Second
+
+type contextKey string
+
+// peerKeyContextKey holds the WireGuard public key of the peer the SSH key of the connection belongs to
+const peerKeyContextKey contextKey = "netbird-peer-key"
+
+// JumpPolicy authorizes and audits the jumps of the peers to the hosts of the routed networks
+type JumpPolicy interface {
+	// Authorize returns an error if the peer with the WireGuard public key peer, connected from its NetBird IP src,
+	// isn't allowed to reach dst through the routing peer
+	Authorize(peer string, src netip.Addr, dst netip.AddrPort) error
+	// Audit records a finished jump
+	Audit(audit JumpAudit)
+}
+
+// JumpAudit is the record of a finished jump
+type JumpAudit struct {
+	Peer     string
+	Source   netip.Addr
+	User     string
+	Dest     netip.AddrPort
+	Duration time.Duration
+	// BytesIn and BytesOut are the bytes received from and sent to the host of the routed network
+	BytesIn  int64
+	BytesOut int64
+}
+
+// directTCPIPData is the payload of a direct-tcpip channel request, RFC 4254 section 7.2
+type directTCPIPData struct {
+	DestAddr   string
+	DestPort   uint32
+	OriginAddr string
+	OriginPort uint32
+}
+
+// SetJumpPolicy allows the peers to jump to the hosts of the routed networks, like ssh -J does with OpenSSH. Nil denies
+// all the jumps.
+func (srv *DefaultServer) SetJumpPolicy(policy JumpPolicy) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	srv.jumpPolicy = policy
+}
+
+// jumpHandler forwards a direct-tcpip channel to a host of the routed networks if the policy allows the peer to reach it
+func (srv *DefaultServer) jumpHandler(_ *ssh.Server, conn *gossh.ServerConn, newChan gossh.NewChannel, ctx ssh.Context) {
+	var data directTCPIPData
+	if err := gossh.Unmarshal(newChan.ExtraData(), &data); err != nil {
+		_ = newChan.Reject(gossh.ConnectionFailed, "invalid forward data")
+		return
+	}
+
+	policy, peer, src, dst, err := srv.authorizeJump(ctx, conn.RemoteAddr(), data)
+	if err != nil {
+		log.Warnf("denied SSH jump of user %s from %s to %s:%d: %v", conn.User(), conn.RemoteAddr(), data.DestAddr, data.DestPort, err)
+		_ = newChan.Reject(gossh.Prohibited, err.Error())
+		return
+	}
+
+	dialer := net.Dialer{Timeout: jumpDialTimeout}
+	target, err := dialer.DialContext(ctx, "tcp", dst.String())
+	if err != nil {
+		log.Infof("SSH jump of peer %s from %s to %s failed: %v", peer, src, dst, err)
+		_ = newChan.Reject(gossh.ConnectionFailed, err.Error())
+		return
+	}
+
+	ch, reqs, err := newChan.Accept()
+	if err != nil {
+		_ = target.Close()
+		return
+	}
+	go gossh.DiscardRequests(reqs)
+
+	log.Infof("SSH jump of user %s, peer %s from %s to %s", conn.User(), peer, src, dst)
+	audit := JumpAudit{Peer: peer, Source: src, User: conn.User(), Dest: dst}
+	start := time.Now()
+	audit.BytesOut, audit.BytesIn = relay(ch, target)
+	audit.Duration = time.Since(start)
+
+	log.Infof("SSH jump of user %s, peer %s from %s to %s ended after %s: %d bytes in, %d bytes out",
+		audit.User, audit.Peer, audit.Source, audit.Dest, audit.Duration.Round(time.Second), audit.BytesIn, audit.BytesOut)
+	policy.Audit(audit)
+}
+
+// authorizeJump checks that the SSH key of the connection belongs to a peer and the policy allows the peer to reach the
+// destination
+func (srv *DefaultServer) authorizeJump(ctx ssh.Context, remote net.Addr, data directTCPIPData) (JumpPolicy, string, netip.Addr, netip.AddrPort, error) {
+	srv.mu.Lock()
+	policy := srv.jumpPolicy
+	srv.mu.Unlock()
+
+	if policy == nil {
+		return nil, "", netip.Addr{}, netip.AddrPort{}, errors.New("jumps are disabled")
+	}
+
+	peer, ok := ctx.Value(peerKeyContextKey).(string)
+	if !ok || peer == "" {
+		return nil, "", netip.Addr{}, netip.AddrPort{}, errors.New("the key doesn't belong to a peer")
+	}
+
+	src, err := netip.ParseAddrPort(remote.String())
+	if err != nil {
+		return nil, "", netip.Addr{}, netip.AddrPort{}, fmt.Errorf("parse source address: %w", err)
+	}
+
+	// the hosts are resolved by the client, names would bypass the policy of the destination
+	dstIP, err := netip.ParseAddr(data.DestAddr)
+	if err != nil {
+		return nil, "", netip.Addr{}, netip.AddrPort{}, fmt.Errorf("destination %q isn't an IP address", data.DestAddr)
+	}
+	if data.DestPort == 0 || data.DestPort > 65535 {
+		return nil, "", netip.Addr{}, netip.AddrPort{}, fmt.Errorf("invalid destination port %d", data.DestPort)
+	}
+	dst := netip.AddrPortFrom(dstIP.Unmap(), uint16(data.DestPort))
+
+	if err := policy.Authorize(peer, src.Addr().Unmap(), dst); err != nil {
+		return nil, "", netip.Addr{}, netip.AddrPort{}, err
+	}
+	return policy, peer, src.Addr().Unmap(), dst, nil
+}
+
+// relay copies the traffic of the channel to the target and back until either side closes, it returns the bytes sent
+// to and received from the target
+func relay(ch gossh.Channel, target net.Conn) (int64, int64) {
+	var sent, received atomic.Int64
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		n, _ := io.Copy(target, ch)
+		sent.Store(n)
+		if tcp, ok := target.(*net.TCPConn); ok {
+			_ = tcp.CloseWrite()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		n, _ := io.Copy(ch, target)
+		received.Store(n)
+		_ = ch.CloseWrite()
+	}()
+
+	wg.Wait()
+	_ = ch.Close()
+	_ = target.Close()
+	return sent.Load(), received.Load()
+}
+
+// Jump connects through the jump host to the SSH server at addr, the host of a network routed by the jump host
+func (c *Client) Jump(addr string, config *gossh.ClientConfig) (*Client, error) {
+	conn, err := c.client.Dial("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("jump to %s: %w", addr, err)
+	}
+
+	clientConn, chans, reqs, err := gossh.NewClientConn(conn, addr, config)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("connect to %s: %w", addr, err)
+	}
+	return &Client{client: gossh.NewClient(clientConn, chans, reqs), via: c}, nil
+}
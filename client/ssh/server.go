@@ -41,6 +41,8 @@ type Server interface {
 	RemoveAuthorizedKey(peer string)
 	// AddAuthorizedKey add a given peer key to server authorized keys
 	AddAuthorizedKey(peer, newKey string) error
+	// SetJumpPolicy allows the peers to jump to the hosts of the routed networks, nil disables the jumps
+	SetJumpPolicy(policy JumpPolicy)
 }
 
 // DefaultServer is the embedded NetBird SSH server
@@ -51,6 +53,8 @@ type DefaultServer struct {
 	mu             sync.Mutex
 	hostKeyPEM     []byte
 	sessions       []ssh.Session
+	// jumpPolicy authorizes the jumps to the hosts of the routed networks, they're denied if nil
+	jumpPolicy JumpPolicy
 }
 
 // newDefaultServer creates new server with provided host key
@@ -107,8 +111,11 @@ func (srv *DefaultServer) publicKeyHandler(ctx ssh.Context, key ssh.PublicKey) b
 	srv.mu.Lock()
 	defer srv.mu.Unlock()
 
-	for _, allowed := range srv.authorizedKeys {
+	for peer, allowed := range srv.authorizedKeys {
 		if ssh.KeysEqual(allowed, key) {
+			if ctx != nil {
+				ctx.SetValue(peerKeyContextKey, peer)
+			}
 			return true
 		}
 	}
@@ -253,7 +260,14 @@ func (srv *DefaultServer) Start() error {
 
 	publicKeyOption := ssh.PublicKeyAuth(srv.publicKeyHandler)
 	hostKeyPEM := ssh.HostKeyPEM(srv.hostKeyPEM)
-	err := ssh.Serve(srv.listener, srv.sessionHandler, publicKeyOption, hostKeyPEM)
+	jumpOption := func(server *ssh.Server) error {
+		server.ChannelHandlers = map[string]ssh.ChannelHandler{
+			"session":      ssh.DefaultSessionHandler,
+			"direct-tcpip": srv.jumpHandler,
+		}
+		return nil
+	}
+	err := ssh.Serve(srv.listener, srv.sessionHandler, publicKeyOption, hostKeyPEM, jumpOption)
 	if err != nil {
 		return err
 	}
@@ -9,6 +9,7 @@ type MockServer struct {
 	StartFunc               func() error
 	AddAuthorizedKeyFunc    func(peer, newKey string) error
 	RemoveAuthorizedKeyFunc func(peer string)
+	SetJumpPolicyFunc       func(policy JumpPolicy)
 }
 
 // RemoveAuthorizedKey removes SSH key of a given peer from the authorized keys
@@ -27,6 +28,14 @@ func (srv *MockServer) AddAuthorizedKey(peer, newKey string) error {
 	return srv.AddAuthorizedKeyFunc(peer, newKey)
 }
 
+// SetJumpPolicy allows the peers to jump to the hosts of the routed networks
+func (srv *MockServer) SetJumpPolicy(policy JumpPolicy) {
+	if srv.SetJumpPolicyFunc == nil {
+		return
+	}
+	srv.SetJumpPolicyFunc(policy)
+}
+
 // Stop stops SSH server.
 func (srv *MockServer) Stop() error {
 	if srv.StopFunc == nil {
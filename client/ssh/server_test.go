@@ -1,11 +1,18 @@
 package ssh
 
 import (
+	"errors"
 	"fmt"
-	"github.com/stretchr/testify/assert"
-	"golang.org/x/crypto/ssh"
+	"io"
+	"net"
+	"net/netip"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
 )
 
 func TestServer_AddAuthorizedKey(t *testing.T) {
@@ -119,3 +126,82 @@ func TestServer_PubKeyHandler(t *testing.T) {
 	}
 
 }
+
+type fakeJumpPolicy struct {
+	allowed netip.AddrPort
+	audits  chan JumpAudit
+}
+
+func (p *fakeJumpPolicy) Authorize(peer string, _ netip.Addr, dst netip.AddrPort) error {
+	if peer != "remotePeer" || dst != p.allowed {
+		return errors.New("not allowed")
+	}
+	return nil
+}
+
+func (p *fakeJumpPolicy) Audit(audit JumpAudit) {
+	p.audits <- audit
+}
+
+func TestServer_Jump(t *testing.T) {
+	key, err := GeneratePrivateKey(ED25519)
+	require.NoError(t, err)
+	server, err := newDefaultServer(key, "127.0.0.1:")
+	require.NoError(t, err)
+
+	remotePrivKey, err := GeneratePrivateKey(ED25519)
+	require.NoError(t, err)
+	remotePubKey, err := GeneratePublicKey(remotePrivKey)
+	require.NoError(t, err)
+	require.NoError(t, server.AddAuthorizedKey("remotePeer", string(remotePubKey)))
+
+	// the host of the routed network echoes the data back
+	target, err := net.Listen("tcp", "127.0.0.1:")
+	require.NoError(t, err)
+	defer target.Close()
+	go func() {
+		conn, err := target.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = io.Copy(conn, conn)
+	}()
+
+	targetAddr := netip.MustParseAddrPort(target.Addr().String())
+	policy := &fakeJumpPolicy{allowed: targetAddr, audits: make(chan JumpAudit, 1)}
+	server.SetJumpPolicy(policy)
+
+	go func() {
+		_ = server.Start()
+	}()
+	defer func() {
+		_ = server.Stop()
+	}()
+
+	client, err := DialWithKey(server.listener.Addr().String(), "root", remotePrivKey)
+	require.NoError(t, err)
+	defer client.Close()
+
+	_, err = client.client.Dial("tcp", "127.0.0.1:1")
+	assert.Error(t, err, "the jump to a host the policy doesn't allow should be rejected")
+
+	conn, err := client.client.Dial("tcp", targetAddr.String())
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("ping"))
+	require.NoError(t, err)
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(conn, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(buf))
+	require.NoError(t, conn.Close())
+
+	select {
+	case audit := <-policy.audits:
+		assert.Equal(t, "remotePeer", audit.Peer)
+		assert.Equal(t, targetAddr, audit.Dest)
+		assert.Equal(t, int64(4), audit.BytesOut)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the jump should be audited")
+	}
+}
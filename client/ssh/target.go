@@ -0,0 +1,139 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"golang.org/x/term"
+)
+
+// defaultIdentityFiles are the private keys in ~/.ssh tried on the hosts of the routed networks, like OpenSSH does
+var defaultIdentityFiles = []string{"id_ed25519", "id_ecdsa", "id_rsa"}
+
+// TargetClientConfig returns the config to log in to a host of a routed network, which doesn't know the NetBird key.
+// It authenticates with the SSH agent, the identity file, the default keys and the password in this order, the host
+// key is checked against the known hosts file and added to it on the first connection.
+func TargetClientConfig(user, identityFile, knownHostsFile string) (*ssh.ClientConfig, error) {
+	var signers []ssh.Signer
+
+	if identityFile != "" {
+		signer, err := loadSigner(identityFile)
+		if err != nil {
+			return nil, fmt.Errorf("load identity file: %w", err)
+		}
+		signers = append(signers, signer)
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		for _, name := range defaultIdentityFiles {
+			// the encrypted and the missing keys are skipped, the agent holds the encrypted ones
+			if signer, err := loadSigner(filepath.Join(home, ".ssh", name)); err == nil {
+				signers = append(signers, signer)
+			}
+		}
+	}
+
+	var auth []ssh.AuthMethod
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		if conn, err := net.Dial("unix", sock); err == nil {
+			auth = append(auth, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+		}
+	}
+	if len(signers) > 0 {
+		auth = append(auth, ssh.PublicKeys(signers...))
+	}
+	auth = append(auth, ssh.PasswordCallback(func() (string, error) {
+		return readPassword(fmt.Sprintf("%s's password: ", user))
+	}))
+
+	return &ssh.ClientConfig{
+		User:            user,
+		Timeout:         10 * time.Second,
+		Auth:            auth,
+		HostKeyCallback: knownHostsCallback(knownHostsFile),
+	}, nil
+}
+
+// DefaultKnownHostsFile returns the known hosts file of OpenSSH of the user
+func DefaultKnownHostsFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".ssh", "known_hosts")
+}
+
+func loadSigner(path string) (ssh.Signer, error) {
+	key, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ssh.ParsePrivateKey(key)
+}
+
+func readPassword(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", errors.New("password authentication needs a terminal")
+	}
+	_, _ = fmt.Fprint(os.Stderr, prompt)
+	password, err := term.ReadPassword(fd)
+	_, _ = fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return string(password), nil
+}
+
+// knownHostsCallback rejects a host whose key doesn't match the known one and trusts the key of an unknown host on
+// the first connection, like StrictHostKeyChecking=accept-new of OpenSSH
+func knownHostsCallback(path string) ssh.HostKeyCallback {
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		if path == "" {
+			return errors.New("no known hosts file to check the host key")
+		}
+
+		check, err := knownhosts.New(path)
+		switch {
+		case err == nil:
+			err = check(hostname, remote, key)
+			var keyErr *knownhosts.KeyError
+			if err == nil {
+				return nil
+			}
+			if !errors.As(err, &keyErr) || len(keyErr.Want) > 0 {
+				return fmt.Errorf("host key of %s: %w", hostname, err)
+			}
+		case !errors.Is(err, os.ErrNotExist):
+			return fmt.Errorf("read known hosts: %w", err)
+		}
+
+		if err := addKnownHost(path, hostname, key); err != nil {
+			return fmt.Errorf("add %s to the known hosts: %w", hostname, err)
+		}
+		_, _ = fmt.Fprintf(os.Stderr, "Warning: Permanently added %s (%s) to the list of known hosts.\n", hostname, ssh.FingerprintSHA256(key))
+		return nil
+	}
+}
+
+func addKnownHost(path, hostname string, key ssh.PublicKey) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(f, knownhosts.Line([]string{knownhosts.Normalize(hostname)}, key)); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -17,6 +17,7 @@ type SystemEventOutput struct {
 	UserMessage string            `json:"userMessage" yaml:"userMessage"`
 	Timestamp   time.Time         `json:"timestamp" yaml:"timestamp"`
 	Metadata    map[string]string `json:"metadata" yaml:"metadata"`
+	ErrorCode   string            `json:"errorCode,omitempty" yaml:"errorCode,omitempty"`
 }
 
 func mapEvents(protoEvents []*proto.SystemEvent) []SystemEventOutput {
@@ -30,6 +31,7 @@ func mapEvents(protoEvents []*proto.SystemEvent) []SystemEventOutput {
 			UserMessage: event.GetUserMessage(),
 			Timestamp:   event.GetTimestamp().AsTime(),
 			Metadata:    event.GetMetadata(),
+			ErrorCode:   event.GetErrorCode(),
 		}
 	}
 	return events
@@ -54,13 +56,19 @@ func parseEvents(events []SystemEventOutput) string {
 			metadataStr = fmt.Sprintf("\n    Metadata: %s", strings.Join(pairs, ", "))
 		}
 
+		errorCodeStr := ""
+		if event.ErrorCode != "" {
+			errorCodeStr = fmt.Sprintf("\n    Error code: %s", event.ErrorCode)
+		}
+
 		eventsString.WriteString(fmt.Sprintf("\n  [%s] %s (%s)"+
-			"\n    Message: %s"+
+			"\n    Message: %s%s"+
 			"\n    Time: %s%s",
 			event.Severity,
 			event.Category,
 			event.ID,
 			event.Message,
+			errorCodeStr,
 			timeStr,
 			metadataStr,
 		))
@@ -16,6 +16,7 @@ import (
 	"github.com/netbirdio/netbird/client/anonymize"
 	"github.com/netbirdio/netbird/client/internal/peer"
 	"github.com/netbirdio/netbird/client/proto"
+	"github.com/netbirdio/netbird/util/pqtls"
 	"github.com/netbirdio/netbird/version"
 )
 
@@ -35,6 +36,15 @@ type PeerStateDetailOutput struct {
 	Latency                time.Duration    `json:"latency" yaml:"latency"`
 	RosenpassEnabled       bool             `json:"quantumResistance" yaml:"quantumResistance"`
 	Networks               []string         `json:"networks" yaml:"networks"`
+	Health                 PeerHealthOutput `json:"health" yaml:"health"`
+}
+
+// PeerHealthOutput is the health score of a connected peer, the grade is empty for the other peers
+type PeerHealthOutput struct {
+	Score     int           `json:"score" yaml:"score"`
+	Grade     string        `json:"grade" yaml:"grade"`
+	ProbeRTT  time.Duration `json:"probeRtt" yaml:"probeRtt"`
+	ProbeLoss float64       `json:"probeLoss" yaml:"probeLoss"`
 }
 
 type PeersStateOutput struct {
@@ -44,21 +54,24 @@ type PeersStateOutput struct {
 }
 
 type SignalStateOutput struct {
-	URL       string `json:"url" yaml:"url"`
-	Connected bool   `json:"connected" yaml:"connected"`
-	Error     string `json:"error" yaml:"error"`
+	URL         string `json:"url" yaml:"url"`
+	Connected   bool   `json:"connected" yaml:"connected"`
+	Error       string `json:"error" yaml:"error"`
+	KeyExchange string `json:"keyExchange" yaml:"keyExchange"`
 }
 
 type ManagementStateOutput struct {
-	URL       string `json:"url" yaml:"url"`
-	Connected bool   `json:"connected" yaml:"connected"`
-	Error     string `json:"error" yaml:"error"`
+	URL         string `json:"url" yaml:"url"`
+	Connected   bool   `json:"connected" yaml:"connected"`
+	Error       string `json:"error" yaml:"error"`
+	KeyExchange string `json:"keyExchange" yaml:"keyExchange"`
 }
 
 type RelayStateOutputDetail struct {
-	URI       string `json:"uri" yaml:"uri"`
-	Available bool   `json:"available" yaml:"available"`
-	Error     string `json:"error" yaml:"error"`
+	URI         string `json:"uri" yaml:"uri"`
+	Available   bool   `json:"available" yaml:"available"`
+	Error       string `json:"error" yaml:"error"`
+	KeyExchange string `json:"keyExchange" yaml:"keyExchange"`
 }
 
 type RelayStateOutput struct {
@@ -79,6 +92,13 @@ type NsServerGroupStateOutput struct {
 	Error   string   `json:"error" yaml:"error"`
 }
 
+// ExitNodeOutput is the exit node chosen by the automatic exit node selection
+type ExitNodeOutput struct {
+	Network string        `json:"network" yaml:"network"`
+	Latency time.Duration `json:"latency" yaml:"latency"`
+	Loss    float32       `json:"loss" yaml:"loss"`
+}
+
 type OutputOverview struct {
 	Peers                   PeersStateOutput           `json:"peers" yaml:"peers"`
 	CliVersion              string                     `json:"cliVersion" yaml:"cliVersion"`
@@ -94,8 +114,11 @@ type OutputOverview struct {
 	RosenpassPermissive     bool                       `json:"quantumResistancePermissive" yaml:"quantumResistancePermissive"`
 	Networks                []string                   `json:"networks" yaml:"networks"`
 	NumberOfForwardingRules int                        `json:"forwardingRules" yaml:"forwardingRules"`
+	ExitNode                *ExitNodeOutput            `json:"exitNode,omitempty" yaml:"exitNode,omitempty"`
 	NSServerGroups          []NsServerGroupStateOutput `json:"dnsServers" yaml:"dnsServers"`
 	Events                  []SystemEventOutput        `json:"events" yaml:"events"`
+	// Colors highlights the health of the peers in the text output for a terminal
+	Colors bool `json:"-" yaml:"-"`
 }
 
 func ConvertToStatusOutputOverview(resp *proto.StatusResponse, anon bool, statusFilter string, prefixNamesFilter []string, prefixNamesFilterMap map[string]struct{}, ipsFilter map[string]struct{}) OutputOverview {
@@ -103,16 +126,18 @@ func ConvertToStatusOutputOverview(resp *proto.StatusResponse, anon bool, status
 
 	managementState := pbFullStatus.GetManagementState()
 	managementOverview := ManagementStateOutput{
-		URL:       managementState.GetURL(),
-		Connected: managementState.GetConnected(),
-		Error:     managementState.Error,
+		URL:         managementState.GetURL(),
+		Connected:   managementState.GetConnected(),
+		Error:       managementState.Error,
+		KeyExchange: managementState.GetKeyExchange(),
 	}
 
 	signalState := pbFullStatus.GetSignalState()
 	signalOverview := SignalStateOutput{
-		URL:       signalState.GetURL(),
-		Connected: signalState.GetConnected(),
-		Error:     signalState.Error,
+		URL:         signalState.GetURL(),
+		Connected:   signalState.GetConnected(),
+		Error:       signalState.Error,
+		KeyExchange: signalState.GetKeyExchange(),
 	}
 
 	relayOverview := mapRelays(pbFullStatus.GetRelays())
@@ -137,6 +162,14 @@ func ConvertToStatusOutputOverview(resp *proto.StatusResponse, anon bool, status
 		Events:                  mapEvents(pbFullStatus.GetEvents()),
 	}
 
+	if exitNode := pbFullStatus.GetExitNode(); exitNode.GetAuto() {
+		overview.ExitNode = &ExitNodeOutput{
+			Network: exitNode.GetNetworkID(),
+			Latency: exitNode.GetLatency().AsDuration(),
+			Loss:    exitNode.GetLoss(),
+		}
+	}
+
 	if anon {
 		anonymizer := anonymize.NewAnonymizer(anonymize.DefaultAddresses())
 		anonymizeOverview(anonymizer, &overview)
@@ -153,9 +186,10 @@ func mapRelays(relays []*proto.RelayState) RelayStateOutput {
 		available := relay.GetAvailable()
 		relayStateDetail = append(relayStateDetail,
 			RelayStateOutputDetail{
-				URI:       relay.URI,
-				Available: available,
-				Error:     relay.GetError(),
+				URI:         relay.URI,
+				Available:   available,
+				Error:       relay.GetError(),
+				KeyExchange: relay.GetKeyExchange(),
 			},
 		)
 
@@ -248,6 +282,12 @@ func mapPeers(
 			Latency:                pbPeerState.GetLatency().AsDuration(),
 			RosenpassEnabled:       pbPeerState.GetRosenpassEnabled(),
 			Networks:               pbPeerState.GetNetworks(),
+			Health: PeerHealthOutput{
+				Score:     int(pbPeerState.GetHealthScore()),
+				Grade:     pbPeerState.GetHealthGrade(),
+				ProbeRTT:  pbPeerState.GetProbeRtt().AsDuration(),
+				ProbeLoss: float64(pbPeerState.GetProbeLoss()),
+			},
 		}
 
 		peersStateDetail = append(peersStateDetail, peerState)
@@ -289,12 +329,24 @@ func ParseToYAML(overview OutputOverview) (string, error) {
 	return string(yamlBytes), nil
 }
 
+// keyExchangeString describes the TLS key exchange negotiated with a server, empty if it isn't known
+func keyExchangeString(keyExchange string) string {
+	if keyExchange == "" {
+		return ""
+	}
+	if pqtls.IsPostQuantum(keyExchange) {
+		return fmt.Sprintf(" (TLS key exchange: %s, post-quantum)", keyExchange)
+	}
+	return fmt.Sprintf(" (TLS key exchange: %s)", keyExchange)
+}
+
 func ParseGeneralSummary(overview OutputOverview, showURL bool, showRelays bool, showNameServers bool) string {
 	var managementConnString string
 	if overview.ManagementState.Connected {
 		managementConnString = "Connected"
 		if showURL {
-			managementConnString = fmt.Sprintf("%s to %s", managementConnString, overview.ManagementState.URL)
+			managementConnString = fmt.Sprintf("%s to %s%s", managementConnString, overview.ManagementState.URL,
+				keyExchangeString(overview.ManagementState.KeyExchange))
 		}
 	} else {
 		managementConnString = "Disconnected"
@@ -307,7 +359,8 @@ func ParseGeneralSummary(overview OutputOverview, showURL bool, showRelays bool,
 	if overview.SignalState.Connected {
 		signalConnString = "Connected"
 		if showURL {
-			signalConnString = fmt.Sprintf("%s to %s", signalConnString, overview.SignalState.URL)
+			signalConnString = fmt.Sprintf("%s to %s%s", signalConnString, overview.SignalState.URL,
+				keyExchangeString(overview.SignalState.KeyExchange))
 		}
 	} else {
 		signalConnString = "Disconnected"
@@ -334,7 +387,7 @@ func ParseGeneralSummary(overview OutputOverview, showURL bool, showRelays bool,
 				available = "Unavailable"
 				reason = fmt.Sprintf(", reason: %s", relay.Error)
 			}
-			relaysString += fmt.Sprintf("\n  [%s] is %s%s", relay.URI, available, reason)
+			relaysString += fmt.Sprintf("\n  [%s] is %s%s%s", relay.URI, available, reason, keyExchangeString(relay.KeyExchange))
 		}
 	} else {
 		relaysString = fmt.Sprintf("%d/%d Available", overview.Relays.Available, overview.Relays.Total)
@@ -384,6 +437,13 @@ func ParseGeneralSummary(overview OutputOverview, showURL bool, showRelays bool,
 	}
 
 	peersCountString := fmt.Sprintf("%d/%d Connected", overview.Peers.Connected, overview.Peers.Total)
+	peersCountString += unhealthyPeersString(overview.Peers, overview.Colors)
+
+	var exitNodeString string
+	if exitNode := overview.ExitNode; exitNode != nil {
+		exitNodeString = fmt.Sprintf("Exit node: auto, %s (latency %s, loss %.0f%%)\n", exitNode.Network,
+			exitNode.Latency.Round(time.Millisecond), exitNode.Loss*100)
+	}
 
 	goos := runtime.GOOS
 	goarch := runtime.GOARCH
@@ -406,6 +466,7 @@ func ParseGeneralSummary(overview OutputOverview, showURL bool, showRelays bool,
 			"Quantum resistance: %s\n"+
 			"Networks: %s\n"+
 			"Forwarding rules: %d\n"+
+			"%s"+
 			"Peers count: %s\n",
 		fmt.Sprintf("%s/%s%s", goos, goarch, goarm),
 		overview.DaemonVersion,
@@ -420,13 +481,14 @@ func ParseGeneralSummary(overview OutputOverview, showURL bool, showRelays bool,
 		rosenpassEnabledStatus,
 		networks,
 		overview.NumberOfForwardingRules,
+		exitNodeString,
 		peersCountString,
 	)
 	return summary
 }
 
 func ParseToFullDetailSummary(overview OutputOverview) string {
-	parsedPeersString := parsePeers(overview.Peers, overview.RosenpassEnabled, overview.RosenpassPermissive)
+	parsedPeersString := parsePeers(overview.Peers, overview.RosenpassEnabled, overview.RosenpassPermissive, overview.Colors)
 	parsedEventsString := parseEvents(overview.Events)
 	summary := ParseGeneralSummary(overview, true, true, true)
 
@@ -442,7 +504,7 @@ func ParseToFullDetailSummary(overview OutputOverview) string {
 	)
 }
 
-func parsePeers(peers PeersStateOutput, rosenpassEnabled, rosenpassPermissive bool) string {
+func parsePeers(peers PeersStateOutput, rosenpassEnabled, rosenpassPermissive, colors bool) string {
 	var (
 		peersString = ""
 	)
@@ -507,7 +569,8 @@ func parsePeers(peers PeersStateOutput, rosenpassEnabled, rosenpassPermissive bo
 				"  Transfer status (received/sent) %s/%s\n"+
 				"  Quantum resistance: %s\n"+
 				"  Networks: %s\n"+
-				"  Latency: %s\n",
+				"  Latency: %s\n"+
+				"  Health: %s\n",
 			peerState.FQDN,
 			peerState.IP,
 			peerState.PubKey,
@@ -525,6 +588,7 @@ func parsePeers(peers PeersStateOutput, rosenpassEnabled, rosenpassPermissive bo
 			rosenpassEnabledStatus,
 			networks,
 			peerState.Latency.String(),
+			healthString(peerState.Health, colors),
 		)
 
 		peersString += peerString
@@ -532,6 +596,63 @@ func parsePeers(peers PeersStateOutput, rosenpassEnabled, rosenpassPermissive bo
 	return peersString
 }
 
+// healthString returns the score and the grade of the peer with the probe measurements, in the color of the grade for a
+// terminal
+func healthString(health PeerHealthOutput, colors bool) string {
+	if health.Grade == "" {
+		return "-"
+	}
+
+	grade := colorize(health.Grade, health.Grade, colors)
+	if health.ProbeRTT == 0 && health.ProbeLoss == 0 {
+		return fmt.Sprintf("%d/100 (%s)", health.Score, grade)
+	}
+	return fmt.Sprintf("%d/100 (%s), probe RTT %s, loss %.0f%%", health.Score, grade,
+		health.ProbeRTT.Round(time.Millisecond), health.ProbeLoss*100)
+}
+
+// unhealthyPeersString counts the degraded and the poor connected peers for the summary
+func unhealthyPeersString(peers PeersStateOutput, colors bool) string {
+	var degraded, poor int
+	for _, p := range peers.Details {
+		switch p.Health.Grade {
+		case string(peer.HealthDegraded):
+			degraded++
+		case string(peer.HealthPoor):
+			poor++
+		}
+	}
+
+	var s string
+	if degraded > 0 {
+		s += ", " + colorize(fmt.Sprintf("%d degraded", degraded), string(peer.HealthDegraded), colors)
+	}
+	if poor > 0 {
+		s += ", " + colorize(fmt.Sprintf("%d poor", poor), string(peer.HealthPoor), colors)
+	}
+	return s
+}
+
+// colorize wraps the text in the ANSI color of the health grade
+func colorize(text, grade string, colors bool) string {
+	if !colors {
+		return text
+	}
+
+	var code string
+	switch grade {
+	case string(peer.HealthGood):
+		code = "32"
+	case string(peer.HealthDegraded):
+		code = "33"
+	case string(peer.HealthPoor):
+		code = "31"
+	default:
+		return text
+	}
+	return "\033[" + code + "m" + text + "\033[0m"
+}
+
 func skipDetailByFilters(
 	peerState *proto.PeerState,
 	isConnected bool,
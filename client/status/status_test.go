@@ -64,17 +64,23 @@ var resp = &proto.StatusResponse{
 				BytesRx:                    2000,
 				BytesTx:                    1000,
 				Latency:                    durationpb.New(time.Duration(10000000)),
+				HealthScore:                62,
+				HealthGrade:                "degraded",
+				ProbeRtt:                   durationpb.New(120 * time.Millisecond),
+				ProbeLoss:                  0.25,
 			},
 		},
 		ManagementState: &proto.ManagementState{
-			URL:       "my-awesome-management.com:443",
-			Connected: true,
-			Error:     "",
+			URL:         "my-awesome-management.com:443",
+			Connected:   true,
+			Error:       "",
+			KeyExchange: "X25519MLKEM768",
 		},
 		SignalState: &proto.SignalState{
-			URL:       "my-awesome-signal.com:443",
-			Connected: true,
-			Error:     "",
+			URL:         "my-awesome-signal.com:443",
+			Connected:   true,
+			Error:       "",
+			KeyExchange: "X25519",
 		},
 		Relays: []*proto.RelayState{
 			{
@@ -170,6 +176,12 @@ var overview = OutputOverview{
 				TransferReceived:       2000,
 				TransferSent:           1000,
 				Latency:                time.Duration(10000000),
+				Health: PeerHealthOutput{
+					Score:     62,
+					Grade:     "degraded",
+					ProbeRTT:  120 * time.Millisecond,
+					ProbeLoss: 0.25,
+				},
 			},
 		},
 	},
@@ -177,14 +189,16 @@ var overview = OutputOverview{
 	CliVersion:    version.NetbirdVersion(),
 	DaemonVersion: "0.14.1",
 	ManagementState: ManagementStateOutput{
-		URL:       "my-awesome-management.com:443",
-		Connected: true,
-		Error:     "",
+		URL:         "my-awesome-management.com:443",
+		Connected:   true,
+		Error:       "",
+		KeyExchange: "X25519MLKEM768",
 	},
 	SignalState: SignalStateOutput{
-		URL:       "my-awesome-signal.com:443",
-		Connected: true,
-		Error:     "",
+		URL:         "my-awesome-signal.com:443",
+		Connected:   true,
+		Error:       "",
+		KeyExchange: "X25519",
 	},
 	Relays: RelayStateOutput{
 		Total:     2,
@@ -296,7 +310,13 @@ func TestParsingToJSON(t *testing.T) {
                 "quantumResistance": false,
                 "networks": [
                   "10.1.0.0/24"
-                ]
+                ],
+                "health": {
+                  "score": 0,
+                  "grade": "",
+                  "probeRtt": 0,
+                  "probeLoss": 0
+                }
               },
               {
                 "fqdn": "peer-2.awesome-domain.com",
@@ -319,7 +339,13 @@ func TestParsingToJSON(t *testing.T) {
                 "transferSent": 1000,
 				"latency": 10000000,
                 "quantumResistance": false,
-                "networks": null
+                "networks": null,
+                "health": {
+                  "score": 62,
+                  "grade": "degraded",
+                  "probeRtt": 120000000,
+                  "probeLoss": 0.25
+                }
               }
             ]
           },
@@ -328,12 +354,14 @@ func TestParsingToJSON(t *testing.T) {
           "management": {
             "url": "my-awesome-management.com:443",
             "connected": true,
-            "error": ""
+            "error": "",
+            "keyExchange": "X25519MLKEM768"
           },
           "signal": {
             "url": "my-awesome-signal.com:443",
             "connected": true,
-            "error": ""
+            "error": "",
+            "keyExchange": "X25519"
           },
           "relays": {
             "total": 2,
@@ -342,12 +370,14 @@ func TestParsingToJSON(t *testing.T) {
               {
                 "uri": "stun:my-awesome-stun.com:3478",
                 "available": true,
-                "error": ""
+                "error": "",
+                "keyExchange": ""
               },
               {
                 "uri": "turns:my-awesome-turn.com:443?transport=tcp",
                 "available": false,
-                "error": "context: deadline exceeded"
+                "error": "context: deadline exceeded",
+                "keyExchange": ""
               }
             ]
           },
@@ -421,6 +451,11 @@ func TestParsingToYAML(t *testing.T) {
           quantumResistance: false
           networks:
             - 10.1.0.0/24
+          health:
+            score: 0
+            grade: ""
+            probeRtt: 0s
+            probeLoss: 0
         - fqdn: peer-2.awesome-domain.com
           netbirdIp: 192.168.178.102
           publicKey: Pubkey2
@@ -440,16 +475,23 @@ func TestParsingToYAML(t *testing.T) {
           latency: 10ms
           quantumResistance: false
           networks: []
+          health:
+            score: 62
+            grade: degraded
+            probeRtt: 120ms
+            probeLoss: 0.25
 cliVersion: development
 daemonVersion: 0.14.1
 management:
     url: my-awesome-management.com:443
     connected: true
     error: ""
+    keyExchange: X25519MLKEM768
 signal:
     url: my-awesome-signal.com:443
     connected: true
     error: ""
+    keyExchange: X25519
 relays:
     total: 2
     available: 1
@@ -457,9 +499,11 @@ relays:
         - uri: stun:my-awesome-stun.com:3478
           available: true
           error: ""
+          keyExchange: ""
         - uri: turns:my-awesome-turn.com:443?transport=tcp
           available: false
           error: 'context: deadline exceeded'
+          keyExchange: ""
 netbirdIp: 192.168.178.100/16
 publicKey: Some-Pub-Key
 usesKernelInterface: true
@@ -515,6 +559,7 @@ func TestParsingToDetail(t *testing.T) {
   Quantum resistance: false
   Networks: 10.1.0.0/24
   Latency: 10ms
+  Health: -
 
  peer-2.awesome-domain.com:
   NetBird IP: 192.168.178.102
@@ -531,13 +576,14 @@ func TestParsingToDetail(t *testing.T) {
   Quantum resistance: false
   Networks: -
   Latency: 10ms
+  Health: 62/100 (degraded), probe RTT 120ms, loss 25%%
 
 Events: No events recorded
 OS: %s/%s
 Daemon version: 0.14.1
 CLI version: %s
-Management: Connected to my-awesome-management.com:443
-Signal: Connected to my-awesome-signal.com:443
+Management: Connected to my-awesome-management.com:443 (TLS key exchange: X25519MLKEM768, post-quantum)
+Signal: Connected to my-awesome-signal.com:443 (TLS key exchange: X25519)
 Relays: 
   [stun:my-awesome-stun.com:3478] is Available
   [turns:my-awesome-turn.com:443?transport=tcp] is Unavailable, reason: context: deadline exceeded
@@ -550,7 +596,7 @@ Interface type: Kernel
 Quantum resistance: false
 Networks: 10.10.0.0/24
 Forwarding rules: 0
-Peers count: 2/2 Connected
+Peers count: 2/2 Connected, 1 degraded
 `, lastConnectionUpdate1, lastHandshake1, lastConnectionUpdate2, lastHandshake2, runtime.GOOS, runtime.GOARCH, overview.CliVersion)
 
 	assert.Equal(t, expectedDetail, detail)
@@ -572,7 +618,7 @@ Interface type: Kernel
 Quantum resistance: false
 Networks: 10.10.0.0/24
 Forwarding rules: 0
-Peers count: 2/2 Connected
+Peers count: 2/2 Connected, 1 degraded
 `
 
 	assert.Equal(t, expectedString, shortVersion)
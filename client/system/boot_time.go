@@ -0,0 +1,20 @@
+//go:build windows || (linux && !android) || (darwin && !ios) || freebsd
+
+package system
+
+import (
+	"time"
+
+	"github.com/shirou/gopsutil/v3/host"
+	log "github.com/sirupsen/logrus"
+)
+
+// bootTime returns the time the system booted at, zero if it can't be read
+func bootTime() time.Time {
+	seconds, err := host.BootTime()
+	if err != nil {
+		log.Warnf("failed to get the boot time: %v", err)
+		return time.Time{}
+	}
+	return time.Unix(int64(seconds), 0).UTC()
+}
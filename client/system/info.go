@@ -5,6 +5,7 @@ import (
 	"net"
 	"net/netip"
 	"strings"
+	"time"
 
 	"google.golang.org/grpc/metadata"
 
@@ -59,6 +60,9 @@ type Info struct {
 	Environment        Environment
 	Files              []File // for posture checks
 
+	// BootTime is the time the system booted at, the uptime is derived from it. Zero if unknown.
+	BootTime time.Time
+
 	RosenpassEnabled    bool
 	RosenpassPermissive bool
 	ServerSSHAllowed    bool
@@ -66,6 +70,11 @@ type Info struct {
 	DisableServerRoutes bool
 	DisableDNS          bool
 	DisableFirewall     bool
+
+	// RouterMode is set when the peer runs as a gateway appliance, it advertises the AdvertisedRoutes LANs.
+	// AdvertisedRoutes also holds the routes learned from the routers of the site.
+	RouterMode       bool
+	AdvertisedRoutes []string
 }
 
 func (i *Info) SetFlags(
@@ -85,6 +94,15 @@ func (i *Info) SetFlags(
 	i.DisableFirewall = disableFirewall
 }
 
+// SetRouterMode marks the peer as a gateway appliance advertising the given LAN prefixes
+func (i *Info) SetRouterMode(enabled bool, lans []string) {
+	i.RouterMode = enabled
+	i.AdvertisedRoutes = nil
+	if enabled {
+		i.AdvertisedRoutes = lans
+	}
+}
+
 // StaticInfo is an object that contains machine information that does not change
 type StaticInfo struct {
 	SystemSerialNumber string
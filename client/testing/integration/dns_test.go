@@ -0,0 +1,90 @@
+//go:build integration && linux
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/client/testing/topology"
+	nbdns "github.com/netbirdio/netbird/dns"
+)
+
+// resolve queries the A record of the name from the host of the peer
+func (p *testPeer) resolve(nameserver netip.Addr, name string) (netip.Addr, error) {
+	var addr netip.Addr
+	err := p.Do(func() error {
+		msg := new(dns.Msg)
+		msg.SetQuestion(dns.Fqdn(name), dns.TypeA)
+
+		client := &dns.Client{Timeout: 2 * time.Second}
+		resp, _, err := client.Exchange(msg, netip.AddrPortFrom(nameserver, 53).String())
+		if err != nil {
+			return fmt.Errorf("query %s: %w", name, err)
+		}
+		for _, rr := range resp.Answer {
+			if a, ok := rr.(*dns.A); ok {
+				addr, _ = netip.AddrFromSlice(a.A.To4())
+				return nil
+			}
+		}
+		return fmt.Errorf("no A record for %s: %s", name, dns.RcodeToString[resp.Rcode])
+	})
+	return addr, err
+}
+
+// startNameserver starts a nameserver in the test namespace answering the queries with no records, the peers
+// deactivate the nameserver groups they can't reach
+func startNameserver(t *testing.T) netip.AddrPort {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp4", netip.AddrPortFrom(topology.ServicesIP, 0).String())
+	require.NoError(t, err)
+
+	s := &dns.Server{
+		PacketConn: conn,
+		Handler: dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+			_ = w.WriteMsg(new(dns.Msg).SetReply(r))
+		}),
+	}
+	go func() {
+		_ = s.ActivateAndServe()
+	}()
+	t.Cleanup(func() {
+		_ = s.Shutdown()
+	})
+
+	return conn.LocalAddr().(*net.UDPAddr).AddrPort()
+}
+
+func TestDNS(t *testing.T) {
+	n := newNetwork(t)
+
+	// the peers manage the resolv.conf with a primary nameserver group only
+	upstream := startNameserver(t)
+	_, err := n.accountManager.CreateNameServerGroup(context.Background(), n.accountID, "primary", "",
+		[]nbdns.NameServer{{IP: upstream.Addr(), NSType: nbdns.UDPNameServerType, Port: int(upstream.Port())}},
+		[]string{n.allGroupID(t)}, true, nil, true, testUserID, false, "", "")
+	require.NoError(t, err)
+
+	peer1 := n.startPeer(t, behind(topology.NATEndpointIndependent)(t, n, "site1"))
+	peer2 := n.startPeer(t, public(t, n, "public"))
+
+	require.EventuallyWithT(t, func(c *assert.CollectT) {
+		nameserver, err := peer1.nameserver()
+		require.NoError(c, err)
+		require.NotEqual(c, topology.ServicesIP, nameserver, "the peer should point the resolv.conf to its resolver")
+
+		addr, err := peer1.resolve(nameserver, peer2.FQDN)
+		require.NoError(c, err)
+		assert.Equal(c, peer2.IP, addr)
+	}, connectTimeout, time.Second, "%s should resolve %s", peer1.Hostname, peer2.FQDN)
+}
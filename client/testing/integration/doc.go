@@ -0,0 +1,20 @@
+// Package integration tests the client end to end in simulated networks: ICE through NATs, the fallback to the relay,
+// the failover of the routes and the DNS.
+//
+// Every test builds a topology of network namespaces with the topology package and starts the management, signal,
+// relay and STUN services in the test namespace. Every peer is a copy of the test binary running the daemon in a
+// host of the topology, with a mount namespace of its own for the files it changes in /etc. The tests drive the
+// peers over the API of the daemon, like the CLI.
+//
+// The tests require root and the integration build tag:
+//
+//	go test -tags integration -exec sudo ./client/testing/...
+//
+// A scenario creates the network with newNetwork, places the hosts behind the NATs it needs and starts a peer in
+// each of them with startPeer. The logs of the peers are printed when a test fails.
+//
+// The tests injecting faults into the peers, like killing the relay connections, require the faultinject build tag
+// too:
+//
+//	go test -tags integration,faultinject -exec sudo ./client/testing/...
+package integration
@@ -0,0 +1,83 @@
+//go:build integration && linux && faultinject
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/client/internal/peer"
+	"github.com/netbirdio/netbird/client/proto"
+	"github.com/netbirdio/netbird/client/testing/topology"
+	"github.com/netbirdio/netbird/util/faultinject"
+)
+
+// setFault injects the fault into the peer through the debug API of its daemon
+func (p *testPeer) setFault(t *testing.T, req *proto.SetFaultRequest) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	_, err := p.daemon.SetFault(ctx, req)
+	require.NoError(t, err, "set fault %s on %s", req.GetFault(), p.Hostname)
+}
+
+// waitFaultInjected waits until the fault set with a count was injected as many times and cleared
+func (p *testPeer) waitFaultInjected(t *testing.T, fault faultinject.Fault) {
+	t.Helper()
+
+	require.EventuallyWithT(t, func(c *assert.CollectT) {
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+		defer cancel()
+
+		resp, err := p.daemon.ListFaults(ctx, &proto.ListFaultsRequest{})
+		require.NoError(c, err)
+		require.True(c, resp.GetEnabled(), "the peer should be built with fault injection")
+		for _, state := range resp.GetFaults() {
+			assert.NotEqual(c, string(fault), state.GetFault(), "the fault should be cleared")
+		}
+	}, connectTimeout, time.Second, "%s should inject %s", p.Hostname, fault)
+}
+
+func TestRelayKill(t *testing.T) {
+	n := newNetwork(t)
+	peer1 := n.startPeer(t, behind(topology.NATUDPBlocked)(t, n, "site1"))
+	peer2 := n.startPeer(t, public(t, n, "public"))
+
+	peer1.waitConnected(t, peer2, relayed)
+	peer2.waitConnected(t, peer1, relayed)
+
+	peer1.setFault(t, &proto.SetFaultRequest{Fault: string(faultinject.RelayKill), Count: 1})
+	peer1.waitFaultInjected(t, faultinject.RelayKill)
+
+	// the peers reconnect through the relay once the client is connected to the relay server again
+	peer1.waitConnected(t, peer2, relayed)
+	peer2.waitConnected(t, peer1, relayed)
+	peer1.requireReachable(t, listenTCP(t, peer2.Host, peer2.IP))
+	peer2.requireReachable(t, listenTCP(t, peer1.Host, peer1.IP))
+}
+
+func TestSignalDrop(t *testing.T) {
+	n := newNetwork(t)
+	peer1 := n.startPeer(t, behind(topology.NATEndpointIndependent)(t, n, "site1"))
+
+	// peer1 drops the offers of peer2, they can't connect until the fault is cleared
+	peer1.setFault(t, &proto.SetFaultRequest{Fault: string(faultinject.SignalDrop)})
+	peer2 := n.startPeer(t, public(t, n, "public"))
+
+	require.Never(t, func() bool {
+		state, err := peer2.peerState(peer1)
+		return err == nil && state.GetConnStatus() == peer.StatusConnected.String()
+	}, 15*time.Second, time.Second, "%s shouldn't connect while %s drops the signal messages", peer2.Hostname, peer1.Hostname)
+
+	peer1.setFault(t, &proto.SetFaultRequest{Fault: string(faultinject.SignalDrop), Clear: true})
+
+	peer1.waitConnected(t, peer2, direct)
+	peer2.waitConnected(t, peer1, direct)
+	peer1.requireReachable(t, listenTCP(t, peer2.Host, peer2.IP))
+}
@@ -0,0 +1,131 @@
+//go:build integration && linux
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+	"google.golang.org/grpc"
+
+	"github.com/netbirdio/netbird/client/internal"
+	"github.com/netbirdio/netbird/client/proto"
+	"github.com/netbirdio/netbird/client/server"
+	"github.com/netbirdio/netbird/client/testing/topology"
+	"github.com/netbirdio/netbird/util"
+)
+
+const (
+	// envPeerDir is set by startPeer, the test binary runs a peer in the directory instead of the tests
+	envPeerDir = "NB_INTEGRATION_PEER_DIR"
+
+	daemonSocket = "daemon.sock"
+	peerLogFile  = "netbird.log"
+	configFile   = "config.json"
+
+	resolvConfPath     = "/etc/resolv.conf"
+	wireguardSocketDir = "/var/run/wireguard"
+)
+
+func TestMain(m *testing.M) {
+	if dir := os.Getenv(envPeerDir); dir != "" {
+		os.Exit(runPeer(dir))
+	}
+
+	if os.Geteuid() != 0 {
+		fmt.Println("skipping the integration tests: they create network namespaces and require root")
+		os.Exit(0)
+	}
+
+	_ = util.InitLog("warn", "console")
+	os.Exit(m.Run())
+}
+
+// runPeer serves the API of a daemon on a socket in the peer directory until it's terminated, like netbird service
+// run. It runs in the network namespace of its host and in a mount namespace of its own.
+func runPeer(dir string) int {
+	if err := isolateFilesystem(dir); err != nil {
+		fmt.Fprintf(os.Stderr, "isolate the filesystem of the peer: %v\n", err)
+		return 1
+	}
+
+	if err := util.InitLog("debug", filepath.Join(dir, peerLogFile)); err != nil {
+		fmt.Fprintf(os.Stderr, "init log: %v\n", err)
+		return 1
+	}
+
+	ctx, cancel := signal.NotifyContext(internal.CtxInitState(context.Background()), syscall.SIGTERM, syscall.SIGINT)
+	defer cancel()
+
+	lis, err := net.Listen("unix", filepath.Join(dir, daemonSocket))
+	if err != nil {
+		log.Errorf("failed to listen on the daemon socket: %v", err)
+		return 1
+	}
+
+	daemon := server.New(ctx, filepath.Join(dir, configFile), filepath.Join(dir, peerLogFile))
+	if err := daemon.Start(); err != nil {
+		log.Errorf("failed to start the daemon: %v", err)
+		return 1
+	}
+
+	s := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(server.UnaryErrorCodeInterceptor),
+		grpc.ChainStreamInterceptor(server.StreamErrorCodeInterceptor),
+	)
+	proto.RegisterDaemonServiceServer(s, daemon)
+	go func() {
+		<-ctx.Done()
+		// the peer restores the interfaces and the resolv.conf before it exits, like the service does when stopped
+		if _, err := daemon.Down(context.Background(), &proto.DownRequest{}); err != nil {
+			log.Errorf("failed to stop the daemon: %v", err)
+		}
+		s.Stop()
+	}()
+
+	if err := s.Serve(lis); err != nil {
+		log.Errorf("failed to serve the daemon: %v", err)
+		return 1
+	}
+	return 0
+}
+
+// isolateFilesystem gives the peer a copy-on-write /etc and a directory of its own for the sockets of the userspace
+// WireGuard, so the peers don't see the changes of each other, and the host doesn't see any of them. The resolv.conf
+// is replaced with a plain file pointing to the test namespace, the peers manage the DNS through the file whatever
+// the host uses. The changes to /etc are kept in the peer directory.
+func isolateFilesystem(dir string) error {
+	upper, work := filepath.Join(dir, "etc"), filepath.Join(dir, "etc.work")
+	for _, d := range []string{upper, work, wireguardSocketDir} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			return fmt.Errorf("create %s: %w", d, err)
+		}
+	}
+
+	// the mounts don't propagate to the host, the mount namespace was made private by the exec of the peer
+	opts := fmt.Sprintf("lowerdir=/etc,upperdir=%s,workdir=%s", upper, work)
+	if err := unix.Mount("overlay", "/etc", "overlay", 0, opts); err != nil {
+		return fmt.Errorf("mount overlay on /etc: %w", err)
+	}
+	if err := unix.Mount("tmpfs", wireguardSocketDir, "tmpfs", 0, ""); err != nil {
+		return fmt.Errorf("mount tmpfs on %s: %w", wireguardSocketDir, err)
+	}
+
+	// the resolv.conf can be a link to the stub file of systemd-resolved, it's replaced rather than written through
+	if err := os.Remove(resolvConfPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove %s: %w", resolvConfPath, err)
+	}
+	content := fmt.Sprintf("nameserver %s\n", topology.ServicesIP)
+	if err := os.WriteFile(resolvConfPath, []byte(content), 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", resolvConfPath, err)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+//go:build integration && linux
+
+package integration
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+
+	"github.com/netbirdio/netbird/client/proto"
+	"github.com/netbirdio/netbird/client/testing/topology"
+)
+
+// location places a host in the topology, behind the NAT of a site of its own or on the internet
+type location func(t *testing.T, n *network, name string) *topology.Host
+
+func behind(nat topology.NAT) location {
+	return func(t *testing.T, n *network, name string) *topology.Host {
+		t.Helper()
+
+		site, err := n.AddSite(name, nat)
+		require.NoError(t, err)
+		host, err := site.AddHost("peer")
+		require.NoError(t, err)
+		return host
+	}
+}
+
+func public(t *testing.T, n *network, name string) *topology.Host {
+	t.Helper()
+
+	host, err := n.AddHost(name)
+	require.NoError(t, err)
+	return host
+}
+
+func direct(c *assert.CollectT, state *proto.PeerState) {
+	assert.False(c, state.GetRelayed(), "the connection should be direct")
+}
+
+func relayed(c *assert.CollectT, state *proto.PeerState) {
+	assert.True(c, state.GetRelayed(), "the connection should be relayed")
+}
+
+func TestNATTraversal(t *testing.T) {
+	tests := []struct {
+		name       string
+		peer1      location
+		peer2      location
+		connection func(*assert.CollectT, *proto.PeerState)
+	}{
+		{
+			name:       "endpoint-independent NATs",
+			peer1:      behind(topology.NATEndpointIndependent),
+			peer2:      behind(topology.NATEndpointIndependent),
+			connection: direct,
+		},
+		{
+			name:       "endpoint-independent NAT and public host",
+			peer1:      behind(topology.NATEndpointIndependent),
+			peer2:      public,
+			connection: direct,
+		},
+		{
+			name:       "endpoint-dependent NAT and public host",
+			peer1:      behind(topology.NATEndpointDependent),
+			peer2:      public,
+			connection: direct,
+		},
+		{
+			name:       "endpoint-dependent NATs",
+			peer1:      behind(topology.NATEndpointDependent),
+			peer2:      behind(topology.NATEndpointDependent),
+			connection: relayed,
+		},
+		{
+			name:       "UDP blocked",
+			peer1:      behind(topology.NATUDPBlocked),
+			peer2:      public,
+			connection: relayed,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newNetwork(t)
+			peer1 := n.startPeer(t, tc.peer1(t, n, "site1"))
+			peer2 := n.startPeer(t, tc.peer2(t, n, "site2"))
+
+			peer1.waitConnected(t, peer2, tc.connection)
+			peer2.waitConnected(t, peer1, tc.connection)
+
+			peer1.requireReachable(t, listenTCP(t, peer2.Host, peer2.IP))
+			peer2.requireReachable(t, listenTCP(t, peer1.Host, peer1.IP))
+		})
+	}
+}
+
+func TestLossyLink(t *testing.T) {
+	n := newNetwork(t)
+
+	site, err := n.AddSite("site1", topology.NATEndpointIndependent)
+	require.NoError(t, err)
+	host, err := site.AddHost("peer")
+	require.NoError(t, err)
+
+	// the conditions of the router apply to all the traffic of the site, the services included
+	err = site.Router.SetLink(topology.Link{Delay: 50 * time.Millisecond, Jitter: 10 * time.Millisecond, Loss: 5})
+	if errors.Is(err, unix.ENOENT) {
+		t.Skip("the kernel lacks the netem queueing discipline")
+	}
+	require.NoError(t, err)
+
+	peer1 := n.startPeer(t, host)
+	peer2 := n.startPeer(t, public(t, n, "public"))
+
+	peer1.waitConnected(t, peer2, direct)
+	peer2.waitConnected(t, peer1, direct)
+
+	peer1.requireReachable(t, listenTCP(t, peer2.Host, peer2.IP))
+	peer2.requireReachable(t, listenTCP(t, peer1.Host, peer1.IP))
+}
@@ -0,0 +1,263 @@
+//go:build integration && linux
+
+package integration
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/netbirdio/netbird/client/internal/peer"
+	"github.com/netbirdio/netbird/client/proto"
+	"github.com/netbirdio/netbird/client/testing/topology"
+)
+
+const (
+	// connectTimeout bounds the time the peers take to connect, the peers connected over the relay first upgrade
+	// to a direct connection when the ICE checks succeed
+	connectTimeout = 90 * time.Second
+	stopTimeout    = 10 * time.Second
+	requestTimeout = 30 * time.Second
+)
+
+// testPeer is a daemon running in a host of the topology. It's a copy of the test binary started by startPeer, the
+// tests drive it over the API of the daemon like the CLI.
+type testPeer struct {
+	*topology.Host
+	// Hostname is the name the peer registers with, the name of the namespace of the host, unique in the topology
+	Hostname string
+	// FQDN and IP are the name and the address of the peer in the overlay network
+	FQDN string
+	IP   netip.Addr
+
+	dir    string
+	daemon proto.DaemonServiceClient
+}
+
+// startPeer starts a daemon in the host and brings it up with the setup key of the account. The daemon is stopped
+// when the test ends, its log is printed if the test failed.
+func (n *network) startPeer(t *testing.T, host *topology.Host) *testPeer {
+	t.Helper()
+
+	p := &testPeer{
+		Host:     host,
+		Hostname: host.Namespace(),
+		dir:      t.TempDir(),
+	}
+
+	output, err := os.Create(filepath.Join(p.dir, "output.log"))
+	require.NoError(t, err)
+	defer output.Close()
+
+	cmd := exec.Command(os.Args[0])
+	cmd.Env = append(os.Environ(),
+		envPeerDir+"="+p.dir,
+		"NB_STATE_DIR="+filepath.Join(p.dir, "state"),
+	)
+	cmd.Stdout, cmd.Stderr = output, output
+	// the parent death signal isn't set, it's sent when the thread starting the peer exits, see topology.Host.Do
+	cmd.SysProcAttr = &syscall.SysProcAttr{Unshareflags: syscall.CLONE_NEWNS}
+	require.NoError(t, host.Do(cmd.Start))
+
+	t.Cleanup(func() {
+		p.stop(t, cmd)
+		if t.Failed() {
+			p.printLogs(t)
+		}
+	})
+
+	socket := filepath.Join(p.dir, daemonSocket)
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(socket)
+		return err == nil
+	}, stopTimeout, 100*time.Millisecond, "the daemon of %s should listen", p.Hostname)
+
+	conn, err := grpc.NewClient("unix://"+socket, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	p.daemon = proto.NewDaemonServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	resp, err := p.daemon.Login(ctx, &proto.LoginRequest{
+		SetupKey:      n.setupKey,
+		ManagementUrl: n.managementURL,
+		Hostname:      p.Hostname,
+	})
+	require.NoError(t, err, "login %s", p.Hostname)
+	require.False(t, resp.NeedsSSOLogin)
+
+	_, err = p.daemon.Up(ctx, &proto.UpRequest{})
+	require.NoError(t, err, "up %s", p.Hostname)
+
+	require.EventuallyWithT(t, func(c *assert.CollectT) {
+		status, err := p.status()
+		require.NoError(c, err)
+		prefix, err := netip.ParsePrefix(status.GetLocalPeerState().GetIP())
+		require.NoError(c, err, "the address should be assigned")
+		p.IP, p.FQDN = prefix.Addr(), status.GetLocalPeerState().GetFqdn()
+	}, connectTimeout, time.Second, "%s should get an address from the management", p.Hostname)
+
+	return p
+}
+
+func (p *testPeer) stop(t *testing.T, cmd *exec.Cmd) {
+	t.Helper()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+		t.Logf("failed to stop %s: %v", p.Hostname, err)
+	}
+	select {
+	case <-done:
+	case <-time.After(stopTimeout):
+		t.Logf("%s didn't stop in %s, killing it", p.Hostname, stopTimeout)
+		_ = cmd.Process.Kill()
+		<-done
+	}
+}
+
+func (p *testPeer) printLogs(t *testing.T) {
+	t.Helper()
+
+	for _, name := range []string{"output.log", peerLogFile} {
+		content, err := os.ReadFile(filepath.Join(p.dir, name))
+		if err != nil {
+			t.Logf("failed to read %s of %s: %v", name, p.Hostname, err)
+			continue
+		}
+		t.Logf("%s of %s:\n%s", name, p.Hostname, content)
+	}
+}
+
+func (p *testPeer) status() (*proto.FullStatus, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	resp, err := p.daemon.Status(ctx, &proto.StatusRequest{GetFullPeerStatus: true})
+	if err != nil {
+		return nil, fmt.Errorf("status of %s: %w", p.Hostname, err)
+	}
+	return resp.GetFullStatus(), nil
+}
+
+// peerState returns the state of the connection to the other peer, nil if the other peer isn't known yet
+func (p *testPeer) peerState(other *testPeer) (*proto.PeerState, error) {
+	status, err := p.status()
+	if err != nil {
+		return nil, err
+	}
+	for _, state := range status.GetPeers() {
+		if state.GetFqdn() == other.FQDN {
+			return state, nil
+		}
+	}
+	return nil, nil
+}
+
+// waitConnected waits for the connection to the other peer to be up and to match the condition, e.g. relayed or
+// not, and returns its state
+func (p *testPeer) waitConnected(t *testing.T, other *testPeer, cond func(*assert.CollectT, *proto.PeerState)) *proto.PeerState {
+	t.Helper()
+
+	var state *proto.PeerState
+	require.EventuallyWithT(t, func(c *assert.CollectT) {
+		var err error
+		state, err = p.peerState(other)
+		require.NoError(c, err)
+		require.NotNil(c, state, "the peer should be known")
+		require.Equal(c, peer.StatusConnected.String(), state.GetConnStatus())
+		cond(c, state)
+	}, connectTimeout, time.Second, "%s should connect to %s", p.Hostname, other.Hostname)
+	return state
+}
+
+// dial connects to the TCP address from the host of the peer
+func (p *testPeer) dial(addr netip.AddrPort) error {
+	return p.Do(func() error {
+		conn, err := net.DialTimeout("tcp", addr.String(), 2*time.Second)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	})
+}
+
+// requireReachable waits until the peer can connect to the TCP address
+func (p *testPeer) requireReachable(t *testing.T, addr netip.AddrPort) {
+	t.Helper()
+
+	require.EventuallyWithT(t, func(c *assert.CollectT) {
+		assert.NoError(c, p.dial(addr))
+	}, connectTimeout, time.Second, "%s should reach %s", p.Hostname, addr)
+}
+
+// nameserver returns the first nameserver of the resolv.conf of the peer
+func (p *testPeer) nameserver() (netip.Addr, error) {
+	// the changes of the peer to /etc are in the upper directory of its overlay, see isolateFilesystem
+	f, err := os.Open(filepath.Join(p.dir, "etc", filepath.Base(resolvConfPath)))
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("open resolv.conf of %s: %w", p.Hostname, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == "nameserver" {
+			return netip.ParseAddr(fields[1])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return netip.Addr{}, fmt.Errorf("read resolv.conf of %s: %w", p.Hostname, err)
+	}
+	return netip.Addr{}, fmt.Errorf("no nameserver in resolv.conf of %s", p.Hostname)
+}
+
+// listenTCP starts a TCP server in the host accepting and closing the connections, it's stopped when the test ends
+func listenTCP(t *testing.T, host *topology.Host, ip netip.Addr) netip.AddrPort {
+	t.Helper()
+
+	var lis net.Listener
+	require.NoError(t, host.Do(func() error {
+		var err error
+		lis, err = net.Listen("tcp", netip.AddrPortFrom(ip, 0).String())
+		return err
+	}))
+	t.Cleanup(func() {
+		_ = lis.Close()
+	})
+
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+
+	return lis.Addr().(*net.TCPAddr).AddrPort()
+}
@@ -0,0 +1,95 @@
+//go:build integration && linux
+
+package integration
+
+import (
+	"context"
+	"net/netip"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/client/testing/topology"
+	"github.com/netbirdio/netbird/management/server/types"
+	"github.com/netbirdio/netbird/route"
+)
+
+// failoverTimeout bounds the time the client takes to notice the routing peer is gone and to switch to another one
+const failoverTimeout = 2 * time.Minute
+
+// waitRoutingPeer waits until one of the routing peers serves the network for the client and returns it
+func waitRoutingPeer(t *testing.T, client *testPeer, network netip.Prefix, routers ...*testPeer) *testPeer {
+	t.Helper()
+
+	var active *testPeer
+	require.EventuallyWithT(t, func(c *assert.CollectT) {
+		active = nil
+		for _, router := range routers {
+			state, err := client.peerState(router)
+			require.NoError(c, err)
+			if slices.Contains(state.GetNetworks(), network.String()) {
+				active = router
+				return
+			}
+		}
+		assert.Fail(c, "no routing peer serves the network")
+	}, failoverTimeout, time.Second, "%s should route %s through one of the routing peers", client.Hostname, network)
+	return active
+}
+
+func TestRouteFailover(t *testing.T) {
+	n := newNetwork(t)
+	ctx := context.Background()
+
+	office, err := n.AddSite("office", topology.NATEndpointIndependent)
+	require.NoError(t, err)
+	home, err := n.AddSite("home", topology.NATEndpointIndependent)
+	require.NoError(t, err)
+
+	var hosts []*topology.Host
+	for _, name := range []string{"router1", "router2", "server"} {
+		host, err := office.AddHost(name)
+		require.NoError(t, err)
+		hosts = append(hosts, host)
+	}
+	clientHost, err := home.AddHost("client")
+	require.NoError(t, err)
+
+	router1 := n.startPeer(t, hosts[0])
+	router2 := n.startPeer(t, hosts[1])
+	client := n.startPeer(t, clientHost)
+	serverAddr := listenTCP(t, hosts[2], hosts[2].IP)
+
+	// the route is distributed to the client only, the routing peers reach the office LAN directly
+	require.NoError(t, n.accountManager.SaveGroup(ctx, n.accountID, testUserID, &types.Group{
+		ID:     "routers",
+		Name:   "routers",
+		Issued: types.GroupIssuedAPI,
+		Peers:  []string{n.peerID(t, router1.Hostname), n.peerID(t, router2.Hostname)},
+	}))
+	require.NoError(t, n.accountManager.SaveGroup(ctx, n.accountID, testUserID, &types.Group{
+		ID:     "clients",
+		Name:   "clients",
+		Issued: types.GroupIssuedAPI,
+		Peers:  []string{n.peerID(t, client.Hostname)},
+	}))
+	_, err = n.accountManager.CreateRoute(ctx, n.accountID, office.LAN, route.IPv4Network, nil, "", []string{"routers"},
+		"office LAN", "office", true, 9999, []string{"clients"}, nil, true, testUserID, false)
+	require.NoError(t, err)
+
+	active := waitRoutingPeer(t, client, office.LAN, router1, router2)
+	client.requireReachable(t, serverAddr)
+
+	standby := router2
+	if active == router2 {
+		standby = router1
+	}
+
+	require.NoError(t, active.Disconnect())
+	require.Equal(t, standby.Hostname, waitRoutingPeer(t, client, office.LAN, standby).Hostname,
+		"the client should fail over to the other routing peer")
+	client.requireReachable(t, serverAddr)
+}
@@ -0,0 +1,244 @@
+//go:build integration && linux
+
+package integration
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/pion/turn/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+
+	"github.com/netbirdio/management-integrations/integrations"
+
+	"github.com/netbirdio/netbird/client/testing/topology"
+	mgmtProto "github.com/netbirdio/netbird/management/proto"
+	"github.com/netbirdio/netbird/management/server"
+	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/integrations/port_forwarding"
+	"github.com/netbirdio/netbird/management/server/permissions"
+	"github.com/netbirdio/netbird/management/server/settings"
+	"github.com/netbirdio/netbird/management/server/store"
+	"github.com/netbirdio/netbird/management/server/telemetry"
+	"github.com/netbirdio/netbird/management/server/types"
+	"github.com/netbirdio/netbird/management/server/users"
+	"github.com/netbirdio/netbird/relay/auth/allow"
+	relayServer "github.com/netbirdio/netbird/relay/server"
+	signalProto "github.com/netbirdio/netbird/signal/proto"
+	signalServer "github.com/netbirdio/netbird/signal/server"
+	"github.com/netbirdio/netbird/util"
+)
+
+const (
+	topologyName = "nbit"
+	dnsDomain    = "netbird.test"
+	testUserID   = "integration-user"
+)
+
+var (
+	kaep = keepalive.EnforcementPolicy{
+		MinTime:             15 * time.Second,
+		PermitWithoutStream: true,
+	}
+
+	kasp = keepalive.ServerParameters{
+		MaxConnectionIdle:     15 * time.Second,
+		MaxConnectionAgeGrace: 5 * time.Second,
+		Time:                  5 * time.Second,
+		Timeout:               2 * time.Second,
+	}
+)
+
+// network is a topology with the management, signal, relay and STUN services running in the test namespace. The
+// account has the default policy, all the peers can reach each other.
+type network struct {
+	*topology.Topology
+
+	accountManager *server.DefaultAccountManager
+	accountID      string
+	managementURL  string
+	setupKey       string
+}
+
+// newNetwork creates the topology and starts the services, they are stopped and the topology removed when the test
+// ends
+func newNetwork(t *testing.T) *network {
+	t.Helper()
+
+	topo, err := topology.New(topologyName)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, topo.Close())
+	})
+
+	n := &network{Topology: topo}
+	n.startManagement(t, startSTUN(t), startRelay(t), startSignal(t))
+	return n
+}
+
+func listen(t *testing.T) net.Listener {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", netip.AddrPortFrom(topology.ServicesIP, 0).String())
+	require.NoError(t, err)
+	return lis
+}
+
+func serve(t *testing.T, s *grpc.Server, lis net.Listener) {
+	t.Helper()
+
+	go func() {
+		if err := s.Serve(lis); err != nil {
+			t.Errorf("serve %s: %v", lis.Addr(), err)
+		}
+	}()
+	t.Cleanup(s.Stop)
+}
+
+// startSTUN starts a STUN server replying with the translated addresses of the peers, it doesn't allocate relays
+func startSTUN(t *testing.T) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp4", netip.AddrPortFrom(topology.ServicesIP, 0).String())
+	require.NoError(t, err)
+
+	s, err := turn.NewServer(turn.ServerConfig{
+		PacketConnConfigs: []turn.PacketConnConfig{{
+			PacketConn: conn,
+			RelayAddressGenerator: &turn.RelayAddressGeneratorStatic{
+				RelayAddress: topology.ServicesIP.AsSlice(),
+				Address:      topology.ServicesIP.String(),
+			},
+		}},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = s.Close()
+	})
+
+	return conn.LocalAddr().String()
+}
+
+func startRelay(t *testing.T) string {
+	t.Helper()
+
+	// the port is picked before the relay listens, the relay announces its address to the peers
+	lis := listen(t)
+	addr := lis.Addr().String()
+	require.NoError(t, lis.Close())
+
+	srv, err := relayServer.NewServer(otel.Meter(""), "rel://"+addr, false, &allow.Auth{})
+	require.NoError(t, err)
+	go func() {
+		if err := srv.Listen(relayServer.ListenerConfig{Address: addr}); err != nil {
+			t.Errorf("relay: %v", err)
+		}
+	}()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(ctx)
+	})
+
+	return "rel://" + addr
+}
+
+func startSignal(t *testing.T) string {
+	t.Helper()
+
+	srv, err := signalServer.NewServer(context.Background(), otel.Meter(""))
+	require.NoError(t, err)
+
+	s := grpc.NewServer(grpc.KeepaliveEnforcementPolicy(kaep), grpc.KeepaliveParams(kasp))
+	signalProto.RegisterSignalExchangeServer(s, srv)
+
+	lis := listen(t)
+	serve(t, s, lis)
+	return lis.Addr().String()
+}
+
+func (n *network) startManagement(t *testing.T, stunAddr, relayAddr, signalAddr string) {
+	t.Helper()
+	ctx := context.Background()
+
+	config := &types.Config{
+		Stuns:      []*types.Host{{Proto: types.UDP, URI: "stun:" + stunAddr}},
+		TURNConfig: &types.TURNConfig{},
+		Relay: &types.Relay{
+			Addresses:      []string{relayAddr},
+			CredentialsTTL: util.Duration{Duration: time.Hour},
+			Secret:         "integration-relay-secret",
+		},
+		Signal: &types.Host{
+			Proto: "http",
+			URI:   signalAddr,
+		},
+		Datadir: t.TempDir(),
+	}
+
+	s, cleanUp, err := store.NewTestStoreFromSQL(ctx, "", config.Datadir)
+	require.NoError(t, err)
+	t.Cleanup(cleanUp)
+
+	eventStore := &activity.InMemoryEventStore{}
+	peersUpdateManager := server.NewPeersUpdateManager(nil)
+	validator, err := integrations.NewIntegratedValidator(ctx, eventStore)
+	require.NoError(t, err)
+	metrics, err := telemetry.NewDefaultAppMetrics(ctx)
+	require.NoError(t, err)
+
+	permissionsManager := permissions.NewManagerMock()
+	settingsManager := settings.NewManager(s, users.NewManager(s), integrations.NewManager(eventStore), permissionsManager)
+
+	n.accountManager, err = server.BuildManager(ctx, s, peersUpdateManager, nil, "", dnsDomain, eventStore, nil, false, validator, metrics, port_forwarding.NewControllerMock(), settingsManager, permissionsManager)
+	require.NoError(t, err)
+
+	secretsManager := server.NewTimeBasedAuthSecretsManager(peersUpdateManager, config.TURNConfig, config.Relay, settingsManager)
+	mgmtServer, err := server.NewServer(ctx, config, n.accountManager, settingsManager, peersUpdateManager, secretsManager, nil, nil, nil)
+	require.NoError(t, err)
+
+	gs := grpc.NewServer(grpc.KeepaliveEnforcementPolicy(kaep), grpc.KeepaliveParams(kasp))
+	mgmtProto.RegisterManagementServiceServer(gs, mgmtServer)
+	lis := listen(t)
+	serve(t, gs, lis)
+	n.managementURL = "http://" + lis.Addr().String()
+
+	account, err := n.accountManager.GetOrCreateAccountByUser(ctx, testUserID, "")
+	require.NoError(t, err)
+	n.accountID = account.Id
+
+	key, err := n.accountManager.CreateSetupKey(ctx, n.accountID, "integration", types.SetupKeyReusable, time.Hour, nil, 0, testUserID, false, false, types.SetupKeyProvisioning{})
+	require.NoError(t, err)
+	n.setupKey = key.Key
+}
+
+// peerID returns the ID of the peer registered with the hostname
+func (n *network) peerID(t *testing.T, hostname string) string {
+	t.Helper()
+
+	account, err := n.accountManager.GetAccount(context.Background(), n.accountID)
+	require.NoError(t, err)
+	for id, p := range account.Peers {
+		if p.Name == hostname {
+			return id
+		}
+	}
+	require.Failf(t, "peer not found", "no peer registered as %s", hostname)
+	return ""
+}
+
+// allGroupID returns the ID of the group with all the peers of the account
+func (n *network) allGroupID(t *testing.T) string {
+	t.Helper()
+
+	group, err := n.accountManager.GetGroupByName(context.Background(), "All", n.accountID)
+	require.NoError(t, err)
+	return group.ID
+}
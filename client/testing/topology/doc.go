@@ -0,0 +1,19 @@
+// Package topology builds networks of Linux network namespaces for the integration tests of the client.
+//
+// A topology is a simulated internet with sites attached to it. Every site is a router translating the addresses of
+// the hosts of its LAN like a home or an office NAT, the public hosts are attached to the internet directly. The
+// namespace of the test is attached to the internet at ServicesIP, the management, signal, relay and STUN services
+// started by the test are reachable by the hosts there:
+//
+//	test namespace (ServicesIP 198.18.0.1)
+//	       |
+//	   internet 198.18.0.0/16 ---- public hosts 198.18.2.x
+//	       |
+//	  site router 198.18.1.x (NAT)
+//	       |
+//	   LAN 10.x.0.0/24 ---- hosts 10.x.0.10, 10.x.0.11, ...
+//
+// The links of the hosts and the routers can be made lossy and slow, or cut, to test the reconnections and the
+// failovers. Creating a topology requires root, the topologies share the address space, so only one of them can
+// exist at a time.
+package topology
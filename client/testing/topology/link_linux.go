@@ -0,0 +1,53 @@
+package topology
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/vishvananda/netlink"
+)
+
+// Link are the conditions of the uplink of a host, the zero Link is a perfect link
+type Link struct {
+	// Delay is added to the packets in each direction, the round trip time grows by twice the delay
+	Delay  time.Duration
+	Jitter time.Duration
+	// Loss is the percentage of the packets dropped in each direction
+	Loss float32
+}
+
+// SetLink applies the conditions to the uplink of the host in both directions, replacing the previous ones. The
+// conditions of a router apply to all the hosts of its site. It requires the netem queueing discipline of the kernel.
+func (h *Host) SetLink(link Link) error {
+	if err := setNetem(h.nl, h.iface, link); err != nil {
+		return fmt.Errorf("set link of %s: %w", h.Name, err)
+	}
+	if err := setNetem(h.outerNl, h.outerIface, link); err != nil {
+		return fmt.Errorf("set link of %s: %w", h.Name, err)
+	}
+	return nil
+}
+
+func setNetem(nl *netlink.Handle, iface string, link Link) error {
+	l, err := nl.LinkByName(iface)
+	if err != nil {
+		return fmt.Errorf("find link %s: %w", iface, err)
+	}
+
+	netem := netlink.NewNetem(
+		netlink.QdiscAttrs{
+			LinkIndex: l.Attrs().Index,
+			Handle:    netlink.MakeHandle(1, 0),
+			Parent:    netlink.HANDLE_ROOT,
+		},
+		netlink.NetemQdiscAttrs{
+			Latency: uint32(link.Delay.Microseconds()),
+			Jitter:  uint32(link.Jitter.Microseconds()),
+			Loss:    link.Loss,
+		},
+	)
+	if err := nl.QdiscReplace(netem); err != nil {
+		return fmt.Errorf("replace queueing discipline of %s: %w", iface, err)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package topology
+
+import (
+	"fmt"
+
+	"github.com/google/nftables"
+	"github.com/google/nftables/binaryutil"
+	"github.com/google/nftables/expr"
+	"golang.org/x/sys/unix"
+)
+
+const natTable = "topology"
+
+// NAT is the address translation of the router of a site, the types follow the NAT behaviour reported by the peers
+type NAT int
+
+const (
+	// NATEndpointIndependent maps the connections from a host port to the same public port whatever their destination,
+	// the replies are accepted from the destination only. The peers behind it connect directly by hole punching.
+	NATEndpointIndependent NAT = iota
+	// NATEndpointDependent maps every destination of a host port to a random public port, a symmetric NAT. The
+	// server reflexive candidates learnt from STUN don't work for the other peers.
+	NATEndpointDependent
+	// NATUDPBlocked translates the addresses like NATEndpointIndependent but drops the outbound UDP, the peers
+	// behind it can only connect over the TCP of the relay
+	NATUDPBlocked
+)
+
+func (n NAT) String() string {
+	switch n {
+	case NATEndpointIndependent:
+		return "endpoint-independent"
+	case NATEndpointDependent:
+		return "endpoint-dependent"
+	case NATUDPBlocked:
+		return "udp-blocked"
+	default:
+		return fmt.Sprintf("NAT(%d)", int(n))
+	}
+}
+
+// apply adds the rules translating the addresses of the packets leaving through the interface
+func (n NAT) apply(conn *nftables.Conn, iface string) error {
+	table := conn.AddTable(&nftables.Table{Name: natTable, Family: nftables.TableFamilyIPv4})
+
+	postrouting := conn.AddChain(&nftables.Chain{
+		Name:     "postrouting",
+		Table:    table,
+		Type:     nftables.ChainTypeNAT,
+		Hooknum:  nftables.ChainHookPostrouting,
+		Priority: nftables.ChainPriorityNATSource,
+	})
+	conn.AddRule(&nftables.Rule{
+		Table: table,
+		Chain: postrouting,
+		Exprs: append(matchOutputInterface(iface),
+			&expr.Masq{FullyRandom: n == NATEndpointDependent},
+		),
+	})
+
+	// the unsolicited packets to the router are dropped before their connection is confirmed, otherwise the
+	// connection takes the mapping of the port and the translation picks another port for the reply traffic of the
+	// hosts, like when both sides of a hole punching send their first packet at the same time
+	input := conn.AddChain(&nftables.Chain{
+		Name:     "input",
+		Table:    table,
+		Type:     nftables.ChainTypeFilter,
+		Hooknum:  nftables.ChainHookInput,
+		Priority: nftables.ChainPriorityFilter,
+	})
+	conn.AddRule(&nftables.Rule{
+		Table: table,
+		Chain: input,
+		Exprs: append(matchInputInterface(iface),
+			&expr.Ct{Key: expr.CtKeySTATE, Register: 1},
+			&expr.Bitwise{
+				SourceRegister: 1,
+				DestRegister:   1,
+				Len:            4,
+				Mask:           binaryutil.NativeEndian.PutUint32(expr.CtStateBitNEW),
+				Xor:            binaryutil.NativeEndian.PutUint32(0),
+			},
+			&expr.Cmp{Op: expr.CmpOpNeq, Register: 1, Data: []byte{0, 0, 0, 0}},
+			&expr.Verdict{Kind: expr.VerdictDrop},
+		),
+	})
+
+	if n == NATUDPBlocked {
+		forward := conn.AddChain(&nftables.Chain{
+			Name:     "forward",
+			Table:    table,
+			Type:     nftables.ChainTypeFilter,
+			Hooknum:  nftables.ChainHookForward,
+			Priority: nftables.ChainPriorityFilter,
+		})
+		conn.AddRule(&nftables.Rule{
+			Table: table,
+			Chain: forward,
+			Exprs: append(matchOutputInterface(iface),
+				&expr.Meta{Key: expr.MetaKeyL4PROTO, Register: 1},
+				&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{unix.IPPROTO_UDP}},
+				&expr.Verdict{Kind: expr.VerdictDrop},
+			),
+		})
+	}
+
+	if err := conn.Flush(); err != nil {
+		return fmt.Errorf("add %s NAT rules: %w", n, err)
+	}
+	return nil
+}
+
+func matchInputInterface(iface string) []expr.Any {
+	return []expr.Any{
+		&expr.Meta{Key: expr.MetaKeyIIFNAME, Register: 1},
+		&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: ifname(iface)},
+	}
+}
+
+func matchOutputInterface(iface string) []expr.Any {
+	return []expr.Any{
+		&expr.Meta{Key: expr.MetaKeyOIFNAME, Register: 1},
+		&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: ifname(iface)},
+	}
+}
+
+func ifname(n string) []byte {
+	b := make([]byte, 16)
+	copy(b, n+"\x00")
+	return b
+}
@@ -0,0 +1,466 @@
+package topology
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"regexp"
+	"runtime"
+
+	"github.com/google/nftables"
+	"github.com/hashicorp/go-multierror"
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+
+	nberrors "github.com/netbirdio/netbird/client/errors"
+)
+
+const (
+	inetBridge = "inet"
+	lanBridge  = "lan"
+	// uplink is the interface of the hosts to their LAN or to the internet
+	uplink = "eth0"
+	// wan is the interface of the routers to the internet
+	wan = "wan"
+
+	maxSites = 250
+	maxHosts = 240
+)
+
+var (
+	// ServicesIP is the address of the test namespace on the internet
+	ServicesIP = netip.MustParseAddr("198.18.0.1")
+
+	internet = netip.MustParsePrefix("198.18.0.0/16")
+
+	topologyNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9]{0,7}$`)
+	nodeNameRegexp     = regexp.MustCompile(`^[a-z][a-z0-9-]{0,15}$`)
+)
+
+// Topology is a simulated internet with the sites and the public hosts attached to it
+type Topology struct {
+	name   string
+	root   *netlink.Handle
+	inet   netns.NsHandle
+	inetNl *netlink.Handle
+
+	sites       []*Site
+	publicHosts []*Host
+	// namespaces are the names of the namespaces created, they are deleted on Close
+	namespaces []string
+}
+
+// Site is a LAN behind a router translating the addresses of its hosts
+type Site struct {
+	Name string
+	NAT  NAT
+	// Router is the router of the site, its IP is the public address of the hosts
+	Router *Host
+	// LAN is the network of the hosts of the site
+	LAN   netip.Prefix
+	Hosts []*Host
+
+	topology *Topology
+	index    int
+}
+
+// Host is a network namespace with an uplink to a LAN or to the internet
+type Host struct {
+	Name string
+	// IP is the address of the host in its LAN, or on the internet for the public hosts and the routers
+	IP netip.Addr
+
+	namespace string
+	ns        netns.NsHandle
+	nl        *netlink.Handle
+	iface     string
+	// outerNl and outerIface are the other end of the uplink, its link state connects the host
+	outerNl    *netlink.Handle
+	outerIface string
+}
+
+// New creates the internet of a topology and attaches the test namespace to it at ServicesIP. The name prefixes the
+// namespaces and the links of the topology, Close removes them.
+func New(name string) (*Topology, error) {
+	if !topologyNameRegexp.MatchString(name) {
+		return nil, fmt.Errorf("invalid topology name %q: use up to 8 lowercase letters and digits", name)
+	}
+
+	root, err := netlink.NewHandle()
+	if err != nil {
+		return nil, fmt.Errorf("create netlink handle: %w", err)
+	}
+
+	t := &Topology{
+		name: name,
+		root: root,
+	}
+
+	if err := t.createInternet(); err != nil {
+		if closeErr := t.Close(); closeErr != nil {
+			return nil, fmt.Errorf("%w, clean up: %v", err, closeErr)
+		}
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (t *Topology) createInternet() error {
+	namespace := t.name + "-inet"
+	ns, nl, err := t.newNamespace(namespace)
+	if err != nil {
+		return err
+	}
+	t.inet = ns
+	t.inetNl = nl
+
+	bridge := &netlink.Bridge{LinkAttrs: netlink.LinkAttrs{Name: inetBridge}}
+	if err := nl.LinkAdd(bridge); err != nil {
+		return fmt.Errorf("add internet bridge: %w", err)
+	}
+	if err := nl.LinkSetUp(bridge); err != nil {
+		return fmt.Errorf("set internet bridge up: %w", err)
+	}
+
+	if err := connect(t.root, t.rootIface(), nl, ns, "svc", inetBridge); err != nil {
+		return fmt.Errorf("attach test namespace: %w", err)
+	}
+
+	return addAddress(t.root, t.rootIface(), netip.PrefixFrom(ServicesIP, internet.Bits()))
+}
+
+// AddSite attaches a site with the NAT to the internet, it has no hosts until they are added
+func (t *Topology) AddSite(name string, nat NAT) (*Site, error) {
+	if !nodeNameRegexp.MatchString(name) {
+		return nil, fmt.Errorf("invalid site name %q", name)
+	}
+	if len(t.sites) == maxSites {
+		return nil, fmt.Errorf("topology has %d sites already", maxSites)
+	}
+
+	index := len(t.sites) + 1
+	site := &Site{
+		Name:     name,
+		NAT:      nat,
+		LAN:      netip.PrefixFrom(netip.AddrFrom4([4]byte{10, byte(index), 0, 0}), 24),
+		topology: t,
+		index:    index,
+	}
+
+	router, err := t.newHost(name, t.name+"-"+name, wan, fmt.Sprintf("w%d", index), t.inet, t.inetNl, inetBridge,
+		netip.PrefixFrom(netip.AddrFrom4([4]byte{198, 18, 1, byte(index)}), internet.Bits()), ServicesIP)
+	if err != nil {
+		return nil, fmt.Errorf("add router of site %s: %w", name, err)
+	}
+	site.Router = router
+
+	if err := site.createLAN(); err != nil {
+		router.close()
+		return nil, fmt.Errorf("add LAN of site %s: %w", name, err)
+	}
+
+	t.sites = append(t.sites, site)
+	return site, nil
+}
+
+// AddHost attaches a host to the internet directly
+func (t *Topology) AddHost(name string) (*Host, error) {
+	if !nodeNameRegexp.MatchString(name) {
+		return nil, fmt.Errorf("invalid host name %q", name)
+	}
+	if len(t.publicHosts) == maxHosts {
+		return nil, fmt.Errorf("topology has %d public hosts already", maxHosts)
+	}
+
+	index := len(t.publicHosts) + 1
+	host, err := t.newHost(name, t.name+"-"+name, uplink, fmt.Sprintf("h%d", index), t.inet, t.inetNl, inetBridge,
+		netip.PrefixFrom(netip.AddrFrom4([4]byte{198, 18, 2, byte(index)}), internet.Bits()), ServicesIP)
+	if err != nil {
+		return nil, fmt.Errorf("add host %s: %w", name, err)
+	}
+
+	t.publicHosts = append(t.publicHosts, host)
+	return host, nil
+}
+
+// Close deletes the namespaces of the topology with their links. The processes started in them have to be stopped
+// first, the namespaces outlive them otherwise.
+func (t *Topology) Close() error {
+	var merr *multierror.Error
+
+	for _, site := range t.sites {
+		for _, host := range append([]*Host{site.Router}, site.Hosts...) {
+			host.close()
+		}
+	}
+	for _, host := range t.publicHosts {
+		host.close()
+	}
+
+	if t.inetNl != nil {
+		t.inetNl.Close()
+	}
+	if t.inet.IsOpen() {
+		if err := t.inet.Close(); err != nil {
+			merr = multierror.Append(merr, fmt.Errorf("close internet namespace: %w", err))
+		}
+	}
+
+	for i := len(t.namespaces) - 1; i >= 0; i-- {
+		if err := netns.DeleteNamed(t.namespaces[i]); err != nil && !errors.Is(err, os.ErrNotExist) {
+			merr = multierror.Append(merr, fmt.Errorf("delete namespace %s: %w", t.namespaces[i], err))
+		}
+	}
+	t.namespaces = nil
+
+	// the link of the test namespace is removed with its other end in the internet namespace, unless a process holds
+	// the namespace
+	if link, err := t.root.LinkByName(t.rootIface()); err == nil {
+		if err := t.root.LinkDel(link); err != nil {
+			merr = multierror.Append(merr, fmt.Errorf("delete link %s: %w", t.rootIface(), err))
+		}
+	}
+	t.root.Close()
+
+	return nberrors.FormatErrorOrNil(merr)
+}
+
+// rootIface is the link of the test namespace to the internet
+func (t *Topology) rootIface() string {
+	return t.name + "-inet"
+}
+
+// AddHost attaches a host to the LAN of the site
+func (s *Site) AddHost(name string) (*Host, error) {
+	if !nodeNameRegexp.MatchString(name) {
+		return nil, fmt.Errorf("invalid host name %q", name)
+	}
+	if len(s.Hosts) == maxHosts {
+		return nil, fmt.Errorf("site %s has %d hosts already", s.Name, maxHosts)
+	}
+
+	index := len(s.Hosts) + 1
+	host, err := s.topology.newHost(name, s.topology.name+"-"+s.Name+"-"+name, uplink, fmt.Sprintf("l%d", index),
+		s.Router.ns, s.Router.nl, lanBridge, netip.PrefixFrom(s.hostIP(9+index), s.LAN.Bits()), s.hostIP(1))
+	if err != nil {
+		return nil, fmt.Errorf("add host %s to site %s: %w", name, s.Name, err)
+	}
+
+	s.Hosts = append(s.Hosts, host)
+	return host, nil
+}
+
+func (s *Site) hostIP(n int) netip.Addr {
+	return netip.AddrFrom4([4]byte{10, byte(s.index), 0, byte(n)})
+}
+
+func (s *Site) createLAN() error {
+	bridge := &netlink.Bridge{LinkAttrs: netlink.LinkAttrs{Name: lanBridge}}
+	if err := s.Router.nl.LinkAdd(bridge); err != nil {
+		return fmt.Errorf("add LAN bridge: %w", err)
+	}
+	if err := s.Router.nl.LinkSetUp(bridge); err != nil {
+		return fmt.Errorf("set LAN bridge up: %w", err)
+	}
+	if err := addAddress(s.Router.nl, lanBridge, netip.PrefixFrom(s.hostIP(1), s.LAN.Bits())); err != nil {
+		return err
+	}
+
+	if err := s.Router.Do(enableForwarding); err != nil {
+		return err
+	}
+
+	conn, err := nftables.New(nftables.WithNetNSFd(int(s.Router.ns)))
+	if err != nil {
+		return fmt.Errorf("create nftables connection: %w", err)
+	}
+	return s.NAT.apply(conn, wan)
+}
+
+// newHost creates a namespace with an uplink attached to the bridge of the outer namespace and a default route via
+// the gateway
+func (t *Topology) newHost(name, namespace, iface, outerIface string, outerNs netns.NsHandle, outerNl *netlink.Handle,
+	bridge string, addr netip.Prefix, gateway netip.Addr) (*Host, error) {
+	ns, nl, err := t.newNamespace(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	host := &Host{
+		Name:       name,
+		IP:         addr.Addr(),
+		namespace:  namespace,
+		ns:         ns,
+		nl:         nl,
+		iface:      iface,
+		outerNl:    outerNl,
+		outerIface: outerIface,
+	}
+
+	if err := connect(nl, iface, outerNl, outerNs, outerIface, bridge); err != nil {
+		host.close()
+		return nil, err
+	}
+	if err := addAddress(nl, iface, addr); err != nil {
+		host.close()
+		return nil, err
+	}
+
+	if err := nl.RouteAdd(&netlink.Route{Gw: gateway.AsSlice()}); err != nil {
+		host.close()
+		return nil, fmt.Errorf("add default route: %w", err)
+	}
+
+	return host, nil
+}
+
+// newNamespace creates a named namespace with the loopback up
+func (t *Topology) newNamespace(name string) (netns.NsHandle, *netlink.Handle, error) {
+	var ns netns.NsHandle
+	err := runLocked(func() error {
+		var err error
+		ns, err = netns.NewNamed(name)
+		return err
+	})
+	if err != nil {
+		return 0, nil, fmt.Errorf("create namespace %s: %w", name, err)
+	}
+	t.namespaces = append(t.namespaces, name)
+
+	nl, err := netlink.NewHandleAt(ns)
+	if err != nil {
+		_ = ns.Close()
+		return 0, nil, fmt.Errorf("create netlink handle in namespace %s: %w", name, err)
+	}
+
+	lo, err := nl.LinkByName("lo")
+	if err == nil {
+		err = nl.LinkSetUp(lo)
+	}
+	if err != nil {
+		nl.Close()
+		_ = ns.Close()
+		return 0, nil, fmt.Errorf("set loopback up in namespace %s: %w", name, err)
+	}
+
+	return ns, nl, nil
+}
+
+// Namespace returns the name of the network namespace of the host, e.g. for ip netns exec
+func (h *Host) Namespace() string {
+	return h.namespace
+}
+
+// Do runs the function in the namespace of the host. The sockets the function opens stay in the namespace after it
+// returns, the goroutines it starts don't.
+func (h *Host) Do(fn func() error) error {
+	return runLocked(func() error {
+		if err := netns.Set(h.ns); err != nil {
+			return fmt.Errorf("enter namespace %s: %w", h.namespace, err)
+		}
+		return fn()
+	})
+}
+
+// Disconnect cuts the uplink of the host, the host keeps its address and routes
+func (h *Host) Disconnect() error {
+	link, err := h.outerNl.LinkByName(h.outerIface)
+	if err != nil {
+		return fmt.Errorf("find uplink of %s: %w", h.Name, err)
+	}
+	if err := h.outerNl.LinkSetDown(link); err != nil {
+		return fmt.Errorf("set uplink of %s down: %w", h.Name, err)
+	}
+	return nil
+}
+
+// Reconnect restores the uplink of the host cut by Disconnect
+func (h *Host) Reconnect() error {
+	link, err := h.outerNl.LinkByName(h.outerIface)
+	if err != nil {
+		return fmt.Errorf("find uplink of %s: %w", h.Name, err)
+	}
+	if err := h.outerNl.LinkSetUp(link); err != nil {
+		return fmt.Errorf("set uplink of %s up: %w", h.Name, err)
+	}
+	return nil
+}
+
+func (h *Host) close() {
+	if h == nil {
+		return
+	}
+	if h.nl != nil {
+		h.nl.Close()
+	}
+	if h.ns.IsOpen() {
+		_ = h.ns.Close()
+	}
+}
+
+// connect creates a veth pair with one end in the namespace of the handle and the other one attached to the bridge
+// of the outer namespace
+func connect(nl *netlink.Handle, iface string, outerNl *netlink.Handle, outerNs netns.NsHandle, outerIface, bridge string) error {
+	veth := &netlink.Veth{
+		LinkAttrs:     netlink.LinkAttrs{Name: iface},
+		PeerName:      outerIface,
+		PeerNamespace: netlink.NsFd(outerNs),
+	}
+	if err := nl.LinkAdd(veth); err != nil {
+		return fmt.Errorf("add link %s: %w", iface, err)
+	}
+
+	outer, err := outerNl.LinkByName(outerIface)
+	if err != nil {
+		return fmt.Errorf("find link %s: %w", outerIface, err)
+	}
+	master, err := outerNl.LinkByName(bridge)
+	if err != nil {
+		return fmt.Errorf("find bridge %s: %w", bridge, err)
+	}
+	if err := outerNl.LinkSetMaster(outer, master); err != nil {
+		return fmt.Errorf("attach link %s to bridge %s: %w", outerIface, bridge, err)
+	}
+	if err := outerNl.LinkSetUp(outer); err != nil {
+		return fmt.Errorf("set link %s up: %w", outerIface, err)
+	}
+
+	if err := nl.LinkSetUp(veth); err != nil {
+		return fmt.Errorf("set link %s up: %w", iface, err)
+	}
+	return nil
+}
+
+func addAddress(nl *netlink.Handle, iface string, prefix netip.Prefix) error {
+	link, err := nl.LinkByName(iface)
+	if err != nil {
+		return fmt.Errorf("find link %s: %w", iface, err)
+	}
+
+	addr := &netlink.Addr{IPNet: &net.IPNet{IP: prefix.Addr().AsSlice(), Mask: net.CIDRMask(prefix.Bits(), 32)}}
+	if err := nl.AddrAdd(link, addr); err != nil {
+		return fmt.Errorf("add address %s to %s: %w", prefix, iface, err)
+	}
+	return nil
+}
+
+func enableForwarding() error {
+	if err := os.WriteFile("/proc/sys/net/ipv4/ip_forward", []byte("1"), 0o644); err != nil {
+		return fmt.Errorf("enable forwarding: %w", err)
+	}
+	return nil
+}
+
+// runLocked runs the function on an OS thread of its own. The thread isn't unlocked, it exits with the goroutine,
+// so the namespace the function enters doesn't leak to the other goroutines.
+func runLocked(fn func() error) error {
+	errCh := make(chan error, 1)
+	go func() {
+		runtime.LockOSThread()
+		errCh <- fn()
+	}()
+	return <-errCh
+}
@@ -0,0 +1,143 @@
+package topology
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+// startReflector starts a UDP server in the test namespace replying with the address of the sender
+func startReflector(t *testing.T) netip.AddrPort {
+	t.Helper()
+
+	conn, err := net.ListenUDP("udp4", net.UDPAddrFromAddrPort(netip.AddrPortFrom(ServicesIP, 0)))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	go func() {
+		buf := make([]byte, 64)
+		for {
+			_, addr, err := conn.ReadFromUDPAddrPort(buf)
+			if err != nil {
+				return
+			}
+			_, _ = conn.WriteToUDPAddrPort([]byte(addr.String()), addr)
+		}
+	}()
+
+	return conn.LocalAddr().(*net.UDPAddr).AddrPort()
+}
+
+// mappedAddresses sends a packet from the same port of the host to each reflector and returns the addresses the
+// reflectors saw, invalid for the reflectors that didn't reply
+func mappedAddresses(t *testing.T, host *Host, reflectors ...netip.AddrPort) []netip.AddrPort {
+	t.Helper()
+
+	var conn *net.UDPConn
+	require.NoError(t, host.Do(func() error {
+		var err error
+		conn, err = net.ListenUDP("udp4", &net.UDPAddr{})
+		return err
+	}))
+	defer conn.Close()
+
+	mapped := make([]netip.AddrPort, 0, len(reflectors))
+	buf := make([]byte, 64)
+	for _, reflector := range reflectors {
+		_, err := conn.WriteToUDPAddrPort([]byte("ping"), reflector)
+		require.NoError(t, err)
+
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(500*time.Millisecond)))
+		n, err := conn.Read(buf)
+		if err != nil {
+			mapped = append(mapped, netip.AddrPort{})
+			continue
+		}
+		addr, err := netip.ParseAddrPort(string(buf[:n]))
+		require.NoError(t, err)
+		mapped = append(mapped, addr)
+	}
+	return mapped
+}
+
+func TestTopology(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("creating network namespaces requires root")
+	}
+
+	topo, err := New("nbtopo")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, topo.Close())
+	})
+
+	sites := map[NAT]*Host{}
+	for i, nat := range []NAT{NATEndpointIndependent, NATEndpointDependent, NATUDPBlocked} {
+		site, err := topo.AddSite(fmt.Sprintf("site%d", i+1), nat)
+		require.NoError(t, err)
+		host, err := site.AddHost("peer")
+		require.NoError(t, err)
+		assert.True(t, site.LAN.Contains(host.IP))
+		sites[nat] = host
+	}
+	public, err := topo.AddHost("public")
+	require.NoError(t, err)
+
+	reflector1, reflector2 := startReflector(t), startReflector(t)
+
+	t.Run("endpoint-independent", func(t *testing.T) {
+		mapped := mappedAddresses(t, sites[NATEndpointIndependent], reflector1, reflector2)
+		require.True(t, mapped[0].IsValid(), "the reflector should reply")
+		assert.Equal(t, topo.sites[0].Router.IP, mapped[0].Addr(), "the router should translate the address")
+		assert.Equal(t, mapped[0], mapped[1], "the destinations should see the same mapping")
+	})
+
+	t.Run("endpoint-dependent", func(t *testing.T) {
+		mapped := mappedAddresses(t, sites[NATEndpointDependent], reflector1, reflector2)
+		require.True(t, mapped[0].IsValid() && mapped[1].IsValid(), "the reflectors should reply")
+		assert.Equal(t, mapped[0].Addr(), mapped[1].Addr())
+		assert.NotEqual(t, mapped[0].Port(), mapped[1].Port(), "the destinations should see different mappings")
+	})
+
+	t.Run("udp-blocked", func(t *testing.T) {
+		mapped := mappedAddresses(t, sites[NATUDPBlocked], reflector1)
+		assert.False(t, mapped[0].IsValid(), "the UDP should be dropped")
+	})
+
+	t.Run("disconnect", func(t *testing.T) {
+		mapped := mappedAddresses(t, public, reflector1)
+		assert.Equal(t, public.IP, mapped[0].Addr(), "the public host shouldn't be translated")
+
+		require.NoError(t, public.Disconnect())
+		mapped = mappedAddresses(t, public, reflector1)
+		assert.False(t, mapped[0].IsValid(), "the disconnected host shouldn't reach the reflector")
+
+		require.NoError(t, public.Reconnect())
+		assert.Eventually(t, func() bool {
+			return mappedAddresses(t, public, reflector1)[0].IsValid()
+		}, 5*time.Second, 100*time.Millisecond, "the reconnected host should reach the reflector once the link is up")
+	})
+
+	t.Run("link", func(t *testing.T) {
+		err := public.SetLink(Link{Loss: 100})
+		if errors.Is(err, unix.ENOENT) {
+			t.Skip("the kernel lacks the netem queueing discipline")
+		}
+		require.NoError(t, err)
+
+		mapped := mappedAddresses(t, public, reflector1)
+		assert.False(t, mapped[0].IsValid(), "the packets should be dropped")
+
+		require.NoError(t, public.SetLink(Link{}))
+		mapped = mappedAddresses(t, public, reflector1)
+		assert.True(t, mapped[0].IsValid())
+	})
+}
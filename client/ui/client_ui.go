@@ -12,6 +12,7 @@ import (
 	"os/exec"
 	"path"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -32,6 +33,7 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 
 	"github.com/netbirdio/netbird/client/internal"
+	"github.com/netbirdio/netbird/client/internal/mdm"
 	"github.com/netbirdio/netbird/client/proto"
 	"github.com/netbirdio/netbird/client/ui/desktop"
 	"github.com/netbirdio/netbird/client/ui/event"
@@ -667,6 +669,7 @@ func (s *serviceClient) onTrayReady() {
 			s.updateExitNodes()
 		}
 	})
+	s.eventManager.AddHandler(s.onRouteApprovalEvent)
 
 	go s.eventManager.Start(s.ctx)
 
@@ -872,8 +875,13 @@ func (s *serviceClient) getSrvConfig() {
 		if !cfg.RosenpassEnabled {
 			s.sRosenpassPermissive.Disable()
 		}
+		if slices.Contains(cfg.ManagedSettings, mdm.SettingManagementURL) {
+			s.iMngURL.Disable()
+		}
 	}
 
+	s.hideDisabledUIActions(cfg.DisabledUIActions)
+
 	if s.mNotifications == nil {
 		return
 	}
@@ -888,6 +896,27 @@ func (s *serviceClient) getSrvConfig() {
 
 }
 
+// hideDisabledUIActions hides the menu items of the actions disallowed by the device management policy
+func (s *serviceClient) hideDisabledUIActions(actions []string) {
+	items := map[string]*systray.MenuItem{
+		mdm.UIActionConnect:          s.mUp,
+		mdm.UIActionDisconnect:       s.mDown,
+		mdm.UIActionSettings:         s.mSettings,
+		mdm.UIActionAdvancedSettings: s.mAdvancedSettings,
+		mdm.UIActionDebugBundle:      s.mCreateDebugBundle,
+		mdm.UIActionNetworks:         s.mNetworks,
+		mdm.UIActionExitNode:         s.mExitNode,
+		mdm.UIActionQuit:             s.mQuit,
+	}
+
+	for _, action := range actions {
+		// the menu items don't exist in the settings and networks windows
+		if item := items[action]; item != nil {
+			item.Hide()
+		}
+	}
+}
+
 func (s *serviceClient) onUpdateAvailable() {
 	s.updateIndicationLock.Lock()
 	defer s.updateIndicationLock.Unlock()
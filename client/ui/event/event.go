@@ -29,12 +29,15 @@ type Manager struct {
 	cancel   context.CancelFunc
 	enabled  bool
 	handlers []Handler
+	// shown are the IDs of the notifications shown to the user, the active ones are listed again on reconnects
+	shown map[string]struct{}
 }
 
 func NewManager(app fyne.App, addr string) *Manager {
 	return &Manager{
-		app:  app,
-		addr: addr,
+		app:   app,
+		addr:  addr,
+		shown: make(map[string]struct{}),
 	}
 }
 
@@ -78,6 +81,15 @@ func (e *Manager) streamEvents() error {
 		log.Info("unsubscribed from daemon events")
 	}()
 
+	// the notifications published while the UI wasn't subscribed are still active in the daemon
+	notifications, err := client.ListNotifications(ctx, &proto.ListNotificationsRequest{})
+	if err != nil {
+		log.Warnf("failed to list notifications: %v", err)
+	}
+	for _, notification := range notifications.GetNotifications() {
+		e.handleEvent(notification)
+	}
+
 	for {
 		event, err := stream.Recv()
 		if err != nil {
@@ -105,8 +117,16 @@ func (e *Manager) handleEvent(event *proto.SystemEvent) {
 	e.mu.Lock()
 	enabled := e.enabled
 	handlers := slices.Clone(e.handlers)
+	_, shown := e.shown[event.Id]
+	if event.Notification != "" {
+		e.shown[event.Id] = struct{}{}
+	}
 	e.mu.Unlock()
 
+	if shown {
+		return
+	}
+
 	// critical events are always shown
 	if !enabled && event.Severity != proto.SystemEvent_CRITICAL {
 		return
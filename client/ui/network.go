@@ -334,7 +334,7 @@ func (s *serviceClient) updateExitNodes() {
 		return
 	}
 
-	exitNodes, err := s.getExitNodes(conn)
+	exitNodes, auto, err := s.getExitNodes(conn)
 	if err != nil {
 		log.Errorf("get exit nodes: %v", err)
 		return
@@ -343,7 +343,7 @@ func (s *serviceClient) updateExitNodes() {
 	s.exitNodeMu.Lock()
 	defer s.exitNodeMu.Unlock()
 
-	s.recreateExitNodeMenu(exitNodes)
+	s.recreateExitNodeMenu(exitNodes, auto)
 
 	if len(s.mExitNodeItems) > 0 {
 		s.mExitNode.Enable()
@@ -354,7 +354,7 @@ func (s *serviceClient) updateExitNodes() {
 	log.Debugf("Exit nodes updated: %d", len(s.mExitNodeItems))
 }
 
-func (s *serviceClient) recreateExitNodeMenu(exitNodes []*proto.Network) {
+func (s *serviceClient) recreateExitNodeMenu(exitNodes []*proto.Network, auto bool) {
 	for _, node := range s.mExitNodeItems {
 		node.cancel()
 		node.Remove()
@@ -366,6 +366,21 @@ func (s *serviceClient) recreateExitNodeMenu(exitNodes []*proto.Network) {
 		s.mExitNode = systray.AddMenuItem("Exit Node", exitNodeMenuDescr)
 	}
 
+	if len(exitNodes) > 1 {
+		menuItem := s.mExitNode.AddSubMenuItemCheckbox(
+			"Auto",
+			"Use the exit node with the lowest latency and loss",
+			auto,
+		)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		s.mExitNodeItems = append(s.mExitNodeItems, menuHandler{
+			MenuItem: menuItem,
+			cancel:   cancel,
+		})
+		go s.handleExitNodeAutoChecked(ctx, menuItem)
+	}
+
 	for _, node := range exitNodes {
 		menuItem := s.mExitNode.AddSubMenuItemCheckbox(
 			node.ID,
@@ -383,13 +398,14 @@ func (s *serviceClient) recreateExitNodeMenu(exitNodes []*proto.Network) {
 
 }
 
-func (s *serviceClient) getExitNodes(conn proto.DaemonServiceClient) ([]*proto.Network, error) {
+// getExitNodes returns the exit nodes and whether the exit node is selected automatically
+func (s *serviceClient) getExitNodes(conn proto.DaemonServiceClient) ([]*proto.Network, bool, error) {
 	ctx, cancel := context.WithTimeout(s.ctx, defaultFailTimeout)
 	defer cancel()
 
 	resp, err := conn.ListNetworks(ctx, &proto.ListNetworksRequest{})
 	if err != nil {
-		return nil, fmt.Errorf("list networks: %v", err)
+		return nil, false, fmt.Errorf("list networks: %v", err)
 	}
 
 	var exitNodes []*proto.Network
@@ -398,7 +414,61 @@ func (s *serviceClient) getExitNodes(conn proto.DaemonServiceClient) ([]*proto.N
 			exitNodes = append(exitNodes, network)
 		}
 	}
-	return exitNodes, nil
+	return exitNodes, resp.GetExitNodeAuto(), nil
+}
+
+func (s *serviceClient) handleExitNodeAutoChecked(ctx context.Context, item *systray.MenuItem) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-item.ClickedCh:
+			if !ok {
+				return
+			}
+			if err := s.toggleExitNodeAuto(item); err != nil {
+				log.Errorf("failed to toggle the automatic exit node selection: %v", err)
+				continue
+			}
+		}
+	}
+}
+
+// toggleExitNodeAuto turns the automatic exit node selection on or off, selecting an exit node turns it off too
+func (s *serviceClient) toggleExitNodeAuto(item *systray.MenuItem) error {
+	conn, err := s.getSrvClient(defaultFailTimeout)
+	if err != nil {
+		return fmt.Errorf("get client: %v", err)
+	}
+
+	s.exitNodeMu.Lock()
+	defer s.exitNodeMu.Unlock()
+
+	req := &proto.SelectNetworksRequest{ExitNodeAuto: true}
+	if item.Checked() {
+		if _, err := conn.DeselectNetworks(s.ctx, req); err != nil {
+			return fmt.Errorf("deselect networks: %v", err)
+		}
+		item.Uncheck()
+		log.Info("Turned the automatic exit node selection off")
+	} else {
+		if _, err := conn.SelectNetworks(s.ctx, req); err != nil {
+			return fmt.Errorf("select networks: %v", err)
+		}
+		item.Check()
+		log.Info("Turned the automatic exit node selection on")
+	}
+
+	// linux/bsd doesn't handle Check/Uncheck well, so we recreate the menu
+	if runtime.GOOS == "linux" || runtime.GOOS == "freebsd" {
+		exitNodes, auto, err := s.getExitNodes(conn)
+		if err != nil {
+			return fmt.Errorf("get exit nodes: %v", err)
+		}
+		s.recreateExitNodeMenu(exitNodes, auto)
+	}
+
+	return nil
 }
 
 func (s *serviceClient) handleChecked(ctx context.Context, id string, item *systray.MenuItem) {
@@ -430,7 +500,7 @@ func (s *serviceClient) toggleExitNode(nodeID string, item *systray.MenuItem) er
 	s.exitNodeMu.Lock()
 	defer s.exitNodeMu.Unlock()
 
-	exitNodes, err := s.getExitNodes(conn)
+	exitNodes, auto, err := s.getExitNodes(conn)
 	if err != nil {
 		return fmt.Errorf("get exit nodes: %v", err)
 	}
@@ -473,7 +543,8 @@ func (s *serviceClient) toggleExitNode(nodeID string, item *systray.MenuItem) er
 
 	// linux/bsd doesn't handle Check/Uncheck well, so we recreate the menu
 	if runtime.GOOS == "linux" || runtime.GOOS == "freebsd" {
-		s.recreateExitNodeMenu(exitNodes)
+		// selecting an exit node turns the automatic selection off
+		s.recreateExitNodeMenu(exitNodes, auto && exitNode == nil)
 	}
 
 	return nil
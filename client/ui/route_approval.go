@@ -0,0 +1,59 @@
+//go:build !(linux && 386)
+
+package main
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/client/proto"
+)
+
+// onRouteApprovalEvent asks the user to approve the high-risk networks the daemon holds back
+func (s *serviceClient) onRouteApprovalEvent(event *proto.SystemEvent) {
+	if event.Category != proto.SystemEvent_NETWORK || event.Metadata["approval"] != "pending" {
+		return
+	}
+
+	netID := event.Metadata["id"]
+	w := s.app.NewWindow("NetBird Network Approval")
+	w.Resize(fyne.NewSize(480, 200))
+
+	message := fmt.Sprintf("The network %s routes %s through the peer %s.\n"+
+		"Only approve it if you expect your traffic to be routed through this peer.",
+		netID, event.Metadata["network"], event.Metadata["peer"])
+
+	confirm := dialog.NewConfirm("Approve network", message, func(approve bool) {
+		defer w.Close()
+		if !approve {
+			return
+		}
+		if err := s.approveNetwork(netID); err != nil {
+			log.Errorf("failed to approve network %s: %v", netID, err)
+			s.app.SendNotification(fyne.NewNotification("Error", fmt.Sprintf("Failed to approve network %s", netID)))
+		}
+	}, w)
+	confirm.SetConfirmText("Approve")
+	confirm.SetDismissText("Ignore")
+
+	w.Show()
+	confirm.Show()
+}
+
+func (s *serviceClient) approveNetwork(netID string) error {
+	conn, err := s.getSrvClient(defaultFailTimeout)
+	if err != nil {
+		return fmt.Errorf(getClientFMT, err)
+	}
+
+	if _, err := conn.ApproveNetworks(s.ctx, &proto.ApproveNetworksRequest{NetworkIDs: []string{netID}}); err != nil {
+		return fmt.Errorf("approve networks: %w", err)
+	}
+
+	go s.updateExitNodes()
+
+	return nil
+}
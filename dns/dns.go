@@ -15,6 +15,8 @@ import (
 const (
 	// DefaultDNSPort well-known port number
 	DefaultDNSPort = 53
+	// ForwarderDNSPort is the port the DNS forwarder of the routing peers listens on
+	ForwarderDNSPort = 5353
 	// RootZone is a string representation of the root zone
 	RootZone = "."
 	// DefaultClass is the class supported by the system
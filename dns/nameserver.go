@@ -24,9 +24,32 @@ const (
 	UDPNameServerTypeString = "udp"
 )
 
+const (
+	// RoutedAnswersDisabled returns the answers of the nameservers unchanged
+	RoutedAnswersDisabled RoutedAnswers = ""
+	// RoutedAnswersPrefer moves the addresses covered by a route of the peer before the other addresses
+	RoutedAnswersPrefer RoutedAnswers = "prefer"
+	// RoutedAnswersOnly removes the addresses not covered by a route of the peer if at least one address is
+	RoutedAnswersOnly RoutedAnswers = "only"
+)
+
 // NameServerType nameserver type
 type NameServerType int
 
+// RoutedAnswers selects how the address records answered by the nameservers of a group are adapted to the routes
+// of the peer, so split-horizon services resolve to the address the peer can reach
+type RoutedAnswers string
+
+// IsValid returns true if the routed answers mode is supported
+func (r RoutedAnswers) IsValid() bool {
+	switch r {
+	case RoutedAnswersDisabled, RoutedAnswersPrefer, RoutedAnswersOnly:
+		return true
+	default:
+		return false
+	}
+}
+
 // String returns nameserver type string
 func (n NameServerType) String() string {
 	switch n {
@@ -69,6 +92,12 @@ type NameServerGroup struct {
 	Enabled bool
 	// SearchDomainsEnabled indicates whether to add match domains to search domains list or not
 	SearchDomainsEnabled bool
+	// RoutedAnswers indicates how the answers are adapted to the routes of the peer
+	RoutedAnswers RoutedAnswers
+	// ForwarderPeer is the ID of the routing peer serving the domains of the group with its DNS forwarder. The other
+	// peers query the forwarder through the tunnel instead of the nameservers, which are only reachable by the
+	// routing peer. Empty if the peers query the nameservers directly.
+	ForwarderPeer string
 }
 
 // NameServer represents a DNS nameserver
@@ -144,6 +173,8 @@ func (g *NameServerGroup) Copy() *NameServerGroup {
 		Primary:              g.Primary,
 		Domains:              make([]string, len(g.Domains)),
 		SearchDomainsEnabled: g.SearchDomainsEnabled,
+		RoutedAnswers:        g.RoutedAnswers,
+		ForwarderPeer:        g.ForwarderPeer,
 	}
 
 	copy(nsGroup.NameServers, g.NameServers)
@@ -160,6 +191,8 @@ func (g *NameServerGroup) IsEqual(other *NameServerGroup) bool {
 		other.Description == g.Description &&
 		other.Primary == g.Primary &&
 		other.SearchDomainsEnabled == g.SearchDomainsEnabled &&
+		other.RoutedAnswers == g.RoutedAnswers &&
+		other.ForwarderPeer == g.ForwarderPeer &&
 		compareNameServerList(g.NameServers, other.NameServers) &&
 		compareGroupsList(g.Groups, other.Groups) &&
 		compareGroupsList(g.Domains, other.Domains)
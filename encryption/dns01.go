@@ -0,0 +1,134 @@
+package encryption
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/caddyserver/certmagic"
+	"github.com/libdns/route53"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/acme"
+)
+
+const (
+	// DNSProviderRoute53 solves the DNS-01 challenge with AWS Route 53, see Route53TLS for its configuration
+	DNSProviderRoute53 = "route53"
+	// DNSProviderRFC2136 solves the DNS-01 challenge with dynamic DNS updates, see RFC2136Provider for its configuration
+	DNSProviderRFC2136 = "rfc2136"
+)
+
+// DNSProviderFactory creates the DNS provider publishing the ACME DNS-01 challenge records.
+// The providers read their credentials from the environment.
+type DNSProviderFactory func() (certmagic.DNSProvider, error)
+
+var (
+	dnsProvidersMu sync.RWMutex
+	dnsProviders   = map[string]DNSProviderFactory{
+		DNSProviderRoute53: func() (certmagic.DNSProvider, error) {
+			return &route53.Provider{}, nil
+		},
+		DNSProviderRFC2136: newRFC2136ProviderFromEnv,
+	}
+)
+
+// RegisterDNSProvider makes the DNS provider available to the DNS-01 challenge under the name,
+// a provider registered with the same name is replaced
+func RegisterDNSProvider(name string, factory DNSProviderFactory) {
+	dnsProvidersMu.Lock()
+	defer dnsProvidersMu.Unlock()
+	dnsProviders[name] = factory
+}
+
+// DNSProviders returns the names of the DNS providers available to the DNS-01 challenge
+func DNSProviders() []string {
+	dnsProvidersMu.RLock()
+	defer dnsProvidersMu.RUnlock()
+
+	names := make([]string, 0, len(dnsProviders))
+	for name := range dnsProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func newDNSProvider(name string) (certmagic.DNSProvider, error) {
+	dnsProvidersMu.RLock()
+	factory, ok := dnsProviders[name]
+	dnsProvidersMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unsupported DNS provider %q, supported providers: %v", name, DNSProviders())
+	}
+
+	provider, err := factory()
+	if err != nil {
+		return nil, fmt.Errorf("create DNS provider %s: %w", name, err)
+	}
+
+	return provider, nil
+}
+
+// DNS01TLS obtains and renews Let's Encrypt certificates with the ACME DNS-01 challenge. The server doesn't have to
+// be reachable from the internet on port 80 or 443, so it also works behind restrictive firewalls and for
+// internal-only hostnames.
+type DNS01TLS struct {
+	DataDir string
+	Email   string
+	Domains []string
+	CA      string
+	// Provider is the name of the DNS provider publishing the challenge records, e.g. route53 or rfc2136
+	Provider string
+}
+
+// GetCertificate obtains the certificates of the domains and returns the TLS config serving them
+func (d *DNS01TLS) GetCertificate() (*tls.Config, error) {
+	if len(d.Domains) == 0 {
+		return nil, fmt.Errorf("no domains provided")
+	}
+
+	provider, err := newDNSProvider(d.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	certmagic.Default.Logger = logger()
+	certmagic.Default.Storage = &certmagic.FileStorage{Path: d.DataDir}
+	certmagic.DefaultACME.Agreed = true
+	if d.Email != "" {
+		certmagic.DefaultACME.Email = d.Email
+	} else {
+		certmagic.DefaultACME.Email = emailFromDomain(d.Domains[0])
+	}
+
+	if d.CA == "" {
+		certmagic.DefaultACME.CA = certmagic.LetsEncryptProductionCA
+	} else {
+		certmagic.DefaultACME.CA = d.CA
+	}
+
+	certmagic.DefaultACME.DNS01Solver = &certmagic.DNS01Solver{
+		DNSManager: certmagic.DNSManager{
+			DNSProvider: provider,
+		},
+	}
+
+	log.Infof("running with Let's Encrypt DNS-01 challenge using %s (%s). Certs will be stored in %s", d.Provider, d.Domains, d.DataDir)
+
+	cm := certmagic.NewDefault()
+	if err := cm.ManageSync(context.Background(), d.Domains); err != nil {
+		log.Errorf("failed to manage certificate: %v", err)
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		GetCertificate: cm.GetCertificate,
+		NextProtos:     []string{"h2", "http/1.1", acme.ALPNProto},
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,474 @@
+package encryption
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"time"
+
+	pb "github.com/golang/protobuf/proto" //nolint
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+)
+
+// The ratcheting scheme protects the messages exchanged through the Signal Exchange against the leak of the
+// WireGuard keys. Each peer holds an ephemeral X25519 key that is replaced every EphemeralKeyLifetime, the
+// previous private keys are erased shortly after. The messages are encrypted with the keys of a chain derived from
+// the Diffie-Hellman of the ephemeral keys mixed with the one of the WireGuard keys, the chain advances with every
+// message and the used keys are dropped. The captured messages can't be decrypted with the WireGuard keys alone and
+// the WireGuard keys are still required to impersonate a peer.
+//
+// The peers advertise their ephemeral key in the messages encrypted with the WireGuard keys, the ratcheting is used
+// once the remote peer advertised its key. The peers not supporting the scheme never advertise a key and keep
+// receiving messages encrypted with the WireGuard keys.
+//
+// A peer erases its private keys as it keeps rotating them for the other peers, the key of a peer learned before an
+// idle period may be gone. The messages fall back to the WireGuard keys, advertising the ephemeral key again, once
+// the remote key is older than remoteKeyLifetime, until the remote peer answers with its current key. A peer failing
+// to decrypt a message, e.g. after a restart, advertises its current key in a message encrypted with the WireGuard
+// keys. The failed message isn't authenticated and never drops the session, the sender moves to the advertised key
+// once it authenticated the advertisement.
+
+const (
+	// RatchetKeySize is the size of the ephemeral public keys advertised by the peers
+	RatchetKeySize = 32
+	// RatchetHeaderSize is the size of the header of the ratcheted messages: the ephemeral key of the sender, the
+	// ephemeral key of the receiver and the message counter
+	RatchetHeaderSize = 2*RatchetKeySize + 4
+
+	// EphemeralKeyLifetime is the period an ephemeral key is used to send messages before it is replaced
+	EphemeralKeyLifetime = 10 * time.Minute
+	// ephemeralKeysKept is the number of ephemeral keys kept to decrypt the messages in flight, including the
+	// current one. Older private keys are erased.
+	ephemeralKeysKept = 3
+	// remoteKeyLifetime is the period an ephemeral key of a remote peer is used after it was learned. The remote peer
+	// keeps the private key for at least this period: the key was current when learned and it's erased once
+	// ephemeralKeysKept newer keys were created, each used for EphemeralKeyLifetime.
+	remoteKeyLifetime = (ephemeralKeysKept - 1) * EphemeralKeyLifetime
+	// maxSkippedKeys limits the keys kept for the messages received out of order
+	maxSkippedKeys = 256
+	// maxReceiveChains limits the chains kept per remote peer
+	maxReceiveChains = 6
+
+	ratchetInfo = "netbird signal ratchet v1"
+)
+
+var (
+	// ErrRatchetUnsupported is returned when the remote peer didn't advertise an ephemeral key, the message must be
+	// encrypted with the WireGuard keys
+	ErrRatchetUnsupported = errors.New("remote peer doesn't support the ratcheting encryption")
+	// ErrRemoteKeyExpired is returned when the ephemeral key of the remote peer may have been erased, the message must
+	// be encrypted with the WireGuard keys advertising the ephemeral key of the peer until the remote peer answers
+	ErrRemoteKeyExpired = errors.New("ephemeral key of the remote peer expired")
+	// ErrEphemeralKeyExpired is returned when a message was encrypted for an ephemeral key of the peer that was
+	// erased, the remote peer must learn the current key
+	ErrEphemeralKeyExpired = errors.New("message was encrypted for an expired ephemeral key")
+
+	messageKeyLabel = []byte{0x01}
+	chainKeyLabel   = []byte{0x02}
+)
+
+type ephemeralKey struct {
+	private   [32]byte
+	public    [32]byte
+	createdAt time.Time
+}
+
+// chainID identifies a chain by the ephemeral keys of the sender and the receiver
+type chainID struct {
+	sender   [32]byte
+	receiver [32]byte
+}
+
+type sendChain struct {
+	id      chainID
+	key     [32]byte
+	counter uint32
+}
+
+type receiveChain struct {
+	id      chainID
+	key     [32]byte
+	next    uint32
+	skipped map[uint32][32]byte
+}
+
+type ratchetSession struct {
+	// remoteKey is the latest authenticated ephemeral key of the remote peer
+	remoteKey [32]byte
+	// remoteKeyAt is the time the remote key was learned
+	remoteKeyAt time.Time
+	send        *sendChain
+	// receive are the chains of the remote peer, the most recent last
+	receive []*receiveChain
+}
+
+// Ratchet encrypts and decrypts the messages exchanged with the remote peers with the ratcheting scheme. It is safe
+// for concurrent use.
+type Ratchet struct {
+	mu         sync.Mutex
+	privateKey wgtypes.Key
+	publicKey  wgtypes.Key
+	// ephemeral are the ephemeral keys of the peer, the current one first
+	ephemeral []*ephemeralKey
+	sessions  map[wgtypes.Key]*ratchetSession
+	now       func() time.Time
+}
+
+// NewRatchet creates a Ratchet of the peer with the WireGuard private key
+func NewRatchet(privateKey wgtypes.Key) *Ratchet {
+	return &Ratchet{
+		privateKey: privateKey,
+		publicKey:  privateKey.PublicKey(),
+		sessions:   make(map[wgtypes.Key]*ratchetSession),
+		now:        time.Now,
+	}
+}
+
+// PublicKey returns the current ephemeral public key advertised to the remote peers
+func (r *Ratchet) PublicKey() ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, err := r.currentKey()
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), key.public[:]...), nil
+}
+
+// SetRemoteKey sets the ephemeral key advertised by the remote peer in a message encrypted with the WireGuard keys.
+// An empty key tells the remote peer doesn't support the scheme, e.g. it was downgraded, the session is dropped.
+func (r *Ratchet) SetRemoteKey(remotePubKey wgtypes.Key, ephemeralKey []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(ephemeralKey) == 0 {
+		delete(r.sessions, remotePubKey)
+		return nil
+	}
+	if len(ephemeralKey) != RatchetKeySize {
+		return fmt.Errorf("invalid ephemeral key length %d", len(ephemeralKey))
+	}
+
+	session, ok := r.sessions[remotePubKey]
+	if !ok {
+		session = &ratchetSession{}
+		r.sessions[remotePubKey] = session
+	}
+	copy(session.remoteKey[:], ephemeralKey)
+	session.remoteKeyAt = r.now()
+	return nil
+}
+
+// EncryptMessage encrypts the protobuf message for the remote peer, it returns the header and the encrypted body.
+// ErrRatchetUnsupported is returned if the remote peer didn't advertise an ephemeral key.
+func (r *Ratchet) EncryptMessage(remotePubKey wgtypes.Key, message pb.Message) ([]byte, []byte, error) {
+	plaintext, err := pb.Marshal(message)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshal message: %w", err)
+	}
+	return r.Encrypt(remotePubKey, plaintext)
+}
+
+// DecryptMessage decrypts the body of a ratcheted message from the remote peer into the protobuf message
+func (r *Ratchet) DecryptMessage(remotePubKey wgtypes.Key, header, body []byte, message pb.Message) error {
+	plaintext, err := r.Decrypt(remotePubKey, header, body)
+	if err != nil {
+		return err
+	}
+	if err := pb.Unmarshal(plaintext, message); err != nil {
+		return fmt.Errorf("unmarshal message: %w", err)
+	}
+	return nil
+}
+
+// Encrypt encrypts the plaintext for the remote peer with the next key of the sending chain, it returns the header
+// and the ciphertext. ErrRemoteKeyExpired is returned if the remote key wasn't refreshed for remoteKeyLifetime.
+func (r *Ratchet) Encrypt(remotePubKey wgtypes.Key, plaintext []byte) ([]byte, []byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, ok := r.sessions[remotePubKey]
+	if !ok {
+		return nil, nil, ErrRatchetUnsupported
+	}
+	if r.now().Sub(session.remoteKeyAt) >= remoteKeyLifetime {
+		return nil, nil, ErrRemoteKeyExpired
+	}
+
+	local, err := r.currentKey()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if session.send != nil && session.send.id.sender == local.public && session.send.counter == math.MaxUint32 {
+		// the chain is exhausted, it continues with a new ephemeral key
+		local.createdAt = time.Time{}
+		if local, err = r.currentKey(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	id := chainID{sender: local.public, receiver: session.remoteKey}
+	if session.send == nil || session.send.id != id {
+		key, err := r.chainKey(local, remotePubKey, id, true)
+		if err != nil {
+			return nil, nil, err
+		}
+		session.send = &sendChain{id: id, key: key}
+	}
+
+	messageKey := advance(&session.send.key)
+	header := encodeHeader(id, session.send.counter)
+	session.send.counter++
+
+	ciphertext, err := seal(messageKey, header, plaintext)
+	if err != nil {
+		return nil, nil, err
+	}
+	return header, ciphertext, nil
+}
+
+// Decrypt decrypts a ratcheted message from the remote peer. The ephemeral key of the remote peer in the header is
+// used for the next messages once the message is authenticated.
+func (r *Ratchet) Decrypt(remotePubKey wgtypes.Key, header, ciphertext []byte) ([]byte, error) {
+	id, counter, err := decodeHeader(header)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	local := r.ephemeralKey(id.receiver)
+	if local == nil {
+		return nil, fmt.Errorf("message from peer %s: %w", remotePubKey, ErrEphemeralKeyExpired)
+	}
+
+	session := r.sessions[remotePubKey]
+	var chain *receiveChain
+	if session != nil {
+		chain = session.receiveChain(id)
+	}
+	if chain == nil {
+		key, err := r.chainKey(local, remotePubKey, id, false)
+		if err != nil {
+			return nil, err
+		}
+		chain = &receiveChain{id: id, key: key, skipped: make(map[uint32][32]byte)}
+	}
+
+	// the chain is updated on a copy and kept only if the message is authentic, a forged message mustn't advance it
+	next := chain.clone()
+	messageKey, err := next.messageKey(counter)
+	if err != nil {
+		return nil, fmt.Errorf("message from peer %s: %w", remotePubKey, err)
+	}
+
+	plaintext, err := open(messageKey, header, ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt message from peer %s", remotePubKey)
+	}
+
+	if session == nil {
+		session = &ratchetSession{}
+		r.sessions[remotePubKey] = session
+	}
+	session.storeReceiveChain(next)
+	if session.isLatest(id) {
+		session.remoteKey = id.sender
+		session.remoteKeyAt = r.now()
+	}
+
+	return plaintext, nil
+}
+
+// currentKey returns the current ephemeral key, it is replaced once expired and the oldest private keys are erased
+func (r *Ratchet) currentKey() (*ephemeralKey, error) {
+	now := r.now()
+	if len(r.ephemeral) > 0 && now.Sub(r.ephemeral[0].createdAt) < EphemeralKeyLifetime {
+		return r.ephemeral[0], nil
+	}
+
+	key := &ephemeralKey{createdAt: now}
+	if _, err := io.ReadFull(rand.Reader, key.private[:]); err != nil {
+		return nil, fmt.Errorf("generate ephemeral key: %w", err)
+	}
+	public, err := curve25519.X25519(key.private[:], curve25519.Basepoint)
+	if err != nil {
+		return nil, fmt.Errorf("derive ephemeral public key: %w", err)
+	}
+	copy(key.public[:], public)
+
+	r.ephemeral = append([]*ephemeralKey{key}, r.ephemeral...)
+	for len(r.ephemeral) > ephemeralKeysKept {
+		expired := r.ephemeral[len(r.ephemeral)-1]
+		clear(expired.private[:])
+		r.ephemeral = r.ephemeral[:len(r.ephemeral)-1]
+	}
+	return key, nil
+}
+
+func (r *Ratchet) ephemeralKey(public [32]byte) *ephemeralKey {
+	for _, key := range r.ephemeral {
+		if key.public == public {
+			return key
+		}
+	}
+	return nil
+}
+
+// chainKey derives the first key of the chain identified by the ephemeral keys, sending tells whether the peer is
+// the sender of the chain
+func (r *Ratchet) chainKey(local *ephemeralKey, remotePubKey wgtypes.Key, id chainID, sending bool) ([32]byte, error) {
+	var key [32]byte
+
+	senderKey, receiverKey, remoteEphemeral := r.publicKey, remotePubKey, id.receiver
+	if !sending {
+		senderKey, receiverKey, remoteEphemeral = remotePubKey, r.publicKey, id.sender
+	}
+
+	ephemeralSecret, err := curve25519.X25519(local.private[:], remoteEphemeral[:])
+	if err != nil {
+		return key, fmt.Errorf("ephemeral key agreement: %w", err)
+	}
+	staticSecret, err := curve25519.X25519(r.privateKey[:], remotePubKey[:])
+	if err != nil {
+		return key, fmt.Errorf("static key agreement: %w", err)
+	}
+
+	secret := append(ephemeralSecret, staticSecret...)
+	info := make([]byte, 0, len(ratchetInfo)+4*32)
+	info = append(info, ratchetInfo...)
+	info = append(info, senderKey[:]...)
+	info = append(info, receiverKey[:]...)
+	info = append(info, id.sender[:]...)
+	info = append(info, id.receiver[:]...)
+
+	_, err = io.ReadFull(hkdf.New(sha256.New, secret, nil, info), key[:])
+	clear(secret)
+	return key, err
+}
+
+func (s *ratchetSession) receiveChain(id chainID) *receiveChain {
+	for _, chain := range s.receive {
+		if chain.id == id {
+			return chain
+		}
+	}
+	return nil
+}
+
+func (s *ratchetSession) storeReceiveChain(chain *receiveChain) {
+	for i, c := range s.receive {
+		if c.id == chain.id {
+			s.receive[i] = chain
+			return
+		}
+	}
+	s.receive = append(s.receive, chain)
+	if len(s.receive) > maxReceiveChains {
+		s.receive = s.receive[1:]
+	}
+}
+
+// isLatest returns whether the chain is the most recent one of the remote peer, the messages of the previous
+// chains still in flight mustn't roll the ephemeral key of the remote peer back
+func (s *ratchetSession) isLatest(id chainID) bool {
+	return len(s.receive) > 0 && s.receive[len(s.receive)-1].id == id
+}
+
+func (c *receiveChain) clone() *receiveChain {
+	skipped := make(map[uint32][32]byte, len(c.skipped))
+	for counter, key := range c.skipped {
+		skipped[counter] = key
+	}
+	return &receiveChain{id: c.id, key: c.key, next: c.next, skipped: skipped}
+}
+
+// messageKey returns the key of the message with the counter, the keys of the skipped messages are kept for the
+// messages received out of order and each key is returned once
+func (c *receiveChain) messageKey(counter uint32) ([32]byte, error) {
+	if counter < c.next {
+		key, ok := c.skipped[counter]
+		if !ok {
+			return key, errors.New("message was already received")
+		}
+		delete(c.skipped, counter)
+		return key, nil
+	}
+
+	if counter-c.next > maxSkippedKeys {
+		return [32]byte{}, fmt.Errorf("too many skipped messages: %d", counter-c.next)
+	}
+	for c.next < counter {
+		c.skipped[c.next] = advance(&c.key)
+		c.next++
+	}
+	for len(c.skipped) > maxSkippedKeys {
+		oldest := c.next
+		for skipped := range c.skipped {
+			oldest = min(oldest, skipped)
+		}
+		delete(c.skipped, oldest)
+	}
+
+	c.next++
+	return advance(&c.key), nil
+}
+
+// advance returns the message key of the chain key and replaces the chain key with the next one
+func advance(chainKey *[32]byte) [32]byte {
+	var messageKey [32]byte
+	mac := hmac.New(sha256.New, chainKey[:])
+	mac.Write(messageKeyLabel)
+	copy(messageKey[:], mac.Sum(nil))
+
+	mac = hmac.New(sha256.New, chainKey[:])
+	mac.Write(chainKeyLabel)
+	copy(chainKey[:], mac.Sum(nil))
+	return messageKey
+}
+
+func encodeHeader(id chainID, counter uint32) []byte {
+	header := make([]byte, 0, RatchetHeaderSize)
+	header = append(header, id.sender[:]...)
+	header = append(header, id.receiver[:]...)
+	return binary.BigEndian.AppendUint32(header, counter)
+}
+
+func decodeHeader(header []byte) (chainID, uint32, error) {
+	var id chainID
+	if len(header) != RatchetHeaderSize {
+		return id, 0, fmt.Errorf("invalid ratchet header length %d", len(header))
+	}
+	copy(id.sender[:], header[:RatchetKeySize])
+	copy(id.receiver[:], header[RatchetKeySize:2*RatchetKeySize])
+	return id, binary.BigEndian.Uint32(header[2*RatchetKeySize:]), nil
+}
+
+// seal encrypts the plaintext authenticating the header, each message key is used once so the nonce is fixed
+func seal(key [32]byte, header, plaintext []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.New(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(nil, make([]byte, aead.NonceSize()), plaintext, header), nil
+}
+
+func open(key [32]byte, header, ciphertext []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.New(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return aead.Open(nil, make([]byte, aead.NonceSize()), ciphertext, header)
+}
@@ -0,0 +1,191 @@
+package encryption
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+)
+
+type testPeer struct {
+	key     wgtypes.Key
+	ratchet *Ratchet
+	now     time.Time
+}
+
+func newTestPeer(t *testing.T) *testPeer {
+	t.Helper()
+
+	key, err := wgtypes.GeneratePrivateKey()
+	require.NoError(t, err)
+
+	peer := &testPeer{key: key, ratchet: NewRatchet(key), now: time.Now()}
+	peer.ratchet.now = func() time.Time { return peer.now }
+	return peer
+}
+
+// advertise passes the ephemeral key of the peer to the remote one like a message encrypted with the WireGuard keys
+func (p *testPeer) advertise(t *testing.T, remote *testPeer) {
+	t.Helper()
+
+	key, err := p.ratchet.PublicKey()
+	require.NoError(t, err)
+	require.NoError(t, remote.ratchet.SetRemoteKey(p.key.PublicKey(), key))
+}
+
+func (p *testPeer) send(t *testing.T, remote *testPeer, msg string) ([]byte, []byte) {
+	t.Helper()
+
+	header, ciphertext, err := p.ratchet.Encrypt(remote.key.PublicKey(), []byte(msg))
+	require.NoError(t, err)
+	return header, ciphertext
+}
+
+func (p *testPeer) receive(remote *testPeer, header, ciphertext []byte) (string, error) {
+	plaintext, err := p.ratchet.Decrypt(remote.key.PublicKey(), header, ciphertext)
+	return string(plaintext), err
+}
+
+func TestRatchet_RequiresRemoteKey(t *testing.T) {
+	alice, bob := newTestPeer(t), newTestPeer(t)
+
+	_, _, err := alice.ratchet.Encrypt(bob.key.PublicKey(), []byte("offer"))
+	assert.ErrorIs(t, err, ErrRatchetUnsupported)
+
+	bob.advertise(t, alice)
+	_, _, err = alice.ratchet.Encrypt(bob.key.PublicKey(), []byte("offer"))
+	assert.NoError(t, err)
+
+	require.NoError(t, alice.ratchet.SetRemoteKey(bob.key.PublicKey(), nil))
+	_, _, err = alice.ratchet.Encrypt(bob.key.PublicKey(), []byte("offer"))
+	assert.ErrorIs(t, err, ErrRatchetUnsupported, "the session should be dropped when the remote peer stops advertising a key")
+}
+
+func TestRatchet_Exchange(t *testing.T) {
+	alice, bob := newTestPeer(t), newTestPeer(t)
+	bob.advertise(t, alice)
+
+	header, ciphertext := alice.send(t, bob, "offer")
+	msg, err := bob.receive(alice, header, ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "offer", msg)
+
+	// bob learned the ephemeral key of alice from the authenticated message
+	header, ciphertext = bob.send(t, alice, "answer")
+	msg, err = alice.receive(bob, header, ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "answer", msg)
+
+	_, err = bob.receive(alice, header, ciphertext)
+	assert.Error(t, err, "a message should only be decrypted by its receiver")
+}
+
+func TestRatchet_OutOfOrderAndReplay(t *testing.T) {
+	alice, bob := newTestPeer(t), newTestPeer(t)
+	bob.advertise(t, alice)
+
+	type message struct{ header, ciphertext []byte }
+	var messages []message
+	for _, msg := range []string{"candidate-1", "candidate-2", "candidate-3"} {
+		header, ciphertext := alice.send(t, bob, msg)
+		messages = append(messages, message{header, ciphertext})
+	}
+
+	for _, i := range []int{2, 0, 1} {
+		msg, err := bob.receive(alice, messages[i].header, messages[i].ciphertext)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"candidate-1", "candidate-2", "candidate-3"}[i], msg)
+	}
+
+	_, err := bob.receive(alice, messages[1].header, messages[1].ciphertext)
+	assert.Error(t, err, "a replayed message should be rejected")
+}
+
+func TestRatchet_RejectsForgedMessages(t *testing.T) {
+	alice, bob, mallory := newTestPeer(t), newTestPeer(t), newTestPeer(t)
+	bob.advertise(t, alice)
+	bob.advertise(t, mallory)
+
+	// mallory knows the ephemeral key of bob but not the WireGuard key of alice
+	header, ciphertext := mallory.send(t, bob, "offer")
+	_, err := bob.receive(alice, header, ciphertext)
+	assert.Error(t, err)
+
+	header, ciphertext = alice.send(t, bob, "offer")
+	ciphertext[0] ^= 0xff
+	_, err = bob.receive(alice, header, ciphertext)
+	assert.Error(t, err)
+
+	ciphertext[0] ^= 0xff
+	msg, err := bob.receive(alice, header, ciphertext)
+	require.NoError(t, err, "a forged message shouldn't advance the chain")
+	assert.Equal(t, "offer", msg)
+}
+
+func TestRatchet_RotatesEphemeralKeys(t *testing.T) {
+	alice, bob := newTestPeer(t), newTestPeer(t)
+	bob.advertise(t, alice)
+
+	header, ciphertext := alice.send(t, bob, "offer")
+	_, err := bob.receive(alice, header, ciphertext)
+	require.NoError(t, err)
+	first := header[:RatchetKeySize]
+
+	alice.now = alice.now.Add(EphemeralKeyLifetime)
+	header, ciphertext = alice.send(t, bob, "candidate")
+	assert.NotEqual(t, first, header[:RatchetKeySize], "the ephemeral key should be replaced once expired")
+	msg, err := bob.receive(alice, header, ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "candidate", msg)
+
+	// the messages in flight for the previous keys are decrypted until the keys are erased
+	bob.now = bob.now.Add(EphemeralKeyLifetime)
+	inFlightHeader, inFlightCiphertext := alice.send(t, bob, "in flight")
+	for i := 0; i < ephemeralKeysKept; i++ {
+		bob.now = bob.now.Add(EphemeralKeyLifetime)
+		_, err = bob.ratchet.PublicKey()
+		require.NoError(t, err)
+	}
+	_, err = bob.receive(alice, inFlightHeader, inFlightCiphertext)
+	assert.ErrorIs(t, err, ErrEphemeralKeyExpired, "the messages for an erased ephemeral key shouldn't be decrypted")
+}
+
+func TestRatchet_IdlePastRotation(t *testing.T) {
+	alice, bob := newTestPeer(t), newTestPeer(t)
+	bob.advertise(t, alice)
+
+	header, ciphertext := alice.send(t, bob, "offer")
+	_, err := bob.receive(alice, header, ciphertext)
+	require.NoError(t, err)
+	header, ciphertext = bob.send(t, alice, "answer")
+	_, err = alice.receive(bob, header, ciphertext)
+	require.NoError(t, err)
+
+	// alice and bob idle while bob keeps rotating its keys for the other peers, the key alice knows is erased
+	idle := time.Duration(ephemeralKeysKept+1) * EphemeralKeyLifetime
+	for i := 0; i <= ephemeralKeysKept; i++ {
+		bob.now = bob.now.Add(EphemeralKeyLifetime)
+		_, err = bob.ratchet.PublicKey()
+		require.NoError(t, err)
+	}
+	alice.now = alice.now.Add(idle)
+
+	_, _, err = alice.ratchet.Encrypt(bob.key.PublicKey(), []byte("offer"))
+	require.ErrorIs(t, err, ErrRemoteKeyExpired, "alice shouldn't encrypt for a key bob may have erased")
+	_, _, err = bob.ratchet.Encrypt(alice.key.PublicKey(), []byte("offer"))
+	require.ErrorIs(t, err, ErrRemoteKeyExpired)
+
+	// alice falls back to the WireGuard keys advertising its current key, bob answers with its current key
+	alice.advertise(t, bob)
+	header, ciphertext = bob.send(t, alice, "answer")
+	msg, err := alice.receive(bob, header, ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "answer", msg)
+
+	header, ciphertext = alice.send(t, bob, "candidate")
+	msg, err = bob.receive(alice, header, ciphertext)
+	require.NoError(t, err, "the ratcheting should resume with the fresh keys")
+	assert.Equal(t, "candidate", msg)
+}
@@ -0,0 +1,143 @@
+package encryption
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/caddyserver/certmagic"
+	"github.com/libdns/libdns"
+	"github.com/miekg/dns"
+)
+
+const (
+	rfc2136NameserverEnv    = "NB_ACME_RFC2136_NAMESERVER"
+	rfc2136TSIGKeyEnv       = "NB_ACME_RFC2136_TSIG_KEY"
+	rfc2136TSIGSecretEnv    = "NB_ACME_RFC2136_TSIG_SECRET"
+	rfc2136TSIGAlgorithmEnv = "NB_ACME_RFC2136_TSIG_ALGORITHM"
+
+	rfc2136Timeout    = 10 * time.Second
+	rfc2136TSIGFudge  = 300
+	rfc2136DefaultTTL = 60
+)
+
+// RFC2136Provider publishes the DNS-01 challenge records with dynamic DNS updates (RFC 2136) signed with a TSIG key,
+// which is supported by BIND, Knot, PowerDNS and most of the self-hosted DNS servers of internal zones.
+// env variables: NB_ACME_RFC2136_NAMESERVER, NB_ACME_RFC2136_TSIG_KEY, NB_ACME_RFC2136_TSIG_SECRET,
+// NB_ACME_RFC2136_TSIG_ALGORITHM (defaults to hmac-sha256)
+type RFC2136Provider struct {
+	// Nameserver is the address of the primary DNS server of the zone, the port defaults to 53
+	Nameserver string
+	// TSIGKey is the name of the key signing the updates, the updates aren't signed if it is empty
+	TSIGKey string
+	// TSIGSecret is the base64 encoded secret of the key
+	TSIGSecret string
+	// TSIGAlgorithm is the algorithm of the key, e.g. hmac-sha256
+	TSIGAlgorithm string
+}
+
+func newRFC2136ProviderFromEnv() (certmagic.DNSProvider, error) {
+	provider := &RFC2136Provider{
+		Nameserver:    os.Getenv(rfc2136NameserverEnv),
+		TSIGKey:       os.Getenv(rfc2136TSIGKeyEnv),
+		TSIGSecret:    os.Getenv(rfc2136TSIGSecretEnv),
+		TSIGAlgorithm: os.Getenv(rfc2136TSIGAlgorithmEnv),
+	}
+
+	if provider.Nameserver == "" {
+		return nil, fmt.Errorf("%s is not set", rfc2136NameserverEnv)
+	}
+	if provider.TSIGKey != "" && provider.TSIGSecret == "" {
+		return nil, fmt.Errorf("%s is required with %s", rfc2136TSIGSecretEnv, rfc2136TSIGKeyEnv)
+	}
+
+	return provider, nil
+}
+
+// AppendRecords adds the TXT records to the zone
+func (p *RFC2136Provider) AppendRecords(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, error) {
+	if err := p.update(ctx, zone, records, true); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// DeleteRecords removes the TXT records from the zone
+func (p *RFC2136Provider) DeleteRecords(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, error) {
+	if err := p.update(ctx, zone, records, false); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (p *RFC2136Provider) update(ctx context.Context, zone string, records []libdns.Record, insert bool) error {
+	zone = dns.Fqdn(zone)
+
+	rrs := make([]dns.RR, 0, len(records))
+	for _, record := range records {
+		if record.Type != "TXT" {
+			return fmt.Errorf("unsupported record type %s, only TXT records are supported", record.Type)
+		}
+
+		ttl := uint32(record.TTL.Seconds())
+		if ttl == 0 {
+			ttl = rfc2136DefaultTTL
+		}
+
+		rrs = append(rrs, &dns.TXT{
+			Hdr: dns.RR_Header{Name: absoluteName(record.Name, zone), Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: ttl},
+			Txt: []string{record.Value},
+		})
+	}
+
+	msg := new(dns.Msg)
+	msg.SetUpdate(zone)
+	if insert {
+		msg.Insert(rrs)
+	} else {
+		msg.Remove(rrs)
+	}
+
+	client := &dns.Client{Net: "tcp", Timeout: rfc2136Timeout}
+	if p.TSIGKey != "" {
+		key := dns.Fqdn(p.TSIGKey)
+		algorithm := dns.HmacSHA256
+		if p.TSIGAlgorithm != "" {
+			algorithm = dns.Fqdn(p.TSIGAlgorithm)
+		}
+		client.TsigSecret = map[string]string{key: p.TSIGSecret}
+		msg.SetTsig(key, algorithm, rfc2136TSIGFudge, time.Now().Unix())
+	}
+
+	resp, _, err := client.ExchangeContext(ctx, msg, p.nameserverAddress())
+	if err != nil {
+		return fmt.Errorf("send DNS update to %s: %w", p.Nameserver, err)
+	}
+	if resp.Rcode != dns.RcodeSuccess {
+		return fmt.Errorf("DNS update of zone %s rejected by %s: %s", zone, p.Nameserver, dns.RcodeToString[resp.Rcode])
+	}
+
+	return nil
+}
+
+func (p *RFC2136Provider) nameserverAddress() string {
+	if _, _, err := net.SplitHostPort(p.Nameserver); err == nil {
+		return p.Nameserver
+	}
+	return net.JoinHostPort(strings.Trim(p.Nameserver, "[]"), "53")
+}
+
+// absoluteName returns the FQDN of the record name, which is relative to the zone
+func absoluteName(name, zone string) string {
+	switch {
+	case name == "" || name == "@":
+		return zone
+	case strings.HasSuffix(name, "."):
+		return name
+	default:
+		return name + "." + zone
+	}
+}
@@ -0,0 +1,145 @@
+package encryption
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testTSIGKey    = "netbird-acme."
+	testTSIGSecret = "c2VjcmV0LWtleS1mb3ItdGVzdHM="
+)
+
+type testDNSServer struct {
+	mu      sync.Mutex
+	updates []*dns.Msg
+}
+
+func (s *testDNSServer) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
+	resp := new(dns.Msg)
+	resp.SetReply(r)
+
+	if r.IsTsig() == nil || w.TsigStatus() != nil {
+		resp.Rcode = dns.RcodeRefused
+	} else {
+		s.mu.Lock()
+		s.updates = append(s.updates, r)
+		s.mu.Unlock()
+		resp.SetTsig(testTSIGKey, dns.HmacSHA256, rfc2136TSIGFudge, time.Now().Unix())
+	}
+
+	_ = w.WriteMsg(resp)
+}
+
+// acceptUpdates accepts the dynamic updates the default accept function of the server rejects with NOTIMP.
+func acceptUpdates(dh dns.Header) dns.MsgAcceptAction {
+	if opcode := int(dh.Bits>>11) & 0xF; opcode == dns.OpcodeUpdate {
+		return dns.MsgAccept
+	}
+	return dns.DefaultMsgAcceptFunc(dh)
+}
+
+func (s *testDNSServer) received() []*dns.Msg {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.updates
+}
+
+func startTestDNSServer(t *testing.T) (*testDNSServer, string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	handler := &testDNSServer{}
+	started := make(chan struct{})
+	server := &dns.Server{
+		Listener:          listener,
+		Net:               "tcp",
+		Handler:           handler,
+		TsigSecret:        map[string]string{testTSIGKey: testTSIGSecret},
+		MsgAcceptFunc:     acceptUpdates,
+		NotifyStartedFunc: func() { close(started) },
+	}
+
+	go func() {
+		_ = server.ActivateAndServe()
+	}()
+	t.Cleanup(func() { _ = server.Shutdown() })
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("DNS server didn't start")
+	}
+
+	return handler, listener.Addr().String()
+}
+
+func TestRFC2136Provider_Records(t *testing.T) {
+	server, address := startTestDNSServer(t)
+
+	provider := &RFC2136Provider{
+		Nameserver: address,
+		TSIGKey:    "netbird-acme",
+		TSIGSecret: testTSIGSecret,
+	}
+
+	records := []libdns.Record{{Type: "TXT", Name: "_acme-challenge.mgmt", Value: "token", TTL: 2 * time.Minute}}
+
+	_, err := provider.AppendRecords(context.Background(), "internal.example.com", records)
+	require.NoError(t, err)
+
+	_, err = provider.DeleteRecords(context.Background(), "internal.example.com.", records)
+	require.NoError(t, err)
+
+	updates := server.received()
+	require.Len(t, updates, 2)
+
+	for _, update := range updates {
+		require.Len(t, update.Question, 1)
+		assert.Equal(t, "internal.example.com.", update.Question[0].Name)
+		require.Len(t, update.Ns, 1)
+		assert.Equal(t, "_acme-challenge.mgmt.internal.example.com.", update.Ns[0].Header().Name)
+		assert.Equal(t, dns.TypeTXT, update.Ns[0].Header().Rrtype)
+	}
+
+	assert.Equal(t, uint16(dns.ClassINET), updates[0].Ns[0].Header().Class, "record should be added")
+	assert.Equal(t, uint16(dns.ClassNONE), updates[1].Ns[0].Header().Class, "record should be removed")
+}
+
+func TestRFC2136Provider_InvalidKey(t *testing.T) {
+	_, address := startTestDNSServer(t)
+
+	provider := &RFC2136Provider{
+		Nameserver: address,
+		TSIGKey:    "netbird-acme",
+		TSIGSecret: "d3Jvbmctc2VjcmV0",
+	}
+
+	_, err := provider.AppendRecords(context.Background(), "internal.example.com", []libdns.Record{{Type: "TXT", Name: "_acme-challenge", Value: "token"}})
+	require.Error(t, err)
+}
+
+func TestNewDNSProvider(t *testing.T) {
+	_, err := newDNSProvider("unknown")
+	require.Error(t, err)
+
+	_, err = newDNSProvider(DNSProviderRFC2136)
+	require.Error(t, err, "nameserver should be required")
+
+	t.Setenv(rfc2136NameserverEnv, "ns1.internal.example.com")
+	provider, err := newDNSProvider(DNSProviderRFC2136)
+	require.NoError(t, err)
+	assert.Equal(t, "ns1.internal.example.com:53", provider.(*RFC2136Provider).nameserverAddress())
+
+	assert.Contains(t, DNSProviders(), DNSProviderRoute53)
+}
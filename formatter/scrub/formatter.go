@@ -0,0 +1,111 @@
+package scrub
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/client/anonymize"
+)
+
+// maxMappings is the number of memorized addresses, domains and emails after which the anonymizer is replaced, so
+// long-running servers don't grow it without limit. The replacements are only consistent until then.
+const maxMappings = 100000
+
+// Options selects the personal data the formatter scrubs
+type Options struct {
+	IPs     bool
+	Domains bool
+	Emails  bool
+	// Level is the least verbose level that is scrubbed, entries at the level and the more verbose ones are scrubbed
+	Level logrus.Level
+}
+
+// Enabled returns true if any kind of personal data is scrubbed
+func (o Options) Enabled() bool {
+	return o.IPs || o.Domains || o.Emails
+}
+
+// ParseOptions parses the comma separated list of the scrubbed data, one or more of ip, domain and email, or all.
+// The level defaults to panic, scrubbing the entries of all levels.
+func ParseOptions(data, level string) (Options, error) {
+	options := Options{Level: logrus.PanicLevel}
+
+	for _, kind := range strings.Split(data, ",") {
+		switch strings.ToLower(strings.TrimSpace(kind)) {
+		case "":
+		case "ip":
+			options.IPs = true
+		case "domain":
+			options.Domains = true
+		case "email":
+			options.Emails = true
+		case "all":
+			options.IPs, options.Domains, options.Emails = true, true, true
+		default:
+			return Options{}, fmt.Errorf("unknown scrubbed data %q, expected ip, domain, email or all", kind)
+		}
+	}
+
+	if level != "" {
+		parsed, err := logrus.ParseLevel(level)
+		if err != nil {
+			return Options{}, fmt.Errorf("parse scrub level: %w", err)
+		}
+		options.Level = parsed
+	}
+
+	return options, nil
+}
+
+// Formatter scrubs IPs, domains and emails from the entries rendered by the wrapped formatter at write time.
+// Reoccurring values are replaced with the same anonymized value, like in the debug bundles.
+type Formatter struct {
+	formatter logrus.Formatter
+	options   Options
+
+	mu         sync.Mutex
+	anonymizer *anonymize.Anonymizer
+}
+
+// NewFormatter wraps the formatter with a Formatter scrubbing the data selected by the options
+func NewFormatter(formatter logrus.Formatter, options Options) *Formatter {
+	return &Formatter{
+		formatter:  formatter,
+		options:    options,
+		anonymizer: anonymize.NewAnonymizer(anonymize.DefaultAddresses()),
+	}
+}
+
+// Format renders the entry with the wrapped formatter and scrubs the output if the entry level is scrubbed
+func (f *Formatter) Format(entry *logrus.Entry) ([]byte, error) {
+	data, err := f.formatter.Format(entry)
+	if err != nil || entry.Level < f.options.Level {
+		return data, err
+	}
+	return []byte(f.Scrub(string(data))), nil
+}
+
+// Scrub replaces the personal data selected by the options in the text
+func (f *Formatter) Scrub(text string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.anonymizer.Len() > maxMappings {
+		f.anonymizer = anonymize.NewAnonymizer(anonymize.DefaultAddresses())
+	}
+
+	// emails go first, their domains would be replaced separately otherwise
+	if f.options.Emails {
+		text = f.anonymizer.AnonymizeEmails(text)
+	}
+	if f.options.Domains {
+		text = f.anonymizer.AnonymizeDomains(text)
+	}
+	if f.options.IPs {
+		text = f.anonymizer.AnonymizeIPs(text)
+	}
+	return text
+}
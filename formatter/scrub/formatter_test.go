@@ -0,0 +1,56 @@
+package scrub
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/formatter/txt"
+)
+
+func TestParseOptions(t *testing.T) {
+	options, err := ParseOptions("", "")
+	require.NoError(t, err)
+	assert.False(t, options.Enabled())
+
+	options, err = ParseOptions("ip, Email", "debug")
+	require.NoError(t, err)
+	assert.Equal(t, Options{IPs: true, Emails: true, Level: logrus.DebugLevel}, options)
+
+	options, err = ParseOptions("all", "")
+	require.NoError(t, err)
+	assert.Equal(t, Options{IPs: true, Domains: true, Emails: true, Level: logrus.PanicLevel}, options)
+
+	_, err = ParseOptions("ip,phone", "")
+	assert.Error(t, err)
+
+	_, err = ParseOptions("ip", "verbose")
+	assert.Error(t, err)
+}
+
+func TestFormatter_Format(t *testing.T) {
+	options, err := ParseOptions("all", "info")
+	require.NoError(t, err)
+	formatter := NewFormatter(txt.NewTextFormatter(), options)
+
+	entry := &logrus.Entry{
+		Data:    logrus.Fields{"source": "client/internal/engine.go:46", "peer": "1.2.3.4"},
+		Time:    time.Date(2021, time.Month(2), 21, 1, 10, 30, 0, time.UTC),
+		Level:   logrus.InfoLevel,
+		Message: "user jane@example.com connected from 1.2.3.4 to peer.example.com",
+	}
+
+	result, err := formatter.Format(entry)
+	require.NoError(t, err)
+	assert.Regexp(t, `^2021-02-21T01:10:30Z INFO \[peer: 198\.51\.100\.0\] client/internal/engine\.go:46: `+
+		`user user-[a-zA-Z0-9]+@anon-[a-zA-Z0-9]+\.domain connected from 198\.51\.100\.0 to peer\.anon-[a-zA-Z0-9]+\.domain\s+$`,
+		string(result))
+
+	entry.Level = logrus.WarnLevel
+	result, err = formatter.Format(entry)
+	require.NoError(t, err)
+	assert.Contains(t, string(result), entry.Message, "entries less verbose than the scrub level should be kept")
+}
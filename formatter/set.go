@@ -5,6 +5,7 @@ import (
 
 	"github.com/netbirdio/netbird/formatter/hook"
 	"github.com/netbirdio/netbird/formatter/logcat"
+	"github.com/netbirdio/netbird/formatter/scrub"
 	"github.com/netbirdio/netbird/formatter/syslog"
 	"github.com/netbirdio/netbird/formatter/txt"
 )
@@ -36,3 +37,8 @@ func SetLogcatFormatter(logger *logrus.Logger) {
 	logger.ReportCaller = true
 	logger.AddHook(hook.NewContextHook())
 }
+
+// SetScrubbing wraps the formatter of given logger with a formatter scrubbing the data selected by the options.
+func SetScrubbing(logger *logrus.Logger, options scrub.Options) {
+	logger.Formatter = scrub.NewFormatter(logger.Formatter, options)
+}
@@ -51,6 +51,7 @@ if [[ "$NETBIRD_STORE_CONFIG_ENGINE" == "postgres" ]]; then
     exit 1
   fi
   export NETBIRD_STORE_ENGINE_POSTGRES_DSN
+  export NETBIRD_STORE_ENGINE_POSTGRES_REPLICA_DSN
 fi
 
 # Check if MySQL is set as the store engine
@@ -22,4 +22,8 @@ type Client interface {
 	GetNetworkMap(sysInfo *system.Info) (*proto.NetworkMap, error)
 	IsHealthy() bool
 	SyncMeta(sysInfo *system.Info) error
+	ReportProbeResult(result *proto.ProbeResult) error
+	ReportDebugBundleStatus(status *proto.DebugBundleStatus) error
+	ReportConnectivity(report *proto.ConnectivityReport) error
+	ReportPeerActionStatus(status *proto.PeerActionStatus) error
 }
@@ -16,6 +16,7 @@ import (
 	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/connectivity"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/netbirdio/netbird/client/system"
 	"github.com/netbirdio/netbird/encryption"
@@ -44,15 +45,23 @@ type GrpcClient struct {
 	conn                  *grpc.ClientConn
 	connStateCallback     ConnStateNotifier
 	connStateCallbackLock sync.RWMutex
+	// clientCert is requested and renewed on login if it isn't nil
+	clientCert *nbgrpc.ClientCertificate
 }
 
 // NewClient creates a new client to Management service
 func NewClient(ctx context.Context, addr string, ourPrivateKey wgtypes.Key, tlsEnabled bool) (*GrpcClient, error) {
+	return NewClientWithCertificate(ctx, addr, ourPrivateKey, tlsEnabled, nil)
+}
+
+// NewClientWithCertificate creates a new client to Management service presenting the client certificate, which is
+// requested from the Management service on login when it is missing or about to expire
+func NewClientWithCertificate(ctx context.Context, addr string, ourPrivateKey wgtypes.Key, tlsEnabled bool, clientCert *nbgrpc.ClientCertificate) (*GrpcClient, error) {
 	var conn *grpc.ClientConn
 
 	operation := func() error {
 		var err error
-		conn, err = nbgrpc.CreateConnection(addr, tlsEnabled)
+		conn, err = nbgrpc.CreateConnection(addr, tlsEnabled, clientCert)
 		if err != nil {
 			log.Printf("createConnection error: %v", err)
 			return err
@@ -74,6 +83,7 @@ func NewClient(ctx context.Context, addr string, ourPrivateKey wgtypes.Key, tlsE
 		ctx:                   ctx,
 		conn:                  conn,
 		connStateCallbackLock: sync.RWMutex{},
+		clientCert:            clientCert,
 	}, nil
 }
 
@@ -319,6 +329,15 @@ func (c *GrpcClient) login(serverKey wgtypes.Key, req *proto.LoginRequest) (*pro
 		return nil, errors.New(errMsgNoMgmtConnection)
 	}
 
+	if c.clientCert != nil && c.clientCert.NeedsRenewal() {
+		csr, err := c.clientCert.NewRequest(c.key.PublicKey().String())
+		if err != nil {
+			log.Errorf("failed to create client certificate request: %s", err)
+			return nil, err
+		}
+		req.ClientCertificateRequest = csr
+	}
+
 	loginReq, err := encryption.EncryptMessage(serverKey, c.key, req)
 	if err != nil {
 		log.Errorf("failed to encrypt message: %s", err)
@@ -359,6 +378,14 @@ func (c *GrpcClient) login(serverKey wgtypes.Key, req *proto.LoginRequest) (*pro
 		return nil, err
 	}
 
+	if c.clientCert != nil && len(loginResp.GetClientCertificate()) > 0 {
+		if err = c.clientCert.Accept(loginResp.GetClientCertificate()); err != nil {
+			log.Errorf("failed to accept the client certificate: %s", err)
+			return nil, err
+		}
+		log.Infof("received a client certificate from Management Service")
+	}
+
 	return loginResp, nil
 }
 
@@ -479,6 +506,118 @@ func (c *GrpcClient) SyncMeta(sysInfo *system.Info) error {
 	return err
 }
 
+// ReportProbeResult sends the result of a probe requested by the Management Service.
+func (c *GrpcClient) ReportProbeResult(result *proto.ProbeResult) error {
+	if !c.ready() {
+		return errors.New(errMsgNoMgmtConnection)
+	}
+
+	serverPubKey, err := c.GetServerPublicKey()
+	if err != nil {
+		log.Debugf(errMsgMgmtPublicKey, err)
+		return err
+	}
+
+	resultReq, err := encryption.EncryptMessage(*serverPubKey, c.key, result)
+	if err != nil {
+		log.Errorf("failed to encrypt message: %s", err)
+		return err
+	}
+
+	mgmCtx, cancel := context.WithTimeout(c.ctx, ConnectTimeout)
+	defer cancel()
+
+	_, err = c.realClient.ReportProbeResult(mgmCtx, &proto.EncryptedMessage{
+		WgPubKey: c.key.PublicKey().String(),
+		Body:     resultReq,
+	})
+	return err
+}
+
+// ReportDebugBundleStatus reports a debug bundle requested by the Management Service that won't be uploaded.
+func (c *GrpcClient) ReportDebugBundleStatus(status *proto.DebugBundleStatus) error {
+	if !c.ready() {
+		return errors.New(errMsgNoMgmtConnection)
+	}
+
+	serverPubKey, err := c.GetServerPublicKey()
+	if err != nil {
+		log.Debugf(errMsgMgmtPublicKey, err)
+		return err
+	}
+
+	statusReq, err := encryption.EncryptMessage(*serverPubKey, c.key, status)
+	if err != nil {
+		log.Errorf("failed to encrypt message: %s", err)
+		return err
+	}
+
+	mgmCtx, cancel := context.WithTimeout(c.ctx, ConnectTimeout)
+	defer cancel()
+
+	_, err = c.realClient.ReportDebugBundleStatus(mgmCtx, &proto.EncryptedMessage{
+		WgPubKey: c.key.PublicKey().String(),
+		Body:     statusReq,
+	})
+	return err
+}
+
+// ReportConnectivity sends the connectivity report of the peer to the Management Service.
+func (c *GrpcClient) ReportConnectivity(report *proto.ConnectivityReport) error {
+	if !c.ready() {
+		return errors.New(errMsgNoMgmtConnection)
+	}
+
+	serverPubKey, err := c.GetServerPublicKey()
+	if err != nil {
+		log.Debugf(errMsgMgmtPublicKey, err)
+		return err
+	}
+
+	reportReq, err := encryption.EncryptMessage(*serverPubKey, c.key, report)
+	if err != nil {
+		log.Errorf("failed to encrypt message: %s", err)
+		return err
+	}
+
+	mgmCtx, cancel := context.WithTimeout(c.ctx, ConnectTimeout)
+	defer cancel()
+
+	_, err = c.realClient.ReportConnectivity(mgmCtx, &proto.EncryptedMessage{
+		WgPubKey: c.key.PublicKey().String(),
+		Body:     reportReq,
+	})
+	return err
+}
+
+// ReportPeerActionStatus reports the result of an action requested by the Management Service.
+func (c *GrpcClient) ReportPeerActionStatus(status *proto.PeerActionStatus) error {
+	if !c.ready() {
+		return errors.New(errMsgNoMgmtConnection)
+	}
+
+	serverPubKey, err := c.GetServerPublicKey()
+	if err != nil {
+		log.Debugf(errMsgMgmtPublicKey, err)
+		return err
+	}
+
+	statusReq, err := encryption.EncryptMessage(*serverPubKey, c.key, status)
+	if err != nil {
+		log.Errorf("failed to encrypt message: %s", err)
+		return err
+	}
+
+	mgmCtx, cancel := context.WithTimeout(c.ctx, ConnectTimeout)
+	defer cancel()
+
+	_, err = c.realClient.ReportPeerActionStatus(mgmCtx, &proto.EncryptedMessage{
+		WgPubKey: c.key.PublicKey().String(),
+		Body:     statusReq,
+	})
+	return err
+}
+
 func (c *GrpcClient) notifyDisconnected(err error) {
 	c.connStateCallbackLock.RLock()
 	defer c.connStateCallbackLock.RUnlock()
@@ -521,6 +660,11 @@ func infoToMetaData(info *system.Info) *proto.PeerSystemMeta {
 		})
 	}
 
+	var bootTime *timestamppb.Timestamp
+	if !info.BootTime.IsZero() {
+		bootTime = timestamppb.New(info.BootTime)
+	}
+
 	return &proto.PeerSystemMeta{
 		Hostname:         info.Hostname,
 		GoOS:             info.GoOS,
@@ -550,6 +694,9 @@ func infoToMetaData(info *system.Info) *proto.PeerSystemMeta {
 			DisableServerRoutes: info.DisableServerRoutes,
 			DisableDNS:          info.DisableDNS,
 			DisableFirewall:     info.DisableFirewall,
+			RouterMode:          info.RouterMode,
 		},
+		AdvertisedRoutes: info.AdvertisedRoutes,
+		BootTime:         bootTime,
 	}
 }
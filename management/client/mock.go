@@ -19,6 +19,10 @@ type MockClient struct {
 	GetDeviceAuthorizationFlowFunc func(serverKey wgtypes.Key) (*proto.DeviceAuthorizationFlow, error)
 	GetPKCEAuthorizationFlowFunc   func(serverKey wgtypes.Key) (*proto.PKCEAuthorizationFlow, error)
 	SyncMetaFunc                   func(sysInfo *system.Info) error
+	ReportProbeResultFunc          func(result *proto.ProbeResult) error
+	ReportDebugBundleStatusFunc    func(status *proto.DebugBundleStatus) error
+	ReportConnectivityFunc         func(report *proto.ConnectivityReport) error
+	ReportPeerActionStatusFunc     func(status *proto.PeerActionStatus) error
 }
 
 func (m *MockClient) IsHealthy() bool {
@@ -85,3 +89,31 @@ func (m *MockClient) SyncMeta(sysInfo *system.Info) error {
 	}
 	return m.SyncMetaFunc(sysInfo)
 }
+
+func (m *MockClient) ReportProbeResult(result *proto.ProbeResult) error {
+	if m.ReportProbeResultFunc == nil {
+		return nil
+	}
+	return m.ReportProbeResultFunc(result)
+}
+
+func (m *MockClient) ReportDebugBundleStatus(status *proto.DebugBundleStatus) error {
+	if m.ReportDebugBundleStatusFunc == nil {
+		return nil
+	}
+	return m.ReportDebugBundleStatusFunc(status)
+}
+
+func (m *MockClient) ReportConnectivity(report *proto.ConnectivityReport) error {
+	if m.ReportConnectivityFunc == nil {
+		return nil
+	}
+	return m.ReportConnectivityFunc(report)
+}
+
+func (m *MockClient) ReportPeerActionStatus(status *proto.PeerActionStatus) error {
+	if m.ReportPeerActionStatusFunc == nil {
+		return nil
+	}
+	return m.ReportPeerActionStatusFunc(status)
+}
@@ -66,6 +66,9 @@ type Client struct {
 	// Events NetBird Events APIs
 	// see more: https://docs.netbird.io/api/resources/events
 	Events *EventsAPI
+
+	// Config NetBird declarative config APIs
+	Config *ConfigAPI
 }
 
 // New initialize new Client instance using PAT token
@@ -102,6 +105,7 @@ func (c *Client) initialize() {
 	c.DNS = &DNSAPI{c}
 	c.GeoLocation = &GeoLocationAPI{c}
 	c.Events = &EventsAPI{c}
+	c.Config = &ConfigAPI{c}
 }
 
 func (c *Client) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Response, error) {
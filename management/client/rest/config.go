@@ -0,0 +1,46 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"github.com/netbirdio/netbird/management/server/http/api"
+)
+
+// ConfigAPI APIs for the declarative config of the account, do not use directly
+type ConfigAPI struct {
+	c *Client
+}
+
+// Export export the config of the account in the YAML format
+func (a *ConfigAPI) Export(ctx context.Context) ([]byte, error) {
+	resp, err := a.c.newRequest(ctx, "GET", "/api/config", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// Plan return the changes applying the config in the YAML or the JSON format would make
+func (a *ConfigAPI) Plan(ctx context.Context, config []byte) (*api.ConfigPlan, error) {
+	resp, err := a.c.newRequest(ctx, "PUT", "/api/config?dry_run=true", bytes.NewReader(config))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	ret, err := parseResponse[api.ConfigPlan](resp)
+	return &ret, err
+}
+
+// Apply apply the config in the YAML or the JSON format and return the changes made
+func (a *ConfigAPI) Apply(ctx context.Context, config []byte) (*api.ConfigPlan, error) {
+	resp, err := a.c.newRequest(ctx, "PUT", "/api/config", bytes.NewReader(config))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	ret, err := parseResponse[api.ConfigPlan](resp)
+	return &ret, err
+}
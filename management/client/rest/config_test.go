@@ -0,0 +1,110 @@
+//go:build integration
+// +build integration
+
+package rest_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/management/client/rest"
+	"github.com/netbirdio/netbird/management/server/http/api"
+	"github.com/netbirdio/netbird/management/server/http/util"
+)
+
+var (
+	testConfig = []byte("version: 1\ngroups:\n  - name: dev\n")
+
+	testConfigPlan = api.ConfigPlan{
+		Changes: []api.ConfigChange{
+			{
+				Kind:   api.ConfigChangeKindGroup,
+				Name:   "dev",
+				Action: api.ConfigChangeActionCreate,
+			},
+		},
+		Warnings: []string{},
+	}
+)
+
+func TestConfig_Export_200(t *testing.T) {
+	withMockClient(func(c *rest.Client, mux *http.ServeMux) {
+		mux.HandleFunc("/api/config", func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "GET", r.Method)
+			_, err := w.Write(testConfig)
+			require.NoError(t, err)
+		})
+		ret, err := c.Config.Export(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, testConfig, ret)
+	})
+}
+
+func TestConfig_Export_Err(t *testing.T) {
+	withMockClient(func(c *rest.Client, mux *http.ServeMux) {
+		mux.HandleFunc("/api/config", func(w http.ResponseWriter, r *http.Request) {
+			retBytes, _ := json.Marshal(util.ErrorResponse{Message: "No", Code: 403})
+			w.WriteHeader(403)
+			_, err := w.Write(retBytes)
+			require.NoError(t, err)
+		})
+		ret, err := c.Config.Export(context.Background())
+		assert.Error(t, err)
+		assert.Equal(t, "No", err.Error())
+		assert.Empty(t, ret)
+	})
+}
+
+func TestConfig_Plan_200(t *testing.T) {
+	withMockClient(func(c *rest.Client, mux *http.ServeMux) {
+		mux.HandleFunc("/api/config", func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "PUT", r.Method)
+			assert.Equal(t, "true", r.URL.Query().Get("dry_run"))
+			reqBytes, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			assert.Equal(t, testConfig, reqBytes)
+			retBytes, _ := json.Marshal(testConfigPlan)
+			_, err = w.Write(retBytes)
+			require.NoError(t, err)
+		})
+		ret, err := c.Config.Plan(context.Background(), testConfig)
+		require.NoError(t, err)
+		assert.Equal(t, testConfigPlan, *ret)
+	})
+}
+
+func TestConfig_Apply_200(t *testing.T) {
+	withMockClient(func(c *rest.Client, mux *http.ServeMux) {
+		mux.HandleFunc("/api/config", func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "PUT", r.Method)
+			assert.Empty(t, r.URL.Query().Get("dry_run"))
+			retBytes, _ := json.Marshal(testConfigPlan)
+			_, err := w.Write(retBytes)
+			require.NoError(t, err)
+		})
+		ret, err := c.Config.Apply(context.Background(), testConfig)
+		require.NoError(t, err)
+		assert.Equal(t, testConfigPlan, *ret)
+	})
+}
+
+func TestConfig_Apply_Err(t *testing.T) {
+	withMockClient(func(c *rest.Client, mux *http.ServeMux) {
+		mux.HandleFunc("/api/config", func(w http.ResponseWriter, r *http.Request) {
+			retBytes, _ := json.Marshal(util.ErrorResponse{Message: "No", Code: 400})
+			w.WriteHeader(400)
+			_, err := w.Write(retBytes)
+			require.NoError(t, err)
+		})
+		ret, err := c.Config.Apply(context.Background(), testConfig)
+		assert.Error(t, err)
+		assert.Equal(t, "No", err.Error())
+		assert.Empty(t, ret)
+	})
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/netbirdio/netbird/formatter/hook"
+	"github.com/netbirdio/netbird/management/server/backup"
+	"github.com/netbirdio/netbird/management/server/store"
+	"github.com/netbirdio/netbird/management/server/types"
+	"github.com/netbirdio/netbird/util"
+)
+
+// passphraseEnv is the environment variable the bundle passphrase is read from if no passphrase file is set
+const passphraseEnv = "NB_ACCOUNT_BUNDLE_PASSPHRASE"
+
+var (
+	bundleAccountID      string
+	bundleDataDir        string
+	bundleFile           string
+	bundlePassphraseFile string
+	bundleOverwrite      bool
+
+	accountCmd = &cobra.Command{
+		Use:          "account",
+		Short:        "Contains sub-commands to export an account to an encrypted bundle and import it into a management instance",
+		Long:         "",
+		SilenceUsage: true,
+	}
+
+	accountExportCmd = &cobra.Command{
+		Use:   "export --account-id ID --file bundle [--passphrase-file file]",
+		Short: "Export an account with its peers, users, groups, policies, routes, DNS settings and posture checks to an encrypted bundle",
+		Long: "Export an account with its peers, users, groups, policies, routes, DNS settings and posture checks to a bundle " +
+			"encrypted with a passphrase. The passphrase is read from the passphrase file or the " + passphraseEnv +
+			" environment variable. The bundle can be imported into another management instance with the import command.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, s, err := openBundleStore(cmd)
+			if err != nil {
+				return err
+			}
+			defer s.Close(ctx) //nolint
+
+			passphrase, err := readBundlePassphrase()
+			if err != nil {
+				return err
+			}
+
+			bundle, err := backup.Export(ctx, s, bundleAccountID, passphrase)
+			if err != nil {
+				return err
+			}
+
+			if err = os.WriteFile(bundleFile, bundle, 0600); err != nil {
+				return fmt.Errorf("write bundle: %w", err)
+			}
+			log.WithContext(ctx).Infof("exported account %s to %s", bundleAccountID, bundleFile)
+
+			return nil
+		},
+	}
+
+	accountImportCmd = &cobra.Command{
+		Use:   "import --file bundle [--passphrase-file file] [--overwrite]",
+		Short: "Import an account from an encrypted bundle",
+		Long: "Import an account from a bundle created by the export command or the export API. The management server " +
+			"has to be stopped during the import, it loads the account when it is started again. An existing account " +
+			"with the same ID is replaced only with --overwrite, which restores it to the state of the bundle.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, s, err := openBundleStore(cmd)
+			if err != nil {
+				return err
+			}
+			defer s.Close(ctx) //nolint
+
+			passphrase, err := readBundlePassphrase()
+			if err != nil {
+				return err
+			}
+
+			bundle, err := os.ReadFile(bundleFile)
+			if err != nil {
+				return fmt.Errorf("read bundle: %w", err)
+			}
+
+			account, err := backup.Import(ctx, s, bundle, passphrase, bundleOverwrite)
+			if err != nil {
+				return err
+			}
+			log.WithContext(ctx).Infof("imported account %s with %d peers and %d users", account.Id, len(account.Peers), len(account.Users))
+
+			return nil
+		},
+	}
+)
+
+func init() {
+	accountCmd.PersistentFlags().StringVar(&types.MgmtConfigPath, "config", defaultMgmtConfig, "Netbird config file location, the store of the config is used")
+	accountCmd.PersistentFlags().StringVar(&bundleDataDir, "datadir", "", "server data directory location, overrides the data directory of the config")
+	accountCmd.PersistentFlags().StringVar(&bundleFile, "file", "", "location of the account bundle")
+	accountCmd.PersistentFlags().StringVar(&bundlePassphraseFile, "passphrase-file", "", "file with the passphrase of the bundle, defaults to the "+passphraseEnv+" environment variable")
+	accountCmd.MarkPersistentFlagRequired("file") //nolint
+
+	accountExportCmd.Flags().StringVar(&bundleAccountID, "account-id", "", "ID of the exported account")
+	accountExportCmd.MarkFlagRequired("account-id") //nolint
+
+	accountImportCmd.Flags().BoolVar(&bundleOverwrite, "overwrite", false, "replace an existing account with the same ID")
+
+	accountCmd.AddCommand(accountExportCmd)
+	accountCmd.AddCommand(accountImportCmd)
+
+	rootCmd.AddCommand(accountCmd)
+}
+
+// openBundleStore initializes the log and opens the store of the management config
+func openBundleStore(cmd *cobra.Command) (context.Context, store.Store, error) {
+	return openConfiguredStore(cmd, bundleDataDir)
+}
+
+// openConfiguredStore initializes the log and opens the store of the management config with its encryption, dataDir
+// overrides the data directory of the config if set
+func openConfiguredStore(cmd *cobra.Command, dataDir string) (context.Context, store.Store, error) {
+	if err := util.InitLog(logLevel, logFile); err != nil {
+		return nil, nil, fmt.Errorf("failed initializing log %v", err)
+	}
+
+	//nolint
+	ctx := context.WithValue(cmd.Context(), hook.ExecutionContextKey, hook.SystemSource)
+
+	config := &types.Config{}
+	if _, err := util.ReadJsonWithEnvSub(types.MgmtConfigPath, config); err != nil {
+		return nil, nil, fmt.Errorf("read config: %w", err)
+	}
+	if dataDir != "" {
+		config.Datadir = dataDir
+	}
+
+	s, err := store.NewStore(ctx, config.StoreConfig.Engine, config.Datadir, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open store: %w", err)
+	}
+
+	if err = setupStoreEncryption(ctx, s, config.StoreConfig.Encryption); err != nil {
+		s.Close(ctx) //nolint
+		return nil, nil, err
+	}
+
+	return ctx, s, nil
+}
+
+func readBundlePassphrase() (string, error) {
+	if bundlePassphraseFile == "" {
+		passphrase := os.Getenv(passphraseEnv)
+		if passphrase == "" {
+			return "", fmt.Errorf("set the bundle passphrase with --passphrase-file or the %s environment variable", passphraseEnv)
+		}
+		return passphrase, nil
+	}
+
+	content, err := os.ReadFile(bundlePassphraseFile)
+	if err != nil {
+		return "", fmt.Errorf("read passphrase file: %w", err)
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
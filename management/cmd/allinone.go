@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+
+	"github.com/netbirdio/netbird/management/server/telemetry"
+	"github.com/netbirdio/netbird/management/server/types"
+	"github.com/netbirdio/netbird/relay/auth"
+	relayServer "github.com/netbirdio/netbird/relay/server"
+	"github.com/netbirdio/netbird/relay/server/listener/ws"
+	signalProto "github.com/netbirdio/netbird/signal/proto"
+	signalServer "github.com/netbirdio/netbird/signal/server"
+	"github.com/netbirdio/netbird/util"
+)
+
+const (
+	allInOneRelayCredentialsTTL = 24 * time.Hour
+	allInOneRelaySecretLength   = 32
+	allInOneShutdownTimeout     = 5 * time.Second
+)
+
+// allInOne serves the signal and relay services with the management server, on its listener with its TLS config and
+// metrics, so a small self-hosted deployment runs a single process
+type allInOne struct {
+	signal *signalServer.Server
+	relay  *relayServer.Server
+}
+
+// applyAllInOneDefaults points the peers to the signal and relay services of this server, unless other services are
+// configured. A relay secret is generated if there is none, true is returned when the config has to be persisted.
+func applyAllInOneDefaults(ctx context.Context, config *types.Config, exposedAddress string, tlsEnabled bool) (bool, error) {
+	if config.Signal == nil || config.Signal.URI == "" {
+		proto := types.HTTP
+		if tlsEnabled {
+			proto = types.HTTPS
+		}
+		config.Signal = &types.Host{Proto: proto, URI: exposedAddress}
+		log.WithContext(ctx).Infof("using the embedded signal service at %s", exposedAddress)
+	}
+
+	if config.Relay == nil {
+		config.Relay = &types.Relay{}
+	}
+
+	if len(config.Relay.Addresses) == 0 {
+		scheme := "rel"
+		if tlsEnabled {
+			scheme = "rels"
+		}
+		config.Relay.Addresses = []string{fmt.Sprintf("%s://%s", scheme, exposedAddress)}
+		// the embedded relay is of the same version as this management service
+		config.Relay.PeerBoundTokens = true
+		log.WithContext(ctx).Infof("using the embedded relay service at %s", config.Relay.Addresses[0])
+	}
+
+	if config.Relay.CredentialsTTL.Duration <= 0 {
+		config.Relay.CredentialsTTL = util.Duration{Duration: allInOneRelayCredentialsTTL}
+	}
+
+	if config.Relay.Secret != "" {
+		return false, nil
+	}
+
+	secret := make([]byte, allInOneRelaySecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return false, fmt.Errorf("generate relay secret: %w", err)
+	}
+	config.Relay.Secret = base64.RawStdEncoding.EncodeToString(secret)
+
+	return true, nil
+}
+
+// newAllInOne creates the signal and relay services sharing the metrics of the management server. The health and the
+// introspection of the relay service are served on the metrics port.
+func newAllInOne(ctx context.Context, config *types.Config, appMetrics telemetry.AppMetrics, exposedAddress string, tlsEnabled bool) (*allInOne, error) {
+	signal, err := signalServer.NewServer(ctx, appMetrics.GetMeter())
+	if err != nil {
+		return nil, fmt.Errorf("creating signal server: %v", err)
+	}
+
+	hashedSecret := sha256.Sum256([]byte(config.Relay.Secret))
+	authenticator := auth.NewTimedHMACValidator(hashedSecret[:], allInOneRelayCredentialsTTL)
+	if config.Relay.PeerBoundTokens {
+		// the credentials of the embedded relay are only issued by this management service, they are bound to the peers
+		authenticator.RequirePeerBinding()
+	}
+
+	relay, err := relayServer.NewServer(appMetrics.GetMeter(), exposedAddress, tlsEnabled, authenticator)
+	if err != nil {
+		return nil, fmt.Errorf("creating relay server: %v", err)
+	}
+	relay.SetAudiences(config.Relay.Addresses)
+
+	introspection := relay.IntrospectionHandler()
+	appMetrics.Handle(relayServer.HealthPath, introspection)
+	appMetrics.Handle(relayServer.IntrospectionPath, introspection)
+
+	return &allInOne{
+		signal: signal,
+		relay:  relay,
+	}, nil
+}
+
+// register adds the signal service to the gRPC server of the management service
+func (a *allInOne) register(gRPCServer *grpc.Server) {
+	signalProto.RegisterSignalExchangeServer(gRPCServer, a.signal)
+}
+
+// handler serves the WebSocket relay connections on the address and passes the other requests to next
+func (a *allInOne) handler(next http.Handler, address string) http.Handler {
+	relayHandler := a.relay.HTTPHandler(address)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == ws.URLPath {
+			relayHandler.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// serveQUIC accepts the QUIC relay connections on the UDP port of the listener. QUIC requires TLS, so the peers
+// fall back to WebSocket when the server runs without it.
+func (a *allInOne) serveQUIC(ctx context.Context, listener net.Listener, tlsConfig *tls.Config) {
+	if tlsConfig == nil {
+		log.WithContext(ctx).Infof("running the embedded relay service without QUIC, it requires TLS")
+		return
+	}
+
+	go func() {
+		if err := a.relay.ListenQUIC(relayServer.ListenerConfig{Address: listener.Addr().String(), TLSConfig: tlsConfig}); err != nil {
+			log.WithContext(ctx).Errorf("failed to serve relay QUIC connections: %v", err)
+		}
+	}()
+}
+
+// shutdown closes the relay connections, the signal streams are closed with the gRPC server
+func (a *allInOne) shutdown(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, allInOneShutdownTimeout)
+	defer cancel()
+
+	if err := a.relay.Shutdown(ctx); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		log.WithContext(ctx).Warnf("failed to stop the embedded relay service: %v", err)
+	}
+}
+
+// allInOneExposedAddress returns the address the peers reach this server at
+func allInOneExposedAddress(config *types.Config) (string, error) {
+	if exposedAddress != "" {
+		return exposedAddress, nil
+	}
+
+	if config.HttpConfig != nil && config.HttpConfig.LetsEncryptDomain != "" {
+		return net.JoinHostPort(config.HttpConfig.LetsEncryptDomain, fmt.Sprint(mgmtPort)), nil
+	}
+
+	return "", fmt.Errorf("--exposed-address is required to run all-in-one without a Let's Encrypt domain")
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/management/server/types"
+	"github.com/netbirdio/netbird/util"
+)
+
+func Test_applyAllInOneDefaults(t *testing.T) {
+	t.Run("unconfigured services", func(t *testing.T) {
+		config := &types.Config{}
+
+		persist, err := applyAllInOneDefaults(context.Background(), config, "netbird.example.com:443", true)
+		require.NoError(t, err)
+
+		assert.True(t, persist, "generated relay secret should be persisted")
+		assert.Equal(t, &types.Host{Proto: types.HTTPS, URI: "netbird.example.com:443"}, config.Signal)
+		assert.Equal(t, []string{"rels://netbird.example.com:443"}, config.Relay.Addresses)
+		assert.Equal(t, allInOneRelayCredentialsTTL, config.Relay.CredentialsTTL.Duration)
+		assert.NotEmpty(t, config.Relay.Secret)
+		assert.True(t, config.Relay.PeerBoundTokens, "the embedded relay should accept the tokens bound to the peers")
+	})
+
+	t.Run("configured services are kept", func(t *testing.T) {
+		signal := &types.Host{Proto: types.HTTPS, URI: "signal.example.com:10000"}
+		relay := &types.Relay{
+			Addresses:      []string{"rels://relay.example.com:443"},
+			CredentialsTTL: util.Duration{Duration: time.Hour},
+			Secret:         "secret",
+		}
+		config := &types.Config{Signal: signal, Relay: relay}
+
+		persist, err := applyAllInOneDefaults(context.Background(), config, "netbird.example.com:80", false)
+		require.NoError(t, err)
+
+		assert.False(t, persist)
+		assert.Equal(t, "signal.example.com:10000", config.Signal.URI)
+		assert.Equal(t, []string{"rels://relay.example.com:443"}, config.Relay.Addresses)
+		assert.Equal(t, "secret", config.Relay.Secret)
+		assert.False(t, config.Relay.PeerBoundTokens, "the configured relays may not accept the tokens bound to the peers")
+	})
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/netbirdio/netbird/management/client/rest"
+	"github.com/netbirdio/netbird/management/server/http/api"
+)
+
+const (
+	// configManagementURLEnv is the environment variable the management URL is read from if no URL is set
+	configManagementURLEnv = "NB_MANAGEMENT_URL"
+	// configTokenEnv is the environment variable the personal access token is read from if no token file is set
+	configTokenEnv = "NB_MANAGEMENT_TOKEN"
+)
+
+var (
+	configManagementURL string
+	configTokenFile     string
+	configFile          string
+	configDryRun        bool
+
+	configCmd = &cobra.Command{
+		Use:          "config",
+		Short:        "Contains sub-commands to export and apply the groups, posture checks, policies, routes and nameserver groups of an account in a declarative YAML format",
+		Long:         "",
+		SilenceUsage: true,
+	}
+
+	configExportCmd = &cobra.Command{
+		Use:   "export [--file config.yaml]",
+		Short: "Export the groups, posture checks, policies, routes and nameserver groups of the account",
+		Long: "Export the groups, posture checks, policies, routes and nameserver groups of the account of the token " +
+			"through the management API in the declarative YAML format. The objects reference each other by their names, " +
+			"so the config can be kept in a repository and applied to the same or another account.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newConfigClient()
+			if err != nil {
+				return err
+			}
+
+			content, err := client.Config.Export(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("export config: %w", err)
+			}
+
+			if configFile == "" {
+				_, err = os.Stdout.Write(content)
+				return err
+			}
+			if err = os.WriteFile(configFile, content, 0600); err != nil {
+				return fmt.Errorf("write config: %w", err)
+			}
+			fmt.Printf("exported the config to %s\n", configFile)
+
+			return nil
+		},
+	}
+
+	configApplyCmd = &cobra.Command{
+		Use:   "apply --file config.yaml [--dry-run]",
+		Short: "Make the groups, posture checks, policies, routes and nameserver groups of the account match the config",
+		Long: "Make the groups, posture checks, policies, routes and nameserver groups of the account of the token match " +
+			"the config and print the changes made. The objects of the sections the config leaves out aren't changed, " +
+			"the objects missing from a section are deleted. With --dry-run the changes are only printed.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newConfigClient()
+			if err != nil {
+				return err
+			}
+
+			if configFile == "" {
+				return errors.New("set the config with --file")
+			}
+			content, err := os.ReadFile(configFile)
+			if err != nil {
+				return fmt.Errorf("read config: %w", err)
+			}
+
+			var plan *api.ConfigPlan
+			if configDryRun {
+				plan, err = client.Config.Plan(cmd.Context(), content)
+			} else {
+				plan, err = client.Config.Apply(cmd.Context(), content)
+			}
+			if err != nil {
+				return fmt.Errorf("apply config: %w", err)
+			}
+
+			printConfigPlan(plan, configDryRun)
+
+			return nil
+		},
+	}
+)
+
+func init() {
+	configCmd.PersistentFlags().StringVar(&configManagementURL, "management-url", "", "URL of the management API, e.g. https://netbird.example.com, defaults to the "+configManagementURLEnv+" environment variable")
+	configCmd.PersistentFlags().StringVar(&configTokenFile, "token-file", "", "file with a personal access token of an admin of the account, defaults to the "+configTokenEnv+" environment variable")
+	configCmd.PersistentFlags().StringVar(&configFile, "file", "", "location of the config")
+
+	configApplyCmd.Flags().BoolVar(&configDryRun, "dry-run", false, "only print the changes applying the config would make")
+
+	configCmd.AddCommand(configExportCmd)
+	configCmd.AddCommand(configApplyCmd)
+
+	rootCmd.AddCommand(configCmd)
+}
+
+// newConfigClient returns a client of the management API authenticated with the personal access token
+func newConfigClient() (*rest.Client, error) {
+	managementURL := configManagementURL
+	if managementURL == "" {
+		managementURL = os.Getenv(configManagementURLEnv)
+	}
+	if managementURL == "" {
+		return nil, fmt.Errorf("set the management URL with --management-url or the %s environment variable", configManagementURLEnv)
+	}
+
+	token := os.Getenv(configTokenEnv)
+	if configTokenFile != "" {
+		content, err := os.ReadFile(configTokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("read token file: %w", err)
+		}
+		token = strings.TrimSpace(string(content))
+	}
+	if token == "" {
+		return nil, fmt.Errorf("set the personal access token with --token-file or the %s environment variable", configTokenEnv)
+	}
+
+	return rest.New(strings.TrimRight(managementURL, "/"), token), nil
+}
+
+func printConfigPlan(plan *api.ConfigPlan, dryRun bool) {
+	for _, warning := range plan.Warnings {
+		fmt.Printf("warning: %s\n", warning)
+	}
+
+	if len(plan.Changes) == 0 {
+		fmt.Println("the account matches the config, no changes")
+		return
+	}
+
+	for _, change := range plan.Changes {
+		line := fmt.Sprintf("%s %s %s", change.Action, change.Kind, change.Name)
+		if change.Fields != nil && len(*change.Fields) > 0 {
+			line += fmt.Sprintf(" (%s)", strings.Join(*change.Fields, ", "))
+		}
+		fmt.Println(line)
+	}
+
+	if dryRun {
+		fmt.Printf("%d changes would be applied\n", len(plan.Changes))
+		return
+	}
+	fmt.Printf("applied %d changes\n", len(plan.Changes))
+}
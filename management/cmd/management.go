@@ -15,6 +15,7 @@ import (
 	"net/url"
 	"os"
 	"path"
+	"path/filepath"
 	"slices"
 	"strings"
 	"time"
@@ -35,6 +36,7 @@ import (
 
 	"github.com/netbirdio/management-integrations/integrations"
 
+	"github.com/netbirdio/netbird/management/server/peeractions"
 	"github.com/netbirdio/netbird/management/server/peers"
 	"github.com/netbirdio/netbird/management/server/types"
 
@@ -42,21 +44,43 @@ import (
 	"github.com/netbirdio/netbird/formatter/hook"
 	mgmtProto "github.com/netbirdio/netbird/management/proto"
 	"github.com/netbirdio/netbird/management/server"
+	"github.com/netbirdio/netbird/management/server/accesshistory"
+	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/activity/bus"
+	"github.com/netbirdio/netbird/management/server/activity/export"
 	"github.com/netbirdio/netbird/management/server/auth"
+	"github.com/netbirdio/netbird/management/server/backup"
+	"github.com/netbirdio/netbird/management/server/cluster"
+	"github.com/netbirdio/netbird/management/server/connectivity"
 	nbContext "github.com/netbirdio/netbird/management/server/context"
+	"github.com/netbirdio/netbird/management/server/controlplane"
+	"github.com/netbirdio/netbird/management/server/debugbundles"
+	"github.com/netbirdio/netbird/management/server/declarative"
 	"github.com/netbirdio/netbird/management/server/geolocation"
 	"github.com/netbirdio/netbird/management/server/groups"
 	nbhttp "github.com/netbirdio/netbird/management/server/http"
 	"github.com/netbirdio/netbird/management/server/idp"
 	"github.com/netbirdio/netbird/management/server/metrics"
+	"github.com/netbirdio/netbird/management/server/monitors"
+	"github.com/netbirdio/netbird/management/server/mtls"
 	"github.com/netbirdio/netbird/management/server/networks"
 	"github.com/netbirdio/netbird/management/server/networks/resources"
 	"github.com/netbirdio/netbird/management/server/networks/routers"
+	"github.com/netbirdio/netbird/management/server/notifications"
+	"github.com/netbirdio/netbird/management/server/probes"
+	"github.com/netbirdio/netbird/management/server/roles"
+	"github.com/netbirdio/netbird/management/server/scim"
 	"github.com/netbirdio/netbird/management/server/settings"
+	"github.com/netbirdio/netbird/management/server/simulation"
 	"github.com/netbirdio/netbird/management/server/store"
+	"github.com/netbirdio/netbird/management/server/stream"
 	"github.com/netbirdio/netbird/management/server/telemetry"
+	"github.com/netbirdio/netbird/management/server/topology"
+	"github.com/netbirdio/netbird/management/server/usage"
 	"github.com/netbirdio/netbird/management/server/users"
+	"github.com/netbirdio/netbird/management/server/webhooks"
 	"github.com/netbirdio/netbird/util"
+	"github.com/netbirdio/netbird/util/pqtls"
 	"github.com/netbirdio/netbird/version"
 )
 
@@ -68,9 +92,14 @@ var (
 	mgmtPort                int
 	mgmtMetricsPort         int
 	mgmtLetsencryptDomain   string
+	mgmtLetsencryptEmail    string
+	mgmtDNSProvider         string
 	mgmtSingleAccModeDomain string
 	certFile                string
 	certKey                 string
+	adminAddress            string
+	allInOneMode            bool
+	exposedAddress          string
 	config                  *types.Config
 
 	kaep = keepalive.EnforcementPolicy{
@@ -159,18 +188,28 @@ var (
 			if err != nil {
 				return err
 			}
-			store, err := store.NewStore(ctx, config.StoreConfig.Engine, config.Datadir, appMetrics)
+			nbCluster, err := cluster.New(ctx, config.Cluster)
+			if err != nil {
+				return fmt.Errorf("failed to initialize cluster: %v", err)
+			}
+			store, err := newStore(ctx, config, nbCluster, appMetrics)
 			if err != nil {
 				return fmt.Errorf("failed creating Store: %s: %v", config.Datadir, err)
 			}
 			peersUpdateManager := server.NewPeersUpdateManager(appMetrics)
 
 			var idpManager idp.Manager
+			var reloadableIdpManager *idp.ReloadableManager
 			if config.IdpManagerConfig != nil {
 				idpManager, err = idp.NewManager(ctx, *config.IdpManagerConfig, appMetrics)
 				if err != nil {
 					return fmt.Errorf("failed retrieving a new idp manager with err: %v", err)
 				}
+				if idpManager != nil {
+					// the IdP credentials can be changed by reloading the config
+					reloadableIdpManager = idp.NewReloadableManager(idpManager)
+					idpManager = reloadableIdpManager
+				}
 			}
 
 			if disableSingleAccMode {
@@ -181,6 +220,29 @@ var (
 				return fmt.Errorf("failed to initialize database: %s", err)
 			}
 
+			eventBroker := stream.NewBroker()
+			usageRecorder := usage.NewRecorder(store)
+			accessHistoryRecorder := accesshistory.NewRecorder(store)
+			eventSinks := []activity.Sink{webhooks.NewDispatcher(ctx, store), eventBroker, usageRecorder, accessHistoryRecorder}
+
+			if config.EventExport != nil {
+				exporter, err := export.NewExporter(ctx, config.EventExport)
+				if err != nil {
+					return fmt.Errorf("failed to initialize event export: %v", err)
+				}
+				eventSinks = append(eventSinks, exporter)
+			}
+
+			if config.EventBus != nil {
+				busSinks, err := bus.NewSinks(ctx, config.EventBus)
+				if err != nil {
+					return fmt.Errorf("failed to initialize event bus: %v", err)
+				}
+				eventSinks = append(eventSinks, busSinks...)
+			}
+
+			eventStore = activity.NewSinkStore(eventStore, eventSinks...)
+
 			if config.DataStoreEncryptionKey != key {
 				log.WithContext(ctx).Infof("update config with activity store key")
 				config.DataStoreEncryptionKey = key
@@ -190,6 +252,31 @@ var (
 				}
 			}
 
+			var embedded *allInOne
+			if allInOneMode {
+				embeddedAddress, err := allInOneExposedAddress(config)
+				if err != nil {
+					return err
+				}
+				embeddedTLS := config.HttpConfig.LetsEncryptDomain != "" || (config.HttpConfig.CertFile != "" && config.HttpConfig.CertKey != "")
+				persist, err := applyAllInOneDefaults(ctx, config, embeddedAddress, embeddedTLS)
+				if err != nil {
+					return err
+				}
+				if persist {
+					log.WithContext(ctx).Infof("update config with generated relay secret")
+					if err := updateMgmtConfig(ctx, types.MgmtConfigPath, config); err != nil {
+						return fmt.Errorf("failed to write out relay secret: %s", err)
+					}
+				}
+				embedded, err = newAllInOne(ctx, config, appMetrics, embeddedAddress, embeddedTLS)
+				if err != nil {
+					return err
+				}
+				usageRecorder.SetRelayTransfer(embedded.relay)
+				embedded.relay.SetAccountResolver(usageRecorder)
+			}
+
 			geo, err := geolocation.NewGeolocation(ctx, config.Datadir, !disableGeoliteUpdate)
 			if err != nil {
 				log.WithContext(ctx).Warnf("could not initialize geolocation service. proceeding without geolocation support: %v", err)
@@ -214,6 +301,14 @@ var (
 				return fmt.Errorf("failed to build default manager: %v", err)
 			}
 
+			notifier, err := notifications.NewNotifier(config.Notifications)
+			if err != nil {
+				return fmt.Errorf("failed to initialize notifications: %v", err)
+			}
+			accountManager.SetNotifier(notifier)
+			accessHistoryRecorder.SetPeerValidator(accountManager)
+			accountManager.SetCluster(ctx, nbCluster)
+
 			secretsManager := server.NewTimeBasedAuthSecretsManager(peersUpdateManager, config.TURNConfig, config.Relay, settingsManager)
 
 			trustedPeers := config.ReverseProxy.TrustedPeers
@@ -241,15 +336,45 @@ var (
 				grpc.ChainStreamInterceptor(realip.StreamServerInterceptorOpts(realipOpts...), streamInterceptor),
 			}
 
+			var clientCertAuthority *mtls.Authority
+			if config.ClientCertificates != nil {
+				clientCertAuthority, err = mtls.NewAuthority(ctx, config.ClientCertificates, config.Datadir)
+				if err != nil {
+					return fmt.Errorf("failed creating client certificate authority: %v", err)
+				}
+			}
+			// serverTLSConfig requests the client certificates of the peers if they are enabled
+			serverTLSConfig := func(tlsConfig *tls.Config) *tls.Config {
+				tlsConfig = pqtls.PreferHybrid(tlsConfig)
+				if clientCertAuthority == nil {
+					return tlsConfig
+				}
+				return clientCertAuthority.ServerTLSConfig(tlsConfig)
+			}
+
 			var certManager *autocert.Manager
 			var tlsConfig *tls.Config
 			tlsEnabled := false
-			if config.HttpConfig.LetsEncryptDomain != "" {
+			if config.HttpConfig.LetsEncryptDomain != "" && config.HttpConfig.LetsEncryptDNSProvider != "" {
+				dns01 := &encryption.DNS01TLS{
+					DataDir:  filepath.Join(config.Datadir, "letsencrypt"),
+					Email:    config.HttpConfig.LetsEncryptEmail,
+					Domains:  []string{config.HttpConfig.LetsEncryptDomain},
+					Provider: config.HttpConfig.LetsEncryptDNSProvider,
+				}
+				tlsConfig, err = dns01.GetCertificate()
+				if err != nil {
+					return fmt.Errorf("failed obtaining LetsEncrypt certificate with DNS challenge: %v", err)
+				}
+				transportCredentials := credentials.NewTLS(serverTLSConfig(tlsConfig))
+				gRPCOpts = append(gRPCOpts, grpc.Creds(transportCredentials))
+				tlsEnabled = true
+			} else if config.HttpConfig.LetsEncryptDomain != "" {
 				certManager, err = encryption.CreateCertManager(config.Datadir, config.HttpConfig.LetsEncryptDomain)
 				if err != nil {
 					return fmt.Errorf("failed creating LetsEncrypt cert manager: %v", err)
 				}
-				transportCredentials := credentials.NewTLS(certManager.TLSConfig())
+				transportCredentials := credentials.NewTLS(serverTLSConfig(certManager.TLSConfig()))
 				gRPCOpts = append(gRPCOpts, grpc.Creds(transportCredentials))
 				tlsEnabled = true
 			} else if config.HttpConfig.CertFile != "" && config.HttpConfig.CertKey != "" {
@@ -258,11 +383,15 @@ var (
 					log.WithContext(ctx).Errorf("cannot load TLS credentials: %v", err)
 					return err
 				}
-				transportCredentials := credentials.NewTLS(tlsConfig)
+				transportCredentials := credentials.NewTLS(serverTLSConfig(tlsConfig))
 				gRPCOpts = append(gRPCOpts, grpc.Creds(transportCredentials))
 				tlsEnabled = true
 			}
 
+			if clientCertAuthority != nil && !tlsEnabled {
+				return fmt.Errorf("client certificates require TLS")
+			}
+
 			authManager := auth.NewManager(store,
 				config.HttpConfig.AuthIssuer,
 				config.HttpConfig.AuthAudience,
@@ -275,8 +404,26 @@ var (
 			resourcesManager := resources.NewManager(store, permissionsManager, groupsManager, accountManager)
 			routersManager := routers.NewManager(store, permissionsManager, accountManager)
 			networksManager := networks.NewManager(store, permissionsManager, resourcesManager, routersManager, accountManager)
-
-			httpAPIHandler, err := nbhttp.NewAPIHandler(ctx, accountManager, networksManager, resourcesManager, routersManager, groupsManager, geo, authManager, appMetrics, integratedPeerValidator, proxyController, permissionsManager, peersManager, settingsManager)
+			scimManager := scim.NewManager(store, permissionsManager, accountManager)
+			rolesManager := roles.NewManager(store, permissionsManager, accountManager)
+			webhooksManager := webhooks.NewManager(store, permissionsManager, accountManager)
+			streamManager := stream.NewManager(permissionsManager, eventBroker)
+			probesManager := probes.NewManager(store, permissionsManager, peersUpdateManager)
+			monitorsManager := monitors.NewManager(store, permissionsManager, accountManager)
+			usageManager := usage.NewManager(store, permissionsManager)
+			topologyManager := topology.NewManager(store, permissionsManager, accountManager)
+			simulationManager := simulation.NewManager(store, permissionsManager, accountManager)
+			backupManager := backup.NewManager(store, permissionsManager, accountManager)
+			accessHistoryManager := accesshistory.NewManager(store, permissionsManager)
+			debugBundlesManager := debugbundles.NewManager(store, permissionsManager, accountManager, peersUpdateManager, config.Datadir, config.DataStoreEncryptionKey)
+			exitNodeRecorder := usage.NewExitNodeRecorder(store, accountManager)
+			connectivityManager := connectivity.NewManager(store, permissionsManager, exitNodeRecorder)
+			peerActionsManager := peeractions.NewManager(store, permissionsManager, accountManager, peersUpdateManager, config.DataStoreEncryptionKey)
+			declarativeManager := declarative.NewManager(accountManager)
+			syncRecorder := controlplane.NewSyncRecorder()
+			controlPlaneManager := controlplane.NewManager(store, permissionsManager, syncRecorder)
+
+			httpAPIHandler, err := nbhttp.NewAPIHandler(ctx, accountManager, networksManager, resourcesManager, routersManager, groupsManager, geo, authManager, appMetrics, integratedPeerValidator, proxyController, permissionsManager, peersManager, settingsManager, scimManager, rolesManager, webhooksManager, streamManager, probesManager, monitorsManager, usageManager, topologyManager, simulationManager, backupManager, accessHistoryManager, debugBundlesManager, connectivityManager, peerActionsManager, declarativeManager, controlPlaneManager)
 
 			if err != nil {
 				return fmt.Errorf("failed creating HTTP API handler: %v", err)
@@ -290,7 +437,42 @@ var (
 			if err != nil {
 				return fmt.Errorf("failed creating gRPC API handler: %v", err)
 			}
+			if clientCertAuthority != nil {
+				srv.SetClientCertificateAuthority(clientCertAuthority)
+				if embedded != nil {
+					embedded.signal.SetClientCertificateVerification(config.ClientCertificates.Required)
+				}
+			}
+			srv.SetProbesManager(probesManager)
+			srv.SetDebugBundlesManager(debugBundlesManager)
+			srv.SetConnectivityManager(connectivityManager)
+			srv.SetPeerActionsManager(peerActionsManager)
+			srv.SetSyncRecorder(syncRecorder)
+
+			monitorScheduler, err := monitors.NewScheduler(store, peersUpdateManager, probesManager, accountManager, appMetrics.GetMeter())
+			if err != nil {
+				return fmt.Errorf("failed creating monitor scheduler: %v", err)
+			}
+			monitorScheduler.Start(ctx)
+			usageRecorder.Start(ctx)
+			exitNodeRecorder.Start(ctx)
+			accessHistoryRecorder.Start(ctx)
+
 			mgmtProto.RegisterManagementServiceServer(gRPCAPIHandler, srv)
+			if embedded != nil {
+				embedded.register(gRPCAPIHandler)
+			}
+
+			configReloader := server.NewConfigReloader(config, accountManager, reloadableIdpManager, secretsManager, srv, store, appMetrics)
+			handleReloadSignal(ctx, configReloader)
+
+			var adminServer *http.Server
+			if adminAddress != "" {
+				adminServer, err = serveAdmin(ctx, adminAddress, configReloader)
+				if err != nil {
+					return err
+				}
+			}
 
 			installationID, err := getInstallationID(ctx, store)
 			if err != nil {
@@ -319,8 +501,19 @@ var (
 			}
 
 			rootHandler := handlerFunc(gRPCAPIHandler, httpAPIHandler)
+			if embedded != nil {
+				rootHandler = embedded.handler(rootHandler, fmt.Sprintf(":%d", mgmtPort))
+			}
 			var listener net.Listener
-			if certManager != nil {
+			if certManager != nil && mgmtPort == 443 && clientCertAuthority != nil {
+				// the listener of the cert manager doesn't request the client certificates, its TLS config answers
+				// the TLS-ALPN challenge on the same port
+				rootHandler = certManager.HTTPHandler(rootHandler)
+				listener, err = tls.Listen("tcp", fmt.Sprintf(":%d", mgmtPort), serverTLSConfig(certManager.TLSConfig()))
+				if err != nil {
+					return fmt.Errorf("failed creating TLS listener on port %d: %v", mgmtPort, err)
+				}
+			} else if certManager != nil {
 				// a call to certManager.Listener() always creates a new listener so we do it once
 				cml := certManager.Listener()
 				if mgmtPort == 443 {
@@ -328,7 +521,7 @@ var (
 					rootHandler = certManager.HTTPHandler(rootHandler)
 					listener = cml
 				} else {
-					listener, err = tls.Listen("tcp", fmt.Sprintf(":%d", mgmtPort), certManager.TLSConfig())
+					listener, err = tls.Listen("tcp", fmt.Sprintf(":%d", mgmtPort), serverTLSConfig(certManager.TLSConfig()))
 					if err != nil {
 						return fmt.Errorf("failed creating TLS listener on port %d: %v", mgmtPort, err)
 					}
@@ -336,7 +529,7 @@ var (
 					serveHTTP(ctx, cml, certManager.HTTPHandler(nil))
 				}
 			} else if tlsConfig != nil {
-				listener, err = tls.Listen("tcp", fmt.Sprintf(":%d", mgmtPort), tlsConfig)
+				listener, err = tls.Listen("tcp", fmt.Sprintf(":%d", mgmtPort), serverTLSConfig(tlsConfig))
 				if err != nil {
 					return fmt.Errorf("failed creating TLS listener on port %d: %v", mgmtPort, err)
 				}
@@ -351,9 +544,20 @@ var (
 			log.WithContext(ctx).Infof("running HTTP server and gRPC server on the same port: %s", listener.Addr().String())
 			serveGRPCWithHTTP(ctx, listener, rootHandler, tlsEnabled)
 
+			if embedded != nil {
+				quicTLSConfig := tlsConfig
+				if certManager != nil {
+					quicTLSConfig = certManager.TLSConfig()
+				}
+				embedded.serveQUIC(ctx, listener, quicTLSConfig)
+			}
+
 			SetupCloseHandler()
 
 			<-stopCh
+			if embedded != nil {
+				embedded.shutdown(ctx)
+			}
 			integratedPeerValidator.Stop(ctx)
 			if geo != nil {
 				_ = geo.Stop()
@@ -361,10 +565,14 @@ var (
 			ephemeralManager.Stop()
 			_ = appMetrics.Close()
 			_ = listener.Close()
+			if adminServer != nil {
+				_ = adminServer.Close()
+			}
 			if certManager != nil {
 				_ = certManager.Listener().Close()
 			}
 			gRPCAPIHandler.Stop()
+			_ = nbCluster.Close()
 			_ = store.Close(ctx)
 			_ = eventStore.Close(ctx)
 			log.WithContext(ctx).Infof("stopped Management Service")
@@ -412,6 +620,28 @@ func notifyStop(ctx context.Context, msg string) {
 	}
 }
 
+// newStore creates the store of the management server. In a cluster its write locks are acquired across the servers.
+func newStore(ctx context.Context, config *types.Config, nbCluster cluster.Cluster, metrics telemetry.AppMetrics) (store.Store, error) {
+	s, err := store.NewStore(ctx, config.StoreConfig.Engine, config.Datadir, metrics)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.SetConnectionPool(ctx, config.StoreConfig); err != nil {
+		return nil, err
+	}
+
+	if err = setupStoreEncryption(ctx, s, config.StoreConfig.Encryption); err != nil {
+		return nil, err
+	}
+
+	if nbCluster.Distributed() {
+		return store.NewDistributedLockStore(s, nbCluster), nil
+	}
+
+	return s, nil
+}
+
 func getInstallationID(ctx context.Context, store store.Store) (string, error) {
 	installationID := store.GetInstallationID()
 	if installationID != "" {
@@ -495,6 +725,12 @@ func loadMgmtConfig(ctx context.Context, mgmtConfigPath string) (*types.Config,
 	if mgmtLetsencryptDomain != "" {
 		loadedConfig.HttpConfig.LetsEncryptDomain = mgmtLetsencryptDomain
 	}
+	if mgmtDNSProvider != "" {
+		loadedConfig.HttpConfig.LetsEncryptDNSProvider = mgmtDNSProvider
+	}
+	if mgmtLetsencryptEmail != "" {
+		loadedConfig.HttpConfig.LetsEncryptEmail = mgmtLetsencryptEmail
+	}
 	if mgmtDataDir != "" {
 		loadedConfig.Datadir = mgmtDataDir
 	}
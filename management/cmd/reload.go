@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/management/server"
+	"github.com/netbirdio/netbird/management/server/types"
+)
+
+const adminReloadPath = "/config/reload"
+
+// reloadConfig reads the config file again and applies it to the running server
+func reloadConfig(ctx context.Context, reloader *server.ConfigReloader) error {
+	newConfig, err := loadMgmtConfig(ctx, types.MgmtConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed reading config file %s: %w", types.MgmtConfigPath, err)
+	}
+
+	if idpSignKeyRefreshEnabled && newConfig.HttpConfig != nil {
+		newConfig.HttpConfig.IdpSignKeyRefreshEnabled = idpSignKeyRefreshEnabled
+	}
+
+	return reloader.Reload(ctx, newConfig)
+}
+
+// handleReloadSignal reloads the config whenever the process receives SIGHUP
+func handleReloadSignal(ctx context.Context, reloader *server.ConfigReloader) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(c)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-c:
+				log.WithContext(ctx).Infof("received SIGHUP, reloading config %s", types.MgmtConfigPath)
+				if err := reloadConfig(ctx, reloader); err != nil {
+					log.WithContext(ctx).Errorf("failed to reload config, keeping the running one: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// serveAdmin serves the admin API on the address, it should only be reachable by the operators of the server.
+// A POST request to /config/reload reloads the config file.
+func serveAdmin(ctx context.Context, address string, reloader *server.ConfigReloader) (*http.Server, error) {
+	mux := http.NewServeMux()
+	mux.HandleFunc(adminReloadPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if err := reloadConfig(ctx, reloader); err != nil {
+			log.WithContext(ctx).Errorf("failed to reload config, keeping the running one: %v", err)
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating admin listener on %s: %v", address, err)
+	}
+
+	srv := &http.Server{Handler: mux}
+	go func() {
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.WithContext(ctx).Errorf("failed to serve admin API: %v", err)
+		}
+	}()
+
+	log.WithContext(ctx).Infof("running admin API: %s", listener.Addr().String())
+
+	return srv, nil
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/netbirdio/netbird/encryption"
 	"github.com/netbirdio/netbird/management/server/types"
 	"github.com/netbirdio/netbird/version"
 )
@@ -58,6 +59,8 @@ func init() {
 	mgmtCmd.Flags().StringVar(&mgmtDataDir, "datadir", defaultMgmtDataDir, "server data directory location")
 	mgmtCmd.Flags().StringVar(&types.MgmtConfigPath, "config", defaultMgmtConfig, "Netbird config file location. Config params specified via command line (e.g. datadir) have a precedence over configuration from this file")
 	mgmtCmd.Flags().StringVar(&mgmtLetsencryptDomain, "letsencrypt-domain", "", "a domain to issue Let's Encrypt certificate for. Enables TLS using Let's Encrypt. Will fetch and renew certificate, and run the server with TLS")
+	mgmtCmd.Flags().StringVar(&mgmtLetsencryptEmail, "letsencrypt-email", "", "email address to use for Let's Encrypt certificate registration with the DNS challenge")
+	mgmtCmd.Flags().StringVar(&mgmtDNSProvider, "letsencrypt-dns-provider", "", fmt.Sprintf("use the DNS provider for Let's Encrypt DNS challenge instead of serving the HTTP challenge, one of %v. The provider credentials are read from the environment", encryption.DNSProviders()))
 	mgmtCmd.Flags().StringVar(&mgmtSingleAccModeDomain, "single-account-mode-domain", defaultSingleAccModeDomain, "Enables single account mode. This means that all the users will be under the same account grouped by the specified domain. If the installation has more than one account, the property is ineffective. Enabled by default with the default domain "+defaultSingleAccModeDomain)
 	mgmtCmd.Flags().BoolVar(&disableSingleAccMode, "disable-single-account-mode", false, "If set to true, disables single account mode. The --single-account-mode-domain property will be ignored and every new user will have a separate NetBird account.")
 	mgmtCmd.Flags().StringVar(&certFile, "cert-file", "", "Location of your SSL certificate. Can be used when you have an existing certificate and don't want a new certificate be generated automatically. If letsencrypt-domain is specified this property has no effect")
@@ -67,6 +70,9 @@ func init() {
 	mgmtCmd.Flags().BoolVar(&idpSignKeyRefreshEnabled, idpSignKeyRefreshEnabledFlagName, false, "Enable cache headers evaluation to determine signing key rotation period. This will refresh the signing key upon expiry.")
 	mgmtCmd.Flags().BoolVar(&userDeleteFromIDPEnabled, "user-delete-from-idp", false, "Allows to delete user from IDP when user is deleted from account")
 	mgmtCmd.Flags().BoolVar(&disableGeoliteUpdate, "disable-geolite-update", true, "disables automatic updates to the Geolite2 geolocation databases")
+	mgmtCmd.Flags().BoolVar(&allInOneMode, "all-in-one", false, "Serves the signal and relay services with the management service on the same port, with the same TLS config and metrics. The peers are pointed to them unless Signal and Relay are configured")
+	mgmtCmd.Flags().StringVar(&exposedAddress, "exposed-address", "", "Address the peers reach the server at in all-in-one mode, e.g. netbird.example.com:443. Defaults to the Let's Encrypt domain and the server port")
+	mgmtCmd.Flags().StringVar(&adminAddress, "admin-address", "", "Address of the admin API, e.g. 127.0.0.1:33074. A POST request to /config/reload reloads the config file, which can also be triggered with SIGHUP. The API has no authentication and is disabled when empty")
 	rootCmd.MarkFlagRequired("config") //nolint
 
 	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "")
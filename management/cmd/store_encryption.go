@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/netbirdio/netbird/management/server/store"
+	"github.com/netbirdio/netbird/management/server/store/crypt"
+	"github.com/netbirdio/netbird/management/server/types"
+)
+
+var (
+	encryptionDataDir            string
+	encryptionRotateDataKey      bool
+	encryptionRewrapIdentityFile string
+
+	storeCmd = &cobra.Command{
+		Use:          "store",
+		Short:        "Contains sub-commands to manage the encryption of the secrets in the store",
+		Long:         "",
+		SilenceUsage: true,
+	}
+
+	storeReencryptCmd = &cobra.Command{
+		Use:   "reencrypt [--rotate-data-key] [--rewrap-age-identity-file file]",
+		Short: "Encrypt the secrets of the store with the active data key",
+		Long: "Encrypt the secrets of the store, e.g. the webhook secrets, with the active data key of the store " +
+			"encryption configured in StoreConfig.Encryption. The secrets written before the encryption was enabled " +
+			"and the secrets of the previous data keys are rewritten. With --rotate-data-key a new data key is " +
+			"activated first. With --rewrap-age-identity-file the data keys are wrapped with the age identity of the " +
+			"file afterward, the config has to point to the new identity file before the next start. The management " +
+			"server can keep running meanwhile.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, s, err := openConfiguredStore(cmd, encryptionDataDir)
+			if err != nil {
+				return err
+			}
+			defer s.Close(ctx) //nolint
+
+			if crypt.GetCipher() == nil {
+				return fmt.Errorf("the store encryption isn't configured, set StoreConfig.Encryption in %s", types.MgmtConfigPath)
+			}
+
+			count, err := s.ReencryptSecrets(ctx, encryptionRotateDataKey)
+			if err != nil {
+				return fmt.Errorf("re-encrypt secrets after %d values: %w", count, err)
+			}
+			log.WithContext(ctx).Infof("re-encrypted %d secrets", count)
+
+			if encryptionRewrapIdentityFile == "" {
+				return nil
+			}
+			wrapper, err := crypt.NewAgeWrapper(encryptionRewrapIdentityFile)
+			if err != nil {
+				return err
+			}
+			return s.RewrapDataKeys(ctx, wrapper)
+		},
+	}
+)
+
+func init() {
+	storeCmd.PersistentFlags().StringVar(&types.MgmtConfigPath, "config", defaultMgmtConfig, "Netbird config file location, the store of the config is used")
+	storeCmd.PersistentFlags().StringVar(&encryptionDataDir, "datadir", "", "server data directory location, overrides the data directory of the config")
+
+	storeReencryptCmd.Flags().BoolVar(&encryptionRotateDataKey, "rotate-data-key", false, "activate a new data key before re-encrypting the secrets")
+	storeReencryptCmd.Flags().StringVar(&encryptionRewrapIdentityFile, "rewrap-age-identity-file", "", "wrap the data keys with the age identity of the file")
+
+	storeCmd.AddCommand(storeReencryptCmd)
+
+	rootCmd.AddCommand(storeCmd)
+}
+
+// setupStoreEncryption encrypts the secrets of the store with the key encryption key of the config, if configured
+func setupStoreEncryption(ctx context.Context, s store.Store, config *types.StoreEncryptionConfig) error {
+	wrapper, err := store.NewKeyWrapper(config)
+	if err != nil {
+		return fmt.Errorf("store encryption: %w", err)
+	}
+	if wrapper == nil {
+		return nil
+	}
+
+	if err = s.SetupEncryption(ctx, wrapper); err != nil {
+		return fmt.Errorf("set up store encryption: %w", err)
+	}
+	return nil
+}
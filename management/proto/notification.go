@@ -0,0 +1,22 @@
+package proto
+
+// The categories of the notifications the clients show to their users, the management service disables them with the
+// disabledNotifications of the PeerConfig
+const (
+	// NotificationSessionExpiry notifies the user before and when the login of the peer expires
+	NotificationSessionExpiry = "session_expiry"
+	// NotificationPostureCheck notifies the user about the posture checks the peer fails
+	NotificationPostureCheck = "posture_check"
+	// NotificationExitNode notifies the user when the exit node becomes unreachable
+	NotificationExitNode = "exit_node"
+	// NotificationRouteApproval notifies the user about the routes waiting for the approval of the user
+	NotificationRouteApproval = "route_approval"
+)
+
+// Notifications are all the notification categories
+var Notifications = []string{
+	NotificationSessionExpiry,
+	NotificationPostureCheck,
+	NotificationExitNode,
+	NotificationRouteApproval,
+}
@@ -0,0 +1,30 @@
+package proto
+
+import (
+	"strconv"
+	"strings"
+)
+
+// The actions a peer can be asked to run with a PeerAction
+const (
+	// PeerActionRestart restarts the engine, recreating the interface and the connections to the peers
+	PeerActionRestart = "restart"
+	// PeerActionReauth logs the peer in to the management service again, the interface is kept
+	PeerActionReauth = "reauth"
+	// PeerActionRotateKey replaces the WireGuard key of the peer
+	PeerActionRotateKey = "rotate_key"
+	// PeerActionCollectStatus reports the status of the peer
+	PeerActionCollectStatus = "collect_status"
+)
+
+// PeerActionSignedData returns the data the signature of the action is computed over. It binds the action to the
+// WireGuard public key of the peer, so a signed action can't be replayed to another peer.
+func PeerActionSignedData(peerKey string, action *PeerAction) []byte {
+	return []byte(strings.Join([]string{
+		"netbird-peer-action",
+		peerKey,
+		action.GetId(),
+		action.GetAction(),
+		strconv.FormatInt(action.GetExpiresAt().AsTime().Unix(), 10),
+	}, "\n"))
+}
@@ -0,0 +1,121 @@
+package accesshistory
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/management/server/accesshistory/types"
+	"github.com/netbirdio/netbird/management/server/permissions"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
+	"github.com/netbirdio/netbird/management/server/topology"
+	nbtypes "github.com/netbirdio/netbird/management/server/types"
+)
+
+const (
+	// DefaultPeriod is the period of the snapshots listed when the request doesn't define it
+	DefaultPeriod = 30 * 24 * time.Hour
+	// MaxPeriod is the longest period of the snapshots listed by a request
+	MaxPeriod = 366 * 24 * time.Hour
+)
+
+type Manager interface {
+	// GetAccessAt returns the snapshot of the access configuration effective at the time and the connectivity graph
+	// reconstructed from it, or the graph of the peer if the peer ID isn't empty
+	GetAccessAt(ctx context.Context, accountID, userID string, at time.Time, peerID string) (*types.Snapshot, *topology.Topology, error)
+	// GetSnapshots returns the snapshots of the account taken in the period without their data, the earliest first.
+	// The period ends now and starts DefaultPeriod before its end if the times are zero.
+	GetSnapshots(ctx context.Context, accountID, userID string, from, to time.Time) ([]*types.Snapshot, error)
+}
+
+type managerImpl struct {
+	store              store.Store
+	permissionsManager permissions.Manager
+}
+
+type mockManager struct {
+}
+
+func NewManager(store store.Store, permissionsManager permissions.Manager) Manager {
+	return &managerImpl{
+		store:              store,
+		permissionsManager: permissionsManager,
+	}
+}
+
+func (m *managerImpl) GetAccessAt(ctx context.Context, accountID, userID string, at time.Time, peerID string) (*types.Snapshot, *topology.Topology, error) {
+	if err := m.validatePermissions(ctx, accountID, userID); err != nil {
+		return nil, nil, err
+	}
+
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	snapshot, err := m.store.GetAccessSnapshotAt(ctx, store.LockingStrengthShare, accountID, at)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	state, err := Restore(snapshot)
+	if err != nil {
+		log.WithContext(ctx).Errorf("failed to restore access snapshot of account %s: %v", accountID, err)
+		return nil, nil, status.Errorf(status.Internal, "failed to restore access snapshot")
+	}
+
+	if peerID != "" && state.Account.GetPeer(peerID) == nil {
+		return nil, nil, status.Errorf(status.NotFound, "peer %s didn't exist at %s", peerID, at.UTC().Format(time.RFC3339))
+	}
+
+	nbtypes.FlattenNestedGroups(state.Account.Groups)
+
+	return snapshot, topology.Build(ctx, state.Account, state.ValidatedPeers, peerID), nil
+}
+
+func (m *managerImpl) GetSnapshots(ctx context.Context, accountID, userID string, from, to time.Time) ([]*types.Snapshot, error) {
+	if err := m.validatePermissions(ctx, accountID, userID); err != nil {
+		return nil, err
+	}
+
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() {
+		from = to.Add(-DefaultPeriod)
+	}
+
+	if from.After(to) {
+		return nil, status.Errorf(status.InvalidArgument, "snapshot period can't start after its end")
+	}
+	if to.Sub(from) > MaxPeriod {
+		return nil, status.Errorf(status.InvalidArgument, "snapshot period can't exceed %d days", int(MaxPeriod.Hours()/24))
+	}
+
+	return m.store.GetAccessSnapshots(ctx, store.LockingStrengthShare, accountID, from, to)
+}
+
+// validatePermissions checks that the user can read the policies, the access history shows the policies of the past
+func (m *managerImpl) validatePermissions(ctx context.Context, accountID, userID string) error {
+	ok, err := m.permissionsManager.ValidateUserPermissions(ctx, accountID, userID, permissions.Policies, permissions.Read)
+	if err != nil {
+		return status.NewPermissionValidationError(err)
+	}
+	if !ok {
+		return status.NewPermissionDeniedError()
+	}
+	return nil
+}
+
+func NewManagerMock() Manager {
+	return &mockManager{}
+}
+
+func (m *mockManager) GetAccessAt(ctx context.Context, accountID, userID string, at time.Time, peerID string) (*types.Snapshot, *topology.Topology, error) {
+	return &types.Snapshot{AccountID: accountID, Timestamp: at}, &topology.Topology{}, nil
+}
+
+func (m *mockManager) GetSnapshots(ctx context.Context, accountID, userID string, from, to time.Time) ([]*types.Snapshot, error) {
+	return []*types.Snapshot{}, nil
+}
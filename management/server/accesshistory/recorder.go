@@ -0,0 +1,170 @@
+package accesshistory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
+)
+
+// SnapshotDelay is the interval the pending snapshots are taken at, the activity events of an account in the interval
+// are covered by one snapshot
+const SnapshotDelay = 10 * time.Second
+
+// PeerValidator returns the peers of the account that receive a network map
+type PeerValidator interface {
+	GetValidatedPeers(ctx context.Context, accountID string) (map[string]struct{}, error)
+}
+
+// pendingSnapshot is a snapshot of an account to take for the events received since the last one
+type pendingSnapshot struct {
+	timestamp time.Time
+	events    int
+}
+
+// Recorder takes the snapshots of the access configuration of the accounts as an activity.Sink. Every activity event
+// of an account schedules a snapshot, the snapshot is stored only if the configuration changed.
+// With several management nodes every node takes the snapshots for the events of the changes it made.
+type Recorder struct {
+	store store.Store
+
+	mu        sync.Mutex
+	validator PeerValidator
+	pending   map[string]*pendingSnapshot
+}
+
+// NewRecorder creates a Recorder, Start takes the snapshots
+func NewRecorder(store store.Store) *Recorder {
+	return &Recorder{
+		store:   store,
+		pending: make(map[string]*pendingSnapshot),
+	}
+}
+
+// SetPeerValidator sets the validator of the peers, all the peers are stored as validated without it
+func (r *Recorder) SetPeerValidator(validator PeerValidator) {
+	r.mu.Lock()
+	r.validator = validator
+	r.mu.Unlock()
+}
+
+// Publish schedules a snapshot of the account of the event
+func (r *Recorder) Publish(_ context.Context, event *activity.Event) error {
+	if event.AccountID == "" {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pending, ok := r.pending[event.AccountID]
+	if !ok {
+		pending = &pendingSnapshot{}
+		r.pending[event.AccountID] = pending
+	}
+	if event.Timestamp.After(pending.timestamp) {
+		pending.timestamp = event.Timestamp
+	}
+	pending.events++
+
+	return nil
+}
+
+// Close takes the pending snapshots
+func (r *Recorder) Close(ctx context.Context) error {
+	r.record(ctx)
+	return nil
+}
+
+// Start takes the pending snapshots every SnapshotDelay until the context is done
+func (r *Recorder) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(SnapshotDelay)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.record(ctx)
+			}
+		}
+	}()
+}
+
+// record takes the snapshots of the accounts with events since the last call
+func (r *Recorder) record(ctx context.Context) {
+	r.mu.Lock()
+	pending := r.pending
+	r.pending = make(map[string]*pendingSnapshot)
+	r.mu.Unlock()
+
+	for accountID, p := range pending {
+		if err := r.snapshot(ctx, accountID, p); err != nil {
+			log.WithContext(ctx).Errorf("failed to take access snapshot of account %s: %v", accountID, err)
+		}
+	}
+}
+
+func (r *Recorder) snapshot(ctx context.Context, accountID string, pending *pendingSnapshot) error {
+	account, err := r.store.GetAccount(ctx, accountID)
+	if err != nil {
+		if s, ok := status.FromError(err); ok && s.Type() == status.NotFound {
+			// the account was deleted, its snapshots are kept for the audit
+			return nil
+		}
+		return err
+	}
+
+	r.mu.Lock()
+	validator := r.validator
+	r.mu.Unlock()
+
+	var validatedPeers map[string]struct{}
+	if validator != nil {
+		validatedPeers, err = validator.GetValidatedPeers(ctx, accountID)
+		if err != nil {
+			return err
+		}
+	} else {
+		validatedPeers = make(map[string]struct{}, len(account.Peers))
+		for peerID := range account.Peers {
+			validatedPeers[peerID] = struct{}{}
+		}
+	}
+
+	snapshot, err := NewSnapshot(account, validatedPeers, pending.timestamp, pending.events)
+	if err != nil {
+		return err
+	}
+
+	latestHash, err := r.latestHash(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	if latestHash == snapshot.Hash {
+		return nil
+	}
+
+	return r.store.SaveAccessSnapshot(ctx, store.LockingStrengthUpdate, snapshot)
+}
+
+// latestHash returns the hash of the latest snapshot of the account, it is empty if there is none. The hash is read
+// from the store because other management nodes store snapshots of the account as well.
+func (r *Recorder) latestHash(ctx context.Context, accountID string) (string, error) {
+	latest, err := r.store.GetAccessSnapshotAt(ctx, store.LockingStrengthShare, accountID, time.Now())
+	if err != nil {
+		if s, ok := status.FromError(err); ok && s.Type() == status.NotFound {
+			return "", nil
+		}
+		return "", err
+	}
+
+	return latest.Hash, nil
+}
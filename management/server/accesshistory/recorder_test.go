@@ -0,0 +1,85 @@
+package accesshistory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/permissions"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
+)
+
+const (
+	testAccountID = "bf1c8084-ba50-4ce7-9439-34653001fc3b"
+	testAdminID   = "edafee4e-63fb-11ec-90d6-0242ac120003"
+	testRegularID = "f4f6d672-63fb-11ec-90d6-0242ac120003"
+	testPeerID    = "ct286bi7qv930dsrrug0"
+	testPolicyID  = "cs1tnh0hhcjnqoiuebf0"
+)
+
+func TestRecorder_Snapshots(t *testing.T) {
+	ctx := context.Background()
+
+	s, cleanUp, err := store.NewTestStoreFromSQL(ctx, "../testdata/store.sql", t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(cleanUp)
+
+	recorder := NewRecorder(s)
+	start := time.Now().UTC().Add(-time.Hour)
+
+	require.NoError(t, recorder.Publish(ctx, &activity.Event{AccountID: testAccountID, Timestamp: start}))
+	require.NoError(t, recorder.Publish(ctx, &activity.Event{AccountID: testAccountID, Timestamp: start.Add(time.Second)}))
+	recorder.record(ctx)
+
+	// the configuration didn't change, no snapshot is stored
+	require.NoError(t, recorder.Publish(ctx, &activity.Event{AccountID: testAccountID, Timestamp: start.Add(time.Minute)}))
+	recorder.record(ctx)
+
+	policy, err := s.GetPolicyByID(ctx, store.LockingStrengthShare, testAccountID, testPolicyID)
+	require.NoError(t, err)
+	policy.Enabled = false
+	require.NoError(t, s.SavePolicy(ctx, store.LockingStrengthUpdate, policy))
+
+	require.NoError(t, recorder.Publish(ctx, &activity.Event{AccountID: testAccountID, Timestamp: start.Add(2 * time.Minute)}))
+	require.NoError(t, recorder.Close(ctx))
+
+	manager := NewManager(s, permissions.NewManager(s))
+
+	_, err = manager.GetSnapshots(ctx, testAccountID, testRegularID, time.Time{}, time.Time{})
+	sErr, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, status.PermissionDenied, sErr.Type())
+
+	snapshots, err := manager.GetSnapshots(ctx, testAccountID, testAdminID, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	assert.Equal(t, 2, snapshots[0].Events)
+	assert.Empty(t, snapshots[0].Data, "listed snapshots shouldn't contain the data")
+	assert.NotEqual(t, snapshots[0].Hash, snapshots[1].Hash)
+
+	snapshot, graph, err := manager.GetAccessAt(ctx, testAccountID, testAdminID, start.Add(90*time.Second), testPeerID)
+	require.NoError(t, err)
+	assert.Equal(t, snapshots[0].ID, snapshot.ID)
+	require.Len(t, graph.Peers, 1)
+	assert.False(t, graph.Peers[0].Connected, "connection status shouldn't be stored")
+
+	state, err := Restore(snapshot)
+	require.NoError(t, err)
+	require.Len(t, state.Account.Policies, 1)
+	assert.True(t, state.Account.Policies[0].Enabled, "policy should have been enabled before the change")
+	assert.Empty(t, state.Account.Users)
+
+	snapshot, _, err = manager.GetAccessAt(ctx, testAccountID, testAdminID, time.Time{}, "")
+	require.NoError(t, err)
+	assert.Equal(t, snapshots[1].ID, snapshot.ID)
+
+	_, _, err = manager.GetAccessAt(ctx, testAccountID, testAdminID, start.Add(-time.Second), "")
+	sErr, ok = status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, status.NotFound, sErr.Type())
+}
@@ -0,0 +1,87 @@
+package accesshistory
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/rs/xid"
+
+	"github.com/netbirdio/netbird/management/server/accesshistory/types"
+	nbtypes "github.com/netbirdio/netbird/management/server/types"
+)
+
+// State is the access configuration of an account stored in a snapshot
+type State struct {
+	Account *nbtypes.Account `json:"account"`
+	// ValidatedPeers are the peers that received a network map, the others were pending approval
+	ValidatedPeers map[string]struct{} `json:"validated_peers"`
+}
+
+// NewSnapshot creates the snapshot of the access configuration of the account. The users and setup keys don't
+// define access and the connection status of the peers changes without an activity event, so they are removed from
+// the account before it is stored. The metadata and location of the peers are kept for the posture checks.
+// The account is modified.
+func NewSnapshot(account *nbtypes.Account, validatedPeers map[string]struct{}, timestamp time.Time, events int) (*types.Snapshot, error) {
+	account.Users = nil
+	account.SetupKeys = nil
+	for _, peer := range account.Peers {
+		if peer.Status != nil {
+			peer.Status.Connected = false
+			peer.Status.LastSeen = time.Time{}
+		}
+	}
+
+	data, err := json.Marshal(&State{Account: account, ValidatedPeers: validatedPeers})
+	if err != nil {
+		return nil, fmt.Errorf("marshal access configuration: %w", err)
+	}
+	hash := sha256.Sum256(data)
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err = writer.Write(data); err != nil {
+		return nil, fmt.Errorf("compress access configuration: %w", err)
+	}
+	if err = writer.Close(); err != nil {
+		return nil, fmt.Errorf("compress access configuration: %w", err)
+	}
+
+	return &types.Snapshot{
+		ID:        xid.New().String(),
+		AccountID: account.Id,
+		Timestamp: timestamp.UTC(),
+		Hash:      hex.EncodeToString(hash[:]),
+		Events:    events,
+		Data:      compressed.Bytes(),
+	}, nil
+}
+
+// Restore returns the access configuration stored in the snapshot
+func Restore(snapshot *types.Snapshot) (*State, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(snapshot.Data))
+	if err != nil {
+		return nil, fmt.Errorf("decompress access snapshot %s: %w", snapshot.ID, err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("decompress access snapshot %s: %w", snapshot.ID, err)
+	}
+
+	state := &State{}
+	if err = json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("unmarshal access snapshot %s: %w", snapshot.ID, err)
+	}
+	if state.Account == nil {
+		return nil, fmt.Errorf("access snapshot %s has no account", snapshot.ID)
+	}
+
+	return state, nil
+}
@@ -0,0 +1,37 @@
+package types
+
+import (
+	"time"
+
+	"github.com/netbirdio/netbird/management/server/http/api"
+)
+
+// Snapshot is a version of the access configuration of an account: its peers, groups, policies, routes, networks,
+// posture checks and settings. A snapshot is taken after the activity events changing the account, the
+// configuration is effective from its timestamp until the timestamp of the next snapshot.
+type Snapshot struct {
+	ID        string `gorm:"primaryKey"`
+	AccountID string `gorm:"index:idx_access_snapshots_account_time"`
+	// Timestamp is the time of the last activity event covered by the snapshot
+	Timestamp time.Time `gorm:"index:idx_access_snapshots_account_time"`
+	// Hash is the SHA-256 hash of the uncompressed configuration, a snapshot isn't stored if the hash is unchanged
+	Hash string
+	// Events is the number of activity events covered by the snapshot
+	Events int
+	// Data is the gzip compressed JSON of the configuration, it isn't loaded when the snapshots are listed
+	Data []byte
+}
+
+// TableName returns the table of the access snapshots
+func (Snapshot) TableName() string {
+	return "access_snapshots"
+}
+
+func (s *Snapshot) ToAPIResponse() *api.AccessSnapshot {
+	return &api.AccessSnapshot{
+		Id:        s.ID,
+		Timestamp: s.Timestamp.UTC(),
+		Hash:      s.Hash,
+		Events:    s.Events,
+	}
+}
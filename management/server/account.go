@@ -23,11 +23,13 @@ import (
 	"github.com/netbirdio/netbird/management/server/account"
 	"github.com/netbirdio/netbird/management/server/activity"
 	nbcache "github.com/netbirdio/netbird/management/server/cache"
+	"github.com/netbirdio/netbird/management/server/cluster"
 	nbcontext "github.com/netbirdio/netbird/management/server/context"
 	"github.com/netbirdio/netbird/management/server/geolocation"
 	"github.com/netbirdio/netbird/management/server/idp"
 	"github.com/netbirdio/netbird/management/server/integrations/integrated_validator"
 	"github.com/netbirdio/netbird/management/server/integrations/port_forwarding"
+	"github.com/netbirdio/netbird/management/server/notifications"
 	nbpeer "github.com/netbirdio/netbird/management/server/peer"
 	"github.com/netbirdio/netbird/management/server/permissions"
 	"github.com/netbirdio/netbird/management/server/posture"
@@ -84,6 +86,13 @@ type DefaultAccountManager struct {
 
 	peerInactivityExpiry Scheduler
 
+	peerLoginExpiryNotice Scheduler
+	// notifiedPeerLogins holds the login expiration time the user of a peer was last notified about, by peer ID
+	notifiedPeerLogins sync.Map
+
+	// rolloutChecks checks the active rollouts of the accounts
+	rolloutChecks Scheduler
+
 	// userDeleteFromIDPEnabled allows to delete user from IDP when user is deleted from account
 	userDeleteFromIDPEnabled bool
 
@@ -92,6 +101,12 @@ type DefaultAccountManager struct {
 	metrics telemetry.AppMetrics
 
 	permissionsManager permissions.Manager
+
+	notifier   notifications.Notifier
+	notifierMu sync.RWMutex
+
+	// cluster connects the management servers sharing the store
+	cluster cluster.Cluster
 }
 
 // getJWTGroupsChanges calculates the changes needed to sync a user's JWT groups.
@@ -178,6 +193,8 @@ func BuildManager(
 		eventStore:               eventStore,
 		peerLoginExpiry:          NewDefaultScheduler(),
 		peerInactivityExpiry:     NewDefaultScheduler(),
+		peerLoginExpiryNotice:    NewDefaultScheduler(),
+		rolloutChecks:            NewDefaultScheduler(),
 		userDeleteFromIDPEnabled: userDeleteFromIDPEnabled,
 		integratedPeerValidator:  integratedPeerValidator,
 		metrics:                  metrics,
@@ -225,6 +242,8 @@ func BuildManager(
 		am.onPeersInvalidated(ctx, accountID)
 	})
 
+	go am.scheduleActiveRollouts(ctx)
+
 	return am, nil
 }
 
@@ -236,6 +255,21 @@ func (am *DefaultAccountManager) GetIdpManager() idp.Manager {
 	return am.idpManager
 }
 
+// hasCustomRolePermission checks whether one of the custom roles of a regular user grants the operation on the module
+func (am *DefaultAccountManager) hasCustomRolePermission(ctx context.Context, accountID string, user *types.User, module permissions.Module, operation permissions.Operation) bool {
+	if !user.IsRegularUser() {
+		return false
+	}
+
+	allowed, err := am.permissionsManager.ValidateUserPermissions(ctx, accountID, user.Id, module, operation)
+	if err != nil {
+		log.WithContext(ctx).Errorf("failed to validate permissions of user %s: %v", user.Id, err)
+		return false
+	}
+
+	return allowed
+}
+
 // UpdateAccountSettings updates Account settings.
 // Only users with role UserRoleAdmin can update the account.
 // User that performs the update has to belong to the account.
@@ -250,6 +284,32 @@ func (am *DefaultAccountManager) UpdateAccountSettings(ctx context.Context, acco
 		return nil, status.Errorf(status.InvalidArgument, "peer login expiration can't be smaller than one hour")
 	}
 
+	if newSettings.PeerLoginExpirationNotification < 0 || newSettings.PeerLoginExpirationNotification >= newSettings.PeerLoginExpiration {
+		return nil, status.Errorf(status.InvalidArgument, "peer login expiration notification must be shorter than the peer login expiration")
+	}
+
+	for _, rule := range newSettings.JWTGroupsMappingRules {
+		if err := rule.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	if newSettings.ConnectionTuning != nil {
+		if err := newSettings.ConnectionTuning.Validate(); err != nil {
+			return nil, status.Errorf(status.InvalidArgument, "invalid connection tuning: %s", err)
+		}
+	}
+
+	if newSettings.ClientSettings != nil {
+		if err := newSettings.ClientSettings.Validate(); err != nil {
+			return nil, status.Errorf(status.InvalidArgument, "invalid client settings: %s", err)
+		}
+	}
+
+	if err := types.ValidateDisabledNotifications(newSettings.DisabledNotifications); err != nil {
+		return nil, status.Errorf(status.InvalidArgument, "invalid disabled notifications: %s", err)
+	}
+
 	unlock := am.Store.AcquireWriteLockByUID(ctx, accountID)
 	defer unlock()
 
@@ -290,6 +350,21 @@ func (am *DefaultAccountManager) UpdateAccountSettings(ctx context.Context, acco
 	}
 
 	updateAccountPeers := false
+	if oldSettings.PeerLoginExpirationGraceEnabled != newSettings.PeerLoginExpirationGraceEnabled {
+		event := activity.AccountPeerLoginExpirationGraceEnabled
+		if !newSettings.PeerLoginExpirationGraceEnabled {
+			event = activity.AccountPeerLoginExpirationGraceDisabled
+		}
+		am.StoreEvent(ctx, userID, accountID, accountID, event, nil)
+		updateAccountPeers = true
+	}
+
+	notificationChanged := oldSettings.PeerLoginExpirationNotification != newSettings.PeerLoginExpirationNotification
+	if notificationChanged {
+		am.StoreEvent(ctx, userID, accountID, accountID, activity.AccountPeerLoginExpirationNotificationUpdated, nil)
+		updateAccountPeers = true
+	}
+
 	if oldSettings.RoutingPeerDNSResolutionEnabled != newSettings.RoutingPeerDNSResolutionEnabled {
 		if newSettings.RoutingPeerDNSResolutionEnabled {
 			am.StoreEvent(ctx, userID, accountID, accountID, activity.AccountRoutingPeerDNSResolutionEnabled, nil)
@@ -300,6 +375,36 @@ func (am *DefaultAccountManager) UpdateAccountSettings(ctx context.Context, acco
 		account.Network.Serial++
 	}
 
+	if oldSettings.RoutingPeerForwardedTrafficFilteringEnabled != newSettings.RoutingPeerForwardedTrafficFilteringEnabled {
+		event := activity.AccountRoutingPeerForwardedTrafficFilteringEnabled
+		if !newSettings.RoutingPeerForwardedTrafficFilteringEnabled {
+			event = activity.AccountRoutingPeerForwardedTrafficFilteringDisabled
+		}
+		am.StoreEvent(ctx, userID, accountID, accountID, event, nil)
+		updateAccountPeers = true
+		account.Network.Serial++
+	}
+
+	if !reflect.DeepEqual(oldSettings.ConnectionTuning, newSettings.ConnectionTuning) {
+		am.StoreEvent(ctx, userID, accountID, accountID, activity.AccountConnectionTuningUpdated, nil)
+		updateAccountPeers = true
+		account.Network.Serial++
+	}
+
+	if !reflect.DeepEqual(oldSettings.ClientSettings, newSettings.ClientSettings) {
+		am.StoreEvent(ctx, userID, accountID, accountID, activity.AccountClientSettingsUpdated, nil)
+		updateAccountPeers = true
+		account.Network.Serial++
+	}
+
+	if !slices.Equal(oldSettings.DisabledNotifications, newSettings.DisabledNotifications) {
+		am.StoreEvent(ctx, userID, accountID, accountID, activity.AccountNotificationSettingsUpdated, map[string]any{
+			"disabled_notifications": newSettings.DisabledNotifications,
+		})
+		updateAccountPeers = true
+		account.Network.Serial++
+	}
+
 	err = am.handleInactivityExpirationSettings(ctx, oldSettings, newSettings, userID, accountID)
 	if err != nil {
 		return nil, err
@@ -322,6 +427,10 @@ func (am *DefaultAccountManager) UpdateAccountSettings(ctx context.Context, acco
 		return nil, err
 	}
 
+	if notificationChanged {
+		am.checkAndSchedulePeerLoginExpirationNotification(ctx, accountID)
+	}
+
 	if updateAccountPeers || extraSettingsChanged {
 		go am.UpdateAccountPeers(ctx, accountID)
 	}
@@ -383,7 +492,7 @@ func (am *DefaultAccountManager) peerLoginExpirationJob(ctx context.Context, acc
 
 		log.WithContext(ctx).Debugf("discovered %d peers to expire for account %s", len(peerIDs), accountID)
 
-		if err := am.expireAndUpdatePeers(ctx, accountID, expiredPeers); err != nil {
+		if err := am.expireAndUpdatePeers(ctx, accountID, expiredPeers, true); err != nil {
 			log.WithContext(ctx).Errorf("failed updating account peers while expiring peers for account %s", accountID)
 			return peerSchedulerRetryInterval, true
 		}
@@ -397,6 +506,7 @@ func (am *DefaultAccountManager) checkAndSchedulePeerLoginExpiration(ctx context
 	if nextRun, ok := am.getNextPeerExpiration(ctx, accountID); ok {
 		go am.peerLoginExpiry.Schedule(ctx, nextRun, accountID, am.peerLoginExpirationJob(ctx, accountID))
 	}
+	am.checkAndSchedulePeerLoginExpirationNotification(ctx, accountID)
 }
 
 // peerInactivityExpirationJob marks login expired for all inactive peers and returns the minimum duration in which the next peer of the account will expire by inactivity if found
@@ -418,7 +528,7 @@ func (am *DefaultAccountManager) peerInactivityExpirationJob(ctx context.Context
 
 		log.Debugf("discovered %d peers to expire for account %s", len(peerIDs), accountID)
 
-		if err := am.expireAndUpdatePeers(ctx, accountID, inactivePeers); err != nil {
+		if err := am.expireAndUpdatePeers(ctx, accountID, inactivePeers, true); err != nil {
 			log.Errorf("failed updating account peers while expiring peers for account %s", accountID)
 			return peerSchedulerRetryInterval, true
 		}
@@ -517,6 +627,13 @@ func (am *DefaultAccountManager) DeleteAccount(ctx context.Context, accountID, u
 		return status.Errorf(status.PermissionDenied, "user is not allowed to delete account. Only account owner can delete account")
 	}
 
+	return am.deleteAccount(ctx, account, userID)
+}
+
+// deleteAccount deletes the account with its users and resources. The caller must hold the account write lock.
+func (am *DefaultAccountManager) deleteAccount(ctx context.Context, account *types.Account, userID string) error {
+	accountID := account.Id
+
 	userInfosMap, err := am.BuildUserInfosForAccount(ctx, accountID, userID, maps.Values(account.Users))
 	if err != nil {
 		return status.Errorf(status.Internal, "failed to build user infos for account %s: %v", accountID, err)
@@ -558,6 +675,7 @@ func (am *DefaultAccountManager) DeleteAccount(ctx context.Context, accountID, u
 	}
 	// cancel peer login expiry job
 	am.peerLoginExpiry.Cancel(ctx, []string{account.Id})
+	am.peerLoginExpiryNotice.Cancel(ctx, []string{account.Id})
 
 	log.WithContext(ctx).Debugf("account %s deleted", accountID)
 	return nil
@@ -1057,6 +1175,10 @@ func (am *DefaultAccountManager) GetAccountIDFromUserAuth(ctx context.Context, u
 	}
 
 	if userAuth.IsChild {
+		// only the admins of the parent account can access a tenant account
+		if err := am.permissionsManager.ValidateAccountAccess(ctx, accountID, user, false); err != nil {
+			return "", "", err
+		}
 		return accountID, user.Id, nil
 	}
 
@@ -1091,8 +1213,8 @@ func (am *DefaultAccountManager) SyncUserJWTGroups(ctx context.Context, userAuth
 		return nil
 	}
 
-	if settings.JWTGroupsClaimName == "" {
-		log.WithContext(ctx).Debugf("JWT groups are enabled but no claim name is set")
+	if settings.JWTGroupsClaimName == "" && len(settings.JWTGroupsMappingRules) == 0 {
+		log.WithContext(ctx).Debugf("JWT groups are enabled but no claim name or mapping rule is set")
 		return nil
 	}
 
@@ -1444,8 +1566,12 @@ func (am *DefaultAccountManager) GetAllConnectedPeers() (map[string]struct{}, er
 	return am.peersUpdateManager.GetAllConnectedPeers(), nil
 }
 
-// HasConnectedChannel returns true if peers has channel in update manager, otherwise false
+// HasConnectedChannel returns true if peers has channel in update manager, otherwise false.
+// In a cluster the channel may be held by another management server, so it is assumed to exist.
 func (am *DefaultAccountManager) HasConnectedChannel(peerID string) bool {
+	if am.cluster != nil && am.cluster.Distributed() {
+		return true
+	}
 	return am.peersUpdateManager.HasChannel(peerID)
 }
 
@@ -1473,7 +1599,7 @@ func (am *DefaultAccountManager) GetAccountIDForPeerKey(ctx context.Context, pee
 	return am.Store.GetAccountIDByPeerPubKey(ctx, peerKey)
 }
 
-func (am *DefaultAccountManager) handleUserPeer(ctx context.Context, transaction store.Store, peer *nbpeer.Peer, settings *types.Settings) (bool, error) {
+func (am *DefaultAccountManager) handleUserPeer(ctx context.Context, transaction store.Store, accountID string, peer *nbpeer.Peer) (bool, error) {
 	user, err := transaction.GetUserByUserID(ctx, store.LockingStrengthShare, peer.UserID)
 	if err != nil {
 		return false, err
@@ -1484,7 +1610,12 @@ func (am *DefaultAccountManager) handleUserPeer(ctx context.Context, transaction
 		return false, err
 	}
 
-	if peerLoginExpired(ctx, peer, settings) {
+	sessionSettings, err := getPeerSessionSettings(ctx, transaction, accountID, peer)
+	if err != nil {
+		return false, err
+	}
+
+	if peerLoginExpired(ctx, peer, sessionSettings) {
 		err = am.handleExpiredPeer(ctx, transaction, user, peer)
 		if err != nil {
 			return false, err
@@ -25,7 +25,7 @@ type Manager interface {
 	GetOrCreateAccountByUser(ctx context.Context, userId, domain string) (*types.Account, error)
 	GetAccount(ctx context.Context, accountID string) (*types.Account, error)
 	CreateSetupKey(ctx context.Context, accountID string, keyName string, keyType types.SetupKeyType, expiresIn time.Duration,
-		autoGroups []string, usageLimit int, userID string, ephemeral bool, allowExtraDNSLabels bool) (*types.SetupKey, error)
+		autoGroups []string, usageLimit int, userID string, ephemeral bool, allowExtraDNSLabels bool, provisioning types.SetupKeyProvisioning) (*types.SetupKey, error)
 	SaveSetupKey(ctx context.Context, accountID string, key *types.SetupKey, userID string) (*types.SetupKey, error)
 	CreateUser(ctx context.Context, accountID, initiatorUserID string, key *types.UserInfo) (*types.UserInfo, error)
 	DeleteUser(ctx context.Context, accountID, initiatorUserID string, targetUserID string) error
@@ -45,19 +45,22 @@ type Manager interface {
 	GetUserFromUserAuth(ctx context.Context, userAuth nbcontext.UserAuth) (*types.User, error)
 	ListUsers(ctx context.Context, accountID string) ([]*types.User, error)
 	GetPeers(ctx context.Context, accountID, userID, nameFilter, ipFilter string) ([]*nbpeer.Peer, error)
+	ListPeers(ctx context.Context, accountID, userID string, filter store.PeerFilter, opts store.ListOptions) ([]*nbpeer.Peer, string, error)
 	MarkPeerConnected(ctx context.Context, peerKey string, connected bool, realIP net.IP, accountID string) error
 	DeletePeer(ctx context.Context, accountID, peerID, userID string) error
 	UpdatePeer(ctx context.Context, accountID, userID string, peer *nbpeer.Peer) (*nbpeer.Peer, error)
 	GetNetworkMap(ctx context.Context, peerID string) (*types.NetworkMap, error)
 	GetPeerNetwork(ctx context.Context, peerID string) (*types.Network, error)
 	AddPeer(ctx context.Context, setupKey, userID string, peer *nbpeer.Peer) (*nbpeer.Peer, *types.NetworkMap, []*posture.Checks, error)
-	CreatePAT(ctx context.Context, accountID string, initiatorUserID string, targetUserID string, tokenName string, expiresIn int) (*types.PersonalAccessTokenGenerated, error)
+	CreatePAT(ctx context.Context, accountID string, initiatorUserID string, targetUserID string, tokenName string, expiresIn int, scopes []string, groups []string) (*types.PersonalAccessTokenGenerated, error)
+	RotatePAT(ctx context.Context, accountID string, initiatorUserID string, targetUserID string, tokenID string, expiresIn int, gracePeriod time.Duration) (*types.PersonalAccessTokenGenerated, error)
 	DeletePAT(ctx context.Context, accountID string, initiatorUserID string, targetUserID string, tokenID string) error
 	GetPAT(ctx context.Context, accountID string, initiatorUserID string, targetUserID string, tokenID string) (*types.PersonalAccessToken, error)
 	GetAllPATs(ctx context.Context, accountID string, initiatorUserID string, targetUserID string) ([]*types.PersonalAccessToken, error)
 	GetUsersFromAccount(ctx context.Context, accountID, userID string) (map[string]*types.UserInfo, error)
 	GetGroup(ctx context.Context, accountId, groupID, userID string) (*types.Group, error)
 	GetAllGroups(ctx context.Context, accountID, userID string) ([]*types.Group, error)
+	ListGroups(ctx context.Context, accountID, userID string, filter store.GroupFilter, opts store.ListOptions) ([]*types.Group, string, error)
 	GetGroupByName(ctx context.Context, groupName, accountID string) (*types.Group, error)
 	SaveGroup(ctx context.Context, accountID, userID string, group *types.Group) error
 	SaveGroups(ctx context.Context, accountID, userID string, newGroups []*types.Group) error
@@ -75,8 +78,14 @@ type Manager interface {
 	SaveRoute(ctx context.Context, accountID, userID string, route *route.Route) error
 	DeleteRoute(ctx context.Context, accountID string, routeID route.ID, userID string) error
 	ListRoutes(ctx context.Context, accountID, userID string) ([]*route.Route, error)
+	StageRouteRollout(ctx context.Context, accountID, userID string, route *route.Route, settings types.RolloutSettings) (*types.Rollout, error)
+	StagePolicyRollout(ctx context.Context, accountID, userID string, policy *types.Policy, settings types.RolloutSettings) (*types.Rollout, error)
+	GetRollout(ctx context.Context, accountID, userID, rolloutID string) (*types.Rollout, error)
+	ListRollouts(ctx context.Context, accountID, userID string) ([]*types.Rollout, error)
+	PromoteRollout(ctx context.Context, accountID, userID, rolloutID string) (*types.Rollout, error)
+	RollbackRollout(ctx context.Context, accountID, userID, rolloutID string) (*types.Rollout, error)
 	GetNameServerGroup(ctx context.Context, accountID, userID, nsGroupID string) (*nbdns.NameServerGroup, error)
-	CreateNameServerGroup(ctx context.Context, accountID string, name, description string, nameServerList []nbdns.NameServer, groups []string, primary bool, domains []string, enabled bool, userID string, searchDomainsEnabled bool) (*nbdns.NameServerGroup, error)
+	CreateNameServerGroup(ctx context.Context, accountID string, name, description string, nameServerList []nbdns.NameServer, groups []string, primary bool, domains []string, enabled bool, userID string, searchDomainsEnabled bool, routedAnswers nbdns.RoutedAnswers, forwarderPeer string) (*nbdns.NameServerGroup, error)
 	SaveNameServerGroup(ctx context.Context, accountID, userID string, nsGroupToSave *nbdns.NameServerGroup) error
 	DeleteNameServerGroup(ctx context.Context, accountID, nsGroupID, userID string) error
 	ListNameServerGroups(ctx context.Context, accountID string, userID string) ([]*nbdns.NameServerGroup, error)
@@ -84,6 +93,7 @@ type Manager interface {
 	StoreEvent(ctx context.Context, initiatorID, targetID, accountID string, activityID activity.ActivityDescriber, meta map[string]any)
 	GetEvents(ctx context.Context, accountID, userID string) ([]*activity.Event, error)
 	GetDNSSettings(ctx context.Context, accountID string, userID string) (*types.DNSSettings, error)
+	GetDNSZone(ctx context.Context, accountID string, userID string) (nbdns.CustomZone, error)
 	SaveDNSSettings(ctx context.Context, accountID string, userID string, dnsSettingsToSave *types.DNSSettings) error
 	GetPeer(ctx context.Context, accountID, peerID, userID string) (*nbpeer.Peer, error)
 	UpdateAccountSettings(ctx context.Context, accountID, userID string, newSettings *types.Settings) (*types.Account, error)
@@ -114,4 +124,14 @@ type Manager interface {
 	CreateAccountByPrivateDomain(ctx context.Context, initiatorId, domain string) (*types.Account, error)
 	UpdateToPrimaryAccount(ctx context.Context, accountId string) (*types.Account, error)
 	GetOwnerInfo(ctx context.Context, accountId string) (*types.UserInfo, error)
+	GetPendingApprovalPeers(ctx context.Context, accountID, userID string) ([]*nbpeer.Peer, error)
+	ApprovePeer(ctx context.Context, accountID, userID, peerID string) (*nbpeer.Peer, error)
+	GetScheduledPeerDeletions(ctx context.Context, accountID, userID string) ([]*types.ScheduledPeerDeletion, error)
+	GetTenants(ctx context.Context, accountID, userID string) ([]*types.Tenant, error)
+	CreateTenant(ctx context.Context, accountID, userID, name, ownerUserID string) (*types.Tenant, error)
+	DeleteTenant(ctx context.Context, accountID, userID, tenantAccountID string) error
+	GetTenantsUsage(ctx context.Context, accountID, userID string) ([]*types.TenantUsage, error)
+	GetAccountNetwork(ctx context.Context, accountID, userID string) (*types.Network, error)
+	UpdateAccountNetwork(ctx context.Context, accountID, userID string, networkRange net.IPNet, reservedRanges []string, dryRun bool) (*types.Network, []*types.PeerIPMigration, error)
+	SetPeerStaticIP(ctx context.Context, accountID, userID, peerID string, ip net.IP) (*nbpeer.Peer, error)
 }
@@ -0,0 +1,230 @@
+package server
+
+import (
+	"context"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/netbirdio/netbird/management/server/activity"
+	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+	"github.com/netbirdio/netbird/management/server/permissions"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
+	"github.com/netbirdio/netbird/management/server/types"
+)
+
+// GetAccountNetwork returns the overlay network of the account
+func (am *DefaultAccountManager) GetAccountNetwork(ctx context.Context, accountID, userID string) (*types.Network, error) {
+	allowed, err := am.permissionsManager.ValidateUserPermissions(ctx, accountID, userID, permissions.Settings, permissions.Read)
+	if err != nil {
+		return nil, status.NewPermissionValidationError(err)
+	}
+	if !allowed {
+		return nil, status.NewPermissionDeniedError()
+	}
+
+	return am.Store.GetAccountNetwork(ctx, store.LockingStrengthShare, accountID)
+}
+
+// UpdateAccountNetwork changes the overlay network range and the reserved ranges of the account.
+// Peers without a static IP that aren't part of the new range are moved to a free IP of it.
+// When dryRun is set, the required peer IP changes are returned without being applied.
+func (am *DefaultAccountManager) UpdateAccountNetwork(ctx context.Context, accountID, userID string, networkRange net.IPNet, reservedRanges []string, dryRun bool) (*types.Network, []*types.PeerIPMigration, error) {
+	if err := types.ValidateNetworkRange(networkRange); err != nil {
+		return nil, nil, err
+	}
+
+	reserved, err := types.ParseReservedRanges(networkRange, reservedRanges)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reservedRanges = make([]string, 0, len(reserved))
+	for _, r := range reserved {
+		reservedRanges = append(reservedRanges, r.String())
+	}
+
+	allowed, err := am.permissionsManager.ValidateUserPermissions(ctx, accountID, userID, permissions.Settings, permissions.Write)
+	if err != nil {
+		return nil, nil, status.NewPermissionValidationError(err)
+	}
+	if !allowed {
+		return nil, nil, status.NewPermissionDeniedError()
+	}
+
+	unlock := am.Store.AcquireWriteLockByUID(ctx, accountID)
+	defer unlock()
+
+	var network *types.Network
+	var migrations []*types.PeerIPMigration
+	var rangeChanged, reservedRangesChanged bool
+	peers := make(map[string]*nbpeer.Peer)
+
+	err = am.Store.ExecuteInTransaction(ctx, func(transaction store.Store) error {
+		network, err = transaction.GetAccountNetwork(ctx, store.LockingStrengthUpdate, accountID)
+		if err != nil {
+			return err
+		}
+
+		accountPeers, err := transaction.GetAccountPeers(ctx, store.LockingStrengthUpdate, accountID, "", "")
+		if err != nil {
+			return err
+		}
+
+		migrations, err = types.PlanPeerIPMigration(accountPeers, networkRange, reserved)
+		if err != nil {
+			return err
+		}
+
+		rangeChanged = network.Net.String() != networkRange.String()
+		if rangeChanged {
+			routes, err := transaction.GetAccountRoutes(ctx, store.LockingStrengthShare, accountID)
+			if err != nil {
+				return err
+			}
+			if err = validateRoutesOutsideNetwork(routes, networkRange); err != nil {
+				return err
+			}
+		}
+		reservedRangesChanged = !slices.Equal(network.ReservedRanges, reservedRanges)
+		if dryRun || (!rangeChanged && !reservedRangesChanged) {
+			return nil
+		}
+
+		for _, peer := range accountPeers {
+			peers[peer.ID] = peer
+		}
+
+		for _, migration := range migrations {
+			peer := peers[migration.PeerID]
+			peer.IP = migration.NewIP
+			if err = transaction.SavePeer(ctx, store.LockingStrengthUpdate, accountID, peer); err != nil {
+				return err
+			}
+		}
+
+		network.Net = networkRange
+		network.ReservedRanges = reservedRanges
+		network.Serial++
+
+		return transaction.SaveAccountNetwork(ctx, store.LockingStrengthUpdate, accountID, network)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if dryRun {
+		return network, migrations, nil
+	}
+
+	if rangeChanged {
+		am.StoreEvent(ctx, userID, accountID, accountID, activity.AccountNetworkRangeUpdated, map[string]any{"network_range": networkRange.String()})
+		for _, migration := range migrations {
+			meta := peers[migration.PeerID].EventMeta(am.GetDNSDomain())
+			meta["old_ip"] = migration.OldIP.String()
+			am.StoreEvent(ctx, userID, migration.PeerID, accountID, activity.PeerIPUpdated, meta)
+		}
+	}
+
+	if reservedRangesChanged {
+		am.StoreEvent(ctx, userID, accountID, accountID, activity.AccountNetworkReservedRangesUpdated, map[string]any{"reserved_ranges": strings.Join(reservedRanges, ",")})
+	}
+
+	if rangeChanged {
+		am.UpdateAccountPeers(ctx, accountID)
+	}
+
+	return network, migrations, nil
+}
+
+// SetPeerStaticIP assigns the given IP to the peer and pins it, so it is kept when the network range of the account
+// changes. A nil IP unpins the current IP of the peer.
+func (am *DefaultAccountManager) SetPeerStaticIP(ctx context.Context, accountID, userID, peerID string, ip net.IP) (*nbpeer.Peer, error) {
+	allowed, err := am.permissionsManager.ValidateUserPermissions(ctx, accountID, userID, permissions.Peers, permissions.Write)
+	if err != nil {
+		return nil, status.NewPermissionValidationError(err)
+	}
+	if !allowed {
+		return nil, status.NewPermissionDeniedError()
+	}
+
+	unlock := am.Store.AcquireWriteLockByUID(ctx, accountID)
+	defer unlock()
+
+	var peer *nbpeer.Peer
+	var oldIP net.IP
+	var ipChanged, unpinned bool
+
+	err = am.Store.ExecuteInTransaction(ctx, func(transaction store.Store) error {
+		peer, err = transaction.GetPeerByID(ctx, store.LockingStrengthUpdate, accountID, peerID)
+		if err != nil {
+			return err
+		}
+
+		if ip == nil {
+			if !peer.StaticIP {
+				return nil
+			}
+			peer.StaticIP = false
+			unpinned = true
+			return transaction.SavePeer(ctx, store.LockingStrengthUpdate, accountID, peer)
+		}
+
+		network, err := transaction.GetAccountNetwork(ctx, store.LockingStrengthShare, accountID)
+		if err != nil {
+			return err
+		}
+
+		if err = types.ValidatePeerIP(network.Net, ip); err != nil {
+			return err
+		}
+
+		ip = ip.To4()
+		if !peer.IP.Equal(ip) {
+			takenIPs, err := transaction.GetTakenIPs(ctx, store.LockingStrengthUpdate, accountID)
+			if err != nil {
+				return err
+			}
+
+			if slices.ContainsFunc(takenIPs, ip.Equal) {
+				return status.Errorf(status.AlreadyExists, "IP %s is already assigned to another peer", ip)
+			}
+
+			oldIP = peer.IP
+			peer.IP = ip
+			ipChanged = true
+		}
+
+		if !ipChanged && peer.StaticIP {
+			return nil
+		}
+		peer.StaticIP = true
+
+		if err = transaction.SavePeer(ctx, store.LockingStrengthUpdate, accountID, peer); err != nil {
+			return err
+		}
+
+		if ipChanged {
+			return transaction.IncrementNetworkSerial(ctx, store.LockingStrengthUpdate, accountID)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if unpinned {
+		am.StoreEvent(ctx, userID, peer.ID, accountID, activity.PeerStaticIPRemoved, peer.EventMeta(am.GetDNSDomain()))
+	}
+
+	if ipChanged {
+		meta := peer.EventMeta(am.GetDNSDomain())
+		meta["old_ip"] = oldIP.String()
+		am.StoreEvent(ctx, userID, peer.ID, accountID, activity.PeerIPUpdated, meta)
+		am.UpdateAccountPeers(ctx, accountID)
+	}
+
+	return peer, nil
+}
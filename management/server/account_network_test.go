@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+
+	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+	"github.com/netbirdio/netbird/management/server/store"
+)
+
+func TestDefaultAccountManager_UpdateAccountNetwork(t *testing.T) {
+	manager, err := createManager(t)
+	require.NoError(t, err, "unable to create account manager")
+
+	accountID, err := manager.GetAccountIDByUserID(context.Background(), userID, "")
+	require.NoError(t, err, "unable to create an account")
+
+	addPeer := func(hostname string) *nbpeer.Peer {
+		key, err := wgtypes.GenerateKey()
+		require.NoError(t, err, "unable to generate WireGuard key")
+		peer, _, _, err := manager.AddPeer(context.Background(), "", userID, &nbpeer.Peer{
+			Key:  key.PublicKey().String(),
+			Meta: nbpeer.PeerSystemMeta{Hostname: hostname},
+		})
+		require.NoError(t, err, "unable to add peer")
+		return peer
+	}
+
+	peer1 := addPeer("peer1")
+	peer2 := addPeer("peer2")
+
+	networkRange := net.IPNet{IP: net.IP{10, 10, 0, 0}, Mask: net.CIDRMask(16, 32)}
+	staticIP := net.IP{10, 10, 0, 10}
+
+	_, err = manager.SetPeerStaticIP(context.Background(), accountID, userID, peer1.ID, staticIP)
+	assert.Error(t, err, "static IP outside of the network range should be rejected")
+
+	_, migrations, err := manager.UpdateAccountNetwork(context.Background(), accountID, userID, networkRange, []string{"10.10.255.0/24"}, true)
+	require.NoError(t, err)
+	assert.Len(t, migrations, 2)
+
+	network, err := manager.Store.GetAccountNetwork(context.Background(), store.LockingStrengthShare, accountID)
+	require.NoError(t, err)
+	assert.NotEqual(t, networkRange.String(), network.Net.String(), "dry run shouldn't change the network")
+
+	network, migrations, err = manager.UpdateAccountNetwork(context.Background(), accountID, userID, networkRange, []string{"10.10.255.0/24"}, false)
+	require.NoError(t, err)
+	assert.Len(t, migrations, 2)
+	assert.Equal(t, networkRange.String(), network.Net.String())
+	assert.Equal(t, []string{"10.10.255.0/24"}, network.ReservedRanges)
+
+	peer1, err = manager.SetPeerStaticIP(context.Background(), accountID, userID, peer1.ID, staticIP)
+	require.NoError(t, err)
+	assert.True(t, peer1.StaticIP)
+	assert.True(t, peer1.IP.Equal(staticIP))
+
+	_, err = manager.SetPeerStaticIP(context.Background(), accountID, userID, peer2.ID, staticIP)
+	assert.Error(t, err, "IP assigned to another peer should be rejected")
+
+	_, _, err = manager.UpdateAccountNetwork(context.Background(), accountID, userID, net.IPNet{IP: net.IP{10, 20, 0, 0}, Mask: net.CIDRMask(16, 32)}, nil, false)
+	assert.Error(t, err, "network range without the static IPs should be rejected")
+}
@@ -56,6 +56,15 @@ func NewAccountRequestBuffer(ctx context.Context, store store.Store) *AccountReq
 	return &ac
 }
 func (ac *AccountRequestBuffer) GetAccountWithBackpressure(ctx context.Context, accountID string) (*types.Account, error) {
+	// the batched reads run outside the batch transaction of the ctx, they wouldn't see its changes
+	if store.InBatchTransaction(ctx) {
+		account, err := ac.store.GetAccount(ctx, accountID)
+		if err == nil {
+			types.FlattenNestedGroups(account.Groups)
+		}
+		return account, err
+	}
+
 	req := &AccountRequest{
 		AccountID:  accountID,
 		ResultChan: make(chan *AccountResult, 1),
@@ -82,6 +91,10 @@ func (ac *AccountRequestBuffer) processGetAccountBatch(ctx context.Context, acco
 
 	startTime := time.Now()
 	account, err := ac.store.GetAccount(ctx, accountID)
+	if err == nil {
+		// the account is only read to calculate the network maps, so the nested groups are resolved once for all of them
+		types.FlattenNestedGroups(account.Groups)
+	}
 	log.WithContext(ctx).Tracef("getting account %s in batch took %s", accountID, time.Since(startTime))
 	result := &AccountResult{Account: account, Err: err}
 
@@ -983,7 +983,7 @@ func TestAccountManager_AddPeer(t *testing.T) {
 
 	serial := account.Network.CurrentSerial() // should be 0
 
-	setupKey, err := manager.CreateSetupKey(context.Background(), account.Id, "test-key", types.SetupKeyReusable, time.Hour, nil, 999, userID, false, false)
+	setupKey, err := manager.CreateSetupKey(context.Background(), account.Id, "test-key", types.SetupKeyReusable, time.Hour, nil, 999, userID, false, false, types.SetupKeyProvisioning{})
 	if err != nil {
 		t.Fatal("error creating setup key")
 		return
@@ -1359,7 +1359,7 @@ func TestAccountManager_DeletePeer(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	setupKey, err := manager.CreateSetupKey(context.Background(), account.Id, "test-key", types.SetupKeyReusable, time.Hour, nil, 999, userID, false, false)
+	setupKey, err := manager.CreateSetupKey(context.Background(), account.Id, "test-key", types.SetupKeyReusable, time.Hour, nil, 999, userID, false, false, types.SetupKeyProvisioning{})
 	if err != nil {
 		t.Fatal("error creating setup key")
 		return
@@ -1620,9 +1620,10 @@ func TestAccount_Copy(t *testing.T) {
 		},
 		Groups: map[string]*types.Group{
 			"group1": {
-				ID:        "group1",
-				Peers:     []string{"peer1"},
-				Resources: []types.Resource{},
+				ID:           "group1",
+				Peers:        []string{"peer1"},
+				Resources:    []types.Resource{},
+				NestedGroups: []string{},
 			},
 		},
 		Policies: []*types.Policy{
@@ -1679,6 +1680,14 @@ func TestAccount_Copy(t *testing.T) {
 				Address:   "172.12.6.1/24",
 			},
 		},
+		Rollouts: []*types.Rollout{
+			{
+				ID:           "rollout1",
+				ObjectID:     "route1",
+				Route:        &route.Route{ID: "route1", Groups: []string{"group1"}},
+				CanaryGroups: []string{"group1"},
+			},
+		},
 	}
 	err := hasNilField(account)
 	if err != nil {
@@ -2878,7 +2887,7 @@ func setupNetworkMapTest(t *testing.T) (*DefaultAccountManager, *types.Account,
 		t.Fatal(err)
 	}
 
-	setupKey, err := manager.CreateSetupKey(context.Background(), account.Id, "test-key", types.SetupKeyReusable, time.Hour, nil, 999, userID, false, false)
+	setupKey, err := manager.CreateSetupKey(context.Background(), account.Id, "test-key", types.SetupKeyReusable, time.Hour, nil, 999, userID, false, false, types.SetupKeyProvisioning{})
 	if err != nil {
 		t.Fatal("error creating setup key")
 	}
@@ -0,0 +1,45 @@
+// Package bus publishes the activity events to Kafka and NATS. The events are produced to Kafka through a Confluent
+// Kafka REST Proxy (v2 API), the management server doesn't speak the Kafka protocol, so a REST Proxy has to be deployed
+// in front of the Kafka brokers.
+package bus
+
+// Config defines the event buses the activity events are published to
+type Config struct {
+	// Kafka publishes the events to a Kafka topic through a Kafka REST Proxy
+	Kafka *KafkaConfig
+	// NATS publishes the events to NATS subjects
+	NATS *NATSConfig
+	// QueueSize is the number of events buffered per bus while it is slow or unreachable. Defaults to 10000
+	QueueSize int
+	// MaxRetries is the number of times publishing an event is retried before it is dropped. Defaults to 5
+	MaxRetries int
+}
+
+// KafkaConfig defines the Kafka topic the events are produced to.
+// Events are produced through the v2 API of a Kafka REST Proxy, keyed by account ID so that the events of an account keep their order.
+// A REST Proxy is required, the Kafka brokers can't be used directly.
+type KafkaConfig struct {
+	// RESTProxyURL is the http or https base URL of the Kafka REST Proxy, e.g. http://kafka-rest:8082. Broker addresses
+	// like kafka://kafka:9092 are rejected
+	RESTProxyURL string
+	// Topic the events are produced to. Defaults to netbird.events
+	Topic string
+	// Headers are additional HTTP headers sent with every request, e.g. an authorization header
+	Headers map[string]string
+}
+
+// NATSConfig defines the NATS server the events are published to.
+// Events are published to <SubjectPrefix>.<account ID>.<activity code> so that subscribers can filter them with wildcards.
+type NATSConfig struct {
+	// Address of the NATS server, e.g. nats://nats:4222 or tls://nats:4222
+	Address string
+	// SubjectPrefix of the subjects the events are published to. Defaults to netbird.events
+	SubjectPrefix string
+	// Token authenticates with the NATS server using a token
+	Token string
+	// User and Password authenticate with the NATS server using credentials
+	User     string
+	Password string
+	// InsecureSkipVerify disables the verification of the server certificate when TLS is used
+	InsecureSkipVerify bool
+}
@@ -0,0 +1,124 @@
+package bus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/netbirdio/netbird/management/server/activity"
+)
+
+const (
+	kafkaContentType    = "application/vnd.kafka.json.v2+json"
+	kafkaRequestTimeout = 10 * time.Second
+)
+
+type kafkaRecord struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+type kafkaProduceRequest struct {
+	Records []kafkaRecord `json:"records"`
+}
+
+type kafkaProduceResponse struct {
+	Offsets []struct {
+		Partition *int   `json:"partition"`
+		Offset    *int64 `json:"offset"`
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+// kafkaPublisher produces the events to a topic through a Kafka REST Proxy
+type kafkaPublisher struct {
+	url        string
+	topic      string
+	headers    map[string]string
+	httpClient *http.Client
+}
+
+func newKafkaPublisher(config *KafkaConfig) (*kafkaPublisher, error) {
+	u, err := url.Parse(config.RESTProxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse kafka rest proxy url: %w", err)
+	}
+	switch {
+	case u.Scheme == "kafka" || u.Scheme == "kafka+ssl":
+		return nil, fmt.Errorf("kafka broker address %q is not supported, the events are produced through a Kafka REST Proxy, set its http or https URL", config.RESTProxyURL)
+	case u.Scheme != "http" && u.Scheme != "https":
+		return nil, fmt.Errorf("unsupported kafka rest proxy url scheme %q, the http or https URL of a Kafka REST Proxy is required", u.Scheme)
+	}
+
+	topic := config.Topic
+	if topic == "" {
+		topic = defaultTopic
+	}
+
+	return &kafkaPublisher{
+		url:     strings.TrimSuffix(config.RESTProxyURL, "/") + "/topics/" + url.PathEscape(topic),
+		topic:   topic,
+		headers: config.Headers,
+		httpClient: &http.Client{
+			Timeout: kafkaRequestTimeout,
+		},
+	}, nil
+}
+
+func (p *kafkaPublisher) publish(ctx context.Context, event *activity.Event, message []byte) error {
+	body, err := json.Marshal(&kafkaProduceRequest{
+		Records: []kafkaRecord{{Key: event.AccountID, Value: message}},
+	})
+	if err != nil {
+		return fmt.Errorf("marshal kafka records: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create kafka produce request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", kafkaContentType)
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+	for k, v := range p.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("send kafka produce request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("kafka rest proxy responded with status %d", resp.StatusCode)
+	}
+
+	produced := &kafkaProduceResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(produced); err != nil {
+		return fmt.Errorf("decode kafka produce response: %w", err)
+	}
+
+	for _, offset := range produced.Offsets {
+		if offset.ErrorCode != nil {
+			return fmt.Errorf("failed to produce to kafka topic %s: %s (code %d)", p.topic, offset.Error, *offset.ErrorCode)
+		}
+	}
+
+	return nil
+}
+
+func (p *kafkaPublisher) close() error {
+	p.httpClient.CloseIdleConnections()
+	return nil
+}
+
+func (p *kafkaPublisher) name() string {
+	return "kafka"
+}
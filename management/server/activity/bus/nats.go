@@ -0,0 +1,165 @@
+package bus
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/management/server/activity"
+)
+
+const (
+	natsDialTimeout   = 10 * time.Second
+	natsFlushTimeout  = 10 * time.Second
+	natsReconnectWait = 2 * time.Second
+)
+
+// natsPublisher publishes the events with the NATS client. The connection is established with the first event and
+// the client reconnects on its own once it was established. Every publish is flushed so that it only succeeds once
+// the server processed the message, the events published while reconnecting fail and are retried by the sink.
+type natsPublisher struct {
+	address string
+	options []nats.Option
+	prefix  string
+
+	mu   sync.Mutex
+	conn *nats.Conn
+}
+
+func newNATSPublisher(config *NATSConfig) (*natsPublisher, error) {
+	if config.Address == "" {
+		return nil, errors.New("nats address is required")
+	}
+
+	address := config.Address
+	if !strings.Contains(address, "://") {
+		address = "nats://" + address
+	}
+
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("parse nats address: %w", err)
+	}
+	if u.Scheme != "nats" && u.Scheme != "tls" {
+		return nil, fmt.Errorf("unsupported nats address scheme %q", u.Scheme)
+	}
+
+	prefix := config.SubjectPrefix
+	if prefix == "" {
+		prefix = defaultTopic
+	}
+
+	options := []nats.Option{
+		nats.Name("netbird-management"),
+		nats.Timeout(natsDialTimeout),
+		nats.MaxReconnects(-1),
+		nats.ReconnectWait(natsReconnectWait),
+		// the sink retries the events, buffering them in the client would publish them twice
+		nats.ReconnectBufSize(-1),
+		nats.NoCallbacksAfterClientClose(),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				log.Warnf("disconnected from nats server %s: %v", u.Host, err)
+			}
+		}),
+		nats.ReconnectHandler(func(nc *nats.Conn) {
+			log.Infof("reconnected to nats server %s", nc.ConnectedUrlRedacted())
+		}),
+	}
+	if config.Token != "" {
+		options = append(options, nats.Token(config.Token))
+	}
+	if config.User != "" {
+		options = append(options, nats.UserInfo(config.User, config.Password))
+	}
+	if u.Scheme == "tls" || config.InsecureSkipVerify {
+		options = append(options, nats.Secure(&tls.Config{
+			InsecureSkipVerify: config.InsecureSkipVerify, //nolint:gosec
+		}))
+	}
+
+	return &natsPublisher{
+		address: address,
+		options: options,
+		prefix:  strings.TrimSuffix(prefix, "."),
+	}, nil
+}
+
+func (p *natsPublisher) publish(ctx context.Context, event *activity.Event, message []byte) error {
+	conn, err := p.connection()
+	if err != nil {
+		return fmt.Errorf("connect to nats: %w", err)
+	}
+
+	if err := conn.Publish(p.subject(event), message); err != nil {
+		return fmt.Errorf("publish to nats: %w", err)
+	}
+
+	flushCtx, cancel := context.WithTimeout(ctx, natsFlushTimeout)
+	defer cancel()
+	if err := conn.FlushWithContext(flushCtx); err != nil {
+		return fmt.Errorf("flush nats connection: %w", err)
+	}
+
+	return nil
+}
+
+// connection returns the NATS connection, it connects if the first connection wasn't established yet
+func (p *natsPublisher) connection() (*nats.Conn, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn != nil {
+		return p.conn, nil
+	}
+
+	conn, err := nats.Connect(p.address, p.options...)
+	if err != nil {
+		return nil, err
+	}
+	p.conn = conn
+
+	return conn, nil
+}
+
+// subject returns <prefix>.<account ID>.<activity code>
+func (p *natsPublisher) subject(event *activity.Event) string {
+	return p.prefix + "." + natsToken(event.AccountID) + "." + natsToken(event.Activity.StringCode())
+}
+
+// natsToken replaces the characters not allowed in subjects
+func natsToken(s string) string {
+	if s == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\r', '\n', '*', '>':
+			return '_'
+		}
+		return r
+	}, s)
+}
+
+func (p *natsPublisher) close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn != nil {
+		p.conn.Close()
+		p.conn = nil
+	}
+	return nil
+}
+
+func (p *natsPublisher) name() string {
+	return "nats"
+}
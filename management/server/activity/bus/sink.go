@@ -0,0 +1,210 @@
+package bus
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/activity/export"
+)
+
+const (
+	defaultTopic          = "netbird.events"
+	defaultQueueSize      = 10000
+	defaultMaxRetries     = 5
+	defaultInitialBackoff = time.Second
+	maxBackoff            = 30 * time.Second
+	// closeTimeout limits the time the queued events are published for when the sink is closed
+	closeTimeout = 10 * time.Second
+)
+
+// publisher sends a JSON encoded event to a bus
+type publisher interface {
+	publish(ctx context.Context, event *activity.Event, message []byte) error
+	close() error
+	// name of the bus used in logs
+	name() string
+}
+
+// NewSinks returns an activity.Sink for every bus defined in the config
+func NewSinks(ctx context.Context, config *Config) ([]activity.Sink, error) {
+	var publishers []publisher
+
+	if config.Kafka != nil {
+		p, err := newKafkaPublisher(config.Kafka)
+		if err != nil {
+			return nil, err
+		}
+		publishers = append(publishers, p)
+	}
+
+	if config.NATS != nil {
+		p, err := newNATSPublisher(config.NATS)
+		if err != nil {
+			return nil, err
+		}
+		publishers = append(publishers, p)
+	}
+
+	queueSize := config.QueueSize
+	if queueSize <= 0 {
+		queueSize = defaultQueueSize
+	}
+
+	maxRetries := config.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = defaultMaxRetries
+	}
+
+	sinks := make([]activity.Sink, 0, len(publishers))
+	for _, p := range publishers {
+		sinks = append(sinks, newSink(ctx, p, queueSize, maxRetries))
+	}
+
+	return sinks, nil
+}
+
+// sink publishes the events to a bus in the background so that saving events is never blocked by the bus.
+// Events are dropped when the queue is full or when publishing failed after the retries.
+// Closing the sink publishes the queued events for up to closeTimeout.
+type sink struct {
+	publisher      publisher
+	formatter      export.Formatter
+	queue          chan *activity.Event
+	maxRetries     int
+	initialBackoff time.Duration
+	closeTimeout   time.Duration
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	closing   chan struct{}
+	closeOnce sync.Once
+}
+
+func newSink(ctx context.Context, publisher publisher, queueSize, maxRetries int) *sink {
+	// the json format has no configuration, so it can't fail
+	formatter, _ := export.NewFormatter(export.FormatJSON)
+
+	// the sink is stopped by Close, so that the events queued when the management server shuts down are published
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	s := &sink{
+		publisher:      publisher,
+		formatter:      formatter,
+		queue:          make(chan *activity.Event, queueSize),
+		maxRetries:     maxRetries,
+		initialBackoff: defaultInitialBackoff,
+		closeTimeout:   closeTimeout,
+		ctx:            ctx,
+		cancel:         cancel,
+		closing:        make(chan struct{}),
+	}
+
+	s.wg.Add(1)
+	go s.run()
+
+	return s
+}
+
+// Publish queues the event. Events are dropped if the queue is full.
+func (s *sink) Publish(_ context.Context, event *activity.Event) error {
+	select {
+	case s.queue <- event:
+	default:
+		log.Warnf("%s event queue is full, dropping event %d of account %s", s.publisher.name(), event.ID, event.AccountID)
+	}
+	return nil
+}
+
+// Close publishes the queued events and closes the connection to the bus. The events still queued after
+// closeTimeout, or once the context is done if its deadline is earlier, are dropped.
+func (s *sink) Close(ctx context.Context) error {
+	s.closeOnce.Do(func() {
+		close(s.closing)
+	})
+
+	timer := time.NewTimer(s.closeTimeout)
+	defer timer.Stop()
+
+	drained := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	s.cancel()
+	s.wg.Wait()
+	if dropped := len(s.queue); dropped > 0 {
+		log.Warnf("closed %s event sink dropping %d queued events", s.publisher.name(), dropped)
+	}
+
+	return s.publisher.close()
+}
+
+func (s *sink) run() {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-s.closing:
+			s.drain()
+			return
+		case event := <-s.queue:
+			s.publish(event)
+		}
+	}
+}
+
+// drain publishes the queued events until the queue is empty or the sink is stopped
+func (s *sink) drain() {
+	for s.ctx.Err() == nil {
+		select {
+		case event := <-s.queue:
+			s.publish(event)
+		default:
+			return
+		}
+	}
+}
+
+func (s *sink) publish(event *activity.Event) {
+	message, err := s.formatter.Format(event)
+	if err != nil {
+		log.Errorf("failed to marshal event %d: %v", event.ID, err)
+		return
+	}
+
+	backoff := s.initialBackoff
+	for attempt := 0; ; attempt++ {
+		err := s.publisher.publish(s.ctx, event, message)
+		if err == nil {
+			return
+		}
+
+		if attempt >= s.maxRetries {
+			log.Warnf("failed to publish event %d to %s after %d attempts: %v", event.ID, s.publisher.name(), attempt+1, err)
+			return
+		}
+
+		log.Debugf("failed to publish event %d to %s, retrying in %s: %v", event.ID, s.publisher.name(), backoff, err)
+
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
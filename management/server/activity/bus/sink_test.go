@@ -0,0 +1,291 @@
+package bus
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/management/server/activity"
+)
+
+func testEvent() *activity.Event {
+	return &activity.Event{
+		ID:          1,
+		Timestamp:   time.Now().UTC(),
+		Activity:    activity.PeerAddedByUser,
+		InitiatorID: "user1",
+		TargetID:    "peer1",
+		AccountID:   "account1",
+	}
+}
+
+func TestSink_Kafka(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	var body []byte
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		_, _ = w.Write([]byte(`{"offsets":[{"partition":0,"offset":1}]}`))
+		received <- r
+	}))
+	defer server.Close()
+
+	sinks, err := NewSinks(context.Background(), &Config{
+		Kafka: &KafkaConfig{RESTProxyURL: server.URL + "/", Topic: "audit"},
+	})
+	require.NoError(t, err)
+	require.Len(t, sinks, 1)
+
+	eventStore := activity.NewSinkStore(&activity.InMemoryEventStore{}, sinks...)
+	defer func() {
+		_ = eventStore.Close(context.Background())
+	}()
+
+	_, err = eventStore.Save(context.Background(), testEvent())
+	require.NoError(t, err)
+
+	select {
+	case r := <-received:
+		assert.Equal(t, "/topics/audit", r.URL.Path)
+		assert.Equal(t, kafkaContentType, r.Header.Get("Content-Type"))
+	case <-time.After(5 * time.Second):
+		t.Fatal("event was not produced")
+	}
+
+	request := &kafkaProduceRequest{}
+	require.NoError(t, json.Unmarshal(body, request))
+	require.Len(t, request.Records, 1)
+	assert.Equal(t, "account1", request.Records[0].Key)
+	assert.Contains(t, string(request.Records[0].Value), `"target_id":"peer1"`)
+}
+
+func TestSink_KafkaRetriesFailedRecords(t *testing.T) {
+	attempts := make(chan struct{}, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"offsets":[{"error_code":50003,"error":"broker not available"}]}`))
+		attempts <- struct{}{}
+	}))
+	defer server.Close()
+
+	p, err := newKafkaPublisher(&KafkaConfig{RESTProxyURL: server.URL})
+	require.NoError(t, err)
+
+	s := newSink(context.Background(), p, 10, 1)
+	s.initialBackoff = time.Millisecond
+	defer func() {
+		_ = s.Close(context.Background())
+	}()
+
+	require.NoError(t, s.Publish(context.Background(), testEvent()))
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-attempts:
+		case <-time.After(5 * time.Second):
+			t.Fatal("failed record was not retried")
+		}
+	}
+}
+
+// serveNATS accepts the clients speaking the NATS protocol, sends their connections to conns and the subject and payload
+// of the published messages to published
+func serveNATS(listener net.Listener, published chan<- [2]string, conns chan<- net.Conn) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		if conns != nil {
+			conns <- conn
+		}
+		go serveNATSConn(conn, published)
+	}
+}
+
+func serveNATSConn(conn net.Conn, published chan<- [2]string) {
+	defer conn.Close()
+
+	_, _ = fmt.Fprint(conn, "INFO {\"server_id\":\"test\",\"max_payload\":1048576}\r\n")
+
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "CONNECT":
+		case "PING":
+			_, _ = fmt.Fprint(conn, "PONG\r\n")
+		case "PUB":
+			size, _ := strconv.Atoi(fields[len(fields)-1])
+			payload := make([]byte, size+2)
+			if _, err := io.ReadFull(reader, payload); err != nil {
+				return
+			}
+			published <- [2]string{fields[1], string(payload[:size])}
+		}
+	}
+}
+
+func TestSink_NATS(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	published := make(chan [2]string, 1)
+	go serveNATS(listener, published, nil)
+
+	sinks, err := NewSinks(context.Background(), &Config{
+		NATS: &NATSConfig{Address: listener.Addr().String(), SubjectPrefix: "audit."},
+	})
+	require.NoError(t, err)
+	require.Len(t, sinks, 1)
+	defer func() {
+		_ = sinks[0].Close(context.Background())
+	}()
+
+	require.NoError(t, sinks[0].Publish(context.Background(), testEvent()))
+
+	select {
+	case msg := <-published:
+		assert.Equal(t, "audit.account1."+activity.PeerAddedByUser.StringCode(), msg[0])
+		assert.Contains(t, msg[1], `"initiator_id":"user1"`)
+	case <-time.After(5 * time.Second):
+		t.Fatal("event was not published")
+	}
+}
+
+func TestSink_NATSReconnects(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	published := make(chan [2]string, 2)
+	conns := make(chan net.Conn, 2)
+	go serveNATS(listener, published, conns)
+
+	p, err := newNATSPublisher(&NATSConfig{Address: listener.Addr().String()})
+	require.NoError(t, err)
+
+	s := newSink(context.Background(), p, 10, 10)
+	s.initialBackoff = 100 * time.Millisecond
+	defer func() {
+		_ = s.Close(context.Background())
+	}()
+
+	require.NoError(t, s.Publish(context.Background(), testEvent()))
+	select {
+	case msg := <-published:
+		assert.Contains(t, msg[1], `"target_id":"peer1"`)
+	case <-time.After(5 * time.Second):
+		t.Fatal("event was not published")
+	}
+
+	// the server drops the connection, the event is published once the client reconnected
+	(<-conns).Close()
+
+	event := testEvent()
+	event.TargetID = "peer2"
+	require.NoError(t, s.Publish(context.Background(), event))
+
+	select {
+	case msg := <-published:
+		assert.Contains(t, msg[1], `"target_id":"peer2"`)
+	case <-time.After(15 * time.Second):
+		t.Fatal("event was not published after the reconnection")
+	}
+
+	select {
+	case <-conns:
+	default:
+		t.Fatal("client should have reconnected")
+	}
+
+	select {
+	case msg := <-published:
+		t.Fatalf("event shouldn't be published twice: %s", msg[1])
+	case <-time.After(500 * time.Millisecond):
+	}
+}
+
+// testPublisher counts the published events, publishing blocks for the delay or until the context is done
+type testPublisher struct {
+	delay     time.Duration
+	published atomic.Int32
+}
+
+func (p *testPublisher) publish(ctx context.Context, _ *activity.Event, _ []byte) error {
+	select {
+	case <-time.After(p.delay):
+		p.published.Add(1)
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *testPublisher) close() error {
+	return nil
+}
+
+func (p *testPublisher) name() string {
+	return "test"
+}
+
+func TestSink_CloseFlushesQueue(t *testing.T) {
+	p := &testPublisher{delay: 10 * time.Millisecond}
+	s := newSink(context.Background(), p, 10, 0)
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, s.Publish(context.Background(), testEvent()))
+	}
+
+	require.NoError(t, s.Close(context.Background()))
+	assert.Equal(t, int32(10), p.published.Load(), "the queued events should be published when the sink is closed")
+}
+
+func TestSink_CloseDeadline(t *testing.T) {
+	p := &testPublisher{delay: time.Hour}
+	s := newSink(context.Background(), p, 10, 0)
+	s.closeTimeout = 100 * time.Millisecond
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, s.Publish(context.Background(), testEvent()))
+	}
+
+	start := time.Now()
+	require.NoError(t, s.Close(context.Background()))
+	assert.Less(t, time.Since(start), 5*time.Second, "closing shouldn't wait for the unreachable bus")
+	assert.Zero(t, p.published.Load())
+}
+
+func TestNewSinks_Invalid(t *testing.T) {
+	_, err := NewSinks(context.Background(), &Config{Kafka: &KafkaConfig{RESTProxyURL: "kafka:9092"}})
+	assert.Error(t, err)
+
+	_, err = NewSinks(context.Background(), &Config{Kafka: &KafkaConfig{RESTProxyURL: "kafka://kafka:9092"}})
+	assert.ErrorContains(t, err, "REST Proxy")
+
+	_, err = NewSinks(context.Background(), &Config{NATS: &NATSConfig{Address: "http://nats:4222"}})
+	assert.Error(t, err)
+}
@@ -169,6 +169,122 @@ const (
 
 	ResourceAddedToGroup     Activity = 82
 	ResourceRemovedFromGroup Activity = 83
+
+	// PeerApprovalPending indicates that a newly registered peer is waiting for an administrator approval
+	PeerApprovalPending Activity = 84
+
+	// UserProvisioned indicates that a user was created by a SCIM client
+	UserProvisioned Activity = 85
+
+	RoleCreated Activity = 86
+	RoleUpdated Activity = 87
+	RoleDeleted Activity = 88
+
+	TenantCreated Activity = 89
+	TenantDeleted Activity = 90
+
+	// PersonalAccessTokenRotated indicates that a user rotated a personal access token
+	PersonalAccessTokenRotated Activity = 91
+
+	// PeerLoginFailed indicates that a peer failed to log in
+	PeerLoginFailed Activity = 92
+	// PeerPostureCheckFailed indicates that a peer stopped passing a posture check
+	PeerPostureCheckFailed Activity = 93
+	// PeerPostureCheckPassed indicates that a peer started passing a posture check
+	PeerPostureCheckPassed Activity = 94
+
+	WebhookCreated Activity = 95
+	WebhookUpdated Activity = 96
+	WebhookDeleted Activity = 97
+
+	// PeerLoginExpiring indicates that the user of a peer was notified about the upcoming expiration of its login
+	PeerLoginExpiring Activity = 98
+	// AccountPeerLoginExpirationNotificationUpdated indicates that a user updated the peer login expiration notification period
+	AccountPeerLoginExpirationNotificationUpdated Activity = 99
+	// AccountPeerLoginExpirationGraceEnabled indicates that a user enabled the peer login expiration grace period
+	AccountPeerLoginExpirationGraceEnabled Activity = 100
+	// AccountPeerLoginExpirationGraceDisabled indicates that a user disabled the peer login expiration grace period
+	AccountPeerLoginExpirationGraceDisabled Activity = 101
+	// SetupKeyProvisioningUpdated indicates that a user updated the IP ranges, routes, posture checks or peer name prefix of a setup key
+	SetupKeyProvisioningUpdated Activity = 102
+	// PeerCleanedUp indicates that a peer was deleted after being offline for longer than its ephemeral cleanup period
+	PeerCleanedUp Activity = 103
+	// PeerDNSAliasesUpdated indicates that a user updated the DNS aliases of a peer
+	PeerDNSAliasesUpdated Activity = 104
+	// AccountNetworkRangeUpdated indicates that a user changed the overlay network range of the account
+	AccountNetworkRangeUpdated Activity = 105
+	// AccountNetworkReservedRangesUpdated indicates that a user updated the reserved ranges of the account network
+	AccountNetworkReservedRangesUpdated Activity = 106
+	// PeerIPUpdated indicates that the IP of a peer was changed by a user or by the change of the network range
+	PeerIPUpdated Activity = 107
+	// PeerStaticIPRemoved indicates that a user unpinned the static IP of a peer
+	PeerStaticIPRemoved Activity = 108
+
+	// MonitorCreated indicates that a user created a synthetic monitor
+	MonitorCreated Activity = 109
+	// MonitorUpdated indicates that a user updated a synthetic monitor
+	MonitorUpdated Activity = 110
+	// MonitorDeleted indicates that a user deleted a synthetic monitor
+	MonitorDeleted Activity = 111
+	// MonitorFailed indicates that the target of a synthetic monitor became unreachable from its peer
+	MonitorFailed Activity = 112
+	// MonitorRecovered indicates that the target of a failed synthetic monitor became reachable again
+	MonitorRecovered Activity = 113
+
+	// AccountExported indicates that a user exported the account to an encrypted bundle
+	AccountExported Activity = 114
+
+	// DebugBundleRequested indicates that a user requested a debug bundle from a peer
+	DebugBundleRequested Activity = 115
+	// DebugBundleUploaded indicates that a peer uploaded a requested debug bundle
+	DebugBundleUploaded Activity = 116
+	// DebugBundleDeclined indicates that a peer declined or failed to upload a requested debug bundle
+	DebugBundleDeclined Activity = 117
+	// DebugBundleDownloaded indicates that a user downloaded a debug bundle of a peer
+	DebugBundleDownloaded Activity = 118
+	// DebugBundleDeleted indicates that a user deleted a debug bundle request of a peer
+	DebugBundleDeleted Activity = 119
+
+	// AccountConnectionTuningUpdated indicates that a user updated the connection tuning of the account
+	AccountConnectionTuningUpdated Activity = 120
+
+	// RolloutStarted indicates that a user staged a route or policy change on canary groups
+	RolloutStarted Activity = 121
+	// RolloutPromoted indicates that a staged route or policy change was applied to all peers
+	RolloutPromoted Activity = 122
+	// RolloutRolledBack indicates that a staged route or policy change was removed from the canary peers
+	RolloutRolledBack Activity = 123
+
+	// AccountClientSettingsUpdated indicates that a user updated the defaults of the client settings of the account
+	AccountClientSettingsUpdated Activity = 124
+
+	// PeerLabelsUpdated indicates that a user updated the labels of a peer
+	PeerLabelsUpdated Activity = 125
+
+	// PeerActionRequested indicates that a user requested an action from a peer
+	PeerActionRequested Activity = 126
+	// PeerActionCompleted indicates that a peer completed a requested action
+	PeerActionCompleted Activity = 127
+	// PeerActionFailed indicates that a peer declined or failed to run a requested action
+	PeerActionFailed Activity = 128
+
+	// AccountNotificationSettingsUpdated indicates that a user updated the notifications the clients show to their users
+	AccountNotificationSettingsUpdated Activity = 129
+
+	// AccountSpecApplied indicates that a user applied a declarative spec of the groups, policies, routes, nameserver
+	// groups and posture checks to the account
+	AccountSpecApplied Activity = 130
+
+	// PeerExitNodeQuotaExceeded indicates that a peer exceeded the exit node quota of its groups and lost access to
+	// the exit nodes until the next month
+	PeerExitNodeQuotaExceeded Activity = 131
+
+	// AccountRoutingPeerForwardedTrafficFilteringEnabled indicates that a user enabled the filtering of the forwarded
+	// traffic on the routing peers
+	AccountRoutingPeerForwardedTrafficFilteringEnabled Activity = 132
+	// AccountRoutingPeerForwardedTrafficFilteringDisabled indicates that a user disabled the filtering of the forwarded
+	// traffic on the routing peers
+	AccountRoutingPeerForwardedTrafficFilteringDisabled Activity = 133
 )
 
 var activityMap = map[Activity]Code{
@@ -264,6 +380,78 @@ var activityMap = map[Activity]Code{
 
 	ResourceAddedToGroup:     {"Resource added to group", "resource.group.add"},
 	ResourceRemovedFromGroup: {"Resource removed from group", "resource.group.delete"},
+
+	PeerApprovalPending: {"Peer pending approval", "peer.approval.pending"},
+
+	UserProvisioned: {"User provisioned", "user.scim.provision"},
+
+	RoleCreated: {"Role created", "role.create"},
+	RoleUpdated: {"Role updated", "role.update"},
+	RoleDeleted: {"Role deleted", "role.delete"},
+
+	TenantCreated: {"Tenant created", "tenant.create"},
+	TenantDeleted: {"Tenant deleted", "tenant.delete"},
+
+	PersonalAccessTokenRotated: {"Personal access token rotated", "personal.access.token.rotate"},
+
+	PeerLoginFailed:        {"Peer login failed", "peer.login.fail"},
+	PeerPostureCheckFailed: {"Peer posture check failed", "peer.posture.check.fail"},
+	PeerPostureCheckPassed: {"Peer posture check passed", "peer.posture.check.pass"},
+
+	WebhookCreated: {"Webhook created", "webhook.create"},
+	WebhookUpdated: {"Webhook updated", "webhook.update"},
+	WebhookDeleted: {"Webhook deleted", "webhook.delete"},
+
+	PeerLoginExpiring: {"Peer login expiring", "peer.login.expiring"},
+	AccountPeerLoginExpirationNotificationUpdated: {"Account peer login expiration notification updated", "account.setting.peer.login.expiration.notification.update"},
+	AccountPeerLoginExpirationGraceEnabled:        {"Account peer login expiration grace period enabled", "account.setting.peer.login.expiration.grace.enable"},
+	AccountPeerLoginExpirationGraceDisabled:       {"Account peer login expiration grace period disabled", "account.setting.peer.login.expiration.grace.disable"},
+
+	SetupKeyProvisioningUpdated: {"Setup key provisioning updated", "setupkey.provisioning.update"},
+	PeerCleanedUp:               {"Offline peer cleaned up", "peer.cleanup"},
+	PeerDNSAliasesUpdated:       {"Peer DNS aliases updated", "peer.dns.aliases.update"},
+
+	AccountNetworkRangeUpdated:          {"Account network range updated", "account.network.range.update"},
+	AccountNetworkReservedRangesUpdated: {"Account network reserved ranges updated", "account.network.reserved.ranges.update"},
+	PeerIPUpdated:                       {"Peer IP updated", "peer.ip.update"},
+	PeerStaticIPRemoved:                 {"Peer static IP removed", "peer.ip.static.delete"},
+
+	MonitorCreated:   {"Monitor created", "monitor.create"},
+	MonitorUpdated:   {"Monitor updated", "monitor.update"},
+	MonitorDeleted:   {"Monitor deleted", "monitor.delete"},
+	MonitorFailed:    {"Monitor failed", "monitor.fail"},
+	MonitorRecovered: {"Monitor recovered", "monitor.recover"},
+
+	AccountExported: {"Account exported", "account.export"},
+
+	DebugBundleRequested:  {"Debug bundle requested", "peer.debug_bundle.request"},
+	DebugBundleUploaded:   {"Debug bundle uploaded", "peer.debug_bundle.upload"},
+	DebugBundleDeclined:   {"Debug bundle declined", "peer.debug_bundle.decline"},
+	DebugBundleDownloaded: {"Debug bundle downloaded", "peer.debug_bundle.download"},
+	DebugBundleDeleted:    {"Debug bundle deleted", "peer.debug_bundle.delete"},
+
+	AccountConnectionTuningUpdated: {"Account connection tuning updated", "account.setting.connection_tuning.update"},
+
+	RolloutStarted:    {"Rollout started", "rollout.start"},
+	RolloutPromoted:   {"Rollout promoted", "rollout.promote"},
+	RolloutRolledBack: {"Rollout rolled back", "rollout.rollback"},
+
+	AccountClientSettingsUpdated: {"Account client settings updated", "account.setting.client_settings.update"},
+
+	PeerLabelsUpdated: {"Peer labels updated", "peer.labels.update"},
+
+	PeerActionRequested: {"Peer action requested", "peer.action.request"},
+	PeerActionCompleted: {"Peer action completed", "peer.action.complete"},
+	PeerActionFailed:    {"Peer action failed", "peer.action.fail"},
+
+	AccountNotificationSettingsUpdated: {"Account notification settings updated", "account.setting.notifications.update"},
+
+	AccountSpecApplied: {"Account spec applied", "account.spec.apply"},
+
+	PeerExitNodeQuotaExceeded: {"Peer exceeded exit node quota", "peer.exit_node_quota.exceed"},
+
+	AccountRoutingPeerForwardedTrafficFilteringEnabled:  {"Account routing peer forwarded traffic filtering enabled", "account.setting.routing.peer.forwarded.traffic.filtering.enable"},
+	AccountRoutingPeerForwardedTrafficFilteringDisabled: {"Account routing peer forwarded traffic filtering disabled", "account.setting.routing.peer.forwarded.traffic.filtering.disable"},
 }
 
 // StringCode returns a string code of the activity
@@ -286,3 +474,13 @@ func (a Activity) Message() string {
 func RegisterActivityMap(codes map[Activity]Code) {
 	maps.Copy(activityMap, codes)
 }
+
+// IsKnownStringCode returns true if the string code belongs to a registered activity
+func IsKnownStringCode(stringCode string) bool {
+	for _, code := range activityMap {
+		if code.Code == stringCode {
+			return true
+		}
+	}
+	return false
+}
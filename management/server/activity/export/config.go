@@ -0,0 +1,48 @@
+package export
+
+import (
+	"github.com/netbirdio/netbird/util"
+)
+
+const (
+	// FormatJSON exports the events as JSON objects
+	FormatJSON = "json"
+	// FormatCEF exports the events in the ArcSight Common Event Format
+	FormatCEF = "cef"
+	// FormatLEEF exports the events in the IBM QRadar Log Event Extended Format 2.0
+	FormatLEEF = "leef"
+)
+
+// Config defines the format and the collector the activity events are exported to
+type Config struct {
+	// Format of the exported events: json, cef or leef. Defaults to json
+	Format string
+	// Syslog ships the events to a syslog collector
+	Syslog *SyslogConfig
+	// HTTP ships the events to an HTTP collector
+	HTTP *HTTPConfig
+	// QueueSize is the number of events buffered while the collector is slow or unreachable. Defaults to 10000
+	QueueSize int
+	// BlockTimeout is how long storing an event waits for space in a full queue before the event is dropped. Defaults to 5s
+	BlockTimeout util.Duration
+}
+
+// SyslogConfig defines the syslog collector receiving RFC 5424 messages
+type SyslogConfig struct {
+	// Network is one of udp, tcp or tls. Defaults to udp
+	Network string
+	// Address of the collector, e.g. siem.example.com:514
+	Address string
+	// AppName is the APP-NAME of the syslog messages. Defaults to netbird-management
+	AppName string
+	// InsecureSkipVerify disables the verification of the collector certificate with the tls network
+	InsecureSkipVerify bool
+}
+
+// HTTPConfig defines the HTTP collector receiving a POST request per event
+type HTTPConfig struct {
+	// URL of the collector, e.g. a Splunk HTTP Event Collector endpoint
+	URL string
+	// Headers are additional HTTP headers sent with every request, e.g. an authorization header
+	Headers map[string]string
+}
@@ -0,0 +1,180 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/management/server/activity"
+)
+
+const (
+	defaultQueueSize      = 10000
+	defaultBlockTimeout   = 5 * time.Second
+	defaultInitialBackoff = time.Second
+	maxBackoff            = time.Minute
+	// flushTimeout is how long closing the exporter waits for the queued events to be delivered
+	flushTimeout = 10 * time.Second
+)
+
+// Exporter is an activity.Sink shipping the events to a syslog or HTTP collector in the background.
+// Events are delivered in order and retried until the collector accepts them. When the queue is full,
+// exporting an event blocks for the configured timeout to slow down the producers before the event is dropped.
+type Exporter struct {
+	formatter      Formatter
+	transport      Transport
+	queue          chan *activity.Event
+	blockTimeout   time.Duration
+	initialBackoff time.Duration
+	dropped        atomic.Uint64
+
+	mu     sync.RWMutex
+	closed bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewExporter creates an Exporter from the config and starts processing events
+func NewExporter(ctx context.Context, config *Config) (*Exporter, error) {
+	if config == nil {
+		return nil, errors.New("event export config is required")
+	}
+
+	formatter, err := NewFormatter(config.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	transport, err := NewTransport(config, formatter)
+	if err != nil {
+		return nil, err
+	}
+
+	queueSize := config.QueueSize
+	if queueSize <= 0 {
+		queueSize = defaultQueueSize
+	}
+
+	blockTimeout := config.BlockTimeout.Duration
+	if blockTimeout <= 0 {
+		blockTimeout = defaultBlockTimeout
+	}
+
+	return newExporter(ctx, formatter, transport, queueSize, blockTimeout), nil
+}
+
+func newExporter(ctx context.Context, formatter Formatter, transport Transport, queueSize int, blockTimeout time.Duration) *Exporter {
+	ctx, cancel := context.WithCancel(ctx)
+	e := &Exporter{
+		formatter:      formatter,
+		transport:      transport,
+		queue:          make(chan *activity.Event, queueSize),
+		blockTimeout:   blockTimeout,
+		initialBackoff: defaultInitialBackoff,
+		ctx:            ctx,
+		cancel:         cancel,
+		done:           make(chan struct{}),
+	}
+
+	go e.run()
+
+	return e
+}
+
+// Publish queues the event for delivery. It blocks up to the block timeout when the queue is full and drops the event afterward.
+func (e *Exporter) Publish(_ context.Context, event *activity.Event) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.closed {
+		return nil
+	}
+
+	select {
+	case e.queue <- event:
+		return nil
+	default:
+	}
+
+	timer := time.NewTimer(e.blockTimeout)
+	defer timer.Stop()
+
+	select {
+	case e.queue <- event:
+	case <-timer.C:
+		dropped := e.dropped.Add(1)
+		log.Warnf("event export queue is full, dropped event %d of account %s, %d events dropped in total", event.ID, event.AccountID, dropped)
+	}
+
+	return nil
+}
+
+// Dropped returns the number of events dropped because the queue was full
+func (e *Exporter) Dropped() uint64 {
+	return e.dropped.Load()
+}
+
+// Close stops accepting events, delivers the queued events within the flush timeout and closes the transport
+func (e *Exporter) Close(_ context.Context) error {
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		return nil
+	}
+	e.closed = true
+	close(e.queue)
+	e.mu.Unlock()
+
+	select {
+	case <-e.done:
+	case <-time.After(flushTimeout):
+		log.Warnf("timed out flushing %d exported events", len(e.queue))
+		e.cancel()
+		<-e.done
+	}
+	e.cancel()
+
+	return e.transport.Close()
+}
+
+func (e *Exporter) run() {
+	defer close(e.done)
+
+	for event := range e.queue {
+		e.export(event)
+	}
+}
+
+// export delivers the event retrying with exponential backoff until it succeeds or the exporter is stopped
+func (e *Exporter) export(event *activity.Event) {
+	message, err := e.formatter.Format(event)
+	if err != nil {
+		log.Errorf("failed to format exported event %d: %v", event.ID, err)
+		return
+	}
+
+	backoff := e.initialBackoff
+	for {
+		err := e.transport.Send(e.ctx, message)
+		if err == nil {
+			return
+		}
+
+		log.Debugf("failed to export event %d, retrying in %s: %v", event.ID, backoff, err)
+
+		select {
+		case <-e.ctx.Done():
+			log.Warnf("failed to export event %d before shutdown: %v", event.ID, err)
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
@@ -0,0 +1,152 @@
+package export
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/management/server/activity"
+)
+
+func TestExporter_HTTPRetriesUntilDelivered(t *testing.T) {
+	var attempts atomic.Int32
+	received := make(chan string, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "token", r.Header.Get("Authorization"))
+		assert.Equal(t, "text/plain", r.Header.Get("Content-Type"))
+		received <- string(body)
+	}))
+	defer server.Close()
+
+	exporter, err := NewExporter(context.Background(), &Config{
+		Format: FormatCEF,
+		HTTP:   &HTTPConfig{URL: server.URL, Headers: map[string]string{"Authorization": "token"}},
+	})
+	require.NoError(t, err)
+	exporter.initialBackoff = time.Millisecond
+
+	eventStore := activity.NewSinkStore(&activity.InMemoryEventStore{}, exporter)
+	_, err = eventStore.Save(context.Background(), testEvent())
+	require.NoError(t, err)
+
+	select {
+	case body := <-received:
+		assert.True(t, strings.HasPrefix(body, "CEF:0|"))
+	case <-time.After(5 * time.Second):
+		t.Fatal("event was not exported")
+	}
+
+	assert.Equal(t, int32(2), attempts.Load())
+	require.NoError(t, eventStore.Close(context.Background()))
+}
+
+func TestExporter_SyslogTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		length, err := reader.ReadString(' ')
+		if err != nil {
+			return
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(length))
+		if err != nil {
+			return
+		}
+		msg := make([]byte, n)
+		if _, err := io.ReadFull(reader, msg); err != nil {
+			return
+		}
+		received <- string(msg)
+	}()
+
+	exporter, err := NewExporter(context.Background(), &Config{
+		Syslog: &SyslogConfig{Network: "tcp", Address: listener.Addr().String()},
+	})
+	require.NoError(t, err)
+	defer func() {
+		_ = exporter.Close(context.Background())
+	}()
+
+	_ = exporter.Publish(context.Background(), testEvent())
+
+	select {
+	case msg := <-received:
+		assert.True(t, strings.HasPrefix(msg, "<134>1 "))
+		assert.Contains(t, msg, " "+defaultAppName+" ")
+		assert.Contains(t, msg, `"activity_code":"user.peer.delete"`)
+	case <-time.After(5 * time.Second):
+		t.Fatal("event was not exported")
+	}
+}
+
+type blockingTransport struct {
+	release chan struct{}
+}
+
+func (t *blockingTransport) Send(ctx context.Context, _ []byte) error {
+	select {
+	case <-t.release:
+	case <-ctx.Done():
+	}
+	return nil
+}
+
+func (t *blockingTransport) Close() error {
+	return nil
+}
+
+func TestExporter_DropsWhenQueueIsFull(t *testing.T) {
+	transport := &blockingTransport{release: make(chan struct{})}
+	exporter := newExporter(context.Background(), &jsonFormatter{}, transport, 1, 10*time.Millisecond)
+
+	// the first event is picked up by the worker blocked on the transport, the second fills the queue
+	_ = exporter.Publish(context.Background(), testEvent())
+	require.Eventually(t, func() bool { return len(exporter.queue) == 0 }, time.Second, time.Millisecond)
+	_ = exporter.Publish(context.Background(), testEvent())
+
+	start := time.Now()
+	_ = exporter.Publish(context.Background(), testEvent())
+	assert.GreaterOrEqual(t, time.Since(start), 10*time.Millisecond, "exporting into a full queue applies backpressure")
+	assert.Equal(t, uint64(1), exporter.Dropped())
+
+	close(transport.release)
+	require.NoError(t, exporter.Close(context.Background()))
+}
+
+func TestConfig_Invalid(t *testing.T) {
+	_, err := NewExporter(context.Background(), &Config{})
+	assert.Error(t, err, "a collector is required")
+
+	_, err = NewExporter(context.Background(), &Config{HTTP: &HTTPConfig{URL: "ftp://collector"}})
+	assert.Error(t, err)
+
+	_, err = NewExporter(context.Background(), &Config{Syslog: &SyslogConfig{Network: "quic", Address: "collector:514"}})
+	assert.Error(t, err)
+}
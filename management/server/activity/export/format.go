@@ -0,0 +1,154 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/version"
+)
+
+const (
+	vendor  = "NetBird"
+	product = "Management"
+)
+
+// Formatter encodes an activity event as a single log line
+type Formatter interface {
+	Format(event *activity.Event) ([]byte, error)
+	// ContentType is the MIME type of the formatted events sent to HTTP collectors
+	ContentType() string
+}
+
+// NewFormatter returns the formatter of the format, json if the format is empty
+func NewFormatter(format string) (Formatter, error) {
+	switch strings.ToLower(format) {
+	case "", FormatJSON:
+		return &jsonFormatter{}, nil
+	case FormatCEF:
+		return &cefFormatter{}, nil
+	case FormatLEEF:
+		return &leefFormatter{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported event export format %q", format)
+	}
+}
+
+type jsonEvent struct {
+	ID           uint64         `json:"id"`
+	Timestamp    time.Time      `json:"timestamp"`
+	Activity     string         `json:"activity"`
+	ActivityCode string         `json:"activity_code"`
+	InitiatorID  string         `json:"initiator_id"`
+	TargetID     string         `json:"target_id"`
+	AccountID    string         `json:"account_id"`
+	Meta         map[string]any `json:"meta,omitempty"`
+}
+
+type jsonFormatter struct{}
+
+func (f *jsonFormatter) Format(event *activity.Event) ([]byte, error) {
+	return json.Marshal(&jsonEvent{
+		ID:           event.ID,
+		Timestamp:    event.Timestamp,
+		Activity:     event.Activity.Message(),
+		ActivityCode: event.Activity.StringCode(),
+		InitiatorID:  event.InitiatorID,
+		TargetID:     event.TargetID,
+		AccountID:    event.AccountID,
+		Meta:         event.Meta,
+	})
+}
+
+func (f *jsonFormatter) ContentType() string {
+	return "application/json"
+}
+
+// cefFormatter formats the events as CEF:Version|Device Vendor|Device Product|Device Version|Signature ID|Name|Severity|Extension
+type cefFormatter struct{}
+
+var (
+	cefHeaderEscaper    = strings.NewReplacer(`\`, `\\`, `|`, `\|`)
+	cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r", `\r`, "\n", `\n`)
+)
+
+func (f *cefFormatter) Format(event *activity.Event) ([]byte, error) {
+	meta, err := json.Marshal(event.Meta)
+	if err != nil {
+		return nil, fmt.Errorf("marshal event meta: %w", err)
+	}
+
+	extensions := []string{
+		"rt=" + fmt.Sprint(event.Timestamp.UnixMilli()),
+		"externalId=" + fmt.Sprint(event.ID),
+		"suid=" + cefExtensionEscaper.Replace(event.InitiatorID),
+		"duid=" + cefExtensionEscaper.Replace(event.TargetID),
+		"cs1Label=accountId",
+		"cs1=" + cefExtensionEscaper.Replace(event.AccountID),
+		"cs2Label=meta",
+		"cs2=" + cefExtensionEscaper.Replace(string(meta)),
+	}
+
+	line := fmt.Sprintf("CEF:0|%s|%s|%s|%s|%s|%d|%s",
+		vendor, product, cefHeaderEscaper.Replace(version.NetbirdVersion()),
+		cefHeaderEscaper.Replace(event.Activity.StringCode()), cefHeaderEscaper.Replace(event.Activity.Message()),
+		severity(event), strings.Join(extensions, " "))
+
+	return []byte(line), nil
+}
+
+func (f *cefFormatter) ContentType() string {
+	return "text/plain"
+}
+
+// leefFormatter formats the events as LEEF:2.0|Vendor|Product|Version|EventID|Delimiter|Attributes
+type leefFormatter struct{}
+
+const leefDelimiter = "^"
+
+var leefEscaper = strings.NewReplacer(leefDelimiter, `\^`, "\r", " ", "\n", " ")
+
+func (f *leefFormatter) Format(event *activity.Event) ([]byte, error) {
+	meta, err := json.Marshal(event.Meta)
+	if err != nil {
+		return nil, fmt.Errorf("marshal event meta: %w", err)
+	}
+
+	attributes := []string{
+		"devTime=" + fmt.Sprint(event.Timestamp.UnixMilli()),
+		"devTimeFormat=epoch",
+		"sev=" + fmt.Sprint(severity(event)),
+		"cat=" + leefEscaper.Replace(event.Activity.Message()),
+		"usrName=" + leefEscaper.Replace(event.InitiatorID),
+		"identSrc=" + leefEscaper.Replace(event.TargetID),
+		"accountId=" + leefEscaper.Replace(event.AccountID),
+		"eventId=" + fmt.Sprint(event.ID),
+		"meta=" + leefEscaper.Replace(string(meta)),
+	}
+
+	line := fmt.Sprintf("LEEF:2.0|%s|%s|%s|%s|%s|%s",
+		vendor, product, cefHeaderEscaper.Replace(version.NetbirdVersion()),
+		cefHeaderEscaper.Replace(event.Activity.StringCode()), leefDelimiter, strings.Join(attributes, leefDelimiter))
+
+	return []byte(line), nil
+}
+
+func (f *leefFormatter) ContentType() string {
+	return "text/plain"
+}
+
+// severity maps the activity to a CEF/LEEF severity between 0 and 10.
+// Deletions, failures and blocks are reported with a higher severity than other changes.
+func severity(event *activity.Event) int {
+	code := event.Activity.StringCode()
+	switch {
+	case strings.Contains(code, "fail"), strings.Contains(code, "block"):
+		return 7
+	case strings.Contains(code, "delete"), strings.Contains(code, "remove"):
+		return 5
+	default:
+		return 3
+	}
+}
@@ -0,0 +1,75 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/management/server/activity"
+)
+
+func testEvent() *activity.Event {
+	return &activity.Event{
+		ID:          7,
+		Timestamp:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Activity:    activity.PeerRemovedByUser,
+		InitiatorID: "user=1",
+		TargetID:    "peer|1",
+		AccountID:   "account1",
+		Meta:        map[string]any{"name": "peer^1\nsecond"},
+	}
+}
+
+func TestFormatter_JSON(t *testing.T) {
+	formatter, err := NewFormatter("")
+	require.NoError(t, err)
+
+	line, err := formatter.Format(testEvent())
+	require.NoError(t, err)
+
+	event := &jsonEvent{}
+	require.NoError(t, json.Unmarshal(line, event))
+	assert.Equal(t, uint64(7), event.ID)
+	assert.Equal(t, activity.PeerRemovedByUser.StringCode(), event.ActivityCode)
+	assert.Equal(t, "peer|1", event.TargetID)
+	assert.Equal(t, "application/json", formatter.ContentType())
+}
+
+func TestFormatter_CEF(t *testing.T) {
+	formatter, err := NewFormatter(FormatCEF)
+	require.NoError(t, err)
+
+	line, err := formatter.Format(testEvent())
+	require.NoError(t, err)
+
+	s := string(line)
+	assert.True(t, strings.HasPrefix(s, "CEF:0|NetBird|Management|"))
+	assert.Contains(t, s, "|"+activity.PeerRemovedByUser.StringCode()+"|"+activity.PeerRemovedByUser.Message()+"|5|")
+	assert.Contains(t, s, `suid=user\=1`)
+	assert.Contains(t, s, "duid=peer|1", "pipes are only escaped in the header")
+	assert.Contains(t, s, "rt=1704164645000")
+	assert.NotContains(t, s, "\n")
+}
+
+func TestFormatter_LEEF(t *testing.T) {
+	formatter, err := NewFormatter(FormatLEEF)
+	require.NoError(t, err)
+
+	line, err := formatter.Format(testEvent())
+	require.NoError(t, err)
+
+	s := string(line)
+	assert.True(t, strings.HasPrefix(s, "LEEF:2.0|NetBird|Management|"))
+	assert.Contains(t, s, "|"+activity.PeerRemovedByUser.StringCode()+"|^|devTime=1704164645000^")
+	assert.Contains(t, s, `peer\^1`)
+	assert.NotContains(t, s, "\n")
+}
+
+func TestFormatter_Unsupported(t *testing.T) {
+	_, err := NewFormatter("xml")
+	assert.Error(t, err)
+}
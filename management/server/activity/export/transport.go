@@ -0,0 +1,219 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	defaultAppName = "netbird-management"
+	// syslogPriority is facility local0 (16) with severity informational (6)
+	syslogPriority = 16*8 + 6
+	dialTimeout    = 10 * time.Second
+	writeTimeout   = 10 * time.Second
+	requestTimeout = 10 * time.Second
+)
+
+// Transport ships formatted events to a collector
+type Transport interface {
+	Send(ctx context.Context, message []byte) error
+	Close() error
+}
+
+// NewTransport returns the transport of the collector defined in the config
+func NewTransport(config *Config, formatter Formatter) (Transport, error) {
+	switch {
+	case config.Syslog != nil && config.HTTP != nil:
+		return nil, errors.New("only one of syslog or http event export can be configured")
+	case config.Syslog != nil:
+		return newSyslogTransport(config.Syslog)
+	case config.HTTP != nil:
+		return newHTTPTransport(config.HTTP, formatter.ContentType())
+	default:
+		return nil, errors.New("event export requires a syslog or http collector")
+	}
+}
+
+// syslogTransport sends RFC 5424 messages to a syslog collector.
+// Stream connections use octet counting framing as defined in RFC 6587.
+type syslogTransport struct {
+	network  string
+	address  string
+	appName  string
+	hostname string
+	tls      *tls.Config
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func newSyslogTransport(config *SyslogConfig) (*syslogTransport, error) {
+	if config.Address == "" {
+		return nil, errors.New("syslog address is required")
+	}
+
+	network := config.Network
+	if network == "" {
+		network = "udp"
+	}
+	if network != "udp" && network != "tcp" && network != "tls" {
+		return nil, fmt.Errorf("unsupported syslog network %q", network)
+	}
+
+	appName := config.AppName
+	if appName == "" {
+		appName = defaultAppName
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+
+	t := &syslogTransport{
+		network:  network,
+		address:  config.Address,
+		appName:  appName,
+		hostname: hostname,
+	}
+
+	if network == "tls" {
+		host, _, err := net.SplitHostPort(config.Address)
+		if err != nil {
+			return nil, fmt.Errorf("parse syslog address: %w", err)
+		}
+		t.tls = &tls.Config{
+			ServerName:         host,
+			InsecureSkipVerify: config.InsecureSkipVerify, //nolint:gosec
+		}
+	}
+
+	return t, nil
+}
+
+// Send writes the message to the collector. The connection is dropped on failure and dialed again on the next send.
+func (t *syslogTransport) Send(ctx context.Context, message []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.conn == nil {
+		conn, err := t.dial(ctx)
+		if err != nil {
+			return fmt.Errorf("dial syslog collector: %w", err)
+		}
+		t.conn = conn
+	}
+
+	frame := t.frame(message)
+	if err := t.conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+		t.closeConn()
+		return fmt.Errorf("set syslog write deadline: %w", err)
+	}
+
+	if _, err := t.conn.Write(frame); err != nil {
+		t.closeConn()
+		return fmt.Errorf("write syslog message: %w", err)
+	}
+
+	return nil
+}
+
+func (t *syslogTransport) dial(ctx context.Context) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	if t.tls != nil {
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: t.tls}
+		return tlsDialer.DialContext(ctx, "tcp", t.address)
+	}
+	return dialer.DialContext(ctx, t.network, t.address)
+}
+
+// frame builds the RFC 5424 message: <PRI>VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+func (t *syslogTransport) frame(message []byte) []byte {
+	header := fmt.Sprintf("<%d>1 %s %s %s %d - - ", syslogPriority, time.Now().UTC().Format(time.RFC3339Nano), t.hostname, t.appName, os.Getpid())
+	msg := append([]byte(header), message...)
+
+	if t.network == "udp" {
+		return msg
+	}
+
+	return append([]byte(fmt.Sprintf("%d ", len(msg))), msg...)
+}
+
+func (t *syslogTransport) closeConn() {
+	if t.conn != nil {
+		_ = t.conn.Close()
+		t.conn = nil
+	}
+}
+
+func (t *syslogTransport) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closeConn()
+	return nil
+}
+
+// httpTransport posts every event to an HTTP collector
+type httpTransport struct {
+	url         string
+	headers     map[string]string
+	contentType string
+	httpClient  *http.Client
+}
+
+func newHTTPTransport(config *HTTPConfig, contentType string) (*httpTransport, error) {
+	u, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("parse event export url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported event export url scheme %q", u.Scheme)
+	}
+
+	return &httpTransport{
+		url:         config.URL,
+		headers:     config.Headers,
+		contentType: contentType,
+		httpClient: &http.Client{
+			Timeout: requestTimeout,
+		},
+	}, nil
+}
+
+func (t *httpTransport) Send(ctx context.Context, message []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(message))
+	if err != nil {
+		return fmt.Errorf("create event export request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", t.contentType)
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("send event export request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("event collector responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (t *httpTransport) Close() error {
+	t.httpClient.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,56 @@
+package activity
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Sink receives the events saved in the activity store, e.g. to deliver them to webhooks or to feed external data pipelines.
+// Publish is called synchronously on every save and should hand the event over to a background worker.
+type Sink interface {
+	// Publish the saved event
+	Publish(ctx context.Context, event *Event) error
+	// Close the sink flushing events if necessary
+	Close(ctx context.Context) error
+}
+
+// SinkStore is a Store publishing every saved event to the sinks
+type SinkStore struct {
+	Store
+	sinks []Sink
+}
+
+// NewSinkStore wraps the store so that saved events are published to the sinks
+func NewSinkStore(store Store, sinks ...Sink) *SinkStore {
+	return &SinkStore{
+		Store: store,
+		sinks: sinks,
+	}
+}
+
+// Save stores the event and publishes it to the sinks. Failing sinks don't fail the save.
+func (s *SinkStore) Save(ctx context.Context, event *Event) (*Event, error) {
+	saved, err := s.Store.Save(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sink := range s.sinks {
+		if err := sink.Publish(ctx, saved); err != nil {
+			log.WithContext(ctx).Warnf("failed to publish event %d to sink: %v", saved.ID, err)
+		}
+	}
+
+	return saved, nil
+}
+
+// Close closes the sinks and the wrapped store
+func (s *SinkStore) Close(ctx context.Context) error {
+	for _, sink := range s.sinks {
+		if err := sink.Close(ctx); err != nil {
+			log.WithContext(ctx).Warnf("failed to close event sink: %v", err)
+		}
+	}
+	return s.Store.Close(ctx)
+}
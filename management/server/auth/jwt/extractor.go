@@ -142,3 +142,53 @@ func (c *ClaimsExtractor) ToGroups(token *jwt.Token, claimName string) []string
 
 	return userJWTGroups
 }
+
+// ToGroupsFromClaimPath extracts the groups from the claim at the given path. The path is matched against the
+// claim names first, so names containing dots (e.g. namespaced URLs) are supported, and then as nested claims
+// separated by dots. A claim holding a single string is treated as a single group.
+func (c *ClaimsExtractor) ToGroupsFromClaimPath(token *jwt.Token, claimPath string) []string {
+	claims := token.Claims.(jwt.MapClaims)
+	userJWTGroups := make([]string, 0)
+
+	claim, ok := lookupClaim(claims, claimPath)
+	if !ok {
+		log.Debugf("JWT claim %q not found", claimPath)
+		return userJWTGroups
+	}
+
+	switch value := claim.(type) {
+	case string:
+		userJWTGroups = append(userJWTGroups, value)
+	case []interface{}:
+		for _, g := range value {
+			if group, ok := g.(string); ok {
+				userJWTGroups = append(userJWTGroups, group)
+			} else {
+				log.Debugf("JWT claim %q contains a non-string group (type: %T): %v", claimPath, g, g)
+			}
+		}
+	default:
+		log.Debugf("JWT claim %q is neither a string nor a string array", claimPath)
+	}
+
+	return userJWTGroups
+}
+
+func lookupClaim(claims map[string]interface{}, path string) (interface{}, bool) {
+	if value, ok := claims[path]; ok {
+		return value, true
+	}
+
+	for i := 0; i < len(path); i++ {
+		if path[i] != '.' {
+			continue
+		}
+		if nested, ok := claims[path[:i]].(map[string]interface{}); ok {
+			if value, ok := lookupClaim(nested, path[i+1:]); ok {
+				return value, true
+			}
+		}
+	}
+
+	return nil, false
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"hash/crc32"
+	"slices"
 
 	"github.com/golang-jwt/jwt"
 
@@ -81,7 +82,7 @@ func (m *manager) EnsureUserAccessByJWTGroups(ctx context.Context, userAuth nbco
 	// Ensures JWT group synchronization to the management is enabled before,
 	// filtering access based on the allowed groups.
 	if settings != nil && settings.JWTGroupsEnabled {
-		userAuth.Groups = m.extractor.ToGroups(token, settings.JWTGroupsClaimName)
+		userAuth.Groups = m.extractGroups(token, settings)
 		if allowedGroups := settings.JWTAllowGroups; len(allowedGroups) > 0 {
 			if !userHasAllowedGroup(allowedGroups, userAuth.Groups) {
 				return userAuth, fmt.Errorf("user does not belong to any of the allowed JWT groups")
@@ -92,6 +93,25 @@ func (m *manager) EnsureUserAccessByJWTGroups(ctx context.Context, userAuth nbco
 	return userAuth, nil
 }
 
+// extractGroups returns the groups of the token mapped by the account JWT groups mapping rules
+// or the groups of the JWTGroupsClaimName claim if no rule is defined
+func (m *manager) extractGroups(token *jwt.Token, settings *types.Settings) []string {
+	if len(settings.JWTGroupsMappingRules) == 0 {
+		return m.extractor.ToGroups(token, settings.JWTGroupsClaimName)
+	}
+
+	groups := make([]string, 0)
+	for _, rule := range settings.JWTGroupsMappingRules {
+		for _, group := range rule.Apply(m.extractor.ToGroupsFromClaimPath(token, rule.Claim)) {
+			if !slices.Contains(groups, group) {
+				groups = append(groups, group)
+			}
+		}
+	}
+
+	return groups
+}
+
 // MarkPATUsed marks a personal access token as used
 func (am *manager) MarkPATUsed(ctx context.Context, tokenID string) error {
 	return am.store.MarkPATUsed(ctx, store.LockingStrengthUpdate, tokenID)
@@ -206,6 +206,40 @@ func TestAuthManager_EnsureUserAccessByJWTGroups(t *testing.T) {
 		_, err = manager.EnsureUserAccessByJWTGroups(context.Background(), userAuth, token)
 		require.Error(t, err, "ensure user access is not in allowed groups")
 	})
+
+	t.Run("JWT groups mapping rules", func(t *testing.T) {
+		account.Settings.JWTGroupsEnabled = true
+		account.Settings.JWTGroupsClaimName = "idp-groups"
+		account.Settings.JWTAllowGroups = []string{}
+		account.Settings.JWTGroupsMappingRules = []*types.JWTGroupsMappingRule{
+			{
+				Claim:       "realm_access.roles",
+				Prefix:      "netbird-",
+				StripPrefix: true,
+				Rename:      map[string]string{"admins": "Administrators"},
+			},
+			{
+				Claim: "https://example.com/department",
+			},
+			{
+				Claim: "idp-groups",
+			},
+		}
+		err := store.SaveAccount(context.Background(), account)
+		require.NoError(t, err, "save account failed")
+
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"idp-groups": []interface{}{"group1", "group2"},
+			"realm_access": map[string]interface{}{
+				"roles": []interface{}{"netbird-admins", "netbird-devs", "offline_access"},
+			},
+			"https://example.com/department": "engineering",
+		})
+
+		userAuth, err := manager.EnsureUserAccessByJWTGroups(context.Background(), userAuth, token)
+		require.NoError(t, err, "ensure user access by JWT groups failed")
+		require.Equal(t, []string{"Administrators", "devs", "engineering", "group1", "group2"}, userAuth.Groups, "group parsed do not match")
+	})
 }
 
 func TestAuthManager_ValidateAndParseToken(t *testing.T) {
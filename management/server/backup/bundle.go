@@ -0,0 +1,167 @@
+package backup
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+
+	"github.com/netbirdio/netbird/management/server/types"
+	"github.com/netbirdio/netbird/version"
+)
+
+const (
+	// FormatVersion is the version of the bundles created by this management version
+	FormatVersion = 1
+
+	// MinPassphraseLength is the minimum length of the passphrase encrypting a bundle
+	MinPassphraseLength = 12
+
+	kdfArgon2id = "argon2id"
+	saltSize    = 16
+	keySize     = 32
+	// maxKDFTime and maxKDFMemory (KiB) limit the resources a crafted bundle can make the key derivation use
+	maxKDFTime   = 10
+	maxKDFMemory = 1024 * 1024
+)
+
+// ErrDecrypt is returned when a bundle can't be decrypted, either because the passphrase is wrong or the bundle
+// was modified
+var ErrDecrypt = errors.New("failed to decrypt the bundle, the passphrase is wrong or the bundle is corrupted")
+
+// kdfParams are the argon2id parameters deriving the bundle key from the passphrase
+type kdfParams struct {
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"`
+	Threads uint8  `json:"threads"`
+}
+
+var defaultKDFParams = kdfParams{Time: 3, Memory: 64 * 1024, Threads: 4}
+
+// envelope is the encrypted bundle written to the file, the parameters needed for decryption are stored in clear
+type envelope struct {
+	Version    int       `json:"version"`
+	KDF        string    `json:"kdf"`
+	KDFParams  kdfParams `json:"kdf_params"`
+	Salt       []byte    `json:"salt"`
+	Nonce      []byte    `json:"nonce"`
+	Ciphertext []byte    `json:"ciphertext"`
+}
+
+// Payload is the content of a bundle
+type Payload struct {
+	Version        int            `json:"version"`
+	NetbirdVersion string         `json:"netbird_version"`
+	ExportedAt     time.Time      `json:"exported_at"`
+	Account        *types.Account `json:"account"`
+}
+
+// Seal encrypts the account with a key derived from the passphrase and returns the bundle
+func Seal(account *types.Account, passphrase string) ([]byte, error) {
+	if err := validatePassphrase(passphrase); err != nil {
+		return nil, err
+	}
+
+	plaintext, err := json.Marshal(&Payload{
+		Version:        FormatVersion,
+		NetbirdVersion: version.NetbirdVersion(),
+		ExportedAt:     time.Now().UTC(),
+		Account:        account,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal account: %w", err)
+	}
+
+	env := &envelope{
+		Version:   FormatVersion,
+		KDF:       kdfArgon2id,
+		KDFParams: defaultKDFParams,
+		Salt:      make([]byte, saltSize),
+	}
+	if _, err = rand.Read(env.Salt); err != nil {
+		return nil, fmt.Errorf("generate salt: %w", err)
+	}
+
+	gcm, err := newGCM(passphrase, env)
+	if err != nil {
+		return nil, err
+	}
+
+	env.Nonce = make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(env.Nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+	env.Ciphertext = gcm.Seal(nil, env.Nonce, plaintext, additionalData(env))
+
+	return json.Marshal(env)
+}
+
+// Open decrypts the bundle with the passphrase and returns its payload
+func Open(bundle []byte, passphrase string) (*Payload, error) {
+	var env envelope
+	if err := json.Unmarshal(bundle, &env); err != nil {
+		return nil, fmt.Errorf("invalid bundle: %w", err)
+	}
+	if env.Version < 1 || env.Version > FormatVersion {
+		return nil, fmt.Errorf("unsupported bundle version %d, the bundle was created by a newer management version", env.Version)
+	}
+	if env.KDF != kdfArgon2id {
+		return nil, fmt.Errorf("unsupported bundle key derivation %s", env.KDF)
+	}
+
+	gcm, err := newGCM(passphrase, &env)
+	if err != nil {
+		return nil, err
+	}
+	if len(env.Nonce) != gcm.NonceSize() {
+		return nil, ErrDecrypt
+	}
+
+	plaintext, err := gcm.Open(nil, env.Nonce, env.Ciphertext, additionalData(&env))
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+
+	var payload Payload
+	if err = json.Unmarshal(plaintext, &payload); err != nil {
+		return nil, fmt.Errorf("invalid bundle payload: %w", err)
+	}
+	if payload.Account == nil || payload.Account.Id == "" {
+		return nil, fmt.Errorf("bundle has no account")
+	}
+
+	return &payload, nil
+}
+
+func newGCM(passphrase string, env *envelope) (cipher.AEAD, error) {
+	params := env.KDFParams
+	if params.Time == 0 || params.Time > maxKDFTime || params.Memory == 0 || params.Memory > maxKDFMemory ||
+		params.Threads == 0 || len(env.Salt) != saltSize {
+		return nil, fmt.Errorf("invalid bundle key derivation parameters")
+	}
+
+	key := argon2.IDKey([]byte(passphrase), env.Salt, params.Time, params.Memory, params.Threads, keySize)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// additionalData authenticates the clear parameters of the envelope
+func additionalData(env *envelope) []byte {
+	return []byte(fmt.Sprintf("netbird-account-bundle/%d/%s/%d/%d/%d", env.Version, env.KDF, env.KDFParams.Time, env.KDFParams.Memory, env.KDFParams.Threads))
+}
+
+func validatePassphrase(passphrase string) error {
+	if len(passphrase) < MinPassphraseLength {
+		return fmt.Errorf("passphrase has to be at least %d characters long", MinPassphraseLength)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package backup
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+	"github.com/netbirdio/netbird/management/server/types"
+)
+
+const testPassphrase = "correct horse battery staple"
+
+func newTestAccount() *types.Account {
+	return &types.Account{
+		Id:     "accountID",
+		Domain: "example.com",
+		Peers: map[string]*nbpeer.Peer{
+			"peer1": {ID: "peer1", AccountID: "accountID", Key: "peer1Key", Name: "peer1"},
+		},
+		Groups: map[string]*types.Group{
+			"group1": {ID: "group1", Name: "group1", Peers: []string{"peer1"}},
+		},
+		Settings: &types.Settings{PeerLoginExpirationEnabled: true},
+	}
+}
+
+func TestSealOpen(t *testing.T) {
+	bundle, err := Seal(newTestAccount(), testPassphrase)
+	require.NoError(t, err)
+	assert.NotContains(t, string(bundle), "peer1Key", "bundle should be encrypted")
+
+	payload, err := Open(bundle, testPassphrase)
+	require.NoError(t, err)
+	assert.Equal(t, FormatVersion, payload.Version)
+	assert.Equal(t, "accountID", payload.Account.Id)
+	assert.Equal(t, "peer1Key", payload.Account.Peers["peer1"].Key)
+	assert.Equal(t, []string{"peer1"}, payload.Account.Groups["group1"].Peers)
+	assert.True(t, payload.Account.Settings.PeerLoginExpirationEnabled)
+
+	_, err = Open(bundle, "another passphrase")
+	assert.ErrorIs(t, err, ErrDecrypt)
+}
+
+func TestSeal_ShortPassphrase(t *testing.T) {
+	_, err := Seal(newTestAccount(), "short")
+	assert.Error(t, err)
+}
+
+func TestOpen_Tampered(t *testing.T) {
+	bundle, err := Seal(newTestAccount(), testPassphrase)
+	require.NoError(t, err)
+
+	var env envelope
+	require.NoError(t, json.Unmarshal(bundle, &env))
+
+	tampered := env
+	tampered.Ciphertext = append([]byte{}, env.Ciphertext...)
+	tampered.Ciphertext[0] ^= 0xff
+	data, err := json.Marshal(&tampered)
+	require.NoError(t, err)
+	_, err = Open(data, testPassphrase)
+	assert.ErrorIs(t, err, ErrDecrypt)
+
+	tampered = env
+	tampered.KDFParams.Memory = maxKDFMemory + 1
+	data, err = json.Marshal(&tampered)
+	require.NoError(t, err)
+	_, err = Open(data, testPassphrase)
+	assert.Error(t, err, "excessive key derivation parameters should be rejected")
+
+	tampered = env
+	tampered.Version = FormatVersion + 1
+	data, err = json.Marshal(&tampered)
+	require.NoError(t, err)
+	_, err = Open(data, testPassphrase)
+	assert.Error(t, err)
+}
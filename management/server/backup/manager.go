@@ -0,0 +1,141 @@
+package backup
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/management/server/account"
+	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/permissions"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
+	"github.com/netbirdio/netbird/management/server/types"
+)
+
+type Manager interface {
+	// ExportAccount returns the account encrypted with the passphrase, only the account owner can export it
+	ExportAccount(ctx context.Context, accountID, userID, passphrase string) ([]byte, error)
+}
+
+type managerImpl struct {
+	store              store.Store
+	permissionsManager permissions.Manager
+	accountManager     account.Manager
+}
+
+func NewManager(store store.Store, permissionsManager permissions.Manager, accountManager account.Manager) Manager {
+	return &managerImpl{
+		store:              store,
+		permissionsManager: permissionsManager,
+		accountManager:     accountManager,
+	}
+}
+
+func (m *managerImpl) ExportAccount(ctx context.Context, accountID, userID, passphrase string) ([]byte, error) {
+	ok, err := m.permissionsManager.ValidateUserPermissions(ctx, accountID, userID, permissions.Accounts, permissions.Write)
+	if err != nil {
+		return nil, status.NewPermissionValidationError(err)
+	}
+	if !ok {
+		return nil, status.NewPermissionDeniedError()
+	}
+
+	bundle, err := Export(ctx, m.store, accountID, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	m.accountManager.StoreEvent(ctx, userID, accountID, accountID, activity.AccountExported, nil)
+
+	return bundle, nil
+}
+
+// Export returns the account of the store encrypted with the passphrase
+func Export(ctx context.Context, s store.Store, accountID, passphrase string) ([]byte, error) {
+	if err := validatePassphrase(passphrase); err != nil {
+		return nil, status.Errorf(status.InvalidArgument, "%s", err)
+	}
+
+	account, err := s.GetAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle, err := Seal(account, passphrase)
+	if err != nil {
+		log.WithContext(ctx).Errorf("failed to export account %s: %v", accountID, err)
+		return nil, status.Errorf(status.Internal, "failed to export account")
+	}
+
+	return bundle, nil
+}
+
+// Import decrypts the bundle and saves its account to the store. An existing account with the same ID is replaced
+// if overwrite is set, otherwise the import fails. The peers and users of the bundle can't belong to another
+// account of the store.
+func Import(ctx context.Context, s store.Store, bundle []byte, passphrase string, overwrite bool) (*types.Account, error) {
+	payload, err := Open(bundle, passphrase)
+	if err != nil {
+		return nil, status.Errorf(status.InvalidArgument, "%s", err)
+	}
+	account := payload.Account
+
+	exists, err := s.AccountExists(ctx, store.LockingStrengthShare, account.Id)
+	if err != nil {
+		return nil, err
+	}
+	if exists && !overwrite {
+		return nil, status.Errorf(status.AlreadyExists, "account %s already exists", account.Id)
+	}
+
+	for _, peer := range account.Peers {
+		peerAccountID, err := s.GetAccountIDByPeerPubKey(ctx, peer.Key)
+		used, err := usedByOtherAccount(err, peerAccountID, account.Id)
+		if err != nil {
+			return nil, err
+		}
+		if used {
+			return nil, status.Errorf(status.AlreadyExists, "peer %s belongs to another account", peer.Name)
+		}
+	}
+	for _, user := range account.Users {
+		userAccountID, err := s.GetAccountIDByUserID(ctx, store.LockingStrengthShare, user.Id)
+		used, err := usedByOtherAccount(err, userAccountID, account.Id)
+		if err != nil {
+			return nil, err
+		}
+		if used {
+			return nil, status.Errorf(status.AlreadyExists, "user %s belongs to another account", user.Id)
+		}
+	}
+
+	if err = s.SaveAccount(ctx, account); err != nil {
+		return nil, err
+	}
+
+	log.WithContext(ctx).Infof("imported account %s exported at %s by management %s", account.Id, payload.ExportedAt, payload.NetbirdVersion)
+
+	return account, nil
+}
+
+// usedByOtherAccount returns true if the lookup of an object found it in another account than the imported one
+func usedByOtherAccount(lookupErr error, ownerAccountID, accountID string) (bool, error) {
+	if lookupErr != nil {
+		if s, ok := status.FromError(lookupErr); ok && s.Type() == status.NotFound {
+			return false, nil
+		}
+		return false, lookupErr
+	}
+	return ownerAccountID != accountID, nil
+}
+
+type mockManager struct{}
+
+func NewManagerMock() Manager {
+	return &mockManager{}
+}
+
+func (m *mockManager) ExportAccount(ctx context.Context, accountID, userID, passphrase string) ([]byte, error) {
+	return []byte("{}"), nil
+}
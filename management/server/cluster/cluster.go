@@ -0,0 +1,107 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/rs/xid"
+)
+
+// Config defines how the management servers sharing a store coordinate with each other.
+// Without it the management server runs standalone and must be the only one serving the peers of the store.
+type Config struct {
+	// RedisAddress is the URL of the Redis server used to exchange the peer updates between the servers and to lock
+	// the account writes, e.g. redis://redis:6379/0. https://github.com/redis/redis-specifications/blob/master/uri/redis.txt
+	RedisAddress string
+	// Channel is the Redis pub/sub channel the peer updates are exchanged on. Defaults to netbird.management.updates
+	Channel string
+}
+
+// MessageType is the kind of change a message asks the other servers to propagate to their connected peers
+type MessageType string
+
+const (
+	// AccountPeersUpdate asks to send a network map update to all connected peers of the account
+	AccountPeersUpdate MessageType = "account_peers_update"
+	// PeerUpdate asks to send a network map update to the connected peer
+	PeerUpdate MessageType = "peer_update"
+	// PeersDisconnect asks to close the sync streams of the peers, e.g. because they were deleted or their login expired
+	PeersDisconnect MessageType = "peers_disconnect"
+)
+
+// Message is exchanged between the management servers
+type Message struct {
+	// Node is the ID of the server that published the message
+	Node      string      `json:"node"`
+	Type      MessageType `json:"type"`
+	AccountID string      `json:"account_id"`
+	PeerIDs   []string    `json:"peer_ids,omitempty"`
+}
+
+// Handler processes the messages published by the other servers
+type Handler func(ctx context.Context, message *Message)
+
+// Cluster connects the management servers sharing a store. Each server owns the sync streams of the peers connected
+// to it, so the updates are published to the other servers which deliver them to their own peers.
+type Cluster interface {
+	// NodeID returns the ID of this server
+	NodeID() string
+	// Distributed returns true if other servers may be serving the peers of the store
+	Distributed() bool
+	// Publish sends the message to the other servers
+	Publish(ctx context.Context, message *Message)
+	// Subscribe starts delivering the messages of the other servers to the handler
+	Subscribe(ctx context.Context, handler Handler)
+	// Lock acquires the lock of the key across the servers and returns a function that releases it
+	Lock(ctx context.Context, key string) (unlock func(), err error)
+	// Close stops the subscription and releases the connections
+	Close() error
+}
+
+// New returns the Cluster defined by the config. If no config is given a standalone cluster is returned.
+func New(ctx context.Context, config *Config) (Cluster, error) {
+	if config == nil || config.RedisAddress == "" {
+		return NewStandalone(), nil
+	}
+
+	return newRedisCluster(ctx, config, newNodeID())
+}
+
+func newNodeID() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		return xid.New().String()
+	}
+	return fmt.Sprintf("%s-%s", hostname, xid.New().String())
+}
+
+// standalone is the Cluster of a single management server: messages aren't published and locks are local only
+type standalone struct {
+	nodeID string
+}
+
+// NewStandalone returns the Cluster of a management server which is the only one serving the peers of its store
+func NewStandalone() Cluster {
+	return &standalone{nodeID: newNodeID()}
+}
+
+func (s *standalone) NodeID() string {
+	return s.nodeID
+}
+
+func (s *standalone) Distributed() bool {
+	return false
+}
+
+func (s *standalone) Publish(_ context.Context, _ *Message) {}
+
+func (s *standalone) Subscribe(_ context.Context, _ Handler) {}
+
+func (s *standalone) Lock(_ context.Context, _ string) (func(), error) {
+	return func() {}, nil
+}
+
+func (s *standalone) Close() error {
+	return nil
+}
@@ -0,0 +1,214 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/xid"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultChannel = "netbird.management.updates"
+	lockKeyPrefix  = "netbird:management:lock:"
+
+	// lockTTL is the expiration of a lock, it is extended while the lock is held so that it only expires
+	// if the server holding it stops
+	lockTTL = 30 * time.Second
+	// lockRetryInterval is the interval between the attempts to acquire a lock held by another server
+	lockRetryInterval = 20 * time.Millisecond
+	// lockWaitTimeout is the maximum time spent waiting for a lock
+	lockWaitTimeout = time.Minute
+	// redisTimeout is the timeout of the requests that aren't bound to the context of the caller
+	redisTimeout = 5 * time.Second
+)
+
+// unlockScript deletes the lock only if it is still held with the token, it might have expired and been acquired by another server
+var unlockScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("del", KEYS[1])
+end
+return 0`)
+
+// extendScript extends the expiration of the lock only if it is still held with the token
+var extendScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("pexpire", KEYS[1], ARGV[2])
+end
+return 0`)
+
+// redisCluster exchanges the messages through Redis pub/sub and locks the keys with expiring Redis keys
+type redisCluster struct {
+	nodeID  string
+	client  *redis.Client
+	channel string
+
+	mu     sync.Mutex
+	pubsub *redis.PubSub
+}
+
+func newRedisCluster(ctx context.Context, config *Config, nodeID string) (*redisCluster, error) {
+	options, err := redis.ParseURL(config.RedisAddress)
+	if err != nil {
+		return nil, fmt.Errorf("parsing cluster redis url: %w", err)
+	}
+
+	client := redis.NewClient(options)
+
+	pingCtx, cancel := context.WithTimeout(ctx, redisTimeout)
+	defer cancel()
+	if err = client.Ping(pingCtx).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("connecting to cluster redis: %w", err)
+	}
+
+	channel := config.Channel
+	if channel == "" {
+		channel = defaultChannel
+	}
+
+	log.WithContext(ctx).Infof("running in cluster mode as node %s, exchanging updates on redis channel %s", nodeID, channel)
+
+	return &redisCluster{
+		nodeID:  nodeID,
+		client:  client,
+		channel: channel,
+	}, nil
+}
+
+func (c *redisCluster) NodeID() string {
+	return c.nodeID
+}
+
+func (c *redisCluster) Distributed() bool {
+	return true
+}
+
+// Publish sends the message to the other servers. Failures are logged, the peers of the other servers then get
+// the change with their next update or when they reconnect.
+func (c *redisCluster) Publish(ctx context.Context, message *Message) {
+	message.Node = c.nodeID
+
+	payload, err := json.Marshal(message)
+	if err != nil {
+		log.WithContext(ctx).Errorf("failed to encode cluster message: %v", err)
+		return
+	}
+
+	publishCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), redisTimeout)
+	defer cancel()
+
+	if err = c.client.Publish(publishCtx, c.channel, payload).Err(); err != nil {
+		log.WithContext(ctx).Errorf("failed to publish %s message of account %s to the cluster: %v", message.Type, message.AccountID, err)
+	}
+}
+
+// Subscribe delivers the messages of the other servers to the handler until the cluster is closed.
+// The handler is called in its own goroutine, so slow handlers don't hold back the subscription.
+func (c *redisCluster) Subscribe(ctx context.Context, handler Handler) {
+	pubsub := c.client.Subscribe(ctx, c.channel)
+
+	c.mu.Lock()
+	c.pubsub = pubsub
+	c.mu.Unlock()
+
+	go func() {
+		for msg := range pubsub.Channel() {
+			var message Message
+			if err := json.Unmarshal([]byte(msg.Payload), &message); err != nil {
+				log.WithContext(ctx).Errorf("failed to decode cluster message: %v", err)
+				continue
+			}
+
+			if message.Node == c.nodeID {
+				continue
+			}
+
+			log.WithContext(ctx).Tracef("received %s message of account %s from node %s", message.Type, message.AccountID, message.Node)
+
+			go handler(ctx, &message)
+		}
+	}()
+}
+
+// Lock acquires the lock of the key, waiting while another server holds it. The lock is extended until it is released.
+func (c *redisCluster) Lock(ctx context.Context, key string) (func(), error) {
+	lockKey := lockKeyPrefix + key
+	token := xid.New().String()
+
+	waitCtx, cancel := context.WithTimeout(ctx, lockWaitTimeout)
+	defer cancel()
+
+	for {
+		acquired, err := c.client.SetNX(waitCtx, lockKey, token, lockTTL).Result()
+		if err != nil {
+			return nil, fmt.Errorf("acquire lock %s: %w", key, err)
+		}
+		if acquired {
+			break
+		}
+
+		select {
+		case <-waitCtx.Done():
+			return nil, fmt.Errorf("acquire lock %s: %w", key, waitCtx.Err())
+		case <-time.After(lockRetryInterval):
+		}
+	}
+
+	done := make(chan struct{})
+	go c.extendLock(lockKey, token, done)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+
+			unlockCtx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+			defer cancel()
+			if err := unlockScript.Run(unlockCtx, c.client, []string{lockKey}, token).Err(); err != nil {
+				log.Errorf("failed to release lock %s, it will expire in %s: %v", key, lockTTL, err)
+			}
+		})
+	}, nil
+}
+
+// extendLock extends the expiration of the lock until done is closed
+func (c *redisCluster) extendLock(lockKey, token string, done chan struct{}) {
+	ticker := time.NewTicker(lockTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+			extended, err := extendScript.Run(ctx, c.client, []string{lockKey}, token, lockTTL.Milliseconds()).Int()
+			cancel()
+			if err != nil {
+				log.Warnf("failed to extend lock %s: %v", lockKey, err)
+				continue
+			}
+			if extended == 0 {
+				log.Warnf("lock %s expired while being held", lockKey)
+				return
+			}
+		}
+	}
+}
+
+func (c *redisCluster) Close() error {
+	c.mu.Lock()
+	pubsub := c.pubsub
+	c.mu.Unlock()
+
+	if pubsub != nil {
+		_ = pubsub.Close()
+	}
+
+	return c.client.Close()
+}
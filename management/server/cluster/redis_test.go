@@ -0,0 +1,131 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	testcontainersredis "github.com/testcontainers/testcontainers-go/modules/redis"
+)
+
+func newTestRedisConfig(t *testing.T) *Config {
+	t.Helper()
+
+	ctx := context.Background()
+	redisContainer, err := testcontainersredis.RunContainer(ctx, testcontainers.WithImage("redis:7"))
+	require.NoError(t, err, "couldn't start redis container")
+	t.Cleanup(func() {
+		if err := redisContainer.Terminate(ctx); err != nil {
+			t.Logf("failed to terminate container: %s", err)
+		}
+	})
+
+	redisURL, err := redisContainer.ConnectionString(ctx)
+	require.NoError(t, err)
+
+	return &Config{RedisAddress: redisURL}
+}
+
+func TestNew_Standalone(t *testing.T) {
+	c, err := New(context.Background(), nil)
+	require.NoError(t, err)
+	assert.False(t, c.Distributed())
+
+	unlock, err := c.Lock(context.Background(), "key")
+	require.NoError(t, err)
+	unlock()
+}
+
+func TestNew_ConnectionFailure(t *testing.T) {
+	_, err := New(context.Background(), &Config{RedisAddress: "redis://127.0.0.1:1"})
+	require.Error(t, err)
+}
+
+func TestRedisCluster_PublishSubscribe(t *testing.T) {
+	config := newTestRedisConfig(t)
+	ctx := context.Background()
+
+	first, err := New(ctx, config)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = first.Close() })
+
+	second, err := New(ctx, config)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = second.Close() })
+
+	firstReceived := make(chan *Message, 1)
+	first.Subscribe(ctx, func(_ context.Context, message *Message) {
+		firstReceived <- message
+	})
+
+	secondReceived := make(chan *Message, 1)
+	second.Subscribe(ctx, func(_ context.Context, message *Message) {
+		secondReceived <- message
+	})
+
+	// wait for the subscriptions to be established
+	time.Sleep(100 * time.Millisecond)
+
+	first.Publish(ctx, &Message{Type: PeerUpdate, AccountID: "account", PeerIDs: []string{"peer"}})
+
+	select {
+	case message := <-secondReceived:
+		assert.Equal(t, first.NodeID(), message.Node)
+		assert.Equal(t, PeerUpdate, message.Type)
+		assert.Equal(t, "account", message.AccountID)
+		assert.Equal(t, []string{"peer"}, message.PeerIDs)
+	case <-time.After(5 * time.Second):
+		t.Fatal("message wasn't delivered to the other node")
+	}
+
+	select {
+	case <-firstReceived:
+		t.Fatal("message was delivered to the publishing node")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestRedisCluster_Lock(t *testing.T) {
+	config := newTestRedisConfig(t)
+	ctx := context.Background()
+
+	first, err := New(ctx, config)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = first.Close() })
+
+	second, err := New(ctx, config)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = second.Close() })
+
+	unlock, err := first.Lock(ctx, "account")
+	require.NoError(t, err)
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+	_, err = second.Lock(timeoutCtx, "account")
+	require.Error(t, err, "lock held by another node should not be acquired")
+
+	otherUnlock, err := second.Lock(ctx, "other-account")
+	require.NoError(t, err)
+	otherUnlock()
+
+	acquired := make(chan struct{})
+	go func() {
+		secondUnlock, err := second.Lock(ctx, "account")
+		if err == nil {
+			secondUnlock()
+			close(acquired)
+		}
+	}()
+
+	unlock()
+
+	select {
+	case <-acquired:
+	case <-time.After(5 * time.Second):
+		t.Fatal("lock wasn't acquired after being released")
+	}
+}
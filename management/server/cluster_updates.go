@@ -0,0 +1,50 @@
+package server
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/management/server/cluster"
+	"github.com/netbirdio/netbird/management/server/store"
+)
+
+// SetCluster connects the account manager to the other management servers sharing the store. The peers connected
+// to the other servers then get the updates of the changes made through this server and the other way around.
+func (am *DefaultAccountManager) SetCluster(ctx context.Context, c cluster.Cluster) {
+	am.cluster = c
+	c.Subscribe(ctx, am.handleClusterMessage)
+}
+
+// handleClusterMessage delivers the updates published by another management server to the peers connected to this one
+func (am *DefaultAccountManager) handleClusterMessage(ctx context.Context, message *cluster.Message) {
+	switch message.Type {
+	case cluster.AccountPeersUpdate:
+		am.updateAccountPeers(ctx, message.AccountID)
+	case cluster.PeerUpdate:
+		for _, peerID := range message.PeerIDs {
+			am.updateAccountPeer(ctx, message.AccountID, peerID)
+		}
+	case cluster.PeersDisconnect:
+		am.peersUpdateManager.CloseChannels(ctx, message.PeerIDs)
+	default:
+		log.WithContext(ctx).Warnf("received unknown cluster message type %s from node %s", message.Type, message.Node)
+	}
+}
+
+// closePeerChannels closes the sync streams of the peers on all management servers, within a batch transaction once
+// the changes are committed
+func (am *DefaultAccountManager) closePeerChannels(ctx context.Context, accountID string, peerIDs []string) {
+	store.RunAfterCommit(ctx, func() {
+		am.peersUpdateManager.CloseChannels(ctx, peerIDs)
+		am.publishToCluster(ctx, &cluster.Message{Type: cluster.PeersDisconnect, AccountID: accountID, PeerIDs: peerIDs})
+	})
+}
+
+// publishToCluster sends the message to the other management servers, if the account manager is part of a cluster
+func (am *DefaultAccountManager) publishToCluster(ctx context.Context, message *cluster.Message) {
+	if am.cluster == nil {
+		return
+	}
+	am.cluster.Publish(ctx, message)
+}
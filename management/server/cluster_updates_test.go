@@ -0,0 +1,98 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/management/server/cluster"
+)
+
+type testCluster struct {
+	mu        sync.Mutex
+	published []*cluster.Message
+}
+
+func (c *testCluster) NodeID() string    { return "test" }
+func (c *testCluster) Distributed() bool { return true }
+func (c *testCluster) Close() error      { return nil }
+
+func (c *testCluster) Publish(_ context.Context, message *cluster.Message) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.published = append(c.published, message)
+}
+
+func (c *testCluster) Subscribe(_ context.Context, _ cluster.Handler) {}
+
+func (c *testCluster) Lock(_ context.Context, _ string) (func(), error) {
+	return func() {}, nil
+}
+
+func (c *testCluster) messages() []*cluster.Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.published
+}
+
+func TestDefaultAccountManager_ClusterUpdates(t *testing.T) {
+	manager, account, peer1, peer2, _ := setupNetworkMapTest(t)
+
+	testCluster := &testCluster{}
+	manager.SetCluster(context.Background(), testCluster)
+
+	t.Run("account peers update is published", func(t *testing.T) {
+		manager.UpdateAccountPeers(context.Background(), account.Id)
+
+		messages := testCluster.messages()
+		require.NotEmpty(t, messages)
+		assert.Equal(t, &cluster.Message{Type: cluster.AccountPeersUpdate, AccountID: account.Id}, messages[len(messages)-1])
+	})
+
+	t.Run("update of a peer connected to another server is published", func(t *testing.T) {
+		manager.UpdateAccountPeer(context.Background(), account.Id, peer1.ID)
+
+		messages := testCluster.messages()
+		require.NotEmpty(t, messages)
+		assert.Equal(t, &cluster.Message{Type: cluster.PeerUpdate, AccountID: account.Id, PeerIDs: []string{peer1.ID}}, messages[len(messages)-1])
+	})
+
+	t.Run("connected peer is reported connected", func(t *testing.T) {
+		assert.True(t, manager.HasConnectedChannel(peer1.ID))
+	})
+
+	t.Run("account peers update of another server is delivered", func(t *testing.T) {
+		updMsg := manager.peersUpdateManager.CreateChannel(context.Background(), peer1.ID)
+		t.Cleanup(func() {
+			manager.peersUpdateManager.CloseChannel(context.Background(), peer1.ID)
+		})
+
+		done := make(chan struct{})
+		go func() {
+			peerShouldReceiveUpdate(t, updMsg)
+			close(done)
+		}()
+
+		manager.handleClusterMessage(context.Background(), &cluster.Message{Node: "other", Type: cluster.AccountPeersUpdate, AccountID: account.Id})
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Error("timeout waiting for peerShouldReceiveUpdate")
+		}
+	})
+
+	t.Run("peers disconnect of another server closes the channel", func(t *testing.T) {
+		updMsg := manager.peersUpdateManager.CreateChannel(context.Background(), peer2.ID)
+
+		manager.handleClusterMessage(context.Background(), &cluster.Message{Node: "other", Type: cluster.PeersDisconnect, AccountID: account.Id, PeerIDs: []string{peer2.ID}})
+
+		_, open := <-updMsg
+		assert.False(t, open, "channel should be closed")
+		assert.False(t, manager.peersUpdateManager.HasChannel(peer2.ID))
+	})
+}
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/management/server/idp"
+	"github.com/netbirdio/netbird/management/server/notifications"
+	"github.com/netbirdio/netbird/management/server/store"
+	"github.com/netbirdio/netbird/management/server/telemetry"
+	"github.com/netbirdio/netbird/management/server/types"
+)
+
+// ConfigReloader applies a changed management config to the running server, so the connected peers keep their
+// sync streams. The IdP, notifications, TURN and relay credentials, the auth flows sent to the clients and the
+// store connection pool are reloaded, the other settings require a restart and keep their running values.
+type ConfigReloader struct {
+	mu     sync.Mutex
+	config *types.Config
+
+	accountManager *DefaultAccountManager
+	// idpManager is nil when the server was started without an IdP manager
+	idpManager     *idp.ReloadableManager
+	secretsManager *TimeBasedAuthSecretsManager
+	grpcServer     *GRPCServer
+	store          store.Store
+	appMetrics     telemetry.AppMetrics
+}
+
+// NewConfigReloader creates a ConfigReloader of the components started with the config
+func NewConfigReloader(config *types.Config, accountManager *DefaultAccountManager, idpManager *idp.ReloadableManager,
+	secretsManager *TimeBasedAuthSecretsManager, grpcServer *GRPCServer, store store.Store, appMetrics telemetry.AppMetrics) *ConfigReloader {
+	return &ConfigReloader{
+		config:         config,
+		accountManager: accountManager,
+		idpManager:     idpManager,
+		secretsManager: secretsManager,
+		grpcServer:     grpcServer,
+		store:          store,
+		appMetrics:     appMetrics,
+	}
+}
+
+// Reload validates the new config and applies it. All the components are created from the new config before any
+// of them is replaced, so an invalid config returns an error and the server keeps running with the current one.
+func (r *ConfigReloader) Reload(ctx context.Context, newConfig *types.Config) error {
+	if newConfig == nil {
+		return fmt.Errorf("config is empty")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	config := r.keepStaticSettings(ctx, newConfig)
+
+	idpManager, err := r.newIdpManager(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	notifier, err := notifications.NewNotifier(config.Notifications)
+	if err != nil {
+		return fmt.Errorf("invalid notifications config: %w", err)
+	}
+
+	if _, _, err = newTokenGenerators(config.TURNConfig, config.Relay); err != nil {
+		return fmt.Errorf("invalid relay config: %w", err)
+	}
+
+	if !reflect.DeepEqual(r.config.StoreConfig, config.StoreConfig) {
+		if err = r.store.SetConnectionPool(ctx, config.StoreConfig); err != nil {
+			return fmt.Errorf("apply store connection pool: %w", err)
+		}
+	}
+
+	if idpManager != nil {
+		r.idpManager.Swap(idpManager)
+	}
+	r.accountManager.SetNotifier(notifier)
+	if err = r.secretsManager.UpdateConfig(ctx, config.TURNConfig, config.Relay); err != nil {
+		// the generators were validated above
+		log.WithContext(ctx).Errorf("failed to update TURN and relay credentials: %v", err)
+	}
+	r.grpcServer.UpdateConfig(config)
+
+	r.config = config
+
+	log.WithContext(ctx).Infof("reloaded management config")
+
+	return nil
+}
+
+// newIdpManager creates the IdP manager of the config, nil is returned if the server runs without an IdP manager.
+// Enabling or disabling the IdP manager requires a restart, as the account manager behaves differently without one.
+func (r *ConfigReloader) newIdpManager(ctx context.Context, config *types.Config) (idp.Manager, error) {
+	var manager idp.Manager
+	if config.IdpManagerConfig != nil {
+		var err error
+		manager, err = idp.NewManager(ctx, *config.IdpManagerConfig, r.appMetrics)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IdP manager config: %w", err)
+		}
+	}
+
+	if (manager == nil) != (r.idpManager == nil) {
+		return nil, fmt.Errorf("enabling or disabling the IdP manager requires a restart")
+	}
+
+	return manager, nil
+}
+
+// keepStaticSettings returns a copy of the new config with the settings that can't be reloaded set to their running values
+func (r *ConfigReloader) keepStaticSettings(ctx context.Context, newConfig *types.Config) *types.Config {
+	config := *newConfig
+
+	keep := func(name string, current, changed any, restore func()) {
+		if !reflect.DeepEqual(current, changed) {
+			log.WithContext(ctx).Warnf("%s can't be reloaded, restart the management server to apply the change", name)
+		}
+		restore()
+	}
+
+	keep("Datadir", r.config.Datadir, config.Datadir, func() { config.Datadir = r.config.Datadir })
+	keep("DataStoreEncryptionKey", r.config.DataStoreEncryptionKey, config.DataStoreEncryptionKey, func() {
+		config.DataStoreEncryptionKey = r.config.DataStoreEncryptionKey
+	})
+	keep("HttpConfig", r.config.HttpConfig, config.HttpConfig, func() { config.HttpConfig = r.config.HttpConfig })
+	keep("StoreConfig.Engine", r.config.StoreConfig.Engine, config.StoreConfig.Engine, func() {
+		config.StoreConfig.Engine = r.config.StoreConfig.Engine
+	})
+	keep("StoreConfig.Encryption", r.config.StoreConfig.Encryption, config.StoreConfig.Encryption, func() {
+		config.StoreConfig.Encryption = r.config.StoreConfig.Encryption
+	})
+	keep("ReverseProxy", r.config.ReverseProxy, config.ReverseProxy, func() { config.ReverseProxy = r.config.ReverseProxy })
+	keep("EventExport", r.config.EventExport, config.EventExport, func() { config.EventExport = r.config.EventExport })
+	keep("EventBus", r.config.EventBus, config.EventBus, func() { config.EventBus = r.config.EventBus })
+	keep("Cluster", r.config.Cluster, config.Cluster, func() { config.Cluster = r.config.Cluster })
+	keep("ClientCertificates", r.config.ClientCertificates, config.ClientCertificates, func() {
+		config.ClientCertificates = r.config.ClientCertificates
+	})
+
+	return &config
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"crypto/sha1"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/management/server/idp"
+	"github.com/netbirdio/netbird/management/server/notifications"
+	"github.com/netbirdio/netbird/management/server/settings"
+	"github.com/netbirdio/netbird/management/server/types"
+	"github.com/netbirdio/netbird/util"
+)
+
+func newTestConfigReloader(t *testing.T, config *types.Config) (*ConfigReloader, *TimeBasedAuthSecretsManager, *GRPCServer) {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	secretsManager := NewTimeBasedAuthSecretsManager(NewPeersUpdateManager(nil), config.TURNConfig, config.Relay, settings.NewMockManager(ctrl))
+	grpcServer := &GRPCServer{config: config}
+
+	reloader := NewConfigReloader(config, &DefaultAccountManager{}, nil, secretsManager, grpcServer, nil, nil)
+
+	return reloader, secretsManager, grpcServer
+}
+
+func newTestReloadConfig(secret string) *types.Config {
+	return &types.Config{
+		Datadir: "/var/lib/netbird",
+		TURNConfig: &types.TURNConfig{
+			CredentialsTTL:       util.Duration{Duration: time.Hour},
+			Secret:               secret,
+			Turns:                []*types.Host{TurnTestHost},
+			TimeBasedCredentials: true,
+		},
+		HttpConfig: &types.HttpServerConfig{AuthIssuer: "https://issuer.example.com"},
+	}
+}
+
+func TestConfigReloader_Reload(t *testing.T) {
+	reloader, secretsManager, grpcServer := newTestConfigReloader(t, newTestReloadConfig("old-secret"))
+
+	newConfig := newTestReloadConfig("new-secret")
+	newConfig.Datadir = "/tmp/netbird"
+	newConfig.HttpConfig = &types.HttpServerConfig{AuthIssuer: "https://other.example.com"}
+
+	require.NoError(t, reloader.Reload(context.Background(), newConfig))
+
+	turnCredentials, err := secretsManager.GenerateTurnToken()
+	require.NoError(t, err)
+	validateMAC(t, sha1.New, turnCredentials.Payload, turnCredentials.Signature, []byte("new-secret"))
+
+	config := grpcServer.getConfig()
+	assert.Equal(t, "new-secret", config.TURNConfig.Secret)
+	assert.Equal(t, "/var/lib/netbird", config.Datadir, "datadir requires a restart")
+	assert.Equal(t, "https://issuer.example.com", config.HttpConfig.AuthIssuer, "http config requires a restart")
+}
+
+func TestConfigReloader_ReloadInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(config *types.Config)
+	}{
+		{
+			name: "invalid notifications",
+			change: func(config *types.Config) {
+				config.Notifications = &notifications.Config{Webhook: &notifications.WebhookConfig{URL: "ftp://example.com"}}
+			},
+		},
+		{
+			name: "invalid idp manager",
+			change: func(config *types.Config) {
+				config.IdpManagerConfig = &idp.Config{ManagerType: "unknown"}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			initialConfig := newTestReloadConfig("old-secret")
+			reloader, secretsManager, grpcServer := newTestConfigReloader(t, initialConfig)
+
+			newConfig := newTestReloadConfig("new-secret")
+			tt.change(newConfig)
+
+			require.Error(t, reloader.Reload(context.Background(), newConfig))
+
+			turnCredentials, err := secretsManager.GenerateTurnToken()
+			require.NoError(t, err)
+			validateMAC(t, sha1.New, turnCredentials.Payload, turnCredentials.Signature, []byte("old-secret"))
+			assert.Same(t, initialConfig, grpcServer.getConfig(), "running config should be kept")
+		})
+	}
+}
@@ -0,0 +1,445 @@
+package connectivity
+
+import (
+	"context"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-version"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/management/proto"
+	"github.com/netbirdio/netbird/management/server/connectivity/types"
+	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+	"github.com/netbirdio/netbird/management/server/permissions"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
+)
+
+const (
+	// SummaryPeriod is the age of the reports included in the summary, the reports of the peers offline for longer
+	// don't describe the current connectivity
+	SummaryPeriod = 24 * time.Hour
+
+	// relayRatioWeight is the weight of the latest report in the moving average of the relay ratio
+	relayRatioWeight = 0.25
+	// maxServers limits the servers stored per report
+	maxServers = 32
+	// maxRoutes limits the route health and the routed network entries stored per report
+	maxRoutes = 512
+	// maxErrorLength limits the errors stored with the routed networks
+	maxErrorLength = 256
+)
+
+var natTypes = []string{types.NATNone, types.NATEndpointIndependent, types.NATEndpointDependent, types.NATUDPBlocked, types.NATUnknown}
+
+// connectionSetupPhases are the phases of the peer connection setups in their order
+var connectionSetupPhases = []string{"signal", "gathering", "nomination", "handshake"}
+
+type Manager interface {
+	// ReportConnectivity stores the connectivity reported by the peer with the WireGuard public key
+	ReportConnectivity(ctx context.Context, peerKey string, report *proto.ConnectivityReport) error
+	// GetPeerReports returns the latest connectivity reports of the peers of the account
+	GetPeerReports(ctx context.Context, accountID, userID string) ([]*types.Report, error)
+	// GetSummary summarizes the connectivity of the peers that reported within the SummaryPeriod
+	GetSummary(ctx context.Context, accountID, userID string) (*types.Summary, error)
+}
+
+// TrafficRecorder records the traffic the routing peers forwarded for the other peers
+type TrafficRecorder interface {
+	// RecordForwardedTraffic records the traffic reported by the peer since its previous report
+	RecordForwardedTraffic(ctx context.Context, peer *nbpeer.Peer, traffic []*proto.ForwardedTraffic) error
+}
+
+type managerImpl struct {
+	store              store.Store
+	permissionsManager permissions.Manager
+	trafficRecorder    TrafficRecorder
+}
+
+type mockManager struct {
+}
+
+// NewManager creates a Manager, the forwarded traffic of the reports is dropped if the traffic recorder is nil
+func NewManager(store store.Store, permissionsManager permissions.Manager, trafficRecorder TrafficRecorder) Manager {
+	return &managerImpl{
+		store:              store,
+		permissionsManager: permissionsManager,
+		trafficRecorder:    trafficRecorder,
+	}
+}
+
+func (m *managerImpl) ReportConnectivity(ctx context.Context, peerKey string, report *proto.ConnectivityReport) error {
+	peer, err := m.store.GetPeerByPeerPubKey(ctx, store.LockingStrengthShare, peerKey)
+	if err != nil {
+		return err
+	}
+
+	natType := report.GetNatType()
+	if !slices.Contains(natTypes, natType) {
+		log.WithContext(ctx).Debugf("peer %s reported the unknown NAT type %q", peer.ID, natType)
+		natType = types.NATUnknown
+	}
+
+	var servers []types.Server
+	for _, server := range report.GetStuns() {
+		servers = append(servers, toServer(types.ServerSTUN, server))
+	}
+	for _, server := range report.GetTurns() {
+		servers = append(servers, toServer(types.ServerTURN, server))
+	}
+	if len(servers) > maxServers {
+		servers = servers[:maxServers]
+	}
+
+	routes := make([]types.RouteHealth, 0, len(report.GetRoutes()))
+	for _, r := range report.GetRoutes() {
+		routes = append(routes, types.RouteHealth{RouteID: r.GetId(), Healthy: r.GetHealthy()})
+	}
+	if len(routes) > maxRoutes {
+		routes = routes[:maxRoutes]
+	}
+
+	setups := toConnectionSetups(report.GetConnectionSetups())
+	routedNetworks := toRoutedNetworks(report.GetRoutedNetworks())
+
+	connected, relayed := int(report.GetConnectedPeers()), int(report.GetRelayedPeers())
+	if relayed > connected {
+		return status.Errorf(status.InvalidArgument, "relayed peers can't exceed the connected peers")
+	}
+
+	err = m.store.UpdateConnectivityReport(ctx, peer.AccountID, peer.ID, func(r *types.Report) {
+		r.NATType = natType
+		r.MappedAddress = report.GetMappedAddress()
+		r.Servers = servers
+		r.ConnectedPeers = connected
+		r.RelayedPeers = relayed
+		r.Routes = routes
+		r.ConnectionSetups = setups
+		r.RoutedNetworks = routedNetworks
+		if connected > 0 {
+			ratio := float64(relayed) / float64(connected)
+			if r.ReportedAt.IsZero() {
+				r.RelayRatio = ratio
+			} else {
+				r.RelayRatio += relayRatioWeight * (ratio - r.RelayRatio)
+			}
+		}
+		r.ReportedAt = time.Now().UTC()
+	})
+	if err != nil {
+		return err
+	}
+
+	if m.trafficRecorder != nil {
+		if err := m.trafficRecorder.RecordForwardedTraffic(ctx, peer, report.GetForwardedTraffic()); err != nil {
+			log.WithContext(ctx).Errorf("failed to record the forwarded traffic of peer %s: %v", peer.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (m *managerImpl) GetPeerReports(ctx context.Context, accountID, userID string) ([]*types.Report, error) {
+	if err := m.validatePermissions(ctx, accountID, userID); err != nil {
+		return nil, err
+	}
+
+	reports, _, err := m.peerReports(ctx, accountID)
+	return reports, err
+}
+
+func (m *managerImpl) GetSummary(ctx context.Context, accountID, userID string) (*types.Summary, error) {
+	if err := m.validatePermissions(ctx, accountID, userID); err != nil {
+		return nil, err
+	}
+
+	reports, peers, err := m.peerReports(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	since := time.Now().Add(-SummaryPeriod)
+	recent := make([]*types.Report, 0, len(reports))
+	for _, report := range reports {
+		if report.ReportedAt.After(since) {
+			recent = append(recent, report)
+		}
+	}
+
+	return summarize(recent, peers), nil
+}
+
+func (m *managerImpl) validatePermissions(ctx context.Context, accountID, userID string) error {
+	ok, err := m.permissionsManager.ValidateUserPermissions(ctx, accountID, userID, permissions.Peers, permissions.Read)
+	if err != nil {
+		return status.NewPermissionValidationError(err)
+	}
+	if !ok {
+		return status.NewPermissionDeniedError()
+	}
+	return nil
+}
+
+// peerReports returns the reports of the existing peers of the account with the peers by ID, the reports of the
+// deleted peers are left out
+func (m *managerImpl) peerReports(ctx context.Context, accountID string) ([]*types.Report, map[string]*nbpeer.Peer, error) {
+	reports, err := m.store.GetAccountConnectivityReports(ctx, store.LockingStrengthShare, accountID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	accountPeers, err := m.store.GetAccountPeers(ctx, store.LockingStrengthShare, accountID, "", "")
+	if err != nil {
+		return nil, nil, err
+	}
+	peers := make(map[string]*nbpeer.Peer, len(accountPeers))
+	for _, peer := range accountPeers {
+		peers[peer.ID] = peer
+	}
+
+	existing := make([]*types.Report, 0, len(reports))
+	for _, report := range reports {
+		peer, ok := peers[report.PeerID]
+		if !ok {
+			continue
+		}
+		report.PeerName = peer.Name
+		existing = append(existing, report)
+	}
+
+	return existing, peers, nil
+}
+
+// summarize aggregates the reports, the peers are grouped into sites by the public IP address seen by the STUN
+// servers, or by the management service if the peer didn't learn it
+func summarize(reports []*types.Report, peers map[string]*nbpeer.Peer) *types.Summary {
+	summary := &types.Summary{
+		ReportedPeers: len(reports),
+		NATTypes:      make(map[string]int),
+	}
+
+	sites := make(map[string]*types.Site)
+	siteRatios := make(map[string]relayRatios)
+	var ratios relayRatios
+
+	for _, report := range reports {
+		summary.NATTypes[report.NATType]++
+		if report.Unreachable(types.ServerSTUN) > 0 {
+			summary.STUNUnreachablePeers++
+		}
+		if report.Unreachable(types.ServerTURN) > 0 {
+			summary.TURNUnreachablePeers++
+		}
+		summary.UnreachableRoutedNetworks += report.UnreachableRoutedNetworks()
+		ratios.add(report)
+
+		peer := peers[report.PeerID]
+		publicIP := sitePublicIP(report, peer)
+		if publicIP == "" {
+			continue
+		}
+		site, ok := sites[publicIP]
+		if !ok {
+			site = &types.Site{
+				PublicIP:    publicIP,
+				CountryCode: peer.Location.CountryCode,
+				CityName:    peer.Location.CityName,
+			}
+			sites[publicIP] = site
+		}
+		site.Peers++
+		siteRatio := siteRatios[publicIP]
+		siteRatio.add(report)
+		siteRatios[publicIP] = siteRatio
+		if !slices.Contains(site.NATTypes, report.NATType) {
+			site.NATTypes = append(site.NATTypes, report.NATType)
+		}
+	}
+
+	summary.RelayRatio = ratios.average()
+	summary.ConnectionSetups = summarizeConnectionSetups(reports, peers)
+
+	for publicIP, site := range sites {
+		site.RelayRatio = siteRatios[publicIP].average()
+		slices.Sort(site.NATTypes)
+		summary.Sites = append(summary.Sites, site)
+	}
+	slices.SortFunc(summary.Sites, func(a, b *types.Site) int {
+		switch {
+		case a.RelayRatio > b.RelayRatio:
+			return -1
+		case a.RelayRatio < b.RelayRatio:
+			return 1
+		case a.Peers != b.Peers:
+			return b.Peers - a.Peers
+		default:
+			return strings.Compare(a.PublicIP, b.PublicIP)
+		}
+	})
+
+	return summary
+}
+
+// summarizeConnectionSetups sums the connection setup phases of the reports per client version, the durations are
+// averaged over the peers weighted by their successful phases. The oldest version comes first.
+func summarizeConnectionSetups(reports []*types.Report, peers map[string]*nbpeer.Peer) []types.ConnectionSetup {
+	type key struct {
+		version string
+		phase   string
+	}
+	sums := make(map[key]*types.ConnectionSetup)
+	for _, report := range reports {
+		var peerVersion string
+		if peer := peers[report.PeerID]; peer != nil {
+			peerVersion = peer.Meta.WtVersion
+		}
+		for _, setup := range report.ConnectionSetups {
+			k := key{version: peerVersion, phase: setup.Phase}
+			sum, ok := sums[k]
+			if !ok {
+				sum = &types.ConnectionSetup{Phase: setup.Phase, Version: peerVersion}
+				sums[k] = sum
+			}
+			sum.Successes += setup.Successes
+			sum.Failures += setup.Failures
+			sum.Median += setup.Median * time.Duration(setup.Successes)
+			sum.P95 += setup.P95 * time.Duration(setup.Successes)
+		}
+	}
+
+	setups := make([]types.ConnectionSetup, 0, len(sums))
+	for _, sum := range sums {
+		if sum.Successes > 0 {
+			sum.Median /= time.Duration(sum.Successes)
+			sum.P95 /= time.Duration(sum.Successes)
+		}
+		setups = append(setups, *sum)
+	}
+	slices.SortFunc(setups, func(a, b types.ConnectionSetup) int {
+		if c := compareVersions(a.Version, b.Version); c != 0 {
+			return c
+		}
+		return slices.Index(connectionSetupPhases, a.Phase) - slices.Index(connectionSetupPhases, b.Phase)
+	})
+	return setups
+}
+
+// compareVersions compares the client versions semantically, the unparsable versions like development builds sort
+// after the releases
+func compareVersions(a, b string) int {
+	va, errA := version.NewVersion(a)
+	vb, errB := version.NewVersion(b)
+	switch {
+	case errA == nil && errB == nil:
+		return va.Compare(vb)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+func toConnectionSetups(stats []*proto.ConnectionSetupStats) []types.ConnectionSetup {
+	setups := make([]types.ConnectionSetup, 0, len(stats))
+	for _, s := range stats {
+		if !slices.Contains(connectionSetupPhases, s.GetPhase()) {
+			log.Debugf("skipping the unknown connection setup phase %q", s.GetPhase())
+			continue
+		}
+		if slices.ContainsFunc(setups, func(setup types.ConnectionSetup) bool { return setup.Phase == s.GetPhase() }) {
+			continue
+		}
+		setups = append(setups, types.ConnectionSetup{
+			Phase:     s.GetPhase(),
+			Successes: int(s.GetSuccesses()),
+			Failures:  int(s.GetFailures()),
+			Median:    s.GetMedian().AsDuration(),
+			P95:       s.GetP95().AsDuration(),
+		})
+	}
+	return setups
+}
+
+func toRoutedNetworks(networks []*proto.NetworkReachability) []types.RoutedNetwork {
+	if len(networks) > maxRoutes {
+		networks = networks[:maxRoutes]
+	}
+
+	routed := make([]types.RoutedNetwork, 0, len(networks))
+	for _, n := range networks {
+		network := types.RoutedNetwork{
+			RouteID:   n.GetRouteId(),
+			Network:   n.GetNetwork(),
+			Reachable: n.GetReachable(),
+			Target:    n.GetTarget(),
+			Latency:   n.GetLatency().AsDuration(),
+			Error:     n.GetError(),
+		}
+		if len(network.Error) > maxErrorLength {
+			network.Error = network.Error[:maxErrorLength]
+		}
+		routed = append(routed, network)
+	}
+	return routed
+}
+
+func sitePublicIP(report *types.Report, peer *nbpeer.Peer) string {
+	if mapped, err := netip.ParseAddrPort(report.MappedAddress); err == nil {
+		return mapped.Addr().String()
+	}
+	if peer != nil && peer.Location.ConnectionIP != nil {
+		return peer.Location.ConnectionIP.String()
+	}
+	return ""
+}
+
+func toServer(serverType string, server *proto.ServerReachability) types.Server {
+	return types.Server{
+		URI:       server.GetUri(),
+		Type:      serverType,
+		Reachable: server.GetReachable(),
+		Error:     server.GetError(),
+	}
+}
+
+// relayRatios averages the relay ratios of the peers that reported connections
+type relayRatios struct {
+	sum   float64
+	peers int
+}
+
+func (r *relayRatios) add(report *types.Report) {
+	if report.ConnectedPeers == 0 && report.RelayRatio == 0 {
+		return
+	}
+	r.sum += report.RelayRatio
+	r.peers++
+}
+
+func (r relayRatios) average() float64 {
+	if r.peers == 0 {
+		return 0
+	}
+	return r.sum / float64(r.peers)
+}
+
+func NewManagerMock() Manager {
+	return &mockManager{}
+}
+
+func (m *mockManager) ReportConnectivity(ctx context.Context, peerKey string, report *proto.ConnectivityReport) error {
+	return nil
+}
+
+func (m *mockManager) GetPeerReports(ctx context.Context, accountID, userID string) ([]*types.Report, error) {
+	return []*types.Report{}, nil
+}
+
+func (m *mockManager) GetSummary(ctx context.Context, accountID, userID string) (*types.Summary, error) {
+	return &types.Summary{NATTypes: map[string]int{}}, nil
+}
@@ -0,0 +1,178 @@
+package connectivity
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/netbirdio/netbird/management/proto"
+	"github.com/netbirdio/netbird/management/server/connectivity/types"
+	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+	"github.com/netbirdio/netbird/management/server/permissions"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
+)
+
+const (
+	testAccountID = "bf1c8084-ba50-4ce7-9439-34653001fc3b"
+	testAdminID   = "edafee4e-63fb-11ec-90d6-0242ac120003"
+	testPeerID    = "ct286bi7qv930dsrrug0"
+	testPeerKey   = "RlSy2vzoG2HyMBTUImXOiVhCBiiBa5qD5xzMxkiFDW4="
+)
+
+func TestManager_ReportConnectivity(t *testing.T) {
+	ctx := context.Background()
+
+	s, cleanUp, err := store.NewTestStoreFromSQL(ctx, "../testdata/store.sql", t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(cleanUp)
+
+	peer, err := s.GetPeerByID(ctx, store.LockingStrengthShare, testAccountID, testPeerID)
+	require.NoError(t, err)
+	peer.Key = testPeerKey
+	require.NoError(t, s.SavePeer(ctx, store.LockingStrengthUpdate, testAccountID, peer))
+
+	manager := NewManager(s, permissions.NewManager(s), nil)
+
+	err = manager.ReportConnectivity(ctx, testPeerKey, &proto.ConnectivityReport{ConnectedPeers: 1, RelayedPeers: 2})
+	sErr, ok := status.FromError(err)
+	require.True(t, ok, "unexpected error %v", err)
+	assert.Equal(t, status.InvalidArgument, sErr.Type())
+
+	require.NoError(t, manager.ReportConnectivity(ctx, testPeerKey, &proto.ConnectivityReport{
+		NatType:        "symmetric",
+		MappedAddress:  "203.0.113.10:51820",
+		Stuns:          []*proto.ServerReachability{{Uri: "stun:stun.example.com:3478", Reachable: true}},
+		Turns:          []*proto.ServerReachability{{Uri: "turn:turn.example.com:3478", Error: "i/o timeout"}},
+		ConnectedPeers: 4,
+		RelayedPeers:   4,
+	}))
+	require.NoError(t, manager.ReportConnectivity(ctx, testPeerKey, &proto.ConnectivityReport{
+		NatType:        types.NATEndpointIndependent,
+		MappedAddress:  "203.0.113.10:51820",
+		ConnectedPeers: 4,
+		Routes:         []*proto.RouteHealth{{Id: "route-1", Healthy: true}, {Id: "route-2"}},
+		ConnectionSetups: []*proto.ConnectionSetupStats{
+			{Phase: "nomination", Successes: 3, Failures: 1, Median: durationpb.New(200 * time.Millisecond), P95: durationpb.New(time.Second)},
+			{Phase: "unknown", Successes: 1},
+		},
+		RoutedNetworks: []*proto.NetworkReachability{
+			{RouteId: "route-3", Network: "10.0.0.0/24", Reachable: true, Target: "10.0.0.1", Latency: durationpb.New(3 * time.Millisecond)},
+			{RouteId: "route-4", Network: "192.168.0.0/24", Target: "192.168.0.1", Error: "no route to 192.168.0.1: route not found"},
+		},
+	}))
+
+	reports, err := manager.GetPeerReports(ctx, testAccountID, testAdminID)
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	assert.Equal(t, testPeerID, reports[0].PeerID)
+	assert.NotEmpty(t, reports[0].PeerName)
+	assert.Equal(t, types.NATEndpointIndependent, reports[0].NATType)
+	assert.Equal(t, 0, reports[0].RelayedPeers)
+	assert.InDelta(t, 0.75, reports[0].RelayRatio, 0.001, "the relay ratio should be averaged over the reports")
+	assert.Equal(t, []types.RouteHealth{{RouteID: "route-1", Healthy: true}, {RouteID: "route-2"}}, reports[0].Routes)
+	assert.Equal(t, []types.ConnectionSetup{{Phase: "nomination", Successes: 3, Failures: 1, Median: 200 * time.Millisecond, P95: time.Second}},
+		reports[0].ConnectionSetups, "the unknown phases should be dropped")
+	assert.Equal(t, []types.RoutedNetwork{
+		{RouteID: "route-3", Network: "10.0.0.0/24", Reachable: true, Target: "10.0.0.1", Latency: 3 * time.Millisecond},
+		{RouteID: "route-4", Network: "192.168.0.0/24", Target: "192.168.0.1", Error: "no route to 192.168.0.1: route not found"},
+	}, reports[0].RoutedNetworks)
+
+	summary, err := manager.GetSummary(ctx, testAccountID, testAdminID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.ReportedPeers)
+	assert.Equal(t, map[string]int{types.NATEndpointIndependent: 1}, summary.NATTypes)
+	assert.Equal(t, 1, summary.UnreachableRoutedNetworks)
+	require.Len(t, summary.Sites, 1)
+	assert.Equal(t, "203.0.113.10", summary.Sites[0].PublicIP)
+}
+
+func TestSummarize(t *testing.T) {
+	peers := map[string]*nbpeer.Peer{
+		"office-1": {ID: "office-1", Location: nbpeer.Location{CountryCode: "DE", CityName: "Berlin"}},
+		"office-2": {ID: "office-2"},
+		"home":     {ID: "home", Location: nbpeer.Location{ConnectionIP: net.ParseIP("198.51.100.7")}},
+		"new":      {ID: "new"},
+	}
+	reports := []*types.Report{
+		{
+			PeerID:         "office-1",
+			NATType:        types.NATEndpointDependent,
+			MappedAddress:  "203.0.113.10:40000",
+			Servers:        []types.Server{{Type: types.ServerSTUN, Reachable: true}, {Type: types.ServerTURN}},
+			ConnectedPeers: 2,
+			RelayRatio:     1,
+		},
+		{
+			PeerID:         "office-2",
+			NATType:        types.NATEndpointIndependent,
+			MappedAddress:  "203.0.113.10:40001",
+			Servers:        []types.Server{{Type: types.ServerSTUN}},
+			ConnectedPeers: 2,
+			RelayRatio:     0.5,
+		},
+		{
+			PeerID:         "home",
+			NATType:        types.NATEndpointIndependent,
+			ConnectedPeers: 1,
+		},
+		{
+			PeerID:  "new",
+			NATType: types.NATUnknown,
+		},
+	}
+
+	summary := summarize(reports, peers)
+
+	assert.Equal(t, 4, summary.ReportedPeers)
+	assert.Equal(t, map[string]int{
+		types.NATEndpointDependent:   1,
+		types.NATEndpointIndependent: 2,
+		types.NATUnknown:             1,
+	}, summary.NATTypes)
+	assert.Equal(t, 1, summary.STUNUnreachablePeers)
+	assert.Equal(t, 1, summary.TURNUnreachablePeers)
+	assert.InDelta(t, 0.5, summary.RelayRatio, 0.001, "the peers without connections should be left out of the relay ratio")
+
+	require.Len(t, summary.Sites, 2)
+	assert.Equal(t, &types.Site{
+		PublicIP:    "203.0.113.10",
+		CountryCode: "DE",
+		CityName:    "Berlin",
+		Peers:       2,
+		RelayRatio:  0.75,
+		NATTypes:    []string{types.NATEndpointDependent, types.NATEndpointIndependent},
+	}, summary.Sites[0])
+	assert.Equal(t, "198.51.100.7", summary.Sites[1].PublicIP, "the connection IP should be used without a mapped address")
+}
+
+func TestSummarizeConnectionSetups(t *testing.T) {
+	peers := map[string]*nbpeer.Peer{
+		"peer-1": {ID: "peer-1", Meta: nbpeer.PeerSystemMeta{WtVersion: "0.36.0"}},
+		"peer-2": {ID: "peer-2", Meta: nbpeer.PeerSystemMeta{WtVersion: "0.36.0"}},
+		"peer-3": {ID: "peer-3", Meta: nbpeer.PeerSystemMeta{WtVersion: "0.9.0"}},
+	}
+	reports := []*types.Report{
+		{PeerID: "peer-1", ConnectionSetups: []types.ConnectionSetup{
+			{Phase: "handshake", Successes: 1, Median: 100 * time.Millisecond, P95: 100 * time.Millisecond},
+			{Phase: "signal", Successes: 1, Failures: 1, Median: 50 * time.Millisecond, P95: 50 * time.Millisecond},
+		}},
+		{PeerID: "peer-2", ConnectionSetups: []types.ConnectionSetup{
+			{Phase: "signal", Successes: 3, Median: 150 * time.Millisecond, P95: 250 * time.Millisecond},
+		}},
+		{PeerID: "peer-3", ConnectionSetups: []types.ConnectionSetup{
+			{Phase: "nomination", Failures: 2},
+		}},
+	}
+
+	assert.Equal(t, []types.ConnectionSetup{
+		{Phase: "nomination", Failures: 2, Version: "0.9.0"},
+		{Phase: "signal", Successes: 4, Failures: 1, Median: 125 * time.Millisecond, P95: 200 * time.Millisecond, Version: "0.36.0"},
+		{Phase: "handshake", Successes: 1, Median: 100 * time.Millisecond, P95: 100 * time.Millisecond, Version: "0.36.0"},
+	}, summarizeConnectionSetups(reports, peers), "the phases should be weighted by their successes and sorted by the version")
+}
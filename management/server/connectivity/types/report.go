@@ -0,0 +1,248 @@
+package types
+
+import (
+	"time"
+
+	"github.com/netbirdio/netbird/management/server/http/api"
+)
+
+// NAT types reported by the peers
+const (
+	NATNone                = "none"
+	NATEndpointIndependent = "endpoint-independent"
+	NATEndpointDependent   = "endpoint-dependent"
+	NATUDPBlocked          = "udp-blocked"
+	NATUnknown             = "unknown"
+)
+
+// Server types of the reachability reports
+const (
+	ServerSTUN = "stun"
+	ServerTURN = "turn"
+)
+
+// Server is the reachability of a STUN or TURN server from the peer
+type Server struct {
+	URI       string
+	Type      string
+	Reachable bool
+	Error     string
+}
+
+// RouteHealth is the health of a client route of the peer, a route is healthy when a routing peer of its high
+// availability group is connected
+type RouteHealth struct {
+	RouteID string
+	Healthy bool
+}
+
+// RoutedNetwork is the reachability of a network the peer routes for the other peers
+type RoutedNetwork struct {
+	RouteID   string
+	Network   string
+	Reachable bool
+	// Target is the address the peer probed in the network
+	Target  string `json:",omitempty"`
+	Latency time.Duration
+	// Error describes why the network isn't reachable
+	Error string `json:",omitempty"`
+}
+
+// ConnectionSetup aggregates a phase of the peer connection setups: signal, gathering, nomination or handshake
+type ConnectionSetup struct {
+	Phase     string
+	Successes int
+	Failures  int
+	// Median and P95 are the percentiles of the durations of the successful phases
+	Median time.Duration
+	P95    time.Duration
+	// Version is the client version of the peers, it's set in the summary only
+	Version string `json:",omitempty"`
+}
+
+// Report is the latest connectivity report of a peer
+type Report struct {
+	PeerID    string `gorm:"primaryKey"`
+	AccountID string `gorm:"index"`
+	// PeerName is set from the peer when the reports are returned, it isn't stored
+	PeerName string `gorm:"-"`
+	NATType  string
+	// MappedAddress is the public address of the peer seen by the STUN servers
+	MappedAddress string
+	Servers       []Server `gorm:"serializer:json"`
+	// ConnectedPeers is the number of the peers connected to the peer
+	ConnectedPeers int
+	// RelayedPeers is the number of the connected peers relayed by a TURN or a relay server
+	RelayedPeers int
+	// RelayRatio is the moving average of the share of the relayed connections over the reports, it tells the peers
+	// always falling back to relay from the peers relayed at times
+	RelayRatio float64
+	// Routes is the health of the client routes of the peer
+	Routes []RouteHealth `gorm:"serializer:json"`
+	// ConnectionSetups are the phases of the peer connection setups since the previous report
+	ConnectionSetups []ConnectionSetup `gorm:"serializer:json"`
+	// RoutedNetworks is the reachability of the networks the peer routes for the other peers
+	RoutedNetworks []RoutedNetwork `gorm:"serializer:json"`
+	ReportedAt     time.Time
+}
+
+// TableName returns the table of the connectivity reports
+func (Report) TableName() string {
+	return "connectivity_reports"
+}
+
+// Unreachable returns the number of the unreachable servers of the type
+func (r *Report) Unreachable(serverType string) int {
+	var unreachable int
+	for _, server := range r.Servers {
+		if server.Type == serverType && !server.Reachable {
+			unreachable++
+		}
+	}
+	return unreachable
+}
+
+// UnreachableRoutedNetworks returns the number of the routed networks the peer can't reach
+func (r *Report) UnreachableRoutedNetworks() int {
+	var unreachable int
+	for _, network := range r.RoutedNetworks {
+		if !network.Reachable {
+			unreachable++
+		}
+	}
+	return unreachable
+}
+
+// Site is a public IP address peers connect from, e.g. an office network
+type Site struct {
+	PublicIP    string
+	CountryCode string
+	CityName    string
+	Peers       int
+	// RelayRatio is the average relay ratio of the peers of the site
+	RelayRatio float64
+	NATTypes   []string
+}
+
+// Summary is the connectivity quality across the peers of an account
+type Summary struct {
+	ReportedPeers int
+	// NATTypes is the number of peers per NAT type
+	NATTypes map[string]int
+	// RelayRatio is the average relay ratio of the peers with connections
+	RelayRatio           float64
+	STUNUnreachablePeers int
+	TURNUnreachablePeers int
+	// UnreachableRoutedNetworks is the number of the networks the routing peers advertise but can't reach
+	UnreachableRoutedNetworks int
+	// Sites are the public IP addresses of the peers, the most relayed first
+	Sites []*Site
+	// ConnectionSetups are the phases of the connection setups of the latest reports per client version
+	ConnectionSetups []ConnectionSetup
+}
+
+func (r *Report) ToAPIResponse() *api.PeerConnectivity {
+	servers := make([]api.ConnectivityServer, 0, len(r.Servers))
+	for _, server := range r.Servers {
+		s := api.ConnectivityServer{
+			Uri:       server.URI,
+			Type:      api.ConnectivityServerType(server.Type),
+			Reachable: server.Reachable,
+		}
+		if server.Error != "" {
+			s.Error = &server.Error
+		}
+		servers = append(servers, s)
+	}
+
+	return &api.PeerConnectivity{
+		PeerId:           r.PeerID,
+		PeerName:         r.PeerName,
+		NatType:          api.NATType(r.NATType),
+		MappedAddress:    r.MappedAddress,
+		Servers:          servers,
+		ConnectedPeers:   r.ConnectedPeers,
+		RelayedPeers:     r.RelayedPeers,
+		RelayRatio:       r.RelayRatio,
+		ConnectionSetups: toAPIConnectionSetups(r.ConnectionSetups),
+		RoutedNetworks:   toAPIRoutedNetworks(r.RoutedNetworks),
+		ReportedAt:       r.ReportedAt,
+	}
+}
+
+func (s *Summary) ToAPIResponse() *api.ConnectivitySummary {
+	natTypes := make([]api.NATTypeCount, 0, len(s.NATTypes))
+	for _, natType := range []string{NATNone, NATEndpointIndependent, NATEndpointDependent, NATUDPBlocked, NATUnknown} {
+		if peers := s.NATTypes[natType]; peers > 0 {
+			natTypes = append(natTypes, api.NATTypeCount{NatType: api.NATType(natType), Peers: peers})
+		}
+	}
+
+	sites := make([]api.ConnectivitySite, 0, len(s.Sites))
+	for _, site := range s.Sites {
+		natTypes := make([]api.NATType, 0, len(site.NATTypes))
+		for _, natType := range site.NATTypes {
+			natTypes = append(natTypes, api.NATType(natType))
+		}
+		sites = append(sites, api.ConnectivitySite{
+			PublicIp:    site.PublicIP,
+			CountryCode: site.CountryCode,
+			CityName:    site.CityName,
+			Peers:       site.Peers,
+			RelayRatio:  site.RelayRatio,
+			NatTypes:    natTypes,
+		})
+	}
+
+	return &api.ConnectivitySummary{
+		ReportedPeers:             s.ReportedPeers,
+		NatTypes:                  natTypes,
+		RelayRatio:                s.RelayRatio,
+		StunUnreachablePeers:      s.STUNUnreachablePeers,
+		TurnUnreachablePeers:      s.TURNUnreachablePeers,
+		UnreachableRoutedNetworks: s.UnreachableRoutedNetworks,
+		Sites:                     sites,
+		ConnectionSetups:          toAPIConnectionSetups(s.ConnectionSetups),
+	}
+}
+
+func toAPIConnectionSetups(setups []ConnectionSetup) []api.ConnectionSetupPhase {
+	phases := make([]api.ConnectionSetupPhase, 0, len(setups))
+	for _, setup := range setups {
+		phase := api.ConnectionSetupPhase{
+			Phase:     setup.Phase,
+			Successes: setup.Successes,
+			Failures:  setup.Failures,
+			MedianMs:  int(setup.Median.Milliseconds()),
+			P95Ms:     int(setup.P95.Milliseconds()),
+		}
+		if setup.Version != "" {
+			phase.Version = &setup.Version
+		}
+		phases = append(phases, phase)
+	}
+	return phases
+}
+
+func toAPIRoutedNetworks(networks []RoutedNetwork) []api.RoutedNetwork {
+	routed := make([]api.RoutedNetwork, 0, len(networks))
+	for _, network := range networks {
+		n := api.RoutedNetwork{
+			RouteId:   network.RouteID,
+			Network:   network.Network,
+			Reachable: network.Reachable,
+		}
+		if network.Target != "" {
+			n.Target = &network.Target
+		}
+		if network.Latency > 0 {
+			latency := int(network.Latency.Milliseconds())
+			n.LatencyMs = &latency
+		}
+		if network.Error != "" {
+			n.Error = &network.Error
+		}
+		routed = append(routed, n)
+	}
+	return routed
+}
@@ -34,6 +34,12 @@ type UserAuth struct {
 
 	// Indicates whether this user has authenticated with a Personal Access Token
 	IsPAT bool
+	// The ID of the Personal Access Token the user has authenticated with
+	PATID string
+	// The module operations the Personal Access Token is restricted to, empty if not restricted
+	PATScopes []string
+	// The groups restricting the peers and groups the Personal Access Token can modify, empty if not restricted
+	PATGroups []string
 }
 
 func GetUserAuthFromRequest(r *http.Request) (UserAuth, error) {
@@ -0,0 +1,120 @@
+package controlplane
+
+import (
+	"context"
+	"time"
+
+	"github.com/netbirdio/netbird/management/server/controlplane/types"
+	"github.com/netbirdio/netbird/management/server/permissions"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
+)
+
+// ExpirationWarningPeriod is the period before their expiration the peer logins and the setup keys are counted as
+// expiring
+const ExpirationWarningPeriod = 7 * 24 * time.Hour
+
+type Manager interface {
+	// GetHealth returns the operational state of the control plane of the account
+	GetHealth(ctx context.Context, accountID, userID string) (*types.Health, error)
+}
+
+type managerImpl struct {
+	store              store.Store
+	permissionsManager permissions.Manager
+	syncRecorder       *SyncRecorder
+}
+
+type mockManager struct {
+}
+
+// NewManager creates a Manager, the sync streams are counted by the recorder
+func NewManager(store store.Store, permissionsManager permissions.Manager, syncRecorder *SyncRecorder) Manager {
+	return &managerImpl{
+		store:              store,
+		permissionsManager: permissionsManager,
+		syncRecorder:       syncRecorder,
+	}
+}
+
+func (m *managerImpl) GetHealth(ctx context.Context, accountID, userID string) (*types.Health, error) {
+	if err := m.validatePermissions(ctx, accountID, userID); err != nil {
+		return nil, err
+	}
+
+	settings, err := m.store.GetAccountSettings(ctx, store.LockingStrengthShare, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	peers, err := m.store.GetAccountPeers(ctx, store.LockingStrengthShare, accountID, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	setupKeys, err := m.store.GetAccountSetupKeys(ctx, store.LockingStrengthShare, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	health := &types.Health{
+		TotalPeers: len(peers),
+	}
+
+	for _, peer := range peers {
+		if peer.Status.Connected {
+			health.ConnectedPeers++
+		}
+		if peer.Status.RequiresApproval {
+			health.PendingApprovalPeers++
+		}
+		if peer.Status.LoginExpired {
+			health.LoginExpiredPeers++
+			continue
+		}
+		if !settings.PeerLoginExpirationEnabled {
+			continue
+		}
+		if expired, timeLeft := peer.LoginExpired(settings.PeerLoginExpiration); !expired && timeLeft > 0 && timeLeft <= ExpirationWarningPeriod {
+			health.LoginExpiringPeers++
+		}
+	}
+
+	warnUntil := time.Now().Add(ExpirationWarningPeriod)
+	for _, key := range setupKeys {
+		if key.IsRevoked() || key.IsOverUsed() {
+			continue
+		}
+		switch {
+		case key.IsExpired():
+			health.ExpiredSetupKeys++
+		case !key.GetExpiresAt().IsZero() && key.GetExpiresAt().Before(warnUntil):
+			health.ExpiringSetupKeys++
+		}
+	}
+
+	if m.syncRecorder != nil {
+		health.Syncs, health.SyncErrors, health.LastSync, health.LastSyncError = m.syncRecorder.Stats(accountID)
+	}
+
+	return health, nil
+}
+
+func (m *managerImpl) validatePermissions(ctx context.Context, accountID, userID string) error {
+	ok, err := m.permissionsManager.ValidateUserPermissions(ctx, accountID, userID, permissions.Settings, permissions.Read)
+	if err != nil {
+		return status.NewPermissionValidationError(err)
+	}
+	if !ok {
+		return status.NewPermissionDeniedError()
+	}
+	return nil
+}
+
+func NewManagerMock() Manager {
+	return &mockManager{}
+}
+
+func (m *mockManager) GetHealth(ctx context.Context, accountID, userID string) (*types.Health, error) {
+	return &types.Health{}, nil
+}
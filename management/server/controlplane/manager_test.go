@@ -0,0 +1,71 @@
+package controlplane
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+	"github.com/netbirdio/netbird/management/server/permissions"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
+	"github.com/netbirdio/netbird/management/server/types"
+)
+
+const (
+	testAccountID = "bf1c8084-ba50-4ce7-9439-34653001fc3b"
+	testAdminID   = "edafee4e-63fb-11ec-90d6-0242ac120003"
+	testRegularID = "f4f6d672-63fb-11ec-90d6-0242ac120003"
+	testPeerID    = "ct286bi7qv930dsrrug0"
+)
+
+func TestManager_GetHealth(t *testing.T) {
+	ctx := context.Background()
+
+	s, cleanUp, err := store.NewTestStoreFromSQL(ctx, "../testdata/store.sql", t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(cleanUp)
+
+	peer, err := s.GetPeerByID(ctx, store.LockingStrengthShare, testAccountID, testPeerID)
+	require.NoError(t, err)
+	peer.Status = &nbpeer.PeerStatus{Connected: true, RequiresApproval: true, LastSeen: time.Now().UTC()}
+	require.NoError(t, s.SavePeer(ctx, store.LockingStrengthUpdate, testAccountID, peer))
+
+	soon := time.Now().Add(24 * time.Hour)
+	past := time.Now().Add(-time.Hour)
+	for _, key := range []*types.SetupKey{
+		{Id: "expiring", AccountID: testAccountID, Key: "expiring", Type: types.SetupKeyReusable, ExpiresAt: &soon},
+		{Id: "expired", AccountID: testAccountID, Key: "expired", Type: types.SetupKeyReusable, ExpiresAt: &past},
+		{Id: "revoked", AccountID: testAccountID, Key: "revoked", Type: types.SetupKeyReusable, ExpiresAt: &soon, Revoked: true},
+		{Id: "used", AccountID: testAccountID, Key: "used", Type: types.SetupKeyOneOff, ExpiresAt: &soon, UsedTimes: 1},
+	} {
+		require.NoError(t, s.SaveSetupKey(ctx, store.LockingStrengthUpdate, key))
+	}
+
+	recorder := NewSyncRecorder()
+	recorder.RecordSync(testAccountID)
+	recorder.RecordSyncError(testAccountID)
+
+	manager := NewManager(s, permissions.NewManager(s), recorder)
+
+	_, err = manager.GetHealth(ctx, testAccountID, testRegularID)
+	sErr, ok := status.FromError(err)
+	require.True(t, ok, "unexpected error %v", err)
+	assert.Equal(t, status.PermissionDenied, sErr.Type())
+
+	health, err := manager.GetHealth(ctx, testAccountID, testAdminID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, health.TotalPeers)
+	assert.Equal(t, 1, health.ConnectedPeers)
+	assert.Equal(t, 1, health.PendingApprovalPeers)
+	assert.Equal(t, 0, health.LoginExpiredPeers)
+	assert.Equal(t, 1, health.ExpiringSetupKeys, "the revoked and the used keys should be left out")
+	assert.Equal(t, 1, health.ExpiredSetupKeys)
+	assert.Equal(t, 1, health.Syncs)
+	assert.Equal(t, 1, health.SyncErrors)
+	assert.False(t, health.LastSync.IsZero())
+	assert.False(t, health.LastSyncError.IsZero())
+}
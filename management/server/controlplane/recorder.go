@@ -0,0 +1,117 @@
+package controlplane
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// SyncStatsPeriod is the period the sync streams are counted over
+	SyncStatsPeriod = time.Hour
+
+	syncStatsBuckets = int(SyncStatsPeriod / time.Minute)
+)
+
+// syncStats counts the sync streams of an account per minute in a ring of buckets
+type syncStats struct {
+	minutes       [syncStatsBuckets]int64
+	syncs         [syncStatsBuckets]int
+	errors        [syncStatsBuckets]int
+	lastSync      time.Time
+	lastError     time.Time
+	lastRecording time.Time
+}
+
+// SyncRecorder counts the sync streams the management service opened and the sync streams that failed per account.
+// The counts are kept in memory, with several management nodes every node counts the streams of its peers only.
+type SyncRecorder struct {
+	mu        sync.Mutex
+	accounts  map[string]*syncStats
+	lastPrune time.Time
+	now       func() time.Time
+}
+
+// NewSyncRecorder creates a SyncRecorder
+func NewSyncRecorder() *SyncRecorder {
+	return &SyncRecorder{
+		accounts: make(map[string]*syncStats),
+		now:      time.Now,
+	}
+}
+
+// RecordSync counts a sync stream of a peer of the account that received its initial sync
+func (r *SyncRecorder) RecordSync(accountID string) {
+	r.record(accountID, false)
+}
+
+// RecordSyncError counts a sync stream of a peer of the account that failed
+func (r *SyncRecorder) RecordSyncError(accountID string) {
+	r.record(accountID, true)
+}
+
+// Stats returns the sync streams and the failed sync streams of the account within the SyncStatsPeriod with the
+// time of the latest ones
+func (r *SyncRecorder) Stats(accountID string) (syncs, errors int, lastSync, lastError time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats, ok := r.accounts[accountID]
+	if !ok {
+		return 0, 0, time.Time{}, time.Time{}
+	}
+
+	since := r.now().Unix()/60 - int64(syncStatsBuckets)
+	for i, minute := range stats.minutes {
+		if minute > since {
+			syncs += stats.syncs[i]
+			errors += stats.errors[i]
+		}
+	}
+
+	return syncs, errors, stats.lastSync, stats.lastError
+}
+
+func (r *SyncRecorder) record(accountID string, failed bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	r.prune(now)
+
+	stats, ok := r.accounts[accountID]
+	if !ok {
+		stats = &syncStats{}
+		r.accounts[accountID] = stats
+	}
+
+	minute := now.Unix() / 60
+	i := int(minute % int64(syncStatsBuckets))
+	if stats.minutes[i] != minute {
+		stats.minutes[i] = minute
+		stats.syncs[i] = 0
+		stats.errors[i] = 0
+	}
+
+	if failed {
+		stats.errors[i]++
+		stats.lastError = now
+	} else {
+		stats.syncs[i]++
+		stats.lastSync = now
+	}
+	stats.lastRecording = now
+}
+
+// prune drops the accounts without sync streams within the SyncStatsPeriod, at most once a minute
+func (r *SyncRecorder) prune(now time.Time) {
+	if now.Sub(r.lastPrune) < time.Minute {
+		return
+	}
+	r.lastPrune = now
+
+	for accountID, stats := range r.accounts {
+		if now.Sub(stats.lastRecording) > SyncStatsPeriod {
+			delete(r.accounts, accountID)
+		}
+	}
+}
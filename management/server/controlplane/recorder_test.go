@@ -0,0 +1,42 @@
+package controlplane
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncRecorder(t *testing.T) {
+	now := time.Date(2024, 10, 2, 12, 0, 0, 0, time.UTC)
+	recorder := NewSyncRecorder()
+	recorder.now = func() time.Time { return now }
+
+	recorder.RecordSync("account-1")
+	recorder.RecordSyncError("account-1")
+	recorder.RecordSync("account-2")
+
+	now = now.Add(30 * time.Minute)
+	recorder.RecordSync("account-1")
+
+	syncs, errors, lastSync, lastError := recorder.Stats("account-1")
+	assert.Equal(t, 2, syncs)
+	assert.Equal(t, 1, errors)
+	assert.Equal(t, now, lastSync)
+	assert.Equal(t, now.Add(-30*time.Minute), lastError)
+
+	now = now.Add(40 * time.Minute)
+	syncs, errors, _, lastError = recorder.Stats("account-1")
+	assert.Equal(t, 1, syncs, "the syncs older than the period should be left out")
+	assert.Equal(t, 0, errors)
+	assert.False(t, lastError.IsZero(), "the latest error should be kept")
+
+	now = now.Add(30 * time.Minute)
+	recorder.RecordSync("account-1")
+	syncs, _, _, _ = recorder.Stats("account-1")
+	assert.Equal(t, 1, syncs, "the bucket of the same minute an hour earlier should be reset")
+
+	syncs, _, lastSync, _ = recorder.Stats("account-2")
+	assert.Equal(t, 0, syncs)
+	assert.True(t, lastSync.IsZero(), "the accounts without syncs within the period should be pruned")
+}
@@ -0,0 +1,49 @@
+package types
+
+import (
+	"time"
+
+	"github.com/netbirdio/netbird/management/server/http/api"
+)
+
+// Health is the operational state of the control plane of an account
+type Health struct {
+	TotalPeers     int
+	ConnectedPeers int
+	// PendingApprovalPeers is the number of the peers waiting for an approval to join the network
+	PendingApprovalPeers int
+	LoginExpiredPeers    int
+	// LoginExpiringPeers is the number of the peers whose login expires within the expiration warning period
+	LoginExpiringPeers int
+	// Syncs and SyncErrors are the sync streams opened and failed within the sync stats period
+	Syncs      int
+	SyncErrors int
+	// LastSync and LastSyncError are zero without sync streams since the management service started
+	LastSync      time.Time
+	LastSyncError time.Time
+	// ExpiringSetupKeys is the number of the valid setup keys that expire within the expiration warning period
+	ExpiringSetupKeys int
+	// ExpiredSetupKeys is the number of the setup keys that expired without being revoked
+	ExpiredSetupKeys int
+}
+
+func (h *Health) ToAPIResponse() *api.ControlPlaneHealth {
+	resp := &api.ControlPlaneHealth{
+		TotalPeers:           h.TotalPeers,
+		ConnectedPeers:       h.ConnectedPeers,
+		PendingApprovalPeers: h.PendingApprovalPeers,
+		LoginExpiredPeers:    h.LoginExpiredPeers,
+		LoginExpiringPeers:   h.LoginExpiringPeers,
+		Syncs:                h.Syncs,
+		SyncErrors:           h.SyncErrors,
+		ExpiringSetupKeys:    h.ExpiringSetupKeys,
+		ExpiredSetupKeys:     h.ExpiredSetupKeys,
+	}
+	if !h.LastSync.IsZero() {
+		resp.LastSyncAt = &h.LastSync
+	}
+	if !h.LastSyncError.IsZero() {
+		resp.LastSyncErrorAt = &h.LastSyncError
+	}
+	return resp
+}
@@ -0,0 +1,398 @@
+package debugbundles
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/netbirdio/netbird/management/proto"
+	"github.com/netbirdio/netbird/management/server/account"
+	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/debugbundles/types"
+	"github.com/netbirdio/netbird/management/server/permissions"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
+)
+
+const (
+	// UploadURLTTL is the time a peer has to upload a requested bundle, it includes the time the local user takes to
+	// consent to the request
+	UploadURLTTL = time.Hour
+	// MaxBundleSize limits the size of an uploaded bundle
+	MaxBundleSize = 200 << 20
+
+	// UploadPathFormat is the path of the presigned upload URLs, the peers resolve it against their management URL
+	UploadPathFormat = "/api/debug-bundles/%s/upload"
+
+	bundlesDir = "debug-bundles"
+)
+
+// PeerUpdater sends the debug bundle requests to the peers connected to the management service
+type PeerUpdater interface {
+	// SendDebugBundleRequest returns false if the peer isn't connected
+	SendDebugBundleRequest(ctx context.Context, peerID string, request *proto.DebugBundleRequest) bool
+}
+
+type Manager interface {
+	// RequestBundle asks the peer to upload a debug bundle to a presigned URL
+	RequestBundle(ctx context.Context, accountID, userID, peerID string) (*types.Request, error)
+	GetPeerRequests(ctx context.Context, accountID, userID, peerID string) ([]*types.Request, error)
+	GetRequest(ctx context.Context, accountID, userID, peerID, requestID string) (*types.Request, error)
+	// OpenBundle returns the bundle uploaded for the request, the caller closes it
+	OpenBundle(ctx context.Context, accountID, userID, peerID, requestID string) (io.ReadCloser, *types.Request, error)
+	// DeleteRequest deletes the request and its uploaded bundle
+	DeleteRequest(ctx context.Context, accountID, userID, peerID, requestID string) error
+	// StoreBundle stores a bundle uploaded to a presigned URL, the signature authenticates the upload
+	StoreBundle(ctx context.Context, accountID, requestID string, expires int64, signature string, bundle io.Reader) error
+	// ReportStatus records a request the peer with the WireGuard public key won't upload a bundle for
+	ReportStatus(ctx context.Context, peerKey string, bundleStatus *proto.DebugBundleStatus) error
+}
+
+type managerImpl struct {
+	store              store.Store
+	permissionsManager permissions.Manager
+	accountManager     account.Manager
+	peerUpdater        PeerUpdater
+	// dataDir is the directory the uploaded bundles are stored in
+	dataDir string
+	// signingKey signs the upload URLs
+	signingKey []byte
+}
+
+type mockManager struct {
+}
+
+// NewManager creates a debug bundles manager storing the bundles in the data directory of the management service.
+// The upload URLs are signed with a key derived from the secret.
+func NewManager(store store.Store, permissionsManager permissions.Manager, accountManager account.Manager, peerUpdater PeerUpdater, dataDir, secret string) Manager {
+	key := sha256.Sum256([]byte("netbird-debug-bundles:" + secret))
+	return &managerImpl{
+		store:              store,
+		permissionsManager: permissionsManager,
+		accountManager:     accountManager,
+		peerUpdater:        peerUpdater,
+		dataDir:            filepath.Join(dataDir, bundlesDir),
+		signingKey:         key[:],
+	}
+}
+
+func (m *managerImpl) RequestBundle(ctx context.Context, accountID, userID, peerID string) (*types.Request, error) {
+	if err := m.validatePermissions(ctx, accountID, userID, permissions.Write); err != nil {
+		return nil, err
+	}
+
+	peer, err := m.store.GetPeerByID(ctx, store.LockingStrengthShare, accountID, peerID)
+	if err != nil {
+		return nil, err
+	}
+
+	request := types.NewRequest(accountID, peerID, userID, UploadURLTTL)
+
+	if err = m.store.SaveDebugBundleRequest(ctx, store.LockingStrengthUpdate, request); err != nil {
+		return nil, fmt.Errorf("failed to save debug bundle request: %w", err)
+	}
+
+	if !m.peerUpdater.SendDebugBundleRequest(ctx, peerID, m.toProtoRequest(request)) {
+		if err = m.store.DeleteDebugBundleRequest(ctx, store.LockingStrengthUpdate, accountID, request.ID); err != nil {
+			log.WithContext(ctx).Errorf("failed to delete the debug bundle request %s of the disconnected peer: %v", request.ID, err)
+		}
+		return nil, status.Errorf(status.PreconditionFailed, "peer %s is not connected", peerID)
+	}
+
+	meta := request.EventMeta()
+	meta["peer_name"] = peer.Name
+	m.accountManager.StoreEvent(ctx, userID, peerID, accountID, activity.DebugBundleRequested, meta)
+
+	return request, nil
+}
+
+func (m *managerImpl) GetPeerRequests(ctx context.Context, accountID, userID, peerID string) ([]*types.Request, error) {
+	if err := m.validatePermissions(ctx, accountID, userID, permissions.Read); err != nil {
+		return nil, err
+	}
+
+	if _, err := m.store.GetPeerByID(ctx, store.LockingStrengthShare, accountID, peerID); err != nil {
+		return nil, err
+	}
+
+	return m.store.GetPeerDebugBundleRequests(ctx, store.LockingStrengthShare, accountID, peerID)
+}
+
+func (m *managerImpl) GetRequest(ctx context.Context, accountID, userID, peerID, requestID string) (*types.Request, error) {
+	if err := m.validatePermissions(ctx, accountID, userID, permissions.Read); err != nil {
+		return nil, err
+	}
+
+	return m.getPeerRequest(ctx, accountID, peerID, requestID)
+}
+
+func (m *managerImpl) OpenBundle(ctx context.Context, accountID, userID, peerID, requestID string) (io.ReadCloser, *types.Request, error) {
+	// the bundles hold the logs and the configuration of the peers, downloading them requires write access
+	if err := m.validatePermissions(ctx, accountID, userID, permissions.Write); err != nil {
+		return nil, nil, err
+	}
+
+	request, err := m.getPeerRequest(ctx, accountID, peerID, requestID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if request.Status != types.StatusUploaded {
+		return nil, nil, status.Errorf(status.PreconditionFailed, "debug bundle %s hasn't been uploaded, its status is %s", requestID, request.EffectiveStatus())
+	}
+
+	bundle, err := os.Open(m.bundlePath(accountID, requestID))
+	if err != nil {
+		log.WithContext(ctx).Errorf("failed to open debug bundle %s: %v", requestID, err)
+		return nil, nil, status.Errorf(status.Internal, "failed to open debug bundle")
+	}
+
+	m.accountManager.StoreEvent(ctx, userID, peerID, accountID, activity.DebugBundleDownloaded, request.EventMeta())
+
+	return bundle, request, nil
+}
+
+func (m *managerImpl) DeleteRequest(ctx context.Context, accountID, userID, peerID, requestID string) error {
+	if err := m.validatePermissions(ctx, accountID, userID, permissions.Write); err != nil {
+		return err
+	}
+
+	unlock := m.store.AcquireWriteLockByUID(ctx, accountID)
+	defer unlock()
+
+	request, err := m.getPeerRequest(ctx, accountID, peerID, requestID)
+	if err != nil {
+		return err
+	}
+
+	if err = m.store.DeleteDebugBundleRequest(ctx, store.LockingStrengthUpdate, accountID, requestID); err != nil {
+		return fmt.Errorf("failed to delete debug bundle request: %w", err)
+	}
+
+	if err = os.Remove(m.bundlePath(accountID, requestID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.WithContext(ctx).Errorf("failed to remove debug bundle %s: %v", requestID, err)
+	}
+
+	m.accountManager.StoreEvent(ctx, userID, peerID, accountID, activity.DebugBundleDeleted, request.EventMeta())
+
+	return nil
+}
+
+func (m *managerImpl) StoreBundle(ctx context.Context, accountID, requestID string, expires int64, signature string, bundle io.Reader) error {
+	if !m.validSignature(accountID, requestID, expires, signature) {
+		return status.Errorf(status.PermissionDenied, "invalid upload signature")
+	}
+	if time.Now().Unix() > expires {
+		return status.Errorf(status.PermissionDenied, "upload URL expired")
+	}
+
+	request, err := m.store.GetDebugBundleRequestByID(ctx, store.LockingStrengthShare, accountID, requestID)
+	if err != nil {
+		return err
+	}
+	if request.Status != types.StatusPending {
+		return status.Errorf(status.PreconditionFailed, "debug bundle %s is %s", requestID, request.Status)
+	}
+
+	// the bundle is received before locking the account, the upload can take a while
+	tempPath, size, err := m.receiveBundle(accountID, requestID, bundle)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := os.Remove(tempPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.WithContext(ctx).Errorf("failed to remove temporary debug bundle file: %v", err)
+		}
+	}()
+
+	unlock := m.store.AcquireWriteLockByUID(ctx, accountID)
+	defer unlock()
+
+	request, err = m.store.GetDebugBundleRequestByID(ctx, store.LockingStrengthUpdate, accountID, requestID)
+	if err != nil {
+		return err
+	}
+	if request.Status != types.StatusPending {
+		return status.Errorf(status.PreconditionFailed, "debug bundle %s is %s", requestID, request.Status)
+	}
+
+	if err = os.Rename(tempPath, m.bundlePath(accountID, requestID)); err != nil {
+		return fmt.Errorf("store debug bundle file: %w", err)
+	}
+
+	request.Status = types.StatusUploaded
+	request.Size = size
+	request.UpdatedAt = time.Now().UTC()
+	if err = m.store.SaveDebugBundleRequest(ctx, store.LockingStrengthUpdate, request); err != nil {
+		return fmt.Errorf("failed to save debug bundle request: %w", err)
+	}
+
+	m.accountManager.StoreEvent(ctx, activity.SystemInitiator, request.PeerID, accountID, activity.DebugBundleUploaded, request.EventMeta())
+
+	return nil
+}
+
+func (m *managerImpl) ReportStatus(ctx context.Context, peerKey string, bundleStatus *proto.DebugBundleStatus) error {
+	peer, err := m.store.GetPeerByPeerPubKey(ctx, store.LockingStrengthShare, peerKey)
+	if err != nil {
+		return err
+	}
+
+	unlock := m.store.AcquireWriteLockByUID(ctx, peer.AccountID)
+	defer unlock()
+
+	request, err := m.getPeerRequest(ctx, peer.AccountID, peer.ID, bundleStatus.GetId())
+	if err != nil {
+		return err
+	}
+	if request.Status != types.StatusPending {
+		return status.Errorf(status.PreconditionFailed, "debug bundle %s is %s", request.ID, request.Status)
+	}
+
+	request.Status = types.StatusFailed
+	if bundleStatus.GetDeclined() {
+		request.Status = types.StatusDeclined
+	}
+	request.Error = bundleStatus.GetError()
+	request.UpdatedAt = time.Now().UTC()
+	if err = m.store.SaveDebugBundleRequest(ctx, store.LockingStrengthUpdate, request); err != nil {
+		return fmt.Errorf("failed to save debug bundle request: %w", err)
+	}
+
+	m.accountManager.StoreEvent(ctx, activity.SystemInitiator, peer.ID, peer.AccountID, activity.DebugBundleDeclined, request.EventMeta())
+
+	return nil
+}
+
+// getPeerRequest returns the request, if it was sent to the peer
+func (m *managerImpl) getPeerRequest(ctx context.Context, accountID, peerID, requestID string) (*types.Request, error) {
+	request, err := m.store.GetDebugBundleRequestByID(ctx, store.LockingStrengthShare, accountID, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if request.PeerID != peerID {
+		return nil, status.NewDebugBundleNotFoundError(requestID)
+	}
+	return request, nil
+}
+
+// receiveBundle writes the uploaded bundle to a temporary file, it is renamed to the bundle once the request is updated
+func (m *managerImpl) receiveBundle(accountID, requestID string, bundle io.Reader) (string, int64, error) {
+	dir := filepath.Join(m.dataDir, accountID)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", 0, fmt.Errorf("create debug bundles directory: %w", err)
+	}
+
+	file, err := os.CreateTemp(dir, requestID+".*.tmp")
+	if err != nil {
+		return "", 0, fmt.Errorf("create debug bundle file: %w", err)
+	}
+
+	size, err := io.Copy(file, io.LimitReader(bundle, MaxBundleSize+1))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && size > MaxBundleSize {
+		err = fmt.Errorf("debug bundle exceeds %d bytes", MaxBundleSize)
+	}
+	if err != nil {
+		if removeErr := os.Remove(file.Name()); removeErr != nil {
+			log.Errorf("failed to remove temporary debug bundle file: %v", removeErr)
+		}
+		return "", 0, status.Errorf(status.InvalidArgument, "failed to receive the debug bundle: %v", err)
+	}
+
+	return file.Name(), size, nil
+}
+
+func (m *managerImpl) bundlePath(accountID, requestID string) string {
+	return filepath.Join(m.dataDir, accountID, requestID+".zip")
+}
+
+// toProtoRequest converts the request to the message sent to the peer, signing its upload URL
+func (m *managerImpl) toProtoRequest(request *types.Request) *proto.DebugBundleRequest {
+	expires := request.ExpiresAt.Unix()
+	query := url.Values{}
+	query.Set("account", request.AccountID)
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", m.sign(request.AccountID, request.ID, expires))
+
+	return &proto.DebugBundleRequest{
+		Id:        request.ID,
+		UploadUrl: fmt.Sprintf(UploadPathFormat, request.ID) + "?" + query.Encode(),
+		ExpiresAt: timestamppb.New(request.ExpiresAt),
+	}
+}
+
+func (m *managerImpl) sign(accountID, requestID string, expires int64) string {
+	mac := hmac.New(sha256.New, m.signingKey)
+	mac.Write([]byte(accountID + "/" + requestID + "/" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (m *managerImpl) validSignature(accountID, requestID string, expires int64, signature string) bool {
+	expected, err := hex.DecodeString(m.sign(accountID, requestID, expires))
+	if err != nil {
+		return false
+	}
+	actual, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(expected, actual)
+}
+
+func (m *managerImpl) validatePermissions(ctx context.Context, accountID, userID string, operation permissions.Operation) error {
+	ok, err := m.permissionsManager.ValidateUserPermissions(ctx, accountID, userID, permissions.Peers, operation)
+	if err != nil {
+		return status.NewPermissionValidationError(err)
+	}
+	if !ok {
+		return status.NewPermissionDeniedError()
+	}
+	return nil
+}
+
+func NewManagerMock() Manager {
+	return &mockManager{}
+}
+
+func (m *mockManager) RequestBundle(ctx context.Context, accountID, userID, peerID string) (*types.Request, error) {
+	return types.NewRequest(accountID, peerID, userID, UploadURLTTL), nil
+}
+
+func (m *mockManager) GetPeerRequests(ctx context.Context, accountID, userID, peerID string) ([]*types.Request, error) {
+	return []*types.Request{}, nil
+}
+
+func (m *mockManager) GetRequest(ctx context.Context, accountID, userID, peerID, requestID string) (*types.Request, error) {
+	return nil, status.NewDebugBundleNotFoundError(requestID)
+}
+
+func (m *mockManager) OpenBundle(ctx context.Context, accountID, userID, peerID, requestID string) (io.ReadCloser, *types.Request, error) {
+	return nil, nil, status.NewDebugBundleNotFoundError(requestID)
+}
+
+func (m *mockManager) DeleteRequest(ctx context.Context, accountID, userID, peerID, requestID string) error {
+	return nil
+}
+
+func (m *mockManager) StoreBundle(ctx context.Context, accountID, requestID string, expires int64, signature string, bundle io.Reader) error {
+	return nil
+}
+
+func (m *mockManager) ReportStatus(ctx context.Context, peerKey string, bundleStatus *proto.DebugBundleStatus) error {
+	return nil
+}
@@ -0,0 +1,168 @@
+package debugbundles
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/management/proto"
+	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/debugbundles/types"
+	"github.com/netbirdio/netbird/management/server/mock_server"
+	"github.com/netbirdio/netbird/management/server/permissions"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
+)
+
+const (
+	testAccountID = "bf1c8084-ba50-4ce7-9439-34653001fc3b"
+	testAdminID   = "edafee4e-63fb-11ec-90d6-0242ac120003"
+	testRegularID = "f4f6d672-63fb-11ec-90d6-0242ac120003"
+	testPeerID    = "ct286bi7qv930dsrrug0"
+)
+
+// testPeerUpdater records the debug bundle requests, the peer isn't connected if disconnected is set
+type testPeerUpdater struct {
+	disconnected bool
+	requests     []*proto.DebugBundleRequest
+}
+
+func (u *testPeerUpdater) SendDebugBundleRequest(_ context.Context, _ string, request *proto.DebugBundleRequest) bool {
+	if u.disconnected {
+		return false
+	}
+	u.requests = append(u.requests, request)
+	return true
+}
+
+func newTestManager(t *testing.T, updater PeerUpdater, events *[]activity.Activity) Manager {
+	t.Helper()
+
+	s, cleanUp, err := store.NewTestStoreFromSQL(context.Background(), "../testdata/store.sql", t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(cleanUp)
+
+	accountManager := &mock_server.MockAccountManager{
+		StoreEventFunc: func(_ context.Context, _, _, _ string, activityID activity.ActivityDescriber, _ map[string]any) {
+			*events = append(*events, activityID.(activity.Activity))
+		},
+	}
+
+	return NewManager(s, permissions.NewManager(s), accountManager, updater, t.TempDir(), "secret")
+}
+
+func assertStatus(t *testing.T, err error, expected status.Type) {
+	t.Helper()
+
+	sErr, ok := status.FromError(err)
+	if assert.True(t, ok, "unexpected error %v", err) {
+		assert.Equal(t, expected, sErr.Type())
+	}
+}
+
+// parseUploadURL returns the account, the expiry and the signature of the upload URL
+func parseUploadURL(t *testing.T, request *proto.DebugBundleRequest) (string, int64, string) {
+	t.Helper()
+
+	u, err := url.Parse(request.GetUploadUrl())
+	require.NoError(t, err)
+	assert.Equal(t, "/api/debug-bundles/"+request.GetId()+"/upload", u.Path)
+
+	expires, err := strconv.ParseInt(u.Query().Get("expires"), 10, 64)
+	require.NoError(t, err)
+
+	return u.Query().Get("account"), expires, u.Query().Get("signature")
+}
+
+func TestManager_RequestBundle(t *testing.T) {
+	ctx := context.Background()
+	var events []activity.Activity
+
+	_, err := newTestManager(t, &testPeerUpdater{}, &events).RequestBundle(ctx, testAccountID, testRegularID, testPeerID)
+	assertStatus(t, err, status.PermissionDenied)
+
+	manager := newTestManager(t, &testPeerUpdater{disconnected: true}, &events)
+	_, err = manager.RequestBundle(ctx, testAccountID, testAdminID, testPeerID)
+	assertStatus(t, err, status.PreconditionFailed)
+
+	requests, err := manager.GetPeerRequests(ctx, testAccountID, testAdminID, testPeerID)
+	require.NoError(t, err)
+	assert.Empty(t, requests, "the request of a disconnected peer shouldn't be kept")
+	assert.Empty(t, events)
+}
+
+func TestManager_StoreBundle(t *testing.T) {
+	ctx := context.Background()
+	var events []activity.Activity
+	updater := &testPeerUpdater{}
+	manager := newTestManager(t, updater, &events)
+
+	request, err := manager.RequestBundle(ctx, testAccountID, testAdminID, testPeerID)
+	require.NoError(t, err)
+	require.Len(t, updater.requests, 1)
+	assert.Equal(t, request.ID, updater.requests[0].GetId())
+
+	accountID, expires, signature := parseUploadURL(t, updater.requests[0])
+	assert.Equal(t, testAccountID, accountID)
+
+	_, _, err = manager.OpenBundle(ctx, testAccountID, testAdminID, testPeerID, request.ID)
+	assertStatus(t, err, status.PreconditionFailed)
+
+	err = manager.StoreBundle(ctx, accountID, request.ID, expires+1, signature, strings.NewReader("bundle"))
+	assertStatus(t, err, status.PermissionDenied)
+
+	err = manager.StoreBundle(ctx, accountID, request.ID, expires, signature, strings.NewReader("bundle"))
+	require.NoError(t, err)
+
+	err = manager.StoreBundle(ctx, accountID, request.ID, expires, signature, strings.NewReader("other bundle"))
+	assertStatus(t, err, status.PreconditionFailed)
+
+	_, _, err = manager.OpenBundle(ctx, testAccountID, testRegularID, testPeerID, request.ID)
+	assertStatus(t, err, status.PermissionDenied)
+
+	bundle, uploaded, err := manager.OpenBundle(ctx, testAccountID, testAdminID, testPeerID, request.ID)
+	require.NoError(t, err)
+	content, err := io.ReadAll(bundle)
+	require.NoError(t, err)
+	require.NoError(t, bundle.Close())
+
+	assert.Equal(t, "bundle", string(content))
+	assert.Equal(t, types.StatusUploaded, uploaded.Status)
+	assert.Equal(t, int64(len(content)), uploaded.Size)
+
+	require.NoError(t, manager.DeleteRequest(ctx, testAccountID, testAdminID, testPeerID, request.ID))
+	_, err = manager.GetRequest(ctx, testAccountID, testAdminID, testPeerID, request.ID)
+	assertStatus(t, err, status.NotFound)
+
+	assert.Equal(t, []activity.Activity{
+		activity.DebugBundleRequested,
+		activity.DebugBundleUploaded,
+		activity.DebugBundleDownloaded,
+		activity.DebugBundleDeleted,
+	}, events)
+}
+
+func TestManager_StoreBundleRejectsOversizedBundles(t *testing.T) {
+	ctx := context.Background()
+	var events []activity.Activity
+	updater := &testPeerUpdater{}
+	manager := newTestManager(t, updater, &events)
+
+	request, err := manager.RequestBundle(ctx, testAccountID, testAdminID, testPeerID)
+	require.NoError(t, err)
+
+	accountID, expires, signature := parseUploadURL(t, updater.requests[0])
+	err = manager.StoreBundle(ctx, accountID, request.ID, expires, signature, bytes.NewReader(make([]byte, MaxBundleSize+1)))
+	assertStatus(t, err, status.InvalidArgument)
+
+	pending, err := manager.GetRequest(ctx, testAccountID, testAdminID, testPeerID, request.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.StatusPending, pending.Status)
+}
@@ -0,0 +1,102 @@
+package types
+
+import (
+	"time"
+
+	"github.com/rs/xid"
+
+	"github.com/netbirdio/netbird/management/server/http/api"
+)
+
+// Status of a debug bundle request
+type Status string
+
+const (
+	// StatusPending is the status of the requests the peer hasn't uploaded a bundle for yet
+	StatusPending Status = "pending"
+	// StatusUploaded is the status of the requests the peer uploaded a bundle for
+	StatusUploaded Status = "uploaded"
+	// StatusDeclined is the status of the requests the local user or the client configuration declined
+	StatusDeclined Status = "declined"
+	// StatusFailed is the status of the requests the peer failed to collect or upload a bundle for
+	StatusFailed Status = "failed"
+	// StatusExpired is the status of the pending requests whose upload URL expired
+	StatusExpired Status = "expired"
+)
+
+// Request of a debug bundle from a peer, initiated by a user of the account
+type Request struct {
+	ID        string `gorm:"primaryKey"`
+	AccountID string `gorm:"index"`
+	PeerID    string `gorm:"index"`
+	// InitiatedBy is the user that requested the bundle
+	InitiatedBy string
+	Status      Status
+	// Error describes why the peer failed to collect or upload the bundle
+	Error string
+	// Size of the uploaded bundle in bytes
+	Size      int64
+	CreatedAt time.Time
+	// ExpiresAt is the time the upload URL sent to the peer expires
+	ExpiresAt time.Time
+	UpdatedAt time.Time
+}
+
+// TableName returns the table of the debug bundle requests
+func (Request) TableName() string {
+	return "debug_bundle_requests"
+}
+
+func NewRequest(accountID, peerID, userID string, ttl time.Duration) *Request {
+	now := time.Now().UTC()
+	return &Request{
+		ID:          xid.New().String(),
+		AccountID:   accountID,
+		PeerID:      peerID,
+		InitiatedBy: userID,
+		Status:      StatusPending,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(ttl),
+		UpdatedAt:   now,
+	}
+}
+
+// EffectiveStatus returns the status of the request, a pending request past its expiry is expired
+func (r *Request) EffectiveStatus() Status {
+	if r.Status == StatusPending && time.Now().After(r.ExpiresAt) {
+		return StatusExpired
+	}
+	return r.Status
+}
+
+// EventMeta returns the activity event meta of the request
+func (r *Request) EventMeta() map[string]any {
+	meta := map[string]any{"request_id": r.ID, "status": string(r.Status)}
+	if r.Size > 0 {
+		meta["size"] = r.Size
+	}
+	if r.Error != "" {
+		meta["error"] = r.Error
+	}
+	return meta
+}
+
+// ToAPIResponse converts the request to its API representation
+func (r *Request) ToAPIResponse() *api.PeerDebugBundle {
+	bundle := &api.PeerDebugBundle{
+		Id:          r.ID,
+		PeerId:      r.PeerID,
+		InitiatedBy: r.InitiatedBy,
+		Status:      api.PeerDebugBundleStatus(r.EffectiveStatus()),
+		CreatedAt:   r.CreatedAt,
+		ExpiresAt:   r.ExpiresAt,
+		UpdatedAt:   r.UpdatedAt,
+	}
+	if r.Status == StatusUploaded {
+		bundle.Size = &r.Size
+	}
+	if r.Error != "" {
+		bundle.Error = &r.Error
+	}
+	return bundle
+}
@@ -0,0 +1,283 @@
+package declarative
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"slices"
+
+	"github.com/rs/xid"
+
+	nbdns "github.com/netbirdio/netbird/dns"
+	"github.com/netbirdio/netbird/management/domain"
+	"github.com/netbirdio/netbird/management/server/account"
+	"github.com/netbirdio/netbird/management/server/http/api"
+	"github.com/netbirdio/netbird/management/server/posture"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/types"
+	"github.com/netbirdio/netbird/route"
+)
+
+// applier makes the changes of a plan through the account manager, so every change is validated, recorded as an
+// event and distributed to the peers like a change made through the API
+type applier struct {
+	accountManager account.Manager
+	accountID      string
+	userID         string
+	idx            *index
+	spec           *Spec
+}
+
+// apply makes the changes in their order and stops at the first failing one, the changes made before are kept
+func (a *applier) apply(ctx context.Context, changes []Change) (int, error) {
+	for i, change := range changes {
+		var err error
+		switch change.Kind {
+		case KindGroup:
+			err = a.applyGroup(ctx, change)
+		case KindPostureCheck:
+			err = a.applyPostureCheck(ctx, change)
+		case KindPolicy:
+			err = a.applyPolicy(ctx, change)
+		case KindRoute:
+			err = a.applyRoute(ctx, change)
+		case KindNameserverGroup:
+			err = a.applyNameserverGroup(ctx, change)
+		default:
+			err = fmt.Errorf("unknown kind %s", change.Kind)
+		}
+		if err != nil {
+			return i, fmt.Errorf("%s %s %s: %w", change.Action, change.Kind, change.Name, err)
+		}
+	}
+	return len(changes), nil
+}
+
+func (a *applier) applyGroup(ctx context.Context, change Change) error {
+	if change.Action == ActionDelete {
+		return a.accountManager.DeleteGroup(ctx, a.accountID, a.userID, a.idx.groups[change.Name].ID)
+	}
+
+	desired := a.spec.Groups[slices.IndexFunc(a.spec.Groups, func(g Group) bool { return g.Name == change.Name })]
+
+	group := &types.Group{Name: desired.Name, Issued: types.GroupIssuedAPI, Peers: []string{}}
+	if existing, ok := a.idx.groups[change.Name]; ok {
+		group = existing.Copy()
+	}
+	if desired.Peers != nil {
+		group.Peers = a.peerIDs(desired.Peers)
+	}
+
+	if err := a.accountManager.SaveGroup(ctx, a.accountID, a.userID, group); err != nil {
+		return err
+	}
+	a.idx.groupIDs[group.Name] = group.ID
+	return nil
+}
+
+func (a *applier) applyPostureCheck(ctx context.Context, change Change) error {
+	if change.Action == ActionDelete {
+		return a.accountManager.DeletePostureChecks(ctx, a.accountID, a.idx.checks[change.Name].ID, a.userID)
+	}
+
+	desired := a.spec.PostureChecks[slices.IndexFunc(a.spec.PostureChecks, func(pc PostureCheck) bool { return pc.Name == change.Name })]
+
+	checks, err := posture.NewChecksFromAPIPostureCheck(api.PostureCheck{
+		Name:        desired.Name,
+		Description: &desired.Description,
+		Checks:      desired.Checks,
+	})
+	if err != nil {
+		return err
+	}
+	if existing, ok := a.idx.checks[change.Name]; ok {
+		checks.ID = existing.ID
+	}
+
+	saved, err := a.accountManager.SavePostureChecks(ctx, a.accountID, a.userID, checks)
+	if err != nil {
+		return err
+	}
+	a.idx.checkIDs[saved.Name] = saved.ID
+	return nil
+}
+
+func (a *applier) applyPolicy(ctx context.Context, change Change) error {
+	existing := a.idx.policies[change.Name]
+	if change.Action == ActionDelete {
+		return a.accountManager.DeletePolicy(ctx, a.accountID, existing.ID, a.userID)
+	}
+
+	desired := a.spec.Policies[slices.IndexFunc(a.spec.Policies, func(p Policy) bool { return p.Name == change.Name })]
+
+	policy := &types.Policy{
+		AccountID:           a.accountID,
+		Name:                desired.Name,
+		Description:         desired.Description,
+		Enabled:             !desired.Disabled,
+		SourcePostureChecks: a.postureCheckIDs(desired.SourcePostureChecks),
+	}
+	if existing != nil {
+		policy.ID = existing.ID
+		policy.Remediation = existing.Remediation
+	}
+
+	for _, rule := range desired.Rules {
+		pr := &types.PolicyRule{
+			ID:                a.ruleID(existing, rule.Name),
+			PolicyID:          policy.ID,
+			Name:              rule.Name,
+			Description:       rule.Description,
+			Enabled:           !rule.Disabled,
+			Action:            types.PolicyTrafficActionType(rule.Action),
+			Protocol:          types.PolicyRuleProtocolType(rule.Protocol),
+			Bidirectional:     rule.Bidirectional,
+			Ports:             rule.Ports,
+			Sources:           a.groupIDs(rule.Sources),
+			SourceLabels:      rule.SourceLabels,
+			Destinations:      a.groupIDs(rule.Destinations),
+			DestinationLabels: rule.DestinationLabels,
+		}
+		for _, portRange := range rule.PortRanges {
+			pr.PortRanges = append(pr.PortRanges, types.RulePortRange{Start: uint16(portRange.Start), End: uint16(portRange.End)})
+		}
+		policy.Rules = append(policy.Rules, pr)
+	}
+
+	_, err := a.accountManager.SavePolicy(ctx, a.accountID, a.userID, policy)
+	return err
+}
+
+// ruleID returns the ID of the rule of the existing policy with the same name. The rules added to an existing policy
+// get a new ID, the rules of a new policy get their IDs when it is saved.
+func (a *applier) ruleID(existing *types.Policy, name string) string {
+	if existing == nil {
+		return ""
+	}
+	for _, rule := range existing.Rules {
+		if rule.Name == name {
+			return rule.ID
+		}
+	}
+	return xid.New().String()
+}
+
+func (a *applier) applyRoute(ctx context.Context, change Change) error {
+	existing := a.idx.routes[change.Name]
+	if change.Action == ActionDelete {
+		return a.accountManager.DeleteRoute(ctx, a.accountID, existing.ID, a.userID)
+	}
+
+	desired := a.spec.Routes[slices.IndexFunc(a.spec.Routes, func(r Route) bool { return r.NetworkID == change.Name })]
+
+	var domains domain.List
+	var networkType route.NetworkType
+	var prefix netip.Prefix
+	if len(desired.Domains) > 0 {
+		var err error
+		domains, err = domain.ValidateDomains(desired.Domains)
+		if err != nil {
+			return status.Errorf(status.InvalidArgument, "invalid domains: %v", err)
+		}
+		networkType = route.DomainNetwork
+	} else {
+		var err error
+		networkType, prefix, err = route.ParseNetwork(desired.Network)
+		if err != nil {
+			return err
+		}
+	}
+
+	peerID := ""
+	if desired.Peer != "" {
+		peerID = a.idx.peerIDs[desired.Peer]
+	}
+
+	if existing == nil {
+		_, err := a.accountManager.CreateRoute(ctx, a.accountID, prefix, networkType, domains, peerID, a.groupIDs(desired.PeerGroups),
+			desired.Description, route.NetID(desired.NetworkID), desired.Masquerade, desired.Metric, a.groupIDs(desired.Groups),
+			a.groupIDs(desired.AccessControlGroups), !desired.Disabled, a.userID, desired.KeepRoute)
+		return err
+	}
+
+	r := existing.Copy()
+	r.Network = prefix
+	r.Domains = domains
+	r.NetworkType = networkType
+	r.KeepRoute = desired.KeepRoute
+	r.Description = desired.Description
+	r.Peer = peerID
+	r.PeerGroups = a.groupIDs(desired.PeerGroups)
+	r.Masquerade = desired.Masquerade
+	r.Metric = desired.Metric
+	r.Enabled = !desired.Disabled
+	r.Groups = a.groupIDs(desired.Groups)
+	r.AccessControlGroups = a.groupIDs(desired.AccessControlGroups)
+
+	return a.accountManager.SaveRoute(ctx, a.accountID, a.userID, r)
+}
+
+func (a *applier) applyNameserverGroup(ctx context.Context, change Change) error {
+	existing := a.idx.nsGroups[change.Name]
+	if change.Action == ActionDelete {
+		return a.accountManager.DeleteNameServerGroup(ctx, a.accountID, existing.ID, a.userID)
+	}
+
+	desired := a.spec.NameserverGroups[slices.IndexFunc(a.spec.NameserverGroups, func(ns NameserverGroup) bool { return ns.Name == change.Name })]
+
+	nameservers := make([]nbdns.NameServer, 0, len(desired.Nameservers))
+	for _, ns := range desired.Nameservers {
+		ip, err := netip.ParseAddr(ns.IP)
+		if err != nil {
+			return status.Errorf(status.InvalidArgument, "invalid nameserver IP %s", ns.IP)
+		}
+		nameservers = append(nameservers, nbdns.NameServer{
+			IP:     ip,
+			NSType: nbdns.ToNameServerType(ns.Type),
+			Port:   ns.Port,
+		})
+	}
+
+	if existing == nil {
+		_, err := a.accountManager.CreateNameServerGroup(ctx, a.accountID, desired.Name, desired.Description, nameservers,
+			a.groupIDs(desired.Groups), desired.Primary, desired.Domains, !desired.Disabled, a.userID, desired.SearchDomainsEnabled,
+			nbdns.RoutedAnswersDisabled, "")
+		return err
+	}
+
+	nsGroup := existing.Copy()
+	nsGroup.Description = desired.Description
+	nsGroup.NameServers = nameservers
+	nsGroup.Groups = a.groupIDs(desired.Groups)
+	nsGroup.Primary = desired.Primary
+	nsGroup.Domains = desired.Domains
+	nsGroup.Enabled = !desired.Disabled
+	nsGroup.SearchDomainsEnabled = desired.SearchDomainsEnabled
+
+	return a.accountManager.SaveNameServerGroup(ctx, a.accountID, a.userID, nsGroup)
+}
+
+// groupIDs maps the group names to the IDs, including the IDs of the groups created by the spec
+func (a *applier) groupIDs(names []string) []string {
+	ids := make([]string, 0, len(names))
+	for _, name := range names {
+		ids = append(ids, a.idx.groupIDs[name])
+	}
+	return ids
+}
+
+func (a *applier) postureCheckIDs(names []string) []string {
+	ids := make([]string, 0, len(names))
+	for _, name := range names {
+		ids = append(ids, a.idx.checkIDs[name])
+	}
+	return ids
+}
+
+func (a *applier) peerIDs(labels []string) []string {
+	ids := make([]string, 0, len(labels))
+	for _, label := range labels {
+		ids = append(ids, a.idx.peerIDs[label])
+	}
+	return ids
+}
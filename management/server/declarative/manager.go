@@ -0,0 +1,151 @@
+package declarative
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/management/server/account"
+	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/status"
+)
+
+type Manager interface {
+	// Export returns the spec of the groups, posture checks, policies, routes and nameserver groups of the account
+	Export(ctx context.Context, accountID, userID string) (*Spec, error)
+	// Plan returns the changes applying the spec to the account makes
+	Plan(ctx context.Context, accountID, userID string, spec *Spec) (*Plan, error)
+	// Apply makes the changes of the plan of the spec and returns the plan. It stops at the first failing change,
+	// the changes made before it are kept and applying the spec again continues with the remaining ones.
+	Apply(ctx context.Context, accountID, userID string, spec *Spec) (*Plan, error)
+}
+
+type managerImpl struct {
+	accountManager account.Manager
+}
+
+func NewManager(accountManager account.Manager) Manager {
+	return &managerImpl{
+		accountManager: accountManager,
+	}
+}
+
+func (m *managerImpl) Export(ctx context.Context, accountID, userID string) (*Spec, error) {
+	s, err := m.loadState(ctx, accountID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return newIndex(s).export(), nil
+}
+
+func (m *managerImpl) Plan(ctx context.Context, accountID, userID string, spec *Spec) (*Plan, error) {
+	idx, desired, err := m.prepare(ctx, accountID, userID, spec)
+	if err != nil {
+		return nil, err
+	}
+	return idx.plan(desired)
+}
+
+func (m *managerImpl) Apply(ctx context.Context, accountID, userID string, spec *Spec) (*Plan, error) {
+	idx, desired, err := m.prepare(ctx, accountID, userID, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := idx.plan(desired)
+	if err != nil {
+		return nil, err
+	}
+	if len(plan.Changes) == 0 {
+		return plan, nil
+	}
+
+	a := &applier{
+		accountManager: m.accountManager,
+		accountID:      accountID,
+		userID:         userID,
+		idx:            idx,
+		spec:           desired,
+	}
+	applied, err := a.apply(ctx, plan.Changes)
+
+	meta := map[string]any{"changes": applied, "planned": len(plan.Changes)}
+	m.accountManager.StoreEvent(ctx, userID, accountID, accountID, activity.AccountSpecApplied, meta)
+
+	if err != nil {
+		log.WithContext(ctx).Warnf("applied %d of %d changes of the spec to account %s: %v", applied, len(plan.Changes), accountID, err)
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// prepare loads the objects of the account and returns a copy of the spec with its defaults set
+func (m *managerImpl) prepare(ctx context.Context, accountID, userID string, spec *Spec) (*index, *Spec, error) {
+	// the spec is copied through its YAML format as it is normalized
+	content, err := Marshal(spec)
+	if err != nil {
+		return nil, nil, err
+	}
+	desired, err := Parse(content)
+	if err != nil {
+		return nil, nil, status.Errorf(status.InvalidArgument, "%s", err)
+	}
+	if err = desired.setDefaults(); err != nil {
+		return nil, nil, status.Errorf(status.InvalidArgument, "%s", err)
+	}
+	desired.normalize()
+
+	s, err := m.loadState(ctx, accountID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return newIndex(s), desired, nil
+}
+
+// loadState reads the objects of the account the user has access to
+func (m *managerImpl) loadState(ctx context.Context, accountID, userID string) (*state, error) {
+	var s state
+	var err error
+
+	if s.peers, err = m.accountManager.GetPeers(ctx, accountID, userID, "", ""); err != nil {
+		return nil, fmt.Errorf("get peers: %w", err)
+	}
+	if s.groups, err = m.accountManager.GetAllGroups(ctx, accountID, userID); err != nil {
+		return nil, fmt.Errorf("get groups: %w", err)
+	}
+	if s.postureChecks, err = m.accountManager.ListPostureChecks(ctx, accountID, userID); err != nil {
+		return nil, fmt.Errorf("get posture checks: %w", err)
+	}
+	if s.policies, err = m.accountManager.ListPolicies(ctx, accountID, userID); err != nil {
+		return nil, fmt.Errorf("get policies: %w", err)
+	}
+	if s.routes, err = m.accountManager.ListRoutes(ctx, accountID, userID); err != nil {
+		return nil, fmt.Errorf("get routes: %w", err)
+	}
+	if s.nsGroups, err = m.accountManager.ListNameServerGroups(ctx, accountID, userID); err != nil {
+		return nil, fmt.Errorf("get nameserver groups: %w", err)
+	}
+
+	return &s, nil
+}
+
+type mockManager struct{}
+
+func NewManagerMock() Manager {
+	return &mockManager{}
+}
+
+func (m *mockManager) Export(ctx context.Context, accountID, userID string) (*Spec, error) {
+	return &Spec{Version: SpecVersion}, nil
+}
+
+func (m *mockManager) Plan(ctx context.Context, accountID, userID string, spec *Spec) (*Plan, error) {
+	return &Plan{}, nil
+}
+
+func (m *mockManager) Apply(ctx context.Context, accountID, userID string, spec *Spec) (*Plan, error) {
+	return &Plan{}, nil
+}
@@ -0,0 +1,593 @@
+package declarative
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	nbdns "github.com/netbirdio/netbird/dns"
+	"github.com/netbirdio/netbird/management/server/http/api"
+	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+	"github.com/netbirdio/netbird/management/server/posture"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/types"
+	"github.com/netbirdio/netbird/route"
+)
+
+// Kind is the kind of the objects of a spec section
+type Kind string
+
+const (
+	KindGroup           Kind = "group"
+	KindPostureCheck    Kind = "posture_check"
+	KindPolicy          Kind = "policy"
+	KindRoute           Kind = "route"
+	KindNameserverGroup Kind = "nameserver_group"
+)
+
+// Action is the change of an object
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// Change is a change applying a spec makes to an object of the account
+type Change struct {
+	Kind   Kind
+	Name   string
+	Action Action
+	// Fields are the changed fields of an updated object
+	Fields []string
+}
+
+// Plan lists the changes applying a spec makes in the order they are applied
+type Plan struct {
+	Changes []Change
+	// Warnings name the objects of the account the spec can't manage
+	Warnings []string
+}
+
+func (p *Plan) ToAPIResponse() *api.ConfigPlan {
+	changes := make([]api.ConfigChange, 0, len(p.Changes))
+	for _, change := range p.Changes {
+		apiChange := api.ConfigChange{
+			Kind:   api.ConfigChangeKind(change.Kind),
+			Name:   change.Name,
+			Action: api.ConfigChangeAction(change.Action),
+		}
+		if len(change.Fields) > 0 {
+			apiChange.Fields = &change.Fields
+		}
+		changes = append(changes, apiChange)
+	}
+
+	warnings := p.Warnings
+	if warnings == nil {
+		warnings = []string{}
+	}
+
+	return &api.ConfigPlan{
+		Changes:  changes,
+		Warnings: warnings,
+	}
+}
+
+// state holds the objects of an account
+type state struct {
+	peers         []*nbpeer.Peer
+	groups        []*types.Group
+	postureChecks []*posture.Checks
+	policies      []*types.Policy
+	routes        []*route.Route
+	nsGroups      []*nbdns.NameServerGroup
+}
+
+// index looks the objects of an account up by their names and IDs. The managed objects are the ones a spec
+// describes, the other objects can only be referenced.
+type index struct {
+	peerLabels map[string]string
+	peerIDs    map[string]string
+	groupNames map[string]string
+	groupIDs   map[string]string
+	checkNames map[string]string
+	checkIDs   map[string]string
+
+	groups   map[string]*types.Group
+	checks   map[string]*posture.Checks
+	policies map[string]*types.Policy
+	routes   map[string]*route.Route
+	nsGroups map[string]*nbdns.NameServerGroup
+
+	// unmanaged are the names of the objects that can't be managed by a spec with the reason
+	unmanaged map[Kind]map[string]string
+}
+
+func newIndex(s *state) *index {
+	idx := &index{
+		peerLabels: make(map[string]string),
+		peerIDs:    make(map[string]string),
+		groupNames: make(map[string]string),
+		groupIDs:   make(map[string]string),
+		checkNames: make(map[string]string),
+		checkIDs:   make(map[string]string),
+		groups:     make(map[string]*types.Group),
+		checks:     make(map[string]*posture.Checks),
+		policies:   make(map[string]*types.Policy),
+		routes:     make(map[string]*route.Route),
+		nsGroups:   make(map[string]*nbdns.NameServerGroup),
+		unmanaged: map[Kind]map[string]string{
+			KindGroup:           {},
+			KindPostureCheck:    {},
+			KindPolicy:          {},
+			KindRoute:           {},
+			KindNameserverGroup: {},
+		},
+	}
+
+	for _, peer := range s.peers {
+		idx.peerLabels[peer.ID] = peer.DNSLabel
+		idx.peerIDs[peer.DNSLabel] = peer.ID
+	}
+
+	for _, group := range s.groups {
+		idx.groupNames[group.ID] = group.Name
+		// the groups issued by the identity provider can share their names, the API groups can't
+		if _, ok := idx.groupIDs[group.Name]; !ok || group.Issued == types.GroupIssuedAPI {
+			idx.groupIDs[group.Name] = group.ID
+		}
+
+		switch {
+		case group.IsGroupAll():
+			idx.unmanaged[KindGroup][group.Name] = "it contains all the peers"
+		case group.Issued != types.GroupIssuedAPI:
+			idx.unmanaged[KindGroup][group.Name] = fmt.Sprintf("it is issued by %s", group.Issued)
+		default:
+			idx.groups[group.Name] = group
+		}
+	}
+
+	for _, checks := range s.postureChecks {
+		idx.checkNames[checks.ID] = checks.Name
+		idx.checkIDs[checks.Name] = checks.ID
+		idx.checks[checks.Name] = checks
+	}
+
+	for _, policy := range s.policies {
+		if hasResourceRules(policy) {
+			idx.unmanaged[KindPolicy][policy.Name] = "its rules select network resources"
+			continue
+		}
+		idx.policies[policy.Name] = policy
+	}
+
+	for _, r := range s.routes {
+		netID := string(r.NetID)
+		if _, ok := idx.routes[netID]; ok {
+			delete(idx.routes, netID)
+			idx.unmanaged[KindRoute][netID] = "several routes share its network ID"
+			continue
+		}
+		if _, ok := idx.unmanaged[KindRoute][netID]; ok {
+			continue
+		}
+		idx.routes[netID] = r
+	}
+
+	for _, nsGroup := range s.nsGroups {
+		idx.nsGroups[nsGroup.Name] = nsGroup
+	}
+
+	return idx
+}
+
+func hasResourceRules(policy *types.Policy) bool {
+	for _, rule := range policy.Rules {
+		if rule.SourceResource.ID != "" || rule.DestinationResource.ID != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// export returns the spec of the managed objects
+func (idx *index) export() *Spec {
+	spec := &Spec{
+		Version:          SpecVersion,
+		Groups:           make([]Group, 0, len(idx.groups)),
+		PostureChecks:    make([]PostureCheck, 0, len(idx.checks)),
+		Policies:         make([]Policy, 0, len(idx.policies)),
+		Routes:           make([]Route, 0, len(idx.routes)),
+		NameserverGroups: make([]NameserverGroup, 0, len(idx.nsGroups)),
+	}
+
+	for _, group := range idx.groups {
+		spec.Groups = append(spec.Groups, idx.toSpecGroup(group))
+	}
+	for _, checks := range idx.checks {
+		spec.PostureChecks = append(spec.PostureChecks, toSpecPostureCheck(checks))
+	}
+	for _, policy := range idx.policies {
+		spec.Policies = append(spec.Policies, idx.toSpecPolicy(policy))
+	}
+	for _, r := range idx.routes {
+		spec.Routes = append(spec.Routes, idx.toSpecRoute(r))
+	}
+	for _, nsGroup := range idx.nsGroups {
+		spec.NameserverGroups = append(spec.NameserverGroups, idx.toSpecNameserverGroup(nsGroup))
+	}
+
+	spec.normalize()
+	return spec
+}
+
+// plan returns the changes applying the spec makes, the spec has to be normalized with its defaults set
+func (idx *index) plan(spec *Spec) (*Plan, error) {
+	if err := idx.validateReferences(spec); err != nil {
+		return nil, err
+	}
+
+	current := idx.export()
+	plan := &Plan{}
+
+	var deletes []Change
+	if spec.Groups != nil {
+		changes, deleted, err := diffSection(KindGroup, current.Groups, spec.Groups, idx.unmanaged[KindGroup],
+			func(g Group) string { return g.Name },
+			func(current, desired Group) Group {
+				// the peers of the group aren't managed if not set
+				if desired.Peers == nil {
+					current.Peers = nil
+				}
+				return current
+			})
+		if err != nil {
+			return nil, err
+		}
+		plan.Changes = append(plan.Changes, changes...)
+		deletes = append(deleted, deletes...)
+	}
+
+	if spec.PostureChecks != nil {
+		changes, deleted, err := diffSection(KindPostureCheck, current.PostureChecks, spec.PostureChecks, idx.unmanaged[KindPostureCheck],
+			func(pc PostureCheck) string { return pc.Name }, nil)
+		if err != nil {
+			return nil, err
+		}
+		plan.Changes = append(plan.Changes, changes...)
+		deletes = append(deleted, deletes...)
+	}
+
+	if spec.Policies != nil {
+		changes, deleted, err := diffSection(KindPolicy, current.Policies, spec.Policies, idx.unmanaged[KindPolicy],
+			func(p Policy) string { return p.Name }, nil)
+		if err != nil {
+			return nil, err
+		}
+		plan.Changes = append(plan.Changes, changes...)
+		deletes = append(deleted, deletes...)
+	}
+
+	if spec.Routes != nil {
+		changes, deleted, err := diffSection(KindRoute, current.Routes, spec.Routes, idx.unmanaged[KindRoute],
+			func(r Route) string { return r.NetworkID }, nil)
+		if err != nil {
+			return nil, err
+		}
+		plan.Changes = append(plan.Changes, changes...)
+		deletes = append(deleted, deletes...)
+	}
+
+	if spec.NameserverGroups != nil {
+		changes, deleted, err := diffSection(KindNameserverGroup, current.NameserverGroups, spec.NameserverGroups, idx.unmanaged[KindNameserverGroup],
+			func(ns NameserverGroup) string { return ns.Name }, nil)
+		if err != nil {
+			return nil, err
+		}
+		plan.Changes = append(plan.Changes, changes...)
+		deletes = append(deleted, deletes...)
+	}
+
+	// the objects are deleted after the objects referencing them were updated, in the reverse order of the sections
+	plan.Changes = append(plan.Changes, deletes...)
+
+	// the unmanaged groups are referenced by the specs, they aren't reported
+	for _, kind := range []Kind{KindPolicy, KindRoute} {
+		unmanaged := idx.unmanaged[kind]
+		for _, name := range sortedKeys(unmanaged) {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("%s %s isn't managed because %s", kind, name, unmanaged[name]))
+		}
+	}
+
+	return plan, nil
+}
+
+// diffSection compares the current objects of a section with the desired ones. The changes are returned in the order
+// of the desired objects, the deletions separately. compareWith adapts a current object to the desired one before
+// they are compared, e.g. to ignore the fields the desired object doesn't manage.
+func diffSection[T any](kind Kind, current, desired []T, unmanaged map[string]string, name func(T) string, compareWith func(current, desired T) T) ([]Change, []Change, error) {
+	currentByName := make(map[string]T, len(current))
+	for _, item := range current {
+		currentByName[name(item)] = item
+	}
+
+	var changes []Change
+	desiredNames := make(map[string]struct{}, len(desired))
+	for _, item := range desired {
+		itemName := name(item)
+		desiredNames[itemName] = struct{}{}
+
+		if reason, ok := unmanaged[itemName]; ok {
+			return nil, nil, status.Errorf(status.InvalidArgument, "%s %s can't be managed because %s", kind, itemName, reason)
+		}
+
+		existing, ok := currentByName[itemName]
+		if !ok {
+			changes = append(changes, Change{Kind: kind, Name: itemName, Action: ActionCreate})
+			continue
+		}
+
+		if compareWith != nil {
+			existing = compareWith(existing, item)
+		}
+		fields, err := changedFields(existing, item)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(fields) > 0 {
+			changes = append(changes, Change{Kind: kind, Name: itemName, Action: ActionUpdate, Fields: fields})
+		}
+	}
+
+	var deletes []Change
+	for _, item := range current {
+		if _, ok := desiredNames[name(item)]; !ok {
+			deletes = append(deletes, Change{Kind: kind, Name: name(item), Action: ActionDelete})
+		}
+	}
+
+	return changes, deletes, nil
+}
+
+// changedFields returns the names of the fields that differ between the objects
+func changedFields(current, desired any) ([]string, error) {
+	currentFields, err := fieldsOf(current)
+	if err != nil {
+		return nil, err
+	}
+	desiredFields, err := fieldsOf(desired)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	for field, value := range desiredFields {
+		if !bytes.Equal(currentFields[field], value) {
+			changed = append(changed, field)
+		}
+	}
+	for field := range currentFields {
+		if _, ok := desiredFields[field]; !ok {
+			changed = append(changed, field)
+		}
+	}
+	slices.Sort(changed)
+
+	return changed, nil
+}
+
+func fieldsOf(v any) (map[string]json.RawMessage, error) {
+	content, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("marshal object: %w", err)
+	}
+	fields := make(map[string]json.RawMessage)
+	if err = json.Unmarshal(content, &fields); err != nil {
+		return nil, fmt.Errorf("unmarshal object: %w", err)
+	}
+	return fields, nil
+}
+
+// validateReferences checks that the groups, the posture checks and the peers the spec references exist in the
+// account or are created by the spec, and aren't deleted by it
+func (idx *index) validateReferences(spec *Spec) error {
+	groupExists := func(name string) bool {
+		if spec.Groups == nil {
+			_, ok := idx.groupIDs[name]
+			return ok
+		}
+		if slices.ContainsFunc(spec.Groups, func(g Group) bool { return g.Name == name }) {
+			return true
+		}
+		_, unmanaged := idx.unmanaged[KindGroup][name]
+		return unmanaged
+	}
+
+	checkExists := func(name string) bool {
+		if spec.PostureChecks == nil {
+			_, ok := idx.checkIDs[name]
+			return ok
+		}
+		return slices.ContainsFunc(spec.PostureChecks, func(pc PostureCheck) bool { return pc.Name == name })
+	}
+
+	checkGroups := func(kind Kind, name string, groups ...[]string) error {
+		for _, list := range groups {
+			for _, group := range list {
+				if !groupExists(group) {
+					return status.Errorf(status.InvalidArgument, "%s %s references group %s that doesn't exist", kind, name, group)
+				}
+			}
+		}
+		return nil
+	}
+
+	checkPeers := func(kind Kind, name string, labels ...string) error {
+		for _, label := range labels {
+			if _, ok := idx.peerIDs[label]; !ok {
+				return status.Errorf(status.InvalidArgument, "%s %s references peer %s that doesn't exist", kind, name, label)
+			}
+		}
+		return nil
+	}
+
+	for _, group := range spec.Groups {
+		if err := checkPeers(KindGroup, group.Name, group.Peers...); err != nil {
+			return err
+		}
+	}
+
+	for _, policy := range spec.Policies {
+		for _, check := range policy.SourcePostureChecks {
+			if !checkExists(check) {
+				return status.Errorf(status.InvalidArgument, "policy %s references posture check %s that doesn't exist", policy.Name, check)
+			}
+		}
+		for _, rule := range policy.Rules {
+			if err := checkGroups(KindPolicy, policy.Name, rule.Sources, rule.Destinations); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, r := range spec.Routes {
+		if err := checkGroups(KindRoute, r.NetworkID, r.PeerGroups, r.Groups, r.AccessControlGroups); err != nil {
+			return err
+		}
+		if r.Peer != "" {
+			if err := checkPeers(KindRoute, r.NetworkID, r.Peer); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, nsGroup := range spec.NameserverGroups {
+		if err := checkGroups(KindNameserverGroup, nsGroup.Name, nsGroup.Groups); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (idx *index) toSpecGroup(group *types.Group) Group {
+	g := Group{Name: group.Name}
+	// the peers of a group with a membership rule are managed by the rule
+	if group.MembershipRule == nil {
+		g.Peers = idx.peerNames(group.Peers)
+	}
+	return g
+}
+
+func toSpecPostureCheck(checks *posture.Checks) PostureCheck {
+	return PostureCheck{
+		Name:        checks.Name,
+		Description: checks.Description,
+		Checks:      checks.ToAPIResponse().Checks,
+	}
+}
+
+func (idx *index) toSpecPolicy(policy *types.Policy) Policy {
+	p := Policy{
+		Name:                policy.Name,
+		Description:         policy.Description,
+		Disabled:            !policy.Enabled,
+		SourcePostureChecks: namesOf(idx.checkNames, policy.SourcePostureChecks),
+		Rules:               make([]PolicyRule, 0, len(policy.Rules)),
+	}
+
+	for _, rule := range policy.Rules {
+		r := PolicyRule{
+			Name:              rule.Name,
+			Description:       rule.Description,
+			Disabled:          !rule.Enabled,
+			Action:            string(rule.Action),
+			Protocol:          string(rule.Protocol),
+			Bidirectional:     rule.Bidirectional,
+			Ports:             rule.Ports,
+			Sources:           namesOf(idx.groupNames, rule.Sources),
+			SourceLabels:      rule.SourceLabels,
+			Destinations:      namesOf(idx.groupNames, rule.Destinations),
+			DestinationLabels: rule.DestinationLabels,
+		}
+		for _, portRange := range rule.PortRanges {
+			r.PortRanges = append(r.PortRanges, PortRange{Start: int(portRange.Start), End: int(portRange.End)})
+		}
+		p.Rules = append(p.Rules, r)
+	}
+
+	return p
+}
+
+func (idx *index) toSpecRoute(r *route.Route) Route {
+	specRoute := Route{
+		NetworkID:           string(r.NetID),
+		Description:         r.Description,
+		Disabled:            !r.Enabled,
+		KeepRoute:           r.KeepRoute,
+		Peer:                idx.peerLabels[r.Peer],
+		PeerGroups:          namesOf(idx.groupNames, r.PeerGroups),
+		Metric:              r.Metric,
+		Masquerade:          r.Masquerade,
+		Groups:              namesOf(idx.groupNames, r.Groups),
+		AccessControlGroups: namesOf(idx.groupNames, r.AccessControlGroups),
+	}
+
+	if r.IsDynamic() {
+		specRoute.Domains = r.Domains.ToSafeStringList()
+	} else {
+		specRoute.Network = r.Network.String()
+	}
+
+	return specRoute
+}
+
+func (idx *index) toSpecNameserverGroup(nsGroup *nbdns.NameServerGroup) NameserverGroup {
+	ns := NameserverGroup{
+		Name:                 nsGroup.Name,
+		Description:          nsGroup.Description,
+		Disabled:             !nsGroup.Enabled,
+		Nameservers:          make([]Nameserver, 0, len(nsGroup.NameServers)),
+		Groups:               namesOf(idx.groupNames, nsGroup.Groups),
+		Primary:              nsGroup.Primary,
+		Domains:              nsGroup.Domains,
+		SearchDomainsEnabled: nsGroup.SearchDomainsEnabled,
+	}
+
+	for _, server := range nsGroup.NameServers {
+		ns.Nameservers = append(ns.Nameservers, Nameserver{
+			IP:   server.IP.String(),
+			Type: server.NSType.String(),
+			Port: server.Port,
+		})
+	}
+
+	return ns
+}
+
+func (idx *index) peerNames(peerIDs []string) []string {
+	return namesOf(idx.peerLabels, peerIDs)
+}
+
+// namesOf maps the IDs to the names, the IDs of the deleted objects are skipped
+func namesOf(names map[string]string, ids []string) []string {
+	var result []string
+	for _, id := range ids {
+		if name, ok := names[id]; ok {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,372 @@
+// Package declarative describes the groups, posture checks, policies, routes and nameserver groups of an account in a
+// declarative YAML format. The objects reference each other and the peers by their names, so a spec exported from
+// one account can be kept in version control, reviewed as a diff and applied to the same or another account. The
+// plan of a spec lists the changes applying it makes, the objects of a section of the spec that are missing from it
+// are deleted.
+package declarative
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	nbdns "github.com/netbirdio/netbird/dns"
+	"github.com/netbirdio/netbird/management/domain"
+	"github.com/netbirdio/netbird/management/server/http/api"
+	"github.com/netbirdio/netbird/management/server/types"
+	"github.com/netbirdio/netbird/route"
+)
+
+// SpecVersion is the version of the spec format
+const SpecVersion = 1
+
+// MaxSpecSize is the size limit of a spec
+const MaxSpecSize = 10 << 20
+
+// Spec is the declarative description of an account. A nil section isn't managed, the objects of an empty section
+// are deleted.
+type Spec struct {
+	Version          int               `json:"version"`
+	Groups           []Group           `json:"groups"`
+	PostureChecks    []PostureCheck    `json:"posture_checks"`
+	Policies         []Policy          `json:"policies"`
+	Routes           []Route           `json:"routes"`
+	NameserverGroups []NameserverGroup `json:"nameserver_groups"`
+}
+
+// Group is a group created through the API, the groups issued by the identity provider or an integration can be
+// referenced by their names but aren't managed
+type Group struct {
+	Name string `json:"name"`
+	// Peers are the DNS labels of the peers of the group, the peers of the group aren't changed if not set
+	Peers []string `json:"peers,omitempty"`
+}
+
+// PostureCheck is a posture check with the checks in the format of the API
+type PostureCheck struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Checks      api.Checks `json:"checks"`
+}
+
+// Policy is an access control policy, the groups and the posture checks are referenced by their names
+type Policy struct {
+	Name                string       `json:"name"`
+	Description         string       `json:"description,omitempty"`
+	Disabled            bool         `json:"disabled,omitempty"`
+	SourcePostureChecks []string     `json:"source_posture_checks,omitempty"`
+	Rules               []PolicyRule `json:"rules"`
+}
+
+// PolicyRule is a rule of a policy
+type PolicyRule struct {
+	Name              string            `json:"name"`
+	Description       string            `json:"description,omitempty"`
+	Disabled          bool              `json:"disabled,omitempty"`
+	Action            string            `json:"action"`
+	Protocol          string            `json:"protocol"`
+	Bidirectional     bool              `json:"bidirectional"`
+	Ports             []string          `json:"ports,omitempty"`
+	PortRanges        []PortRange       `json:"port_ranges,omitempty"`
+	Sources           []string          `json:"sources,omitempty"`
+	SourceLabels      map[string]string `json:"source_labels,omitempty"`
+	Destinations      []string          `json:"destinations,omitempty"`
+	DestinationLabels map[string]string `json:"destination_labels,omitempty"`
+}
+
+// PortRange is a range of ports of a policy rule
+type PortRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Route is a network route identified by its network ID, the routing peer is referenced by its DNS label
+type Route struct {
+	NetworkID           string   `json:"network_id"`
+	Description         string   `json:"description,omitempty"`
+	Disabled            bool     `json:"disabled,omitempty"`
+	Network             string   `json:"network,omitempty"`
+	Domains             []string `json:"domains,omitempty"`
+	KeepRoute           bool     `json:"keep_route,omitempty"`
+	Peer                string   `json:"peer,omitempty"`
+	PeerGroups          []string `json:"peer_groups,omitempty"`
+	Metric              int      `json:"metric,omitempty"`
+	Masquerade          bool     `json:"masquerade,omitempty"`
+	Groups              []string `json:"groups"`
+	AccessControlGroups []string `json:"access_control_groups,omitempty"`
+}
+
+// NameserverGroup is a group of nameservers distributed to the peers of the groups
+type NameserverGroup struct {
+	Name                 string       `json:"name"`
+	Description          string       `json:"description,omitempty"`
+	Disabled             bool         `json:"disabled,omitempty"`
+	Nameservers          []Nameserver `json:"nameservers"`
+	Groups               []string     `json:"groups"`
+	Primary              bool         `json:"primary,omitempty"`
+	Domains              []string     `json:"domains,omitempty"`
+	SearchDomainsEnabled bool         `json:"search_domains_enabled,omitempty"`
+}
+
+// Nameserver is a nameserver of a nameserver group, the type defaults to udp and the port to 53
+type Nameserver struct {
+	IP   string `json:"ip"`
+	Type string `json:"type,omitempty"`
+	Port int    `json:"port,omitempty"`
+}
+
+// Parse parses a spec in the YAML or the JSON format, the unknown fields are rejected
+func Parse(data []byte) (*Spec, error) {
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid spec: %w", err)
+	}
+
+	// the spec is decoded with the JSON field names shared with the API
+	content, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid spec: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+
+	var spec Spec
+	if err = decoder.Decode(&spec); err != nil {
+		return nil, fmt.Errorf("invalid spec: %w", err)
+	}
+
+	if err = spec.validate(); err != nil {
+		return nil, err
+	}
+
+	return &spec, nil
+}
+
+// Marshal returns the spec in the YAML format with the fields in their declaration order
+func Marshal(spec *Spec) ([]byte, error) {
+	content, err := json.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("marshal spec: %w", err)
+	}
+
+	var node yaml.Node
+	if err = yaml.Unmarshal(content, &node); err != nil {
+		return nil, fmt.Errorf("convert spec: %w", err)
+	}
+	resetStyle(&node)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err = encoder.Encode(&node); err != nil {
+		return nil, fmt.Errorf("encode spec: %w", err)
+	}
+	if err = encoder.Close(); err != nil {
+		return nil, fmt.Errorf("encode spec: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// resetStyle drops the JSON flow style of the nodes, so they are written in the block style
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
+
+func (s *Spec) validate() error {
+	if s.Version != SpecVersion {
+		return fmt.Errorf("unsupported spec version %d, the supported version is %d", s.Version, SpecVersion)
+	}
+
+	sections := []struct {
+		kind  Kind
+		names []string
+	}{
+		{KindGroup, names(s.Groups, func(g Group) string { return g.Name })},
+		{KindPostureCheck, names(s.PostureChecks, func(pc PostureCheck) string { return pc.Name })},
+		{KindPolicy, names(s.Policies, func(p Policy) string { return p.Name })},
+		{KindRoute, names(s.Routes, func(r Route) string { return r.NetworkID })},
+		{KindNameserverGroup, names(s.NameserverGroups, func(ns NameserverGroup) string { return ns.Name })},
+	}
+
+	for _, section := range sections {
+		seen := make(map[string]struct{}, len(section.names))
+		for _, name := range section.names {
+			if name == "" {
+				return fmt.Errorf("%s without a name", section.kind)
+			}
+			if _, ok := seen[name]; ok {
+				return fmt.Errorf("%s %s is defined more than once", section.kind, name)
+			}
+			seen[name] = struct{}{}
+		}
+	}
+
+	for _, policy := range s.Policies {
+		if len(policy.Rules) == 0 {
+			return fmt.Errorf("policy %s has no rules", policy.Name)
+		}
+		for _, rule := range policy.Rules {
+			if err := rule.validate(); err != nil {
+				return fmt.Errorf("policy %s rule %s: %w", policy.Name, rule.Name, err)
+			}
+		}
+	}
+
+	for _, r := range s.Routes {
+		if (r.Network == "") == (len(r.Domains) == 0) {
+			return fmt.Errorf("route %s: either network or domains should be set", r.NetworkID)
+		}
+		if (r.Peer == "") == (len(r.PeerGroups) == 0) {
+			return fmt.Errorf("route %s: either peer or peer groups should be set", r.NetworkID)
+		}
+		if r.Metric != 0 && (r.Metric < route.MinMetric || r.Metric > route.MaxMetric) {
+			return fmt.Errorf("route %s: metric should be between %d and %d", r.NetworkID, route.MinMetric, route.MaxMetric)
+		}
+	}
+
+	for _, nsGroup := range s.NameserverGroups {
+		if len(nsGroup.Nameservers) == 0 {
+			return fmt.Errorf("nameserver group %s has no nameservers", nsGroup.Name)
+		}
+		for _, ns := range nsGroup.Nameservers {
+			if _, err := netip.ParseAddr(ns.IP); err != nil {
+				return fmt.Errorf("nameserver group %s: invalid nameserver IP %s", nsGroup.Name, ns.IP)
+			}
+			if ns.Type != "" && nbdns.ToNameServerType(ns.Type) == nbdns.InvalidNameServerType {
+				return fmt.Errorf("nameserver group %s: invalid nameserver type %s", nsGroup.Name, ns.Type)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *PolicyRule) validate() error {
+	switch types.PolicyTrafficActionType(r.Action) {
+	case types.PolicyTrafficActionAccept, types.PolicyTrafficActionDrop:
+	default:
+		return fmt.Errorf("unknown action %q", r.Action)
+	}
+
+	if len(r.Sources) == 0 && len(r.SourceLabels) == 0 {
+		return fmt.Errorf("sources or source labels should be set")
+	}
+	if len(r.Destinations) == 0 && len(r.DestinationLabels) == 0 {
+		return fmt.Errorf("destinations or destination labels should be set")
+	}
+
+	if len(r.Ports) != 0 && len(r.PortRanges) != 0 {
+		return fmt.Errorf("specify either individual ports or port ranges, not both")
+	}
+	for _, p := range r.Ports {
+		if port, err := strconv.Atoi(p); err != nil || port < 1 || port > 65535 {
+			return fmt.Errorf("valid port value is in 1..65535 range")
+		}
+	}
+	for _, portRange := range r.PortRanges {
+		if portRange.Start < 1 || portRange.End > 65535 || portRange.Start > portRange.End {
+			return fmt.Errorf("valid port range is in 1..65535 range")
+		}
+	}
+
+	switch types.PolicyRuleProtocolType(r.Protocol) {
+	case types.PolicyRuleProtocolALL, types.PolicyRuleProtocolICMP:
+		if len(r.Ports) != 0 || len(r.PortRanges) != 0 {
+			return fmt.Errorf("for all or icmp protocol ports are not allowed")
+		}
+		if !r.Bidirectional {
+			return fmt.Errorf("for all or icmp protocol the rule can only be bidirectional")
+		}
+	case types.PolicyRuleProtocolTCP, types.PolicyRuleProtocolUDP:
+	default:
+		return fmt.Errorf("unknown protocol %q", r.Protocol)
+	}
+
+	return nil
+}
+
+// setDefaults sets the default values of the optional fields, so they are compared with the exported objects
+func (s *Spec) setDefaults() error {
+	for i := range s.Routes {
+		r := &s.Routes[i]
+		if r.Metric == 0 {
+			r.Metric = route.MaxMetric
+		}
+		if len(r.Domains) > 0 {
+			domains, err := domain.ValidateDomains(r.Domains)
+			if err != nil {
+				return fmt.Errorf("route %s: invalid domains: %w", r.NetworkID, err)
+			}
+			r.Domains = domains.ToSafeStringList()
+		}
+		if r.Network != "" {
+			_, prefix, err := route.ParseNetwork(r.Network)
+			if err != nil {
+				return fmt.Errorf("route %s: %w", r.NetworkID, err)
+			}
+			r.Network = prefix.String()
+		}
+	}
+
+	for i := range s.NameserverGroups {
+		for j := range s.NameserverGroups[i].Nameservers {
+			ns := &s.NameserverGroups[i].Nameservers[j]
+			if ns.Type == "" {
+				ns.Type = nbdns.UDPNameServerTypeString
+			}
+			if ns.Port == 0 {
+				ns.Port = nbdns.DefaultDNSPort
+			}
+		}
+	}
+
+	return nil
+}
+
+// normalize sorts the unordered lists of the spec, so equal specs are compared and exported the same way
+func (s *Spec) normalize() {
+	for i := range s.Groups {
+		slices.Sort(s.Groups[i].Peers)
+	}
+	for i := range s.Policies {
+		slices.Sort(s.Policies[i].SourcePostureChecks)
+		for j := range s.Policies[i].Rules {
+			rule := &s.Policies[i].Rules[j]
+			slices.Sort(rule.Sources)
+			slices.Sort(rule.Destinations)
+		}
+	}
+	for i := range s.Routes {
+		slices.Sort(s.Routes[i].PeerGroups)
+		slices.Sort(s.Routes[i].Groups)
+		slices.Sort(s.Routes[i].AccessControlGroups)
+	}
+	for i := range s.NameserverGroups {
+		slices.Sort(s.NameserverGroups[i].Groups)
+	}
+
+	slices.SortFunc(s.Groups, func(a, b Group) int { return strings.Compare(a.Name, b.Name) })
+	slices.SortFunc(s.PostureChecks, func(a, b PostureCheck) int { return strings.Compare(a.Name, b.Name) })
+	slices.SortFunc(s.Policies, func(a, b Policy) int { return strings.Compare(a.Name, b.Name) })
+	slices.SortFunc(s.Routes, func(a, b Route) int { return strings.Compare(a.NetworkID, b.NetworkID) })
+	slices.SortFunc(s.NameserverGroups, func(a, b NameserverGroup) int { return strings.Compare(a.Name, b.Name) })
+}
+
+func names[T any](items []T, name func(T) string) []string {
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		result = append(result, name(item))
+	}
+	return result
+}
@@ -0,0 +1,124 @@
+package declarative
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSpec = `
+version: 1
+groups:
+  - name: prod
+    peers: [db-1, app-1]
+  - name: dev
+policies:
+  - name: dev-to-prod
+    rules:
+      - name: postgres
+        action: accept
+        protocol: tcp
+        ports: ["5432"]
+        sources: [dev]
+        destinations: [prod]
+routes:
+  - network_id: office
+    network: 10.0.0.1/16
+    peer_groups: [prod]
+    groups: [dev]
+nameserver_groups:
+  - name: internal
+    nameservers:
+      - ip: 10.0.0.53
+    groups: [dev]
+`
+
+func TestParse(t *testing.T) {
+	spec, err := Parse([]byte(testSpec))
+	require.NoError(t, err)
+
+	require.NoError(t, spec.setDefaults())
+	spec.normalize()
+
+	assert.Nil(t, spec.PostureChecks, "a section left out shouldn't be managed")
+	require.Len(t, spec.Groups, 2)
+	assert.Equal(t, "dev", spec.Groups[0].Name)
+	assert.Nil(t, spec.Groups[0].Peers, "the peers of a group without peers shouldn't be managed")
+	assert.Equal(t, []string{"app-1", "db-1"}, spec.Groups[1].Peers)
+
+	require.Len(t, spec.Routes, 1)
+	assert.Equal(t, "10.0.0.0/16", spec.Routes[0].Network)
+	assert.Equal(t, 9999, spec.Routes[0].Metric)
+
+	require.Len(t, spec.NameserverGroups, 1)
+	assert.Equal(t, Nameserver{IP: "10.0.0.53", Type: "udp", Port: 53}, spec.NameserverGroups[0].Nameservers[0])
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		err  string
+	}{
+		{
+			name: "unsupported version",
+			spec: "version: 2",
+			err:  "unsupported spec version 2",
+		},
+		{
+			name: "unknown field",
+			spec: "version: 1\ngroups:\n  - name: dev\n    members: [db-1]",
+			err:  `unknown field "members"`,
+		},
+		{
+			name: "duplicate name",
+			spec: "version: 1\ngroups:\n  - name: dev\n  - name: dev",
+			err:  "group dev is defined more than once",
+		},
+		{
+			name: "policy without rules",
+			spec: "version: 1\npolicies:\n  - name: dev",
+			err:  "policy dev has no rules",
+		},
+		{
+			name: "ports of all protocol",
+			spec: "version: 1\npolicies:\n  - name: dev\n    rules:\n      - name: all\n        action: accept\n        protocol: all\n" +
+				"        bidirectional: true\n        ports: [\"22\"]\n        sources: [dev]\n        destinations: [dev]",
+			err: "for all or icmp protocol ports are not allowed",
+		},
+		{
+			name: "route with network and domains",
+			spec: "version: 1\nroutes:\n  - network_id: office\n    network: 10.0.0.0/16\n    domains: [example.com]\n    peer: db-1\n    groups: [dev]",
+			err:  "route office: either network or domains should be set",
+		},
+		{
+			name: "invalid nameserver",
+			spec: "version: 1\nnameserver_groups:\n  - name: internal\n    nameservers:\n      - ip: internal\n    groups: [dev]",
+			err:  "nameserver group internal: invalid nameserver IP internal",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.spec))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	spec, err := Parse([]byte(testSpec))
+	require.NoError(t, err)
+	spec.Groups[1].Name = "true"
+	spec.Policies[0].Description = "1"
+
+	content, err := Marshal(spec)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "groups:\n  - name: prod\n", "the spec should be written in the block style")
+
+	parsed, err := Parse(content)
+	require.NoError(t, err, "the strings shouldn't be written as other types")
+	assert.Equal(t, spec, parsed)
+}
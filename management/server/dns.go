@@ -10,6 +10,7 @@ import (
 	nbdns "github.com/netbirdio/netbird/dns"
 	"github.com/netbirdio/netbird/management/proto"
 	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/permissions"
 	"github.com/netbirdio/netbird/management/server/status"
 	"github.com/netbirdio/netbird/management/server/store"
 	"github.com/netbirdio/netbird/management/server/types"
@@ -71,13 +72,36 @@ func (am *DefaultAccountManager) GetDNSSettings(ctx context.Context, accountID s
 		return nil, err
 	}
 
-	if user.IsRegularUser() {
+	if user.IsRegularUser() && !am.hasCustomRolePermission(ctx, accountID, user, permissions.DNS, permissions.Read) {
 		return nil, status.NewAdminPermissionError()
 	}
 
 	return am.Store.GetAccountDNSSettings(ctx, store.LockingStrengthShare, accountID)
 }
 
+// GetDNSZone validates a user role and returns the DNS zone of the account's peers
+func (am *DefaultAccountManager) GetDNSZone(ctx context.Context, accountID string, userID string) (nbdns.CustomZone, error) {
+	user, err := am.Store.GetUserByUserID(ctx, store.LockingStrengthShare, userID)
+	if err != nil {
+		return nbdns.CustomZone{}, err
+	}
+
+	if err := am.permissionsManager.ValidateAccountAccess(ctx, accountID, user, false); err != nil {
+		return nbdns.CustomZone{}, err
+	}
+
+	if user.IsRegularUser() && !am.hasCustomRolePermission(ctx, accountID, user, permissions.DNS, permissions.Read) {
+		return nbdns.CustomZone{}, status.NewAdminPermissionError()
+	}
+
+	account, err := am.Store.GetAccount(ctx, accountID)
+	if err != nil {
+		return nbdns.CustomZone{}, err
+	}
+
+	return account.GetPeersCustomZone(ctx, am.dnsDomain), nil
+}
+
 // SaveDNSSettings validates a user role and updates the account's DNS settings
 func (am *DefaultAccountManager) SaveDNSSettings(ctx context.Context, accountID string, userID string, dnsSettingsToSave *types.DNSSettings) error {
 	if dnsSettingsToSave == nil {
@@ -93,7 +117,7 @@ func (am *DefaultAccountManager) SaveDNSSettings(ctx context.Context, accountID
 		return err
 	}
 
-	if !user.HasAdminPower() {
+	if !user.HasAdminPower() && !am.hasCustomRolePermission(ctx, accountID, user, permissions.DNS, permissions.Write) {
 		return status.NewAdminPermissionError()
 	}
 
@@ -232,6 +256,10 @@ func toProtocolDNSConfig(update nbdns.Config, cache *DNSConfigCache) *proto.DNSC
 
 	for _, nsGroup := range update.NameServerGroups {
 		cacheKey := nsGroup.ID
+		// the forwarder peer gets the group with the original nameservers, the other peers the forwarded one
+		if nsGroup.ForwarderPeer != "" {
+			cacheKey += ":forwarder"
+		}
 		if cachedGroup, exists := cache.GetNameServerGroup(cacheKey); exists {
 			protoUpdate.NameServerGroups = append(protoUpdate.NameServerGroups, cachedGroup)
 		} else {
@@ -268,6 +296,8 @@ func convertToProtoNameServerGroup(nsGroup *nbdns.NameServerGroup) *proto.NameSe
 		Primary:              nsGroup.Primary,
 		Domains:              nsGroup.Domains,
 		SearchDomainsEnabled: nsGroup.SearchDomainsEnabled,
+		RoutedAnswers:        string(nsGroup.RoutedAnswers),
+		Forwarder:            nsGroup.ForwarderPeer != "",
 		NameServers:          make([]*proto.NameServer, 0, len(nsGroup.NameServers)),
 	}
 	for _, ns := range nsGroup.NameServers {
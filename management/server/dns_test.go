@@ -548,7 +548,7 @@ func TestDNSAccountPeersUpdate(t *testing.T) {
 				Port:   dns.DefaultDNSPort,
 			}},
 			[]string{"groupB"},
-			true, []string{}, true, userID, false,
+			true, []string{}, true, userID, false, nbdns.RoutedAnswersDisabled, "",
 		)
 		assert.NoError(t, err)
 
@@ -581,7 +581,7 @@ func TestDNSAccountPeersUpdate(t *testing.T) {
 				Port:   dns.DefaultDNSPort,
 			}},
 			[]string{"groupA"},
-			true, []string{}, true, userID, false,
+			true, []string{}, true, userID, false, nbdns.RoutedAnswersDisabled, "",
 		)
 		assert.NoError(t, err)
 
@@ -11,6 +11,7 @@ import (
 	"github.com/netbirdio/netbird/management/server/activity"
 	nbpeer "github.com/netbirdio/netbird/management/server/peer"
 	"github.com/netbirdio/netbird/management/server/store"
+	"github.com/netbirdio/netbird/management/server/types"
 )
 
 const (
@@ -24,6 +25,7 @@ var (
 type ephemeralPeer struct {
 	id        string
 	accountID string
+	lifetime  time.Duration
 	deadline  time.Time
 	next      *ephemeralPeer
 }
@@ -31,8 +33,9 @@ type ephemeralPeer struct {
 // todo: consider to remove peer from ephemeral list when the peer has been deleted via API. If we do not do it
 // in worst case we will get invalid error message in this manager.
 
-// EphemeralManager keep a list of ephemeral peers. After ephemeralLifeTime inactivity the peer will be deleted
-// automatically. Inactivity means the peer disconnected from the Management server.
+// EphemeralManager keep a list of ephemeral peers ordered by deadline. After ephemeralLifeTime inactivity the peer
+// will be deleted automatically. Inactivity means the peer disconnected from the Management server.
+// The ephemeral policies of the groups of a peer override the inactivity period and can apply to non-ephemeral peers.
 type EphemeralManager struct {
 	store          store.Store
 	accountManager nbAccount.Manager
@@ -60,9 +63,7 @@ func (e *EphemeralManager) LoadInitialPeers(ctx context.Context) {
 
 	e.loadEphemeralPeers(ctx)
 	if e.headPeer != nil {
-		e.timer = time.AfterFunc(ephemeralLifeTime, func() {
-			e.cleanup(ctx)
-		})
+		e.scheduleCleanup(ctx)
 	}
 }
 
@@ -79,15 +80,16 @@ func (e *EphemeralManager) Stop() {
 // OnPeerConnected remove the peer from the linked list of ephemeral peers. Because it has been called when the peer
 // is active the manager will not delete it while it is active.
 func (e *EphemeralManager) OnPeerConnected(ctx context.Context, peer *nbpeer.Peer) {
-	if !peer.Ephemeral {
+	e.peersLock.Lock()
+	defer e.peersLock.Unlock()
+
+	// non-ephemeral peers can be on the list due to the ephemeral policies of their groups
+	if !peer.Ephemeral && !e.isPeerOnList(peer.ID) {
 		return
 	}
 
 	log.WithContext(ctx).Tracef("remove peer from ephemeral list: %s", peer.ID)
 
-	e.peersLock.Lock()
-	defer e.peersLock.Unlock()
-
 	e.removePeer(peer.ID)
 
 	// stop the unnecessary timer
@@ -98,9 +100,10 @@ func (e *EphemeralManager) OnPeerConnected(ctx context.Context, peer *nbpeer.Pee
 }
 
 // OnPeerDisconnected add the peer to the linked list of ephemeral peers. Because of the peer
-// is inactive it will be deleted after the ephemeralLifeTime period.
+// is inactive it will be deleted after the ephemeralLifeTime period or the period of the ephemeral policies of its groups.
 func (e *EphemeralManager) OnPeerDisconnected(ctx context.Context, peer *nbpeer.Peer) {
-	if !peer.Ephemeral {
+	lifetime, ok := e.getPeerLifetime(ctx, peer)
+	if !ok {
 		return
 	}
 
@@ -113,14 +116,23 @@ func (e *EphemeralManager) OnPeerDisconnected(ctx context.Context, peer *nbpeer.
 		return
 	}
 
-	e.addPeer(peer.AccountID, peer.ID, newDeadLine())
-	if e.timer == nil {
-		e.timer = time.AfterFunc(e.headPeer.deadline.Sub(timeNow()), func() {
-			e.cleanup(ctx)
-		})
+	e.addPeer(peer.AccountID, peer.ID, lifetime, timeNow().Add(lifetime))
+	if e.timer == nil || e.headPeer.id == peer.ID {
+		e.scheduleCleanup(ctx)
 	}
 }
 
+// getPeerLifetime returns the inactivity period after which the peer is deleted according to the ephemeral policies of
+// its groups, and false if the peer is never deleted
+func (e *EphemeralManager) getPeerLifetime(ctx context.Context, peer *nbpeer.Peer) (time.Duration, bool) {
+	groups, err := e.store.GetPeerGroups(ctx, store.LockingStrengthShare, peer.AccountID, peer.ID)
+	if err != nil {
+		log.WithContext(ctx).Debugf("failed to get groups of peer %s: %s", peer.ID, err)
+	}
+
+	return types.GetEphemeralLifetime(peer, groups, ephemeralLifeTime)
+}
+
 func (e *EphemeralManager) loadEphemeralPeers(ctx context.Context) {
 	peers, err := e.store.GetAllEphemeralPeers(ctx, store.LockingStrengthShare)
 	if err != nil {
@@ -128,12 +140,52 @@ func (e *EphemeralManager) loadEphemeralPeers(ctx context.Context) {
 		return
 	}
 
-	t := newDeadLine()
+	peers = append(peers, e.loadPeersWithEphemeralPolicy(ctx)...)
+
+	var loaded int
 	for _, p := range peers {
-		e.addPeer(p.AccountID, p.ID, t)
+		lifetime, ok := e.getPeerLifetime(ctx, p)
+		if !ok {
+			continue
+		}
+		e.addPeer(p.AccountID, p.ID, lifetime, loadedPeerDeadline(p, lifetime))
+		loaded++
 	}
 
-	log.WithContext(ctx).Debugf("loaded ephemeral peer(s): %d", len(peers))
+	log.WithContext(ctx).Debugf("loaded ephemeral peer(s): %d", loaded)
+}
+
+// loadPeersWithEphemeralPolicy loads the non-ephemeral peers of the groups whose ephemeral policy applies to them
+func (e *EphemeralManager) loadPeersWithEphemeralPolicy(ctx context.Context) []*nbpeer.Peer {
+	groups, err := e.store.GetAllGroupsWithEphemeralPolicy(ctx, store.LockingStrengthShare)
+	if err != nil {
+		log.WithContext(ctx).Debugf("failed to load groups with ephemeral policy: %s", err)
+		return nil
+	}
+
+	var peers []*nbpeer.Peer
+	loadedPeers := make(map[string]struct{})
+	for _, group := range groups {
+		if !group.EphemeralPolicy.IncludeNonEphemeral || len(group.Peers) == 0 {
+			continue
+		}
+
+		groupPeers, err := e.store.GetPeersByIDs(ctx, store.LockingStrengthShare, group.AccountID, group.Peers)
+		if err != nil {
+			log.WithContext(ctx).Debugf("failed to load peers of group %s: %s", group.ID, err)
+			continue
+		}
+
+		for _, p := range groupPeers {
+			if _, ok := loadedPeers[p.ID]; ok || p.Ephemeral {
+				continue
+			}
+			loadedPeers[p.ID] = struct{}{}
+			peers = append(peers, p)
+		}
+	}
+
+	return peers
 }
 
 func (e *EphemeralManager) cleanup(ctx context.Context) {
@@ -155,9 +207,7 @@ func (e *EphemeralManager) cleanup(ctx context.Context) {
 	}
 
 	if e.headPeer != nil {
-		e.timer = time.AfterFunc(e.headPeer.deadline.Sub(timeNow()), func() {
-			e.cleanup(ctx)
-		})
+		e.scheduleCleanup(ctx)
 	} else {
 		e.timer = nil
 	}
@@ -169,24 +219,55 @@ func (e *EphemeralManager) cleanup(ctx context.Context) {
 		err := e.accountManager.DeletePeer(ctx, p.accountID, id, activity.SystemInitiator)
 		if err != nil {
 			log.WithContext(ctx).Errorf("failed to delete ephemeral peer: %s", err)
+			continue
 		}
+
+		meta := map[string]any{"cleanup_after": p.lifetime.String()}
+		e.accountManager.StoreEvent(ctx, activity.SystemInitiator, id, p.accountID, activity.PeerCleanedUp, meta)
+	}
+}
+
+// scheduleCleanup (re)schedules the cleanup procedure to the deadline of the head of the list
+func (e *EphemeralManager) scheduleCleanup(ctx context.Context) {
+	if e.timer != nil {
+		e.timer.Stop()
 	}
+	e.timer = time.AfterFunc(e.headPeer.deadline.Sub(timeNow()), func() {
+		e.cleanup(ctx)
+	})
 }
 
-func (e *EphemeralManager) addPeer(accountID string, peerID string, deadline time.Time) {
+// addPeer inserts the peer into the list keeping it ordered by deadline
+func (e *EphemeralManager) addPeer(accountID string, peerID string, lifetime time.Duration, deadline time.Time) {
 	ep := &ephemeralPeer{
 		id:        peerID,
 		accountID: accountID,
+		lifetime:  lifetime,
 		deadline:  deadline,
 	}
 
-	if e.headPeer == nil {
+	if e.headPeer == nil || deadline.Before(e.headPeer.deadline) {
+		ep.next = e.headPeer
 		e.headPeer = ep
+		if e.tailPeer == nil {
+			e.tailPeer = ep
+		}
+		return
 	}
-	if e.tailPeer != nil {
+
+	// most of the peers share the same lifetime, so they are appended to the tail
+	if !deadline.Before(e.tailPeer.deadline) {
 		e.tailPeer.next = ep
+		e.tailPeer = ep
+		return
 	}
-	e.tailPeer = ep
+
+	p := e.headPeer
+	for p.next != nil && !deadline.Before(p.next.deadline) {
+		p = p.next
+	}
+	ep.next = p.next
+	p.next = ep
 }
 
 func (e *EphemeralManager) removePeer(id string) {
@@ -223,6 +304,16 @@ func (e *EphemeralManager) isPeerOnList(id string) bool {
 	return false
 }
 
-func newDeadLine() time.Time {
-	return timeNow().Add(ephemeralLifeTime)
+// loadedPeerDeadline returns the deadline of a peer loaded from the database. The peer is deleted once its lifetime
+// elapsed since it was last seen, but not before the ephemeralLifeTime period to let it reconnect after a restart.
+func loadedPeerDeadline(peer *nbpeer.Peer, lifetime time.Duration) time.Time {
+	deadline := timeNow().Add(ephemeralLifeTime)
+	if peer.Status == nil || peer.Status.LastSeen.IsZero() {
+		return deadline
+	}
+
+	if lastSeenDeadline := peer.Status.LastSeen.Add(lifetime); lastSeenDeadline.After(deadline) {
+		return lastSeenDeadline
+	}
+	return deadline
 }
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"slices"
+
+	"github.com/netbirdio/netbird/management/server/permissions"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
+	"github.com/netbirdio/netbird/management/server/types"
+)
+
+// GetScheduledPeerDeletions returns the offline peers of the account that are going to be deleted by the ephemeral
+// peers cleanup, ordered by deletion time. The deletion time is estimated from the last time the peers were seen.
+func (am *DefaultAccountManager) GetScheduledPeerDeletions(ctx context.Context, accountID, userID string) ([]*types.ScheduledPeerDeletion, error) {
+	allowed, err := am.permissionsManager.ValidateUserPermissions(ctx, accountID, userID, permissions.Peers, permissions.Read)
+	if err != nil {
+		return nil, status.NewPermissionValidationError(err)
+	}
+	if !allowed {
+		return nil, status.NewPermissionDeniedError()
+	}
+
+	peers, err := am.Store.GetAccountPeers(ctx, store.LockingStrengthShare, accountID, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := am.Store.GetAccountGroups(ctx, store.LockingStrengthShare, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	peerGroups := make(map[string][]*types.Group)
+	for _, group := range groups {
+		if group.EphemeralPolicy == nil {
+			continue
+		}
+		for _, peerID := range group.Peers {
+			peerGroups[peerID] = append(peerGroups[peerID], group)
+		}
+	}
+
+	deletions := make([]*types.ScheduledPeerDeletion, 0)
+	for _, peer := range peers {
+		if peer.Status == nil || peer.Status.Connected {
+			continue
+		}
+
+		lifetime, ok := types.GetEphemeralLifetime(peer, peerGroups[peer.ID], ephemeralLifeTime)
+		if !ok {
+			continue
+		}
+
+		deletions = append(deletions, &types.ScheduledPeerDeletion{
+			Peer:         peer,
+			CleanupAfter: lifetime,
+			ScheduledAt:  peer.Status.LastSeen.Add(lifetime),
+		})
+	}
+
+	slices.SortFunc(deletions, func(a, b *types.ScheduledPeerDeletion) int {
+		return a.ScheduledAt.Compare(b.ScheduledAt)
+	})
+
+	return deletions, nil
+}
@@ -3,10 +3,12 @@ package server
 import (
 	"context"
 	"fmt"
+	"slices"
 	"testing"
 	"time"
 
 	nbAccount "github.com/netbirdio/netbird/management/server/account"
+	"github.com/netbirdio/netbird/management/server/activity"
 	nbpeer "github.com/netbirdio/netbird/management/server/peer"
 	"github.com/netbirdio/netbird/management/server/store"
 	"github.com/netbirdio/netbird/management/server/types"
@@ -27,6 +29,36 @@ func (s *MockStore) GetAllEphemeralPeers(_ context.Context, _ store.LockingStren
 	return peers, nil
 }
 
+func (s *MockStore) GetAllGroupsWithEphemeralPolicy(_ context.Context, _ store.LockingStrength) ([]*types.Group, error) {
+	var groups []*types.Group
+	for _, g := range s.account.Groups {
+		if g.EphemeralPolicy != nil {
+			groups = append(groups, g)
+		}
+	}
+	return groups, nil
+}
+
+func (s *MockStore) GetPeerGroups(_ context.Context, _ store.LockingStrength, _ string, peerID string) ([]*types.Group, error) {
+	var groups []*types.Group
+	for _, g := range s.account.Groups {
+		if slices.Contains(g.Peers, peerID) {
+			groups = append(groups, g)
+		}
+	}
+	return groups, nil
+}
+
+func (s *MockStore) GetPeersByIDs(_ context.Context, _ store.LockingStrength, _ string, peerIDs []string) (map[string]*nbpeer.Peer, error) {
+	peers := make(map[string]*nbpeer.Peer)
+	for _, id := range peerIDs {
+		if p, ok := s.account.Peers[id]; ok {
+			peers[id] = p
+		}
+	}
+	return peers, nil
+}
+
 type MocAccountManager struct {
 	nbAccount.Manager
 	store *MockStore
@@ -41,6 +73,9 @@ func (a MocAccountManager) GetStore() store.Store {
 	return a.store
 }
 
+func (a MocAccountManager) StoreEvent(_ context.Context, _, _, _ string, _ activity.ActivityDescriber, _ map[string]any) {
+}
+
 func TestNewManager(t *testing.T) {
 	startTime := time.Now()
 	timeNow = func() time.Time {
@@ -126,6 +161,64 @@ func TestNewManagerPeerDisconnected(t *testing.T) {
 	}
 }
 
+func TestNewManagerGroupEphemeralPolicy(t *testing.T) {
+	startTime := time.Now()
+	timeNow = func() time.Time {
+		return startTime
+	}
+
+	store := &MockStore{}
+	am := MocAccountManager{
+		store: store,
+	}
+
+	seedPeers(store, 2, 2)
+	store.account.Groups["ci"] = &types.Group{
+		ID:              "ci",
+		Peers:           []string{"ephemeral_peer_0"},
+		EphemeralPolicy: &types.EphemeralPolicy{CleanupAfter: time.Minute},
+	}
+	store.account.Groups["kiosk"] = &types.Group{
+		ID:              "kiosk",
+		Peers:           []string{"peer_0"},
+		EphemeralPolicy: &types.EphemeralPolicy{CleanupAfter: 30 * 24 * time.Hour, IncludeNonEphemeral: true},
+	}
+
+	mgr := NewEphemeralManager(store, am)
+	mgr.loadEphemeralPeers(context.Background())
+	if !mgr.isPeerOnList("peer_0") {
+		t.Errorf("non-ephemeral peer of a group with ephemeral policy should be loaded")
+	}
+
+	for _, v := range store.account.Peers {
+		mgr.OnPeerConnected(context.Background(), v)
+	}
+	for _, v := range store.account.Peers {
+		mgr.OnPeerDisconnected(context.Background(), v)
+	}
+	if mgr.isPeerOnList("peer_1") {
+		t.Errorf("non-ephemeral peer without ephemeral policy should not be on the list")
+	}
+
+	startTime = startTime.Add(time.Minute + 1)
+	mgr.cleanup(context.Background())
+	if _, ok := store.account.Peers["ephemeral_peer_0"]; ok {
+		t.Errorf("ephemeral peer should be deleted after the cleanup period of its group")
+	}
+	if len(store.account.Peers) != 3 {
+		t.Errorf("failed to cleanup ephemeral peers, expected: %d, result: %d", 3, len(store.account.Peers))
+	}
+
+	startTime = startTime.Add(30 * 24 * time.Hour)
+	mgr.cleanup(context.Background())
+	if _, ok := store.account.Peers["peer_0"]; ok {
+		t.Errorf("non-ephemeral peer should be deleted after the cleanup period of its group")
+	}
+	if _, ok := store.account.Peers["peer_1"]; !ok {
+		t.Errorf("non-ephemeral peer without ephemeral policy should not be deleted")
+	}
+}
+
 func seedPeers(store *MockStore, numberOfPeers int, numberOfEphemeralPeers int) {
 	store.account = newAccountWithId(context.Background(), "my account", "", "")
 
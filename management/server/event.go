@@ -3,12 +3,15 @@ package server
 import (
 	"context"
 	"fmt"
+	"maps"
 	"os"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 
 	"github.com/netbirdio/netbird/management/server/activity"
+	nbcontext "github.com/netbirdio/netbird/management/server/context"
+	"github.com/netbirdio/netbird/management/server/permissions"
 	"github.com/netbirdio/netbird/management/server/status"
 	"github.com/netbirdio/netbird/management/server/store"
 	"github.com/netbirdio/netbird/management/server/types"
@@ -30,7 +33,7 @@ func (am *DefaultAccountManager) GetEvents(ctx context.Context, accountID, userI
 		return nil, err
 	}
 
-	if !(user.HasAdminPower() || user.IsServiceUser) {
+	if !(user.HasAdminPower() || user.IsServiceUser) && !am.hasCustomRolePermission(ctx, accountID, user, permissions.Events, permissions.Read) {
 		return nil, status.Errorf(status.PermissionDenied, "only users with admin power can view events")
 	}
 
@@ -66,21 +69,43 @@ func (am *DefaultAccountManager) GetEvents(ctx context.Context, accountID, userI
 
 func (am *DefaultAccountManager) StoreEvent(ctx context.Context, initiatorID, targetID, accountID string, activityID activity.ActivityDescriber, meta map[string]any) {
 	if isEnabled() {
-		go func() {
-			_, err := am.eventStore.Save(ctx, &activity.Event{
-				Timestamp:   time.Now().UTC(),
-				Activity:    activityID,
-				InitiatorID: initiatorID,
-				TargetID:    targetID,
-				AccountID:   accountID,
-				Meta:        meta,
-			})
-			if err != nil {
-				// todo add metric
-				log.WithContext(ctx).Errorf("received an error while storing an activity event, error: %s", err)
-			}
-		}()
+		meta = withTokenAttribution(ctx, initiatorID, meta)
+
+		// the events of a batch transaction are stored once its changes are committed
+		store.RunAfterCommit(ctx, func() {
+			go func() {
+				_, err := am.eventStore.Save(ctx, &activity.Event{
+					Timestamp:   time.Now().UTC(),
+					Activity:    activityID,
+					InitiatorID: initiatorID,
+					TargetID:    targetID,
+					AccountID:   accountID,
+					Meta:        meta,
+				})
+				if err != nil {
+					// todo add metric
+					log.WithContext(ctx).Errorf("received an error while storing an activity event, error: %s", err)
+				}
+			}()
+		})
+	}
+}
+
+// withTokenAttribution adds the personal access token the initiator has authenticated with to the event meta,
+// so changes made by service users can be traced back to the pipeline holding the token
+func withTokenAttribution(ctx context.Context, initiatorID string, meta map[string]any) map[string]any {
+	userAuth, err := nbcontext.GetUserAuthFromContext(ctx)
+	if err != nil || !userAuth.IsPAT || userAuth.PATID == "" || userAuth.UserId != initiatorID {
+		return meta
 	}
+
+	attributed := maps.Clone(meta)
+	if attributed == nil {
+		attributed = make(map[string]any)
+	}
+	attributed["token_id"] = userAuth.PATID
+
+	return attributed
 }
 
 type eventUserInfo struct {
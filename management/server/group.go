@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
 	"slices"
+	"strings"
 
 	"github.com/rs/xid"
 	log "github.com/sirupsen/logrus"
@@ -12,6 +14,8 @@ import (
 	nbdns "github.com/netbirdio/netbird/dns"
 	"github.com/netbirdio/netbird/management/server/activity"
 	routerTypes "github.com/netbirdio/netbird/management/server/networks/routers/types"
+	"github.com/netbirdio/netbird/management/server/permissions"
+	roleTypes "github.com/netbirdio/netbird/management/server/roles/types"
 	"github.com/netbirdio/netbird/management/server/status"
 	"github.com/netbirdio/netbird/management/server/store"
 	"github.com/netbirdio/netbird/management/server/types"
@@ -39,7 +43,7 @@ func (am *DefaultAccountManager) CheckGroupPermissions(ctx context.Context, acco
 		return err
 	}
 
-	if user.IsRegularUser() {
+	if user.IsRegularUser() && !am.hasCustomRolePermission(ctx, accountID, user, permissions.Groups, permissions.Read) {
 		return status.NewAdminPermissionError()
 	}
 
@@ -62,6 +66,14 @@ func (am *DefaultAccountManager) GetAllGroups(ctx context.Context, accountID, us
 	return am.Store.GetAccountGroups(ctx, store.LockingStrengthShare, accountID)
 }
 
+// ListGroups returns a page of the account groups matching the filter and the cursor of the next page
+func (am *DefaultAccountManager) ListGroups(ctx context.Context, accountID, userID string, filter store.GroupFilter, opts store.ListOptions) ([]*types.Group, string, error) {
+	if err := am.CheckGroupPermissions(ctx, accountID, userID); err != nil {
+		return nil, "", err
+	}
+	return am.Store.ListAccountGroups(ctx, store.LockingStrengthShare, accountID, filter, opts)
+}
+
 // GetGroupByName filters all groups in an account by name and returns the one with the most peers
 func (am *DefaultAccountManager) GetGroupByName(ctx context.Context, groupName, accountID string) (*types.Group, error) {
 	return am.Store.GetGroupByName(ctx, store.LockingStrengthShare, accountID, groupName)
@@ -87,13 +99,16 @@ func (am *DefaultAccountManager) SaveGroups(ctx context.Context, accountID, user
 		return err
 	}
 
-	if user.IsRegularUser() {
+	if user.IsRegularUser() && !am.hasCustomRolePermission(ctx, accountID, user, permissions.Groups, permissions.Write) {
 		return status.NewAdminPermissionError()
 	}
 
 	var eventsToStore []func()
 	var groupsToSave []*types.Group
 	var updateAccountPeers bool
+	var sessionPoliciesChanged bool
+	var connectionTuningChanged bool
+	var portPolicyChanged bool
 
 	err = am.Store.ExecuteInTransaction(ctx, func(transaction store.Store) error {
 		groupIDs := make([]string, 0, len(groups))
@@ -102,12 +117,34 @@ func (am *DefaultAccountManager) SaveGroups(ctx context.Context, accountID, user
 				return err
 			}
 
+			if newGroup.HasMembershipRule() {
+				if err = applyGroupMembershipRule(ctx, transaction, accountID, newGroup); err != nil {
+					return err
+				}
+			}
+
 			newGroup.AccountID = accountID
 			groupsToSave = append(groupsToSave, newGroup)
 			groupIDs = append(groupIDs, newGroup.ID)
 
 			events := am.prepareGroupEvents(ctx, transaction, accountID, userID, newGroup)
 			eventsToStore = append(eventsToStore, events...)
+
+			if isSessionPolicyChanged(ctx, transaction, accountID, newGroup) {
+				sessionPoliciesChanged = true
+			}
+
+			if isConnectionTuningChanged(ctx, transaction, accountID, newGroup) {
+				connectionTuningChanged = true
+			}
+
+			if isPortPolicyChanged(ctx, transaction, accountID, newGroup) {
+				portPolicyChanged = true
+			}
+		}
+
+		if err = validateNestedGroups(ctx, transaction, accountID, groupsToSave); err != nil {
+			return err
 		}
 
 		updateAccountPeers, err = areGroupChangesAffectPeers(ctx, transaction, accountID, groupIDs)
@@ -129,7 +166,12 @@ func (am *DefaultAccountManager) SaveGroups(ctx context.Context, accountID, user
 		storeEvent()
 	}
 
-	if updateAccountPeers {
+	if sessionPoliciesChanged {
+		am.checkAndSchedulePeerLoginExpiration(ctx, accountID)
+		am.checkAndSchedulePeerInactivityExpiration(ctx, accountID)
+	}
+
+	if updateAccountPeers || sessionPoliciesChanged || connectionTuningChanged || portPolicyChanged {
 		am.UpdateAccountPeers(ctx, accountID)
 	}
 
@@ -219,7 +261,7 @@ func (am *DefaultAccountManager) DeleteGroups(ctx context.Context, accountID, us
 		return err
 	}
 
-	if user.IsRegularUser() {
+	if user.IsRegularUser() && !am.hasCustomRolePermission(ctx, accountID, user, permissions.Groups, permissions.Write) {
 		return status.NewAdminPermissionError()
 	}
 
@@ -455,6 +497,77 @@ func validateNewGroup(ctx context.Context, transaction store.Store, accountID st
 		}
 	}
 
+	if newGroup.SessionPolicy != nil {
+		if err := newGroup.SessionPolicy.Validate(); err != nil {
+			return status.Errorf(status.InvalidArgument, "invalid session policy: %s", err)
+		}
+	}
+
+	if newGroup.EphemeralPolicy != nil {
+		if err := newGroup.EphemeralPolicy.Validate(); err != nil {
+			return status.Errorf(status.InvalidArgument, "invalid ephemeral policy: %s", err)
+		}
+	}
+
+	if newGroup.ConnectionTuning != nil {
+		if err := newGroup.ConnectionTuning.Validate(); err != nil {
+			return status.Errorf(status.InvalidArgument, "invalid connection tuning: %s", err)
+		}
+	}
+
+	if newGroup.PortPolicy != nil {
+		if err := newGroup.PortPolicy.Validate(); err != nil {
+			return status.Errorf(status.InvalidArgument, "invalid port policy: %s", err)
+		}
+	}
+
+	if newGroup.ExitNodeQuota != nil {
+		if err := newGroup.ExitNodeQuota.Validate(); err != nil {
+			return status.Errorf(status.InvalidArgument, "invalid exit node quota: %s", err)
+		}
+	}
+
+	return nil
+}
+
+// validateNestedGroups validates that the nested groups of the new groups exist and don't form a cycle.
+func validateNestedGroups(ctx context.Context, transaction store.Store, accountID string, newGroups []*types.Group) error {
+	if !slices.ContainsFunc(newGroups, (*types.Group).HasNestedGroups) {
+		return nil
+	}
+
+	accountGroups, err := transaction.GetAccountGroups(ctx, store.LockingStrengthShare, accountID)
+	if err != nil {
+		return err
+	}
+
+	groups := make(map[string]*types.Group, len(accountGroups)+len(newGroups))
+	for _, group := range accountGroups {
+		groups[group.ID] = group
+	}
+	for _, group := range newGroups {
+		groups[group.ID] = group
+	}
+
+	for _, newGroup := range newGroups {
+		for _, nestedID := range newGroup.NestedGroups {
+			if nestedID == newGroup.ID {
+				return status.Errorf(status.InvalidArgument, "group %s can't contain itself", newGroup.Name)
+			}
+			if _, ok := groups[nestedID]; !ok {
+				return status.Errorf(status.InvalidArgument, "group with ID \"%s\" not found", nestedID)
+			}
+		}
+
+		if cycle := types.FindGroupCycle(groups, newGroup.ID); cycle != nil {
+			names := make([]string, 0, len(cycle))
+			for _, id := range cycle {
+				names = append(names, groups[id].Name)
+			}
+			return status.Errorf(status.InvalidArgument, "nested groups form a cycle: %s", strings.Join(names, " -> "))
+		}
+	}
+
 	return nil
 }
 
@@ -502,6 +615,14 @@ func validateDeleteGroup(ctx context.Context, transaction store.Store, group *ty
 		return &GroupLinkError{"network router", linkedRouter.ID}
 	}
 
+	if isLinked, linkedRole := isGroupLinkedToRole(ctx, transaction, group.AccountID, group.ID); isLinked {
+		return &GroupLinkError{"role", linkedRole.Name}
+	}
+
+	if isLinked, parentGroup := isGroupNestedInGroup(ctx, transaction, group.AccountID, group.ID); isLinked {
+		return &GroupLinkError{"group", parentGroup.Name}
+	}
+
 	return checkGroupLinkedToSettings(ctx, transaction, group)
 }
 
@@ -633,6 +754,39 @@ func isGroupLinkedToNetworkRouter(ctx context.Context, transaction store.Store,
 	return false, nil
 }
 
+// isGroupLinkedToRole checks if a group is linked to any custom role in the account.
+func isGroupLinkedToRole(ctx context.Context, transaction store.Store, accountID string, groupID string) (bool, *roleTypes.Role) {
+	roles, err := transaction.GetAccountRoles(ctx, store.LockingStrengthShare, accountID)
+	if err != nil {
+		log.WithContext(ctx).Errorf("error retrieving roles while checking group linkage: %v", err)
+		return false, nil
+	}
+
+	for _, role := range roles {
+		if slices.Contains(role.Groups, groupID) {
+			return true, role
+		}
+	}
+	return false, nil
+}
+
+// isGroupNestedInGroup checks if a group is nested in another group.
+func isGroupNestedInGroup(ctx context.Context, transaction store.Store, accountID string, groupID string) (bool, *types.Group) {
+	groups, err := transaction.GetAccountGroups(ctx, store.LockingStrengthShare, accountID)
+	if err != nil {
+		log.WithContext(ctx).Errorf("error retrieving groups while checking group linkage: %v", err)
+		return false, nil
+	}
+
+	for _, group := range groups {
+		if slices.Contains(group.NestedGroups, groupID) {
+			return true, group
+		}
+	}
+
+	return false, nil
+}
+
 // areGroupChangesAffectPeers checks if any changes to the specified groups will affect peers.
 func areGroupChangesAffectPeers(ctx context.Context, transaction store.Store, accountID string, groupIDs []string) (bool, error) {
 	if len(groupIDs) == 0 {
@@ -644,6 +798,18 @@ func areGroupChangesAffectPeers(ctx context.Context, transaction store.Store, ac
 		return false, err
 	}
 
+	accountGroups, err := transaction.GetAccountGroups(ctx, store.LockingStrengthShare, accountID)
+	if err != nil {
+		return false, err
+	}
+
+	// the changes of a nested group affect the peers of the groups containing it
+	groupsMap := make(map[string]*types.Group, len(accountGroups))
+	for _, group := range accountGroups {
+		groupsMap[group.ID] = group
+	}
+	groupIDs = append(slices.Clone(groupIDs), types.GetParentGroupIDs(groupsMap, groupIDs)...)
+
 	for _, groupID := range groupIDs {
 		if slices.Contains(dnsSettings.DisabledManagementGroups, groupID) {
 			return true, nil
@@ -689,3 +855,23 @@ func anyGroupHasPeersOrResources(ctx context.Context, transaction store.Store, a
 
 	return false, nil
 }
+
+// isConnectionTuningChanged checks if the connection tuning of the group is different from the stored one.
+func isConnectionTuningChanged(ctx context.Context, transaction store.Store, accountID string, group *types.Group) bool {
+	oldGroup, err := transaction.GetGroupByID(ctx, store.LockingStrengthShare, accountID, group.ID)
+	if err != nil {
+		return group.ConnectionTuning != nil
+	}
+
+	return !reflect.DeepEqual(oldGroup.ConnectionTuning, group.ConnectionTuning)
+}
+
+// isPortPolicyChanged checks if the port policy of the group is different from the stored one.
+func isPortPolicyChanged(ctx context.Context, transaction store.Store, accountID string, group *types.Group) bool {
+	oldGroup, err := transaction.GetGroupByID(ctx, store.LockingStrengthShare, accountID, group.ID)
+	if err != nil {
+		return group.PortPolicy != nil
+	}
+
+	return !reflect.DeepEqual(oldGroup.PortPolicy, group.PortPolicy)
+}
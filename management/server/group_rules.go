@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"slices"
+
+	"github.com/netbirdio/netbird/management/server/activity"
+	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+	"github.com/netbirdio/netbird/management/server/posture"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
+	"github.com/netbirdio/netbird/management/server/types"
+)
+
+// applyGroupMembershipRules adds the peer to the groups with a membership rule it matches and removes it from the
+// groups with a rule it doesn't match anymore. It returns the events to store and whether the groups of the peer changed.
+func (am *DefaultAccountManager) applyGroupMembershipRules(ctx context.Context, transaction store.Store, accountID string, peer *nbpeer.Peer) ([]func(), bool, error) {
+	groups, err := transaction.GetAccountGroups(ctx, store.LockingStrengthUpdate, accountID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	ruleGroups := make([]*types.Group, 0)
+	for _, group := range groups {
+		if group.HasMembershipRule() {
+			ruleGroups = append(ruleGroups, group)
+		}
+	}
+	if len(ruleGroups) == 0 {
+		return nil, false, nil
+	}
+
+	postureChecks, err := getMembershipRulesPostureChecks(ctx, transaction, accountID, ruleGroups)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var eventsToStore []func()
+	var changedGroups []*types.Group
+	for _, group := range ruleGroups {
+		event := activity.GroupAddedToPeer
+		if group.MembershipRule.Matches(ctx, peer, postureChecks) {
+			if !group.AddPeer(peer.ID) {
+				continue
+			}
+		} else {
+			if !group.RemovePeer(peer.ID) {
+				continue
+			}
+			event = activity.GroupRemovedFromPeer
+		}
+
+		changedGroups = append(changedGroups, group)
+		meta := map[string]any{
+			"group": group.Name, "group_id": group.ID,
+			"peer_ip": peer.IP.String(), "peer_fqdn": peer.FQDN(am.GetDNSDomain()),
+		}
+		eventsToStore = append(eventsToStore, func() {
+			am.StoreEvent(ctx, activity.SystemInitiator, peer.ID, accountID, event, meta)
+		})
+	}
+
+	if len(changedGroups) == 0 {
+		return nil, false, nil
+	}
+
+	if err = transaction.SaveGroups(ctx, store.LockingStrengthUpdate, changedGroups); err != nil {
+		return nil, false, err
+	}
+
+	return eventsToStore, true, nil
+}
+
+// applyGroupMembershipRule sets the peers of a group with a membership rule to the peers of the account matching it.
+func applyGroupMembershipRule(ctx context.Context, transaction store.Store, accountID string, group *types.Group) error {
+	if err := group.MembershipRule.Validate(); err != nil {
+		return status.Errorf(status.InvalidArgument, "%s", err)
+	}
+
+	postureChecks, err := getMembershipRulesPostureChecks(ctx, transaction, accountID, []*types.Group{group})
+	if err != nil {
+		return err
+	}
+
+	for _, checksID := range group.MembershipRule.PostureCheckIDs {
+		if _, ok := postureChecks[checksID]; !ok {
+			return status.Errorf(status.InvalidArgument, "posture checks with ID \"%s\" not found", checksID)
+		}
+	}
+
+	peers, err := transaction.GetAccountPeers(ctx, store.LockingStrengthShare, accountID, "", "")
+	if err != nil {
+		return err
+	}
+
+	group.Peers = make([]string, 0)
+	for _, peer := range peers {
+		if group.MembershipRule.Matches(ctx, peer, postureChecks) {
+			group.Peers = append(group.Peers, peer.ID)
+		}
+	}
+
+	return nil
+}
+
+// getMembershipRulesPostureChecks returns the posture checks used by the membership rules of the groups.
+func getMembershipRulesPostureChecks(ctx context.Context, transaction store.Store, accountID string, groups []*types.Group) (map[string]*posture.Checks, error) {
+	var checksIDs []string
+	for _, group := range groups {
+		for _, checksID := range group.MembershipRule.PostureCheckIDs {
+			if !slices.Contains(checksIDs, checksID) {
+				checksIDs = append(checksIDs, checksID)
+			}
+		}
+	}
+
+	if len(checksIDs) == 0 {
+		return nil, nil
+	}
+
+	return transaction.GetPostureChecksByIDs(ctx, store.LockingStrengthShare, accountID, checksIDs)
+}
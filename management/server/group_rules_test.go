@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+
+	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+	"github.com/netbirdio/netbird/management/server/types"
+)
+
+func TestDefaultAccountManager_GroupMembershipRules(t *testing.T) {
+	manager, err := createManager(t)
+	require.NoError(t, err)
+
+	userID := "testingUser"
+	account, err := createAccount(manager, "test_account", userID, "netbird.cloud")
+	require.NoError(t, err)
+
+	setupKey, err := manager.CreateSetupKey(context.Background(), account.Id, "test-key", types.SetupKeyReusable, time.Hour, nil, 999, userID, false, false, types.SetupKeyProvisioning{})
+	require.NoError(t, err)
+
+	group := &types.Group{
+		ID:     "grp-web",
+		Name:   "web servers",
+		Issued: types.GroupIssuedAPI,
+		MembershipRule: &types.GroupMembershipRule{
+			HostnameRegex: "^web-",
+			SetupKeyIDs:   []string{setupKey.Id},
+		},
+	}
+	require.NoError(t, manager.SaveGroup(context.Background(), account.Id, userID, group))
+
+	addPeer := func(hostname string) *nbpeer.Peer {
+		key, err := wgtypes.GeneratePrivateKey()
+		require.NoError(t, err)
+
+		peer, _, _, err := manager.AddPeer(context.Background(), setupKey.Key, "", &nbpeer.Peer{
+			Key:  key.PublicKey().String(),
+			Meta: nbpeer.PeerSystemMeta{Hostname: hostname, GoOS: "linux"},
+		})
+		require.NoError(t, err)
+		return peer
+	}
+
+	webPeer := addPeer("web-1")
+	dbPeer := addPeer("db-1")
+	assert.Equal(t, setupKey.Id, webPeer.SetupKeyID)
+
+	group, err = manager.GetGroup(context.Background(), account.Id, group.ID, userID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{webPeer.ID}, group.Peers)
+
+	t.Run("saving the rule updates the members", func(t *testing.T) {
+		updated := group.Copy()
+		updated.Peers = []string{dbPeer.ID}
+		updated.MembershipRule = &types.GroupMembershipRule{OS: []string{"linux"}}
+		require.NoError(t, manager.SaveGroup(context.Background(), account.Id, userID, updated))
+
+		updated, err = manager.GetGroup(context.Background(), account.Id, group.ID, userID)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{webPeer.ID, dbPeer.ID}, updated.Peers)
+	})
+
+	t.Run("invalid rule", func(t *testing.T) {
+		updated := group.Copy()
+		updated.MembershipRule = &types.GroupMembershipRule{HostnameRegex: "web-("}
+		assert.Error(t, manager.SaveGroup(context.Background(), account.Id, userID, updated))
+
+		updated.MembershipRule = &types.GroupMembershipRule{PostureCheckIDs: []string{"unknown"}}
+		assert.Error(t, manager.SaveGroup(context.Background(), account.Id, userID, updated))
+	})
+}
@@ -145,6 +145,51 @@ func TestDefaultAccountManager_DeleteGroup(t *testing.T) {
 	}
 }
 
+func TestDefaultAccountManager_NestedGroups(t *testing.T) {
+	am, err := createManager(t)
+	require.NoError(t, err, "failed to create account manager")
+
+	manager, account, err := initTestGroupAccount(am)
+	require.NoError(t, err, "failed to init testing account")
+
+	parent := &types.Group{ID: "grp-parent", Name: "parent", Issued: types.GroupIssuedAPI, NestedGroups: []string{"grp-child"}}
+	child := &types.Group{ID: "grp-child", Name: "child", Issued: types.GroupIssuedAPI}
+	err = manager.SaveGroups(context.Background(), account.Id, groupAdminUserID, []*types.Group{child, parent})
+	require.NoError(t, err, "failed to save nested groups")
+
+	t.Run("unknown nested group", func(t *testing.T) {
+		group := &types.Group{ID: "grp-unknown", Name: "unknown", Issued: types.GroupIssuedAPI, NestedGroups: []string{"missing"}}
+		err := manager.SaveGroup(context.Background(), account.Id, groupAdminUserID, group)
+		sErr, ok := status.FromError(err)
+		require.True(t, ok, "expected a status error, got %v", err)
+		assert.Equal(t, status.InvalidArgument, sErr.Type())
+	})
+
+	t.Run("group containing itself", func(t *testing.T) {
+		group := child.Copy()
+		group.NestedGroups = []string{child.ID}
+		err := manager.SaveGroup(context.Background(), account.Id, groupAdminUserID, group)
+		sErr, ok := status.FromError(err)
+		require.True(t, ok, "expected a status error, got %v", err)
+		assert.Equal(t, status.InvalidArgument, sErr.Type())
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		group := child.Copy()
+		group.NestedGroups = []string{parent.ID}
+		err := manager.SaveGroup(context.Background(), account.Id, groupAdminUserID, group)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cycle")
+	})
+
+	t.Run("delete nested group", func(t *testing.T) {
+		err := manager.DeleteGroup(context.Background(), account.Id, groupAdminUserID, child.ID)
+		var groupErr *GroupLinkError
+		require.ErrorAs(t, err, &groupErr)
+		assert.Equal(t, "group", groupErr.Resource)
+	})
+}
+
 func TestDefaultAccountManager_DeleteGroups(t *testing.T) {
 	am, err := createManager(t)
 	assert.NoError(t, err, "Failed to create account manager")
@@ -594,7 +639,7 @@ func TestGroupAccountPeersUpdate(t *testing.T) {
 				Port:   nbdns.DefaultDNSPort,
 			}},
 			[]string{"groupC"},
-			true, nil, true, userID, false,
+			true, nil, true, userID, false, nbdns.RoutedAnswersDisabled, "",
 		)
 		assert.NoError(t, err)
 
@@ -17,6 +17,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 
 	integrationsConfig "github.com/netbirdio/management-integrations/integrations/config"
 
@@ -25,13 +26,20 @@ import (
 	"github.com/netbirdio/netbird/management/server/account"
 	"github.com/netbirdio/netbird/management/server/activity"
 	"github.com/netbirdio/netbird/management/server/auth"
+	"github.com/netbirdio/netbird/management/server/connectivity"
 	nbContext "github.com/netbirdio/netbird/management/server/context"
+	"github.com/netbirdio/netbird/management/server/controlplane"
+	"github.com/netbirdio/netbird/management/server/debugbundles"
+	"github.com/netbirdio/netbird/management/server/mtls"
 	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+	"github.com/netbirdio/netbird/management/server/peeractions"
 	"github.com/netbirdio/netbird/management/server/posture"
+	"github.com/netbirdio/netbird/management/server/probes"
 	"github.com/netbirdio/netbird/management/server/settings"
 	internalStatus "github.com/netbirdio/netbird/management/server/status"
 	"github.com/netbirdio/netbird/management/server/telemetry"
 	"github.com/netbirdio/netbird/management/server/types"
+	"github.com/netbirdio/netbird/util/errcode"
 )
 
 // GRPCServer an instance of a Management gRPC API server
@@ -41,12 +49,26 @@ type GRPCServer struct {
 	wgKey           wgtypes.Key
 	proto.UnimplementedManagementServiceServer
 	peersUpdateManager *PeersUpdateManager
-	config             *types.Config
-	secretsManager     SecretsManager
-	appMetrics         telemetry.AppMetrics
-	ephemeralManager   *EphemeralManager
-	peerLocks          sync.Map
-	authManager        auth.Manager
+	// config is replaced when the management config is reloaded, it is guarded by configMu
+	config           *types.Config
+	configMu         sync.RWMutex
+	secretsManager   SecretsManager
+	appMetrics       telemetry.AppMetrics
+	ephemeralManager *EphemeralManager
+	peerLocks        sync.Map
+	authManager      auth.Manager
+	// clientCertAuthority issues and verifies the client certificates of the peers, if set
+	clientCertAuthority *mtls.Authority
+	// probesManager receives the results of the probes requested from the peers, if set
+	probesManager probes.Manager
+	// debugBundlesManager receives the status of the debug bundles requested from the peers, if set
+	debugBundlesManager debugbundles.Manager
+	// connectivityManager receives the connectivity reports of the peers, if set
+	connectivityManager connectivity.Manager
+	// peerActionsManager receives the results of the actions requested from the peers, if set
+	peerActionsManager peeractions.Manager
+	// syncRecorder counts the sync streams of the peers and their errors per account, if set
+	syncRecorder *controlplane.SyncRecorder
 }
 
 // NewServer creates a new Management server
@@ -90,6 +112,79 @@ func NewServer(
 	}, nil
 }
 
+// UpdateConfig replaces the config sent to the peers with their login and sync responses
+func (s *GRPCServer) UpdateConfig(config *types.Config) {
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+	s.config = config
+}
+
+// SetClientCertificateAuthority enables the client certificates of the peers, the server TLS config has to request
+// and verify them with the authority
+func (s *GRPCServer) SetClientCertificateAuthority(authority *mtls.Authority) {
+	s.clientCertAuthority = authority
+}
+
+// SetProbesManager enables the peers to report the results of the probes requested by the management service
+func (s *GRPCServer) SetProbesManager(manager probes.Manager) {
+	s.probesManager = manager
+}
+
+// SetDebugBundlesManager enables the peers to report the debug bundles requested by the management service they won't upload
+func (s *GRPCServer) SetDebugBundlesManager(manager debugbundles.Manager) {
+	s.debugBundlesManager = manager
+}
+
+// SetPeerActionsManager enables the peers to report the results of the actions requested by the management service
+func (s *GRPCServer) SetPeerActionsManager(manager peeractions.Manager) {
+	s.peerActionsManager = manager
+}
+
+// SetConnectivityManager enables the peers to report their connectivity
+func (s *GRPCServer) SetConnectivityManager(manager connectivity.Manager) {
+	s.connectivityManager = manager
+}
+
+// SetSyncRecorder enables counting the sync streams of the peers and their errors per account
+func (s *GRPCServer) SetSyncRecorder(recorder *controlplane.SyncRecorder) {
+	s.syncRecorder = recorder
+}
+
+// verifyClientCertificate checks the client certificate of the call was issued to the peer
+func (s *GRPCServer) verifyClientCertificate(ctx context.Context, peerKey wgtypes.Key) error {
+	if s.clientCertAuthority == nil {
+		return nil
+	}
+
+	if err := s.clientCertAuthority.VerifyPeer(ctx, peerKey.String()); err != nil {
+		log.WithContext(ctx).Debugf("rejected the client certificate of peer %s: %v", peerKey.String(), err)
+		return status.Errorf(codes.Unauthenticated, "%v", err)
+	}
+
+	return nil
+}
+
+// issueClientCertificate signs the client certificate requested with the login request, if any
+func (s *GRPCServer) issueClientCertificate(ctx context.Context, loginReq *proto.LoginRequest, peerKey wgtypes.Key) ([]byte, error) {
+	if s.clientCertAuthority == nil || len(loginReq.GetClientCertificateRequest()) == 0 {
+		return nil, nil
+	}
+
+	cert, err := s.clientCertAuthority.Sign(loginReq.GetClientCertificateRequest(), peerKey.String())
+	if err != nil {
+		log.WithContext(ctx).Warnf("failed issuing a client certificate to peer %s: %v", peerKey.String(), err)
+		return nil, status.Errorf(codes.InvalidArgument, "invalid client certificate request")
+	}
+
+	return cert, nil
+}
+
+func (s *GRPCServer) getConfig() *types.Config {
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
+	return s.config
+}
+
 func (s *GRPCServer) GetServerKey(ctx context.Context, req *proto.Empty) (*proto.ServerKeyResponse, error) {
 	ip := ""
 	p, ok := peer.FromContext(ctx)
@@ -141,6 +236,10 @@ func (s *GRPCServer) Sync(req *proto.EncryptedMessage, srv proto.ManagementServi
 		return err
 	}
 
+	if err = s.verifyClientCertificate(ctx, peerKey); err != nil {
+		return err
+	}
+
 	// nolint:staticcheck
 	ctx = context.WithValue(ctx, nbContext.PeerIDKey, peerKey.String())
 
@@ -175,20 +274,28 @@ func (s *GRPCServer) Sync(req *proto.EncryptedMessage, srv proto.ManagementServi
 	peer, netMap, postureChecks, err := s.accountManager.SyncAndMarkPeer(ctx, accountID, peerKey.String(), extractPeerMeta(ctx, syncReq.GetMeta()), realIP)
 	if err != nil {
 		log.WithContext(ctx).Debugf("error while syncing peer %s: %v", peerKey.String(), err)
+		s.recordSyncError(accountID)
 		return mapError(ctx, err)
 	}
 
 	err = s.sendInitialSync(ctx, peerKey, peer, netMap, postureChecks, srv)
 	if err != nil {
 		log.WithContext(ctx).Debugf("error while sending initial sync for %s: %v", peerKey.String(), err)
+		s.recordSyncError(accountID)
 		return err
 	}
 
+	if s.syncRecorder != nil {
+		s.syncRecorder.RecordSync(accountID)
+	}
+
 	updates := s.peersUpdateManager.CreateChannel(ctx, peer.ID)
 
-	s.ephemeralManager.OnPeerConnected(ctx, peer)
+	if s.ephemeralManager != nil {
+		s.ephemeralManager.OnPeerConnected(ctx, peer)
+	}
 
-	s.secretsManager.SetupRefresh(ctx, accountID, peer.ID)
+	s.secretsManager.SetupRefresh(ctx, accountID, peer.ID, peerKey.String())
 
 	if s.appMetrics != nil {
 		s.appMetrics.GRPCMetrics().CountSyncRequestDuration(time.Since(reqStart))
@@ -221,6 +328,7 @@ func (s *GRPCServer) handleUpdates(ctx context.Context, accountID string, peerKe
 			log.WithContext(ctx).Debugf("received an update for peer %s", peerKey.String())
 
 			if err := s.sendUpdate(ctx, accountID, peerKey, peer, update, srv); err != nil {
+				s.recordSyncError(accountID)
 				return err
 			}
 
@@ -254,6 +362,13 @@ func (s *GRPCServer) sendUpdate(ctx context.Context, accountID string, peerKey w
 	return nil
 }
 
+// recordSyncError counts a failed sync stream of the account, if the sync streams are counted
+func (s *GRPCServer) recordSyncError(accountID string) {
+	if s.syncRecorder != nil {
+		s.syncRecorder.RecordSyncError(accountID)
+	}
+}
+
 func (s *GRPCServer) cancelPeerRoutines(ctx context.Context, accountID string, peer *nbpeer.Peer) {
 	unlock := s.acquirePeerLockByUID(ctx, peer.Key)
 	defer unlock()
@@ -264,7 +379,9 @@ func (s *GRPCServer) cancelPeerRoutines(ctx context.Context, accountID string, p
 	}
 	s.peersUpdateManager.CloseChannel(ctx, peer.ID)
 	s.secretsManager.CancelRefresh(peer.ID)
-	s.ephemeralManager.OnPeerDisconnected(ctx, peer)
+	if s.ephemeralManager != nil {
+		s.ephemeralManager.OnPeerDisconnected(ctx, peer)
+	}
 
 	log.WithContext(ctx).Tracef("peer %s has been disconnected", peer.Key)
 }
@@ -326,20 +443,20 @@ func mapError(ctx context.Context, err error) error {
 	if e, ok := internalStatus.FromError(err); ok {
 		switch e.Type() {
 		case internalStatus.PermissionDenied:
-			return status.Error(codes.PermissionDenied, e.Message)
+			return errcode.Status(codes.PermissionDenied, e.ErrorCode(), e.Message).Err()
 		case internalStatus.Unauthorized:
-			return status.Error(codes.PermissionDenied, e.Message)
+			return errcode.Status(codes.PermissionDenied, e.ErrorCode(), e.Message).Err()
 		case internalStatus.Unauthenticated:
-			return status.Error(codes.PermissionDenied, e.Message)
+			return errcode.Status(codes.PermissionDenied, e.ErrorCode(), e.Message).Err()
 		case internalStatus.PreconditionFailed:
-			return status.Error(codes.FailedPrecondition, e.Message)
+			return errcode.Status(codes.FailedPrecondition, e.ErrorCode(), e.Message).Err()
 		case internalStatus.NotFound:
-			return status.Error(codes.NotFound, e.Message)
+			return errcode.Status(codes.NotFound, e.ErrorCode(), e.Message).Err()
 		default:
 		}
 	}
 	log.WithContext(ctx).Errorf("got an unhandled error: %s", err)
-	return status.Errorf(codes.Internal, "failed handling request")
+	return errcode.Status(codes.Internal, errcode.Internal, "failed handling request").Err()
 }
 
 func extractPeerMeta(ctx context.Context, meta *proto.PeerSystemMeta) nbpeer.PeerSystemMeta {
@@ -374,6 +491,11 @@ func extractPeerMeta(ctx context.Context, meta *proto.PeerSystemMeta) nbpeer.Pee
 		})
 	}
 
+	var bootTime time.Time
+	if meta.GetBootTime() != nil {
+		bootTime = meta.GetBootTime().AsTime().UTC()
+	}
+
 	return nbpeer.PeerSystemMeta{
 		Hostname:           meta.GetHostname(),
 		GoOS:               meta.GetGoOS(),
@@ -392,8 +514,25 @@ func extractPeerMeta(ctx context.Context, meta *proto.PeerSystemMeta) nbpeer.Pee
 			Cloud:    meta.GetEnvironment().GetCloud(),
 			Platform: meta.GetEnvironment().GetPlatform(),
 		},
-		Files: files,
+		Files:            files,
+		RouterMode:       meta.GetFlags().GetRouterMode(),
+		AdvertisedRoutes: advertisedRoutes(ctx, meta.GetAdvertisedRoutes()),
+		BootTime:         bootTime,
+	}
+}
+
+// advertisedRoutes returns the valid LAN prefixes advertised by a peer in router mode
+func advertisedRoutes(ctx context.Context, routes []string) []string {
+	var valid []string
+	for _, r := range routes {
+		prefix, err := netip.ParsePrefix(r)
+		if err != nil {
+			log.WithContext(ctx).Warnf("failed to parse advertised route %s: %v", r, err)
+			continue
+		}
+		valid = append(valid, prefix.Masked().String())
 	}
+	return valid
 }
 
 func (s *GRPCServer) parseRequest(ctx context.Context, req *proto.EncryptedMessage, parsed pb.Message) (wgtypes.Key, error) {
@@ -476,13 +615,20 @@ func (s *GRPCServer) Login(ctx context.Context, req *proto.EncryptedMessage) (*p
 	}
 
 	// if the login request contains setup key then it is a registration request
-	if loginReq.GetSetupKey() != "" {
+	if loginReq.GetSetupKey() != "" && s.ephemeralManager != nil {
 		s.ephemeralManager.OnPeerDisconnected(ctx, peer)
 	}
 
+	clientCert, err := s.issueClientCertificate(ctx, loginReq, peerKey)
+	if err != nil {
+		return nil, err
+	}
+
+	config := s.getConfig()
+
 	var relayToken *Token
-	if s.config.Relay != nil && len(s.config.Relay.Addresses) > 0 {
-		relayToken, err = s.secretsManager.GenerateRelayToken()
+	if config.Relay != nil && len(config.Relay.Addresses) > 0 {
+		relayToken, err = s.secretsManager.GenerateRelayToken(peerKey.String())
 		if err != nil {
 			log.Errorf("failed generating Relay token: %v", err)
 		}
@@ -490,9 +636,10 @@ func (s *GRPCServer) Login(ctx context.Context, req *proto.EncryptedMessage) (*p
 
 	// if peer has reached this point then it has logged in
 	loginResp := &proto.LoginResponse{
-		NetbirdConfig: toNetbirdConfig(s.config, nil, relayToken, nil),
-		PeerConfig:    toPeerConfig(peer, netMap.Network, s.accountManager.GetDNSDomain(), false),
-		Checks:        toProtocolChecks(ctx, postureChecks),
+		NetbirdConfig:     toNetbirdConfig(config, nil, relayToken, nil),
+		PeerConfig:        toPeerConfig(peer, netMap, s.accountManager.GetDNSDomain(), false),
+		Checks:            toProtocolChecks(ctx, postureChecks),
+		ClientCertificate: clientCert,
 	}
 	encryptedResp, err := encryption.EncryptMessage(peerKey, s.wgKey, loginResp)
 	if err != nil {
@@ -605,29 +752,97 @@ func toNetbirdConfig(config *types.Config, turnCredentials *Token, relayToken *T
 	}
 
 	nbConfig := &proto.NetbirdConfig{
-		Stuns:  stuns,
-		Turns:  turns,
-		Signal: signalCfg,
-		Relay:  relayCfg,
+		Stuns:          stuns,
+		Turns:          turns,
+		Signal:         signalCfg,
+		Relay:          relayCfg,
+		PeerActionsKey: peeractions.PublicKey(config.DataStoreEncryptionKey),
 	}
 
 	return nbConfig
 }
 
-func toPeerConfig(peer *nbpeer.Peer, network *types.Network, dnsName string, dnsResolutionOnRoutingPeerEnabled bool) *proto.PeerConfig {
-	netmask, _ := network.Net.Mask.Size()
+func toPeerConfig(peer *nbpeer.Peer, networkMap *types.NetworkMap, dnsName string, dnsResolutionOnRoutingPeerEnabled bool) *proto.PeerConfig {
+	netmask, _ := networkMap.Network.Net.Mask.Size()
 	fqdn := peer.FQDN(dnsName)
-	return &proto.PeerConfig{
+	peerConfig := &proto.PeerConfig{
 		Address:                         fmt.Sprintf("%s/%d", peer.IP.String(), netmask), // take it from the network
 		SshConfig:                       &proto.SSHConfig{SshEnabled: peer.SSHEnabled},
 		Fqdn:                            fqdn,
 		RoutingPeerDnsResolutionEnabled: dnsResolutionOnRoutingPeerEnabled,
+		LoginExpirationGrace:            networkMap.LoginExpirationGrace,
+		LoginExpired:                    networkMap.LoginExpired,
+	}
+
+	// let the client notify the user and end the session of the peer when its login expires
+	if loginExpiresAt := networkMap.LoginExpiresAt; !loginExpiresAt.IsZero() {
+		peerConfig.LoginExpiresAt = &timestamp.Timestamp{Seconds: loginExpiresAt.Unix(), Nanos: int32(loginExpiresAt.Nanosecond())}
+		if networkMap.LoginExpirationNotification > 0 {
+			peerConfig.LoginExpirationNotification = durationpb.New(networkMap.LoginExpirationNotification)
+		}
+	}
+
+	peerConfig.ConnectionTuning = toProtocolConnectionTuning(networkMap.ConnectionTuning)
+	peerConfig.PortPolicy = toProtocolPortPolicy(networkMap.PortPolicy)
+	peerConfig.ClientSettings = toProtocolClientSettings(networkMap.ClientSettings)
+	peerConfig.DisabledNotifications = networkMap.DisabledNotifications
+	peerConfig.FailedPostureChecks = networkMap.FailedPostureChecks
+
+	return peerConfig
+}
+
+// toProtocolConnectionTuning converts the connection tuning, the zero durations are left unset for the client defaults to apply
+func toProtocolConnectionTuning(tuning types.ConnectionTuning) *proto.ConnectionTuning {
+	if tuning == (types.ConnectionTuning{}) {
+		return nil
+	}
+
+	toDuration := func(d time.Duration) *durationpb.Duration {
+		if d == 0 {
+			return nil
+		}
+		return durationpb.New(d)
+	}
+
+	return &proto.ConnectionTuning{
+		WgKeepalive:               toDuration(tuning.WgKeepalive),
+		HandshakeRetryMaxInterval: toDuration(tuning.HandshakeRetryMaxInterval),
+		HandshakeRetryTimeout:     toDuration(tuning.HandshakeRetryTimeout),
+		IceKeepalive:              toDuration(tuning.ICEKeepalive),
+		IceDisconnectedTimeout:    toDuration(tuning.ICEDisconnectedTimeout),
+		IceFailedTimeout:          toDuration(tuning.ICEFailedTimeout),
+	}
+}
+
+// toProtocolPortPolicy converts the port policy, nil if the peer isn't constrained
+func toProtocolPortPolicy(policy types.PortPolicy) *proto.PortPolicy {
+	if policy.IsEmpty() {
+		return nil
+	}
+
+	return &proto.PortPolicy{
+		WgPortRangeStart: uint32(policy.WgPortRangeStart),
+		WgPortRangeEnd:   uint32(policy.WgPortRangeEnd),
+		Tcp443Only:       policy.TCP443Only,
 	}
 }
 
-func toSyncResponse(ctx context.Context, config *types.Config, peer *nbpeer.Peer, turnCredentials *Token, relayCredentials *Token, networkMap *types.NetworkMap, dnsName string, checks []*posture.Checks, dnsCache *DNSConfigCache, dnsResolutionOnRoutingPeerEnabled bool, extraSettings *types.ExtraSettings) *proto.SyncResponse {
+// toProtocolClientSettings converts the client settings of the account, nil if the peer keeps its settings
+func toProtocolClientSettings(settings *types.ClientSettings) *proto.ClientSettings {
+	if settings == nil {
+		return nil
+	}
+
+	return &proto.ClientSettings{
+		DnsManagementEnabled: settings.DNSManagementEnabled,
+		AcceptRoutes:         settings.AcceptRoutes,
+		FirewallEnabled:      settings.FirewallMode != types.FirewallModeDisabled,
+	}
+}
+
+func toSyncResponse(ctx context.Context, config *types.Config, peer *nbpeer.Peer, turnCredentials *Token, relayCredentials *Token, networkMap *types.NetworkMap, dnsName string, checks []*posture.Checks, dnsCache *DNSConfigCache, remotePeerCache *RemotePeerConfigCache, dnsResolutionOnRoutingPeerEnabled bool, extraSettings *types.ExtraSettings) *proto.SyncResponse {
 	response := &proto.SyncResponse{
-		PeerConfig: toPeerConfig(peer, networkMap.Network, dnsName, dnsResolutionOnRoutingPeerEnabled),
+		PeerConfig: toPeerConfig(peer, networkMap, dnsName, dnsResolutionOnRoutingPeerEnabled),
 		NetworkMap: &proto.NetworkMap{
 			Serial:    networkMap.Network.CurrentSerial(),
 			Routes:    toProtocolRoutes(networkMap.Routes),
@@ -643,13 +858,13 @@ func toSyncResponse(ctx context.Context, config *types.Config, peer *nbpeer.Peer
 	response.NetworkMap.PeerConfig = response.PeerConfig
 
 	allPeers := make([]*proto.RemotePeerConfig, 0, len(networkMap.Peers)+len(networkMap.OfflinePeers))
-	allPeers = appendRemotePeerConfig(allPeers, networkMap.Peers, dnsName)
+	allPeers = appendRemotePeerConfig(allPeers, networkMap.Peers, dnsName, remotePeerCache)
 	response.RemotePeers = allPeers
 	response.NetworkMap.RemotePeers = allPeers
 	response.RemotePeersIsEmpty = len(allPeers) == 0
 	response.NetworkMap.RemotePeersIsEmpty = response.RemotePeersIsEmpty
 
-	response.NetworkMap.OfflinePeers = appendRemotePeerConfig(nil, networkMap.OfflinePeers, dnsName)
+	response.NetworkMap.OfflinePeers = appendRemotePeerConfig(nil, networkMap.OfflinePeers, dnsName, remotePeerCache)
 
 	firewallRules := toProtocolFirewallRules(networkMap.FirewallRules)
 	response.NetworkMap.FirewallRules = firewallRules
@@ -670,14 +885,45 @@ func toSyncResponse(ctx context.Context, config *types.Config, peer *nbpeer.Peer
 	return response
 }
 
-func appendRemotePeerConfig(dst []*proto.RemotePeerConfig, peers []*nbpeer.Peer, dnsName string) []*proto.RemotePeerConfig {
+// RemotePeerConfigCache shares the remote peer configs between the sync responses sent to the peers of an account
+// by an account peers update, so a peer is converted once instead of once for every peer it is connected to.
+// The cached configs are shared by the responses and must not be modified.
+type RemotePeerConfigCache struct {
+	configs sync.Map
+}
+
+func (c *RemotePeerConfigCache) get(peerID string) (*proto.RemotePeerConfig, bool) {
+	if c == nil {
+		return nil, false
+	}
+	if value, ok := c.configs.Load(peerID); ok {
+		return value.(*proto.RemotePeerConfig), true
+	}
+	return nil, false
+}
+
+func (c *RemotePeerConfigCache) set(peerID string, config *proto.RemotePeerConfig) {
+	if c == nil {
+		return
+	}
+	c.configs.Store(peerID, config)
+}
+
+func appendRemotePeerConfig(dst []*proto.RemotePeerConfig, peers []*nbpeer.Peer, dnsName string, cache *RemotePeerConfigCache) []*proto.RemotePeerConfig {
 	for _, rPeer := range peers {
-		dst = append(dst, &proto.RemotePeerConfig{
+		if config, ok := cache.get(rPeer.ID); ok {
+			dst = append(dst, config)
+			continue
+		}
+
+		config := &proto.RemotePeerConfig{
 			WgPubKey:   rPeer.Key,
 			AllowedIps: []string{rPeer.IP.String() + "/32"},
 			SshConfig:  &proto.SSHConfig{SshPubKey: []byte(rPeer.SSHKey)},
 			Fqdn:       rPeer.FQDN(dnsName),
-		})
+		}
+		cache.set(rPeer.ID, config)
+		dst = append(dst, config)
 	}
 	return dst
 }
@@ -691,8 +937,10 @@ func (s *GRPCServer) IsHealthy(ctx context.Context, req *proto.Empty) (*proto.Em
 func (s *GRPCServer) sendInitialSync(ctx context.Context, peerKey wgtypes.Key, peer *nbpeer.Peer, networkMap *types.NetworkMap, postureChecks []*posture.Checks, srv proto.ManagementService_SyncServer) error {
 	var err error
 
+	config := s.getConfig()
+
 	var turnToken *Token
-	if s.config.TURNConfig != nil && s.config.TURNConfig.TimeBasedCredentials {
+	if config.TURNConfig != nil && config.TURNConfig.TimeBasedCredentials {
 		turnToken, err = s.secretsManager.GenerateTurnToken()
 		if err != nil {
 			log.Errorf("failed generating TURN token: %v", err)
@@ -700,8 +948,8 @@ func (s *GRPCServer) sendInitialSync(ctx context.Context, peerKey wgtypes.Key, p
 	}
 
 	var relayToken *Token
-	if s.config.Relay != nil && len(s.config.Relay.Addresses) > 0 {
-		relayToken, err = s.secretsManager.GenerateRelayToken()
+	if config.Relay != nil && len(config.Relay.Addresses) > 0 {
+		relayToken, err = s.secretsManager.GenerateRelayToken(peerKey.String())
 		if err != nil {
 			log.Errorf("failed generating Relay token: %v", err)
 		}
@@ -712,7 +960,7 @@ func (s *GRPCServer) sendInitialSync(ctx context.Context, peerKey wgtypes.Key, p
 		return status.Errorf(codes.Internal, "error handling request")
 	}
 
-	plainResp := toSyncResponse(ctx, s.config, peer, turnToken, relayToken, networkMap, s.accountManager.GetDNSDomain(), postureChecks, nil, settings.RoutingPeerDNSResolutionEnabled, settings.Extra)
+	plainResp := toSyncResponse(ctx, config, peer, turnToken, relayToken, networkMap, s.accountManager.GetDNSDomain(), postureChecks, nil, nil, settings.RoutingPeerDNSResolutionEnabled, settings.Extra)
 
 	encryptedResp, err := encryption.EncryptMessage(peerKey, s.wgKey, plainResp)
 	if err != nil {
@@ -756,26 +1004,27 @@ func (s *GRPCServer) GetDeviceAuthorizationFlow(ctx context.Context, req *proto.
 		return nil, status.Error(codes.InvalidArgument, errMSG)
 	}
 
-	if s.config.DeviceAuthorizationFlow == nil || s.config.DeviceAuthorizationFlow.Provider == string(types.NONE) {
+	config := s.getConfig()
+	if config.DeviceAuthorizationFlow == nil || config.DeviceAuthorizationFlow.Provider == string(types.NONE) {
 		return nil, status.Error(codes.NotFound, "no device authorization flow information available")
 	}
 
-	provider, ok := proto.DeviceAuthorizationFlowProvider_value[strings.ToUpper(s.config.DeviceAuthorizationFlow.Provider)]
+	provider, ok := proto.DeviceAuthorizationFlowProvider_value[strings.ToUpper(config.DeviceAuthorizationFlow.Provider)]
 	if !ok {
-		return nil, status.Errorf(codes.InvalidArgument, "no provider found in the protocol for %s", s.config.DeviceAuthorizationFlow.Provider)
+		return nil, status.Errorf(codes.InvalidArgument, "no provider found in the protocol for %s", config.DeviceAuthorizationFlow.Provider)
 	}
 
 	flowInfoResp := &proto.DeviceAuthorizationFlow{
 		Provider: proto.DeviceAuthorizationFlowProvider(provider),
 		ProviderConfig: &proto.ProviderConfig{
-			ClientID:           s.config.DeviceAuthorizationFlow.ProviderConfig.ClientID,
-			ClientSecret:       s.config.DeviceAuthorizationFlow.ProviderConfig.ClientSecret,
-			Domain:             s.config.DeviceAuthorizationFlow.ProviderConfig.Domain,
-			Audience:           s.config.DeviceAuthorizationFlow.ProviderConfig.Audience,
-			DeviceAuthEndpoint: s.config.DeviceAuthorizationFlow.ProviderConfig.DeviceAuthEndpoint,
-			TokenEndpoint:      s.config.DeviceAuthorizationFlow.ProviderConfig.TokenEndpoint,
-			Scope:              s.config.DeviceAuthorizationFlow.ProviderConfig.Scope,
-			UseIDToken:         s.config.DeviceAuthorizationFlow.ProviderConfig.UseIDToken,
+			ClientID:           config.DeviceAuthorizationFlow.ProviderConfig.ClientID,
+			ClientSecret:       config.DeviceAuthorizationFlow.ProviderConfig.ClientSecret,
+			Domain:             config.DeviceAuthorizationFlow.ProviderConfig.Domain,
+			Audience:           config.DeviceAuthorizationFlow.ProviderConfig.Audience,
+			DeviceAuthEndpoint: config.DeviceAuthorizationFlow.ProviderConfig.DeviceAuthEndpoint,
+			TokenEndpoint:      config.DeviceAuthorizationFlow.ProviderConfig.TokenEndpoint,
+			Scope:              config.DeviceAuthorizationFlow.ProviderConfig.Scope,
+			UseIDToken:         config.DeviceAuthorizationFlow.ProviderConfig.UseIDToken,
 		},
 	}
 
@@ -814,20 +1063,21 @@ func (s *GRPCServer) GetPKCEAuthorizationFlow(ctx context.Context, req *proto.En
 		return nil, status.Error(codes.InvalidArgument, errMSG)
 	}
 
-	if s.config.PKCEAuthorizationFlow == nil {
+	config := s.getConfig()
+	if config.PKCEAuthorizationFlow == nil {
 		return nil, status.Error(codes.NotFound, "no pkce authorization flow information available")
 	}
 
 	flowInfoResp := &proto.PKCEAuthorizationFlow{
 		ProviderConfig: &proto.ProviderConfig{
-			Audience:              s.config.PKCEAuthorizationFlow.ProviderConfig.Audience,
-			ClientID:              s.config.PKCEAuthorizationFlow.ProviderConfig.ClientID,
-			ClientSecret:          s.config.PKCEAuthorizationFlow.ProviderConfig.ClientSecret,
-			TokenEndpoint:         s.config.PKCEAuthorizationFlow.ProviderConfig.TokenEndpoint,
-			AuthorizationEndpoint: s.config.PKCEAuthorizationFlow.ProviderConfig.AuthorizationEndpoint,
-			Scope:                 s.config.PKCEAuthorizationFlow.ProviderConfig.Scope,
-			RedirectURLs:          s.config.PKCEAuthorizationFlow.ProviderConfig.RedirectURLs,
-			UseIDToken:            s.config.PKCEAuthorizationFlow.ProviderConfig.UseIDToken,
+			Audience:              config.PKCEAuthorizationFlow.ProviderConfig.Audience,
+			ClientID:              config.PKCEAuthorizationFlow.ProviderConfig.ClientID,
+			ClientSecret:          config.PKCEAuthorizationFlow.ProviderConfig.ClientSecret,
+			TokenEndpoint:         config.PKCEAuthorizationFlow.ProviderConfig.TokenEndpoint,
+			AuthorizationEndpoint: config.PKCEAuthorizationFlow.ProviderConfig.AuthorizationEndpoint,
+			Scope:                 config.PKCEAuthorizationFlow.ProviderConfig.Scope,
+			RedirectURLs:          config.PKCEAuthorizationFlow.ProviderConfig.RedirectURLs,
+			UseIDToken:            config.PKCEAuthorizationFlow.ProviderConfig.UseIDToken,
 		},
 	}
 
@@ -854,6 +1104,10 @@ func (s *GRPCServer) SyncMeta(ctx context.Context, req *proto.EncryptedMessage)
 		return nil, err
 	}
 
+	if err = s.verifyClientCertificate(ctx, peerKey); err != nil {
+		return nil, err
+	}
+
 	if syncMetaReq.GetMeta() == nil {
 		msg := status.Errorf(codes.FailedPrecondition,
 			"peer system meta has to be provided on sync. Peer %s, remote addr %s", peerKey.String(), realIP)
@@ -869,6 +1123,98 @@ func (s *GRPCServer) SyncMeta(ctx context.Context, req *proto.EncryptedMessage)
 	return &proto.Empty{}, nil
 }
 
+// ReportProbeResult endpoint is used by the peers to report the result of a probe requested with a sync update
+func (s *GRPCServer) ReportProbeResult(ctx context.Context, req *proto.EncryptedMessage) (*proto.Empty, error) {
+	if s.probesManager == nil {
+		return nil, status.Errorf(codes.Unimplemented, "probes are not enabled")
+	}
+
+	result := &proto.ProbeResult{}
+	peerKey, err := s.parseRequest(ctx, req, result)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.verifyClientCertificate(ctx, peerKey); err != nil {
+		return nil, err
+	}
+
+	if err = s.probesManager.ReportResult(ctx, peerKey.String(), result); err != nil {
+		return nil, mapError(ctx, err)
+	}
+
+	return &proto.Empty{}, nil
+}
+
+// ReportDebugBundleStatus endpoint is used by the peers to report a debug bundle requested with a sync update they won't upload
+func (s *GRPCServer) ReportDebugBundleStatus(ctx context.Context, req *proto.EncryptedMessage) (*proto.Empty, error) {
+	if s.debugBundlesManager == nil {
+		return nil, status.Errorf(codes.Unimplemented, "debug bundles are not enabled")
+	}
+
+	bundleStatus := &proto.DebugBundleStatus{}
+	peerKey, err := s.parseRequest(ctx, req, bundleStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.verifyClientCertificate(ctx, peerKey); err != nil {
+		return nil, err
+	}
+
+	if err = s.debugBundlesManager.ReportStatus(ctx, peerKey.String(), bundleStatus); err != nil {
+		return nil, mapError(ctx, err)
+	}
+
+	return &proto.Empty{}, nil
+}
+
+// ReportPeerActionStatus endpoint is used by the peers to report the result of an action requested with a sync update
+func (s *GRPCServer) ReportPeerActionStatus(ctx context.Context, req *proto.EncryptedMessage) (*proto.Empty, error) {
+	if s.peerActionsManager == nil {
+		return nil, status.Errorf(codes.Unimplemented, "peer actions are not enabled")
+	}
+
+	actionStatus := &proto.PeerActionStatus{}
+	peerKey, err := s.parseRequest(ctx, req, actionStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.verifyClientCertificate(ctx, peerKey); err != nil {
+		return nil, err
+	}
+
+	if err = s.peerActionsManager.ReportStatus(ctx, peerKey.String(), actionStatus); err != nil {
+		return nil, mapError(ctx, err)
+	}
+
+	return &proto.Empty{}, nil
+}
+
+// ReportConnectivity endpoint is used by the peers to report their NAT type, STUN and TURN reachability and connection types
+func (s *GRPCServer) ReportConnectivity(ctx context.Context, req *proto.EncryptedMessage) (*proto.Empty, error) {
+	if s.connectivityManager == nil {
+		return nil, status.Errorf(codes.Unimplemented, "connectivity reports are not enabled")
+	}
+
+	report := &proto.ConnectivityReport{}
+	peerKey, err := s.parseRequest(ctx, req, report)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.verifyClientCertificate(ctx, peerKey); err != nil {
+		return nil, err
+	}
+
+	if err = s.connectivityManager.ReportConnectivity(ctx, peerKey.String(), report); err != nil {
+		return nil, mapError(ctx, err)
+	}
+
+	return &proto.Empty{}, nil
+}
+
 // toProtocolChecks converts posture checks to protocol checks.
 func toProtocolChecks(ctx context.Context, postureChecks []*posture.Checks) []*proto.Checks {
 	protoChecks := make([]*proto.Checks, 0, len(postureChecks))
@@ -12,6 +12,55 @@ const (
 	TokenAuthScopes  = "TokenAuth.Scopes"
 )
 
+// Defines values for AccountSettingsDisabledNotifications.
+const (
+	AccountSettingsDisabledNotificationsExitNode      AccountSettingsDisabledNotifications = "exit_node"
+	AccountSettingsDisabledNotificationsPostureCheck  AccountSettingsDisabledNotifications = "posture_check"
+	AccountSettingsDisabledNotificationsRouteApproval AccountSettingsDisabledNotifications = "route_approval"
+	AccountSettingsDisabledNotificationsSessionExpiry AccountSettingsDisabledNotifications = "session_expiry"
+)
+
+// Defines values for BulkOperationMethod.
+const (
+	BulkOperationMethodDELETE BulkOperationMethod = "DELETE"
+	BulkOperationMethodPOST   BulkOperationMethod = "POST"
+	BulkOperationMethodPUT    BulkOperationMethod = "PUT"
+)
+
+// Defines values for ClientSettingsFirewallMode.
+const (
+	ClientSettingsFirewallModeDisabled ClientSettingsFirewallMode = "disabled"
+	ClientSettingsFirewallModeEnforce  ClientSettingsFirewallMode = "enforce"
+)
+
+// Defines values for ConfigChangeAction.
+const (
+	ConfigChangeActionCreate ConfigChangeAction = "create"
+	ConfigChangeActionDelete ConfigChangeAction = "delete"
+	ConfigChangeActionUpdate ConfigChangeAction = "update"
+)
+
+// Defines values for ConfigChangeKind.
+const (
+	ConfigChangeKindGroup           ConfigChangeKind = "group"
+	ConfigChangeKindNameserverGroup ConfigChangeKind = "nameserver_group"
+	ConfigChangeKindPolicy          ConfigChangeKind = "policy"
+	ConfigChangeKindPostureCheck    ConfigChangeKind = "posture_check"
+	ConfigChangeKindRoute           ConfigChangeKind = "route"
+)
+
+// Defines values for ConnectivityServerType.
+const (
+	ConnectivityServerTypeStun ConnectivityServerType = "stun"
+	ConnectivityServerTypeTurn ConnectivityServerType = "turn"
+)
+
+// Defines values for DNSRecordType.
+const (
+	DNSRecordTypeA    DNSRecordType = "A"
+	DNSRecordTypeAAAA DNSRecordType = "AAAA"
+)
+
 // Defines values for EventActivityCode.
 const (
 	EventActivityCodeAccountCreate                            EventActivityCode = "account.create"
@@ -69,6 +118,42 @@ const (
 	GeoLocationCheckActionDeny  GeoLocationCheckAction = "deny"
 )
 
+// Defines values for GetApiConfigParamsFormat.
+const (
+	GetApiConfigParamsFormatJson GetApiConfigParamsFormat = "json"
+	GetApiConfigParamsFormatYaml GetApiConfigParamsFormat = "yaml"
+)
+
+// Defines values for GetApiGroupsParamsSortBy.
+const (
+	GetApiGroupsParamsSortByName GetApiGroupsParamsSortBy = "name"
+)
+
+// Defines values for GetApiGroupsParamsOrder.
+const (
+	GetApiGroupsParamsOrderAsc  GetApiGroupsParamsOrder = "asc"
+	GetApiGroupsParamsOrderDesc GetApiGroupsParamsOrder = "desc"
+)
+
+// Defines values for GetApiUsageParamsFormat.
+const (
+	GetApiUsageParamsFormatCsv  GetApiUsageParamsFormat = "csv"
+	GetApiUsageParamsFormatJson GetApiUsageParamsFormat = "json"
+)
+
+// Defines values for GetApiPeersParamsSortBy.
+const (
+	GetApiPeersParamsSortByLastSeen GetApiPeersParamsSortBy = "last_seen"
+	GetApiPeersParamsSortByName     GetApiPeersParamsSortBy = "name"
+	GetApiPeersParamsSortByOs       GetApiPeersParamsSortBy = "os"
+)
+
+// Defines values for GetApiPeersParamsOrder.
+const (
+	GetApiPeersParamsOrderAsc  GetApiPeersParamsOrder = "asc"
+	GetApiPeersParamsOrderDesc GetApiPeersParamsOrder = "desc"
+)
+
 // Defines values for GroupIssued.
 const (
 	GroupIssuedApi         GroupIssued = "api"
@@ -104,6 +189,34 @@ const (
 	IngressPortAllocationRequestPortRangeProtocolUdp    IngressPortAllocationRequestPortRangeProtocol = "udp"
 )
 
+// Defines values for MonitorType.
+const (
+	MonitorTypeHttp MonitorType = "http"
+	MonitorTypeIcmp MonitorType = "icmp"
+	MonitorTypeTcp  MonitorType = "tcp"
+)
+
+// Defines values for NATType.
+const (
+	NATTypeEndpointDependent   NATType = "endpoint-dependent"
+	NATTypeEndpointIndependent NATType = "endpoint-independent"
+	NATTypeNone                NATType = "none"
+	NATTypeUdpBlocked          NATType = "udp-blocked"
+	NATTypeUnknown             NATType = "unknown"
+)
+
+// Defines values for NameserverGroupRoutedAnswers.
+const (
+	NameserverGroupRoutedAnswersOnly   NameserverGroupRoutedAnswers = "only"
+	NameserverGroupRoutedAnswersPrefer NameserverGroupRoutedAnswers = "prefer"
+)
+
+// Defines values for NameserverGroupRequestRoutedAnswers.
+const (
+	NameserverGroupRequestRoutedAnswersOnly   NameserverGroupRequestRoutedAnswers = "only"
+	NameserverGroupRequestRoutedAnswersPrefer NameserverGroupRequestRoutedAnswers = "prefer"
+)
+
 // Defines values for NameserverNsType.
 const (
 	NameserverNsTypeUdp NameserverNsType = "udp"
@@ -116,12 +229,59 @@ const (
 	NetworkResourceTypeSubnet NetworkResourceType = "subnet"
 )
 
+// Defines values for PeerActionAction.
+const (
+	PeerActionActionCollectStatus PeerActionAction = "collect_status"
+	PeerActionActionReauth        PeerActionAction = "reauth"
+	PeerActionActionRestart       PeerActionAction = "restart"
+	PeerActionActionRotateKey     PeerActionAction = "rotate_key"
+)
+
+// Defines values for PeerActionStatus.
+const (
+	PeerActionStatusDeclined  PeerActionStatus = "declined"
+	PeerActionStatusExpired   PeerActionStatus = "expired"
+	PeerActionStatusFailed    PeerActionStatus = "failed"
+	PeerActionStatusPending   PeerActionStatus = "pending"
+	PeerActionStatusSucceeded PeerActionStatus = "succeeded"
+)
+
+// Defines values for PeerActionRequestAction.
+const (
+	PeerActionRequestActionCollectStatus PeerActionRequestAction = "collect_status"
+	PeerActionRequestActionReauth        PeerActionRequestAction = "reauth"
+	PeerActionRequestActionRestart       PeerActionRequestAction = "restart"
+	PeerActionRequestActionRotateKey     PeerActionRequestAction = "rotate_key"
+)
+
+// Defines values for PeerDebugBundleStatus.
+const (
+	PeerDebugBundleStatusDeclined PeerDebugBundleStatus = "declined"
+	PeerDebugBundleStatusExpired  PeerDebugBundleStatus = "expired"
+	PeerDebugBundleStatusFailed   PeerDebugBundleStatus = "failed"
+	PeerDebugBundleStatusPending  PeerDebugBundleStatus = "pending"
+	PeerDebugBundleStatusUploaded PeerDebugBundleStatus = "uploaded"
+)
+
+// Defines values for PeerFirewallRuleDirection.
+const (
+	PeerFirewallRuleDirectionIn  PeerFirewallRuleDirection = "in"
+	PeerFirewallRuleDirectionOut PeerFirewallRuleDirection = "out"
+)
+
 // Defines values for PeerNetworkRangeCheckAction.
 const (
 	PeerNetworkRangeCheckActionAllow PeerNetworkRangeCheckAction = "allow"
 	PeerNetworkRangeCheckActionDeny  PeerNetworkRangeCheckAction = "deny"
 )
 
+// Defines values for PolicySimulationRequestProtocol.
+const (
+	PolicySimulationRequestProtocolIcmp PolicySimulationRequestProtocol = "icmp"
+	PolicySimulationRequestProtocolTcp  PolicySimulationRequestProtocol = "tcp"
+	PolicySimulationRequestProtocolUdp  PolicySimulationRequestProtocol = "udp"
+)
+
 // Defines values for PolicyRuleAction.
 const (
 	PolicyRuleActionAccept PolicyRuleAction = "accept"
@@ -171,6 +331,21 @@ const (
 	ResourceTypeSubnet ResourceType = "subnet"
 )
 
+// Defines values for RolloutKind.
+const (
+	RolloutKindPolicy RolloutKind = "policy"
+	RolloutKindRoute  RolloutKind = "route"
+)
+
+// Defines values for RolloutStatus.
+const (
+	RolloutStatusCanary     RolloutStatus = "canary"
+	RolloutStatusCancelled  RolloutStatus = "cancelled"
+	RolloutStatusPromoted   RolloutStatus = "promoted"
+	RolloutStatusReady      RolloutStatus = "ready"
+	RolloutStatusRolledBack RolloutStatus = "rolled_back"
+)
+
 // Defines values for UserStatus.
 const (
 	UserStatusActive  UserStatus = "active"
@@ -185,6 +360,27 @@ const (
 	UserPermissionsDashboardViewLimited UserPermissionsDashboardView = "limited"
 )
 
+// AccessHistory defines model for AccessHistory.
+type AccessHistory struct {
+	Snapshot AccessSnapshot `json:"snapshot"`
+	Topology Topology       `json:"topology"`
+}
+
+// AccessSnapshot defines model for AccessSnapshot.
+type AccessSnapshot struct {
+	// Events Number of activity events covered by the snapshot
+	Events int `json:"events"`
+
+	// Hash SHA-256 hash of the access configuration, snapshots with the same hash have the same configuration
+	Hash string `json:"hash"`
+
+	// Id Access snapshot ID
+	Id string `json:"id"`
+
+	// Timestamp Time of the last activity event covered by the snapshot, the access configuration was effective from then until the next snapshot
+	Timestamp time.Time `json:"timestamp"`
+}
+
 // AccessiblePeer defines model for AccessiblePeer.
 type AccessiblePeer struct {
 	// CityName Commonly used English name of the city
@@ -228,6 +424,12 @@ type Account struct {
 	Settings AccountSettings `json:"settings"`
 }
 
+// AccountExportRequest defines model for AccountExportRequest.
+type AccountExportRequest struct {
+	// Passphrase Passphrase encrypting the bundle, it is required to import the bundle
+	Passphrase string `json:"passphrase"`
+}
+
 // AccountExtraSettings defines model for AccountExtraSettings.
 type AccountExtraSettings struct {
 	// NetworkTrafficLogsEnabled Enables or disables network traffic logs. If enabled, all network traffic logs from peers will be stored.
@@ -236,10 +438,34 @@ type AccountExtraSettings struct {
 	// NetworkTrafficPacketCounterEnabled Enables or disables network traffic packet counter. If enabled, network packets and their size will be counted and reported. (This can have an slight impact on performance)
 	NetworkTrafficPacketCounterEnabled bool `json:"network_traffic_packet_counter_enabled"`
 
-	// PeerApprovalEnabled (Cloud only) Enables or disables peer approval globally. If enabled, all peers added will be in pending state until approved by an admin.
+	// PeerApprovalEnabled Enables or disables peer approval globally. If enabled, all peers added will be in pending state until approved by an admin.
 	PeerApprovalEnabled bool `json:"peer_approval_enabled"`
 }
 
+// AccountNetwork defines model for AccountNetwork.
+type AccountNetwork struct {
+	// NetworkRange Overlay network range of the account in CIDR notation, peers get their IP addresses from it. It has to be part of a private or shared address space with a prefix length between /8 and /28.
+	NetworkRange string `json:"network_range"`
+
+	// ReservedRanges Sub-ranges of the network range in CIDR notation excluded from the automatic allocation of peer IPs. Their IPs can still be assigned to peers as static IPs.
+	ReservedRanges []string `json:"reserved_ranges"`
+}
+
+// AccountNetworkRequest defines model for AccountNetworkRequest.
+type AccountNetworkRequest = AccountNetwork
+
+// AccountNetworkUpdate defines model for AccountNetworkUpdate.
+type AccountNetworkUpdate struct {
+	// MigratedPeers Peers moved to a new IP address because they weren't part of the new network range
+	MigratedPeers []PeerIPMigration `json:"migrated_peers"`
+
+	// NetworkRange Overlay network range of the account in CIDR notation, peers get their IP addresses from it. It has to be part of a private or shared address space with a prefix length between /8 and /28.
+	NetworkRange string `json:"network_range"`
+
+	// ReservedRanges Sub-ranges of the network range in CIDR notation excluded from the automatic allocation of peer IPs. Their IPs can still be assigned to peers as static IPs.
+	ReservedRanges []string `json:"reserved_ranges"`
+}
+
 // AccountRequest defines model for AccountRequest.
 type AccountRequest struct {
 	Settings AccountSettings `json:"settings"`
@@ -247,7 +473,15 @@ type AccountRequest struct {
 
 // AccountSettings defines model for AccountSettings.
 type AccountSettings struct {
-	Extra *AccountExtraSettings `json:"extra,omitempty"`
+	// ClientSettings Defaults of the client settings distributed to the peers. The clients apply them to the settings not set locally with the flags, the UI or the device policy, the client defaults apply to the omitted settings. The peers keep the settings they were installed with when the defaults aren't set. The keepalives are set with the connection tuning.
+	ClientSettings *ClientSettings `json:"client_settings,omitempty"`
+
+	// ConnectionTuning Timers of the peer connections overriding the client defaults, the defaults apply to the omitted timers. The timers set on a group take precedence over the account ones, the shortest one applies when a peer is part of several groups setting it. Changes apply to the peer connections once re-established.
+	ConnectionTuning *ConnectionTuning `json:"connection_tuning,omitempty"`
+
+	// DisabledNotifications Categories of the notifications the clients don't show to their users. session_expiry notifies before and when the login expires, posture_check notifies about the failed posture checks, exit_node when the exit node becomes unreachable and route_approval about the routes waiting for approval. All the notifications are shown when it's empty.
+	DisabledNotifications *[]AccountSettingsDisabledNotifications `json:"disabled_notifications,omitempty"`
+	Extra                 *AccountExtraSettings                   `json:"extra,omitempty"`
 
 	// GroupsPropagationEnabled Allows propagate the new user auto groups to peers that belongs to the user
 	GroupsPropagationEnabled *bool `json:"groups_propagation_enabled,omitempty"`
@@ -261,6 +495,9 @@ type AccountSettings struct {
 	// JwtGroupsEnabled Allows extract groups from JWT claim and add it to account groups.
 	JwtGroupsEnabled *bool `json:"jwt_groups_enabled,omitempty"`
 
+	// JwtGroupsMappingRules List of rules mapping the groups of one or more JWT claims onto account groups. When set, the rules take precedence over jwt_groups_claim_name.
+	JwtGroupsMappingRules *[]JWTGroupsMappingRule `json:"jwt_groups_mapping_rules,omitempty"`
+
 	// PeerInactivityExpiration Period of time of inactivity after which peer session expires (seconds).
 	PeerInactivityExpiration int `json:"peer_inactivity_expiration"`
 
@@ -273,13 +510,25 @@ type AccountSettings struct {
 	// PeerLoginExpirationEnabled Enables or disables peer login expiration globally. After peer's login has expired the user has to log in (authenticate). Applies only to peers that were added by a user (interactive SSO login).
 	PeerLoginExpirationEnabled bool `json:"peer_login_expiration_enabled"`
 
+	// PeerLoginExpirationGraceEnabled Keeps the peers with an expired login connected with access limited to the remediation policies until their user logs in again, instead of disconnecting them.
+	PeerLoginExpirationGraceEnabled *bool `json:"peer_login_expiration_grace_enabled,omitempty"`
+
+	// PeerLoginExpirationNotification Period of time before the peer login expires at which its user is notified (seconds). No notification is sent when 0.
+	PeerLoginExpirationNotification *int `json:"peer_login_expiration_notification,omitempty"`
+
 	// RegularUsersViewBlocked Allows blocking regular users from viewing parts of the system.
 	RegularUsersViewBlocked bool `json:"regular_users_view_blocked"`
 
 	// RoutingPeerDnsResolutionEnabled Enables or disables DNS resolution on the routing peers
 	RoutingPeerDnsResolutionEnabled *bool `json:"routing_peer_dns_resolution_enabled,omitempty"`
+
+	// RoutingPeerForwardedTrafficFilteringEnabled Makes the routing peers filter the traffic they forward to the networks of the routes without access control groups with the policies allowing traffic to the routing peers, instead of accepting all of it
+	RoutingPeerForwardedTrafficFilteringEnabled *bool `json:"routing_peer_forwarded_traffic_filtering_enabled,omitempty"`
 }
 
+// AccountSettingsDisabledNotifications defines model for AccountSettings.DisabledNotifications.
+type AccountSettingsDisabledNotifications string
+
 // AvailablePorts defines model for AvailablePorts.
 type AvailablePorts struct {
 	// Tcp Number of available TCP  ports left on the ingress peer
@@ -289,6 +538,72 @@ type AvailablePorts struct {
 	Udp int `json:"udp"`
 }
 
+// BulkOperation defines model for BulkOperation.
+type BulkOperation struct {
+	// Body Request body of the operation, the same as the body of the single resource endpoint
+	Body *map[string]interface{} `json:"body,omitempty"`
+
+	// Method HTTP method of the operation
+	Method BulkOperationMethod `json:"method"`
+
+	// Path API path of the resource relative to /api, e.g. /groups or /policies/{policyId}
+	Path string `json:"path"`
+}
+
+// BulkOperationMethod HTTP method of the operation
+type BulkOperationMethod string
+
+// BulkOperationResult defines model for BulkOperationResult.
+type BulkOperationResult struct {
+	// Body Response body of the operation, the same as the response of the single resource endpoint
+	Body *map[string]interface{} `json:"body,omitempty"`
+
+	// Error Error message of the failed operation
+	Error *string `json:"error,omitempty"`
+
+	// ErrorCode Stable code of the error of the failed operation
+	ErrorCode *string `json:"error_code,omitempty"`
+
+	// Index Position of the operation in the request
+	Index int `json:"index"`
+
+	// RolledBack Operation succeeded but its changes were rolled back because another operation of the atomic request failed
+	RolledBack bool `json:"rolled_back"`
+
+	// Skipped Operation was not applied because a previous operation failed and stop_on_error or atomic is set
+	Skipped bool `json:"skipped"`
+
+	// Status HTTP status code of the operation
+	Status int `json:"status"`
+}
+
+// BulkRequest defines model for BulkRequest.
+type BulkRequest struct {
+	// Atomic Applies all the operations or none of them, the first failed operation skips the remaining ones and rolls back the operations applied before it
+	Atomic *bool `json:"atomic,omitempty"`
+
+	// Operations Operations applied in order
+	Operations []BulkOperation `json:"operations"`
+
+	// StopOnError Skips the remaining operations after the first failed one, the operations applied before it are kept
+	StopOnError *bool `json:"stop_on_error,omitempty"`
+}
+
+// BulkResponse defines model for BulkResponse.
+type BulkResponse struct {
+	// Failed Number of failed operations
+	Failed int `json:"failed"`
+
+	// Results Results of the operations in the order of the request
+	Results []BulkOperationResult `json:"results"`
+
+	// RolledBack Changes of the request were rolled back because an operation of the atomic request failed
+	RolledBack bool `json:"rolled_back"`
+
+	// Succeeded Number of succeeded operations
+	Succeeded int `json:"succeeded"`
+}
+
 // Checks List of objects that perform the actual checks
 type Checks struct {
 	// GeoLocationCheck Posture check for geo location
@@ -305,6 +620,9 @@ type Checks struct {
 
 	// ProcessCheck Posture Check for binaries exist and are running in the peer’s system
 	ProcessCheck *ProcessCheck `json:"process_check,omitempty"`
+
+	// UptimeCheck Posture check failing the peers running without a reboot for longer than the maximum uptime, a reboot applying the pending OS updates on most systems. The peers not reporting their boot time, like the mobile peers and the older clients, fail the check.
+	UptimeCheck *UptimeCheck `json:"uptime_check,omitempty"`
 }
 
 // City Describe city geographical location information
@@ -319,6 +637,210 @@ type City struct {
 // CityName Commonly used English name of the city
 type CityName = string
 
+// ClientSettings Defaults of the client settings distributed to the peers. The clients apply them to the settings not set locally with the flags, the UI or the device policy, the client defaults apply to the omitted settings. The peers keep the settings they were installed with when the defaults aren't set. The keepalives are set with the connection tuning.
+type ClientSettings struct {
+	// AcceptRoutes Lets the clients install the routes of the account
+	AcceptRoutes *bool `json:"accept_routes,omitempty"`
+
+	// DnsManagementEnabled Lets the clients configure the DNS of the host
+	DnsManagementEnabled *bool `json:"dns_management_enabled,omitempty"`
+
+	// FirewallMode How the clients enforce the access policies, enforce filters the traffic of the peers with the firewall of the client and disabled leaves the traffic unfiltered
+	FirewallMode *ClientSettingsFirewallMode `json:"firewall_mode,omitempty"`
+}
+
+// ClientSettingsFirewallMode How the clients enforce the access policies, enforce filters the traffic of the peers with the firewall of the client and disabled leaves the traffic unfiltered
+type ClientSettingsFirewallMode string
+
+// ConfigChange defines model for ConfigChange.
+type ConfigChange struct {
+	// Action Change of the object
+	Action ConfigChangeAction `json:"action"`
+
+	// Fields Changed fields of an updated object
+	Fields *[]string `json:"fields,omitempty"`
+
+	// Kind Kind of the changed object
+	Kind ConfigChangeKind `json:"kind"`
+
+	// Name Name of the changed object, the network identifier for routes
+	Name string `json:"name"`
+}
+
+// ConfigChangeAction Change of the object
+type ConfigChangeAction string
+
+// ConfigChangeKind Kind of the changed object
+type ConfigChangeKind string
+
+// ConfigPlan defines model for ConfigPlan.
+type ConfigPlan struct {
+	// Changes Changes applying the config makes, in the order they are applied
+	Changes []ConfigChange `json:"changes"`
+
+	// Warnings Objects of the account the config can't manage
+	Warnings []string `json:"warnings"`
+}
+
+// ConflictResponse defines model for ConflictResponse.
+type ConflictResponse struct {
+	// Code HTTP status code
+	Code int `json:"code"`
+
+	// CurrentVersion Current ETag of the resource, set if the version of the resource doesn't match If-Match
+	CurrentVersion *string `json:"current_version,omitempty"`
+
+	// ErrorCode Stable code of the error
+	ErrorCode string `json:"error_code"`
+
+	// Message Reason of the conflict
+	Message string `json:"message"`
+}
+
+// ConnectionSetupPhase Durations and outcomes of a phase of the peer connection setups
+type ConnectionSetupPhase struct {
+	// Failures Number of the failed phases
+	Failures int `json:"failures"`
+
+	// MedianMs Median duration of the successful phases in milliseconds
+	MedianMs int `json:"median_ms"`
+
+	// P95Ms 95th percentile of the duration of the successful phases in milliseconds
+	P95Ms int `json:"p95_ms"`
+
+	// Phase Phase of the connection setups, one of signal, gathering, nomination or handshake
+	Phase string `json:"phase"`
+
+	// Successes Number of the successful phases
+	Successes int `json:"successes"`
+
+	// Version Client version of the peers, set in the summary which groups the phases by the version
+	Version *string `json:"version,omitempty"`
+}
+
+// ConnectionTuning Timers of the peer connections overriding the client defaults, the defaults apply to the omitted timers. The timers set on a group take precedence over the account ones, the shortest one applies when a peer is part of several groups setting it. Changes apply to the peer connections once re-established.
+type ConnectionTuning struct {
+	// HandshakeRetryMaxInterval Maximum interval between the connection offers sent while the connection isn't established, in seconds
+	HandshakeRetryMaxInterval *int `json:"handshake_retry_max_interval,omitempty"`
+
+	// HandshakeRetryTimeout Period after which no more connection offers are sent until the next connection change, in seconds
+	HandshakeRetryTimeout *int `json:"handshake_retry_timeout,omitempty"`
+
+	// IceDisconnectedTimeout Period without ICE traffic after which the connection is considered disconnected, in seconds
+	IceDisconnectedTimeout *int `json:"ice_disconnected_timeout,omitempty"`
+
+	// IceFailedTimeout Period after the disconnection after which the ICE connection is considered failed, in seconds
+	IceFailedTimeout *int `json:"ice_failed_timeout,omitempty"`
+
+	// IceKeepalive Interval of the ICE keepalive messages, in seconds
+	IceKeepalive *int `json:"ice_keepalive,omitempty"`
+
+	// WgKeepalive WireGuard persistent keepalive interval, in seconds
+	WgKeepalive *int `json:"wg_keepalive,omitempty"`
+}
+
+// ConnectivityServer defines model for ConnectivityServer.
+type ConnectivityServer struct {
+	// Error Reason the server was unreachable
+	Error *string `json:"error,omitempty"`
+
+	// Reachable Whether the server answered the peer
+	Reachable bool `json:"reachable"`
+
+	// Type Type of the server
+	Type ConnectivityServerType `json:"type"`
+
+	// Uri URI of the STUN or TURN server
+	Uri string `json:"uri"`
+}
+
+// ConnectivityServerType Type of the server
+type ConnectivityServerType string
+
+// ConnectivitySite defines model for ConnectivitySite.
+type ConnectivitySite struct {
+	// CityName City of the public IP address
+	CityName string `json:"city_name"`
+
+	// CountryCode Country code of the public IP address
+	CountryCode string `json:"country_code"`
+
+	// NatTypes NAT types reported by the peers of the site
+	NatTypes []NATType `json:"nat_types"`
+
+	// Peers Number of peers behind the public IP address
+	Peers int `json:"peers"`
+
+	// PublicIp Public IP address the peers connect from
+	PublicIp string `json:"public_ip"`
+
+	// RelayRatio Average relay ratio of the peers of the site
+	RelayRatio float64 `json:"relay_ratio"`
+}
+
+// ConnectivitySummary defines model for ConnectivitySummary.
+type ConnectivitySummary struct {
+	// ConnectionSetups Phases of the peer connection setups of the latest reports per client version, the durations are averaged over the peers weighted by their successful phases
+	ConnectionSetups []ConnectionSetupPhase `json:"connection_setups"`
+
+	// NatTypes Number of peers per NAT type
+	NatTypes []NATTypeCount `json:"nat_types"`
+
+	// RelayRatio Average relay ratio of the peers with connections
+	RelayRatio float64 `json:"relay_ratio"`
+
+	// ReportedPeers Number of peers that reported their connectivity within the last 24 hours
+	ReportedPeers int `json:"reported_peers"`
+
+	// Sites Public IP addresses of the peers, the most relayed first
+	Sites []ConnectivitySite `json:"sites"`
+
+	// StunUnreachablePeers Number of peers that couldn't reach at least one STUN server
+	StunUnreachablePeers int `json:"stun_unreachable_peers"`
+
+	// TurnUnreachablePeers Number of peers that couldn't reach at least one TURN server
+	TurnUnreachablePeers int `json:"turn_unreachable_peers"`
+
+	// UnreachableRoutedNetworks Number of the networks the routing peers advertise but can't reach
+	UnreachableRoutedNetworks int `json:"unreachable_routed_networks"`
+}
+
+// ControlPlaneHealth defines model for ControlPlaneHealth.
+type ControlPlaneHealth struct {
+	// ConnectedPeers Number of peers connected to the management service
+	ConnectedPeers int `json:"connected_peers"`
+
+	// ExpiredSetupKeys Number of setup keys that expired without being revoked
+	ExpiredSetupKeys int `json:"expired_setup_keys"`
+
+	// ExpiringSetupKeys Number of valid setup keys that expire within 7 days
+	ExpiringSetupKeys int `json:"expiring_setup_keys"`
+
+	// LastSyncAt Time of the latest sync stream opened for a peer, omitted without sync streams since the management service started
+	LastSyncAt *time.Time `json:"last_sync_at,omitempty"`
+
+	// LastSyncErrorAt Time of the latest failed sync stream, omitted without failed sync streams since the management service started
+	LastSyncErrorAt *time.Time `json:"last_sync_error_at,omitempty"`
+
+	// LoginExpiredPeers Number of peers whose login expired
+	LoginExpiredPeers int `json:"login_expired_peers"`
+
+	// LoginExpiringPeers Number of peers whose login expires within 7 days
+	LoginExpiringPeers int `json:"login_expiring_peers"`
+
+	// PendingApprovalPeers Number of peers waiting for an approval to join the network
+	PendingApprovalPeers int `json:"pending_approval_peers"`
+
+	// SyncErrors Number of sync streams of the peers that failed within the last hour
+	SyncErrors int `json:"sync_errors"`
+
+	// Syncs Number of sync streams the management service opened for the peers within the last hour
+	Syncs int `json:"syncs"`
+
+	// TotalPeers Number of peers of the account
+	TotalPeers int `json:"total_peers"`
+}
+
 // Country Describe country geographical location information
 type Country struct {
 	// CountryCode 2-letter ISO 3166-1 alpha-2 code that represents the country
@@ -336,9 +858,18 @@ type CreateSetupKeyRequest struct {
 	// AllowExtraDnsLabels Allow extra DNS labels to be added to the peer
 	AllowExtraDnsLabels *bool `json:"allow_extra_dns_labels,omitempty"`
 
+	// AllowedIpRanges List of source IP ranges in CIDR notation peers are allowed to register from with this key. Empty list allows any source IP.
+	AllowedIpRanges *[]string `json:"allowed_ip_ranges,omitempty"`
+
 	// AutoGroups List of group IDs to auto-assign to peers registered with this key
 	AutoGroups []string `json:"auto_groups"`
 
+	// AutoPostureChecks List of posture check IDs peers have to pass to register with this key
+	AutoPostureChecks *[]string `json:"auto_posture_checks,omitempty"`
+
+	// AutoRoutes List of network route IDs to auto-assign to peers registered with this key. The peers are added as routing peers of the routes.
+	AutoRoutes *[]string `json:"auto_routes,omitempty"`
+
 	// Ephemeral Indicate that the peer will be ephemeral or not
 	Ephemeral *bool `json:"ephemeral,omitempty"`
 
@@ -348,6 +879,9 @@ type CreateSetupKeyRequest struct {
 	// Name Setup Key name
 	Name string `json:"name"`
 
+	// PeerNamePrefix Prefix added to the name of the peers registered with this key
+	PeerNamePrefix *string `json:"peer_name_prefix,omitempty"`
+
 	// Type Setup key type, one-off for single time usage and reusable
 	Type string `json:"type"`
 
@@ -355,12 +889,51 @@ type CreateSetupKeyRequest struct {
 	UsageLimit int `json:"usage_limit"`
 }
 
+// DNSRecord defines model for DNSRecord.
+type DNSRecord struct {
+	// Name Fully qualified domain name of the record
+	Name string `json:"name"`
+
+	// Ttl Time to live of the record in seconds
+	Ttl int `json:"ttl"`
+
+	// Type Record type
+	Type DNSRecordType `json:"type"`
+
+	// Value IP address of the record
+	Value string `json:"value"`
+}
+
+// DNSRecordType Record type
+type DNSRecordType string
+
 // DNSSettings defines model for DNSSettings.
 type DNSSettings struct {
 	// DisabledManagementGroups Groups whose DNS management is disabled
 	DisabledManagementGroups []string `json:"disabled_management_groups"`
 }
 
+// DNSZone defines model for DNSZone.
+type DNSZone struct {
+	// Domain DNS domain of the peers
+	Domain string `json:"domain"`
+
+	// Records A and AAAA records of the peers
+	Records []DNSRecord `json:"records"`
+}
+
+// ErrorResponse defines model for ErrorResponse.
+type ErrorResponse struct {
+	// Code HTTP status code
+	Code int `json:"code"`
+
+	// ErrorCode Stable code of the error, e.g. ROUTE_CONFLICT, the generic code of the HTTP status (NOT_FOUND, INVALID_ARGUMENT, ...) for the errors without a specific one
+	ErrorCode string `json:"error_code"`
+
+	// Message Error message
+	Message string `json:"message"`
+}
+
 // Event defines model for Event.
 type Event struct {
 	// Activity The activity that occurred during the event
@@ -394,6 +967,39 @@ type Event struct {
 // EventActivityCode The string code of the activity that occurred during the event
 type EventActivityCode string
 
+// ExitNodeUsage defines model for ExitNodeUsage.
+type ExitNodeUsage struct {
+	// ExitNodeId ID of the exit node peer
+	ExitNodeId string `json:"exit_node_id"`
+
+	// ExitNodeName Name of the exit node peer
+	ExitNodeName string `json:"exit_node_name"`
+
+	// LastReportedAt Last time the exit node reported traffic of the peer in the month
+	LastReportedAt time.Time `json:"last_reported_at"`
+
+	// Month Month (UTC) of the usage in YYYY-MM format
+	Month string `json:"month"`
+
+	// PeerId ID of the peer the traffic was forwarded for
+	PeerId string `json:"peer_id"`
+
+	// PeerName Name of the peer the traffic was forwarded for
+	PeerName string `json:"peer_name"`
+
+	// QuotaExceeded Indicates that the peer exceeded the exit node quota of its groups in the month and can't use the exit nodes until the next month
+	QuotaExceeded bool `json:"quota_exceeded"`
+
+	// RxBytes Traffic the exit node sent to the peer, in bytes
+	RxBytes int64 `json:"rx_bytes"`
+
+	// TxBytes Traffic the exit node forwarded from the peer, in bytes
+	TxBytes int64 `json:"tx_bytes"`
+
+	// UserId ID of the user the peer belongs to, empty for the peers registered with a setup key
+	UserId string `json:"user_id"`
+}
+
 // GeoLocationCheck Posture check for geo location
 type GeoLocationCheck struct {
 	// Action Action to take upon policy match
@@ -408,29 +1014,83 @@ type GeoLocationCheckAction string
 
 // Group defines model for Group.
 type Group struct {
+	// ConnectionTuning Timers of the peer connections overriding the client defaults, the defaults apply to the omitted timers. The timers set on a group take precedence over the account ones, the shortest one applies when a peer is part of several groups setting it. Changes apply to the peer connections once re-established.
+	ConnectionTuning *ConnectionTuning `json:"connection_tuning,omitempty"`
+
+	// EphemeralPolicy Cleanup settings deleting the peers of the group after they have been offline for a period. When a peer is part of several groups with a policy, the shortest period applies.
+	EphemeralPolicy *GroupEphemeralPolicy `json:"ephemeral_policy,omitempty"`
+
+	// ExitNodeQuota Limits the traffic the exit nodes forward for the peers of the group in a month (UTC). The traffic of the peers of a user adds up, the peers registered with a setup key are limited individually. When a peer is part of several groups with a quota, the smallest quota applies. The exit routes are withdrawn from the peers exceeding the quota until the next month, the access control groups of the exit routes have to be set to block their traffic on the exit nodes.
+	ExitNodeQuota *GroupExitNodeQuota `json:"exit_node_quota,omitempty"`
+
 	// Id Group ID
 	Id string `json:"id"`
 
 	// Issued How the group was issued (api, integration, jwt)
 	Issued *GroupIssued `json:"issued,omitempty"`
 
+	// MembershipRule Rule adding the matching peers to the group and removing the others when they register or update. The peers of a group with a rule can't be managed manually.
+	MembershipRule *GroupMembershipRule `json:"membership_rule,omitempty"`
+
 	// Name Group Name identifier
 	Name string `json:"name"`
 
+	// NestedGroups List of IDs of the groups whose peers are members of this group too
+	NestedGroups *[]string `json:"nested_groups,omitempty"`
+
 	// Peers List of peers object
 	Peers []PeerMinimum `json:"peers"`
 
 	// PeersCount Count of peers associated to the group
-	PeersCount int        `json:"peers_count"`
-	Resources  []Resource `json:"resources"`
+	PeersCount int `json:"peers_count"`
+
+	// PortPolicy Pins the WireGuard listen port of the peers of the group and constrains the transports of their connections, for peers behind firewalls permitting only known ports. When a peer is part of several groups with a policy, the intersection of the port ranges applies and the TCP 443 mode applies if any group enables it. Changes apply once the peers reconnect.
+	PortPolicy *GroupPortPolicy `json:"port_policy,omitempty"`
+	Resources  []Resource       `json:"resources"`
 
 	// ResourcesCount Count of resources associated to the group
 	ResourcesCount int `json:"resources_count"`
+
+	// SessionPolicy Expiration settings overriding the account settings for the peers of the group. When a peer is part of several groups with a policy, the strictest settings apply.
+	SessionPolicy *GroupSessionPolicy `json:"session_policy,omitempty"`
+}
+
+// GroupEphemeralPolicy Cleanup settings deleting the peers of the group after they have been offline for a period. When a peer is part of several groups with a policy, the shortest period applies.
+type GroupEphemeralPolicy struct {
+	// CleanupAfter Period of time a peer has to be offline before it is deleted, in seconds
+	CleanupAfter *int `json:"cleanup_after,omitempty"`
+
+	// IncludeNonEphemeral Applies the policy to the peers of the group that weren't registered as ephemeral too
+	IncludeNonEphemeral *bool `json:"include_non_ephemeral,omitempty"`
+}
+
+// GroupExitNodeQuota Limits the traffic the exit nodes forward for the peers of the group in a month (UTC). The traffic of the peers of a user adds up, the peers registered with a setup key are limited individually. When a peer is part of several groups with a quota, the smallest quota applies. The exit routes are withdrawn from the peers exceeding the quota until the next month, the access control groups of the exit routes have to be set to block their traffic on the exit nodes.
+type GroupExitNodeQuota struct {
+	// MonthlyBytes Traffic in both directions the exit nodes forward for a user or a peer in a month, in bytes
+	MonthlyBytes int64 `json:"monthly_bytes"`
 }
 
 // GroupIssued How the group was issued (api, integration, jwt)
 type GroupIssued string
 
+// GroupMembershipRule Rule adding the matching peers to the group and removing the others when they register or update. The peers of a group with a rule can't be managed manually.
+type GroupMembershipRule struct {
+	// Countries ISO 3166-1 alpha-2 codes of the countries the peers connect from
+	Countries *[]string `json:"countries,omitempty"`
+
+	// HostnameRegex Regular expression matching the hostname of the peers
+	HostnameRegex *string `json:"hostname_regex,omitempty"`
+
+	// Os Operating system families of the peers
+	Os *[]string `json:"os,omitempty"`
+
+	// PostureCheckIds IDs of the posture checks the peers have to pass
+	PostureCheckIds *[]string `json:"posture_check_ids,omitempty"`
+
+	// SetupKeyIds IDs of the setup keys the peers were registered with
+	SetupKeyIds *[]string `json:"setup_key_ids,omitempty"`
+}
+
 // GroupMinimum defines model for GroupMinimum.
 type GroupMinimum struct {
 	// Id Group ID
@@ -452,14 +1112,65 @@ type GroupMinimum struct {
 // GroupMinimumIssued How the group was issued (api, integration, jwt)
 type GroupMinimumIssued string
 
+// GroupPortPolicy Pins the WireGuard listen port of the peers of the group and constrains the transports of their connections, for peers behind firewalls permitting only known ports. When a peer is part of several groups with a policy, the intersection of the port ranges applies and the TCP 443 mode applies if any group enables it. Changes apply once the peers reconnect.
+type GroupPortPolicy struct {
+	// Tcp443Only Limits the peers to the connections relayed over TCP port 443, the peers send no UDP traffic to the other peers and the relay servers
+	Tcp443Only *bool `json:"tcp443_only,omitempty"`
+
+	// WgPortRangeEnd Last WireGuard listen port the peers can use, the port is fixed when it equals the start
+	WgPortRangeEnd *int `json:"wg_port_range_end,omitempty"`
+
+	// WgPortRangeStart First WireGuard listen port the peers can use
+	WgPortRangeStart *int `json:"wg_port_range_start,omitempty"`
+}
+
 // GroupRequest defines model for GroupRequest.
 type GroupRequest struct {
+	// ConnectionTuning Timers of the connections of the peers of the group. The tuning of the group is kept when omitted on update and removed when empty.
+	ConnectionTuning *ConnectionTuning `json:"connection_tuning,omitempty"`
+
+	// EphemeralPolicy Cleanup settings of the offline peers of the group. The policy of the group is kept when omitted on update and removed when empty.
+	EphemeralPolicy *GroupEphemeralPolicy `json:"ephemeral_policy,omitempty"`
+
+	// ExitNodeQuota Monthly traffic the exit nodes forward for the peers of the group. The quota of the group is kept when omitted on update and removed when its monthly bytes are 0.
+	ExitNodeQuota *GroupExitNodeQuota `json:"exit_node_quota,omitempty"`
+
+	// MembershipRule Rule managing the peers of the group. The rule of the group is kept when omitted on update and removed when empty.
+	MembershipRule *GroupMembershipRule `json:"membership_rule,omitempty"`
+
 	// Name Group name identifier
 	Name string `json:"name"`
 
+	// NestedGroups List of IDs of the groups whose peers are members of this group too
+	NestedGroups *[]string `json:"nested_groups,omitempty"`
+
 	// Peers List of peers ids
-	Peers     *[]string   `json:"peers,omitempty"`
-	Resources *[]Resource `json:"resources,omitempty"`
+	Peers *[]string `json:"peers,omitempty"`
+
+	// PortPolicy WireGuard listen port and transports of the peers of the group. The policy of the group is kept when omitted on update and removed when empty.
+	PortPolicy *GroupPortPolicy `json:"port_policy,omitempty"`
+	Resources  *[]Resource      `json:"resources,omitempty"`
+
+	// SessionPolicy Expiration settings of the peers of the group. The policy of the group is kept when omitted on update and removed when empty.
+	SessionPolicy *GroupSessionPolicy `json:"session_policy,omitempty"`
+}
+
+// GroupSessionPolicy Expiration settings overriding the account settings for the peers of the group. When a peer is part of several groups with a policy, the strictest settings apply.
+type GroupSessionPolicy struct {
+	// InactivityExpiration Period of time of inactivity after which the session of the peers expires, in seconds. The account setting applies when omitted.
+	InactivityExpiration *int `json:"inactivity_expiration,omitempty"`
+
+	// InactivityExpirationEnabled Enables or disables the inactivity expiration of the peers, the account setting applies when omitted
+	InactivityExpirationEnabled *bool `json:"inactivity_expiration_enabled,omitempty"`
+
+	// LoginExpiration Period of time after which the login of the peers expires, in seconds. The account setting applies when omitted.
+	LoginExpiration *int `json:"login_expiration,omitempty"`
+
+	// LoginExpirationEnabled Enables or disables the login expiration of the peers, the account setting applies when omitted
+	LoginExpirationEnabled *bool `json:"login_expiration_enabled,omitempty"`
+
+	// RequireReauth Applies the expiration to the peers even if it was disabled on the peer itself
+	RequireReauth *bool `json:"require_reauth,omitempty"`
 }
 
 // IngressPeer defines model for IngressPeer.
@@ -595,6 +1306,24 @@ type IngressPortAllocationRequestPortRange struct {
 // IngressPortAllocationRequestPortRangeProtocol The protocol accepted by the port range
 type IngressPortAllocationRequestPortRangeProtocol string
 
+// JWTGroupsMappingRule defines model for JWTGroupsMappingRule.
+type JWTGroupsMappingRule struct {
+	// Claim Path of the claim holding the groups. Nested claims are separated by dots.
+	Claim string `json:"claim"`
+
+	// Prefix Only groups starting with this prefix are mapped
+	Prefix *string `json:"prefix,omitempty"`
+
+	// Rename Map of group names (after the prefix is stripped) to account group names
+	Rename *map[string]string `json:"rename,omitempty"`
+
+	// StripPrefix Removes the prefix from the mapped group names
+	StripPrefix *bool `json:"strip_prefix,omitempty"`
+}
+
+// Labels Key/value labels, the keys and the values start and end with an alphanumeric character and can contain dots, dashes and slashes, up to 63 characters
+type Labels map[string]string
+
 // Location Describe geographical location information
 type Location struct {
 	// CityName Commonly used English name of the city
@@ -619,6 +1348,102 @@ type MinVersionCheck struct {
 // NBVersionCheck Posture check for the version of operating system
 type NBVersionCheck = MinVersionCheck
 
+// Monitor defines model for Monitor.
+type Monitor struct {
+	// Address IP address checked by the tcp and icmp monitors
+	Address *string `json:"address,omitempty"`
+
+	// Enabled Monitor status
+	Enabled bool `json:"enabled"`
+
+	// Id Monitor ID
+	Id string `json:"id"`
+
+	// IntervalSeconds Interval of the checks in seconds
+	IntervalSeconds int `json:"interval_seconds"`
+
+	// Name Monitor name
+	Name string `json:"name"`
+
+	// PeerId Identifier of the peer running the checks, e.g. a routing peer
+	PeerId string `json:"peer_id"`
+
+	// Port TCP port connected to by the tcp monitors
+	Port *int `json:"port,omitempty"`
+
+	// TimeoutSeconds Timeout of a check in seconds
+	TimeoutSeconds int `json:"timeout_seconds"`
+
+	// Type Check run by the monitor, a TCP connection, an HTTP GET request or an ICMP ping
+	Type MonitorType `json:"type"`
+
+	// Url URL requested by the http monitors, a server error status fails the check
+	Url *string `json:"url,omitempty"`
+}
+
+// MonitorRequest defines model for MonitorRequest.
+type MonitorRequest struct {
+	// Address IP address checked by the tcp and icmp monitors
+	Address *string `json:"address,omitempty"`
+
+	// Enabled Monitor status
+	Enabled bool `json:"enabled"`
+
+	// IntervalSeconds Interval of the checks in seconds, between 10 and 86400. Defaults to 60
+	IntervalSeconds *int `json:"interval_seconds,omitempty"`
+
+	// Name Monitor name
+	Name string `json:"name"`
+
+	// PeerId Identifier of the peer running the checks, e.g. a routing peer
+	PeerId string `json:"peer_id"`
+
+	// Port TCP port connected to by the tcp monitors
+	Port *int `json:"port,omitempty"`
+
+	// TimeoutSeconds Timeout of a check in seconds, up to 30 and shorter than the interval. Defaults to 5
+	TimeoutSeconds *int `json:"timeout_seconds,omitempty"`
+
+	// Type Check run by the monitor, a TCP connection, an HTTP GET request or an ICMP ping
+	Type MonitorType `json:"type"`
+
+	// Url URL requested by the http monitors, a server error status fails the check
+	Url *string `json:"url,omitempty"`
+}
+
+// MonitorResult defines model for MonitorResult.
+type MonitorResult struct {
+	// Error Reason the check failed
+	Error *string `json:"error,omitempty"`
+
+	// HttpStatus Response status code of the http monitors
+	HttpStatus *int `json:"http_status,omitempty"`
+
+	// LatencyMs Round-trip time of the check in milliseconds
+	LatencyMs float64 `json:"latency_ms"`
+
+	// Reachable Indicates whether the target passed the check
+	Reachable bool `json:"reachable"`
+
+	// Timestamp Time of the check
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// MonitorType Check run by the monitor, a TCP connection, an HTTP GET request or an ICMP ping
+type MonitorType string
+
+// NATType NAT type of the peer classified by comparing the public addresses seen by the STUN servers
+type NATType string
+
+// NATTypeCount defines model for NATTypeCount.
+type NATTypeCount struct {
+	// NatType NAT type of the peer classified by comparing the public addresses seen by the STUN servers
+	NatType NATType `json:"nat_type"`
+
+	// Peers Number of peers with the NAT type
+	Peers int `json:"peers"`
+}
+
 // Nameserver defines model for Nameserver.
 type Nameserver struct {
 	// Ip Nameserver IP
@@ -645,6 +1470,9 @@ type NameserverGroup struct {
 	// Enabled Nameserver group status
 	Enabled bool `json:"enabled"`
 
+	// ForwarderPeer ID of the routing peer serving the domains of the group with its DNS forwarder. Peers query the forwarder through the tunnel instead of the nameservers, which only have to be reachable by the routing peer. The peers need a policy allowing access to the routing peer. Only for nameserver groups with match domains.
+	ForwarderPeer *string `json:"forwarder_peer,omitempty"`
+
 	// Groups Distribution group IDs that defines group of peers that will use this nameserver group
 	Groups []string `json:"groups"`
 
@@ -660,10 +1488,16 @@ type NameserverGroup struct {
 	// Primary Defines if a nameserver group is primary that resolves all domains. It should be true only if domains list is empty.
 	Primary bool `json:"primary"`
 
+	// RoutedAnswers Reorders the A and AAAA answers of the nameservers so the addresses reachable through a route of the peer come first with "prefer", or drops the other addresses when at least one answer is routed with "only". Answers are returned as is when not set.
+	RoutedAnswers *NameserverGroupRoutedAnswers `json:"routed_answers,omitempty"`
+
 	// SearchDomainsEnabled Search domain status for match domains. It should be true only if domains list is not empty.
 	SearchDomainsEnabled bool `json:"search_domains_enabled"`
 }
 
+// NameserverGroupRoutedAnswers Reorders the A and AAAA answers of the nameservers so the addresses reachable through a route of the peer come first with "prefer", or drops the other addresses when at least one answer is routed with "only". Answers are returned as is when not set.
+type NameserverGroupRoutedAnswers string
+
 // NameserverGroupRequest defines model for NameserverGroupRequest.
 type NameserverGroupRequest struct {
 	// Description Description of the nameserver group
@@ -675,6 +1509,9 @@ type NameserverGroupRequest struct {
 	// Enabled Nameserver group status
 	Enabled bool `json:"enabled"`
 
+	// ForwarderPeer ID of the routing peer serving the domains of the group with its DNS forwarder. Peers query the forwarder through the tunnel instead of the nameservers, which only have to be reachable by the routing peer. The peers need a policy allowing access to the routing peer. Only for nameserver groups with match domains.
+	ForwarderPeer *string `json:"forwarder_peer,omitempty"`
+
 	// Groups Distribution group IDs that defines group of peers that will use this nameserver group
 	Groups []string `json:"groups"`
 
@@ -687,10 +1524,16 @@ type NameserverGroupRequest struct {
 	// Primary Defines if a nameserver group is primary that resolves all domains. It should be true only if domains list is empty.
 	Primary bool `json:"primary"`
 
+	// RoutedAnswers Reorders the A and AAAA answers of the nameservers so the addresses reachable through a route of the peer come first with "prefer", or drops the other addresses when at least one answer is routed with "only". Answers are returned as is when not set.
+	RoutedAnswers *NameserverGroupRequestRoutedAnswers `json:"routed_answers,omitempty"`
+
 	// SearchDomainsEnabled Search domain status for match domains. It should be true only if domains list is not empty.
 	SearchDomainsEnabled bool `json:"search_domains_enabled"`
 }
 
+// NameserverGroupRequestRoutedAnswers Reorders the A and AAAA answers of the nameservers so the addresses reachable through a route of the peer come first with "prefer", or drops the other addresses when at least one answer is routed with "only". Answers are returned as is when not set.
+type NameserverGroupRequestRoutedAnswers string
+
 // Network defines model for Network.
 type Network struct {
 	// Description Network description
@@ -726,7 +1569,7 @@ type NetworkRequest struct {
 
 // NetworkResource defines model for NetworkResource.
 type NetworkResource struct {
-	// Address Network resource address (either a direct host like 1.1.1.1 or 1.1.1.1/32, or a subnet like 192.168.178.0/24, or domains like example.com and *.example.com)
+	// Address Network resource address (either a direct host like 1.1.1.1 or 1.1.1.1/32, or a subnet like 192.168.178.0/24, or domains like example.com and *.example.com), optionally followed by a protocol and a port or a port range like tcp/5432 or udp/5000-5100 to only grant access to them
 	Address string `json:"address"`
 
 	// Description Network resource description
@@ -741,6 +1584,9 @@ type NetworkResource struct {
 	// Id Network Resource ID
 	Id string `json:"id"`
 
+	// Labels Key/value labels of the network resource, the policies can select the resources by their labels. The labels of the resource are kept when omitted on update and removed when empty.
+	Labels *Labels `json:"labels,omitempty"`
+
 	// Name Network resource name
 	Name string `json:"name"`
 
@@ -750,7 +1596,7 @@ type NetworkResource struct {
 
 // NetworkResourceMinimum defines model for NetworkResourceMinimum.
 type NetworkResourceMinimum struct {
-	// Address Network resource address (either a direct host like 1.1.1.1 or 1.1.1.1/32, or a subnet like 192.168.178.0/24, or domains like example.com and *.example.com)
+	// Address Network resource address (either a direct host like 1.1.1.1 or 1.1.1.1/32, or a subnet like 192.168.178.0/24, or domains like example.com and *.example.com), optionally followed by a protocol and a port or a port range like tcp/5432 or udp/5000-5100 to only grant access to them
 	Address string `json:"address"`
 
 	// Description Network resource description
@@ -759,13 +1605,16 @@ type NetworkResourceMinimum struct {
 	// Enabled Network resource status
 	Enabled bool `json:"enabled"`
 
+	// Labels Key/value labels of the network resource, the policies can select the resources by their labels. The labels of the resource are kept when omitted on update and removed when empty.
+	Labels *Labels `json:"labels,omitempty"`
+
 	// Name Network resource name
 	Name string `json:"name"`
 }
 
 // NetworkResourceRequest defines model for NetworkResourceRequest.
 type NetworkResourceRequest struct {
-	// Address Network resource address (either a direct host like 1.1.1.1 or 1.1.1.1/32, or a subnet like 192.168.178.0/24, or domains like example.com and *.example.com)
+	// Address Network resource address (either a direct host like 1.1.1.1 or 1.1.1.1/32, or a subnet like 192.168.178.0/24, or domains like example.com and *.example.com), optionally followed by a protocol and a port or a port range like tcp/5432 or udp/5000-5100 to only grant access to them
 	Address string `json:"address"`
 
 	// Description Network resource description
@@ -777,6 +1626,9 @@ type NetworkResourceRequest struct {
 	// Groups Group IDs containing the resource
 	Groups []string `json:"groups"`
 
+	// Labels Key/value labels of the network resource, the policies can select the resources by their labels. The labels of the resource are kept when omitted on update and removed when empty.
+	Labels *Labels `json:"labels,omitempty"`
+
 	// Name Network resource name
 	Name string `json:"name"`
 }
@@ -937,6 +1789,9 @@ type OSVersionCheck struct {
 
 // Peer defines model for Peer.
 type Peer struct {
+	// AdvertisedRoutes Prefixes advertised by the peer, the LANs of a peer in router mode and the routes it learned from the routers of its site
+	AdvertisedRoutes []string `json:"advertised_routes"`
+
 	// ApprovalRequired (Cloud only) Indicates whether peer needs approval
 	ApprovalRequired bool `json:"approval_required"`
 
@@ -952,6 +1807,9 @@ type Peer struct {
 	// CountryCode 2-letter ISO 3166-1 alpha-2 code that represents the country
 	CountryCode CountryCode `json:"country_code"`
 
+	// DnsAliases DNS labels set by users as aliases of the peer, resolvable within the account domain independently of the peer name
+	DnsAliases []string `json:"dns_aliases"`
+
 	// DnsLabel Peer's DNS label is the parsed peer name for domain resolution. It is used to form an FQDN by appending the account's domain to the peer label. e.g. peer-dns-label.netbird.cloud
 	DnsLabel string `json:"dns_label"`
 
@@ -973,12 +1831,18 @@ type Peer struct {
 	// InactivityExpirationEnabled Indicates whether peer inactivity expiration has been enabled or not
 	InactivityExpirationEnabled bool `json:"inactivity_expiration_enabled"`
 
+	// Inventory Hardware and software inventory reported by the peer
+	Inventory *PeerInventory `json:"inventory,omitempty"`
+
 	// Ip Peer's IP address
 	Ip string `json:"ip"`
 
 	// KernelVersion Peer's operating system kernel version
 	KernelVersion string `json:"kernel_version"`
 
+	// Labels Key/value labels of the peer
+	Labels *Labels `json:"labels,omitempty"`
+
 	// LastLogin Last time this peer performed log in (authentication). E.g., user authenticated.
 	LastLogin time.Time `json:"last_login"`
 
@@ -997,12 +1861,18 @@ type Peer struct {
 	// Os Peer's operating system and version
 	Os string `json:"os"`
 
+	// RouterMode Indicates whether the peer runs as a gateway appliance in router mode
+	RouterMode bool `json:"router_mode"`
+
 	// SerialNumber System serial number
 	SerialNumber string `json:"serial_number"`
 
 	// SshEnabled Indicates whether SSH server is enabled on this peer
 	SshEnabled bool `json:"ssh_enabled"`
 
+	// StaticIp Indicates whether the IP of the peer was pinned and is kept when the account network range changes
+	StaticIp bool `json:"static_ip"`
+
 	// UiVersion Peer's desktop UI version
 	UiVersion string `json:"ui_version"`
 
@@ -1013,11 +1883,62 @@ type Peer struct {
 	Version string `json:"version"`
 }
 
+// PeerAction defines model for PeerAction.
+type PeerAction struct {
+	// Action Action the peer is asked to run
+	Action PeerActionAction `json:"action"`
+
+	// CreatedAt Time the action was requested
+	CreatedAt time.Time `json:"created_at"`
+
+	// Error Reason the peer declined or failed to run the action
+	Error *string `json:"error,omitempty"`
+
+	// ExpiresAt Time after which the peer doesn't run the action anymore
+	ExpiresAt time.Time `json:"expires_at"`
+
+	// Id Peer action ID
+	Id string `json:"id"`
+
+	// InitiatedBy Identifier of the user that requested the action
+	InitiatedBy string `json:"initiated_by"`
+
+	// Output Status reported by the peer for the collect_status actions
+	Output *string `json:"output,omitempty"`
+
+	// PeerId Identifier of the peer the action was requested from
+	PeerId string `json:"peer_id"`
+
+	// Status Status of the action, a pending action expires if the peer doesn't report its result in time
+	Status PeerActionStatus `json:"status"`
+
+	// UpdatedAt Time the status of the action last changed
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PeerActionAction Action the peer is asked to run
+type PeerActionAction string
+
+// PeerActionStatus Status of the action, a pending action expires if the peer doesn't report its result in time
+type PeerActionStatus string
+
+// PeerActionRequest defines model for PeerActionRequest.
+type PeerActionRequest struct {
+	// Action Action the peer is asked to run
+	Action PeerActionRequestAction `json:"action"`
+}
+
+// PeerActionRequestAction Action the peer is asked to run
+type PeerActionRequestAction string
+
 // PeerBatch defines model for PeerBatch.
 type PeerBatch struct {
 	// AccessiblePeersCount Number of accessible peers
 	AccessiblePeersCount int `json:"accessible_peers_count"`
 
+	// AdvertisedRoutes Prefixes advertised by the peer, the LANs of a peer in router mode and the routes it learned from the routers of its site
+	AdvertisedRoutes []string `json:"advertised_routes"`
+
 	// ApprovalRequired (Cloud only) Indicates whether peer needs approval
 	ApprovalRequired bool `json:"approval_required"`
 
@@ -1033,6 +1954,9 @@ type PeerBatch struct {
 	// CountryCode 2-letter ISO 3166-1 alpha-2 code that represents the country
 	CountryCode CountryCode `json:"country_code"`
 
+	// DnsAliases DNS labels set by users as aliases of the peer, resolvable within the account domain independently of the peer name
+	DnsAliases []string `json:"dns_aliases"`
+
 	// DnsLabel Peer's DNS label is the parsed peer name for domain resolution. It is used to form an FQDN by appending the account's domain to the peer label. e.g. peer-dns-label.netbird.cloud
 	DnsLabel string `json:"dns_label"`
 
@@ -1054,12 +1978,18 @@ type PeerBatch struct {
 	// InactivityExpirationEnabled Indicates whether peer inactivity expiration has been enabled or not
 	InactivityExpirationEnabled bool `json:"inactivity_expiration_enabled"`
 
+	// Inventory Hardware and software inventory reported by the peer
+	Inventory *PeerInventory `json:"inventory,omitempty"`
+
 	// Ip Peer's IP address
 	Ip string `json:"ip"`
 
 	// KernelVersion Peer's operating system kernel version
 	KernelVersion string `json:"kernel_version"`
 
+	// Labels Key/value labels of the peer
+	Labels *Labels `json:"labels,omitempty"`
+
 	// LastLogin Last time this peer performed log in (authentication). E.g., user authenticated.
 	LastLogin time.Time `json:"last_login"`
 
@@ -1078,12 +2008,18 @@ type PeerBatch struct {
 	// Os Peer's operating system and version
 	Os string `json:"os"`
 
+	// RouterMode Indicates whether the peer runs as a gateway appliance in router mode
+	RouterMode bool `json:"router_mode"`
+
 	// SerialNumber System serial number
 	SerialNumber string `json:"serial_number"`
 
 	// SshEnabled Indicates whether SSH server is enabled on this peer
 	SshEnabled bool `json:"ssh_enabled"`
 
+	// StaticIp Indicates whether the IP of the peer was pinned and is kept when the account network range changes
+	StaticIp bool `json:"static_ip"`
+
 	// UiVersion Peer's desktop UI version
 	UiVersion string `json:"ui_version"`
 
@@ -1094,6 +2030,171 @@ type PeerBatch struct {
 	Version string `json:"version"`
 }
 
+// PeerConnectivity defines model for PeerConnectivity.
+type PeerConnectivity struct {
+	// ConnectedPeers Number of peers connected to the peer
+	ConnectedPeers int `json:"connected_peers"`
+
+	// ConnectionSetups Phases of the peer connection setups since the previous report
+	ConnectionSetups []ConnectionSetupPhase `json:"connection_setups"`
+
+	// MappedAddress Public address of the peer seen by the STUN servers
+	MappedAddress string `json:"mapped_address"`
+
+	// NatType NAT type of the peer classified by comparing the public addresses seen by the STUN servers
+	NatType NATType `json:"nat_type"`
+
+	// PeerId Peer ID
+	PeerId string `json:"peer_id"`
+
+	// PeerName Peer name
+	PeerName string `json:"peer_name"`
+
+	// RelayRatio Moving average of the share of the relayed connections over the reports
+	RelayRatio float64 `json:"relay_ratio"`
+
+	// RelayedPeers Number of the connected peers relayed by a TURN or a relay server
+	RelayedPeers int `json:"relayed_peers"`
+
+	// ReportedAt Time of the latest report
+	ReportedAt time.Time `json:"reported_at"`
+
+	// RoutedNetworks Reachability of the networks the peer routes for the other peers
+	RoutedNetworks []RoutedNetwork `json:"routed_networks"`
+
+	// Servers Reachability of the STUN and TURN servers from the peer
+	Servers []ConnectivityServer `json:"servers"`
+}
+
+// PeerDebugBundle defines model for PeerDebugBundle.
+type PeerDebugBundle struct {
+	// CreatedAt Time the debug bundle was requested
+	CreatedAt time.Time `json:"created_at"`
+
+	// Error Reason the peer didn't upload the bundle
+	Error *string `json:"error,omitempty"`
+
+	// ExpiresAt Time the peer has to upload the debug bundle by
+	ExpiresAt time.Time `json:"expires_at"`
+
+	// Id Debug bundle request ID
+	Id string `json:"id"`
+
+	// InitiatedBy Identifier of the user that requested the debug bundle
+	InitiatedBy string `json:"initiated_by"`
+
+	// PeerId Identifier of the peer the debug bundle was requested from
+	PeerId string `json:"peer_id"`
+
+	// Size Size of the uploaded bundle in bytes
+	Size *int64 `json:"size,omitempty"`
+
+	// Status Status of the request, a pending request expires if the peer doesn't upload the bundle in time
+	Status PeerDebugBundleStatus `json:"status"`
+
+	// UpdatedAt Time the status of the request last changed
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PeerDebugBundleStatus Status of the request, a pending request expires if the peer doesn't upload the bundle in time
+type PeerDebugBundleStatus string
+
+// PeerFirewallRule defines model for PeerFirewallRule.
+type PeerFirewallRule struct {
+	// Action Action applied to the traffic, accept or drop
+	Action string `json:"action"`
+
+	// Direction Direction of the traffic, in from the remote peer or out to the remote peer
+	Direction PeerFirewallRuleDirection `json:"direction"`
+
+	// PeerId ID of the remote peer with the IP address, if the rule matches a single peer
+	PeerId *string `json:"peer_id,omitempty"`
+
+	// PeerIp IP address of the remote peer, 0.0.0.0 or :: match all the peers
+	PeerIp string `json:"peer_ip"`
+
+	// PeerName Name of the remote peer with the IP address, if the rule matches a single peer
+	PeerName *string `json:"peer_name,omitempty"`
+
+	// PolicyId ID of the policy the rule is derived from
+	PolicyId string `json:"policy_id"`
+
+	// PolicyName Name of the policy the rule is derived from, empty if the policy is unknown
+	PolicyName string `json:"policy_name"`
+
+	// PolicyRuleId ID of the policy rule the rule is derived from, it is sent to the client as the policy ID of the rule
+	PolicyRuleId string `json:"policy_rule_id"`
+
+	// PolicyRuleName Name of the policy rule the rule is derived from, empty if the policy rule is unknown
+	PolicyRuleName string `json:"policy_rule_name"`
+
+	// Port Port of the traffic, empty for all the ports or a port range
+	Port *string `json:"port,omitempty"`
+
+	// PortRange Policy rule affected ports range
+	PortRange *RulePortRange `json:"port_range,omitempty"`
+
+	// Protocol Protocol of the traffic, all, tcp, udp or icmp
+	Protocol string `json:"protocol"`
+}
+
+// PeerFirewallRuleDirection Direction of the traffic, in from the remote peer or out to the remote peer
+type PeerFirewallRuleDirection string
+
+// PeerFirewallRules defines model for PeerFirewallRules.
+type PeerFirewallRules struct {
+	// RouteRules Firewall rules of the traffic routed by the peer
+	RouteRules []PeerRouteFirewallRule `json:"route_rules"`
+
+	// Rules Firewall rules of the traffic between the peer and the other peers
+	Rules []PeerFirewallRule `json:"rules"`
+
+	// Serial Serial of the network map the rules are part of
+	Serial uint64 `json:"serial"`
+}
+
+// PeerIPMigration defines model for PeerIPMigration.
+type PeerIPMigration struct {
+	// NewIp IP address of the peer in the new network range
+	NewIp string `json:"new_ip"`
+
+	// OldIp IP address of the peer before the change of the network range
+	OldIp string `json:"old_ip"`
+
+	// PeerId Peer ID
+	PeerId string `json:"peer_id"`
+
+	// PeerName Peer's hostname
+	PeerName string `json:"peer_name"`
+}
+
+// PeerInventory Hardware and software inventory reported by the peer
+type PeerInventory struct {
+	// BootTime Time the system booted at, omitted if the peer doesn't report it
+	BootTime *time.Time `json:"boot_time,omitempty"`
+
+	// ClientVersion Version of the NetBird client
+	ClientVersion string `json:"client_version"`
+
+	// KernelVersion Kernel version, the build number on Windows
+	KernelVersion string `json:"kernel_version"`
+
+	// Manufacturer Manufacturer of the system
+	Manufacturer string `json:"manufacturer"`
+
+	// Model Model of the system
+	Model string `json:"model"`
+
+	// OsVersion Version of the operating system
+	OsVersion string `json:"os_version"`
+
+	// SerialNumber System serial number
+	SerialNumber string `json:"serial_number"`
+
+	// Uptime Time since the system booted, in seconds, omitted if the peer doesn't report its boot time
+	Uptime *int `json:"uptime,omitempty"`
+}
+
 // PeerMinimum defines model for PeerMinimum.
 type PeerMinimum struct {
 	// Id Peer ID
@@ -1115,14 +2216,92 @@ type PeerNetworkRangeCheck struct {
 // PeerNetworkRangeCheckAction Action to take upon policy match
 type PeerNetworkRangeCheckAction string
 
+// PeerProbeRequest defines model for PeerProbeRequest.
+type PeerProbeRequest struct {
+	// Address IP address probed by the peer, e.g. of a routed network
+	Address *string `json:"address,omitempty"`
+
+	// Port TCP port connected to, the target is pinged with ICMP when omitted
+	Port *int `json:"port,omitempty"`
+
+	// TargetPeerId Identifier of the peer probed over the NetBird network, either the target peer or the address has to be set
+	TargetPeerId *string `json:"target_peer_id,omitempty"`
+
+	// TimeoutSeconds Timeout of the probe in seconds, up to 30
+	TimeoutSeconds *int `json:"timeout_seconds,omitempty"`
+
+	// Url URL requested by the peer with an HTTP GET instead of probing a peer or an address, a server error status fails the probe
+	Url *string `json:"url,omitempty"`
+}
+
+// PeerProbeResult defines model for PeerProbeResult.
+type PeerProbeResult struct {
+	// Error Reason the target wasn't reachable
+	Error *string `json:"error,omitempty"`
+
+	// HttpStatus Response status code of an HTTP probe
+	HttpStatus *int `json:"http_status,omitempty"`
+
+	// LatencyMs Round-trip time of the probe in milliseconds
+	LatencyMs float64 `json:"latency_ms"`
+
+	// Reachable Indicates whether the target responded to the probe
+	Reachable bool `json:"reachable"`
+
+	// Target IP address or URL probed by the peer
+	Target string `json:"target"`
+}
+
 // PeerRequest defines model for PeerRequest.
 type PeerRequest struct {
 	// ApprovalRequired (Cloud only) Indicates whether peer needs approval
-	ApprovalRequired            *bool  `json:"approval_required,omitempty"`
-	InactivityExpirationEnabled bool   `json:"inactivity_expiration_enabled"`
-	LoginExpirationEnabled      bool   `json:"login_expiration_enabled"`
-	Name                        string `json:"name"`
-	SshEnabled                  bool   `json:"ssh_enabled"`
+	ApprovalRequired *bool `json:"approval_required,omitempty"`
+
+	// DnsAliases DNS labels resolving to the peer within the account domain independently of the peer name. The aliases of the peer are kept when omitted. Regular users can only set the aliases of their own peers.
+	DnsAliases                  *[]string `json:"dns_aliases,omitempty"`
+	InactivityExpirationEnabled bool      `json:"inactivity_expiration_enabled"`
+
+	// Ip Static IP address assigned to the peer, it has to be part of the account network range. The IP is kept when the network range changes. An empty value unpins the current IP, the IP of the peer is kept when omitted.
+	Ip *string `json:"ip,omitempty"`
+
+	// Labels Key/value labels of the peer, the policies can select the peers by their labels. The labels of the peer are kept when omitted and removed when empty.
+	Labels                 *Labels `json:"labels,omitempty"`
+	LoginExpirationEnabled bool    `json:"login_expiration_enabled"`
+	Name                   string  `json:"name"`
+	SshEnabled             bool    `json:"ssh_enabled"`
+}
+
+// PeerRouteFirewallRule defines model for PeerRouteFirewallRule.
+type PeerRouteFirewallRule struct {
+	// Action Action applied to the traffic, accept or drop
+	Action string `json:"action"`
+
+	// Destination Network prefix of the routed traffic
+	Destination string `json:"destination"`
+
+	// Domains Domains of the routed traffic, for the routes of domains
+	Domains []string `json:"domains"`
+
+	// IsDynamic The rule applies to the IP addresses the domains resolve to
+	IsDynamic bool `json:"is_dynamic"`
+
+	// PolicyId ID of the policy the rule is derived from
+	PolicyId string `json:"policy_id"`
+
+	// PolicyName Name of the policy the rule is derived from, empty if the policy is unknown
+	PolicyName string `json:"policy_name"`
+
+	// Port Port of the traffic, 0 for all the ports or a port range
+	Port int `json:"port"`
+
+	// PortRange Policy rule affected ports range
+	PortRange *RulePortRange `json:"port_range,omitempty"`
+
+	// Protocol Protocol of the traffic, all, tcp, udp or icmp
+	Protocol string `json:"protocol"`
+
+	// SourceRanges IP ranges of the peers the routed traffic is accepted from
+	SourceRanges []string `json:"source_ranges"`
 }
 
 // PersonalAccessToken defines model for PersonalAccessToken.
@@ -1136,6 +2315,9 @@ type PersonalAccessToken struct {
 	// ExpirationDate Date the token expires
 	ExpirationDate time.Time `json:"expiration_date"`
 
+	// Groups Group IDs restricting the peers and groups the token can modify, a token with groups can't modify the resources of the other modules
+	Groups *[]string `json:"groups,omitempty"`
+
 	// Id ID of a token
 	Id string `json:"id"`
 
@@ -1144,6 +2326,9 @@ type PersonalAccessToken struct {
 
 	// Name Name of the token
 	Name string `json:"name"`
+
+	// Scopes Module operations the token is restricted to in the module:operation format. Write implies read. Tokens without scopes have the permissions of their user
+	Scopes *[]string `json:"scopes,omitempty"`
 }
 
 // PersonalAccessTokenGenerated defines model for PersonalAccessTokenGenerated.
@@ -1159,8 +2344,23 @@ type PersonalAccessTokenRequest struct {
 	// ExpiresIn Expiration in days
 	ExpiresIn int `json:"expires_in"`
 
+	// Groups Group IDs restricting the peers and groups the token can modify, a token with groups can't modify the resources of the other modules
+	Groups *[]string `json:"groups,omitempty"`
+
 	// Name Name of the token
 	Name string `json:"name"`
+
+	// Scopes Module operations the token is restricted to in the module:operation format. Write implies read. Tokens without scopes have the permissions of their user
+	Scopes *[]string `json:"scopes,omitempty"`
+}
+
+// PersonalAccessTokenRotateRequest defines model for PersonalAccessTokenRotateRequest.
+type PersonalAccessTokenRotateRequest struct {
+	// ExpiresIn Expiration of the new token in days
+	ExpiresIn int `json:"expires_in"`
+
+	// GracePeriod Minutes the rotated token stays valid next to the new token. Defaults to 1440 (24 hours), 0 revokes it immediately
+	GracePeriod *int `json:"grace_period,omitempty"`
 }
 
 // Policy defines model for Policy.
@@ -1177,6 +2377,9 @@ type Policy struct {
 	// Name Policy name identifier
 	Name string `json:"name"`
 
+	// Remediation Keeps applying the policy to the peers with an expired login when the login expiration grace period is enabled, e.g. to let them reach the identity provider
+	Remediation *bool `json:"remediation,omitempty"`
+
 	// Rules Policy rule object for policy UI editor
 	Rules []PolicyRule `json:"rules"`
 
@@ -1195,6 +2398,9 @@ type PolicyCreate struct {
 	// Name Policy name identifier
 	Name string `json:"name"`
 
+	// Remediation Keeps applying the policy to the peers with an expired login when the login expiration grace period is enabled, e.g. to let them reach the identity provider
+	Remediation *bool `json:"remediation,omitempty"`
+
 	// Rules Policy rule object for policy UI editor
 	Rules []PolicyRuleUpdate `json:"rules"`
 
@@ -1202,6 +2408,30 @@ type PolicyCreate struct {
 	SourcePostureChecks *[]string `json:"source_posture_checks,omitempty"`
 }
 
+// PolicyDryRun defines model for PolicyDryRun.
+type PolicyDryRun struct {
+	// Peers Peers whose connections would change
+	Peers []PolicyDryRunPeer `json:"peers"`
+}
+
+// PolicyDryRunPeer defines model for PolicyDryRunPeer.
+type PolicyDryRunPeer struct {
+	// AddedPeers IDs of the peers the peer could connect to after the change only
+	AddedPeers []string `json:"added_peers"`
+
+	// ChangedPeers IDs of the peers the peer could connect to with different rules, e.g. other ports
+	ChangedPeers []string `json:"changed_peers"`
+
+	// PeerId Peer ID
+	PeerId string `json:"peer_id"`
+
+	// PeerName Peer name
+	PeerName string `json:"peer_name"`
+
+	// RemovedPeers IDs of the peers the peer couldn't connect to after the change anymore
+	RemovedPeers []string `json:"removed_peers"`
+}
+
 // PolicyMinimum defines model for PolicyMinimum.
 type PolicyMinimum struct {
 	// Description Policy friendly description
@@ -1212,6 +2442,25 @@ type PolicyMinimum struct {
 
 	// Name Policy name identifier
 	Name string `json:"name"`
+
+	// Remediation Keeps applying the policy to the peers with an expired login when the login expiration grace period is enabled, e.g. to let them reach the identity provider
+	Remediation *bool `json:"remediation,omitempty"`
+}
+
+// PolicyRolloutRequest defines model for PolicyRolloutRequest.
+type PolicyRolloutRequest struct {
+	// AutoPromote Promotes the change to all peers once the observation period passed without failure
+	AutoPromote *bool `json:"auto_promote,omitempty"`
+
+	// CanaryGroups IDs of the groups whose peers receive the staged change first
+	CanaryGroups []string `json:"canary_groups"`
+
+	// FailureThreshold Share of the reporting canary peers with unhealthy routes above which the change is rolled back, 0 rolls back on the first unhealthy peer
+	FailureThreshold *float64 `json:"failure_threshold,omitempty"`
+
+	// ObservationPeriod Seconds the canary peers are observed before the change can be promoted, 30 minutes if not set
+	ObservationPeriod *int         `json:"observation_period,omitempty"`
+	Policy            PolicyCreate `json:"policy"`
 }
 
 // PolicyRule defines model for PolicyRule.
@@ -1226,6 +2475,9 @@ type PolicyRule struct {
 	Description         *string   `json:"description,omitempty"`
 	DestinationResource *Resource `json:"destinationResource,omitempty"`
 
+	// DestinationLabels Selects the peers, the routes and the network resources having all the labels as destinations, in addition to the destination groups
+	DestinationLabels *Labels `json:"destination_labels,omitempty"`
+
 	// Destinations Policy rule destination group IDs
 	Destinations *[]GroupMinimum `json:"destinations,omitempty"`
 
@@ -1248,6 +2500,9 @@ type PolicyRule struct {
 	Protocol       PolicyRuleProtocol `json:"protocol"`
 	SourceResource *Resource          `json:"sourceResource,omitempty"`
 
+	// SourceLabels Selects the peers having all the labels as sources, in addition to the source groups
+	SourceLabels *Labels `json:"source_labels,omitempty"`
+
 	// Sources Policy rule source group IDs
 	Sources *[]GroupMinimum `json:"sources,omitempty"`
 }
@@ -1269,6 +2524,9 @@ type PolicyRuleMinimum struct {
 	// Description Policy rule friendly description
 	Description *string `json:"description,omitempty"`
 
+	// DestinationLabels Selects the peers, the routes and the network resources having all the labels as destinations, in addition to the destination groups
+	DestinationLabels *Labels `json:"destination_labels,omitempty"`
+
 	// Enabled Policy rule status
 	Enabled bool `json:"enabled"`
 
@@ -1283,6 +2541,9 @@ type PolicyRuleMinimum struct {
 
 	// Protocol Policy rule type of the traffic
 	Protocol PolicyRuleMinimumProtocol `json:"protocol"`
+
+	// SourceLabels Selects the peers having all the labels as sources, in addition to the source groups
+	SourceLabels *Labels `json:"source_labels,omitempty"`
 }
 
 // PolicyRuleMinimumAction Policy rule accept or drops packets
@@ -1303,6 +2564,9 @@ type PolicyRuleUpdate struct {
 	Description         *string   `json:"description,omitempty"`
 	DestinationResource *Resource `json:"destinationResource,omitempty"`
 
+	// DestinationLabels Selects the peers, the routes and the network resources having all the labels as destinations, in addition to the destination groups
+	DestinationLabels *Labels `json:"destination_labels,omitempty"`
+
 	// Destinations Policy rule destination group IDs
 	Destinations *[]string `json:"destinations,omitempty"`
 
@@ -1325,6 +2589,9 @@ type PolicyRuleUpdate struct {
 	Protocol       PolicyRuleUpdateProtocol `json:"protocol"`
 	SourceResource *Resource                `json:"sourceResource,omitempty"`
 
+	// SourceLabels Selects the peers having all the labels as sources, in addition to the source groups
+	SourceLabels *Labels `json:"source_labels,omitempty"`
+
 	// Sources Policy rule source group IDs
 	Sources *[]string `json:"sources,omitempty"`
 }
@@ -1335,6 +2602,54 @@ type PolicyRuleUpdateAction string
 // PolicyRuleUpdateProtocol Policy rule type of the traffic
 type PolicyRuleUpdateProtocol string
 
+// PolicySimulationRequest defines model for PolicySimulationRequest.
+type PolicySimulationRequest struct {
+	// DestinationAddress Destination IP address, e.g. of a routed network or a network resource
+	DestinationAddress *string `json:"destination_address,omitempty"`
+
+	// DestinationPeerId ID of the destination peer, either a destination peer or a destination address has to be set
+	DestinationPeerId *string `json:"destination_peer_id,omitempty"`
+
+	// Port Destination port of the tcp and udp traffic
+	Port *int `json:"port,omitempty"`
+
+	// Protocol Protocol of the traffic
+	Protocol PolicySimulationRequestProtocol `json:"protocol"`
+
+	// SourcePeerId ID of the peer initiating the traffic
+	SourcePeerId string `json:"source_peer_id"`
+}
+
+// PolicySimulationRequestProtocol Protocol of the traffic
+type PolicySimulationRequestProtocol string
+
+// PolicySimulationResult defines model for PolicySimulationResult.
+type PolicySimulationResult struct {
+	// Allowed Indicates whether the traffic is allowed
+	Allowed bool `json:"allowed"`
+
+	// Reason Explanation of the result
+	Reason string `json:"reason"`
+
+	// Rules Policy rules matching the traffic
+	Rules []PolicySimulationRule `json:"rules"`
+}
+
+// PolicySimulationRule defines model for PolicySimulationRule.
+type PolicySimulationRule struct {
+	// Action Action of the policy rule
+	Action string `json:"action"`
+
+	// Name Policy name
+	Name string `json:"name"`
+
+	// PolicyId Policy ID, empty for the routes without access control groups
+	PolicyId string `json:"policy_id"`
+
+	// RoutingPeerId ID of the routing peer enforcing the rule of a routed destination
+	RoutingPeerId *string `json:"routing_peer_id,omitempty"`
+}
+
 // PolicyUpdate defines model for PolicyUpdate.
 type PolicyUpdate struct {
 	// Description Policy friendly description
@@ -1346,6 +2661,9 @@ type PolicyUpdate struct {
 	// Name Policy name identifier
 	Name string `json:"name"`
 
+	// Remediation Keeps applying the policy to the peers with an expired login when the login expiration grace period is enabled, e.g. to let them reach the identity provider
+	Remediation *bool `json:"remediation,omitempty"`
+
 	// Rules Policy rule object for policy UI editor
 	Rules []PolicyRuleUpdate `json:"rules"`
 
@@ -1407,6 +2725,111 @@ type Resource struct {
 // ResourceType defines model for ResourceType.
 type ResourceType string
 
+// Role defines model for Role.
+type Role struct {
+	// Description Role description
+	Description *string `json:"description,omitempty"`
+
+	// Groups List of group IDs whose members get the role
+	Groups []string `json:"groups"`
+
+	// Id Role ID
+	Id string `json:"id"`
+
+	// Name Role name
+	Name string `json:"name"`
+
+	// Permissions Map of module names to the operations the role allows on them. Write access implies read access.
+	Permissions map[string][]string `json:"permissions"`
+
+	// Users List of user IDs the role is directly assigned to
+	Users []string `json:"users"`
+}
+
+// RoleRequest defines model for RoleRequest.
+type RoleRequest struct {
+	// Description Role description
+	Description *string `json:"description,omitempty"`
+
+	// Groups List of group IDs whose members get the role. Groups synced from the IdP JWT claims map IdP groups onto the role.
+	Groups *[]string `json:"groups,omitempty"`
+
+	// Name Role name
+	Name string `json:"name"`
+
+	// Permissions Map of module names to the operations the role allows on them. Write access implies read access.
+	Permissions map[string][]string `json:"permissions"`
+
+	// Users List of user IDs the role is directly assigned to
+	Users *[]string `json:"users,omitempty"`
+}
+
+// Rollout defines model for Rollout.
+type Rollout struct {
+	// AutoPromote Promotes the change to all peers once the observation period passed without failure
+	AutoPromote bool `json:"auto_promote"`
+
+	// CanaryGroups IDs of the groups whose peers receive the staged change first
+	CanaryGroups []string `json:"canary_groups"`
+
+	// CreatedBy ID of the user who started the rollout
+	CreatedBy string `json:"created_by"`
+
+	// FailureThreshold Share of the reporting canary peers with unhealthy routes above which the change is rolled back
+	FailureThreshold float64 `json:"failure_threshold"`
+
+	// FinishedAt Time the rollout was promoted, rolled back or cancelled
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+
+	// Id Rollout ID
+	Id string `json:"id"`
+
+	// Kind Kind of the staged object
+	Kind RolloutKind `json:"kind"`
+
+	// ObjectId ID of the staged route or policy
+	ObjectId string `json:"object_id"`
+
+	// ObservationPeriod Seconds the canary peers are observed before the change can be promoted
+	ObservationPeriod int `json:"observation_period"`
+
+	// Reason Reason the rollout was rolled back or cancelled
+	Reason *string `json:"reason,omitempty"`
+
+	// ReportedPeers Number of the canary peers that reported their route health since the rollout started
+	ReportedPeers int `json:"reported_peers"`
+
+	// StartedAt Time the rollout started
+	StartedAt time.Time `json:"started_at"`
+
+	// Status Stage of the rollout, the change applies to the canary peers in the canary and ready stages
+	Status RolloutStatus `json:"status"`
+
+	// UnhealthyPeers Number of the reporting canary peers with unhealthy routes
+	UnhealthyPeers int `json:"unhealthy_peers"`
+}
+
+// RolloutKind Kind of the staged object
+type RolloutKind string
+
+// RolloutStatus Stage of the rollout, the change applies to the canary peers in the canary and ready stages
+type RolloutStatus string
+
+// RolloutSettings defines model for RolloutSettings.
+type RolloutSettings struct {
+	// AutoPromote Promotes the change to all peers once the observation period passed without failure
+	AutoPromote *bool `json:"auto_promote,omitempty"`
+
+	// CanaryGroups IDs of the groups whose peers receive the staged change first
+	CanaryGroups []string `json:"canary_groups"`
+
+	// FailureThreshold Share of the reporting canary peers with unhealthy routes above which the change is rolled back, 0 rolls back on the first unhealthy peer
+	FailureThreshold *float64 `json:"failure_threshold,omitempty"`
+
+	// ObservationPeriod Seconds the canary peers are observed before the change can be promoted, 30 minutes if not set
+	ObservationPeriod *int `json:"observation_period,omitempty"`
+}
+
 // Route defines model for Route.
 type Route struct {
 	// AccessControlGroups Access control group identifier associated with route.
@@ -1415,6 +2838,15 @@ type Route struct {
 	// Description Route description
 	Description string `json:"description"`
 
+	// DnsDomains Domains of the routed network, the clients of the route resolve them with the `dns_servers` instead of a separate nameserver group. Can't be set together with `domains`
+	DnsDomains *[]string `json:"dns_domains,omitempty"`
+
+	// DnsSearchDomainsEnabled Indicate if the `dns_domains` should be added to the search domains of the clients
+	DnsSearchDomainsEnabled *bool `json:"dns_search_domains_enabled,omitempty"`
+
+	// DnsServers IP addresses of up to 3 nameservers in the routed network resolving the `dns_domains` on port 53
+	DnsServers *[]string `json:"dns_servers,omitempty"`
+
 	// Domains Domain list to be dynamically resolved. Max of 32 domains can be added per route configuration. Conflicts with network
 	Domains *[]string `json:"domains,omitempty"`
 
@@ -1430,6 +2862,9 @@ type Route struct {
 	// KeepRoute Indicate if the route should be kept after a domain doesn't resolve that IP anymore
 	KeepRoute bool `json:"keep_route"`
 
+	// Labels Key/value labels of the route, the policies can select the routes by their labels. The labels of the route are kept when omitted on update and removed when empty.
+	Labels *Labels `json:"labels,omitempty"`
+
 	// Masquerade Indicate if peer should masquerade traffic to this route's prefix
 	Masquerade bool `json:"masquerade"`
 
@@ -1452,6 +2887,30 @@ type Route struct {
 	PeerGroups *[]string `json:"peer_groups,omitempty"`
 }
 
+// RouteDryRun defines model for RouteDryRun.
+type RouteDryRun struct {
+	// Peers Peers whose routes would change
+	Peers []RouteDryRunPeer `json:"peers"`
+}
+
+// RouteDryRunPeer defines model for RouteDryRunPeer.
+type RouteDryRunPeer struct {
+	// AddedRoutingPeers IDs of the routing peers the peer would use for the route after the change only
+	AddedRoutingPeers []string `json:"added_routing_peers"`
+
+	// ChangedRoutingPeers IDs of the routing peers the peer would use for other networks or domains of the route
+	ChangedRoutingPeers []string `json:"changed_routing_peers"`
+
+	// PeerId Peer ID
+	PeerId string `json:"peer_id"`
+
+	// PeerName Peer name
+	PeerName string `json:"peer_name"`
+
+	// RemovedRoutingPeers IDs of the routing peers the peer wouldn't use for the route after the change anymore
+	RemovedRoutingPeers []string `json:"removed_routing_peers"`
+}
+
 // RouteRequest defines model for RouteRequest.
 type RouteRequest struct {
 	// AccessControlGroups Access control group identifier associated with route.
@@ -1460,6 +2919,15 @@ type RouteRequest struct {
 	// Description Route description
 	Description string `json:"description"`
 
+	// DnsDomains Domains of the routed network, the clients of the route resolve them with the `dns_servers` instead of a separate nameserver group. Can't be set together with `domains`
+	DnsDomains *[]string `json:"dns_domains,omitempty"`
+
+	// DnsSearchDomainsEnabled Indicate if the `dns_domains` should be added to the search domains of the clients
+	DnsSearchDomainsEnabled *bool `json:"dns_search_domains_enabled,omitempty"`
+
+	// DnsServers IP addresses of up to 3 nameservers in the routed network resolving the `dns_domains` on port 53
+	DnsServers *[]string `json:"dns_servers,omitempty"`
+
 	// Domains Domain list to be dynamically resolved. Max of 32 domains can be added per route configuration. Conflicts with network
 	Domains *[]string `json:"domains,omitempty"`
 
@@ -1472,6 +2940,9 @@ type RouteRequest struct {
 	// KeepRoute Indicate if the route should be kept after a domain doesn't resolve that IP anymore
 	KeepRoute bool `json:"keep_route"`
 
+	// Labels Key/value labels of the route, the policies can select the routes by their labels. The labels of the route are kept when omitted on update and removed when empty.
+	Labels *Labels `json:"labels,omitempty"`
+
 	// Masquerade Indicate if peer should masquerade traffic to this route's prefix
 	Masquerade bool `json:"masquerade"`
 
@@ -1491,6 +2962,43 @@ type RouteRequest struct {
 	PeerGroups *[]string `json:"peer_groups,omitempty"`
 }
 
+// RouteRolloutRequest defines model for RouteRolloutRequest.
+type RouteRolloutRequest struct {
+	// AutoPromote Promotes the change to all peers once the observation period passed without failure
+	AutoPromote *bool `json:"auto_promote,omitempty"`
+
+	// CanaryGroups IDs of the groups whose peers receive the staged change first
+	CanaryGroups []string `json:"canary_groups"`
+
+	// FailureThreshold Share of the reporting canary peers with unhealthy routes above which the change is rolled back, 0 rolls back on the first unhealthy peer
+	FailureThreshold *float64 `json:"failure_threshold,omitempty"`
+
+	// ObservationPeriod Seconds the canary peers are observed before the change can be promoted, 30 minutes if not set
+	ObservationPeriod *int         `json:"observation_period,omitempty"`
+	Route             RouteRequest `json:"route"`
+}
+
+// RoutedNetwork Reachability of a network the peer routes, checked by the peer periodically
+type RoutedNetwork struct {
+	// Error Reason the network is unreachable
+	Error *string `json:"error,omitempty"`
+
+	// LatencyMs Round trip time to the target in milliseconds, set if it replied
+	LatencyMs *int `json:"latency_ms,omitempty"`
+
+	// Network Network range of the route
+	Network string `json:"network"`
+
+	// Reachable Whether the route to the network leads through an interface that is up and the target or the gateway replied, the directly connected networks don't need a reply
+	Reachable bool `json:"reachable"`
+
+	// RouteId ID of the route advertising the network
+	RouteId string `json:"route_id"`
+
+	// Target Address probed in the network
+	Target *string `json:"target,omitempty"`
+}
+
 // RulePortRange Policy rule affected ports range
 type RulePortRange struct {
 	// End The ending port of the range
@@ -1500,14 +3008,44 @@ type RulePortRange struct {
 	Start int `json:"start"`
 }
 
+// ScheduledPeerDeletion defines model for ScheduledPeerDeletion.
+type ScheduledPeerDeletion struct {
+	// CleanupAfter Period of time the peer has to be offline before it is deleted, in seconds
+	CleanupAfter int `json:"cleanup_after"`
+
+	// Ephemeral Indicates whether the peer was registered as ephemeral
+	Ephemeral bool `json:"ephemeral"`
+
+	// LastSeen Last time the peer was connected to the management service
+	LastSeen time.Time `json:"last_seen"`
+
+	// PeerId Peer ID
+	PeerId string `json:"peer_id"`
+
+	// PeerName Peer's hostname
+	PeerName string `json:"peer_name"`
+
+	// ScheduledAt Estimated time the peer is going to be deleted at
+	ScheduledAt time.Time `json:"scheduled_at"`
+}
+
 // SetupKey defines model for SetupKey.
 type SetupKey struct {
 	// AllowExtraDnsLabels Allow extra DNS labels to be added to the peer
 	AllowExtraDnsLabels bool `json:"allow_extra_dns_labels"`
 
+	// AllowedIpRanges List of source IP ranges in CIDR notation peers are allowed to register from with this key. Empty list allows any source IP.
+	AllowedIpRanges []string `json:"allowed_ip_ranges"`
+
 	// AutoGroups List of group IDs to auto-assign to peers registered with this key
 	AutoGroups []string `json:"auto_groups"`
 
+	// AutoPostureChecks List of posture check IDs peers have to pass to register with this key
+	AutoPostureChecks []string `json:"auto_posture_checks"`
+
+	// AutoRoutes List of network route IDs to auto-assign to peers registered with this key. The peers are added as routing peers of the routes.
+	AutoRoutes []string `json:"auto_routes"`
+
 	// Ephemeral Indicate that the peer will be ephemeral or not
 	Ephemeral bool `json:"ephemeral"`
 
@@ -1526,6 +3064,9 @@ type SetupKey struct {
 	// Name Setup key name identifier
 	Name string `json:"name"`
 
+	// PeerNamePrefix Prefix added to the name of the peers registered with this key
+	PeerNamePrefix string `json:"peer_name_prefix"`
+
 	// Revoked Setup key revocation status
 	Revoked bool `json:"revoked"`
 
@@ -1553,9 +3094,18 @@ type SetupKeyBase struct {
 	// AllowExtraDnsLabels Allow extra DNS labels to be added to the peer
 	AllowExtraDnsLabels bool `json:"allow_extra_dns_labels"`
 
+	// AllowedIpRanges List of source IP ranges in CIDR notation peers are allowed to register from with this key. Empty list allows any source IP.
+	AllowedIpRanges []string `json:"allowed_ip_ranges"`
+
 	// AutoGroups List of group IDs to auto-assign to peers registered with this key
 	AutoGroups []string `json:"auto_groups"`
 
+	// AutoPostureChecks List of posture check IDs peers have to pass to register with this key
+	AutoPostureChecks []string `json:"auto_posture_checks"`
+
+	// AutoRoutes List of network route IDs to auto-assign to peers registered with this key. The peers are added as routing peers of the routes.
+	AutoRoutes []string `json:"auto_routes"`
+
 	// Ephemeral Indicate that the peer will be ephemeral or not
 	Ephemeral bool `json:"ephemeral"`
 
@@ -1571,6 +3121,9 @@ type SetupKeyBase struct {
 	// Name Setup key name identifier
 	Name string `json:"name"`
 
+	// PeerNamePrefix Prefix added to the name of the peers registered with this key
+	PeerNamePrefix string `json:"peer_name_prefix"`
+
 	// Revoked Setup key revocation status
 	Revoked bool `json:"revoked"`
 
@@ -1598,9 +3151,18 @@ type SetupKeyClear struct {
 	// AllowExtraDnsLabels Allow extra DNS labels to be added to the peer
 	AllowExtraDnsLabels bool `json:"allow_extra_dns_labels"`
 
+	// AllowedIpRanges List of source IP ranges in CIDR notation peers are allowed to register from with this key. Empty list allows any source IP.
+	AllowedIpRanges []string `json:"allowed_ip_ranges"`
+
 	// AutoGroups List of group IDs to auto-assign to peers registered with this key
 	AutoGroups []string `json:"auto_groups"`
 
+	// AutoPostureChecks List of posture check IDs peers have to pass to register with this key
+	AutoPostureChecks []string `json:"auto_posture_checks"`
+
+	// AutoRoutes List of network route IDs to auto-assign to peers registered with this key. The peers are added as routing peers of the routes.
+	AutoRoutes []string `json:"auto_routes"`
+
 	// Ephemeral Indicate that the peer will be ephemeral or not
 	Ephemeral bool `json:"ephemeral"`
 
@@ -1619,6 +3181,9 @@ type SetupKeyClear struct {
 	// Name Setup key name identifier
 	Name string `json:"name"`
 
+	// PeerNamePrefix Prefix added to the name of the peers registered with this key
+	PeerNamePrefix string `json:"peer_name_prefix"`
+
 	// Revoked Setup key revocation status
 	Revoked bool `json:"revoked"`
 
@@ -1643,13 +3208,190 @@ type SetupKeyClear struct {
 
 // SetupKeyRequest defines model for SetupKeyRequest.
 type SetupKeyRequest struct {
+	// AllowedIpRanges List of source IP ranges in CIDR notation peers are allowed to register from with this key. Empty list allows any source IP.
+	AllowedIpRanges *[]string `json:"allowed_ip_ranges,omitempty"`
+
 	// AutoGroups List of group IDs to auto-assign to peers registered with this key
 	AutoGroups []string `json:"auto_groups"`
 
+	// AutoPostureChecks List of posture check IDs peers have to pass to register with this key
+	AutoPostureChecks *[]string `json:"auto_posture_checks,omitempty"`
+
+	// AutoRoutes List of network route IDs to auto-assign to peers registered with this key. The peers are added as routing peers of the routes.
+	AutoRoutes *[]string `json:"auto_routes,omitempty"`
+
+	// PeerNamePrefix Prefix added to the name of the peers registered with this key
+	PeerNamePrefix *string `json:"peer_name_prefix,omitempty"`
+
 	// Revoked Setup key revocation status
 	Revoked bool `json:"revoked"`
 }
 
+// Tenant defines model for Tenant.
+type Tenant struct {
+	// CreatedAt Tenant creation date
+	CreatedAt time.Time `json:"created_at"`
+
+	// CreatedBy ID of the user that created the tenant
+	CreatedBy string `json:"created_by"`
+
+	// Id Tenant account ID
+	Id string `json:"id"`
+
+	// Name Tenant name
+	Name string `json:"name"`
+}
+
+// TenantRequest defines model for TenantRequest.
+type TenantRequest struct {
+	// Name Tenant name
+	Name string `json:"name"`
+
+	// OwnerUserId IdP user ID of the tenant account owner. The user must not belong to any account.
+	OwnerUserId string `json:"owner_user_id"`
+}
+
+// TenantUsage defines model for TenantUsage.
+type TenantUsage struct {
+	// ConnectedPeers Number of connected peers in the tenant account
+	ConnectedPeers int `json:"connected_peers"`
+
+	// Id Tenant account ID
+	Id string `json:"id"`
+
+	// Name Tenant name
+	Name string `json:"name"`
+
+	// Peers Number of peers in the tenant account
+	Peers int `json:"peers"`
+
+	// Users Number of users in the tenant account, service users excluded
+	Users int `json:"users"`
+}
+
+// TenantsUsage defines model for TenantsUsage.
+type TenantsUsage struct {
+	// Tenants Usage of each tenant account
+	Tenants []TenantUsage `json:"tenants"`
+
+	// TotalConnectedPeers Number of connected peers in all tenant accounts
+	TotalConnectedPeers int `json:"total_connected_peers"`
+
+	// TotalPeers Number of peers in all tenant accounts
+	TotalPeers int `json:"total_peers"`
+
+	// TotalUsers Number of users in all tenant accounts, service users excluded
+	TotalUsers int `json:"total_users"`
+}
+
+// Topology defines model for Topology.
+type Topology struct {
+	// Connections Connections allowed between the peers
+	Connections []TopologyConnection `json:"connections"`
+
+	// Peers Peers of the account
+	Peers []TopologyPeer `json:"peers"`
+
+	// Routes Routes and exit nodes applied to the peers
+	Routes []TopologyRoute `json:"routes"`
+}
+
+// TopologyConnection defines model for TopologyConnection.
+type TopologyConnection struct {
+	// DestinationPeerId ID of the peer receiving the connection
+	DestinationPeerId string `json:"destination_peer_id"`
+
+	// Policies Policy rules applied to the connection
+	Policies []TopologyConnectionPolicy `json:"policies"`
+
+	// SourcePeerId ID of the peer initiating the connection
+	SourcePeerId string `json:"source_peer_id"`
+}
+
+// TopologyConnectionPolicy defines model for TopologyConnectionPolicy.
+type TopologyConnectionPolicy struct {
+	// Action Action of the policy rule
+	Action string `json:"action"`
+
+	// Name Policy name
+	Name string `json:"name"`
+
+	// PolicyId Policy ID
+	PolicyId string `json:"policy_id"`
+
+	// Ports Ports and port ranges of the policy rule, all ports if empty
+	Ports *[]string `json:"ports,omitempty"`
+
+	// Protocol Protocol of the policy rule
+	Protocol string `json:"protocol"`
+}
+
+// TopologyPeer defines model for TopologyPeer.
+type TopologyPeer struct {
+	// Approved Indicates whether the peer is approved, peers pending approval have no connections
+	Approved bool `json:"approved"`
+
+	// Connected Indicates whether the peer is connected to the management service
+	Connected bool `json:"connected"`
+
+	// Id Peer ID
+	Id string `json:"id"`
+
+	// Ip Peer IP address
+	Ip string `json:"ip"`
+
+	// Name Peer name
+	Name string `json:"name"`
+}
+
+// TopologyRoute defines model for TopologyRoute.
+type TopologyRoute struct {
+	// Domains Domains of the dynamic route
+	Domains *[]string `json:"domains,omitempty"`
+
+	// ExitNode Indicates whether the route is a default route of an exit node
+	ExitNode bool `json:"exit_node"`
+
+	// Name Route network identifier or network resource name
+	Name string `json:"name"`
+
+	// Network Network range of the route
+	Network *string `json:"network,omitempty"`
+
+	// PeerId ID of the peer the route applies to
+	PeerId string `json:"peer_id"`
+
+	// RouteId ID of the route or of the network resource
+	RouteId string `json:"route_id"`
+
+	// RoutingPeerId ID of the routing peer forwarding the traffic of the route
+	RoutingPeerId string `json:"routing_peer_id"`
+}
+
+// UptimeCheck Posture check failing the peers running without a reboot for longer than the maximum uptime, a reboot applying the pending OS updates on most systems. The peers not reporting their boot time, like the mobile peers and the older clients, fail the check.
+type UptimeCheck struct {
+	// MaxUptime Maximum time since the last boot, in seconds, at least one hour
+	MaxUptime int `json:"max_uptime"`
+}
+
+// UsageSample defines model for UsageSample.
+type UsageSample struct {
+	// ActivePeers Number of peers seen by the management service on the day
+	ActivePeers int `json:"active_peers"`
+
+	// Date Day (UTC) of the usage in YYYY-MM-DD format
+	Date string `json:"date"`
+
+	// Events Number of activity events on the day
+	Events int `json:"events"`
+
+	// PeakConnectedPeers Highest number of peers connected to the management service at the same time on the day
+	PeakConnectedPeers int `json:"peak_connected_peers"`
+
+	// RelayBytes Traffic of the peers relayed by the relay service embedded in the management server, in bytes
+	RelayBytes int64 `json:"relay_bytes"`
+}
+
 // User defines model for User.
 type User struct {
 	// AutoGroups Group IDs to auto-assign to peers registered by this user
@@ -1729,6 +3471,129 @@ type UserRequest struct {
 	Role string `json:"role"`
 }
 
+// Webhook defines model for Webhook.
+type Webhook struct {
+	// Enabled Webhook status
+	Enabled bool `json:"enabled"`
+
+	// Events Activity codes the webhook is fired on. Fired on all events if empty.
+	Events []string `json:"events"`
+
+	// HasSecret Indicates whether the request body is signed with a secret
+	HasSecret bool `json:"has_secret"`
+
+	// Id Webhook ID
+	Id string `json:"id"`
+
+	// MaxRetries Number of delivery retries with exponential backoff after a failed request
+	MaxRetries int `json:"max_retries"`
+
+	// Name Webhook name
+	Name string `json:"name"`
+
+	// Url URL of the endpoint receiving a POST request per event
+	Url string `json:"url"`
+}
+
+// WebhookRequest defines model for WebhookRequest.
+type WebhookRequest struct {
+	// Enabled Webhook status
+	Enabled bool `json:"enabled"`
+
+	// Events Activity codes the webhook is fired on. Fired on all events if empty.
+	Events *[]string `json:"events,omitempty"`
+
+	// MaxRetries Number of delivery retries with exponential backoff after a failed request
+	MaxRetries *int `json:"max_retries,omitempty"`
+
+	// Name Webhook name
+	Name string `json:"name"`
+
+	// Secret Secret signing the request body with HMAC-SHA256 in the X-NetBird-Signature header. The current secret is kept on update if not set.
+	Secret *string `json:"secret,omitempty"`
+
+	// Url URL of the endpoint receiving a POST request per event
+	Url string `json:"url"`
+}
+
+// GetApiAccessHistoryParams defines parameters for GetApiAccessHistory.
+type GetApiAccessHistoryParams struct {
+	// Timestamp Point in time in RFC 3339 format, defaults to now
+	Timestamp *string `form:"timestamp,omitempty" json:"timestamp,omitempty"`
+
+	// PeerId Only returns the connections and routes of the peer
+	PeerId *string `form:"peer_id,omitempty" json:"peer_id,omitempty"`
+}
+
+// GetApiAccessHistorySnapshotsParams defines parameters for GetApiAccessHistorySnapshots.
+type GetApiAccessHistorySnapshotsParams struct {
+	// From Start of the period in RFC 3339 format, defaults to 30 days before its end
+	From *string `form:"from,omitempty" json:"from,omitempty"`
+
+	// To End of the period in RFC 3339 format, defaults to now
+	To *string `form:"to,omitempty" json:"to,omitempty"`
+}
+
+// GetApiConfigParams defines parameters for GetApiConfig.
+type GetApiConfigParams struct {
+	// Format Format of the response, defaults to yaml
+	Format *GetApiConfigParamsFormat `form:"format,omitempty" json:"format,omitempty"`
+}
+
+// GetApiConfigParamsFormat defines parameters for GetApiConfig.
+type GetApiConfigParamsFormat string
+
+// PutApiConfigParams defines parameters for PutApiConfig.
+type PutApiConfigParams struct {
+	// DryRun Returns the changes applying the config would make without applying them
+	DryRun *bool `form:"dry_run,omitempty" json:"dry_run,omitempty"`
+}
+
+// GetApiEventsStreamParams defines parameters for GetApiEventsStream.
+type GetApiEventsStreamParams struct {
+	// Events Comma separated list of activity codes the stream is filtered by, all events are streamed if empty
+	Events *string `form:"events,omitempty" json:"events,omitempty"`
+}
+
+// GetApiGroupsParams defines parameters for GetApiGroups.
+type GetApiGroupsParams struct {
+	// Name Filter groups by name
+	Name *string `form:"name,omitempty" json:"name,omitempty"`
+
+	// SortBy Field the results are sorted by, defaults to the name
+	SortBy *GetApiGroupsParamsSortBy `form:"sort_by,omitempty" json:"sort_by,omitempty"`
+
+	// Order Sort order, defaults to ascending
+	Order *GetApiGroupsParamsOrder `form:"order,omitempty" json:"order,omitempty"`
+
+	// Cursor Cursor of the page to return, as returned in the X-Next-Cursor header of the previous page
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+
+	// Limit Maximum number of results between 1 and 1000, defaults to 100 when a cursor is set
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// GetApiGroupsParamsSortBy defines parameters for GetApiGroups.
+type GetApiGroupsParamsSortBy string
+
+// GetApiGroupsParamsOrder defines parameters for GetApiGroups.
+type GetApiGroupsParamsOrder string
+
+// GetApiMonitorsMonitorIdResultsParams defines parameters for GetApiMonitorsMonitorIdResults.
+type GetApiMonitorsMonitorIdResultsParams struct {
+	// Since Only returns the results since the time (RFC 3339)
+	Since *time.Time `form:"since,omitempty" json:"since,omitempty"`
+
+	// Limit Maximum number of results returned, defaults to 100 and can be up to 10000
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// GetApiNetworksNetworkIdResourcesParams defines parameters for GetApiNetworksNetworkIdResources.
+type GetApiNetworksNetworkIdResourcesParams struct {
+	// Label Filter resources by label, in the key=value format. The resources having all the labels match.
+	Label *[]string `form:"label,omitempty" json:"label,omitempty"`
+}
+
 // GetApiPeersParams defines parameters for GetApiPeers.
 type GetApiPeersParams struct {
 	// Name Filter peers by name
@@ -1736,23 +3601,134 @@ type GetApiPeersParams struct {
 
 	// Ip Filter peers by IP address
 	Ip *string `form:"ip,omitempty" json:"ip,omitempty"`
+
+	// Os Filter peers by operating system family, e.g. linux, windows or darwin
+	Os *string `form:"os,omitempty" json:"os,omitempty"`
+
+	// Group Filter peers by group ID
+	Group *string `form:"group,omitempty" json:"group,omitempty"`
+
+	// Connected Filter peers by connection status
+	Connected *bool `form:"connected,omitempty" json:"connected,omitempty"`
+
+	// SerialNumber Filter peers by system serial number
+	SerialNumber *string `form:"serial_number,omitempty" json:"serial_number,omitempty"`
+
+	// Model Filter peers by system model, matching the models containing it
+	Model *string `form:"model,omitempty" json:"model,omitempty"`
+
+	// KernelVersion Filter peers by kernel version, matching the versions starting with it
+	KernelVersion *string `form:"kernel_version,omitempty" json:"kernel_version,omitempty"`
+
+	// Version Filter peers by NetBird client version, matching the versions starting with it
+	Version *string `form:"version,omitempty" json:"version,omitempty"`
+
+	// MinUptime Filter peers running for at least this time since their last boot, in seconds
+	MinUptime *int `form:"min_uptime,omitempty" json:"min_uptime,omitempty"`
+
+	// Label Filter peers by label, in the key=value format. The peers having all the labels match.
+	Label *[]string `form:"label,omitempty" json:"label,omitempty"`
+
+	// SortBy Field the results are sorted by, defaults to the name
+	SortBy *GetApiPeersParamsSortBy `form:"sort_by,omitempty" json:"sort_by,omitempty"`
+
+	// Order Sort order, defaults to ascending
+	Order *GetApiPeersParamsOrder `form:"order,omitempty" json:"order,omitempty"`
+
+	// Cursor Cursor of the page to return, as returned in the X-Next-Cursor header of the previous page
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+
+	// Limit Maximum number of results between 1 and 1000, defaults to 100 when a cursor is set
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
 }
 
+// GetApiPeersParamsSortBy defines parameters for GetApiPeers.
+type GetApiPeersParamsSortBy string
+
+// GetApiPeersParamsOrder defines parameters for GetApiPeers.
+type GetApiPeersParamsOrder string
+
 // GetApiPeersPeerIdIngressPortsParams defines parameters for GetApiPeersPeerIdIngressPorts.
 type GetApiPeersPeerIdIngressPortsParams struct {
 	// Name Filters ingress port allocations by name
 	Name *string `form:"name,omitempty" json:"name,omitempty"`
 }
 
+// GetApiRoutesParams defines parameters for GetApiRoutes.
+type GetApiRoutesParams struct {
+	// Label Filter routes by label, in the key=value format. The routes having all the labels match.
+	Label *[]string `form:"label,omitempty" json:"label,omitempty"`
+}
+
+// GetApiTopologyParams defines parameters for GetApiTopology.
+type GetApiTopologyParams struct {
+	// PeerId Only returns the connections and routes of the peer
+	PeerId *string `form:"peer_id,omitempty" json:"peer_id,omitempty"`
+}
+
+// GetApiUsageParams defines parameters for GetApiUsage.
+type GetApiUsageParams struct {
+	// From First day (UTC) of the usage in YYYY-MM-DD format, defaults to 30 days before the last day
+	From *string `form:"from,omitempty" json:"from,omitempty"`
+
+	// To Last day (UTC) of the usage in YYYY-MM-DD format, defaults to today
+	To *string `form:"to,omitempty" json:"to,omitempty"`
+
+	// Format Format of the response, defaults to json
+	Format *GetApiUsageParamsFormat `form:"format,omitempty" json:"format,omitempty"`
+}
+
+// GetApiUsageParamsFormat defines parameters for GetApiUsage.
+type GetApiUsageParamsFormat string
+
+// GetApiUsageExitNodesParams defines parameters for GetApiUsageExitNodes.
+type GetApiUsageExitNodesParams struct {
+	// Month Month (UTC) of the usage in YYYY-MM format, defaults to the current month
+	Month *string `form:"month,omitempty" json:"month,omitempty"`
+}
+
 // GetApiUsersParams defines parameters for GetApiUsers.
 type GetApiUsersParams struct {
 	// ServiceUser Filters users and returns either regular users or service users
 	ServiceUser *bool `form:"service_user,omitempty" json:"service_user,omitempty"`
 }
 
+// PostApiPoliciesParams defines parameters for PostApiPolicies.
+type PostApiPoliciesParams struct {
+	// DryRun Returns the peers whose connections would change without saving the policy
+	DryRun *bool `form:"dry_run,omitempty" json:"dry_run,omitempty"`
+}
+
+// PutApiPoliciesPolicyIdParams defines parameters for PutApiPoliciesPolicyId.
+type PutApiPoliciesPolicyIdParams struct {
+	// DryRun Returns the peers whose connections would change without saving the policy
+	DryRun *bool `form:"dry_run,omitempty" json:"dry_run,omitempty"`
+}
+
+// PutApiRoutesRouteIdParams defines parameters for PutApiRoutesRouteId.
+type PutApiRoutesRouteIdParams struct {
+	// DryRun Returns the peers whose routes would change without saving the route
+	DryRun *bool `form:"dry_run,omitempty" json:"dry_run,omitempty"`
+}
+
+// PutApiAccountsAccountIdNetworkParams defines parameters for PutApiAccountsAccountIdNetwork.
+type PutApiAccountsAccountIdNetworkParams struct {
+	// DryRun Returns the peers that would be moved to a new IP address without applying the change
+	DryRun *bool `form:"dry_run,omitempty" json:"dry_run,omitempty"`
+}
+
 // PutApiAccountsAccountIdJSONRequestBody defines body for PutApiAccountsAccountId for application/json ContentType.
 type PutApiAccountsAccountIdJSONRequestBody = AccountRequest
 
+// PostApiAccountsAccountIdExportJSONRequestBody defines body for PostApiAccountsAccountIdExport for application/json ContentType.
+type PostApiAccountsAccountIdExportJSONRequestBody = AccountExportRequest
+
+// PutApiAccountsAccountIdNetworkJSONRequestBody defines body for PutApiAccountsAccountIdNetwork for application/json ContentType.
+type PutApiAccountsAccountIdNetworkJSONRequestBody = AccountNetworkRequest
+
+// PostApiBulkJSONRequestBody defines body for PostApiBulk for application/json ContentType.
+type PostApiBulkJSONRequestBody = BulkRequest
+
 // PostApiDnsNameserversJSONRequestBody defines body for PostApiDnsNameservers for application/json ContentType.
 type PostApiDnsNameserversJSONRequestBody = NameserverGroupRequest
 
@@ -1774,6 +3750,12 @@ type PostApiIngressPeersJSONRequestBody = IngressPeerCreateRequest
 // PutApiIngressPeersIngressPeerIdJSONRequestBody defines body for PutApiIngressPeersIngressPeerId for application/json ContentType.
 type PutApiIngressPeersIngressPeerIdJSONRequestBody = IngressPeerUpdateRequest
 
+// PostApiMonitorsJSONRequestBody defines body for PostApiMonitors for application/json ContentType.
+type PostApiMonitorsJSONRequestBody = MonitorRequest
+
+// PutApiMonitorsMonitorIdJSONRequestBody defines body for PutApiMonitorsMonitorId for application/json ContentType.
+type PutApiMonitorsMonitorIdJSONRequestBody = MonitorRequest
+
 // PostApiNetworksJSONRequestBody defines body for PostApiNetworks for application/json ContentType.
 type PostApiNetworksJSONRequestBody = NetworkRequest
 
@@ -1795,36 +3777,60 @@ type PutApiNetworksNetworkIdRoutersRouterIdJSONRequestBody = NetworkRouterReques
 // PutApiPeersPeerIdJSONRequestBody defines body for PutApiPeersPeerId for application/json ContentType.
 type PutApiPeersPeerIdJSONRequestBody = PeerRequest
 
+// PostApiPeersPeerIdActionsJSONRequestBody defines body for PostApiPeersPeerIdActions for application/json ContentType.
+type PostApiPeersPeerIdActionsJSONRequestBody = PeerActionRequest
+
 // PostApiPeersPeerIdIngressPortsJSONRequestBody defines body for PostApiPeersPeerIdIngressPorts for application/json ContentType.
 type PostApiPeersPeerIdIngressPortsJSONRequestBody = IngressPortAllocationRequest
 
 // PutApiPeersPeerIdIngressPortsAllocationIdJSONRequestBody defines body for PutApiPeersPeerIdIngressPortsAllocationId for application/json ContentType.
 type PutApiPeersPeerIdIngressPortsAllocationIdJSONRequestBody = IngressPortAllocationRequest
 
+// PostApiPeersPeerIdProbeJSONRequestBody defines body for PostApiPeersPeerIdProbe for application/json ContentType.
+type PostApiPeersPeerIdProbeJSONRequestBody = PeerProbeRequest
+
 // PostApiPoliciesJSONRequestBody defines body for PostApiPolicies for application/json ContentType.
 type PostApiPoliciesJSONRequestBody = PolicyUpdate
 
+// PostApiPoliciesSimulateJSONRequestBody defines body for PostApiPoliciesSimulate for application/json ContentType.
+type PostApiPoliciesSimulateJSONRequestBody = PolicySimulationRequest
+
 // PutApiPoliciesPolicyIdJSONRequestBody defines body for PutApiPoliciesPolicyId for application/json ContentType.
 type PutApiPoliciesPolicyIdJSONRequestBody = PolicyCreate
 
+// PostApiPoliciesPolicyIdRolloutsJSONRequestBody defines body for PostApiPoliciesPolicyIdRollouts for application/json ContentType.
+type PostApiPoliciesPolicyIdRolloutsJSONRequestBody = PolicyRolloutRequest
+
 // PostApiPostureChecksJSONRequestBody defines body for PostApiPostureChecks for application/json ContentType.
 type PostApiPostureChecksJSONRequestBody = PostureCheckUpdate
 
 // PutApiPostureChecksPostureCheckIdJSONRequestBody defines body for PutApiPostureChecksPostureCheckId for application/json ContentType.
 type PutApiPostureChecksPostureCheckIdJSONRequestBody = PostureCheckUpdate
 
+// PostApiRolesJSONRequestBody defines body for PostApiRoles for application/json ContentType.
+type PostApiRolesJSONRequestBody = RoleRequest
+
+// PutApiRolesRoleIdJSONRequestBody defines body for PutApiRolesRoleId for application/json ContentType.
+type PutApiRolesRoleIdJSONRequestBody = RoleRequest
+
 // PostApiRoutesJSONRequestBody defines body for PostApiRoutes for application/json ContentType.
 type PostApiRoutesJSONRequestBody = RouteRequest
 
 // PutApiRoutesRouteIdJSONRequestBody defines body for PutApiRoutesRouteId for application/json ContentType.
 type PutApiRoutesRouteIdJSONRequestBody = RouteRequest
 
+// PostApiRoutesRouteIdRolloutsJSONRequestBody defines body for PostApiRoutesRouteIdRollouts for application/json ContentType.
+type PostApiRoutesRouteIdRolloutsJSONRequestBody = RouteRolloutRequest
+
 // PostApiSetupKeysJSONRequestBody defines body for PostApiSetupKeys for application/json ContentType.
 type PostApiSetupKeysJSONRequestBody = CreateSetupKeyRequest
 
 // PutApiSetupKeysKeyIdJSONRequestBody defines body for PutApiSetupKeysKeyId for application/json ContentType.
 type PutApiSetupKeysKeyIdJSONRequestBody = SetupKeyRequest
 
+// PostApiTenantsJSONRequestBody defines body for PostApiTenants for application/json ContentType.
+type PostApiTenantsJSONRequestBody = TenantRequest
+
 // PostApiUsersJSONRequestBody defines body for PostApiUsers for application/json ContentType.
 type PostApiUsersJSONRequestBody = UserCreateRequest
 
@@ -1833,3 +3839,12 @@ type PutApiUsersUserIdJSONRequestBody = UserRequest
 
 // PostApiUsersUserIdTokensJSONRequestBody defines body for PostApiUsersUserIdTokens for application/json ContentType.
 type PostApiUsersUserIdTokensJSONRequestBody = PersonalAccessTokenRequest
+
+// PostApiUsersUserIdTokensTokenIdRotateJSONRequestBody defines body for PostApiUsersUserIdTokensTokenIdRotate for application/json ContentType.
+type PostApiUsersUserIdTokensTokenIdRotateJSONRequestBody = PersonalAccessTokenRotateRequest
+
+// PostApiWebhooksJSONRequestBody defines body for PostApiWebhooks for application/json ContentType.
+type PostApiWebhooksJSONRequestBody = WebhookRequest
+
+// PutApiWebhooksWebhookIdJSONRequestBody defines body for PutApiWebhooksWebhookId for application/json ContentType.
+type PutApiWebhooksWebhookIdJSONRequestBody = WebhookRequest
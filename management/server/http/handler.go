@@ -11,31 +11,63 @@ import (
 	"github.com/netbirdio/management-integrations/integrations"
 
 	"github.com/netbirdio/netbird/management/server/account"
+	"github.com/netbirdio/netbird/management/server/backup"
 	"github.com/netbirdio/netbird/management/server/settings"
 
 	"github.com/netbirdio/netbird/management/server/integrations/port_forwarding"
 	"github.com/netbirdio/netbird/management/server/permissions"
 
+	nbaccesshistory "github.com/netbirdio/netbird/management/server/accesshistory"
 	"github.com/netbirdio/netbird/management/server/auth"
+	nbconnectivity "github.com/netbirdio/netbird/management/server/connectivity"
+	nbcontrolplane "github.com/netbirdio/netbird/management/server/controlplane"
+	nbdebugbundles "github.com/netbirdio/netbird/management/server/debugbundles"
+	nbdeclarative "github.com/netbirdio/netbird/management/server/declarative"
 	"github.com/netbirdio/netbird/management/server/geolocation"
 	nbgroups "github.com/netbirdio/netbird/management/server/groups"
+	"github.com/netbirdio/netbird/management/server/http/handlers/accesshistory"
 	"github.com/netbirdio/netbird/management/server/http/handlers/accounts"
+	"github.com/netbirdio/netbird/management/server/http/handlers/bulk"
+	"github.com/netbirdio/netbird/management/server/http/handlers/connectivity"
+	"github.com/netbirdio/netbird/management/server/http/handlers/controlplane"
+	"github.com/netbirdio/netbird/management/server/http/handlers/debugbundles"
+	"github.com/netbirdio/netbird/management/server/http/handlers/declarative"
 	"github.com/netbirdio/netbird/management/server/http/handlers/dns"
 	"github.com/netbirdio/netbird/management/server/http/handlers/events"
 	"github.com/netbirdio/netbird/management/server/http/handlers/groups"
+	"github.com/netbirdio/netbird/management/server/http/handlers/monitors"
 	"github.com/netbirdio/netbird/management/server/http/handlers/networks"
+	"github.com/netbirdio/netbird/management/server/http/handlers/peeractions"
 	"github.com/netbirdio/netbird/management/server/http/handlers/peers"
 	"github.com/netbirdio/netbird/management/server/http/handlers/policies"
+	"github.com/netbirdio/netbird/management/server/http/handlers/probes"
+	"github.com/netbirdio/netbird/management/server/http/handlers/roles"
+	"github.com/netbirdio/netbird/management/server/http/handlers/rollouts"
 	"github.com/netbirdio/netbird/management/server/http/handlers/routes"
+	"github.com/netbirdio/netbird/management/server/http/handlers/scim"
 	"github.com/netbirdio/netbird/management/server/http/handlers/setup_keys"
+	"github.com/netbirdio/netbird/management/server/http/handlers/tenants"
+	"github.com/netbirdio/netbird/management/server/http/handlers/topology"
+	"github.com/netbirdio/netbird/management/server/http/handlers/usage"
 	"github.com/netbirdio/netbird/management/server/http/handlers/users"
+	"github.com/netbirdio/netbird/management/server/http/handlers/webhooks"
 	"github.com/netbirdio/netbird/management/server/http/middleware"
 	"github.com/netbirdio/netbird/management/server/integrations/integrated_validator"
+	nbmonitors "github.com/netbirdio/netbird/management/server/monitors"
 	nbnetworks "github.com/netbirdio/netbird/management/server/networks"
 	"github.com/netbirdio/netbird/management/server/networks/resources"
 	"github.com/netbirdio/netbird/management/server/networks/routers"
+	nbpeeractions "github.com/netbirdio/netbird/management/server/peeractions"
 	nbpeers "github.com/netbirdio/netbird/management/server/peers"
+	nbprobes "github.com/netbirdio/netbird/management/server/probes"
+	nbroles "github.com/netbirdio/netbird/management/server/roles"
+	nbscim "github.com/netbirdio/netbird/management/server/scim"
+	"github.com/netbirdio/netbird/management/server/simulation"
+	"github.com/netbirdio/netbird/management/server/stream"
 	"github.com/netbirdio/netbird/management/server/telemetry"
+	nbtopology "github.com/netbirdio/netbird/management/server/topology"
+	nbusage "github.com/netbirdio/netbird/management/server/usage"
+	nbwebhooks "github.com/netbirdio/netbird/management/server/webhooks"
 )
 
 const apiPrefix = "/api"
@@ -56,6 +88,22 @@ func NewAPIHandler(
 	permissionsManager permissions.Manager,
 	peersManager nbpeers.Manager,
 	settingsManager settings.Manager,
+	scimManager nbscim.Manager,
+	rolesManager nbroles.Manager,
+	webhooksManager nbwebhooks.Manager,
+	streamManager stream.Manager,
+	probesManager nbprobes.Manager,
+	monitorsManager nbmonitors.Manager,
+	usageManager nbusage.Manager,
+	topologyManager nbtopology.Manager,
+	simulationManager simulation.Manager,
+	backupManager backup.Manager,
+	accessHistoryManager nbaccesshistory.Manager,
+	debugBundlesManager nbdebugbundles.Manager,
+	connectivityManager nbconnectivity.Manager,
+	peerActionsManager nbpeeractions.Manager,
+	declarativeManager nbdeclarative.Manager,
+	controlPlaneManager nbcontrolplane.Manager,
 ) (http.Handler, error) {
 
 	authMiddleware := middleware.NewAuthMiddleware(
@@ -64,9 +112,22 @@ func NewAPIHandler(
 		accountManager.SyncUserJWTGroups,
 	)
 
-	corsMiddleware := cors.AllowAll()
-
-	acMiddleware := middleware.NewAccessControl(accountManager.GetUserFromUserAuth)
+	corsMiddleware := cors.New(cors.Options{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{
+			http.MethodHead,
+			http.MethodGet,
+			http.MethodPost,
+			http.MethodPut,
+			http.MethodPatch,
+			http.MethodDelete,
+		},
+		AllowedHeaders: []string{"*"},
+		// the versions of the resources and the replays of the idempotent requests are readable by the browser clients
+		ExposedHeaders: []string{"ETag", middleware.IdempotentReplayedHeader},
+	})
+
+	acMiddleware := middleware.NewAccessControl(accountManager.GetUserFromUserAuth, accountManager.GetPeerGroups, permissionsManager)
 
 	rootRouter := mux.NewRouter()
 	metricsMiddleware := appMetrics.HTTPMiddleware()
@@ -74,22 +135,44 @@ func NewAPIHandler(
 	prefix := apiPrefix
 	router := rootRouter.PathPrefix(prefix).Subrouter()
 
-	router.Use(metricsMiddleware.Handler, corsMiddleware.Handler, authMiddleware.Handler, acMiddleware.Handler)
+	idempotencyMiddleware := middleware.NewIdempotency(accountManager.GetStore())
+	etagMiddleware := middleware.NewETag(accountManager.GetStore())
+
+	router.Use(metricsMiddleware.Handler, corsMiddleware.Handler, authMiddleware.Handler, acMiddleware.Handler,
+		idempotencyMiddleware.Handler, etagMiddleware.Handler)
 
 	if _, err := integrations.RegisterHandlers(ctx, prefix, router, accountManager, integratedValidator, appMetrics.GetMeter(), permissionsManager, peersManager, proxyController, settingsManager); err != nil {
 		return nil, fmt.Errorf("register integrations endpoints: %w", err)
 	}
 
-	accounts.AddEndpoints(accountManager, settingsManager, router)
+	accounts.AddEndpoints(accountManager, settingsManager, backupManager, router)
 	peers.AddEndpoints(accountManager, router)
 	users.AddEndpoints(accountManager, router)
 	setup_keys.AddEndpoints(accountManager, router)
-	policies.AddEndpoints(accountManager, LocationManager, router)
+	policies.AddEndpoints(accountManager, LocationManager, simulationManager, router)
 	groups.AddEndpoints(accountManager, router)
-	routes.AddEndpoints(accountManager, router)
+	routes.AddEndpoints(accountManager, simulationManager, router)
+	rollouts.AddEndpoints(accountManager, router)
 	dns.AddEndpoints(accountManager, router)
-	events.AddEndpoints(accountManager, router)
+	events.AddEndpoints(accountManager, streamManager, router)
 	networks.AddEndpoints(networksManager, resourceManager, routerManager, groupsManager, accountManager, router)
+	scim.AddEndpoints(scimManager, router)
+	roles.AddEndpoints(rolesManager, router)
+	tenants.AddEndpoints(accountManager, router)
+	webhooks.AddEndpoints(webhooksManager, router)
+	probes.AddEndpoints(probesManager, router)
+	monitors.AddEndpoints(monitorsManager, router)
+	usage.AddEndpoints(usageManager, router)
+	topology.AddEndpoints(topologyManager, router)
+	accesshistory.AddEndpoints(accessHistoryManager, router)
+	connectivity.AddEndpoints(connectivityManager, router)
+	peeractions.AddEndpoints(peerActionsManager, router)
+	declarative.AddEndpoints(declarativeManager, router)
+	controlplane.AddEndpoints(controlPlaneManager, router)
+	if err := debugbundles.AddEndpoints(debugBundlesManager, router); err != nil {
+		return nil, fmt.Errorf("register debug bundles endpoints: %w", err)
+	}
+	bulk.AddEndpoints(accountManager.GetStore(), router)
 
 	return rootRouter, nil
 }
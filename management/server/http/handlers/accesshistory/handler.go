@@ -0,0 +1,110 @@
+package accesshistory
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/netbirdio/netbird/management/server/accesshistory"
+	nbcontext "github.com/netbirdio/netbird/management/server/context"
+	"github.com/netbirdio/netbird/management/server/http/api"
+	"github.com/netbirdio/netbird/management/server/http/util"
+	"github.com/netbirdio/netbird/management/server/status"
+)
+
+// handler is a handler that returns the effective access of the account at a point in time
+type handler struct {
+	accessHistoryManager accesshistory.Manager
+}
+
+func AddEndpoints(accessHistoryManager accesshistory.Manager, router *mux.Router) {
+	accessHistoryHandler := newHandler(accessHistoryManager)
+	router.HandleFunc("/access-history", accessHistoryHandler.getAccessHistory).Methods("GET", "OPTIONS")
+	router.HandleFunc("/access-history/snapshots", accessHistoryHandler.getSnapshots).Methods("GET", "OPTIONS")
+}
+
+func newHandler(accessHistoryManager accesshistory.Manager) *handler {
+	return &handler{
+		accessHistoryManager: accessHistoryManager,
+	}
+}
+
+func (h *handler) getAccessHistory(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	accountID, userID := userAuth.AccountId, userAuth.UserId
+
+	query := r.URL.Query()
+
+	at, err := parseTime(query.Get("timestamp"))
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	snapshot, graph, err := h.accessHistoryManager.GetAccessAt(r.Context(), accountID, userID, at, query.Get("peer_id"))
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	util.WriteJSONObject(r.Context(), w, &api.AccessHistory{
+		Snapshot: *snapshot.ToAPIResponse(),
+		Topology: *graph.ToAPIResponse(),
+	})
+}
+
+func (h *handler) getSnapshots(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	accountID, userID := userAuth.AccountId, userAuth.UserId
+
+	query := r.URL.Query()
+
+	from, err := parseTime(query.Get("from"))
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+	to, err := parseTime(query.Get("to"))
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	snapshots, err := h.accessHistoryManager.GetSnapshots(r.Context(), accountID, userID, from, to)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	snapshotsResponse := make([]*api.AccessSnapshot, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		snapshotsResponse = append(snapshotsResponse, snapshot.ToAPIResponse())
+	}
+
+	util.WriteJSONObject(r.Context(), w, snapshotsResponse)
+}
+
+// parseTime parses an RFC 3339 time of the query, the zero time is returned if it is empty
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, status.Errorf(status.InvalidArgument, "invalid time %s, expected RFC 3339 format", value)
+	}
+
+	return t, nil
+}
@@ -2,14 +2,20 @@ package accounts
 
 import (
 	"encoding/json"
+	"fmt"
+	"net"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
 
 	"github.com/netbirdio/netbird/management/server/account"
+	"github.com/netbirdio/netbird/management/server/backup"
 	nbcontext "github.com/netbirdio/netbird/management/server/context"
 	"github.com/netbirdio/netbird/management/server/http/api"
+	"github.com/netbirdio/netbird/management/server/http/handlers/groups"
 	"github.com/netbirdio/netbird/management/server/http/util"
 	"github.com/netbirdio/netbird/management/server/settings"
 	"github.com/netbirdio/netbird/management/server/status"
@@ -20,20 +26,25 @@ import (
 type handler struct {
 	accountManager  account.Manager
 	settingsManager settings.Manager
+	backupManager   backup.Manager
 }
 
-func AddEndpoints(accountManager account.Manager, settingsManager settings.Manager, router *mux.Router) {
-	accountsHandler := newHandler(accountManager, settingsManager)
+func AddEndpoints(accountManager account.Manager, settingsManager settings.Manager, backupManager backup.Manager, router *mux.Router) {
+	accountsHandler := newHandler(accountManager, settingsManager, backupManager)
 	router.HandleFunc("/accounts/{accountId}", accountsHandler.updateAccount).Methods("PUT", "OPTIONS")
 	router.HandleFunc("/accounts/{accountId}", accountsHandler.deleteAccount).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/accounts/{accountId}/network", accountsHandler.getAccountNetwork).Methods("GET", "OPTIONS")
+	router.HandleFunc("/accounts/{accountId}/network", accountsHandler.updateAccountNetwork).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/accounts/{accountId}/export", accountsHandler.exportAccount).Methods("POST", "OPTIONS")
 	router.HandleFunc("/accounts", accountsHandler.getAllAccounts).Methods("GET", "OPTIONS")
 }
 
 // newHandler creates a new handler HTTP handler
-func newHandler(accountManager account.Manager, settingsManager settings.Manager) *handler {
+func newHandler(accountManager account.Manager, settingsManager settings.Manager, backupManager backup.Manager) *handler {
 	return &handler{
 		accountManager:  accountManager,
 		settingsManager: settingsManager,
+		backupManager:   backupManager,
 	}
 }
 
@@ -110,9 +121,32 @@ func (h *handler) updateAccount(w http.ResponseWriter, r *http.Request) {
 	if req.Settings.JwtAllowGroups != nil {
 		settings.JWTAllowGroups = *req.Settings.JwtAllowGroups
 	}
+	if req.Settings.JwtGroupsMappingRules != nil {
+		settings.JWTGroupsMappingRules = toJWTGroupsMappingRules(*req.Settings.JwtGroupsMappingRules)
+	}
 	if req.Settings.RoutingPeerDnsResolutionEnabled != nil {
 		settings.RoutingPeerDNSResolutionEnabled = *req.Settings.RoutingPeerDnsResolutionEnabled
 	}
+	if req.Settings.RoutingPeerForwardedTrafficFilteringEnabled != nil {
+		settings.RoutingPeerForwardedTrafficFilteringEnabled = *req.Settings.RoutingPeerForwardedTrafficFilteringEnabled
+	}
+	if req.Settings.PeerLoginExpirationNotification != nil {
+		settings.PeerLoginExpirationNotification = time.Duration(*req.Settings.PeerLoginExpirationNotification) * time.Second
+	}
+	if req.Settings.PeerLoginExpirationGraceEnabled != nil {
+		settings.PeerLoginExpirationGraceEnabled = *req.Settings.PeerLoginExpirationGraceEnabled
+	}
+	if req.Settings.ConnectionTuning != nil {
+		settings.ConnectionTuning = groups.ToConnectionTuning(req.Settings.ConnectionTuning)
+	}
+	if req.Settings.ClientSettings != nil {
+		settings.ClientSettings = toClientSettings(req.Settings.ClientSettings)
+	}
+	if req.Settings.DisabledNotifications != nil {
+		for _, category := range *req.Settings.DisabledNotifications {
+			settings.DisabledNotifications = append(settings.DisabledNotifications, string(category))
+		}
+	}
 
 	updatedAccount, err := h.accountManager.UpdateAccountSettings(r.Context(), accountID, userID, settings)
 	if err != nil {
@@ -149,12 +183,153 @@ func (h *handler) deleteAccount(w http.ResponseWriter, r *http.Request) {
 	util.WriteJSONObject(r.Context(), w, util.EmptyObject{})
 }
 
+// exportAccount is HTTP POST handler that returns the account encrypted with the passphrase of the request
+func (h *handler) exportAccount(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	accountID := mux.Vars(r)["accountId"]
+	if len(accountID) == 0 {
+		util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid account ID"), w)
+		return
+	}
+
+	var req api.PostApiAccountsAccountIdExportJSONRequestBody
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		util.WriteErrorResponse("couldn't parse JSON request", http.StatusBadRequest, w)
+		return
+	}
+
+	bundle, err := h.backupManager.ExportAccount(r.Context(), accountID, userAuth.UserId, req.Passphrase)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"netbird-account-%s-%s.bundle\"", accountID, time.Now().UTC().Format("20060102")))
+	if _, err = w.Write(bundle); err != nil {
+		log.WithContext(r.Context()).Errorf("failed to write the account export: %v", err)
+	}
+}
+
+// getAccountNetwork is HTTP GET handler that returns the overlay network range and the reserved ranges of the account
+func (h *handler) getAccountNetwork(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	accountID := mux.Vars(r)["accountId"]
+	if len(accountID) == 0 {
+		util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid account ID"), w)
+		return
+	}
+
+	network, err := h.accountManager.GetAccountNetwork(r.Context(), accountID, userAuth.UserId)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	util.WriteJSONObject(r.Context(), w, toAccountNetworkResponse(network))
+}
+
+// updateAccountNetwork is HTTP PUT handler that changes the overlay network range and the reserved ranges of the account.
+// With the dry_run query parameter it only returns the peers that would be moved to a new IP.
+func (h *handler) updateAccountNetwork(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	accountID := mux.Vars(r)["accountId"]
+	if len(accountID) == 0 {
+		util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid account ID"), w)
+		return
+	}
+
+	var dryRun bool
+	if value := r.URL.Query().Get("dry_run"); value != "" {
+		dryRun, err = strconv.ParseBool(value)
+		if err != nil {
+			util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid dry_run value %s", value), w)
+			return
+		}
+	}
+
+	var req api.PutApiAccountsAccountIdNetworkJSONRequestBody
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		util.WriteErrorResponse("couldn't parse JSON request", http.StatusBadRequest, w)
+		return
+	}
+
+	_, networkRange, err := net.ParseCIDR(req.NetworkRange)
+	if err != nil {
+		util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid network range %s", req.NetworkRange), w)
+		return
+	}
+
+	network, migrations, err := h.accountManager.UpdateAccountNetwork(r.Context(), accountID, userAuth.UserId, *networkRange, req.ReservedRanges, dryRun)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	resp := &api.AccountNetworkUpdate{
+		MigratedPeers: make([]api.PeerIPMigration, 0, len(migrations)),
+	}
+	if dryRun {
+		resp.NetworkRange = networkRange.String()
+		resp.ReservedRanges = req.ReservedRanges
+	} else {
+		networkResp := toAccountNetworkResponse(network)
+		resp.NetworkRange = networkResp.NetworkRange
+		resp.ReservedRanges = networkResp.ReservedRanges
+	}
+	if resp.ReservedRanges == nil {
+		resp.ReservedRanges = []string{}
+	}
+
+	for _, migration := range migrations {
+		resp.MigratedPeers = append(resp.MigratedPeers, api.PeerIPMigration{
+			PeerId:   migration.PeerID,
+			PeerName: migration.PeerName,
+			OldIp:    migration.OldIP.String(),
+			NewIp:    migration.NewIP.String(),
+		})
+	}
+
+	util.WriteJSONObject(r.Context(), w, resp)
+}
+
+func toAccountNetworkResponse(network *types.Network) *api.AccountNetwork {
+	reservedRanges := network.ReservedRanges
+	if reservedRanges == nil {
+		reservedRanges = []string{}
+	}
+
+	return &api.AccountNetwork{
+		NetworkRange:   network.Net.String(),
+		ReservedRanges: reservedRanges,
+	}
+}
+
 func toAccountResponse(accountID string, settings *types.Settings) *api.Account {
 	jwtAllowGroups := settings.JWTAllowGroups
 	if jwtAllowGroups == nil {
 		jwtAllowGroups = []string{}
 	}
 
+	peerLoginExpirationNotification := int(settings.PeerLoginExpirationNotification.Seconds())
+
 	apiSettings := api.AccountSettings{
 		PeerLoginExpiration:             int(settings.PeerLoginExpiration.Seconds()),
 		PeerLoginExpirationEnabled:      settings.PeerLoginExpirationEnabled,
@@ -164,8 +339,34 @@ func toAccountResponse(accountID string, settings *types.Settings) *api.Account
 		JwtGroupsEnabled:                &settings.JWTGroupsEnabled,
 		JwtGroupsClaimName:              &settings.JWTGroupsClaimName,
 		JwtAllowGroups:                  &jwtAllowGroups,
+		JwtGroupsMappingRules:           toAPIJWTGroupsMappingRules(settings.JWTGroupsMappingRules),
 		RegularUsersViewBlocked:         settings.RegularUsersViewBlocked,
 		RoutingPeerDnsResolutionEnabled: &settings.RoutingPeerDNSResolutionEnabled,
+		PeerLoginExpirationNotification: &peerLoginExpirationNotification,
+		PeerLoginExpirationGraceEnabled: &settings.PeerLoginExpirationGraceEnabled,
+
+		RoutingPeerForwardedTrafficFilteringEnabled: &settings.RoutingPeerForwardedTrafficFilteringEnabled,
+	}
+
+	if settings.ConnectionTuning != nil {
+		apiSettings.ConnectionTuning = groups.ToConnectionTuningResponse(settings.ConnectionTuning)
+	}
+
+	if settings.ClientSettings != nil {
+		firewallMode := api.ClientSettingsFirewallMode(settings.ClientSettings.FirewallMode)
+		apiSettings.ClientSettings = &api.ClientSettings{
+			DnsManagementEnabled: &settings.ClientSettings.DNSManagementEnabled,
+			AcceptRoutes:         &settings.ClientSettings.AcceptRoutes,
+			FirewallMode:         &firewallMode,
+		}
+	}
+
+	if len(settings.DisabledNotifications) > 0 {
+		disabledNotifications := make([]api.AccountSettingsDisabledNotifications, 0, len(settings.DisabledNotifications))
+		for _, category := range settings.DisabledNotifications {
+			disabledNotifications = append(disabledNotifications, api.AccountSettingsDisabledNotifications(category))
+		}
+		apiSettings.DisabledNotifications = &disabledNotifications
 	}
 
 	if settings.Extra != nil {
@@ -181,3 +382,52 @@ func toAccountResponse(accountID string, settings *types.Settings) *api.Account
 		Settings: apiSettings,
 	}
 }
+
+// toClientSettings converts the client settings of the request, the client defaults apply to the omitted settings
+func toClientSettings(req *api.ClientSettings) *types.ClientSettings {
+	settings := types.NewClientSettings()
+	if req.DnsManagementEnabled != nil {
+		settings.DNSManagementEnabled = *req.DnsManagementEnabled
+	}
+	if req.AcceptRoutes != nil {
+		settings.AcceptRoutes = *req.AcceptRoutes
+	}
+	if req.FirewallMode != nil {
+		settings.FirewallMode = types.FirewallMode(*req.FirewallMode)
+	}
+	return settings
+}
+
+func toJWTGroupsMappingRules(apiRules []api.JWTGroupsMappingRule) []*types.JWTGroupsMappingRule {
+	rules := make([]*types.JWTGroupsMappingRule, 0, len(apiRules))
+	for _, apiRule := range apiRules {
+		rule := &types.JWTGroupsMappingRule{Claim: apiRule.Claim}
+		if apiRule.Prefix != nil {
+			rule.Prefix = *apiRule.Prefix
+		}
+		if apiRule.StripPrefix != nil {
+			rule.StripPrefix = *apiRule.StripPrefix
+		}
+		if apiRule.Rename != nil {
+			rule.Rename = *apiRule.Rename
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+func toAPIJWTGroupsMappingRules(rules []*types.JWTGroupsMappingRule) *[]api.JWTGroupsMappingRule {
+	apiRules := make([]api.JWTGroupsMappingRule, 0, len(rules))
+	for _, rule := range rules {
+		apiRule := api.JWTGroupsMappingRule{
+			Claim:       rule.Claim,
+			Prefix:      &rule.Prefix,
+			StripPrefix: &rule.StripPrefix,
+		}
+		if rule.Rename != nil {
+			apiRule.Rename = &rule.Rename
+		}
+		apiRules = append(apiRules, apiRule)
+	}
+	return &apiRules
+}
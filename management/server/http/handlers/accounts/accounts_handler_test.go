@@ -61,6 +61,7 @@ func TestAccounts_AccountsHandler(t *testing.T) {
 
 	sr := func(v string) *string { return &v }
 	br := func(v bool) *bool { return &v }
+	ir := func(v int) *int { return &v }
 
 	handler := initAccountsTestData(t, &types.Account{
 		Id:      accountID,
@@ -100,8 +101,13 @@ func TestAccounts_AccountsHandler(t *testing.T) {
 				JwtGroupsClaimName:              sr(""),
 				JwtGroupsEnabled:                br(false),
 				JwtAllowGroups:                  &[]string{},
+				JwtGroupsMappingRules:           &[]api.JWTGroupsMappingRule{},
 				RegularUsersViewBlocked:         true,
 				RoutingPeerDnsResolutionEnabled: br(false),
+				PeerLoginExpirationNotification: ir(0),
+				PeerLoginExpirationGraceEnabled: br(false),
+
+				RoutingPeerForwardedTrafficFilteringEnabled: br(false),
 			},
 			expectedArray: true,
 			expectedID:    accountID,
@@ -120,8 +126,13 @@ func TestAccounts_AccountsHandler(t *testing.T) {
 				JwtGroupsClaimName:              sr(""),
 				JwtGroupsEnabled:                br(false),
 				JwtAllowGroups:                  &[]string{},
+				JwtGroupsMappingRules:           &[]api.JWTGroupsMappingRule{},
 				RegularUsersViewBlocked:         false,
 				RoutingPeerDnsResolutionEnabled: br(false),
+				PeerLoginExpirationNotification: ir(0),
+				PeerLoginExpirationGraceEnabled: br(false),
+
+				RoutingPeerForwardedTrafficFilteringEnabled: br(false),
 			},
 			expectedArray: false,
 			expectedID:    accountID,
@@ -131,7 +142,7 @@ func TestAccounts_AccountsHandler(t *testing.T) {
 			expectedBody:   true,
 			requestType:    http.MethodPut,
 			requestPath:    "/api/accounts/" + accountID,
-			requestBody:    bytes.NewBufferString("{\"settings\": {\"peer_login_expiration\": 15552000,\"peer_login_expiration_enabled\": false,\"jwt_groups_enabled\":true,\"jwt_groups_claim_name\":\"roles\",\"jwt_allow_groups\":[\"test\"],\"regular_users_view_blocked\":true}}"),
+			requestBody:    bytes.NewBufferString("{\"settings\": {\"peer_login_expiration\": 15552000,\"peer_login_expiration_enabled\": false,\"jwt_groups_enabled\":true,\"jwt_groups_claim_name\":\"roles\",\"jwt_allow_groups\":[\"test\"],\"regular_users_view_blocked\":true,\"routing_peer_forwarded_traffic_filtering_enabled\":true}}"),
 			expectedStatus: http.StatusOK,
 			expectedSettings: api.AccountSettings{
 				PeerLoginExpiration:             15552000,
@@ -140,8 +151,13 @@ func TestAccounts_AccountsHandler(t *testing.T) {
 				JwtGroupsClaimName:              sr("roles"),
 				JwtGroupsEnabled:                br(true),
 				JwtAllowGroups:                  &[]string{"test"},
+				JwtGroupsMappingRules:           &[]api.JWTGroupsMappingRule{},
 				RegularUsersViewBlocked:         true,
 				RoutingPeerDnsResolutionEnabled: br(false),
+				PeerLoginExpirationNotification: ir(0),
+				PeerLoginExpirationGraceEnabled: br(false),
+
+				RoutingPeerForwardedTrafficFilteringEnabled: br(true),
 			},
 			expectedArray: false,
 			expectedID:    accountID,
@@ -160,8 +176,45 @@ func TestAccounts_AccountsHandler(t *testing.T) {
 				JwtGroupsClaimName:              sr("groups"),
 				JwtGroupsEnabled:                br(true),
 				JwtAllowGroups:                  &[]string{},
+				JwtGroupsMappingRules:           &[]api.JWTGroupsMappingRule{},
 				RegularUsersViewBlocked:         true,
 				RoutingPeerDnsResolutionEnabled: br(false),
+				PeerLoginExpirationNotification: ir(0),
+				PeerLoginExpirationGraceEnabled: br(false),
+
+				RoutingPeerForwardedTrafficFilteringEnabled: br(false),
+			},
+			expectedArray: false,
+			expectedID:    accountID,
+		},
+		{
+			name:           "PutAccount OK with JWT groups mapping rules",
+			expectedBody:   true,
+			requestType:    http.MethodPut,
+			requestPath:    "/api/accounts/" + accountID,
+			requestBody:    bytes.NewBufferString("{\"settings\": {\"peer_login_expiration\": 554400,\"peer_login_expiration_enabled\": true,\"jwt_groups_enabled\":true,\"jwt_groups_mapping_rules\":[{\"claim\":\"realm_access.roles\",\"prefix\":\"netbird-\",\"strip_prefix\":true,\"rename\":{\"admins\":\"Administrators\"}}],\"regular_users_view_blocked\":true}}"),
+			expectedStatus: http.StatusOK,
+			expectedSettings: api.AccountSettings{
+				PeerLoginExpiration:        554400,
+				PeerLoginExpirationEnabled: true,
+				GroupsPropagationEnabled:   br(false),
+				JwtGroupsClaimName:         sr(""),
+				JwtGroupsEnabled:           br(true),
+				JwtAllowGroups:             &[]string{},
+				JwtGroupsMappingRules: &[]api.JWTGroupsMappingRule{
+					{
+						Claim:       "realm_access.roles",
+						Prefix:      sr("netbird-"),
+						StripPrefix: br(true),
+						Rename:      &map[string]string{"admins": "Administrators"},
+					},
+				},
+				RegularUsersViewBlocked:         true,
+				RoutingPeerDnsResolutionEnabled: br(false),
+				PeerLoginExpirationNotification: ir(0),
+				PeerLoginExpirationGraceEnabled: br(false),
+
+				RoutingPeerForwardedTrafficFilteringEnabled: br(false),
 			},
 			expectedArray: false,
 			expectedID:    accountID,
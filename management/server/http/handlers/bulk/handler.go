@@ -0,0 +1,256 @@
+package bulk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+
+	nbcontext "github.com/netbirdio/netbird/management/server/context"
+	"github.com/netbirdio/netbird/management/server/http/api"
+	"github.com/netbirdio/netbird/management/server/http/util"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
+	"github.com/netbirdio/netbird/util/errcode"
+)
+
+const (
+	// maxOperations limits the number of operations of a single bulk request
+	maxOperations = 1000
+	apiPrefix     = "/api"
+)
+
+// resourcePrefixes are the API paths bulk operations can be applied to
+var resourcePrefixes = []string{"/peers", "/groups", "/routes", "/policies"}
+
+var (
+	// errOperationFailed rolls back the savepoint of a failed operation
+	errOperationFailed = errors.New("bulk operation failed")
+	// errRolledBack rolls back the transaction of an atomic request with a failed operation
+	errRolledBack = errors.New("bulk request rolled back")
+)
+
+// handler applies bulk operations by serving every operation through the API router,
+// so that each of them goes through the same authentication, access control and validation as a single request.
+// The operations run in a single store transaction, every operation in a savepoint of it: the changes of a failed
+// operation are rolled back and the others are committed together, an atomic request is rolled back as a whole.
+type handler struct {
+	router *mux.Router
+	store  store.Store
+}
+
+func AddEndpoints(store store.Store, router *mux.Router) {
+	bulkHandler := newHandler(store, router)
+	router.HandleFunc("/bulk", bulkHandler.applyOperations).Methods("POST", "OPTIONS")
+}
+
+func newHandler(store store.Store, router *mux.Router) *handler {
+	return &handler{
+		router: router,
+		store:  store,
+	}
+}
+
+func (h *handler) applyOperations(w http.ResponseWriter, r *http.Request) {
+	if _, err := nbcontext.GetUserAuthFromContext(r.Context()); err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	var req api.PostApiBulkJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.WriteErrorResponse("couldn't parse JSON request", http.StatusBadRequest, w)
+		return
+	}
+
+	if len(req.Operations) == 0 {
+		util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "at least one operation is required"), w)
+		return
+	}
+
+	if len(req.Operations) > maxOperations {
+		util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "a bulk request can't have more than %d operations", maxOperations), w)
+		return
+	}
+
+	for i, operation := range req.Operations {
+		if err := validateOperation(operation); err != nil {
+			util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "operation %d: %s", i, err), w)
+			return
+		}
+	}
+
+	atomic := req.Atomic != nil && *req.Atomic
+	stopOnError := atomic || req.StopOnError != nil && *req.StopOnError
+
+	var resp api.BulkResponse
+	err := h.store.ExecuteInBatchTransaction(r.Context(), func(ctx context.Context) error {
+		resp = api.BulkResponse{
+			Results: make([]api.BulkOperationResult, 0, len(req.Operations)),
+		}
+
+		failed := false
+		for i, operation := range req.Operations {
+			if failed && stopOnError {
+				resp.Results = append(resp.Results, api.BulkOperationResult{Index: i, Skipped: true})
+				continue
+			}
+
+			result := h.applyInSavepoint(ctx, r, i, operation)
+			if succeeded(result) {
+				resp.Succeeded++
+			} else {
+				resp.Failed++
+				failed = true
+			}
+			resp.Results = append(resp.Results, result)
+		}
+
+		if failed && atomic {
+			return errRolledBack
+		}
+		return nil
+	})
+	switch {
+	case errors.Is(err, errRolledBack):
+		resp.RolledBack = true
+		for i := range resp.Results {
+			resp.Results[i].RolledBack = succeeded(resp.Results[i])
+		}
+	case err != nil:
+		log.WithContext(r.Context()).Errorf("failed to commit the bulk operations: %v", err)
+		util.WriteError(r.Context(), status.Errorf(status.Internal, "failed to commit the bulk operations"), w)
+		return
+	}
+
+	util.WriteJSONObject(r.Context(), w, &resp)
+}
+
+// applyInSavepoint applies the operation in a savepoint of the bulk transaction, the changes of the operation are
+// rolled back if it fails
+func (h *handler) applyInSavepoint(ctx context.Context, r *http.Request, index int, operation api.BulkOperation) api.BulkOperationResult {
+	var result api.BulkOperationResult
+	err := h.store.ExecuteInTransaction(ctx, func(store.Store) error {
+		result = h.apply(ctx, r, index, operation)
+		if !succeeded(result) {
+			return errOperationFailed
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errOperationFailed) {
+		log.WithContext(ctx).Errorf("failed to apply bulk operation %d: %v", index, err)
+		return withError(api.BulkOperationResult{Index: index}, http.StatusInternalServerError, errcode.Internal, "failed to apply the operation")
+	}
+	return result
+}
+
+func succeeded(result api.BulkOperationResult) bool {
+	return !result.Skipped && result.Status >= 200 && result.Status <= 299
+}
+
+// apply serves the operation through the API router with the credentials of the bulk request
+func (h *handler) apply(ctx context.Context, r *http.Request, index int, operation api.BulkOperation) api.BulkOperationResult {
+	result := api.BulkOperationResult{Index: index}
+
+	var body []byte
+	if operation.Body != nil {
+		var err error
+		body, err = json.Marshal(operation.Body)
+		if err != nil {
+			return withError(result, http.StatusBadRequest, errcode.InvalidArgument, "couldn't encode the operation body")
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, string(operation.Method), apiPrefix+operation.Path, bytes.NewReader(body))
+	if err != nil {
+		return withError(result, http.StatusBadRequest, errcode.InvalidArgument, "invalid operation path")
+	}
+	req.Header.Set("Authorization", r.Header.Get("Authorization"))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = r.RemoteAddr
+
+	recorder := newResponseRecorder()
+	h.router.ServeHTTP(recorder, req)
+	result.Status = recorder.status
+
+	if recorder.body.Len() == 0 {
+		return result
+	}
+
+	if result.Status < 200 || result.Status > 299 {
+		errResp := &util.ErrorResponse{}
+		if err := json.Unmarshal(recorder.body.Bytes(), errResp); err != nil || errResp.Message == "" {
+			return withError(result, result.Status, "", strings.TrimSpace(recorder.body.String()))
+		}
+		return withError(result, result.Status, errcode.Code(errResp.ErrorCode), errResp.Message)
+	}
+
+	respBody := map[string]interface{}{}
+	if err := json.Unmarshal(recorder.body.Bytes(), &respBody); err != nil {
+		log.WithContext(ctx).Debugf("bulk operation %d returned a body that is not a JSON object: %v", index, err)
+		return result
+	}
+	if len(respBody) > 0 {
+		result.Body = &respBody
+	}
+
+	return result
+}
+
+func validateOperation(operation api.BulkOperation) error {
+	switch operation.Method {
+	case api.BulkOperationMethodPOST, api.BulkOperationMethodPUT, api.BulkOperationMethodDELETE:
+	default:
+		return fmt.Errorf("unsupported method %q", operation.Method)
+	}
+
+	for _, prefix := range resourcePrefixes {
+		if operation.Path == prefix || strings.HasPrefix(operation.Path, prefix+"/") {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("path %q is not one of %s", operation.Path, strings.Join(resourcePrefixes, ", "))
+}
+
+func withError(result api.BulkOperationResult, code int, errorCode errcode.Code, message string) api.BulkOperationResult {
+	result.Status = code
+	result.Error = &message
+	if errorCode != "" {
+		errCode := string(errorCode)
+		result.ErrorCode = &errCode
+	}
+	return result
+}
+
+// responseRecorder captures the response of an operation
+type responseRecorder struct {
+	header http.Header
+	body   bytes.Buffer
+	status int
+}
+
+func newResponseRecorder() *responseRecorder {
+	return &responseRecorder{
+		header: make(http.Header),
+		status: http.StatusOK,
+	}
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	return r.body.Write(b)
+}
+
+func (r *responseRecorder) WriteHeader(code int) {
+	r.status = code
+}
@@ -0,0 +1,194 @@
+package bulk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	nbcontext "github.com/netbirdio/netbird/management/server/context"
+	"github.com/netbirdio/netbird/management/server/http/api"
+	"github.com/netbirdio/netbird/management/server/http/util"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
+	"github.com/netbirdio/netbird/management/server/types"
+)
+
+const testAccountID = "bf1c8084-ba50-4ce7-9439-34653001fc3b"
+
+func testRouter(t *testing.T) (*mux.Router, store.Store) {
+	t.Helper()
+
+	testStore, cleanup, err := store.NewTestStoreFromSQL(context.Background(), "../../../testdata/store.sql", t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(cleanup)
+
+	router := mux.NewRouter()
+	apiRouter := router.PathPrefix("/api").Subrouter()
+	apiRouter.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "Token secret", r.Header.Get("Authorization"), "operations are authenticated with the credentials of the bulk request")
+			next.ServeHTTP(w, nbcontext.SetUserAuthInRequest(r, nbcontext.UserAuth{UserId: "test_user", AccountId: "test_account"}))
+		})
+	})
+
+	apiRouter.HandleFunc("/groups", func(w http.ResponseWriter, r *http.Request) {
+		var req api.GroupRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		group := &types.Group{ID: "group-" + req.Name, AccountID: testAccountID, Name: req.Name}
+		if err := testStore.SaveGroup(r.Context(), store.LockingStrengthUpdate, group); err != nil {
+			util.WriteError(r.Context(), err, w)
+			return
+		}
+		util.WriteJSONObject(r.Context(), w, &api.Group{Id: group.ID, Name: group.Name})
+	}).Methods("POST")
+	apiRouter.HandleFunc("/groups/{groupId}", func(w http.ResponseWriter, r *http.Request) {
+		// the group is saved before the operation fails, the change is rolled back with the operation
+		group := &types.Group{ID: mux.Vars(r)["groupId"], AccountID: testAccountID, Name: "partial"}
+		require.NoError(t, testStore.SaveGroup(r.Context(), store.LockingStrengthUpdate, group))
+		util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid group %s", group.ID), w)
+	}).Methods("PUT")
+	apiRouter.HandleFunc("/groups/{groupId}", func(w http.ResponseWriter, r *http.Request) {
+		util.WriteError(r.Context(), status.Errorf(status.NotFound, "group %s not found", mux.Vars(r)["groupId"]), w)
+	}).Methods("DELETE")
+
+	AddEndpoints(testStore, apiRouter)
+
+	return router, testStore
+}
+
+func groupExists(t *testing.T, s store.Store, groupID string) bool {
+	t.Helper()
+
+	_, err := s.GetGroupByID(context.Background(), store.LockingStrengthShare, testAccountID, groupID)
+	if err != nil {
+		sErr, ok := status.FromError(err)
+		require.True(t, ok && sErr.Type() == status.NotFound, "unexpected error: %v", err)
+		return false
+	}
+	return true
+}
+
+func doBulk(t *testing.T, router *mux.Router, req *api.BulkRequest) (int, *api.BulkResponse) {
+	t.Helper()
+
+	body, err := json.Marshal(req)
+	require.NoError(t, err)
+
+	httpReq := httptest.NewRequest(http.MethodPost, "/api/bulk", bytes.NewReader(body))
+	httpReq.Header.Set("Authorization", "Token secret")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httpReq)
+
+	resp := &api.BulkResponse{}
+	if recorder.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), resp))
+	}
+
+	return recorder.Code, resp
+}
+
+func TestBulk_ApplyOperations(t *testing.T) {
+	router, testStore := testRouter(t)
+	stop := true
+
+	operations := []api.BulkOperation{
+		{Method: api.BulkOperationMethodPOST, Path: "/groups", Body: &map[string]interface{}{"name": "dev"}},
+		{Method: api.BulkOperationMethodDELETE, Path: "/groups/missing"},
+		{Method: api.BulkOperationMethodPOST, Path: "/groups", Body: &map[string]interface{}{"name": "prod"}},
+	}
+
+	code, resp := doBulk(t, router, &api.BulkRequest{Operations: operations})
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, resp.Results, 3)
+	assert.Equal(t, 2, resp.Succeeded)
+	assert.Equal(t, 1, resp.Failed)
+	assert.Equal(t, http.StatusOK, resp.Results[0].Status)
+	assert.Equal(t, "group-dev", (*resp.Results[0].Body)["id"])
+	assert.Equal(t, http.StatusNotFound, resp.Results[1].Status)
+	assert.Equal(t, "group missing not found", *resp.Results[1].Error)
+	assert.Equal(t, http.StatusOK, resp.Results[2].Status, "a failed operation doesn't stop the others by default")
+	assert.False(t, resp.RolledBack)
+	assert.True(t, groupExists(t, testStore, "group-dev"))
+	assert.True(t, groupExists(t, testStore, "group-prod"))
+
+	operations = []api.BulkOperation{
+		{Method: api.BulkOperationMethodPOST, Path: "/groups", Body: &map[string]interface{}{"name": "staging"}},
+		{Method: api.BulkOperationMethodDELETE, Path: "/groups/missing"},
+		{Method: api.BulkOperationMethodPOST, Path: "/groups", Body: &map[string]interface{}{"name": "qa"}},
+	}
+	code, resp = doBulk(t, router, &api.BulkRequest{Operations: operations, StopOnError: &stop})
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 1, resp.Succeeded)
+	assert.Equal(t, 1, resp.Failed)
+	assert.Equal(t, http.StatusOK, resp.Results[0].Status, "the operations applied before the failed one are kept")
+	assert.True(t, resp.Results[2].Skipped)
+	assert.True(t, groupExists(t, testStore, "group-staging"))
+	assert.False(t, groupExists(t, testStore, "group-qa"))
+}
+
+func TestBulk_RollBackFailedOperations(t *testing.T) {
+	router, testStore := testRouter(t)
+	atomic := true
+
+	operations := []api.BulkOperation{
+		{Method: api.BulkOperationMethodPOST, Path: "/groups", Body: &map[string]interface{}{"name": "dev"}},
+		{Method: api.BulkOperationMethodPUT, Path: "/groups/partial"},
+		{Method: api.BulkOperationMethodPOST, Path: "/groups", Body: &map[string]interface{}{"name": "prod"}},
+	}
+
+	code, resp := doBulk(t, router, &api.BulkRequest{Operations: operations})
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 2, resp.Succeeded)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Results[1].Status)
+	assert.False(t, groupExists(t, testStore, "partial"), "the changes of a failed operation should be rolled back")
+	assert.True(t, groupExists(t, testStore, "group-dev"))
+	assert.True(t, groupExists(t, testStore, "group-prod"))
+
+	operations = []api.BulkOperation{
+		{Method: api.BulkOperationMethodPOST, Path: "/groups", Body: &map[string]interface{}{"name": "staging"}},
+		{Method: api.BulkOperationMethodPUT, Path: "/groups/partial"},
+		{Method: api.BulkOperationMethodPOST, Path: "/groups", Body: &map[string]interface{}{"name": "qa"}},
+	}
+
+	code, resp = doBulk(t, router, &api.BulkRequest{Operations: operations, Atomic: &atomic})
+	require.Equal(t, http.StatusOK, code)
+	assert.True(t, resp.RolledBack)
+	assert.Equal(t, 1, resp.Succeeded)
+	assert.Equal(t, 1, resp.Failed)
+	assert.Equal(t, http.StatusOK, resp.Results[0].Status)
+	assert.True(t, resp.Results[0].RolledBack, "the succeeded operations of an atomic request should be rolled back")
+	assert.False(t, resp.Results[1].RolledBack)
+	assert.True(t, resp.Results[2].Skipped)
+	assert.False(t, groupExists(t, testStore, "group-staging"))
+	assert.False(t, groupExists(t, testStore, "partial"))
+	assert.False(t, groupExists(t, testStore, "group-qa"))
+}
+
+func TestBulk_InvalidOperations(t *testing.T) {
+	router, _ := testRouter(t)
+
+	tt := []struct {
+		name       string
+		operations []api.BulkOperation
+	}{
+		{name: "no operations"},
+		{name: "unsupported method", operations: []api.BulkOperation{{Method: "GET", Path: "/groups"}}},
+		{name: "unsupported resource", operations: []api.BulkOperation{{Method: api.BulkOperationMethodPOST, Path: "/users"}}},
+		{name: "nested bulk", operations: []api.BulkOperation{{Method: api.BulkOperationMethodPOST, Path: "/bulk"}}},
+		{name: "too many operations", operations: make([]api.BulkOperation, maxOperations+1)},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			code, _ := doBulk(t, router, &api.BulkRequest{Operations: tc.operations})
+			assert.Equal(t, http.StatusUnprocessableEntity, code)
+		})
+	}
+}
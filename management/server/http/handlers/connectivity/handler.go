@@ -0,0 +1,66 @@
+package connectivity
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/netbirdio/netbird/management/server/connectivity"
+	nbcontext "github.com/netbirdio/netbird/management/server/context"
+	"github.com/netbirdio/netbird/management/server/http/api"
+	"github.com/netbirdio/netbird/management/server/http/util"
+)
+
+// handler is a handler that returns the connectivity reported by the peers of the account
+type handler struct {
+	connectivityManager connectivity.Manager
+}
+
+func AddEndpoints(connectivityManager connectivity.Manager, router *mux.Router) {
+	connectivityHandler := newHandler(connectivityManager)
+	router.HandleFunc("/connectivity", connectivityHandler.getSummary).Methods("GET", "OPTIONS")
+	router.HandleFunc("/connectivity/peers", connectivityHandler.getPeerReports).Methods("GET", "OPTIONS")
+}
+
+func newHandler(connectivityManager connectivity.Manager) *handler {
+	return &handler{
+		connectivityManager: connectivityManager,
+	}
+}
+
+func (h *handler) getSummary(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	summary, err := h.connectivityManager.GetSummary(r.Context(), userAuth.AccountId, userAuth.UserId)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	util.WriteJSONObject(r.Context(), w, summary.ToAPIResponse())
+}
+
+func (h *handler) getPeerReports(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	reports, err := h.connectivityManager.GetPeerReports(r.Context(), userAuth.AccountId, userAuth.UserId)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	reportsResponse := make([]*api.PeerConnectivity, 0, len(reports))
+	for _, report := range reports {
+		reportsResponse = append(reportsResponse, report.ToAPIResponse())
+	}
+
+	util.WriteJSONObject(r.Context(), w, reportsResponse)
+}
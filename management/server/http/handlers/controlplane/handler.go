@@ -0,0 +1,43 @@
+package controlplane
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	nbcontext "github.com/netbirdio/netbird/management/server/context"
+	"github.com/netbirdio/netbird/management/server/controlplane"
+	"github.com/netbirdio/netbird/management/server/http/util"
+)
+
+// handler is a handler that returns the operational state of the control plane of the account
+type handler struct {
+	controlPlaneManager controlplane.Manager
+}
+
+func AddEndpoints(controlPlaneManager controlplane.Manager, router *mux.Router) {
+	controlPlaneHandler := newHandler(controlPlaneManager)
+	router.HandleFunc("/control-plane/health", controlPlaneHandler.getHealth).Methods("GET", "OPTIONS")
+}
+
+func newHandler(controlPlaneManager controlplane.Manager) *handler {
+	return &handler{
+		controlPlaneManager: controlPlaneManager,
+	}
+}
+
+func (h *handler) getHealth(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	health, err := h.controlPlaneManager.GetHealth(r.Context(), userAuth.AccountId, userAuth.UserId)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	util.WriteJSONObject(r.Context(), w, health.ToAPIResponse())
+}
@@ -0,0 +1,200 @@
+package debugbundles
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+
+	nbcontext "github.com/netbirdio/netbird/management/server/context"
+	"github.com/netbirdio/netbird/management/server/debugbundles"
+	"github.com/netbirdio/netbird/management/server/http/api"
+	"github.com/netbirdio/netbird/management/server/http/middleware/bypass"
+	"github.com/netbirdio/netbird/management/server/http/util"
+	"github.com/netbirdio/netbird/management/server/status"
+)
+
+// uploadBypassPath is the path of the presigned upload URLs, the uploads are authenticated by their signature
+const uploadBypassPath = "/api/debug-bundles/*/upload"
+
+// handler is a handler that requests debug bundles from the peers and serves the uploaded bundles
+type handler struct {
+	debugBundlesManager debugbundles.Manager
+}
+
+func AddEndpoints(debugBundlesManager debugbundles.Manager, router *mux.Router) error {
+	if err := bypass.AddBypassPath(uploadBypassPath); err != nil {
+		return fmt.Errorf("add bypass path: %w", err)
+	}
+
+	debugBundlesHandler := newHandler(debugBundlesManager)
+	router.HandleFunc("/peers/{peerId}/debug-bundles", debugBundlesHandler.getAllDebugBundles).Methods("GET", "OPTIONS")
+	router.HandleFunc("/peers/{peerId}/debug-bundles", debugBundlesHandler.requestDebugBundle).Methods("POST", "OPTIONS")
+	router.HandleFunc("/peers/{peerId}/debug-bundles/{bundleId}", debugBundlesHandler.getDebugBundle).Methods("GET", "OPTIONS")
+	router.HandleFunc("/peers/{peerId}/debug-bundles/{bundleId}", debugBundlesHandler.deleteDebugBundle).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/peers/{peerId}/debug-bundles/{bundleId}/download", debugBundlesHandler.downloadDebugBundle).Methods("GET", "OPTIONS")
+	router.HandleFunc("/debug-bundles/{bundleId}/upload", debugBundlesHandler.uploadDebugBundle).Methods("PUT", "OPTIONS")
+	return nil
+}
+
+func newHandler(debugBundlesManager debugbundles.Manager) *handler {
+	return &handler{
+		debugBundlesManager: debugBundlesManager,
+	}
+}
+
+func (h *handler) getAllDebugBundles(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	peerID := mux.Vars(r)["peerId"]
+	if len(peerID) == 0 {
+		util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid peer ID"), w)
+		return
+	}
+
+	requests, err := h.debugBundlesManager.GetPeerRequests(r.Context(), userAuth.AccountId, userAuth.UserId, peerID)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	bundles := make([]*api.PeerDebugBundle, 0, len(requests))
+	for _, request := range requests {
+		bundles = append(bundles, request.ToAPIResponse())
+	}
+
+	util.WriteJSONObject(r.Context(), w, bundles)
+}
+
+func (h *handler) requestDebugBundle(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	peerID := mux.Vars(r)["peerId"]
+	if len(peerID) == 0 {
+		util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid peer ID"), w)
+		return
+	}
+
+	request, err := h.debugBundlesManager.RequestBundle(r.Context(), userAuth.AccountId, userAuth.UserId, peerID)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	util.WriteJSONObject(r.Context(), w, request.ToAPIResponse())
+}
+
+func (h *handler) getDebugBundle(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	peerID, bundleID, ok := bundleVars(w, r)
+	if !ok {
+		return
+	}
+
+	request, err := h.debugBundlesManager.GetRequest(r.Context(), userAuth.AccountId, userAuth.UserId, peerID, bundleID)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	util.WriteJSONObject(r.Context(), w, request.ToAPIResponse())
+}
+
+func (h *handler) deleteDebugBundle(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	peerID, bundleID, ok := bundleVars(w, r)
+	if !ok {
+		return
+	}
+
+	if err = h.debugBundlesManager.DeleteRequest(r.Context(), userAuth.AccountId, userAuth.UserId, peerID, bundleID); err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	util.WriteJSONObject(r.Context(), w, util.EmptyObject{})
+}
+
+func (h *handler) downloadDebugBundle(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	peerID, bundleID, ok := bundleVars(w, r)
+	if !ok {
+		return
+	}
+
+	bundle, request, err := h.debugBundlesManager.OpenBundle(r.Context(), userAuth.AccountId, userAuth.UserId, peerID, bundleID)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+	defer bundle.Close()
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Length", strconv.FormatInt(request.Size, 10))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"netbird-debug-%s-%s.zip\"", request.PeerID, request.ID))
+	if _, err = io.Copy(w, bundle); err != nil {
+		log.WithContext(r.Context()).Errorf("failed to write the debug bundle %s: %v", request.ID, err)
+	}
+}
+
+// uploadDebugBundle receives a bundle uploaded by a peer to a presigned URL, it isn't authenticated by a user
+func (h *handler) uploadDebugBundle(w http.ResponseWriter, r *http.Request) {
+	bundleID := mux.Vars(r)["bundleId"]
+	query := r.URL.Query()
+
+	expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if len(bundleID) == 0 || err != nil {
+		util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid upload URL"), w)
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, debugbundles.MaxBundleSize)
+	err = h.debugBundlesManager.StoreBundle(r.Context(), query.Get("account"), bundleID, expires, query.Get("signature"), body)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	util.WriteJSONObject(r.Context(), w, util.EmptyObject{})
+}
+
+// bundleVars returns the peer and the bundle IDs of the request path, an error response is written if either is missing
+func bundleVars(w http.ResponseWriter, r *http.Request) (string, string, bool) {
+	vars := mux.Vars(r)
+	peerID, bundleID := vars["peerId"], vars["bundleId"]
+	if len(peerID) == 0 {
+		util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid peer ID"), w)
+		return "", "", false
+	}
+	if len(bundleID) == 0 {
+		util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid debug bundle ID"), w)
+		return "", "", false
+	}
+	return peerID, bundleID, true
+}
@@ -0,0 +1,120 @@
+package declarative
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+
+	nbcontext "github.com/netbirdio/netbird/management/server/context"
+	"github.com/netbirdio/netbird/management/server/declarative"
+	"github.com/netbirdio/netbird/management/server/http/api"
+	"github.com/netbirdio/netbird/management/server/http/util"
+	"github.com/netbirdio/netbird/management/server/status"
+)
+
+// handler is a handler that exports and applies the declarative config of the account
+type handler struct {
+	declarativeManager declarative.Manager
+}
+
+func AddEndpoints(declarativeManager declarative.Manager, router *mux.Router) {
+	configHandler := newHandler(declarativeManager)
+	router.HandleFunc("/config", configHandler.exportConfig).Methods("GET", "OPTIONS")
+	router.HandleFunc("/config", configHandler.applyConfig).Methods("PUT", "OPTIONS")
+}
+
+func newHandler(declarativeManager declarative.Manager) *handler {
+	return &handler{
+		declarativeManager: declarativeManager,
+	}
+}
+
+// exportConfig is HTTP GET handler that returns the config of the account in the YAML or the JSON format
+func (h *handler) exportConfig(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	format := api.GetApiConfigParamsFormat(r.URL.Query().Get("format"))
+	if format != "" && format != api.GetApiConfigParamsFormatYaml && format != api.GetApiConfigParamsFormatJson {
+		util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid format %s", format), w)
+		return
+	}
+
+	spec, err := h.declarativeManager.Export(r.Context(), userAuth.AccountId, userAuth.UserId)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	if format == api.GetApiConfigParamsFormatJson {
+		util.WriteJSONObject(r.Context(), w, spec)
+		return
+	}
+
+	content, err := declarative.Marshal(spec)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/yaml")
+	if _, err = w.Write(content); err != nil {
+		log.WithContext(r.Context()).Errorf("failed to write the config: %v", err)
+	}
+}
+
+// applyConfig is HTTP PUT handler that makes the account match the config in the YAML or the JSON format.
+// With the dry_run query parameter it only returns the changes applying the config would make.
+func (h *handler) applyConfig(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	var dryRun bool
+	if value := r.URL.Query().Get("dry_run"); value != "" {
+		dryRun, err = strconv.ParseBool(value)
+		if err != nil {
+			util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid dry_run value %s", value), w)
+			return
+		}
+	}
+
+	content, err := io.ReadAll(http.MaxBytesReader(w, r.Body, declarative.MaxSpecSize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "config exceeds %d bytes", maxBytesErr.Limit), w)
+			return
+		}
+		util.WriteErrorResponse("couldn't read the config", http.StatusBadRequest, w)
+		return
+	}
+
+	spec, err := declarative.Parse(content)
+	if err != nil {
+		util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid config: %v", err), w)
+		return
+	}
+
+	var plan *declarative.Plan
+	if dryRun {
+		plan, err = h.declarativeManager.Plan(r.Context(), userAuth.AccountId, userAuth.UserId, spec)
+	} else {
+		plan, err = h.declarativeManager.Apply(r.Context(), userAuth.AccountId, userAuth.UserId, spec)
+	}
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	util.WriteJSONObject(r.Context(), w, plan.ToAPIResponse())
+}
@@ -22,6 +22,7 @@ type dnsSettingsHandler struct {
 func AddEndpoints(accountManager account.Manager, router *mux.Router) {
 	addDNSSettingEndpoint(accountManager, router)
 	addDNSNameserversEndpoint(accountManager, router)
+	addDNSZoneEndpoint(accountManager, router)
 }
 
 func addDNSSettingEndpoint(accountManager account.Manager, router *mux.Router) {
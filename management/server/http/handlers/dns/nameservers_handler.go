@@ -83,7 +83,7 @@ func (h *nameserversHandler) createNameserverGroup(w http.ResponseWriter, r *htt
 		return
 	}
 
-	nsGroup, err := h.accountManager.CreateNameServerGroup(r.Context(), accountID, req.Name, req.Description, nsList, req.Groups, req.Primary, req.Domains, req.Enabled, userID, req.SearchDomainsEnabled)
+	nsGroup, err := h.accountManager.CreateNameServerGroup(r.Context(), accountID, req.Name, req.Description, nsList, req.Groups, req.Primary, req.Domains, req.Enabled, userID, req.SearchDomainsEnabled, toServerRoutedAnswers(req.RoutedAnswers), toServerForwarderPeer(req.ForwarderPeer))
 	if err != nil {
 		util.WriteError(r.Context(), err, w)
 		return
@@ -133,6 +133,8 @@ func (h *nameserversHandler) updateNameserverGroup(w http.ResponseWriter, r *htt
 		Groups:               req.Groups,
 		Enabled:              req.Enabled,
 		SearchDomainsEnabled: req.SearchDomainsEnabled,
+		RoutedAnswers:        toServerRoutedAnswers(req.RoutedAnswers),
+		ForwarderPeer:        toServerForwarderPeer(req.ForwarderPeer),
 	}
 
 	err = h.accountManager.SaveNameServerGroup(r.Context(), accountID, userID, updatedNSGroup)
@@ -211,6 +213,20 @@ func toServerNSList(apiNSList []api.Nameserver) ([]nbdns.NameServer, error) {
 	return nsList, nil
 }
 
+func toServerRoutedAnswers(routedAnswers *api.NameserverGroupRequestRoutedAnswers) nbdns.RoutedAnswers {
+	if routedAnswers == nil {
+		return nbdns.RoutedAnswersDisabled
+	}
+	return nbdns.RoutedAnswers(*routedAnswers)
+}
+
+func toServerForwarderPeer(forwarderPeer *string) string {
+	if forwarderPeer == nil {
+		return ""
+	}
+	return *forwarderPeer
+}
+
 func toNameserverGroupResponse(serverNSGroup *nbdns.NameServerGroup) *api.NameserverGroup {
 	var nsList []api.Nameserver
 	for _, ns := range serverNSGroup.NameServers {
@@ -222,6 +238,17 @@ func toNameserverGroupResponse(serverNSGroup *nbdns.NameServerGroup) *api.Namese
 		nsList = append(nsList, apiNS)
 	}
 
+	var routedAnswers *api.NameserverGroupRoutedAnswers
+	if serverNSGroup.RoutedAnswers != nbdns.RoutedAnswersDisabled {
+		mode := api.NameserverGroupRoutedAnswers(serverNSGroup.RoutedAnswers)
+		routedAnswers = &mode
+	}
+
+	var forwarderPeer *string
+	if serverNSGroup.ForwarderPeer != "" {
+		forwarderPeer = &serverNSGroup.ForwarderPeer
+	}
+
 	return &api.NameserverGroup{
 		Id:                   serverNSGroup.ID,
 		Name:                 serverNSGroup.Name,
@@ -232,5 +259,7 @@ func toNameserverGroupResponse(serverNSGroup *nbdns.NameServerGroup) *api.Namese
 		Nameservers:          nsList,
 		Enabled:              serverNSGroup.Enabled,
 		SearchDomainsEnabled: serverNSGroup.SearchDomainsEnabled,
+		RoutedAnswers:        routedAnswers,
+		ForwarderPeer:        forwarderPeer,
 	}
 }
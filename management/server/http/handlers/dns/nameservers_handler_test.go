@@ -15,6 +15,7 @@ import (
 	nbdns "github.com/netbirdio/netbird/dns"
 	"github.com/netbirdio/netbird/management/server/http/api"
 	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/util"
 
 	"github.com/gorilla/mux"
 
@@ -60,7 +61,7 @@ func initNameserversTestData() *nameserversHandler {
 				}
 				return nil, status.Errorf(status.NotFound, "nameserver group with ID %s not found", nsGroupID)
 			},
-			CreateNameServerGroupFunc: func(_ context.Context, accountID string, name, description string, nameServerList []nbdns.NameServer, groups []string, primary bool, domains []string, enabled bool, _ string, searchDomains bool) (*nbdns.NameServerGroup, error) {
+			CreateNameServerGroupFunc: func(_ context.Context, accountID string, name, description string, nameServerList []nbdns.NameServer, groups []string, primary bool, domains []string, enabled bool, _ string, searchDomains bool, routedAnswers nbdns.RoutedAnswers, forwarderPeer string) (*nbdns.NameServerGroup, error) {
 				return &nbdns.NameServerGroup{
 					ID:                   existingNSGroupID,
 					Name:                 name,
@@ -71,6 +72,8 @@ func initNameserversTestData() *nameserversHandler {
 					Primary:              primary,
 					Domains:              domains,
 					SearchDomainsEnabled: searchDomains,
+					RoutedAnswers:        routedAnswers,
+					ForwarderPeer:        forwarderPeer,
 				}, nil
 			},
 			DeleteNameServerGroupFunc: func(_ context.Context, accountID, nsGroupID, _ string) error {
@@ -134,6 +137,31 @@ func TestNameserversHandlers(t *testing.T) {
 				Primary: true,
 			},
 		},
+		{
+			name:        "POST With Routed Answers",
+			requestType: http.MethodPost,
+			requestPath: "/api/dns/nameservers",
+			requestBody: bytes.NewBuffer(
+				[]byte("{\"name\":\"name\",\"Description\":\"Post\",\"nameservers\":[{\"ip\":\"1.1.1.1\",\"ns_type\":\"udp\",\"port\":53}],\"groups\":[\"group\"],\"enabled\":true,\"primary\":true,\"routed_answers\":\"prefer\"}")),
+			expectedStatus: http.StatusOK,
+			expectedBody:   true,
+			expectedNSGroup: &api.NameserverGroup{
+				Id:          existingNSGroupID,
+				Name:        "name",
+				Description: "Post",
+				Nameservers: []api.Nameserver{
+					{
+						Ip:     "1.1.1.1",
+						NsType: "udp",
+						Port:   53,
+					},
+				},
+				Groups:        []string{"group"},
+				Enabled:       true,
+				Primary:       true,
+				RoutedAnswers: util.ToPtr(api.NameserverGroupRoutedAnswersPrefer),
+			},
+		},
 		{
 			name:        "POST Invalid Nameserver",
 			requestType: http.MethodPost,
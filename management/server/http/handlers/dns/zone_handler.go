@@ -0,0 +1,64 @@
+package dns
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/miekg/dns"
+
+	"github.com/netbirdio/netbird/management/server/account"
+	nbcontext "github.com/netbirdio/netbird/management/server/context"
+	"github.com/netbirdio/netbird/management/server/http/api"
+	"github.com/netbirdio/netbird/management/server/http/util"
+)
+
+// zoneHandler is a handler that returns the DNS zone of the peers
+type zoneHandler struct {
+	accountManager account.Manager
+}
+
+func addDNSZoneEndpoint(accountManager account.Manager, router *mux.Router) {
+	zoneHandler := &zoneHandler{accountManager: accountManager}
+	router.HandleFunc("/dns/zone", zoneHandler.getZone).Methods("GET", "OPTIONS")
+}
+
+// getZone returns the A and AAAA records of the peers
+func (h *zoneHandler) getZone(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	zone, err := h.accountManager.GetDNSZone(r.Context(), userAuth.AccountId, userAuth.UserId)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	apiZone := api.DNSZone{
+		Domain:  strings.TrimSuffix(zone.Domain, "."),
+		Records: make([]api.DNSRecord, 0, len(zone.Records)),
+	}
+	for _, record := range zone.Records {
+		var recordType api.DNSRecordType
+		switch uint16(record.Type) {
+		case dns.TypeA:
+			recordType = api.DNSRecordTypeA
+		case dns.TypeAAAA:
+			recordType = api.DNSRecordTypeAAAA
+		default:
+			continue
+		}
+
+		apiZone.Records = append(apiZone.Records, api.DNSRecord{
+			Name:  strings.TrimSuffix(record.Name, "."),
+			Type:  recordType,
+			Ttl:   record.TTL,
+			Value: record.RData,
+		})
+	}
+
+	util.WriteJSONObject(r.Context(), w, apiZone)
+}
@@ -0,0 +1,59 @@
+package dns
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	nbdns "github.com/netbirdio/netbird/dns"
+	nbcontext "github.com/netbirdio/netbird/management/server/context"
+	"github.com/netbirdio/netbird/management/server/http/api"
+	"github.com/netbirdio/netbird/management/server/mock_server"
+)
+
+func TestZoneHandler(t *testing.T) {
+	h := &zoneHandler{
+		accountManager: &mock_server.MockAccountManager{
+			GetDNSZoneFunc: func(_ context.Context, _, _ string) (nbdns.CustomZone, error) {
+				return nbdns.CustomZone{
+					Domain: "netbird.cloud.",
+					Records: []nbdns.SimpleRecord{
+						{Name: "peer-a.netbird.cloud.", Type: int(dns.TypeA), Class: nbdns.DefaultClass, TTL: 300, RData: "100.64.0.1"},
+						{Name: "peer-a.netbird.cloud.", Type: int(dns.TypeAAAA), Class: nbdns.DefaultClass, TTL: 300, RData: "fd00::1"},
+						{Name: "web.netbird.cloud.", Type: int(dns.TypeCNAME), Class: nbdns.DefaultClass, TTL: 300, RData: "peer-a.netbird.cloud."},
+					},
+				}, nil
+			},
+		},
+	}
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/dns/zone", nil)
+	req = nbcontext.SetUserAuthInRequest(req, nbcontext.UserAuth{
+		UserId:    testDNSSettingsUserID,
+		AccountId: testDNSSettingsAccountID,
+	})
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/dns/zone", h.getZone).Methods("GET")
+	router.ServeHTTP(recorder, req)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var zone api.DNSZone
+	require.NoError(t, json.NewDecoder(recorder.Body).Decode(&zone))
+	assert.Equal(t, api.DNSZone{
+		Domain: "netbird.cloud",
+		Records: []api.DNSRecord{
+			{Name: "peer-a.netbird.cloud", Type: api.DNSRecordTypeA, Ttl: 300, Value: "100.64.0.1"},
+			{Name: "peer-a.netbird.cloud", Type: api.DNSRecordTypeAAAA, Ttl: 300, Value: "fd00::1"},
+		},
+	}, zone)
+}
@@ -12,22 +12,25 @@ import (
 	nbcontext "github.com/netbirdio/netbird/management/server/context"
 	"github.com/netbirdio/netbird/management/server/http/api"
 	"github.com/netbirdio/netbird/management/server/http/util"
+	"github.com/netbirdio/netbird/management/server/stream"
 )
 
 // handler HTTP handler
 type handler struct {
 	accountManager account.Manager
+	streamManager  stream.Manager
 }
 
-func AddEndpoints(accountManager account.Manager, router *mux.Router) {
-	eventsHandler := newHandler(accountManager)
+func AddEndpoints(accountManager account.Manager, streamManager stream.Manager, router *mux.Router) {
+	eventsHandler := newHandler(accountManager, streamManager)
 	router.HandleFunc("/events", eventsHandler.getAllEvents).Methods("GET", "OPTIONS")
 	router.HandleFunc("/events/audit", eventsHandler.getAllEvents).Methods("GET", "OPTIONS")
+	router.HandleFunc("/events/stream", eventsHandler.streamEvents).Methods("GET")
 }
 
 // newHandler creates a new events handler
-func newHandler(accountManager account.Manager) *handler {
-	return &handler{accountManager: accountManager}
+func newHandler(accountManager account.Manager, streamManager stream.Manager) *handler {
+	return &handler{accountManager: accountManager, streamManager: streamManager}
 }
 
 // getAllEvents list of the given account
@@ -0,0 +1,91 @@
+package events
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	log "github.com/sirupsen/logrus"
+
+	nbcontext "github.com/netbirdio/netbird/management/server/context"
+	"github.com/netbirdio/netbird/management/server/http/util"
+)
+
+const (
+	streamPingInterval = 30 * time.Second
+	streamWriteTimeout = 10 * time.Second
+)
+
+// streamEvents upgrades the request to a WebSocket and sends the account events as JSON messages as they happen.
+// The optional events query parameter is a comma separated list of activity codes the stream is filtered by.
+func (h *handler) streamEvents(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		log.WithContext(r.Context()).Error(err)
+		http.Redirect(w, r, "/", http.StatusInternalServerError)
+		return
+	}
+
+	var codes []string
+	if filter := r.URL.Query().Get("events"); filter != "" {
+		for _, code := range strings.Split(filter, ",") {
+			if code = strings.TrimSpace(code); code != "" {
+				codes = append(codes, code)
+			}
+		}
+	}
+
+	sub, err := h.streamManager.Subscribe(r.Context(), userAuth.AccountId, userAuth.UserId, codes)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+	defer h.streamManager.Unsubscribe(sub)
+
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		log.WithContext(r.Context()).Debugf("failed to accept event stream: %v", err)
+		return
+	}
+	defer conn.CloseNow()
+
+	// the stream is write only, reading is only needed to handle the control frames and to notice the client leaving
+	ctx := conn.CloseRead(r.Context())
+
+	ticker := time.NewTicker(streamPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := ping(ctx, conn); err != nil {
+				log.WithContext(ctx).Debugf("event stream of user %s stopped responding: %v", userAuth.UserId, err)
+				return
+			}
+		case event, ok := <-sub.Events():
+			if !ok {
+				_ = conn.Close(websocket.StatusTryAgainLater, "event stream subscription ended")
+				return
+			}
+
+			writeCtx, cancel := context.WithTimeout(ctx, streamWriteTimeout)
+			err := wsjson.Write(writeCtx, conn, toEventResponse(event))
+			cancel()
+			if err != nil {
+				log.WithContext(ctx).Debugf("failed to write to the event stream of user %s: %v", userAuth.UserId, err)
+				return
+			}
+		}
+	}
+}
+
+func ping(ctx context.Context, conn *websocket.Conn) error {
+	ctx, cancel := context.WithTimeout(ctx, streamWriteTimeout)
+	defer cancel()
+	return conn.Ping(ctx)
+}
@@ -0,0 +1,62 @@
+package events
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/management/server/activity"
+	nbcontext "github.com/netbirdio/netbird/management/server/context"
+	"github.com/netbirdio/netbird/management/server/http/api"
+	"github.com/netbirdio/netbird/management/server/permissions"
+	"github.com/netbirdio/netbird/management/server/stream"
+)
+
+func TestEvents_StreamEvents(t *testing.T) {
+	broker := stream.NewBroker()
+	h := newHandler(nil, stream.NewManager(permissions.NewManagerMock(), broker))
+
+	router := mux.NewRouter()
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, nbcontext.SetUserAuthInRequest(r, nbcontext.UserAuth{
+				UserId:    r.URL.Query().Get("user"),
+				AccountId: "test_account",
+			}))
+		})
+	})
+	router.HandleFunc("/api/events/stream", h.streamEvents).Methods("GET")
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/events/stream?user=test_user&events=" + activity.PeerAddedByUser.StringCode()
+	conn, _, err := websocket.Dial(ctx, url, nil)
+	require.NoError(t, err)
+	defer conn.CloseNow()
+
+	// the subscription is created before the connection is upgraded, so the events can be published once dialing returned
+	require.NoError(t, broker.Publish(ctx, &activity.Event{ID: 1, Activity: activity.PolicyAdded, AccountID: "test_account"}))
+	require.NoError(t, broker.Publish(ctx, &activity.Event{ID: 2, Activity: activity.PeerAddedByUser, AccountID: "test_account", TargetID: "peer1"}))
+
+	event := &api.Event{}
+	require.NoError(t, wsjson.Read(ctx, conn, event))
+	assert.Equal(t, "2", event.Id)
+	assert.Equal(t, "peer1", event.TargetId)
+
+	_, resp, err := websocket.Dial(ctx, strings.Replace(url, "test_user", "forbiddenUser", 1), nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode, "users not allowed to read events can't subscribe")
+}
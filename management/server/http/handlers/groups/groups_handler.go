@@ -3,6 +3,7 @@ package groups
 import (
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
 	log "github.com/sirupsen/logrus"
@@ -12,8 +13,10 @@ import (
 	nbpeer "github.com/netbirdio/netbird/management/server/peer"
 
 	"github.com/netbirdio/netbird/management/server/http/api"
+	"github.com/netbirdio/netbird/management/server/http/pagination"
 	"github.com/netbirdio/netbird/management/server/http/util"
 	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
 	"github.com/netbirdio/netbird/management/server/types"
 )
 
@@ -48,6 +51,18 @@ func (h *handler) getAllGroups(w http.ResponseWriter, r *http.Request) {
 	}
 	accountID, userID := userAuth.AccountId, userAuth.UserId
 
+	opts, paginated, err := pagination.ParseListOptions(r)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	nameFilter := r.URL.Query().Get("name")
+	if paginated || nameFilter != "" {
+		h.listGroups(w, r, accountID, userID, store.GroupFilter{Name: nameFilter}, opts)
+		return
+	}
+
 	groups, err := h.accountManager.GetAllGroups(r.Context(), accountID, userID)
 	if err != nil {
 		util.WriteError(r.Context(), err, w)
@@ -68,6 +83,34 @@ func (h *handler) getAllGroups(w http.ResponseWriter, r *http.Request) {
 	util.WriteJSONObject(r.Context(), w, groupsResponse)
 }
 
+// listGroups writes a page of the groups filtered and sorted by the store, loading only the peers of the listed groups
+func (h *handler) listGroups(w http.ResponseWriter, r *http.Request, accountID, userID string, filter store.GroupFilter, opts store.ListOptions) {
+	groups, nextCursor, err := h.accountManager.ListGroups(r.Context(), accountID, userID, filter, opts)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	peerIDs := make([]string, 0)
+	for _, group := range groups {
+		peerIDs = append(peerIDs, group.Peers...)
+	}
+
+	peers, _, err := h.accountManager.ListPeers(r.Context(), accountID, userID, store.PeerFilter{IDs: peerIDs}, store.ListOptions{})
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	groupsResponse := make([]*api.Group, 0, len(groups))
+	for _, group := range groups {
+		groupsResponse = append(groupsResponse, toGroupResponse(peers, group))
+	}
+
+	pagination.WriteNextCursor(w, nextCursor)
+	util.WriteJSONObject(r.Context(), w, groupsResponse)
+}
+
 // updateGroup handles update to a group identified by a given ID
 func (h *handler) updateGroup(w http.ResponseWriter, r *http.Request) {
 	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
@@ -134,11 +177,53 @@ func (h *handler) updateGroup(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	nestedGroups := existingGroup.NestedGroups
+	if req.NestedGroups != nil {
+		nestedGroups = *req.NestedGroups
+	}
+
+	membershipRule := existingGroup.MembershipRule
+	if req.MembershipRule != nil {
+		membershipRule = toMembershipRule(req.MembershipRule)
+	}
+
+	sessionPolicy := existingGroup.SessionPolicy
+	if req.SessionPolicy != nil {
+		sessionPolicy = toSessionPolicy(req.SessionPolicy)
+	}
+
+	ephemeralPolicy := existingGroup.EphemeralPolicy
+	if req.EphemeralPolicy != nil {
+		ephemeralPolicy = toEphemeralPolicy(req.EphemeralPolicy)
+	}
+
+	connectionTuning := existingGroup.ConnectionTuning
+	if req.ConnectionTuning != nil {
+		connectionTuning = ToConnectionTuning(req.ConnectionTuning)
+	}
+
+	portPolicy := existingGroup.PortPolicy
+	if req.PortPolicy != nil {
+		portPolicy = toPortPolicy(req.PortPolicy)
+	}
+
+	exitNodeQuota := existingGroup.ExitNodeQuota
+	if req.ExitNodeQuota != nil {
+		exitNodeQuota = toExitNodeQuota(req.ExitNodeQuota)
+	}
+
 	group := types.Group{
 		ID:                   groupID,
 		Name:                 req.Name,
 		Peers:                peers,
 		Resources:            resources,
+		NestedGroups:         nestedGroups,
+		MembershipRule:       membershipRule,
+		SessionPolicy:        sessionPolicy,
+		EphemeralPolicy:      ephemeralPolicy,
+		ConnectionTuning:     connectionTuning,
+		PortPolicy:           portPolicy,
+		ExitNodeQuota:        exitNodeQuota,
 		Issued:               existingGroup.Issued,
 		IntegrationReference: existingGroup.IntegrationReference,
 	}
@@ -196,11 +281,23 @@ func (h *handler) createGroup(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	var nestedGroups []string
+	if req.NestedGroups != nil {
+		nestedGroups = *req.NestedGroups
+	}
+
 	group := types.Group{
-		Name:      req.Name,
-		Peers:     peers,
-		Resources: resources,
-		Issued:    types.GroupIssuedAPI,
+		Name:             req.Name,
+		Peers:            peers,
+		Resources:        resources,
+		NestedGroups:     nestedGroups,
+		MembershipRule:   toMembershipRule(req.MembershipRule),
+		SessionPolicy:    toSessionPolicy(req.SessionPolicy),
+		EphemeralPolicy:  toEphemeralPolicy(req.EphemeralPolicy),
+		ConnectionTuning: ToConnectionTuning(req.ConnectionTuning),
+		PortPolicy:       toPortPolicy(req.PortPolicy),
+		ExitNodeQuota:    toExitNodeQuota(req.ExitNodeQuota),
+		Issued:           types.GroupIssuedAPI,
 	}
 
 	err = h.accountManager.SaveGroup(r.Context(), accountID, userID, &group)
@@ -318,5 +415,245 @@ func toGroupResponse(peers []*nbpeer.Peer, group *types.Group) *api.Group {
 
 	gr.ResourcesCount = len(gr.Resources)
 
+	if group.HasNestedGroups() {
+		gr.NestedGroups = &group.NestedGroups
+	}
+
+	if group.HasMembershipRule() {
+		gr.MembershipRule = toMembershipRuleResponse(group.MembershipRule)
+	}
+
+	if group.SessionPolicy != nil {
+		gr.SessionPolicy = toSessionPolicyResponse(group.SessionPolicy)
+	}
+
+	if group.EphemeralPolicy != nil {
+		gr.EphemeralPolicy = toEphemeralPolicyResponse(group.EphemeralPolicy)
+	}
+
+	if group.ConnectionTuning != nil {
+		gr.ConnectionTuning = ToConnectionTuningResponse(group.ConnectionTuning)
+	}
+
+	if group.PortPolicy != nil {
+		gr.PortPolicy = toPortPolicyResponse(group.PortPolicy)
+	}
+
+	if group.ExitNodeQuota != nil {
+		gr.ExitNodeQuota = &api.GroupExitNodeQuota{MonthlyBytes: group.ExitNodeQuota.MonthlyBytes}
+	}
+
 	return &gr
 }
+
+// toMembershipRule converts the membership rule of the request, an empty rule removes the rule of the group
+func toMembershipRule(req *api.GroupMembershipRule) *types.GroupMembershipRule {
+	if req == nil {
+		return nil
+	}
+
+	rule := &types.GroupMembershipRule{}
+	if req.HostnameRegex != nil {
+		rule.HostnameRegex = *req.HostnameRegex
+	}
+	if req.Os != nil {
+		rule.OS = *req.Os
+	}
+	if req.SetupKeyIds != nil {
+		rule.SetupKeyIDs = *req.SetupKeyIds
+	}
+	if req.PostureCheckIds != nil {
+		rule.PostureCheckIDs = *req.PostureCheckIds
+	}
+	if req.Countries != nil {
+		rule.Countries = *req.Countries
+	}
+
+	if rule.HostnameRegex == "" && len(rule.OS) == 0 && len(rule.SetupKeyIDs) == 0 && len(rule.PostureCheckIDs) == 0 && len(rule.Countries) == 0 {
+		return nil
+	}
+
+	return rule
+}
+
+func toMembershipRuleResponse(rule *types.GroupMembershipRule) *api.GroupMembershipRule {
+	resp := &api.GroupMembershipRule{}
+	if rule.HostnameRegex != "" {
+		resp.HostnameRegex = &rule.HostnameRegex
+	}
+	if len(rule.OS) > 0 {
+		resp.Os = &rule.OS
+	}
+	if len(rule.SetupKeyIDs) > 0 {
+		resp.SetupKeyIds = &rule.SetupKeyIDs
+	}
+	if len(rule.PostureCheckIDs) > 0 {
+		resp.PostureCheckIds = &rule.PostureCheckIDs
+	}
+	if len(rule.Countries) > 0 {
+		resp.Countries = &rule.Countries
+	}
+	return resp
+}
+
+// toSessionPolicy converts the session policy of the request, an empty policy removes the policy of the group
+func toSessionPolicy(req *api.GroupSessionPolicy) *types.SessionPolicy {
+	if req == nil {
+		return nil
+	}
+
+	policy := &types.SessionPolicy{
+		LoginExpirationEnabled:      req.LoginExpirationEnabled,
+		InactivityExpirationEnabled: req.InactivityExpirationEnabled,
+	}
+	if req.LoginExpiration != nil {
+		policy.LoginExpiration = time.Duration(*req.LoginExpiration) * time.Second
+	}
+	if req.InactivityExpiration != nil {
+		policy.InactivityExpiration = time.Duration(*req.InactivityExpiration) * time.Second
+	}
+	if req.RequireReauth != nil {
+		policy.RequireReauth = *req.RequireReauth
+	}
+
+	if *policy == (types.SessionPolicy{}) {
+		return nil
+	}
+
+	return policy
+}
+
+func toSessionPolicyResponse(policy *types.SessionPolicy) *api.GroupSessionPolicy {
+	resp := &api.GroupSessionPolicy{
+		LoginExpirationEnabled:      policy.LoginExpirationEnabled,
+		InactivityExpirationEnabled: policy.InactivityExpirationEnabled,
+		RequireReauth:               &policy.RequireReauth,
+	}
+	if policy.LoginExpiration != 0 {
+		loginExpiration := int(policy.LoginExpiration.Seconds())
+		resp.LoginExpiration = &loginExpiration
+	}
+	if policy.InactivityExpiration != 0 {
+		inactivityExpiration := int(policy.InactivityExpiration.Seconds())
+		resp.InactivityExpiration = &inactivityExpiration
+	}
+	return resp
+}
+
+// toEphemeralPolicy converts the ephemeral policy of the request, a policy without cleanup period removes the policy of the group
+func toEphemeralPolicy(req *api.GroupEphemeralPolicy) *types.EphemeralPolicy {
+	if req == nil || req.CleanupAfter == nil || *req.CleanupAfter == 0 {
+		return nil
+	}
+
+	policy := &types.EphemeralPolicy{
+		CleanupAfter: time.Duration(*req.CleanupAfter) * time.Second,
+	}
+	if req.IncludeNonEphemeral != nil {
+		policy.IncludeNonEphemeral = *req.IncludeNonEphemeral
+	}
+
+	return policy
+}
+
+func toEphemeralPolicyResponse(policy *types.EphemeralPolicy) *api.GroupEphemeralPolicy {
+	cleanupAfter := int(policy.CleanupAfter.Seconds())
+	return &api.GroupEphemeralPolicy{
+		CleanupAfter:        &cleanupAfter,
+		IncludeNonEphemeral: &policy.IncludeNonEphemeral,
+	}
+}
+
+// ToConnectionTuning converts the connection tuning of the request, an empty tuning removes the tuning
+func ToConnectionTuning(req *api.ConnectionTuning) *types.ConnectionTuning {
+	if req == nil {
+		return nil
+	}
+
+	toDuration := func(seconds *int) time.Duration {
+		if seconds == nil {
+			return 0
+		}
+		return time.Duration(*seconds) * time.Second
+	}
+
+	tuning := &types.ConnectionTuning{
+		WgKeepalive:               toDuration(req.WgKeepalive),
+		HandshakeRetryMaxInterval: toDuration(req.HandshakeRetryMaxInterval),
+		HandshakeRetryTimeout:     toDuration(req.HandshakeRetryTimeout),
+		ICEKeepalive:              toDuration(req.IceKeepalive),
+		ICEDisconnectedTimeout:    toDuration(req.IceDisconnectedTimeout),
+		ICEFailedTimeout:          toDuration(req.IceFailedTimeout),
+	}
+	if tuning.IsEmpty() {
+		return nil
+	}
+
+	return tuning
+}
+
+// ToConnectionTuningResponse converts the connection tuning, the timers keeping the client defaults are omitted
+func ToConnectionTuningResponse(tuning *types.ConnectionTuning) *api.ConnectionTuning {
+	toSeconds := func(d time.Duration) *int {
+		if d == 0 {
+			return nil
+		}
+		seconds := int(d.Seconds())
+		return &seconds
+	}
+
+	return &api.ConnectionTuning{
+		WgKeepalive:               toSeconds(tuning.WgKeepalive),
+		HandshakeRetryMaxInterval: toSeconds(tuning.HandshakeRetryMaxInterval),
+		HandshakeRetryTimeout:     toSeconds(tuning.HandshakeRetryTimeout),
+		IceKeepalive:              toSeconds(tuning.ICEKeepalive),
+		IceDisconnectedTimeout:    toSeconds(tuning.ICEDisconnectedTimeout),
+		IceFailedTimeout:          toSeconds(tuning.ICEFailedTimeout),
+	}
+}
+
+// toPortPolicy converts the port policy of the request, an empty policy removes the policy of the group
+func toPortPolicy(req *api.GroupPortPolicy) *types.PortPolicy {
+	if req == nil {
+		return nil
+	}
+
+	toPort := func(port *int) int {
+		if port == nil {
+			return 0
+		}
+		return *port
+	}
+
+	policy := &types.PortPolicy{
+		WgPortRangeStart: toPort(req.WgPortRangeStart),
+		WgPortRangeEnd:   toPort(req.WgPortRangeEnd),
+	}
+	if req.Tcp443Only != nil {
+		policy.TCP443Only = *req.Tcp443Only
+	}
+	if policy.IsEmpty() {
+		return nil
+	}
+
+	return policy
+}
+
+func toPortPolicyResponse(policy *types.PortPolicy) *api.GroupPortPolicy {
+	resp := &api.GroupPortPolicy{
+		Tcp443Only: &policy.TCP443Only,
+	}
+	if policy.HasPortRange() {
+		resp.WgPortRangeStart = &policy.WgPortRangeStart
+		resp.WgPortRangeEnd = &policy.WgPortRangeEnd
+	}
+	return resp
+}
+
+// toExitNodeQuota converts the exit node quota of the request, a quota of 0 bytes removes the quota of the group
+func toExitNodeQuota(req *api.GroupExitNodeQuota) *types.ExitNodeQuota {
+	if req == nil || req.MonthlyBytes == 0 {
+		return nil
+	}
+	return &types.ExitNodeQuota{MonthlyBytes: req.MonthlyBytes}
+}
@@ -0,0 +1,223 @@
+package monitors
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	nbcontext "github.com/netbirdio/netbird/management/server/context"
+	"github.com/netbirdio/netbird/management/server/http/api"
+	"github.com/netbirdio/netbird/management/server/http/util"
+	"github.com/netbirdio/netbird/management/server/monitors"
+	"github.com/netbirdio/netbird/management/server/monitors/types"
+	"github.com/netbirdio/netbird/management/server/status"
+)
+
+// handler is a handler that manages the synthetic monitors of the account and returns their results
+type handler struct {
+	monitorsManager monitors.Manager
+}
+
+func AddEndpoints(monitorsManager monitors.Manager, router *mux.Router) {
+	monitorsHandler := newHandler(monitorsManager)
+	router.HandleFunc("/monitors", monitorsHandler.getAllMonitors).Methods("GET", "OPTIONS")
+	router.HandleFunc("/monitors", monitorsHandler.createMonitor).Methods("POST", "OPTIONS")
+	router.HandleFunc("/monitors/{monitorId}", monitorsHandler.getMonitor).Methods("GET", "OPTIONS")
+	router.HandleFunc("/monitors/{monitorId}", monitorsHandler.updateMonitor).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/monitors/{monitorId}", monitorsHandler.deleteMonitor).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/monitors/{monitorId}/results", monitorsHandler.getMonitorResults).Methods("GET", "OPTIONS")
+}
+
+func newHandler(monitorsManager monitors.Manager) *handler {
+	return &handler{
+		monitorsManager: monitorsManager,
+	}
+}
+
+func (h *handler) getAllMonitors(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	accountID, userID := userAuth.AccountId, userAuth.UserId
+
+	monitors, err := h.monitorsManager.GetAllMonitors(r.Context(), accountID, userID)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	monitorsResponse := make([]*api.Monitor, 0, len(monitors))
+	for _, monitor := range monitors {
+		monitorsResponse = append(monitorsResponse, monitor.ToAPIResponse())
+	}
+
+	util.WriteJSONObject(r.Context(), w, monitorsResponse)
+}
+
+func (h *handler) createMonitor(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	accountID, userID := userAuth.AccountId, userAuth.UserId
+
+	var req api.PostApiMonitorsJSONRequestBody
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		util.WriteErrorResponse("couldn't parse JSON request", http.StatusBadRequest, w)
+		return
+	}
+
+	monitor := &types.Monitor{}
+	monitor.FromAPIRequest(&req)
+
+	monitor.AccountID = accountID
+	monitor, err = h.monitorsManager.CreateMonitor(r.Context(), userID, monitor)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	util.WriteJSONObject(r.Context(), w, monitor.ToAPIResponse())
+}
+
+func (h *handler) getMonitor(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	accountID, userID := userAuth.AccountId, userAuth.UserId
+
+	monitorID := mux.Vars(r)["monitorId"]
+	if len(monitorID) == 0 {
+		util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid monitor ID"), w)
+		return
+	}
+
+	monitor, err := h.monitorsManager.GetMonitor(r.Context(), accountID, userID, monitorID)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	util.WriteJSONObject(r.Context(), w, monitor.ToAPIResponse())
+}
+
+func (h *handler) updateMonitor(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	accountID, userID := userAuth.AccountId, userAuth.UserId
+
+	monitorID := mux.Vars(r)["monitorId"]
+	if len(monitorID) == 0 {
+		util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid monitor ID"), w)
+		return
+	}
+
+	var req api.PutApiMonitorsMonitorIdJSONRequestBody
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		util.WriteErrorResponse("couldn't parse JSON request", http.StatusBadRequest, w)
+		return
+	}
+
+	monitor := &types.Monitor{}
+	monitor.FromAPIRequest(&req)
+
+	monitor.ID = monitorID
+	monitor.AccountID = accountID
+	monitor, err = h.monitorsManager.UpdateMonitor(r.Context(), userID, monitor)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	util.WriteJSONObject(r.Context(), w, monitor.ToAPIResponse())
+}
+
+func (h *handler) deleteMonitor(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	accountID, userID := userAuth.AccountId, userAuth.UserId
+
+	monitorID := mux.Vars(r)["monitorId"]
+	if len(monitorID) == 0 {
+		util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid monitor ID"), w)
+		return
+	}
+
+	err = h.monitorsManager.DeleteMonitor(r.Context(), accountID, userID, monitorID)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	util.WriteJSONObject(r.Context(), w, util.EmptyObject{})
+}
+
+func (h *handler) getMonitorResults(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	accountID, userID := userAuth.AccountId, userAuth.UserId
+
+	monitorID := mux.Vars(r)["monitorId"]
+	if len(monitorID) == 0 {
+		util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid monitor ID"), w)
+		return
+	}
+
+	query := r.URL.Query()
+
+	var since time.Time
+	if value := query.Get("since"); value != "" {
+		since, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid since time %s", value), w)
+			return
+		}
+	}
+
+	var limit int
+	if value := query.Get("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid limit %s", value), w)
+			return
+		}
+	}
+
+	results, err := h.monitorsManager.GetMonitorResults(r.Context(), accountID, userID, monitorID, since, limit)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	resultsResponse := make([]*api.MonitorResult, 0, len(results))
+	for _, result := range results {
+		resultsResponse = append(resultsResponse, result.ToAPIResponse())
+	}
+
+	util.WriteJSONObject(r.Context(), w, resultsResponse)
+}
@@ -0,0 +1,114 @@
+package peeractions
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	nbcontext "github.com/netbirdio/netbird/management/server/context"
+	"github.com/netbirdio/netbird/management/server/http/api"
+	"github.com/netbirdio/netbird/management/server/http/util"
+	"github.com/netbirdio/netbird/management/server/peeractions"
+	"github.com/netbirdio/netbird/management/server/status"
+)
+
+// handler is a handler that requests actions from the peers and returns their results
+type handler struct {
+	peerActionsManager peeractions.Manager
+}
+
+func AddEndpoints(peerActionsManager peeractions.Manager, router *mux.Router) {
+	peerActionsHandler := newHandler(peerActionsManager)
+	router.HandleFunc("/peers/{peerId}/actions", peerActionsHandler.getAllPeerActions).Methods("GET", "OPTIONS")
+	router.HandleFunc("/peers/{peerId}/actions", peerActionsHandler.requestPeerAction).Methods("POST", "OPTIONS")
+	router.HandleFunc("/peers/{peerId}/actions/{actionId}", peerActionsHandler.getPeerAction).Methods("GET", "OPTIONS")
+}
+
+func newHandler(peerActionsManager peeractions.Manager) *handler {
+	return &handler{
+		peerActionsManager: peerActionsManager,
+	}
+}
+
+func (h *handler) getAllPeerActions(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	peerID := mux.Vars(r)["peerId"]
+	if len(peerID) == 0 {
+		util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid peer ID"), w)
+		return
+	}
+
+	actions, err := h.peerActionsManager.GetPeerActions(r.Context(), userAuth.AccountId, userAuth.UserId, peerID)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	apiActions := make([]*api.PeerAction, 0, len(actions))
+	for _, action := range actions {
+		apiActions = append(apiActions, action.ToAPIResponse())
+	}
+
+	util.WriteJSONObject(r.Context(), w, apiActions)
+}
+
+func (h *handler) requestPeerAction(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	peerID := mux.Vars(r)["peerId"]
+	if len(peerID) == 0 {
+		util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid peer ID"), w)
+		return
+	}
+
+	var req api.PeerActionRequest
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.WriteErrorResponse("couldn't parse JSON request", http.StatusBadRequest, w)
+		return
+	}
+
+	action, err := h.peerActionsManager.RequestAction(r.Context(), userAuth.AccountId, userAuth.UserId, peerID, string(req.Action))
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	util.WriteJSONObject(r.Context(), w, action.ToAPIResponse())
+}
+
+func (h *handler) getPeerAction(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	vars := mux.Vars(r)
+	peerID, actionID := vars["peerId"], vars["actionId"]
+	if len(peerID) == 0 {
+		util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid peer ID"), w)
+		return
+	}
+	if len(actionID) == 0 {
+		util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid peer action ID"), w)
+		return
+	}
+
+	action, err := h.peerActionsManager.GetAction(r.Context(), userAuth.AccountId, userAuth.UserId, peerID, actionID)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	util.WriteJSONObject(r.Context(), w, action.ToAPIResponse())
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 	log "github.com/sirupsen/logrus"
@@ -13,9 +16,12 @@ import (
 	nbcontext "github.com/netbirdio/netbird/management/server/context"
 	"github.com/netbirdio/netbird/management/server/groups"
 	"github.com/netbirdio/netbird/management/server/http/api"
+	"github.com/netbirdio/netbird/management/server/http/pagination"
 	"github.com/netbirdio/netbird/management/server/http/util"
+	"github.com/netbirdio/netbird/management/server/labels"
 	nbpeer "github.com/netbirdio/netbird/management/server/peer"
 	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
 	"github.com/netbirdio/netbird/management/server/types"
 )
 
@@ -27,9 +33,13 @@ type Handler struct {
 func AddEndpoints(accountManager account.Manager, router *mux.Router) {
 	peersHandler := NewHandler(accountManager)
 	router.HandleFunc("/peers", peersHandler.GetAllPeers).Methods("GET", "OPTIONS")
+	router.HandleFunc("/peers/pending", peersHandler.GetPendingApprovalPeers).Methods("GET", "OPTIONS")
+	router.HandleFunc("/peers/scheduled-deletions", peersHandler.GetScheduledPeerDeletions).Methods("GET", "OPTIONS")
 	router.HandleFunc("/peers/{peerId}", peersHandler.HandlePeer).
 		Methods("GET", "PUT", "DELETE", "OPTIONS")
 	router.HandleFunc("/peers/{peerId}/accessible-peers", peersHandler.GetAccessiblePeers).Methods("GET", "OPTIONS")
+	router.HandleFunc("/peers/{peerId}/firewall-rules", peersHandler.GetPeerFirewallRules).Methods("GET", "OPTIONS")
+	router.HandleFunc("/peers/{peerId}/approve", peersHandler.ApprovePeer).Methods("POST", "OPTIONS")
 }
 
 // NewHandler creates a new peers Handler
@@ -97,6 +107,14 @@ func (h *Handler) updatePeer(ctx context.Context, accountID, userID, peerID stri
 		InactivityExpirationEnabled: req.InactivityExpirationEnabled,
 	}
 
+	if req.DnsAliases != nil {
+		update.DNSAliases = *req.DnsAliases
+	}
+
+	if req.Labels != nil {
+		update.Labels = *req.Labels
+	}
+
 	if req.ApprovalRequired != nil {
 		// todo: looks like that we reset all status property, is it right?
 		update.Status = &nbpeer.PeerStatus{
@@ -104,6 +122,22 @@ func (h *Handler) updatePeer(ctx context.Context, accountID, userID, peerID stri
 		}
 	}
 
+	if req.Ip != nil {
+		var ip net.IP
+		if *req.Ip != "" {
+			ip = net.ParseIP(*req.Ip)
+			if ip == nil {
+				util.WriteError(ctx, status.Errorf(status.InvalidArgument, "invalid IP address %s", *req.Ip), w)
+				return
+			}
+		}
+
+		if _, err = h.accountManager.SetPeerStaticIP(ctx, accountID, userID, peerID, ip); err != nil {
+			util.WriteError(ctx, err, w)
+			return
+		}
+	}
+
 	peer, err := h.accountManager.UpdatePeer(ctx, accountID, userID, update)
 	if err != nil {
 		util.WriteError(ctx, err, w)
@@ -180,12 +214,9 @@ func (h *Handler) GetAllPeers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	nameFilter := r.URL.Query().Get("name")
-	ipFilter := r.URL.Query().Get("ip")
-
 	accountID, userID := userAuth.AccountId, userAuth.UserId
 
-	peers, err := h.accountManager.GetPeers(r.Context(), accountID, userID, nameFilter, ipFilter)
+	peers, nextCursor, err := h.listPeers(r, accountID, userID)
 	if err != nil {
 		util.WriteError(r.Context(), err, w)
 		return
@@ -215,9 +246,168 @@ func (h *Handler) GetAllPeers(w http.ResponseWriter, r *http.Request) {
 	}
 	h.setApprovalRequiredFlag(respBody, validPeersMap)
 
+	pagination.WriteNextCursor(w, nextCursor)
+	util.WriteJSONObject(r.Context(), w, respBody)
+}
+
+// listPeers returns the peers matching the query parameters. The peers are filtered, sorted and paginated by the store
+// when the os, group, connected, inventory or label filters or the pagination parameters are set, the entire collection
+// is returned otherwise.
+func (h *Handler) listPeers(r *http.Request, accountID, userID string) ([]*nbpeer.Peer, string, error) {
+	query := r.URL.Query()
+
+	opts, paginated, err := pagination.ParseListOptions(r)
+	if err != nil {
+		return nil, "", err
+	}
+
+	filter := store.PeerFilter{
+		Name:          query.Get("name"),
+		IP:            query.Get("ip"),
+		OS:            query.Get("os"),
+		GroupID:       query.Get("group"),
+		SerialNumber:  query.Get("serial_number"),
+		Model:         query.Get("model"),
+		KernelVersion: query.Get("kernel_version"),
+		Version:       query.Get("version"),
+	}
+
+	if connected := query.Get("connected"); connected != "" {
+		value, err := strconv.ParseBool(connected)
+		if err != nil {
+			return nil, "", status.Errorf(status.InvalidArgument, "connected must be true or false")
+		}
+		filter.Connected = &value
+	}
+
+	if minUptime := query.Get("min_uptime"); minUptime != "" {
+		seconds, err := strconv.Atoi(minUptime)
+		if err != nil || seconds < 0 {
+			return nil, "", status.Errorf(status.InvalidArgument, "min_uptime must be a positive number of seconds")
+		}
+		filter.MinUptime = time.Duration(seconds) * time.Second
+	}
+
+	filter.Labels, err = labels.Parse(query["label"])
+	if err != nil {
+		return nil, "", status.Errorf(status.InvalidArgument, "%s", err)
+	}
+
+	if !paginated && filter.OS == "" && filter.GroupID == "" && filter.Connected == nil && !filter.HasInventoryFilter() && len(filter.Labels) == 0 {
+		peers, err := h.accountManager.GetPeers(r.Context(), accountID, userID, filter.Name, filter.IP)
+		return peers, "", err
+	}
+
+	return h.accountManager.ListPeers(r.Context(), accountID, userID, filter, opts)
+}
+
+// GetPendingApprovalPeers returns a list of peers waiting for an administrator approval
+func (h *Handler) GetPendingApprovalPeers(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	accountID, userID := userAuth.AccountId, userAuth.UserId
+
+	peers, err := h.accountManager.GetPendingApprovalPeers(r.Context(), accountID, userID)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	dnsDomain := h.accountManager.GetDNSDomain()
+
+	grps, _ := h.accountManager.GetAllGroups(r.Context(), accountID, userID)
+
+	grpsInfoMap := groups.ToGroupsInfoMap(grps, len(peers))
+	respBody := make([]*api.PeerBatch, 0, len(peers))
+	for _, peer := range peers {
+		peerToReturn, err := h.checkPeerStatus(peer)
+		if err != nil {
+			util.WriteError(r.Context(), err, w)
+			return
+		}
+
+		item := toPeerListItemResponse(peerToReturn, grpsInfoMap[peer.ID], dnsDomain, 0)
+		item.ApprovalRequired = true
+		respBody = append(respBody, item)
+	}
+
 	util.WriteJSONObject(r.Context(), w, respBody)
 }
 
+// GetScheduledPeerDeletions returns a list of offline peers that are going to be deleted by the ephemeral peers cleanup
+func (h *Handler) GetScheduledPeerDeletions(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	accountID, userID := userAuth.AccountId, userAuth.UserId
+
+	deletions, err := h.accountManager.GetScheduledPeerDeletions(r.Context(), accountID, userID)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	respBody := make([]*api.ScheduledPeerDeletion, 0, len(deletions))
+	for _, deletion := range deletions {
+		respBody = append(respBody, &api.ScheduledPeerDeletion{
+			PeerId:       deletion.Peer.ID,
+			PeerName:     deletion.Peer.Name,
+			Ephemeral:    deletion.Peer.Ephemeral,
+			LastSeen:     deletion.Peer.Status.LastSeen,
+			CleanupAfter: int(deletion.CleanupAfter.Seconds()),
+			ScheduledAt:  deletion.ScheduledAt,
+		})
+	}
+
+	util.WriteJSONObject(r.Context(), w, respBody)
+}
+
+// ApprovePeer approves a peer waiting for an administrator approval
+func (h *Handler) ApprovePeer(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	accountID, userID := userAuth.AccountId, userAuth.UserId
+	peerID := mux.Vars(r)["peerId"]
+	if len(peerID) == 0 {
+		util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid peer ID"), w)
+		return
+	}
+
+	peer, err := h.accountManager.ApprovePeer(r.Context(), accountID, userID, peerID)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	peerGroups, err := h.accountManager.GetPeerGroups(r.Context(), accountID, peer.ID)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+	grpsInfoMap := groups.ToGroupsInfoMap(peerGroups, 0)
+
+	validPeers, err := h.accountManager.GetValidatedPeers(r.Context(), accountID)
+	if err != nil {
+		log.WithContext(r.Context()).Errorf("failed to list approved peers: %v", err)
+		util.WriteError(r.Context(), fmt.Errorf("internal error"), w)
+		return
+	}
+
+	_, valid := validPeers[peer.ID]
+	util.WriteJSONObject(r.Context(), w, toSinglePeerResponse(peer, grpsInfoMap[peerID], h.accountManager.GetDNSDomain(), valid))
+}
+
 func (h *Handler) setApprovalRequiredFlag(respBody []*api.PeerBatch, approvedPeersMap map[string]struct{}) {
 	for _, peer := range respBody {
 		_, ok := approvedPeersMap[peer.Id]
@@ -280,12 +470,155 @@ func (h *Handler) GetAccessiblePeers(w http.ResponseWriter, r *http.Request) {
 
 	dnsDomain := h.accountManager.GetDNSDomain()
 
+	types.FlattenNestedGroups(account.Groups)
 	customZone := account.GetPeersCustomZone(r.Context(), dnsDomain)
 	netMap := account.GetPeerNetworkMap(r.Context(), peerID, customZone, validPeers, account.GetResourcePoliciesMap(), account.GetResourceRoutersMap(), nil)
 
 	util.WriteJSONObject(r.Context(), w, toAccessiblePeers(netMap, dnsDomain))
 }
 
+// GetPeerFirewallRules returns the firewall rules included in the network map of the peer with the policies they are
+// derived from, i.e. the rules the client was told to apply.
+func (h *Handler) GetPeerFirewallRules(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	accountID, userID := userAuth.AccountId, userAuth.UserId
+
+	vars := mux.Vars(r)
+	peerID := vars["peerId"]
+	if len(peerID) == 0 {
+		util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid peer ID"), w)
+		return
+	}
+
+	account, err := h.accountManager.GetAccountByID(r.Context(), accountID, userID)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	peer, ok := account.Peers[peerID]
+	if !ok {
+		util.WriteError(r.Context(), status.Errorf(status.NotFound, "peer not found"), w)
+		return
+	}
+
+	user, err := h.accountManager.GetUserByID(r.Context(), userID)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	// regular users can only see the rules of their own peers
+	if !user.HasAdminPower() && !user.IsServiceUser && !userAuth.IsChild && peer.UserID != user.Id {
+		util.WriteError(r.Context(), status.NewPermissionDeniedError(), w)
+		return
+	}
+
+	netMap, err := h.accountManager.GetNetworkMap(r.Context(), peerID)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	util.WriteJSONObject(r.Context(), w, toPeerFirewallRules(account, netMap))
+}
+
+func toPeerFirewallRules(account *types.Account, netMap *types.NetworkMap) *api.PeerFirewallRules {
+	// the peer rules reference the policy rules, the route rules the policies
+	policies := make(map[string]*types.Policy, len(account.Policies))
+	rulePolicies := make(map[string]*types.Policy)
+	policyRules := make(map[string]*types.PolicyRule)
+	for _, policy := range account.Policies {
+		policies[policy.ID] = policy
+		for _, rule := range policy.Rules {
+			rulePolicies[rule.ID] = policy
+			policyRules[rule.ID] = rule
+		}
+	}
+
+	peersByIP := make(map[string]*nbpeer.Peer, len(account.Peers))
+	for _, peer := range account.Peers {
+		peersByIP[peer.IP.String()] = peer
+	}
+
+	response := &api.PeerFirewallRules{
+		Rules:      make([]api.PeerFirewallRule, 0, len(netMap.FirewallRules)),
+		RouteRules: make([]api.PeerRouteFirewallRule, 0, len(netMap.RoutesFirewallRules)),
+	}
+	if netMap.Network != nil {
+		response.Serial = netMap.Network.CurrentSerial()
+	}
+
+	for _, rule := range netMap.FirewallRules {
+		apiRule := api.PeerFirewallRule{
+			PolicyRuleId: rule.PolicyID,
+			PeerIp:       rule.PeerIP,
+			Direction:    api.PeerFirewallRuleDirectionIn,
+			Action:       rule.Action,
+			Protocol:     rule.Protocol,
+			PortRange:    toAPIPortRange(rule.PortRange),
+		}
+		if policy, ok := rulePolicies[rule.PolicyID]; ok {
+			apiRule.PolicyId = policy.ID
+			apiRule.PolicyName = policy.Name
+			apiRule.PolicyRuleName = policyRules[rule.PolicyID].Name
+		}
+		if rule.Direction == types.FirewallRuleDirectionOUT {
+			apiRule.Direction = api.PeerFirewallRuleDirectionOut
+		}
+		if rule.Port != "" {
+			apiRule.Port = &rule.Port
+		}
+		if peer, ok := peersByIP[rule.PeerIP]; ok {
+			apiRule.PeerId = &peer.ID
+			apiRule.PeerName = &peer.Name
+		}
+		response.Rules = append(response.Rules, apiRule)
+	}
+
+	for _, rule := range netMap.RoutesFirewallRules {
+		sourceRanges := rule.SourceRanges
+		if sourceRanges == nil {
+			sourceRanges = []string{}
+		}
+		domains := rule.Domains.ToPunycodeList()
+		if domains == nil {
+			domains = []string{}
+		}
+		var policyName string
+		if policy, ok := policies[rule.PolicyID]; ok {
+			policyName = policy.Name
+		}
+		response.RouteRules = append(response.RouteRules, api.PeerRouteFirewallRule{
+			PolicyId:     rule.PolicyID,
+			PolicyName:   policyName,
+			SourceRanges: sourceRanges,
+			Destination:  rule.Destination,
+			Domains:      domains,
+			IsDynamic:    rule.IsDynamic,
+			Action:       rule.Action,
+			Protocol:     rule.Protocol,
+			Port:         int(rule.Port),
+			PortRange:    toAPIPortRange(rule.PortRange),
+		})
+	}
+
+	return response
+}
+
+// toAPIPortRange returns nil if the rule doesn't define a port range
+func toAPIPortRange(portRange types.RulePortRange) *api.RulePortRange {
+	if portRange.Start == 0 && portRange.End == 0 {
+		return nil
+	}
+	return &api.RulePortRange{Start: int(portRange.Start), End: int(portRange.End)}
+}
+
 func toAccessiblePeers(netMap *types.NetworkMap, dnsDomain string) []api.AccessiblePeer {
 	accessiblePeers := make([]api.AccessiblePeer, 0, len(netMap.Peers)+len(netMap.OfflinePeers))
 	for _, p := range netMap.Peers {
@@ -339,6 +672,10 @@ func toSinglePeerResponse(peer *nbpeer.Peer, groupsInfo []api.GroupMinimum, dnsD
 		UiVersion:                   peer.Meta.UIVersion,
 		DnsLabel:                    fqdn(peer, dnsDomain),
 		ExtraDnsLabels:              fqdnList(peer.ExtraDNSLabels, dnsDomain),
+		DnsAliases:                  dnsAliases(peer),
+		StaticIp:                    peer.StaticIP,
+		RouterMode:                  peer.Meta.RouterMode,
+		AdvertisedRoutes:            advertisedRoutes(peer),
 		LoginExpirationEnabled:      peer.LoginExpirationEnabled,
 		LastLogin:                   peer.GetLastLogin(),
 		LoginExpired:                peer.Status.LoginExpired,
@@ -347,6 +684,8 @@ func toSinglePeerResponse(peer *nbpeer.Peer, groupsInfo []api.GroupMinimum, dnsD
 		CityName:                    peer.Location.CityName,
 		SerialNumber:                peer.Meta.SystemSerialNumber,
 		InactivityExpirationEnabled: peer.InactivityExpirationEnabled,
+		Inventory:                   toPeerInventory(peer),
+		Labels:                      peerLabels(peer),
 	}
 }
 
@@ -374,6 +713,10 @@ func toPeerListItemResponse(peer *nbpeer.Peer, groupsInfo []api.GroupMinimum, dn
 		UiVersion:              peer.Meta.UIVersion,
 		DnsLabel:               fqdn(peer, dnsDomain),
 		ExtraDnsLabels:         fqdnList(peer.ExtraDNSLabels, dnsDomain),
+		DnsAliases:             dnsAliases(peer),
+		StaticIp:               peer.StaticIP,
+		RouterMode:             peer.Meta.RouterMode,
+		AdvertisedRoutes:       advertisedRoutes(peer),
 		LoginExpirationEnabled: peer.LoginExpirationEnabled,
 		LastLogin:              peer.GetLastLogin(),
 		LoginExpired:           peer.Status.LoginExpired,
@@ -381,11 +724,38 @@ func toPeerListItemResponse(peer *nbpeer.Peer, groupsInfo []api.GroupMinimum, dn
 		CountryCode:            peer.Location.CountryCode,
 		CityName:               peer.Location.CityName,
 		SerialNumber:           peer.Meta.SystemSerialNumber,
+		Inventory:              toPeerInventory(peer),
+		Labels:                 peerLabels(peer),
 
 		InactivityExpirationEnabled: peer.InactivityExpirationEnabled,
 	}
 }
 
+// toPeerInventory returns the inventory reported by the peer, the boot time and the uptime are omitted if the peer
+// doesn't report its boot time
+func toPeerInventory(peer *nbpeer.Peer) *api.PeerInventory {
+	osVersion := peer.Meta.OSVersion
+	if osVersion == "" {
+		osVersion = peer.Meta.Core
+	}
+
+	inventory := &api.PeerInventory{
+		Manufacturer:  peer.Meta.SystemManufacturer,
+		Model:         peer.Meta.SystemProductName,
+		SerialNumber:  peer.Meta.SystemSerialNumber,
+		OsVersion:     osVersion,
+		KernelVersion: peer.Meta.KernelVersion,
+		ClientVersion: peer.Meta.WtVersion,
+	}
+	if !peer.Meta.BootTime.IsZero() {
+		bootTime := peer.Meta.BootTime
+		uptime := int(peer.Meta.Uptime().Seconds())
+		inventory.BootTime = &bootTime
+		inventory.Uptime = &uptime
+	}
+	return inventory
+}
+
 func fqdn(peer *nbpeer.Peer, dnsDomain string) string {
 	fqdn := peer.FQDN(dnsDomain)
 	if fqdn == "" {
@@ -394,6 +764,32 @@ func fqdn(peer *nbpeer.Peer, dnsDomain string) string {
 		return fqdn
 	}
 }
+
+// advertisedRoutes returns the LAN prefixes advertised by the peer as a non-nil list
+func advertisedRoutes(peer *nbpeer.Peer) []string {
+	if peer.Meta.AdvertisedRoutes == nil {
+		return []string{}
+	}
+	return peer.Meta.AdvertisedRoutes
+}
+
+// dnsAliases returns the DNS aliases of the peer as a non-nil list
+func dnsAliases(peer *nbpeer.Peer) []string {
+	if peer.DNSAliases == nil {
+		return []string{}
+	}
+	return peer.DNSAliases
+}
+
+// peerLabels returns the labels of the peer, nil if it has none
+func peerLabels(peer *nbpeer.Peer) *api.Labels {
+	if len(peer.Labels) == 0 {
+		return nil
+	}
+	peerLabels := api.Labels(peer.Labels)
+	return &peerLabels
+}
+
 func fqdnList(extraLabels []string, dnsDomain string) []string {
 	fqdnList := make([]string, 0, len(extraLabels))
 	for _, label := range extraLabels {
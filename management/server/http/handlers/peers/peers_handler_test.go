@@ -15,6 +15,7 @@ import (
 	"github.com/gorilla/mux"
 	"golang.org/x/exp/maps"
 
+	nbdns "github.com/netbirdio/netbird/dns"
 	nbcontext "github.com/netbirdio/netbird/management/server/context"
 	"github.com/netbirdio/netbird/management/server/http/api"
 	nbpeer "github.com/netbirdio/netbird/management/server/peer"
@@ -161,6 +162,13 @@ func initTestMetaData(peers ...*nbpeer.Peer) *Handler {
 			GetAccountByIDFunc: func(ctx context.Context, accountID string, userID string) (*types.Account, error) {
 				return account, nil
 			},
+			GetNetworkMapFunc: func(ctx context.Context, peerID string) (*types.NetworkMap, error) {
+				validatedPeers := make(map[string]struct{}, len(account.Peers))
+				for id := range account.Peers {
+					validatedPeers[id] = struct{}{}
+				}
+				return account.GetPeerNetworkMap(ctx, peerID, nbdns.CustomZone{}, validatedPeers, nil, nil, nil), nil
+			},
 			HasConnectedChannelFunc: func(peerID string) bool {
 				statuses := make(map[string]struct{})
 				for _, peer := range peers {
@@ -447,3 +455,100 @@ func TestGetAccessiblePeers(t *testing.T) {
 		})
 	}
 }
+
+func TestGetPeerFirewallRules(t *testing.T) {
+	peer1 := &nbpeer.Peer{
+		ID:     "peer1",
+		Key:    "key1",
+		IP:     net.ParseIP("100.64.0.1"),
+		Status: &nbpeer.PeerStatus{Connected: true},
+		Name:   "peer1",
+		UserID: regularUser,
+	}
+
+	peer2 := &nbpeer.Peer{
+		ID:     "peer2",
+		Key:    "key2",
+		IP:     net.ParseIP("100.64.0.2"),
+		Status: &nbpeer.PeerStatus{Connected: true},
+		Name:   "peer2",
+		UserID: adminUser,
+	}
+
+	p := initTestMetaData(peer1, peer2)
+
+	tt := []struct {
+		name           string
+		peerID         string
+		callerUserID   string
+		expectedStatus int
+	}{
+		{
+			name:           "non admin user can access owned peer",
+			peerID:         "peer1",
+			callerUserID:   regularUser,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "non admin user can't access unowned peer",
+			peerID:         "peer2",
+			callerUserID:   regularUser,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "admin user can access unowned peer",
+			peerID:         "peer1",
+			callerUserID:   adminUser,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "unknown peer",
+			peerID:         "peer3",
+			callerUserID:   adminUser,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/peers/%s/firewall-rules", tc.peerID), nil)
+			req = nbcontext.SetUserAuthInRequest(req, nbcontext.UserAuth{
+				UserId:    tc.callerUserID,
+				Domain:    "hotmail.com",
+				AccountId: "test_id",
+			})
+
+			router := mux.NewRouter()
+			router.HandleFunc("/api/peers/{peerId}/firewall-rules", p.GetPeerFirewallRules).Methods("GET")
+			router.ServeHTTP(recorder, req)
+
+			res := recorder.Result()
+			defer res.Body.Close()
+			if res.StatusCode != tc.expectedStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", res.StatusCode, tc.expectedStatus)
+			}
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var rules api.PeerFirewallRules
+			if err := json.NewDecoder(res.Body).Decode(&rules); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+
+			assert.Equal(t, uint64(51), rules.Serial)
+			assert.Empty(t, rules.RouteRules)
+			assert.Len(t, rules.Rules, 2, "bidirectional rule should apply to both directions")
+			for _, rule := range rules.Rules {
+				assert.Equal(t, "policy", rule.PolicyId)
+				assert.Equal(t, "policy", rule.PolicyName)
+				assert.Equal(t, "rule", rule.PolicyRuleId)
+				assert.Equal(t, "rule", rule.PolicyRuleName)
+				assert.Equal(t, "peer2", *rule.PeerId)
+				assert.Equal(t, "80", *rule.Port)
+				assert.Equal(t, "accept", rule.Action)
+			}
+		})
+	}
+}
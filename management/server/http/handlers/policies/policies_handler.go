@@ -3,6 +3,7 @@ package policies
 import (
 	"encoding/json"
 	"net/http"
+	"net/netip"
 	"strconv"
 
 	"github.com/gorilla/mux"
@@ -12,29 +13,34 @@ import (
 	"github.com/netbirdio/netbird/management/server/geolocation"
 	"github.com/netbirdio/netbird/management/server/http/api"
 	"github.com/netbirdio/netbird/management/server/http/util"
+	"github.com/netbirdio/netbird/management/server/simulation"
 	"github.com/netbirdio/netbird/management/server/status"
 	"github.com/netbirdio/netbird/management/server/types"
 )
 
 // handler is a handler that returns policy of the account
 type handler struct {
-	accountManager account.Manager
+	accountManager    account.Manager
+	simulationManager simulation.Manager
 }
 
-func AddEndpoints(accountManager account.Manager, locationManager geolocation.Geolocation, router *mux.Router) {
-	policiesHandler := newHandler(accountManager)
+func AddEndpoints(accountManager account.Manager, locationManager geolocation.Geolocation, simulationManager simulation.Manager, router *mux.Router) {
+	policiesHandler := newHandler(accountManager, simulationManager)
 	router.HandleFunc("/policies", policiesHandler.getAllPolicies).Methods("GET", "OPTIONS")
 	router.HandleFunc("/policies", policiesHandler.createPolicy).Methods("POST", "OPTIONS")
+	router.HandleFunc("/policies/simulate", policiesHandler.simulatePolicies).Methods("POST", "OPTIONS")
 	router.HandleFunc("/policies/{policyId}", policiesHandler.updatePolicy).Methods("PUT", "OPTIONS")
 	router.HandleFunc("/policies/{policyId}", policiesHandler.getPolicy).Methods("GET", "OPTIONS")
 	router.HandleFunc("/policies/{policyId}", policiesHandler.deletePolicy).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/policies/{policyId}/rollouts", policiesHandler.createPolicyRollout).Methods("POST", "OPTIONS")
 	addPostureCheckEndpoint(accountManager, locationManager, router)
 }
 
 // newHandler creates a new policies handler
-func newHandler(accountManager account.Manager) *handler {
+func newHandler(accountManager account.Manager, simulationManager simulation.Manager) *handler {
 	return &handler{
-		accountManager: accountManager,
+		accountManager:    accountManager,
+		simulationManager: simulationManager,
 	}
 }
 
@@ -112,24 +118,67 @@ func (h *handler) createPolicy(w http.ResponseWriter, r *http.Request) {
 	h.savePolicy(w, r, accountID, userID, "")
 }
 
-// savePolicy handles policy creation and update
+// savePolicy handles policy creation and update.
+// With the dry_run query parameter it only returns the peers whose connections would change.
 func (h *handler) savePolicy(w http.ResponseWriter, r *http.Request, accountID string, userID string, policyID string) {
+	var dryRun bool
+	if value := r.URL.Query().Get("dry_run"); value != "" {
+		var err error
+		dryRun, err = strconv.ParseBool(value)
+		if err != nil {
+			util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid dry_run value %s", value), w)
+			return
+		}
+	}
+
 	var req api.PutApiPoliciesPolicyIdJSONRequestBody
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		util.WriteErrorResponse("couldn't parse JSON request", http.StatusBadRequest, w)
 		return
 	}
 
-	if req.Name == "" {
-		util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "policy name shouldn't be empty"), w)
+	policy, err := policyFromRequest(accountID, policyID, req)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
 		return
 	}
 
-	if len(req.Rules) == 0 {
-		util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "policy rules shouldn't be empty"), w)
+	if dryRun {
+		h.dryRunPolicy(w, r, accountID, userID, policy)
 		return
 	}
 
+	policy, err = h.accountManager.SavePolicy(r.Context(), accountID, userID, policy)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	allGroups, err := h.accountManager.GetAllGroups(r.Context(), accountID, userID)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	resp := toPolicyResponse(allGroups, policy)
+	if len(resp.Rules) == 0 {
+		util.WriteError(r.Context(), status.Errorf(status.Internal, "no rules in the policy"), w)
+		return
+	}
+
+	util.WriteJSONObject(r.Context(), w, resp)
+}
+
+// policyFromRequest validates the request and returns the policy it describes, the policy ID is empty for new policies
+func policyFromRequest(accountID, policyID string, req api.PolicyCreate) (*types.Policy, error) {
+	if req.Name == "" {
+		return nil, status.Errorf(status.InvalidArgument, "policy name shouldn't be empty")
+	}
+
+	if len(req.Rules) == 0 {
+		return nil, status.Errorf(status.InvalidArgument, "policy rules shouldn't be empty")
+	}
+
 	description := ""
 	if req.Description != nil {
 		description = *req.Description
@@ -142,6 +191,10 @@ func (h *handler) savePolicy(w http.ResponseWriter, r *http.Request, accountID s
 		Enabled:     req.Enabled,
 		Description: description,
 	}
+	if req.Remediation != nil {
+		policy.Remediation = *req.Remediation
+	}
+
 	for _, rule := range req.Rules {
 		var ruleID string
 		if rule.Id != nil && policyID != "" {
@@ -150,23 +203,30 @@ func (h *handler) savePolicy(w http.ResponseWriter, r *http.Request, accountID s
 
 		hasSources := rule.Sources != nil
 		hasSourceResource := rule.SourceResource != nil
+		hasSourceLabels := rule.SourceLabels != nil && len(*rule.SourceLabels) > 0
 
 		hasDestinations := rule.Destinations != nil
 		hasDestinationResource := rule.DestinationResource != nil
+		hasDestinationLabels := rule.DestinationLabels != nil && len(*rule.DestinationLabels) > 0
 
 		if hasSources && hasSourceResource {
-			util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "specify either sources or  source resources, not both"), w)
-			return
+			return nil, status.Errorf(status.InvalidArgument, "specify either sources or  source resources, not both")
 		}
 
 		if hasDestinations && hasDestinationResource {
-			util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "specify either destinations or  destination resources, not both"), w)
-			return
+			return nil, status.Errorf(status.InvalidArgument, "specify either destinations or  destination resources, not both")
 		}
 
-		if !(hasSources || hasSourceResource) || !(hasDestinations || hasDestinationResource) {
-			util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "specify either sources or source resources and destinations or destination resources"), w)
-			return
+		if hasSourceResource && hasSourceLabels {
+			return nil, status.Errorf(status.InvalidArgument, "specify either source labels or source resources, not both")
+		}
+
+		if hasDestinationResource && hasDestinationLabels {
+			return nil, status.Errorf(status.InvalidArgument, "specify either destination labels or destination resources, not both")
+		}
+
+		if !(hasSources || hasSourceResource || hasSourceLabels) || !(hasDestinations || hasDestinationResource || hasDestinationLabels) {
+			return nil, status.Errorf(status.InvalidArgument, "specify either sources, source labels or source resources and destinations, destination labels or destination resources")
 		}
 
 		pr := types.PolicyRule{
@@ -187,10 +247,18 @@ func (h *handler) savePolicy(w http.ResponseWriter, r *http.Request, accountID s
 			pr.SourceResource = *sourceResource
 		}
 
+		if hasSourceLabels {
+			pr.SourceLabels = *rule.SourceLabels
+		}
+
 		if hasDestinations {
 			pr.Destinations = *rule.Destinations
 		}
 
+		if hasDestinationLabels {
+			pr.DestinationLabels = *rule.DestinationLabels
+		}
+
 		if hasDestinationResource {
 			// TODO: validate the resource id and type
 			destinationResource := &types.Resource{}
@@ -209,8 +277,7 @@ func (h *handler) savePolicy(w http.ResponseWriter, r *http.Request, accountID s
 		case api.PolicyRuleUpdateActionDrop:
 			pr.Action = types.PolicyTrafficActionDrop
 		default:
-			util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "unknown action type"), w)
-			return
+			return nil, status.Errorf(status.InvalidArgument, "unknown action type")
 		}
 
 		switch rule.Protocol {
@@ -223,20 +290,17 @@ func (h *handler) savePolicy(w http.ResponseWriter, r *http.Request, accountID s
 		case api.PolicyRuleUpdateProtocolIcmp:
 			pr.Protocol = types.PolicyRuleProtocolICMP
 		default:
-			util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "unknown protocol type: %v", rule.Protocol), w)
-			return
+			return nil, status.Errorf(status.InvalidArgument, "unknown protocol type: %v", rule.Protocol)
 		}
 
 		if (rule.Ports != nil && len(*rule.Ports) != 0) && (rule.PortRanges != nil && len(*rule.PortRanges) != 0) {
-			util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "specify either individual ports or port ranges, not both"), w)
-			return
+			return nil, status.Errorf(status.InvalidArgument, "specify either individual ports or port ranges, not both")
 		}
 
 		if rule.Ports != nil && len(*rule.Ports) != 0 {
 			for _, v := range *rule.Ports {
 				if port, err := strconv.Atoi(v); err != nil || port < 1 || port > 65535 {
-					util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "valid port value is in 1..65535 range"), w)
-					return
+					return nil, status.Errorf(status.InvalidArgument, "valid port value is in 1..65535 range")
 				}
 				pr.Ports = append(pr.Ports, v)
 			}
@@ -245,8 +309,7 @@ func (h *handler) savePolicy(w http.ResponseWriter, r *http.Request, accountID s
 		if rule.PortRanges != nil && len(*rule.PortRanges) != 0 {
 			for _, portRange := range *rule.PortRanges {
 				if portRange.Start < 1 || portRange.End > 65535 {
-					util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "valid port value is in 1..65535 range"), w)
-					return
+					return nil, status.Errorf(status.InvalidArgument, "valid port value is in 1..65535 range")
 				}
 				pr.PortRanges = append(pr.PortRanges, types.RulePortRange{
 					Start: uint16(portRange.Start),
@@ -259,17 +322,14 @@ func (h *handler) savePolicy(w http.ResponseWriter, r *http.Request, accountID s
 		switch pr.Protocol {
 		case types.PolicyRuleProtocolALL, types.PolicyRuleProtocolICMP:
 			if len(pr.Ports) != 0 || len(pr.PortRanges) != 0 {
-				util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "for ALL or ICMP protocol ports is not allowed"), w)
-				return
+				return nil, status.Errorf(status.InvalidArgument, "for ALL or ICMP protocol ports is not allowed")
 			}
 			if !pr.Bidirectional {
-				util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "for ALL or ICMP protocol type flow can be only bi-directional"), w)
-				return
+				return nil, status.Errorf(status.InvalidArgument, "for ALL or ICMP protocol type flow can be only bi-directional")
 			}
 		case types.PolicyRuleProtocolTCP, types.PolicyRuleProtocolUDP:
 			if !pr.Bidirectional && (len(pr.Ports) == 0 || len(pr.PortRanges) != 0) {
-				util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "for ALL or ICMP protocol type flow can be only bi-directional"), w)
-				return
+				return nil, status.Errorf(status.InvalidArgument, "for ALL or ICMP protocol type flow can be only bi-directional")
 			}
 		}
 
@@ -280,27 +340,143 @@ func (h *handler) savePolicy(w http.ResponseWriter, r *http.Request, accountID s
 		policy.SourcePostureChecks = *req.SourcePostureChecks
 	}
 
-	policy, err := h.accountManager.SavePolicy(r.Context(), accountID, userID, policy)
+	return policy, nil
+}
+
+// createPolicyRollout stages the policy change on the canary groups
+func (h *handler) createPolicyRollout(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
 	if err != nil {
 		util.WriteError(r.Context(), err, w)
 		return
 	}
 
-	allGroups, err := h.accountManager.GetAllGroups(r.Context(), accountID, userID)
+	accountID, userID := userAuth.AccountId, userAuth.UserId
+	policyID := mux.Vars(r)["policyId"]
+	if len(policyID) == 0 {
+		util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid policy ID"), w)
+		return
+	}
+
+	var req api.PostApiPoliciesPolicyIdRolloutsJSONRequestBody
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.WriteErrorResponse("couldn't parse JSON request", http.StatusBadRequest, w)
+		return
+	}
+
+	policy, err := policyFromRequest(accountID, policyID, req.Policy)
 	if err != nil {
 		util.WriteError(r.Context(), err, w)
 		return
 	}
 
-	resp := toPolicyResponse(allGroups, policy)
-	if len(resp.Rules) == 0 {
-		util.WriteError(r.Context(), status.Errorf(status.Internal, "no rules in the policy"), w)
+	settings := types.RolloutSettingsFromAPIRequest(req.CanaryGroups, req.ObservationPeriod, req.FailureThreshold, req.AutoPromote)
+
+	rollout, err := h.accountManager.StagePolicyRollout(r.Context(), accountID, userID, policy, settings)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	util.WriteJSONObject(r.Context(), w, rollout.ToAPIResponse())
+}
+
+// dryRunPolicy returns the peers whose connections would change if the policy was saved
+func (h *handler) dryRunPolicy(w http.ResponseWriter, r *http.Request, accountID, userID string, policy *types.Policy) {
+	diffs, err := h.simulationManager.DryRunPolicy(r.Context(), accountID, userID, policy)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	resp := &api.PolicyDryRun{
+		Peers: make([]api.PolicyDryRunPeer, 0, len(diffs)),
+	}
+	for _, diff := range diffs {
+		resp.Peers = append(resp.Peers, api.PolicyDryRunPeer{
+			PeerId:       diff.PeerID,
+			PeerName:     diff.PeerName,
+			AddedPeers:   emptyIfNil(diff.AddedPeers),
+			RemovedPeers: emptyIfNil(diff.RemovedPeers),
+			ChangedPeers: emptyIfNil(diff.ChangedPeers),
+		})
+	}
+
+	util.WriteJSONObject(r.Context(), w, resp)
+}
+
+// simulatePolicies evaluates the policies for the traffic of the request without changing them
+func (h *handler) simulatePolicies(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	accountID, userID := userAuth.AccountId, userAuth.UserId
+
+	var req api.PostApiPoliciesSimulateJSONRequestBody
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.WriteErrorResponse("couldn't parse JSON request", http.StatusBadRequest, w)
+		return
+	}
+
+	query := &simulation.Query{
+		SourcePeerID: req.SourcePeerId,
+		Protocol:     string(req.Protocol),
+	}
+	if req.DestinationPeerId != nil {
+		query.DestinationPeerID = *req.DestinationPeerId
+	}
+	if req.DestinationAddress != nil {
+		query.DestinationAddress, err = netip.ParseAddr(*req.DestinationAddress)
+		if err != nil {
+			util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid destination address %s", *req.DestinationAddress), w)
+			return
+		}
+	}
+	if req.Port != nil {
+		if *req.Port < 1 || *req.Port > 65535 {
+			util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "valid port value is in 1..65535 range"), w)
+			return
+		}
+		query.Port = uint16(*req.Port)
+	}
+
+	result, err := h.simulationManager.Simulate(r.Context(), accountID, userID, query)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
 		return
 	}
 
+	resp := &api.PolicySimulationResult{
+		Allowed: result.Allowed,
+		Reason:  result.Reason,
+		Rules:   make([]api.PolicySimulationRule, 0, len(result.Rules)),
+	}
+	for _, rule := range result.Rules {
+		apiRule := api.PolicySimulationRule{
+			PolicyId: rule.PolicyID,
+			Name:     rule.Name,
+			Action:   rule.Action,
+		}
+		if rule.RoutingPeerID != "" {
+			routingPeerID := rule.RoutingPeerID
+			apiRule.RoutingPeerId = &routingPeerID
+		}
+		resp.Rules = append(resp.Rules, apiRule)
+	}
+
 	util.WriteJSONObject(r.Context(), w, resp)
 }
 
+func emptyIfNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
 // deletePolicy handles policy deletion request
 func (h *handler) deletePolicy(w http.ResponseWriter, r *http.Request) {
 	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
@@ -375,6 +551,7 @@ func toPolicyResponse(groups []*types.Group, policy *types.Policy) *api.Policy {
 		Name:                policy.Name,
 		Description:         &policy.Description,
 		Enabled:             policy.Enabled,
+		Remediation:         &policy.Remediation,
 		SourcePostureChecks: policy.SourcePostureChecks,
 	}
 	for _, r := range policy.Rules {
@@ -397,6 +574,16 @@ func toPolicyResponse(groups []*types.Group, policy *types.Policy) *api.Policy {
 			rule.Ports = &portsCopy
 		}
 
+		if len(r.SourceLabels) != 0 {
+			sourceLabels := api.Labels(r.SourceLabels)
+			rule.SourceLabels = &sourceLabels
+		}
+
+		if len(r.DestinationLabels) != 0 {
+			destinationLabels := api.Labels(r.DestinationLabels)
+			rule.DestinationLabels = &destinationLabels
+		}
+
 		if len(r.PortRanges) != 0 {
 			portRanges := make([]api.RulePortRange, 0, len(r.PortRanges))
 			for _, portRange := range r.PortRanges {
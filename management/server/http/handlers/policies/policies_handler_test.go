@@ -145,6 +145,7 @@ func TestPoliciesGetPolicy(t *testing.T) {
 func TestPoliciesWritePolicy(t *testing.T) {
 	str := func(s string) *string { return &s }
 	emptyString := ""
+	noRemediation := false
 	tt := []struct {
 		name           string
 		expectedStatus int
@@ -178,6 +179,7 @@ func TestPoliciesWritePolicy(t *testing.T) {
 				Id:          str("id-was-set"),
 				Name:        "Default POSTed Policy",
 				Description: &emptyString,
+				Remediation: &noRemediation,
 				Rules: []api.PolicyRule{
 					{
 						Id:            str("id-was-set"),
@@ -227,6 +229,7 @@ func TestPoliciesWritePolicy(t *testing.T) {
 				Id:          str("id-existed"),
 				Name:        "Default POSTed Policy",
 				Description: &emptyString,
+				Remediation: &noRemediation,
 				Rules: []api.PolicyRule{
 					{
 						Id:            str("id-existed"),
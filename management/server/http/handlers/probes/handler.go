@@ -0,0 +1,108 @@
+package probes
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	nbcontext "github.com/netbirdio/netbird/management/server/context"
+	"github.com/netbirdio/netbird/management/server/http/api"
+	"github.com/netbirdio/netbird/management/server/http/util"
+	"github.com/netbirdio/netbird/management/server/probes"
+	"github.com/netbirdio/netbird/management/server/status"
+)
+
+// handler is a handler that probes the reachability of peers and routed addresses from the peers
+type handler struct {
+	probesManager probes.Manager
+}
+
+func AddEndpoints(probesManager probes.Manager, router *mux.Router) {
+	probesHandler := newHandler(probesManager)
+	router.HandleFunc("/peers/{peerId}/probe", probesHandler.probe).Methods("POST", "OPTIONS")
+}
+
+func newHandler(probesManager probes.Manager) *handler {
+	return &handler{
+		probesManager: probesManager,
+	}
+}
+
+func (h *handler) probe(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	accountID, userID := userAuth.AccountId, userAuth.UserId
+
+	peerID := mux.Vars(r)["peerId"]
+	if len(peerID) == 0 {
+		util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid peer ID"), w)
+		return
+	}
+
+	var req api.PostApiPeersPeerIdProbeJSONRequestBody
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		util.WriteErrorResponse("couldn't parse JSON request", http.StatusBadRequest, w)
+		return
+	}
+
+	target, err := toProbeTarget(&req)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	result, err := h.probesManager.ProbePeer(r.Context(), accountID, userID, peerID, target)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	util.WriteJSONObject(r.Context(), w, toProbeResultResponse(result))
+}
+
+func toProbeTarget(req *api.PeerProbeRequest) (*probes.Target, error) {
+	target := &probes.Target{}
+
+	if req.TargetPeerId != nil {
+		target.PeerID = *req.TargetPeerId
+	}
+	if req.Address != nil {
+		target.Address = *req.Address
+	}
+	if req.Url != nil {
+		target.URL = *req.Url
+	}
+	if req.Port != nil {
+		if *req.Port < 1 || *req.Port > 65535 {
+			return nil, status.Errorf(status.InvalidArgument, "invalid port %d", *req.Port)
+		}
+		target.Port = uint16(*req.Port)
+	}
+	if req.TimeoutSeconds != nil {
+		target.Timeout = time.Duration(*req.TimeoutSeconds) * time.Second
+	}
+
+	return target, nil
+}
+
+func toProbeResultResponse(result *probes.Result) *api.PeerProbeResult {
+	response := &api.PeerProbeResult{
+		Target:    result.Target,
+		Reachable: result.Reachable,
+		LatencyMs: float64(result.Latency.Microseconds()) / 1000,
+	}
+	if result.HTTPStatus != 0 {
+		response.HttpStatus = &result.HTTPStatus
+	}
+	if result.Error != "" {
+		response.Error = &result.Error
+	}
+	return response
+}
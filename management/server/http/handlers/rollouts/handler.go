@@ -0,0 +1,90 @@
+package rollouts
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/netbirdio/netbird/management/server/account"
+	nbcontext "github.com/netbirdio/netbird/management/server/context"
+	"github.com/netbirdio/netbird/management/server/http/api"
+	"github.com/netbirdio/netbird/management/server/http/util"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/types"
+)
+
+// handler is the handler of the route and policy rollouts of the account
+type handler struct {
+	accountManager account.Manager
+}
+
+func AddEndpoints(accountManager account.Manager, router *mux.Router) {
+	rolloutsHandler := newHandler(accountManager)
+	router.HandleFunc("/rollouts", rolloutsHandler.getAllRollouts).Methods("GET", "OPTIONS")
+	router.HandleFunc("/rollouts/{rolloutId}", rolloutsHandler.getRollout).Methods("GET", "OPTIONS")
+	router.HandleFunc("/rollouts/{rolloutId}/promote", rolloutsHandler.promoteRollout).Methods("POST", "OPTIONS")
+	router.HandleFunc("/rollouts/{rolloutId}/rollback", rolloutsHandler.rollbackRollout).Methods("POST", "OPTIONS")
+}
+
+func newHandler(accountManager account.Manager) *handler {
+	return &handler{
+		accountManager: accountManager,
+	}
+}
+
+func (h *handler) getAllRollouts(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	rollouts, err := h.accountManager.ListRollouts(r.Context(), userAuth.AccountId, userAuth.UserId)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	rolloutsResponse := make([]*api.Rollout, 0, len(rollouts))
+	for _, rollout := range rollouts {
+		rolloutsResponse = append(rolloutsResponse, rollout.ToAPIResponse())
+	}
+
+	util.WriteJSONObject(r.Context(), w, rolloutsResponse)
+}
+
+func (h *handler) getRollout(w http.ResponseWriter, r *http.Request) {
+	h.handleRollout(w, r, h.accountManager.GetRollout)
+}
+
+func (h *handler) promoteRollout(w http.ResponseWriter, r *http.Request) {
+	h.handleRollout(w, r, h.accountManager.PromoteRollout)
+}
+
+func (h *handler) rollbackRollout(w http.ResponseWriter, r *http.Request) {
+	h.handleRollout(w, r, h.accountManager.RollbackRollout)
+}
+
+// handleRollout applies the operation to the rollout of the request path and writes the resulting rollout
+func (h *handler) handleRollout(w http.ResponseWriter, r *http.Request, operation func(ctx context.Context, accountID, userID, rolloutID string) (*types.Rollout, error)) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	rolloutID := mux.Vars(r)["rolloutId"]
+	if len(rolloutID) == 0 {
+		util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid rollout ID"), w)
+		return
+	}
+
+	rollout, err := operation(r.Context(), userAuth.AccountId, userAuth.UserId, rolloutID)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	util.WriteJSONObject(r.Context(), w, rollout.ToAPIResponse())
+}
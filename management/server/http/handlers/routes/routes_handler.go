@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/netip"
+	"strconv"
 	"unicode/utf8"
 
 	"github.com/gorilla/mux"
@@ -13,7 +14,10 @@ import (
 	nbcontext "github.com/netbirdio/netbird/management/server/context"
 	"github.com/netbirdio/netbird/management/server/http/api"
 	"github.com/netbirdio/netbird/management/server/http/util"
+	"github.com/netbirdio/netbird/management/server/labels"
+	"github.com/netbirdio/netbird/management/server/simulation"
 	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/types"
 	"github.com/netbirdio/netbird/route"
 )
 
@@ -21,22 +25,25 @@ const failedToConvertRoute = "failed to convert route to response: %v"
 
 // handler is the routes handler of the account
 type handler struct {
-	accountManager account.Manager
+	accountManager    account.Manager
+	simulationManager simulation.Manager
 }
 
-func AddEndpoints(accountManager account.Manager, router *mux.Router) {
-	routesHandler := newHandler(accountManager)
+func AddEndpoints(accountManager account.Manager, simulationManager simulation.Manager, router *mux.Router) {
+	routesHandler := newHandler(accountManager, simulationManager)
 	router.HandleFunc("/routes", routesHandler.getAllRoutes).Methods("GET", "OPTIONS")
 	router.HandleFunc("/routes", routesHandler.createRoute).Methods("POST", "OPTIONS")
 	router.HandleFunc("/routes/{routeId}", routesHandler.updateRoute).Methods("PUT", "OPTIONS")
 	router.HandleFunc("/routes/{routeId}", routesHandler.getRoute).Methods("GET", "OPTIONS")
 	router.HandleFunc("/routes/{routeId}", routesHandler.deleteRoute).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/routes/{routeId}/rollouts", routesHandler.createRouteRollout).Methods("POST", "OPTIONS")
 }
 
 // newHandler returns a new instance of routes handler
-func newHandler(accountManager account.Manager) *handler {
+func newHandler(accountManager account.Manager, simulationManager simulation.Manager) *handler {
 	return &handler{
-		accountManager: accountManager,
+		accountManager:    accountManager,
+		simulationManager: simulationManager,
 	}
 }
 
@@ -50,6 +57,12 @@ func (h *handler) getAllRoutes(w http.ResponseWriter, r *http.Request) {
 
 	accountID, userID := userAuth.AccountId, userAuth.UserId
 
+	labelSelector, err := labels.Parse(r.URL.Query()["label"])
+	if err != nil {
+		util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "%s", err), w)
+		return
+	}
+
 	routes, err := h.accountManager.ListRoutes(r.Context(), accountID, userID)
 	if err != nil {
 		util.WriteError(r.Context(), err, w)
@@ -57,6 +70,9 @@ func (h *handler) getAllRoutes(w http.ResponseWriter, r *http.Request) {
 	}
 	apiRoutes := make([]*api.Route, 0)
 	for _, route := range routes {
+		if labelSelector != nil && !labels.Matches(labelSelector, route.Labels) {
+			continue
+		}
 		route, err := toRouteResponse(route)
 		if err != nil {
 			util.WriteError(r.Context(), status.Errorf(status.Internal, failedToConvertRoute, err), w)
@@ -124,6 +140,25 @@ func (h *handler) createRoute(w http.ResponseWriter, r *http.Request) {
 		accessControlGroupIds = *req.AccessControlGroups
 	}
 
+	// the labels are validated before the route is created as they are saved with an update of the new route
+	if req.Labels != nil {
+		if err := labels.Validate(*req.Labels); err != nil {
+			util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "%s", err), w)
+			return
+		}
+	}
+
+	// the DNS configuration is validated before the route is created as it's saved with an update of the new route
+	dnsRoute := &route.Route{Network: newPrefix, Domains: domains}
+	if err := routeDNSFromRequest(req, dnsRoute); err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+	if err := dnsRoute.ValidateDNS(); err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
 	newRoute, err := h.accountManager.CreateRoute(r.Context(), accountID, newPrefix, networkType, domains, peerId, peerGroupIds,
 		req.Description, route.NetID(req.NetworkId), req.Masquerade, req.Metric, req.Groups, accessControlGroupIds, req.Enabled, userID, req.KeepRoute)
 
@@ -132,6 +167,20 @@ func (h *handler) createRoute(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	hasLabels := req.Labels != nil && len(*req.Labels) > 0
+	if hasLabels || dnsRoute.HasDNS() {
+		if hasLabels {
+			newRoute.Labels = *req.Labels
+		}
+		newRoute.DNSDomains = dnsRoute.DNSDomains
+		newRoute.DNSServers = dnsRoute.DNSServers
+		newRoute.DNSSearchDomainsEnabled = dnsRoute.DNSSearchDomainsEnabled
+		if err = h.accountManager.SaveRoute(r.Context(), accountID, userID, newRoute); err != nil {
+			util.WriteError(r.Context(), err, w)
+			return
+		}
+	}
+
 	routes, err := toRouteResponse(newRoute)
 	if err != nil {
 		util.WriteError(r.Context(), status.Errorf(status.Internal, failedToConvertRoute, err), w)
@@ -166,7 +215,8 @@ func (h *handler) validateRoute(req api.PostApiRoutesJSONRequestBody) error {
 	return nil
 }
 
-// updateRoute handles update to a route identified by a given ID
+// updateRoute handles update to a route identified by a given ID.
+// With the dry_run query parameter it only returns the peers whose routes would change.
 func (h *handler) updateRoute(w http.ResponseWriter, r *http.Request) {
 	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
 	if err != nil {
@@ -182,6 +232,15 @@ func (h *handler) updateRoute(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var dryRun bool
+	if value := r.URL.Query().Get("dry_run"); value != "" {
+		dryRun, err = strconv.ParseBool(value)
+		if err != nil {
+			util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid dry_run value %s", value), w)
+			return
+		}
+	}
+
 	_, err = h.accountManager.GetRoute(r.Context(), accountID, route.ID(routeID), userID)
 	if err != nil {
 		util.WriteError(r.Context(), err, w)
@@ -195,18 +254,40 @@ func (h *handler) updateRoute(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.validateRoute(req); err != nil {
+	newRoute, err := h.routeFromRequest(route.ID(routeID), req)
+	if err != nil {
 		util.WriteError(r.Context(), err, w)
 		return
 	}
 
-	peerID := ""
-	if req.Peer != nil {
-		peerID = *req.Peer
+	if dryRun {
+		h.dryRunRoute(w, r, accountID, userID, newRoute)
+		return
+	}
+
+	err = h.accountManager.SaveRoute(r.Context(), accountID, userID, newRoute)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	routes, err := toRouteResponse(newRoute)
+	if err != nil {
+		util.WriteError(r.Context(), status.Errorf(status.Internal, failedToConvertRoute, err), w)
+		return
+	}
+
+	util.WriteJSONObject(r.Context(), w, routes)
+}
+
+// routeFromRequest validates the request and returns the route with the ID it describes
+func (h *handler) routeFromRequest(routeID route.ID, req api.RouteRequest) (*route.Route, error) {
+	if err := h.validateRoute(req); err != nil {
+		return nil, err
 	}
 
 	newRoute := &route.Route{
-		ID:          route.ID(routeID),
+		ID:          routeID,
 		NetID:       route.NetID(req.NetworkId),
 		Masquerade:  req.Masquerade,
 		Metric:      req.Metric,
@@ -219,21 +300,20 @@ func (h *handler) updateRoute(w http.ResponseWriter, r *http.Request) {
 	if req.Domains != nil {
 		d, err := domain.ValidateDomains(*req.Domains)
 		if err != nil {
-			util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid domains: %v", err), w)
-			return
+			return nil, status.Errorf(status.InvalidArgument, "invalid domains: %v", err)
 		}
 		newRoute.Domains = d
 		newRoute.NetworkType = route.DomainNetwork
 	} else if req.Network != nil {
+		var err error
 		newRoute.NetworkType, newRoute.Network, err = route.ParseNetwork(*req.Network)
 		if err != nil {
-			util.WriteError(r.Context(), err, w)
-			return
+			return nil, err
 		}
 	}
 
 	if req.Peer != nil {
-		newRoute.Peer = peerID
+		newRoute.Peer = *req.Peer
 	}
 
 	if req.PeerGroups != nil {
@@ -244,19 +324,111 @@ func (h *handler) updateRoute(w http.ResponseWriter, r *http.Request) {
 		newRoute.AccessControlGroups = *req.AccessControlGroups
 	}
 
-	err = h.accountManager.SaveRoute(r.Context(), accountID, userID, newRoute)
+	if req.Labels != nil {
+		newRoute.Labels = *req.Labels
+	}
+
+	if err := routeDNSFromRequest(req, newRoute); err != nil {
+		return nil, err
+	}
+
+	return newRoute, nil
+}
+
+// routeDNSFromRequest sets the DNS configuration of the routed network from the request
+func routeDNSFromRequest(req api.RouteRequest, newRoute *route.Route) error {
+	if req.DnsDomains != nil {
+		dnsDomains, err := domain.ValidateDomainsStrSlice(*req.DnsDomains)
+		if err != nil {
+			return status.Errorf(status.InvalidArgument, "invalid DNS domains: %v", err)
+		}
+		newRoute.DNSDomains = dnsDomains
+	}
+
+	if req.DnsServers != nil {
+		for _, server := range *req.DnsServers {
+			addr, err := netip.ParseAddr(server)
+			if err != nil {
+				return status.Errorf(status.InvalidArgument, "invalid DNS server %s", server)
+			}
+			newRoute.DNSServers = append(newRoute.DNSServers, addr.Unmap())
+		}
+	}
+
+	if req.DnsSearchDomainsEnabled != nil {
+		newRoute.DNSSearchDomainsEnabled = *req.DnsSearchDomainsEnabled
+	}
+
+	return nil
+}
+
+// dryRunRoute returns the peers whose routes would change if the route was saved
+func (h *handler) dryRunRoute(w http.ResponseWriter, r *http.Request, accountID, userID string, newRoute *route.Route) {
+	diffs, err := h.simulationManager.DryRunRoute(r.Context(), accountID, userID, newRoute)
 	if err != nil {
 		util.WriteError(r.Context(), err, w)
 		return
 	}
 
-	routes, err := toRouteResponse(newRoute)
+	resp := &api.RouteDryRun{
+		Peers: make([]api.RouteDryRunPeer, 0, len(diffs)),
+	}
+	for _, diff := range diffs {
+		resp.Peers = append(resp.Peers, api.RouteDryRunPeer{
+			PeerId:              diff.PeerID,
+			PeerName:            diff.PeerName,
+			AddedRoutingPeers:   emptyIfNil(diff.AddedRoutingPeers),
+			RemovedRoutingPeers: emptyIfNil(diff.RemovedRoutingPeers),
+			ChangedRoutingPeers: emptyIfNil(diff.ChangedRoutingPeers),
+		})
+	}
+
+	util.WriteJSONObject(r.Context(), w, resp)
+}
+
+// createRouteRollout stages the route change on the canary groups
+func (h *handler) createRouteRollout(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
 	if err != nil {
-		util.WriteError(r.Context(), status.Errorf(status.Internal, failedToConvertRoute, err), w)
+		util.WriteError(r.Context(), err, w)
 		return
 	}
 
-	util.WriteJSONObject(r.Context(), w, routes)
+	accountID, userID := userAuth.AccountId, userAuth.UserId
+	routeID := mux.Vars(r)["routeId"]
+	if len(routeID) == 0 {
+		util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid route ID"), w)
+		return
+	}
+
+	var req api.PostApiRoutesRouteIdRolloutsJSONRequestBody
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.WriteErrorResponse("couldn't parse JSON request", http.StatusBadRequest, w)
+		return
+	}
+
+	newRoute, err := h.routeFromRequest(route.ID(routeID), req.Route)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	settings := types.RolloutSettingsFromAPIRequest(req.CanaryGroups, req.ObservationPeriod, req.FailureThreshold, req.AutoPromote)
+
+	rollout, err := h.accountManager.StageRouteRollout(r.Context(), accountID, userID, newRoute, settings)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	util.WriteJSONObject(r.Context(), w, rollout.ToAPIResponse())
+}
+
+func emptyIfNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
 }
 
 // deleteRoute handles route deletion request
@@ -341,5 +513,18 @@ func toRouteResponse(serverRoute *route.Route) (*api.Route, error) {
 	if len(serverRoute.AccessControlGroups) > 0 {
 		route.AccessControlGroups = &serverRoute.AccessControlGroups
 	}
+	if len(serverRoute.Labels) > 0 {
+		routeLabels := api.Labels(serverRoute.Labels)
+		route.Labels = &routeLabels
+	}
+	if serverRoute.HasDNS() {
+		dnsServers := make([]string, 0, len(serverRoute.DNSServers))
+		for _, server := range serverRoute.DNSServers {
+			dnsServers = append(dnsServers, server.String())
+		}
+		route.DnsDomains = &serverRoute.DNSDomains
+		route.DnsServers = &dnsServers
+		route.DnsSearchDomainsEnabled = &serverRoute.DNSSearchDomainsEnabled
+	}
 	return route, nil
 }
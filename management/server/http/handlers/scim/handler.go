@@ -0,0 +1,406 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+
+	nbcontext "github.com/netbirdio/netbird/management/server/context"
+	"github.com/netbirdio/netbird/management/server/scim"
+	"github.com/netbirdio/netbird/management/server/scim/types"
+	"github.com/netbirdio/netbird/management/server/status"
+)
+
+const (
+	scimPrefix      = "/scim/v2"
+	scimContentType = "application/scim+json"
+)
+
+// handler implements the SCIM 2.0 (RFC 7644) users and groups endpoints
+type handler struct {
+	scimManager scim.Manager
+}
+
+func AddEndpoints(scimManager scim.Manager, router *mux.Router) {
+	h := &handler{scimManager: scimManager}
+
+	router.HandleFunc(scimPrefix+"/ServiceProviderConfig", h.getServiceProviderConfig).Methods("GET", "OPTIONS")
+	router.HandleFunc(scimPrefix+"/Users", h.listUsers).Methods("GET", "OPTIONS")
+	router.HandleFunc(scimPrefix+"/Users", h.createUser).Methods("POST", "OPTIONS")
+	router.HandleFunc(scimPrefix+"/Users/{id}", h.getUser).Methods("GET", "OPTIONS")
+	router.HandleFunc(scimPrefix+"/Users/{id}", h.replaceUser).Methods("PUT", "OPTIONS")
+	router.HandleFunc(scimPrefix+"/Users/{id}", h.patchUser).Methods("PATCH", "OPTIONS")
+	router.HandleFunc(scimPrefix+"/Users/{id}", h.deleteUser).Methods("DELETE", "OPTIONS")
+	router.HandleFunc(scimPrefix+"/Groups", h.listGroups).Methods("GET", "OPTIONS")
+	router.HandleFunc(scimPrefix+"/Groups", h.createGroup).Methods("POST", "OPTIONS")
+	router.HandleFunc(scimPrefix+"/Groups/{id}", h.getGroup).Methods("GET", "OPTIONS")
+	router.HandleFunc(scimPrefix+"/Groups/{id}", h.replaceGroup).Methods("PUT", "OPTIONS")
+	router.HandleFunc(scimPrefix+"/Groups/{id}", h.patchGroup).Methods("PATCH", "OPTIONS")
+	router.HandleFunc(scimPrefix+"/Groups/{id}", h.deleteGroup).Methods("DELETE", "OPTIONS")
+}
+
+func (h *handler) getServiceProviderConfig(w http.ResponseWriter, r *http.Request) {
+	writeResponse(r.Context(), w, http.StatusOK, map[string]any{
+		"schemas":        []string{types.ServiceProviderConfigSchema},
+		"patch":          map[string]bool{"supported": true},
+		"bulk":           map[string]any{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]any{"supported": true, "maxResults": 0},
+		"changePassword": map[string]bool{"supported": false},
+		"sort":           map[string]bool{"supported": false},
+		"etag":           map[string]bool{"supported": false},
+		"authenticationSchemes": []map[string]any{{
+			"type":        "oauthbearertoken",
+			"name":        "Personal Access Token",
+			"description": "Personal access token of a NetBird service user with admin role",
+			"primary":     true,
+		}},
+	})
+}
+
+func (h *handler) listUsers(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		writeError(r.Context(), w, err)
+		return
+	}
+
+	users, err := h.scimManager.ListUsers(r.Context(), userAuth.AccountId, userAuth.UserId, r.URL.Query().Get("filter"))
+	if err != nil {
+		writeError(r.Context(), w, err)
+		return
+	}
+
+	resources := make([]any, 0, len(users))
+	for _, user := range users {
+		resources = append(resources, withUserLocation(r, user))
+	}
+
+	writeList(r, w, resources)
+}
+
+func (h *handler) getUser(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		writeError(r.Context(), w, err)
+		return
+	}
+
+	user, err := h.scimManager.GetUser(r.Context(), userAuth.AccountId, userAuth.UserId, mux.Vars(r)["id"])
+	if err != nil {
+		writeError(r.Context(), w, err)
+		return
+	}
+
+	writeResponse(r.Context(), w, http.StatusOK, withUserLocation(r, user))
+}
+
+func (h *handler) createUser(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		writeError(r.Context(), w, err)
+		return
+	}
+
+	var req types.User
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(r.Context(), w, status.Errorf(status.BadRequest, "couldn't parse JSON request"))
+		return
+	}
+
+	user, err := h.scimManager.CreateUser(r.Context(), userAuth.AccountId, userAuth.UserId, &req)
+	if err != nil {
+		writeError(r.Context(), w, err)
+		return
+	}
+
+	writeResponse(r.Context(), w, http.StatusCreated, withUserLocation(r, user))
+}
+
+func (h *handler) replaceUser(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		writeError(r.Context(), w, err)
+		return
+	}
+
+	var req types.User
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(r.Context(), w, status.Errorf(status.BadRequest, "couldn't parse JSON request"))
+		return
+	}
+
+	user, err := h.scimManager.ReplaceUser(r.Context(), userAuth.AccountId, userAuth.UserId, mux.Vars(r)["id"], &req)
+	if err != nil {
+		writeError(r.Context(), w, err)
+		return
+	}
+
+	writeResponse(r.Context(), w, http.StatusOK, withUserLocation(r, user))
+}
+
+func (h *handler) patchUser(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		writeError(r.Context(), w, err)
+		return
+	}
+
+	var req types.PatchRequest
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(r.Context(), w, status.Errorf(status.BadRequest, "couldn't parse JSON request"))
+		return
+	}
+
+	user, err := h.scimManager.PatchUser(r.Context(), userAuth.AccountId, userAuth.UserId, mux.Vars(r)["id"], req.Operations)
+	if err != nil {
+		writeError(r.Context(), w, err)
+		return
+	}
+
+	writeResponse(r.Context(), w, http.StatusOK, withUserLocation(r, user))
+}
+
+func (h *handler) deleteUser(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		writeError(r.Context(), w, err)
+		return
+	}
+
+	if err = h.scimManager.DeleteUser(r.Context(), userAuth.AccountId, userAuth.UserId, mux.Vars(r)["id"]); err != nil {
+		writeError(r.Context(), w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) listGroups(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		writeError(r.Context(), w, err)
+		return
+	}
+
+	groups, err := h.scimManager.ListGroups(r.Context(), userAuth.AccountId, userAuth.UserId, r.URL.Query().Get("filter"))
+	if err != nil {
+		writeError(r.Context(), w, err)
+		return
+	}
+
+	excludeMembers := r.URL.Query().Get("excludedAttributes") == "members"
+
+	resources := make([]any, 0, len(groups))
+	for _, group := range groups {
+		if excludeMembers {
+			group.Members = nil
+		}
+		resources = append(resources, withGroupLocation(r, group))
+	}
+
+	writeList(r, w, resources)
+}
+
+func (h *handler) getGroup(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		writeError(r.Context(), w, err)
+		return
+	}
+
+	group, err := h.scimManager.GetGroup(r.Context(), userAuth.AccountId, userAuth.UserId, mux.Vars(r)["id"])
+	if err != nil {
+		writeError(r.Context(), w, err)
+		return
+	}
+
+	if r.URL.Query().Get("excludedAttributes") == "members" {
+		group.Members = nil
+	}
+
+	writeResponse(r.Context(), w, http.StatusOK, withGroupLocation(r, group))
+}
+
+func (h *handler) createGroup(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		writeError(r.Context(), w, err)
+		return
+	}
+
+	var req types.Group
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(r.Context(), w, status.Errorf(status.BadRequest, "couldn't parse JSON request"))
+		return
+	}
+
+	group, err := h.scimManager.CreateGroup(r.Context(), userAuth.AccountId, userAuth.UserId, &req)
+	if err != nil {
+		writeError(r.Context(), w, err)
+		return
+	}
+
+	writeResponse(r.Context(), w, http.StatusCreated, withGroupLocation(r, group))
+}
+
+func (h *handler) replaceGroup(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		writeError(r.Context(), w, err)
+		return
+	}
+
+	var req types.Group
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(r.Context(), w, status.Errorf(status.BadRequest, "couldn't parse JSON request"))
+		return
+	}
+
+	group, err := h.scimManager.ReplaceGroup(r.Context(), userAuth.AccountId, userAuth.UserId, mux.Vars(r)["id"], &req)
+	if err != nil {
+		writeError(r.Context(), w, err)
+		return
+	}
+
+	writeResponse(r.Context(), w, http.StatusOK, withGroupLocation(r, group))
+}
+
+func (h *handler) patchGroup(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		writeError(r.Context(), w, err)
+		return
+	}
+
+	var req types.PatchRequest
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(r.Context(), w, status.Errorf(status.BadRequest, "couldn't parse JSON request"))
+		return
+	}
+
+	group, err := h.scimManager.PatchGroup(r.Context(), userAuth.AccountId, userAuth.UserId, mux.Vars(r)["id"], req.Operations)
+	if err != nil {
+		writeError(r.Context(), w, err)
+		return
+	}
+
+	writeResponse(r.Context(), w, http.StatusOK, withGroupLocation(r, group))
+}
+
+func (h *handler) deleteGroup(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		writeError(r.Context(), w, err)
+		return
+	}
+
+	if err = h.scimManager.DeleteGroup(r.Context(), userAuth.AccountId, userAuth.UserId, mux.Vars(r)["id"]); err != nil {
+		writeError(r.Context(), w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func withUserLocation(r *http.Request, user *types.User) *types.User {
+	if user.Meta != nil {
+		user.Meta.Location = resourceLocation(r, "Users", user.ID)
+	}
+	return user
+}
+
+func withGroupLocation(r *http.Request, group *types.Group) *types.Group {
+	if group.Meta != nil {
+		group.Meta.Location = resourceLocation(r, "Groups", group.ID)
+	}
+	return group
+}
+
+func resourceLocation(r *http.Request, resourceType, id string) string {
+	scheme := "https"
+	if r.TLS == nil {
+		scheme = "http"
+	}
+	return fmt.Sprintf("%s://%s/api%s/%s/%s", scheme, r.Host, scimPrefix, resourceType, id)
+}
+
+// writeList writes the resources as a list response honoring the startIndex and count pagination parameters
+func writeList(r *http.Request, w http.ResponseWriter, resources []any) {
+	startIndex, err := strconv.Atoi(r.URL.Query().Get("startIndex"))
+	if err != nil || startIndex < 1 {
+		startIndex = 1
+	}
+
+	count, err := strconv.Atoi(r.URL.Query().Get("count"))
+	if err != nil || count < 0 {
+		count = len(resources)
+	}
+
+	total := len(resources)
+	page := make([]any, 0)
+	if startIndex <= total {
+		end := min(startIndex-1+count, total)
+		page = resources[startIndex-1 : end]
+	}
+
+	writeResponse(r.Context(), w, http.StatusOK, &types.ListResponse{
+		Schemas:      []string{types.ListResponseSchema},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(page),
+		Resources:    page,
+	})
+}
+
+func writeResponse(ctx context.Context, w http.ResponseWriter, httpStatus int, obj any) {
+	w.Header().Set("Content-Type", scimContentType)
+	w.WriteHeader(httpStatus)
+	if err := json.NewEncoder(w).Encode(obj); err != nil {
+		log.WithContext(ctx).Errorf("failed to encode SCIM response: %v", err)
+	}
+}
+
+// writeError converts an error to a SCIM error response
+func writeError(ctx context.Context, w http.ResponseWriter, err error) {
+	log.WithContext(ctx).Errorf("got a SCIM handler error: %s", err.Error())
+
+	httpStatus := http.StatusInternalServerError
+	scimType := ""
+	detail := "internal server error"
+
+	if errStatus, ok := status.FromError(err); ok {
+		detail = errStatus.Message
+		switch errStatus.Type() {
+		case status.UserAlreadyExists, status.AlreadyExists:
+			httpStatus = http.StatusConflict
+			scimType = "uniqueness"
+		case status.PreconditionFailed:
+			httpStatus = http.StatusPreconditionFailed
+		case status.PermissionDenied:
+			httpStatus = http.StatusForbidden
+		case status.NotFound:
+			httpStatus = http.StatusNotFound
+		case status.InvalidArgument:
+			httpStatus = http.StatusBadRequest
+			scimType = "invalidValue"
+		case status.BadRequest:
+			httpStatus = http.StatusBadRequest
+			scimType = "invalidSyntax"
+		case status.Unauthorized, status.Unauthenticated:
+			httpStatus = http.StatusUnauthorized
+		default:
+		}
+	}
+
+	writeResponse(ctx, w, httpStatus, &types.Error{
+		Schemas:  []string{types.ErrorSchema},
+		Status:   strconv.Itoa(httpStatus),
+		ScimType: scimType,
+		Detail:   detail,
+	})
+}
@@ -85,8 +85,11 @@ func (h *handler) createSetupKey(w http.ResponseWriter, r *http.Request) {
 		allowExtraDNSLabels = *req.AllowExtraDnsLabels
 	}
 
+	var provisioning types.SetupKeyProvisioning
+	applyProvisioningRequest(&provisioning, req.AllowedIpRanges, req.AutoRoutes, req.AutoPostureChecks, req.PeerNamePrefix)
+
 	setupKey, err := h.accountManager.CreateSetupKey(r.Context(), accountID, req.Name, types.SetupKeyType(req.Type), expiresIn,
-		req.AutoGroups, req.UsageLimit, userID, ephemeral, allowExtraDNSLabels)
+		req.AutoGroups, req.UsageLimit, userID, ephemeral, allowExtraDNSLabels, provisioning)
 	if err != nil {
 		util.WriteError(r.Context(), err, w)
 		return
@@ -152,10 +155,19 @@ func (h *handler) updateSetupKey(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// provisioning settings omitted from the request are kept as they are
+	oldKey, err := h.accountManager.GetSetupKey(r.Context(), accountID, userID, keyID)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
 	newKey := &types.SetupKey{}
 	newKey.AutoGroups = req.AutoGroups
 	newKey.Revoked = req.Revoked
 	newKey.Id = keyID
+	newKey.SetupKeyProvisioning = oldKey.SetupKeyProvisioning.Copy()
+	applyProvisioningRequest(&newKey.SetupKeyProvisioning, req.AllowedIpRanges, req.AutoRoutes, req.AutoPostureChecks, req.PeerNamePrefix)
 
 	newKey, err = h.accountManager.SaveSetupKey(r.Context(), accountID, newKey, userID)
 	if err != nil {
@@ -251,5 +263,25 @@ func ToResponseBody(key *types.SetupKey) *api.SetupKey {
 		UsageLimit:          key.UsageLimit,
 		Ephemeral:           key.Ephemeral,
 		AllowExtraDnsLabels: key.AllowExtraDNSLabels,
+		AllowedIpRanges:     key.AllowedIPRanges,
+		AutoRoutes:          key.AutoRoutes,
+		AutoPostureChecks:   key.AutoPostureChecks,
+		PeerNamePrefix:      key.PeerNamePrefix,
+	}
+}
+
+// applyProvisioningRequest sets the provisioning settings provided in the request
+func applyProvisioningRequest(provisioning *types.SetupKeyProvisioning, allowedIPRanges, autoRoutes, autoPostureChecks *[]string, peerNamePrefix *string) {
+	if allowedIPRanges != nil {
+		provisioning.AllowedIPRanges = *allowedIPRanges
+	}
+	if autoRoutes != nil {
+		provisioning.AutoRoutes = *autoRoutes
+	}
+	if autoPostureChecks != nil {
+		provisioning.AutoPostureChecks = *autoPostureChecks
+	}
+	if peerNamePrefix != nil {
+		provisioning.PeerNamePrefix = *peerNamePrefix
 	}
 }
@@ -32,12 +32,13 @@ func initSetupKeysTestMetaData(defaultKey *types.SetupKey, newKey *types.SetupKe
 	return &handler{
 		accountManager: &mock_server.MockAccountManager{
 			CreateSetupKeyFunc: func(_ context.Context, _ string, keyName string, typ types.SetupKeyType, _ time.Duration, _ []string,
-				_ int, _ string, ephemeral bool, allowExtraDNSLabels bool,
+				_ int, _ string, ephemeral bool, allowExtraDNSLabels bool, provisioning types.SetupKeyProvisioning,
 			) (*types.SetupKey, error) {
 				if keyName == newKey.Name || typ != newKey.Type {
 					nk := newKey.Copy()
 					nk.Ephemeral = ephemeral
 					nk.AllowExtraDNSLabels = allowExtraDNSLabels
+					nk.SetupKeyProvisioning = provisioning
 					return nk, nil
 				}
 				return nil, fmt.Errorf("failed creating setup key")
@@ -0,0 +1,154 @@
+package tenants
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/netbirdio/netbird/management/server/account"
+	nbcontext "github.com/netbirdio/netbird/management/server/context"
+	"github.com/netbirdio/netbird/management/server/http/api"
+	"github.com/netbirdio/netbird/management/server/http/util"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/types"
+)
+
+// handler is a handler that handles the tenant accounts of an account
+type handler struct {
+	accountManager account.Manager
+}
+
+func AddEndpoints(accountManager account.Manager, router *mux.Router) {
+	tenantsHandler := newHandler(accountManager)
+	router.HandleFunc("/tenants", tenantsHandler.getAllTenants).Methods("GET", "OPTIONS")
+	router.HandleFunc("/tenants", tenantsHandler.createTenant).Methods("POST", "OPTIONS")
+	router.HandleFunc("/tenants/usage", tenantsHandler.getTenantsUsage).Methods("GET", "OPTIONS")
+	router.HandleFunc("/tenants/{tenantId}", tenantsHandler.deleteTenant).Methods("DELETE", "OPTIONS")
+}
+
+func newHandler(accountManager account.Manager) *handler {
+	return &handler{
+		accountManager: accountManager,
+	}
+}
+
+func (h *handler) getAllTenants(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	accountID, userID := userAuth.AccountId, userAuth.UserId
+
+	tenants, err := h.accountManager.GetTenants(r.Context(), accountID, userID)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	tenantsResponse := make([]*api.Tenant, 0, len(tenants))
+	for _, tenant := range tenants {
+		tenantsResponse = append(tenantsResponse, toTenantResponse(tenant))
+	}
+
+	util.WriteJSONObject(r.Context(), w, tenantsResponse)
+}
+
+func (h *handler) createTenant(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	accountID, userID := userAuth.AccountId, userAuth.UserId
+
+	var req api.PostApiTenantsJSONRequestBody
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		util.WriteErrorResponse("couldn't parse JSON request", http.StatusBadRequest, w)
+		return
+	}
+
+	tenant, err := h.accountManager.CreateTenant(r.Context(), accountID, userID, req.Name, req.OwnerUserId)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	util.WriteJSONObject(r.Context(), w, toTenantResponse(tenant))
+}
+
+func (h *handler) getTenantsUsage(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	accountID, userID := userAuth.AccountId, userAuth.UserId
+
+	usage, err := h.accountManager.GetTenantsUsage(r.Context(), accountID, userID)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	util.WriteJSONObject(r.Context(), w, toTenantsUsageResponse(usage))
+}
+
+func (h *handler) deleteTenant(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	accountID, userID := userAuth.AccountId, userAuth.UserId
+
+	tenantID := mux.Vars(r)["tenantId"]
+	if len(tenantID) == 0 {
+		util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid tenant ID"), w)
+		return
+	}
+
+	err = h.accountManager.DeleteTenant(r.Context(), accountID, userID, tenantID)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	util.WriteJSONObject(r.Context(), w, util.EmptyObject{})
+}
+
+func toTenantResponse(tenant *types.Tenant) *api.Tenant {
+	return &api.Tenant{
+		Id:        tenant.AccountID,
+		Name:      tenant.Name,
+		CreatedBy: tenant.CreatedBy,
+		CreatedAt: tenant.CreatedAt,
+	}
+}
+
+func toTenantsUsageResponse(usage []*types.TenantUsage) *api.TenantsUsage {
+	response := &api.TenantsUsage{
+		Tenants: make([]api.TenantUsage, 0, len(usage)),
+	}
+
+	for _, tenantUsage := range usage {
+		response.Tenants = append(response.Tenants, api.TenantUsage{
+			Id:             tenantUsage.AccountID,
+			Name:           tenantUsage.Name,
+			Peers:          tenantUsage.Peers,
+			ConnectedPeers: tenantUsage.ConnectedPeers,
+			Users:          tenantUsage.Users,
+		})
+		response.TotalPeers += tenantUsage.Peers
+		response.TotalConnectedPeers += tenantUsage.ConnectedPeers
+		response.TotalUsers += tenantUsage.Users
+	}
+
+	return response
+}
@@ -0,0 +1,45 @@
+package topology
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	nbcontext "github.com/netbirdio/netbird/management/server/context"
+	"github.com/netbirdio/netbird/management/server/http/util"
+	"github.com/netbirdio/netbird/management/server/topology"
+)
+
+// handler is a handler that returns the effective connectivity graph of the account
+type handler struct {
+	topologyManager topology.Manager
+}
+
+func AddEndpoints(topologyManager topology.Manager, router *mux.Router) {
+	topologyHandler := newHandler(topologyManager)
+	router.HandleFunc("/topology", topologyHandler.getTopology).Methods("GET", "OPTIONS")
+}
+
+func newHandler(topologyManager topology.Manager) *handler {
+	return &handler{
+		topologyManager: topologyManager,
+	}
+}
+
+func (h *handler) getTopology(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	accountID, userID := userAuth.AccountId, userAuth.UserId
+
+	graph, err := h.topologyManager.GetTopology(r.Context(), accountID, userID, r.URL.Query().Get("peer_id"))
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	util.WriteJSONObject(r.Context(), w, graph.ToAPIResponse())
+}
@@ -0,0 +1,165 @@
+package usage
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+
+	nbcontext "github.com/netbirdio/netbird/management/server/context"
+	"github.com/netbirdio/netbird/management/server/http/api"
+	"github.com/netbirdio/netbird/management/server/http/util"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/usage"
+	"github.com/netbirdio/netbird/management/server/usage/types"
+)
+
+// csvHeader is the header row of the CSV export of the usage
+var csvHeader = []string{"date", "active_peers", "peak_connected_peers", "relay_bytes", "events"}
+
+// handler is a handler that returns the usage of the account
+type handler struct {
+	usageManager usage.Manager
+}
+
+func AddEndpoints(usageManager usage.Manager, router *mux.Router) {
+	usageHandler := newHandler(usageManager)
+	router.HandleFunc("/usage", usageHandler.getUsage).Methods("GET", "OPTIONS")
+	router.HandleFunc("/usage/exit-nodes", usageHandler.getExitNodeUsage).Methods("GET", "OPTIONS")
+}
+
+func newHandler(usageManager usage.Manager) *handler {
+	return &handler{
+		usageManager: usageManager,
+	}
+}
+
+func (h *handler) getUsage(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	accountID, userID := userAuth.AccountId, userAuth.UserId
+
+	query := r.URL.Query()
+
+	from, err := parseDay(query.Get("from"))
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+	to, err := parseDay(query.Get("to"))
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	format := api.GetApiUsageParamsFormat(query.Get("format"))
+	if format != "" && format != api.GetApiUsageParamsFormatJson && format != api.GetApiUsageParamsFormatCsv {
+		util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid format %s", format), w)
+		return
+	}
+
+	samples, err := h.usageManager.GetUsage(r.Context(), accountID, userID, from, to)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	if format == api.GetApiUsageParamsFormatCsv {
+		writeCSV(r, w, samples)
+		return
+	}
+
+	samplesResponse := make([]*api.UsageSample, 0, len(samples))
+	for _, sample := range samples {
+		samplesResponse = append(samplesResponse, sample.ToAPIResponse())
+	}
+
+	util.WriteJSONObject(r.Context(), w, samplesResponse)
+}
+
+func (h *handler) getExitNodeUsage(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	accountID, userID := userAuth.AccountId, userAuth.UserId
+
+	month, err := parseMonth(r.URL.Query().Get("month"))
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	exitNodeUsage, err := h.usageManager.GetExitNodeUsage(r.Context(), accountID, userID, month)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	usageResponse := make([]*api.ExitNodeUsage, 0, len(exitNodeUsage))
+	for _, u := range exitNodeUsage {
+		usageResponse = append(usageResponse, u.ToAPIResponse())
+	}
+
+	util.WriteJSONObject(r.Context(), w, usageResponse)
+}
+
+func writeCSV(r *http.Request, w http.ResponseWriter, samples []*types.Sample) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="usage.csv"`)
+	w.WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(w)
+	rows := make([][]string, 0, len(samples)+1)
+	rows = append(rows, csvHeader)
+	for _, sample := range samples {
+		rows = append(rows, []string{
+			sample.Date.UTC().Format(types.DateFormat),
+			strconv.Itoa(sample.ActivePeers),
+			strconv.Itoa(sample.PeakConnectedPeers),
+			strconv.FormatInt(sample.RelayBytes, 10),
+			strconv.Itoa(sample.Events),
+		})
+	}
+
+	if err := writer.WriteAll(rows); err != nil {
+		log.WithContext(r.Context()).Errorf("failed to write usage CSV: %v", err)
+	}
+}
+
+// parseDay parses a day of the usage period, the zero time is returned if it is empty
+func parseDay(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	day, err := time.Parse(types.DateFormat, value)
+	if err != nil {
+		return time.Time{}, status.Errorf(status.InvalidArgument, "invalid day %s, expected YYYY-MM-DD", value)
+	}
+
+	return day, nil
+}
+
+// parseMonth parses the month of the exit node usage, the zero time is returned if it is empty
+func parseMonth(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	month, err := time.Parse(types.MonthFormat, value)
+	if err != nil {
+		return time.Time{}, status.Errorf(status.InvalidArgument, "invalid month %s, expected YYYY-MM", value)
+	}
+
+	return month, nil
+}
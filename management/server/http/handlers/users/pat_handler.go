@@ -3,6 +3,7 @@ package users
 import (
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
 
@@ -25,6 +26,7 @@ func addUsersTokensEndpoint(accountManager account.Manager, router *mux.Router)
 	router.HandleFunc("/users/{userId}/tokens", tokenHandler.createToken).Methods("POST", "OPTIONS")
 	router.HandleFunc("/users/{userId}/tokens/{tokenId}", tokenHandler.getToken).Methods("GET", "OPTIONS")
 	router.HandleFunc("/users/{userId}/tokens/{tokenId}", tokenHandler.deleteToken).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/users/{userId}/tokens/{tokenId}/rotate", tokenHandler.rotateToken).Methods("POST", "OPTIONS")
 }
 
 // newPATsHandler creates a new patHandler HTTP handler
@@ -118,7 +120,59 @@ func (h *patHandler) createToken(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pat, err := h.accountManager.CreatePAT(r.Context(), accountID, userID, targetUserID, req.Name, req.ExpiresIn)
+	var scopes, groups []string
+	if req.Scopes != nil {
+		scopes = *req.Scopes
+	}
+	if req.Groups != nil {
+		groups = *req.Groups
+	}
+
+	pat, err := h.accountManager.CreatePAT(r.Context(), accountID, userID, targetUserID, req.Name, req.ExpiresIn, scopes, groups)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	util.WriteJSONObject(r.Context(), w, toPATGeneratedResponse(pat))
+}
+
+// rotateToken is HTTP POST handler that replaces a personal access token of the given user with a new one.
+// The rotated token stays valid for the requested grace period.
+func (h *patHandler) rotateToken(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	accountID, userID := userAuth.AccountId, userAuth.UserId
+	vars := mux.Vars(r)
+	targetUserID := vars["userId"]
+	if len(targetUserID) == 0 {
+		util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid user ID"), w)
+		return
+	}
+
+	tokenID := vars["tokenId"]
+	if len(tokenID) == 0 {
+		util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid token ID"), w)
+		return
+	}
+
+	var req api.PostApiUsersUserIdTokensTokenIdRotateJSONRequestBody
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		util.WriteErrorResponse("couldn't parse JSON request", http.StatusBadRequest, w)
+		return
+	}
+
+	gracePeriod := types.DefaultPATRotationGracePeriod
+	if req.GracePeriod != nil {
+		gracePeriod = time.Duration(*req.GracePeriod) * time.Minute
+	}
+
+	pat, err := h.accountManager.RotatePAT(r.Context(), accountID, userID, targetUserID, tokenID, req.ExpiresIn, gracePeriod)
 	if err != nil {
 		util.WriteError(r.Context(), err, w)
 		return
@@ -159,7 +213,7 @@ func (h *patHandler) deleteToken(w http.ResponseWriter, r *http.Request) {
 }
 
 func toPATResponse(pat *types.PersonalAccessToken) *api.PersonalAccessToken {
-	return &api.PersonalAccessToken{
+	response := &api.PersonalAccessToken{
 		CreatedAt:      pat.CreatedAt,
 		CreatedBy:      pat.CreatedBy,
 		Name:           pat.Name,
@@ -167,6 +221,15 @@ func toPATResponse(pat *types.PersonalAccessToken) *api.PersonalAccessToken {
 		Id:             pat.ID,
 		LastUsed:       pat.LastUsed,
 	}
+
+	if len(pat.Scopes) > 0 {
+		response.Scopes = &pat.Scopes
+	}
+	if len(pat.Groups) > 0 {
+		response.Groups = &pat.Groups
+	}
+
+	return response
 }
 
 func toPATGeneratedResponse(pat *types.PersonalAccessTokenGenerated) *api.PersonalAccessTokenGenerated {
@@ -0,0 +1,171 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	nbcontext "github.com/netbirdio/netbird/management/server/context"
+	"github.com/netbirdio/netbird/management/server/http/api"
+	"github.com/netbirdio/netbird/management/server/http/util"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/webhooks"
+	"github.com/netbirdio/netbird/management/server/webhooks/types"
+)
+
+// handler is a handler that manages the webhooks of the account
+type handler struct {
+	webhooksManager webhooks.Manager
+}
+
+func AddEndpoints(webhooksManager webhooks.Manager, router *mux.Router) {
+	webhooksHandler := newHandler(webhooksManager)
+	router.HandleFunc("/webhooks", webhooksHandler.getAllWebhooks).Methods("GET", "OPTIONS")
+	router.HandleFunc("/webhooks", webhooksHandler.createWebhook).Methods("POST", "OPTIONS")
+	router.HandleFunc("/webhooks/{webhookId}", webhooksHandler.getWebhook).Methods("GET", "OPTIONS")
+	router.HandleFunc("/webhooks/{webhookId}", webhooksHandler.updateWebhook).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/webhooks/{webhookId}", webhooksHandler.deleteWebhook).Methods("DELETE", "OPTIONS")
+}
+
+func newHandler(webhooksManager webhooks.Manager) *handler {
+	return &handler{
+		webhooksManager: webhooksManager,
+	}
+}
+
+func (h *handler) getAllWebhooks(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	accountID, userID := userAuth.AccountId, userAuth.UserId
+
+	webhooks, err := h.webhooksManager.GetAllWebhooks(r.Context(), accountID, userID)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	webhooksResponse := make([]*api.Webhook, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		webhooksResponse = append(webhooksResponse, webhook.ToAPIResponse())
+	}
+
+	util.WriteJSONObject(r.Context(), w, webhooksResponse)
+}
+
+func (h *handler) createWebhook(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	accountID, userID := userAuth.AccountId, userAuth.UserId
+
+	var req api.WebhookRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		util.WriteErrorResponse("couldn't parse JSON request", http.StatusBadRequest, w)
+		return
+	}
+
+	webhook := &types.Webhook{}
+	webhook.FromAPIRequest(&req)
+
+	webhook.AccountID = accountID
+	webhook, err = h.webhooksManager.CreateWebhook(r.Context(), userID, webhook)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	util.WriteJSONObject(r.Context(), w, webhook.ToAPIResponse())
+}
+
+func (h *handler) getWebhook(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	accountID, userID := userAuth.AccountId, userAuth.UserId
+
+	webhookID := mux.Vars(r)["webhookId"]
+	if len(webhookID) == 0 {
+		util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid webhook ID"), w)
+		return
+	}
+
+	webhook, err := h.webhooksManager.GetWebhook(r.Context(), accountID, userID, webhookID)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	util.WriteJSONObject(r.Context(), w, webhook.ToAPIResponse())
+}
+
+func (h *handler) updateWebhook(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	accountID, userID := userAuth.AccountId, userAuth.UserId
+
+	webhookID := mux.Vars(r)["webhookId"]
+	if len(webhookID) == 0 {
+		util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid webhook ID"), w)
+		return
+	}
+
+	var req api.WebhookRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		util.WriteErrorResponse("couldn't parse JSON request", http.StatusBadRequest, w)
+		return
+	}
+
+	webhook := &types.Webhook{}
+	webhook.FromAPIRequest(&req)
+
+	webhook.ID = webhookID
+	webhook.AccountID = accountID
+	webhook, err = h.webhooksManager.UpdateWebhook(r.Context(), userID, webhook)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	util.WriteJSONObject(r.Context(), w, webhook.ToAPIResponse())
+}
+
+func (h *handler) deleteWebhook(w http.ResponseWriter, r *http.Request) {
+	userAuth, err := nbcontext.GetUserAuthFromContext(r.Context())
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	accountID, userID := userAuth.AccountId, userAuth.UserId
+
+	webhookID := mux.Vars(r)["webhookId"]
+	if len(webhookID) == 0 {
+		util.WriteError(r.Context(), status.Errorf(status.InvalidArgument, "invalid webhook ID"), w)
+		return
+	}
+
+	err = h.webhooksManager.DeleteWebhook(r.Context(), accountID, userID, webhookID)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	util.WriteJSONObject(r.Context(), w, util.EmptyObject{})
+}
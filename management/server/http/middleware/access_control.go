@@ -4,12 +4,16 @@ import (
 	"context"
 	"net/http"
 	"regexp"
+	"slices"
+	"strings"
 
+	"github.com/gorilla/mux"
 	log "github.com/sirupsen/logrus"
 
 	nbcontext "github.com/netbirdio/netbird/management/server/context"
 	"github.com/netbirdio/netbird/management/server/http/middleware/bypass"
 	"github.com/netbirdio/netbird/management/server/http/util"
+	"github.com/netbirdio/netbird/management/server/permissions"
 	"github.com/netbirdio/netbird/management/server/status"
 	"github.com/netbirdio/netbird/management/server/types"
 )
@@ -17,20 +21,70 @@ import (
 // GetUser function defines a function to fetch user from Account by jwtclaims.AuthorizationClaims
 type GetUser func(ctx context.Context, userAuth nbcontext.UserAuth) (*types.User, error)
 
+// GetPeerGroups function defines a function to fetch the groups of a peer
+type GetPeerGroups func(ctx context.Context, accountID, peerID string) ([]*types.Group, error)
+
 // AccessControl middleware to restrict to make POST/PUT/DELETE requests by admin only
+// or by users granted write access to the requested module by a custom role.
+// It also restricts scoped personal access tokens to their scopes and groups.
 type AccessControl struct {
-	getUser GetUser
+	getUser            GetUser
+	getPeerGroups      GetPeerGroups
+	permissionsManager permissions.Manager
 }
 
 // NewAccessControl instance constructor
-func NewAccessControl(getUser GetUser) *AccessControl {
+func NewAccessControl(getUser GetUser, getPeerGroups GetPeerGroups, permissionsManager permissions.Manager) *AccessControl {
 	return &AccessControl{
-		getUser: getUser,
+		getUser:            getUser,
+		getPeerGroups:      getPeerGroups,
+		permissionsManager: permissionsManager,
 	}
 }
 
 var tokenPathRegexp = regexp.MustCompile(`^.*/api/users/.*/tokens.*$`)
 
+// pathModules maps the API path prefixes to the permission modules they belong to. Longer prefixes come first.
+var pathModules = []struct {
+	prefix string
+	module permissions.Module
+}{
+	{"/api/dns/nameservers", permissions.Nameservers},
+	{"/api/dns/settings", permissions.DNS},
+	{"/api/dns/zone", permissions.DNS},
+	{"/api/peers", permissions.Peers},
+	{"/api/groups", permissions.Groups},
+	{"/api/routes", permissions.Routes},
+	{"/api/rollouts", permissions.Routes},
+	{"/api/policies", permissions.Policies},
+	{"/api/setup-keys", permissions.SetupKeys},
+	{"/api/posture-checks", permissions.PostureChecks},
+	{"/api/locations", permissions.PostureChecks},
+	{"/api/networks", permissions.Networks},
+	{"/api/users", permissions.Users},
+	{"/api/scim", permissions.Users},
+	{"/api/events", permissions.Events},
+	{"/api/accounts", permissions.Settings},
+	{"/api/roles", permissions.Roles},
+	{"/api/tenants", permissions.Tenants},
+	{"/api/webhooks", permissions.Webhooks},
+	{"/api/monitors", permissions.Monitors},
+	{"/api/usage", permissions.Settings},
+	{"/api/connectivity", permissions.Peers},
+	{"/api/topology", permissions.Policies},
+	{"/api/access-history", permissions.Policies},
+}
+
+// moduleFromPath returns the permission module the request path belongs to
+func moduleFromPath(path string) (permissions.Module, bool) {
+	for _, pm := range pathModules {
+		if strings.HasPrefix(path, pm.prefix) {
+			return pm.module, true
+		}
+	}
+	return "", false
+}
+
 // Handler method of the middleware which forbids all modify requests for non admin users
 func (a *AccessControl) Handler(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -57,6 +111,11 @@ func (a *AccessControl) Handler(h http.Handler) http.Handler {
 			return
 		}
 
+		if userAuth.IsPAT && !a.isAllowedByPAT(r, userAuth) {
+			util.WriteError(r.Context(), status.Errorf(status.PermissionDenied, "the token scopes don't allow this operation"), w)
+			return
+		}
+
 		if !user.HasAdminPower() {
 			switch r.Method {
 			case http.MethodDelete, http.MethodPost, http.MethodPatch, http.MethodPut:
@@ -67,6 +126,11 @@ func (a *AccessControl) Handler(h http.Handler) http.Handler {
 					return
 				}
 
+				if a.hasWritePermission(r, user) {
+					h.ServeHTTP(w, r)
+					return
+				}
+
 				util.WriteError(r.Context(), status.Errorf(status.PermissionDenied, "only users with admin power can perform this operation"), w)
 				return
 			}
@@ -75,3 +139,74 @@ func (a *AccessControl) Handler(h http.Handler) http.Handler {
 		h.ServeHTTP(w, r)
 	})
 }
+
+// hasWritePermission checks whether a custom role grants the user write access to the module of the request.
+// The handlers validate the permissions again for the operation they perform.
+func (a *AccessControl) hasWritePermission(r *http.Request, user *types.User) bool {
+	module, ok := moduleFromPath(r.URL.Path)
+	if !ok {
+		return false
+	}
+
+	allowed, err := a.permissionsManager.ValidateUserPermissions(r.Context(), user.AccountID, user.Id, module, permissions.Write)
+	if err != nil {
+		log.WithContext(r.Context()).Errorf("failed to validate user permissions: %s", err)
+		return false
+	}
+
+	return allowed
+}
+
+// isAllowedByPAT checks whether the scopes and groups of the personal access token allow the request.
+// Requests to paths not belonging to any module are denied for scoped tokens. The tokens restricted to groups
+// can only modify the groups and the peers, the modules which resources can't be scoped to groups are denied.
+func (a *AccessControl) isAllowedByPAT(r *http.Request, userAuth nbcontext.UserAuth) bool {
+	if len(userAuth.PATScopes) == 0 && len(userAuth.PATGroups) == 0 {
+		return true
+	}
+
+	module, ok := moduleFromPath(r.URL.Path)
+	if !ok {
+		return false
+	}
+
+	operation := types.PATScopeWrite
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		operation = types.PATScopeRead
+	}
+
+	if !types.PATScopesAllow(userAuth.PATScopes, string(module), operation) {
+		return false
+	}
+
+	if len(userAuth.PATGroups) == 0 || operation == types.PATScopeRead {
+		return true
+	}
+
+	vars := mux.Vars(r)
+	switch module {
+	case permissions.Groups:
+		return slices.Contains(userAuth.PATGroups, vars["groupId"])
+	case permissions.Peers:
+		peerID := vars["peerId"]
+		if peerID == "" {
+			return false
+		}
+
+		groups, err := a.getPeerGroups(r.Context(), userAuth.AccountId, peerID)
+		if err != nil {
+			log.WithContext(r.Context()).Errorf("failed to get peer groups: %s", err)
+			return false
+		}
+
+		for _, group := range groups {
+			if slices.Contains(userAuth.PATGroups, group.ID) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
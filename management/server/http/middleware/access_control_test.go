@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	nbcontext "github.com/netbirdio/netbird/management/server/context"
+	"github.com/netbirdio/netbird/management/server/permissions"
+	"github.com/netbirdio/netbird/management/server/types"
+)
+
+func TestAccessControl_PATScopes(t *testing.T) {
+	tt := []struct {
+		name           string
+		method         string
+		path           string
+		scopes         []string
+		groups         []string
+		expectedStatus int
+	}{
+		{
+			name:           "unscoped token",
+			method:         http.MethodPut,
+			path:           "/api/routes/route1",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "read scope allows read",
+			method:         http.MethodGet,
+			path:           "/api/peers",
+			scopes:         []string{"peers:read"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "read scope denies write",
+			method:         http.MethodPut,
+			path:           "/api/peers/peer1",
+			scopes:         []string{"peers:read"},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "write scope allows read",
+			method:         http.MethodGet,
+			path:           "/api/routes",
+			scopes:         []string{"routes:write"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "other module is denied",
+			method:         http.MethodGet,
+			path:           "/api/groups",
+			scopes:         []string{"routes:write"},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "path without module is denied",
+			method:         http.MethodGet,
+			path:           "/api/unknown",
+			scopes:         []string{"routes:write"},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "allowed group",
+			method:         http.MethodPut,
+			path:           "/api/groups/group1",
+			groups:         []string{"group1"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "other group is denied",
+			method:         http.MethodPut,
+			path:           "/api/groups/group2",
+			groups:         []string{"group1"},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "peer in allowed group",
+			method:         http.MethodPut,
+			path:           "/api/peers/peer1",
+			scopes:         []string{"peers:write"},
+			groups:         []string{"group1"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "group restricted token reads other modules",
+			method:         http.MethodGet,
+			path:           "/api/routes",
+			groups:         []string{"group1"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "group restricted token can't modify other modules",
+			method:         http.MethodPut,
+			path:           "/api/routes/route1",
+			groups:         []string{"group1"},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "group restricted token can't create groups",
+			method:         http.MethodPost,
+			path:           "/api/groups",
+			groups:         []string{"group1"},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "peer outside allowed groups is denied",
+			method:         http.MethodDelete,
+			path:           "/api/peers/peer2",
+			scopes:         []string{"peers:write"},
+			groups:         []string{"group1"},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	peerGroups := map[string][]*types.Group{
+		"peer1": {{ID: "group1"}},
+		"peer2": {{ID: "group2"}},
+	}
+
+	acMiddleware := NewAccessControl(
+		func(_ context.Context, userAuth nbcontext.UserAuth) (*types.User, error) {
+			return &types.User{Id: userAuth.UserId, AccountID: userAuth.AccountId, Role: types.UserRoleAdmin}, nil
+		},
+		func(_ context.Context, _, peerID string) ([]*types.Group, error) {
+			return peerGroups[peerID], nil
+		},
+		permissions.NewManagerMock(),
+	)
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			userAuth := nbcontext.UserAuth{
+				UserId:    userID,
+				AccountId: accountID,
+				IsPAT:     true,
+				PATScopes: tc.scopes,
+				PATGroups: tc.groups,
+			}
+
+			router := mux.NewRouter()
+			router.Use(func(h http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					h.ServeHTTP(w, nbcontext.SetUserAuthInRequest(r, userAuth))
+				})
+			}, acMiddleware.Handler)
+			router.HandleFunc("/api/groups/{groupId}", func(w http.ResponseWriter, r *http.Request) {})
+			router.HandleFunc("/api/peers/{peerId}", func(w http.ResponseWriter, r *http.Request) {})
+			router.PathPrefix("/api/").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+		})
+	}
+}
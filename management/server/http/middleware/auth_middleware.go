@@ -15,6 +15,7 @@ import (
 	"github.com/netbirdio/netbird/management/server/http/middleware/bypass"
 	"github.com/netbirdio/netbird/management/server/http/util"
 	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/types"
 )
 
 type EnsureAccountFunc func(ctx context.Context, userAuth nbcontext.UserAuth) (string, string, error)
@@ -50,8 +51,8 @@ func (m *AuthMiddleware) Handler(h http.Handler) http.Handler {
 		auth := strings.Split(r.Header.Get("Authorization"), " ")
 		authType := strings.ToLower(auth[0])
 
-		// fallback to token when receive pat as bearer
-		if len(auth) >= 2 && authType == "bearer" && strings.HasPrefix(auth[1], "nbp_") {
+		// fallback to token when receive pat as bearer, the SCIM clients only send the tokens as OAuth bearer tokens
+		if len(auth) >= 2 && authType == "bearer" && strings.HasPrefix(auth[1], types.PATPrefix) {
 			authType = "token"
 			auth[0] = authType
 		}
@@ -153,6 +154,19 @@ func (m *AuthMiddleware) checkPATFromRequest(r *http.Request, auth []string) (*h
 		Domain:         accDomain,
 		DomainCategory: accCategory,
 		IsPAT:          true,
+		PATID:          pat.ID,
+		PATScopes:      pat.Scopes,
+		PATGroups:      pat.Groups,
+	}
+
+	// service users of a parent account manage its tenants by passing the tenant account ID
+	if impersonate, ok := r.URL.Query()["account"]; ok && len(impersonate) == 1 && impersonate[0] != user.AccountID {
+		userAuth.AccountId = impersonate[0]
+		userAuth.IsChild = true
+
+		if _, _, err = m.ensureAccount(ctx, userAuth); err != nil {
+			return r, err
+		}
 	}
 
 	return nbcontext.SetUserAuthInRequest(r, userAuth), nil
@@ -236,18 +236,21 @@ func TestAuthMiddleware_Handler_Child(t *testing.T) {
 				Domain:         testAccount.Domain,
 				DomainCategory: testAccount.DomainCategory,
 				IsPAT:          true,
+				PATID:          tokenID,
 			},
 		},
 		{
-			name:       "Valid PAT Token ignores child",
+			name:       "Valid PAT Token with child",
 			path:       "/test?account=xyz",
 			authHeader: "Token " + PAT,
 			expectedUserAuth: &nbcontext.UserAuth{
-				AccountId:      accountID,
+				AccountId:      "xyz",
 				UserId:         userID,
 				Domain:         testAccount.Domain,
 				DomainCategory: testAccount.DomainCategory,
+				IsChild:        true,
 				IsPAT:          true,
+				PATID:          tokenID,
 			},
 		},
 		{
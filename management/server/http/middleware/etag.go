@@ -0,0 +1,232 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	nbcontext "github.com/netbirdio/netbird/management/server/context"
+	"github.com/netbirdio/netbird/management/server/http/middleware/bypass"
+	"github.com/netbirdio/netbird/management/server/http/util"
+	"github.com/netbirdio/netbird/management/server/store"
+)
+
+// versionedResource matches the API paths of the resources versioned by the ETag middleware
+var versionedResource = regexp.MustCompile(`^/api/(groups|policies|routes)/[^/]+$`)
+
+var (
+	// errVersionMismatch rolls back a write whose If-Match doesn't match the version of the resource
+	errVersionMismatch = errors.New("resource version mismatch")
+	// errWriteFailed rolls back the changes of a failed write
+	errWriteFailed = errors.New("resource write failed")
+)
+
+// ETag middleware versions the groups, policies and routes of the API by a revision kept in the store. The revision is
+// incremented in the store transaction of every PUT, PATCH or DELETE request of the resource, the responses of the
+// resource carry it in the ETag header. A request with an If-Match header is applied only if the revision still
+// matches, the check and the write are part of the same transaction, otherwise a 409 response with the current version
+// is returned, so concurrent edits through any management server don't overwrite each other. The changes of a
+// resource made through other endpoints, e.g. the peers added to a group by a setup key, don't change its version.
+type ETag struct {
+	store store.Store
+}
+
+// NewETag instance constructor
+func NewETag(store store.Store) *ETag {
+	return &ETag{
+		store: store,
+	}
+}
+
+// Handler method of the middleware which sets the ETag of the versioned resources and checks the If-Match preconditions
+func (e *ETag) Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if bypass.ShouldBypass(r.URL.Path, h, w, r) {
+			return
+		}
+
+		if !versionedResource.MatchString(r.URL.Path) {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		userAuth, err := nbcontext.GetUserAuthFromRequest(r)
+		if err != nil {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			e.serveRead(h, w, r, userAuth.AccountId)
+		case http.MethodPut, http.MethodPatch, http.MethodDelete:
+			e.serveWrite(h, w, r, userAuth.AccountId)
+		default:
+			h.ServeHTTP(w, r)
+		}
+	})
+}
+
+// serveRead serves the resource with its version. The revision is read before the resource, so a write in between
+// makes the returned version stale rather than newer than the returned resource.
+func (e *ETag) serveRead(h http.Handler, w http.ResponseWriter, r *http.Request, accountID string) {
+	revision, err := e.store.GetResourceRevision(r.Context(), store.LockingStrengthShare, accountID, r.URL.Path)
+	if err != nil {
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	writer := &etagWriter{ResponseWriter: w, version: resourceVersion(revision)}
+	h.ServeHTTP(writer, r)
+	writer.finish(r)
+}
+
+// serveWrite applies the write in a store transaction incrementing the revision of the resource, if the previous
+// revision matches If-Match. The changes of a failed write are rolled back.
+func (e *ETag) serveWrite(h http.Handler, w http.ResponseWriter, r *http.Request, accountID string) {
+	ifMatch := r.Header.Get("If-Match")
+	resp := &bufferedResponse{header: make(http.Header), status: http.StatusOK}
+
+	var currentVersion string
+	err := e.store.ExecuteInBatchTransaction(r.Context(), func(ctx context.Context) error {
+		// the incremented revision stays locked until the write is committed or rolled back
+		revision, err := e.store.IncrementResourceRevision(ctx, accountID, r.URL.Path)
+		if err != nil {
+			return err
+		}
+
+		currentVersion = resourceVersion(revision - 1)
+		if ifMatch != "" && !matchesVersion(ifMatch, currentVersion) {
+			return errVersionMismatch
+		}
+
+		h.ServeHTTP(resp, r.WithContext(ctx))
+		if resp.status < 200 || resp.status > 299 {
+			return errWriteFailed
+		}
+
+		if r.Method != http.MethodDelete {
+			resp.header.Set("ETag", resourceVersion(revision))
+		}
+		return nil
+	})
+	switch {
+	case errors.Is(err, errVersionMismatch):
+		writeConflict(w, "the resource was modified, its current version doesn't match If-Match", currentVersion)
+		return
+	case errors.Is(err, errWriteFailed):
+	case err != nil:
+		util.WriteError(r.Context(), err, w)
+		return
+	}
+
+	resp.writeTo(r.Context(), w)
+}
+
+// resourceVersion returns the ETag of a revision
+func resourceVersion(revision uint64) string {
+	return `"` + strconv.FormatUint(revision, 10) + `"`
+}
+
+// matchesVersion checks whether the version is one of the ETags of an If-Match or If-None-Match header
+func matchesVersion(header, version string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || tag == version {
+			return true
+		}
+	}
+	return false
+}
+
+// etagWriter buffers the successful JSON responses to set their ETag, the other responses are written through
+type etagWriter struct {
+	http.ResponseWriter
+	version     string
+	wroteHeader bool
+	buffered    bool
+	body        bytes.Buffer
+}
+
+func (w *etagWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.buffered = code == http.StatusOK && strings.HasPrefix(w.Header().Get("Content-Type"), "application/json")
+	if !w.buffered {
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (w *etagWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.buffered {
+		return w.body.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *etagWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok && !w.buffered {
+		f.Flush()
+	}
+}
+
+// finish writes the buffered response with its ETag, a request matching If-None-Match gets a 304 response
+func (w *etagWriter) finish(r *http.Request) {
+	if !w.buffered {
+		return
+	}
+
+	w.Header().Set("ETag", w.version)
+
+	if matchesVersion(r.Header.Get("If-None-Match"), w.version) {
+		w.Header().Del("Content-Type")
+		w.ResponseWriter.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.ResponseWriter.WriteHeader(http.StatusOK)
+	if _, err := w.ResponseWriter.Write(w.body.Bytes()); err != nil {
+		log.WithContext(r.Context()).Debugf("failed to write the response: %v", err)
+	}
+}
+
+// bufferedResponse captures the response of a write, it is written once the write is committed or rolled back
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *bufferedResponse) Header() http.Header {
+	return r.header
+}
+
+func (r *bufferedResponse) Write(b []byte) (int, error) {
+	return r.body.Write(b)
+}
+
+func (r *bufferedResponse) WriteHeader(code int) {
+	r.status = code
+}
+
+// writeTo writes the buffered response
+func (r *bufferedResponse) writeTo(ctx context.Context, w http.ResponseWriter) {
+	for name, values := range r.header {
+		w.Header()[name] = values
+	}
+	w.WriteHeader(r.status)
+	if _, err := w.Write(r.body.Bytes()); err != nil {
+		log.WithContext(ctx).Debugf("failed to write the response: %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	nbcontext "github.com/netbirdio/netbird/management/server/context"
+	"github.com/netbirdio/netbird/management/server/http/api"
+	"github.com/netbirdio/netbird/management/server/store"
+)
+
+func newMiddlewareTestStore(t *testing.T) store.Store {
+	t.Helper()
+
+	testStore, cleanup, err := store.NewTestStoreFromSQL(context.Background(), "", t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(cleanup)
+	return testStore
+}
+
+// groupsBackend keeps the groups served by the test routers, the routers of a test act as management servers sharing it
+type groupsBackend struct {
+	mu     sync.Mutex
+	groups map[string]string
+}
+
+func newETagTestRouter(testStore store.Store, backend *groupsBackend) *mux.Router {
+	rootRouter := mux.NewRouter()
+	router := rootRouter.PathPrefix("/api").Subrouter()
+	router.Use(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h.ServeHTTP(w, nbcontext.SetUserAuthInRequest(r, nbcontext.UserAuth{AccountId: "account1", UserId: "user1"}))
+		})
+	}, NewETag(testStore).Handler)
+
+	writeGroup := func(w http.ResponseWriter, id, name string) {
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(map[string]string{"id": id, "name": name})
+	}
+
+	router.HandleFunc("/groups/{groupId}", func(w http.ResponseWriter, r *http.Request) {
+		backend.mu.Lock()
+		defer backend.mu.Unlock()
+
+		id := mux.Vars(r)["groupId"]
+		if _, ok := backend.groups[id]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writeGroup(w, id, backend.groups[id])
+	}).Methods(http.MethodGet)
+
+	router.HandleFunc("/groups/{groupId}", func(w http.ResponseWriter, r *http.Request) {
+		backend.mu.Lock()
+		defer backend.mu.Unlock()
+
+		id := mux.Vars(r)["groupId"]
+		if _, ok := backend.groups[id]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		name, _ := io.ReadAll(r.Body)
+		backend.groups[id] = string(name)
+		writeGroup(w, id, backend.groups[id])
+	}).Methods(http.MethodPut)
+
+	return rootRouter
+}
+
+func serveETagRequest(router *mux.Router, method, path, body string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestETag_Handler(t *testing.T) {
+	testStore := newMiddlewareTestStore(t)
+	router := newETagTestRouter(testStore, &groupsBackend{groups: map[string]string{"group1": "dev"}})
+	serve := func(method, path, body string, header http.Header) *httptest.ResponseRecorder {
+		return serveETagRequest(router, method, path, body, header)
+	}
+
+	get := serve(http.MethodGet, "/api/groups/group1", "", nil)
+	require.Equal(t, http.StatusOK, get.Code)
+	version := get.Header().Get("ETag")
+	require.NotEmpty(t, version)
+	assert.JSONEq(t, `{"id":"group1","name":"dev"}`, get.Body.String())
+
+	notModified := serve(http.MethodGet, "/api/groups/group1", "", http.Header{"If-None-Match": {version}})
+	assert.Equal(t, http.StatusNotModified, notModified.Code)
+	assert.Empty(t, notModified.Body.String())
+
+	update := serve(http.MethodPut, "/api/groups/group1", "prod", http.Header{"If-Match": {version}})
+	require.Equal(t, http.StatusOK, update.Code)
+	newVersion := update.Header().Get("ETag")
+	assert.NotEqual(t, version, newVersion)
+	assert.Equal(t, newVersion, serve(http.MethodGet, "/api/groups/group1", "", nil).Header().Get("ETag"),
+		"the response of the update should carry the version of the updated resource")
+
+	stale := serve(http.MethodPut, "/api/groups/group1", "staging", http.Header{"If-Match": {version}})
+	require.Equal(t, http.StatusConflict, stale.Code, "a stale version should conflict")
+	conflict := &api.ConflictResponse{}
+	require.NoError(t, json.Unmarshal(stale.Body.Bytes(), conflict))
+	require.NotNil(t, conflict.CurrentVersion)
+	assert.Equal(t, newVersion, *conflict.CurrentVersion)
+	assert.Equal(t, http.StatusConflict, conflict.Code)
+
+	current := serve(http.MethodGet, "/api/groups/group1", "", nil)
+	assert.JSONEq(t, `{"id":"group1","name":"prod"}`, current.Body.String(), "the conflicting update shouldn't be applied")
+	assert.Equal(t, newVersion, current.Header().Get("ETag"), "the conflicting update shouldn't change the version")
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodPut, "/api/groups/group1", "staging", http.Header{"If-Match": {"*"}}).Code)
+	version = serve(http.MethodGet, "/api/groups/group1", "", nil).Header().Get("ETag")
+	assert.Equal(t, http.StatusOK, serve(http.MethodPut, "/api/groups/group1", "test", nil).Code,
+		"an update without If-Match should be applied")
+	assert.Equal(t, http.StatusConflict, serve(http.MethodPut, "/api/groups/group1", "qa", http.Header{"If-Match": {version}}).Code,
+		"an update without If-Match should change the version")
+
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodPut, "/api/groups/missing", "test", nil).Code)
+	revision, err := testStore.GetResourceRevision(context.Background(), store.LockingStrengthShare, "account1", "/api/groups/missing")
+	require.NoError(t, err)
+	assert.Zero(t, revision, "a failed write should be rolled back")
+}
+
+func TestETag_SharedStore(t *testing.T) {
+	testStore := newMiddlewareTestStore(t)
+	backend := &groupsBackend{groups: map[string]string{"group1": "dev"}}
+	server1 := newETagTestRouter(testStore, backend)
+	server2 := newETagTestRouter(testStore, backend)
+
+	version := serveETagRequest(server1, http.MethodGet, "/api/groups/group1", "", nil).Header().Get("ETag")
+	require.NotEmpty(t, version)
+
+	update := serveETagRequest(server2, http.MethodPut, "/api/groups/group1", "prod", http.Header{"If-Match": {version}})
+	require.Equal(t, http.StatusOK, update.Code)
+
+	stale := serveETagRequest(server1, http.MethodPut, "/api/groups/group1", "staging", http.Header{"If-Match": {version}})
+	assert.Equal(t, http.StatusConflict, stale.Code, "the version should be shared by the management servers")
+	assert.Equal(t, update.Header().Get("ETag"), stale.Header().Get("ETag"))
+}
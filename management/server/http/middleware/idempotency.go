@@ -0,0 +1,255 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	nbcontext "github.com/netbirdio/netbird/management/server/context"
+	"github.com/netbirdio/netbird/management/server/http/api"
+	"github.com/netbirdio/netbird/management/server/http/util"
+	"github.com/netbirdio/netbird/management/server/store"
+	"github.com/netbirdio/netbird/management/server/types"
+	"github.com/netbirdio/netbird/util/errcode"
+)
+
+const (
+	// IdempotencyKeyHeader is the header of the key identifying a mutating request across its retries
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set on the responses replayed for a retried request
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	// idempotencyTTL is the period the response of a request is replayed for its retries
+	idempotencyTTL = 24 * time.Hour
+	// idempotencyReservationTTL is the period the key of a request in progress is reserved for, the retries are
+	// applied again once it expires in case the management server serving the request was stopped
+	idempotencyReservationTTL = 5 * time.Minute
+	// maxIdempotencyKeyLength limits the length of the keys
+	maxIdempotencyKeyLength = 255
+	// idempotencySweepInterval is the minimal period between the removals of the expired keys
+	idempotencySweepInterval = time.Minute
+)
+
+// Idempotency middleware applies a POST, PUT, PATCH or DELETE request with an Idempotency-Key header once. The retries
+// of the request with the same key replay its response, so retrying automations don't create duplicate resources.
+// The keys and the responses are kept in the store shared by the management servers, the keys are scoped to the user
+// of the request.
+type Idempotency struct {
+	store store.Store
+
+	mu        sync.Mutex
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewIdempotency instance constructor
+func NewIdempotency(store store.Store) *Idempotency {
+	return &Idempotency{
+		store: store,
+		now:   time.Now,
+	}
+}
+
+// Handler method of the middleware which replays the responses of the retried requests
+func (m *Idempotency) Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" || !isMutating(r.Method) {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		userAuth, err := nbcontext.GetUserAuthFromRequest(r)
+		if err != nil {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			util.WriteErrorResponse("the idempotency key can't be longer than 255 characters", http.StatusBadRequest, w)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			util.WriteErrorResponse("couldn't read the request body", http.StatusBadRequest, w)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		fingerprint := requestFingerprint(r, body)
+		now := m.now()
+		m.sweep(r.Context(), now)
+
+		record := &types.IdempotencyKey{
+			ID:          scopedIdempotencyKey(userAuth, key),
+			AccountID:   userAuth.AccountId,
+			Fingerprint: fingerprint[:],
+			ExpiresAt:   now.Add(idempotencyReservationTTL),
+		}
+		kept, reserved, err := m.store.ReserveIdempotencyKey(r.Context(), record, now)
+		if err != nil {
+			util.WriteError(r.Context(), err, w)
+			return
+		}
+
+		if !reserved {
+			replay(r.Context(), w, kept, fingerprint)
+			return
+		}
+
+		// the record is updated even if the client went away, the retries would be rejected as in progress otherwise
+		ctx := context.WithoutCancel(r.Context())
+		recorder := &recordingWriter{ResponseWriter: w, status: http.StatusOK}
+		completed := false
+		defer func() {
+			// the handler panicked, the reservation is released so the retries aren't rejected as in progress
+			if !completed {
+				m.release(ctx, record)
+			}
+		}()
+
+		h.ServeHTTP(recorder, r)
+		m.complete(ctx, record, recorder)
+		completed = true
+	})
+}
+
+// complete keeps the recorded response for the retries. Server errors aren't kept, the retries apply the request again.
+func (m *Idempotency) complete(ctx context.Context, record *types.IdempotencyKey, recorder *recordingWriter) {
+	if recorder.status >= http.StatusInternalServerError {
+		m.release(ctx, record)
+		return
+	}
+
+	record.Completed = true
+	record.Status = recorder.status
+	record.Header = recorder.Header().Clone()
+	record.Body = recorder.body.Bytes()
+	record.ExpiresAt = m.now().Add(idempotencyTTL)
+	if err := m.store.SaveIdempotencyKey(ctx, record); err != nil {
+		log.WithContext(ctx).Errorf("failed to keep the response of the idempotent request: %v", err)
+	}
+}
+
+// release removes the reservation of a request that wasn't completed, the retries apply the request again
+func (m *Idempotency) release(ctx context.Context, record *types.IdempotencyKey) {
+	if err := m.store.DeleteIdempotencyKey(ctx, record.ID); err != nil {
+		log.WithContext(ctx).Errorf("failed to release the idempotency key: %v", err)
+	}
+}
+
+// sweep removes the expired keys, at most once every idempotencySweepInterval
+func (m *Idempotency) sweep(ctx context.Context, now time.Time) {
+	m.mu.Lock()
+	if now.Sub(m.lastSweep) < idempotencySweepInterval {
+		m.mu.Unlock()
+		return
+	}
+	m.lastSweep = now
+	m.mu.Unlock()
+
+	if err := m.store.DeleteExpiredIdempotencyKeys(ctx, now); err != nil {
+		log.WithContext(ctx).Warnf("failed to remove the expired idempotency keys: %v", err)
+	}
+}
+
+// replay writes the kept response, the request has to be the same as the one the response was recorded for
+func replay(ctx context.Context, w http.ResponseWriter, record *types.IdempotencyKey, fingerprint [sha256.Size]byte) {
+	if !bytes.Equal(record.Fingerprint, fingerprint[:]) {
+		util.WriteErrorResponse("the idempotency key was already used for a different request", http.StatusUnprocessableEntity, w)
+		return
+	}
+
+	if !record.Completed {
+		writeConflict(w, "a request with the same idempotency key is in progress", "")
+		return
+	}
+
+	for name, values := range record.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(record.Status)
+	if _, err := w.Write(record.Body); err != nil {
+		log.WithContext(ctx).Debugf("failed to write the replayed response: %v", err)
+	}
+}
+
+// scopedIdempotencyKey returns the ID of the key scoped to the account and the user of the request
+func scopedIdempotencyKey(userAuth nbcontext.UserAuth, key string) string {
+	sum := sha256.Sum256([]byte(userAuth.AccountId + "/" + userAuth.UserId + "/" + key))
+	return hex.EncodeToString(sum[:])
+}
+
+// requestFingerprint identifies the request a key was used for by its method, URI including the query and body
+func requestFingerprint(r *http.Request, body []byte) [sha256.Size]byte {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	hash.Write(body)
+
+	var fingerprint [sha256.Size]byte
+	copy(fingerprint[:], hash.Sum(nil))
+	return fingerprint
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// writeConflict writes a 409 response, currentVersion is the current ETag of the resource if the conflict is caused
+// by a modification of the resource
+func writeConflict(w http.ResponseWriter, message, currentVersion string) {
+	resp := api.ConflictResponse{
+		Message:   message,
+		Code:      http.StatusConflict,
+		ErrorCode: string(errcode.Aborted),
+	}
+	if currentVersion != "" {
+		resp.CurrentVersion = &currentVersion
+		w.Header().Set("ETag", currentVersion)
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusConflict)
+	if err := json.NewEncoder(w).Encode(&resp); err != nil {
+		log.Debugf("failed to write the conflict response: %v", err)
+	}
+}
+
+// recordingWriter writes the response through and records it
+type recordingWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (w *recordingWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	nbcontext "github.com/netbirdio/netbird/management/server/context"
+	"github.com/netbirdio/netbird/management/server/types"
+)
+
+func TestIdempotency_Handler(t *testing.T) {
+	var applied int
+	handler := NewIdempotency(newMiddlewareTestStore(t)).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		applied++
+		if strings.Contains(r.URL.Path, "failing") {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = fmt.Fprintf(w, `{"id":"route%d"}`, applied)
+	}))
+
+	serve := func(userID, path, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		req = nbcontext.SetUserAuthInRequest(req, nbcontext.UserAuth{AccountId: "account1", UserId: userID})
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := serve("user1", "/api/routes", "key1", `{"network":"10.0.0.0/24"}`)
+	require.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, `{"id":"route1"}`, first.Body.String())
+
+	retry := serve("user1", "/api/routes", "key1", `{"network":"10.0.0.0/24"}`)
+	assert.Equal(t, http.StatusOK, retry.Code)
+	assert.Equal(t, `{"id":"route1"}`, retry.Body.String(), "the retry should replay the first response")
+	assert.Equal(t, "true", retry.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, 1, applied, "the retry shouldn't be applied again")
+
+	reused := serve("user1", "/api/routes", "key1", `{"network":"10.0.1.0/24"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, reused.Code, "a key reused for a different request should be rejected")
+
+	otherQuery := serve("user1", "/api/routes?account=tenant1", "key1", `{"network":"10.0.0.0/24"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, otherQuery.Code, "a key reused for a different query should be rejected")
+
+	otherUser := serve("user2", "/api/routes", "key1", `{"network":"10.0.0.0/24"}`)
+	assert.Equal(t, `{"id":"route2"}`, otherUser.Body.String(), "the keys should be scoped to the user")
+
+	serve("user1", "/api/routes", "", `{"network":"10.0.0.0/24"}`)
+	serve("user1", "/api/routes", "", `{"network":"10.0.0.0/24"}`)
+	assert.Equal(t, 4, applied, "requests without a key should always be applied")
+
+	serve("user1", "/api/failing", "key2", "")
+	failedRetry := serve("user1", "/api/failing", "key2", "")
+	assert.Equal(t, http.StatusInternalServerError, failedRetry.Code)
+	assert.Empty(t, failedRetry.Header().Get(IdempotentReplayedHeader), "server errors shouldn't be replayed")
+	assert.Equal(t, 6, applied)
+}
+
+func TestIdempotency_Panic(t *testing.T) {
+	var applied int
+	handler := NewIdempotency(newMiddlewareTestStore(t)).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		applied++
+		if applied == 1 {
+			panic("handler failure")
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	assert.Panics(t, func() { serveIdempotentDelete(handler) })
+
+	retry := serveIdempotentDelete(handler)
+	assert.Equal(t, http.StatusOK, retry.Code, "the retry of a panicked request shouldn't be rejected as in progress")
+	assert.Empty(t, retry.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, 2, applied)
+}
+
+func serveIdempotentDelete(handler http.Handler) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodDelete, "/api/routes/route1", nil)
+	req.Header.Set(IdempotencyKeyHeader, "key1")
+	req = nbcontext.SetUserAuthInRequest(req, nbcontext.UserAuth{AccountId: "account1", UserId: "user1"})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdempotency_InProgress(t *testing.T) {
+	testStore := newMiddlewareTestStore(t)
+	m := NewIdempotency(testStore)
+
+	var applied int
+	handler := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		applied++
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	// another management server is serving the request
+	fingerprint := requestFingerprint(httptest.NewRequest(http.MethodDelete, "/api/routes/route1", nil), nil)
+	now := time.Now()
+	_, reserved, err := testStore.ReserveIdempotencyKey(context.Background(), &types.IdempotencyKey{
+		ID:          scopedIdempotencyKey(nbcontext.UserAuth{AccountId: "account1", UserId: "user1"}, "key1"),
+		AccountID:   "account1",
+		Fingerprint: fingerprint[:],
+		ExpiresAt:   now.Add(idempotencyReservationTTL),
+	}, now)
+	require.NoError(t, err)
+	require.True(t, reserved)
+
+	assert.Equal(t, http.StatusConflict, serveIdempotentDelete(handler).Code, "a retry of a request in progress should conflict")
+	assert.Zero(t, applied)
+
+	m.now = func() time.Time { return now.Add(idempotencyReservationTTL + time.Second) }
+	retry := serveIdempotentDelete(handler)
+	assert.Equal(t, http.StatusOK, retry.Code, "the request should be applied once the reservation expires")
+	assert.Empty(t, retry.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, 1, applied)
+}
+
+func TestIdempotency_SharedStore(t *testing.T) {
+	testStore := newMiddlewareTestStore(t)
+
+	var applied int
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		applied++
+		w.WriteHeader(http.StatusOK)
+	})
+	server1 := NewIdempotency(testStore).Handler(handler)
+	server2 := NewIdempotency(testStore).Handler(handler)
+
+	require.Equal(t, http.StatusOK, serveIdempotentDelete(server1).Code)
+
+	retry := serveIdempotentDelete(server2)
+	assert.Equal(t, http.StatusOK, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get(IdempotentReplayedHeader), "the keys should be shared by the management servers")
+	assert.Equal(t, 1, applied)
+}
@@ -0,0 +1,49 @@
+package pagination
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
+)
+
+// NextCursorHeader holds the cursor of the next page of a paginated list response, it is not set on the last page
+const NextCursorHeader = "X-Next-Cursor"
+
+// ParseListOptions reads the sort_by, order, cursor and limit query parameters of a list request.
+// The returned bool is false if none of them are set, so that the handlers can keep returning the entire collection.
+func ParseListOptions(r *http.Request) (store.ListOptions, bool, error) {
+	query := r.URL.Query()
+	opts := store.ListOptions{
+		SortBy: query.Get("sort_by"),
+		Cursor: query.Get("cursor"),
+	}
+
+	switch query.Get("order") {
+	case "", "asc":
+	case "desc":
+		opts.Descending = true
+	default:
+		return opts, false, status.Errorf(status.InvalidArgument, "order must be asc or desc")
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 1 || value > store.MaxListLimit {
+			return opts, false, status.Errorf(status.InvalidArgument, "limit must be between 1 and %d", store.MaxListLimit)
+		}
+		opts.Limit = value
+	}
+
+	requested := query.Has("sort_by") || query.Has("order") || query.Has("cursor") || query.Has("limit")
+
+	return opts, requested, nil
+}
+
+// WriteNextCursor sets the cursor of the next page on the response if there is one
+func WriteNextCursor(w http.ResponseWriter, cursor string) {
+	if cursor != "" {
+		w.Header().Set(NextCursorHeader, cursor)
+	}
+}
@@ -0,0 +1,96 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/management/server/http/api"
+	"github.com/netbirdio/netbird/management/server/http/testing/testing_tools"
+)
+
+func doBulk(t *testing.T, apiHandler http.Handler, token string, req *api.BulkRequest) (int, *api.BulkResponse) {
+	t.Helper()
+
+	body, err := json.Marshal(req)
+	require.NoError(t, err)
+
+	httpReq := httptest.NewRequest(http.MethodPost, "/api/bulk", bytes.NewReader(body))
+	httpReq.Header.Set("Authorization", "Token "+token)
+	recorder := httptest.NewRecorder()
+	apiHandler.ServeHTTP(recorder, httpReq)
+
+	resp := &api.BulkResponse{}
+	if recorder.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), resp))
+	}
+	return recorder.Code, resp
+}
+
+func groupNames(t *testing.T, apiHandler http.Handler, token string) []string {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/groups", nil)
+	req.Header.Set("Authorization", "Token "+token)
+	recorder := httptest.NewRecorder()
+	apiHandler.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	var groups []api.Group
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &groups))
+
+	names := make([]string, 0, len(groups))
+	for _, group := range groups {
+		names = append(names, group.Name)
+	}
+	return names
+}
+
+func Test_Bulk_Transaction(t *testing.T) {
+	apiHandler, am, _ := testing_tools.BuildApiBlackBoxWithDBState(t, "../testdata/users.sql", nil, false)
+
+	pat, err := am.CreatePAT(context.Background(), testing_tools.TestAccountId, testing_tools.TestServiceAdminId, testing_tools.TestServiceAdminId, "bulk", 30, nil, nil)
+	require.NoError(t, err)
+
+	atomic := true
+	operations := []api.BulkOperation{
+		{Method: api.BulkOperationMethodPOST, Path: "/groups", Body: &map[string]interface{}{"name": "bulk-dev"}},
+		{Method: api.BulkOperationMethodDELETE, Path: "/groups/missing"},
+		{Method: api.BulkOperationMethodPOST, Path: "/groups", Body: &map[string]interface{}{"name": "bulk-prod"}},
+	}
+
+	code, resp := doBulk(t, apiHandler, pat.PlainToken, &api.BulkRequest{Operations: operations, Atomic: &atomic})
+	require.Equal(t, http.StatusOK, code)
+	assert.True(t, resp.RolledBack)
+	assert.True(t, resp.Results[0].RolledBack)
+	assert.True(t, resp.Results[2].Skipped)
+	assert.NotContains(t, groupNames(t, apiHandler, pat.PlainToken), "bulk-dev", "the atomic request should be rolled back")
+
+	code, resp = doBulk(t, apiHandler, pat.PlainToken, &api.BulkRequest{Operations: operations})
+	require.Equal(t, http.StatusOK, code)
+	assert.False(t, resp.RolledBack)
+	assert.Equal(t, 2, resp.Succeeded)
+	assert.Equal(t, 1, resp.Failed)
+	assert.Subset(t, groupNames(t, apiHandler, pat.PlainToken), []string{"bulk-dev", "bulk-prod"})
+}
+
+func Test_Bulk_ScopedToken(t *testing.T) {
+	apiHandler, am, _ := testing_tools.BuildApiBlackBoxWithDBState(t, "../testdata/users.sql", nil, false)
+
+	pat, err := am.CreatePAT(context.Background(), testing_tools.TestAccountId, testing_tools.TestServiceAdminId, testing_tools.TestServiceAdminId, "bulk", 30, []string{"groups:read"}, nil)
+	require.NoError(t, err)
+
+	code, _ := doBulk(t, apiHandler, pat.PlainToken, &api.BulkRequest{Operations: []api.BulkOperation{
+		{Method: api.BulkOperationMethodPOST, Path: "/groups", Body: &map[string]interface{}{"name": "bulk-dev"}},
+	}})
+	assert.Equal(t, http.StatusForbidden, code, "the bulk request should go through the token scope checks")
+}
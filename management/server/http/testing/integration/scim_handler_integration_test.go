@@ -0,0 +1,80 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/management/server/http/testing/testing_tools"
+)
+
+func Test_SCIM_Authentication(t *testing.T) {
+	apiHandler, am, _ := testing_tools.BuildApiBlackBoxWithDBState(t, "../testdata/users.sql", nil, false)
+
+	pat, err := am.CreatePAT(context.Background(), testing_tools.TestAccountId, testing_tools.TestServiceAdminId, testing_tools.TestServiceAdminId, "scim", 30, nil, nil)
+	require.NoError(t, err)
+
+	tt := []struct {
+		name           string
+		path           string
+		authHeader     string
+		expectedStatus int
+	}{
+		{
+			name:           "PAT as bearer token",
+			path:           "/api/scim/v2/Users",
+			authHeader:     "Bearer " + pat.PlainToken,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "PAT as bearer token for the provider config",
+			path:           "/api/scim/v2/ServiceProviderConfig",
+			authHeader:     "Bearer " + pat.PlainToken,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "PAT as token",
+			path:           "/api/scim/v2/Groups",
+			authHeader:     "Token " + pat.PlainToken,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Unknown PAT as bearer token",
+			path:           "/api/scim/v2/Users",
+			authHeader:     "Bearer nbp_unknownunknownunknownunknownun000000",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Invalid JWT",
+			path:           "/api/scim/v2/Users",
+			authHeader:     "Bearer " + testing_tools.InvalidToken,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Without authentication",
+			path:           "/api/scim/v2/Users",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.authHeader != "" {
+				req.Header.Set("Authorization", tc.authHeader)
+			}
+			recorder := httptest.NewRecorder()
+
+			apiHandler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tc.expectedStatus, recorder.Code, recorder.Body.String())
+		})
+	}
+}
@@ -27,22 +27,38 @@ import (
 	"github.com/netbirdio/netbird/management/server/users"
 
 	"github.com/netbirdio/netbird/management/server"
+	"github.com/netbirdio/netbird/management/server/accesshistory"
 	"github.com/netbirdio/netbird/management/server/account"
 	"github.com/netbirdio/netbird/management/server/activity"
 	"github.com/netbirdio/netbird/management/server/auth"
+	"github.com/netbirdio/netbird/management/server/backup"
+	"github.com/netbirdio/netbird/management/server/connectivity"
 	nbcontext "github.com/netbirdio/netbird/management/server/context"
+	"github.com/netbirdio/netbird/management/server/controlplane"
+	"github.com/netbirdio/netbird/management/server/debugbundles"
+	"github.com/netbirdio/netbird/management/server/declarative"
 	"github.com/netbirdio/netbird/management/server/geolocation"
 	"github.com/netbirdio/netbird/management/server/groups"
 	nbhttp "github.com/netbirdio/netbird/management/server/http"
+	"github.com/netbirdio/netbird/management/server/monitors"
 	"github.com/netbirdio/netbird/management/server/networks"
 	"github.com/netbirdio/netbird/management/server/networks/resources"
 	"github.com/netbirdio/netbird/management/server/networks/routers"
 	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+	"github.com/netbirdio/netbird/management/server/peeractions"
 	"github.com/netbirdio/netbird/management/server/posture"
+	"github.com/netbirdio/netbird/management/server/probes"
+	"github.com/netbirdio/netbird/management/server/roles"
+	"github.com/netbirdio/netbird/management/server/scim"
+	"github.com/netbirdio/netbird/management/server/simulation"
 	"github.com/netbirdio/netbird/management/server/store"
+	"github.com/netbirdio/netbird/management/server/stream"
 	"github.com/netbirdio/netbird/management/server/telemetry"
+	"github.com/netbirdio/netbird/management/server/topology"
 	"github.com/netbirdio/netbird/management/server/types"
+	"github.com/netbirdio/netbird/management/server/usage"
 	"github.com/netbirdio/netbird/management/server/util"
+	"github.com/netbirdio/netbird/management/server/webhooks"
 )
 
 const (
@@ -145,7 +161,7 @@ func BuildApiBlackBoxWithDBState(t TB, sqlFile string, expectedPeerUpdate *serve
 	groupsManagerMock := groups.NewManagerMock()
 	peersManager := peers.NewManager(store, permissionsManagerMock)
 
-	apiHandler, err := nbhttp.NewAPIHandler(context.Background(), am, networksManagerMock, resourcesManagerMock, routersManagerMock, groupsManagerMock, geoMock, authManagerMock, metrics, validatorMock, proxyController, permissionsManagerMock, peersManager, settingsManager)
+	apiHandler, err := nbhttp.NewAPIHandler(context.Background(), am, networksManagerMock, resourcesManagerMock, routersManagerMock, groupsManagerMock, geoMock, authManagerMock, metrics, validatorMock, proxyController, permissionsManagerMock, peersManager, settingsManager, scim.NewManagerMock(), roles.NewManagerMock(), webhooks.NewManagerMock(), stream.NewManagerMock(), probes.NewManagerMock(), monitors.NewManagerMock(), usage.NewManagerMock(), topology.NewManagerMock(), simulation.NewManagerMock(), backup.NewManagerMock(), accesshistory.NewManagerMock(), debugbundles.NewManagerMock(), connectivity.NewManagerMock(), peeractions.NewManagerMock(), declarative.NewManagerMock(), controlplane.NewManagerMock())
 	if err != nil {
 		t.Fatalf("Failed to create API handler: %v", err)
 	}
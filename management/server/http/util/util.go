@@ -12,6 +12,7 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/util/errcode"
 )
 
 // EmptyObject is an empty struct used to return empty JSON object
@@ -21,6 +22,8 @@ type EmptyObject struct {
 type ErrorResponse struct {
 	Message string `json:"message"`
 	Code    int    `json:"code"`
+	// ErrorCode is the stable code of the error, see the errcode package
+	ErrorCode string `json:"error_code"`
 }
 
 // WriteJSONObject simply writes object to the HTTP response in JSON format
@@ -68,17 +71,48 @@ func (d *Duration) UnmarshalJSON(b []byte) error {
 
 // WriteErrorResponse prepares and writes an error response i nJSON
 func WriteErrorResponse(errMsg string, httpStatus int, w http.ResponseWriter) {
+	writeErrorResponse(errMsg, httpStatus, httpErrorCode(httpStatus), w)
+}
+
+func writeErrorResponse(errMsg string, httpStatus int, code errcode.Code, w http.ResponseWriter) {
 	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 	w.WriteHeader(httpStatus)
 	err := json.NewEncoder(w).Encode(&ErrorResponse{
-		Message: errMsg,
-		Code:    httpStatus,
+		Message:   errMsg,
+		Code:      httpStatus,
+		ErrorCode: string(code),
 	})
 	if err != nil {
 		http.Error(w, "failed handling request", http.StatusInternalServerError)
 	}
 }
 
+// httpErrorCode returns the generic code of the errors returned with the HTTP status
+func httpErrorCode(httpStatus int) errcode.Code {
+	switch httpStatus {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return errcode.InvalidArgument
+	case http.StatusUnauthorized:
+		return errcode.Unauthenticated
+	case http.StatusForbidden:
+		return errcode.PermissionDenied
+	case http.StatusNotFound:
+		return errcode.NotFound
+	case http.StatusConflict:
+		return errcode.AlreadyExists
+	case http.StatusPreconditionFailed:
+		return errcode.PreconditionFailed
+	case http.StatusNotImplemented:
+		return errcode.Unimplemented
+	case http.StatusServiceUnavailable:
+		return errcode.Unavailable
+	case http.StatusInternalServerError:
+		return errcode.Internal
+	default:
+		return errcode.Unknown
+	}
+}
+
 // WriteError converts an error to an JSON error response.
 // If it is known internal error of type server.Error then it sets the messages from the error, a generic message otherwise
 func WriteError(ctx context.Context, err error, w http.ResponseWriter) {
@@ -86,6 +120,7 @@ func WriteError(ctx context.Context, err error, w http.ResponseWriter) {
 	errStatus, ok := status.FromError(err)
 	httpStatus := http.StatusInternalServerError
 	msg := "internal server error"
+	code := errcode.Internal
 	if ok {
 		switch errStatus.Type() {
 		case status.UserAlreadyExists:
@@ -109,10 +144,11 @@ func WriteError(ctx context.Context, err error, w http.ResponseWriter) {
 		default:
 		}
 		msg = strings.ToLower(err.Error())
+		code = errStatus.ErrorCode()
 	} else {
 		unhandledMSG := fmt.Sprintf("got unhandled error code, error: %s", err.Error())
 		log.WithContext(ctx).Error(unhandledMSG)
 	}
 
-	WriteErrorResponse(msg, httpStatus, w)
+	writeErrorResponse(msg, httpStatus, code, w)
 }
@@ -0,0 +1,63 @@
+package idp
+
+import (
+	"context"
+	"sync"
+)
+
+// ReloadableManager is a Manager delegating to an IdP manager that can be replaced while the server is running,
+// e.g. when the IdP credentials of the management config are changed and reloaded
+type ReloadableManager struct {
+	mu      sync.RWMutex
+	manager Manager
+}
+
+// NewReloadableManager returns a ReloadableManager delegating to the manager
+func NewReloadableManager(manager Manager) *ReloadableManager {
+	return &ReloadableManager{manager: manager}
+}
+
+// Swap replaces the manager the calls are delegated to. Calls in progress complete with the previous manager.
+func (r *ReloadableManager) Swap(manager Manager) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.manager = manager
+}
+
+func (r *ReloadableManager) current() Manager {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.manager
+}
+
+func (r *ReloadableManager) UpdateUserAppMetadata(ctx context.Context, userId string, appMetadata AppMetadata) error {
+	return r.current().UpdateUserAppMetadata(ctx, userId, appMetadata)
+}
+
+func (r *ReloadableManager) GetUserDataByID(ctx context.Context, userId string, appMetadata AppMetadata) (*UserData, error) {
+	return r.current().GetUserDataByID(ctx, userId, appMetadata)
+}
+
+func (r *ReloadableManager) GetAccount(ctx context.Context, accountId string) ([]*UserData, error) {
+	return r.current().GetAccount(ctx, accountId)
+}
+
+func (r *ReloadableManager) GetAllAccounts(ctx context.Context) (map[string][]*UserData, error) {
+	return r.current().GetAllAccounts(ctx)
+}
+
+func (r *ReloadableManager) CreateUser(ctx context.Context, email, name, accountID, invitedByEmail string) (*UserData, error) {
+	return r.current().CreateUser(ctx, email, name, accountID, invitedByEmail)
+}
+
+func (r *ReloadableManager) GetUserByEmail(ctx context.Context, email string) ([]*UserData, error) {
+	return r.current().GetUserByEmail(ctx, email)
+}
+
+func (r *ReloadableManager) InviteUserByID(ctx context.Context, userID string) error {
+	return r.current().InviteUserByID(ctx, userID)
+}
+
+func (r *ReloadableManager) DeleteUser(ctx context.Context, userID string) error {
+	return r.current().DeleteUser(ctx, userID)
+}
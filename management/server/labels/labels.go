@@ -0,0 +1,95 @@
+// Package labels validates and matches the key/value labels of the peers, the routes and the network resources.
+// The labels select the peers and the resources of the policies, e.g. env=prod, without a group per attribute.
+package labels
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// maxLabels is the maximum number of labels of an object
+	maxLabels      = 64
+	maxKeyLength   = 63
+	maxValueLength = 63
+)
+
+// labelRegex restricts the keys and the values to the characters that need no escaping in the URLs and the selectors
+var labelRegex = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9._/-]*[a-zA-Z0-9])?$`)
+
+// Validate checks the number of labels and the format of their keys and values
+func Validate(labels map[string]string) error {
+	if len(labels) > maxLabels {
+		return fmt.Errorf("too many labels, the maximum is %d", maxLabels)
+	}
+
+	for key, value := range labels {
+		if len(key) > maxKeyLength || !labelRegex.MatchString(key) {
+			return fmt.Errorf("invalid label key %q", key)
+		}
+		if value == "" {
+			continue
+		}
+		if len(value) > maxValueLength || !labelRegex.MatchString(value) {
+			return fmt.Errorf("invalid value %q of the label %s", value, key)
+		}
+	}
+
+	return nil
+}
+
+// Matches checks if the labels have all the labels of the selector. An empty selector matches nothing so a rule
+// without a selector doesn't select every labeled object.
+func Matches(selector, labels map[string]string) bool {
+	if len(selector) == 0 {
+		return false
+	}
+
+	for key, value := range selector {
+		if v, ok := labels[key]; !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Parse parses the key=value selectors, e.g. of the query parameters of the list endpoints
+func Parse(selectors []string) (map[string]string, error) {
+	if len(selectors) == 0 {
+		return nil, nil
+	}
+
+	parsed := make(map[string]string, len(selectors))
+	for _, selector := range selectors {
+		key, value, found := strings.Cut(selector, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid label selector %q, expected key=value", selector)
+		}
+		parsed[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+
+	if err := Validate(parsed); err != nil {
+		return nil, err
+	}
+	return parsed, nil
+}
+
+// String returns the labels in the sorted key=value format
+func String(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for key, value := range labels {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// JSONFragment returns the label as it appears in the JSON serialized labels, to match the labels stored as JSON
+func JSONFragment(key, value string) string {
+	k, _ := json.Marshal(key)
+	v, _ := json.Marshal(value)
+	return string(k) + ":" + string(v)
+}
@@ -0,0 +1,44 @@
+package labels
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(map[string]string{"env": "prod", "pci": "true", "team/owner": "net-ops", "critical": ""}))
+	assert.Error(t, Validate(map[string]string{"": "prod"}), "empty key")
+	assert.Error(t, Validate(map[string]string{"env ": "prod"}), "key with a space")
+	assert.Error(t, Validate(map[string]string{"env": "prod%"}), "value with a wildcard")
+	assert.Error(t, Validate(map[string]string{"env": "-prod"}), "value starting with a dash")
+}
+
+func TestMatches(t *testing.T) {
+	labels := map[string]string{"env": "prod", "pci": "true"}
+
+	assert.True(t, Matches(map[string]string{"env": "prod"}, labels))
+	assert.True(t, Matches(map[string]string{"env": "prod", "pci": "true"}, labels))
+	assert.False(t, Matches(map[string]string{"env": "dev"}, labels))
+	assert.False(t, Matches(map[string]string{"env": "prod", "team": "ops"}, labels))
+	assert.False(t, Matches(nil, labels), "an empty selector shouldn't match")
+}
+
+func TestParse(t *testing.T) {
+	parsed, err := Parse([]string{"env=prod", "pci=true"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"env": "prod", "pci": "true"}, parsed)
+	assert.Equal(t, "env=prod,pci=true", String(parsed))
+
+	_, err = Parse([]string{"env"})
+	assert.Error(t, err)
+
+	parsed, err = Parse(nil)
+	require.NoError(t, err)
+	assert.Nil(t, parsed)
+}
+
+func TestJSONFragment(t *testing.T) {
+	assert.Equal(t, `"env":"prod"`, JSONFragment("env", "prod"))
+}
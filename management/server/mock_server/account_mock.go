@@ -28,13 +28,14 @@ type MockAccountManager struct {
 	GetOrCreateAccountByUserFunc func(ctx context.Context, userId, domain string) (*types.Account, error)
 	GetAccountFunc               func(ctx context.Context, accountID string) (*types.Account, error)
 	CreateSetupKeyFunc           func(ctx context.Context, accountId string, keyName string, keyType types.SetupKeyType,
-		expiresIn time.Duration, autoGroups []string, usageLimit int, userID string, ephemeral bool, allowExtraDNSLabels bool) (*types.SetupKey, error)
+		expiresIn time.Duration, autoGroups []string, usageLimit int, userID string, ephemeral bool, allowExtraDNSLabels bool, provisioning types.SetupKeyProvisioning) (*types.SetupKey, error)
 	GetSetupKeyFunc                     func(ctx context.Context, accountID, userID, keyID string) (*types.SetupKey, error)
 	AccountExistsFunc                   func(ctx context.Context, accountID string) (bool, error)
 	GetAccountIDByUserIdFunc            func(ctx context.Context, userId, domain string) (string, error)
 	GetUserFromUserAuthFunc             func(ctx context.Context, userAuth nbcontext.UserAuth) (*types.User, error)
 	ListUsersFunc                       func(ctx context.Context, accountID string) ([]*types.User, error)
 	GetPeersFunc                        func(ctx context.Context, accountID, userID, nameFilter, ipFilter string) ([]*nbpeer.Peer, error)
+	ListPeersFunc                       func(ctx context.Context, accountID, userID string, filter store.PeerFilter, opts store.ListOptions) ([]*nbpeer.Peer, string, error)
 	MarkPeerConnectedFunc               func(ctx context.Context, peerKey string, connected bool, realIP net.IP) error
 	SyncAndMarkPeerFunc                 func(ctx context.Context, accountID string, peerPubKey string, meta nbpeer.PeerSystemMeta, realIP net.IP) (*nbpeer.Peer, *types.NetworkMap, []*posture.Checks, error)
 	DeletePeerFunc                      func(ctx context.Context, accountID, peerKey, userID string) error
@@ -43,6 +44,7 @@ type MockAccountManager struct {
 	AddPeerFunc                         func(ctx context.Context, setupKey string, userId string, peer *nbpeer.Peer) (*nbpeer.Peer, *types.NetworkMap, []*posture.Checks, error)
 	GetGroupFunc                        func(ctx context.Context, accountID, groupID, userID string) (*types.Group, error)
 	GetAllGroupsFunc                    func(ctx context.Context, accountID, userID string) ([]*types.Group, error)
+	ListGroupsFunc                      func(ctx context.Context, accountID, userID string, filter store.GroupFilter, opts store.ListOptions) ([]*types.Group, string, error)
 	GetGroupByNameFunc                  func(ctx context.Context, accountID, groupName string) (*types.Group, error)
 	SaveGroupFunc                       func(ctx context.Context, accountID, userID string, group *types.Group) error
 	SaveGroupsFunc                      func(ctx context.Context, accountID, userID string, groups []*types.Group) error
@@ -64,6 +66,12 @@ type MockAccountManager struct {
 	SaveRouteFunc                       func(ctx context.Context, accountID string, userID string, route *route.Route) error
 	DeleteRouteFunc                     func(ctx context.Context, accountID string, routeID route.ID, userID string) error
 	ListRoutesFunc                      func(ctx context.Context, accountID, userID string) ([]*route.Route, error)
+	StageRouteRolloutFunc               func(ctx context.Context, accountID, userID string, route *route.Route, settings types.RolloutSettings) (*types.Rollout, error)
+	StagePolicyRolloutFunc              func(ctx context.Context, accountID, userID string, policy *types.Policy, settings types.RolloutSettings) (*types.Rollout, error)
+	GetRolloutFunc                      func(ctx context.Context, accountID, userID, rolloutID string) (*types.Rollout, error)
+	ListRolloutsFunc                    func(ctx context.Context, accountID, userID string) ([]*types.Rollout, error)
+	PromoteRolloutFunc                  func(ctx context.Context, accountID, userID, rolloutID string) (*types.Rollout, error)
+	RollbackRolloutFunc                 func(ctx context.Context, accountID, userID, rolloutID string) (*types.Rollout, error)
 	SaveSetupKeyFunc                    func(ctx context.Context, accountID string, key *types.SetupKey, userID string) (*types.SetupKey, error)
 	ListSetupKeysFunc                   func(ctx context.Context, accountID, userID string) ([]*types.SetupKey, error)
 	SaveUserFunc                        func(ctx context.Context, accountID, userID string, user *types.User) (*types.UserInfo, error)
@@ -71,12 +79,13 @@ type MockAccountManager struct {
 	SaveOrAddUsersFunc                  func(ctx context.Context, accountID, initiatorUserID string, update []*types.User, addIfNotExists bool) ([]*types.UserInfo, error)
 	DeleteUserFunc                      func(ctx context.Context, accountID string, initiatorUserID string, targetUserID string) error
 	DeleteRegularUsersFunc              func(ctx context.Context, accountID, initiatorUserID string, targetUserIDs []string, userInfos map[string]*types.UserInfo) error
-	CreatePATFunc                       func(ctx context.Context, accountID string, initiatorUserID string, targetUserId string, tokenName string, expiresIn int) (*types.PersonalAccessTokenGenerated, error)
+	CreatePATFunc                       func(ctx context.Context, accountID string, initiatorUserID string, targetUserId string, tokenName string, expiresIn int, scopes []string, groups []string) (*types.PersonalAccessTokenGenerated, error)
+	RotatePATFunc                       func(ctx context.Context, accountID string, initiatorUserID string, targetUserId string, tokenID string, expiresIn int, gracePeriod time.Duration) (*types.PersonalAccessTokenGenerated, error)
 	DeletePATFunc                       func(ctx context.Context, accountID string, initiatorUserID string, targetUserId string, tokenID string) error
 	GetPATFunc                          func(ctx context.Context, accountID string, initiatorUserID string, targetUserId string, tokenID string) (*types.PersonalAccessToken, error)
 	GetAllPATsFunc                      func(ctx context.Context, accountID string, initiatorUserID string, targetUserId string) ([]*types.PersonalAccessToken, error)
 	GetNameServerGroupFunc              func(ctx context.Context, accountID, userID, nsGroupID string) (*nbdns.NameServerGroup, error)
-	CreateNameServerGroupFunc           func(ctx context.Context, accountID string, name, description string, nameServerList []nbdns.NameServer, groups []string, primary bool, domains []string, enabled bool, userID string, searchDomainsEnabled bool) (*nbdns.NameServerGroup, error)
+	CreateNameServerGroupFunc           func(ctx context.Context, accountID string, name, description string, nameServerList []nbdns.NameServer, groups []string, primary bool, domains []string, enabled bool, userID string, searchDomainsEnabled bool, routedAnswers nbdns.RoutedAnswers, forwarderPeer string) (*nbdns.NameServerGroup, error)
 	SaveNameServerGroupFunc             func(ctx context.Context, accountID, userID string, nsGroupToSave *nbdns.NameServerGroup) error
 	DeleteNameServerGroupFunc           func(ctx context.Context, accountID, nsGroupID, userID string) error
 	ListNameServerGroupsFunc            func(ctx context.Context, accountID string, userID string) ([]*nbdns.NameServerGroup, error)
@@ -87,6 +96,7 @@ type MockAccountManager struct {
 	StoreEventFunc                      func(ctx context.Context, initiatorID, targetID, accountID string, activityID activity.ActivityDescriber, meta map[string]any)
 	GetEventsFunc                       func(ctx context.Context, accountID, userID string) ([]*activity.Event, error)
 	GetDNSSettingsFunc                  func(ctx context.Context, accountID, userID string) (*types.DNSSettings, error)
+	GetDNSZoneFunc                      func(ctx context.Context, accountID, userID string) (nbdns.CustomZone, error)
 	SaveDNSSettingsFunc                 func(ctx context.Context, accountID, userID string, dnsSettingsToSave *types.DNSSettings) error
 	GetPeerFunc                         func(ctx context.Context, accountID, peerID, userID string) (*nbpeer.Peer, error)
 	UpdateAccountSettingsFunc           func(ctx context.Context, accountID, userID string, newSettings *types.Settings) (*types.Account, error)
@@ -115,10 +125,23 @@ type MockAccountManager struct {
 	CreateAccountByPrivateDomainFunc    func(ctx context.Context, initiatorId, domain string) (*types.Account, error)
 	UpdateToPrimaryAccountFunc          func(ctx context.Context, accountId string) (*types.Account, error)
 	GetOwnerInfoFunc                    func(ctx context.Context, accountID string) (*types.UserInfo, error)
+	GetPendingApprovalPeersFunc         func(ctx context.Context, accountID, userID string) ([]*nbpeer.Peer, error)
+	ApprovePeerFunc                     func(ctx context.Context, accountID, userID, peerID string) (*nbpeer.Peer, error)
+	GetScheduledPeerDeletionsFunc       func(ctx context.Context, accountID, userID string) ([]*types.ScheduledPeerDeletion, error)
+	GetTenantsFunc                      func(ctx context.Context, accountID, userID string) ([]*types.Tenant, error)
+	CreateTenantFunc                    func(ctx context.Context, accountID, userID, name, ownerUserID string) (*types.Tenant, error)
+	DeleteTenantFunc                    func(ctx context.Context, accountID, userID, tenantAccountID string) error
+	GetTenantsUsageFunc                 func(ctx context.Context, accountID, userID string) ([]*types.TenantUsage, error)
+	GetAccountNetworkFunc               func(ctx context.Context, accountID, userID string) (*types.Network, error)
+	UpdateAccountNetworkFunc            func(ctx context.Context, accountID, userID string, networkRange net.IPNet, reservedRanges []string, dryRun bool) (*types.Network, []*types.PeerIPMigration, error)
+	SetPeerStaticIPFunc                 func(ctx context.Context, accountID, userID, peerID string, ip net.IP) (*nbpeer.Peer, error)
+	UpdateAccountPeersFunc              func(ctx context.Context, accountID string)
 }
 
 func (am *MockAccountManager) UpdateAccountPeers(ctx context.Context, accountID string) {
-	// do nothing
+	if am.UpdateAccountPeersFunc != nil {
+		am.UpdateAccountPeersFunc(ctx, accountID)
+	}
 }
 
 func (am *MockAccountManager) DeleteSetupKey(ctx context.Context, accountID, userID, keyID string) error {
@@ -169,6 +192,14 @@ func (am *MockAccountManager) GetAllGroups(ctx context.Context, accountID, userI
 	return nil, status.Errorf(codes.Unimplemented, "method GetAllGroups is not implemented")
 }
 
+// ListGroups mock implementation of ListGroups from server.AccountManager interface
+func (am *MockAccountManager) ListGroups(ctx context.Context, accountID, userID string, filter store.GroupFilter, opts store.ListOptions) ([]*types.Group, string, error) {
+	if am.ListGroupsFunc != nil {
+		return am.ListGroupsFunc(ctx, accountID, userID, filter, opts)
+	}
+	return nil, "", status.Errorf(codes.Unimplemented, "method ListGroups is not implemented")
+}
+
 // GetUsersFromAccount mock implementation of GetUsersFromAccount from server.AccountManager interface
 func (am *MockAccountManager) GetUsersFromAccount(ctx context.Context, accountID string, userID string) (map[string]*types.UserInfo, error) {
 	if am.GetUsersFromAccountFunc != nil {
@@ -210,9 +241,10 @@ func (am *MockAccountManager) CreateSetupKey(
 	userID string,
 	ephemeral bool,
 	allowExtraDNSLabels bool,
+	provisioning types.SetupKeyProvisioning,
 ) (*types.SetupKey, error) {
 	if am.CreateSetupKeyFunc != nil {
-		return am.CreateSetupKeyFunc(ctx, accountID, keyName, keyType, expiresIn, autoGroups, usageLimit, userID, ephemeral, allowExtraDNSLabels)
+		return am.CreateSetupKeyFunc(ctx, accountID, keyName, keyType, expiresIn, autoGroups, usageLimit, userID, ephemeral, allowExtraDNSLabels, provisioning)
 	}
 	return nil, status.Errorf(codes.Unimplemented, "method CreateSetupKey is not implemented")
 }
@@ -253,13 +285,21 @@ func (am *MockAccountManager) DeleteAccount(ctx context.Context, accountID, user
 }
 
 // CreatePAT mock implementation of GetPAT from server.AccountManager interface
-func (am *MockAccountManager) CreatePAT(ctx context.Context, accountID string, initiatorUserID string, targetUserID string, name string, expiresIn int) (*types.PersonalAccessTokenGenerated, error) {
+func (am *MockAccountManager) CreatePAT(ctx context.Context, accountID string, initiatorUserID string, targetUserID string, name string, expiresIn int, scopes []string, groups []string) (*types.PersonalAccessTokenGenerated, error) {
 	if am.CreatePATFunc != nil {
-		return am.CreatePATFunc(ctx, accountID, initiatorUserID, targetUserID, name, expiresIn)
+		return am.CreatePATFunc(ctx, accountID, initiatorUserID, targetUserID, name, expiresIn, scopes, groups)
 	}
 	return nil, status.Errorf(codes.Unimplemented, "method CreatePAT is not implemented")
 }
 
+// RotatePAT mock implementation of RotatePAT from server.AccountManager interface
+func (am *MockAccountManager) RotatePAT(ctx context.Context, accountID string, initiatorUserID string, targetUserID string, tokenID string, expiresIn int, gracePeriod time.Duration) (*types.PersonalAccessTokenGenerated, error) {
+	if am.RotatePATFunc != nil {
+		return am.RotatePATFunc(ctx, accountID, initiatorUserID, targetUserID, tokenID, expiresIn, gracePeriod)
+	}
+	return nil, status.Errorf(codes.Unimplemented, "method RotatePAT is not implemented")
+}
+
 // DeletePAT mock implementation of DeletePAT from server.AccountManager interface
 func (am *MockAccountManager) DeletePAT(ctx context.Context, accountID string, initiatorUserID string, targetUserID string, tokenID string) error {
 	if am.DeletePATFunc != nil {
@@ -480,6 +520,54 @@ func (am *MockAccountManager) ListRoutes(ctx context.Context, accountID, userID
 	return nil, status.Errorf(codes.Unimplemented, "method ListRoutes is not implemented")
 }
 
+// StageRouteRollout mocks StageRouteRollout of the AccountManager interface
+func (am *MockAccountManager) StageRouteRollout(ctx context.Context, accountID, userID string, route *route.Route, settings types.RolloutSettings) (*types.Rollout, error) {
+	if am.StageRouteRolloutFunc != nil {
+		return am.StageRouteRolloutFunc(ctx, accountID, userID, route, settings)
+	}
+	return nil, status.Errorf(codes.Unimplemented, "method StageRouteRollout is not implemented")
+}
+
+// StagePolicyRollout mocks StagePolicyRollout of the AccountManager interface
+func (am *MockAccountManager) StagePolicyRollout(ctx context.Context, accountID, userID string, policy *types.Policy, settings types.RolloutSettings) (*types.Rollout, error) {
+	if am.StagePolicyRolloutFunc != nil {
+		return am.StagePolicyRolloutFunc(ctx, accountID, userID, policy, settings)
+	}
+	return nil, status.Errorf(codes.Unimplemented, "method StagePolicyRollout is not implemented")
+}
+
+// GetRollout mocks GetRollout of the AccountManager interface
+func (am *MockAccountManager) GetRollout(ctx context.Context, accountID, userID, rolloutID string) (*types.Rollout, error) {
+	if am.GetRolloutFunc != nil {
+		return am.GetRolloutFunc(ctx, accountID, userID, rolloutID)
+	}
+	return nil, status.Errorf(codes.Unimplemented, "method GetRollout is not implemented")
+}
+
+// ListRollouts mocks ListRollouts of the AccountManager interface
+func (am *MockAccountManager) ListRollouts(ctx context.Context, accountID, userID string) ([]*types.Rollout, error) {
+	if am.ListRolloutsFunc != nil {
+		return am.ListRolloutsFunc(ctx, accountID, userID)
+	}
+	return nil, status.Errorf(codes.Unimplemented, "method ListRollouts is not implemented")
+}
+
+// PromoteRollout mocks PromoteRollout of the AccountManager interface
+func (am *MockAccountManager) PromoteRollout(ctx context.Context, accountID, userID, rolloutID string) (*types.Rollout, error) {
+	if am.PromoteRolloutFunc != nil {
+		return am.PromoteRolloutFunc(ctx, accountID, userID, rolloutID)
+	}
+	return nil, status.Errorf(codes.Unimplemented, "method PromoteRollout is not implemented")
+}
+
+// RollbackRollout mocks RollbackRollout of the AccountManager interface
+func (am *MockAccountManager) RollbackRollout(ctx context.Context, accountID, userID, rolloutID string) (*types.Rollout, error) {
+	if am.RollbackRolloutFunc != nil {
+		return am.RollbackRolloutFunc(ctx, accountID, userID, rolloutID)
+	}
+	return nil, status.Errorf(codes.Unimplemented, "method RollbackRollout is not implemented")
+}
+
 // SaveSetupKey mocks SaveSetupKey of the AccountManager interface
 func (am *MockAccountManager) SaveSetupKey(ctx context.Context, accountID string, key *types.SetupKey, userID string) (*types.SetupKey, error) {
 	if am.SaveSetupKeyFunc != nil {
@@ -563,9 +651,9 @@ func (am *MockAccountManager) GetNameServerGroup(ctx context.Context, accountID,
 }
 
 // CreateNameServerGroup mocks CreateNameServerGroup of the AccountManager interface
-func (am *MockAccountManager) CreateNameServerGroup(ctx context.Context, accountID string, name, description string, nameServerList []nbdns.NameServer, groups []string, primary bool, domains []string, enabled bool, userID string, searchDomainsEnabled bool) (*nbdns.NameServerGroup, error) {
+func (am *MockAccountManager) CreateNameServerGroup(ctx context.Context, accountID string, name, description string, nameServerList []nbdns.NameServer, groups []string, primary bool, domains []string, enabled bool, userID string, searchDomainsEnabled bool, routedAnswers nbdns.RoutedAnswers, forwarderPeer string) (*nbdns.NameServerGroup, error) {
 	if am.CreateNameServerGroupFunc != nil {
-		return am.CreateNameServerGroupFunc(ctx, accountID, name, description, nameServerList, groups, primary, domains, enabled, userID, searchDomainsEnabled)
+		return am.CreateNameServerGroupFunc(ctx, accountID, name, description, nameServerList, groups, primary, domains, enabled, userID, searchDomainsEnabled, routedAnswers, forwarderPeer)
 	}
 	return nil, nil
 }
@@ -617,6 +705,14 @@ func (am *MockAccountManager) GetPeers(ctx context.Context, accountID, userID, n
 	return nil, status.Errorf(codes.Unimplemented, "method GetPeers is not implemented")
 }
 
+// ListPeers mocks ListPeers of the AccountManager interface
+func (am *MockAccountManager) ListPeers(ctx context.Context, accountID, userID string, filter store.PeerFilter, opts store.ListOptions) ([]*nbpeer.Peer, string, error) {
+	if am.ListPeersFunc != nil {
+		return am.ListPeersFunc(ctx, accountID, userID, filter, opts)
+	}
+	return nil, "", status.Errorf(codes.Unimplemented, "method ListPeers is not implemented")
+}
+
 // GetDNSDomain mocks GetDNSDomain of the AccountManager interface
 func (am *MockAccountManager) GetDNSDomain() string {
 	if am.GetDNSDomainFunc != nil {
@@ -641,6 +737,14 @@ func (am *MockAccountManager) GetDNSSettings(ctx context.Context, accountID stri
 	return nil, status.Errorf(codes.Unimplemented, "method GetDNSSettings is not implemented")
 }
 
+// GetDNSZone mocks GetDNSZone of the AccountManager interface
+func (am *MockAccountManager) GetDNSZone(ctx context.Context, accountID string, userID string) (nbdns.CustomZone, error) {
+	if am.GetDNSZoneFunc != nil {
+		return am.GetDNSZoneFunc(ctx, accountID, userID)
+	}
+	return nbdns.CustomZone{}, status.Errorf(codes.Unimplemented, "method GetDNSZone is not implemented")
+}
+
 // SaveDNSSettings mocks SaveDNSSettings of the AccountManager interface
 func (am *MockAccountManager) SaveDNSSettings(ctx context.Context, accountID string, userID string, dnsSettingsToSave *types.DNSSettings) error {
 	if am.SaveDNSSettingsFunc != nil {
@@ -871,3 +975,73 @@ func (am *MockAccountManager) GetOwnerInfo(ctx context.Context, accountId string
 	}
 	return nil, status.Errorf(codes.Unimplemented, "method GetOwnerInfo is not implemented")
 }
+
+func (am *MockAccountManager) GetPendingApprovalPeers(ctx context.Context, accountID, userID string) ([]*nbpeer.Peer, error) {
+	if am.GetPendingApprovalPeersFunc != nil {
+		return am.GetPendingApprovalPeersFunc(ctx, accountID, userID)
+	}
+	return nil, status.Errorf(codes.Unimplemented, "method GetPendingApprovalPeers is not implemented")
+}
+
+func (am *MockAccountManager) ApprovePeer(ctx context.Context, accountID, userID, peerID string) (*nbpeer.Peer, error) {
+	if am.ApprovePeerFunc != nil {
+		return am.ApprovePeerFunc(ctx, accountID, userID, peerID)
+	}
+	return nil, status.Errorf(codes.Unimplemented, "method ApprovePeer is not implemented")
+}
+
+func (am *MockAccountManager) GetScheduledPeerDeletions(ctx context.Context, accountID, userID string) ([]*types.ScheduledPeerDeletion, error) {
+	if am.GetScheduledPeerDeletionsFunc != nil {
+		return am.GetScheduledPeerDeletionsFunc(ctx, accountID, userID)
+	}
+	return nil, status.Errorf(codes.Unimplemented, "method GetScheduledPeerDeletions is not implemented")
+}
+
+func (am *MockAccountManager) GetTenants(ctx context.Context, accountID, userID string) ([]*types.Tenant, error) {
+	if am.GetTenantsFunc != nil {
+		return am.GetTenantsFunc(ctx, accountID, userID)
+	}
+	return nil, status.Errorf(codes.Unimplemented, "method GetTenants is not implemented")
+}
+
+func (am *MockAccountManager) CreateTenant(ctx context.Context, accountID, userID, name, ownerUserID string) (*types.Tenant, error) {
+	if am.CreateTenantFunc != nil {
+		return am.CreateTenantFunc(ctx, accountID, userID, name, ownerUserID)
+	}
+	return nil, status.Errorf(codes.Unimplemented, "method CreateTenant is not implemented")
+}
+
+func (am *MockAccountManager) DeleteTenant(ctx context.Context, accountID, userID, tenantAccountID string) error {
+	if am.DeleteTenantFunc != nil {
+		return am.DeleteTenantFunc(ctx, accountID, userID, tenantAccountID)
+	}
+	return status.Errorf(codes.Unimplemented, "method DeleteTenant is not implemented")
+}
+
+func (am *MockAccountManager) GetTenantsUsage(ctx context.Context, accountID, userID string) ([]*types.TenantUsage, error) {
+	if am.GetTenantsUsageFunc != nil {
+		return am.GetTenantsUsageFunc(ctx, accountID, userID)
+	}
+	return nil, status.Errorf(codes.Unimplemented, "method GetTenantsUsage is not implemented")
+}
+
+func (am *MockAccountManager) GetAccountNetwork(ctx context.Context, accountID, userID string) (*types.Network, error) {
+	if am.GetAccountNetworkFunc != nil {
+		return am.GetAccountNetworkFunc(ctx, accountID, userID)
+	}
+	return nil, status.Errorf(codes.Unimplemented, "method GetAccountNetwork is not implemented")
+}
+
+func (am *MockAccountManager) UpdateAccountNetwork(ctx context.Context, accountID, userID string, networkRange net.IPNet, reservedRanges []string, dryRun bool) (*types.Network, []*types.PeerIPMigration, error) {
+	if am.UpdateAccountNetworkFunc != nil {
+		return am.UpdateAccountNetworkFunc(ctx, accountID, userID, networkRange, reservedRanges, dryRun)
+	}
+	return nil, nil, status.Errorf(codes.Unimplemented, "method UpdateAccountNetwork is not implemented")
+}
+
+func (am *MockAccountManager) SetPeerStaticIP(ctx context.Context, accountID, userID, peerID string, ip net.IP) (*nbpeer.Peer, error) {
+	if am.SetPeerStaticIPFunc != nil {
+		return am.SetPeerStaticIPFunc(ctx, accountID, userID, peerID, ip)
+	}
+	return nil, status.Errorf(codes.Unimplemented, "method SetPeerStaticIP is not implemented")
+}
@@ -0,0 +1,250 @@
+package monitors
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rs/xid"
+
+	"github.com/netbirdio/netbird/management/server/account"
+	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/monitors/types"
+	"github.com/netbirdio/netbird/management/server/permissions"
+	"github.com/netbirdio/netbird/management/server/probes"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
+)
+
+const (
+	// DefaultResultsLimit is the number of results returned when the request doesn't limit them
+	DefaultResultsLimit = 100
+	// MaxResultsLimit is the highest number of results returned by a request
+	MaxResultsLimit = 10000
+)
+
+type Manager interface {
+	GetAllMonitors(ctx context.Context, accountID, userID string) ([]*types.Monitor, error)
+	CreateMonitor(ctx context.Context, userID string, monitor *types.Monitor) (*types.Monitor, error)
+	GetMonitor(ctx context.Context, accountID, userID, monitorID string) (*types.Monitor, error)
+	UpdateMonitor(ctx context.Context, userID string, monitor *types.Monitor) (*types.Monitor, error)
+	DeleteMonitor(ctx context.Context, accountID, userID, monitorID string) error
+	// GetMonitorResults returns the results of the monitor since the time, the latest first
+	GetMonitorResults(ctx context.Context, accountID, userID, monitorID string, since time.Time, limit int) ([]*types.Result, error)
+}
+
+type managerImpl struct {
+	store              store.Store
+	permissionsManager permissions.Manager
+	accountManager     account.Manager
+}
+
+type mockManager struct {
+}
+
+func NewManager(store store.Store, permissionsManager permissions.Manager, accountManager account.Manager) Manager {
+	return &managerImpl{
+		store:              store,
+		permissionsManager: permissionsManager,
+		accountManager:     accountManager,
+	}
+}
+
+func (m *managerImpl) GetAllMonitors(ctx context.Context, accountID, userID string) ([]*types.Monitor, error) {
+	if err := m.validatePermissions(ctx, accountID, userID, permissions.Read); err != nil {
+		return nil, err
+	}
+
+	return m.store.GetAccountMonitors(ctx, store.LockingStrengthShare, accountID)
+}
+
+func (m *managerImpl) CreateMonitor(ctx context.Context, userID string, monitor *types.Monitor) (*types.Monitor, error) {
+	if err := m.validatePermissions(ctx, monitor.AccountID, userID, permissions.Write); err != nil {
+		return nil, err
+	}
+
+	monitor.ID = xid.New().String()
+
+	if err := m.validateMonitor(ctx, monitor); err != nil {
+		return nil, err
+	}
+
+	if err := m.store.SaveMonitor(ctx, store.LockingStrengthUpdate, monitor); err != nil {
+		return nil, fmt.Errorf("failed to save monitor: %w", err)
+	}
+
+	m.accountManager.StoreEvent(ctx, userID, monitor.ID, monitor.AccountID, activity.MonitorCreated, monitor.EventMeta())
+
+	return monitor, nil
+}
+
+func (m *managerImpl) GetMonitor(ctx context.Context, accountID, userID, monitorID string) (*types.Monitor, error) {
+	if err := m.validatePermissions(ctx, accountID, userID, permissions.Read); err != nil {
+		return nil, err
+	}
+
+	return m.store.GetMonitorByID(ctx, store.LockingStrengthShare, accountID, monitorID)
+}
+
+func (m *managerImpl) UpdateMonitor(ctx context.Context, userID string, monitor *types.Monitor) (*types.Monitor, error) {
+	if err := m.validatePermissions(ctx, monitor.AccountID, userID, permissions.Write); err != nil {
+		return nil, err
+	}
+
+	unlock := m.store.AcquireWriteLockByUID(ctx, monitor.AccountID)
+	defer unlock()
+
+	if _, err := m.store.GetMonitorByID(ctx, store.LockingStrengthUpdate, monitor.AccountID, monitor.ID); err != nil {
+		return nil, fmt.Errorf("failed to get monitor: %w", err)
+	}
+
+	if err := m.validateMonitor(ctx, monitor); err != nil {
+		return nil, err
+	}
+
+	if err := m.store.SaveMonitor(ctx, store.LockingStrengthUpdate, monitor); err != nil {
+		return nil, fmt.Errorf("failed to save monitor: %w", err)
+	}
+
+	m.accountManager.StoreEvent(ctx, userID, monitor.ID, monitor.AccountID, activity.MonitorUpdated, monitor.EventMeta())
+
+	return monitor, nil
+}
+
+func (m *managerImpl) DeleteMonitor(ctx context.Context, accountID, userID, monitorID string) error {
+	if err := m.validatePermissions(ctx, accountID, userID, permissions.Write); err != nil {
+		return err
+	}
+
+	unlock := m.store.AcquireWriteLockByUID(ctx, accountID)
+	defer unlock()
+
+	monitor, err := m.store.GetMonitorByID(ctx, store.LockingStrengthUpdate, accountID, monitorID)
+	if err != nil {
+		return fmt.Errorf("failed to get monitor: %w", err)
+	}
+
+	if err = m.store.DeleteMonitor(ctx, store.LockingStrengthUpdate, accountID, monitorID); err != nil {
+		return fmt.Errorf("failed to delete monitor: %w", err)
+	}
+
+	m.accountManager.StoreEvent(ctx, userID, monitorID, accountID, activity.MonitorDeleted, monitor.EventMeta())
+
+	return nil
+}
+
+func (m *managerImpl) GetMonitorResults(ctx context.Context, accountID, userID, monitorID string, since time.Time, limit int) ([]*types.Result, error) {
+	if err := m.validatePermissions(ctx, accountID, userID, permissions.Read); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = DefaultResultsLimit
+	}
+	if limit > MaxResultsLimit {
+		return nil, status.Errorf(status.InvalidArgument, "results limit can't exceed %d", MaxResultsLimit)
+	}
+
+	if _, err := m.store.GetMonitorByID(ctx, store.LockingStrengthShare, accountID, monitorID); err != nil {
+		return nil, err
+	}
+
+	return m.store.GetMonitorResults(ctx, store.LockingStrengthShare, accountID, monitorID, since, limit)
+}
+
+func (m *managerImpl) validatePermissions(ctx context.Context, accountID, userID string, operation permissions.Operation) error {
+	ok, err := m.permissionsManager.ValidateUserPermissions(ctx, accountID, userID, permissions.Monitors, operation)
+	if err != nil {
+		return status.NewPermissionValidationError(err)
+	}
+	if !ok {
+		return status.NewPermissionDeniedError()
+	}
+	return nil
+}
+
+// validateMonitor checks that the monitor runs on a peer of the account and defines the target of its type
+func (m *managerImpl) validateMonitor(ctx context.Context, monitor *types.Monitor) error {
+	if strings.TrimSpace(monitor.Name) == "" {
+		return status.Errorf(status.InvalidArgument, "monitor name can't be empty")
+	}
+
+	if _, err := m.store.GetPeerByID(ctx, store.LockingStrengthShare, monitor.AccountID, monitor.PeerID); err != nil {
+		return err
+	}
+
+	if err := validateTarget(monitor); err != nil {
+		return err
+	}
+
+	if monitor.Interval < types.MinInterval || monitor.Interval > types.MaxInterval {
+		return status.Errorf(status.InvalidArgument, "monitor interval has to be between %s and %s", types.MinInterval, types.MaxInterval)
+	}
+
+	if monitor.Timeout <= 0 || monitor.Timeout > probes.MaxTimeout || monitor.Timeout >= monitor.Interval {
+		return status.Errorf(status.InvalidArgument, "monitor timeout has to be between 0 and %s and shorter than the interval", probes.MaxTimeout)
+	}
+
+	return nil
+}
+
+func validateTarget(monitor *types.Monitor) error {
+	switch monitor.Type {
+	case types.TypeHTTP:
+		u, err := url.Parse(monitor.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return status.Errorf(status.InvalidArgument, "http monitor url must be a valid http or https URL")
+		}
+		if monitor.Address != "" || monitor.Port != 0 {
+			return status.Errorf(status.InvalidArgument, "http monitor can't define an address or a port")
+		}
+	case types.TypeTCP, types.TypeICMP:
+		if _, err := netip.ParseAddr(monitor.Address); err != nil {
+			return status.Errorf(status.InvalidArgument, "%s monitor address must be a valid IP address", monitor.Type)
+		}
+		if monitor.URL != "" {
+			return status.Errorf(status.InvalidArgument, "%s monitor can't define a url", monitor.Type)
+		}
+		if monitor.Type == types.TypeTCP && monitor.Port == 0 {
+			return status.Errorf(status.InvalidArgument, "tcp monitor has to define a port")
+		}
+		if monitor.Type == types.TypeICMP && monitor.Port != 0 {
+			return status.Errorf(status.InvalidArgument, "icmp monitor can't define a port")
+		}
+	default:
+		return status.Errorf(status.InvalidArgument, "unknown monitor type %s", monitor.Type)
+	}
+
+	return nil
+}
+
+func NewManagerMock() Manager {
+	return &mockManager{}
+}
+
+func (m *mockManager) GetAllMonitors(ctx context.Context, accountID, userID string) ([]*types.Monitor, error) {
+	return []*types.Monitor{}, nil
+}
+
+func (m *mockManager) CreateMonitor(ctx context.Context, userID string, monitor *types.Monitor) (*types.Monitor, error) {
+	return monitor, nil
+}
+
+func (m *mockManager) GetMonitor(ctx context.Context, accountID, userID, monitorID string) (*types.Monitor, error) {
+	return &types.Monitor{}, nil
+}
+
+func (m *mockManager) UpdateMonitor(ctx context.Context, userID string, monitor *types.Monitor) (*types.Monitor, error) {
+	return monitor, nil
+}
+
+func (m *mockManager) DeleteMonitor(ctx context.Context, accountID, userID, monitorID string) error {
+	return nil
+}
+
+func (m *mockManager) GetMonitorResults(ctx context.Context, accountID, userID, monitorID string, since time.Time, limit int) ([]*types.Result, error) {
+	return []*types.Result{}, nil
+}
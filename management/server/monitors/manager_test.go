@@ -0,0 +1,147 @@
+package monitors
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/management/proto"
+	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/mock_server"
+	"github.com/netbirdio/netbird/management/server/monitors/types"
+	"github.com/netbirdio/netbird/management/server/permissions"
+	"github.com/netbirdio/netbird/management/server/probes"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
+)
+
+const (
+	testAccountID = "bf1c8084-ba50-4ce7-9439-34653001fc3b"
+	testAdminID   = "edafee4e-63fb-11ec-90d6-0242ac120003"
+	testRegularID = "f4f6d672-63fb-11ec-90d6-0242ac120003"
+	testPeerID    = "ct286bi7qv930dsrrug0"
+)
+
+func newTestStore(t *testing.T) store.Store {
+	t.Helper()
+
+	s, cleanUp, err := store.NewTestStoreFromSQL(context.Background(), "../testdata/store.sql", t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(cleanUp)
+
+	return s
+}
+
+func TestManager_ValidatesMonitors(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+	manager := NewManager(s, permissions.NewManager(s), &mock_server.MockAccountManager{})
+
+	newMonitor := func(monitorType types.Type, address string, port uint16, url string, interval time.Duration) *types.Monitor {
+		return types.NewMonitor(testAccountID, "monitor", testPeerID, monitorType, address, port, url, interval, types.DefaultTimeout, true)
+	}
+
+	_, err := manager.CreateMonitor(ctx, testRegularID, newMonitor(types.TypeICMP, "10.0.0.1", 0, "", time.Minute))
+	sErr, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, status.PermissionDenied, sErr.Type())
+
+	invalid := map[string]*types.Monitor{
+		"unknown type":             newMonitor("udp", "10.0.0.1", 53, "", time.Minute),
+		"tcp monitor without port": newMonitor(types.TypeTCP, "10.0.0.1", 0, "", time.Minute),
+		"icmp monitor with port":   newMonitor(types.TypeICMP, "10.0.0.1", 22, "", time.Minute),
+		"invalid address":          newMonitor(types.TypeICMP, "router.local", 0, "", time.Minute),
+		"invalid url":              newMonitor(types.TypeHTTP, "", 0, "ftp://10.0.0.1", time.Minute),
+		"too short interval":       newMonitor(types.TypeICMP, "10.0.0.1", 0, "", time.Second),
+		"timeout above interval":   newMonitor(types.TypeICMP, "10.0.0.1", 0, "", types.MinInterval),
+		"unknown peer":             types.NewMonitor(testAccountID, "monitor", "unknown", types.TypeICMP, "10.0.0.1", 0, "", time.Minute, types.DefaultTimeout, true),
+	}
+	invalid["timeout above interval"].Timeout = types.MinInterval
+
+	for name, monitor := range invalid {
+		_, err = manager.CreateMonitor(ctx, testAdminID, monitor)
+		assert.Error(t, err, name)
+	}
+
+	monitor, err := manager.CreateMonitor(ctx, testAdminID, newMonitor(types.TypeHTTP, "", 0, "http://10.0.0.1:8080/health", time.Minute))
+	require.NoError(t, err)
+
+	monitors, err := manager.GetAllMonitors(ctx, testAccountID, testAdminID)
+	require.NoError(t, err)
+	require.Len(t, monitors, 1)
+	assert.Equal(t, monitor.ID, monitors[0].ID)
+
+	_, err = manager.GetMonitorResults(ctx, testAccountID, testAdminID, monitor.ID, time.Time{}, MaxResultsLimit+1)
+	assert.Error(t, err, "results limit can't be exceeded")
+
+	require.NoError(t, manager.DeleteMonitor(ctx, testAccountID, testAdminID, monitor.ID))
+	_, err = manager.GetMonitor(ctx, testAccountID, testAdminID, monitor.ID)
+	sErr, ok = status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, status.NotFound, sErr.Type())
+}
+
+type testProbesManager struct {
+	probes.Manager
+	results []*probes.Result
+}
+
+func (m *testProbesManager) Probe(_ context.Context, _, _ string, target *probes.Target) (*probes.Result, error) {
+	result := m.results[0]
+	m.results = m.results[1:]
+	return result, nil
+}
+
+func (m *testProbesManager) ReportResult(context.Context, string, *proto.ProbeResult) error {
+	return nil
+}
+
+type testConnectedPeers struct{}
+
+func (testConnectedPeers) HasChannel(string) bool {
+	return true
+}
+
+func TestScheduler_RecordsResults(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+
+	var mu sync.Mutex
+	var events []activity.Activity
+	accountManager := &mock_server.MockAccountManager{
+		StoreEventFunc: func(_ context.Context, _, _, _ string, activityID activity.ActivityDescriber, _ map[string]any) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, activityID.(activity.Activity))
+		},
+	}
+
+	probesManager := &testProbesManager{results: []*probes.Result{
+		{Reachable: true, Latency: 10 * time.Millisecond},
+		{Error: "connection refused"},
+		{Error: "connection refused"},
+		{Reachable: true, Latency: 20 * time.Millisecond},
+	}}
+
+	scheduler, err := NewScheduler(s, testConnectedPeers{}, probesManager, accountManager, nil)
+	require.NoError(t, err)
+
+	monitor := types.NewMonitor(testAccountID, "monitor", testPeerID, types.TypeTCP, "10.0.0.1", 443, "", time.Minute, types.DefaultTimeout, true)
+	require.NoError(t, s.SaveMonitor(ctx, store.LockingStrengthUpdate, monitor))
+
+	for range probesManager.results {
+		scheduler.check(ctx, monitor)
+	}
+
+	results, err := s.GetMonitorResults(ctx, store.LockingStrengthShare, testAccountID, monitor.ID, time.Time{}, DefaultResultsLimit)
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+	assert.True(t, results[0].Reachable, "latest result should come first")
+	assert.Equal(t, 20*time.Millisecond, results[0].Latency)
+
+	assert.Equal(t, []activity.Activity{activity.MonitorFailed, activity.MonitorRecovered}, events)
+}
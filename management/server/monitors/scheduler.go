@@ -0,0 +1,276 @@
+package monitors
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/netbirdio/netbird/management/server/account"
+	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/monitors/types"
+	"github.com/netbirdio/netbird/management/server/probes"
+	"github.com/netbirdio/netbird/management/server/store"
+)
+
+const (
+	// ResultRetention is the time the results of the monitors are kept
+	ResultRetention = 7 * 24 * time.Hour
+
+	schedulerTick = 5 * time.Second
+	pruneInterval = time.Hour
+)
+
+// Scheduler runs the checks of the enabled monitors at their interval, records the results and reports the
+// monitors becoming unreachable or recovering as activity events.
+// With several management nodes every node checks the monitors of the peers connected to it.
+type Scheduler struct {
+	store          store.Store
+	peers          ConnectedPeers
+	probesManager  probes.Manager
+	accountManager account.Manager
+	metrics        *schedulerMetrics
+
+	mu sync.Mutex
+	// lastRun is the start of the last check of the monitors
+	lastRun map[string]time.Time
+	// running holds the monitors with a check in progress
+	running map[string]struct{}
+	// failing holds the monitors whose last check failed
+	failing map[string]struct{}
+}
+
+// ConnectedPeers tells whether a peer is connected to this management node
+type ConnectedPeers interface {
+	HasChannel(peerID string) bool
+}
+
+type schedulerMetrics struct {
+	checks  metric.Int64Counter
+	failed  metric.Int64Counter
+	latency metric.Float64Histogram
+}
+
+// NewScheduler creates a Scheduler, the metrics of the checks are recorded with the meter if it isn't nil
+func NewScheduler(store store.Store, peers ConnectedPeers, probesManager probes.Manager, accountManager account.Manager, meter metric.Meter) (*Scheduler, error) {
+	s := &Scheduler{
+		store:          store,
+		peers:          peers,
+		probesManager:  probesManager,
+		accountManager: accountManager,
+		lastRun:        make(map[string]time.Time),
+		running:        make(map[string]struct{}),
+		failing:        make(map[string]struct{}),
+	}
+
+	if meter != nil {
+		metrics, err := newSchedulerMetrics(meter)
+		if err != nil {
+			return nil, err
+		}
+		s.metrics = metrics
+	}
+
+	return s, nil
+}
+
+func newSchedulerMetrics(meter metric.Meter) (*schedulerMetrics, error) {
+	checks, err := meter.Int64Counter("management.monitor.check.counter",
+		metric.WithUnit("1"),
+		metric.WithDescription("Number of checks run by the monitors"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	failed, err := meter.Int64Counter("management.monitor.check.failed.counter",
+		metric.WithUnit("1"),
+		metric.WithDescription("Number of checks of the monitors that found their target unreachable"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	latency, err := meter.Float64Histogram("management.monitor.check.latency.ms",
+		metric.WithUnit("milliseconds"),
+		metric.WithDescription("Latency to the targets of the monitors measured by their peers"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schedulerMetrics{checks: checks, failed: failed, latency: latency}, nil
+}
+
+// Start runs the scheduler until the context is done
+func (s *Scheduler) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(schedulerTick)
+		defer ticker.Stop()
+
+		lastPrune := time.Time{}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				s.runDue(ctx, now)
+
+				if now.Sub(lastPrune) >= pruneInterval {
+					lastPrune = now
+					if err := s.store.DeleteMonitorResultsBefore(ctx, store.LockingStrengthUpdate, now.Add(-ResultRetention)); err != nil {
+						log.WithContext(ctx).Errorf("failed to prune monitor results: %v", err)
+					}
+				}
+			}
+		}
+	}()
+}
+
+// runDue starts the checks of the monitors whose interval elapsed since their last check
+func (s *Scheduler) runDue(ctx context.Context, now time.Time) {
+	monitors, err := s.store.GetEnabledMonitors(ctx, store.LockingStrengthShare)
+	if err != nil {
+		log.WithContext(ctx).Errorf("failed to get monitors: %v", err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	enabled := make(map[string]struct{}, len(monitors))
+	for _, monitor := range monitors {
+		enabled[monitor.ID] = struct{}{}
+
+		if _, ok := s.running[monitor.ID]; ok {
+			continue
+		}
+		if now.Sub(s.lastRun[monitor.ID]) < monitor.Interval {
+			continue
+		}
+
+		s.lastRun[monitor.ID] = now
+		s.running[monitor.ID] = struct{}{}
+		go s.check(ctx, monitor)
+	}
+
+	// forget the deleted and disabled monitors
+	for id := range s.lastRun {
+		if _, ok := enabled[id]; !ok {
+			delete(s.lastRun, id)
+			delete(s.failing, id)
+		}
+	}
+}
+
+// check runs the check of the monitor on its peer and records the result
+func (s *Scheduler) check(ctx context.Context, monitor *types.Monitor) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.running, monitor.ID)
+		s.mu.Unlock()
+	}()
+
+	result, ok := s.probe(ctx, monitor)
+	if !ok {
+		return
+	}
+
+	if err := s.store.SaveMonitorResult(ctx, store.LockingStrengthUpdate, result); err != nil {
+		log.WithContext(ctx).Errorf("failed to save the result of monitor %s: %v", monitor.ID, err)
+	}
+
+	s.recordMetrics(ctx, monitor, result)
+	s.updateState(ctx, monitor, result)
+}
+
+// probe asks the peer of the monitor to check its target. False is returned if the peer is connected to another
+// management node, which checks the monitor instead.
+func (s *Scheduler) probe(ctx context.Context, monitor *types.Monitor) (*types.Result, bool) {
+	result := &types.Result{
+		AccountID: monitor.AccountID,
+		MonitorID: monitor.ID,
+		Timestamp: time.Now().UTC(),
+	}
+
+	if !s.peers.HasChannel(monitor.PeerID) {
+		peer, err := s.store.GetPeerByID(ctx, store.LockingStrengthShare, monitor.AccountID, monitor.PeerID)
+		if err != nil {
+			log.WithContext(ctx).Errorf("failed to get the peer of monitor %s: %v", monitor.ID, err)
+			return nil, false
+		}
+		if peer.Status != nil && peer.Status.Connected {
+			return nil, false
+		}
+
+		result.Error = "peer is not connected"
+		return result, true
+	}
+
+	target := &probes.Target{Timeout: monitor.Timeout}
+	switch monitor.Type {
+	case types.TypeHTTP:
+		target.URL = monitor.URL
+	default:
+		target.Address = monitor.Address
+		target.Port = monitor.Port
+	}
+
+	probeResult, err := s.probesManager.Probe(ctx, monitor.AccountID, monitor.PeerID, target)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, false
+		}
+		result.Error = err.Error()
+		return result, true
+	}
+
+	result.Reachable = probeResult.Reachable
+	result.Latency = probeResult.Latency
+	result.HTTPStatus = probeResult.HTTPStatus
+	result.Error = probeResult.Error
+	return result, true
+}
+
+func (s *Scheduler) recordMetrics(ctx context.Context, monitor *types.Monitor, result *types.Result) {
+	if s.metrics == nil {
+		return
+	}
+
+	attrs := metric.WithAttributes(
+		attribute.String("account_id", monitor.AccountID),
+		attribute.String("monitor_id", monitor.ID),
+		attribute.String("type", string(monitor.Type)),
+	)
+
+	s.metrics.checks.Add(ctx, 1, attrs)
+	if !result.Reachable {
+		s.metrics.failed.Add(ctx, 1, attrs)
+		return
+	}
+	s.metrics.latency.Record(ctx, float64(result.Latency.Microseconds())/1000, attrs)
+}
+
+// updateState stores an activity event when the target of the monitor becomes unreachable or recovers
+func (s *Scheduler) updateState(ctx context.Context, monitor *types.Monitor, result *types.Result) {
+	s.mu.Lock()
+	_, wasFailing := s.failing[monitor.ID]
+	if result.Reachable {
+		delete(s.failing, monitor.ID)
+	} else {
+		s.failing[monitor.ID] = struct{}{}
+	}
+	s.mu.Unlock()
+
+	meta := monitor.EventMeta()
+	switch {
+	case !result.Reachable && !wasFailing:
+		meta["error"] = result.Error
+		s.accountManager.StoreEvent(ctx, activity.SystemInitiator, monitor.ID, monitor.AccountID, activity.MonitorFailed, meta)
+	case result.Reachable && wasFailing:
+		s.accountManager.StoreEvent(ctx, activity.SystemInitiator, monitor.ID, monitor.AccountID, activity.MonitorRecovered, meta)
+	}
+}
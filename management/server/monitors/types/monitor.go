@@ -0,0 +1,187 @@
+package types
+
+import (
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/rs/xid"
+
+	"github.com/netbirdio/netbird/management/server/http/api"
+)
+
+// Type of the check a monitor runs
+type Type string
+
+const (
+	// TypeTCP connects to the port of the address
+	TypeTCP Type = "tcp"
+	// TypeHTTP requests the URL with an HTTP GET, a server error status fails the check
+	TypeHTTP Type = "http"
+	// TypeICMP pings the address
+	TypeICMP Type = "icmp"
+)
+
+const (
+	// DefaultInterval is the interval of the monitors that don't define it
+	DefaultInterval = time.Minute
+	// MinInterval is the shortest interval a monitor can define
+	MinInterval = 10 * time.Second
+	// MaxInterval is the longest interval a monitor can define
+	MaxInterval = 24 * time.Hour
+	// DefaultTimeout is the check timeout of the monitors that don't define it
+	DefaultTimeout = 5 * time.Second
+)
+
+// Monitor periodically asks a peer, e.g. a routing peer, to check a target across the network and records the results
+type Monitor struct {
+	ID        string `gorm:"primaryKey"`
+	AccountID string `gorm:"index"`
+	Name      string
+	// PeerID is the peer running the checks
+	PeerID string
+	Type   Type
+	// Address is the IP address checked by the TCP and ICMP monitors
+	Address string
+	// Port is the TCP port connected to by the TCP monitors
+	Port uint16
+	// URL is requested by the HTTP monitors, encrypted at rest as it may embed credentials
+	URL      string `gorm:"serializer:encrypted"`
+	Interval time.Duration
+	Timeout  time.Duration
+	Enabled  bool
+}
+
+// Result of a check of a monitor, the results of a monitor form its time series
+type Result struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement"`
+	AccountID string    `gorm:"index"`
+	MonitorID string    `gorm:"index:idx_monitor_results_monitor_time"`
+	Timestamp time.Time `gorm:"index:idx_monitor_results_monitor_time"`
+	Reachable bool
+	Latency   time.Duration
+	// HTTPStatus is the response status code of the HTTP monitors
+	HTTPStatus int
+	Error      string
+}
+
+// TableName returns the table of the monitor results
+func (Result) TableName() string {
+	return "monitor_results"
+}
+
+func NewMonitor(accountID, name, peerID string, monitorType Type, address string, port uint16, url string, interval, timeout time.Duration, enabled bool) *Monitor {
+	return &Monitor{
+		ID:        xid.New().String(),
+		AccountID: accountID,
+		Name:      name,
+		PeerID:    peerID,
+		Type:      monitorType,
+		Address:   address,
+		Port:      port,
+		URL:       url,
+		Interval:  interval,
+		Timeout:   timeout,
+		Enabled:   enabled,
+	}
+}
+
+// ToAPIResponse converts the monitor to its API representation
+func (m *Monitor) ToAPIResponse() *api.Monitor {
+	monitor := &api.Monitor{
+		Id:              m.ID,
+		Name:            m.Name,
+		PeerId:          m.PeerID,
+		Type:            api.MonitorType(m.Type),
+		IntervalSeconds: int(m.Interval / time.Second),
+		TimeoutSeconds:  int(m.Timeout / time.Second),
+		Enabled:         m.Enabled,
+	}
+
+	if m.Address != "" {
+		monitor.Address = &m.Address
+	}
+	if m.Port != 0 {
+		port := int(m.Port)
+		monitor.Port = &port
+	}
+	if m.URL != "" {
+		monitor.Url = &m.URL
+	}
+
+	return monitor
+}
+
+// FromAPIRequest updates the monitor with the request
+func (m *Monitor) FromAPIRequest(req *api.MonitorRequest) {
+	m.Name = req.Name
+	m.PeerID = req.PeerId
+	m.Type = Type(req.Type)
+	m.Enabled = req.Enabled
+
+	m.Address = ""
+	if req.Address != nil {
+		m.Address = *req.Address
+	}
+
+	m.Port = 0
+	if req.Port != nil && *req.Port > 0 && *req.Port <= 65535 {
+		m.Port = uint16(*req.Port)
+	}
+
+	m.URL = ""
+	if req.Url != nil {
+		m.URL = *req.Url
+	}
+
+	m.Interval = DefaultInterval
+	if req.IntervalSeconds != nil {
+		m.Interval = time.Duration(*req.IntervalSeconds) * time.Second
+	}
+
+	m.Timeout = DefaultTimeout
+	if req.TimeoutSeconds != nil {
+		m.Timeout = time.Duration(*req.TimeoutSeconds) * time.Second
+	}
+}
+
+// Copy returns a copy of the monitor
+func (m *Monitor) Copy() *Monitor {
+	monitor := *m
+	return &monitor
+}
+
+// EventMeta returns the activity event meta of the monitor
+func (m *Monitor) EventMeta() map[string]any {
+	return map[string]any{"name": m.Name, "type": string(m.Type), "target": m.Target()}
+}
+
+// Target returns the checked address, address and port or URL
+func (m *Monitor) Target() string {
+	switch m.Type {
+	case TypeHTTP:
+		return m.URL
+	case TypeTCP:
+		return net.JoinHostPort(m.Address, strconv.Itoa(int(m.Port)))
+	default:
+		return m.Address
+	}
+}
+
+// ToAPIResponse converts the result to its API representation
+func (r *Result) ToAPIResponse() *api.MonitorResult {
+	result := &api.MonitorResult{
+		Timestamp: r.Timestamp,
+		Reachable: r.Reachable,
+		LatencyMs: float64(r.Latency.Microseconds()) / 1000,
+	}
+
+	if r.HTTPStatus != 0 {
+		result.HttpStatus = &r.HTTPStatus
+	}
+	if r.Error != "" {
+		result.Error = &r.Error
+	}
+
+	return result
+}
@@ -0,0 +1,231 @@
+package mtls
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/util"
+	nbgrpc "github.com/netbirdio/netbird/util/grpc"
+)
+
+const (
+	// DefaultCertificateValidity is the validity of the issued client certificates if none is configured
+	DefaultCertificateValidity = 90 * 24 * time.Hour
+
+	caValidity     = 10 * 365 * 24 * time.Hour
+	caCertFileName = "client-ca.crt"
+	caKeyFileName  = "client-ca.key"
+	caCommonName   = "NetBird Client CA"
+	// clockSkew backdates the issued certificates, so peers with a slightly wrong clock accept them
+	clockSkew = 5 * time.Minute
+)
+
+var (
+	// ErrCertificateRequired is returned when a peer without a valid client certificate calls a protected method
+	ErrCertificateRequired = errors.New("client certificate is required")
+	// ErrCertificateMismatch is returned when the client certificate was issued to another peer
+	ErrCertificateMismatch = errors.New("client certificate wasn't issued to the peer")
+)
+
+// Config of the client certificates issued to the peers for mutual TLS with the management and signal services
+type Config struct {
+	// CACertFile and CAKeyFile are the PEM files of the CA signing the client certificates. If they are empty a CA is
+	// generated and stored in the data directory, the signal service has to trust its certificate.
+	CACertFile string
+	CAKeyFile  string
+	// CertificateValidity is the validity of the issued certificates, the peers renew them on login
+	CertificateValidity util.Duration
+	// Required rejects the peers without a valid client certificate. Otherwise the certificates are issued and
+	// verified when presented, which allows rolling them out to the peers before enforcing them.
+	Required bool
+}
+
+// Authority issues the client certificates of the peers and verifies them on the gRPC calls
+type Authority struct {
+	cert     *x509.Certificate
+	key      crypto.Signer
+	pool     *x509.CertPool
+	validity time.Duration
+	required bool
+}
+
+// NewAuthority loads the CA of the config, or the one in the data directory which is generated on the first run
+func NewAuthority(ctx context.Context, config *Config, dataDir string) (*Authority, error) {
+	certFile, keyFile := config.CACertFile, config.CAKeyFile
+	if certFile == "" && keyFile == "" {
+		certFile = filepath.Join(dataDir, caCertFileName)
+		keyFile = filepath.Join(dataDir, caKeyFileName)
+		if err := generateCAIfMissing(ctx, certFile, keyFile); err != nil {
+			return nil, err
+		}
+	}
+
+	keyPair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load client CA: %w", err)
+	}
+
+	cert, err := x509.ParseCertificate(keyPair.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("parse client CA certificate: %w", err)
+	}
+	if !cert.IsCA {
+		return nil, fmt.Errorf("client CA certificate %s isn't a CA", certFile)
+	}
+
+	key, ok := keyPair.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported client CA key type %T", keyPair.PrivateKey)
+	}
+
+	validity := config.CertificateValidity.Duration
+	if validity <= 0 {
+		validity = DefaultCertificateValidity
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+
+	return &Authority{
+		cert:     cert,
+		key:      key,
+		pool:     pool,
+		validity: validity,
+		required: config.Required,
+	}, nil
+}
+
+// ServerTLSConfig returns a copy of the TLS config of the server requesting and verifying the client certificates.
+// They are verified if presented only, the login of the peers without a certificate has to succeed for them to get one.
+func (a *Authority) ServerTLSConfig(config *tls.Config) *tls.Config {
+	config = config.Clone()
+	config.ClientAuth = tls.VerifyClientCertIfGiven
+	config.ClientCAs = a.pool
+	return config
+}
+
+// Sign issues a client certificate of the certificate request (DER) to the peer, the common name of the request has
+// to be the WireGuard public key of the peer. The certificate is returned in DER.
+func (a *Authority) Sign(csrDER []byte, peerKey string) ([]byte, error) {
+	csr, err := x509.ParseCertificateRequest(csrDER)
+	if err != nil {
+		return nil, fmt.Errorf("parse certificate request: %w", err)
+	}
+	if err = csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("invalid certificate request signature: %w", err)
+	}
+	if csr.Subject.CommonName != peerKey {
+		return nil, fmt.Errorf("certificate request common name %s doesn't match the peer key", csr.Subject.CommonName)
+	}
+
+	serial, err := serialNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: peerKey},
+		NotBefore:    now.Add(-clockSkew),
+		NotAfter:     now.Add(a.validity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	cert, err := x509.CreateCertificate(rand.Reader, template, a.cert, csr.PublicKey, a.key)
+	if err != nil {
+		return nil, fmt.Errorf("create client certificate: %w", err)
+	}
+
+	return cert, nil
+}
+
+// VerifyPeer checks the client certificate of the gRPC call was issued to the peer. A call without a certificate is
+// accepted unless the certificates are required.
+func (a *Authority) VerifyPeer(ctx context.Context, peerKey string) error {
+	commonName, ok := nbgrpc.VerifiedClientCommonName(ctx)
+	if !ok {
+		if a.required {
+			return ErrCertificateRequired
+		}
+		return nil
+	}
+
+	if commonName != peerKey {
+		return ErrCertificateMismatch
+	}
+
+	return nil
+}
+
+func generateCAIfMissing(ctx context.Context, certFile, keyFile string) error {
+	if _, err := os.Stat(certFile); err == nil {
+		return nil
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fmt.Errorf("generate client CA key: %w", err)
+	}
+
+	serial, err := serialNumber()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: caCommonName},
+		NotBefore:             now.Add(-clockSkew),
+		NotAfter:              now.Add(caValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return fmt.Errorf("create client CA certificate: %w", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("marshal client CA key: %w", err)
+	}
+
+	if err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		return fmt.Errorf("write client CA key: %w", err)
+	}
+	if err = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}), 0644); err != nil {
+		return fmt.Errorf("write client CA certificate: %w", err)
+	}
+
+	log.WithContext(ctx).Infof("generated the client CA %s, configure it as the client CA of the signal service", certFile)
+
+	return nil
+}
+
+func serialNumber() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("generate serial number: %w", err)
+	}
+	return serial, nil
+}
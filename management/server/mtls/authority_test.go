@@ -0,0 +1,84 @@
+package mtls
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+
+	"github.com/netbirdio/netbird/util"
+	nbgrpc "github.com/netbirdio/netbird/util/grpc"
+)
+
+const testPeerKey = "RlSy2vzoG2HyMBTUImXOiVhCBiiBa5qD5xzMxkiFDW4="
+
+func verifiedContext(t *testing.T, certDER []byte) context.Context {
+	t.Helper()
+
+	cert, err := x509.ParseCertificate(certDER)
+	require.NoError(t, err)
+
+	return peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}},
+	})
+}
+
+func TestAuthority_Sign(t *testing.T) {
+	dataDir := t.TempDir()
+	config := &Config{CertificateValidity: util.Duration{Duration: time.Hour}, Required: true}
+
+	authority, err := NewAuthority(context.Background(), config, dataDir)
+	require.NoError(t, err)
+
+	clientCert := nbgrpc.NewClientCertificate()
+	assert.True(t, clientCert.NeedsRenewal(), "missing certificate should be requested")
+
+	csr, err := clientCert.NewRequest(testPeerKey)
+	require.NoError(t, err)
+
+	_, err = authority.Sign(csr, "another-peer-key")
+	require.Error(t, err, "certificate of another peer shouldn't be issued")
+
+	certDER, err := authority.Sign(csr, testPeerKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(certDER)
+	require.NoError(t, err)
+	assert.Equal(t, testPeerKey, cert.Subject.CommonName)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), cert.NotAfter, time.Minute)
+
+	_, err = cert.Verify(x509.VerifyOptions{Roots: authority.pool, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+	require.NoError(t, err)
+
+	require.NoError(t, clientCert.Accept(certDER))
+	assert.False(t, clientCert.NeedsRenewal())
+
+	reloaded, err := NewAuthority(context.Background(), config, dataDir)
+	require.NoError(t, err)
+	assert.True(t, reloaded.cert.Equal(authority.cert), "generated CA should be reused")
+}
+
+func TestAuthority_VerifyPeer(t *testing.T) {
+	authority, err := NewAuthority(context.Background(), &Config{}, t.TempDir())
+	require.NoError(t, err)
+
+	csr, err := nbgrpc.NewClientCertificate().NewRequest(testPeerKey)
+	require.NoError(t, err)
+	certDER, err := authority.Sign(csr, testPeerKey)
+	require.NoError(t, err)
+
+	ctx := verifiedContext(t, certDER)
+
+	assert.NoError(t, authority.VerifyPeer(ctx, testPeerKey))
+	assert.ErrorIs(t, authority.VerifyPeer(ctx, "another-peer-key"), ErrCertificateMismatch)
+	assert.NoError(t, authority.VerifyPeer(context.Background(), testPeerKey), "certificate should be optional")
+
+	authority.required = true
+	assert.ErrorIs(t, authority.VerifyPeer(context.Background(), testPeerKey), ErrCertificateRequired)
+}
@@ -11,6 +11,7 @@ import (
 
 	nbdns "github.com/netbirdio/netbird/dns"
 	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/permissions"
 	"github.com/netbirdio/netbird/management/server/status"
 	"github.com/netbirdio/netbird/management/server/store"
 	"github.com/netbirdio/netbird/management/server/types"
@@ -29,7 +30,7 @@ func (am *DefaultAccountManager) GetNameServerGroup(ctx context.Context, account
 		return nil, err
 	}
 
-	if user.IsRegularUser() {
+	if user.IsRegularUser() && !am.hasCustomRolePermission(ctx, accountID, user, permissions.Nameservers, permissions.Read) {
 		return nil, status.NewAdminPermissionError()
 	}
 
@@ -37,7 +38,7 @@ func (am *DefaultAccountManager) GetNameServerGroup(ctx context.Context, account
 }
 
 // CreateNameServerGroup creates and saves a new nameserver group
-func (am *DefaultAccountManager) CreateNameServerGroup(ctx context.Context, accountID string, name, description string, nameServerList []nbdns.NameServer, groups []string, primary bool, domains []string, enabled bool, userID string, searchDomainEnabled bool) (*nbdns.NameServerGroup, error) {
+func (am *DefaultAccountManager) CreateNameServerGroup(ctx context.Context, accountID string, name, description string, nameServerList []nbdns.NameServer, groups []string, primary bool, domains []string, enabled bool, userID string, searchDomainEnabled bool, routedAnswers nbdns.RoutedAnswers, forwarderPeer string) (*nbdns.NameServerGroup, error) {
 	unlock := am.Store.AcquireWriteLockByUID(ctx, accountID)
 	defer unlock()
 
@@ -61,6 +62,8 @@ func (am *DefaultAccountManager) CreateNameServerGroup(ctx context.Context, acco
 		Primary:              primary,
 		Domains:              domains,
 		SearchDomainsEnabled: searchDomainEnabled,
+		RoutedAnswers:        routedAnswers,
+		ForwarderPeer:        forwarderPeer,
 	}
 
 	var updateAccountPeers bool
@@ -207,7 +210,7 @@ func (am *DefaultAccountManager) ListNameServerGroups(ctx context.Context, accou
 		return nil, err
 	}
 
-	if user.IsRegularUser() {
+	if user.IsRegularUser() && !am.hasCustomRolePermission(ctx, accountID, user, permissions.Nameservers, permissions.Read) {
 		return nil, status.NewAdminPermissionError()
 	}
 
@@ -225,6 +228,15 @@ func validateNameServerGroup(ctx context.Context, transaction store.Store, accou
 		return err
 	}
 
+	if !nameserverGroup.RoutedAnswers.IsValid() {
+		return status.Errorf(status.InvalidArgument, "nameserver group routed answers should be %q, %q or empty, got %q",
+			nbdns.RoutedAnswersPrefer, nbdns.RoutedAnswersOnly, nameserverGroup.RoutedAnswers)
+	}
+
+	if err = validateForwarderPeer(ctx, transaction, accountID, nameserverGroup); err != nil {
+		return err
+	}
+
 	nsServerGroups, err := transaction.GetAccountNameServerGroups(ctx, store.LockingStrengthShare, accountID)
 	if err != nil {
 		return err
@@ -243,6 +255,20 @@ func validateNameServerGroup(ctx context.Context, transaction store.Store, accou
 	return validateGroups(nameserverGroup.Groups, groups)
 }
 
+// validateForwarderPeer checks that the forwarder peer exists, it only forwards the match domains of the group
+func validateForwarderPeer(ctx context.Context, transaction store.Store, accountID string, nameserverGroup *nbdns.NameServerGroup) error {
+	if nameserverGroup.ForwarderPeer == "" {
+		return nil
+	}
+
+	if nameserverGroup.Primary {
+		return status.Errorf(status.InvalidArgument, "nameserver group with a forwarder peer can't be primary, the forwarder only serves the match domains")
+	}
+
+	_, err := transaction.GetPeerByID(ctx, store.LockingStrengthShare, accountID, nameserverGroup.ForwarderPeer)
+	return err
+}
+
 // areNameServerGroupChangesAffectPeers checks if the changes in the nameserver group affect the peers.
 func areNameServerGroupChangesAffectPeers(ctx context.Context, transaction store.Store, newNSGroup, oldNSGroup *nbdns.NameServerGroup) (bool, error) {
 	if !newNSGroup.Enabled && !oldNSGroup.Enabled {
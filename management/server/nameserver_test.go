@@ -43,6 +43,8 @@ func TestCreateNameServerGroup(t *testing.T) {
 		primary       bool
 		domains       []string
 		searchDomains bool
+		routedAnswers nbdns.RoutedAnswers
+		forwarderPeer string
 	}
 
 	testCases := []struct {
@@ -212,6 +214,101 @@ func TestCreateNameServerGroup(t *testing.T) {
 			errFunc:      require.Error,
 			shouldCreate: false,
 		},
+		{
+			name: "Create A NS Group With Routed Answers",
+			inputArgs: input{
+				name:          "super",
+				description:   "super",
+				groups:        []string{group1ID},
+				domains:       []string{validDomain},
+				routedAnswers: nbdns.RoutedAnswersOnly,
+				nameServers: []nbdns.NameServer{
+					{
+						IP:     netip.MustParseAddr("1.1.1.1"),
+						NSType: nbdns.UDPNameServerType,
+						Port:   nbdns.DefaultDNSPort,
+					},
+				},
+				enabled: true,
+			},
+			errFunc:      require.NoError,
+			shouldCreate: true,
+			expectedNSGroup: &nbdns.NameServerGroup{
+				Name:          "super",
+				Description:   "super",
+				Domains:       []string{"example.com"},
+				Groups:        []string{group1ID},
+				RoutedAnswers: nbdns.RoutedAnswersOnly,
+				NameServers: []nbdns.NameServer{
+					{
+						IP:     netip.MustParseAddr("1.1.1.1"),
+						NSType: nbdns.UDPNameServerType,
+						Port:   nbdns.DefaultDNSPort,
+					},
+				},
+				Enabled: true,
+			},
+		},
+		{
+			name: "Create A NS Group With Invalid Routed Answers Should Fail",
+			inputArgs: input{
+				name:          "super",
+				description:   "super",
+				groups:        []string{group1ID},
+				domains:       []string{validDomain},
+				routedAnswers: "always",
+				nameServers: []nbdns.NameServer{
+					{
+						IP:     netip.MustParseAddr("1.1.1.1"),
+						NSType: nbdns.UDPNameServerType,
+						Port:   nbdns.DefaultDNSPort,
+					},
+				},
+				enabled: true,
+			},
+			errFunc:      require.Error,
+			shouldCreate: false,
+		},
+		{
+			name: "Create A NS Group With Unknown Forwarder Peer Should Fail",
+			inputArgs: input{
+				name:          "super",
+				description:   "super",
+				groups:        []string{group1ID},
+				domains:       []string{validDomain},
+				forwarderPeer: "unknown-peer",
+				nameServers: []nbdns.NameServer{
+					{
+						IP:     netip.MustParseAddr("10.0.0.53"),
+						NSType: nbdns.UDPNameServerType,
+						Port:   nbdns.DefaultDNSPort,
+					},
+				},
+				enabled: true,
+			},
+			errFunc:      require.Error,
+			shouldCreate: false,
+		},
+		{
+			name: "Create A Primary NS Group With Forwarder Peer Should Fail",
+			inputArgs: input{
+				name:          "super",
+				description:   "super",
+				primary:       true,
+				groups:        []string{group1ID},
+				forwarderPeer: "unknown-peer",
+				nameServers: []nbdns.NameServer{
+					{
+						IP:     netip.MustParseAddr("10.0.0.53"),
+						NSType: nbdns.UDPNameServerType,
+						Port:   nbdns.DefaultDNSPort,
+					},
+				},
+				enabled: true,
+			},
+			errFunc:      require.Error,
+			shouldCreate: false,
+		},
 		{
 			name: "Create A NS Group With No Nameservers Should Fail",
 			inputArgs: input{
@@ -403,6 +500,8 @@ func TestCreateNameServerGroup(t *testing.T) {
 				testCase.inputArgs.enabled,
 				userID,
 				testCase.inputArgs.searchDomains,
+				testCase.inputArgs.routedAnswers,
+				testCase.inputArgs.forwarderPeer,
 			)
 
 			testCase.errFunc(t, err)
@@ -990,7 +1089,7 @@ func TestNameServerAccountPeersUpdate(t *testing.T) {
 				Port:   nbdns.DefaultDNSPort,
 			}},
 			[]string{"groupA"},
-			true, []string{}, true, userID, false,
+			true, []string{}, true, userID, false, nbdns.RoutedAnswersDisabled, "",
 		)
 		assert.NoError(t, err)
 
@@ -1035,7 +1134,7 @@ func TestNameServerAccountPeersUpdate(t *testing.T) {
 				Port:   nbdns.DefaultDNSPort,
 			}},
 			[]string{"groupB"},
-			true, []string{}, true, userID, false,
+			true, []string{}, true, userID, false, nbdns.RoutedAnswersDisabled, "",
 		)
 		assert.NoError(t, err)
 
@@ -8,6 +8,7 @@ import (
 	"github.com/netbirdio/netbird/management/server/account"
 	"github.com/netbirdio/netbird/management/server/activity"
 	"github.com/netbirdio/netbird/management/server/groups"
+	"github.com/netbirdio/netbird/management/server/labels"
 	"github.com/netbirdio/netbird/management/server/networks/resources/types"
 	"github.com/netbirdio/netbird/management/server/permissions"
 	"github.com/netbirdio/netbird/management/server/status"
@@ -101,10 +102,16 @@ func (m *managerImpl) CreateResource(ctx context.Context, userID string, resourc
 		return nil, status.NewPermissionDeniedError()
 	}
 
+	if err = labels.Validate(resource.Labels); err != nil {
+		return nil, status.Errorf(status.InvalidArgument, "%s", err)
+	}
+
+	resourceLabels := resource.Labels
 	resource, err = types.NewNetworkResource(resource.AccountID, resource.NetworkID, resource.Name, resource.Description, resource.Address, resource.GroupIDs, resource.Enabled)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create new network resource: %w", err)
 	}
+	resource.Labels = resourceLabels
 
 	unlock := m.store.AcquireWriteLockByUID(ctx, resource.AccountID)
 	defer unlock()
@@ -193,7 +200,16 @@ func (m *managerImpl) UpdateResource(ctx context.Context, userID string, resourc
 		return nil, status.NewPermissionDeniedError()
 	}
 
-	resourceType, domain, prefix, err := types.GetResourceType(resource.Address)
+	if err = labels.Validate(resource.Labels); err != nil {
+		return nil, status.Errorf(status.InvalidArgument, "%s", err)
+	}
+
+	address, scope, err := types.GetResourceScope(resource.Address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resource scope: %w", err)
+	}
+
+	resourceType, domain, prefix, err := types.GetResourceType(address)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get resource type: %w", err)
 	}
@@ -201,6 +217,7 @@ func (m *managerImpl) UpdateResource(ctx context.Context, userID string, resourc
 	resource.Type = resourceType
 	resource.Domain = domain
 	resource.Prefix = prefix
+	resource.Scope = scope
 
 	unlock := m.store.AcquireWriteLockByUID(ctx, resource.AccountID)
 	defer unlock()
@@ -231,6 +248,11 @@ func (m *managerImpl) UpdateResource(ctx context.Context, userID string, resourc
 			return fmt.Errorf("failed to get network resource: %w", err)
 		}
 
+		// the labels are kept when omitted on update
+		if resource.Labels == nil {
+			resource.Labels = oldResource.Labels
+		}
+
 		err = transaction.SaveNetworkResource(ctx, store.LockingStrengthUpdate, resource)
 		if err != nil {
 			return fmt.Errorf("failed to save network resource: %w", err)
@@ -3,8 +3,11 @@ package types
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"net/netip"
 	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/rs/xid"
 
@@ -29,6 +32,36 @@ func (p NetworkResourceType) String() string {
 	return string(p)
 }
 
+// Protocols a resource can be scoped to
+const (
+	ProtocolTCP = "tcp"
+	ProtocolUDP = "udp"
+)
+
+// Scope restricts the access to a resource to a protocol and a port range, e.g. tcp/5432. The zero Scope grants
+// access to all the protocols and ports of the resource.
+type Scope struct {
+	Protocol  string
+	StartPort uint16
+	EndPort   uint16
+}
+
+// IsZero returns true if the resource isn't scoped
+func (s Scope) IsZero() bool {
+	return s.Protocol == ""
+}
+
+// String returns the scope in the protocol/port or the protocol/start-end format
+func (s Scope) String() string {
+	if s.IsZero() {
+		return ""
+	}
+	if s.StartPort == s.EndPort {
+		return fmt.Sprintf("%s/%d", s.Protocol, s.StartPort)
+	}
+	return fmt.Sprintf("%s/%d-%d", s.Protocol, s.StartPort, s.EndPort)
+}
+
 type NetworkResource struct {
 	ID          string `gorm:"index"`
 	NetworkID   string `gorm:"index"`
@@ -40,11 +73,20 @@ type NetworkResource struct {
 	GroupIDs    []string `gorm:"-"`
 	Domain      string
 	Prefix      netip.Prefix `gorm:"serializer:json"`
-	Enabled     bool
+	// Scope restricts the policies of the resource to a protocol and a port range
+	Scope   Scope `gorm:"embedded;embeddedPrefix:scope_"`
+	Enabled bool
+	// Labels are the key/value labels set by users, the policies can select the resources by their labels
+	Labels map[string]string `gorm:"serializer:json"`
 }
 
 func NewNetworkResource(accountID, networkID, name, description, address string, groupIDs []string, enabled bool) (*NetworkResource, error) {
-	resourceType, domain, prefix, err := GetResourceType(address)
+	resourceAddress, scope, err := GetResourceScope(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address: %w", err)
+	}
+
+	resourceType, domain, prefix, err := GetResourceType(resourceAddress)
 	if err != nil {
 		return nil, fmt.Errorf("invalid address: %w", err)
 	}
@@ -59,6 +101,7 @@ func NewNetworkResource(accountID, networkID, name, description, address string,
 		Address:     address,
 		Domain:      domain,
 		Prefix:      prefix,
+		Scope:       scope,
 		GroupIDs:    groupIDs,
 		Enabled:     enabled,
 	}, nil
@@ -69,6 +112,15 @@ func (n *NetworkResource) ToAPIResponse(groups []api.GroupMinimum) *api.NetworkR
 	if n.Type == Domain {
 		addr = n.Domain
 	}
+	if !n.Scope.IsZero() {
+		addr += " " + n.Scope.String()
+	}
+
+	var labels *api.Labels
+	if len(n.Labels) > 0 {
+		resourceLabels := api.Labels(n.Labels)
+		labels = &resourceLabels
+	}
 
 	return &api.NetworkResource{
 		Id:          n.ID,
@@ -78,6 +130,7 @@ func (n *NetworkResource) ToAPIResponse(groups []api.GroupMinimum) *api.NetworkR
 		Address:     addr,
 		Groups:      groups,
 		Enabled:     n.Enabled,
+		Labels:      labels,
 	}
 }
 
@@ -90,6 +143,9 @@ func (n *NetworkResource) FromAPIRequest(req *api.NetworkResourceRequest) {
 	n.Address = req.Address
 	n.GroupIDs = req.Groups
 	n.Enabled = req.Enabled
+	if req.Labels != nil {
+		n.Labels = *req.Labels
+	}
 }
 
 func (n *NetworkResource) Copy() *NetworkResource {
@@ -103,8 +159,10 @@ func (n *NetworkResource) Copy() *NetworkResource {
 		Address:     n.Address,
 		Domain:      n.Domain,
 		Prefix:      n.Prefix,
+		Scope:       n.Scope,
 		GroupIDs:    n.GroupIDs,
 		Enabled:     n.Enabled,
+		Labels:      maps.Clone(n.Labels),
 	}
 }
 
@@ -153,6 +211,53 @@ func (n *NetworkResource) EventMeta(network *networkTypes.Network) map[string]an
 	return map[string]any{"name": n.Name, "type": n.Type, "network_name": network.Name, "network_id": network.ID}
 }
 
+// GetResourceScope splits the scope off the address of a resource, e.g. "10.1.2.3 tcp/5432" is the host 10.1.2.3
+// scoped to the TCP port 5432. The scope is zero if the address has none.
+func GetResourceScope(address string) (string, Scope, error) {
+	fields := strings.Fields(address)
+	switch len(fields) {
+	case 1:
+		return fields[0], Scope{}, nil
+	case 2:
+	default:
+		return "", Scope{}, errors.New("expected an address optionally followed by a protocol/port scope")
+	}
+
+	protocol, ports, found := strings.Cut(strings.ToLower(fields[1]), "/")
+	if !found {
+		return "", Scope{}, fmt.Errorf("invalid scope %q, expected protocol/port", fields[1])
+	}
+	if protocol != ProtocolTCP && protocol != ProtocolUDP {
+		return "", Scope{}, fmt.Errorf("invalid scope protocol %q, expected tcp or udp", protocol)
+	}
+
+	start, end, isRange := strings.Cut(ports, "-")
+	if !isRange {
+		end = start
+	}
+	startPort, err := parsePort(start)
+	if err != nil {
+		return "", Scope{}, err
+	}
+	endPort, err := parsePort(end)
+	if err != nil {
+		return "", Scope{}, err
+	}
+	if endPort < startPort {
+		return "", Scope{}, fmt.Errorf("invalid scope port range %s, the end is smaller than the start", ports)
+	}
+
+	return fields[0], Scope{Protocol: protocol, StartPort: startPort, EndPort: endPort}, nil
+}
+
+func parsePort(s string) (uint16, error) {
+	port, err := strconv.ParseUint(s, 10, 16)
+	if err != nil || port == 0 {
+		return 0, fmt.Errorf("invalid scope port %q", s)
+	}
+	return uint16(port), nil
+}
+
 // GetResourceType returns the type of the resource based on the address
 func GetResourceType(address string) (NetworkResourceType, string, netip.Prefix, error) {
 	if prefix, err := netip.ParsePrefix(address); err == nil {
@@ -51,3 +51,42 @@ func TestGetResourceType(t *testing.T) {
 		})
 	}
 }
+
+func TestGetResourceScope(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedAddress string
+		expectedScope   Scope
+		expectedErr     bool
+	}{
+		{"10.1.2.3", "10.1.2.3", Scope{}, false},
+		{"10.1.2.3 tcp/5432", "10.1.2.3", Scope{Protocol: ProtocolTCP, StartPort: 5432, EndPort: 5432}, false},
+		{" 10.1.2.0/24  UDP/5000-5100 ", "10.1.2.0/24", Scope{Protocol: ProtocolUDP, StartPort: 5000, EndPort: 5100}, false},
+		{"db.example.com tcp/5432", "db.example.com", Scope{Protocol: ProtocolTCP, StartPort: 5432, EndPort: 5432}, false},
+		// Invalid scopes
+		{"10.1.2.3 5432", "", Scope{}, true},
+		{"10.1.2.3 icmp/1", "", Scope{}, true},
+		{"10.1.2.3 tcp/0", "", Scope{}, true},
+		{"10.1.2.3 tcp/70000", "", Scope{}, true},
+		{"10.1.2.3 tcp/5100-5000", "", Scope{}, true},
+		{"10.1.2.3 tcp/22 udp/53", "", Scope{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			address, scope, err := GetResourceScope(tt.input)
+
+			if tt.expectedErr != (err != nil) {
+				t.Errorf("Expected error %v, got %v", tt.expectedErr, err)
+			}
+
+			if address != tt.expectedAddress {
+				t.Errorf("Expected address %v, got %v", tt.expectedAddress, address)
+			}
+
+			if scope != tt.expectedScope {
+				t.Errorf("Expected scope %v, got %v", tt.expectedScope, scope)
+			}
+		})
+	}
+}
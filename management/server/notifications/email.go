@@ -0,0 +1,87 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+)
+
+// EmailConfig defines the SMTP server and recipients of the notification emails
+type EmailConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	// From is the sender address of the notification emails
+	From string
+	// To is the list of recipients of the notification emails
+	To []string
+}
+
+// EmailNotifier sends notifications by email through an SMTP server
+type EmailNotifier struct {
+	config   *EmailConfig
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewEmailNotifier creates a new EmailNotifier
+func NewEmailNotifier(config *EmailConfig) (*EmailNotifier, error) {
+	if config.Host == "" {
+		return nil, errors.New("smtp host is required")
+	}
+	if config.From == "" {
+		return nil, errors.New("email sender is required")
+	}
+	if len(config.To) == 0 {
+		return nil, errors.New("at least one email recipient is required")
+	}
+
+	return &EmailNotifier{
+		config:   config,
+		sendMail: smtp.SendMail,
+	}, nil
+}
+
+// Notify sends the notification to all configured recipients
+func (e *EmailNotifier) Notify(_ context.Context, notification *Notification) error {
+	port := e.config.Port
+	if port == 0 {
+		port = 587
+	}
+	addr := net.JoinHostPort(e.config.Host, strconv.Itoa(port))
+
+	var auth smtp.Auth
+	if e.config.Username != "" {
+		auth = smtp.PlainAuth("", e.config.Username, e.config.Password, e.config.Host)
+	}
+
+	if err := e.sendMail(addr, auth, e.config.From, e.config.To, e.buildMessage(notification)); err != nil {
+		return fmt.Errorf("send notification email: %w", err)
+	}
+
+	return nil
+}
+
+func (e *EmailNotifier) buildMessage(notification *Notification) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + e.config.From + "\r\n")
+	b.WriteString("To: " + strings.Join(e.config.To, ", ") + "\r\n")
+	b.WriteString("Subject: " + encodeSubject(notification.Subject) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(notification.Message + "\r\n")
+	return []byte(b.String())
+}
+
+// encodeSubject returns the subject as a single header line. The subject contains names reported by the peers, the
+// line breaks are removed so they can't add headers and the non-ASCII characters are RFC 2047 encoded.
+func encodeSubject(subject string) string {
+	subject = strings.NewReplacer("\r", " ", "\n", " ").Replace(subject)
+	return mime.QEncoding.Encode("utf-8", subject)
+}
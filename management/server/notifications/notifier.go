@@ -0,0 +1,87 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Notification is a message sent to the account administrators about something that requires their attention
+type Notification struct {
+	// AccountID is the account the notification belongs to
+	AccountID string `json:"account_id"`
+	// Event is a string code identifying the notification, e.g. peer.approval.pending
+	Event string `json:"event"`
+	// Subject is a short human-readable summary of the notification
+	Subject string `json:"subject"`
+	// Message is a longer human-readable description of the notification
+	Message string `json:"message"`
+	// Timestamp of the notification
+	Timestamp time.Time `json:"timestamp"`
+	// Meta holds additional information about the notification target
+	Meta map[string]any `json:"meta,omitempty"`
+}
+
+// Notifier delivers notifications to the account administrators
+type Notifier interface {
+	Notify(ctx context.Context, notification *Notification) error
+}
+
+// Config holds the notification channels configured for the management service
+type Config struct {
+	// Webhook is an optional webhook endpoint receiving notifications as JSON
+	Webhook *WebhookConfig
+	// Email is an optional SMTP configuration used to send notifications by email
+	Email *EmailConfig
+}
+
+// NewNotifier returns a Notifier delivering notifications to all channels defined in the config.
+// If no channel is configured a no-op notifier is returned.
+func NewNotifier(config *Config) (Notifier, error) {
+	if config == nil {
+		return &noopNotifier{}, nil
+	}
+
+	var notifiers multiNotifier
+
+	if config.Webhook != nil {
+		webhook, err := NewWebhookNotifier(config.Webhook)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, webhook)
+	}
+
+	if config.Email != nil {
+		email, err := NewEmailNotifier(config.Email)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, email)
+	}
+
+	if len(notifiers) == 0 {
+		return &noopNotifier{}, nil
+	}
+
+	return notifiers, nil
+}
+
+type multiNotifier []Notifier
+
+// Notify sends the notification through every channel and joins the errors of the failed ones
+func (m multiNotifier) Notify(ctx context.Context, notification *Notification) error {
+	var errs []error
+	for _, n := range m {
+		if err := n.Notify(ctx, notification); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type noopNotifier struct{}
+
+func (n *noopNotifier) Notify(_ context.Context, _ *Notification) error {
+	return nil
+}
@@ -0,0 +1,121 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookNotifier_Notify(t *testing.T) {
+	var received Notification
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	notifier, err := NewWebhookNotifier(&WebhookConfig{
+		URL:     server.URL,
+		Headers: map[string]string{"Authorization": "Bearer secret"},
+	})
+	require.NoError(t, err)
+
+	notification := &Notification{
+		AccountID: "account",
+		Event:     "peer.approval.pending",
+		Subject:   "subject",
+		Message:   "message",
+		Timestamp: time.Now().UTC(),
+		Meta:      map[string]any{"name": "peer"},
+	}
+	require.NoError(t, notifier.Notify(context.Background(), notification))
+	assert.Equal(t, notification.AccountID, received.AccountID)
+	assert.Equal(t, notification.Event, received.Event)
+	assert.Equal(t, "peer", received.Meta["name"])
+}
+
+func TestWebhookNotifier_NotifyFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	notifier, err := NewWebhookNotifier(&WebhookConfig{URL: server.URL})
+	require.NoError(t, err)
+	assert.Error(t, notifier.Notify(context.Background(), &Notification{}))
+}
+
+func TestNewWebhookNotifier_InvalidURL(t *testing.T) {
+	_, err := NewWebhookNotifier(&WebhookConfig{URL: "ftp://example.com"})
+	assert.Error(t, err)
+}
+
+func TestEmailNotifier_Notify(t *testing.T) {
+	notifier, err := NewEmailNotifier(&EmailConfig{
+		Host: "smtp.example.com",
+		From: "netbird@example.com",
+		To:   []string{"admin@example.com"},
+	})
+	require.NoError(t, err)
+
+	var sentAddr string
+	var sentMsg []byte
+	notifier.sendMail = func(addr string, _ smtp.Auth, from string, to []string, msg []byte) error {
+		sentAddr = addr
+		sentMsg = msg
+		assert.Equal(t, "netbird@example.com", from)
+		assert.Equal(t, []string{"admin@example.com"}, to)
+		return nil
+	}
+
+	err = notifier.Notify(context.Background(), &Notification{Subject: "New peer", Message: "peer is waiting"})
+	require.NoError(t, err)
+	assert.Equal(t, "smtp.example.com:587", sentAddr)
+	assert.True(t, strings.Contains(string(sentMsg), "Subject: New peer\r\n"))
+	assert.True(t, strings.HasSuffix(string(sentMsg), "peer is waiting\r\n"))
+}
+
+func TestEmailNotifier_SubjectInjection(t *testing.T) {
+	notifier, err := NewEmailNotifier(&EmailConfig{
+		Host: "smtp.example.com",
+		From: "netbird@example.com",
+		To:   []string{"admin@example.com"},
+	})
+	require.NoError(t, err)
+
+	msg := string(notifier.buildMessage(&Notification{
+		Subject: "NetBird peer evil\r\nBcc: attacker@example.com\r\n is waiting for approval",
+		Message: "peer is waiting",
+	}))
+
+	headers, _, found := strings.Cut(msg, "\r\n\r\n")
+	require.True(t, found)
+	for _, line := range strings.Split(headers, "\r\n") {
+		assert.False(t, strings.HasPrefix(line, "Bcc:"), "the subject shouldn't add headers")
+	}
+	assert.Contains(t, headers, "Subject: NetBird peer evil  Bcc: attacker@example.com   is waiting for approval\r\n")
+
+	msg = string(notifier.buildMessage(&Notification{Subject: "Peer größe", Message: "peer is waiting"}))
+	assert.Contains(t, msg, "Subject: =?utf-8?q?Peer_gr=C3=B6=C3=9Fe?=\r\n")
+}
+
+func TestNewNotifier(t *testing.T) {
+	notifier, err := NewNotifier(nil)
+	require.NoError(t, err)
+	assert.IsType(t, &noopNotifier{}, notifier)
+
+	notifier, err = NewNotifier(&Config{Webhook: &WebhookConfig{URL: "https://example.com/hook"}})
+	require.NoError(t, err)
+	assert.Len(t, notifier, 1)
+}
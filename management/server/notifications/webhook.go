@@ -0,0 +1,77 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const defaultWebhookTimeout = 10 * time.Second
+
+// WebhookConfig defines the endpoint receiving notifications
+type WebhookConfig struct {
+	// URL of the endpoint that receives a POST request per notification
+	URL string
+	// Headers are additional HTTP headers sent with every request, e.g. an authorization header
+	Headers map[string]string
+}
+
+// WebhookNotifier posts notifications as JSON to a configured endpoint
+type WebhookNotifier struct {
+	url        string
+	headers    map[string]string
+	httpClient *http.Client
+}
+
+// NewWebhookNotifier creates a new WebhookNotifier
+func NewWebhookNotifier(config *WebhookConfig) (*WebhookNotifier, error) {
+	u, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("parse webhook url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported webhook url scheme %q", u.Scheme)
+	}
+
+	return &WebhookNotifier{
+		url:     config.URL,
+		headers: config.Headers,
+		httpClient: &http.Client{
+			Timeout: defaultWebhookTimeout,
+		},
+	}, nil
+}
+
+// Notify sends the notification to the webhook endpoint
+func (w *WebhookNotifier) Notify(ctx context.Context, notification *Notification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("marshal notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create webhook request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range w.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("send webhook request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook endpoint responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
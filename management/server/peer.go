@@ -15,20 +15,57 @@ import (
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/exp/maps"
 
+	nbdns "github.com/netbirdio/netbird/dns"
 	"github.com/netbirdio/netbird/management/domain"
 	"github.com/netbirdio/netbird/management/server/geolocation"
 
 	"github.com/netbirdio/netbird/management/server/idp"
+	"github.com/netbirdio/netbird/management/server/labels"
 	"github.com/netbirdio/netbird/management/server/posture"
 	"github.com/netbirdio/netbird/management/server/store"
 	"github.com/netbirdio/netbird/management/server/types"
 
 	"github.com/netbirdio/netbird/management/proto"
 	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/cluster"
 	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+	"github.com/netbirdio/netbird/management/server/permissions"
 	"github.com/netbirdio/netbird/management/server/status"
 )
 
+const (
+	// maxPeerDNSAliases is the maximum number of DNS aliases a peer can have
+	maxPeerDNSAliases = 10
+)
+
+// ListPeers returns a page of the account peers matching the filter and the cursor of the next page.
+// Regular users only list the peers they added.
+func (am *DefaultAccountManager) ListPeers(ctx context.Context, accountID, userID string, filter store.PeerFilter, opts store.ListOptions) ([]*nbpeer.Peer, string, error) {
+	user, err := am.Store.GetUserByUserID(ctx, store.LockingStrengthShare, userID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := am.permissionsManager.ValidateAccountAccess(ctx, accountID, user, false); err != nil {
+		return nil, "", err
+	}
+
+	if user.IsRegularUser() {
+		settings, err := am.Store.GetAccountSettings(ctx, store.LockingStrengthShare, accountID)
+		if err != nil {
+			return nil, "", err
+		}
+
+		if settings.RegularUsersViewBlocked {
+			return []*nbpeer.Peer{}, "", nil
+		}
+
+		filter.UserID = user.Id
+	}
+
+	return am.Store.ListAccountPeers(ctx, store.LockingStrengthShare, accountID, filter, opts)
+}
+
 // GetPeers returns a list of peers under the given account filtering out peers that do not belong to a user if
 // the current user is not an admin.
 func (am *DefaultAccountManager) GetPeers(ctx context.Context, accountID, userID, nameFilter, ipFilter string) ([]*nbpeer.Peer, error) {
@@ -76,7 +113,7 @@ func (am *DefaultAccountManager) GetPeers(ctx context.Context, accountID, userID
 		return nil, err
 	}
 
-	approvedPeersMap, err := am.integratedPeerValidator.GetValidatedPeers(accountID, maps.Values(account.Groups), maps.Values(account.Peers), account.Settings.Extra)
+	approvedPeersMap, err := am.getValidatedPeers(accountID, maps.Values(account.Groups), maps.Values(account.Peers), account.Settings.Extra)
 	if err != nil {
 		return nil, err
 	}
@@ -100,8 +137,9 @@ func (am *DefaultAccountManager) MarkPeerConnected(ctx context.Context, peerPubK
 	}()
 
 	var peer *nbpeer.Peer
-	var settings *types.Settings
 	var expired bool
+	var ruleEvents []func()
+	var groupsChanged bool
 	var err error
 
 	err = am.Store.ExecuteInTransaction(ctx, func(transaction store.Store) error {
@@ -110,29 +148,41 @@ func (am *DefaultAccountManager) MarkPeerConnected(ctx context.Context, peerPubK
 			return err
 		}
 
+		countryBefore := peer.Location.CountryCode
 		expired, err = updatePeerStatusAndLocation(ctx, am.geo, transaction, peer, connected, realIP, accountID)
+		if err != nil {
+			return err
+		}
+
+		if peer.Location.CountryCode != countryBefore {
+			ruleEvents, groupsChanged, err = am.applyGroupMembershipRules(ctx, transaction, accountID, peer)
+		}
 		return err
 	})
 	if err != nil {
 		return err
 	}
 
+	for _, storeEvent := range ruleEvents {
+		storeEvent()
+	}
+
 	if peer.AddedWithSSOLogin() {
-		settings, err = am.Store.GetAccountSettings(ctx, store.LockingStrengthShare, accountID)
+		sessionSettings, err := getPeerSessionSettings(ctx, am.Store, accountID, peer)
 		if err != nil {
 			return err
 		}
 
-		if peer.LoginExpirationEnabled && settings.PeerLoginExpirationEnabled {
+		if sessionSettings.LoginExpirationApplies(peer) {
 			am.checkAndSchedulePeerLoginExpiration(ctx, accountID)
 		}
 
-		if peer.InactivityExpirationEnabled && settings.PeerInactivityExpirationEnabled {
+		if sessionSettings.InactivityExpirationApplies(peer) {
 			am.checkAndSchedulePeerInactivityExpiration(ctx, accountID)
 		}
 	}
 
-	if expired {
+	if expired || groupsChanged {
 		// we need to update other peers because when peer login expires all other peers are notified to disconnect from
 		// the expired one. Here we notify them that connection is now allowed again.
 		am.UpdateAccountPeers(ctx, accountID)
@@ -197,6 +247,8 @@ func (am *DefaultAccountManager) UpdatePeer(ctx context.Context, accountID, user
 	var peerGroupList []string
 	var requiresPeerUpdates bool
 	var peerLabelChanged bool
+	var dnsAliasesChanged bool
+	var labelsChanged bool
 	var sshChanged bool
 	var loginExpirationChanged bool
 	var inactivityExpirationChanged bool
@@ -238,6 +290,28 @@ func (am *DefaultAccountManager) UpdatePeer(ctx context.Context, accountID, user
 			peerLabelChanged = true
 		}
 
+		if update.DNSAliases != nil && !slices.Equal(peer.DNSAliases, update.DNSAliases) {
+			if user.IsRegularUser() && peer.UserID != user.Id && !am.hasCustomRolePermission(ctx, accountID, user, permissions.Peers, permissions.Write) {
+				return status.Errorf(status.PermissionDenied, "only administrators and the owner of the peer can set its DNS aliases")
+			}
+
+			if err = validatePeerDNSAliases(ctx, transaction, accountID, peer, update.DNSAliases); err != nil {
+				return err
+			}
+
+			peer.DNSAliases = update.DNSAliases
+			dnsAliasesChanged = true
+		}
+
+		if update.Labels != nil && !maps.Equal(peer.Labels, update.Labels) {
+			if err = labels.Validate(update.Labels); err != nil {
+				return status.Errorf(status.InvalidArgument, "%s", err)
+			}
+
+			peer.Labels = update.Labels
+			labelsChanged = true
+		}
+
 		if peer.SSHEnabled != update.SSHEnabled {
 			peer.SSHEnabled = update.SSHEnabled
 			sshChanged = true
@@ -277,6 +351,18 @@ func (am *DefaultAccountManager) UpdatePeer(ctx context.Context, accountID, user
 		am.StoreEvent(ctx, userID, peer.ID, accountID, activity.PeerRenamed, peer.EventMeta(am.GetDNSDomain()))
 	}
 
+	if dnsAliasesChanged {
+		meta := peer.EventMeta(am.GetDNSDomain())
+		meta["dns_aliases"] = strings.Join(peer.DNSAliases, ",")
+		am.StoreEvent(ctx, userID, peer.ID, accountID, activity.PeerDNSAliasesUpdated, meta)
+	}
+
+	if labelsChanged {
+		meta := peer.EventMeta(am.GetDNSDomain())
+		meta["labels"] = labels.String(peer.Labels)
+		am.StoreEvent(ctx, userID, peer.ID, accountID, activity.PeerLabelsUpdated, meta)
+	}
+
 	if loginExpirationChanged {
 		event := activity.PeerLoginExpirationEnabled
 		if !peer.LoginExpirationEnabled {
@@ -284,7 +370,7 @@ func (am *DefaultAccountManager) UpdatePeer(ctx context.Context, accountID, user
 		}
 		am.StoreEvent(ctx, userID, peer.IP.String(), accountID, event, peer.EventMeta(am.GetDNSDomain()))
 
-		if peer.AddedWithSSOLogin() && peer.LoginExpirationEnabled && settings.PeerLoginExpirationEnabled {
+		if am.peerExpirationApplies(ctx, accountID, peer, types.SessionSettings.LoginExpirationApplies) {
 			am.checkAndSchedulePeerLoginExpiration(ctx, accountID)
 		}
 	}
@@ -296,12 +382,12 @@ func (am *DefaultAccountManager) UpdatePeer(ctx context.Context, accountID, user
 		}
 		am.StoreEvent(ctx, userID, peer.IP.String(), accountID, event, peer.EventMeta(am.GetDNSDomain()))
 
-		if peer.AddedWithSSOLogin() && peer.InactivityExpirationEnabled && settings.PeerInactivityExpirationEnabled {
+		if am.peerExpirationApplies(ctx, accountID, peer, types.SessionSettings.InactivityExpirationApplies) {
 			am.checkAndSchedulePeerInactivityExpiration(ctx, accountID)
 		}
 	}
 
-	if peerLabelChanged || requiresPeerUpdates {
+	if peerLabelChanged || dnsAliasesChanged || labelsChanged || requiresPeerUpdates {
 		am.UpdateAccountPeers(ctx, accountID)
 	} else if sshChanged {
 		am.UpdateAccountPeer(ctx, accountID, peer.ID)
@@ -395,6 +481,7 @@ func (am *DefaultAccountManager) GetNetworkMap(ctx context.Context, peerID strin
 	if err != nil {
 		return nil, err
 	}
+	types.FlattenNestedGroups(account.Groups)
 
 	peer := account.GetPeer(peerID)
 	if peer == nil {
@@ -406,7 +493,7 @@ func (am *DefaultAccountManager) GetNetworkMap(ctx context.Context, peerID strin
 		groups[groupID] = group.Peers
 	}
 
-	validatedPeers, err := am.integratedPeerValidator.GetValidatedPeers(account.Id, maps.Values(account.Groups), maps.Values(account.Peers), account.Settings.Extra)
+	validatedPeers, err := am.getValidatedPeers(account.Id, maps.Values(account.Groups), maps.Values(account.Peers), account.Settings.Extra)
 	if err != nil {
 		return nil, err
 	}
@@ -491,6 +578,7 @@ func (am *DefaultAccountManager) AddPeer(ctx context.Context, setupKey, userID s
 
 	var newPeer *nbpeer.Peer
 	var updateAccountPeers bool
+	var ruleEvents []func()
 
 	err = am.Store.ExecuteInTransaction(ctx, func(transaction store.Store) error {
 		var setupKeyID string
@@ -498,6 +586,7 @@ func (am *DefaultAccountManager) AddPeer(ctx context.Context, setupKey, userID s
 		var ephemeral bool
 		var groupsToAdd []string
 		var allowExtraDNSLabels bool
+		var provisioning types.SetupKeyProvisioning
 		if addedByUser {
 			user, err := transaction.GetUserByUserID(ctx, store.LockingStrengthUpdate, userID)
 			if err != nil {
@@ -528,6 +617,11 @@ func (am *DefaultAccountManager) AddPeer(ctx context.Context, setupKey, userID s
 			if !sk.AllowExtraDNSLabels && len(peer.ExtraDNSLabels) > 0 {
 				return status.Errorf(status.PreconditionFailed, "couldn't add peer: setup key doesn't allow extra DNS labels")
 			}
+
+			if !sk.IsIPAllowed(peer.Location.ConnectionIP) {
+				return status.Errorf(status.PermissionDenied, "couldn't add peer: setup key doesn't allow registration from %s", peer.Location.ConnectionIP)
+			}
+			provisioning = sk.SetupKeyProvisioning
 		}
 
 		if (strings.ToLower(peer.Meta.Hostname) == "iphone" || strings.ToLower(peer.Meta.Hostname) == "ipad") && userID != "" {
@@ -539,7 +633,8 @@ func (am *DefaultAccountManager) AddPeer(ctx context.Context, setupKey, userID s
 			}
 		}
 
-		freeLabel, err := am.getFreeDNSLabel(ctx, transaction, accountID, peer.Meta.Hostname)
+		peerName := provisioning.PeerNamePrefix + peer.Meta.Hostname
+		freeLabel, err := am.getFreeDNSLabel(ctx, transaction, accountID, peerName)
 		if err != nil {
 			return fmt.Errorf("failed to get free DNS label: %w", err)
 		}
@@ -556,7 +651,7 @@ func (am *DefaultAccountManager) AddPeer(ctx context.Context, setupKey, userID s
 			Key:                         peer.Key,
 			IP:                          freeIP,
 			Meta:                        peer.Meta,
-			Name:                        peer.Meta.Hostname,
+			Name:                        peerName,
 			DNSLabel:                    freeLabel,
 			UserID:                      userID,
 			Status:                      &nbpeer.PeerStatus{Connected: false, LastSeen: registrationTime},
@@ -570,6 +665,7 @@ func (am *DefaultAccountManager) AddPeer(ctx context.Context, setupKey, userID s
 			InactivityExpirationEnabled: addedByUser,
 			ExtraDNSLabels:              peer.ExtraDNSLabels,
 			AllowExtraDNSLabels:         allowExtraDNSLabels,
+			SetupKeyID:                  setupKeyID,
 		}
 		opEvent.TargetID = newPeer.ID
 		opEvent.Meta = newPeer.EventMeta(am.GetDNSDomain())
@@ -588,11 +684,18 @@ func (am *DefaultAccountManager) AddPeer(ctx context.Context, setupKey, userID s
 			}
 		}
 
+		if err = validatePeerSetupKeyPostureChecks(ctx, transaction, accountID, newPeer, provisioning.AutoPostureChecks); err != nil {
+			return err
+		}
+
 		settings, err := transaction.GetAccountSettings(ctx, store.LockingStrengthShare, accountID)
 		if err != nil {
 			return fmt.Errorf("failed to get account settings: %w", err)
 		}
 		newPeer = am.integratedPeerValidator.PreparePeer(ctx, accountID, newPeer, groupsToAdd, settings.Extra)
+		if requiresApprovalOnRegistration(settings) {
+			newPeer.Status.RequiresApproval = true
+		}
 
 		err = transaction.AddPeerToAllGroup(ctx, store.LockingStrengthUpdate, accountID, newPeer.ID)
 		if err != nil {
@@ -613,6 +716,17 @@ func (am *DefaultAccountManager) AddPeer(ctx context.Context, setupKey, userID s
 			return fmt.Errorf("failed to add peer to account: %w", err)
 		}
 
+		ruleEvents, _, err = am.applyGroupMembershipRules(ctx, transaction, accountID, newPeer)
+		if err != nil {
+			return fmt.Errorf("failed to apply group membership rules: %w", err)
+		}
+
+		routeEvents, err := am.addPeerToSetupKeyRoutes(ctx, transaction, accountID, setupKeyID, newPeer, provisioning.AutoRoutes)
+		if err != nil {
+			return fmt.Errorf("failed to add peer to setup key routes: %w", err)
+		}
+		ruleEvents = append(ruleEvents, routeEvents...)
+
 		err = transaction.IncrementNetworkSerial(ctx, store.LockingStrengthUpdate, accountID)
 		if err != nil {
 			return fmt.Errorf("failed to increment network serial: %w", err)
@@ -634,6 +748,7 @@ func (am *DefaultAccountManager) AddPeer(ctx context.Context, setupKey, userID s
 		if err != nil {
 			return err
 		}
+		updateAccountPeers = updateAccountPeers || len(provisioning.AutoRoutes) > 0
 
 		log.WithContext(ctx).Debugf("Peer %s added to account %s", newPeer.ID, accountID)
 		return nil
@@ -648,6 +763,13 @@ func (am *DefaultAccountManager) AddPeer(ctx context.Context, setupKey, userID s
 	}
 
 	am.StoreEvent(ctx, opEvent.InitiatorID, opEvent.TargetID, opEvent.AccountID, opEvent.Activity, opEvent.Meta)
+	for _, storeEvent := range ruleEvents {
+		storeEvent()
+	}
+
+	if newPeer.Status.RequiresApproval {
+		am.notifyPeerApprovalPending(ctx, accountID, newPeer)
+	}
 
 	unlock()
 	unlock = nil
@@ -656,7 +778,7 @@ func (am *DefaultAccountManager) AddPeer(ctx context.Context, setupKey, userID s
 		am.UpdateAccountPeers(ctx, accountID)
 	}
 
-	return am.getValidatedPeerWithMap(ctx, false, accountID, newPeer)
+	return am.getValidatedPeerWithMap(ctx, newPeer.Status.RequiresApproval, accountID, newPeer)
 }
 
 func getFreeIP(ctx context.Context, transaction store.Store, accountID string) (net.IP, error) {
@@ -670,7 +792,7 @@ func getFreeIP(ctx context.Context, transaction store.Store, accountID string) (
 		return nil, fmt.Errorf("failed getting network: %w", err)
 	}
 
-	nextIp, err := types.AllocatePeerIP(network.Net, takenIps)
+	nextIp, err := types.AllocatePeerIP(network.Net, takenIps, network.GetReservedRanges()...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to allocate new peer ip: %w", err)
 	}
@@ -689,8 +811,11 @@ func (am *DefaultAccountManager) SyncPeer(ctx context.Context, sync types.PeerSy
 	var peerNotValid bool
 	var isStatusChanged bool
 	var updated bool
+	var metaBefore nbpeer.PeerSystemMeta
 	var err error
 	var postureChecks []*posture.Checks
+	var ruleEvents []func()
+	var groupsChanged bool
 
 	settings, err := am.Store.GetAccountSettings(ctx, store.LockingStrengthShare, accountID)
 	if err != nil {
@@ -714,7 +839,13 @@ func (am *DefaultAccountManager) SyncPeer(ctx context.Context, sync types.PeerSy
 			}
 		}
 
-		if peerLoginExpired(ctx, peer, settings) {
+		sessionSettings, err := getPeerSessionSettings(ctx, transaction, accountID, peer)
+		if err != nil {
+			return err
+		}
+
+		// in the grace period the expired peers keep syncing to get access to the remediation policies
+		if peerLoginExpired(ctx, peer, sessionSettings) && !settings.PeerLoginExpirationGraceEnabled {
 			return status.NewPeerLoginExpiredError()
 		}
 
@@ -728,6 +859,7 @@ func (am *DefaultAccountManager) SyncPeer(ctx context.Context, sync types.PeerSy
 			return err
 		}
 
+		metaBefore = peer.Meta
 		updated = peer.UpdateMetaIfNew(sync.Meta)
 		if updated {
 			am.metrics.AccountManagerMetrics().CountPeerMetUpdate()
@@ -740,6 +872,11 @@ func (am *DefaultAccountManager) SyncPeer(ctx context.Context, sync types.PeerSy
 			if err != nil {
 				return err
 			}
+
+			ruleEvents, groupsChanged, err = am.applyGroupMembershipRules(ctx, transaction, accountID, peer)
+			if err != nil {
+				return err
+			}
 		}
 		return nil
 	})
@@ -747,11 +884,19 @@ func (am *DefaultAccountManager) SyncPeer(ctx context.Context, sync types.PeerSy
 		return nil, nil, nil, err
 	}
 
-	if isStatusChanged || sync.UpdateAccountPeers || (updated && len(postureChecks) > 0) {
+	if updated {
+		am.storePostureCheckTransitionEvents(ctx, accountID, peer, metaBefore, postureChecks)
+	}
+
+	for _, storeEvent := range ruleEvents {
+		storeEvent()
+	}
+
+	if isStatusChanged || sync.UpdateAccountPeers || groupsChanged || (updated && len(postureChecks) > 0) {
 		am.UpdateAccountPeers(ctx, accountID)
 	}
 
-	return am.getValidatedPeerWithMap(ctx, peerNotValid, accountID, peer)
+	return am.getValidatedPeerWithMap(ctx, peerNotValid || peer.Status.RequiresApproval, accountID, peer)
 }
 
 func (am *DefaultAccountManager) handlePeerLoginNotFound(ctx context.Context, login types.PeerLogin, err error) (*nbpeer.Peer, *types.NetworkMap, []*posture.Checks, error) {
@@ -804,7 +949,10 @@ func (am *DefaultAccountManager) LoginPeer(ctx context.Context, login types.Peer
 	var isRequiresApproval bool
 	var isStatusChanged bool
 	var isPeerUpdated bool
+	var metaBefore nbpeer.PeerSystemMeta
 	var postureChecks []*posture.Checks
+	var ruleEvents []func()
+	var groupsChanged bool
 
 	settings, err := am.Store.GetAccountSettings(ctx, store.LockingStrengthShare, accountID)
 	if err != nil {
@@ -826,7 +974,7 @@ func (am *DefaultAccountManager) LoginPeer(ctx context.Context, login types.Peer
 				return status.Errorf(status.Unauthenticated, "invalid user")
 			}
 
-			changed, err := am.handleUserPeer(ctx, transaction, peer, settings)
+			changed, err := am.handleUserPeer(ctx, transaction, accountID, peer)
 			if err != nil {
 				return err
 			}
@@ -847,6 +995,7 @@ func (am *DefaultAccountManager) LoginPeer(ctx context.Context, login types.Peer
 			return err
 		}
 
+		metaBefore = peer.Meta
 		isPeerUpdated = peer.UpdateMetaIfNew(login.Meta)
 		if isPeerUpdated {
 			am.metrics.AccountManagerMetrics().CountPeerMetUpdate()
@@ -856,6 +1005,11 @@ func (am *DefaultAccountManager) LoginPeer(ctx context.Context, login types.Peer
 			if err != nil {
 				return err
 			}
+
+			ruleEvents, groupsChanged, err = am.applyGroupMembershipRules(ctx, transaction, accountID, peer)
+			if err != nil {
+				return err
+			}
 		}
 
 		if peer.SSHKey != login.SSHKey {
@@ -886,17 +1040,91 @@ func (am *DefaultAccountManager) LoginPeer(ctx context.Context, login types.Peer
 		return nil
 	})
 	if err != nil {
+		if peer != nil {
+			am.storePeerLoginFailedEvent(ctx, accountID, login, peer, err)
+		}
 		return nil, nil, nil, err
 	}
 
 	unlockPeer()
 	unlockPeer = nil
 
-	if updateRemotePeers || isStatusChanged || (isPeerUpdated && len(postureChecks) > 0) {
+	if isPeerUpdated {
+		am.storePostureCheckTransitionEvents(ctx, accountID, peer, metaBefore, postureChecks)
+	}
+
+	for _, storeEvent := range ruleEvents {
+		storeEvent()
+	}
+
+	if updateRemotePeers || isStatusChanged || groupsChanged || (isPeerUpdated && len(postureChecks) > 0) {
 		am.UpdateAccountPeers(ctx, accountID)
 	}
 
-	return am.getValidatedPeerWithMap(ctx, isRequiresApproval, accountID, peer)
+	return am.getValidatedPeerWithMap(ctx, isRequiresApproval || peer.Status.RequiresApproval, accountID, peer)
+}
+
+// storePeerLoginFailedEvent stores an event for a peer login rejected by the management, e.g. because of a user mismatch
+// or a blocked user. Internal errors are not stored as they are not caused by the peer.
+func (am *DefaultAccountManager) storePeerLoginFailedEvent(ctx context.Context, accountID string, login types.PeerLogin, peer *nbpeer.Peer, err error) {
+	sErr, ok := status.FromError(err)
+	if !ok {
+		return
+	}
+
+	switch sErr.Type() {
+	case status.Unauthenticated, status.PermissionDenied, status.PreconditionFailed, status.InvalidArgument:
+	default:
+		return
+	}
+
+	initiatorID := activity.SystemInitiator
+	if login.UserID != "" {
+		initiatorID = login.UserID
+	}
+
+	meta := peer.EventMeta(am.GetDNSDomain())
+	meta["reason"] = sErr.Message
+	am.StoreEvent(ctx, initiatorID, peer.ID, accountID, activity.PeerLoginFailed, meta)
+}
+
+// storePostureCheckTransitionEvents stores an event for every posture check the peer started or stopped passing
+// after its system meta changed
+func (am *DefaultAccountManager) storePostureCheckTransitionEvents(ctx context.Context, accountID string, peer *nbpeer.Peer, metaBefore nbpeer.PeerSystemMeta, postureChecks []*posture.Checks) {
+	peerBefore := *peer
+	peerBefore.Meta = metaBefore
+
+	for _, checks := range postureChecks {
+		passedBefore := peerPassesPostureChecks(ctx, peerBefore, checks)
+		passed := peerPassesPostureChecks(ctx, *peer, checks)
+		if passedBefore == passed {
+			continue
+		}
+
+		event := activity.PeerPostureCheckFailed
+		if passed {
+			event = activity.PeerPostureCheckPassed
+		}
+
+		meta := peer.EventMeta(am.GetDNSDomain())
+		meta["posture_check_id"] = checks.ID
+		meta["posture_check_name"] = checks.Name
+		am.StoreEvent(ctx, activity.SystemInitiator, peer.ID, accountID, event, meta)
+	}
+}
+
+// peerPassesPostureChecks returns true if the peer passes all the checks of the posture checks
+func peerPassesPostureChecks(ctx context.Context, peer nbpeer.Peer, postureChecks *posture.Checks) bool {
+	for _, check := range postureChecks.GetChecks() {
+		isValid, err := check.Check(ctx, peer)
+		if err != nil {
+			log.WithContext(ctx).Debugf("an error occurred check %s: on peer: %s :%s", check.Name(), peer.ID, err.Error())
+		}
+		if !isValid {
+			return false
+		}
+	}
+	return true
 }
 
 // getPeerPostureChecks returns the posture checks for the peer.
@@ -976,12 +1204,12 @@ func (am *DefaultAccountManager) checkIFPeerNeedsLoginWithoutLock(ctx context.Co
 		return nil
 	}
 
-	settings, err := am.Store.GetAccountSettings(ctx, store.LockingStrengthShare, accountID)
+	sessionSettings, err := getPeerSessionSettings(ctx, am.Store, accountID, peer)
 	if err != nil {
 		return err
 	}
 
-	if peerLoginExpired(ctx, peer, settings) {
+	if peerLoginExpired(ctx, peer, sessionSettings) {
 		return status.NewPeerLoginExpiredError()
 	}
 
@@ -1011,7 +1239,7 @@ func (am *DefaultAccountManager) getValidatedPeerWithMap(ctx context.Context, is
 		return nil, nil, nil, err
 	}
 
-	approvedPeersMap, err := am.integratedPeerValidator.GetValidatedPeers(account.Id, maps.Values(account.Groups), maps.Values(account.Peers), account.Settings.Extra)
+	approvedPeersMap, err := am.getValidatedPeers(account.Id, maps.Values(account.Groups), maps.Values(account.Peers), account.Settings.Extra)
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -1082,9 +1310,8 @@ func checkAuth(ctx context.Context, loginUserID string, peer *nbpeer.Peer) error
 	return nil
 }
 
-func peerLoginExpired(ctx context.Context, peer *nbpeer.Peer, settings *types.Settings) bool {
-	expired, expiresIn := peer.LoginExpired(settings.PeerLoginExpiration)
-	expired = settings.PeerLoginExpirationEnabled && expired
+func peerLoginExpired(ctx context.Context, peer *nbpeer.Peer, settings types.SessionSettings) bool {
+	expired, expiresIn := settings.LoginExpired(peer)
 	if expired || peer.Status.LoginExpired {
 		log.WithContext(ctx).Debugf("peer's %s login expired %v ago", peer.ID, expiresIn)
 		return true
@@ -1134,7 +1361,7 @@ func (am *DefaultAccountManager) GetPeer(ctx context.Context, accountID, peerID,
 		return nil, err
 	}
 
-	approvedPeersMap, err := am.integratedPeerValidator.GetValidatedPeers(accountID, maps.Values(account.Groups), maps.Values(account.Peers), account.Settings.Extra)
+	approvedPeersMap, err := am.getValidatedPeers(accountID, maps.Values(account.Groups), maps.Values(account.Peers), account.Settings.Extra)
 	if err != nil {
 		return nil, err
 	}
@@ -1153,7 +1380,16 @@ func (am *DefaultAccountManager) GetPeer(ctx context.Context, accountID, peerID,
 
 // UpdateAccountPeers updates all peers that belong to an account.
 // Should be called when changes have to be synced to peers.
+// Within a batch transaction the peers are updated once the changes are committed.
 func (am *DefaultAccountManager) UpdateAccountPeers(ctx context.Context, accountID string) {
+	store.RunAfterCommit(ctx, func() {
+		am.updateAccountPeers(ctx, accountID)
+		am.publishToCluster(ctx, &cluster.Message{Type: cluster.AccountPeersUpdate, AccountID: accountID})
+	})
+}
+
+// updateAccountPeers updates the peers of the account connected to this management server
+func (am *DefaultAccountManager) updateAccountPeers(ctx context.Context, accountID string) {
 	account, err := am.requestBuffer.GetAccountWithBackpressure(ctx, accountID)
 	if err != nil {
 		log.WithContext(ctx).Errorf("failed to send out updates to peers. failed to get account: %v", err)
@@ -1162,7 +1398,7 @@ func (am *DefaultAccountManager) UpdateAccountPeers(ctx context.Context, account
 
 	start := time.Now()
 
-	approvedPeersMap, err := am.integratedPeerValidator.GetValidatedPeers(account.Id, maps.Values(account.Groups), maps.Values(account.Peers), account.Settings.Extra)
+	approvedPeersMap, err := am.getValidatedPeers(account.Id, maps.Values(account.Groups), maps.Values(account.Peers), account.Settings.Extra)
 	if err != nil {
 		log.WithContext(ctx).Errorf("failed to send out updates to peers, failed to get validate peers: %v", err)
 		return
@@ -1172,6 +1408,8 @@ func (am *DefaultAccountManager) UpdateAccountPeers(ctx context.Context, account
 	semaphore := make(chan struct{}, 10)
 
 	dnsCache := &DNSConfigCache{}
+	remotePeerCache := &RemotePeerConfigCache{}
+	networkMapCache := types.NewNetworkMapCache()
 	customZone := account.GetPeersCustomZone(ctx, am.dnsDomain)
 	resourcePolicies := account.GetResourcePoliciesMap()
 	routers := account.GetResourceRoutersMap()
@@ -1200,7 +1438,7 @@ func (am *DefaultAccountManager) UpdateAccountPeers(ctx context.Context, account
 				return
 			}
 
-			remotePeerNetworkMap := account.GetPeerNetworkMap(ctx, p.ID, customZone, approvedPeersMap, resourcePolicies, routers, am.metrics.AccountManagerMetrics())
+			remotePeerNetworkMap := account.GetPeerNetworkMapWithCache(ctx, p.ID, customZone, approvedPeersMap, resourcePolicies, routers, am.metrics.AccountManagerMetrics(), networkMapCache)
 
 			proxyNetworkMap, ok := proxyNetworkMaps[p.ID]
 			if ok {
@@ -1213,7 +1451,7 @@ func (am *DefaultAccountManager) UpdateAccountPeers(ctx context.Context, account
 				return
 			}
 
-			update := toSyncResponse(ctx, nil, p, nil, nil, remotePeerNetworkMap, am.GetDNSDomain(), postureChecks, dnsCache, account.Settings.RoutingPeerDNSResolutionEnabled, extraSetting)
+			update := toSyncResponse(ctx, nil, p, nil, nil, remotePeerNetworkMap, am.GetDNSDomain(), postureChecks, dnsCache, remotePeerCache, account.Settings.RoutingPeerDNSResolutionEnabled, extraSetting)
 			am.peersUpdateManager.SendUpdate(ctx, p.ID, &UpdateMessage{Update: update, NetworkMap: remotePeerNetworkMap})
 		}(peer)
 	}
@@ -1228,7 +1466,21 @@ func (am *DefaultAccountManager) UpdateAccountPeers(ctx context.Context, account
 
 // UpdateAccountPeer updates a single peer that belongs to an account.
 // Should be called when changes need to be synced to a specific peer only.
+// Within a batch transaction the peer is updated once the changes are committed.
 func (am *DefaultAccountManager) UpdateAccountPeer(ctx context.Context, accountId string, peerId string) {
+	store.RunAfterCommit(ctx, func() {
+		if am.peersUpdateManager.HasChannel(peerId) {
+			am.updateAccountPeer(ctx, accountId, peerId)
+			return
+		}
+
+		// the peer may be connected to another management server
+		am.publishToCluster(ctx, &cluster.Message{Type: cluster.PeerUpdate, AccountID: accountId, PeerIDs: []string{peerId}})
+	})
+}
+
+// updateAccountPeer updates the peer if it is connected to this management server
+func (am *DefaultAccountManager) updateAccountPeer(ctx context.Context, accountId string, peerId string) {
 	if !am.peersUpdateManager.HasChannel(peerId) {
 		log.WithContext(ctx).Tracef("peer %s doesn't have a channel, skipping network map update", peerId)
 		return
@@ -1246,7 +1498,7 @@ func (am *DefaultAccountManager) UpdateAccountPeer(ctx context.Context, accountI
 		return
 	}
 
-	approvedPeersMap, err := am.integratedPeerValidator.GetValidatedPeers(account.Id, maps.Values(account.Groups), maps.Values(account.Peers), account.Settings.Extra)
+	approvedPeersMap, err := am.getValidatedPeers(account.Id, maps.Values(account.Groups), maps.Values(account.Peers), account.Settings.Extra)
 	if err != nil {
 		log.WithContext(ctx).Errorf("failed to send update to peer %s, failed to validate peers: %v", peerId, err)
 		return
@@ -1282,7 +1534,7 @@ func (am *DefaultAccountManager) UpdateAccountPeer(ctx context.Context, accountI
 		return
 	}
 
-	update := toSyncResponse(ctx, nil, peer, nil, nil, remotePeerNetworkMap, am.GetDNSDomain(), postureChecks, dnsCache, account.Settings.RoutingPeerDNSResolutionEnabled, extraSettings)
+	update := toSyncResponse(ctx, nil, peer, nil, nil, remotePeerNetworkMap, am.GetDNSDomain(), postureChecks, dnsCache, nil, account.Settings.RoutingPeerDNSResolutionEnabled, extraSettings)
 	am.peersUpdateManager.SendUpdate(ctx, peer.ID, &UpdateMessage{Update: update, NetworkMap: remotePeerNetworkMap})
 }
 
@@ -1290,7 +1542,7 @@ func (am *DefaultAccountManager) UpdateAccountPeer(ctx context.Context, accountI
 // If there is no peer that expires this function returns false and a duration of 0.
 // This function only considers peers that haven't been expired yet and that are connected.
 func (am *DefaultAccountManager) getNextPeerExpiration(ctx context.Context, accountID string) (time.Duration, bool) {
-	peersWithExpiry, err := am.Store.GetAccountPeersWithExpiration(ctx, store.LockingStrengthShare, accountID)
+	peersWithExpiry, sessionSettings, err := getPeersWithExpiration(ctx, am.Store, accountID)
 	if err != nil {
 		log.WithContext(ctx).Errorf("failed to get peers with expiration: %v", err)
 		return peerSchedulerRetryInterval, true
@@ -1300,19 +1552,17 @@ func (am *DefaultAccountManager) getNextPeerExpiration(ctx context.Context, acco
 		return 0, false
 	}
 
-	settings, err := am.Store.GetAccountSettings(ctx, store.LockingStrengthShare, accountID)
-	if err != nil {
-		log.WithContext(ctx).Errorf("failed to get account settings: %v", err)
-		return peerSchedulerRetryInterval, true
-	}
-
 	var nextExpiry *time.Duration
 	for _, peer := range peersWithExpiry {
 		// consider only connected peers because others will require login on connecting to the management server
 		if peer.Status.LoginExpired || !peer.Status.Connected {
 			continue
 		}
-		_, duration := peer.LoginExpired(settings.PeerLoginExpiration)
+		expiresAt := sessionSettings(peer.ID).LoginExpiresAt(peer)
+		if expiresAt.IsZero() {
+			continue
+		}
+		duration := time.Until(expiresAt)
 		if nextExpiry == nil || duration < *nextExpiry {
 			// if expiration is below 1s return 1s duration
 			// this avoids issues with ticker that can't be set to < 0
@@ -1334,7 +1584,7 @@ func (am *DefaultAccountManager) getNextPeerExpiration(ctx context.Context, acco
 // If there is no peer that expires this function returns false and a duration of 0.
 // This function only considers peers that haven't been expired yet and that are not connected.
 func (am *DefaultAccountManager) getNextInactivePeerExpiration(ctx context.Context, accountID string) (time.Duration, bool) {
-	peersWithInactivity, err := am.Store.GetAccountPeersWithInactivity(ctx, store.LockingStrengthShare, accountID)
+	peersWithInactivity, sessionSettings, err := getPeersWithInactivity(ctx, am.Store, accountID)
 	if err != nil {
 		log.WithContext(ctx).Errorf("failed to get peers with inactivity: %v", err)
 		return peerSchedulerRetryInterval, true
@@ -1344,18 +1594,16 @@ func (am *DefaultAccountManager) getNextInactivePeerExpiration(ctx context.Conte
 		return 0, false
 	}
 
-	settings, err := am.Store.GetAccountSettings(ctx, store.LockingStrengthShare, accountID)
-	if err != nil {
-		log.WithContext(ctx).Errorf("failed to get account settings: %v", err)
-		return peerSchedulerRetryInterval, true
-	}
-
 	var nextExpiry *time.Duration
 	for _, peer := range peersWithInactivity {
 		if peer.Status.LoginExpired || peer.Status.Connected {
 			continue
 		}
-		_, duration := peer.SessionExpired(settings.PeerInactivityExpiration)
+		expiresAt := sessionSettings(peer.ID).InactivityExpiresAt(peer)
+		if expiresAt.IsZero() {
+			continue
+		}
+		duration := time.Until(expiresAt)
 		if nextExpiry == nil || duration < *nextExpiry {
 			// if expiration is below 1s return 1s duration
 			// this avoids issues with ticker that can't be set to < 0
@@ -1375,19 +1623,14 @@ func (am *DefaultAccountManager) getNextInactivePeerExpiration(ctx context.Conte
 
 // getExpiredPeers returns peers that have been expired.
 func (am *DefaultAccountManager) getExpiredPeers(ctx context.Context, accountID string) ([]*nbpeer.Peer, error) {
-	peersWithExpiry, err := am.Store.GetAccountPeersWithExpiration(ctx, store.LockingStrengthShare, accountID)
-	if err != nil {
-		return nil, err
-	}
-
-	settings, err := am.Store.GetAccountSettings(ctx, store.LockingStrengthShare, accountID)
+	peersWithExpiry, sessionSettings, err := getPeersWithExpiration(ctx, am.Store, accountID)
 	if err != nil {
 		return nil, err
 	}
 
 	var peers []*nbpeer.Peer
 	for _, peer := range peersWithExpiry {
-		expired, _ := peer.LoginExpired(settings.PeerLoginExpiration)
+		expired, _ := sessionSettings(peer.ID).LoginExpired(peer)
 		if expired {
 			peers = append(peers, peer)
 		}
@@ -1398,19 +1641,14 @@ func (am *DefaultAccountManager) getExpiredPeers(ctx context.Context, accountID
 
 // getInactivePeers returns peers that have been expired by inactivity
 func (am *DefaultAccountManager) getInactivePeers(ctx context.Context, accountID string) ([]*nbpeer.Peer, error) {
-	peersWithInactivity, err := am.Store.GetAccountPeersWithInactivity(ctx, store.LockingStrengthShare, accountID)
-	if err != nil {
-		return nil, err
-	}
-
-	settings, err := am.Store.GetAccountSettings(ctx, store.LockingStrengthShare, accountID)
+	peersWithInactivity, sessionSettings, err := getPeersWithInactivity(ctx, am.Store, accountID)
 	if err != nil {
 		return nil, err
 	}
 
 	var peers []*nbpeer.Peer
 	for _, inactivePeer := range peersWithInactivity {
-		inactive, _ := inactivePeer.SessionExpired(settings.PeerInactivityExpiration)
+		inactive, _ := sessionSettings(inactivePeer.ID).SessionExpired(inactivePeer)
 		if inactive {
 			peers = append(peers, inactivePeer)
 		}
@@ -1439,6 +1677,36 @@ func getPeerGroupIDs(ctx context.Context, transaction store.Store, accountID str
 	return groupIDs, err
 }
 
+// validatePeerDNSAliases checks that the DNS aliases are valid DNS labels not used by any other peer of the account
+func validatePeerDNSAliases(ctx context.Context, transaction store.Store, accountID string, peer *nbpeer.Peer, aliases []string) error {
+	if len(aliases) > maxPeerDNSAliases {
+		return status.Errorf(status.InvalidArgument, "a peer can't have more than %d DNS aliases", maxPeerDNSAliases)
+	}
+
+	existingLabels, err := getPeerDNSLabels(ctx, transaction, accountID)
+	if err != nil {
+		return err
+	}
+
+	// the current aliases of the peer can be kept
+	for _, alias := range peer.DNSAliases {
+		delete(existingLabels, alias)
+	}
+
+	for i, alias := range aliases {
+		label, err := nbdns.GetParsedDomainLabel(alias)
+		if err != nil || label != alias {
+			return status.Errorf(status.InvalidArgument, "invalid DNS alias %s: only lowercase letters, digits and hyphens are allowed", alias)
+		}
+
+		if _, ok := existingLabels[alias]; ok || slices.Contains(aliases[:i], alias) {
+			return status.Errorf(status.AlreadyExists, "DNS alias %s is already in use", alias)
+		}
+	}
+
+	return nil
+}
+
 func getPeerDNSLabels(ctx context.Context, transaction store.Store, accountID string) (types.LookupMap, error) {
 	dnsLabels, err := transaction.GetPeerLabelsInAccount(ctx, store.LockingStrengthShare, accountID)
 	if err != nil {
@@ -1495,7 +1763,7 @@ func deletePeers(ctx context.Context, am *DefaultAccountManager, transaction sto
 			},
 			NetworkMap: &types.NetworkMap{},
 		})
-		am.peersUpdateManager.CloseChannel(ctx, peer.ID)
+		am.closePeerChannels(ctx, accountID, []string{peer.ID})
 		peerDeletedEvents = append(peerDeletedEvents, func() {
 			am.StoreEvent(ctx, userID, peer.ID, accountID, activity.PeerRemovedByUser, peer.EventMeta(am.GetDNSDomain()))
 		})
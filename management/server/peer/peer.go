@@ -1,6 +1,7 @@
 package peer
 
 import (
+	"maps"
 	"net"
 	"net/netip"
 	"slices"
@@ -21,6 +22,8 @@ type Peer struct {
 	Key string `gorm:"index"`
 	// IP address of the Peer
 	IP net.IP `gorm:"serializer:json"`
+	// StaticIP indicates that the IP was pinned by an administrator and is kept when the network range changes
+	StaticIP bool
 	// Meta is a Peer system meta data
 	Meta PeerSystemMeta `gorm:"embedded;embeddedPrefix:meta_"`
 	// Name is peer's name (machine name)
@@ -28,6 +31,8 @@ type Peer struct {
 	// DNSLabel is the parsed peer name for domain resolution. It is used to form an FQDN by appending the account's
 	// domain to the peer label. e.g. peer-dns-label.netbird.cloud
 	DNSLabel string
+	// DNSAliases is a list of DNS labels set by users that resolve to the peer independently of its name
+	DNSAliases []string `gorm:"serializer:json"`
 	// Status peer's management connection status
 	Status *PeerStatus `gorm:"embedded;embeddedPrefix:peer_status_"`
 	// The user ID that registered the peer
@@ -54,6 +59,13 @@ type Peer struct {
 	ExtraDNSLabels []string `gorm:"serializer:json"`
 	// AllowExtraDNSLabels indicates whether the peer allows extra DNS labels to be used for resolving the peer
 	AllowExtraDNSLabels bool
+	// SetupKeyID is the ID of the setup key the peer was registered with, empty if it was added by a user
+	SetupKeyID string
+	// Labels are the key/value labels set by users, the policies can select the peers by their labels
+	Labels map[string]string `gorm:"serializer:json"`
+	// ExitNodeQuotaExceeded indicates that the peer exceeded the exit node quota of its groups in the current month,
+	// the exit routes aren't distributed to the peer until the quota resets
+	ExitNodeQuotaExceeded bool
 }
 
 type PeerStatus struct { //nolint:revive
@@ -112,6 +124,21 @@ type PeerSystemMeta struct { //nolint:revive
 	SystemManufacturer string
 	Environment        Environment `gorm:"serializer:json"`
 	Files              []File      `gorm:"serializer:json"`
+	// RouterMode is set when the peer runs as a gateway appliance
+	RouterMode bool
+	// AdvertisedRoutes are the LAN prefixes the peer advertises in router mode and the routes it learned from the
+	// routers of its site
+	AdvertisedRoutes []string `gorm:"serializer:json"`
+	// BootTime is the time the system of the peer booted at, zero if the peer doesn't report it
+	BootTime time.Time
+}
+
+// Uptime returns the time since the system of the peer booted, zero if the peer doesn't report its boot time
+func (p PeerSystemMeta) Uptime() time.Duration {
+	if p.BootTime.IsZero() {
+		return 0
+	}
+	return time.Since(p.BootTime)
 }
 
 func (p PeerSystemMeta) isEqual(other PeerSystemMeta) bool {
@@ -155,7 +182,20 @@ func (p PeerSystemMeta) isEqual(other PeerSystemMeta) bool {
 		p.SystemProductName == other.SystemProductName &&
 		p.SystemManufacturer == other.SystemManufacturer &&
 		p.Environment.Cloud == other.Environment.Cloud &&
-		p.Environment.Platform == other.Environment.Platform
+		p.Environment.Platform == other.Environment.Platform &&
+		p.RouterMode == other.RouterMode &&
+		slices.Equal(p.AdvertisedRoutes, other.AdvertisedRoutes) &&
+		isBootTimeEqual(p.BootTime, other.BootTime)
+}
+
+// isBootTimeEqual checks if the boot times are of the same boot, the boot time derived from the uptime by some
+// systems drifts by a few seconds between the reports
+func isBootTimeEqual(bootTime, other time.Time) bool {
+	if bootTime.IsZero() || other.IsZero() {
+		return bootTime.IsZero() == other.IsZero()
+	}
+	diff := bootTime.Sub(other)
+	return diff > -time.Minute && diff < time.Minute
 }
 
 func (p PeerSystemMeta) isEmpty() bool {
@@ -175,7 +215,9 @@ func (p PeerSystemMeta) isEmpty() bool {
 		p.SystemManufacturer == "" &&
 		p.Environment.Cloud == "" &&
 		p.Environment.Platform == "" &&
-		len(p.Files) == 0
+		len(p.Files) == 0 &&
+		!p.RouterMode &&
+		len(p.AdvertisedRoutes) == 0
 }
 
 // AddedWithSSOLogin indicates whether this peer has been added with an SSO login by a user.
@@ -194,9 +236,11 @@ func (p *Peer) Copy() *Peer {
 		AccountID:                   p.AccountID,
 		Key:                         p.Key,
 		IP:                          p.IP,
+		StaticIP:                    p.StaticIP,
 		Meta:                        p.Meta,
 		Name:                        p.Name,
 		DNSLabel:                    p.DNSLabel,
+		DNSAliases:                  slices.Clone(p.DNSAliases),
 		Status:                      peerStatus,
 		UserID:                      p.UserID,
 		SSHKey:                      p.SSHKey,
@@ -209,6 +253,9 @@ func (p *Peer) Copy() *Peer {
 		InactivityExpirationEnabled: p.InactivityExpirationEnabled,
 		ExtraDNSLabels:              slices.Clone(p.ExtraDNSLabels),
 		AllowExtraDNSLabels:         p.AllowExtraDNSLabels,
+		SetupKeyID:                  p.SetupKeyID,
+		Labels:                      maps.Clone(p.Labels),
+		ExitNodeQuotaExceeded:       p.ExitNodeQuotaExceeded,
 	}
 }
 
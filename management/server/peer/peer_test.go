@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/netip"
 	"testing"
+	"time"
 )
 
 // FQDNOld is the original implementation for benchmarking purposes
@@ -83,3 +84,17 @@ func TestIsEqual(t *testing.T) {
 		t.Error("meta1 should be equal to meta2")
 	}
 }
+
+func TestIsEqual_BootTime(t *testing.T) {
+	bootTime := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	if !(PeerSystemMeta{BootTime: bootTime}).isEqual(PeerSystemMeta{BootTime: bootTime.Add(2 * time.Second)}) {
+		t.Error("boot times drifting by a few seconds should be of the same boot")
+	}
+	if (PeerSystemMeta{BootTime: bootTime}).isEqual(PeerSystemMeta{BootTime: bootTime.Add(time.Hour)}) {
+		t.Error("a reboot should change the meta")
+	}
+	if (PeerSystemMeta{}).isEqual(PeerSystemMeta{BootTime: bootTime}) {
+		t.Error("a reported boot time should change the meta")
+	}
+}
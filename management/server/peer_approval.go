@@ -0,0 +1,165 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/notifications"
+	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+	"github.com/netbirdio/netbird/management/server/permissions"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
+	"github.com/netbirdio/netbird/management/server/types"
+)
+
+// notificationTimeout bounds the delivery of a notification to the administrators
+const notificationTimeout = 30 * time.Second
+
+// SetNotifier sets the notifier used to inform account administrators about events requiring their attention.
+// It can be replaced while the server is running, e.g. when the notifications config is reloaded.
+func (am *DefaultAccountManager) SetNotifier(notifier notifications.Notifier) {
+	am.notifierMu.Lock()
+	defer am.notifierMu.Unlock()
+	am.notifier = notifier
+}
+
+func (am *DefaultAccountManager) getNotifier() notifications.Notifier {
+	am.notifierMu.RLock()
+	defer am.notifierMu.RUnlock()
+	return am.notifier
+}
+
+// GetPendingApprovalPeers returns the peers of the account that are waiting for an administrator approval
+func (am *DefaultAccountManager) GetPendingApprovalPeers(ctx context.Context, accountID, userID string) ([]*nbpeer.Peer, error) {
+	allowed, err := am.permissionsManager.ValidateUserPermissions(ctx, accountID, userID, permissions.Peers, permissions.Write)
+	if err != nil {
+		return nil, status.NewPermissionValidationError(err)
+	}
+	if !allowed {
+		return nil, status.NewPermissionDeniedError()
+	}
+
+	peers, err := am.Store.GetAccountPeers(ctx, store.LockingStrengthShare, accountID, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	pendingPeers := make([]*nbpeer.Peer, 0)
+	for _, peer := range peers {
+		if peer.Status != nil && peer.Status.RequiresApproval {
+			pendingPeers = append(pendingPeers, peer)
+		}
+	}
+
+	return pendingPeers, nil
+}
+
+// ApprovePeer approves a peer waiting for an administrator approval so that it starts receiving the network map
+func (am *DefaultAccountManager) ApprovePeer(ctx context.Context, accountID, userID, peerID string) (*nbpeer.Peer, error) {
+	allowed, err := am.permissionsManager.ValidateUserPermissions(ctx, accountID, userID, permissions.Peers, permissions.Write)
+	if err != nil {
+		return nil, status.NewPermissionValidationError(err)
+	}
+	if !allowed {
+		return nil, status.NewPermissionDeniedError()
+	}
+
+	unlock := am.Store.AcquireWriteLockByUID(ctx, accountID)
+	defer unlock()
+
+	var peer *nbpeer.Peer
+	var updateAccountPeers bool
+
+	err = am.Store.ExecuteInTransaction(ctx, func(transaction store.Store) error {
+		peer, err = transaction.GetPeerByID(ctx, store.LockingStrengthUpdate, accountID, peerID)
+		if err != nil {
+			return err
+		}
+
+		if !peer.Status.RequiresApproval {
+			return status.Errorf(status.PreconditionFailed, "peer %s doesn't require approval", peerID)
+		}
+
+		peer.Status.RequiresApproval = false
+		if err = transaction.SavePeerStatus(ctx, store.LockingStrengthUpdate, accountID, peerID, *peer.Status); err != nil {
+			return err
+		}
+
+		if err = transaction.IncrementNetworkSerial(ctx, store.LockingStrengthUpdate, accountID); err != nil {
+			return err
+		}
+
+		updateAccountPeers, err = isPeerInActiveGroup(ctx, transaction, accountID, peerID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	am.StoreEvent(ctx, userID, peer.ID, accountID, activity.PeerApproved, peer.EventMeta(am.GetDNSDomain()))
+
+	if updateAccountPeers {
+		am.UpdateAccountPeers(ctx, accountID)
+	} else {
+		am.UpdateAccountPeer(ctx, accountID, peerID)
+	}
+
+	return peer, nil
+}
+
+// requiresApprovalOnRegistration returns true if a newly registered peer has to be approved by an administrator
+func requiresApprovalOnRegistration(settings *types.Settings) bool {
+	return settings != nil && settings.Extra != nil && settings.Extra.PeerApprovalEnabled
+}
+
+// getValidatedPeers returns the peers validated by the integrated validator leaving out
+// the ones that are still waiting for an administrator approval.
+func (am *DefaultAccountManager) getValidatedPeers(accountID string, groups []*types.Group, peers []*nbpeer.Peer, extraSettings *types.ExtraSettings) (map[string]struct{}, error) {
+	validatedPeers, err := am.integratedPeerValidator.GetValidatedPeers(accountID, groups, peers, extraSettings)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, peer := range peers {
+		if peer.Status != nil && peer.Status.RequiresApproval {
+			delete(validatedPeers, peer.ID)
+		}
+	}
+
+	return validatedPeers, nil
+}
+
+// notifyPeerApprovalPending stores the pending approval event and notifies the account administrators about it
+func (am *DefaultAccountManager) notifyPeerApprovalPending(ctx context.Context, accountID string, peer *nbpeer.Peer) {
+	meta := peer.EventMeta(am.GetDNSDomain())
+	am.StoreEvent(ctx, activity.SystemInitiator, peer.ID, accountID, activity.PeerApprovalPending, meta)
+
+	notifier := am.getNotifier()
+	if notifier == nil {
+		return
+	}
+
+	notification := &notifications.Notification{
+		AccountID: accountID,
+		Event:     activity.PeerApprovalPending.StringCode(),
+		Subject:   fmt.Sprintf("NetBird peer %s is waiting for approval", peer.Name),
+		Message: fmt.Sprintf("A new peer %s (%s, %s) has been registered and will not receive the network map until an administrator approves it.",
+			peer.Name, peer.Meta.OS, peer.IP),
+		Timestamp: time.Now().UTC(),
+		Meta:      meta,
+	}
+
+	go func() {
+		// the notification outlives the login request of the peer
+		notifyCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), notificationTimeout)
+		defer cancel()
+
+		if err := notifier.Notify(notifyCtx, notification); err != nil {
+			log.WithContext(ctx).Errorf("failed to notify about peer %s pending approval: %v", peer.ID, err)
+		}
+	}()
+}
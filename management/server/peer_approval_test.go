@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"net"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/integrations/port_forwarding"
+	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+	"github.com/netbirdio/netbird/management/server/permissions"
+	"github.com/netbirdio/netbird/management/server/settings"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
+	"github.com/netbirdio/netbird/management/server/telemetry"
+	"github.com/netbirdio/netbird/management/server/types"
+)
+
+func TestDefaultAccountManager_PeerApproval(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("The SQLite store is not properly supported by Windows yet")
+	}
+
+	s, cleanup, err := store.NewTestStoreFromSQL(context.Background(), "testdata/extended-store.sql", t.TempDir())
+	require.NoError(t, err)
+	defer cleanup()
+
+	metrics, err := telemetry.NewDefaultAppMetrics(context.Background())
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+	settingsMockManager := settings.NewMockManager(ctrl)
+	settingsMockManager.EXPECT().
+		GetExtraSettings(gomock.Any(), gomock.Any()).
+		Return(&types.ExtraSettings{}, nil).
+		AnyTimes()
+
+	am, err := BuildManager(context.Background(), s, NewPeersUpdateManager(nil), nil, "", "netbird.cloud", &activity.InMemoryEventStore{}, nil, false, MocIntegratedValidator{}, metrics, port_forwarding.NewControllerMock(), settingsMockManager, permissions.NewManager(s))
+	require.NoError(t, err)
+
+	const (
+		accountID   = "bf1c8084-ba50-4ce7-9439-34653001fc3b"
+		adminUserID = "edafee4e-63fb-11ec-90d6-0242ac120003"
+		userID      = "f4f6d672-63fb-11ec-90d6-0242ac120003"
+		setupKey    = "A2C8E62B-38F5-4553-B31E-DD66C696CEBB"
+	)
+
+	account, err := s.GetAccount(context.Background(), accountID)
+	require.NoError(t, err)
+	account.Settings.Extra = &types.ExtraSettings{PeerApprovalEnabled: true}
+	require.NoError(t, s.SaveAccount(context.Background(), account))
+
+	newPeer := &nbpeer.Peer{
+		Key: "pendingPeerKey",
+		IP:  net.IP{123, 123, 123, 123},
+		Meta: nbpeer.PeerSystemMeta{
+			Hostname: "pendingPeer",
+			GoOS:     "linux",
+		},
+		Status: &nbpeer.PeerStatus{Connected: false, LastSeen: time.Now()},
+	}
+
+	addedPeer, networkMap, _, err := am.AddPeer(context.Background(), setupKey, "", newPeer)
+	require.NoError(t, err)
+	assert.True(t, addedPeer.Status.RequiresApproval, "peer should wait for approval")
+	assert.Empty(t, networkMap.Peers, "pending peer should not receive remote peers")
+
+	validatedPeers, err := am.GetValidatedPeers(context.Background(), accountID)
+	require.NoError(t, err)
+	assert.NotContains(t, validatedPeers, addedPeer.ID)
+
+	_, err = am.GetPendingApprovalPeers(context.Background(), accountID, userID)
+	require.Error(t, err)
+	sErr, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, status.PermissionDenied, sErr.Type())
+
+	pendingPeers, err := am.GetPendingApprovalPeers(context.Background(), accountID, adminUserID)
+	require.NoError(t, err)
+	require.Len(t, pendingPeers, 1)
+	assert.Equal(t, addedPeer.ID, pendingPeers[0].ID)
+
+	approvedPeer, err := am.ApprovePeer(context.Background(), accountID, adminUserID, addedPeer.ID)
+	require.NoError(t, err)
+	assert.False(t, approvedPeer.Status.RequiresApproval)
+
+	storedPeer, err := s.GetPeerByID(context.Background(), store.LockingStrengthShare, accountID, addedPeer.ID)
+	require.NoError(t, err)
+	assert.False(t, storedPeer.Status.RequiresApproval)
+
+	validatedPeers, err = am.GetValidatedPeers(context.Background(), accountID)
+	require.NoError(t, err)
+	assert.Contains(t, validatedPeers, addedPeer.ID)
+
+	_, err = am.ApprovePeer(context.Background(), accountID, adminUserID, addedPeer.ID)
+	require.Error(t, err, "approving an already approved peer should fail")
+}
@@ -0,0 +1,149 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/notifications"
+	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+	"github.com/netbirdio/netbird/management/server/store"
+)
+
+// peerLoginExpiration is the login expiration time of a peer
+type peerLoginExpiration struct {
+	peer      *nbpeer.Peer
+	expiresAt time.Time
+}
+
+// checkAndSchedulePeerLoginExpirationNotification schedules the notification of the users of the peers whose login is about to expire
+func (am *DefaultAccountManager) checkAndSchedulePeerLoginExpirationNotification(ctx context.Context, accountID string) {
+	am.peerLoginExpiryNotice.Cancel(ctx, []string{accountID})
+	if nextRun, ok := am.getNextPeerLoginExpirationNotification(ctx, accountID); ok {
+		go am.peerLoginExpiryNotice.Schedule(ctx, nextRun, accountID, am.peerLoginExpirationNotificationJob(ctx, accountID))
+	}
+}
+
+// peerLoginExpirationNotificationJob notifies the users of the peers whose login expires within the notification period
+// and returns the minimum duration in which the next notification of the account is due if found
+func (am *DefaultAccountManager) peerLoginExpirationNotificationJob(ctx context.Context, accountID string) func() (time.Duration, bool) {
+	return func() (time.Duration, bool) {
+		notice, expirations, err := am.getPeerLoginExpirations(ctx, accountID)
+		if err != nil {
+			log.WithContext(ctx).Errorf("failed to get peer login expirations for account %s: %v", accountID, err)
+			return peerSchedulerRetryInterval, true
+		}
+
+		now := time.Now().UTC()
+		for _, expiration := range expirations {
+			if expiration.expiresAt.Add(-notice).After(now) || am.peerLoginExpirationNotified(expiration) {
+				continue
+			}
+			am.notifiedPeerLogins.Store(expiration.peer.ID, expiration.expiresAt)
+			am.notifyPeerLoginExpiring(ctx, accountID, expiration.peer, expiration.expiresAt)
+		}
+
+		return am.getNextPeerLoginExpirationNotification(ctx, accountID)
+	}
+}
+
+// getNextPeerLoginExpirationNotification returns the minimum duration in which the next notification of the account is due.
+// If there is no notification to send this function returns false and a duration of 0.
+func (am *DefaultAccountManager) getNextPeerLoginExpirationNotification(ctx context.Context, accountID string) (time.Duration, bool) {
+	notice, expirations, err := am.getPeerLoginExpirations(ctx, accountID)
+	if err != nil {
+		log.WithContext(ctx).Errorf("failed to get peer login expirations for account %s: %v", accountID, err)
+		return peerSchedulerRetryInterval, true
+	}
+
+	var nextNotification *time.Duration
+	for _, expiration := range expirations {
+		if am.peerLoginExpirationNotified(expiration) {
+			continue
+		}
+		duration := time.Until(expiration.expiresAt.Add(-notice))
+		if nextNotification == nil || duration < *nextNotification {
+			// this avoids issues with ticker that can't be set to < 0
+			if duration < time.Second {
+				return time.Second, true
+			}
+			nextNotification = &duration
+		}
+	}
+
+	if nextNotification == nil {
+		return 0, false
+	}
+
+	return *nextNotification, true
+}
+
+// getPeerLoginExpirations returns the notification period of the account and the upcoming login expirations of its
+// connected peers. No expiration is returned when the notifications are disabled.
+func (am *DefaultAccountManager) getPeerLoginExpirations(ctx context.Context, accountID string) (time.Duration, []peerLoginExpiration, error) {
+	settings, err := am.Store.GetAccountSettings(ctx, store.LockingStrengthShare, accountID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if settings.PeerLoginExpirationNotification <= 0 {
+		return 0, nil, nil
+	}
+
+	peersWithExpiry, sessionSettings, err := getPeersWithExpiration(ctx, am.Store, accountID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var expirations []peerLoginExpiration
+	for _, peer := range peersWithExpiry {
+		if peer.Status.LoginExpired || !peer.Status.Connected {
+			continue
+		}
+		expiresAt := sessionSettings(peer.ID).LoginExpiresAt(peer)
+		if expiresAt.IsZero() || !expiresAt.After(time.Now().UTC()) {
+			continue
+		}
+		expirations = append(expirations, peerLoginExpiration{peer: peer, expiresAt: expiresAt})
+	}
+
+	return settings.PeerLoginExpirationNotification, expirations, nil
+}
+
+// peerLoginExpirationNotified checks if the user of the peer was already notified about the login expiration
+func (am *DefaultAccountManager) peerLoginExpirationNotified(expiration peerLoginExpiration) bool {
+	notified, ok := am.notifiedPeerLogins.Load(expiration.peer.ID)
+	return ok && notified.(time.Time).Equal(expiration.expiresAt)
+}
+
+// notifyPeerLoginExpiring stores the login expiring event, which integrations can forward to the user,
+// and notifies the account administrators about it
+func (am *DefaultAccountManager) notifyPeerLoginExpiring(ctx context.Context, accountID string, peer *nbpeer.Peer, expiresAt time.Time) {
+	meta := peer.EventMeta(am.GetDNSDomain())
+	meta["expires_at"] = expiresAt
+	am.StoreEvent(ctx, activity.SystemInitiator, peer.ID, accountID, activity.PeerLoginExpiring, meta)
+
+	notifier := am.getNotifier()
+	if notifier == nil {
+		return
+	}
+
+	notification := &notifications.Notification{
+		AccountID: accountID,
+		Event:     activity.PeerLoginExpiring.StringCode(),
+		Subject:   fmt.Sprintf("NetBird peer %s login expires soon", peer.Name),
+		Message: fmt.Sprintf("The login of peer %s (%s, %s) expires at %s. Its user has to log in again to keep the peer connected.",
+			peer.Name, peer.Meta.OS, peer.IP, expiresAt.Format(time.RFC1123)),
+		Timestamp: time.Now().UTC(),
+		Meta:      meta,
+	}
+
+	go func() {
+		if err := notifier.Notify(ctx, notification); err != nil {
+			log.WithContext(ctx).Errorf("failed to notify about peer %s login expiring: %v", peer.ID, err)
+		}
+	}()
+}
@@ -10,6 +10,7 @@ import (
 	"net/netip"
 	"os"
 	"runtime"
+	"slices"
 	"testing"
 	"time"
 
@@ -172,7 +173,7 @@ func TestAccountManager_GetNetworkMap(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	setupKey, err := manager.CreateSetupKey(context.Background(), account.Id, "test-key", types.SetupKeyReusable, time.Hour, nil, 999, userId, false, false)
+	setupKey, err := manager.CreateSetupKey(context.Background(), account.Id, "test-key", types.SetupKeyReusable, time.Hour, nil, 999, userId, false, false, types.SetupKeyProvisioning{})
 	if err != nil {
 		t.Fatal("error creating setup key")
 		return
@@ -421,7 +422,7 @@ func TestAccountManager_GetPeerNetwork(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	setupKey, err := manager.CreateSetupKey(context.Background(), account.Id, "test-key", types.SetupKeyReusable, time.Hour, nil, 999, userId, false, false)
+	setupKey, err := manager.CreateSetupKey(context.Background(), account.Id, "test-key", types.SetupKeyReusable, time.Hour, nil, 999, userId, false, false, types.SetupKeyProvisioning{})
 	if err != nil {
 		t.Fatal("error creating setup key")
 		return
@@ -493,7 +494,7 @@ func TestDefaultAccountManager_GetPeer(t *testing.T) {
 	}
 
 	// two peers one added by a regular user and one with a setup key
-	setupKey, err := manager.CreateSetupKey(context.Background(), account.Id, "test-key", types.SetupKeyReusable, time.Hour, nil, 999, adminUser, false, false)
+	setupKey, err := manager.CreateSetupKey(context.Background(), account.Id, "test-key", types.SetupKeyReusable, time.Hour, nil, 999, adminUser, false, false, types.SetupKeyProvisioning{})
 	if err != nil {
 		t.Fatal("error creating setup key")
 		return
@@ -1157,7 +1158,7 @@ func TestToSyncResponse(t *testing.T) {
 	}
 	dnsCache := &DNSConfigCache{}
 
-	response := toSyncResponse(context.Background(), config, peer, turnRelayToken, turnRelayToken, networkMap, dnsName, checks, dnsCache, true, nil)
+	response := toSyncResponse(context.Background(), config, peer, turnRelayToken, turnRelayToken, networkMap, dnsName, checks, dnsCache, nil, true, nil)
 
 	assert.NotNil(t, response)
 	// assert peer config
@@ -1771,7 +1772,7 @@ func TestPeerAccountPeersUpdate(t *testing.T) {
 				Port:   nbdns.DefaultDNSPort,
 			}},
 			[]string{"groupC"},
-			true, []string{}, true, userID, false,
+			true, []string{}, true, userID, false, nbdns.RoutedAnswersDisabled, "",
 		)
 		require.NoError(t, err)
 
@@ -1866,3 +1867,45 @@ func Test_DeletePeer(t *testing.T) {
 	assert.NotContains(t, group.Peers, "peer1")
 
 }
+
+func TestDefaultAccountManager_UpdatePeer_DNSAliases(t *testing.T) {
+	manager, err := createManager(t)
+	require.NoError(t, err, "unable to create account manager")
+
+	accountID, err := manager.GetAccountIDByUserID(context.Background(), userID, "")
+	require.NoError(t, err, "unable to create an account")
+
+	addPeer := func(hostname string) *nbpeer.Peer {
+		key, err := wgtypes.GenerateKey()
+		require.NoError(t, err, "unable to generate WireGuard key")
+		peer, _, _, err := manager.AddPeer(context.Background(), "", userID, &nbpeer.Peer{
+			Key:  key.PublicKey().String(),
+			Meta: nbpeer.PeerSystemMeta{Hostname: hostname},
+		})
+		require.NoError(t, err, "unable to add peer")
+		return peer
+	}
+
+	peer1 := addPeer("laptop")
+	peer2 := addPeer("laptop")
+
+	update := peer1.Copy()
+	update.DNSAliases = []string{"build-server"}
+	updated, err := manager.UpdatePeer(context.Background(), accountID, userID, update)
+	require.NoError(t, err, "unable to set the DNS aliases")
+	assert.Equal(t, []string{"build-server"}, updated.DNSAliases)
+
+	update = peer2.Copy()
+	for _, aliases := range [][]string{{"build-server"}, {peer1.DNSLabel}, {"Build_Server"}, {"ci", "ci"}} {
+		update.DNSAliases = aliases
+		_, err = manager.UpdatePeer(context.Background(), accountID, userID, update)
+		assert.Error(t, err, "aliases %v should be rejected", aliases)
+	}
+
+	account, err := manager.Store.GetAccount(context.Background(), accountID)
+	require.NoError(t, err)
+	zone := account.GetPeersCustomZone(context.Background(), "netbird.cloud")
+	assert.True(t, slices.ContainsFunc(zone.Records, func(record nbdns.SimpleRecord) bool {
+		return record.Name == "build-server.netbird.cloud" && record.RData == peer1.IP.String()
+	}), "DNS alias should be part of the peers zone")
+}
@@ -0,0 +1,283 @@
+package peeractions
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/netbirdio/netbird/management/proto"
+	"github.com/netbirdio/netbird/management/server/account"
+	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/peeractions/types"
+	"github.com/netbirdio/netbird/management/server/permissions"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
+)
+
+const (
+	// ActionTTL is the time a peer has to run a requested action, a peer reconnecting later doesn't run it anymore
+	ActionTTL = 5 * time.Minute
+	// MaxOutputSize limits the size of the output reported by the peers
+	MaxOutputSize = 64 << 10
+)
+
+// PeerUpdater sends the actions to the peers connected to the management service
+type PeerUpdater interface {
+	// SendPeerAction returns false if the peer isn't connected
+	SendPeerAction(ctx context.Context, peerID string, action *proto.PeerAction) bool
+}
+
+type Manager interface {
+	// RequestAction asks the peer to run a signed action
+	RequestAction(ctx context.Context, accountID, userID, peerID, action string) (*types.Action, error)
+	GetPeerActions(ctx context.Context, accountID, userID, peerID string) ([]*types.Action, error)
+	GetAction(ctx context.Context, accountID, userID, peerID, actionID string) (*types.Action, error)
+	// ReportStatus records the result of an action of the peer with the WireGuard public key. The key of the peer is
+	// replaced with the new key of a rotate key action.
+	ReportStatus(ctx context.Context, peerKey string, actionStatus *proto.PeerActionStatus) error
+}
+
+type managerImpl struct {
+	store              store.Store
+	permissionsManager permissions.Manager
+	accountManager     account.Manager
+	peerUpdater        PeerUpdater
+	// signingKey signs the actions, the peers verify them with the public key of the NetbirdConfig
+	signingKey ed25519.PrivateKey
+}
+
+type mockManager struct {
+}
+
+// signingKeys caches the signing keys derived from the secrets, the public key is sent with every NetbirdConfig
+var signingKeys sync.Map
+
+// SigningKey returns the key the actions are signed with, derived from the secret of the management service
+func SigningKey(secret string) ed25519.PrivateKey {
+	if key, ok := signingKeys.Load(secret); ok {
+		return key.(ed25519.PrivateKey)
+	}
+	seed := sha256.Sum256([]byte("netbird-peer-actions:" + secret))
+	key := ed25519.NewKeyFromSeed(seed[:])
+	signingKeys.Store(secret, key)
+	return key
+}
+
+// PublicKey returns the public key the peers verify the actions with, nil if the secret isn't set
+func PublicKey(secret string) []byte {
+	if secret == "" {
+		return nil
+	}
+	return SigningKey(secret).Public().(ed25519.PublicKey)
+}
+
+// NewManager creates a peer actions manager signing the actions with a key derived from the secret
+func NewManager(store store.Store, permissionsManager permissions.Manager, accountManager account.Manager, peerUpdater PeerUpdater, secret string) Manager {
+	return &managerImpl{
+		store:              store,
+		permissionsManager: permissionsManager,
+		accountManager:     accountManager,
+		peerUpdater:        peerUpdater,
+		signingKey:         SigningKey(secret),
+	}
+}
+
+func (m *managerImpl) RequestAction(ctx context.Context, accountID, userID, peerID, action string) (*types.Action, error) {
+	if err := m.validatePermissions(ctx, accountID, userID, permissions.Write); err != nil {
+		return nil, err
+	}
+
+	if !types.IsValidAction(action) {
+		return nil, status.Errorf(status.InvalidArgument, "invalid peer action %q", action)
+	}
+
+	peer, err := m.store.GetPeerByID(ctx, store.LockingStrengthShare, accountID, peerID)
+	if err != nil {
+		return nil, err
+	}
+
+	peerAction := types.NewAction(accountID, peerID, userID, action, ActionTTL)
+
+	if err = m.store.SavePeerAction(ctx, store.LockingStrengthUpdate, peerAction); err != nil {
+		return nil, fmt.Errorf("failed to save peer action: %w", err)
+	}
+
+	if !m.peerUpdater.SendPeerAction(ctx, peerID, m.toProtoAction(peer.Key, peerAction)) {
+		if err = m.store.DeletePeerAction(ctx, store.LockingStrengthUpdate, accountID, peerAction.ID); err != nil {
+			log.WithContext(ctx).Errorf("failed to delete the peer action %s of the disconnected peer: %v", peerAction.ID, err)
+		}
+		return nil, status.Errorf(status.PreconditionFailed, "peer %s is not connected", peerID)
+	}
+
+	meta := peerAction.EventMeta()
+	meta["peer_name"] = peer.Name
+	m.accountManager.StoreEvent(ctx, userID, peerID, accountID, activity.PeerActionRequested, meta)
+
+	return peerAction, nil
+}
+
+func (m *managerImpl) GetPeerActions(ctx context.Context, accountID, userID, peerID string) ([]*types.Action, error) {
+	if err := m.validatePermissions(ctx, accountID, userID, permissions.Read); err != nil {
+		return nil, err
+	}
+
+	if _, err := m.store.GetPeerByID(ctx, store.LockingStrengthShare, accountID, peerID); err != nil {
+		return nil, err
+	}
+
+	return m.store.GetPeerActions(ctx, store.LockingStrengthShare, accountID, peerID)
+}
+
+func (m *managerImpl) GetAction(ctx context.Context, accountID, userID, peerID, actionID string) (*types.Action, error) {
+	if err := m.validatePermissions(ctx, accountID, userID, permissions.Read); err != nil {
+		return nil, err
+	}
+
+	return m.getPeerAction(ctx, accountID, peerID, actionID)
+}
+
+func (m *managerImpl) ReportStatus(ctx context.Context, peerKey string, actionStatus *proto.PeerActionStatus) error {
+	peer, err := m.store.GetPeerByPeerPubKey(ctx, store.LockingStrengthShare, peerKey)
+	if err != nil {
+		return err
+	}
+
+	unlock := m.store.AcquireWriteLockByUID(ctx, peer.AccountID)
+	defer unlock()
+
+	action, err := m.getPeerAction(ctx, peer.AccountID, peer.ID, actionStatus.GetId())
+	if err != nil {
+		return err
+	}
+	if action.Status != types.StatusPending {
+		return status.Errorf(status.PreconditionFailed, "peer action %s is %s", action.ID, action.Status)
+	}
+
+	action.Status = types.StatusSucceeded
+	switch {
+	case actionStatus.GetDeclined():
+		action.Status = types.StatusDeclined
+		action.Error = actionStatus.GetError()
+	case actionStatus.GetError() != "":
+		action.Status = types.StatusFailed
+		action.Error = actionStatus.GetError()
+	case action.Action == proto.PeerActionRotateKey:
+		if err = m.rotateKey(ctx, peer.AccountID, peer.ID, actionStatus.GetNewKey()); err != nil {
+			action.Status = types.StatusFailed
+			action.Error = err.Error()
+		}
+	}
+
+	output := actionStatus.GetOutput()
+	if len(output) > MaxOutputSize {
+		output = output[:MaxOutputSize]
+	}
+	action.Output = output
+	action.UpdatedAt = time.Now().UTC()
+	if err = m.store.SavePeerAction(ctx, store.LockingStrengthUpdate, action); err != nil {
+		return fmt.Errorf("failed to save peer action: %w", err)
+	}
+
+	event := activity.PeerActionCompleted
+	if action.Status != types.StatusSucceeded {
+		event = activity.PeerActionFailed
+	}
+	m.accountManager.StoreEvent(ctx, activity.SystemInitiator, peer.ID, peer.AccountID, event, action.EventMeta())
+
+	if action.Status == types.StatusSucceeded && action.Action == proto.PeerActionRotateKey {
+		m.accountManager.UpdateAccountPeers(ctx, peer.AccountID)
+	}
+
+	if action.Status == types.StatusFailed && action.Action == proto.PeerActionRotateKey {
+		// the peer logs in with the new key next, it can't log in anymore if the key wasn't replaced
+		return status.Errorf(status.InvalidArgument, "failed to rotate the key of peer %s: %s", peer.ID, action.Error)
+	}
+
+	return nil
+}
+
+// rotateKey replaces the WireGuard public key of the peer with the new key reported by the peer
+func (m *managerImpl) rotateKey(ctx context.Context, accountID, peerID, newKey string) error {
+	key, err := wgtypes.ParseKey(newKey)
+	if err != nil {
+		return fmt.Errorf("invalid new key: %w", err)
+	}
+
+	_, err = m.store.GetPeerByPeerPubKey(ctx, store.LockingStrengthShare, key.String())
+	if err == nil {
+		return errors.New("the new key is used by another peer")
+	}
+	if sErr, ok := status.FromError(err); !ok || sErr.Type() != status.NotFound {
+		return fmt.Errorf("check the new key: %w", err)
+	}
+
+	peer, err := m.store.GetPeerByID(ctx, store.LockingStrengthUpdate, accountID, peerID)
+	if err != nil {
+		return err
+	}
+	peer.Key = key.String()
+
+	return m.store.SavePeer(ctx, store.LockingStrengthUpdate, accountID, peer)
+}
+
+// getPeerAction returns the action, if it was sent to the peer
+func (m *managerImpl) getPeerAction(ctx context.Context, accountID, peerID, actionID string) (*types.Action, error) {
+	action, err := m.store.GetPeerActionByID(ctx, store.LockingStrengthShare, accountID, actionID)
+	if err != nil {
+		return nil, err
+	}
+	if action.PeerID != peerID {
+		return nil, status.NewPeerActionNotFoundError(actionID)
+	}
+	return action, nil
+}
+
+// toProtoAction converts the action to the message sent to the peer, signing it for the key of the peer
+func (m *managerImpl) toProtoAction(peerKey string, action *types.Action) *proto.PeerAction {
+	peerAction := &proto.PeerAction{
+		Id:        action.ID,
+		Action:    action.Action,
+		ExpiresAt: timestamppb.New(action.ExpiresAt),
+	}
+	peerAction.Signature = ed25519.Sign(m.signingKey, proto.PeerActionSignedData(peerKey, peerAction))
+	return peerAction
+}
+
+func (m *managerImpl) validatePermissions(ctx context.Context, accountID, userID string, operation permissions.Operation) error {
+	ok, err := m.permissionsManager.ValidateUserPermissions(ctx, accountID, userID, permissions.Peers, operation)
+	if err != nil {
+		return status.NewPermissionValidationError(err)
+	}
+	if !ok {
+		return status.NewPermissionDeniedError()
+	}
+	return nil
+}
+
+func NewManagerMock() Manager {
+	return &mockManager{}
+}
+
+func (m *mockManager) RequestAction(ctx context.Context, accountID, userID, peerID, action string) (*types.Action, error) {
+	return types.NewAction(accountID, peerID, userID, action, ActionTTL), nil
+}
+
+func (m *mockManager) GetPeerActions(ctx context.Context, accountID, userID, peerID string) ([]*types.Action, error) {
+	return []*types.Action{}, nil
+}
+
+func (m *mockManager) GetAction(ctx context.Context, accountID, userID, peerID, actionID string) (*types.Action, error) {
+	return nil, status.NewPeerActionNotFoundError(actionID)
+}
+
+func (m *mockManager) ReportStatus(ctx context.Context, peerKey string, actionStatus *proto.PeerActionStatus) error {
+	return nil
+}
@@ -0,0 +1,187 @@
+package peeractions
+
+import (
+	"context"
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+
+	"github.com/netbirdio/netbird/management/proto"
+	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/mock_server"
+	"github.com/netbirdio/netbird/management/server/peeractions/types"
+	"github.com/netbirdio/netbird/management/server/permissions"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
+)
+
+const (
+	testAccountID = "bf1c8084-ba50-4ce7-9439-34653001fc3b"
+	testAdminID   = "edafee4e-63fb-11ec-90d6-0242ac120003"
+	testRegularID = "f4f6d672-63fb-11ec-90d6-0242ac120003"
+	testPeerID    = "ct286bi7qv930dsrrug0"
+	testSecret    = "secret"
+)
+
+// testPeerUpdater records the actions sent to the peer, the peer isn't connected if disconnected is set
+type testPeerUpdater struct {
+	disconnected bool
+	actions      []*proto.PeerAction
+}
+
+func (u *testPeerUpdater) SendPeerAction(_ context.Context, _ string, action *proto.PeerAction) bool {
+	if u.disconnected {
+		return false
+	}
+	u.actions = append(u.actions, action)
+	return true
+}
+
+// newTestManager returns the manager and the store it uses
+func newTestManager(t *testing.T, updater PeerUpdater, events *[]activity.Activity) (Manager, store.Store) {
+	t.Helper()
+
+	s, cleanUp, err := store.NewTestStoreFromSQL(context.Background(), "../testdata/store.sql", t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(cleanUp)
+
+	accountManager := &mock_server.MockAccountManager{
+		StoreEventFunc: func(_ context.Context, _, _, _ string, activityID activity.ActivityDescriber, _ map[string]any) {
+			*events = append(*events, activityID.(activity.Activity))
+		},
+	}
+
+	return NewManager(s, permissions.NewManager(s), accountManager, updater, testSecret), s
+}
+
+func assertStatus(t *testing.T, err error, expected status.Type) {
+	t.Helper()
+
+	sErr, ok := status.FromError(err)
+	if assert.True(t, ok, "unexpected error %v", err) {
+		assert.Equal(t, expected, sErr.Type())
+	}
+}
+
+func testPeerKey(t *testing.T, s store.Store) string {
+	t.Helper()
+
+	peer, err := s.GetPeerByID(context.Background(), store.LockingStrengthShare, testAccountID, testPeerID)
+	require.NoError(t, err)
+	return peer.Key
+}
+
+func TestManager_RequestAction(t *testing.T) {
+	ctx := context.Background()
+	var events []activity.Activity
+
+	manager, _ := newTestManager(t, &testPeerUpdater{}, &events)
+	_, err := manager.RequestAction(ctx, testAccountID, testRegularID, testPeerID, proto.PeerActionRestart)
+	assertStatus(t, err, status.PermissionDenied)
+
+	_, err = manager.RequestAction(ctx, testAccountID, testAdminID, testPeerID, "format_disk")
+	assertStatus(t, err, status.InvalidArgument)
+
+	manager, _ = newTestManager(t, &testPeerUpdater{disconnected: true}, &events)
+	_, err = manager.RequestAction(ctx, testAccountID, testAdminID, testPeerID, proto.PeerActionRestart)
+	assertStatus(t, err, status.PreconditionFailed)
+
+	actions, err := manager.GetPeerActions(ctx, testAccountID, testAdminID, testPeerID)
+	require.NoError(t, err)
+	assert.Empty(t, actions, "the action of a disconnected peer shouldn't be kept")
+	assert.Empty(t, events)
+}
+
+func TestManager_ActionIsSignedForThePeer(t *testing.T) {
+	ctx := context.Background()
+	var events []activity.Activity
+	updater := &testPeerUpdater{}
+	manager, s := newTestManager(t, updater, &events)
+
+	action, err := manager.RequestAction(ctx, testAccountID, testAdminID, testPeerID, proto.PeerActionRestart)
+	require.NoError(t, err)
+	require.Len(t, updater.actions, 1)
+
+	sent := updater.actions[0]
+	assert.Equal(t, action.ID, sent.GetId())
+	assert.Equal(t, proto.PeerActionRestart, sent.GetAction())
+
+	publicKey := ed25519.PublicKey(PublicKey(testSecret))
+	assert.True(t, ed25519.Verify(publicKey, proto.PeerActionSignedData(testPeerKey(t, s), sent), sent.GetSignature()))
+	assert.False(t, ed25519.Verify(publicKey, proto.PeerActionSignedData("other peer", sent), sent.GetSignature()),
+		"the signature shouldn't be valid for another peer")
+
+	sent.Action = proto.PeerActionRotateKey
+	assert.False(t, ed25519.Verify(publicKey, proto.PeerActionSignedData(testPeerKey(t, s), sent), sent.GetSignature()),
+		"the signature shouldn't be valid for another action")
+
+	assert.Equal(t, []activity.Activity{activity.PeerActionRequested}, events)
+}
+
+func TestManager_ReportStatus(t *testing.T) {
+	ctx := context.Background()
+	var events []activity.Activity
+	manager, s := newTestManager(t, &testPeerUpdater{}, &events)
+	peerKey := testPeerKey(t, s)
+
+	action, err := manager.RequestAction(ctx, testAccountID, testAdminID, testPeerID, proto.PeerActionCollectStatus)
+	require.NoError(t, err)
+
+	err = manager.ReportStatus(ctx, peerKey, &proto.PeerActionStatus{Id: "unknown"})
+	assertStatus(t, err, status.NotFound)
+
+	require.NoError(t, manager.ReportStatus(ctx, peerKey, &proto.PeerActionStatus{Id: action.ID, Output: "status"}))
+
+	err = manager.ReportStatus(ctx, peerKey, &proto.PeerActionStatus{Id: action.ID, Output: "other status"})
+	assertStatus(t, err, status.PreconditionFailed)
+
+	completed, err := manager.GetAction(ctx, testAccountID, testAdminID, testPeerID, action.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.StatusSucceeded, completed.Status)
+	assert.Equal(t, "status", completed.Output)
+
+	declined, err := manager.RequestAction(ctx, testAccountID, testAdminID, testPeerID, proto.PeerActionRestart)
+	require.NoError(t, err)
+	require.NoError(t, manager.ReportStatus(ctx, peerKey, &proto.PeerActionStatus{Id: declined.ID, Declined: true, Error: "not allowed"}))
+
+	declined, err = manager.GetAction(ctx, testAccountID, testAdminID, testPeerID, declined.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.StatusDeclined, declined.Status)
+	assert.Equal(t, "not allowed", declined.Error)
+
+	assert.Equal(t, []activity.Activity{
+		activity.PeerActionRequested,
+		activity.PeerActionCompleted,
+		activity.PeerActionRequested,
+		activity.PeerActionFailed,
+	}, events)
+}
+
+func TestManager_ReportStatusRotatesKey(t *testing.T) {
+	ctx := context.Background()
+	var events []activity.Activity
+	manager, s := newTestManager(t, &testPeerUpdater{}, &events)
+	peerKey := testPeerKey(t, s)
+
+	action, err := manager.RequestAction(ctx, testAccountID, testAdminID, testPeerID, proto.PeerActionRotateKey)
+	require.NoError(t, err)
+
+	err = manager.ReportStatus(ctx, peerKey, &proto.PeerActionStatus{Id: action.ID, NewKey: "invalid"})
+	assertStatus(t, err, status.InvalidArgument)
+	assert.Equal(t, peerKey, testPeerKey(t, s), "the key shouldn't be replaced by an invalid key")
+
+	action, err = manager.RequestAction(ctx, testAccountID, testAdminID, testPeerID, proto.PeerActionRotateKey)
+	require.NoError(t, err)
+
+	newKey, err := wgtypes.GeneratePrivateKey()
+	require.NoError(t, err)
+	require.NoError(t, manager.ReportStatus(ctx, peerKey, &proto.PeerActionStatus{Id: action.ID, NewKey: newKey.PublicKey().String()}))
+	assert.Equal(t, newKey.PublicKey().String(), testPeerKey(t, s))
+
+	rotated, err := manager.GetAction(ctx, testAccountID, testAdminID, testPeerID, action.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.StatusSucceeded, rotated.Status)
+}
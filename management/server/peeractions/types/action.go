@@ -0,0 +1,114 @@
+package types
+
+import (
+	"time"
+
+	"github.com/rs/xid"
+
+	"github.com/netbirdio/netbird/management/proto"
+	"github.com/netbirdio/netbird/management/server/http/api"
+)
+
+// Status of a peer action
+type Status string
+
+const (
+	// StatusPending is the status of the actions the peer hasn't reported a result for yet
+	StatusPending Status = "pending"
+	// StatusSucceeded is the status of the actions the peer ran
+	StatusSucceeded Status = "succeeded"
+	// StatusDeclined is the status of the actions the client configuration doesn't allow
+	StatusDeclined Status = "declined"
+	// StatusFailed is the status of the actions the peer failed to run
+	StatusFailed Status = "failed"
+	// StatusExpired is the status of the pending actions the peer didn't report a result for in time
+	StatusExpired Status = "expired"
+)
+
+// Action requested from a peer by a user of the account, e.g. to fix a stuck peer without access to the machine
+type Action struct {
+	ID        string `gorm:"primaryKey"`
+	AccountID string `gorm:"index"`
+	PeerID    string `gorm:"index"`
+	// InitiatedBy is the user that requested the action
+	InitiatedBy string
+	// Action is one of the peer actions of the management protocol, e.g. restart
+	Action string
+	Status Status
+	// Error describes why the peer declined or failed to run the action
+	Error string
+	// Output is the status reported by the peer for the collect status actions
+	Output    string
+	CreatedAt time.Time
+	// ExpiresAt is the time after which the peer doesn't run the action anymore
+	ExpiresAt time.Time
+	UpdatedAt time.Time
+}
+
+// TableName returns the table of the peer actions
+func (Action) TableName() string {
+	return "peer_actions"
+}
+
+func NewAction(accountID, peerID, userID, action string, ttl time.Duration) *Action {
+	now := time.Now().UTC()
+	return &Action{
+		ID:          xid.New().String(),
+		AccountID:   accountID,
+		PeerID:      peerID,
+		InitiatedBy: userID,
+		Action:      action,
+		Status:      StatusPending,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(ttl),
+		UpdatedAt:   now,
+	}
+}
+
+// IsValidAction checks if the action is one of the peer actions of the management protocol
+func IsValidAction(action string) bool {
+	switch action {
+	case proto.PeerActionRestart, proto.PeerActionReauth, proto.PeerActionRotateKey, proto.PeerActionCollectStatus:
+		return true
+	default:
+		return false
+	}
+}
+
+// EffectiveStatus returns the status of the action, a pending action past its expiry is expired
+func (a *Action) EffectiveStatus() Status {
+	if a.Status == StatusPending && time.Now().After(a.ExpiresAt) {
+		return StatusExpired
+	}
+	return a.Status
+}
+
+// EventMeta returns the activity event meta of the action
+func (a *Action) EventMeta() map[string]any {
+	meta := map[string]any{"action_id": a.ID, "action": a.Action, "status": string(a.Status)}
+	if a.Error != "" {
+		meta["error"] = a.Error
+	}
+	return meta
+}
+
+// ToAPIResponse converts the action to its API representation
+func (a *Action) ToAPIResponse() *api.PeerAction {
+	action := &api.PeerAction{
+		Id:          a.ID,
+		PeerId:      a.PeerID,
+		InitiatedBy: a.InitiatedBy,
+		Action:      api.PeerActionAction(a.Action),
+		Status:      api.PeerActionStatus(a.EffectiveStatus()),
+		CreatedAt:   a.CreatedAt,
+		ExpiresAt:   a.ExpiresAt,
+		UpdatedAt:   a.UpdatedAt,
+	}
+	if a.Error != "" {
+		action.Error = &a.Error
+	}
+	if a.Output != "" {
+		action.Output = &a.Output
+	}
+	return action
+}
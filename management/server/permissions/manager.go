@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/netbirdio/netbird/management/server/status"
 	"github.com/netbirdio/netbird/management/server/store"
@@ -13,13 +14,32 @@ import (
 type Module string
 
 const (
-	Networks Module = "networks"
-	Peers    Module = "peers"
-	Groups   Module = "groups"
-	Settings Module = "settings"
-	Accounts Module = "accounts"
+	Networks      Module = "networks"
+	Peers         Module = "peers"
+	Groups        Module = "groups"
+	Settings      Module = "settings"
+	Accounts      Module = "accounts"
+	Users         Module = "users"
+	Routes        Module = "routes"
+	Policies      Module = "policies"
+	DNS           Module = "dns"
+	Nameservers   Module = "nameservers"
+	SetupKeys     Module = "setup_keys"
+	Events        Module = "events"
+	PostureChecks Module = "posture_checks"
+	Roles         Module = "roles"
+	Tenants       Module = "tenants"
+	Webhooks      Module = "webhooks"
+	Monitors      Module = "monitors"
 )
 
+// Modules are all the permission modules, e.g. the modules personal access tokens can be scoped to
+var Modules = []Module{Networks, Peers, Groups, Settings, Accounts, Users, Routes, Policies, DNS, Nameservers, SetupKeys, Events, PostureChecks, Roles, Tenants, Webhooks, Monitors}
+
+// CustomRoleModules are the modules custom roles can grant permissions on.
+// Accounts and roles are left out so that a custom role can't be used to escalate privileges.
+var CustomRoleModules = []Module{Networks, Peers, Groups, Settings, Users, Routes, Policies, DNS, Nameservers, SetupKeys, Events, PostureChecks, Monitors}
+
 type Operation string
 
 const (
@@ -72,6 +92,10 @@ func (m *managerImpl) ValidateUserPermissions(ctx context.Context, accountID, us
 	case types.UserRoleAdmin, types.UserRoleOwner:
 		return true, nil
 	case types.UserRoleUser:
+		allowed, err := m.validateCustomRolePermissions(ctx, accountID, user, module, operation)
+		if err != nil || allowed {
+			return allowed, err
+		}
 		return m.validateRegularUserPermissions(ctx, accountID, module, operation)
 	case types.UserRoleBillingAdmin:
 		return false, nil
@@ -100,13 +124,47 @@ func (m *managerImpl) validateRegularUserPermissions(ctx context.Context, accoun
 	return false, nil
 }
 
+// validateCustomRolePermissions checks whether one of the custom roles assigned to the user, directly or through its groups, grants the operation
+func (m *managerImpl) validateCustomRolePermissions(ctx context.Context, accountID string, user *types.User, module Module, operation Operation) (bool, error) {
+	if !slices.Contains(CustomRoleModules, module) {
+		return false, nil
+	}
+
+	roles, err := m.store.GetAccountRoles(ctx, store.LockingStrengthShare, accountID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get roles: %w", err)
+	}
+
+	for _, role := range roles {
+		if role.AppliesTo(user.Id, user.AutoGroups) && role.Allows(string(module), string(operation)) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
 func (m *managerImpl) ValidateAccountAccess(ctx context.Context, accountID string, user *types.User, allowOwnerAndAdmin bool) error {
-	if user.AccountID != accountID {
+	if user.AccountID != accountID && !m.isParentAccountAdmin(ctx, accountID, user) {
 		return status.NewUserNotPartOfAccountError()
 	}
 	return nil
 }
 
+// isParentAccountAdmin checks whether the user administers the account as an admin of its parent account
+func (m *managerImpl) isParentAccountAdmin(ctx context.Context, accountID string, user *types.User) bool {
+	if !user.HasAdminPower() {
+		return false
+	}
+
+	tenant, err := m.store.GetTenantByAccountID(ctx, store.LockingStrengthShare, accountID)
+	if err != nil {
+		return false
+	}
+
+	return tenant.ParentAccountID == user.AccountID
+}
+
 func NewManagerMock() Manager {
 	return &managerMock{}
 }
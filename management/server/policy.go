@@ -11,6 +11,8 @@ import (
 	"github.com/netbirdio/netbird/management/server/types"
 
 	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/labels"
+	"github.com/netbirdio/netbird/management/server/permissions"
 	"github.com/netbirdio/netbird/management/server/posture"
 	"github.com/netbirdio/netbird/management/server/status"
 )
@@ -26,7 +28,7 @@ func (am *DefaultAccountManager) GetPolicy(ctx context.Context, accountID, polic
 		return nil, err
 	}
 
-	if user.IsRegularUser() {
+	if user.IsRegularUser() && !am.hasCustomRolePermission(ctx, accountID, user, permissions.Policies, permissions.Read) {
 		return nil, status.NewAdminPermissionError()
 	}
 
@@ -47,7 +49,7 @@ func (am *DefaultAccountManager) SavePolicy(ctx context.Context, accountID, user
 		return nil, err
 	}
 
-	if user.IsRegularUser() {
+	if user.IsRegularUser() && !am.hasCustomRolePermission(ctx, accountID, user, permissions.Policies, permissions.Write) {
 		return nil, status.NewAdminPermissionError()
 	}
 
@@ -104,7 +106,7 @@ func (am *DefaultAccountManager) DeletePolicy(ctx context.Context, accountID, po
 		return err
 	}
 
-	if user.IsRegularUser() {
+	if user.IsRegularUser() && !am.hasCustomRolePermission(ctx, accountID, user, permissions.Policies, permissions.Write) {
 		return status.NewAdminPermissionError()
 	}
 
@@ -152,7 +154,7 @@ func (am *DefaultAccountManager) ListPolicies(ctx context.Context, accountID, us
 		return nil, err
 	}
 
-	if user.IsRegularUser() {
+	if user.IsRegularUser() && !am.hasCustomRolePermission(ctx, accountID, user, permissions.Policies, permissions.Read) {
 		return nil, status.NewAdminPermissionError()
 	}
 
@@ -171,6 +173,11 @@ func arePolicyChangesAffectPeers(ctx context.Context, transaction store.Store, a
 			return false, nil
 		}
 
+		// the peers and the resources selected by labels aren't tracked by the groups
+		if existingPolicy.HasLabelSelectors() {
+			return true, nil
+		}
+
 		hasPeers, err := anyGroupHasPeersOrResources(ctx, transaction, policy.AccountID, existingPolicy.RuleGroups())
 		if err != nil {
 			return false, err
@@ -181,6 +188,10 @@ func arePolicyChangesAffectPeers(ctx context.Context, transaction store.Store, a
 		}
 	}
 
+	if policy.HasLabelSelectors() {
+		return true, nil
+	}
+
 	return anyGroupHasPeersOrResources(ctx, transaction, policy.AccountID, policy.RuleGroups())
 }
 
@@ -215,6 +226,13 @@ func validatePolicy(ctx context.Context, transaction store.Store, accountID stri
 
 		ruleCopy.Sources = getValidGroupIDs(groups, ruleCopy.Sources)
 		ruleCopy.Destinations = getValidGroupIDs(groups, ruleCopy.Destinations)
+
+		if err = labels.Validate(ruleCopy.SourceLabels); err != nil {
+			return status.Errorf(status.InvalidArgument, "invalid source labels: %s", err)
+		}
+		if err = labels.Validate(ruleCopy.DestinationLabels); err != nil {
+			return status.Errorf(status.InvalidArgument, "invalid destination labels: %s", err)
+		}
 		policy.Rules[i] = ruleCopy
 	}
 
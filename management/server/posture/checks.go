@@ -5,6 +5,7 @@ import (
 	"errors"
 	"net/netip"
 	"regexp"
+	"time"
 
 	"github.com/hashicorp/go-version"
 	"github.com/netbirdio/netbird/management/server/http/api"
@@ -18,6 +19,7 @@ const (
 	GeoLocationCheckName      = "GeoLocationCheck"
 	PeerNetworkRangeCheckName = "PeerNetworkRangeCheck"
 	ProcessCheckName          = "ProcessCheck"
+	UptimeCheckName           = "UptimeCheck"
 
 	CheckActionAllow string = "allow"
 	CheckActionDeny  string = "deny"
@@ -58,6 +60,7 @@ type ChecksDefinition struct {
 	GeoLocationCheck      *GeoLocationCheck      `json:",omitempty"`
 	PeerNetworkRangeCheck *PeerNetworkRangeCheck `json:",omitempty"`
 	ProcessCheck          *ProcessCheck          `json:",omitempty"`
+	UptimeCheck           *UptimeCheck           `json:",omitempty"`
 }
 
 // Copy returns a copy of a checks definition.
@@ -110,6 +113,9 @@ func (cd ChecksDefinition) Copy() ChecksDefinition {
 		}
 		copy(cdCopy.ProcessCheck.Processes, processCheck.Processes)
 	}
+	if cd.UptimeCheck != nil {
+		cdCopy.UptimeCheck = &UptimeCheck{MaxUptime: cd.UptimeCheck.MaxUptime}
+	}
 	return cdCopy
 }
 
@@ -153,6 +159,9 @@ func (pc *Checks) GetChecks() []Check {
 	if pc.Checks.ProcessCheck != nil {
 		checks = append(checks, pc.Checks.ProcessCheck)
 	}
+	if pc.Checks.UptimeCheck != nil {
+		checks = append(checks, pc.Checks.UptimeCheck)
+	}
 	return checks
 }
 
@@ -208,6 +217,12 @@ func buildPostureCheck(postureChecksID string, name string, description string,
 		postureChecks.Checks.ProcessCheck = toProcessCheck(processCheck)
 	}
 
+	if uptimeCheck := checks.UptimeCheck; uptimeCheck != nil {
+		postureChecks.Checks.UptimeCheck = &UptimeCheck{
+			MaxUptime: time.Duration(uptimeCheck.MaxUptime) * time.Second,
+		}
+	}
+
 	return &postureChecks, nil
 }
 
@@ -242,6 +257,12 @@ func (pc *Checks) ToAPIResponse() *api.PostureCheck {
 		checks.ProcessCheck = toProcessCheckResponse(pc.Checks.ProcessCheck)
 	}
 
+	if pc.Checks.UptimeCheck != nil {
+		checks.UptimeCheck = &api.UptimeCheck{
+			MaxUptime: int(pc.Checks.UptimeCheck.MaxUptime.Seconds()),
+		}
+	}
+
 	return &api.PostureCheck{
 		Id:          pc.ID,
 		Name:        pc.Name,
@@ -0,0 +1,49 @@
+package posture
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+)
+
+// minMaxUptime prevents a check failing the peers rebooting as usual
+const minMaxUptime = time.Hour
+
+// UptimeCheck fails the peers running for longer than the maximum uptime without a reboot, a reboot applying the
+// pending OS updates on most systems. The peers not reporting their boot time, like the mobile peers and the older
+// clients, fail the check.
+type UptimeCheck struct {
+	MaxUptime time.Duration
+}
+
+var _ Check = (*UptimeCheck)(nil)
+
+func (u *UptimeCheck) Check(ctx context.Context, peer nbpeer.Peer) (bool, error) {
+	uptime := peer.Meta.Uptime()
+	if uptime == 0 {
+		return false, fmt.Errorf("peer %s doesn't report its boot time", peer.ID)
+	}
+
+	if uptime > u.MaxUptime {
+		log.WithContext(ctx).Debugf("peer %s uptime %s is longer than the maximum allowed uptime %s",
+			peer.ID, uptime.Round(time.Minute), u.MaxUptime)
+		return false, nil
+	}
+
+	return true, nil
+}
+
+func (u *UptimeCheck) Name() string {
+	return UptimeCheckName
+}
+
+func (u *UptimeCheck) Validate() error {
+	if u.MaxUptime < minMaxUptime {
+		return fmt.Errorf("%s maximum uptime can't be shorter than %s", u.Name(), minMaxUptime)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package posture
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/netbirdio/netbird/management/server/peer"
+)
+
+func TestUptimeCheck_Check(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   peer.Peer
+		check   UptimeCheck
+		wantErr bool
+		isValid bool
+	}{
+		{
+			name: "Peer rebooted recently",
+			input: peer.Peer{
+				Meta: peer.PeerSystemMeta{
+					BootTime: time.Now().Add(-2 * time.Hour),
+				},
+			},
+			check: UptimeCheck{
+				MaxUptime: 24 * time.Hour,
+			},
+			wantErr: false,
+			isValid: true,
+		},
+		{
+			name: "Peer running for too long",
+			input: peer.Peer{
+				Meta: peer.PeerSystemMeta{
+					BootTime: time.Now().Add(-48 * time.Hour),
+				},
+			},
+			check: UptimeCheck{
+				MaxUptime: 24 * time.Hour,
+			},
+			wantErr: false,
+			isValid: false,
+		},
+		{
+			name:  "Peer not reporting its boot time",
+			input: peer.Peer{},
+			check: UptimeCheck{
+				MaxUptime: 24 * time.Hour,
+			},
+			wantErr: true,
+			isValid: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isValid, err := tt.check.Check(context.Background(), tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.isValid, isValid)
+		})
+	}
+}
+
+func TestUptimeCheck_Validate(t *testing.T) {
+	assert.NoError(t, (&UptimeCheck{MaxUptime: 24 * time.Hour}).Validate())
+	assert.Error(t, (&UptimeCheck{MaxUptime: time.Minute}).Validate())
+}
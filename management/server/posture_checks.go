@@ -10,6 +10,7 @@ import (
 	"golang.org/x/exp/maps"
 
 	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/permissions"
 	"github.com/netbirdio/netbird/management/server/posture"
 	"github.com/netbirdio/netbird/management/server/status"
 	"github.com/netbirdio/netbird/management/server/store"
@@ -26,7 +27,7 @@ func (am *DefaultAccountManager) GetPostureChecks(ctx context.Context, accountID
 		return nil, err
 	}
 
-	if !user.HasAdminPower() {
+	if !user.HasAdminPower() && !am.hasCustomRolePermission(ctx, accountID, user, permissions.PostureChecks, permissions.Read) {
 		return nil, status.NewAdminPermissionError()
 	}
 
@@ -47,7 +48,7 @@ func (am *DefaultAccountManager) SavePostureChecks(ctx context.Context, accountI
 		return nil, err
 	}
 
-	if !user.HasAdminPower() {
+	if !user.HasAdminPower() && !am.hasCustomRolePermission(ctx, accountID, user, permissions.PostureChecks, permissions.Write) {
 		return nil, status.NewAdminPermissionError()
 	}
 
@@ -103,7 +104,7 @@ func (am *DefaultAccountManager) DeletePostureChecks(ctx context.Context, accoun
 		return err
 	}
 
-	if !user.HasAdminPower() {
+	if !user.HasAdminPower() && !am.hasCustomRolePermission(ctx, accountID, user, permissions.PostureChecks, permissions.Write) {
 		return status.NewAdminPermissionError()
 	}
 
@@ -119,6 +120,14 @@ func (am *DefaultAccountManager) DeletePostureChecks(ctx context.Context, accoun
 			return err
 		}
 
+		if err = isPostureCheckLinkedToGroupRule(ctx, transaction, postureChecksID, accountID); err != nil {
+			return err
+		}
+
+		if err = isPostureCheckLinkedToSetupKey(ctx, transaction, postureChecksID, accountID); err != nil {
+			return err
+		}
+
 		if err = transaction.IncrementNetworkSerial(ctx, store.LockingStrengthUpdate, accountID); err != nil {
 			return err
 		}
@@ -145,7 +154,7 @@ func (am *DefaultAccountManager) ListPostureChecks(ctx context.Context, accountI
 		return nil, err
 	}
 
-	if !user.HasAdminPower() {
+	if !user.HasAdminPower() && !am.hasCustomRolePermission(ctx, accountID, user, permissions.PostureChecks, permissions.Read) {
 		return nil, status.NewAdminPermissionError()
 	}
 
@@ -286,3 +295,35 @@ func isPostureCheckLinkedToPolicy(ctx context.Context, transaction store.Store,
 
 	return nil
 }
+
+// isPostureCheckLinkedToSetupKey checks whether the posture check is required by any account setup key.
+func isPostureCheckLinkedToSetupKey(ctx context.Context, transaction store.Store, postureChecksID, accountID string) error {
+	setupKeys, err := transaction.GetAccountSetupKeys(ctx, store.LockingStrengthShare, accountID)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range setupKeys {
+		if slices.Contains(key.AutoPostureChecks, postureChecksID) {
+			return status.Errorf(status.PreconditionFailed, "posture checks have been linked to setup key: %s", key.Name)
+		}
+	}
+
+	return nil
+}
+
+// isPostureCheckLinkedToGroupRule checks whether the posture check is used by the membership rule of any account group.
+func isPostureCheckLinkedToGroupRule(ctx context.Context, transaction store.Store, postureChecksID, accountID string) error {
+	groups, err := transaction.GetAccountGroups(ctx, store.LockingStrengthShare, accountID)
+	if err != nil {
+		return err
+	}
+
+	for _, group := range groups {
+		if group.HasMembershipRule() && slices.Contains(group.MembershipRule.PostureCheckIDs, postureChecksID) {
+			return status.Errorf(status.PreconditionFailed, "posture checks have been linked to the membership rule of group: %s", group.Name)
+		}
+	}
+
+	return nil
+}
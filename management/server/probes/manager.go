@@ -0,0 +1,257 @@
+package probes
+
+import (
+	"context"
+	"net/netip"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/rs/xid"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/netbirdio/netbird/management/proto"
+	"github.com/netbirdio/netbird/management/server/permissions"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
+)
+
+const (
+	// DefaultTimeout is the timeout of the probes that don't set one
+	DefaultTimeout = 5 * time.Second
+	// MaxTimeout limits the time an API request waits for the result of a probe
+	MaxTimeout = 30 * time.Second
+
+	// resultGracePeriod is added to the probe timeout to receive the result reported by the peer
+	resultGracePeriod = 2 * time.Second
+)
+
+// Target of a probe, either a peer of the account, an IP address reachable from the probing peer, e.g. of a routed
+// network, or an HTTP URL requested by the probing peer
+type Target struct {
+	PeerID  string
+	Address string
+	// Port is the TCP port connected to, the target is pinged if it is 0
+	Port uint16
+	// URL is requested with an HTTP GET, a server error status fails the probe
+	URL     string
+	Timeout time.Duration
+}
+
+// Result of a probe reported by the probing peer
+type Result struct {
+	Target    string
+	Reachable bool
+	Latency   time.Duration
+	Error     string
+	// HTTPStatus is the response status code of an HTTP probe
+	HTTPStatus int
+}
+
+// PeerUpdater sends the probe requests to the peers connected to the management service
+type PeerUpdater interface {
+	// SendProbeRequest returns false if the peer isn't connected
+	SendProbeRequest(ctx context.Context, peerID string, request *proto.ProbeRequest) bool
+}
+
+type Manager interface {
+	// ProbePeer asks the peer to probe the target and waits for the result
+	ProbePeer(ctx context.Context, accountID, userID, peerID string, target *Target) (*Result, error)
+	// Probe is ProbePeer without the permission check of a user, it is used by the management service itself
+	Probe(ctx context.Context, accountID, peerID string, target *Target) (*Result, error)
+	// ReportResult delivers the result of a probe reported by the peer with the WireGuard public key
+	ReportResult(ctx context.Context, peerKey string, result *proto.ProbeResult) error
+}
+
+type pendingProbe struct {
+	peerKey string
+	target  string
+	results chan *Result
+}
+
+type managerImpl struct {
+	store              store.Store
+	permissionsManager permissions.Manager
+	peerUpdater        PeerUpdater
+
+	mu      sync.Mutex
+	pending map[string]*pendingProbe
+}
+
+func NewManager(store store.Store, permissionsManager permissions.Manager, peerUpdater PeerUpdater) Manager {
+	return &managerImpl{
+		store:              store,
+		permissionsManager: permissionsManager,
+		peerUpdater:        peerUpdater,
+		pending:            make(map[string]*pendingProbe),
+	}
+}
+
+func (m *managerImpl) ProbePeer(ctx context.Context, accountID, userID, peerID string, target *Target) (*Result, error) {
+	ok, err := m.permissionsManager.ValidateUserPermissions(ctx, accountID, userID, permissions.Peers, permissions.Write)
+	if err != nil {
+		return nil, status.NewPermissionValidationError(err)
+	}
+	if !ok {
+		return nil, status.NewPermissionDeniedError()
+	}
+
+	return m.Probe(ctx, accountID, peerID, target)
+}
+
+func (m *managerImpl) Probe(ctx context.Context, accountID, peerID string, target *Target) (*Result, error) {
+	timeout, err := probeTimeout(target.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	peer, err := m.store.GetPeerByID(ctx, store.LockingStrengthShare, accountID, peerID)
+	if err != nil {
+		return nil, err
+	}
+
+	address, err := m.targetAddress(ctx, accountID, peerID, target)
+	if err != nil {
+		return nil, err
+	}
+
+	probe := &pendingProbe{
+		peerKey: peer.Key,
+		target:  address,
+		results: make(chan *Result, 1),
+	}
+	probeID := xid.New().String()
+
+	m.mu.Lock()
+	m.pending[probeID] = probe
+	m.mu.Unlock()
+
+	defer func() {
+		m.mu.Lock()
+		delete(m.pending, probeID)
+		m.mu.Unlock()
+	}()
+
+	request := &proto.ProbeRequest{
+		Id:      probeID,
+		Port:    uint32(target.Port),
+		Url:     target.URL,
+		Timeout: durationpb.New(timeout),
+	}
+	if target.URL == "" {
+		request.Target = address
+	}
+	if !m.peerUpdater.SendProbeRequest(ctx, peerID, request) {
+		return nil, status.Errorf(status.PreconditionFailed, "peer %s is not connected", peerID)
+	}
+
+	timer := time.NewTimer(timeout + resultGracePeriod)
+	defer timer.Stop()
+
+	select {
+	case result := <-probe.results:
+		return result, nil
+	case <-timer.C:
+		return nil, status.Errorf(status.PreconditionFailed, "peer %s didn't report the probe result in time, it may run a client version without probe support", peerID)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (m *managerImpl) ReportResult(ctx context.Context, peerKey string, result *proto.ProbeResult) error {
+	m.mu.Lock()
+	probe, ok := m.pending[result.GetId()]
+	m.mu.Unlock()
+
+	if !ok {
+		log.WithContext(ctx).Debugf("received the result of unknown probe %s from peer %s", result.GetId(), peerKey)
+		return status.Errorf(status.NotFound, "probe %s not found", result.GetId())
+	}
+
+	if probe.peerKey != peerKey {
+		return status.Errorf(status.PermissionDenied, "probe %s wasn't requested from the peer", result.GetId())
+	}
+
+	select {
+	case probe.results <- &Result{
+		Target:     probe.target,
+		Reachable:  result.GetReachable(),
+		Latency:    result.GetLatency().AsDuration(),
+		Error:      result.GetError(),
+		HTTPStatus: int(result.GetHttpStatus()),
+	}:
+	default:
+		// the result has already been reported
+	}
+
+	return nil
+}
+
+// targetAddress returns the IP address probed by the peer, or the URL of an HTTP probe
+func (m *managerImpl) targetAddress(ctx context.Context, accountID, peerID string, target *Target) (string, error) {
+	set := 0
+	for _, value := range []string{target.PeerID, target.Address, target.URL} {
+		if value != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return "", status.Errorf(status.InvalidArgument, "either a target peer, an address or a URL has to be set")
+	}
+
+	if target.URL != "" {
+		u, err := url.Parse(target.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return "", status.Errorf(status.InvalidArgument, "probe url must be a valid http or https URL")
+		}
+		return target.URL, nil
+	}
+
+	if target.PeerID != "" {
+		if target.PeerID == peerID {
+			return "", status.Errorf(status.InvalidArgument, "peer can't probe itself")
+		}
+		targetPeer, err := m.store.GetPeerByID(ctx, store.LockingStrengthShare, accountID, target.PeerID)
+		if err != nil {
+			return "", err
+		}
+		return targetPeer.IP.String(), nil
+	}
+
+	address, err := netip.ParseAddr(target.Address)
+	if err != nil {
+		return "", status.Errorf(status.InvalidArgument, "invalid target address %s", target.Address)
+	}
+
+	return address.String(), nil
+}
+
+func probeTimeout(timeout time.Duration) (time.Duration, error) {
+	switch {
+	case timeout == 0:
+		return DefaultTimeout, nil
+	case timeout < 0 || timeout > MaxTimeout:
+		return 0, status.Errorf(status.InvalidArgument, "probe timeout has to be between 0 and %s", MaxTimeout)
+	default:
+		return timeout, nil
+	}
+}
+
+type mockManager struct{}
+
+func NewManagerMock() Manager {
+	return &mockManager{}
+}
+
+func (m *mockManager) ProbePeer(ctx context.Context, accountID, userID, peerID string, target *Target) (*Result, error) {
+	return &Result{Target: target.Address, Reachable: true}, nil
+}
+
+func (m *mockManager) Probe(ctx context.Context, accountID, peerID string, target *Target) (*Result, error) {
+	return &Result{Target: target.Address, Reachable: true}, nil
+}
+
+func (m *mockManager) ReportResult(ctx context.Context, peerKey string, result *proto.ProbeResult) error {
+	return nil
+}
@@ -0,0 +1,107 @@
+package probes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/netbirdio/netbird/management/proto"
+	"github.com/netbirdio/netbird/management/server/permissions"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
+)
+
+const (
+	testAccountID = "bf1c8084-ba50-4ce7-9439-34653001fc3b"
+	testAdminID   = "edafee4e-63fb-11ec-90d6-0242ac120003"
+	testRegularID = "f4f6d672-63fb-11ec-90d6-0242ac120003"
+	testPeerID    = "ct286bi7qv930dsrrug0"
+)
+
+// testPeerUpdater passes the probe requests to onRequest, the peer isn't connected if it is nil
+type testPeerUpdater struct {
+	onRequest func(request *proto.ProbeRequest)
+}
+
+func (u *testPeerUpdater) SendProbeRequest(_ context.Context, _ string, request *proto.ProbeRequest) bool {
+	if u.onRequest == nil {
+		return false
+	}
+	go u.onRequest(request)
+	return true
+}
+
+func newTestManager(t *testing.T, updater PeerUpdater) Manager {
+	t.Helper()
+
+	s, cleanUp, err := store.NewTestStoreFromSQL(context.Background(), "../testdata/store.sql", t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(cleanUp)
+
+	return NewManager(s, permissions.NewManager(s), updater)
+}
+
+func assertStatus(t *testing.T, err error, expected status.Type) {
+	t.Helper()
+
+	sErr, ok := status.FromError(err)
+	if assert.True(t, ok, "unexpected error %v", err) {
+		assert.Equal(t, expected, sErr.Type())
+	}
+}
+
+func TestManager_ProbePeerValidatesTarget(t *testing.T) {
+	ctx := context.Background()
+	manager := newTestManager(t, &testPeerUpdater{})
+
+	_, err := manager.ProbePeer(ctx, testAccountID, testRegularID, testPeerID, &Target{Address: "10.0.0.1"})
+	assertStatus(t, err, status.PermissionDenied)
+
+	_, err = manager.ProbePeer(ctx, testAccountID, testAdminID, testPeerID, &Target{})
+	assertStatus(t, err, status.InvalidArgument)
+
+	_, err = manager.ProbePeer(ctx, testAccountID, testAdminID, testPeerID, &Target{PeerID: testPeerID})
+	assertStatus(t, err, status.InvalidArgument)
+
+	_, err = manager.ProbePeer(ctx, testAccountID, testAdminID, testPeerID, &Target{Address: "not-an-ip"})
+	assertStatus(t, err, status.InvalidArgument)
+
+	_, err = manager.ProbePeer(ctx, testAccountID, testAdminID, testPeerID, &Target{Address: "10.0.0.1", Timeout: time.Minute})
+	assertStatus(t, err, status.InvalidArgument)
+
+	_, err = manager.ProbePeer(ctx, testAccountID, testAdminID, testPeerID, &Target{Address: "10.0.0.1"})
+	assertStatus(t, err, status.PreconditionFailed)
+}
+
+func TestManager_ProbePeerReturnsReportedResult(t *testing.T) {
+	ctx := context.Background()
+
+	var manager Manager
+	var requested *proto.ProbeRequest
+	manager = newTestManager(t, &testPeerUpdater{onRequest: func(request *proto.ProbeRequest) {
+		requested = request
+		result := &proto.ProbeResult{Id: request.GetId(), Reachable: true, Latency: durationpb.New(20 * time.Millisecond)}
+
+		err := manager.ReportResult(ctx, "another-peer-key", result)
+		assertStatus(t, err, status.PermissionDenied)
+
+		// the test peer has no key
+		assert.NoError(t, manager.ReportResult(ctx, "", result))
+	}})
+
+	result, err := manager.ProbePeer(ctx, testAccountID, testAdminID, testPeerID, &Target{Address: "10.0.0.1", Port: 443})
+	require.NoError(t, err)
+
+	assert.Equal(t, "10.0.0.1", requested.GetTarget())
+	assert.Equal(t, uint32(443), requested.GetPort())
+	assert.Equal(t, DefaultTimeout, requested.GetTimeout().AsDuration())
+
+	assert.Equal(t, &Result{Target: "10.0.0.1", Reachable: true, Latency: 20 * time.Millisecond}, result)
+
+	err = manager.ReportResult(ctx, "", &proto.ProbeResult{Id: requested.GetId()})
+	assertStatus(t, err, status.NotFound)
+}
@@ -0,0 +1,220 @@
+package roles
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/rs/xid"
+
+	"github.com/netbirdio/netbird/management/server/account"
+	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/permissions"
+	"github.com/netbirdio/netbird/management/server/roles/types"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
+	nbtypes "github.com/netbirdio/netbird/management/server/types"
+)
+
+type Manager interface {
+	GetAllRoles(ctx context.Context, accountID, userID string) ([]*types.Role, error)
+	CreateRole(ctx context.Context, userID string, role *types.Role) (*types.Role, error)
+	GetRole(ctx context.Context, accountID, userID, roleID string) (*types.Role, error)
+	UpdateRole(ctx context.Context, userID string, role *types.Role) (*types.Role, error)
+	DeleteRole(ctx context.Context, accountID, userID, roleID string) error
+}
+
+type managerImpl struct {
+	store              store.Store
+	permissionsManager permissions.Manager
+	accountManager     account.Manager
+}
+
+type mockManager struct {
+}
+
+func NewManager(store store.Store, permissionsManager permissions.Manager, accountManager account.Manager) Manager {
+	return &managerImpl{
+		store:              store,
+		permissionsManager: permissionsManager,
+		accountManager:     accountManager,
+	}
+}
+
+func (m *managerImpl) GetAllRoles(ctx context.Context, accountID, userID string) ([]*types.Role, error) {
+	if err := m.validatePermissions(ctx, accountID, userID, permissions.Read); err != nil {
+		return nil, err
+	}
+
+	return m.store.GetAccountRoles(ctx, store.LockingStrengthShare, accountID)
+}
+
+func (m *managerImpl) CreateRole(ctx context.Context, userID string, role *types.Role) (*types.Role, error) {
+	if err := m.validatePermissions(ctx, role.AccountID, userID, permissions.Write); err != nil {
+		return nil, err
+	}
+
+	role.ID = xid.New().String()
+
+	unlock := m.store.AcquireWriteLockByUID(ctx, role.AccountID)
+	defer unlock()
+
+	if err := m.validateRole(ctx, role); err != nil {
+		return nil, err
+	}
+
+	if err := m.store.SaveRole(ctx, store.LockingStrengthUpdate, role); err != nil {
+		return nil, fmt.Errorf("failed to save role: %w", err)
+	}
+
+	m.accountManager.StoreEvent(ctx, userID, role.ID, role.AccountID, activity.RoleCreated, role.EventMeta())
+
+	return role, nil
+}
+
+func (m *managerImpl) GetRole(ctx context.Context, accountID, userID, roleID string) (*types.Role, error) {
+	if err := m.validatePermissions(ctx, accountID, userID, permissions.Read); err != nil {
+		return nil, err
+	}
+
+	return m.store.GetRoleByID(ctx, store.LockingStrengthShare, accountID, roleID)
+}
+
+func (m *managerImpl) UpdateRole(ctx context.Context, userID string, role *types.Role) (*types.Role, error) {
+	if err := m.validatePermissions(ctx, role.AccountID, userID, permissions.Write); err != nil {
+		return nil, err
+	}
+
+	unlock := m.store.AcquireWriteLockByUID(ctx, role.AccountID)
+	defer unlock()
+
+	_, err := m.store.GetRoleByID(ctx, store.LockingStrengthUpdate, role.AccountID, role.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+
+	if err = m.validateRole(ctx, role); err != nil {
+		return nil, err
+	}
+
+	if err = m.store.SaveRole(ctx, store.LockingStrengthUpdate, role); err != nil {
+		return nil, fmt.Errorf("failed to save role: %w", err)
+	}
+
+	m.accountManager.StoreEvent(ctx, userID, role.ID, role.AccountID, activity.RoleUpdated, role.EventMeta())
+
+	return role, nil
+}
+
+func (m *managerImpl) DeleteRole(ctx context.Context, accountID, userID, roleID string) error {
+	if err := m.validatePermissions(ctx, accountID, userID, permissions.Write); err != nil {
+		return err
+	}
+
+	unlock := m.store.AcquireWriteLockByUID(ctx, accountID)
+	defer unlock()
+
+	role, err := m.store.GetRoleByID(ctx, store.LockingStrengthUpdate, accountID, roleID)
+	if err != nil {
+		return fmt.Errorf("failed to get role: %w", err)
+	}
+
+	if err = m.store.DeleteRole(ctx, store.LockingStrengthUpdate, accountID, roleID); err != nil {
+		return fmt.Errorf("failed to delete role: %w", err)
+	}
+
+	m.accountManager.StoreEvent(ctx, userID, roleID, accountID, activity.RoleDeleted, role.EventMeta())
+
+	return nil
+}
+
+func (m *managerImpl) validatePermissions(ctx context.Context, accountID, userID string, operation permissions.Operation) error {
+	ok, err := m.permissionsManager.ValidateUserPermissions(ctx, accountID, userID, permissions.Roles, operation)
+	if err != nil {
+		return status.NewPermissionValidationError(err)
+	}
+	if !ok {
+		return status.NewPermissionDeniedError()
+	}
+	return nil
+}
+
+// validateRole checks that the role grants known operations on the modules allowed for custom roles
+// and that its groups and users exist in the account
+func (m *managerImpl) validateRole(ctx context.Context, role *types.Role) error {
+	if strings.TrimSpace(role.Name) == "" {
+		return status.Errorf(status.InvalidArgument, "role name can't be empty")
+	}
+
+	for module, operations := range role.Permissions {
+		if !slices.Contains(permissions.CustomRoleModules, permissions.Module(module)) {
+			return status.Errorf(status.InvalidArgument, "custom roles can't grant permissions on module %s", module)
+		}
+
+		for _, operation := range operations {
+			if operation != string(permissions.Read) && operation != string(permissions.Write) {
+				return status.Errorf(status.InvalidArgument, "invalid operation %s for module %s", operation, module)
+			}
+		}
+	}
+
+	roles, err := m.store.GetAccountRoles(ctx, store.LockingStrengthShare, role.AccountID)
+	if err != nil {
+		return fmt.Errorf("failed to get roles: %w", err)
+	}
+
+	for _, existing := range roles {
+		if existing.ID != role.ID && existing.Name == role.Name {
+			return status.Errorf(status.AlreadyExists, "role with name %s already exists", role.Name)
+		}
+	}
+
+	groups, err := m.store.GetGroupsByIDs(ctx, store.LockingStrengthShare, role.AccountID, role.Groups)
+	if err != nil {
+		return fmt.Errorf("failed to get groups: %w", err)
+	}
+
+	for _, groupID := range role.Groups {
+		if _, ok := groups[groupID]; !ok {
+			return status.Errorf(status.InvalidArgument, "group %s not found", groupID)
+		}
+	}
+
+	users, err := m.store.GetAccountUsers(ctx, store.LockingStrengthShare, role.AccountID)
+	if err != nil {
+		return fmt.Errorf("failed to get users: %w", err)
+	}
+
+	for _, userID := range role.Users {
+		if !slices.ContainsFunc(users, func(user *nbtypes.User) bool { return user.Id == userID }) {
+			return status.Errorf(status.InvalidArgument, "user %s not found", userID)
+		}
+	}
+
+	return nil
+}
+
+func NewManagerMock() Manager {
+	return &mockManager{}
+}
+
+func (m *mockManager) GetAllRoles(ctx context.Context, accountID, userID string) ([]*types.Role, error) {
+	return []*types.Role{}, nil
+}
+
+func (m *mockManager) CreateRole(ctx context.Context, userID string, role *types.Role) (*types.Role, error) {
+	return role, nil
+}
+
+func (m *mockManager) GetRole(ctx context.Context, accountID, userID, roleID string) (*types.Role, error) {
+	return &types.Role{}, nil
+}
+
+func (m *mockManager) UpdateRole(ctx context.Context, userID string, role *types.Role) (*types.Role, error) {
+	return role, nil
+}
+
+func (m *mockManager) DeleteRole(ctx context.Context, accountID, userID, roleID string) error {
+	return nil
+}
@@ -0,0 +1,145 @@
+package roles
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/management/server/mock_server"
+	"github.com/netbirdio/netbird/management/server/permissions"
+	"github.com/netbirdio/netbird/management/server/roles/types"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
+)
+
+const (
+	testAccountID = "bf1c8084-ba50-4ce7-9439-34653001fc3b"
+	testAdminID   = "edafee4e-63fb-11ec-90d6-0242ac120003"
+	testRegularID = "f4f6d672-63fb-11ec-90d6-0242ac120003"
+	testGroupID   = "cfefqs706sqkneg59g2g"
+)
+
+func newTestManager(t *testing.T) (Manager, permissions.Manager, store.Store) {
+	t.Helper()
+
+	s, cleanUp, err := store.NewTestStoreFromSQL(context.Background(), "../testdata/extended-store.sql", t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(cleanUp)
+
+	permissionsManager := permissions.NewManager(s)
+	return NewManager(s, permissionsManager, &mock_server.MockAccountManager{}), permissionsManager, s
+}
+
+func Test_CustomRoleGrantsPermissions(t *testing.T) {
+	ctx := context.Background()
+	manager, permissionsManager, _ := newTestManager(t)
+
+	allowed, err := permissionsManager.ValidateUserPermissions(ctx, testAccountID, testRegularID, permissions.Routes, permissions.Write)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	role := types.NewRole(testAccountID, "Route manager", "")
+	role.Permissions = map[string][]string{"routes": {"write"}}
+	role.Users = []string{testRegularID}
+	_, err = manager.CreateRole(ctx, testAdminID, role)
+	require.NoError(t, err)
+
+	allowed, err = permissionsManager.ValidateUserPermissions(ctx, testAccountID, testRegularID, permissions.Routes, permissions.Write)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, err = permissionsManager.ValidateUserPermissions(ctx, testAccountID, testRegularID, permissions.Routes, permissions.Read)
+	require.NoError(t, err)
+	assert.True(t, allowed, "write access should imply read access")
+
+	allowed, err = permissionsManager.ValidateUserPermissions(ctx, testAccountID, testRegularID, permissions.Policies, permissions.Read)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
+
+func Test_CustomRoleAssignedThroughGroups(t *testing.T) {
+	ctx := context.Background()
+	manager, permissionsManager, s := newTestManager(t)
+
+	role := types.NewRole(testAccountID, "Auditor", "")
+	role.Permissions = map[string][]string{"events": {"read"}}
+	role.Groups = []string{testGroupID}
+	_, err := manager.CreateRole(ctx, testAdminID, role)
+	require.NoError(t, err)
+
+	allowed, err := permissionsManager.ValidateUserPermissions(ctx, testAccountID, testRegularID, permissions.Events, permissions.Read)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	user, err := s.GetUserByUserID(ctx, store.LockingStrengthShare, testRegularID)
+	require.NoError(t, err)
+	user.AutoGroups = append(user.AutoGroups, testGroupID)
+	require.NoError(t, s.SaveUser(ctx, store.LockingStrengthUpdate, user))
+
+	allowed, err = permissionsManager.ValidateUserPermissions(ctx, testAccountID, testRegularID, permissions.Events, permissions.Read)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, err = permissionsManager.ValidateUserPermissions(ctx, testAccountID, testRegularID, permissions.Events, permissions.Write)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
+
+func Test_CreateRoleValidation(t *testing.T) {
+	ctx := context.Background()
+	manager, _, _ := newTestManager(t)
+
+	tests := []struct {
+		name        string
+		role        *types.Role
+		expectedErr status.Type
+	}{
+		{
+			name:        "privileged module",
+			role:        &types.Role{AccountID: testAccountID, Name: "escalation", Permissions: map[string][]string{"roles": {"write"}}},
+			expectedErr: status.InvalidArgument,
+		},
+		{
+			name:        "unknown operation",
+			role:        &types.Role{AccountID: testAccountID, Name: "unknown", Permissions: map[string][]string{"peers": {"delete"}}},
+			expectedErr: status.InvalidArgument,
+		},
+		{
+			name:        "unknown group",
+			role:        &types.Role{AccountID: testAccountID, Name: "group", Groups: []string{"missing"}},
+			expectedErr: status.InvalidArgument,
+		},
+		{
+			name:        "unknown user",
+			role:        &types.Role{AccountID: testAccountID, Name: "user", Users: []string{"missing"}},
+			expectedErr: status.InvalidArgument,
+		},
+		{
+			name:        "empty name",
+			role:        &types.Role{AccountID: testAccountID},
+			expectedErr: status.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := manager.CreateRole(ctx, testAdminID, tt.role)
+			sErr, ok := status.FromError(err)
+			require.True(t, ok)
+			assert.Equal(t, tt.expectedErr, sErr.Type())
+		})
+	}
+}
+
+func Test_CreateRoleFailsWithPermissionDenied(t *testing.T) {
+	manager, _, _ := newTestManager(t)
+
+	role := types.NewRole(testAccountID, "Helpdesk", "")
+	role.Users = []string{testRegularID}
+	_, err := manager.CreateRole(context.Background(), testRegularID, role)
+	sErr, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, status.PermissionDenied, sErr.Type())
+}
@@ -0,0 +1,114 @@
+package types
+
+import (
+	"slices"
+
+	"github.com/rs/xid"
+
+	"github.com/netbirdio/netbird/management/server/http/api"
+)
+
+// Role is a custom account role. It grants its permissions to the users with the regular user role
+// that are assigned to it directly or are members of one of its groups.
+type Role struct {
+	ID          string `gorm:"primaryKey"`
+	AccountID   string `gorm:"index"`
+	Name        string
+	Description string
+	// Permissions maps a module name to the operations allowed on it
+	Permissions map[string][]string `gorm:"serializer:json"`
+	// Groups are the IDs of the groups whose members get the role, e.g. the groups synced from the IdP JWT claims
+	Groups []string `gorm:"serializer:json"`
+	// Users are the IDs of the users the role is directly assigned to
+	Users []string `gorm:"serializer:json"`
+}
+
+func NewRole(accountID, name, description string) *Role {
+	return &Role{
+		ID:          xid.New().String(),
+		AccountID:   accountID,
+		Name:        name,
+		Description: description,
+		Permissions: map[string][]string{},
+	}
+}
+
+// AppliesTo returns true if the role is assigned to the user directly or through one of the given groups
+func (r *Role) AppliesTo(userID string, userGroups []string) bool {
+	if slices.Contains(r.Users, userID) {
+		return true
+	}
+
+	for _, groupID := range userGroups {
+		if slices.Contains(r.Groups, groupID) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Allows returns true if the role grants the operation on the module. Write access implies read access.
+func (r *Role) Allows(module, operation string) bool {
+	operations, ok := r.Permissions[module]
+	if !ok {
+		return false
+	}
+
+	return slices.Contains(operations, operation) || (operation == "read" && slices.Contains(operations, "write"))
+}
+
+func (r *Role) ToAPIResponse() *api.Role {
+	return &api.Role{
+		Id:          r.ID,
+		Name:        r.Name,
+		Description: &r.Description,
+		Permissions: r.Permissions,
+		Groups:      append([]string{}, r.Groups...),
+		Users:       append([]string{}, r.Users...),
+	}
+}
+
+func (r *Role) FromAPIRequest(req *api.RoleRequest) {
+	r.Name = req.Name
+	if req.Description != nil {
+		r.Description = *req.Description
+	}
+
+	r.Permissions = req.Permissions
+	if r.Permissions == nil {
+		r.Permissions = map[string][]string{}
+	}
+
+	r.Groups = nil
+	if req.Groups != nil {
+		r.Groups = *req.Groups
+	}
+
+	r.Users = nil
+	if req.Users != nil {
+		r.Users = *req.Users
+	}
+}
+
+// Copy returns a copy of the role
+func (r *Role) Copy() *Role {
+	permissions := make(map[string][]string, len(r.Permissions))
+	for module, operations := range r.Permissions {
+		permissions[module] = slices.Clone(operations)
+	}
+
+	return &Role{
+		ID:          r.ID,
+		AccountID:   r.AccountID,
+		Name:        r.Name,
+		Description: r.Description,
+		Permissions: permissions,
+		Groups:      slices.Clone(r.Groups),
+		Users:       slices.Clone(r.Users),
+	}
+}
+
+func (r *Role) EventMeta() map[string]any {
+	return map[string]any{"name": r.Name}
+}
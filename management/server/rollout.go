@@ -0,0 +1,419 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/rs/xid"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/management/server/activity"
+	connectivityTypes "github.com/netbirdio/netbird/management/server/connectivity/types"
+	"github.com/netbirdio/netbird/management/server/permissions"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
+	"github.com/netbirdio/netbird/management/server/types"
+	"github.com/netbirdio/netbird/route"
+)
+
+// rolloutCheckInterval is the interval the route health reported by the canary peers of the active rollouts is checked
+const rolloutCheckInterval = time.Minute
+
+// StageRouteRollout applies the route change to the peers of the canary groups and the routing peers of the staged
+// route only. The change is promoted to all peers once the canary peers reported healthy routes for the observation
+// period, or rolled back.
+func (am *DefaultAccountManager) StageRouteRollout(ctx context.Context, accountID, userID string, routeToStage *route.Route, settings types.RolloutSettings) (*types.Rollout, error) {
+	if routeToStage == nil {
+		return nil, status.Errorf(status.InvalidArgument, "route provided is nil")
+	}
+
+	unlock := am.Store.AcquireWriteLockByUID(ctx, accountID)
+	defer unlock()
+
+	if err := am.validateRolloutUser(ctx, accountID, userID, permissions.Routes); err != nil {
+		return nil, err
+	}
+
+	account, err := am.Store.GetAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := account.Routes[routeToStage.ID]; !ok {
+		return nil, status.Errorf(status.NotFound, "route with ID %s doesn't exist", routeToStage.ID)
+	}
+
+	if err = am.validateRoute(account, routeToStage); err != nil {
+		return nil, err
+	}
+
+	rollout := &types.Rollout{
+		Kind:     types.RolloutKindRoute,
+		ObjectID: string(routeToStage.ID),
+		Route:    routeToStage,
+	}
+
+	return am.startRollout(ctx, account, userID, rollout, settings)
+}
+
+// StagePolicyRollout applies the policy change to the peers of the canary groups only. The change is promoted to all
+// peers once the canary peers reported healthy routes for the observation period, or rolled back.
+func (am *DefaultAccountManager) StagePolicyRollout(ctx context.Context, accountID, userID string, policy *types.Policy, settings types.RolloutSettings) (*types.Rollout, error) {
+	unlock := am.Store.AcquireWriteLockByUID(ctx, accountID)
+	defer unlock()
+
+	if err := am.validateRolloutUser(ctx, accountID, userID, permissions.Policies); err != nil {
+		return nil, err
+	}
+
+	if policy.ID == "" {
+		return nil, status.Errorf(status.InvalidArgument, "only changes of existing policies can be staged")
+	}
+	policy.AccountID = accountID
+
+	if err := validatePolicy(ctx, am.Store, accountID, policy); err != nil {
+		return nil, err
+	}
+
+	account, err := am.Store.GetAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	rollout := &types.Rollout{
+		Kind:     types.RolloutKindPolicy,
+		ObjectID: policy.ID,
+		Policy:   policy,
+	}
+
+	return am.startRollout(ctx, account, userID, rollout, settings)
+}
+
+func (am *DefaultAccountManager) startRollout(ctx context.Context, account *types.Account, userID string, rollout *types.Rollout, settings types.RolloutSettings) (*types.Rollout, error) {
+	if err := validateRolloutSettings(account, &settings); err != nil {
+		return nil, err
+	}
+
+	if slices.ContainsFunc(account.Rollouts, func(r *types.Rollout) bool {
+		return r.IsActive() && r.Kind == rollout.Kind && r.ObjectID == rollout.ObjectID
+	}) {
+		return nil, status.Errorf(status.PreconditionFailed, "the %s %s already has an active rollout", rollout.Kind, rollout.ObjectID)
+	}
+
+	rollout.ID = xid.New().String()
+	rollout.AccountID = account.Id
+	rollout.CanaryGroups = settings.CanaryGroups
+	rollout.ObservationPeriod = settings.ObservationPeriod
+	rollout.FailureThreshold = settings.FailureThreshold
+	rollout.AutoPromote = settings.AutoPromote
+	rollout.Status = types.RolloutStatusCanary
+	rollout.CreatedBy = userID
+	rollout.StartedAt = time.Now().UTC()
+
+	if err := am.Store.SaveRollout(ctx, store.LockingStrengthUpdate, rollout); err != nil {
+		return nil, err
+	}
+
+	am.StoreEvent(ctx, userID, rollout.ID, account.Id, activity.RolloutStarted, rolloutEventMeta(rollout))
+
+	go am.UpdateAccountPeers(ctx, account.Id)
+	am.scheduleRolloutChecks(ctx, account.Id)
+
+	return rollout, nil
+}
+
+func validateRolloutSettings(account *types.Account, settings *types.RolloutSettings) error {
+	if len(settings.CanaryGroups) == 0 {
+		return status.Errorf(status.InvalidArgument, "at least one canary group has to be set")
+	}
+	if err := validateGroups(settings.CanaryGroups, account.Groups); err != nil {
+		return err
+	}
+
+	if settings.ObservationPeriod == 0 {
+		settings.ObservationPeriod = types.DefaultRolloutObservationPeriod
+	}
+	if settings.ObservationPeriod < types.MinRolloutObservationPeriod || settings.ObservationPeriod > types.MaxRolloutObservationPeriod {
+		return status.Errorf(status.InvalidArgument, "observation period should be between %s and %s",
+			types.MinRolloutObservationPeriod, types.MaxRolloutObservationPeriod)
+	}
+
+	if settings.FailureThreshold < 0 || settings.FailureThreshold >= 1 {
+		return status.Errorf(status.InvalidArgument, "failure threshold should be at least 0 and below 1")
+	}
+
+	return nil
+}
+
+// GetRollout returns the rollout of the account
+func (am *DefaultAccountManager) GetRollout(ctx context.Context, accountID, userID, rolloutID string) (*types.Rollout, error) {
+	if err := am.validateRolloutReader(ctx, accountID, userID); err != nil {
+		return nil, err
+	}
+
+	return am.Store.GetRolloutByID(ctx, store.LockingStrengthShare, accountID, rolloutID)
+}
+
+// ListRollouts returns the rollouts of the account, the latest first
+func (am *DefaultAccountManager) ListRollouts(ctx context.Context, accountID, userID string) ([]*types.Rollout, error) {
+	if err := am.validateRolloutReader(ctx, accountID, userID); err != nil {
+		return nil, err
+	}
+
+	return am.Store.GetAccountRollouts(ctx, store.LockingStrengthShare, accountID)
+}
+
+// PromoteRollout saves the staged route or policy of the active rollout, applying it to all peers
+func (am *DefaultAccountManager) PromoteRollout(ctx context.Context, accountID, userID, rolloutID string) (*types.Rollout, error) {
+	rollout, err := am.Store.GetRolloutByID(ctx, store.LockingStrengthShare, accountID, rolloutID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = am.validateRolloutUser(ctx, accountID, userID, rolloutModule(rollout)); err != nil {
+		return nil, err
+	}
+
+	if !rollout.IsActive() {
+		return nil, status.Errorf(status.PreconditionFailed, "rollout %s is %s", rolloutID, rollout.Status)
+	}
+
+	return am.promoteRollout(ctx, rollout, userID)
+}
+
+// RollbackRollout removes the staged route or policy of the active rollout from the canary peers
+func (am *DefaultAccountManager) RollbackRollout(ctx context.Context, accountID, userID, rolloutID string) (*types.Rollout, error) {
+	unlock := am.Store.AcquireWriteLockByUID(ctx, accountID)
+	defer unlock()
+
+	rollout, err := am.Store.GetRolloutByID(ctx, store.LockingStrengthShare, accountID, rolloutID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = am.validateRolloutUser(ctx, accountID, userID, rolloutModule(rollout)); err != nil {
+		return nil, err
+	}
+
+	if !rollout.IsActive() {
+		return nil, status.Errorf(status.PreconditionFailed, "rollout %s is %s", rolloutID, rollout.Status)
+	}
+
+	return am.finishRollout(ctx, rollout, userID, types.RolloutStatusRolledBack, "rolled back by a user")
+}
+
+// promoteRollout saves the staged route or policy as the user. The route or the policy is saved before the rollout is
+// finished, so that the canary peers don't fall back to the previous version in between.
+func (am *DefaultAccountManager) promoteRollout(ctx context.Context, rollout *types.Rollout, userID string) (*types.Rollout, error) {
+	var err error
+	switch rollout.Kind {
+	case types.RolloutKindRoute:
+		err = am.SaveRoute(ctx, rollout.AccountID, userID, rollout.Route.Copy())
+	case types.RolloutKindPolicy:
+		_, err = am.SavePolicy(ctx, rollout.AccountID, userID, rollout.Policy.Copy())
+	default:
+		err = status.Errorf(status.Internal, "unknown rollout kind %s", rollout.Kind)
+	}
+	if err != nil {
+		if e, ok := status.FromError(err); !ok || e.Type() != status.NotFound {
+			return nil, err
+		}
+	}
+
+	unlock := am.Store.AcquireWriteLockByUID(ctx, rollout.AccountID)
+	defer unlock()
+
+	if err != nil {
+		return am.finishRollout(ctx, rollout, userID, types.RolloutStatusCancelled, fmt.Sprintf("the %s was deleted", rollout.Kind))
+	}
+	return am.finishRollout(ctx, rollout, userID, types.RolloutStatusPromoted, "")
+}
+
+// finishRollout saves the rollout with the final status, the account has to be locked
+func (am *DefaultAccountManager) finishRollout(ctx context.Context, rollout *types.Rollout, userID string, rolloutStatus types.RolloutStatus, reason string) (*types.Rollout, error) {
+	rollout.Finish(rolloutStatus, reason)
+	if err := am.Store.SaveRollout(ctx, store.LockingStrengthUpdate, rollout); err != nil {
+		return nil, err
+	}
+
+	switch rolloutStatus {
+	case types.RolloutStatusPromoted:
+		am.StoreEvent(ctx, userID, rollout.ID, rollout.AccountID, activity.RolloutPromoted, rolloutEventMeta(rollout))
+	case types.RolloutStatusRolledBack:
+		am.StoreEvent(ctx, userID, rollout.ID, rollout.AccountID, activity.RolloutRolledBack, rolloutEventMeta(rollout))
+	}
+
+	go am.UpdateAccountPeers(ctx, rollout.AccountID)
+
+	return rollout, nil
+}
+
+// scheduleActiveRollouts schedules the checks of the accounts with active rollouts after a restart
+func (am *DefaultAccountManager) scheduleActiveRollouts(ctx context.Context) {
+	rollouts, err := am.Store.GetActiveRollouts(ctx, store.LockingStrengthShare)
+	if err != nil {
+		log.WithContext(ctx).Errorf("failed to get active rollouts: %v", err)
+		return
+	}
+
+	scheduled := make(map[string]struct{})
+	for _, rollout := range rollouts {
+		if _, ok := scheduled[rollout.AccountID]; ok {
+			continue
+		}
+		scheduled[rollout.AccountID] = struct{}{}
+		am.scheduleRolloutChecks(ctx, rollout.AccountID)
+	}
+}
+
+// scheduleRolloutChecks schedules the checks of the active rollouts of the account unless they already are
+func (am *DefaultAccountManager) scheduleRolloutChecks(ctx context.Context, accountID string) {
+	am.rolloutChecks.Schedule(ctx, rolloutCheckInterval, accountID, am.rolloutCheckJob(ctx, accountID))
+}
+
+// rolloutCheckJob evaluates the route health reported by the canary peers of the active rollouts of the account,
+// rolling back the failed ones and promoting the observed ones. It runs as long as the account has active rollouts.
+func (am *DefaultAccountManager) rolloutCheckJob(ctx context.Context, accountID string) func() (time.Duration, bool) {
+	return func() (time.Duration, bool) {
+		rollouts, err := am.Store.GetAccountRollouts(ctx, store.LockingStrengthShare, accountID)
+		if err != nil {
+			log.WithContext(ctx).Errorf("failed to get rollouts of account %s: %v", accountID, err)
+			return peerSchedulerRetryInterval, true
+		}
+
+		rollouts = slices.DeleteFunc(rollouts, func(r *types.Rollout) bool { return !r.IsActive() })
+		if len(rollouts) == 0 {
+			return 0, false
+		}
+
+		account, err := am.Store.GetAccount(ctx, accountID)
+		if err != nil {
+			log.WithContext(ctx).Errorf("failed to get account %s: %v", accountID, err)
+			return peerSchedulerRetryInterval, true
+		}
+
+		reports, err := am.Store.GetAccountConnectivityReports(ctx, store.LockingStrengthShare, accountID)
+		if err != nil {
+			log.WithContext(ctx).Errorf("failed to get connectivity reports of account %s: %v", accountID, err)
+			return peerSchedulerRetryInterval, true
+		}
+
+		for _, rollout := range rollouts {
+			if err := am.checkRollout(ctx, account, rollout.ID, reports); err != nil {
+				log.WithContext(ctx).Errorf("failed to check rollout %s of account %s: %v", rollout.ID, accountID, err)
+			}
+		}
+
+		return rolloutCheckInterval, true
+	}
+}
+
+func (am *DefaultAccountManager) checkRollout(ctx context.Context, account *types.Account, rolloutID string, reports []*connectivityTypes.Report) error {
+	rollout, err := am.evaluateRollout(ctx, account, rolloutID, reports)
+	if err != nil || rollout == nil {
+		return err
+	}
+
+	_, err = am.promoteRollout(ctx, rollout, rollout.CreatedBy)
+	return err
+}
+
+// evaluateRollout updates the rollout from the route health reported by its canary peers, rolling it back if it
+// failed. It returns the rollout if it has to be promoted.
+func (am *DefaultAccountManager) evaluateRollout(ctx context.Context, account *types.Account, rolloutID string, reports []*connectivityTypes.Report) (*types.Rollout, error) {
+	unlock := am.Store.AcquireWriteLockByUID(ctx, account.Id)
+	defer unlock()
+
+	// the rollout is read again, a user might have finished it since the check started
+	rollout, err := am.Store.GetRolloutByID(ctx, store.LockingStrengthUpdate, account.Id, rolloutID)
+	if err != nil || !rollout.IsActive() {
+		return nil, err
+	}
+
+	if !rolloutObjectExists(account, rollout) {
+		_, err = am.finishRollout(ctx, rollout, activity.SystemInitiator, types.RolloutStatusCancelled, fmt.Sprintf("the %s was deleted", rollout.Kind))
+		return nil, err
+	}
+
+	rollout.ReportedPeers, rollout.UnhealthyPeers = rollout.Evaluate(account.GetRolloutCanaryPeers(rollout), reports)
+
+	switch {
+	case rollout.Failed():
+		reason := fmt.Sprintf("%d of %d canary peers reported unhealthy routes", rollout.UnhealthyPeers, rollout.ReportedPeers)
+		_, err = am.finishRollout(ctx, rollout, activity.SystemInitiator, types.RolloutStatusRolledBack, reason)
+		return nil, err
+	case rollout.ObservationEnded() && rollout.AutoPromote:
+		return rollout, nil
+	case rollout.ObservationEnded():
+		rollout.Status = types.RolloutStatusReady
+	}
+
+	return nil, am.Store.SaveRollout(ctx, store.LockingStrengthUpdate, rollout)
+}
+
+func rolloutObjectExists(account *types.Account, rollout *types.Rollout) bool {
+	switch rollout.Kind {
+	case types.RolloutKindRoute:
+		_, ok := account.Routes[route.ID(rollout.ObjectID)]
+		return ok
+	case types.RolloutKindPolicy:
+		return slices.ContainsFunc(account.Policies, func(p *types.Policy) bool { return p.ID == rollout.ObjectID })
+	}
+	return false
+}
+
+func (am *DefaultAccountManager) validateRolloutUser(ctx context.Context, accountID, userID string, module permissions.Module) error {
+	user, err := am.Store.GetUserByUserID(ctx, store.LockingStrengthShare, userID)
+	if err != nil {
+		return err
+	}
+
+	if err = am.permissionsManager.ValidateAccountAccess(ctx, accountID, user, false); err != nil {
+		return err
+	}
+
+	if !user.IsAdminOrServiceUser() && !am.hasCustomRolePermission(ctx, accountID, user, module, permissions.Write) {
+		return status.NewAdminPermissionError()
+	}
+
+	return nil
+}
+
+func (am *DefaultAccountManager) validateRolloutReader(ctx context.Context, accountID, userID string) error {
+	user, err := am.Store.GetUserByUserID(ctx, store.LockingStrengthShare, userID)
+	if err != nil {
+		return err
+	}
+
+	if err = am.permissionsManager.ValidateAccountAccess(ctx, accountID, user, false); err != nil {
+		return err
+	}
+
+	if !user.IsAdminOrServiceUser() && !am.hasCustomRolePermission(ctx, accountID, user, permissions.Routes, permissions.Read) {
+		return status.NewAdminPermissionError()
+	}
+
+	return nil
+}
+
+func rolloutModule(rollout *types.Rollout) permissions.Module {
+	if rollout.Kind == types.RolloutKindPolicy {
+		return permissions.Policies
+	}
+	return permissions.Routes
+}
+
+func rolloutEventMeta(rollout *types.Rollout) map[string]any {
+	meta := map[string]any{
+		"kind":          string(rollout.Kind),
+		"object_id":     rollout.ObjectID,
+		"canary_groups": rollout.CanaryGroups,
+		"status":        string(rollout.Status),
+	}
+	if rollout.Reason != "" {
+		meta["reason"] = rollout.Reason
+	}
+	return meta
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/netip"
+	"slices"
 	"unicode/utf8"
 
 	"github.com/rs/xid"
@@ -14,6 +15,8 @@ import (
 	"github.com/netbirdio/netbird/management/domain"
 	"github.com/netbirdio/netbird/management/proto"
 	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/labels"
+	"github.com/netbirdio/netbird/management/server/permissions"
 	"github.com/netbirdio/netbird/management/server/status"
 	"github.com/netbirdio/netbird/route"
 )
@@ -29,7 +32,7 @@ func (am *DefaultAccountManager) GetRoute(ctx context.Context, accountID string,
 		return nil, err
 	}
 
-	if !user.IsAdminOrServiceUser() {
+	if !user.IsAdminOrServiceUser() && !am.hasCustomRolePermission(ctx, accountID, user, permissions.Routes, permissions.Read) {
 		return nil, status.Errorf(status.PermissionDenied, "only users with admin power can view Network Routes")
 	}
 
@@ -79,7 +82,7 @@ func (am *DefaultAccountManager) checkRoutePrefixOrDomainsExistForPeers(account
 			return status.Errorf(status.InvalidArgument, "peer with ID %s not found", peerID)
 		}
 		if _, ok := seenPeers[peerID]; ok {
-			return status.Errorf(status.AlreadyExists,
+			return status.NewRouteConflictError(
 				"failed to add route with %s - peer %s already has this route", getRouteDescriptor(prefix, domains), peerID)
 		}
 	}
@@ -89,8 +92,8 @@ func (am *DefaultAccountManager) checkRoutePrefixOrDomainsExistForPeers(account
 		group := account.GetGroup(groupID) // we validated the group existence before entering this function, no need to check again.
 
 		if _, ok := seenPeerGroups[groupID]; ok {
-			return status.Errorf(
-				status.AlreadyExists, "failed to add route with %s - peer group %s already has this route",
+			return status.NewRouteConflictError(
+				"failed to add route with %s - peer group %s already has this route",
 				getRouteDescriptor(prefix, domains), group.Name)
 		}
 
@@ -101,7 +104,7 @@ func (am *DefaultAccountManager) checkRoutePrefixOrDomainsExistForPeers(account
 				if peer == nil {
 					return status.Errorf(status.InvalidArgument, "peer with ID %s not found", peerID)
 				}
-				return status.Errorf(status.AlreadyExists,
+				return status.NewRouteConflictError(
 					"failed to add route with %s - peer %s from the group %s already has this route",
 					getRouteDescriptor(prefix, domains), peer.Name, group.Name)
 			}
@@ -205,6 +208,10 @@ func (am *DefaultAccountManager) CreateRoute(ctx context.Context, accountID stri
 	newRoute.KeepRoute = keepRoute
 	newRoute.AccessControlGroups = accessControlGroupIDs
 
+	if err = validateRouteOverlaps(account, &newRoute); err != nil {
+		return nil, err
+	}
+
 	if account.Routes == nil {
 		account.Routes = make(map[route.ID]*route.Route)
 	}
@@ -234,14 +241,6 @@ func (am *DefaultAccountManager) SaveRoute(ctx context.Context, accountID, userI
 		return status.Errorf(status.InvalidArgument, "route provided is nil")
 	}
 
-	if routeToSave.Metric < route.MinMetric || routeToSave.Metric > route.MaxMetric {
-		return status.Errorf(status.InvalidArgument, "metric should be between %d and %d", route.MinMetric, route.MaxMetric)
-	}
-
-	if utf8.RuneCountInString(string(routeToSave.NetID)) > route.MaxNetIDChar || routeToSave.NetID == "" {
-		return status.Errorf(status.InvalidArgument, "identifier should be between 1 and %d", route.MaxNetIDChar)
-	}
-
 	user, err := am.Store.GetUserByUserID(ctx, store.LockingStrengthShare, userID)
 	if err != nil {
 		return err
@@ -256,6 +255,41 @@ func (am *DefaultAccountManager) SaveRoute(ctx context.Context, accountID, userI
 		return err
 	}
 
+	if err = am.validateRoute(account, routeToSave); err != nil {
+		return err
+	}
+
+	oldRoute := account.Routes[routeToSave.ID]
+	// the labels are kept when omitted on update
+	if routeToSave.Labels == nil && oldRoute != nil {
+		routeToSave.Labels = oldRoute.Labels
+	}
+	account.Routes[routeToSave.ID] = routeToSave
+
+	account.Network.IncSerial()
+	if err = am.Store.SaveAccount(ctx, account); err != nil {
+		return err
+	}
+
+	if am.isRouteChangeAffectPeers(account, oldRoute) || am.isRouteChangeAffectPeers(account, routeToSave) {
+		am.UpdateAccountPeers(ctx, accountID)
+	}
+
+	am.StoreEvent(ctx, userID, string(routeToSave.ID), accountID, activity.RouteUpdated, routeToSave.EventMeta())
+
+	return nil
+}
+
+// validateRoute checks the route to save against the account, the network of a domain route is set to the placeholder
+func (am *DefaultAccountManager) validateRoute(account *types.Account, routeToSave *route.Route) error {
+	if routeToSave.Metric < route.MinMetric || routeToSave.Metric > route.MaxMetric {
+		return status.Errorf(status.InvalidArgument, "metric should be between %d and %d", route.MinMetric, route.MaxMetric)
+	}
+
+	if utf8.RuneCountInString(string(routeToSave.NetID)) > route.MaxNetIDChar || routeToSave.NetID == "" {
+		return status.Errorf(status.InvalidArgument, "identifier should be between 1 and %d", route.MaxNetIDChar)
+	}
+
 	if len(routeToSave.Domains) > 0 && routeToSave.Network.IsValid() {
 		return status.Errorf(status.InvalidArgument, "domains and network should not be provided at the same time")
 	}
@@ -273,44 +307,35 @@ func (am *DefaultAccountManager) SaveRoute(ctx context.Context, accountID, userI
 	}
 
 	if len(routeToSave.PeerGroups) > 0 {
-		err = validateGroups(routeToSave.PeerGroups, account.Groups)
-		if err != nil {
+		if err := validateGroups(routeToSave.PeerGroups, account.Groups); err != nil {
 			return err
 		}
 	}
 
 	if len(routeToSave.AccessControlGroups) > 0 {
-		err = validateGroups(routeToSave.AccessControlGroups, account.Groups)
-		if err != nil {
+		if err := validateGroups(routeToSave.AccessControlGroups, account.Groups); err != nil {
 			return err
 		}
 	}
 
-	err = am.checkRoutePrefixOrDomainsExistForPeers(account, routeToSave.Peer, routeToSave.ID, routeToSave.Copy().PeerGroups, routeToSave.Network, routeToSave.Domains)
-	if err != nil {
-		return err
+	if err := labels.Validate(routeToSave.Labels); err != nil {
+		return status.Errorf(status.InvalidArgument, "%s", err)
 	}
 
-	err = validateGroups(routeToSave.Groups, account.Groups)
-	if err != nil {
+	if err := routeToSave.ValidateDNS(); err != nil {
 		return err
 	}
 
-	oldRoute := account.Routes[routeToSave.ID]
-	account.Routes[routeToSave.ID] = routeToSave
-
-	account.Network.IncSerial()
-	if err = am.Store.SaveAccount(ctx, account); err != nil {
+	err := am.checkRoutePrefixOrDomainsExistForPeers(account, routeToSave.Peer, routeToSave.ID, routeToSave.Copy().PeerGroups, routeToSave.Network, routeToSave.Domains)
+	if err != nil {
 		return err
 	}
 
-	if am.isRouteChangeAffectPeers(account, oldRoute) || am.isRouteChangeAffectPeers(account, routeToSave) {
-		am.UpdateAccountPeers(ctx, accountID)
+	if err = validateGroups(routeToSave.Groups, account.Groups); err != nil {
+		return err
 	}
 
-	am.StoreEvent(ctx, userID, string(routeToSave.ID), accountID, activity.RouteUpdated, routeToSave.EventMeta())
-
-	return nil
+	return validateRouteOverlaps(account, routeToSave)
 }
 
 // DeleteRoute deletes route with routeID
@@ -336,6 +361,12 @@ func (am *DefaultAccountManager) DeleteRoute(ctx context.Context, accountID stri
 	if routy == nil {
 		return status.Errorf(status.NotFound, "route with ID %s doesn't exist", routeID)
 	}
+
+	for _, key := range account.SetupKeys {
+		if slices.Contains(key.AutoRoutes, string(routeID)) {
+			return status.Errorf(status.PreconditionFailed, "route has been linked to setup key: %s", key.Name)
+		}
+	}
 	delete(account.Routes, routeID)
 
 	account.Network.IncSerial()
@@ -363,7 +394,7 @@ func (am *DefaultAccountManager) ListRoutes(ctx context.Context, accountID, user
 		return nil, err
 	}
 
-	if !user.IsAdminOrServiceUser() {
+	if !user.IsAdminOrServiceUser() && !am.hasCustomRolePermission(ctx, accountID, user, permissions.Routes, permissions.Read) {
 		return nil, status.Errorf(status.PermissionDenied, "only users with admin power can view Network Routes")
 	}
 
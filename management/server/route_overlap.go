@@ -0,0 +1,112 @@
+package server
+
+import (
+	"net"
+	"net/netip"
+	"slices"
+
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/types"
+	"github.com/netbirdio/netbird/route"
+)
+
+// validateRouteOverlaps checks that the network of the route doesn't fall into the overlay network of the account and
+// that no other route distributes the same network with another network identifier to the same groups. The clients
+// would send the traffic of the peers to the routing peer in the first case and pick one of the routes at random in
+// the second one.
+func validateRouteOverlaps(account *types.Account, routeToSave *route.Route) error {
+	if routeToSave.IsDynamic() {
+		return nil
+	}
+
+	if overlay, ok := overlayContaining(routeToSave.Network, overlayPrefixes(account.Network)); ok {
+		return status.Errorf(status.InvalidArgument,
+			"route %s network %s is part of the NetBird network %s of the account, the peers would route the traffic "+
+				"of the other peers through it. Use a network outside of %s or change the network range of the account",
+			routeToSave.NetID, routeToSave.Network, overlay, overlay)
+	}
+
+	if !routeToSave.Enabled {
+		return nil
+	}
+
+	for _, r := range account.Routes {
+		if r.ID == routeToSave.ID || r.NetID == routeToSave.NetID || !r.Enabled || r.IsDynamic() || r.Network != routeToSave.Network {
+			continue
+		}
+
+		for _, groupID := range routeToSave.Groups {
+			if !slices.Contains(r.Groups, groupID) {
+				continue
+			}
+			groupName := groupID
+			if group := account.GetGroup(groupID); group != nil {
+				groupName = group.Name
+			}
+			return status.Errorf(status.InvalidArgument,
+				"route %s network %s overlaps route %s that routes the same network to the peers of the group %s. "+
+					"Use the network identifier %s to make the routes highly available or distribute them to different groups",
+				routeToSave.NetID, routeToSave.Network, r.NetID, groupName, r.NetID)
+		}
+	}
+
+	return nil
+}
+
+// validateRoutesOutsideNetwork checks that none of the network routes falls into the new network range of the account
+func validateRoutesOutsideNetwork(routes []*route.Route, networkRange net.IPNet) error {
+	overlay, ok := ipNetToPrefix(networkRange)
+	if !ok {
+		return nil
+	}
+
+	for _, r := range routes {
+		if r.IsDynamic() {
+			continue
+		}
+		if _, ok := overlayContaining(r.Network, []netip.Prefix{overlay}); ok {
+			return status.Errorf(status.InvalidArgument,
+				"network range %s contains the network %s of route %s, change the network of the route first or use "+
+					"a network range that doesn't contain it", overlay, r.Network, r.NetID)
+		}
+	}
+
+	return nil
+}
+
+// overlayContaining returns the overlay network the prefix is part of. A route network containing an overlay network,
+// e.g. the default route of an exit node, doesn't overlap as the more specific overlay route is preferred.
+func overlayContaining(prefix netip.Prefix, overlays []netip.Prefix) (netip.Prefix, bool) {
+	if !prefix.IsValid() {
+		return netip.Prefix{}, false
+	}
+
+	for _, overlay := range overlays {
+		if prefix.Bits() >= overlay.Bits() && overlay.Contains(prefix.Addr()) {
+			return overlay, true
+		}
+	}
+
+	return netip.Prefix{}, false
+}
+
+// overlayPrefixes returns the overlay network of the account
+func overlayPrefixes(network *types.Network) []netip.Prefix {
+	if network == nil {
+		return nil
+	}
+
+	if prefix, ok := ipNetToPrefix(network.Net); ok {
+		return []netip.Prefix{prefix}
+	}
+	return nil
+}
+
+func ipNetToPrefix(ipNet net.IPNet) (netip.Prefix, bool) {
+	addr, ok := netip.AddrFromSlice(ipNet.IP)
+	if !ok || ipNet.Mask == nil {
+		return netip.Prefix{}, false
+	}
+	ones, _ := ipNet.Mask.Size()
+	return netip.PrefixFrom(addr.Unmap(), ones).Masked(), true
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/types"
+	"github.com/netbirdio/netbird/route"
+)
+
+func TestValidateRouteOverlaps(t *testing.T) {
+	_, overlay, err := net.ParseCIDR("100.64.0.0/16")
+	require.NoError(t, err)
+
+	account := &types.Account{
+		Network: &types.Network{Net: *overlay},
+		Groups: map[string]*types.Group{
+			"group1": {ID: "group1", Name: "dev"},
+			"group2": {ID: "group2", Name: "prod"},
+		},
+		Routes: map[route.ID]*route.Route{
+			"route1": {
+				ID:      "route1",
+				NetID:   "office",
+				Network: netip.MustParsePrefix("10.0.0.0/16"),
+				Groups:  []string{"group1"},
+				Enabled: true,
+			},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		route *route.Route
+		err   string
+	}{
+		{
+			name:  "route outside of the overlay network",
+			route: &route.Route{ID: "route2", NetID: "lab", Network: netip.MustParsePrefix("192.168.0.0/24"), Groups: []string{"group1"}, Enabled: true},
+		},
+		{
+			name:  "route in the overlay network",
+			route: &route.Route{ID: "route2", NetID: "lab", Network: netip.MustParsePrefix("100.64.10.0/24"), Groups: []string{"group1"}, Enabled: true},
+			err:   "route lab network 100.64.10.0/24 is part of the NetBird network 100.64.0.0/16",
+		},
+		{
+			name:  "route containing the overlay network",
+			route: &route.Route{ID: "route2", NetID: "exit", Network: netip.MustParsePrefix("0.0.0.0/0"), Groups: []string{"group1"}, Enabled: true},
+		},
+		{
+			name:  "same network with another identifier to the same group",
+			route: &route.Route{ID: "route2", NetID: "office-backup", Network: netip.MustParsePrefix("10.0.0.0/16"), Groups: []string{"group2", "group1"}, Enabled: true},
+			err:   "route office-backup network 10.0.0.0/16 overlaps route office that routes the same network to the peers of the group dev",
+		},
+		{
+			name:  "same network with the same identifier",
+			route: &route.Route{ID: "route2", NetID: "office", Network: netip.MustParsePrefix("10.0.0.0/16"), Groups: []string{"group1"}, Enabled: true},
+		},
+		{
+			name:  "same network with another identifier to another group",
+			route: &route.Route{ID: "route2", NetID: "office-backup", Network: netip.MustParsePrefix("10.0.0.0/16"), Groups: []string{"group2"}, Enabled: true},
+		},
+		{
+			name:  "disabled route with the same network",
+			route: &route.Route{ID: "route2", NetID: "office-backup", Network: netip.MustParsePrefix("10.0.0.0/16"), Groups: []string{"group1"}},
+		},
+		{
+			name:  "update of the existing route",
+			route: &route.Route{ID: "route1", NetID: "office-new", Network: netip.MustParsePrefix("10.0.0.0/16"), Groups: []string{"group1"}, Enabled: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRouteOverlaps(account, tt.route)
+			if tt.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+			sErr, ok := status.FromError(err)
+			require.True(t, ok)
+			assert.Equal(t, status.InvalidArgument, sErr.Type())
+		})
+	}
+}
+
+func TestValidateRoutesOutsideNetwork(t *testing.T) {
+	routes := []*route.Route{
+		{NetID: "office", Network: netip.MustParsePrefix("10.10.0.0/16")},
+		{NetID: "exit", Network: netip.MustParsePrefix("0.0.0.0/0")},
+		{NetID: "dns", Network: netip.MustParsePrefix("192.0.2.0/32"), NetworkType: route.DomainNetwork},
+	}
+
+	_, networkRange, err := net.ParseCIDR("100.64.0.0/16")
+	require.NoError(t, err)
+	assert.NoError(t, validateRoutesOutsideNetwork(routes, *networkRange))
+
+	_, networkRange, err = net.ParseCIDR("10.0.0.0/8")
+	require.NoError(t, err)
+	err = validateRoutesOutsideNetwork(routes, *networkRange)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "network range 10.0.0.0/8 contains the network 10.10.0.0/16 of route office")
+}
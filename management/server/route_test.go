@@ -23,10 +23,12 @@ import (
 	nbpeer "github.com/netbirdio/netbird/management/server/peer"
 	"github.com/netbirdio/netbird/management/server/permissions"
 	"github.com/netbirdio/netbird/management/server/settings"
+	"github.com/netbirdio/netbird/management/server/status"
 	"github.com/netbirdio/netbird/management/server/store"
 	"github.com/netbirdio/netbird/management/server/telemetry"
 	"github.com/netbirdio/netbird/management/server/types"
 	"github.com/netbirdio/netbird/route"
+	"github.com/netbirdio/netbird/util/errcode"
 )
 
 const (
@@ -54,6 +56,14 @@ const (
 var existingNetwork = netip.MustParsePrefix("10.10.10.0/24")
 var existingDomains = domain.List{"example.com"}
 
+// requireRouteConflict asserts the error is an AlreadyExists error with the route conflict code
+func requireRouteConflict(t require.TestingT, err error, msgAndArgs ...interface{}) {
+	sErr, ok := status.FromError(err)
+	require.True(t, ok, msgAndArgs...)
+	require.Equal(t, status.AlreadyExists, sErr.Type(), msgAndArgs...)
+	require.Equal(t, errcode.RouteConflict, errcode.Of(err), msgAndArgs...)
+}
+
 func TestCreateRoute(t *testing.T) {
 	type input struct {
 		network             netip.Prefix
@@ -235,7 +245,7 @@ func TestCreateRoute(t *testing.T) {
 				groups:      []string{routeGroup1},
 			},
 			createInitRoute: true,
-			errFunc:         require.Error,
+			errFunc:         requireRouteConflict,
 			shouldCreate:    false,
 		},
 		{
@@ -252,7 +262,7 @@ func TestCreateRoute(t *testing.T) {
 				groups:      []string{routeGroup1},
 			},
 			createInitRoute: true,
-			errFunc:         require.Error,
+			errFunc:         requireRouteConflict,
 			shouldCreate:    false,
 		},
 		{
@@ -269,7 +279,7 @@ func TestCreateRoute(t *testing.T) {
 				groups:       []string{routeGroup1},
 			},
 			createInitRoute: true,
-			errFunc:         require.Error,
+			errFunc:         requireRouteConflict,
 			shouldCreate:    false,
 		},
 		{
@@ -286,7 +296,7 @@ func TestCreateRoute(t *testing.T) {
 				groups:       []string{routeGroup1},
 			},
 			createInitRoute: true,
-			errFunc:         require.Error,
+			errFunc:         requireRouteConflict,
 			shouldCreate:    false,
 		},
 		{
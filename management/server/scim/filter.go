@@ -0,0 +1,56 @@
+package scim
+
+import (
+	"strings"
+
+	"github.com/netbirdio/netbird/management/server/status"
+)
+
+// filter is a parsed SCIM filter. Only the equality operator is supported
+// as it is the only one used by the identity providers to look up resources.
+type filter struct {
+	attribute string
+	value     string
+}
+
+// parseFilter parses a filter of the form `attribute eq "value"`. An empty expression matches everything.
+func parseFilter(expression string) (*filter, error) {
+	expression = strings.TrimSpace(expression)
+	if expression == "" {
+		return nil, nil
+	}
+
+	parts := strings.SplitN(expression, " ", 3)
+	if len(parts) != 3 || !strings.EqualFold(parts[1], "eq") {
+		return nil, status.Errorf(status.InvalidArgument, "unsupported filter: %s", expression)
+	}
+
+	value := strings.TrimSpace(parts[2])
+	if len(value) < 2 || !strings.HasPrefix(value, `"`) || !strings.HasSuffix(value, `"`) {
+		return nil, status.Errorf(status.InvalidArgument, "filter value must be a quoted string: %s", expression)
+	}
+
+	return &filter{
+		attribute: strings.ToLower(parts[0]),
+		value:     strings.ReplaceAll(value[1:len(value)-1], `\"`, `"`),
+	}, nil
+}
+
+// matches returns true if the filter matches the given attribute values. Attribute names are lower-cased.
+func (f *filter) matches(attributes map[string]string) bool {
+	if f == nil {
+		return true
+	}
+
+	value, ok := attributes[f.attribute]
+	if !ok {
+		return false
+	}
+
+	// userName is case-insensitive according to RFC 7643
+	if f.attribute == "username" {
+		return strings.EqualFold(value, f.value)
+	}
+
+	return value == f.value
+}
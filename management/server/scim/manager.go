@@ -0,0 +1,943 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/rs/xid"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/management/server/account"
+	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/idp"
+	"github.com/netbirdio/netbird/management/server/integration_reference"
+	"github.com/netbirdio/netbird/management/server/permissions"
+	"github.com/netbirdio/netbird/management/server/scim/types"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
+	nbtypes "github.com/netbirdio/netbird/management/server/types"
+)
+
+// Manager handles the resources pushed by a SCIM client (e.g. Okta or Entra ID).
+// SCIM users are NetBird users issued by the "scim" integration and SCIM group membership
+// is mapped to the users' auto groups.
+type Manager interface {
+	ListUsers(ctx context.Context, accountID, userID, filter string) ([]*types.User, error)
+	GetUser(ctx context.Context, accountID, userID, id string) (*types.User, error)
+	CreateUser(ctx context.Context, accountID, userID string, user *types.User) (*types.User, error)
+	ReplaceUser(ctx context.Context, accountID, userID, id string, user *types.User) (*types.User, error)
+	PatchUser(ctx context.Context, accountID, userID, id string, operations []types.PatchOperation) (*types.User, error)
+	DeleteUser(ctx context.Context, accountID, userID, id string) error
+
+	ListGroups(ctx context.Context, accountID, userID, filter string) ([]*types.Group, error)
+	GetGroup(ctx context.Context, accountID, userID, id string) (*types.Group, error)
+	CreateGroup(ctx context.Context, accountID, userID string, group *types.Group) (*types.Group, error)
+	ReplaceGroup(ctx context.Context, accountID, userID, id string, group *types.Group) (*types.Group, error)
+	PatchGroup(ctx context.Context, accountID, userID, id string, operations []types.PatchOperation) (*types.Group, error)
+	DeleteGroup(ctx context.Context, accountID, userID, id string) error
+}
+
+type managerImpl struct {
+	store              store.Store
+	permissionsManager permissions.Manager
+	accountManager     account.Manager
+}
+
+type mockManager struct {
+}
+
+func NewManager(store store.Store, permissionsManager permissions.Manager, accountManager account.Manager) Manager {
+	return &managerImpl{
+		store:              store,
+		permissionsManager: permissionsManager,
+		accountManager:     accountManager,
+	}
+}
+
+func (m *managerImpl) validatePermissions(ctx context.Context, accountID, userID string, operation permissions.Operation) error {
+	ok, err := m.permissionsManager.ValidateUserPermissions(ctx, accountID, userID, permissions.Users, operation)
+	if err != nil {
+		return status.NewPermissionValidationError(err)
+	}
+	if !ok {
+		return status.NewPermissionDeniedError()
+	}
+
+	ok, err = m.permissionsManager.ValidateUserPermissions(ctx, accountID, userID, permissions.Groups, operation)
+	if err != nil {
+		return status.NewPermissionValidationError(err)
+	}
+	if !ok {
+		return status.NewPermissionDeniedError()
+	}
+
+	return nil
+}
+
+func (m *managerImpl) ListUsers(ctx context.Context, accountID, userID, filterExpression string) ([]*types.User, error) {
+	if err := m.validatePermissions(ctx, accountID, userID, permissions.Read); err != nil {
+		return nil, err
+	}
+
+	f, err := parseFilter(filterExpression)
+	if err != nil {
+		return nil, err
+	}
+
+	provisionedUsers, err := m.store.GetAccountProvisionedUsers(ctx, store.LockingStrengthShare, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := m.getAccountUsersMap(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := m.getAccountGroupsMap(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	scimUsers := make([]*types.User, 0, len(provisionedUsers))
+	for _, provisionedUser := range provisionedUsers {
+		user, ok := users[provisionedUser.ID]
+		if !ok {
+			// the user was removed outside SCIM, e.g. from the dashboard
+			continue
+		}
+
+		if !f.matches(map[string]string{
+			"id":           provisionedUser.ID,
+			"username":     provisionedUser.UserName,
+			"externalid":   provisionedUser.ExternalID,
+			"displayname":  provisionedUser.DisplayName,
+			"emails":       provisionedUser.Email,
+			"emails.value": provisionedUser.Email,
+		}) {
+			continue
+		}
+
+		scimUsers = append(scimUsers, toSCIMUser(provisionedUser, user, groups))
+	}
+
+	return scimUsers, nil
+}
+
+func (m *managerImpl) GetUser(ctx context.Context, accountID, userID, id string) (*types.User, error) {
+	if err := m.validatePermissions(ctx, accountID, userID, permissions.Read); err != nil {
+		return nil, err
+	}
+
+	provisionedUser, user, err := m.getProvisionedUser(ctx, accountID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := m.getAccountGroupsMap(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toSCIMUser(provisionedUser, user, groups), nil
+}
+
+// CreateUser provisions a new NetBird user. The externalId of the SCIM user has to be set to the
+// identity provider user ID, the one used as the subject of the access tokens, so the user
+// can be matched on login. An existing NetBird user with this ID is taken over by SCIM.
+func (m *managerImpl) CreateUser(ctx context.Context, accountID, userID string, scimUser *types.User) (*types.User, error) {
+	if err := m.validatePermissions(ctx, accountID, userID, permissions.Write); err != nil {
+		return nil, err
+	}
+
+	if err := validateUser(scimUser); err != nil {
+		return nil, err
+	}
+
+	if scimUser.ExternalID == "" {
+		return nil, status.Errorf(status.InvalidArgument, "externalId is required and must be set to the identity provider user ID")
+	}
+
+	if err := m.validateUserNameUnique(ctx, accountID, "", scimUser.UserName); err != nil {
+		return nil, err
+	}
+
+	user, err := m.store.GetUserByUserID(ctx, store.LockingStrengthShare, scimUser.ExternalID)
+	if err != nil {
+		if sErr, ok := status.FromError(err); !ok || sErr.Type() != status.NotFound {
+			return nil, err
+		}
+		user = nbtypes.NewRegularUser(scimUser.ExternalID)
+	} else {
+		if user.AccountID != accountID {
+			return nil, status.Errorf(status.AlreadyExists, "user %s already exists", scimUser.ExternalID)
+		}
+		if user.IsServiceUser {
+			return nil, status.Errorf(status.InvalidArgument, "can't provision service user %s", user.Id)
+		}
+		user = user.Copy()
+	}
+
+	user.AccountID = accountID
+	user.Blocked = !scimUser.IsActive()
+	user.Issued = nbtypes.UserIssuedIntegration
+	user.IntegrationReference = integration_reference.IntegrationReference{IntegrationType: types.IntegrationType}
+
+	provisionedUser := &types.ProvisionedUser{ID: user.Id, AccountID: accountID}
+	provisionedUser.FromUser(scimUser)
+
+	if _, err = m.accountManager.SaveOrAddUser(ctx, accountID, userID, user, true); err != nil {
+		return nil, err
+	}
+
+	if err = m.saveProvisionedUser(ctx, provisionedUser); err != nil {
+		return nil, err
+	}
+
+	m.accountManager.StoreEvent(ctx, userID, user.Id, accountID, activity.UserProvisioned, map[string]any{"username": provisionedUser.UserName})
+
+	return m.GetUser(ctx, accountID, userID, user.Id)
+}
+
+func (m *managerImpl) ReplaceUser(ctx context.Context, accountID, userID, id string, scimUser *types.User) (*types.User, error) {
+	if err := m.validatePermissions(ctx, accountID, userID, permissions.Write); err != nil {
+		return nil, err
+	}
+
+	if err := validateUser(scimUser); err != nil {
+		return nil, err
+	}
+
+	provisionedUser, user, err := m.getProvisionedUser(ctx, accountID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = m.validateUserNameUnique(ctx, accountID, id, scimUser.UserName); err != nil {
+		return nil, err
+	}
+
+	provisionedUser = provisionedUser.Copy()
+	provisionedUser.FromUser(scimUser)
+
+	return m.updateUser(ctx, accountID, userID, provisionedUser, user, scimUser.IsActive())
+}
+
+func (m *managerImpl) PatchUser(ctx context.Context, accountID, userID, id string, operations []types.PatchOperation) (*types.User, error) {
+	if err := m.validatePermissions(ctx, accountID, userID, permissions.Write); err != nil {
+		return nil, err
+	}
+
+	provisionedUser, user, err := m.getProvisionedUser(ctx, accountID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	scimUser := provisionedUser.ToUser(!user.Blocked, nil)
+	for _, operation := range operations {
+		if err = applyUserOperation(scimUser, operation); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = validateUser(scimUser); err != nil {
+		return nil, err
+	}
+
+	if err = m.validateUserNameUnique(ctx, accountID, id, scimUser.UserName); err != nil {
+		return nil, err
+	}
+
+	provisionedUser = provisionedUser.Copy()
+	provisionedUser.FromUser(scimUser)
+
+	return m.updateUser(ctx, accountID, userID, provisionedUser, user, scimUser.IsActive())
+}
+
+// DeleteUser removes the provisioned user from the account together with its peers
+func (m *managerImpl) DeleteUser(ctx context.Context, accountID, userID, id string) error {
+	if err := m.validatePermissions(ctx, accountID, userID, permissions.Write); err != nil {
+		return err
+	}
+
+	provisionedUser, _, err := m.getProvisionedUser(ctx, accountID, id)
+	if err != nil {
+		return err
+	}
+
+	if err = m.accountManager.DeleteUser(ctx, accountID, userID, provisionedUser.ID); err != nil {
+		return err
+	}
+
+	if err = m.store.DeleteProvisionedUser(ctx, store.LockingStrengthUpdate, accountID, provisionedUser.ID); err != nil {
+		return err
+	}
+
+	key := integration_reference.IntegrationReference{IntegrationType: types.IntegrationType}.CacheKey(accountID, provisionedUser.ID)
+	if err = m.accountManager.GetExternalCacheManager().Delete(ctx, key); err != nil {
+		log.WithContext(ctx).Debugf("failed to delete provisioned user %s from cache: %v", provisionedUser.ID, err)
+	}
+
+	return nil
+}
+
+func (m *managerImpl) ListGroups(ctx context.Context, accountID, userID, filterExpression string) ([]*types.Group, error) {
+	if err := m.validatePermissions(ctx, accountID, userID, permissions.Read); err != nil {
+		return nil, err
+	}
+
+	f, err := parseFilter(filterExpression)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := m.store.GetAccountGroups(ctx, store.LockingStrengthShare, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := m.store.GetAccountUsers(ctx, store.LockingStrengthShare, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	scimGroups := make([]*types.Group, 0, len(groups))
+	for _, group := range groups {
+		if group.IsGroupAll() {
+			continue
+		}
+
+		if !f.matches(map[string]string{"id": group.ID, "displayname": group.Name}) {
+			continue
+		}
+
+		scimGroups = append(scimGroups, toSCIMGroup(group, users))
+	}
+
+	return scimGroups, nil
+}
+
+func (m *managerImpl) GetGroup(ctx context.Context, accountID, userID, id string) (*types.Group, error) {
+	if err := m.validatePermissions(ctx, accountID, userID, permissions.Read); err != nil {
+		return nil, err
+	}
+
+	group, err := m.getGroup(ctx, accountID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := m.store.GetAccountUsers(ctx, store.LockingStrengthShare, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toSCIMGroup(group, users), nil
+}
+
+func (m *managerImpl) CreateGroup(ctx context.Context, accountID, userID string, scimGroup *types.Group) (*types.Group, error) {
+	if err := m.validatePermissions(ctx, accountID, userID, permissions.Write); err != nil {
+		return nil, err
+	}
+
+	if scimGroup.DisplayName == "" {
+		return nil, status.Errorf(status.InvalidArgument, "displayName is required")
+	}
+
+	if err := m.validateGroupNameUnique(ctx, accountID, "", scimGroup.DisplayName); err != nil {
+		return nil, err
+	}
+
+	group := &nbtypes.Group{
+		ID:                   xid.New().String(),
+		AccountID:            accountID,
+		Name:                 scimGroup.DisplayName,
+		Issued:               nbtypes.GroupIssuedIntegration,
+		Peers:                []string{},
+		IntegrationReference: integration_reference.IntegrationReference{IntegrationType: types.IntegrationType},
+	}
+
+	if err := m.accountManager.SaveGroup(ctx, accountID, userID, group); err != nil {
+		return nil, err
+	}
+
+	if err := m.updateGroupMembers(ctx, accountID, userID, group.ID, memberIDs(scimGroup.Members), nil); err != nil {
+		return nil, err
+	}
+
+	return m.GetGroup(ctx, accountID, userID, group.ID)
+}
+
+func (m *managerImpl) ReplaceGroup(ctx context.Context, accountID, userID, id string, scimGroup *types.Group) (*types.Group, error) {
+	if err := m.validatePermissions(ctx, accountID, userID, permissions.Write); err != nil {
+		return nil, err
+	}
+
+	if scimGroup.DisplayName == "" {
+		return nil, status.Errorf(status.InvalidArgument, "displayName is required")
+	}
+
+	group, err := m.getGroup(ctx, accountID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = m.renameGroup(ctx, accountID, userID, group, scimGroup.DisplayName); err != nil {
+		return nil, err
+	}
+
+	users, err := m.store.GetAccountUsers(ctx, store.LockingStrengthShare, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	currentMembers := memberIDs(toSCIMGroup(group, users).Members)
+	newMembers := memberIDs(scimGroup.Members)
+
+	toAdd := difference(newMembers, currentMembers)
+	toRemove := difference(currentMembers, newMembers)
+	if err = m.updateGroupMembers(ctx, accountID, userID, group.ID, toAdd, toRemove); err != nil {
+		return nil, err
+	}
+
+	return m.GetGroup(ctx, accountID, userID, group.ID)
+}
+
+func (m *managerImpl) PatchGroup(ctx context.Context, accountID, userID, id string, operations []types.PatchOperation) (*types.Group, error) {
+	if err := m.validatePermissions(ctx, accountID, userID, permissions.Write); err != nil {
+		return nil, err
+	}
+
+	group, err := m.getGroup(ctx, accountID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := m.store.GetAccountUsers(ctx, store.LockingStrengthShare, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	scimGroup := toSCIMGroup(group, users)
+	currentMembers := memberIDs(scimGroup.Members)
+
+	for _, operation := range operations {
+		if err = applyGroupOperation(scimGroup, operation); err != nil {
+			return nil, err
+		}
+	}
+
+	if scimGroup.DisplayName == "" {
+		return nil, status.Errorf(status.InvalidArgument, "displayName is required")
+	}
+
+	if err = m.renameGroup(ctx, accountID, userID, group, scimGroup.DisplayName); err != nil {
+		return nil, err
+	}
+
+	newMembers := memberIDs(scimGroup.Members)
+	toAdd := difference(newMembers, currentMembers)
+	toRemove := difference(currentMembers, newMembers)
+	if err = m.updateGroupMembers(ctx, accountID, userID, group.ID, toAdd, toRemove); err != nil {
+		return nil, err
+	}
+
+	return m.GetGroup(ctx, accountID, userID, group.ID)
+}
+
+// DeleteGroup removes the group from its members' auto groups and deletes it
+func (m *managerImpl) DeleteGroup(ctx context.Context, accountID, userID, id string) error {
+	if err := m.validatePermissions(ctx, accountID, userID, permissions.Write); err != nil {
+		return err
+	}
+
+	group, err := m.getGroup(ctx, accountID, id)
+	if err != nil {
+		return err
+	}
+
+	users, err := m.store.GetAccountUsers(ctx, store.LockingStrengthShare, accountID)
+	if err != nil {
+		return err
+	}
+
+	if err = m.updateGroupMembers(ctx, accountID, userID, group.ID, nil, memberIDs(toSCIMGroup(group, users).Members)); err != nil {
+		return err
+	}
+
+	return m.accountManager.DeleteGroup(ctx, accountID, userID, group.ID)
+}
+
+func (m *managerImpl) getProvisionedUser(ctx context.Context, accountID, id string) (*types.ProvisionedUser, *nbtypes.User, error) {
+	provisionedUser, err := m.store.GetProvisionedUserByID(ctx, store.LockingStrengthShare, accountID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := m.store.GetUserByUserID(ctx, store.LockingStrengthShare, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if user.AccountID != accountID {
+		return nil, nil, status.NewUserNotFoundError(id)
+	}
+
+	return provisionedUser, user, nil
+}
+
+func (m *managerImpl) updateUser(ctx context.Context, accountID, userID string, provisionedUser *types.ProvisionedUser, user *nbtypes.User, active bool) (*types.User, error) {
+	if user.Blocked == active {
+		update := user.Copy()
+		update.Blocked = !active
+		if _, err := m.accountManager.SaveOrAddUser(ctx, accountID, userID, update, false); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := m.saveProvisionedUser(ctx, provisionedUser); err != nil {
+		return nil, err
+	}
+
+	return m.GetUser(ctx, accountID, userID, provisionedUser.ID)
+}
+
+// saveProvisionedUser stores the provisioned user and caches its data so it is displayed
+// for the integration issued user without querying the identity provider
+func (m *managerImpl) saveProvisionedUser(ctx context.Context, provisionedUser *types.ProvisionedUser) error {
+	if err := m.store.SaveProvisionedUser(ctx, store.LockingStrengthUpdate, provisionedUser); err != nil {
+		return err
+	}
+
+	userData := &idp.UserData{
+		ID:    provisionedUser.ID,
+		Email: provisionedUser.Email,
+		Name:  provisionedUser.FullName(),
+	}
+
+	// the SCIM client is the source of truth for the user data and the entry is refreshed on every update
+	key := integration_reference.IntegrationReference{IntegrationType: types.IntegrationType}.CacheKey(provisionedUser.AccountID, provisionedUser.ID)
+	if err := m.accountManager.GetExternalCacheManager().Set(ctx, key, userData, 0); err != nil {
+		log.WithContext(ctx).Errorf("failed to cache provisioned user %s data: %v", provisionedUser.ID, err)
+	}
+
+	return nil
+}
+
+func (m *managerImpl) validateUserNameUnique(ctx context.Context, accountID, id, userName string) error {
+	provisionedUsers, err := m.store.GetAccountProvisionedUsers(ctx, store.LockingStrengthShare, accountID)
+	if err != nil {
+		return err
+	}
+
+	for _, provisionedUser := range provisionedUsers {
+		if provisionedUser.ID != id && strings.EqualFold(provisionedUser.UserName, userName) {
+			return status.Errorf(status.AlreadyExists, "user with userName %s already exists", userName)
+		}
+	}
+
+	return nil
+}
+
+func (m *managerImpl) getGroup(ctx context.Context, accountID, id string) (*nbtypes.Group, error) {
+	group, err := m.store.GetGroupByID(ctx, store.LockingStrengthShare, accountID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if group.IsGroupAll() {
+		return nil, status.NewGroupNotFoundError(id)
+	}
+
+	return group, nil
+}
+
+func (m *managerImpl) validateGroupNameUnique(ctx context.Context, accountID, id, name string) error {
+	group, err := m.store.GetGroupByName(ctx, store.LockingStrengthShare, accountID, name)
+	if err != nil {
+		if sErr, ok := status.FromError(err); ok && sErr.Type() == status.NotFound {
+			return nil
+		}
+		return err
+	}
+
+	if group.ID != id {
+		return status.Errorf(status.AlreadyExists, "group with name %s already exists", name)
+	}
+
+	return nil
+}
+
+func (m *managerImpl) renameGroup(ctx context.Context, accountID, userID string, group *nbtypes.Group, name string) error {
+	if group.Name == name {
+		return nil
+	}
+
+	if err := m.validateGroupNameUnique(ctx, accountID, group.ID, name); err != nil {
+		return err
+	}
+
+	update := group.Copy()
+	update.Name = name
+	return m.accountManager.SaveGroup(ctx, accountID, userID, update)
+}
+
+// updateGroupMembers adds the group to the auto groups of the added members and removes it from the removed ones
+func (m *managerImpl) updateGroupMembers(ctx context.Context, accountID, userID, groupID string, toAdd, toRemove []string) error {
+	if len(toAdd) == 0 && len(toRemove) == 0 {
+		return nil
+	}
+
+	updates := make(map[string]*nbtypes.User)
+	getUpdate := func(memberID string) (*nbtypes.User, error) {
+		if update, ok := updates[memberID]; ok {
+			return update, nil
+		}
+
+		user, err := m.store.GetUserByUserID(ctx, store.LockingStrengthShare, memberID)
+		if err != nil || user.AccountID != accountID {
+			return nil, status.Errorf(status.InvalidArgument, "member %s doesn't exist", memberID)
+		}
+
+		update := user.Copy()
+		updates[memberID] = update
+		return update, nil
+	}
+
+	for _, memberID := range toAdd {
+		update, err := getUpdate(memberID)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(update.AutoGroups, groupID) {
+			update.AutoGroups = append(update.AutoGroups, groupID)
+		}
+	}
+
+	for _, memberID := range toRemove {
+		update, err := getUpdate(memberID)
+		if err != nil {
+			return err
+		}
+		update.AutoGroups = slices.DeleteFunc(update.AutoGroups, func(id string) bool {
+			return id == groupID
+		})
+	}
+
+	usersToSave := make([]*nbtypes.User, 0, len(updates))
+	for _, update := range updates {
+		usersToSave = append(usersToSave, update)
+	}
+
+	unlock := m.store.AcquireWriteLockByUID(ctx, accountID)
+	defer unlock()
+
+	_, err := m.accountManager.SaveOrAddUsers(ctx, accountID, userID, usersToSave, false)
+	return err
+}
+
+func (m *managerImpl) getAccountUsersMap(ctx context.Context, accountID string) (map[string]*nbtypes.User, error) {
+	users, err := m.store.GetAccountUsers(ctx, store.LockingStrengthShare, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	usersMap := make(map[string]*nbtypes.User, len(users))
+	for _, user := range users {
+		usersMap[user.Id] = user
+	}
+
+	return usersMap, nil
+}
+
+func (m *managerImpl) getAccountGroupsMap(ctx context.Context, accountID string) (map[string]*nbtypes.Group, error) {
+	groups, err := m.store.GetAccountGroups(ctx, store.LockingStrengthShare, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	groupsMap := make(map[string]*nbtypes.Group, len(groups))
+	for _, group := range groups {
+		groupsMap[group.ID] = group
+	}
+
+	return groupsMap, nil
+}
+
+func validateUser(user *types.User) error {
+	if user.UserName == "" {
+		return status.Errorf(status.InvalidArgument, "userName is required")
+	}
+	return nil
+}
+
+func toSCIMUser(provisionedUser *types.ProvisionedUser, user *nbtypes.User, groups map[string]*nbtypes.Group) *types.User {
+	refs := make([]types.Reference, 0, len(user.AutoGroups))
+	for _, groupID := range user.AutoGroups {
+		group, ok := groups[groupID]
+		if !ok {
+			continue
+		}
+		refs = append(refs, types.Reference{Value: group.ID, Display: group.Name})
+	}
+
+	return provisionedUser.ToUser(!user.Blocked, refs)
+}
+
+func toSCIMGroup(group *nbtypes.Group, users []*nbtypes.User) *types.Group {
+	members := make([]types.Reference, 0)
+	for _, user := range users {
+		if user.IsServiceUser || !slices.Contains(user.AutoGroups, group.ID) {
+			continue
+		}
+		members = append(members, types.Reference{Value: user.Id})
+	}
+
+	return &types.Group{
+		Schemas:     []string{types.GroupSchema},
+		ID:          group.ID,
+		DisplayName: group.Name,
+		Members:     members,
+		Meta:        &types.Meta{ResourceType: types.GroupResourceType},
+	}
+}
+
+// applyUserOperation applies a PATCH operation to the user. Only the simple attributes
+// supported by NetBird can be patched.
+func applyUserOperation(user *types.User, operation types.PatchOperation) error {
+	op := operation.Operation()
+	if op != "add" && op != "replace" {
+		return status.Errorf(status.InvalidArgument, "unsupported user patch operation: %s", operation.Op)
+	}
+
+	if operation.Path == "" {
+		var values map[string]json.RawMessage
+		if err := json.Unmarshal(operation.Value, &values); err != nil {
+			return status.Errorf(status.InvalidArgument, "invalid patch value: %v", err)
+		}
+		for path, value := range values {
+			if err := setUserAttribute(user, path, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return setUserAttribute(user, operation.Path, operation.Value)
+}
+
+func setUserAttribute(user *types.User, path string, value json.RawMessage) error {
+	if user.Name == nil {
+		user.Name = &types.Name{}
+	}
+
+	var err error
+	switch strings.ToLower(path) {
+	case "active":
+		var active bool
+		active, err = parseBool(value)
+		user.Active = &active
+	case "username":
+		err = json.Unmarshal(value, &user.UserName)
+	case "externalid":
+		err = json.Unmarshal(value, &user.ExternalID)
+	case "displayname":
+		err = json.Unmarshal(value, &user.DisplayName)
+	case "name":
+		err = json.Unmarshal(value, user.Name)
+	case "name.givenname":
+		err = json.Unmarshal(value, &user.Name.GivenName)
+	case "name.familyname":
+		err = json.Unmarshal(value, &user.Name.FamilyName)
+	case "emails":
+		err = json.Unmarshal(value, &user.Emails)
+	case `emails[type eq "work"].value`, "emails.value":
+		var email string
+		err = json.Unmarshal(value, &email)
+		user.Emails = []types.Email{{Value: email, Type: "work", Primary: true}}
+	default:
+		// attributes not supported by NetBird are ignored
+		log.Debugf("ignoring unsupported SCIM user attribute %s", path)
+	}
+
+	if err != nil {
+		return status.Errorf(status.InvalidArgument, "invalid value of %s: %v", path, err)
+	}
+
+	return nil
+}
+
+// applyGroupOperation applies a PATCH operation to the group display name or members
+func applyGroupOperation(group *types.Group, operation types.PatchOperation) error {
+	path := strings.ToLower(operation.Path)
+
+	switch operation.Operation() {
+	case "add", "replace":
+		if path == "" {
+			var values map[string]json.RawMessage
+			if err := json.Unmarshal(operation.Value, &values); err != nil {
+				return status.Errorf(status.InvalidArgument, "invalid patch value: %v", err)
+			}
+			for attribute, value := range values {
+				if err := setGroupAttribute(group, strings.ToLower(attribute), value, operation.Operation() == "add"); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return setGroupAttribute(group, path, operation.Value, operation.Operation() == "add")
+	case "remove":
+		if path == "members" {
+			if len(operation.Value) == 0 {
+				group.Members = nil
+				return nil
+			}
+			var members []types.Reference
+			if err := json.Unmarshal(operation.Value, &members); err != nil {
+				return status.Errorf(status.InvalidArgument, "invalid members value: %v", err)
+			}
+			group.Members = removeMembers(group.Members, memberIDs(members))
+			return nil
+		}
+
+		// e.g. members[value eq "user-id"]
+		if strings.HasPrefix(path, "members[") && strings.HasSuffix(path, "]") {
+			f, err := parseFilter(operation.Path[len("members[") : len(operation.Path)-1])
+			if err != nil {
+				return err
+			}
+			if f == nil || f.attribute != "value" {
+				return status.Errorf(status.InvalidArgument, "unsupported members filter: %s", operation.Path)
+			}
+			group.Members = removeMembers(group.Members, []string{f.value})
+			return nil
+		}
+
+		return status.Errorf(status.InvalidArgument, "unsupported group patch path: %s", operation.Path)
+	default:
+		return status.Errorf(status.InvalidArgument, "unsupported group patch operation: %s", operation.Op)
+	}
+}
+
+func setGroupAttribute(group *types.Group, attribute string, value json.RawMessage, add bool) error {
+	switch attribute {
+	case "displayname":
+		if err := json.Unmarshal(value, &group.DisplayName); err != nil {
+			return status.Errorf(status.InvalidArgument, "invalid displayName value: %v", err)
+		}
+	case "externalid":
+		// the group external ID is not stored
+	case "members":
+		var members []types.Reference
+		if err := json.Unmarshal(value, &members); err != nil {
+			return status.Errorf(status.InvalidArgument, "invalid members value: %v", err)
+		}
+		if add {
+			group.Members = append(group.Members, members...)
+		} else {
+			group.Members = members
+		}
+	default:
+		return status.Errorf(status.InvalidArgument, "unsupported group patch path: %s", attribute)
+	}
+
+	return nil
+}
+
+// parseBool parses a boolean value that can be sent as a string by some clients (e.g. "False" by Entra ID)
+func parseBool(value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return false, fmt.Errorf("not a boolean: %s", string(value))
+	}
+
+	return strconv.ParseBool(strings.ToLower(s))
+}
+
+func memberIDs(members []types.Reference) []string {
+	ids := make([]string, 0, len(members))
+	for _, member := range members {
+		if member.Value != "" && !slices.Contains(ids, member.Value) {
+			ids = append(ids, member.Value)
+		}
+	}
+	return ids
+}
+
+func removeMembers(members []types.Reference, ids []string) []types.Reference {
+	return slices.DeleteFunc(members, func(member types.Reference) bool {
+		return slices.Contains(ids, member.Value)
+	})
+}
+
+// difference returns the elements of a that are not in b
+func difference(a, b []string) []string {
+	var diff []string
+	for _, item := range a {
+		if !slices.Contains(b, item) {
+			diff = append(diff, item)
+		}
+	}
+	return diff
+}
+
+func NewManagerMock() Manager {
+	return &mockManager{}
+}
+
+func (m *mockManager) ListUsers(ctx context.Context, accountID, userID, filter string) ([]*types.User, error) {
+	return []*types.User{}, nil
+}
+
+func (m *mockManager) GetUser(ctx context.Context, accountID, userID, id string) (*types.User, error) {
+	return &types.User{}, nil
+}
+
+func (m *mockManager) CreateUser(ctx context.Context, accountID, userID string, user *types.User) (*types.User, error) {
+	return user, nil
+}
+
+func (m *mockManager) ReplaceUser(ctx context.Context, accountID, userID, id string, user *types.User) (*types.User, error) {
+	return user, nil
+}
+
+func (m *mockManager) PatchUser(ctx context.Context, accountID, userID, id string, operations []types.PatchOperation) (*types.User, error) {
+	return &types.User{}, nil
+}
+
+func (m *mockManager) DeleteUser(ctx context.Context, accountID, userID, id string) error {
+	return nil
+}
+
+func (m *mockManager) ListGroups(ctx context.Context, accountID, userID, filter string) ([]*types.Group, error) {
+	return []*types.Group{}, nil
+}
+
+func (m *mockManager) GetGroup(ctx context.Context, accountID, userID, id string) (*types.Group, error) {
+	return &types.Group{}, nil
+}
+
+func (m *mockManager) CreateGroup(ctx context.Context, accountID, userID string, group *types.Group) (*types.Group, error) {
+	return group, nil
+}
+
+func (m *mockManager) ReplaceGroup(ctx context.Context, accountID, userID, id string, group *types.Group) (*types.Group, error) {
+	return group, nil
+}
+
+func (m *mockManager) PatchGroup(ctx context.Context, accountID, userID, id string, operations []types.PatchOperation) (*types.Group, error) {
+	return &types.Group{}, nil
+}
+
+func (m *mockManager) DeleteGroup(ctx context.Context, accountID, userID, id string) error {
+	return nil
+}
@@ -0,0 +1,204 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/management/server/account"
+	nbcache "github.com/netbirdio/netbird/management/server/cache"
+	"github.com/netbirdio/netbird/management/server/mock_server"
+	"github.com/netbirdio/netbird/management/server/permissions"
+	"github.com/netbirdio/netbird/management/server/scim/types"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
+	nbtypes "github.com/netbirdio/netbird/management/server/types"
+)
+
+const (
+	testAccountID  = "bf1c8084-ba50-4ce7-9439-34653001fc3b"
+	testAdminID    = "edafee4e-63fb-11ec-90d6-0242ac120003"
+	testRegularID  = "f4f6d672-63fb-11ec-90d6-0242ac120003"
+	testGroupID    = "cfefqs706sqkneg59g2g"
+	testExternalID = "idp-user-id"
+)
+
+func newTestManager(t *testing.T) (Manager, store.Store) {
+	t.Helper()
+
+	s, cleanUp, err := store.NewTestStoreFromSQL(context.Background(), "../testdata/extended-store.sql", t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(cleanUp)
+
+	cacheStore, err := nbcache.NewStore(context.Background(), nbcache.DefaultIDPCacheExpirationMax, nbcache.DefaultIDPCacheCleanupInterval)
+	require.NoError(t, err)
+	externalCache := nbcache.NewUserDataCache(cacheStore)
+
+	am := &mock_server.MockAccountManager{
+		SaveOrAddUserFunc: func(ctx context.Context, accountID, userID string, user *nbtypes.User, addIfNotExists bool) (*nbtypes.UserInfo, error) {
+			return &nbtypes.UserInfo{ID: user.Id}, s.SaveUser(ctx, store.LockingStrengthUpdate, user)
+		},
+		SaveOrAddUsersFunc: func(ctx context.Context, accountID, userID string, users []*nbtypes.User, addIfNotExists bool) ([]*nbtypes.UserInfo, error) {
+			return nil, s.SaveUsers(ctx, store.LockingStrengthUpdate, users)
+		},
+		SaveGroupFunc: func(ctx context.Context, accountID, userID string, group *nbtypes.Group) error {
+			return s.SaveGroup(ctx, store.LockingStrengthUpdate, group)
+		},
+		DeleteGroupFunc: func(ctx context.Context, accountID, userID, groupID string) error {
+			return s.DeleteGroup(ctx, store.LockingStrengthUpdate, accountID, groupID)
+		},
+		DeleteUserFunc: func(ctx context.Context, accountID, initiatorUserID, targetUserID string) error {
+			return s.DeleteUser(ctx, store.LockingStrengthUpdate, accountID, targetUserID)
+		},
+		GetExternalCacheManagerFunc: func() account.ExternalCacheManager {
+			return externalCache
+		},
+	}
+
+	return NewManager(s, permissions.NewManager(s), am), s
+}
+
+func Test_CreateUserProvisionsUser(t *testing.T) {
+	ctx := context.Background()
+	manager, s := newTestManager(t)
+
+	user, err := manager.CreateUser(ctx, testAccountID, testAdminID, &types.User{
+		ExternalID: testExternalID,
+		UserName:   "john@example.com",
+		Name:       &types.Name{GivenName: "John", FamilyName: "Doe"},
+		Emails:     []types.Email{{Value: "john@example.com", Primary: true}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, testExternalID, user.ID)
+	assert.True(t, *user.Active)
+
+	nbUser, err := s.GetUserByUserID(ctx, store.LockingStrengthShare, testExternalID)
+	require.NoError(t, err)
+	assert.Equal(t, nbtypes.UserIssuedIntegration, nbUser.Issued)
+	assert.Equal(t, types.IntegrationType, nbUser.IntegrationReference.IntegrationType)
+
+	users, err := manager.ListUsers(ctx, testAccountID, testAdminID, `userName eq "JOHN@example.com"`)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, "john@example.com", users[0].UserName)
+
+	_, err = manager.CreateUser(ctx, testAccountID, testAdminID, &types.User{ExternalID: "other", UserName: "john@example.com"})
+	sErr, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, status.AlreadyExists, sErr.Type())
+}
+
+func Test_CreateUserRequiresExternalID(t *testing.T) {
+	manager, _ := newTestManager(t)
+
+	_, err := manager.CreateUser(context.Background(), testAccountID, testAdminID, &types.User{UserName: "john@example.com"})
+	sErr, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, status.InvalidArgument, sErr.Type())
+}
+
+func Test_CreateUserFailsWithPermissionDenied(t *testing.T) {
+	manager, _ := newTestManager(t)
+
+	_, err := manager.CreateUser(context.Background(), testAccountID, testRegularID, &types.User{ExternalID: testExternalID, UserName: "john@example.com"})
+	sErr, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, status.PermissionDenied, sErr.Type())
+}
+
+func Test_PatchUserDeactivatesUser(t *testing.T) {
+	ctx := context.Background()
+	manager, s := newTestManager(t)
+
+	_, err := manager.CreateUser(ctx, testAccountID, testAdminID, &types.User{ExternalID: testExternalID, UserName: "john@example.com"})
+	require.NoError(t, err)
+
+	// Entra ID sends booleans as strings
+	user, err := manager.PatchUser(ctx, testAccountID, testAdminID, testExternalID, []types.PatchOperation{
+		{Op: "Replace", Path: "active", Value: json.RawMessage(`"False"`)},
+	})
+	require.NoError(t, err)
+	assert.False(t, *user.Active)
+
+	nbUser, err := s.GetUserByUserID(ctx, store.LockingStrengthShare, testExternalID)
+	require.NoError(t, err)
+	assert.True(t, nbUser.Blocked)
+
+	user, err = manager.PatchUser(ctx, testAccountID, testAdminID, testExternalID, []types.PatchOperation{
+		{Op: "replace", Value: json.RawMessage(`{"active": true, "displayName": "John Doe"}`)},
+	})
+	require.NoError(t, err)
+	assert.True(t, *user.Active)
+	assert.Equal(t, "John Doe", user.DisplayName)
+}
+
+func Test_GroupMembership(t *testing.T) {
+	ctx := context.Background()
+	manager, s := newTestManager(t)
+
+	_, err := manager.CreateUser(ctx, testAccountID, testAdminID, &types.User{ExternalID: testExternalID, UserName: "john@example.com"})
+	require.NoError(t, err)
+
+	group, err := manager.CreateGroup(ctx, testAccountID, testAdminID, &types.Group{
+		DisplayName: "Engineering",
+		Members:     []types.Reference{{Value: testExternalID}},
+	})
+	require.NoError(t, err)
+	require.Len(t, group.Members, 1)
+
+	nbGroup, err := s.GetGroupByID(ctx, store.LockingStrengthShare, testAccountID, group.ID)
+	require.NoError(t, err)
+	assert.Equal(t, nbtypes.GroupIssuedIntegration, nbGroup.Issued)
+
+	nbUser, err := s.GetUserByUserID(ctx, store.LockingStrengthShare, testExternalID)
+	require.NoError(t, err)
+	assert.Contains(t, nbUser.AutoGroups, group.ID)
+
+	group, err = manager.PatchGroup(ctx, testAccountID, testAdminID, group.ID, []types.PatchOperation{
+		{Op: "remove", Path: `members[value eq "` + testExternalID + `"]`},
+		{Op: "add", Path: "members", Value: json.RawMessage(`[{"value": "` + testRegularID + `"}]`)},
+	})
+	require.NoError(t, err)
+	require.Len(t, group.Members, 1)
+	assert.Equal(t, testRegularID, group.Members[0].Value)
+
+	nbUser, err = s.GetUserByUserID(ctx, store.LockingStrengthShare, testExternalID)
+	require.NoError(t, err)
+	assert.NotContains(t, nbUser.AutoGroups, group.ID)
+
+	require.NoError(t, manager.DeleteGroup(ctx, testAccountID, testAdminID, group.ID))
+
+	nbUser, err = s.GetUserByUserID(ctx, store.LockingStrengthShare, testRegularID)
+	require.NoError(t, err)
+	assert.NotContains(t, nbUser.AutoGroups, group.ID)
+
+	_, err = manager.CreateGroup(ctx, testAccountID, testAdminID, &types.Group{DisplayName: "AwesomeGroup2"})
+	sErr, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, status.AlreadyExists, sErr.Type())
+
+	groups, err := manager.ListGroups(ctx, testAccountID, testAdminID, `displayName eq "AwesomeGroup2"`)
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	assert.Equal(t, testGroupID, groups[0].ID)
+}
+
+func Test_ParseFilter(t *testing.T) {
+	f, err := parseFilter(`userName eq "john@example.com"`)
+	require.NoError(t, err)
+	assert.Equal(t, "username", f.attribute)
+	assert.Equal(t, "john@example.com", f.value)
+
+	f, err = parseFilter("")
+	require.NoError(t, err)
+	assert.True(t, f.matches(map[string]string{}))
+
+	_, err = parseFilter(`userName co "john"`)
+	assert.Error(t, err)
+
+	_, err = parseFilter(`userName eq john`)
+	assert.Error(t, err)
+}
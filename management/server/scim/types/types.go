@@ -0,0 +1,189 @@
+package types
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+const (
+	UserSchema                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	GroupSchema                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	ListResponseSchema          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	PatchOpSchema               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	ErrorSchema                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+	ServiceProviderConfigSchema = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+
+	// IntegrationType is the integration type set on users and groups created by a SCIM client
+	IntegrationType = "scim"
+
+	UserResourceType  = "User"
+	GroupResourceType = "Group"
+)
+
+// ProvisionedUser holds the attributes of a user pushed by a SCIM client that are not part of the NetBird user
+type ProvisionedUser struct {
+	// ID is the ID of the NetBird user
+	ID          string `gorm:"primaryKey"`
+	AccountID   string `gorm:"index"`
+	ExternalID  string
+	UserName    string `gorm:"index"`
+	DisplayName string
+	GivenName   string
+	FamilyName  string
+	Email       string
+}
+
+// Copy returns a copy of the provisioned user
+func (p *ProvisionedUser) Copy() *ProvisionedUser {
+	c := *p
+	return &c
+}
+
+// User is the SCIM core user resource
+type User struct {
+	Schemas     []string    `json:"schemas"`
+	ID          string      `json:"id,omitempty"`
+	ExternalID  string      `json:"externalId,omitempty"`
+	UserName    string      `json:"userName"`
+	DisplayName string      `json:"displayName,omitempty"`
+	Name        *Name       `json:"name,omitempty"`
+	Emails      []Email     `json:"emails,omitempty"`
+	Active      *bool       `json:"active,omitempty"`
+	Groups      []Reference `json:"groups,omitempty"`
+	Meta        *Meta       `json:"meta,omitempty"`
+}
+
+// Name is the name of a SCIM user
+type Name struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+// Email is an email address of a SCIM user
+type Email struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// Reference points to another SCIM resource, e.g. a group member
+type Reference struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+}
+
+// Group is the SCIM core group resource
+type Group struct {
+	Schemas     []string    `json:"schemas"`
+	ID          string      `json:"id,omitempty"`
+	ExternalID  string      `json:"externalId,omitempty"`
+	DisplayName string      `json:"displayName"`
+	Members     []Reference `json:"members,omitempty"`
+	Meta        *Meta       `json:"meta,omitempty"`
+}
+
+// Meta holds the resource metadata
+type Meta struct {
+	ResourceType string `json:"resourceType"`
+	Location     string `json:"location,omitempty"`
+}
+
+// ListResponse is the response of a SCIM list or query request
+type ListResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    []any    `json:"Resources"`
+}
+
+// PatchRequest is the body of a SCIM PATCH request
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+// PatchOperation is a single SCIM PATCH operation
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Operation returns the lower-cased operation name, as some clients (e.g. Entra ID) send it capitalized
+func (o PatchOperation) Operation() string {
+	return strings.ToLower(o.Op)
+}
+
+// Error is the SCIM error response
+type Error struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+// PrimaryEmail returns the primary email of the user or the first one if none is marked as primary
+func (u *User) PrimaryEmail() string {
+	for _, email := range u.Emails {
+		if email.Primary {
+			return email.Value
+		}
+	}
+	if len(u.Emails) > 0 {
+		return u.Emails[0].Value
+	}
+	return ""
+}
+
+// IsActive returns false only if the user was explicitly deactivated
+func (u *User) IsActive() bool {
+	return u.Active == nil || *u.Active
+}
+
+// FromUser fills the provisioned user attributes from a SCIM user resource
+func (p *ProvisionedUser) FromUser(user *User) {
+	p.ExternalID = user.ExternalID
+	p.UserName = user.UserName
+	p.DisplayName = user.DisplayName
+	p.GivenName = ""
+	p.FamilyName = ""
+	if user.Name != nil {
+		p.GivenName = user.Name.GivenName
+		p.FamilyName = user.Name.FamilyName
+	}
+	p.Email = user.PrimaryEmail()
+}
+
+// FullName returns the name to be displayed for the provisioned user
+func (p *ProvisionedUser) FullName() string {
+	if p.DisplayName != "" {
+		return p.DisplayName
+	}
+	if name := strings.TrimSpace(p.GivenName + " " + p.FamilyName); name != "" {
+		return name
+	}
+	return p.UserName
+}
+
+// ToUser converts the provisioned user to a SCIM user resource
+func (p *ProvisionedUser) ToUser(active bool, groups []Reference) *User {
+	user := &User{
+		Schemas:     []string{UserSchema},
+		ID:          p.ID,
+		ExternalID:  p.ExternalID,
+		UserName:    p.UserName,
+		DisplayName: p.DisplayName,
+		Active:      &active,
+		Groups:      groups,
+		Meta:        &Meta{ResourceType: UserResourceType},
+	}
+	if p.GivenName != "" || p.FamilyName != "" {
+		user.Name = &Name{GivenName: p.GivenName, FamilyName: p.FamilyName}
+	}
+	if p.Email != "" {
+		user.Emails = []Email{{Value: p.Email, Type: "work", Primary: true}}
+	}
+	return user
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"context"
+	"reflect"
+
+	log "github.com/sirupsen/logrus"
+
+	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+	"github.com/netbirdio/netbird/management/server/store"
+	"github.com/netbirdio/netbird/management/server/types"
+)
+
+// getSessionSettingsResolver returns a function resolving the session settings of the peers of the account,
+// and whether any group of the account has a session policy.
+func getSessionSettingsResolver(ctx context.Context, transaction store.Store, accountID string) (func(peerID string) types.SessionSettings, bool, error) {
+	settings, err := transaction.GetAccountSettings(ctx, store.LockingStrengthShare, accountID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	groups, err := transaction.GetAccountGroups(ctx, store.LockingStrengthShare, accountID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	groupsMap := make(map[string]*types.Group, len(groups))
+	for _, group := range groups {
+		groupsMap[group.ID] = group
+	}
+	types.FlattenNestedGroups(groupsMap)
+
+	return types.NewSessionSettingsResolver(settings, groupsMap), types.HasSessionPolicies(groups), nil
+}
+
+// getPeerSessionSettings returns the session settings of the peer.
+// The peers added with a setup key don't expire, so their settings are not looked up.
+func getPeerSessionSettings(ctx context.Context, transaction store.Store, accountID string, peer *nbpeer.Peer) (types.SessionSettings, error) {
+	if !peer.AddedWithSSOLogin() {
+		return types.SessionSettings{}, nil
+	}
+
+	resolve, _, err := getSessionSettingsResolver(ctx, transaction, accountID)
+	if err != nil {
+		return types.SessionSettings{}, err
+	}
+
+	return resolve(peer.ID), nil
+}
+
+// peerExpirationApplies checks if the expiration checked by applies is enabled for the peer, logging the lookup failures.
+func (am *DefaultAccountManager) peerExpirationApplies(ctx context.Context, accountID string, peer *nbpeer.Peer, applies func(types.SessionSettings, *nbpeer.Peer) bool) bool {
+	sessionSettings, err := getPeerSessionSettings(ctx, am.Store, accountID, peer)
+	if err != nil {
+		log.WithContext(ctx).Errorf("failed to get session settings of peer %s: %v", peer.ID, err)
+		return false
+	}
+
+	return applies(sessionSettings, peer)
+}
+
+// getPeersWithExpiration returns the peers whose login can expire with their session settings resolver.
+// Without session policies, only the peers with the login expiration enabled have to be considered.
+func getPeersWithExpiration(ctx context.Context, transaction store.Store, accountID string) ([]*nbpeer.Peer, func(peerID string) types.SessionSettings, error) {
+	resolve, hasPolicies, err := getSessionSettingsResolver(ctx, transaction, accountID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !hasPolicies {
+		peers, err := transaction.GetAccountPeersWithExpiration(ctx, store.LockingStrengthShare, accountID)
+		return peers, resolve, err
+	}
+
+	peers, err := getAccountSSOPeers(ctx, transaction, accountID)
+	return peers, resolve, err
+}
+
+// getPeersWithInactivity returns the peers whose session can expire by inactivity with their session settings resolver.
+func getPeersWithInactivity(ctx context.Context, transaction store.Store, accountID string) ([]*nbpeer.Peer, func(peerID string) types.SessionSettings, error) {
+	resolve, hasPolicies, err := getSessionSettingsResolver(ctx, transaction, accountID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !hasPolicies {
+		peers, err := transaction.GetAccountPeersWithInactivity(ctx, store.LockingStrengthShare, accountID)
+		return peers, resolve, err
+	}
+
+	peers, err := getAccountSSOPeers(ctx, transaction, accountID)
+	return peers, resolve, err
+}
+
+func getAccountSSOPeers(ctx context.Context, transaction store.Store, accountID string) ([]*nbpeer.Peer, error) {
+	peers, err := transaction.GetAccountPeers(ctx, store.LockingStrengthShare, accountID, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	ssoPeers := make([]*nbpeer.Peer, 0, len(peers))
+	for _, peer := range peers {
+		if peer.AddedWithSSOLogin() {
+			ssoPeers = append(ssoPeers, peer)
+		}
+	}
+
+	return ssoPeers, nil
+}
+
+// isSessionPolicyChanged checks if the session policy of the group is different from the stored one.
+func isSessionPolicyChanged(ctx context.Context, transaction store.Store, accountID string, group *types.Group) bool {
+	oldGroup, err := transaction.GetGroupByID(ctx, store.LockingStrengthShare, accountID, group.ID)
+	if err != nil {
+		return group.SessionPolicy != nil
+	}
+
+	return !reflect.DeepEqual(oldGroup.SessionPolicy, group.SessionPolicy)
+}
@@ -2,16 +2,21 @@ package server
 
 import (
 	"context"
+	"reflect"
 	"slices"
 	"time"
 
+	"github.com/rs/xid"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/netbirdio/netbird/management/server/activity"
+	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+	"github.com/netbirdio/netbird/management/server/permissions"
 	"github.com/netbirdio/netbird/management/server/status"
 	"github.com/netbirdio/netbird/management/server/store"
 	"github.com/netbirdio/netbird/management/server/types"
 	"github.com/netbirdio/netbird/management/server/util"
+	"github.com/netbirdio/netbird/route"
 )
 
 const (
@@ -50,9 +55,10 @@ type SetupKeyUpdateOperation struct {
 }
 
 // CreateSetupKey generates a new setup key with a given name, type, list of groups IDs to auto-assign to peers registered with this key,
-// and adds it to the specified account. A list of autoGroups IDs can be empty.
+// provisioning settings, and adds it to the specified account. A list of autoGroups IDs can be empty.
 func (am *DefaultAccountManager) CreateSetupKey(ctx context.Context, accountID string, keyName string, keyType types.SetupKeyType,
-	expiresIn time.Duration, autoGroups []string, usageLimit int, userID string, ephemeral bool, allowExtraDNSLabels bool) (*types.SetupKey, error) {
+	expiresIn time.Duration, autoGroups []string, usageLimit int, userID string, ephemeral bool, allowExtraDNSLabels bool,
+	provisioning types.SetupKeyProvisioning) (*types.SetupKey, error) {
 	unlock := am.Store.AcquireWriteLockByUID(ctx, accountID)
 	defer unlock()
 
@@ -65,7 +71,7 @@ func (am *DefaultAccountManager) CreateSetupKey(ctx context.Context, accountID s
 		return nil, err
 	}
 
-	if user.IsRegularUser() {
+	if user.IsRegularUser() && !am.hasCustomRolePermission(ctx, accountID, user, permissions.SetupKeys, permissions.Write) {
 		return nil, status.NewAdminPermissionError()
 	}
 
@@ -78,8 +84,13 @@ func (am *DefaultAccountManager) CreateSetupKey(ctx context.Context, accountID s
 			return status.Errorf(status.InvalidArgument, "invalid auto groups: %v", err)
 		}
 
+		if err = validateSetupKeyProvisioning(ctx, transaction, accountID, provisioning); err != nil {
+			return err
+		}
+
 		setupKey, plainKey = types.GenerateSetupKey(keyName, keyType, expiresIn, autoGroups, usageLimit, ephemeral, allowExtraDNSLabels)
 		setupKey.AccountID = accountID
+		setupKey.SetupKeyProvisioning = provisioning.Copy()
 
 		events := am.prepareSetupKeyEvents(ctx, transaction, accountID, userID, autoGroups, nil, setupKey)
 		eventsToStore = append(eventsToStore, events...)
@@ -104,7 +115,8 @@ func (am *DefaultAccountManager) CreateSetupKey(ctx context.Context, accountID s
 // SaveSetupKey saves the provided SetupKey to the database overriding the existing one.
 // Due to the unique nature of a SetupKey certain properties must not be overwritten
 // (e.g. the key itself, creation date, ID, etc).
-// These properties are overwritten: AutoGroups, Revoked (only from false to true), and the UpdatedAt. The rest is copied from the existing key.
+// These properties are overwritten: AutoGroups, Revoked (only from false to true), the provisioning settings, and the UpdatedAt.
+// The rest is copied from the existing key.
 func (am *DefaultAccountManager) SaveSetupKey(ctx context.Context, accountID string, keyToSave *types.SetupKey, userID string) (*types.SetupKey, error) {
 	if keyToSave == nil {
 		return nil, status.Errorf(status.InvalidArgument, "provided setup key to update is nil")
@@ -122,7 +134,7 @@ func (am *DefaultAccountManager) SaveSetupKey(ctx context.Context, accountID str
 		return nil, err
 	}
 
-	if user.IsRegularUser() {
+	if user.IsRegularUser() && !am.hasCustomRolePermission(ctx, accountID, user, permissions.SetupKeys, permissions.Write) {
 		return nil, status.NewAdminPermissionError()
 	}
 
@@ -135,6 +147,10 @@ func (am *DefaultAccountManager) SaveSetupKey(ctx context.Context, accountID str
 			return status.Errorf(status.InvalidArgument, "invalid auto groups: %v", err)
 		}
 
+		if err = validateSetupKeyProvisioning(ctx, transaction, accountID, keyToSave.SetupKeyProvisioning); err != nil {
+			return err
+		}
+
 		oldKey, err = transaction.GetSetupKeyByID(ctx, store.LockingStrengthShare, accountID, keyToSave.Id)
 		if err != nil {
 			return err
@@ -144,10 +160,11 @@ func (am *DefaultAccountManager) SaveSetupKey(ctx context.Context, accountID str
 			return status.Errorf(status.InvalidArgument, "can't un-revoke a revoked setup key")
 		}
 
-		// only auto groups, revoked status (from false to true) can be updated
+		// only auto groups, revoked status (from false to true) and provisioning settings can be updated
 		newKey = oldKey.Copy()
 		newKey.AutoGroups = keyToSave.AutoGroups
 		newKey.Revoked = keyToSave.Revoked
+		newKey.SetupKeyProvisioning = keyToSave.SetupKeyProvisioning.Copy()
 		newKey.UpdatedAt = time.Now().UTC()
 
 		addedGroups := util.Difference(newKey.AutoGroups, oldKey.AutoGroups)
@@ -166,6 +183,10 @@ func (am *DefaultAccountManager) SaveSetupKey(ctx context.Context, accountID str
 		am.StoreEvent(ctx, userID, newKey.Id, accountID, activity.SetupKeyRevoked, newKey.EventMeta())
 	}
 
+	if !reflect.DeepEqual(oldKey.SetupKeyProvisioning, newKey.SetupKeyProvisioning) {
+		am.StoreEvent(ctx, userID, newKey.Id, accountID, activity.SetupKeyProvisioningUpdated, newKey.EventMeta())
+	}
+
 	for _, storeEvent := range eventsToStore {
 		storeEvent()
 	}
@@ -184,7 +205,7 @@ func (am *DefaultAccountManager) ListSetupKeys(ctx context.Context, accountID, u
 		return nil, err
 	}
 
-	if user.IsRegularUser() {
+	if user.IsRegularUser() && !am.hasCustomRolePermission(ctx, accountID, user, permissions.SetupKeys, permissions.Read) {
 		return nil, status.NewAdminPermissionError()
 	}
 
@@ -202,7 +223,7 @@ func (am *DefaultAccountManager) GetSetupKey(ctx context.Context, accountID, use
 		return nil, err
 	}
 
-	if user.IsRegularUser() {
+	if user.IsRegularUser() && !am.hasCustomRolePermission(ctx, accountID, user, permissions.SetupKeys, permissions.Read) {
 		return nil, status.NewAdminPermissionError()
 	}
 
@@ -230,7 +251,7 @@ func (am *DefaultAccountManager) DeleteSetupKey(ctx context.Context, accountID,
 		return err
 	}
 
-	if user.IsRegularUser() {
+	if user.IsRegularUser() && !am.hasCustomRolePermission(ctx, accountID, user, permissions.SetupKeys, permissions.Write) {
 		return status.NewAdminPermissionError()
 	}
 
@@ -273,6 +294,97 @@ func validateSetupKeyAutoGroups(ctx context.Context, transaction store.Store, ac
 	return nil
 }
 
+// validateSetupKeyProvisioning checks that the IP ranges are valid and the routes and posture checks exist in the account
+func validateSetupKeyProvisioning(ctx context.Context, transaction store.Store, accountID string, provisioning types.SetupKeyProvisioning) error {
+	if err := provisioning.Validate(); err != nil {
+		return status.Errorf(status.InvalidArgument, "invalid setup key provisioning: %v", err)
+	}
+
+	for _, routeID := range provisioning.AutoRoutes {
+		if _, err := transaction.GetRouteByID(ctx, store.LockingStrengthShare, routeID, accountID); err != nil {
+			return status.Errorf(status.InvalidArgument, "invalid auto routes: route %s not found", routeID)
+		}
+	}
+
+	postureChecks, err := transaction.GetPostureChecksByIDs(ctx, store.LockingStrengthShare, accountID, provisioning.AutoPostureChecks)
+	if err != nil {
+		return err
+	}
+
+	for _, postureChecksID := range provisioning.AutoPostureChecks {
+		if _, ok := postureChecks[postureChecksID]; !ok {
+			return status.Errorf(status.InvalidArgument, "invalid auto posture checks: posture checks %s not found", postureChecksID)
+		}
+	}
+
+	return nil
+}
+
+// validatePeerSetupKeyPostureChecks checks that the registering peer passes the posture checks of the setup key
+func validatePeerSetupKeyPostureChecks(ctx context.Context, transaction store.Store, accountID string, peer *nbpeer.Peer, postureChecksIDs []string) error {
+	if len(postureChecksIDs) == 0 {
+		return nil
+	}
+
+	postureChecks, err := transaction.GetPostureChecksByIDs(ctx, store.LockingStrengthShare, accountID, postureChecksIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, postureChecksID := range postureChecksIDs {
+		checks, ok := postureChecks[postureChecksID]
+		if !ok {
+			return status.Errorf(status.PreconditionFailed, "couldn't add peer: setup key posture checks %s not found", postureChecksID)
+		}
+
+		if !peerPassesPostureChecks(ctx, *peer, checks) {
+			return status.Errorf(status.PermissionDenied, "couldn't add peer: peer doesn't pass the setup key posture checks %s", checks.Name)
+		}
+	}
+
+	return nil
+}
+
+// addPeerToSetupKeyRoutes adds the registering peer as a routing peer of the setup key routes.
+// The peer joins the routing peer groups of the routes that use them, otherwise a high-availability copy of the route
+// is created for the peer. It returns the events to store once the transaction is committed.
+func (am *DefaultAccountManager) addPeerToSetupKeyRoutes(ctx context.Context, transaction store.Store, accountID, setupKeyID string,
+	peer *nbpeer.Peer, routeIDs []string) ([]func(), error) {
+	var eventsToStore []func()
+
+	for _, routeID := range routeIDs {
+		r, err := transaction.GetRouteByID(ctx, store.LockingStrengthShare, routeID, accountID)
+		if err != nil {
+			log.WithContext(ctx).Warnf("skipped adding peer %s to setup key route %s: %v", peer.ID, routeID, err)
+			continue
+		}
+
+		if len(r.PeerGroups) > 0 {
+			for _, groupID := range r.PeerGroups {
+				if err = transaction.AddPeerToGroup(ctx, store.LockingStrengthUpdate, accountID, peer.ID, groupID); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+
+		newRoute := r.Copy()
+		newRoute.ID = route.ID(xid.New().String())
+		newRoute.AccountID = accountID
+		newRoute.Peer = peer.ID
+
+		if err = transaction.SaveRoute(ctx, store.LockingStrengthUpdate, newRoute); err != nil {
+			return nil, err
+		}
+
+		eventsToStore = append(eventsToStore, func() {
+			am.StoreEvent(ctx, setupKeyID, string(newRoute.ID), accountID, activity.RouteCreated, newRoute.EventMeta())
+		})
+	}
+
+	return eventsToStore, nil
+}
+
 // prepareSetupKeyEvents prepares a list of event functions to be stored.
 func (am *DefaultAccountManager) prepareSetupKeyEvents(ctx context.Context, transaction store.Store, accountID, userID string, addedGroups, removedGroups []string, key *types.SetupKey) []func() {
 	var eventsToStore []func()
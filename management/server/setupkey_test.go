@@ -5,6 +5,7 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"fmt"
+	"net"
 	"strconv"
 	"strings"
 	"testing"
@@ -13,8 +14,10 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
 
 	"github.com/netbirdio/netbird/management/server/activity"
+	nbpeer "github.com/netbirdio/netbird/management/server/peer"
 	"github.com/netbirdio/netbird/management/server/types"
 )
 
@@ -50,7 +53,7 @@ func TestDefaultAccountManager_SaveSetupKey(t *testing.T) {
 	keyName := "my-test-key"
 
 	key, err := manager.CreateSetupKey(context.Background(), account.Id, keyName, types.SetupKeyReusable, expiresIn, []string{},
-		types.SetupKeyUnlimitedUsage, userID, false, false)
+		types.SetupKeyUnlimitedUsage, userID, false, false, types.SetupKeyProvisioning{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -168,7 +171,7 @@ func TestDefaultAccountManager_CreateSetupKey(t *testing.T) {
 	for _, tCase := range []testCase{testCase1, testCase2, testCase3} {
 		t.Run(tCase.name, func(t *testing.T) {
 			key, err := manager.CreateSetupKey(context.Background(), account.Id, tCase.expectedKeyName, types.SetupKeyReusable, expiresIn,
-				tCase.expectedGroups, types.SetupKeyUnlimitedUsage, userID, false, false)
+				tCase.expectedGroups, types.SetupKeyUnlimitedUsage, userID, false, false, types.SetupKeyProvisioning{})
 
 			if tCase.expectedFailure {
 				if err == nil {
@@ -210,7 +213,7 @@ func TestGetSetupKeys(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	plainKey, err := manager.CreateSetupKey(context.Background(), account.Id, "key1", types.SetupKeyReusable, time.Hour, nil, types.SetupKeyUnlimitedUsage, userID, false, false)
+	plainKey, err := manager.CreateSetupKey(context.Background(), account.Id, "key1", types.SetupKeyReusable, time.Hour, nil, types.SetupKeyUnlimitedUsage, userID, false, false, types.SetupKeyProvisioning{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -436,7 +439,7 @@ func TestSetupKeyAccountPeersUpdate(t *testing.T) {
 			close(done)
 		}()
 
-		setupKey, err = manager.CreateSetupKey(context.Background(), account.Id, "key1", types.SetupKeyReusable, time.Hour, nil, 999, userID, false, false)
+		setupKey, err = manager.CreateSetupKey(context.Background(), account.Id, "key1", types.SetupKeyReusable, time.Hour, nil, 999, userID, false, false, types.SetupKeyProvisioning{})
 		assert.NoError(t, err)
 
 		select {
@@ -477,7 +480,7 @@ func TestDefaultAccountManager_CreateSetupKey_ShouldNotAllowToUpdateRevokedKey(t
 		t.Fatal(err)
 	}
 
-	key, err := manager.CreateSetupKey(context.Background(), account.Id, "testName", types.SetupKeyReusable, time.Hour, nil, types.SetupKeyUnlimitedUsage, userID, false, false)
+	key, err := manager.CreateSetupKey(context.Background(), account.Id, "testName", types.SetupKeyReusable, time.Hour, nil, types.SetupKeyUnlimitedUsage, userID, false, false, types.SetupKeyProvisioning{})
 	assert.NoError(t, err)
 
 	// revoke the key
@@ -492,3 +495,55 @@ func TestDefaultAccountManager_CreateSetupKey_ShouldNotAllowToUpdateRevokedKey(t
 	assert.Error(t, err, "should not allow to update revoked key")
 
 }
+
+func TestSetupKeyProvisioning_IsIPAllowed(t *testing.T) {
+	provisioning := types.SetupKeyProvisioning{AllowedIPRanges: []string{"10.0.0.0/8", "2001:db8::/32"}}
+
+	assert.True(t, provisioning.IsIPAllowed(net.ParseIP("10.1.2.3")))
+	assert.True(t, provisioning.IsIPAllowed(net.ParseIP("2001:db8::1")))
+	assert.False(t, provisioning.IsIPAllowed(net.ParseIP("192.168.1.1")))
+	assert.False(t, provisioning.IsIPAllowed(nil))
+	assert.True(t, types.SetupKeyProvisioning{}.IsIPAllowed(nil), "keys without IP ranges should allow any source IP")
+}
+
+func TestDefaultAccountManager_AddPeerWithSetupKeyProvisioning(t *testing.T) {
+	manager, err := createManager(t)
+	require.NoError(t, err)
+
+	userID := "testingUser"
+	account, err := manager.GetOrCreateAccountByUser(context.Background(), userID, "")
+	require.NoError(t, err)
+
+	_, err = manager.CreateSetupKey(context.Background(), account.Id, "invalid", types.SetupKeyReusable, time.Hour, nil,
+		types.SetupKeyUnlimitedUsage, userID, false, false, types.SetupKeyProvisioning{AllowedIPRanges: []string{"10.0.0.0"}})
+	assert.Error(t, err, "should not allow invalid IP ranges")
+
+	_, err = manager.CreateSetupKey(context.Background(), account.Id, "invalid", types.SetupKeyReusable, time.Hour, nil,
+		types.SetupKeyUnlimitedUsage, userID, false, false, types.SetupKeyProvisioning{AutoRoutes: []string{"missing"}})
+	assert.Error(t, err, "should not allow unknown routes")
+
+	key, err := manager.CreateSetupKey(context.Background(), account.Id, "gateways", types.SetupKeyReusable, time.Hour, nil,
+		types.SetupKeyUnlimitedUsage, userID, false, false, types.SetupKeyProvisioning{
+			AllowedIPRanges: []string{"10.0.0.0/8"},
+			PeerNamePrefix:  "gw-",
+		})
+	require.NoError(t, err)
+
+	newPeer := func(ip string) *nbpeer.Peer {
+		peerKey, err := wgtypes.GeneratePrivateKey()
+		require.NoError(t, err)
+		return &nbpeer.Peer{
+			Key:      peerKey.PublicKey().String(),
+			Meta:     nbpeer.PeerSystemMeta{Hostname: "appliance"},
+			Location: nbpeer.Location{ConnectionIP: net.ParseIP(ip)},
+		}
+	}
+
+	_, _, _, err = manager.AddPeer(context.Background(), key.Key, "", newPeer("192.168.1.10"))
+	assert.Error(t, err, "should not allow registration from outside of the allowed IP ranges")
+
+	peer, _, _, err := manager.AddPeer(context.Background(), key.Key, "", newPeer("10.1.2.3"))
+	require.NoError(t, err)
+	assert.Equal(t, "gw-appliance", peer.Name)
+	assert.Equal(t, "gw-appliance", peer.DNSLabel)
+}
@@ -0,0 +1,199 @@
+package simulation
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/rs/xid"
+
+	"github.com/netbirdio/netbird/management/server/topology"
+	"github.com/netbirdio/netbird/management/server/types"
+	"github.com/netbirdio/netbird/route"
+)
+
+// PeerDiff is the change of the connections a peer can initiate caused by a policy change
+type PeerDiff struct {
+	PeerID   string
+	PeerName string
+	// AddedPeers are the peers the peer can connect to after the change only
+	AddedPeers []string
+	// RemovedPeers are the peers the peer can't connect to after the change anymore
+	RemovedPeers []string
+	// ChangedPeers are the peers the peer can connect to with different policy rules, e.g. other ports
+	ChangedPeers []string
+}
+
+// RouteDiff is the change of the routing peers a peer sends the traffic of a route through caused by a route change
+type RouteDiff struct {
+	PeerID   string
+	PeerName string
+	// AddedRoutingPeers are the routing peers the peer uses for the route after the change only
+	AddedRoutingPeers []string
+	// RemovedRoutingPeers are the routing peers the peer doesn't use for the route after the change anymore
+	RemovedRoutingPeers []string
+	// ChangedRoutingPeers are the routing peers the peer uses for other networks or domains of the route
+	ChangedRoutingPeers []string
+}
+
+type connectionKey struct {
+	source      string
+	destination string
+}
+
+// DiffPolicy returns the peers whose connections change if the policy is created, or updated if the account has a
+// policy with its ID. The account isn't changed, the groups of the account have to be flattened.
+func DiffPolicy(ctx context.Context, account *types.Account, validatedPeers map[string]struct{}, policy *types.Policy) []*PeerDiff {
+	before := connections(topology.Build(ctx, account, validatedPeers, ""))
+
+	changed := account.Copy()
+	policy = policy.Copy()
+	if policy.ID == "" {
+		policy.ID = xid.New().String()
+	}
+	for _, rule := range policy.Rules {
+		if rule.ID == "" {
+			rule.ID = xid.New().String()
+		}
+		rule.PolicyID = policy.ID
+	}
+
+	replaced := false
+	for i, existing := range changed.Policies {
+		if existing.ID == policy.ID {
+			changed.Policies[i] = policy
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		changed.Policies = append(changed.Policies, policy)
+	}
+
+	after := connections(topology.Build(ctx, changed, validatedPeers, ""))
+
+	diffs := make(map[string]*PeerDiff)
+	peerDiff := func(peerID string) *PeerDiff {
+		diff, ok := diffs[peerID]
+		if !ok {
+			diff = &PeerDiff{PeerID: peerID}
+			if peer := account.GetPeer(peerID); peer != nil {
+				diff.PeerName = peer.Name
+			}
+			diffs[peerID] = diff
+		}
+		return diff
+	}
+
+	for key, policies := range after {
+		previous, ok := before[key]
+		switch {
+		case !ok:
+			diff := peerDiff(key.source)
+			diff.AddedPeers = append(diff.AddedPeers, key.destination)
+		case !reflect.DeepEqual(previous, policies):
+			diff := peerDiff(key.source)
+			diff.ChangedPeers = append(diff.ChangedPeers, key.destination)
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			diff := peerDiff(key.source)
+			diff.RemovedPeers = append(diff.RemovedPeers, key.destination)
+		}
+	}
+
+	result := make([]*PeerDiff, 0, len(diffs))
+	for _, diff := range diffs {
+		sort.Strings(diff.AddedPeers)
+		sort.Strings(diff.RemovedPeers)
+		sort.Strings(diff.ChangedPeers)
+		result = append(result, diff)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].PeerID < result[j].PeerID })
+
+	return result
+}
+
+// connections returns the policy rules of the connections of the topology. The rules are compared by their effect,
+// so that a new policy with the same rules as an existing one isn't reported as a change.
+func connections(t *topology.Topology) map[connectionKey]map[string]struct{} {
+	result := make(map[connectionKey]map[string]struct{}, len(t.Connections))
+	for _, connection := range t.Connections {
+		rules := make(map[string]struct{}, len(connection.Policies))
+		for _, policy := range connection.Policies {
+			rules[policy.Action+"/"+policy.Protocol+"/"+strings.Join(policy.Ports, ",")] = struct{}{}
+		}
+		result[connectionKey{source: connection.SourcePeerID, destination: connection.DestinationPeerID}] = rules
+	}
+	return result
+}
+
+// DiffRoute returns the peers whose routes change if the route is saved. The account isn't changed, the groups of the
+// account have to be flattened.
+func DiffRoute(ctx context.Context, account *types.Account, validatedPeers map[string]struct{}, r *route.Route) []*RouteDiff {
+	before := routes(topology.Build(ctx, account, validatedPeers, ""), string(r.ID))
+
+	changed := account.Copy()
+	if changed.Routes == nil {
+		changed.Routes = make(map[route.ID]*route.Route)
+	}
+	changed.Routes[r.ID] = r.Copy()
+
+	after := routes(topology.Build(ctx, changed, validatedPeers, ""), string(r.ID))
+
+	diffs := make(map[string]*RouteDiff)
+	routeDiff := func(peerID string) *RouteDiff {
+		diff, ok := diffs[peerID]
+		if !ok {
+			diff = &RouteDiff{PeerID: peerID}
+			if peer := account.GetPeer(peerID); peer != nil {
+				diff.PeerName = peer.Name
+			}
+			diffs[peerID] = diff
+		}
+		return diff
+	}
+
+	for key, destination := range after {
+		previous, ok := before[key]
+		switch {
+		case !ok:
+			diff := routeDiff(key.source)
+			diff.AddedRoutingPeers = append(diff.AddedRoutingPeers, key.destination)
+		case previous != destination:
+			diff := routeDiff(key.source)
+			diff.ChangedRoutingPeers = append(diff.ChangedRoutingPeers, key.destination)
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			diff := routeDiff(key.source)
+			diff.RemovedRoutingPeers = append(diff.RemovedRoutingPeers, key.destination)
+		}
+	}
+
+	result := make([]*RouteDiff, 0, len(diffs))
+	for _, diff := range diffs {
+		sort.Strings(diff.AddedRoutingPeers)
+		sort.Strings(diff.RemovedRoutingPeers)
+		sort.Strings(diff.ChangedRoutingPeers)
+		result = append(result, diff)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].PeerID < result[j].PeerID })
+
+	return result
+}
+
+// routes returns the networks or domains of the route by the peer and the routing peer the peer uses for it
+func routes(t *topology.Topology, routeID string) map[connectionKey]string {
+	result := make(map[connectionKey]string)
+	for _, r := range t.Routes {
+		if r.RouteID != routeID {
+			continue
+		}
+		result[connectionKey{source: r.PeerID, destination: r.RoutingPeerID}] = r.Network + strings.Join(r.Domains, ",")
+	}
+	return result
+}
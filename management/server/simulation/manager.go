@@ -0,0 +1,178 @@
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/netbirdio/netbird/management/server/account"
+	"github.com/netbirdio/netbird/management/server/permissions"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
+	"github.com/netbirdio/netbird/management/server/types"
+	"github.com/netbirdio/netbird/route"
+)
+
+type Manager interface {
+	// Simulate evaluates the policies of the account for the query without changing them
+	Simulate(ctx context.Context, accountID, userID string, query *Query) (*Result, error)
+	// DryRunPolicy returns the peers whose connections would change if the policy was saved
+	DryRunPolicy(ctx context.Context, accountID, userID string, policy *types.Policy) ([]*PeerDiff, error)
+	// DryRunRoute returns the peers whose routes would change if the route was saved
+	DryRunRoute(ctx context.Context, accountID, userID string, r *route.Route) ([]*RouteDiff, error)
+}
+
+type managerImpl struct {
+	store              store.Store
+	permissionsManager permissions.Manager
+	accountManager     account.Manager
+}
+
+type mockManager struct {
+}
+
+func NewManager(store store.Store, permissionsManager permissions.Manager, accountManager account.Manager) Manager {
+	return &managerImpl{
+		store:              store,
+		permissionsManager: permissionsManager,
+		accountManager:     accountManager,
+	}
+}
+
+func (m *managerImpl) Simulate(ctx context.Context, accountID, userID string, query *Query) (*Result, error) {
+	if err := validateQuery(query); err != nil {
+		return nil, err
+	}
+
+	account, validatedPeers, err := m.getAccount(ctx, accountID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if account.GetPeer(query.SourcePeerID) == nil {
+		return nil, status.NewPeerNotFoundError(query.SourcePeerID)
+	}
+	if query.DestinationPeerID != "" && account.GetPeer(query.DestinationPeerID) == nil {
+		return nil, status.NewPeerNotFoundError(query.DestinationPeerID)
+	}
+
+	return Evaluate(ctx, account, validatedPeers, query), nil
+}
+
+func (m *managerImpl) DryRunPolicy(ctx context.Context, accountID, userID string, policy *types.Policy) ([]*PeerDiff, error) {
+	account, validatedPeers, err := m.getAccount(ctx, accountID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if policy.ID != "" && !slices.ContainsFunc(account.Policies, func(p *types.Policy) bool { return p.ID == policy.ID }) {
+		return nil, status.NewPolicyNotFoundError(policy.ID)
+	}
+
+	for _, rule := range policy.Rules {
+		for _, groupID := range slices.Concat(rule.Sources, rule.Destinations) {
+			if _, ok := account.Groups[groupID]; !ok {
+				return nil, status.Errorf(status.InvalidArgument, "group %s not found", groupID)
+			}
+		}
+	}
+	for _, checkID := range policy.SourcePostureChecks {
+		if account.GetPostureChecks(checkID) == nil {
+			return nil, status.Errorf(status.InvalidArgument, "posture checks %s not found", checkID)
+		}
+	}
+
+	return DiffPolicy(ctx, account, validatedPeers, policy), nil
+}
+
+func (m *managerImpl) DryRunRoute(ctx context.Context, accountID, userID string, r *route.Route) ([]*RouteDiff, error) {
+	ok, err := m.permissionsManager.ValidateUserPermissions(ctx, accountID, userID, permissions.Routes, permissions.Read)
+	if err != nil {
+		return nil, status.NewPermissionValidationError(err)
+	}
+	if !ok {
+		return nil, status.NewPermissionDeniedError()
+	}
+
+	account, validatedPeers, err := m.getAccount(ctx, accountID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := account.Routes[r.ID]; !ok {
+		return nil, status.Errorf(status.NotFound, "route with ID %s doesn't exist", r.ID)
+	}
+	if r.Peer != "" && account.GetPeer(r.Peer) == nil {
+		return nil, status.NewPeerNotFoundError(r.Peer)
+	}
+	for _, groupID := range slices.Concat(r.PeerGroups, r.Groups, r.AccessControlGroups) {
+		if _, ok := account.Groups[groupID]; !ok {
+			return nil, status.Errorf(status.InvalidArgument, "group %s not found", groupID)
+		}
+	}
+
+	return DiffRoute(ctx, account, validatedPeers, r), nil
+}
+
+// getAccount checks the user can read the policies and returns the account with flattened groups and its
+// validated peers
+func (m *managerImpl) getAccount(ctx context.Context, accountID, userID string) (*types.Account, map[string]struct{}, error) {
+	ok, err := m.permissionsManager.ValidateUserPermissions(ctx, accountID, userID, permissions.Policies, permissions.Read)
+	if err != nil {
+		return nil, nil, status.NewPermissionValidationError(err)
+	}
+	if !ok {
+		return nil, nil, status.NewPermissionDeniedError()
+	}
+
+	account, err := m.store.GetAccount(ctx, accountID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	validatedPeers, err := m.accountManager.GetValidatedPeers(ctx, accountID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get validated peers: %w", err)
+	}
+
+	types.FlattenNestedGroups(account.Groups)
+
+	return account, validatedPeers, nil
+}
+
+func validateQuery(query *Query) error {
+	if query.SourcePeerID == "" {
+		return status.Errorf(status.InvalidArgument, "source peer has to be set")
+	}
+	if (query.DestinationPeerID == "") == !query.DestinationAddress.IsValid() {
+		return status.Errorf(status.InvalidArgument, "either a destination peer or a destination address has to be set")
+	}
+
+	switch types.PolicyRuleProtocolType(query.Protocol) {
+	case types.PolicyRuleProtocolTCP, types.PolicyRuleProtocolUDP:
+		if query.Port == 0 {
+			return status.Errorf(status.InvalidArgument, "%s query has to define a port", query.Protocol)
+		}
+	case types.PolicyRuleProtocolICMP:
+	default:
+		return status.Errorf(status.InvalidArgument, "protocol has to be tcp, udp or icmp")
+	}
+
+	return nil
+}
+
+func NewManagerMock() Manager {
+	return &mockManager{}
+}
+
+func (m *mockManager) Simulate(ctx context.Context, accountID, userID string, query *Query) (*Result, error) {
+	return &Result{}, nil
+}
+
+func (m *mockManager) DryRunPolicy(ctx context.Context, accountID, userID string, policy *types.Policy) ([]*PeerDiff, error) {
+	return []*PeerDiff{}, nil
+}
+
+func (m *mockManager) DryRunRoute(ctx context.Context, accountID, userID string, r *route.Route) ([]*RouteDiff, error) {
+	return []*RouteDiff{}, nil
+}
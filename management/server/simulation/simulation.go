@@ -0,0 +1,269 @@
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strconv"
+
+	nbdns "github.com/netbirdio/netbird/dns"
+	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+	"github.com/netbirdio/netbird/management/server/types"
+)
+
+// Query asks whether the source peer can reach the destination peer or address with the protocol and port
+type Query struct {
+	SourcePeerID string
+	// DestinationPeerID and DestinationAddress are mutually exclusive, the address is reached through a route or a
+	// network resource unless it is the address of a peer
+	DestinationPeerID  string
+	DestinationAddress netip.Addr
+	// Protocol is tcp, udp or icmp
+	Protocol string
+	// Port is required by the tcp and udp queries
+	Port uint16
+}
+
+// Result of a query with the policy rules matching the traffic
+type Result struct {
+	Allowed bool
+	Reason  string
+	Rules   []*MatchedRule
+}
+
+// MatchedRule is a policy rule matching the traffic of a query
+type MatchedRule struct {
+	PolicyID string
+	Name     string
+	Action   string
+	// RoutingPeerID is the routing peer enforcing the rule of a routed destination
+	RoutingPeerID string
+}
+
+type evaluator struct {
+	ctx            context.Context
+	account        *types.Account
+	validatedPeers map[string]struct{}
+	policyNames    map[string]string
+	// rulePolicies maps the policy rules to their policies, the firewall rules of the peers reference the policy rules
+	rulePolicies map[string]string
+}
+
+// Evaluate answers the query with the firewall rules of the network maps generated for the peers, the groups of the
+// account have to be flattened
+func Evaluate(ctx context.Context, account *types.Account, validatedPeers map[string]struct{}, query *Query) *Result {
+	e := &evaluator{
+		ctx:            ctx,
+		account:        account,
+		validatedPeers: validatedPeers,
+		policyNames:    make(map[string]string, len(account.Policies)),
+		rulePolicies:   make(map[string]string),
+	}
+	for _, policy := range account.Policies {
+		e.policyNames[policy.ID] = policy.Name
+		for _, rule := range policy.Rules {
+			e.rulePolicies[rule.ID] = policy.ID
+		}
+	}
+
+	source := account.GetPeer(query.SourcePeerID)
+	if _, ok := validatedPeers[source.ID]; !ok {
+		return &Result{Reason: "source peer is pending approval"}
+	}
+
+	destination := account.GetPeer(query.DestinationPeerID)
+	if destination == nil {
+		destination = e.peerByAddress(query.DestinationAddress)
+	}
+	if destination != nil {
+		return e.evaluatePeer(source, destination, query)
+	}
+
+	return e.evaluateRoutedAddress(source, query)
+}
+
+func (e *evaluator) evaluatePeer(source, destination *nbpeer.Peer, query *Query) *Result {
+	if source.ID == destination.ID {
+		return &Result{Reason: "source and destination are the same peer"}
+	}
+	if _, ok := e.validatedPeers[destination.ID]; !ok {
+		return &Result{Reason: "destination peer is pending approval"}
+	}
+
+	networkMap := e.networkMap(source.ID)
+	if !slices.ContainsFunc(networkMap.Peers, func(p *nbpeer.Peer) bool { return p.ID == destination.ID }) {
+		if slices.ContainsFunc(networkMap.OfflinePeers, func(p *nbpeer.Peer) bool { return p.ID == destination.ID }) {
+			return &Result{Reason: "login of the destination peer expired"}
+		}
+		return &Result{Reason: "no policy connects the source and destination peers"}
+	}
+
+	destinationIPs := []string{destination.IP.String(), "0.0.0.0"}
+
+	var rules []*MatchedRule
+	for _, rule := range networkMap.FirewallRules {
+		if rule.Direction != types.FirewallRuleDirectionOUT || !slices.Contains(destinationIPs, rule.PeerIP) {
+			continue
+		}
+		if !protocolMatches(rule.Protocol, query.Protocol) || !portMatches(rule.Port, rule.PortRange, query) {
+			continue
+		}
+		policyID := rule.PolicyID
+		if id, ok := e.rulePolicies[rule.PolicyID]; ok {
+			policyID = id
+		}
+		rules = e.addRule(rules, policyID, rule.Action, "")
+	}
+
+	return decide(rules, "no policy rule allows the protocol and port")
+}
+
+func (e *evaluator) evaluateRoutedAddress(source *nbpeer.Peer, query *Query) *Result {
+	networkMap := e.networkMap(source.ID)
+
+	// the most specific routes to the address are used by the peer
+	bits := -1
+	var routingPeers []*nbpeer.Peer
+	for _, r := range networkMap.Routes {
+		if r.IsDynamic() || !r.Network.Contains(query.DestinationAddress) || r.Network.Bits() < bits {
+			continue
+		}
+		routingPeer := e.peerByKey(r.Peer)
+		if routingPeer == nil || routingPeer.ID == source.ID {
+			continue
+		}
+		if r.Network.Bits() > bits {
+			bits = r.Network.Bits()
+			routingPeers = nil
+		}
+		if !slices.Contains(routingPeers, routingPeer) {
+			routingPeers = append(routingPeers, routingPeer)
+		}
+	}
+	if len(routingPeers) == 0 {
+		return &Result{Reason: "no route or network resource to the destination address"}
+	}
+
+	sourceIP, _ := netip.AddrFromSlice(source.IP.To4())
+
+	var rules []*MatchedRule
+	for _, routingPeer := range routingPeers {
+		for _, rule := range e.networkMap(routingPeer.ID).RoutesFirewallRules {
+			destination, err := netip.ParsePrefix(rule.Destination)
+			if err != nil || rule.IsDynamic || !destination.Contains(query.DestinationAddress) {
+				continue
+			}
+			if !sourceMatches(rule.SourceRanges, sourceIP) {
+				continue
+			}
+			if !protocolMatches(rule.Protocol, query.Protocol) || !portMatches(portString(rule.Port), rule.PortRange, query) {
+				continue
+			}
+			rules = e.addRule(rules, rule.PolicyID, rule.Action, routingPeer.ID)
+		}
+	}
+
+	return decide(rules, "no policy rule of the routing peers allows the source peer, protocol and port")
+}
+
+func (e *evaluator) networkMap(peerID string) *types.NetworkMap {
+	return e.account.GetPeerNetworkMap(e.ctx, peerID, nbdns.CustomZone{}, e.validatedPeers, e.account.GetResourcePoliciesMap(), e.account.GetResourceRoutersMap(), nil)
+}
+
+func (e *evaluator) addRule(rules []*MatchedRule, policyID, action, routingPeerID string) []*MatchedRule {
+	for _, rule := range rules {
+		if rule.PolicyID == policyID && rule.Action == action && rule.RoutingPeerID == routingPeerID {
+			return rules
+		}
+	}
+
+	name := e.policyNames[policyID]
+	if policyID == "" {
+		name = "route without access control groups"
+	}
+
+	return append(rules, &MatchedRule{PolicyID: policyID, Name: name, Action: action, RoutingPeerID: routingPeerID})
+}
+
+func (e *evaluator) peerByAddress(address netip.Addr) *nbpeer.Peer {
+	if !address.IsValid() {
+		return nil
+	}
+	for _, peer := range e.account.Peers {
+		ip, ok := netip.AddrFromSlice(peer.IP)
+		if ok && ip.Unmap() == address.Unmap() {
+			return peer
+		}
+	}
+	return nil
+}
+
+func (e *evaluator) peerByKey(key string) *nbpeer.Peer {
+	for _, peer := range e.account.Peers {
+		if peer.Key == key {
+			return peer
+		}
+	}
+	return nil
+}
+
+// decide allows the traffic if a rule accepts it and no rule drops it
+func decide(rules []*MatchedRule, noMatchReason string) *Result {
+	if len(rules) == 0 {
+		return &Result{Reason: noMatchReason}
+	}
+
+	result := &Result{Rules: rules}
+	for _, rule := range rules {
+		if rule.Action == string(types.PolicyTrafficActionDrop) {
+			result.Reason = fmt.Sprintf("denied by policy %s", policyLabel(rule))
+			return result
+		}
+	}
+
+	result.Allowed = true
+	result.Reason = fmt.Sprintf("allowed by policy %s", policyLabel(rules[0]))
+	return result
+}
+
+func policyLabel(rule *MatchedRule) string {
+	if rule.Name != "" {
+		return rule.Name
+	}
+	return rule.PolicyID
+}
+
+func protocolMatches(ruleProtocol, protocol string) bool {
+	return ruleProtocol == string(types.PolicyRuleProtocolALL) || ruleProtocol == protocol
+}
+
+func portMatches(port string, portRange types.RulePortRange, query *Query) bool {
+	if query.Protocol == string(types.PolicyRuleProtocolICMP) {
+		return true
+	}
+	if port == "" && portRange.Start == 0 {
+		return true
+	}
+	if port != "" {
+		return port == strconv.Itoa(int(query.Port))
+	}
+	return query.Port >= portRange.Start && query.Port <= portRange.End
+}
+
+func portString(port uint16) string {
+	if port == 0 {
+		return ""
+	}
+	return strconv.Itoa(int(port))
+}
+
+func sourceMatches(sourceRanges []string, source netip.Addr) bool {
+	for _, sourceRange := range sourceRanges {
+		prefix, err := netip.ParsePrefix(sourceRange)
+		if err == nil && prefix.Contains(source) {
+			return true
+		}
+	}
+	return false
+}
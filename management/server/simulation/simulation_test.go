@@ -0,0 +1,208 @@
+package simulation
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+	"github.com/netbirdio/netbird/management/server/types"
+	"github.com/netbirdio/netbird/route"
+)
+
+func newTestAccount() *types.Account {
+	newPeer := func(id, ip string) *nbpeer.Peer {
+		return &nbpeer.Peer{
+			ID:        id,
+			AccountID: "accountID",
+			Key:       id + "Key",
+			Name:      id,
+			IP:        net.ParseIP(ip),
+			Status:    &nbpeer.PeerStatus{Connected: true},
+		}
+	}
+
+	return &types.Account{
+		Id: "accountID",
+		Peers: map[string]*nbpeer.Peer{
+			"client": newPeer("client", "100.64.0.1"),
+			"server": newPeer("server", "100.64.0.2"),
+			"router": newPeer("router", "100.64.0.3"),
+		},
+		Network:  &types.Network{Identifier: "net", Net: net.IPNet{IP: net.ParseIP("100.64.0.0"), Mask: net.CIDRMask(10, 32)}},
+		Settings: &types.Settings{},
+		Groups: map[string]*types.Group{
+			"clients": {ID: "clients", Name: "clients", Peers: []string{"client"}},
+			"servers": {ID: "servers", Name: "servers", Peers: []string{"server"}},
+			"routers": {ID: "routers", Name: "routers", Peers: []string{"router"}},
+		},
+		Policies: []*types.Policy{
+			{
+				ID:      "ssh",
+				Name:    "SSH to servers",
+				Enabled: true,
+				Rules: []*types.PolicyRule{{
+					ID:           "sshRule",
+					PolicyID:     "ssh",
+					Enabled:      true,
+					Action:       types.PolicyTrafficActionAccept,
+					Protocol:     types.PolicyRuleProtocolTCP,
+					Ports:        []string{"22"},
+					Sources:      []string{"clients"},
+					Destinations: []string{"servers"},
+				}},
+			},
+			{
+				ID:      "routing",
+				Name:    "Clients to routers",
+				Enabled: true,
+				Rules: []*types.PolicyRule{{
+					ID:            "routingRule",
+					PolicyID:      "routing",
+					Enabled:       true,
+					Action:        types.PolicyTrafficActionAccept,
+					Protocol:      types.PolicyRuleProtocolALL,
+					Bidirectional: true,
+					Sources:       []string{"clients"},
+					Destinations:  []string{"routers"},
+				}},
+			},
+		},
+		Routes: map[route.ID]*route.Route{
+			"exit": {
+				ID:          "exit",
+				NetID:       "exit",
+				Network:     netip.MustParsePrefix("0.0.0.0/0"),
+				NetworkType: route.IPv4Network,
+				PeerGroups:  []string{"routers"},
+				Groups:      []string{"clients"},
+				Enabled:     true,
+			},
+		},
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	account := newTestAccount()
+	validatedPeers := map[string]struct{}{"client": {}, "server": {}, "router": {}}
+
+	tests := []struct {
+		name    string
+		query   *Query
+		allowed bool
+		policy  string
+	}{
+		{
+			name:    "allowed port",
+			query:   &Query{SourcePeerID: "client", DestinationPeerID: "server", Protocol: "tcp", Port: 22},
+			allowed: true,
+			policy:  "ssh",
+		},
+		{
+			name:  "other port",
+			query: &Query{SourcePeerID: "client", DestinationPeerID: "server", Protocol: "tcp", Port: 80},
+		},
+		{
+			name:  "unidirectional policy",
+			query: &Query{SourcePeerID: "server", DestinationPeerID: "client", Protocol: "tcp", Port: 22},
+		},
+		{
+			name:    "peer address",
+			query:   &Query{SourcePeerID: "client", DestinationAddress: netip.MustParseAddr("100.64.0.2"), Protocol: "tcp", Port: 22},
+			allowed: true,
+			policy:  "ssh",
+		},
+		{
+			name:    "routed address",
+			query:   &Query{SourcePeerID: "client", DestinationAddress: netip.MustParseAddr("1.1.1.1"), Protocol: "icmp"},
+			allowed: true,
+		},
+		{
+			name:  "no route",
+			query: &Query{SourcePeerID: "server", DestinationAddress: netip.MustParseAddr("1.1.1.1"), Protocol: "icmp"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result := Evaluate(context.Background(), account, validatedPeers, tc.query)
+			assert.Equal(t, tc.allowed, result.Allowed, result.Reason)
+			if tc.policy != "" {
+				require.Len(t, result.Rules, 1)
+				assert.Equal(t, tc.policy, result.Rules[0].PolicyID)
+			}
+		})
+	}
+
+	result := Evaluate(context.Background(), account, validatedPeers, &Query{SourcePeerID: "client", DestinationAddress: netip.MustParseAddr("1.1.1.1"), Protocol: "icmp"})
+	require.Len(t, result.Rules, 1)
+	assert.Equal(t, "router", result.Rules[0].RoutingPeerID)
+}
+
+func TestDiffPolicy(t *testing.T) {
+	account := newTestAccount()
+	validatedPeers := map[string]struct{}{"client": {}, "server": {}, "router": {}}
+
+	policy := account.Policies[0].Copy()
+	policy.Rules[0].Ports = []string{"22", "443"}
+
+	diffs := DiffPolicy(context.Background(), account, validatedPeers, policy)
+	require.Len(t, diffs, 1)
+	assert.Equal(t, &PeerDiff{PeerID: "client", PeerName: "client", ChangedPeers: []string{"server"}}, diffs[0])
+	assert.Equal(t, []string{"22"}, account.Policies[0].Rules[0].Ports, "account shouldn't be changed")
+
+	policy = &types.Policy{
+		Name:    "Servers to routers",
+		Enabled: true,
+		Rules: []*types.PolicyRule{{
+			Enabled:      true,
+			Action:       types.PolicyTrafficActionAccept,
+			Protocol:     types.PolicyRuleProtocolALL,
+			Sources:      []string{"servers"},
+			Destinations: []string{"routers"},
+		}},
+	}
+
+	diffs = DiffPolicy(context.Background(), account, validatedPeers, policy)
+	require.Len(t, diffs, 1)
+	assert.Equal(t, &PeerDiff{PeerID: "server", PeerName: "server", AddedPeers: []string{"router"}}, diffs[0])
+	assert.Len(t, account.Policies, 2)
+
+	disabled := account.Policies[0].Copy()
+	disabled.Enabled = false
+
+	diffs = DiffPolicy(context.Background(), account, validatedPeers, disabled)
+	require.Len(t, diffs, 1)
+	assert.Equal(t, []string{"server"}, diffs[0].RemovedPeers)
+}
+
+func TestDiffRoute(t *testing.T) {
+	account := newTestAccount()
+	validatedPeers := map[string]struct{}{"client": {}, "server": {}, "router": {}}
+
+	moved := account.Routes["exit"].Copy()
+	moved.PeerGroups = []string{"servers"}
+
+	diffs := DiffRoute(context.Background(), account, validatedPeers, moved)
+	require.Len(t, diffs, 1)
+	assert.Equal(t, &RouteDiff{PeerID: "client", PeerName: "client", AddedRoutingPeers: []string{"server"}, RemovedRoutingPeers: []string{"router"}}, diffs[0])
+	assert.Equal(t, []string{"routers"}, account.Routes["exit"].PeerGroups, "account shouldn't be changed")
+
+	narrowed := account.Routes["exit"].Copy()
+	narrowed.Network = netip.MustParsePrefix("10.0.0.0/8")
+
+	diffs = DiffRoute(context.Background(), account, validatedPeers, narrowed)
+	require.Len(t, diffs, 1)
+	assert.Equal(t, &RouteDiff{PeerID: "client", PeerName: "client", ChangedRoutingPeers: []string{"router"}}, diffs[0])
+
+	disabled := account.Routes["exit"].Copy()
+	disabled.Enabled = false
+
+	diffs = DiffRoute(context.Background(), account, validatedPeers, disabled)
+	require.Len(t, diffs, 1)
+	assert.Equal(t, []string{"router"}, diffs[0].RemovedRoutingPeers)
+}
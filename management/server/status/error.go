@@ -3,6 +3,8 @@ package status
 import (
 	"errors"
 	"fmt"
+
+	"github.com/netbirdio/netbird/util/errcode"
 )
 
 const (
@@ -46,6 +48,8 @@ var ErrExtraSettingsNotFound = fmt.Errorf("extra settings not found")
 type Error struct {
 	ErrorType Type
 	Message   string
+	// Code is the stable code of the error returned by the APIs, it defaults to the generic code of the error type
+	Code errcode.Code
 }
 
 // Type returns the Type of the error
@@ -53,6 +57,30 @@ func (e *Error) Type() Type {
 	return e.ErrorType
 }
 
+// ErrorCode returns the stable code of the error
+func (e *Error) ErrorCode() errcode.Code {
+	if e.Code != "" {
+		return e.Code
+	}
+
+	switch e.ErrorType {
+	case UserAlreadyExists, AlreadyExists:
+		return errcode.AlreadyExists
+	case PreconditionFailed:
+		return errcode.PreconditionFailed
+	case PermissionDenied:
+		return errcode.PermissionDenied
+	case NotFound:
+		return errcode.NotFound
+	case InvalidArgument, BadRequest:
+		return errcode.InvalidArgument
+	case Unauthorized, Unauthenticated:
+		return errcode.Unauthenticated
+	default:
+		return errcode.Internal
+	}
+}
+
 // Error is an error string
 func (e *Error) Error() string {
 	return e.Message
@@ -100,12 +128,17 @@ func NewUserNotFoundError(userKey string) error {
 
 // NewPeerNotRegisteredError creates a new Error with NotFound type for a missing peer
 func NewPeerNotRegisteredError() error {
-	return Errorf(Unauthenticated, "peer is not registered")
+	return &Error{ErrorType: Unauthenticated, Message: "peer is not registered", Code: errcode.PeerNotRegistered}
 }
 
 // NewPeerLoginExpiredError creates a new Error with PermissionDenied type for an expired peer
 func NewPeerLoginExpiredError() error {
-	return Errorf(PermissionDenied, "peer login has expired, please log in once more")
+	return &Error{ErrorType: PermissionDenied, Message: "peer login has expired, please log in once more", Code: errcode.LoginExpired}
+}
+
+// NewRouteConflictError creates a new Error with AlreadyExists type for a route colliding with an existing one
+func NewRouteConflictError(format string, a ...interface{}) error {
+	return &Error{ErrorType: AlreadyExists, Message: fmt.Sprintf(format, a...), Code: errcode.RouteConflict}
 }
 
 // NewSetupKeyNotFoundError creates a new Error with NotFound type for a missing setup key
@@ -186,6 +219,41 @@ func NewPermissionValidationError(err error) error {
 	return Errorf(PermissionDenied, "failed to validate user permissions: %s", err)
 }
 
+// NewRoleNotFoundError creates a new Error with NotFound type for a missing custom role.
+func NewRoleNotFoundError(roleID string) error {
+	return Errorf(NotFound, "role: %s not found", roleID)
+}
+
+// NewTenantNotFoundError creates a new Error with NotFound type for a missing tenant account.
+func NewTenantNotFoundError(accountID string) error {
+	return Errorf(NotFound, "tenant: %s not found", accountID)
+}
+
+// NewWebhookNotFoundError creates a new Error with NotFound type for a missing webhook.
+func NewWebhookNotFoundError(webhookID string) error {
+	return Errorf(NotFound, "webhook: %s not found", webhookID)
+}
+
+// NewMonitorNotFoundError creates a new Error with NotFound type for a missing monitor.
+func NewMonitorNotFoundError(monitorID string) error {
+	return Errorf(NotFound, "monitor: %s not found", monitorID)
+}
+
+// NewDebugBundleNotFoundError creates a new Error with NotFound type for a missing debug bundle request.
+func NewDebugBundleNotFoundError(requestID string) error {
+	return Errorf(NotFound, "debug bundle: %s not found", requestID)
+}
+
+// NewPeerActionNotFoundError creates a new Error with NotFound type for a missing peer action.
+func NewPeerActionNotFoundError(actionID string) error {
+	return Errorf(NotFound, "peer action: %s not found", actionID)
+}
+
+// NewRolloutNotFoundError creates a new Error with NotFound type for a missing rollout.
+func NewRolloutNotFoundError(rolloutID string) error {
+	return Errorf(NotFound, "rollout: %s not found", rolloutID)
+}
+
 func NewResourceNotPartOfNetworkError(resourceID, networkID string) error {
 	return Errorf(BadRequest, "resource %s is not part of the network %s", resourceID, networkID)
 }
@@ -0,0 +1,97 @@
+package store
+
+import (
+	"context"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// batchContextKey is the context key of the batch transaction the store calls made with the context join
+type batchContextKey struct{}
+
+// batchTransaction is a transaction shared by every store call made with the context of a batch.
+// The transactions started within the batch are savepoints of it, the calls deferred to the commit by a savepoint
+// that is rolled back are dropped with its changes.
+type batchTransaction struct {
+	mu          sync.Mutex
+	db          *gorm.DB
+	done        bool
+	afterCommit []func()
+}
+
+func batchFromContext(ctx context.Context) *batchTransaction {
+	// some loaders, e.g. the one of the account user data cache, call the store without a context
+	if ctx == nil {
+		return nil
+	}
+	batch, _ := ctx.Value(batchContextKey{}).(*batchTransaction)
+	return batch
+}
+
+// InBatchTransaction tells whether the store calls made with the ctx are part of an uncommitted batch transaction
+func InBatchTransaction(ctx context.Context) bool {
+	batch := batchFromContext(ctx)
+	return batch != nil && batch.conn() != nil
+}
+
+// RunAfterCommit runs fn once the batch transaction of the ctx is committed, fn is dropped if the changes it follows
+// are rolled back. Outside a batch transaction fn runs right away.
+func RunAfterCommit(ctx context.Context, fn func()) {
+	batch := batchFromContext(ctx)
+	if batch == nil || !batch.deferCall(fn) {
+		fn()
+	}
+}
+
+// conn returns the transaction of the batch, nil once it is committed or rolled back
+func (b *batchTransaction) conn() *gorm.DB {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.done {
+		return nil
+	}
+	return b.db
+}
+
+func (b *batchTransaction) deferCall(fn func()) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.done {
+		return false
+	}
+	b.afterCommit = append(b.afterCommit, fn)
+	return true
+}
+
+// savepoint runs the operation in a savepoint of the batch transaction, the changes of the operation and the calls it
+// deferred to the commit are rolled back if it fails
+func (b *batchTransaction) savepoint(db *gorm.DB, operation func(tx *gorm.DB) error) error {
+	b.mu.Lock()
+	mark := len(b.afterCommit)
+	b.mu.Unlock()
+
+	err := db.Transaction(operation)
+	if err != nil {
+		b.mu.Lock()
+		if len(b.afterCommit) > mark {
+			b.afterCommit = b.afterCommit[:mark]
+		}
+		b.mu.Unlock()
+	}
+	return err
+}
+
+// finish ends the batch, the store calls made with its context afterwards run outside of it. It returns the calls
+// deferred to the commit.
+func (b *batchTransaction) finish() []func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.done = true
+	afterCommit := b.afterCommit
+	b.afterCommit = nil
+	return afterCommit
+}
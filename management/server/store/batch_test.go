@@ -0,0 +1,77 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/types"
+)
+
+const batchTestAccountID = "bf1c8084-ba50-4ce7-9439-34653001fc3b"
+
+func groupExists(t *testing.T, store Store, groupID string) bool {
+	t.Helper()
+
+	_, err := store.GetGroupByID(context.Background(), LockingStrengthShare, batchTestAccountID, groupID)
+	if err != nil {
+		sErr, ok := status.FromError(err)
+		require.True(t, ok && sErr.Type() == status.NotFound, "unexpected error: %v", err)
+		return false
+	}
+	return true
+}
+
+func saveTestGroup(ctx context.Context, store Store, groupID string) error {
+	return store.SaveGroup(ctx, LockingStrengthUpdate, &types.Group{ID: groupID, AccountID: batchTestAccountID, Name: groupID})
+}
+
+func TestSqlStore_ExecuteInBatchTransaction(t *testing.T) {
+	store, cleanUp, err := NewTestStoreFromSQL(context.Background(), "../testdata/store.sql", t.TempDir())
+	t.Cleanup(cleanUp)
+	require.NoError(t, err)
+
+	var committed []string
+	errFailed := errors.New("failed")
+
+	err = store.ExecuteInBatchTransaction(context.Background(), func(ctx context.Context) error {
+		assert.True(t, InBatchTransaction(ctx))
+
+		require.NoError(t, saveTestGroup(ctx, store, "kept"))
+		RunAfterCommit(ctx, func() { committed = append(committed, "kept") })
+
+		err := store.ExecuteInTransaction(ctx, func(tx Store) error {
+			require.NoError(t, saveTestGroup(ctx, tx, "rolled-back"))
+			RunAfterCommit(ctx, func() { committed = append(committed, "rolled-back") })
+			return errFailed
+		})
+		assert.ErrorIs(t, err, errFailed)
+
+		_, err = store.GetGroupByID(ctx, LockingStrengthShare, batchTestAccountID, "kept")
+		assert.NoError(t, err, "the changes should be visible within the batch")
+		assert.Empty(t, committed, "the deferred calls should wait for the commit")
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.True(t, groupExists(t, store, "kept"))
+	assert.False(t, groupExists(t, store, "rolled-back"), "the changes of a failed savepoint should be rolled back")
+	assert.Equal(t, []string{"kept"}, committed, "the calls deferred by a failed savepoint should be dropped")
+
+	committed = nil
+	err = store.ExecuteInBatchTransaction(context.Background(), func(ctx context.Context) error {
+		require.NoError(t, saveTestGroup(ctx, store, "discarded"))
+		RunAfterCommit(ctx, func() { committed = append(committed, "discarded") })
+		return errFailed
+	})
+	assert.ErrorIs(t, err, errFailed)
+	assert.False(t, groupExists(t, store, "discarded"), "the changes of a failed batch should be rolled back")
+	assert.Empty(t, committed)
+
+	RunAfterCommit(context.Background(), func() { committed = append(committed, "direct") })
+	assert.Equal(t, []string{"direct"}, committed, "the calls outside a batch should run right away")
+}
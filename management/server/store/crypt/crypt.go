@@ -0,0 +1,203 @@
+// Package crypt encrypts the secrets of the management store at rest with envelope encryption. The secrets are
+// encrypted with data keys, which are stored in the database wrapped with a key kept outside of it: an age identity
+// file or a HashiCorp Vault transit key. A copy of the database alone doesn't reveal the secrets.
+//
+// The encrypted columns are tagged with the encrypted serializer, e.g. `gorm:"serializer:encrypted"`. An encrypted
+// value names its data key, so the values encrypted with earlier data keys and the plaintext values written before the
+// encryption was enabled stay readable until they're rewritten.
+package crypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// prefix marks the encrypted values, followed by the ID of the data key and the sealed value
+	prefix = "nbenc:v1:"
+	// DataKeySize is the size of the AES-256 data keys
+	DataKeySize = 32
+)
+
+// ErrNoCipher is returned when an encrypted value is read without the encryption being set up
+var ErrNoCipher = errors.New("the value is encrypted but the store encryption isn't configured")
+
+// DataKey is a data key of the store wrapped with the key encryption key
+type DataKey struct {
+	ID         string `gorm:"primaryKey"`
+	WrappedKey []byte
+	// Active is set on the data key the secrets are encrypted with, the other keys only decrypt
+	Active    bool
+	CreatedAt time.Time
+}
+
+// TableName returns the table of the data keys
+func (DataKey) TableName() string {
+	return "encryption_keys"
+}
+
+// Cipher encrypts the values with the active data key and decrypts them with the data key they name
+type Cipher struct {
+	mu       sync.RWMutex
+	keys     map[string]cipher.AEAD
+	activeID string
+	// load returns the unwrapped data key missing locally, e.g. activated by another management server
+	load func(id string) ([]byte, error)
+}
+
+// NewCipher creates a cipher without data keys, load is called for the data keys not added yet and may be nil
+func NewCipher(load func(id string) ([]byte, error)) *Cipher {
+	return &Cipher{
+		keys: make(map[string]cipher.AEAD),
+		load: load,
+	}
+}
+
+// NewDataKey generates a random data key
+func NewDataKey() ([]byte, error) {
+	key := make([]byte, DataKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("generate data key: %w", err)
+	}
+	return key, nil
+}
+
+// AddKey adds an unwrapped data key, the values are encrypted with it if active is set
+func (c *Cipher) AddKey(id string, key []byte, active bool) error {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return fmt.Errorf("data key %s: %w", id, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.keys[id] = aead
+	if active {
+		c.activeID = id
+	}
+	return nil
+}
+
+// ActiveKeyID returns the ID of the data key the values are encrypted with
+func (c *Cipher) ActiveKeyID() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.activeID
+}
+
+// Encrypt seals the value with the active data key
+func (c *Cipher) Encrypt(value string) (string, error) {
+	c.mu.RLock()
+	id := c.activeID
+	aead := c.keys[id]
+	c.mu.RUnlock()
+
+	if aead == nil {
+		return "", errors.New("no active data key")
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(value)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("generate nonce: %w", err)
+	}
+	// the key ID is authenticated, a value can't be moved under another key
+	sealed := aead.Seal(nonce, nonce, []byte(value), []byte(id))
+
+	return prefix + id + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens an encrypted value, the plaintext values are returned unchanged
+func (c *Cipher) Decrypt(value string) (string, error) {
+	id, sealed, ok, err := parse(value)
+	if err != nil || !ok {
+		return value, err
+	}
+
+	aead, err := c.key(id)
+	if err != nil {
+		return "", err
+	}
+
+	nonceSize := aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return "", errors.New("encrypted value too short")
+	}
+	plain, err := aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], []byte(id))
+	if err != nil {
+		return "", fmt.Errorf("decrypt with data key %s: %w", id, err)
+	}
+	return string(plain), nil
+}
+
+// IsCurrent returns true if the value is encrypted with the active data key or empty, the other values are rewritten
+// by a re-encryption
+func (c *Cipher) IsCurrent(value string) bool {
+	if value == "" {
+		return true
+	}
+	id, _, ok, err := parse(value)
+	return err == nil && ok && id == c.ActiveKeyID()
+}
+
+// IsEncrypted returns true if the value was encrypted by a cipher
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+func (c *Cipher) key(id string) (cipher.AEAD, error) {
+	c.mu.RLock()
+	aead, ok := c.keys[id]
+	c.mu.RUnlock()
+	if ok {
+		return aead, nil
+	}
+
+	if c.load == nil {
+		return nil, fmt.Errorf("unknown data key %s", id)
+	}
+	key, err := c.load(id)
+	if err != nil {
+		return nil, fmt.Errorf("load data key %s: %w", id, err)
+	}
+	if err := c.AddKey(id, key, false); err != nil {
+		return nil, err
+	}
+	return c.key(id)
+}
+
+// parse splits an encrypted value into the ID of its data key and the sealed value, ok is false for a plaintext value
+func parse(value string) (id string, sealed []byte, ok bool, err error) {
+	if !IsEncrypted(value) {
+		return "", nil, false, nil
+	}
+
+	id, encoded, found := strings.Cut(strings.TrimPrefix(value, prefix), ":")
+	if !found || id == "" {
+		return "", nil, false, errors.New("malformed encrypted value")
+	}
+	sealed, err = base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", nil, false, fmt.Errorf("decode encrypted value: %w", err)
+	}
+	return id, sealed, true, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != DataKeySize {
+		return nil, fmt.Errorf("invalid key size %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
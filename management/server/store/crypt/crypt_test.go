@@ -0,0 +1,166 @@
+package crypt
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newTestCipher(t *testing.T, id string) *Cipher {
+	t.Helper()
+	key, err := NewDataKey()
+	require.NoError(t, err)
+	c := NewCipher(nil)
+	require.NoError(t, c.AddKey(id, key, true))
+	return c
+}
+
+func TestCipher(t *testing.T) {
+	c := newTestCipher(t, "first")
+
+	encrypted, err := c.Encrypt("secret")
+	require.NoError(t, err)
+	assert.True(t, IsEncrypted(encrypted))
+	assert.NotContains(t, encrypted, "secret")
+	assert.True(t, c.IsCurrent(encrypted))
+
+	plain, err := c.Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", plain)
+
+	plain, err = c.Decrypt("written before the encryption")
+	require.NoError(t, err)
+	assert.Equal(t, "written before the encryption", plain, "the plaintext values should stay readable")
+	assert.False(t, c.IsCurrent("written before the encryption"))
+
+	key, err := NewDataKey()
+	require.NoError(t, err)
+	require.NoError(t, c.AddKey("second", key, true))
+	assert.False(t, c.IsCurrent(encrypted), "the values of the previous data key should be re-encrypted")
+	plain, err = c.Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", plain, "the previous data key should still decrypt")
+
+	moved := strings.Replace(encrypted, ":first:", ":second:", 1)
+	_, err = c.Decrypt(moved)
+	assert.Error(t, err, "the data key ID should be authenticated")
+}
+
+func TestCipher_LoadsMissingKeys(t *testing.T) {
+	key, err := NewDataKey()
+	require.NoError(t, err)
+	writer := NewCipher(nil)
+	require.NoError(t, writer.AddKey("other-server", key, true))
+	encrypted, err := writer.Encrypt("secret")
+	require.NoError(t, err)
+
+	var loaded []string
+	reader := NewCipher(func(id string) ([]byte, error) {
+		loaded = append(loaded, id)
+		return key, nil
+	})
+	for i := 0; i < 2; i++ {
+		plain, err := reader.Decrypt(encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, "secret", plain)
+	}
+	assert.Equal(t, []string{"other-server"}, loaded, "the data key should be loaded once")
+
+	_, err = NewCipher(nil).Decrypt(encrypted)
+	assert.Error(t, err, "an unknown data key shouldn't decrypt")
+}
+
+func TestSerializer(t *testing.T) {
+	type record struct {
+		ID     string `gorm:"primaryKey"`
+		Secret string `gorm:"serializer:encrypted"`
+	}
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "store.db")), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&record{}))
+	t.Cleanup(func() { SetCipher(nil) })
+
+	SetCipher(nil)
+	require.NoError(t, db.Create(&record{ID: "plain", Secret: "before"}).Error)
+
+	SetCipher(newTestCipher(t, "key"))
+	require.NoError(t, db.Create(&record{ID: "encrypted", Secret: "after"}).Error)
+
+	var raw string
+	require.NoError(t, db.Raw("SELECT secret FROM records WHERE id = ?", "encrypted").Scan(&raw).Error)
+	assert.True(t, IsEncrypted(raw), "the column should be encrypted in the database")
+
+	var records []record
+	require.NoError(t, db.Order("id").Find(&records).Error)
+	require.Len(t, records, 2)
+	assert.Equal(t, "after", records[0].Secret)
+	assert.Equal(t, "before", records[1].Secret)
+
+	SetCipher(nil)
+	err = db.Where("id = ?", "encrypted").First(&record{}).Error
+	assert.ErrorIs(t, err, ErrNoCipher)
+}
+
+func TestAgeWrapper(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	file := filepath.Join(t.TempDir(), "identity.txt")
+	require.NoError(t, os.WriteFile(file, []byte(identity.String()+"\n"), 0600))
+
+	w, err := NewAgeWrapper(file)
+	require.NoError(t, err)
+
+	key, err := NewDataKey()
+	require.NoError(t, err)
+	wrapped, err := w.Wrap(context.Background(), key)
+	require.NoError(t, err)
+	assert.NotContains(t, string(wrapped), string(key))
+
+	unwrapped, err := w.Unwrap(context.Background(), wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, key, unwrapped)
+}
+
+func TestVaultTransitWrapper(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "token", r.Header.Get("X-Vault-Token"))
+
+		var req map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		switch r.URL.Path {
+		case "/v1/transit/encrypt/netbird":
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]string{"ciphertext": "vault:v1:" + req["plaintext"]}})
+		case "/v1/transit/decrypt/netbird":
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]string{"plaintext": strings.TrimPrefix(req["ciphertext"], "vault:v1:")}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv(vaultTokenEnv, "token")
+	w, err := NewVaultTransitWrapper(server.URL, "netbird")
+	require.NoError(t, err)
+
+	key, err := NewDataKey()
+	require.NoError(t, err)
+	wrapped, err := w.Wrap(context.Background(), key)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(wrapped), "vault:v1:"))
+
+	unwrapped, err := w.Unwrap(context.Background(), wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, key, unwrapped)
+}
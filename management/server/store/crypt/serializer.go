@@ -0,0 +1,75 @@
+package crypt
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+
+	"gorm.io/gorm/schema"
+)
+
+// SerializerName is the name of the serializer of the encrypted columns
+const SerializerName = "encrypted"
+
+// active is the cipher of the encrypted serializer, the values are written in plaintext while it's nil
+var active atomic.Pointer[Cipher]
+
+func init() {
+	schema.RegisterSerializer(SerializerName, Serializer{})
+}
+
+// SetCipher sets the cipher of the encrypted columns, nil writes them in plaintext
+func SetCipher(c *Cipher) {
+	active.Store(c)
+}
+
+// GetCipher returns the cipher of the encrypted columns, nil if the encryption isn't set up
+func GetCipher() *Cipher {
+	return active.Load()
+}
+
+// Serializer encrypts the string columns with the cipher set by SetCipher
+type Serializer struct{}
+
+// Scan decrypts the database value into the field
+func (Serializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue any) error {
+	var value string
+	switch v := dbValue.(type) {
+	case nil:
+	case string:
+		value = v
+	case []byte:
+		value = string(v)
+	default:
+		return fmt.Errorf("unsupported value type %T of encrypted column %s", dbValue, field.DBName)
+	}
+
+	if IsEncrypted(value) {
+		c := active.Load()
+		if c == nil {
+			return fmt.Errorf("column %s: %w", field.DBName, ErrNoCipher)
+		}
+		plain, err := c.Decrypt(value)
+		if err != nil {
+			return fmt.Errorf("column %s: %w", field.DBName, err)
+		}
+		value = plain
+	}
+
+	return field.Set(ctx, dst, value)
+}
+
+// Value encrypts the field value for the database, the empty values stay empty
+func (Serializer) Value(_ context.Context, field *schema.Field, _ reflect.Value, fieldValue any) (any, error) {
+	value, ok := fieldValue.(string)
+	if !ok {
+		return nil, fmt.Errorf("unsupported type %T of encrypted field %s", fieldValue, field.Name)
+	}
+
+	c := active.Load()
+	if c == nil || value == "" {
+		return value, nil
+	}
+	return c.Encrypt(value)
+}
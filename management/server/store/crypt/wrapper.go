@@ -0,0 +1,173 @@
+package crypt
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+)
+
+// vaultTokenEnv is the environment variable the token of the Vault server is read from
+const vaultTokenEnv = "VAULT_TOKEN"
+
+// KeyWrapper wraps the data keys with the key encryption key kept outside of the database
+type KeyWrapper interface {
+	Wrap(ctx context.Context, dataKey []byte) ([]byte, error)
+	Unwrap(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+type ageWrapper struct {
+	identities []age.Identity
+	recipients []age.Recipient
+}
+
+// NewAgeWrapper returns a wrapper encrypting the data keys to the X25519 identities of the age identity file, e.g.
+// created with age-keygen
+func NewAgeWrapper(identityFile string) (KeyWrapper, error) {
+	f, err := os.Open(identityFile)
+	if err != nil {
+		return nil, fmt.Errorf("open age identity file: %w", err)
+	}
+	defer f.Close()
+
+	identities, err := age.ParseIdentities(f)
+	if err != nil {
+		return nil, fmt.Errorf("parse age identity file: %w", err)
+	}
+
+	w := &ageWrapper{identities: identities}
+	for _, identity := range identities {
+		if x25519, ok := identity.(*age.X25519Identity); ok {
+			w.recipients = append(w.recipients, x25519.Recipient())
+		}
+	}
+	if len(w.recipients) == 0 {
+		return nil, errors.New("no X25519 identity in the age identity file")
+	}
+	return w, nil
+}
+
+func (w *ageWrapper) Wrap(_ context.Context, dataKey []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer, err := age.Encrypt(&buf, w.recipients...)
+	if err != nil {
+		return nil, fmt.Errorf("age encrypt: %w", err)
+	}
+	if _, err := writer.Write(dataKey); err != nil {
+		return nil, fmt.Errorf("age encrypt: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("age encrypt: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (w *ageWrapper) Unwrap(_ context.Context, wrapped []byte) ([]byte, error) {
+	reader, err := age.Decrypt(bytes.NewReader(wrapped), w.identities...)
+	if err != nil {
+		return nil, fmt.Errorf("age decrypt: %w", err)
+	}
+	key, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("age decrypt: %w", err)
+	}
+	return key, nil
+}
+
+type vaultTransitWrapper struct {
+	client  *http.Client
+	address string
+	key     string
+	token   string
+}
+
+// NewVaultTransitWrapper returns a wrapper encrypting the data keys with the transit secrets engine of a HashiCorp
+// Vault server, the key never leaves the server. The token is read from the VAULT_TOKEN environment variable.
+func NewVaultTransitWrapper(address, key string) (KeyWrapper, error) {
+	if _, err := url.ParseRequestURI(address); err != nil {
+		return nil, fmt.Errorf("invalid Vault address %s: %w", address, err)
+	}
+	if key == "" {
+		return nil, errors.New("the Vault transit key isn't set")
+	}
+	token := os.Getenv(vaultTokenEnv)
+	if token == "" {
+		return nil, fmt.Errorf("set the Vault token with the %s environment variable", vaultTokenEnv)
+	}
+
+	return &vaultTransitWrapper{
+		client:  &http.Client{Timeout: 10 * time.Second},
+		address: strings.TrimSuffix(address, "/"),
+		key:     key,
+		token:   token,
+	}, nil
+}
+
+func (w *vaultTransitWrapper) Wrap(ctx context.Context, dataKey []byte) ([]byte, error) {
+	var resp struct {
+		Data struct {
+			Ciphertext string `json:"ciphertext"`
+		} `json:"data"`
+	}
+	req := map[string]string{"plaintext": base64.StdEncoding.EncodeToString(dataKey)}
+	if err := w.call(ctx, "encrypt", req, &resp); err != nil {
+		return nil, err
+	}
+	return []byte(resp.Data.Ciphertext), nil
+}
+
+func (w *vaultTransitWrapper) Unwrap(ctx context.Context, wrapped []byte) ([]byte, error) {
+	var resp struct {
+		Data struct {
+			Plaintext string `json:"plaintext"`
+		} `json:"data"`
+	}
+	if err := w.call(ctx, "decrypt", map[string]string{"ciphertext": string(wrapped)}, &resp); err != nil {
+		return nil, err
+	}
+	key, err := base64.StdEncoding.DecodeString(resp.Data.Plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("decode vault plaintext: %w", err)
+	}
+	return key, nil
+}
+
+func (w *vaultTransitWrapper) call(ctx context.Context, operation string, body, result any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("%s/v1/transit/%s/%s", w.address, operation, url.PathEscape(w.key))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", w.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("vault transit %s: %w", operation, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("vault transit %s: status %d: %s", operation, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("decode vault transit %s response: %w", operation, err)
+	}
+	return nil
+}
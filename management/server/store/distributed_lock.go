@@ -0,0 +1,42 @@
+package store
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Locker locks keys across the management servers sharing the store
+type Locker interface {
+	Lock(ctx context.Context, key string) (unlock func(), err error)
+}
+
+// distributedLockStore extends the write locks of the store to all management servers sharing it
+type distributedLockStore struct {
+	Store
+	locker Locker
+}
+
+// NewDistributedLockStore returns the store with its write locks also acquired through the locker, so the writes to
+// an account are serialized across the management servers. Read locks stay local to the server.
+func NewDistributedLockStore(store Store, locker Locker) Store {
+	return &distributedLockStore{Store: store, locker: locker}
+}
+
+// AcquireWriteLockByUID acquires the local lock of the ID first, so only one request per server waits for the distributed lock.
+// If the distributed lock can't be acquired the write proceeds with the local lock only and the database transactions
+// keep the data consistent.
+func (s *distributedLockStore) AcquireWriteLockByUID(ctx context.Context, uniqueID string) (unlock func()) {
+	localUnlock := s.Store.AcquireWriteLockByUID(ctx, uniqueID)
+
+	distributedUnlock, err := s.locker.Lock(ctx, uniqueID)
+	if err != nil {
+		log.WithContext(ctx).Errorf("failed to acquire distributed write lock for ID %s: %v", uniqueID, err)
+		return localUnlock
+	}
+
+	return func() {
+		distributedUnlock()
+		localUnlock()
+	}
+}
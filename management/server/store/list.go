@@ -0,0 +1,183 @@
+package store
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/netbirdio/netbird/management/server/status"
+)
+
+const (
+	// DefaultListLimit is the page size used when a cursor is given without a limit
+	DefaultListLimit = 100
+	// MaxListLimit is the largest page size that can be requested
+	MaxListLimit = 1000
+
+	SortByName     = "name"
+	SortByOS       = "os"
+	SortByLastSeen = "last_seen"
+)
+
+// ListOptions sorts and paginates the results of the list queries.
+// Pagination is cursor based: the cursor returned with a page points after its last item.
+type ListOptions struct {
+	// SortBy is the field the results are sorted by, defaults to the name
+	SortBy string
+	// Descending reverses the sort order
+	Descending bool
+	// Cursor continues the listing after the page it was returned with
+	Cursor string
+	// Limit is the maximum number of results, all results are returned if 0 and no cursor is set
+	Limit int
+}
+
+// PeerFilter filters the peers returned by ListAccountPeers
+type PeerFilter struct {
+	// Name matches the peers with the name containing it
+	Name string
+	// IP matches the peers with the IP containing it
+	IP string
+	// OS matches the peers of the operating system family, e.g. linux, windows or darwin
+	OS string
+	// GroupID matches the peers of the group
+	GroupID string
+	// Connected matches the connected or disconnected peers
+	Connected *bool
+	// UserID matches the peers added by the user
+	UserID string
+	// IDs matches the peers with the IDs
+	IDs []string
+	// SerialNumber matches the peers with the system serial number
+	SerialNumber string
+	// Model matches the peers with the system model containing it
+	Model string
+	// KernelVersion matches the peers with the kernel version starting with it
+	KernelVersion string
+	// Version matches the peers with the NetBird version starting with it
+	Version string
+	// MinUptime matches the peers that booted at least MinUptime ago, the peers not reporting their boot time don't match
+	MinUptime time.Duration
+	// Labels matches the peers having all the labels
+	Labels map[string]string
+}
+
+// HasInventoryFilter checks if the filter matches the peers by their inventory
+func (f PeerFilter) HasInventoryFilter() bool {
+	return f.SerialNumber != "" || f.Model != "" || f.KernelVersion != "" || f.Version != "" || f.MinUptime > 0
+}
+
+// GroupFilter filters the groups returned by ListAccountGroups
+type GroupFilter struct {
+	// Name matches the groups with the name containing it
+	Name string
+}
+
+// sortColumn is a column the results can be sorted by
+type sortColumn struct {
+	name   string
+	isTime bool
+}
+
+var (
+	peerSortColumns = map[string]sortColumn{
+		SortByName:     {name: "name"},
+		SortByOS:       {name: "meta_go_os"},
+		SortByLastSeen: {name: "peer_status_last_seen", isTime: true},
+	}
+	groupSortColumns = map[string]sortColumn{
+		SortByName: {name: "name"},
+	}
+)
+
+// listCursor is the position after the last item of a page: the value of the sort column and the ID breaking ties
+type listCursor struct {
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+func encodeCursor(value, id string) string {
+	b, _ := json.Marshal(&listCursor{Value: value, ID: id})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(cursor string) (*listCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, status.Errorf(status.InvalidArgument, "invalid cursor")
+	}
+
+	c := &listCursor{}
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, status.Errorf(status.InvalidArgument, "invalid cursor")
+	}
+
+	return c, nil
+}
+
+// paginate applies the sort order, the cursor and the limit of the options to the query.
+// It returns the limit the caller has to trim the results to, or 0 if the results are not limited.
+// One more row than the limit is queried to know whether there is a next page.
+func paginate(query *gorm.DB, opts ListOptions, columns map[string]sortColumn) (*gorm.DB, sortColumn, int, error) {
+	sortBy := opts.SortBy
+	if sortBy == "" {
+		sortBy = SortByName
+	}
+
+	column, ok := columns[sortBy]
+	if !ok {
+		return nil, sortColumn{}, 0, status.Errorf(status.InvalidArgument, "results can't be sorted by %s", sortBy)
+	}
+
+	limit := opts.Limit
+	if limit < 0 || limit > MaxListLimit {
+		return nil, sortColumn{}, 0, status.Errorf(status.InvalidArgument, "limit must be between 1 and %d", MaxListLimit)
+	}
+	if limit == 0 && opts.Cursor != "" {
+		limit = DefaultListLimit
+	}
+
+	direction, comparison := "ASC", ">"
+	if opts.Descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	if opts.Cursor != "" {
+		cursor, err := decodeCursor(opts.Cursor)
+		if err != nil {
+			return nil, sortColumn{}, 0, err
+		}
+
+		var value any = cursor.Value
+		if column.isTime {
+			value, err = time.Parse(time.RFC3339Nano, cursor.Value)
+			if err != nil {
+				return nil, sortColumn{}, 0, status.Errorf(status.InvalidArgument, "invalid cursor")
+			}
+		}
+
+		condition := fmt.Sprintf("(%s %s ? OR (%s = ? AND id %s ?))", column.name, comparison, column.name, comparison)
+		query = query.Where(condition, value, value, cursor.ID)
+	}
+
+	query = query.Order(fmt.Sprintf("%s %s, id %s", column.name, direction, direction))
+	if limit > 0 {
+		query = query.Limit(limit + 1)
+	}
+
+	return query, column, limit, nil
+}
+
+// nextPage trims the results to the limit and returns the cursor of the next page, empty if it was the last page
+func nextPage[T any](items []T, limit int, cursorOf func(T) (string, string)) ([]T, string) {
+	if limit == 0 || len(items) <= limit {
+		return items, ""
+	}
+
+	items = items[:limit]
+	value, id := cursorOf(items[limit-1])
+	return items, encodeCursor(value, id)
+}
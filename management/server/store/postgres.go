@@ -0,0 +1,97 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/netbirdio/netbird/management/server/types"
+)
+
+// postgresIndex is an index created by the Postgres store in addition to the ones of the models
+type postgresIndex struct {
+	name    string
+	table   string
+	columns []string
+}
+
+// postgresIndexes speed up the account scoped lookups and the sorted, paginated lists of large accounts.
+// The ID is the last column of the list indexes as it breaks the ties of the sort column.
+var postgresIndexes = []postgresIndex{
+	{name: "idx_peers_account_id_name", table: "peers", columns: []string{"account_id", "name", "id"}},
+	{name: "idx_peers_account_id_os", table: "peers", columns: []string{"account_id", "meta_go_os", "id"}},
+	{name: "idx_peers_account_id_last_seen", table: "peers", columns: []string{"account_id", "peer_status_last_seen", "id"}},
+	{name: "idx_peers_account_id_connected", table: "peers", columns: []string{"account_id", "peer_status_connected"}},
+	{name: "idx_peers_account_id_user_id", table: "peers", columns: []string{"account_id", "user_id"}},
+	{name: "idx_groups_account_id_name", table: "groups", columns: []string{"account_id", "name", "id"}},
+}
+
+// createPostgresIndexes creates the missing Postgres indexes without locking the tables for writes,
+// so it is safe to run against a live database. An index left invalid by an interrupted build is rebuilt.
+// The indexes are created concurrently, which isn't possible inside a transaction.
+func createPostgresIndexes(ctx context.Context, db *gorm.DB) error {
+	for _, index := range postgresIndexes {
+		var valid []bool
+		err := db.Raw(
+			"SELECT i.indisvalid FROM pg_index i JOIN pg_class c ON c.oid = i.indexrelid WHERE c.relname = ? AND pg_table_is_visible(c.oid)",
+			index.name,
+		).Scan(&valid).Error
+		if err != nil {
+			return fmt.Errorf("check index %s: %w", index.name, err)
+		}
+
+		if len(valid) > 0 && valid[0] {
+			continue
+		}
+
+		if len(valid) > 0 {
+			log.WithContext(ctx).Warnf("rebuilding invalid index %s", index.name)
+			if err = db.Exec(fmt.Sprintf("DROP INDEX CONCURRENTLY IF EXISTS %s", index.name)).Error; err != nil {
+				return fmt.Errorf("drop invalid index %s: %w", index.name, err)
+			}
+		}
+
+		log.WithContext(ctx).Infof("creating index %s on %s", index.name, index.table)
+		stmt := fmt.Sprintf("CREATE INDEX CONCURRENTLY IF NOT EXISTS %s ON %s (%s)",
+			index.name, index.table, strings.Join(index.columns, ", "))
+		if err = db.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("create index %s: %w", index.name, err)
+		}
+	}
+
+	return nil
+}
+
+// openPostgresReplica opens the read replica used by the list queries, its pool is sized like the one of the primary
+func openPostgresReplica(ctx context.Context, dsn string) (*gorm.DB, error) {
+	replica, err := gorm.Open(postgres.Open(dsn), getGormConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	sql, err := replica.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	getConnectionPoolConfig(ctx, types.PostgresStoreEngine).apply(ctx, sql)
+
+	log.WithContext(ctx).Info("using read replica for list queries")
+
+	return replica, nil
+}
+
+// listDB returns the database the list queries read from. The read replica is used when it is configured,
+// the query doesn't lock the rows for update and the store isn't part of a transaction, as the replica
+// may lag behind the primary and doesn't support locking reads.
+func (s *SqlStore) listDB(ctx context.Context, lockStrength LockingStrength) *gorm.DB {
+	if s.replica != nil && lockStrength != LockingStrengthUpdate && !InBatchTransaction(ctx) {
+		return s.replica
+	}
+	return s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)})
+}
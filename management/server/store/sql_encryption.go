@@ -0,0 +1,251 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rs/xid"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/netbirdio/netbird/management/server/store/crypt"
+	"github.com/netbirdio/netbird/management/server/types"
+
+	monitorTypes "github.com/netbirdio/netbird/management/server/monitors/types"
+	webhookTypes "github.com/netbirdio/netbird/management/server/webhooks/types"
+)
+
+// reencryptBatchSize is the number of rows read at once by the re-encryption
+const reencryptBatchSize = 500
+
+// encryptedModels are the models with columns written with the encrypted serializer
+var encryptedModels = []any{&webhookTypes.Webhook{}, &monitorTypes.Monitor{}}
+
+// NewKeyWrapper returns the wrapper of the data keys of the store encryption config, nil if the encryption isn't
+// configured
+func NewKeyWrapper(config *types.StoreEncryptionConfig) (crypt.KeyWrapper, error) {
+	switch {
+	case config == nil:
+		return nil, nil
+	case config.AgeIdentityFile != "" && config.VaultAddress != "":
+		return nil, errors.New("configure either an age identity file or a Vault transit key")
+	case config.AgeIdentityFile != "":
+		return crypt.NewAgeWrapper(config.AgeIdentityFile)
+	case config.VaultAddress != "":
+		return crypt.NewVaultTransitWrapper(config.VaultAddress, config.VaultTransitKey)
+	default:
+		return nil, nil
+	}
+}
+
+// SetupEncryption unwraps the data keys of the store and encrypts the secrets written from now on with the active
+// one. The first data key is created on the first setup. The secrets written before stay in plaintext until they're
+// rewritten, e.g. by ReencryptSecrets.
+func (s *SqlStore) SetupEncryption(ctx context.Context, wrapper crypt.KeyWrapper) error {
+	var dataKeys []crypt.DataKey
+	if err := s.db.Order("created_at").Find(&dataKeys).Error; err != nil {
+		return fmt.Errorf("get data keys: %w", err)
+	}
+
+	c := crypt.NewCipher(func(id string) ([]byte, error) {
+		var dataKey crypt.DataKey
+		if err := s.db.First(&dataKey, idQueryCondition, id).Error; err != nil {
+			return nil, err
+		}
+		return wrapper.Unwrap(ctx, dataKey.WrappedKey)
+	})
+	for _, dataKey := range dataKeys {
+		key, err := wrapper.Unwrap(ctx, dataKey.WrappedKey)
+		if err != nil {
+			return fmt.Errorf("unwrap data key %s: %w", dataKey.ID, err)
+		}
+		if err = c.AddKey(dataKey.ID, key, dataKey.Active); err != nil {
+			return err
+		}
+	}
+
+	if c.ActiveKeyID() == "" {
+		if err := s.activateNewDataKey(ctx, wrapper, c); err != nil {
+			return err
+		}
+	}
+
+	s.keyWrapper = wrapper
+	crypt.SetCipher(c)
+	log.WithContext(ctx).Infof("encrypting the secrets of the store with data key %s", c.ActiveKeyID())
+
+	return nil
+}
+
+// ReencryptSecrets rewrites the secrets not encrypted with the active data key, including the plaintext ones, and
+// returns their number. A new data key is activated first with rotate. The store stays online, the values changed
+// meanwhile are skipped as they're written with the active data key.
+func (s *SqlStore) ReencryptSecrets(ctx context.Context, rotate bool) (int, error) {
+	c := crypt.GetCipher()
+	if c == nil || s.keyWrapper == nil {
+		return 0, errors.New("the store encryption isn't set up")
+	}
+
+	if rotate {
+		if err := s.activateNewDataKey(ctx, s.keyWrapper, c); err != nil {
+			return 0, err
+		}
+	}
+
+	var total int
+	for _, model := range encryptedModels {
+		stmt := &gorm.Statement{DB: s.db}
+		if err := stmt.Parse(model); err != nil {
+			return total, fmt.Errorf("parse model: %w", err)
+		}
+
+		var columns []string
+		for _, field := range stmt.Schema.Fields {
+			if field.TagSettings["SERIALIZER"] == crypt.SerializerName {
+				columns = append(columns, field.DBName)
+			}
+		}
+
+		count, err := s.reencryptTable(ctx, c, stmt.Schema.Table, stmt.Schema.PrioritizedPrimaryField.DBName, columns)
+		total += count
+		if err != nil {
+			return total, fmt.Errorf("re-encrypt %s: %w", stmt.Schema.Table, err)
+		}
+	}
+
+	return total, nil
+}
+
+// RewrapDataKeys wraps the data keys with a new key encryption key, e.g. a new age identity. The store has to be
+// configured with the new key encryption key afterward.
+func (s *SqlStore) RewrapDataKeys(ctx context.Context, wrapper crypt.KeyWrapper) error {
+	if s.keyWrapper == nil {
+		return errors.New("the store encryption isn't set up")
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var dataKeys []crypt.DataKey
+		if err := tx.Clauses(clause.Locking{Strength: string(LockingStrengthUpdate)}).Find(&dataKeys).Error; err != nil {
+			return fmt.Errorf("get data keys: %w", err)
+		}
+
+		for _, dataKey := range dataKeys {
+			key, err := s.keyWrapper.Unwrap(ctx, dataKey.WrappedKey)
+			if err != nil {
+				return fmt.Errorf("unwrap data key %s: %w", dataKey.ID, err)
+			}
+			wrapped, err := wrapper.Wrap(ctx, key)
+			if err != nil {
+				return fmt.Errorf("wrap data key %s: %w", dataKey.ID, err)
+			}
+			if err = tx.Model(&dataKey).Update("wrapped_key", wrapped).Error; err != nil {
+				return fmt.Errorf("save data key %s: %w", dataKey.ID, err)
+			}
+		}
+
+		log.WithContext(ctx).Infof("wrapped %d data keys with the new key encryption key", len(dataKeys))
+		return nil
+	})
+}
+
+// activateNewDataKey stores a new data key and encrypts the secrets with it, the previous data keys are kept to
+// decrypt the secrets written with them
+func (s *SqlStore) activateNewDataKey(ctx context.Context, wrapper crypt.KeyWrapper, c *crypt.Cipher) error {
+	key, err := crypt.NewDataKey()
+	if err != nil {
+		return err
+	}
+	wrapped, err := wrapper.Wrap(ctx, key)
+	if err != nil {
+		return fmt.Errorf("wrap data key: %w", err)
+	}
+
+	dataKey := crypt.DataKey{ID: xid.New().String(), WrappedKey: wrapped, Active: true}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&crypt.DataKey{}).Where("active = ?", true).Update("active", false).Error; err != nil {
+			return err
+		}
+		return tx.Create(&dataKey).Error
+	})
+	if err != nil {
+		return fmt.Errorf("save data key: %w", err)
+	}
+
+	log.WithContext(ctx).Infof("activated data key %s", dataKey.ID)
+	return c.AddKey(dataKey.ID, key, true)
+}
+
+// reencryptTable rewrites the values of the columns of the table not encrypted with the active data key. A value is
+// only replaced if it's unchanged, so the concurrent writes aren't lost.
+func (s *SqlStore) reencryptTable(ctx context.Context, c *crypt.Cipher, table, primaryKey string, columns []string) (int, error) {
+	var count int
+	var lastID any = ""
+	for {
+		var rows []map[string]any
+		err := s.db.Table(table).
+			Select(append([]string{primaryKey}, columns...)).
+			Where(clause.Gt{Column: clause.Column{Name: primaryKey}, Value: lastID}).
+			Order(clause.OrderByColumn{Column: clause.Column{Name: primaryKey}}).
+			Limit(reencryptBatchSize).
+			Find(&rows).Error
+		if err != nil {
+			return count, err
+		}
+
+		for _, row := range rows {
+			lastID = row[primaryKey]
+			for _, column := range columns {
+				rewritten, err := s.reencryptValue(c, table, primaryKey, lastID, column, columnString(row[column]))
+				if err != nil {
+					return count, fmt.Errorf("row %v: %w", lastID, err)
+				}
+				if rewritten {
+					count++
+				}
+			}
+		}
+
+		if len(rows) < reencryptBatchSize {
+			log.WithContext(ctx).Debugf("re-encrypted %d values of %s", count, table)
+			return count, nil
+		}
+	}
+}
+
+func (s *SqlStore) reencryptValue(c *crypt.Cipher, table, primaryKey string, id any, column, value string) (bool, error) {
+	if c.IsCurrent(value) {
+		return false, nil
+	}
+
+	plain, err := c.Decrypt(value)
+	if err != nil {
+		return false, err
+	}
+	encrypted, err := c.Encrypt(plain)
+	if err != nil {
+		return false, err
+	}
+
+	result := s.db.Table(table).
+		Where(clause.Eq{Column: clause.Column{Name: primaryKey}, Value: id}).
+		Where(clause.Eq{Column: clause.Column{Name: column}, Value: value}).
+		Update(column, encrypted)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// columnString returns the value of a text column, the drivers return strings or bytes
+func columnString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,83 @@
+package store
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/management/server/store/crypt"
+	webhookTypes "github.com/netbirdio/netbird/management/server/webhooks/types"
+)
+
+func newTestAgeWrapper(t *testing.T) crypt.KeyWrapper {
+	t.Helper()
+
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	file := filepath.Join(t.TempDir(), "identity.txt")
+	require.NoError(t, os.WriteFile(file, []byte(identity.String()+"\n"), 0600))
+
+	wrapper, err := crypt.NewAgeWrapper(file)
+	require.NoError(t, err)
+	return wrapper
+}
+
+func TestSqlStore_ReencryptSecrets(t *testing.T) {
+	ctx := context.Background()
+	s, cleanup, err := NewTestStoreFromSQL(ctx, "../testdata/extended-store.sql", t.TempDir())
+	t.Cleanup(cleanup)
+	require.NoError(t, err)
+	t.Cleanup(func() { crypt.SetCipher(nil) })
+
+	accountID := "bf1c8084-ba50-4ce7-9439-34653001fc3b"
+	webhook := webhookTypes.NewWebhook(accountID, "slack", "https://hooks.example.com/T000/secret-token", "signing-secret", nil, 3, true)
+	require.NoError(t, s.SaveWebhook(ctx, LockingStrengthUpdate, webhook))
+
+	_, err = s.ReencryptSecrets(ctx, false)
+	require.Error(t, err, "the re-encryption should fail without encryption")
+
+	wrapper := newTestAgeWrapper(t)
+	require.NoError(t, s.SetupEncryption(ctx, wrapper))
+
+	count, err := s.ReencryptSecrets(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count, "the plaintext URL and secret should be encrypted")
+
+	sqlStore := s.(*SqlStore)
+	var raw webhookRow
+	require.NoError(t, sqlStore.db.Table("webhooks").Select("url", "secret").Where(idQueryCondition, webhook.ID).Scan(&raw).Error)
+	assert.True(t, crypt.IsEncrypted(raw.URL))
+	assert.True(t, crypt.IsEncrypted(raw.Secret))
+
+	count, err = s.ReencryptSecrets(ctx, false)
+	require.NoError(t, err)
+	assert.Zero(t, count, "the values of the active data key shouldn't be rewritten")
+
+	previousKeyID := crypt.GetCipher().ActiveKeyID()
+	count, err = s.ReencryptSecrets(ctx, true)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.NotEqual(t, previousKeyID, crypt.GetCipher().ActiveKeyID())
+
+	newWrapper := newTestAgeWrapper(t)
+	require.NoError(t, s.RewrapDataKeys(ctx, newWrapper))
+
+	crypt.SetCipher(nil)
+	assert.Error(t, s.SetupEncryption(ctx, wrapper), "the previous key encryption key shouldn't unwrap the data keys")
+	require.NoError(t, s.SetupEncryption(ctx, newWrapper))
+
+	stored, err := s.GetWebhookByID(ctx, LockingStrengthShare, accountID, webhook.ID)
+	require.NoError(t, err)
+	assert.Equal(t, webhook.URL, stored.URL)
+	assert.Equal(t, webhook.Secret, stored.Secret)
+}
+
+type webhookRow struct {
+	URL    string
+	Secret string
+}
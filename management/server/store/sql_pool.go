@@ -0,0 +1,112 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"runtime"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/management/server/types"
+)
+
+const (
+	maxOpenConnsEnv    = "NB_SQL_MAX_OPEN_CONNS"
+	maxIdleConnsEnv    = "NB_SQL_MAX_IDLE_CONNS"
+	connMaxLifetimeEnv = "NB_SQL_CONN_MAX_LIFETIME"
+	connMaxIdleTimeEnv = "NB_SQL_CONN_MAX_IDLE_TIME"
+)
+
+// connectionPoolConfig holds the connection pool settings of a database
+type connectionPoolConfig struct {
+	maxOpenConns    int
+	maxIdleConns    int
+	connMaxLifetime time.Duration
+	connMaxIdleTime time.Duration
+}
+
+// getConnectionPoolConfig reads the connection pool settings from the environment.
+// The idle connections default to the open connections, so the pool isn't drained between request bursts.
+// SQLite is limited to a single connection.
+func getConnectionPoolConfig(ctx context.Context, storeEngine types.Engine) connectionPoolConfig {
+	conns, err := strconv.Atoi(os.Getenv(maxOpenConnsEnv))
+	if err != nil {
+		conns = runtime.NumCPU()
+	}
+
+	if storeEngine == types.SqliteStoreEngine {
+		if err == nil {
+			log.WithContext(ctx).Warnf("setting %s is not supported for sqlite, using default value 1", maxOpenConnsEnv)
+		}
+		return connectionPoolConfig{maxOpenConns: 1, maxIdleConns: 1}
+	}
+
+	config := connectionPoolConfig{maxOpenConns: conns, maxIdleConns: conns}
+
+	if value, ok := os.LookupEnv(maxIdleConnsEnv); ok {
+		idleConns, err := strconv.Atoi(value)
+		if err != nil || idleConns < 0 {
+			log.WithContext(ctx).Warnf("invalid %s value %q, using default value %d", maxIdleConnsEnv, value, conns)
+		} else {
+			config.maxIdleConns = min(idleConns, conns)
+		}
+	}
+
+	config.connMaxLifetime = getDurationEnv(ctx, connMaxLifetimeEnv)
+	config.connMaxIdleTime = getDurationEnv(ctx, connMaxIdleTimeEnv)
+
+	return config
+}
+
+// withStoreConfig overrides the settings with the ones set in the store config. SQLite keeps a single connection.
+func (c connectionPoolConfig) withStoreConfig(storeEngine types.Engine, config types.StoreConfig) connectionPoolConfig {
+	if storeEngine == types.SqliteStoreEngine {
+		return c
+	}
+
+	if config.MaxOpenConns > 0 {
+		c.maxOpenConns = config.MaxOpenConns
+		c.maxIdleConns = min(c.maxIdleConns, c.maxOpenConns)
+	}
+	if config.MaxIdleConns > 0 {
+		c.maxIdleConns = min(config.MaxIdleConns, c.maxOpenConns)
+	}
+	if config.ConnMaxLifetime.Duration > 0 {
+		c.connMaxLifetime = config.ConnMaxLifetime.Duration
+	}
+	if config.ConnMaxIdleTime.Duration > 0 {
+		c.connMaxIdleTime = config.ConnMaxIdleTime.Duration
+	}
+
+	return c
+}
+
+// apply sets the connection pool settings on the database
+func (c connectionPoolConfig) apply(ctx context.Context, db *sql.DB) {
+	db.SetMaxOpenConns(c.maxOpenConns)
+	db.SetMaxIdleConns(c.maxIdleConns)
+	db.SetConnMaxLifetime(c.connMaxLifetime)
+	db.SetConnMaxIdleTime(c.connMaxIdleTime)
+
+	log.WithContext(ctx).Infof("Set max open db connections to %d, max idle connections to %d, max lifetime to %s and max idle time to %s",
+		c.maxOpenConns, c.maxIdleConns, c.connMaxLifetime, c.connMaxIdleTime)
+}
+
+// getDurationEnv parses the duration of the environment variable, 0 (no limit) is returned if it isn't set or invalid
+func getDurationEnv(ctx context.Context, key string) time.Duration {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return 0
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		log.WithContext(ctx).Warnf("invalid %s value %q, the connections won't be limited", key, value)
+		return 0
+	}
+
+	return duration
+}
@@ -0,0 +1,88 @@
+package store
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/netbirdio/netbird/management/server/types"
+	"github.com/netbirdio/netbird/util"
+)
+
+func TestGetConnectionPoolConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		engine   types.Engine
+		env      map[string]string
+		expected connectionPoolConfig
+	}{
+		{
+			name:     "defaults",
+			engine:   types.PostgresStoreEngine,
+			expected: connectionPoolConfig{maxOpenConns: runtime.NumCPU(), maxIdleConns: runtime.NumCPU()},
+		},
+		{
+			name:   "configured",
+			engine: types.PostgresStoreEngine,
+			env: map[string]string{
+				maxOpenConnsEnv:    "20",
+				maxIdleConnsEnv:    "5",
+				connMaxLifetimeEnv: "30m",
+				connMaxIdleTimeEnv: "5m",
+			},
+			expected: connectionPoolConfig{maxOpenConns: 20, maxIdleConns: 5, connMaxLifetime: 30 * time.Minute, connMaxIdleTime: 5 * time.Minute},
+		},
+		{
+			name:     "idle connections limited to open connections",
+			engine:   types.MysqlStoreEngine,
+			env:      map[string]string{maxOpenConnsEnv: "10", maxIdleConnsEnv: "50"},
+			expected: connectionPoolConfig{maxOpenConns: 10, maxIdleConns: 10},
+		},
+		{
+			name:   "invalid values",
+			engine: types.PostgresStoreEngine,
+			env: map[string]string{
+				maxOpenConnsEnv:    "10",
+				maxIdleConnsEnv:    "-1",
+				connMaxLifetimeEnv: "forever",
+				connMaxIdleTimeEnv: "-5m",
+			},
+			expected: connectionPoolConfig{maxOpenConns: 10, maxIdleConns: 10},
+		},
+		{
+			name:     "sqlite",
+			engine:   types.SqliteStoreEngine,
+			env:      map[string]string{maxOpenConnsEnv: "10", connMaxLifetimeEnv: "30m"},
+			expected: connectionPoolConfig{maxOpenConns: 1, maxIdleConns: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			assert.Equal(t, tt.expected, getConnectionPoolConfig(context.Background(), tt.engine))
+		})
+	}
+}
+
+func TestConnectionPoolConfig_WithStoreConfig(t *testing.T) {
+	base := connectionPoolConfig{maxOpenConns: 8, maxIdleConns: 8, connMaxLifetime: time.Hour}
+
+	config := base.withStoreConfig(types.PostgresStoreEngine, types.StoreConfig{
+		MaxOpenConns:    4,
+		ConnMaxIdleTime: util.Duration{Duration: 5 * time.Minute},
+	})
+	assert.Equal(t, connectionPoolConfig{maxOpenConns: 4, maxIdleConns: 4, connMaxLifetime: time.Hour, connMaxIdleTime: 5 * time.Minute}, config)
+
+	config = base.withStoreConfig(types.PostgresStoreEngine, types.StoreConfig{MaxIdleConns: 20})
+	assert.Equal(t, 8, config.maxIdleConns, "idle connections should be limited to open connections")
+
+	config = connectionPoolConfig{maxOpenConns: 1, maxIdleConns: 1}.withStoreConfig(types.SqliteStoreEngine, types.StoreConfig{MaxOpenConns: 10})
+	assert.Equal(t, connectionPoolConfig{maxOpenConns: 1, maxIdleConns: 1}, config)
+}
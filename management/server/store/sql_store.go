@@ -10,7 +10,6 @@ import (
 	"path/filepath"
 	"runtime"
 	"runtime/debug"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -26,14 +25,25 @@ import (
 	"github.com/netbirdio/netbird/management/server/util"
 
 	nbdns "github.com/netbirdio/netbird/dns"
+	accessHistoryTypes "github.com/netbirdio/netbird/management/server/accesshistory/types"
+	connectivityTypes "github.com/netbirdio/netbird/management/server/connectivity/types"
+	debugBundleTypes "github.com/netbirdio/netbird/management/server/debugbundles/types"
+	"github.com/netbirdio/netbird/management/server/labels"
+	monitorTypes "github.com/netbirdio/netbird/management/server/monitors/types"
 	resourceTypes "github.com/netbirdio/netbird/management/server/networks/resources/types"
 	routerTypes "github.com/netbirdio/netbird/management/server/networks/routers/types"
 	networkTypes "github.com/netbirdio/netbird/management/server/networks/types"
 	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+	peerActionTypes "github.com/netbirdio/netbird/management/server/peeractions/types"
 	"github.com/netbirdio/netbird/management/server/posture"
+	roleTypes "github.com/netbirdio/netbird/management/server/roles/types"
+	scimTypes "github.com/netbirdio/netbird/management/server/scim/types"
 	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store/crypt"
 	"github.com/netbirdio/netbird/management/server/telemetry"
 	"github.com/netbirdio/netbird/management/server/types"
+	usageTypes "github.com/netbirdio/netbird/management/server/usage/types"
+	webhookTypes "github.com/netbirdio/netbird/management/server/webhooks/types"
 	"github.com/netbirdio/netbird/route"
 )
 
@@ -51,11 +61,14 @@ const (
 // SqlStore represents an account storage backed by a Sql DB persisted to disk
 type SqlStore struct {
 	db                *gorm.DB
+	replica           *gorm.DB
 	resourceLocks     sync.Map
 	globalAccountLock sync.Mutex
 	metrics           telemetry.AppMetrics
 	installationPK    int
 	storeEngine       types.Engine
+	// keyWrapper wraps the data keys of the store encryption, nil if it isn't set up
+	keyWrapper crypt.KeyWrapper
 }
 
 type installation struct {
@@ -72,21 +85,7 @@ func NewSqlStore(ctx context.Context, db *gorm.DB, storeEngine types.Engine, met
 		return nil, err
 	}
 
-	conns, err := strconv.Atoi(os.Getenv("NB_SQL_MAX_OPEN_CONNS"))
-	if err != nil {
-		conns = runtime.NumCPU()
-	}
-
-	if storeEngine == types.SqliteStoreEngine {
-		if err == nil {
-			log.WithContext(ctx).Warnf("setting NB_SQL_MAX_OPEN_CONNS is not supported for sqlite, using default value 1")
-		}
-		conns = 1
-	}
-
-	sql.SetMaxOpenConns(conns)
-
-	log.WithContext(ctx).Infof("Set max open db connections to %d", conns)
+	getConnectionPoolConfig(ctx, storeEngine).apply(ctx, sql)
 
 	if err := migrate(ctx, db); err != nil {
 		return nil, fmt.Errorf("migrate: %w", err)
@@ -96,11 +95,21 @@ func NewSqlStore(ctx context.Context, db *gorm.DB, storeEngine types.Engine, met
 		&types.Account{}, &types.Policy{}, &types.PolicyRule{}, &route.Route{}, &nbdns.NameServerGroup{},
 		&installation{}, &types.ExtraSettings{}, &posture.Checks{}, &nbpeer.NetworkAddress{},
 		&networkTypes.Network{}, &routerTypes.NetworkRouter{}, &resourceTypes.NetworkResource{},
+		&scimTypes.ProvisionedUser{}, &roleTypes.Role{}, &types.Tenant{},
+		&webhookTypes.Webhook{}, &monitorTypes.Monitor{}, &monitorTypes.Result{}, &usageTypes.Sample{}, &usageTypes.ExitNodeUsage{}, &accessHistoryTypes.Snapshot{},
+		&debugBundleTypes.Request{}, &connectivityTypes.Report{}, &types.Rollout{}, &peerActionTypes.Action{},
+		&crypt.DataKey{}, &types.ResourceRevision{}, &types.IdempotencyKey{},
 	)
 	if err != nil {
 		return nil, fmt.Errorf("auto migrate: %w", err)
 	}
 
+	if storeEngine == types.PostgresStoreEngine {
+		if err := createPostgresIndexes(ctx, db); err != nil {
+			return nil, fmt.Errorf("create indexes: %w", err)
+		}
+	}
+
 	return &SqlStore{db: db, storeEngine: storeEngine, metrics: metrics, installationPK: 1}, nil
 }
 
@@ -179,7 +188,7 @@ func (s *SqlStore) SaveAccount(ctx context.Context, account *types.Account) erro
 
 	generateAccountSQLTypes(account)
 
-	err := s.db.Transaction(func(tx *gorm.DB) error {
+	err := s.getDB(ctx).Transaction(func(tx *gorm.DB) error {
 		result := tx.Select(clause.Associations).Delete(account.Policies, "account_id = ?", account.Id)
 		if result.Error != nil {
 			return result.Error
@@ -258,7 +267,7 @@ func generateAccountSQLTypes(account *types.Account) {
 func (s *SqlStore) checkAccountDomainBeforeSave(ctx context.Context, accountID, newDomain string) {
 	var acc types.Account
 	var domain string
-	result := s.db.Model(&acc).Select("domain").Where(idQueryCondition, accountID).First(&domain)
+	result := s.getDB(ctx).Model(&acc).Select("domain").Where(idQueryCondition, accountID).First(&domain)
 	if result.Error != nil {
 		if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			log.WithContext(ctx).Errorf("error when getting account %s from the store to check domain: %s", accountID, result.Error)
@@ -273,7 +282,7 @@ func (s *SqlStore) checkAccountDomainBeforeSave(ctx context.Context, accountID,
 func (s *SqlStore) DeleteAccount(ctx context.Context, account *types.Account) error {
 	start := time.Now()
 
-	err := s.db.Transaction(func(tx *gorm.DB) error {
+	err := s.getDB(ctx).Transaction(func(tx *gorm.DB) error {
 		result := tx.Select(clause.Associations).Delete(account.Policies, "account_id = ?", account.Id)
 		if result.Error != nil {
 			return result.Error
@@ -323,7 +332,7 @@ func (s *SqlStore) SavePeer(ctx context.Context, lockStrength LockingStrength, a
 	peerCopy := peer.Copy()
 	peerCopy.AccountID = accountID
 
-	err := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Transaction(func(tx *gorm.DB) error {
+	err := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Transaction(func(tx *gorm.DB) error {
 		// check if peer exists before saving
 		var peerID string
 		result := tx.Model(&nbpeer.Peer{}).Select("id").Find(&peerID, accountAndIDQueryCondition, accountID, peer.ID)
@@ -358,7 +367,7 @@ func (s *SqlStore) UpdateAccountDomainAttributes(ctx context.Context, accountID
 	}
 
 	fieldsToUpdate := []string{"domain", "domain_category", "is_domain_primary_account"}
-	result := s.db.Model(&types.Account{}).
+	result := s.getDB(ctx).Model(&types.Account{}).
 		Select(fieldsToUpdate).
 		Where(idQueryCondition, accountID).
 		Updates(&accountCopy)
@@ -379,9 +388,9 @@ func (s *SqlStore) SavePeerStatus(ctx context.Context, lockStrength LockingStren
 
 	fieldsToUpdate := []string{
 		"peer_status_last_seen", "peer_status_connected",
-		"peer_status_login_expired", "peer_status_required_approval",
+		"peer_status_login_expired", "peer_status_requires_approval",
 	}
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Model(&nbpeer.Peer{}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Model(&nbpeer.Peer{}).
 		Select(fieldsToUpdate).
 		Where(accountAndIDQueryCondition, accountID, peerID).
 		Updates(&peerCopy)
@@ -403,7 +412,7 @@ func (s *SqlStore) SavePeerLocation(ctx context.Context, lockStrength LockingStr
 	// updating the struct ensures the correct data format is inserted into the database.
 	peerCopy.Location = peerWithLocation.Location
 
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Model(&nbpeer.Peer{}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Model(&nbpeer.Peer{}).
 		Where(accountAndIDQueryCondition, accountID, peerWithLocation.ID).
 		Updates(peerCopy)
 
@@ -424,7 +433,7 @@ func (s *SqlStore) SaveUsers(ctx context.Context, lockStrength LockingStrength,
 		return nil
 	}
 
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}, clause.OnConflict{UpdateAll: true}).Create(&users)
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}, clause.OnConflict{UpdateAll: true}).Create(&users)
 	if result.Error != nil {
 		log.WithContext(ctx).Errorf("failed to save users to store: %s", result.Error)
 		return status.Errorf(status.Internal, "failed to save users to store")
@@ -434,7 +443,7 @@ func (s *SqlStore) SaveUsers(ctx context.Context, lockStrength LockingStrength,
 
 // SaveUser saves the given user to the database.
 func (s *SqlStore) SaveUser(ctx context.Context, lockStrength LockingStrength, user *types.User) error {
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Save(user)
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Save(user)
 	if result.Error != nil {
 		log.WithContext(ctx).Errorf("failed to save user to store: %s", result.Error)
 		return status.Errorf(status.Internal, "failed to save user to store")
@@ -448,7 +457,7 @@ func (s *SqlStore) SaveGroups(ctx context.Context, lockStrength LockingStrength,
 		return nil
 	}
 
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}, clause.OnConflict{UpdateAll: true}).Create(&groups)
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}, clause.OnConflict{UpdateAll: true}).Create(&groups)
 	if result.Error != nil {
 		return status.Errorf(status.Internal, "failed to save groups to store: %v", result.Error)
 	}
@@ -477,7 +486,7 @@ func (s *SqlStore) GetAccountByPrivateDomain(ctx context.Context, domain string)
 
 func (s *SqlStore) GetAccountIDByPrivateDomain(ctx context.Context, lockStrength LockingStrength, domain string) (string, error) {
 	var accountID string
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Model(&types.Account{}).Select("id").
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Model(&types.Account{}).Select("id").
 		Where("domain = ? and is_domain_primary_account = ? and domain_category = ?",
 			strings.ToLower(domain), true, types.PrivateCategory,
 		).First(&accountID)
@@ -494,7 +503,7 @@ func (s *SqlStore) GetAccountIDByPrivateDomain(ctx context.Context, lockStrength
 
 func (s *SqlStore) GetAccountBySetupKey(ctx context.Context, setupKey string) (*types.Account, error) {
 	var key types.SetupKey
-	result := s.db.Select("account_id").First(&key, GetKeyQueryCondition(s), setupKey)
+	result := s.getDB(ctx).Select("account_id").First(&key, GetKeyQueryCondition(s), setupKey)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, status.NewSetupKeyNotFoundError(setupKey)
@@ -512,7 +521,7 @@ func (s *SqlStore) GetAccountBySetupKey(ctx context.Context, setupKey string) (*
 
 func (s *SqlStore) GetTokenIDByHashedToken(ctx context.Context, hashedToken string) (string, error) {
 	var token types.PersonalAccessToken
-	result := s.db.First(&token, "hashed_token = ?", hashedToken)
+	result := s.getDB(ctx).First(&token, "hashed_token = ?", hashedToken)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return "", status.Errorf(status.NotFound, "account not found: index lookup failed")
@@ -526,7 +535,7 @@ func (s *SqlStore) GetTokenIDByHashedToken(ctx context.Context, hashedToken stri
 
 func (s *SqlStore) GetUserByPATID(ctx context.Context, lockStrength LockingStrength, patID string) (*types.User, error) {
 	var user types.User
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
 		Joins("JOIN personal_access_tokens ON personal_access_tokens.user_id = users.id").
 		Where("personal_access_tokens.id = ?", patID).First(&user)
 	if result.Error != nil {
@@ -542,7 +551,7 @@ func (s *SqlStore) GetUserByPATID(ctx context.Context, lockStrength LockingStren
 
 func (s *SqlStore) GetUserByUserID(ctx context.Context, lockStrength LockingStrength, userID string) (*types.User, error) {
 	var user types.User
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).First(&user, idQueryCondition, userID)
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).First(&user, idQueryCondition, userID)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, status.NewUserNotFoundError(userID)
@@ -554,7 +563,7 @@ func (s *SqlStore) GetUserByUserID(ctx context.Context, lockStrength LockingStre
 }
 
 func (s *SqlStore) DeleteUser(ctx context.Context, lockStrength LockingStrength, accountID, userID string) error {
-	err := s.db.Transaction(func(tx *gorm.DB) error {
+	err := s.getDB(ctx).Transaction(func(tx *gorm.DB) error {
 		result := tx.Clauses(clause.Locking{Strength: string(lockStrength)}).
 			Delete(&types.PersonalAccessToken{}, "user_id = ?", userID)
 		if result.Error != nil {
@@ -574,7 +583,7 @@ func (s *SqlStore) DeleteUser(ctx context.Context, lockStrength LockingStrength,
 
 func (s *SqlStore) GetAccountUsers(ctx context.Context, lockStrength LockingStrength, accountID string) ([]*types.User, error) {
 	var users []*types.User
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Find(&users, accountIDCondition, accountID)
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Find(&users, accountIDCondition, accountID)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, status.Errorf(status.NotFound, "accountID not found: index lookup failed")
@@ -588,7 +597,7 @@ func (s *SqlStore) GetAccountUsers(ctx context.Context, lockStrength LockingStre
 
 func (s *SqlStore) GetAccountOwner(ctx context.Context, lockStrength LockingStrength, accountID string) (*types.User, error) {
 	var user types.User
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).First(&user, "account_id = ? AND role = ?", accountID, types.UserRoleOwner)
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).First(&user, "account_id = ? AND role = ?", accountID, types.UserRoleOwner)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, status.Errorf(status.NotFound, "account owner not found: index lookup failed")
@@ -601,7 +610,7 @@ func (s *SqlStore) GetAccountOwner(ctx context.Context, lockStrength LockingStre
 
 func (s *SqlStore) GetAccountGroups(ctx context.Context, lockStrength LockingStrength, accountID string) ([]*types.Group, error) {
 	var groups []*types.Group
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Find(&groups, accountIDCondition, accountID)
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Find(&groups, accountIDCondition, accountID)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, status.Errorf(status.NotFound, "accountID not found: index lookup failed")
@@ -613,12 +622,39 @@ func (s *SqlStore) GetAccountGroups(ctx context.Context, lockStrength LockingStr
 	return groups, nil
 }
 
+// ListAccountGroups returns the groups of the account matching the filter, sorted and paginated by the options,
+// with the cursor of the next page if there is one.
+func (s *SqlStore) ListAccountGroups(ctx context.Context, lockStrength LockingStrength, accountID string, filter GroupFilter, opts ListOptions) ([]*types.Group, string, error) {
+	query := s.listDB(ctx, lockStrength).Where(accountIDCondition, accountID)
+
+	if filter.Name != "" {
+		query = query.Where("name LIKE ?", "%"+filter.Name+"%")
+	}
+
+	query, _, limit, err := paginate(query, opts, groupSortColumns)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var groups []*types.Group
+	if err := query.Find(&groups).Error; err != nil {
+		log.WithContext(ctx).Errorf("failed to list groups from the store: %s", err)
+		return nil, "", status.Errorf(status.Internal, "failed to list groups from the store")
+	}
+
+	groups, next := nextPage(groups, limit, func(group *types.Group) (string, string) {
+		return group.Name, group.ID
+	})
+
+	return groups, next, nil
+}
+
 func (s *SqlStore) GetResourceGroups(ctx context.Context, lockStrength LockingStrength, accountID, resourceID string) ([]*types.Group, error) {
 	var groups []*types.Group
 
 	likePattern := `%"ID":"` + resourceID + `"%`
 
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
 		Where("resources LIKE ?", likePattern).
 		Find(&groups)
 
@@ -634,7 +670,7 @@ func (s *SqlStore) GetResourceGroups(ctx context.Context, lockStrength LockingSt
 
 func (s *SqlStore) GetAccountsCounter(ctx context.Context) (int64, error) {
 	var count int64
-	result := s.db.Model(&types.Account{}).Count(&count)
+	result := s.getDB(ctx).Model(&types.Account{}).Count(&count)
 	if result.Error != nil {
 		return 0, fmt.Errorf("failed to get all accounts counter: %w", result.Error)
 	}
@@ -644,7 +680,7 @@ func (s *SqlStore) GetAccountsCounter(ctx context.Context) (int64, error) {
 
 func (s *SqlStore) GetAllAccounts(ctx context.Context) (all []*types.Account) {
 	var accounts []types.Account
-	result := s.db.Find(&accounts)
+	result := s.getDB(ctx).Find(&accounts)
 	if result.Error != nil {
 		return all
 	}
@@ -668,7 +704,7 @@ func (s *SqlStore) GetAccount(ctx context.Context, accountID string) (*types.Acc
 	}()
 
 	var account types.Account
-	result := s.db.Model(&account).
+	result := s.getDB(ctx).Model(&account).
 		Preload("UsersG.PATsG"). // have to be specifies as this is nester reference
 		Preload(clause.Associations).
 		First(&account, idQueryCondition, accountID)
@@ -683,7 +719,7 @@ func (s *SqlStore) GetAccount(ctx context.Context, accountID string) (*types.Acc
 	// we have to manually preload policy rules as it seems that gorm preloading doesn't do it for us
 	for i, policy := range account.Policies {
 		var rules []*types.PolicyRule
-		err := s.db.Model(&types.PolicyRule{}).Find(&rules, "policy_id = ?", policy.ID).Error
+		err := s.getDB(ctx).Model(&types.PolicyRule{}).Find(&rules, "policy_id = ?", policy.ID).Error
 		if err != nil {
 			return nil, status.Errorf(status.NotFound, "rule not found")
 		}
@@ -735,7 +771,7 @@ func (s *SqlStore) GetAccount(ctx context.Context, accountID string) (*types.Acc
 
 func (s *SqlStore) GetAccountByUser(ctx context.Context, userID string) (*types.Account, error) {
 	var user types.User
-	result := s.db.Select("account_id").First(&user, idQueryCondition, userID)
+	result := s.getDB(ctx).Select("account_id").First(&user, idQueryCondition, userID)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, status.Errorf(status.NotFound, "account not found: index lookup failed")
@@ -752,7 +788,7 @@ func (s *SqlStore) GetAccountByUser(ctx context.Context, userID string) (*types.
 
 func (s *SqlStore) GetAccountByPeerID(ctx context.Context, peerID string) (*types.Account, error) {
 	var peer nbpeer.Peer
-	result := s.db.Select("account_id").First(&peer, idQueryCondition, peerID)
+	result := s.getDB(ctx).Select("account_id").First(&peer, idQueryCondition, peerID)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, status.Errorf(status.NotFound, "account not found: index lookup failed")
@@ -769,7 +805,7 @@ func (s *SqlStore) GetAccountByPeerID(ctx context.Context, peerID string) (*type
 
 func (s *SqlStore) GetAccountByPeerPubKey(ctx context.Context, peerKey string) (*types.Account, error) {
 	var peer nbpeer.Peer
-	result := s.db.Select("account_id").First(&peer, GetKeyQueryCondition(s), peerKey)
+	result := s.getDB(ctx).Select("account_id").First(&peer, GetKeyQueryCondition(s), peerKey)
 
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -788,7 +824,7 @@ func (s *SqlStore) GetAccountByPeerPubKey(ctx context.Context, peerKey string) (
 func (s *SqlStore) GetAccountIDByPeerPubKey(ctx context.Context, peerKey string) (string, error) {
 	var peer nbpeer.Peer
 	var accountID string
-	result := s.db.Model(&peer).Select("account_id").Where(GetKeyQueryCondition(s), peerKey).First(&accountID)
+	result := s.getDB(ctx).Model(&peer).Select("account_id").Where(GetKeyQueryCondition(s), peerKey).First(&accountID)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return "", status.Errorf(status.NotFound, "account not found: index lookup failed")
@@ -801,7 +837,7 @@ func (s *SqlStore) GetAccountIDByPeerPubKey(ctx context.Context, peerKey string)
 
 func (s *SqlStore) GetAccountIDByUserID(ctx context.Context, lockStrength LockingStrength, userID string) (string, error) {
 	var accountID string
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Model(&types.User{}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Model(&types.User{}).
 		Select("account_id").Where(idQueryCondition, userID).First(&accountID)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -815,7 +851,7 @@ func (s *SqlStore) GetAccountIDByUserID(ctx context.Context, lockStrength Lockin
 
 func (s *SqlStore) GetAccountIDByPeerID(ctx context.Context, lockStrength LockingStrength, peerID string) (string, error) {
 	var accountID string
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Model(&nbpeer.Peer{}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Model(&nbpeer.Peer{}).
 		Select("account_id").Where(idQueryCondition, peerID).First(&accountID)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -829,7 +865,7 @@ func (s *SqlStore) GetAccountIDByPeerID(ctx context.Context, lockStrength Lockin
 
 func (s *SqlStore) GetAccountIDBySetupKey(ctx context.Context, setupKey string) (string, error) {
 	var accountID string
-	result := s.db.Model(&types.SetupKey{}).Select("account_id").Where(GetKeyQueryCondition(s), setupKey).First(&accountID)
+	result := s.getDB(ctx).Model(&types.SetupKey{}).Select("account_id").Where(GetKeyQueryCondition(s), setupKey).First(&accountID)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return "", status.NewSetupKeyNotFoundError(setupKey)
@@ -849,7 +885,7 @@ func (s *SqlStore) GetTakenIPs(ctx context.Context, lockStrength LockingStrength
 	var ipJSONStrings []string
 
 	// Fetch the IP addresses as JSON strings
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Model(&nbpeer.Peer{}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Model(&nbpeer.Peer{}).
 		Where("account_id = ?", accountID).
 		Pluck("ip", &ipJSONStrings)
 	if result.Error != nil {
@@ -872,9 +908,10 @@ func (s *SqlStore) GetTakenIPs(ctx context.Context, lockStrength LockingStrength
 	return ips, nil
 }
 
+// GetPeerLabelsInAccount retrieves the DNS labels and aliases of the peers of an account.
 func (s *SqlStore) GetPeerLabelsInAccount(ctx context.Context, lockStrength LockingStrength, accountID string) ([]string, error) {
 	var labels []string
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Model(&nbpeer.Peer{}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Model(&nbpeer.Peer{}).
 		Where("account_id = ?", accountID).
 		Pluck("dns_label", &labels)
 
@@ -886,12 +923,27 @@ func (s *SqlStore) GetPeerLabelsInAccount(ctx context.Context, lockStrength Lock
 		return nil, status.Errorf(status.Internal, "issue getting dns labels from store: %s", result.Error)
 	}
 
+	// the DNS aliases of the peers are taken too
+	var peersWithAliases []*nbpeer.Peer
+	result = s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Model(&nbpeer.Peer{}).
+		Select("dns_aliases").
+		Where("account_id = ? AND dns_aliases IS NOT NULL", accountID).
+		Find(&peersWithAliases)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("error when getting dns aliases from the store: %s", result.Error)
+		return nil, status.Errorf(status.Internal, "issue getting dns aliases from store: %s", result.Error)
+	}
+
+	for _, peer := range peersWithAliases {
+		labels = append(labels, peer.DNSAliases...)
+	}
+
 	return labels, nil
 }
 
 func (s *SqlStore) GetAccountNetwork(ctx context.Context, lockStrength LockingStrength, accountID string) (*types.Network, error) {
 	var accountNetwork types.AccountNetwork
-	if err := s.db.Model(&types.Account{}).Where(idQueryCondition, accountID).First(&accountNetwork).Error; err != nil {
+	if err := s.getDB(ctx).Model(&types.Account{}).Where(idQueryCondition, accountID).First(&accountNetwork).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, status.NewAccountNotFoundError(accountID)
 		}
@@ -900,9 +952,30 @@ func (s *SqlStore) GetAccountNetwork(ctx context.Context, lockStrength LockingSt
 	return accountNetwork.Network, nil
 }
 
+// SaveAccountNetwork updates the network range, the reserved ranges and the serial of the account network.
+func (s *SqlStore) SaveAccountNetwork(ctx context.Context, lockStrength LockingStrength, accountID string, network *types.Network) error {
+	accountCopy := types.Account{Network: network}
+
+	fieldsToUpdate := []string{"network_net", "network_reserved_ranges", "network_serial"}
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Model(&types.Account{}).
+		Select(fieldsToUpdate).
+		Where(idQueryCondition, accountID).
+		Updates(&accountCopy)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to save account network to store: %v", result.Error)
+		return status.Errorf(status.Internal, "failed to save account network to store")
+	}
+
+	if result.RowsAffected == 0 {
+		return status.NewAccountNotFoundError(accountID)
+	}
+
+	return nil
+}
+
 func (s *SqlStore) GetPeerByPeerPubKey(ctx context.Context, lockStrength LockingStrength, peerKey string) (*nbpeer.Peer, error) {
 	var peer nbpeer.Peer
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).First(&peer, GetKeyQueryCondition(s), peerKey)
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).First(&peer, GetKeyQueryCondition(s), peerKey)
 
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -916,7 +989,7 @@ func (s *SqlStore) GetPeerByPeerPubKey(ctx context.Context, lockStrength Locking
 
 func (s *SqlStore) GetAccountSettings(ctx context.Context, lockStrength LockingStrength, accountID string) (*types.Settings, error) {
 	var accountSettings types.AccountSettings
-	if err := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Model(&types.Account{}).Where(idQueryCondition, accountID).First(&accountSettings).Error; err != nil {
+	if err := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Model(&types.Account{}).Where(idQueryCondition, accountID).First(&accountSettings).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, status.Errorf(status.NotFound, "settings not found")
 		}
@@ -927,7 +1000,7 @@ func (s *SqlStore) GetAccountSettings(ctx context.Context, lockStrength LockingS
 
 func (s *SqlStore) GetAccountCreatedBy(ctx context.Context, lockStrength LockingStrength, accountID string) (string, error) {
 	var createdBy string
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Model(&types.Account{}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Model(&types.Account{}).
 		Select("created_by").First(&createdBy, idQueryCondition, accountID)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -942,7 +1015,7 @@ func (s *SqlStore) GetAccountCreatedBy(ctx context.Context, lockStrength Locking
 // SaveUserLastLogin stores the last login time for a user in DB.
 func (s *SqlStore) SaveUserLastLogin(ctx context.Context, accountID, userID string, lastLogin time.Time) error {
 	var user types.User
-	result := s.db.First(&user, accountAndIDQueryCondition, accountID, userID)
+	result := s.getDB(ctx).First(&user, accountAndIDQueryCondition, accountID, userID)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return status.NewUserNotFoundError(userID)
@@ -952,7 +1025,7 @@ func (s *SqlStore) SaveUserLastLogin(ctx context.Context, accountID, userID stri
 
 	if !lastLogin.IsZero() {
 		user.LastLogin = &lastLogin
-		return s.db.Save(&user).Error
+		return s.getDB(ctx).Save(&user).Error
 	}
 
 	return nil
@@ -975,6 +1048,16 @@ func (s *SqlStore) GetPostureCheckByChecksDefinition(accountID string, checks *p
 
 // Close closes the underlying DB connection
 func (s *SqlStore) Close(_ context.Context) error {
+	if s.replica != nil {
+		replica, err := s.replica.DB()
+		if err != nil {
+			return fmt.Errorf("get replica db: %w", err)
+		}
+		if err = replica.Close(); err != nil {
+			return fmt.Errorf("close replica db: %w", err)
+		}
+	}
+
 	sql, err := s.db.DB()
 	if err != nil {
 		return fmt.Errorf("get db: %w", err)
@@ -982,6 +1065,24 @@ func (s *SqlStore) Close(_ context.Context) error {
 	return sql.Close()
 }
 
+// SetConnectionPool applies the connection pool settings of the store config to the database and its read replica
+func (s *SqlStore) SetConnectionPool(ctx context.Context, config types.StoreConfig) error {
+	poolConfig := getConnectionPoolConfig(ctx, s.storeEngine).withStoreConfig(s.storeEngine, config)
+
+	for _, db := range []*gorm.DB{s.db, s.replica} {
+		if db == nil {
+			continue
+		}
+		sql, err := db.DB()
+		if err != nil {
+			return fmt.Errorf("get db: %w", err)
+		}
+		poolConfig.apply(ctx, sql)
+	}
+
+	return nil
+}
+
 // GetStoreEngine returns underlying store engine
 func (s *SqlStore) GetStoreEngine() types.Engine {
 	return s.storeEngine
@@ -1011,7 +1112,20 @@ func NewPostgresqlStore(ctx context.Context, dsn string, metrics telemetry.AppMe
 		return nil, err
 	}
 
-	return NewSqlStore(ctx, db, types.PostgresStoreEngine, metrics)
+	store, err := NewSqlStore(ctx, db, types.PostgresStoreEngine, metrics)
+	if err != nil {
+		return nil, err
+	}
+
+	if replicaDsn := os.Getenv(postgresReplicaDsnEnv); replicaDsn != "" {
+		store.replica, err = openPostgresReplica(ctx, replicaDsn)
+		if err != nil {
+			_ = store.Close(ctx)
+			return nil, fmt.Errorf("open read replica: %w", err)
+		}
+	}
+
+	return store, nil
 }
 
 // NewMysqlStore creates a new MySQL store.
@@ -1124,7 +1238,7 @@ func NewMysqlStoreFromSqlStore(ctx context.Context, sqliteStore *SqlStore, dsn s
 
 func (s *SqlStore) GetSetupKeyBySecret(ctx context.Context, lockStrength LockingStrength, key string) (*types.SetupKey, error) {
 	var setupKey types.SetupKey
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
 		First(&setupKey, GetKeyQueryCondition(s), key)
 
 	if result.Error != nil {
@@ -1138,7 +1252,7 @@ func (s *SqlStore) GetSetupKeyBySecret(ctx context.Context, lockStrength Locking
 }
 
 func (s *SqlStore) IncrementSetupKeyUsage(ctx context.Context, setupKeyID string) error {
-	result := s.db.Model(&types.SetupKey{}).
+	result := s.getDB(ctx).Model(&types.SetupKey{}).
 		Where(idQueryCondition, setupKeyID).
 		Updates(map[string]interface{}{
 			"used_times": gorm.Expr("used_times + 1"),
@@ -1159,7 +1273,7 @@ func (s *SqlStore) IncrementSetupKeyUsage(ctx context.Context, setupKeyID string
 // AddPeerToAllGroup adds a peer to the 'All' group. Method always needs to run in a transaction
 func (s *SqlStore) AddPeerToAllGroup(ctx context.Context, lockStrength LockingStrength, accountID string, peerID string) error {
 	var group types.Group
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
 		First(&group, "account_id = ? AND name = ?", accountID, "All")
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -1176,7 +1290,7 @@ func (s *SqlStore) AddPeerToAllGroup(ctx context.Context, lockStrength LockingSt
 
 	group.Peers = append(group.Peers, peerID)
 
-	if err := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Save(&group).Error; err != nil {
+	if err := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Save(&group).Error; err != nil {
 		return status.Errorf(status.Internal, "issue updating group 'All': %s", err)
 	}
 
@@ -1186,7 +1300,7 @@ func (s *SqlStore) AddPeerToAllGroup(ctx context.Context, lockStrength LockingSt
 // AddPeerToGroup adds a peer to a group. Method always needs to run in a transaction
 func (s *SqlStore) AddPeerToGroup(ctx context.Context, lockStrength LockingStrength, accountId string, peerId string, groupID string) error {
 	var group types.Group
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Where(accountAndIDQueryCondition, accountId, groupID).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Where(accountAndIDQueryCondition, accountId, groupID).
 		First(&group)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -1204,7 +1318,7 @@ func (s *SqlStore) AddPeerToGroup(ctx context.Context, lockStrength LockingStren
 
 	group.Peers = append(group.Peers, peerId)
 
-	if err := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Save(&group).Error; err != nil {
+	if err := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Save(&group).Error; err != nil {
 		return status.Errorf(status.Internal, "issue updating group: %s", err)
 	}
 
@@ -1214,7 +1328,7 @@ func (s *SqlStore) AddPeerToGroup(ctx context.Context, lockStrength LockingStren
 // AddResourceToGroup adds a resource to a group. Method always needs to run n a transaction
 func (s *SqlStore) AddResourceToGroup(ctx context.Context, accountId string, groupID string, resource *types.Resource) error {
 	var group types.Group
-	result := s.db.Where(accountAndIDQueryCondition, accountId, groupID).First(&group)
+	result := s.getDB(ctx).Where(accountAndIDQueryCondition, accountId, groupID).First(&group)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return status.NewGroupNotFoundError(groupID)
@@ -1231,7 +1345,7 @@ func (s *SqlStore) AddResourceToGroup(ctx context.Context, accountId string, gro
 
 	group.Resources = append(group.Resources, *resource)
 
-	if err := s.db.Save(&group).Error; err != nil {
+	if err := s.getDB(ctx).Save(&group).Error; err != nil {
 		return status.Errorf(status.Internal, "issue updating group: %s", err)
 	}
 
@@ -1241,7 +1355,7 @@ func (s *SqlStore) AddResourceToGroup(ctx context.Context, accountId string, gro
 // RemoveResourceFromGroup removes a resource from a group. Method always needs to run in a transaction
 func (s *SqlStore) RemoveResourceFromGroup(ctx context.Context, accountId string, groupID string, resourceID string) error {
 	var group types.Group
-	result := s.db.Where(accountAndIDQueryCondition, accountId, groupID).First(&group)
+	result := s.getDB(ctx).Where(accountAndIDQueryCondition, accountId, groupID).First(&group)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return status.NewGroupNotFoundError(groupID)
@@ -1257,7 +1371,7 @@ func (s *SqlStore) RemoveResourceFromGroup(ctx context.Context, accountId string
 		}
 	}
 
-	if err := s.db.Save(&group).Error; err != nil {
+	if err := s.getDB(ctx).Save(&group).Error; err != nil {
 		return status.Errorf(status.Internal, "issue updating group: %s", err)
 	}
 
@@ -1267,7 +1381,7 @@ func (s *SqlStore) RemoveResourceFromGroup(ctx context.Context, accountId string
 // GetPeerGroups retrieves all groups assigned to a specific peer in a given account.
 func (s *SqlStore) GetPeerGroups(ctx context.Context, lockStrength LockingStrength, accountId string, peerId string) ([]*types.Group, error) {
 	var groups []*types.Group
-	query := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).
+	query := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
 		Find(&groups, "account_id = ? AND peers LIKE ?", accountId, fmt.Sprintf(`%%"%s"%%`, peerId))
 
 	if query.Error != nil {
@@ -1280,7 +1394,7 @@ func (s *SqlStore) GetPeerGroups(ctx context.Context, lockStrength LockingStreng
 // GetAccountPeers retrieves peers for an account.
 func (s *SqlStore) GetAccountPeers(ctx context.Context, lockStrength LockingStrength, accountID, nameFilter, ipFilter string) ([]*nbpeer.Peer, error) {
 	var peers []*nbpeer.Peer
-	query := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Where(accountIDCondition, accountID)
+	query := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Where(accountIDCondition, accountID)
 
 	if nameFilter != "" {
 		query = query.Where("name LIKE ?", "%"+nameFilter+"%")
@@ -1297,6 +1411,91 @@ func (s *SqlStore) GetAccountPeers(ctx context.Context, lockStrength LockingStre
 	return peers, nil
 }
 
+// ListAccountPeers returns the peers of the account matching the filter, sorted and paginated by the options,
+// with the cursor of the next page if there is one.
+func (s *SqlStore) ListAccountPeers(ctx context.Context, lockStrength LockingStrength, accountID string, filter PeerFilter, opts ListOptions) ([]*nbpeer.Peer, string, error) {
+	query := s.listDB(ctx, lockStrength).Where(accountIDCondition, accountID)
+
+	if filter.Name != "" {
+		query = query.Where("name LIKE ?", "%"+filter.Name+"%")
+	}
+	if filter.IP != "" {
+		query = query.Where("ip LIKE ?", "%"+filter.IP+"%")
+	}
+	if filter.OS != "" {
+		query = query.Where("meta_go_os = ?", filter.OS)
+	}
+	if filter.Connected != nil {
+		query = query.Where("peer_status_connected = ?", *filter.Connected)
+	}
+	if filter.UserID != "" {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.SerialNumber != "" {
+		query = query.Where("meta_system_serial_number = ?", filter.SerialNumber)
+	}
+	if filter.Model != "" {
+		query = query.Where("meta_system_product_name LIKE ?", "%"+filter.Model+"%")
+	}
+	if filter.KernelVersion != "" {
+		query = query.Where("meta_kernel_version LIKE ?", filter.KernelVersion+"%")
+	}
+	if filter.Version != "" {
+		query = query.Where("meta_wt_version LIKE ?", filter.Version+"%")
+	}
+	if filter.MinUptime > 0 {
+		query = query.Where("meta_boot_time > ? AND meta_boot_time <= ?", time.Time{}, time.Now().UTC().Add(-filter.MinUptime))
+	}
+	for key, value := range filter.Labels {
+		// the labels are stored as JSON and can't contain the LIKE wildcards
+		query = query.Where("labels LIKE ?", "%"+labels.JSONFragment(key, value)+"%")
+	}
+	if filter.IDs != nil {
+		if len(filter.IDs) == 0 {
+			return []*nbpeer.Peer{}, "", nil
+		}
+		query = query.Where("id IN ?", filter.IDs)
+	}
+	if filter.GroupID != "" {
+		group, err := s.GetGroupByID(ctx, lockStrength, accountID, filter.GroupID)
+		if err != nil {
+			return nil, "", err
+		}
+		if len(group.Peers) == 0 {
+			return []*nbpeer.Peer{}, "", nil
+		}
+		query = query.Where("id IN ?", group.Peers)
+	}
+
+	query, column, limit, err := paginate(query, opts, peerSortColumns)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var peers []*nbpeer.Peer
+	if err := query.Find(&peers).Error; err != nil {
+		log.WithContext(ctx).Errorf("failed to list peers from the store: %s", err)
+		return nil, "", status.Errorf(status.Internal, "failed to list peers from store")
+	}
+
+	peers, next := nextPage(peers, limit, func(peer *nbpeer.Peer) (string, string) {
+		switch column.name {
+		case "meta_go_os":
+			return peer.Meta.GoOS, peer.ID
+		case "peer_status_last_seen":
+			var lastSeen time.Time
+			if peer.Status != nil {
+				lastSeen = peer.Status.LastSeen
+			}
+			return lastSeen.Format(time.RFC3339Nano), peer.ID
+		default:
+			return peer.Name, peer.ID
+		}
+	})
+
+	return peers, next, nil
+}
+
 // GetUserPeers retrieves peers for a user.
 func (s *SqlStore) GetUserPeers(ctx context.Context, lockStrength LockingStrength, accountID, userID string) ([]*nbpeer.Peer, error) {
 	var peers []*nbpeer.Peer
@@ -1306,7 +1505,7 @@ func (s *SqlStore) GetUserPeers(ctx context.Context, lockStrength LockingStrengt
 		return peers, nil
 	}
 
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
 		Find(&peers, "account_id = ? AND user_id = ?", accountID, userID)
 	if err := result.Error; err != nil {
 		log.WithContext(ctx).Errorf("failed to get peers from the store: %s", err)
@@ -1317,7 +1516,7 @@ func (s *SqlStore) GetUserPeers(ctx context.Context, lockStrength LockingStrengt
 }
 
 func (s *SqlStore) AddPeerToAccount(ctx context.Context, lockStrength LockingStrength, peer *nbpeer.Peer) error {
-	if err := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Create(peer).Error; err != nil {
+	if err := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Create(peer).Error; err != nil {
 		return status.Errorf(status.Internal, "issue adding peer to account: %s", err)
 	}
 
@@ -1327,7 +1526,7 @@ func (s *SqlStore) AddPeerToAccount(ctx context.Context, lockStrength LockingStr
 // GetPeerByID retrieves a peer by its ID and account ID.
 func (s *SqlStore) GetPeerByID(ctx context.Context, lockStrength LockingStrength, accountID, peerID string) (*nbpeer.Peer, error) {
 	var peer *nbpeer.Peer
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
 		First(&peer, accountAndIDQueryCondition, accountID, peerID)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -1342,7 +1541,7 @@ func (s *SqlStore) GetPeerByID(ctx context.Context, lockStrength LockingStrength
 // GetPeersByIDs retrieves peers by their IDs and account ID.
 func (s *SqlStore) GetPeersByIDs(ctx context.Context, lockStrength LockingStrength, accountID string, peerIDs []string) (map[string]*nbpeer.Peer, error) {
 	var peers []*nbpeer.Peer
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Find(&peers, accountAndIDsQueryCondition, accountID, peerIDs)
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Find(&peers, accountAndIDsQueryCondition, accountID, peerIDs)
 	if result.Error != nil {
 		log.WithContext(ctx).Errorf("failed to get peers by ID's from the store: %s", result.Error)
 		return nil, status.Errorf(status.Internal, "failed to get peers by ID's from the store")
@@ -1359,7 +1558,7 @@ func (s *SqlStore) GetPeersByIDs(ctx context.Context, lockStrength LockingStreng
 // GetAccountPeersWithExpiration retrieves a list of peers that have login expiration enabled and added by a user.
 func (s *SqlStore) GetAccountPeersWithExpiration(ctx context.Context, lockStrength LockingStrength, accountID string) ([]*nbpeer.Peer, error) {
 	var peers []*nbpeer.Peer
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
 		Where("login_expiration_enabled = ? AND user_id IS NOT NULL AND user_id != ''", true).
 		Find(&peers, accountIDCondition, accountID)
 	if err := result.Error; err != nil {
@@ -1373,7 +1572,7 @@ func (s *SqlStore) GetAccountPeersWithExpiration(ctx context.Context, lockStreng
 // GetAccountPeersWithInactivity retrieves a list of peers that have login expiration enabled and added by a user.
 func (s *SqlStore) GetAccountPeersWithInactivity(ctx context.Context, lockStrength LockingStrength, accountID string) ([]*nbpeer.Peer, error) {
 	var peers []*nbpeer.Peer
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
 		Where("inactivity_expiration_enabled = ? AND user_id IS NOT NULL AND user_id != ''", true).
 		Find(&peers, accountIDCondition, accountID)
 	if err := result.Error; err != nil {
@@ -1387,7 +1586,7 @@ func (s *SqlStore) GetAccountPeersWithInactivity(ctx context.Context, lockStreng
 // GetAllEphemeralPeers retrieves all peers with Ephemeral set to true across all accounts, optimized for batch processing.
 func (s *SqlStore) GetAllEphemeralPeers(ctx context.Context, lockStrength LockingStrength) ([]*nbpeer.Peer, error) {
 	var allEphemeralPeers, batchPeers []*nbpeer.Peer
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
 		Where("ephemeral = ?", true).
 		FindInBatches(&batchPeers, 1000, func(tx *gorm.DB, batch int) error {
 			allEphemeralPeers = append(allEphemeralPeers, batchPeers...)
@@ -1402,9 +1601,23 @@ func (s *SqlStore) GetAllEphemeralPeers(ctx context.Context, lockStrength Lockin
 	return allEphemeralPeers, nil
 }
 
+// GetAllGroupsWithEphemeralPolicy retrieves the groups of all accounts with an ephemeral policy.
+func (s *SqlStore) GetAllGroupsWithEphemeralPolicy(ctx context.Context, lockStrength LockingStrength) ([]*types.Group, error) {
+	var groups []*types.Group
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
+		Where("ephemeral_policy IS NOT NULL AND ephemeral_policy != ?", "null").
+		Find(&groups)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to retrieve groups with ephemeral policy: %s", result.Error)
+		return nil, status.Errorf(status.Internal, "failed to retrieve groups with ephemeral policy")
+	}
+
+	return groups, nil
+}
+
 // DeletePeer removes a peer from the store.
 func (s *SqlStore) DeletePeer(ctx context.Context, lockStrength LockingStrength, accountID string, peerID string) error {
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
 		Delete(&nbpeer.Peer{}, accountAndIDQueryCondition, accountID, peerID)
 	if err := result.Error; err != nil {
 		log.WithContext(ctx).Errorf("failed to delete peer from the store: %s", err)
@@ -1419,7 +1632,7 @@ func (s *SqlStore) DeletePeer(ctx context.Context, lockStrength LockingStrength,
 }
 
 func (s *SqlStore) IncrementNetworkSerial(ctx context.Context, lockStrength LockingStrength, accountId string) error {
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
 		Model(&types.Account{}).Where(idQueryCondition, accountId).Update("network_serial", gorm.Expr("network_serial + 1"))
 	if result.Error != nil {
 		log.WithContext(ctx).Errorf("failed to increment network serial count in store: %v", result.Error)
@@ -1429,6 +1642,14 @@ func (s *SqlStore) IncrementNetworkSerial(ctx context.Context, lockStrength Lock
 }
 
 func (s *SqlStore) ExecuteInTransaction(ctx context.Context, operation func(store Store) error) error {
+	if batch := batchFromContext(ctx); batch != nil {
+		if db := batch.conn(); db != nil {
+			return batch.savepoint(db, func(tx *gorm.DB) error {
+				return operation(s.withTx(tx))
+			})
+		}
+	}
+
 	startTime := time.Now()
 	tx := s.db.Begin()
 	if tx.Error != nil {
@@ -1451,6 +1672,56 @@ func (s *SqlStore) ExecuteInTransaction(ctx context.Context, operation func(stor
 	return err
 }
 
+// ExecuteInBatchTransaction runs the operation in a single transaction joined by every store call made with the
+// context passed to the operation, the transactions started by the calls are savepoints of it. The changes are
+// committed if the operation succeeds and rolled back otherwise, the calls deferred with RunAfterCommit run once they
+// are committed. A batch started within another one joins it.
+func (s *SqlStore) ExecuteInBatchTransaction(ctx context.Context, operation func(ctx context.Context) error) error {
+	if InBatchTransaction(ctx) {
+		return operation(ctx)
+	}
+
+	startTime := time.Now()
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	batch := &batchTransaction{db: tx}
+	err := operation(context.WithValue(ctx, batchContextKey{}, batch))
+	afterCommit := batch.finish()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return err
+	}
+
+	log.WithContext(ctx).Tracef("batch transaction took %v", time.Since(startTime))
+	if s.metrics != nil {
+		s.metrics.StoreMetrics().CountTransactionDuration(time.Since(startTime))
+	}
+
+	for _, fn := range afterCommit {
+		fn()
+	}
+
+	return nil
+}
+
+// getDB returns the database the queries made with the ctx run on, the transaction of the batch the ctx belongs to
+// if there is one
+func (s *SqlStore) getDB(ctx context.Context) *gorm.DB {
+	if batch := batchFromContext(ctx); batch != nil {
+		if db := batch.conn(); db != nil {
+			return db
+		}
+	}
+	return s.db
+}
+
 func (s *SqlStore) withTx(tx *gorm.DB) Store {
 	return &SqlStore{
 		db:          tx,
@@ -1464,7 +1735,7 @@ func (s *SqlStore) GetDB() *gorm.DB {
 
 func (s *SqlStore) GetAccountDNSSettings(ctx context.Context, lockStrength LockingStrength, accountID string) (*types.DNSSettings, error) {
 	var accountDNSSettings types.AccountDNSSettings
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Model(&types.Account{}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Model(&types.Account{}).
 		First(&accountDNSSettings, idQueryCondition, accountID)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -1479,7 +1750,7 @@ func (s *SqlStore) GetAccountDNSSettings(ctx context.Context, lockStrength Locki
 // AccountExists checks whether an account exists by the given ID.
 func (s *SqlStore) AccountExists(ctx context.Context, lockStrength LockingStrength, id string) (bool, error) {
 	var accountID string
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Model(&types.Account{}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Model(&types.Account{}).
 		Select("id").First(&accountID, idQueryCondition, id)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -1494,7 +1765,7 @@ func (s *SqlStore) AccountExists(ctx context.Context, lockStrength LockingStreng
 // GetAccountDomainAndCategory retrieves the Domain and DomainCategory fields for an account based on the given accountID.
 func (s *SqlStore) GetAccountDomainAndCategory(ctx context.Context, lockStrength LockingStrength, accountID string) (string, string, error) {
 	var account types.Account
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Model(&types.Account{}).Select("domain", "domain_category").
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Model(&types.Account{}).Select("domain", "domain_category").
 		Where(idQueryCondition, accountID).First(&account)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -1509,7 +1780,7 @@ func (s *SqlStore) GetAccountDomainAndCategory(ctx context.Context, lockStrength
 // GetGroupByID retrieves a group by ID and account ID.
 func (s *SqlStore) GetGroupByID(ctx context.Context, lockStrength LockingStrength, accountID, groupID string) (*types.Group, error) {
 	var group *types.Group
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).First(&group, accountAndIDQueryCondition, accountID, groupID)
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).First(&group, accountAndIDQueryCondition, accountID, groupID)
 	if err := result.Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, status.NewGroupNotFoundError(groupID)
@@ -1527,7 +1798,7 @@ func (s *SqlStore) GetGroupByName(ctx context.Context, lockStrength LockingStren
 
 	// TODO: This fix is accepted for now, but if we need to handle this more frequently
 	// we may need to reconsider changing the types.
-	query := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Preload(clause.Associations)
+	query := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Preload(clause.Associations)
 
 	switch s.storeEngine {
 	case types.PostgresStoreEngine:
@@ -1552,7 +1823,7 @@ func (s *SqlStore) GetGroupByName(ctx context.Context, lockStrength LockingStren
 // GetGroupsByIDs retrieves groups by their IDs and account ID.
 func (s *SqlStore) GetGroupsByIDs(ctx context.Context, lockStrength LockingStrength, accountID string, groupIDs []string) (map[string]*types.Group, error) {
 	var groups []*types.Group
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Find(&groups, accountAndIDsQueryCondition, accountID, groupIDs)
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Find(&groups, accountAndIDsQueryCondition, accountID, groupIDs)
 	if result.Error != nil {
 		log.WithContext(ctx).Errorf("failed to get groups by ID's from store: %s", result.Error)
 		return nil, status.Errorf(status.Internal, "failed to get groups by ID's from store")
@@ -1568,7 +1839,7 @@ func (s *SqlStore) GetGroupsByIDs(ctx context.Context, lockStrength LockingStren
 
 // SaveGroup saves a group to the store.
 func (s *SqlStore) SaveGroup(ctx context.Context, lockStrength LockingStrength, group *types.Group) error {
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Save(group)
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Save(group)
 	if result.Error != nil {
 		log.WithContext(ctx).Errorf("failed to save group to store: %v", result.Error)
 		return status.Errorf(status.Internal, "failed to save group to store")
@@ -1578,7 +1849,7 @@ func (s *SqlStore) SaveGroup(ctx context.Context, lockStrength LockingStrength,
 
 // DeleteGroup deletes a group from the database.
 func (s *SqlStore) DeleteGroup(ctx context.Context, lockStrength LockingStrength, accountID, groupID string) error {
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
 		Delete(&types.Group{}, accountAndIDQueryCondition, accountID, groupID)
 	if err := result.Error; err != nil {
 		log.WithContext(ctx).Errorf("failed to delete group from store: %s", result.Error)
@@ -1594,7 +1865,7 @@ func (s *SqlStore) DeleteGroup(ctx context.Context, lockStrength LockingStrength
 
 // DeleteGroups deletes groups from the database.
 func (s *SqlStore) DeleteGroups(ctx context.Context, strength LockingStrength, accountID string, groupIDs []string) error {
-	result := s.db.Clauses(clause.Locking{Strength: string(strength)}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(strength)}).
 		Delete(&types.Group{}, accountAndIDsQueryCondition, accountID, groupIDs)
 	if result.Error != nil {
 		log.WithContext(ctx).Errorf("failed to delete groups from store: %v", result.Error)
@@ -1607,7 +1878,7 @@ func (s *SqlStore) DeleteGroups(ctx context.Context, strength LockingStrength, a
 // GetAccountPolicies retrieves policies for an account.
 func (s *SqlStore) GetAccountPolicies(ctx context.Context, lockStrength LockingStrength, accountID string) ([]*types.Policy, error) {
 	var policies []*types.Policy
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
 		Preload(clause.Associations).Find(&policies, accountIDCondition, accountID)
 	if err := result.Error; err != nil {
 		log.WithContext(ctx).Errorf("failed to get policies from the store: %s", result.Error)
@@ -1620,7 +1891,7 @@ func (s *SqlStore) GetAccountPolicies(ctx context.Context, lockStrength LockingS
 // GetPolicyByID retrieves a policy by its ID and account ID.
 func (s *SqlStore) GetPolicyByID(ctx context.Context, lockStrength LockingStrength, accountID, policyID string) (*types.Policy, error) {
 	var policy *types.Policy
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Preload(clause.Associations).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Preload(clause.Associations).
 		First(&policy, accountAndIDQueryCondition, accountID, policyID)
 	if err := result.Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -1634,7 +1905,7 @@ func (s *SqlStore) GetPolicyByID(ctx context.Context, lockStrength LockingStreng
 }
 
 func (s *SqlStore) CreatePolicy(ctx context.Context, lockStrength LockingStrength, policy *types.Policy) error {
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Create(policy)
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Create(policy)
 	if result.Error != nil {
 		log.WithContext(ctx).Errorf("failed to create policy in store: %s", result.Error)
 		return status.Errorf(status.Internal, "failed to create policy in store")
@@ -1645,7 +1916,7 @@ func (s *SqlStore) CreatePolicy(ctx context.Context, lockStrength LockingStrengt
 
 // SavePolicy saves a policy to the database.
 func (s *SqlStore) SavePolicy(ctx context.Context, lockStrength LockingStrength, policy *types.Policy) error {
-	result := s.db.Session(&gorm.Session{FullSaveAssociations: true}).
+	result := s.getDB(ctx).Session(&gorm.Session{FullSaveAssociations: true}).
 		Clauses(clause.Locking{Strength: string(lockStrength)}).Save(policy)
 	if err := result.Error; err != nil {
 		log.WithContext(ctx).Errorf("failed to save policy to the store: %s", err)
@@ -1655,7 +1926,7 @@ func (s *SqlStore) SavePolicy(ctx context.Context, lockStrength LockingStrength,
 }
 
 func (s *SqlStore) DeletePolicy(ctx context.Context, lockStrength LockingStrength, accountID, policyID string) error {
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
 		Delete(&types.Policy{}, accountAndIDQueryCondition, accountID, policyID)
 	if err := result.Error; err != nil {
 		log.WithContext(ctx).Errorf("failed to delete policy from store: %s", err)
@@ -1672,7 +1943,7 @@ func (s *SqlStore) DeletePolicy(ctx context.Context, lockStrength LockingStrengt
 // GetAccountPostureChecks retrieves posture checks for an account.
 func (s *SqlStore) GetAccountPostureChecks(ctx context.Context, lockStrength LockingStrength, accountID string) ([]*posture.Checks, error) {
 	var postureChecks []*posture.Checks
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Find(&postureChecks, accountIDCondition, accountID)
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Find(&postureChecks, accountIDCondition, accountID)
 	if result.Error != nil {
 		log.WithContext(ctx).Errorf("failed to get posture checks from store: %s", result.Error)
 		return nil, status.Errorf(status.Internal, "failed to get posture checks from store")
@@ -1684,7 +1955,7 @@ func (s *SqlStore) GetAccountPostureChecks(ctx context.Context, lockStrength Loc
 // GetPostureChecksByID retrieves posture checks by their ID and account ID.
 func (s *SqlStore) GetPostureChecksByID(ctx context.Context, lockStrength LockingStrength, accountID, postureChecksID string) (*posture.Checks, error) {
 	var postureCheck *posture.Checks
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
 		First(&postureCheck, accountAndIDQueryCondition, accountID, postureChecksID)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -1700,7 +1971,7 @@ func (s *SqlStore) GetPostureChecksByID(ctx context.Context, lockStrength Lockin
 // GetPostureChecksByIDs retrieves posture checks by their IDs and account ID.
 func (s *SqlStore) GetPostureChecksByIDs(ctx context.Context, lockStrength LockingStrength, accountID string, postureChecksIDs []string) (map[string]*posture.Checks, error) {
 	var postureChecks []*posture.Checks
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Find(&postureChecks, accountAndIDsQueryCondition, accountID, postureChecksIDs)
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Find(&postureChecks, accountAndIDsQueryCondition, accountID, postureChecksIDs)
 	if result.Error != nil {
 		log.WithContext(ctx).Errorf("failed to get posture checks by ID's from store: %s", result.Error)
 		return nil, status.Errorf(status.Internal, "failed to get posture checks by ID's from store")
@@ -1716,7 +1987,7 @@ func (s *SqlStore) GetPostureChecksByIDs(ctx context.Context, lockStrength Locki
 
 // SavePostureChecks saves a posture checks to the database.
 func (s *SqlStore) SavePostureChecks(ctx context.Context, lockStrength LockingStrength, postureCheck *posture.Checks) error {
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Save(postureCheck)
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Save(postureCheck)
 	if result.Error != nil {
 		log.WithContext(ctx).Errorf("failed to save posture checks to store: %s", result.Error)
 		return status.Errorf(status.Internal, "failed to save posture checks to store")
@@ -1727,7 +1998,7 @@ func (s *SqlStore) SavePostureChecks(ctx context.Context, lockStrength LockingSt
 
 // DeletePostureChecks deletes a posture checks from the database.
 func (s *SqlStore) DeletePostureChecks(ctx context.Context, lockStrength LockingStrength, accountID, postureChecksID string) error {
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
 		Delete(&posture.Checks{}, accountAndIDQueryCondition, accountID, postureChecksID)
 	if result.Error != nil {
 		log.WithContext(ctx).Errorf("failed to delete posture checks from store: %s", result.Error)
@@ -1743,18 +2014,29 @@ func (s *SqlStore) DeletePostureChecks(ctx context.Context, lockStrength Locking
 
 // GetAccountRoutes retrieves network routes for an account.
 func (s *SqlStore) GetAccountRoutes(ctx context.Context, lockStrength LockingStrength, accountID string) ([]*route.Route, error) {
-	return getRecords[*route.Route](s.db, lockStrength, accountID)
+	return getRecords[*route.Route](s.getDB(ctx), lockStrength, accountID)
 }
 
 // GetRouteByID retrieves a route by its ID and account ID.
 func (s *SqlStore) GetRouteByID(ctx context.Context, lockStrength LockingStrength, routeID string, accountID string) (*route.Route, error) {
-	return getRecordByID[route.Route](s.db, lockStrength, routeID, accountID)
+	return getRecordByID[route.Route](s.getDB(ctx), lockStrength, routeID, accountID)
+}
+
+// SaveRoute saves a network route to the database.
+func (s *SqlStore) SaveRoute(ctx context.Context, lockStrength LockingStrength, r *route.Route) error {
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Save(r)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to save route to store: %v", result.Error)
+		return status.Errorf(status.Internal, "failed to save route to store")
+	}
+
+	return nil
 }
 
 // GetAccountSetupKeys retrieves setup keys for an account.
 func (s *SqlStore) GetAccountSetupKeys(ctx context.Context, lockStrength LockingStrength, accountID string) ([]*types.SetupKey, error) {
 	var setupKeys []*types.SetupKey
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
 		Find(&setupKeys, accountIDCondition, accountID)
 	if err := result.Error; err != nil {
 		log.WithContext(ctx).Errorf("failed to get setup keys from the store: %s", err)
@@ -1767,7 +2049,7 @@ func (s *SqlStore) GetAccountSetupKeys(ctx context.Context, lockStrength Locking
 // GetSetupKeyByID retrieves a setup key by its ID and account ID.
 func (s *SqlStore) GetSetupKeyByID(ctx context.Context, lockStrength LockingStrength, accountID, setupKeyID string) (*types.SetupKey, error) {
 	var setupKey *types.SetupKey
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
 		First(&setupKey, accountAndIDQueryCondition, accountID, setupKeyID)
 	if err := result.Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -1782,7 +2064,7 @@ func (s *SqlStore) GetSetupKeyByID(ctx context.Context, lockStrength LockingStre
 
 // SaveSetupKey saves a setup key to the database.
 func (s *SqlStore) SaveSetupKey(ctx context.Context, lockStrength LockingStrength, setupKey *types.SetupKey) error {
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Save(setupKey)
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Save(setupKey)
 	if result.Error != nil {
 		log.WithContext(ctx).Errorf("failed to save setup key to store: %s", result.Error)
 		return status.Errorf(status.Internal, "failed to save setup key to store")
@@ -1793,7 +2075,7 @@ func (s *SqlStore) SaveSetupKey(ctx context.Context, lockStrength LockingStrengt
 
 // DeleteSetupKey deletes a setup key from the database.
 func (s *SqlStore) DeleteSetupKey(ctx context.Context, lockStrength LockingStrength, accountID, keyID string) error {
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Delete(&types.SetupKey{}, accountAndIDQueryCondition, accountID, keyID)
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Delete(&types.SetupKey{}, accountAndIDQueryCondition, accountID, keyID)
 	if result.Error != nil {
 		log.WithContext(ctx).Errorf("failed to delete setup key from store: %s", result.Error)
 		return status.Errorf(status.Internal, "failed to delete setup key from store")
@@ -1809,7 +2091,7 @@ func (s *SqlStore) DeleteSetupKey(ctx context.Context, lockStrength LockingStren
 // GetAccountNameServerGroups retrieves name server groups for an account.
 func (s *SqlStore) GetAccountNameServerGroups(ctx context.Context, lockStrength LockingStrength, accountID string) ([]*nbdns.NameServerGroup, error) {
 	var nsGroups []*nbdns.NameServerGroup
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Find(&nsGroups, accountIDCondition, accountID)
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Find(&nsGroups, accountIDCondition, accountID)
 	if err := result.Error; err != nil {
 		log.WithContext(ctx).Errorf("failed to get name server groups from the store: %s", err)
 		return nil, status.Errorf(status.Internal, "failed to get name server groups from store")
@@ -1821,7 +2103,7 @@ func (s *SqlStore) GetAccountNameServerGroups(ctx context.Context, lockStrength
 // GetNameServerGroupByID retrieves a name server group by its ID and account ID.
 func (s *SqlStore) GetNameServerGroupByID(ctx context.Context, lockStrength LockingStrength, accountID, nsGroupID string) (*nbdns.NameServerGroup, error) {
 	var nsGroup *nbdns.NameServerGroup
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
 		First(&nsGroup, accountAndIDQueryCondition, accountID, nsGroupID)
 	if err := result.Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -1836,7 +2118,7 @@ func (s *SqlStore) GetNameServerGroupByID(ctx context.Context, lockStrength Lock
 
 // SaveNameServerGroup saves a name server group to the database.
 func (s *SqlStore) SaveNameServerGroup(ctx context.Context, lockStrength LockingStrength, nameServerGroup *nbdns.NameServerGroup) error {
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Save(nameServerGroup)
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Save(nameServerGroup)
 	if err := result.Error; err != nil {
 		log.WithContext(ctx).Errorf("failed to save name server group to the store: %s", err)
 		return status.Errorf(status.Internal, "failed to save name server group to store")
@@ -1846,7 +2128,7 @@ func (s *SqlStore) SaveNameServerGroup(ctx context.Context, lockStrength Locking
 
 // DeleteNameServerGroup deletes a name server group from the database.
 func (s *SqlStore) DeleteNameServerGroup(ctx context.Context, lockStrength LockingStrength, accountID, nsGroupID string) error {
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Delete(&nbdns.NameServerGroup{}, accountAndIDQueryCondition, accountID, nsGroupID)
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Delete(&nbdns.NameServerGroup{}, accountAndIDQueryCondition, accountID, nsGroupID)
 	if err := result.Error; err != nil {
 		log.WithContext(ctx).Errorf("failed to delete name server group from the store: %s", err)
 		return status.Errorf(status.Internal, "failed to delete name server group from store")
@@ -1894,7 +2176,7 @@ func getRecordByID[T any](db *gorm.DB, lockStrength LockingStrength, recordID, a
 
 // SaveDNSSettings saves the DNS settings to the store.
 func (s *SqlStore) SaveDNSSettings(ctx context.Context, lockStrength LockingStrength, accountID string, settings *types.DNSSettings) error {
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Model(&types.Account{}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Model(&types.Account{}).
 		Where(idQueryCondition, accountID).Updates(&types.AccountDNSSettings{DNSSettings: *settings})
 	if result.Error != nil {
 		log.WithContext(ctx).Errorf("failed to save dns settings to store: %v", result.Error)
@@ -1910,7 +2192,7 @@ func (s *SqlStore) SaveDNSSettings(ctx context.Context, lockStrength LockingStre
 
 func (s *SqlStore) GetAccountNetworks(ctx context.Context, lockStrength LockingStrength, accountID string) ([]*networkTypes.Network, error) {
 	var networks []*networkTypes.Network
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Find(&networks, accountIDCondition, accountID)
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Find(&networks, accountIDCondition, accountID)
 	if result.Error != nil {
 		log.WithContext(ctx).Errorf("failed to get networks from the store: %s", result.Error)
 		return nil, status.Errorf(status.Internal, "failed to get networks from store")
@@ -1921,7 +2203,7 @@ func (s *SqlStore) GetAccountNetworks(ctx context.Context, lockStrength LockingS
 
 func (s *SqlStore) GetNetworkByID(ctx context.Context, lockStrength LockingStrength, accountID, networkID string) (*networkTypes.Network, error) {
 	var network *networkTypes.Network
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
 		First(&network, accountAndIDQueryCondition, accountID, networkID)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -1936,7 +2218,7 @@ func (s *SqlStore) GetNetworkByID(ctx context.Context, lockStrength LockingStren
 }
 
 func (s *SqlStore) SaveNetwork(ctx context.Context, lockStrength LockingStrength, network *networkTypes.Network) error {
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Save(network)
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Save(network)
 	if result.Error != nil {
 		log.WithContext(ctx).Errorf("failed to save network to store: %v", result.Error)
 		return status.Errorf(status.Internal, "failed to save network to store")
@@ -1946,7 +2228,7 @@ func (s *SqlStore) SaveNetwork(ctx context.Context, lockStrength LockingStrength
 }
 
 func (s *SqlStore) DeleteNetwork(ctx context.Context, lockStrength LockingStrength, accountID, networkID string) error {
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
 		Delete(&networkTypes.Network{}, accountAndIDQueryCondition, accountID, networkID)
 	if result.Error != nil {
 		log.WithContext(ctx).Errorf("failed to delete network from store: %v", result.Error)
@@ -1962,7 +2244,7 @@ func (s *SqlStore) DeleteNetwork(ctx context.Context, lockStrength LockingStreng
 
 func (s *SqlStore) GetNetworkRoutersByNetID(ctx context.Context, lockStrength LockingStrength, accountID, netID string) ([]*routerTypes.NetworkRouter, error) {
 	var netRouters []*routerTypes.NetworkRouter
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
 		Find(&netRouters, "account_id = ? AND network_id = ?", accountID, netID)
 	if result.Error != nil {
 		log.WithContext(ctx).Errorf("failed to get network routers from store: %v", result.Error)
@@ -1974,7 +2256,7 @@ func (s *SqlStore) GetNetworkRoutersByNetID(ctx context.Context, lockStrength Lo
 
 func (s *SqlStore) GetNetworkRoutersByAccountID(ctx context.Context, lockStrength LockingStrength, accountID string) ([]*routerTypes.NetworkRouter, error) {
 	var netRouters []*routerTypes.NetworkRouter
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
 		Find(&netRouters, accountIDCondition, accountID)
 	if result.Error != nil {
 		log.WithContext(ctx).Errorf("failed to get network routers from store: %v", result.Error)
@@ -1986,7 +2268,7 @@ func (s *SqlStore) GetNetworkRoutersByAccountID(ctx context.Context, lockStrengt
 
 func (s *SqlStore) GetNetworkRouterByID(ctx context.Context, lockStrength LockingStrength, accountID, routerID string) (*routerTypes.NetworkRouter, error) {
 	var netRouter *routerTypes.NetworkRouter
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
 		First(&netRouter, accountAndIDQueryCondition, accountID, routerID)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -2000,7 +2282,7 @@ func (s *SqlStore) GetNetworkRouterByID(ctx context.Context, lockStrength Lockin
 }
 
 func (s *SqlStore) SaveNetworkRouter(ctx context.Context, lockStrength LockingStrength, router *routerTypes.NetworkRouter) error {
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Save(router)
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Save(router)
 	if result.Error != nil {
 		log.WithContext(ctx).Errorf("failed to save network router to store: %v", result.Error)
 		return status.Errorf(status.Internal, "failed to save network router to store")
@@ -2010,7 +2292,7 @@ func (s *SqlStore) SaveNetworkRouter(ctx context.Context, lockStrength LockingSt
 }
 
 func (s *SqlStore) DeleteNetworkRouter(ctx context.Context, lockStrength LockingStrength, accountID, routerID string) error {
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
 		Delete(&routerTypes.NetworkRouter{}, accountAndIDQueryCondition, accountID, routerID)
 	if result.Error != nil {
 		log.WithContext(ctx).Errorf("failed to delete network router from store: %v", result.Error)
@@ -2026,7 +2308,7 @@ func (s *SqlStore) DeleteNetworkRouter(ctx context.Context, lockStrength Locking
 
 func (s *SqlStore) GetNetworkResourcesByNetID(ctx context.Context, lockStrength LockingStrength, accountID, networkID string) ([]*resourceTypes.NetworkResource, error) {
 	var netResources []*resourceTypes.NetworkResource
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
 		Find(&netResources, "account_id = ? AND network_id = ?", accountID, networkID)
 	if result.Error != nil {
 		log.WithContext(ctx).Errorf("failed to get network resources from store: %v", result.Error)
@@ -2038,7 +2320,7 @@ func (s *SqlStore) GetNetworkResourcesByNetID(ctx context.Context, lockStrength
 
 func (s *SqlStore) GetNetworkResourcesByAccountID(ctx context.Context, lockStrength LockingStrength, accountID string) ([]*resourceTypes.NetworkResource, error) {
 	var netResources []*resourceTypes.NetworkResource
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
 		Find(&netResources, accountIDCondition, accountID)
 	if result.Error != nil {
 		log.WithContext(ctx).Errorf("failed to get network resources from store: %v", result.Error)
@@ -2050,7 +2332,7 @@ func (s *SqlStore) GetNetworkResourcesByAccountID(ctx context.Context, lockStren
 
 func (s *SqlStore) GetNetworkResourceByID(ctx context.Context, lockStrength LockingStrength, accountID, resourceID string) (*resourceTypes.NetworkResource, error) {
 	var netResources *resourceTypes.NetworkResource
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
 		First(&netResources, accountAndIDQueryCondition, accountID, resourceID)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -2065,7 +2347,7 @@ func (s *SqlStore) GetNetworkResourceByID(ctx context.Context, lockStrength Lock
 
 func (s *SqlStore) GetNetworkResourceByName(ctx context.Context, lockStrength LockingStrength, accountID, resourceName string) (*resourceTypes.NetworkResource, error) {
 	var netResources *resourceTypes.NetworkResource
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
 		First(&netResources, "account_id = ? AND name = ?", accountID, resourceName)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -2079,7 +2361,7 @@ func (s *SqlStore) GetNetworkResourceByName(ctx context.Context, lockStrength Lo
 }
 
 func (s *SqlStore) SaveNetworkResource(ctx context.Context, lockStrength LockingStrength, resource *resourceTypes.NetworkResource) error {
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Save(resource)
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Save(resource)
 	if result.Error != nil {
 		log.WithContext(ctx).Errorf("failed to save network resource to store: %v", result.Error)
 		return status.Errorf(status.Internal, "failed to save network resource to store")
@@ -2089,7 +2371,7 @@ func (s *SqlStore) SaveNetworkResource(ctx context.Context, lockStrength Locking
 }
 
 func (s *SqlStore) DeleteNetworkResource(ctx context.Context, lockStrength LockingStrength, accountID, resourceID string) error {
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
 		Delete(&resourceTypes.NetworkResource{}, accountAndIDQueryCondition, accountID, resourceID)
 	if result.Error != nil {
 		log.WithContext(ctx).Errorf("failed to delete network resource from store: %v", result.Error)
@@ -2106,7 +2388,7 @@ func (s *SqlStore) DeleteNetworkResource(ctx context.Context, lockStrength Locki
 // GetPATByHashedToken returns a PersonalAccessToken by its hashed token.
 func (s *SqlStore) GetPATByHashedToken(ctx context.Context, lockStrength LockingStrength, hashedToken string) (*types.PersonalAccessToken, error) {
 	var pat types.PersonalAccessToken
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).First(&pat, "hashed_token = ?", hashedToken)
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).First(&pat, "hashed_token = ?", hashedToken)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, status.NewPATNotFoundError(hashedToken)
@@ -2121,7 +2403,7 @@ func (s *SqlStore) GetPATByHashedToken(ctx context.Context, lockStrength Locking
 // GetPATByID retrieves a personal access token by its ID and user ID.
 func (s *SqlStore) GetPATByID(ctx context.Context, lockStrength LockingStrength, userID string, patID string) (*types.PersonalAccessToken, error) {
 	var pat types.PersonalAccessToken
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
 		First(&pat, "id = ? AND user_id = ?", patID, userID)
 	if err := result.Error; err != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -2137,7 +2419,7 @@ func (s *SqlStore) GetPATByID(ctx context.Context, lockStrength LockingStrength,
 // GetUserPATs retrieves personal access tokens for a user.
 func (s *SqlStore) GetUserPATs(ctx context.Context, lockStrength LockingStrength, userID string) ([]*types.PersonalAccessToken, error) {
 	var pats []*types.PersonalAccessToken
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Find(&pats, "user_id = ?", userID)
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Find(&pats, "user_id = ?", userID)
 	if err := result.Error; err != nil {
 		log.WithContext(ctx).Errorf("failed to get user pat's from the store: %s", err)
 		return nil, status.Errorf(status.Internal, "failed to get user pat's from store")
@@ -2153,7 +2435,7 @@ func (s *SqlStore) MarkPATUsed(ctx context.Context, lockStrength LockingStrength
 	}
 
 	fieldsToUpdate := []string{"last_used"}
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Select(fieldsToUpdate).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Select(fieldsToUpdate).
 		Where(idQueryCondition, patID).Updates(&patCopy)
 	if result.Error != nil {
 		log.WithContext(ctx).Errorf("failed to mark pat as used: %s", result.Error)
@@ -2169,7 +2451,7 @@ func (s *SqlStore) MarkPATUsed(ctx context.Context, lockStrength LockingStrength
 
 // SavePAT saves a personal access token to the database.
 func (s *SqlStore) SavePAT(ctx context.Context, lockStrength LockingStrength, pat *types.PersonalAccessToken) error {
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).Save(pat)
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Save(pat)
 	if err := result.Error; err != nil {
 		log.WithContext(ctx).Errorf("failed to save pat to the store: %s", err)
 		return status.Errorf(status.Internal, "failed to save pat to store")
@@ -2180,7 +2462,7 @@ func (s *SqlStore) SavePAT(ctx context.Context, lockStrength LockingStrength, pa
 
 // DeletePAT deletes a personal access token from the database.
 func (s *SqlStore) DeletePAT(ctx context.Context, lockStrength LockingStrength, userID, patID string) error {
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
 		Delete(&types.PersonalAccessToken{}, "user_id = ? AND id = ?", userID, patID)
 	if err := result.Error; err != nil {
 		log.WithContext(ctx).Errorf("failed to delete pat from the store: %s", err)
@@ -2198,7 +2480,7 @@ func (s *SqlStore) GetPeerByIP(ctx context.Context, lockStrength LockingStrength
 	jsonValue := fmt.Sprintf(`"%s"`, ip.String())
 
 	var peer nbpeer.Peer
-	result := s.db.Clauses(clause.Locking{Strength: string(lockStrength)}).
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
 		First(&peer, "account_id = ? AND ip = ?", accountID, jsonValue)
 	if result.Error != nil {
 		// no logging here
@@ -2210,7 +2492,7 @@ func (s *SqlStore) GetPeerByIP(ctx context.Context, lockStrength LockingStrength
 
 func (s *SqlStore) CountAccountsByPrivateDomain(ctx context.Context, domain string) (int64, error) {
 	var count int64
-	result := s.db.Model(&types.Account{}).
+	result := s.getDB(ctx).Model(&types.Account{}).
 		Where("domain = ? AND domain_category = ?",
 			strings.ToLower(domain), types.PrivateCategory,
 		).Count(&count)
@@ -2221,3 +2503,836 @@ func (s *SqlStore) CountAccountsByPrivateDomain(ctx context.Context, domain stri
 
 	return count, nil
 }
+
+func (s *SqlStore) GetAccountProvisionedUsers(ctx context.Context, lockStrength LockingStrength, accountID string) ([]*scimTypes.ProvisionedUser, error) {
+	var users []*scimTypes.ProvisionedUser
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Find(&users, accountIDCondition, accountID)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to get provisioned users from the store: %s", result.Error)
+		return nil, status.Errorf(status.Internal, "failed to get provisioned users from store")
+	}
+
+	return users, nil
+}
+
+func (s *SqlStore) GetProvisionedUserByID(ctx context.Context, lockStrength LockingStrength, accountID, userID string) (*scimTypes.ProvisionedUser, error) {
+	var user *scimTypes.ProvisionedUser
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
+		First(&user, accountAndIDQueryCondition, accountID, userID)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, status.NewUserNotFoundError(userID)
+		}
+
+		log.WithContext(ctx).Errorf("failed to get provisioned user from store: %v", result.Error)
+		return nil, status.Errorf(status.Internal, "failed to get provisioned user from store")
+	}
+
+	return user, nil
+}
+
+func (s *SqlStore) SaveProvisionedUser(ctx context.Context, lockStrength LockingStrength, user *scimTypes.ProvisionedUser) error {
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Save(user)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to save provisioned user to store: %v", result.Error)
+		return status.Errorf(status.Internal, "failed to save provisioned user to store")
+	}
+
+	return nil
+}
+
+func (s *SqlStore) DeleteProvisionedUser(ctx context.Context, lockStrength LockingStrength, accountID, userID string) error {
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
+		Delete(&scimTypes.ProvisionedUser{}, accountAndIDQueryCondition, accountID, userID)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to delete provisioned user from store: %v", result.Error)
+		return status.Errorf(status.Internal, "failed to delete provisioned user from store")
+	}
+
+	if result.RowsAffected == 0 {
+		return status.NewUserNotFoundError(userID)
+	}
+
+	return nil
+}
+
+func (s *SqlStore) GetAccountRoles(ctx context.Context, lockStrength LockingStrength, accountID string) ([]*roleTypes.Role, error) {
+	var roles []*roleTypes.Role
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Find(&roles, accountIDCondition, accountID)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to get roles from the store: %s", result.Error)
+		return nil, status.Errorf(status.Internal, "failed to get roles from store")
+	}
+
+	return roles, nil
+}
+
+func (s *SqlStore) GetRoleByID(ctx context.Context, lockStrength LockingStrength, accountID, roleID string) (*roleTypes.Role, error) {
+	var role *roleTypes.Role
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
+		First(&role, accountAndIDQueryCondition, accountID, roleID)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, status.NewRoleNotFoundError(roleID)
+		}
+
+		log.WithContext(ctx).Errorf("failed to get role from store: %v", result.Error)
+		return nil, status.Errorf(status.Internal, "failed to get role from store")
+	}
+
+	return role, nil
+}
+
+func (s *SqlStore) SaveRole(ctx context.Context, lockStrength LockingStrength, role *roleTypes.Role) error {
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Save(role)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to save role to store: %v", result.Error)
+		return status.Errorf(status.Internal, "failed to save role to store")
+	}
+
+	return nil
+}
+
+func (s *SqlStore) DeleteRole(ctx context.Context, lockStrength LockingStrength, accountID, roleID string) error {
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
+		Delete(&roleTypes.Role{}, accountAndIDQueryCondition, accountID, roleID)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to delete role from store: %v", result.Error)
+		return status.Errorf(status.Internal, "failed to delete role from store")
+	}
+
+	if result.RowsAffected == 0 {
+		return status.NewRoleNotFoundError(roleID)
+	}
+
+	return nil
+}
+
+func (s *SqlStore) GetTenantByAccountID(ctx context.Context, lockStrength LockingStrength, accountID string) (*types.Tenant, error) {
+	var tenant *types.Tenant
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
+		First(&tenant, accountIDCondition, accountID)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, status.NewTenantNotFoundError(accountID)
+		}
+
+		log.WithContext(ctx).Errorf("failed to get tenant from store: %v", result.Error)
+		return nil, status.Errorf(status.Internal, "failed to get tenant from store")
+	}
+
+	return tenant, nil
+}
+
+func (s *SqlStore) GetParentAccountTenants(ctx context.Context, lockStrength LockingStrength, parentAccountID string) ([]*types.Tenant, error) {
+	var tenants []*types.Tenant
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
+		Find(&tenants, "parent_account_id = ?", parentAccountID)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to get tenants from the store: %s", result.Error)
+		return nil, status.Errorf(status.Internal, "failed to get tenants from store")
+	}
+
+	return tenants, nil
+}
+
+func (s *SqlStore) SaveTenant(ctx context.Context, lockStrength LockingStrength, tenant *types.Tenant) error {
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Save(tenant)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to save tenant to store: %v", result.Error)
+		return status.Errorf(status.Internal, "failed to save tenant to store")
+	}
+
+	return nil
+}
+
+func (s *SqlStore) DeleteTenant(ctx context.Context, lockStrength LockingStrength, accountID string) error {
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
+		Delete(&types.Tenant{}, accountIDCondition, accountID)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to delete tenant from store: %v", result.Error)
+		return status.Errorf(status.Internal, "failed to delete tenant from store")
+	}
+
+	if result.RowsAffected == 0 {
+		return status.NewTenantNotFoundError(accountID)
+	}
+
+	return nil
+}
+
+func (s *SqlStore) GetAccountWebhooks(ctx context.Context, lockStrength LockingStrength, accountID string) ([]*webhookTypes.Webhook, error) {
+	var webhooks []*webhookTypes.Webhook
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Find(&webhooks, accountIDCondition, accountID)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to get webhooks from the store: %s", result.Error)
+		return nil, status.Errorf(status.Internal, "failed to get webhooks from store")
+	}
+
+	return webhooks, nil
+}
+
+func (s *SqlStore) GetWebhookByID(ctx context.Context, lockStrength LockingStrength, accountID, webhookID string) (*webhookTypes.Webhook, error) {
+	var webhook *webhookTypes.Webhook
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
+		First(&webhook, accountAndIDQueryCondition, accountID, webhookID)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, status.NewWebhookNotFoundError(webhookID)
+		}
+
+		log.WithContext(ctx).Errorf("failed to get webhook from store: %v", result.Error)
+		return nil, status.Errorf(status.Internal, "failed to get webhook from store")
+	}
+
+	return webhook, nil
+}
+
+func (s *SqlStore) SaveWebhook(ctx context.Context, lockStrength LockingStrength, webhook *webhookTypes.Webhook) error {
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Save(webhook)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to save webhook to store: %v", result.Error)
+		return status.Errorf(status.Internal, "failed to save webhook to store")
+	}
+
+	return nil
+}
+
+func (s *SqlStore) DeleteWebhook(ctx context.Context, lockStrength LockingStrength, accountID, webhookID string) error {
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
+		Delete(&webhookTypes.Webhook{}, accountAndIDQueryCondition, accountID, webhookID)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to delete webhook from store: %v", result.Error)
+		return status.Errorf(status.Internal, "failed to delete webhook from store")
+	}
+
+	if result.RowsAffected == 0 {
+		return status.NewWebhookNotFoundError(webhookID)
+	}
+
+	return nil
+}
+
+func (s *SqlStore) GetAccountMonitors(ctx context.Context, lockStrength LockingStrength, accountID string) ([]*monitorTypes.Monitor, error) {
+	var monitors []*monitorTypes.Monitor
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Find(&monitors, accountIDCondition, accountID)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to get monitors from the store: %s", result.Error)
+		return nil, status.Errorf(status.Internal, "failed to get monitors from store")
+	}
+
+	return monitors, nil
+}
+
+// GetEnabledMonitors returns the enabled monitors of all the accounts
+func (s *SqlStore) GetEnabledMonitors(ctx context.Context, lockStrength LockingStrength) ([]*monitorTypes.Monitor, error) {
+	var monitors []*monitorTypes.Monitor
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Find(&monitors, "enabled = ?", true)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to get enabled monitors from the store: %s", result.Error)
+		return nil, status.Errorf(status.Internal, "failed to get monitors from store")
+	}
+
+	return monitors, nil
+}
+
+func (s *SqlStore) GetMonitorByID(ctx context.Context, lockStrength LockingStrength, accountID, monitorID string) (*monitorTypes.Monitor, error) {
+	var monitor *monitorTypes.Monitor
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
+		First(&monitor, accountAndIDQueryCondition, accountID, monitorID)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, status.NewMonitorNotFoundError(monitorID)
+		}
+
+		log.WithContext(ctx).Errorf("failed to get monitor from store: %v", result.Error)
+		return nil, status.Errorf(status.Internal, "failed to get monitor from store")
+	}
+
+	return monitor, nil
+}
+
+func (s *SqlStore) SaveMonitor(ctx context.Context, lockStrength LockingStrength, monitor *monitorTypes.Monitor) error {
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Save(monitor)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to save monitor to store: %v", result.Error)
+		return status.Errorf(status.Internal, "failed to save monitor to store")
+	}
+
+	return nil
+}
+
+// DeleteMonitor deletes the monitor and its results
+func (s *SqlStore) DeleteMonitor(ctx context.Context, lockStrength LockingStrength, accountID, monitorID string) error {
+	return s.getDB(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.Locking{Strength: string(lockStrength)}).
+			Delete(&monitorTypes.Monitor{}, accountAndIDQueryCondition, accountID, monitorID)
+		if result.Error != nil {
+			log.WithContext(ctx).Errorf("failed to delete monitor from store: %v", result.Error)
+			return status.Errorf(status.Internal, "failed to delete monitor from store")
+		}
+
+		if result.RowsAffected == 0 {
+			return status.NewMonitorNotFoundError(monitorID)
+		}
+
+		result = tx.Delete(&monitorTypes.Result{}, "account_id = ? and monitor_id = ?", accountID, monitorID)
+		if result.Error != nil {
+			log.WithContext(ctx).Errorf("failed to delete monitor results from store: %v", result.Error)
+			return status.Errorf(status.Internal, "failed to delete monitor from store")
+		}
+
+		return nil
+	})
+}
+
+func (s *SqlStore) SaveMonitorResult(ctx context.Context, lockStrength LockingStrength, monitorResult *monitorTypes.Result) error {
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Create(monitorResult)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to save monitor result to store: %v", result.Error)
+		return status.Errorf(status.Internal, "failed to save monitor result to store")
+	}
+
+	return nil
+}
+
+// GetMonitorResults returns the results of the monitor since the time, the latest first
+func (s *SqlStore) GetMonitorResults(ctx context.Context, lockStrength LockingStrength, accountID, monitorID string, since time.Time, limit int) ([]*monitorTypes.Result, error) {
+	var results []*monitorTypes.Result
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
+		Where("account_id = ? and monitor_id = ? and timestamp >= ?", accountID, monitorID, since).
+		Order("timestamp desc, id desc").
+		Limit(limit).
+		Find(&results)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to get monitor results from the store: %s", result.Error)
+		return nil, status.Errorf(status.Internal, "failed to get monitor results from store")
+	}
+
+	return results, nil
+}
+
+// DeleteMonitorResultsBefore deletes the monitor results of all the accounts older than the time
+func (s *SqlStore) DeleteMonitorResultsBefore(ctx context.Context, lockStrength LockingStrength, before time.Time) error {
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
+		Delete(&monitorTypes.Result{}, "timestamp < ?", before)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to delete monitor results from store: %v", result.Error)
+		return status.Errorf(status.Internal, "failed to delete monitor results from store")
+	}
+
+	return nil
+}
+
+// CountPeersByAccount returns the number of peers of every account seen since the time, or connected, and the number
+// of connected peers
+func (s *SqlStore) CountPeersByAccount(ctx context.Context, activeSince time.Time) ([]*usageTypes.PeerCounts, error) {
+	var counts []*usageTypes.PeerCounts
+	result := s.getDB(ctx).Model(&nbpeer.Peer{}).
+		Select("account_id, "+
+			"SUM(CASE WHEN peer_status_last_seen >= ? OR peer_status_connected = ? THEN 1 ELSE 0 END) AS active, "+
+			"SUM(CASE WHEN peer_status_connected = ? THEN 1 ELSE 0 END) AS connected", activeSince, true, true).
+		Group("account_id").
+		Scan(&counts)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to count peers in the store: %s", result.Error)
+		return nil, status.Errorf(status.Internal, "failed to count peers in store")
+	}
+
+	return counts, nil
+}
+
+// GetAllPeerKeys returns the WireGuard public keys of the peers of all the accounts
+func (s *SqlStore) GetAllPeerKeys(ctx context.Context, lockStrength LockingStrength) ([]*usageTypes.PeerKey, error) {
+	var keys []*usageTypes.PeerKey
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Model(&nbpeer.Peer{}).
+		Select("account_id", "key").
+		Scan(&keys)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to get peer keys from the store: %s", result.Error)
+		return nil, status.Errorf(status.Internal, "failed to get peer keys from store")
+	}
+
+	return keys, nil
+}
+
+// UpdateUsageSample applies the update to the usage sample of the account on the day in a transaction, the sample is
+// created if there is none
+func (s *SqlStore) UpdateUsageSample(ctx context.Context, accountID string, date time.Time, update func(sample *usageTypes.Sample)) error {
+	return s.getDB(ctx).Transaction(func(tx *gorm.DB) error {
+		var sample usageTypes.Sample
+		result := tx.Clauses(clause.Locking{Strength: string(LockingStrengthUpdate)}).
+			Limit(1).Find(&sample, "account_id = ? and date = ?", accountID, date)
+		if result.Error != nil {
+			log.WithContext(ctx).Errorf("failed to get usage sample from the store: %s", result.Error)
+			return status.Errorf(status.Internal, "failed to get usage sample from store")
+		}
+		if result.RowsAffected == 0 {
+			sample = usageTypes.Sample{AccountID: accountID, Date: date}
+		}
+
+		update(&sample)
+
+		if err := tx.Save(&sample).Error; err != nil {
+			log.WithContext(ctx).Errorf("failed to save usage sample to store: %v", err)
+			return status.Errorf(status.Internal, "failed to save usage sample to store")
+		}
+
+		return nil
+	})
+}
+
+// GetAccountUsage returns the usage samples of the account between the days, the earliest first
+func (s *SqlStore) GetAccountUsage(ctx context.Context, lockStrength LockingStrength, accountID string, from, to time.Time) ([]*usageTypes.Sample, error) {
+	var samples []*usageTypes.Sample
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
+		Where("account_id = ? and date >= ? and date <= ?", accountID, from, to).
+		Order("date asc").
+		Find(&samples)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to get usage samples from the store: %s", result.Error)
+		return nil, status.Errorf(status.Internal, "failed to get usage samples from store")
+	}
+
+	return samples, nil
+}
+
+// AddExitNodeUsage adds the traffic to the exit node usage of the peer in the month in a transaction, the usage is
+// created if there is none
+func (s *SqlStore) AddExitNodeUsage(ctx context.Context, usage *usageTypes.ExitNodeUsage) error {
+	return s.getDB(ctx).Transaction(func(tx *gorm.DB) error {
+		var stored usageTypes.ExitNodeUsage
+		result := tx.Clauses(clause.Locking{Strength: string(LockingStrengthUpdate)}).
+			Limit(1).Find(&stored, "month = ? and exit_node_id = ? and peer_id = ?", usage.Month, usage.ExitNodeID, usage.PeerID)
+		if result.Error != nil {
+			log.WithContext(ctx).Errorf("failed to get exit node usage from the store: %s", result.Error)
+			return status.Errorf(status.Internal, "failed to get exit node usage from store")
+		}
+		if result.RowsAffected == 0 {
+			stored = usageTypes.ExitNodeUsage{Month: usage.Month, ExitNodeID: usage.ExitNodeID, PeerID: usage.PeerID}
+		}
+
+		stored.AccountID = usage.AccountID
+		stored.UserID = usage.UserID
+		stored.RxBytes += usage.RxBytes
+		stored.TxBytes += usage.TxBytes
+		stored.UpdatedAt = usage.UpdatedAt
+
+		if err := tx.Save(&stored).Error; err != nil {
+			log.WithContext(ctx).Errorf("failed to save exit node usage to store: %v", err)
+			return status.Errorf(status.Internal, "failed to save exit node usage to store")
+		}
+
+		return nil
+	})
+}
+
+// GetAccountExitNodeUsage returns the exit node usage of the peers of the account in the month
+func (s *SqlStore) GetAccountExitNodeUsage(ctx context.Context, lockStrength LockingStrength, accountID, month string) ([]*usageTypes.ExitNodeUsage, error) {
+	var usage []*usageTypes.ExitNodeUsage
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
+		Where("account_id = ? and month = ?", accountID, month).
+		Find(&usage)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to get exit node usage from the store: %s", result.Error)
+		return nil, status.Errorf(status.Internal, "failed to get exit node usage from store")
+	}
+
+	return usage, nil
+}
+
+// SetPeersExitNodeQuotaExceeded marks the peers of the account as exceeding their exit node quota or not
+func (s *SqlStore) SetPeersExitNodeQuotaExceeded(ctx context.Context, lockStrength LockingStrength, accountID string, peerIDs []string, exceeded bool) error {
+	if len(peerIDs) == 0 {
+		return nil
+	}
+
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Model(&nbpeer.Peer{}).
+		Where("account_id = ? and id IN ?", accountID, peerIDs).
+		Update("exit_node_quota_exceeded", exceeded)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to update exit node quota of peers in the store: %s", result.Error)
+		return status.Errorf(status.Internal, "failed to update exit node quota of peers in store")
+	}
+
+	return nil
+}
+
+// ResetExitNodeQuotas clears the exit node quota flag of the peers of all the accounts, it returns the accounts whose
+// peers were flagged
+func (s *SqlStore) ResetExitNodeQuotas(ctx context.Context) ([]string, error) {
+	var accountIDs []string
+	err := s.getDB(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&nbpeer.Peer{}).
+			Where("exit_node_quota_exceeded = ?", true).
+			Distinct().Pluck("account_id", &accountIDs)
+		if result.Error != nil {
+			return result.Error
+		}
+		if len(accountIDs) == 0 {
+			return nil
+		}
+
+		return tx.Model(&nbpeer.Peer{}).
+			Where("exit_node_quota_exceeded = ?", true).
+			Update("exit_node_quota_exceeded", false).Error
+	})
+	if err != nil {
+		log.WithContext(ctx).Errorf("failed to reset exit node quotas in the store: %s", err)
+		return nil, status.Errorf(status.Internal, "failed to reset exit node quotas in store")
+	}
+
+	return accountIDs, nil
+}
+
+// SaveAccessSnapshot stores the access snapshot of an account
+func (s *SqlStore) SaveAccessSnapshot(ctx context.Context, lockStrength LockingStrength, snapshot *accessHistoryTypes.Snapshot) error {
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Create(snapshot)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to save access snapshot to store: %v", result.Error)
+		return status.Errorf(status.Internal, "failed to save access snapshot to store")
+	}
+
+	return nil
+}
+
+// GetAccessSnapshotAt returns the latest access snapshot of the account taken at or before the time
+func (s *SqlStore) GetAccessSnapshotAt(ctx context.Context, lockStrength LockingStrength, accountID string, at time.Time) (*accessHistoryTypes.Snapshot, error) {
+	var snapshot accessHistoryTypes.Snapshot
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
+		Where("account_id = ? and timestamp <= ?", accountID, at).
+		Order("timestamp desc").
+		Limit(1).
+		Find(&snapshot)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to get access snapshot from the store: %s", result.Error)
+		return nil, status.Errorf(status.Internal, "failed to get access snapshot from store")
+	}
+	if result.RowsAffected == 0 {
+		return nil, status.Errorf(status.NotFound, "no access snapshot of the account at %s", at.UTC().Format(time.RFC3339))
+	}
+
+	return &snapshot, nil
+}
+
+// GetAccessSnapshots returns the access snapshots of the account taken in the period without their data, the
+// earliest first
+func (s *SqlStore) GetAccessSnapshots(ctx context.Context, lockStrength LockingStrength, accountID string, from, to time.Time) ([]*accessHistoryTypes.Snapshot, error) {
+	var snapshots []*accessHistoryTypes.Snapshot
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
+		Omit("data").
+		Where("account_id = ? and timestamp >= ? and timestamp <= ?", accountID, from, to).
+		Order("timestamp asc").
+		Find(&snapshots)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to get access snapshots from the store: %s", result.Error)
+		return nil, status.Errorf(status.Internal, "failed to get access snapshots from store")
+	}
+
+	return snapshots, nil
+}
+
+// GetPeerDebugBundleRequests returns the debug bundle requests of the peer, the latest first
+func (s *SqlStore) GetPeerDebugBundleRequests(ctx context.Context, lockStrength LockingStrength, accountID, peerID string) ([]*debugBundleTypes.Request, error) {
+	var requests []*debugBundleTypes.Request
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
+		Where("account_id = ? and peer_id = ?", accountID, peerID).
+		Order("created_at desc").
+		Find(&requests)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to get debug bundle requests from the store: %s", result.Error)
+		return nil, status.Errorf(status.Internal, "failed to get debug bundle requests from store")
+	}
+
+	return requests, nil
+}
+
+func (s *SqlStore) GetDebugBundleRequestByID(ctx context.Context, lockStrength LockingStrength, accountID, requestID string) (*debugBundleTypes.Request, error) {
+	var request *debugBundleTypes.Request
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
+		First(&request, accountAndIDQueryCondition, accountID, requestID)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, status.NewDebugBundleNotFoundError(requestID)
+		}
+
+		log.WithContext(ctx).Errorf("failed to get debug bundle request from store: %v", result.Error)
+		return nil, status.Errorf(status.Internal, "failed to get debug bundle request from store")
+	}
+
+	return request, nil
+}
+
+func (s *SqlStore) SaveDebugBundleRequest(ctx context.Context, lockStrength LockingStrength, request *debugBundleTypes.Request) error {
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Save(request)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to save debug bundle request to store: %v", result.Error)
+		return status.Errorf(status.Internal, "failed to save debug bundle request to store")
+	}
+
+	return nil
+}
+
+func (s *SqlStore) DeleteDebugBundleRequest(ctx context.Context, lockStrength LockingStrength, accountID, requestID string) error {
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
+		Delete(&debugBundleTypes.Request{}, accountAndIDQueryCondition, accountID, requestID)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to delete debug bundle request from store: %v", result.Error)
+		return status.Errorf(status.Internal, "failed to delete debug bundle request from store")
+	}
+
+	if result.RowsAffected == 0 {
+		return status.NewDebugBundleNotFoundError(requestID)
+	}
+
+	return nil
+}
+
+// GetAccountRollouts returns the rollouts of the account, the latest first
+func (s *SqlStore) GetAccountRollouts(ctx context.Context, lockStrength LockingStrength, accountID string) ([]*types.Rollout, error) {
+	var rollouts []*types.Rollout
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
+		Where(accountIDCondition, accountID).
+		Order("started_at desc").
+		Find(&rollouts)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to get rollouts from the store: %s", result.Error)
+		return nil, status.Errorf(status.Internal, "failed to get rollouts from store")
+	}
+
+	return rollouts, nil
+}
+
+// GetActiveRollouts returns the rollouts applied to canary peers of all accounts
+func (s *SqlStore) GetActiveRollouts(ctx context.Context, lockStrength LockingStrength) ([]*types.Rollout, error) {
+	var rollouts []*types.Rollout
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
+		Where("status IN ?", []types.RolloutStatus{types.RolloutStatusCanary, types.RolloutStatusReady}).
+		Find(&rollouts)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to get active rollouts from the store: %s", result.Error)
+		return nil, status.Errorf(status.Internal, "failed to get active rollouts from store")
+	}
+
+	return rollouts, nil
+}
+
+func (s *SqlStore) GetRolloutByID(ctx context.Context, lockStrength LockingStrength, accountID, rolloutID string) (*types.Rollout, error) {
+	var rollout *types.Rollout
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
+		First(&rollout, accountAndIDQueryCondition, accountID, rolloutID)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, status.NewRolloutNotFoundError(rolloutID)
+		}
+
+		log.WithContext(ctx).Errorf("failed to get rollout from store: %v", result.Error)
+		return nil, status.Errorf(status.Internal, "failed to get rollout from store")
+	}
+
+	return rollout, nil
+}
+
+func (s *SqlStore) SaveRollout(ctx context.Context, lockStrength LockingStrength, rollout *types.Rollout) error {
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Save(rollout)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to save rollout to store: %v", result.Error)
+		return status.Errorf(status.Internal, "failed to save rollout to store")
+	}
+
+	return nil
+}
+
+// GetPeerActions returns the actions requested from the peer, the latest first
+func (s *SqlStore) GetPeerActions(ctx context.Context, lockStrength LockingStrength, accountID, peerID string) ([]*peerActionTypes.Action, error) {
+	var actions []*peerActionTypes.Action
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
+		Where("account_id = ? and peer_id = ?", accountID, peerID).
+		Order("created_at desc").
+		Find(&actions)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to get peer actions from the store: %s", result.Error)
+		return nil, status.Errorf(status.Internal, "failed to get peer actions from store")
+	}
+
+	return actions, nil
+}
+
+func (s *SqlStore) GetPeerActionByID(ctx context.Context, lockStrength LockingStrength, accountID, actionID string) (*peerActionTypes.Action, error) {
+	var action *peerActionTypes.Action
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
+		First(&action, accountAndIDQueryCondition, accountID, actionID)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, status.NewPeerActionNotFoundError(actionID)
+		}
+
+		log.WithContext(ctx).Errorf("failed to get peer action from store: %v", result.Error)
+		return nil, status.Errorf(status.Internal, "failed to get peer action from store")
+	}
+
+	return action, nil
+}
+
+func (s *SqlStore) SavePeerAction(ctx context.Context, lockStrength LockingStrength, action *peerActionTypes.Action) error {
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).Save(action)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to save peer action to store: %v", result.Error)
+		return status.Errorf(status.Internal, "failed to save peer action to store")
+	}
+
+	return nil
+}
+
+func (s *SqlStore) DeletePeerAction(ctx context.Context, lockStrength LockingStrength, accountID, actionID string) error {
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
+		Delete(&peerActionTypes.Action{}, accountAndIDQueryCondition, accountID, actionID)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to delete peer action from store: %v", result.Error)
+		return status.Errorf(status.Internal, "failed to delete peer action from store")
+	}
+
+	if result.RowsAffected == 0 {
+		return status.NewPeerActionNotFoundError(actionID)
+	}
+
+	return nil
+}
+
+// UpdateConnectivityReport applies the update to the connectivity report of the peer in a transaction, the report is
+// created if there is none
+func (s *SqlStore) UpdateConnectivityReport(ctx context.Context, accountID, peerID string, update func(report *connectivityTypes.Report)) error {
+	return s.getDB(ctx).Transaction(func(tx *gorm.DB) error {
+		var report connectivityTypes.Report
+		result := tx.Clauses(clause.Locking{Strength: string(LockingStrengthUpdate)}).
+			Limit(1).Find(&report, "peer_id = ?", peerID)
+		if result.Error != nil {
+			log.WithContext(ctx).Errorf("failed to get connectivity report from the store: %s", result.Error)
+			return status.Errorf(status.Internal, "failed to get connectivity report from store")
+		}
+		if result.RowsAffected == 0 {
+			report = connectivityTypes.Report{PeerID: peerID}
+		}
+		report.AccountID = accountID
+
+		update(&report)
+
+		if err := tx.Save(&report).Error; err != nil {
+			log.WithContext(ctx).Errorf("failed to save connectivity report to store: %v", err)
+			return status.Errorf(status.Internal, "failed to save connectivity report to store")
+		}
+
+		return nil
+	})
+}
+
+// GetAccountConnectivityReports returns the latest connectivity reports of the peers of the account
+func (s *SqlStore) GetAccountConnectivityReports(ctx context.Context, lockStrength LockingStrength, accountID string) ([]*connectivityTypes.Report, error) {
+	var reports []*connectivityTypes.Report
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
+		Where("account_id = ?", accountID).
+		Order("peer_id asc").
+		Find(&reports)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to get connectivity reports from the store: %s", result.Error)
+		return nil, status.Errorf(status.Internal, "failed to get connectivity reports from store")
+	}
+
+	return reports, nil
+}
+
+// GetResourceRevision returns the revision of the API resource, 0 if it was never written through the API
+func (s *SqlStore) GetResourceRevision(ctx context.Context, lockStrength LockingStrength, accountID, resource string) (uint64, error) {
+	var revision types.ResourceRevision
+	result := s.getDB(ctx).Clauses(clause.Locking{Strength: string(lockStrength)}).
+		Where("account_id = ? and resource = ?", accountID, resource).
+		Limit(1).
+		Find(&revision)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to get resource revision from the store: %s", result.Error)
+		return 0, status.Errorf(status.Internal, "failed to get resource revision from store")
+	}
+
+	return revision.Revision, nil
+}
+
+// IncrementResourceRevision increments the revision of the API resource and returns it. The upsert locks the revision
+// until the transaction ends, so the concurrent writes to the resource are serialized.
+func (s *SqlStore) IncrementResourceRevision(ctx context.Context, accountID, resource string) (uint64, error) {
+	revision := &types.ResourceRevision{AccountID: accountID, Resource: resource, Revision: 1}
+	result := s.getDB(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "account_id"}, {Name: "resource"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"revision": gorm.Expr("resource_revisions.revision + 1")}),
+	}).Create(revision)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to increment resource revision in the store: %s", result.Error)
+		return 0, status.Errorf(status.Internal, "failed to increment resource revision in store")
+	}
+
+	return s.GetResourceRevision(ctx, LockingStrengthUpdate, accountID, resource)
+}
+
+// ReserveIdempotencyKey stores the record of a new request with an idempotency key. It returns the unexpired record
+// kept for the key and false if the key is in use, an expired record is replaced.
+func (s *SqlStore) ReserveIdempotencyKey(ctx context.Context, key *types.IdempotencyKey, now time.Time) (*types.IdempotencyKey, bool, error) {
+	db := s.getDB(ctx)
+
+	result := db.Where("id = ? and expires_at < ?", key.ID, now).Delete(&types.IdempotencyKey{})
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to delete expired idempotency key from the store: %s", result.Error)
+		return nil, false, status.Errorf(status.Internal, "failed to reserve idempotency key in store")
+	}
+
+	result = db.Clauses(clause.OnConflict{DoNothing: true}).Create(key)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to save idempotency key to the store: %s", result.Error)
+		return nil, false, status.Errorf(status.Internal, "failed to reserve idempotency key in store")
+	}
+	if result.RowsAffected == 1 {
+		return key, true, nil
+	}
+
+	var kept types.IdempotencyKey
+	result = db.Limit(1).Find(&kept, idQueryCondition, key.ID)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to get idempotency key from the store: %s", result.Error)
+		return nil, false, status.Errorf(status.Internal, "failed to reserve idempotency key in store")
+	}
+	if result.RowsAffected == 0 {
+		// the record expired and was deleted by another request meanwhile
+		return s.ReserveIdempotencyKey(ctx, key, now)
+	}
+
+	return &kept, false, nil
+}
+
+func (s *SqlStore) SaveIdempotencyKey(ctx context.Context, key *types.IdempotencyKey) error {
+	result := s.getDB(ctx).Save(key)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to save idempotency key to the store: %s", result.Error)
+		return status.Errorf(status.Internal, "failed to save idempotency key to store")
+	}
+
+	return nil
+}
+
+func (s *SqlStore) DeleteIdempotencyKey(ctx context.Context, keyID string) error {
+	result := s.getDB(ctx).Delete(&types.IdempotencyKey{}, idQueryCondition, keyID)
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to delete idempotency key from the store: %s", result.Error)
+		return status.Errorf(status.Internal, "failed to delete idempotency key from store")
+	}
+
+	return nil
+}
+
+// DeleteExpiredIdempotencyKeys deletes the records of the idempotency keys expired at now
+func (s *SqlStore) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) error {
+	result := s.getDB(ctx).Where("expires_at < ?", now).Delete(&types.IdempotencyKey{})
+	if result.Error != nil {
+		log.WithContext(ctx).Errorf("failed to delete expired idempotency keys from the store: %s", result.Error)
+		return status.Errorf(status.Internal, "failed to delete expired idempotency keys from store")
+	}
+
+	return nil
+}
@@ -747,6 +747,29 @@ func TestPostgresql_NewStore(t *testing.T) {
 	}
 }
 
+func TestPostgresql_Indexes(t *testing.T) {
+	if (os.Getenv("CI") == "true" && runtime.GOOS == "darwin") || runtime.GOOS == "windows" {
+		t.Skip("skip CI tests on darwin and windows")
+	}
+
+	t.Setenv("NETBIRD_STORE_ENGINE", string(types.PostgresStoreEngine))
+	store, cleanUp, err := NewTestStoreFromSQL(context.Background(), "", t.TempDir())
+	t.Cleanup(cleanUp)
+	require.NoError(t, err)
+
+	db := store.(*SqlStore).GetDB()
+	for _, index := range postgresIndexes {
+		var valid []bool
+		err = db.Raw("SELECT i.indisvalid FROM pg_index i JOIN pg_class c ON c.oid = i.indexrelid WHERE c.relname = ?", index.name).
+			Scan(&valid).Error
+		require.NoError(t, err)
+		require.Equal(t, []bool{true}, valid, "index %s", index.name)
+	}
+
+	// running the migration again keeps the existing indexes
+	require.NoError(t, createPostgresIndexes(context.Background(), db))
+}
+
 func TestPostgresql_SaveAccount(t *testing.T) {
 	if (os.Getenv("CI") == "true" && runtime.GOOS == "darwin") || runtime.GOOS == "windows" {
 		t.Skip("skip CI tests on darwin and windows")
@@ -2638,6 +2661,71 @@ func TestSqlStore_GetAccountPeers(t *testing.T) {
 
 }
 
+func TestSqlStore_ListAccountPeers(t *testing.T) {
+	store, cleanup, err := NewTestStoreFromSQL(context.Background(), "../testdata/store_with_expired_peers.sql", t.TempDir())
+	t.Cleanup(cleanup)
+	require.NoError(t, err)
+
+	accountID := "bf1c8084-ba50-4ce7-9439-34653001fc3b"
+
+	t.Run("should paginate peers sorted by name", func(t *testing.T) {
+		peers, cursor, err := store.ListAccountPeers(context.Background(), LockingStrengthShare, accountID, PeerFilter{}, ListOptions{Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, peers, 2)
+		require.NotEmpty(t, cursor)
+		assert.Equal(t, "expiredhost", peers[0].Name)
+		assert.Equal(t, "f2a34f6a4731", peers[1].Name)
+
+		peers, cursor, err = store.ListAccountPeers(context.Background(), LockingStrengthShare, accountID, PeerFilter{}, ListOptions{Limit: 2, Cursor: cursor})
+		require.NoError(t, err)
+		require.Len(t, peers, 2)
+		assert.Empty(t, cursor, "there is no page after the last one")
+		assert.Equal(t, "cg3161rlo1hs9cq94gdg", peers[0].ID, "peers with the same name are ordered by ID")
+		assert.Equal(t, "csrnkiq7qv9d8aitqd50", peers[1].ID)
+	})
+
+	t.Run("should sort peers by last seen descending", func(t *testing.T) {
+		peers, _, err := store.ListAccountPeers(context.Background(), LockingStrengthShare, accountID, PeerFilter{}, ListOptions{SortBy: SortByLastSeen, Descending: true, Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, peers, 1)
+		assert.Equal(t, "testhost", peers[0].Name)
+	})
+
+	t.Run("should filter peers", func(t *testing.T) {
+		connected := false
+		peers, _, err := store.ListAccountPeers(context.Background(), LockingStrengthShare, accountID, PeerFilter{OS: "linux", Connected: &connected, Name: "host"}, ListOptions{})
+		require.NoError(t, err)
+		assert.Len(t, peers, 3)
+
+		peers, _, err = store.ListAccountPeers(context.Background(), LockingStrengthShare, accountID, PeerFilter{OS: "windows"}, ListOptions{})
+		require.NoError(t, err)
+		assert.Len(t, peers, 0)
+
+		peers, _, err = store.ListAccountPeers(context.Background(), LockingStrengthShare, accountID, PeerFilter{IDs: []string{"cg05lnblo1hkg2j514p0"}}, ListOptions{})
+		require.NoError(t, err)
+		assert.Len(t, peers, 1)
+	})
+
+	t.Run("should filter peers by inventory", func(t *testing.T) {
+		peers, _, err := store.ListAccountPeers(context.Background(), LockingStrengthShare, accountID, PeerFilter{Version: "0.12"}, ListOptions{})
+		require.NoError(t, err)
+		require.Len(t, peers, 1)
+		assert.Equal(t, "cfvprsrlo1hqoo49ohog", peers[0].ID)
+
+		peers, _, err = store.ListAccountPeers(context.Background(), LockingStrengthShare, accountID, PeerFilter{MinUptime: time.Hour}, ListOptions{})
+		require.NoError(t, err)
+		assert.Len(t, peers, 0, "the peers not reporting their boot time shouldn't match")
+	})
+
+	t.Run("should reject invalid options", func(t *testing.T) {
+		_, _, err := store.ListAccountPeers(context.Background(), LockingStrengthShare, accountID, PeerFilter{}, ListOptions{SortBy: "key"})
+		assert.Error(t, err)
+
+		_, _, err = store.ListAccountPeers(context.Background(), LockingStrengthShare, accountID, PeerFilter{}, ListOptions{Cursor: "not-a-cursor"})
+		assert.Error(t, err)
+	})
+}
+
 func TestSqlStore_GetAccountPeersWithExpiration(t *testing.T) {
 	store, cleanup, err := NewTestStoreFromSQL(context.Background(), "../testdata/store_with_expired_peers.sql", t.TempDir())
 	t.Cleanup(cleanup)
@@ -23,17 +23,27 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/netbirdio/netbird/dns"
+	"github.com/netbirdio/netbird/management/server/store/crypt"
 	"github.com/netbirdio/netbird/management/server/telemetry"
 	"github.com/netbirdio/netbird/management/server/testutil"
 	"github.com/netbirdio/netbird/management/server/types"
 	"github.com/netbirdio/netbird/util"
 
+	accessHistoryTypes "github.com/netbirdio/netbird/management/server/accesshistory/types"
+	connectivityTypes "github.com/netbirdio/netbird/management/server/connectivity/types"
+	debugBundleTypes "github.com/netbirdio/netbird/management/server/debugbundles/types"
 	"github.com/netbirdio/netbird/management/server/migration"
+	monitorTypes "github.com/netbirdio/netbird/management/server/monitors/types"
 	resourceTypes "github.com/netbirdio/netbird/management/server/networks/resources/types"
 	routerTypes "github.com/netbirdio/netbird/management/server/networks/routers/types"
 	networkTypes "github.com/netbirdio/netbird/management/server/networks/types"
 	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+	peerActionTypes "github.com/netbirdio/netbird/management/server/peeractions/types"
 	"github.com/netbirdio/netbird/management/server/posture"
+	roleTypes "github.com/netbirdio/netbird/management/server/roles/types"
+	scimTypes "github.com/netbirdio/netbird/management/server/scim/types"
+	usageTypes "github.com/netbirdio/netbird/management/server/usage/types"
+	webhookTypes "github.com/netbirdio/netbird/management/server/webhooks/types"
 	"github.com/netbirdio/netbird/route"
 )
 
@@ -91,6 +101,7 @@ type Store interface {
 	DeletePAT(ctx context.Context, strength LockingStrength, userID, patID string) error
 
 	GetAccountGroups(ctx context.Context, lockStrength LockingStrength, accountID string) ([]*types.Group, error)
+	ListAccountGroups(ctx context.Context, lockStrength LockingStrength, accountID string, filter GroupFilter, opts ListOptions) ([]*types.Group, string, error)
 	GetResourceGroups(ctx context.Context, lockStrength LockingStrength, accountID, resourceID string) ([]*types.Group, error)
 	GetGroupByID(ctx context.Context, lockStrength LockingStrength, accountID, groupID string) (*types.Group, error)
 	GetGroupByName(ctx context.Context, lockStrength LockingStrength, groupName, accountID string) (*types.Group, error)
@@ -122,12 +133,14 @@ type Store interface {
 	AddPeerToAccount(ctx context.Context, lockStrength LockingStrength, peer *nbpeer.Peer) error
 	GetPeerByPeerPubKey(ctx context.Context, lockStrength LockingStrength, peerKey string) (*nbpeer.Peer, error)
 	GetAccountPeers(ctx context.Context, lockStrength LockingStrength, accountID, nameFilter, ipFilter string) ([]*nbpeer.Peer, error)
+	ListAccountPeers(ctx context.Context, lockStrength LockingStrength, accountID string, filter PeerFilter, opts ListOptions) ([]*nbpeer.Peer, string, error)
 	GetUserPeers(ctx context.Context, lockStrength LockingStrength, accountID, userID string) ([]*nbpeer.Peer, error)
 	GetPeerByID(ctx context.Context, lockStrength LockingStrength, accountID string, peerID string) (*nbpeer.Peer, error)
 	GetPeersByIDs(ctx context.Context, lockStrength LockingStrength, accountID string, peerIDs []string) (map[string]*nbpeer.Peer, error)
 	GetAccountPeersWithExpiration(ctx context.Context, lockStrength LockingStrength, accountID string) ([]*nbpeer.Peer, error)
 	GetAccountPeersWithInactivity(ctx context.Context, lockStrength LockingStrength, accountID string) ([]*nbpeer.Peer, error)
 	GetAllEphemeralPeers(ctx context.Context, lockStrength LockingStrength) ([]*nbpeer.Peer, error)
+	GetAllGroupsWithEphemeralPolicy(ctx context.Context, lockStrength LockingStrength) ([]*types.Group, error)
 	SavePeer(ctx context.Context, lockStrength LockingStrength, accountID string, peer *nbpeer.Peer) error
 	SavePeerStatus(ctx context.Context, lockStrength LockingStrength, accountID, peerID string, status nbpeer.PeerStatus) error
 	SavePeerLocation(ctx context.Context, lockStrength LockingStrength, accountID string, peer *nbpeer.Peer) error
@@ -142,6 +155,7 @@ type Store interface {
 
 	GetAccountRoutes(ctx context.Context, lockStrength LockingStrength, accountID string) ([]*route.Route, error)
 	GetRouteByID(ctx context.Context, lockStrength LockingStrength, routeID string, accountID string) (*route.Route, error)
+	SaveRoute(ctx context.Context, lockStrength LockingStrength, r *route.Route) error
 
 	GetAccountNameServerGroups(ctx context.Context, lockStrength LockingStrength, accountID string) ([]*dns.NameServerGroup, error)
 	GetNameServerGroupByID(ctx context.Context, lockStrength LockingStrength, nameServerGroupID string, accountID string) (*dns.NameServerGroup, error)
@@ -151,6 +165,7 @@ type Store interface {
 	GetTakenIPs(ctx context.Context, lockStrength LockingStrength, accountId string) ([]net.IP, error)
 	IncrementNetworkSerial(ctx context.Context, lockStrength LockingStrength, accountId string) error
 	GetAccountNetwork(ctx context.Context, lockStrength LockingStrength, accountId string) (*types.Network, error)
+	SaveAccountNetwork(ctx context.Context, lockStrength LockingStrength, accountID string, network *types.Network) error
 
 	GetInstallationID() string
 	SaveInstallationID(ctx context.Context, ID string) error
@@ -167,7 +182,17 @@ type Store interface {
 	// GetStoreEngine should return Engine of the current store implementation.
 	// This is also a method of metrics.DataSource interface.
 	GetStoreEngine() types.Engine
+	// SetConnectionPool applies the connection pool settings of the store config
+	SetConnectionPool(ctx context.Context, config types.StoreConfig) error
+	// SetupEncryption encrypts the secrets of the store with the data keys wrapped by the wrapper
+	SetupEncryption(ctx context.Context, wrapper crypt.KeyWrapper) error
+	// ReencryptSecrets rewrites the secrets not encrypted with the active data key, with rotate a new one is activated first
+	ReencryptSecrets(ctx context.Context, rotate bool) (int, error)
+	// RewrapDataKeys wraps the data keys with a new key encryption key
+	RewrapDataKeys(ctx context.Context, wrapper crypt.KeyWrapper) error
 	ExecuteInTransaction(ctx context.Context, f func(store Store) error) error
+	// ExecuteInBatchTransaction runs f in a single transaction joined by the store calls made with the context passed to f
+	ExecuteInBatchTransaction(ctx context.Context, f func(ctx context.Context) error) error
 
 	GetAccountNetworks(ctx context.Context, lockStrength LockingStrength, accountID string) ([]*networkTypes.Network, error)
 	GetNetworkByID(ctx context.Context, lockStrength LockingStrength, accountID, networkID string) (*networkTypes.Network, error)
@@ -187,11 +212,85 @@ type Store interface {
 	SaveNetworkResource(ctx context.Context, lockStrength LockingStrength, resource *resourceTypes.NetworkResource) error
 	DeleteNetworkResource(ctx context.Context, lockStrength LockingStrength, accountID, resourceID string) error
 	GetPeerByIP(ctx context.Context, lockStrength LockingStrength, accountID string, ip net.IP) (*nbpeer.Peer, error)
+
+	GetAccountProvisionedUsers(ctx context.Context, lockStrength LockingStrength, accountID string) ([]*scimTypes.ProvisionedUser, error)
+	GetProvisionedUserByID(ctx context.Context, lockStrength LockingStrength, accountID, userID string) (*scimTypes.ProvisionedUser, error)
+	SaveProvisionedUser(ctx context.Context, lockStrength LockingStrength, user *scimTypes.ProvisionedUser) error
+	DeleteProvisionedUser(ctx context.Context, lockStrength LockingStrength, accountID, userID string) error
+
+	GetAccountRoles(ctx context.Context, lockStrength LockingStrength, accountID string) ([]*roleTypes.Role, error)
+	GetRoleByID(ctx context.Context, lockStrength LockingStrength, accountID, roleID string) (*roleTypes.Role, error)
+	SaveRole(ctx context.Context, lockStrength LockingStrength, role *roleTypes.Role) error
+	DeleteRole(ctx context.Context, lockStrength LockingStrength, accountID, roleID string) error
+
+	GetTenantByAccountID(ctx context.Context, lockStrength LockingStrength, accountID string) (*types.Tenant, error)
+	GetParentAccountTenants(ctx context.Context, lockStrength LockingStrength, parentAccountID string) ([]*types.Tenant, error)
+	SaveTenant(ctx context.Context, lockStrength LockingStrength, tenant *types.Tenant) error
+	DeleteTenant(ctx context.Context, lockStrength LockingStrength, accountID string) error
+
+	GetAccountWebhooks(ctx context.Context, lockStrength LockingStrength, accountID string) ([]*webhookTypes.Webhook, error)
+	GetWebhookByID(ctx context.Context, lockStrength LockingStrength, accountID, webhookID string) (*webhookTypes.Webhook, error)
+	SaveWebhook(ctx context.Context, lockStrength LockingStrength, webhook *webhookTypes.Webhook) error
+	DeleteWebhook(ctx context.Context, lockStrength LockingStrength, accountID, webhookID string) error
+
+	GetAccountMonitors(ctx context.Context, lockStrength LockingStrength, accountID string) ([]*monitorTypes.Monitor, error)
+	GetEnabledMonitors(ctx context.Context, lockStrength LockingStrength) ([]*monitorTypes.Monitor, error)
+	GetMonitorByID(ctx context.Context, lockStrength LockingStrength, accountID, monitorID string) (*monitorTypes.Monitor, error)
+	SaveMonitor(ctx context.Context, lockStrength LockingStrength, monitor *monitorTypes.Monitor) error
+	DeleteMonitor(ctx context.Context, lockStrength LockingStrength, accountID, monitorID string) error
+	SaveMonitorResult(ctx context.Context, lockStrength LockingStrength, result *monitorTypes.Result) error
+	GetMonitorResults(ctx context.Context, lockStrength LockingStrength, accountID, monitorID string, since time.Time, limit int) ([]*monitorTypes.Result, error)
+	DeleteMonitorResultsBefore(ctx context.Context, lockStrength LockingStrength, before time.Time) error
+
+	CountPeersByAccount(ctx context.Context, activeSince time.Time) ([]*usageTypes.PeerCounts, error)
+	GetAllPeerKeys(ctx context.Context, lockStrength LockingStrength) ([]*usageTypes.PeerKey, error)
+	UpdateUsageSample(ctx context.Context, accountID string, date time.Time, update func(sample *usageTypes.Sample)) error
+	GetAccountUsage(ctx context.Context, lockStrength LockingStrength, accountID string, from, to time.Time) ([]*usageTypes.Sample, error)
+	AddExitNodeUsage(ctx context.Context, usage *usageTypes.ExitNodeUsage) error
+	GetAccountExitNodeUsage(ctx context.Context, lockStrength LockingStrength, accountID, month string) ([]*usageTypes.ExitNodeUsage, error)
+	SetPeersExitNodeQuotaExceeded(ctx context.Context, lockStrength LockingStrength, accountID string, peerIDs []string, exceeded bool) error
+	ResetExitNodeQuotas(ctx context.Context) ([]string, error)
+
+	SaveAccessSnapshot(ctx context.Context, lockStrength LockingStrength, snapshot *accessHistoryTypes.Snapshot) error
+	GetAccessSnapshotAt(ctx context.Context, lockStrength LockingStrength, accountID string, at time.Time) (*accessHistoryTypes.Snapshot, error)
+	GetAccessSnapshots(ctx context.Context, lockStrength LockingStrength, accountID string, from, to time.Time) ([]*accessHistoryTypes.Snapshot, error)
+
+	GetPeerDebugBundleRequests(ctx context.Context, lockStrength LockingStrength, accountID, peerID string) ([]*debugBundleTypes.Request, error)
+	GetDebugBundleRequestByID(ctx context.Context, lockStrength LockingStrength, accountID, requestID string) (*debugBundleTypes.Request, error)
+	SaveDebugBundleRequest(ctx context.Context, lockStrength LockingStrength, request *debugBundleTypes.Request) error
+	DeleteDebugBundleRequest(ctx context.Context, lockStrength LockingStrength, accountID, requestID string) error
+
+	GetPeerActions(ctx context.Context, lockStrength LockingStrength, accountID, peerID string) ([]*peerActionTypes.Action, error)
+	GetPeerActionByID(ctx context.Context, lockStrength LockingStrength, accountID, actionID string) (*peerActionTypes.Action, error)
+	SavePeerAction(ctx context.Context, lockStrength LockingStrength, action *peerActionTypes.Action) error
+	DeletePeerAction(ctx context.Context, lockStrength LockingStrength, accountID, actionID string) error
+
+	UpdateConnectivityReport(ctx context.Context, accountID, peerID string, update func(report *connectivityTypes.Report)) error
+	GetAccountConnectivityReports(ctx context.Context, lockStrength LockingStrength, accountID string) ([]*connectivityTypes.Report, error)
+
+	GetAccountRollouts(ctx context.Context, lockStrength LockingStrength, accountID string) ([]*types.Rollout, error)
+	GetActiveRollouts(ctx context.Context, lockStrength LockingStrength) ([]*types.Rollout, error)
+	GetRolloutByID(ctx context.Context, lockStrength LockingStrength, accountID, rolloutID string) (*types.Rollout, error)
+	SaveRollout(ctx context.Context, lockStrength LockingStrength, rollout *types.Rollout) error
+
+	// GetResourceRevision returns the revision of the API resource, 0 if it was never written through the API
+	GetResourceRevision(ctx context.Context, lockStrength LockingStrength, accountID, resource string) (uint64, error)
+	// IncrementResourceRevision increments the revision of the API resource and returns it, the revision stays locked
+	// until the transaction ends
+	IncrementResourceRevision(ctx context.Context, accountID, resource string) (uint64, error)
+
+	// ReserveIdempotencyKey stores the record of a new request with an idempotency key. It returns the unexpired record
+	// kept for the key and false if the key is in use.
+	ReserveIdempotencyKey(ctx context.Context, key *types.IdempotencyKey, now time.Time) (*types.IdempotencyKey, bool, error)
+	SaveIdempotencyKey(ctx context.Context, key *types.IdempotencyKey) error
+	DeleteIdempotencyKey(ctx context.Context, keyID string) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) error
 }
 
 const (
-	postgresDsnEnv = "NETBIRD_STORE_ENGINE_POSTGRES_DSN"
-	mysqlDsnEnv    = "NETBIRD_STORE_ENGINE_MYSQL_DSN"
+	postgresDsnEnv        = "NETBIRD_STORE_ENGINE_POSTGRES_DSN"
+	postgresReplicaDsnEnv = "NETBIRD_STORE_ENGINE_POSTGRES_REPLICA_DSN"
+	mysqlDsnEnv           = "NETBIRD_STORE_ENGINE_MYSQL_DSN"
 )
 
 var supportedEngines = []types.Engine{types.SqliteStoreEngine, types.PostgresStoreEngine, types.MysqlStoreEngine}
@@ -0,0 +1,154 @@
+package stream
+
+import (
+	"context"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/status"
+)
+
+const (
+	// subscriptionBufferSize is the number of events buffered per subscriber.
+	// Subscribers that fall further behind are disconnected so that they can resubscribe and catch up using the events API.
+	subscriptionBufferSize = 256
+	// maxAccountSubscriptions limits the number of concurrent subscriptions of an account
+	maxAccountSubscriptions = 100
+)
+
+// Subscription receives the events of an account
+type Subscription struct {
+	accountID string
+	codes     map[string]struct{}
+	events    chan *activity.Event
+	closeOnce sync.Once
+}
+
+// Events returns the channel of the subscribed events. The channel is closed when the subscription ends,
+// either because the subscriber didn't keep up with the events or because the server is shutting down.
+func (s *Subscription) Events() <-chan *activity.Event {
+	return s.events
+}
+
+func (s *Subscription) matches(event *activity.Event) bool {
+	if len(s.codes) == 0 {
+		return true
+	}
+	_, ok := s.codes[event.Activity.StringCode()]
+	return ok
+}
+
+func (s *Subscription) close() {
+	s.closeOnce.Do(func() {
+		close(s.events)
+	})
+}
+
+// Broker is an activity.Sink fanning out the saved events to the subscriptions of their account
+type Broker struct {
+	mu            sync.RWMutex
+	subscriptions map[string]map[*Subscription]struct{}
+	closed        bool
+}
+
+// NewBroker creates a Broker without subscriptions
+func NewBroker() *Broker {
+	return &Broker{
+		subscriptions: make(map[string]map[*Subscription]struct{}),
+	}
+}
+
+// Subscribe returns a subscription to the events of the account, filtered by the activity codes if any
+func (b *Broker) Subscribe(accountID string, codes []string) (*Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, status.Errorf(status.Internal, "event stream is shutting down")
+	}
+
+	if len(b.subscriptions[accountID]) >= maxAccountSubscriptions {
+		return nil, status.Errorf(status.PreconditionFailed, "account reached the limit of %d event stream subscriptions", maxAccountSubscriptions)
+	}
+
+	sub := &Subscription{
+		accountID: accountID,
+		codes:     make(map[string]struct{}, len(codes)),
+		events:    make(chan *activity.Event, subscriptionBufferSize),
+	}
+	for _, code := range codes {
+		sub.codes[code] = struct{}{}
+	}
+
+	if b.subscriptions[accountID] == nil {
+		b.subscriptions[accountID] = make(map[*Subscription]struct{})
+	}
+	b.subscriptions[accountID][sub] = struct{}{}
+
+	return sub, nil
+}
+
+// Unsubscribe ends the subscription
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.remove(sub)
+}
+
+func (b *Broker) remove(sub *Subscription) {
+	subs := b.subscriptions[sub.accountID]
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(b.subscriptions, sub.accountID)
+	}
+	sub.close()
+}
+
+// Publish sends the event to the matching subscriptions of its account without blocking
+func (b *Broker) Publish(ctx context.Context, event *activity.Event) error {
+	b.mu.RLock()
+	var slow []*Subscription
+	for sub := range b.subscriptions[event.AccountID] {
+		if !sub.matches(event) {
+			continue
+		}
+
+		select {
+		case sub.events <- event:
+		default:
+			slow = append(slow, sub)
+		}
+	}
+	b.mu.RUnlock()
+
+	if len(slow) == 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, sub := range slow {
+		log.WithContext(ctx).Debugf("closing event stream subscription of account %s that fell behind", sub.accountID)
+		b.remove(sub)
+	}
+
+	return nil
+}
+
+// Close ends all subscriptions
+func (b *Broker) Close(_ context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for _, subs := range b.subscriptions {
+		for sub := range subs {
+			b.remove(sub)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package stream
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/management/server/activity"
+)
+
+func TestBroker_Publish(t *testing.T) {
+	broker := NewBroker()
+	ctx := context.Background()
+
+	all, err := broker.Subscribe("account1", nil)
+	require.NoError(t, err)
+	filtered, err := broker.Subscribe("account1", []string{activity.PeerAddedByUser.StringCode()})
+	require.NoError(t, err)
+	other, err := broker.Subscribe("account2", nil)
+	require.NoError(t, err)
+
+	require.NoError(t, broker.Publish(ctx, &activity.Event{ID: 1, Activity: activity.PolicyAdded, AccountID: "account1"}))
+	require.NoError(t, broker.Publish(ctx, &activity.Event{ID: 2, Activity: activity.PeerAddedByUser, AccountID: "account1"}))
+
+	assert.Equal(t, uint64(1), (<-all.Events()).ID)
+	assert.Equal(t, uint64(2), (<-all.Events()).ID)
+	assert.Equal(t, uint64(2), (<-filtered.Events()).ID)
+	assert.Len(t, other.Events(), 0, "events are only sent to the subscriptions of their account")
+
+	broker.Unsubscribe(all)
+	_, ok := <-all.Events()
+	assert.False(t, ok, "unsubscribing closes the events channel")
+
+	require.NoError(t, broker.Close(ctx))
+	_, ok = <-other.Events()
+	assert.False(t, ok, "closing the broker ends all subscriptions")
+
+	_, err = broker.Subscribe("account1", nil)
+	assert.Error(t, err)
+}
+
+func TestBroker_ClosesSlowSubscriptions(t *testing.T) {
+	broker := NewBroker()
+	ctx := context.Background()
+
+	sub, err := broker.Subscribe("account1", nil)
+	require.NoError(t, err)
+
+	for i := 0; i <= subscriptionBufferSize; i++ {
+		require.NoError(t, broker.Publish(ctx, &activity.Event{ID: uint64(i), Activity: activity.PolicyAdded, AccountID: "account1"}))
+	}
+
+	received := 0
+	for range sub.Events() {
+		received++
+	}
+	assert.Equal(t, subscriptionBufferSize, received, "the buffered events are delivered before the subscription is closed")
+}
+
+func TestBroker_SubscriptionLimit(t *testing.T) {
+	broker := NewBroker()
+
+	for i := 0; i < maxAccountSubscriptions; i++ {
+		_, err := broker.Subscribe("account1", nil)
+		require.NoError(t, err)
+	}
+
+	_, err := broker.Subscribe("account1", nil)
+	assert.Error(t, err)
+
+	_, err = broker.Subscribe("account2", nil)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,67 @@
+package stream
+
+import (
+	"context"
+
+	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/permissions"
+	"github.com/netbirdio/netbird/management/server/status"
+)
+
+type Manager interface {
+	Subscribe(ctx context.Context, accountID, userID string, codes []string) (*Subscription, error)
+	Unsubscribe(sub *Subscription)
+}
+
+type managerImpl struct {
+	permissionsManager permissions.Manager
+	broker             *Broker
+}
+
+type mockManager struct {
+	broker *Broker
+}
+
+func NewManager(permissionsManager permissions.Manager, broker *Broker) Manager {
+	return &managerImpl{
+		permissionsManager: permissionsManager,
+		broker:             broker,
+	}
+}
+
+// Subscribe returns a subscription to the events of the account if the user is allowed to read them
+func (m *managerImpl) Subscribe(ctx context.Context, accountID, userID string, codes []string) (*Subscription, error) {
+	ok, err := m.permissionsManager.ValidateUserPermissions(ctx, accountID, userID, permissions.Events, permissions.Read)
+	if err != nil {
+		return nil, status.NewPermissionValidationError(err)
+	}
+	if !ok {
+		return nil, status.NewPermissionDeniedError()
+	}
+
+	for _, code := range codes {
+		if !activity.IsKnownStringCode(code) {
+			return nil, status.Errorf(status.InvalidArgument, "unknown activity code %s", code)
+		}
+	}
+
+	return m.broker.Subscribe(accountID, codes)
+}
+
+func (m *managerImpl) Unsubscribe(sub *Subscription) {
+	m.broker.Unsubscribe(sub)
+}
+
+func NewManagerMock() Manager {
+	return &mockManager{
+		broker: NewBroker(),
+	}
+}
+
+func (m *mockManager) Subscribe(ctx context.Context, accountID, userID string, codes []string) (*Subscription, error) {
+	return m.broker.Subscribe(accountID, codes)
+}
+
+func (m *mockManager) Unsubscribe(sub *Subscription) {
+	m.broker.Unsubscribe(sub)
+}
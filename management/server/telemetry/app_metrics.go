@@ -23,6 +23,7 @@ type MockAppMetrics struct {
 	GetMeterFunc                 func() metric2.Meter
 	CloseFunc                    func() error
 	ExposeFunc                   func(ctx context.Context, port int, endpoint string) error
+	HandleFunc                   func(pattern string, handler http.Handler)
 	IDPMetricsFunc               func() *IDPMetrics
 	HTTPMiddlewareFunc           func() *HTTPMiddleware
 	GRPCMetricsFunc              func() *GRPCMetrics
@@ -55,6 +56,13 @@ func (mock *MockAppMetrics) Expose(ctx context.Context, port int, endpoint strin
 	return fmt.Errorf("unimplemented")
 }
 
+// Handle mocks the Handle function of the AppMetrics interface
+func (mock *MockAppMetrics) Handle(pattern string, handler http.Handler) {
+	if mock.HandleFunc != nil {
+		mock.HandleFunc(pattern, handler)
+	}
+}
+
 // IDPMetrics mocks the IDPMetrics function of the IDPMetrics interface
 func (mock *MockAppMetrics) IDPMetrics() *IDPMetrics {
 	if mock.IDPMetricsFunc != nil {
@@ -108,6 +116,7 @@ type AppMetrics interface {
 	GetMeter() metric2.Meter
 	Close() error
 	Expose(ctx context.Context, port int, endpoint string) error
+	Handle(pattern string, handler http.Handler)
 	IDPMetrics() *IDPMetrics
 	HTTPMiddleware() *HTTPMiddleware
 	GRPCMetrics() *GRPCMetrics
@@ -121,6 +130,7 @@ type defaultAppMetrics struct {
 	// Meter can be used by different application parts to create counters and measure things
 	Meter                 metric2.Meter
 	listener              net.Listener
+	handlers              *http.ServeMux
 	ctx                   context.Context
 	idpMetrics            *IDPMetrics
 	httpMiddleware        *HTTPMiddleware
@@ -178,6 +188,7 @@ func (appMetrics *defaultAppMetrics) Expose(ctx context.Context, port int, endpo
 	rootRouter.Handle(endpoint, promhttp.HandlerFor(
 		prometheus2.DefaultGatherer,
 		promhttp.HandlerOpts{EnableOpenMetrics: true}))
+	rootRouter.PathPrefix("/").Handler(appMetrics.handlers)
 	listener, err := net.Listen("tcp4", fmt.Sprintf(":%d", port))
 	if err != nil {
 		return err
@@ -195,6 +206,11 @@ func (appMetrics *defaultAppMetrics) Expose(ctx context.Context, port int, endpo
 	return nil
 }
 
+// Handle serves the handler under the pattern next to the metrics endpoint, also after the metrics are exposed
+func (appMetrics *defaultAppMetrics) Handle(pattern string, handler http.Handler) {
+	appMetrics.handlers.Handle(pattern, handler)
+}
+
 // GetMeter returns metrics meter that can be used to add various counters
 func (appMetrics *defaultAppMetrics) GetMeter() metric2.Meter {
 	return appMetrics.Meter
@@ -243,6 +259,7 @@ func NewDefaultAppMetrics(ctx context.Context) (AppMetrics, error) {
 
 	return &defaultAppMetrics{
 		Meter:                 meter,
+		handlers:              http.NewServeMux(),
 		ctx:                   ctx,
 		idpMetrics:            idpMetrics,
 		httpMiddleware:        middleware,
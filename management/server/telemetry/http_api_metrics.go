@@ -1,8 +1,10 @@
 package telemetry
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"strings"
 	"time"
@@ -52,6 +54,15 @@ func (rw *WrappedResponseWriter) WriteHeader(code int) {
 	rw.wroteHeader = true
 }
 
+// Hijack lets the handlers take over the connection, e.g. to upgrade it to a WebSocket
+func (rw *WrappedResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := rw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	return hijacker.Hijack()
+}
+
 // HTTPMiddleware handler used to collect metrics of every request/response coming to the API.
 // Also adds request tracing (logging).
 type HTTPMiddleware struct {
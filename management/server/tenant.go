@@ -0,0 +1,175 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/permissions"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
+	"github.com/netbirdio/netbird/management/server/types"
+)
+
+// GetTenants returns the tenant accounts administered by the account
+func (am *DefaultAccountManager) GetTenants(ctx context.Context, accountID, userID string) ([]*types.Tenant, error) {
+	allowed, err := am.permissionsManager.ValidateUserPermissions(ctx, accountID, userID, permissions.Tenants, permissions.Read)
+	if err != nil {
+		return nil, status.NewPermissionValidationError(err)
+	}
+	if !allowed {
+		return nil, status.NewPermissionDeniedError()
+	}
+
+	return am.Store.GetParentAccountTenants(ctx, store.LockingStrengthShare, accountID)
+}
+
+// CreateTenant creates a new account administered by the account. The owner of the tenant account is the given
+// IdP user, usually the administrator of the customer the tenant is created for.
+// Tenants can't have tenants of their own.
+func (am *DefaultAccountManager) CreateTenant(ctx context.Context, accountID, userID, name, ownerUserID string) (*types.Tenant, error) {
+	allowed, err := am.permissionsManager.ValidateUserPermissions(ctx, accountID, userID, permissions.Tenants, permissions.Write)
+	if err != nil {
+		return nil, status.NewPermissionValidationError(err)
+	}
+	if !allowed {
+		return nil, status.NewPermissionDeniedError()
+	}
+
+	if strings.TrimSpace(name) == "" {
+		return nil, status.Errorf(status.InvalidArgument, "tenant name can't be empty")
+	}
+
+	if ownerUserID == "" {
+		return nil, status.Errorf(status.InvalidArgument, "tenant owner user ID can't be empty")
+	}
+
+	_, err = am.Store.GetTenantByAccountID(ctx, store.LockingStrengthShare, accountID)
+	if handleNotFound(err) != nil {
+		return nil, err
+	}
+	if err == nil {
+		return nil, status.Errorf(status.PreconditionFailed, "tenant accounts can't have tenants")
+	}
+
+	_, err = am.Store.GetUserByUserID(ctx, store.LockingStrengthShare, ownerUserID)
+	if handleNotFound(err) != nil {
+		return nil, err
+	}
+	if err == nil {
+		return nil, status.Errorf(status.AlreadyExists, "user %s already belongs to an account", ownerUserID)
+	}
+
+	tenantAccount, err := am.newAccount(ctx, ownerUserID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if err = am.Store.SaveAccount(ctx, tenantAccount); err != nil {
+		return nil, err
+	}
+
+	tenant := &types.Tenant{
+		AccountID:       tenantAccount.Id,
+		ParentAccountID: accountID,
+		Name:            name,
+		CreatedBy:       userID,
+		CreatedAt:       time.Now().UTC(),
+	}
+
+	if err = am.Store.SaveTenant(ctx, store.LockingStrengthUpdate, tenant); err != nil {
+		return nil, err
+	}
+
+	am.StoreEvent(ctx, userID, tenant.AccountID, accountID, activity.TenantCreated, tenant.EventMeta())
+
+	return tenant, nil
+}
+
+// DeleteTenant deletes a tenant account administered by the account together with all its resources
+func (am *DefaultAccountManager) DeleteTenant(ctx context.Context, accountID, userID, tenantAccountID string) error {
+	allowed, err := am.permissionsManager.ValidateUserPermissions(ctx, accountID, userID, permissions.Tenants, permissions.Write)
+	if err != nil {
+		return status.NewPermissionValidationError(err)
+	}
+	if !allowed {
+		return status.NewPermissionDeniedError()
+	}
+
+	tenant, err := am.Store.GetTenantByAccountID(ctx, store.LockingStrengthShare, tenantAccountID)
+	if err != nil {
+		return err
+	}
+
+	if tenant.ParentAccountID != accountID {
+		return status.NewTenantNotFoundError(tenantAccountID)
+	}
+
+	unlock := am.Store.AcquireWriteLockByUID(ctx, tenantAccountID)
+	defer unlock()
+
+	tenantAccount, err := am.Store.GetAccount(ctx, tenantAccountID)
+	if err != nil {
+		return err
+	}
+
+	if err = am.deleteAccount(ctx, tenantAccount, userID); err != nil {
+		return err
+	}
+
+	if err = am.Store.DeleteTenant(ctx, store.LockingStrengthUpdate, tenantAccountID); err != nil {
+		return err
+	}
+
+	am.StoreEvent(ctx, userID, tenantAccountID, accountID, activity.TenantDeleted, tenant.EventMeta())
+
+	return nil
+}
+
+// GetTenantsUsage returns the peers and users usage of each tenant account administered by the account
+func (am *DefaultAccountManager) GetTenantsUsage(ctx context.Context, accountID, userID string) ([]*types.TenantUsage, error) {
+	tenants, err := am.GetTenants(ctx, accountID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make([]*types.TenantUsage, 0, len(tenants))
+	for _, tenant := range tenants {
+		peers, err := am.Store.GetAccountPeers(ctx, store.LockingStrengthShare, tenant.AccountID, "", "")
+		if err != nil {
+			log.WithContext(ctx).Errorf("failed to get peers of tenant %s: %v", tenant.AccountID, err)
+			return nil, err
+		}
+
+		users, err := am.Store.GetAccountUsers(ctx, store.LockingStrengthShare, tenant.AccountID)
+		if err != nil {
+			log.WithContext(ctx).Errorf("failed to get users of tenant %s: %v", tenant.AccountID, err)
+			return nil, err
+		}
+
+		tenantUsage := &types.TenantUsage{
+			AccountID: tenant.AccountID,
+			Name:      tenant.Name,
+			Peers:     len(peers),
+		}
+
+		for _, peer := range peers {
+			if peer.Status != nil && peer.Status.Connected {
+				tenantUsage.ConnectedPeers++
+			}
+		}
+
+		for _, user := range users {
+			if !user.IsServiceUser {
+				tenantUsage.Users++
+			}
+		}
+
+		usage = append(usage, tenantUsage)
+	}
+
+	return usage, nil
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/integrations/port_forwarding"
+	"github.com/netbirdio/netbird/management/server/permissions"
+	"github.com/netbirdio/netbird/management/server/settings"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
+	"github.com/netbirdio/netbird/management/server/telemetry"
+	"github.com/netbirdio/netbird/management/server/types"
+)
+
+func TestDefaultAccountManager_Tenants(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("The SQLite store is not properly supported by Windows yet")
+	}
+
+	s, cleanup, err := store.NewTestStoreFromSQL(context.Background(), "testdata/extended-store.sql", t.TempDir())
+	require.NoError(t, err)
+	defer cleanup()
+
+	metrics, err := telemetry.NewDefaultAppMetrics(context.Background())
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+	settingsMockManager := settings.NewMockManager(ctrl)
+	settingsMockManager.EXPECT().
+		GetExtraSettings(gomock.Any(), gomock.Any()).
+		Return(&types.ExtraSettings{}, nil).
+		AnyTimes()
+
+	am, err := BuildManager(context.Background(), s, NewPeersUpdateManager(nil), nil, "", "netbird.cloud", &activity.InMemoryEventStore{}, nil, false, MocIntegratedValidator{}, metrics, port_forwarding.NewControllerMock(), settingsMockManager, permissions.NewManager(s))
+	require.NoError(t, err)
+
+	const (
+		accountID   = "bf1c8084-ba50-4ce7-9439-34653001fc3b"
+		adminUserID = "edafee4e-63fb-11ec-90d6-0242ac120003"
+		userID      = "f4f6d672-63fb-11ec-90d6-0242ac120003"
+		ownerUserID = "tenant-owner"
+	)
+
+	ctx := context.Background()
+
+	_, err = am.CreateTenant(ctx, accountID, userID, "Customer A", ownerUserID)
+	require.Error(t, err, "regular users can't create tenants")
+
+	tenant, err := am.CreateTenant(ctx, accountID, adminUserID, "Customer A", ownerUserID)
+	require.NoError(t, err)
+	assert.Equal(t, accountID, tenant.ParentAccountID)
+
+	_, err = am.CreateTenant(ctx, accountID, adminUserID, "Customer B", ownerUserID)
+	sErr, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, status.AlreadyExists, sErr.Type(), "the tenant owner already belongs to an account")
+
+	tenants, err := am.GetTenants(ctx, accountID, adminUserID)
+	require.NoError(t, err)
+	require.Len(t, tenants, 1)
+	assert.Equal(t, tenant.AccountID, tenants[0].AccountID)
+
+	t.Run("parent admins administer the tenant", func(t *testing.T) {
+		_, err := am.GetAccountSettings(ctx, tenant.AccountID, adminUserID)
+		require.NoError(t, err)
+
+		_, err = am.GetAccountSettings(ctx, tenant.AccountID, userID)
+		require.Error(t, err)
+	})
+
+	t.Run("tenants can't have tenants", func(t *testing.T) {
+		_, err := am.CreateTenant(ctx, tenant.AccountID, ownerUserID, "Customer C", "another-owner")
+		sErr, ok := status.FromError(err)
+		require.True(t, ok)
+		assert.Equal(t, status.PreconditionFailed, sErr.Type())
+	})
+
+	t.Run("tenant admins can't access the parent account", func(t *testing.T) {
+		_, err := am.GetAccountSettings(ctx, accountID, ownerUserID)
+		require.Error(t, err)
+	})
+
+	t.Run("usage is rolled up to the parent account", func(t *testing.T) {
+		usage, err := am.GetTenantsUsage(ctx, accountID, adminUserID)
+		require.NoError(t, err)
+		require.Len(t, usage, 1)
+		assert.Equal(t, &types.TenantUsage{AccountID: tenant.AccountID, Name: "Customer A", Users: 1}, usage[0])
+	})
+
+	require.NoError(t, am.DeleteTenant(ctx, accountID, adminUserID, tenant.AccountID))
+
+	exists, err := am.AccountExists(ctx, tenant.AccountID)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	tenants, err = am.GetTenants(ctx, accountID, adminUserID)
+	require.NoError(t, err)
+	assert.Empty(t, tenants)
+}
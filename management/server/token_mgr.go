@@ -6,6 +6,8 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"fmt"
+	"maps"
+	"reflect"
 	"sync"
 	"time"
 
@@ -16,6 +18,7 @@ import (
 	"github.com/netbirdio/netbird/management/server/types"
 	auth "github.com/netbirdio/netbird/relay/auth/hmac"
 	authv2 "github.com/netbirdio/netbird/relay/auth/hmac/v2"
+	"github.com/netbirdio/netbird/relay/messages"
 
 	integrationsConfig "github.com/netbirdio/management-integrations/integrations/config"
 )
@@ -25,14 +28,16 @@ const defaultDuration = 12 * time.Hour
 // SecretsManager used to manage TURN and relay secrets
 type SecretsManager interface {
 	GenerateTurnToken() (*Token, error)
-	GenerateRelayToken() (*Token, error)
-	SetupRefresh(ctx context.Context, accountID, peerKey string)
-	CancelRefresh(peerKey string)
+	GenerateRelayToken(peerKey string) (*Token, error)
+	SetupRefresh(ctx context.Context, accountID, peerID, peerKey string)
+	CancelRefresh(peerID string)
 }
 
 // TimeBasedAuthSecretsManager generates credentials with TTL and using pre-shared secret known to TURN server
 type TimeBasedAuthSecretsManager struct {
-	mux             sync.Mutex
+	mux sync.Mutex
+	// cfgMux guards the configs and the token generators, which are replaced when the config is reloaded
+	cfgMux          sync.RWMutex
 	turnCfg         *types.TURNConfig
 	relayCfg        *types.Relay
 	turnHmacToken   *auth.TimedHMAC
@@ -41,6 +46,14 @@ type TimeBasedAuthSecretsManager struct {
 	settingsManager settings.Manager
 	turnCancelMap   map[string]chan struct{}
 	relayCancelMap  map[string]chan struct{}
+	// refreshedPeers holds the peers with a credentials refresh by ID
+	refreshedPeers map[string]refreshedPeer
+}
+
+// refreshedPeer is a peer with a credentials refresh, the relay credentials are bound to its WireGuard public key
+type refreshedPeer struct {
+	accountID string
+	peerKey   string
 }
 
 type Token auth.Token
@@ -52,53 +65,133 @@ func NewTimeBasedAuthSecretsManager(updateManager *PeersUpdateManager, turnCfg *
 		relayCfg:        relayCfg,
 		turnCancelMap:   make(map[string]chan struct{}),
 		relayCancelMap:  make(map[string]chan struct{}),
+		refreshedPeers:  make(map[string]refreshedPeer),
 		settingsManager: settingsManager,
 	}
 
+	var err error
+	mgr.turnHmacToken, mgr.relayHmacToken, err = newTokenGenerators(turnCfg, relayCfg)
+	if err != nil {
+		log.Errorf("failed to create relay token generator: %s", err)
+	}
+
+	return mgr
+}
+
+// newTokenGenerators creates the generators of the TURN and relay credentials, a generator is nil if its config isn't set
+func newTokenGenerators(turnCfg *types.TURNConfig, relayCfg *types.Relay) (*auth.TimedHMAC, *authv2.Generator, error) {
+	var turnHmacToken *auth.TimedHMAC
 	if turnCfg != nil {
 		duration := turnCfg.CredentialsTTL.Duration
 		if turnCfg.CredentialsTTL.Duration <= 0 {
 			log.Warnf("TURN credentials TTL is not set or invalid, using default value %s", defaultDuration)
 			duration = defaultDuration
 		}
-		mgr.turnHmacToken = auth.NewTimedHMAC(turnCfg.Secret, duration)
+		turnHmacToken = auth.NewTimedHMAC(turnCfg.Secret, duration)
 	}
 
-	if relayCfg != nil {
-		duration := relayCfg.CredentialsTTL.Duration
-		if relayCfg.CredentialsTTL.Duration <= 0 {
-			log.Warnf("Relay credentials TTL is not set or invalid, using default value %s", defaultDuration)
-			duration = defaultDuration
-		}
+	if relayCfg == nil {
+		return turnHmacToken, nil, nil
+	}
+
+	duration := relayCfg.CredentialsTTL.Duration
+	if relayCfg.CredentialsTTL.Duration <= 0 {
+		log.Warnf("Relay credentials TTL is not set or invalid, using default value %s", defaultDuration)
+		duration = defaultDuration
+	}
+
+	hashedSecret := sha256.Sum256([]byte(relayCfg.Secret))
+	relayHmacToken, err := authv2.NewGenerator(authv2.AuthAlgoHMACSHA256, hashedSecret[:], duration)
+	if err != nil {
+		return turnHmacToken, nil, err
+	}
+
+	return turnHmacToken, relayHmacToken, nil
+}
+
+// UpdateConfig replaces the TURN and relay configs. When the secrets or the credentials settings change, e.g. when
+// a secret is rotated, the connected peers are sent new credentials right away, otherwise they get them with their
+// next refresh, their refresh interval is updated when they reconnect.
+func (m *TimeBasedAuthSecretsManager) UpdateConfig(ctx context.Context, turnCfg *types.TURNConfig, relayCfg *types.Relay) error {
+	turnHmacToken, relayHmacToken, err := newTokenGenerators(turnCfg, relayCfg)
+	if err != nil {
+		return fmt.Errorf("create relay token generator: %w", err)
+	}
+
+	m.cfgMux.Lock()
+	changed := !reflect.DeepEqual(m.turnCfg, turnCfg) || !reflect.DeepEqual(m.relayCfg, relayCfg)
+	m.turnCfg = turnCfg
+	m.relayCfg = relayCfg
+	m.turnHmacToken = turnHmacToken
+	m.relayHmacToken = relayHmacToken
+	m.cfgMux.Unlock()
+
+	if changed {
+		go m.rotateCredentials(ctx)
+	}
+
+	return nil
+}
 
-		hashedSecret := sha256.Sum256([]byte(relayCfg.Secret))
-		var err error
-		if mgr.relayHmacToken, err = authv2.NewGenerator(authv2.AuthAlgoHMACSHA256, hashedSecret[:], duration); err != nil {
-			log.Errorf("failed to create relay token generator: %s", err)
+// rotateCredentials sends new credentials to the peers with a credentials refresh and restarts their refresh with
+// the current settings
+func (m *TimeBasedAuthSecretsManager) rotateCredentials(ctx context.Context) {
+	m.mux.Lock()
+	refreshedPeers := maps.Clone(m.refreshedPeers)
+	m.mux.Unlock()
+
+	log.WithContext(ctx).Infof("sending new credentials to %d peers", len(refreshedPeers))
+
+	turnCfg, _, _, _ := m.getConfig()
+	for peerID, peer := range refreshedPeers {
+		m.SetupRefresh(ctx, peer.accountID, peerID, peer.peerKey)
+
+		if turnCfg != nil && turnCfg.TimeBasedCredentials {
+			m.pushNewTURNAndRelayTokens(ctx, peer.accountID, peerID, peer.peerKey)
+		} else {
+			m.pushNewRelayTokens(ctx, peer.accountID, peerID, peer.peerKey)
 		}
 	}
+}
 
-	return mgr
+// getConfig returns the current TURN and relay configs with their credentials generators
+func (m *TimeBasedAuthSecretsManager) getConfig() (*types.TURNConfig, *types.Relay, *auth.TimedHMAC, *authv2.Generator) {
+	m.cfgMux.RLock()
+	defer m.cfgMux.RUnlock()
+	return m.turnCfg, m.relayCfg, m.turnHmacToken, m.relayHmacToken
 }
 
 // GenerateTurnToken generates new time-based secret credentials for TURN
 func (m *TimeBasedAuthSecretsManager) GenerateTurnToken() (*Token, error) {
-	if m.turnHmacToken == nil {
+	_, _, turnHmacToken, _ := m.getConfig()
+	if turnHmacToken == nil {
 		return nil, fmt.Errorf("TURN configuration is not set")
 	}
-	turnToken, err := m.turnHmacToken.GenerateToken(sha1.New)
+	turnToken, err := turnHmacToken.GenerateToken(sha1.New)
 	if err != nil {
 		return nil, fmt.Errorf("generate TURN token: %s", err)
 	}
 	return (*Token)(turnToken), nil
 }
 
-// GenerateRelayToken generates new time-based secret credentials for relay
-func (m *TimeBasedAuthSecretsManager) GenerateRelayToken() (*Token, error) {
-	if m.relayHmacToken == nil {
+// GenerateRelayToken generates new time-based secret credentials for relay. With PeerBoundTokens the credentials
+// authenticate only the relay peer ID of the WireGuard public key on the configured relays. The relays don't verify
+// that the peer holds the key, the binding keeps a peer from registering with the ID of another peer but doesn't
+// protect a captured token.
+func (m *TimeBasedAuthSecretsManager) GenerateRelayToken(peerKey string) (*Token, error) {
+	_, relayCfg, _, relayHmacToken := m.getConfig()
+	if relayHmacToken == nil {
 		return nil, fmt.Errorf("relay configuration is not set")
 	}
-	relayToken, err := m.relayHmacToken.GenerateToken()
+
+	var relayToken *authv2.Token
+	var err error
+	if relayCfg.PeerBoundTokens {
+		_, relayPeerID := messages.HashID(peerKey)
+		relayToken, err = relayHmacToken.GeneratePeerToken(relayPeerID, relayCfg.Addresses)
+	} else {
+		relayToken, err = relayHmacToken.GenerateToken()
+	}
 	if err != nil {
 		return nil, fmt.Errorf("generate relay token: %s", err)
 	}
@@ -129,33 +222,58 @@ func (m *TimeBasedAuthSecretsManager) CancelRefresh(peerID string) {
 	defer m.mux.Unlock()
 	m.cancelTURN(peerID)
 	m.cancelRelay(peerID)
+	delete(m.refreshedPeers, peerID)
 }
 
 // SetupRefresh starts peer credentials refresh
-func (m *TimeBasedAuthSecretsManager) SetupRefresh(ctx context.Context, accountID, peerID string) {
+func (m *TimeBasedAuthSecretsManager) SetupRefresh(ctx context.Context, accountID, peerID, peerKey string) {
 	m.mux.Lock()
 	defer m.mux.Unlock()
 
 	m.cancelTURN(peerID)
 	m.cancelRelay(peerID)
 
-	if m.turnCfg != nil && m.turnCfg.TimeBasedCredentials {
+	turnCfg, relayCfg, _, _ := m.getConfig()
+
+	m.refreshedPeers[peerID] = refreshedPeer{accountID: accountID, peerKey: peerKey}
+
+	if turnCfg != nil && turnCfg.TimeBasedCredentials {
 		turnCancel := make(chan struct{}, 1)
 		m.turnCancelMap[peerID] = turnCancel
-		go m.refreshTURNTokens(ctx, accountID, peerID, turnCancel)
+		interval := refreshInterval(turnCfg.CredentialsTTL.Duration, turnCfg.CredentialsRefreshInterval.Duration)
+		go m.refreshTURNTokens(ctx, accountID, peerID, peerKey, interval, turnCancel)
 		log.WithContext(ctx).Debugf("starting TURN refresh for %s", peerID)
 	}
 
-	if m.relayCfg != nil {
+	if relayCfg != nil {
 		relayCancel := make(chan struct{}, 1)
 		m.relayCancelMap[peerID] = relayCancel
-		go m.refreshRelayTokens(ctx, accountID, peerID, relayCancel)
+		interval := refreshInterval(relayCfg.CredentialsTTL.Duration, relayCfg.CredentialsRefreshInterval.Duration)
+		go m.refreshRelayTokens(ctx, accountID, peerID, peerKey, interval, relayCancel)
 		log.WithContext(ctx).Debugf("starting relay refresh for %s", peerID)
 	}
 }
 
-func (m *TimeBasedAuthSecretsManager) refreshTURNTokens(ctx context.Context, accountID, peerID string, cancel chan struct{}) {
-	ticker := time.NewTicker(m.turnCfg.CredentialsTTL.Duration / 4 * 3)
+// refreshInterval returns how often the credentials of the TTL are refreshed, so the peers get new ones before the
+// current ones expire. The configured interval is used if it is shorter than the TTL, otherwise 3/4 of the TTL.
+func refreshInterval(ttl, configured time.Duration) time.Duration {
+	if ttl <= 0 {
+		ttl = defaultDuration
+	}
+
+	if configured > 0 && configured < ttl {
+		return configured
+	}
+
+	if configured > 0 {
+		log.Warnf("credentials refresh interval %s isn't shorter than the credentials TTL %s, refreshing them every %s", configured, ttl, ttl/4*3)
+	}
+
+	return ttl / 4 * 3
+}
+
+func (m *TimeBasedAuthSecretsManager) refreshTURNTokens(ctx context.Context, accountID, peerID, peerKey string, interval time.Duration, cancel chan struct{}) {
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
@@ -164,13 +282,13 @@ func (m *TimeBasedAuthSecretsManager) refreshTURNTokens(ctx context.Context, acc
 			log.WithContext(ctx).Debugf("stopping TURN refresh for %s", peerID)
 			return
 		case <-ticker.C:
-			m.pushNewTURNAndRelayTokens(ctx, accountID, peerID)
+			m.pushNewTURNAndRelayTokens(ctx, accountID, peerID, peerKey)
 		}
 	}
 }
 
-func (m *TimeBasedAuthSecretsManager) refreshRelayTokens(ctx context.Context, accountID, peerID string, cancel chan struct{}) {
-	ticker := time.NewTicker(m.relayCfg.CredentialsTTL.Duration / 4 * 3)
+func (m *TimeBasedAuthSecretsManager) refreshRelayTokens(ctx context.Context, accountID, peerID, peerKey string, interval time.Duration, cancel chan struct{}) {
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
@@ -179,20 +297,26 @@ func (m *TimeBasedAuthSecretsManager) refreshRelayTokens(ctx context.Context, ac
 			log.WithContext(ctx).Debugf("stopping relay refresh for %s", peerID)
 			return
 		case <-ticker.C:
-			m.pushNewRelayTokens(ctx, accountID, peerID)
+			m.pushNewRelayTokens(ctx, accountID, peerID, peerKey)
 		}
 	}
 }
 
-func (m *TimeBasedAuthSecretsManager) pushNewTURNAndRelayTokens(ctx context.Context, accountID, peerID string) {
-	turnToken, err := m.turnHmacToken.GenerateToken(sha1.New)
+func (m *TimeBasedAuthSecretsManager) pushNewTURNAndRelayTokens(ctx context.Context, accountID, peerID, peerKey string) {
+	turnCfg, relayCfg, turnHmacToken, _ := m.getConfig()
+	if turnHmacToken == nil {
+		log.WithContext(ctx).Debugf("TURN configuration was removed, not sending TURN credentials to peer %s", peerID)
+		return
+	}
+
+	turnToken, err := turnHmacToken.GenerateToken(sha1.New)
 	if err != nil {
 		log.WithContext(ctx).Errorf("failed to generate token for peer '%s': %s", peerID, err)
 		return
 	}
 
 	var turns []*proto.ProtectedHostConfig
-	for _, host := range m.turnCfg.Turns {
+	for _, host := range turnCfg.Turns {
 		turn := &proto.ProtectedHostConfig{
 			HostConfig: &proto.HostConfig{
 				Uri:      host.URI,
@@ -211,11 +335,11 @@ func (m *TimeBasedAuthSecretsManager) pushNewTURNAndRelayTokens(ctx context.Cont
 	}
 
 	// workaround for the case when client is unable to handle turn and relay updates at different time
-	if m.relayCfg != nil {
-		token, err := m.GenerateRelayToken()
+	if relayCfg != nil {
+		token, err := m.GenerateRelayToken(peerKey)
 		if err == nil {
 			update.NetbirdConfig.Relay = &proto.RelayConfig{
-				Urls:           m.relayCfg.Addresses,
+				Urls:           relayCfg.Addresses,
 				TokenPayload:   token.Payload,
 				TokenSignature: token.Signature,
 			}
@@ -228,8 +352,14 @@ func (m *TimeBasedAuthSecretsManager) pushNewTURNAndRelayTokens(ctx context.Cont
 	m.updateManager.SendUpdate(ctx, peerID, &UpdateMessage{Update: update})
 }
 
-func (m *TimeBasedAuthSecretsManager) pushNewRelayTokens(ctx context.Context, accountID, peerID string) {
-	relayToken, err := m.relayHmacToken.GenerateToken()
+func (m *TimeBasedAuthSecretsManager) pushNewRelayTokens(ctx context.Context, accountID, peerID, peerKey string) {
+	_, relayCfg, _, relayHmacToken := m.getConfig()
+	if relayHmacToken == nil {
+		log.WithContext(ctx).Debugf("relay configuration was removed, not sending relay credentials to peer %s", peerID)
+		return
+	}
+
+	relayToken, err := m.GenerateRelayToken(peerKey)
 	if err != nil {
 		log.Errorf("failed to generate relay token for peer '%s': %s", peerID, err)
 		return
@@ -238,9 +368,9 @@ func (m *TimeBasedAuthSecretsManager) pushNewRelayTokens(ctx context.Context, ac
 	update := &proto.SyncResponse{
 		NetbirdConfig: &proto.NetbirdConfig{
 			Relay: &proto.RelayConfig{
-				Urls:           m.relayCfg.Addresses,
-				TokenPayload:   string(relayToken.Payload),
-				TokenSignature: base64.StdEncoding.EncodeToString(relayToken.Signature),
+				Urls:           relayCfg.Addresses,
+				TokenPayload:   relayToken.Payload,
+				TokenSignature: relayToken.Signature,
 			},
 			// omit Turns to avoid updates there
 		},
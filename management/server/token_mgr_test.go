@@ -7,6 +7,7 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"hash"
+	"strconv"
 	"testing"
 	"time"
 
@@ -16,9 +17,13 @@ import (
 	"github.com/netbirdio/netbird/management/proto"
 	"github.com/netbirdio/netbird/management/server/settings"
 	"github.com/netbirdio/netbird/management/server/types"
+	authv2 "github.com/netbirdio/netbird/relay/auth/hmac/v2"
+	"github.com/netbirdio/netbird/relay/messages"
 	"github.com/netbirdio/netbird/util"
 )
 
+const testPeerKey = "RlSy2vzoG2HyMBTUImXOiVhCBiiBa5qD5xzMxkiFDW4="
+
 var TurnTestHost = &types.Host{
 	Proto:    types.UDP,
 	URI:      "turn:turn.netbird.io:77777",
@@ -60,7 +65,7 @@ func TestTimeBasedAuthSecretsManager_GenerateCredentials(t *testing.T) {
 
 	validateMAC(t, sha1.New, turnCredentials.Payload, turnCredentials.Signature, []byte(secret))
 
-	relayCredentials, err := tested.GenerateRelayToken()
+	relayCredentials, err := tested.GenerateRelayToken(testPeerKey)
 	require.NoError(t, err)
 
 	if relayCredentials.Payload == "" {
@@ -72,6 +77,32 @@ func TestTimeBasedAuthSecretsManager_GenerateCredentials(t *testing.T) {
 
 	hashedSecret := sha256.Sum256([]byte(secret))
 	validateMAC(t, sha256.New, relayCredentials.Payload, relayCredentials.Signature, hashedSecret[:])
+
+	_, err = strconv.ParseInt(relayCredentials.Payload, 10, 64)
+	require.NoError(t, err, "the relay token should keep the payload of the older relays by default")
+
+	rc.PeerBoundTokens = true
+	require.NoError(t, tested.UpdateConfig(context.Background(), &types.TURNConfig{
+		CredentialsTTL:       ttl,
+		Secret:               secret,
+		Turns:                []*types.Host{TurnTestHost},
+		TimeBasedCredentials: true,
+	}, rc))
+
+	relayCredentials, err = tested.GenerateRelayToken(testPeerKey)
+	require.NoError(t, err)
+	validateMAC(t, sha256.New, relayCredentials.Payload, relayCredentials.Signature, hashedSecret[:])
+
+	signature, err := base64.StdEncoding.DecodeString(relayCredentials.Signature)
+	require.NoError(t, err)
+	token := (&authv2.Token{AuthAlgo: authv2.AuthAlgoHMACSHA256, Signature: signature, Payload: []byte(relayCredentials.Payload)}).Marshal()
+	_, relayPeerID := messages.HashID(testPeerKey)
+	_, otherRelayPeerID := messages.HashID("other_peer_key")
+
+	validator := authv2.NewValidator(hashedSecret[:])
+	require.NoError(t, validator.Validate(authv2.Credentials{PeerID: relayPeerID, Audiences: []string{"localhost:0"}, Token: token}))
+	require.Error(t, validator.Validate(authv2.Credentials{PeerID: otherRelayPeerID, Token: token}), "the relay token should be bound to the peer")
+	require.Error(t, validator.Validate(authv2.Credentials{PeerID: relayPeerID, Audiences: []string{"rels://other.relay:443"}, Token: token}), "the relay token should be bound to the relays")
 }
 
 func TestTimeBasedAuthSecretsManager_SetupRefresh(t *testing.T) {
@@ -102,7 +133,7 @@ func TestTimeBasedAuthSecretsManager_SetupRefresh(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	tested.SetupRefresh(ctx, "someAccountID", peer)
+	tested.SetupRefresh(ctx, "someAccountID", peer, testPeerKey)
 
 	if _, ok := tested.turnCancelMap[peer]; !ok {
 		t.Errorf("expecting peer to be present in the turn cancel map, got not present")
@@ -201,7 +232,7 @@ func TestTimeBasedAuthSecretsManager_CancelRefresh(t *testing.T) {
 		TimeBasedCredentials: true,
 	}, rc, settingsMockManager)
 
-	tested.SetupRefresh(context.Background(), "someAccountID", peer)
+	tested.SetupRefresh(context.Background(), "someAccountID", peer, testPeerKey)
 	if _, ok := tested.turnCancelMap[peer]; !ok {
 		t.Errorf("expecting peer to be present in turn cancel map, got not present")
 	}
@@ -238,3 +269,70 @@ func validateMAC(t *testing.T, algo func() hash.Hash, username string, actualMAC
 		t.Errorf("expected password MAC to be %s. got %s", expectedMAC, decodedMAC)
 	}
 }
+
+func TestTimeBasedAuthSecretsManager_UpdateConfigRotatesCredentials(t *testing.T) {
+	ttl := util.Duration{Duration: time.Hour}
+	peersManager := NewPeersUpdateManager(nil)
+	peer := "some_peer"
+	updateChannel := peersManager.CreateChannel(context.Background(), peer)
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+	settingsMockManager := settings.NewMockManager(ctrl)
+	settingsMockManager.EXPECT().GetExtraSettings(gomock.Any(), "someAccountID").Return(&types.ExtraSettings{}, nil).AnyTimes()
+
+	turnConfig := func(secret string) *types.TURNConfig {
+		return &types.TURNConfig{
+			CredentialsTTL:       ttl,
+			Secret:               secret,
+			Turns:                []*types.Host{TurnTestHost},
+			TimeBasedCredentials: true,
+		}
+	}
+	relayConfig := func(secret string) *types.Relay {
+		return &types.Relay{
+			Addresses:      []string{"localhost:0"},
+			CredentialsTTL: ttl,
+			Secret:         secret,
+		}
+	}
+
+	tested := NewTimeBasedAuthSecretsManager(peersManager, turnConfig("old_secret"), relayConfig("old_secret"), settingsMockManager)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tested.SetupRefresh(ctx, "someAccountID", peer, testPeerKey)
+
+	require.NoError(t, tested.UpdateConfig(ctx, turnConfig("new_secret"), relayConfig("new_secret")))
+
+	select {
+	case update := <-updateChannel:
+		turns := update.Update.GetNetbirdConfig().GetTurns()
+		require.Len(t, turns, 1)
+		validateMAC(t, sha1.New, turns[0].GetUser(), turns[0].GetPassword(), []byte("new_secret"))
+
+		relay := update.Update.GetNetbirdConfig().GetRelay()
+		require.NotNil(t, relay)
+		hashedSecret := sha256.Sum256([]byte("new_secret"))
+		validateMAC(t, sha256.New, relay.GetTokenPayload(), relay.GetTokenSignature(), hashedSecret[:])
+	case <-time.After(5 * time.Second):
+		t.Fatal("rotated credentials weren't sent to the peer")
+	}
+
+	tested.CancelRefresh(peer)
+	require.NoError(t, tested.UpdateConfig(ctx, turnConfig("newest_secret"), relayConfig("newest_secret")))
+
+	select {
+	case <-updateChannel:
+		t.Fatal("credentials shouldn't be sent to a peer without a refresh")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestRefreshInterval(t *testing.T) {
+	require.Equal(t, 45*time.Minute, refreshInterval(time.Hour, 0))
+	require.Equal(t, 10*time.Minute, refreshInterval(time.Hour, 10*time.Minute))
+	require.Equal(t, 45*time.Minute, refreshInterval(time.Hour, 2*time.Hour), "interval longer than the TTL should be ignored")
+	require.Equal(t, defaultDuration/4*3, refreshInterval(0, 0))
+}
@@ -0,0 +1,70 @@
+package topology
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/netbirdio/netbird/management/server/account"
+	"github.com/netbirdio/netbird/management/server/permissions"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
+	"github.com/netbirdio/netbird/management/server/types"
+)
+
+type Manager interface {
+	// GetTopology returns the effective connectivity graph of the account, or of the peer if the peer ID isn't empty
+	GetTopology(ctx context.Context, accountID, userID, peerID string) (*Topology, error)
+}
+
+type managerImpl struct {
+	store              store.Store
+	permissionsManager permissions.Manager
+	accountManager     account.Manager
+}
+
+type mockManager struct {
+}
+
+func NewManager(store store.Store, permissionsManager permissions.Manager, accountManager account.Manager) Manager {
+	return &managerImpl{
+		store:              store,
+		permissionsManager: permissionsManager,
+		accountManager:     accountManager,
+	}
+}
+
+func (m *managerImpl) GetTopology(ctx context.Context, accountID, userID, peerID string) (*Topology, error) {
+	ok, err := m.permissionsManager.ValidateUserPermissions(ctx, accountID, userID, permissions.Policies, permissions.Read)
+	if err != nil {
+		return nil, status.NewPermissionValidationError(err)
+	}
+	if !ok {
+		return nil, status.NewPermissionDeniedError()
+	}
+
+	account, err := m.store.GetAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if peerID != "" && account.GetPeer(peerID) == nil {
+		return nil, status.NewPeerNotFoundError(peerID)
+	}
+
+	validatedPeers, err := m.accountManager.GetValidatedPeers(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get validated peers: %w", err)
+	}
+
+	types.FlattenNestedGroups(account.Groups)
+
+	return Build(ctx, account, validatedPeers, peerID), nil
+}
+
+func NewManagerMock() Manager {
+	return &mockManager{}
+}
+
+func (m *mockManager) GetTopology(ctx context.Context, accountID, userID, peerID string) (*Topology, error) {
+	return &Topology{}, nil
+}
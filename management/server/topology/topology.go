@@ -0,0 +1,307 @@
+package topology
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	nbdns "github.com/netbirdio/netbird/dns"
+	"github.com/netbirdio/netbird/management/server/http/api"
+	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+	"github.com/netbirdio/netbird/management/server/types"
+	"github.com/netbirdio/netbird/route"
+)
+
+// Topology is the effective connectivity graph of an account, computed from the network maps of its peers
+type Topology struct {
+	Peers       []*Peer
+	Connections []*Connection
+	Routes      []*Route
+}
+
+// Peer is a node of the graph
+type Peer struct {
+	ID        string
+	Name      string
+	IP        string
+	Connected bool
+	// Approved is false for the peers pending approval, they don't get a network map
+	Approved bool
+}
+
+// Connection allows the source peer to connect to the destination peer
+type Connection struct {
+	SourcePeerID      string
+	DestinationPeerID string
+	Policies          []*ConnectionPolicy
+}
+
+// ConnectionPolicy is a policy rule applied to a connection
+type ConnectionPolicy struct {
+	PolicyID string
+	Name     string
+	Action   string
+	Protocol string
+	Ports    []string
+}
+
+// Route applies to the peer, the peer sends the traffic of the network through the routing peer
+type Route struct {
+	PeerID        string
+	RouteID       string
+	Name          string
+	Network       string
+	Domains       []string
+	RoutingPeerID string
+	// ExitNode is true for the default routes
+	ExitNode bool
+}
+
+type connectionKey struct {
+	source      string
+	destination string
+}
+
+type policyKey struct {
+	policyID string
+	action   string
+	protocol string
+}
+
+// Build computes the topology of the account from the network maps of the peers. If the peer ID isn't empty only
+// the connections and routes of the peer are included. The groups of the account have to be flattened.
+func Build(ctx context.Context, account *types.Account, validatedPeers map[string]struct{}, peerID string) *Topology {
+	resourcePolicies := account.GetResourcePoliciesMap()
+	routers := account.GetResourceRoutersMap()
+
+	// the firewall rules of the peers reference the policy rules
+	rulePolicies := make(map[string]*types.Policy)
+	for _, policy := range account.Policies {
+		for _, rule := range policy.Rules {
+			rulePolicies[rule.ID] = policy
+		}
+	}
+
+	peersByIP := make(map[string]*nbpeer.Peer, len(account.Peers))
+	peersByKey := make(map[string]*nbpeer.Peer, len(account.Peers))
+	for _, peer := range account.Peers {
+		peersByIP[peer.IP.String()] = peer
+		peersByKey[peer.Key] = peer
+	}
+
+	topology := &Topology{}
+	connections := make(map[connectionKey]map[policyKey]*ConnectionPolicy)
+
+	addPolicy := func(source, destination string, rule *types.FirewallRule) {
+		key := connectionKey{source: source, destination: destination}
+		if connections[key] == nil {
+			connections[key] = make(map[policyKey]*ConnectionPolicy)
+		}
+		policyID, policyName := rule.PolicyID, ""
+		if p, ok := rulePolicies[rule.PolicyID]; ok {
+			policyID, policyName = p.ID, p.Name
+		}
+		pKey := policyKey{policyID: policyID, action: rule.Action, protocol: rule.Protocol}
+		policy, ok := connections[key][pKey]
+		if !ok {
+			policy = &ConnectionPolicy{
+				PolicyID: policyID,
+				Name:     policyName,
+				Action:   rule.Action,
+				Protocol: rule.Protocol,
+			}
+			connections[key][pKey] = policy
+		}
+		if port := rulePort(rule); port != "" && !slices.Contains(policy.Ports, port) {
+			policy.Ports = append(policy.Ports, port)
+		}
+	}
+
+	for _, peer := range account.Peers {
+		_, approved := validatedPeers[peer.ID]
+		topology.Peers = append(topology.Peers, &Peer{
+			ID:        peer.ID,
+			Name:      peer.Name,
+			IP:        peer.IP.String(),
+			Connected: peer.Status != nil && peer.Status.Connected,
+			Approved:  approved,
+		})
+
+		if peerID != "" && peer.ID != peerID {
+			continue
+		}
+
+		networkMap := account.GetPeerNetworkMap(ctx, peer.ID, nbdns.CustomZone{}, validatedPeers, resourcePolicies, routers, nil)
+
+		for _, rule := range networkMap.FirewallRules {
+			for _, remote := range rulePeers(rule, networkMap.Peers, peersByIP) {
+				if rule.Direction == types.FirewallRuleDirectionOUT {
+					addPolicy(peer.ID, remote.ID, rule)
+				} else {
+					addPolicy(remote.ID, peer.ID, rule)
+				}
+			}
+		}
+
+		for _, r := range networkMap.Routes {
+			routingPeer, ok := peersByKey[r.Peer]
+			if !ok || routingPeer.ID == peer.ID {
+				continue
+			}
+			topology.Routes = append(topology.Routes, newRoute(peer.ID, routingPeer.ID, r))
+		}
+	}
+
+	for key, policies := range connections {
+		connection := &Connection{SourcePeerID: key.source, DestinationPeerID: key.destination}
+		for _, policy := range policies {
+			connection.Policies = append(connection.Policies, policy)
+		}
+		sort.Slice(connection.Policies, func(i, j int) bool {
+			a, b := connection.Policies[i], connection.Policies[j]
+			if a.PolicyID != b.PolicyID {
+				return a.PolicyID < b.PolicyID
+			}
+			return a.Action+a.Protocol < b.Action+b.Protocol
+		})
+		topology.Connections = append(topology.Connections, connection)
+	}
+
+	sort.Slice(topology.Peers, func(i, j int) bool { return topology.Peers[i].ID < topology.Peers[j].ID })
+	sort.Slice(topology.Connections, func(i, j int) bool {
+		a, b := topology.Connections[i], topology.Connections[j]
+		if a.SourcePeerID != b.SourcePeerID {
+			return a.SourcePeerID < b.SourcePeerID
+		}
+		return a.DestinationPeerID < b.DestinationPeerID
+	})
+	sort.Slice(topology.Routes, func(i, j int) bool {
+		a, b := topology.Routes[i], topology.Routes[j]
+		if a.PeerID != b.PeerID {
+			return a.PeerID < b.PeerID
+		}
+		if a.RouteID != b.RouteID {
+			return a.RouteID < b.RouteID
+		}
+		return a.RoutingPeerID < b.RoutingPeerID
+	})
+
+	return topology
+}
+
+// rulePeers returns the peers of the network map the firewall rule applies to, the rules of the All group apply to
+// all of them
+func rulePeers(rule *types.FirewallRule, mapPeers []*nbpeer.Peer, peersByIP map[string]*nbpeer.Peer) []*nbpeer.Peer {
+	if rule.PeerIP == "0.0.0.0" || rule.PeerIP == "::" {
+		return mapPeers
+	}
+
+	peer, ok := peersByIP[rule.PeerIP]
+	if !ok {
+		return nil
+	}
+
+	for _, p := range mapPeers {
+		if p.ID == peer.ID {
+			return []*nbpeer.Peer{peer}
+		}
+	}
+
+	// the peer is not reachable, e.g. its login expired
+	return nil
+}
+
+func rulePort(rule *types.FirewallRule) string {
+	if rule.Port != "" {
+		return rule.Port
+	}
+	if rule.PortRange.Start != 0 {
+		return fmt.Sprintf("%d-%d", rule.PortRange.Start, rule.PortRange.End)
+	}
+	return ""
+}
+
+func newRoute(peerID, routingPeerID string, r *route.Route) *Route {
+	// the distributed route IDs are suffixed with the routing peer ID
+	routeID, _, _ := strings.Cut(string(r.ID), ":")
+
+	topologyRoute := &Route{
+		PeerID:        peerID,
+		RouteID:       routeID,
+		Name:          string(r.NetID),
+		RoutingPeerID: routingPeerID,
+	}
+
+	if r.IsDynamic() {
+		topologyRoute.Domains = r.Domains.ToPunycodeList()
+	} else {
+		topologyRoute.Network = r.Network.String()
+		topologyRoute.ExitNode = r.Network.Bits() == 0
+	}
+
+	return topologyRoute
+}
+
+func (t *Topology) ToAPIResponse() *api.Topology {
+	response := &api.Topology{
+		Peers:       make([]api.TopologyPeer, 0, len(t.Peers)),
+		Connections: make([]api.TopologyConnection, 0, len(t.Connections)),
+		Routes:      make([]api.TopologyRoute, 0, len(t.Routes)),
+	}
+
+	for _, peer := range t.Peers {
+		response.Peers = append(response.Peers, api.TopologyPeer{
+			Id:        peer.ID,
+			Name:      peer.Name,
+			Ip:        peer.IP,
+			Connected: peer.Connected,
+			Approved:  peer.Approved,
+		})
+	}
+
+	for _, connection := range t.Connections {
+		policies := make([]api.TopologyConnectionPolicy, 0, len(connection.Policies))
+		for _, policy := range connection.Policies {
+			apiPolicy := api.TopologyConnectionPolicy{
+				PolicyId: policy.PolicyID,
+				Name:     policy.Name,
+				Action:   policy.Action,
+				Protocol: policy.Protocol,
+			}
+			if len(policy.Ports) > 0 {
+				ports := policy.Ports
+				apiPolicy.Ports = &ports
+			}
+			policies = append(policies, apiPolicy)
+		}
+
+		response.Connections = append(response.Connections, api.TopologyConnection{
+			SourcePeerId:      connection.SourcePeerID,
+			DestinationPeerId: connection.DestinationPeerID,
+			Policies:          policies,
+		})
+	}
+
+	for _, r := range t.Routes {
+		apiRoute := api.TopologyRoute{
+			PeerId:        r.PeerID,
+			RouteId:       r.RouteID,
+			Name:          r.Name,
+			RoutingPeerId: r.RoutingPeerID,
+			ExitNode:      r.ExitNode,
+		}
+		if r.Network != "" {
+			network := r.Network
+			apiRoute.Network = &network
+		}
+		if len(r.Domains) > 0 {
+			domains := r.Domains
+			apiRoute.Domains = &domains
+		}
+		response.Routes = append(response.Routes, apiRoute)
+	}
+
+	return response
+}
@@ -0,0 +1,129 @@
+package topology
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+	"github.com/netbirdio/netbird/management/server/types"
+	"github.com/netbirdio/netbird/route"
+)
+
+func newTestAccount() *types.Account {
+	newPeer := func(id, ip string) *nbpeer.Peer {
+		return &nbpeer.Peer{
+			ID:        id,
+			AccountID: "accountID",
+			Key:       id + "Key",
+			Name:      id,
+			IP:        net.ParseIP(ip),
+			Status:    &nbpeer.PeerStatus{Connected: true},
+		}
+	}
+
+	return &types.Account{
+		Id: "accountID",
+		Peers: map[string]*nbpeer.Peer{
+			"client":  newPeer("client", "100.64.0.1"),
+			"server":  newPeer("server", "100.64.0.2"),
+			"router":  newPeer("router", "100.64.0.3"),
+			"pending": newPeer("pending", "100.64.0.4"),
+		},
+		Network:  &types.Network{Identifier: "net", Net: net.IPNet{IP: net.ParseIP("100.64.0.0"), Mask: net.CIDRMask(10, 32)}},
+		Settings: &types.Settings{},
+		Groups: map[string]*types.Group{
+			"all":     {ID: "all", Name: "All", Peers: []string{"client", "server", "router", "pending"}},
+			"clients": {ID: "clients", Name: "clients", Peers: []string{"client", "pending"}},
+			"servers": {ID: "servers", Name: "servers", Peers: []string{"server"}},
+			"routers": {ID: "routers", Name: "routers", Peers: []string{"router"}},
+		},
+		Policies: []*types.Policy{
+			{
+				ID:      "ssh",
+				Name:    "SSH to servers",
+				Enabled: true,
+				Rules: []*types.PolicyRule{{
+					ID:           "sshRule",
+					PolicyID:     "ssh",
+					Enabled:      true,
+					Action:       types.PolicyTrafficActionAccept,
+					Protocol:     types.PolicyRuleProtocolTCP,
+					Ports:        []string{"22"},
+					Sources:      []string{"clients"},
+					Destinations: []string{"servers"},
+				}},
+			},
+			{
+				ID:      "routing",
+				Name:    "Clients to routers",
+				Enabled: true,
+				Rules: []*types.PolicyRule{{
+					ID:            "routingRule",
+					PolicyID:      "routing",
+					Enabled:       true,
+					Action:        types.PolicyTrafficActionAccept,
+					Protocol:      types.PolicyRuleProtocolALL,
+					Bidirectional: true,
+					Sources:       []string{"clients"},
+					Destinations:  []string{"routers"},
+				}},
+			},
+		},
+		Routes: map[route.ID]*route.Route{
+			"exit": {
+				ID:          "exit",
+				NetID:       "exit",
+				Network:     netip.MustParsePrefix("0.0.0.0/0"),
+				NetworkType: route.IPv4Network,
+				PeerGroups:  []string{"routers"},
+				Groups:      []string{"clients"},
+				Enabled:     true,
+			},
+		},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	account := newTestAccount()
+	validatedPeers := map[string]struct{}{"client": {}, "server": {}, "router": {}}
+
+	topology := Build(context.Background(), account, validatedPeers, "")
+
+	require.Len(t, topology.Peers, 4)
+	assert.False(t, topology.Peers[1].Approved, "pending peer shouldn't be approved")
+
+	connections := make(map[string]*Connection)
+	for _, connection := range topology.Connections {
+		connections[connection.SourcePeerID+"->"+connection.DestinationPeerID] = connection
+	}
+	require.Len(t, connections, 3, "pending peer shouldn't have connections")
+
+	ssh := connections["client->server"]
+	require.NotNil(t, ssh)
+	require.Len(t, ssh.Policies, 1)
+	assert.Equal(t, &ConnectionPolicy{PolicyID: "ssh", Name: "SSH to servers", Action: "accept", Protocol: "tcp", Ports: []string{"22"}}, ssh.Policies[0])
+
+	assert.Nil(t, connections["server->client"], "unidirectional policy should allow one direction only")
+	assert.NotNil(t, connections["client->router"])
+	assert.NotNil(t, connections["router->client"])
+
+	require.Len(t, topology.Routes, 1)
+	assert.Equal(t, &Route{PeerID: "client", RouteID: "exit", Name: "exit", Network: "0.0.0.0/0", RoutingPeerID: "router", ExitNode: true}, topology.Routes[0])
+}
+
+func TestBuild_Peer(t *testing.T) {
+	account := newTestAccount()
+	validatedPeers := map[string]struct{}{"client": {}, "server": {}, "router": {}}
+
+	topology := Build(context.Background(), account, validatedPeers, "server")
+
+	require.Len(t, topology.Connections, 1)
+	assert.Equal(t, "client", topology.Connections[0].SourcePeerID)
+	assert.Equal(t, "server", topology.Connections[0].DestinationPeerID)
+	assert.Empty(t, topology.Routes, "routes of the other peers shouldn't be included")
+}
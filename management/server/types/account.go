@@ -17,6 +17,7 @@ import (
 
 	nbdns "github.com/netbirdio/netbird/dns"
 	"github.com/netbirdio/netbird/management/domain"
+	"github.com/netbirdio/netbird/management/server/labels"
 	resourceTypes "github.com/netbirdio/netbird/management/server/networks/resources/types"
 	routerTypes "github.com/netbirdio/netbird/management/server/networks/routers/types"
 	networkTypes "github.com/netbirdio/netbird/management/server/networks/types"
@@ -36,6 +37,9 @@ const (
 	PublicCategory  = "public"
 	PrivateCategory = "private"
 	UnknownCategory = "unknown"
+
+	// routeNSGroupPrefix prefixes the IDs of the nameserver groups of the routes, unlike the xid of the other groups
+	routeNSGroupPrefix = "route:"
 )
 
 type LookupMap map[string]struct{}
@@ -73,6 +77,9 @@ type Account struct {
 	Networks         []*networkTypes.Network          `gorm:"foreignKey:AccountID;references:id"`
 	NetworkRouters   []*routerTypes.NetworkRouter     `gorm:"foreignKey:AccountID;references:id"`
 	NetworkResources []*resourceTypes.NetworkResource `gorm:"foreignKey:AccountID;references:id"`
+
+	// Rollouts are the staged route and policy changes of the account
+	Rollouts []*Rollout `gorm:"foreignKey:AccountID;references:id"`
 }
 
 // Subclass used in gorm to only load network and not whole account
@@ -213,6 +220,7 @@ func (a *Account) GetGroup(groupID string) *Group {
 }
 
 // GetPeerNetworkMap returns the networkmap for the given peer ID.
+// The network map of a peer in the login expiration grace period is limited to the remediation policies.
 func (a *Account) GetPeerNetworkMap(
 	ctx context.Context,
 	peerID string,
@@ -221,6 +229,45 @@ func (a *Account) GetPeerNetworkMap(
 	resourcePolicies map[string][]*Policy,
 	routers map[string]map[string]*routerTypes.NetworkRouter,
 	metrics *telemetry.AccountManagerMetrics,
+) *NetworkMap {
+	return a.GetPeerNetworkMapWithCache(ctx, peerID, peersCustomZone, validatedPeersMap, resourcePolicies, routers, metrics, nil)
+}
+
+// GetPeerNetworkMapWithCache returns the networkmap for the given peer ID sharing the fragments of the cache with the
+// network maps of the other peers generated from the same account state. The returned map must not be modified.
+func (a *Account) GetPeerNetworkMapWithCache(
+	ctx context.Context,
+	peerID string,
+	peersCustomZone nbdns.CustomZone,
+	validatedPeersMap map[string]struct{},
+	resourcePolicies map[string][]*Policy,
+	routers map[string]map[string]*routerTypes.NetworkRouter,
+	metrics *telemetry.AccountManagerMetrics,
+	cache *NetworkMapCache,
+) *NetworkMap {
+	// the staged versions of the routes and the policies differ from the ones the cache was filled from
+	if canary, ok := a.canaryAccount(peerID); ok {
+		return canary.GetPeerNetworkMapWithCache(ctx, peerID, peersCustomZone, validatedPeersMap, resourcePolicies, routers, metrics, nil)
+	}
+
+	if peer := a.Peers[peerID]; peer != nil && a.peerInLoginExpirationGrace(peer) {
+		nm := a.remediationAccount().getPeerNetworkMap(ctx, peerID, peersCustomZone, validatedPeersMap, remediationResourcePolicies(resourcePolicies), routers, metrics, cache)
+		nm.LoginExpired = true
+		return nm
+	}
+
+	return a.getPeerNetworkMap(ctx, peerID, peersCustomZone, validatedPeersMap, resourcePolicies, routers, metrics, cache)
+}
+
+func (a *Account) getPeerNetworkMap(
+	ctx context.Context,
+	peerID string,
+	peersCustomZone nbdns.CustomZone,
+	validatedPeersMap map[string]struct{},
+	resourcePolicies map[string][]*Policy,
+	routers map[string]map[string]*routerTypes.NetworkRouter,
+	metrics *telemetry.AccountManagerMetrics,
+	cache *NetworkMapCache,
 ) *NetworkMap {
 	start := time.Now()
 
@@ -237,20 +284,22 @@ func (a *Account) GetPeerNetworkMap(
 		}
 	}
 
-	aclPeers, firewallRules := a.GetPeerConnectionResources(ctx, peerID, validatedPeersMap)
-	// exclude expired peers
+	aclPeers, firewallRules := a.getPeerConnectionResources(ctx, peerID, validatedPeersMap, cache)
+	// exclude expired peers, except the ones in the login expiration grace period the peer is a remediation target of
+	sessionSettings := cache.getSessionSettings(a)
+	remediationPeers := a.getRemediationPeers(ctx, peerID, validatedPeersMap, cache)
 	var peersToConnect []*nbpeer.Peer
 	var expiredPeers []*nbpeer.Peer
 	for _, p := range aclPeers {
-		expired, _ := p.LoginExpired(a.Settings.PeerLoginExpiration)
-		if a.Settings.PeerLoginExpirationEnabled && expired {
+		expired, _ := sessionSettings(p.ID).LoginExpired(p)
+		if _, ok := remediationPeers[p.ID]; expired && !ok {
 			expiredPeers = append(expiredPeers, p)
 			continue
 		}
 		peersToConnect = append(peersToConnect, p)
 	}
 
-	routesUpdate := a.GetRoutesToSync(ctx, peerID, peersToConnect)
+	routesUpdate := filterExceededExitRoutes(peer, a.GetRoutesToSync(ctx, peerID, peersToConnect))
 	routesFirewallRules := a.GetPeerRoutesFirewallRules(ctx, peerID, validatedPeersMap)
 	isRouter, networkResourcesRoutes, sourcePeers := a.GetNetworkResourcesRoutesToSync(ctx, peerID, resourcePolicies, routers)
 	var networkResourcesFirewallRules []*RouteFirewallRule
@@ -271,7 +320,7 @@ func (a *Account) GetPeerNetworkMap(
 			zones = append(zones, peersCustomZone)
 		}
 		dnsUpdate.CustomZones = zones
-		dnsUpdate.NameServerGroups = getPeerNSGroups(a, peerID)
+		dnsUpdate.NameServerGroups = append(getPeerNSGroups(a, peerID, peersToConnectIncludingRouters), getRoutesNSGroups(routesUpdate)...)
 	}
 
 	nm := &NetworkMap{
@@ -282,6 +331,15 @@ func (a *Account) GetPeerNetworkMap(
 		OfflinePeers:        expiredPeers,
 		FirewallRules:       firewallRules,
 		RoutesFirewallRules: slices.Concat(networkResourcesFirewallRules, routesFirewallRules),
+		LoginExpiresAt:      sessionSettings(peerID).LoginExpiresAt(peer),
+
+		LoginExpirationNotification: a.Settings.PeerLoginExpirationNotification,
+		LoginExpirationGrace:        a.Settings.PeerLoginExpirationGraceEnabled,
+		ConnectionTuning:            a.getPeerConnectionTuning(peerID),
+		PortPolicy:                  a.getPeerPortPolicy(peerID),
+		ClientSettings:              a.Settings.ClientSettings.Copy(),
+		DisabledNotifications:       slices.Clone(a.Settings.DisabledNotifications),
+		FailedPostureChecks:         a.getPeerFailedPostureChecks(ctx, peerID),
 	}
 
 	if metrics != nil {
@@ -344,7 +402,7 @@ func (a *Account) addNetworksRoutingPeers(
 	return peersToConnect
 }
 
-func getPeerNSGroups(account *Account, peerID string) []*nbdns.NameServerGroup {
+func getPeerNSGroups(account *Account, peerID string, peersToConnect []*nbpeer.Peer) []*nbdns.NameServerGroup {
 	groupList := account.GetPeerGroups(peerID)
 
 	var peerNSGroups []*nbdns.NameServerGroup
@@ -353,9 +411,20 @@ func getPeerNSGroups(account *Account, peerID string) []*nbdns.NameServerGroup {
 		if !nsGroup.Enabled {
 			continue
 		}
+		// the forwarder peer resolves the domains of the group with the nameservers for the other peers
+		if nsGroup.ForwarderPeer == peerID {
+			peerNSGroups = append(peerNSGroups, nsGroup.Copy())
+			continue
+		}
 		for _, gID := range nsGroup.Groups {
 			_, found := groupList[gID]
 			if found {
+				if nsGroup.ForwarderPeer != "" {
+					if forwarded := forwardedNSGroup(nsGroup, peersToConnect); forwarded != nil {
+						peerNSGroups = append(peerNSGroups, forwarded)
+					}
+					break
+				}
 				if !peerIsNameserver(account.GetPeer(peerID), nsGroup) {
 					peerNSGroups = append(peerNSGroups, nsGroup.Copy())
 					break
@@ -367,6 +436,64 @@ func getPeerNSGroups(account *Account, peerID string) []*nbdns.NameServerGroup {
 	return peerNSGroups
 }
 
+// forwardedNSGroup returns the nameserver group with the DNS forwarder of its forwarder peer as the only nameserver,
+// nil if the peer doesn't connect to the forwarder peer. Only the copy of the forwarder peer keeps the ForwarderPeer.
+func forwardedNSGroup(nsGroup *nbdns.NameServerGroup, peersToConnect []*nbpeer.Peer) *nbdns.NameServerGroup {
+	for _, p := range peersToConnect {
+		if p.ID != nsGroup.ForwarderPeer {
+			continue
+		}
+
+		ip, ok := netip.AddrFromSlice(p.IP)
+		if !ok {
+			return nil
+		}
+
+		forwarded := nsGroup.Copy()
+		forwarded.NameServers = []nbdns.NameServer{{
+			IP:     ip.Unmap(),
+			NSType: nbdns.UDPNameServerType,
+			Port:   nbdns.ForwarderDNSPort,
+		}}
+		forwarded.ForwarderPeer = ""
+		return forwarded
+	}
+	return nil
+}
+
+// getRoutesNSGroups returns the nameserver groups of the DNS configuration carried by the routes of the peer, the
+// routes of an HA group share one
+func getRoutesNSGroups(routes []*route.Route) []*nbdns.NameServerGroup {
+	var nsGroups []*nbdns.NameServerGroup
+	seen := make(map[route.HAUniqueID]struct{})
+	for _, r := range routes {
+		if !r.Enabled || !r.HasDNS() {
+			continue
+		}
+
+		haID := r.GetHAUniqueID()
+		if _, ok := seen[haID]; ok {
+			continue
+		}
+		seen[haID] = struct{}{}
+
+		nameServers := make([]nbdns.NameServer, 0, len(r.DNSServers))
+		for _, ip := range r.DNSServers {
+			nameServers = append(nameServers, nbdns.NameServer{IP: ip, NSType: nbdns.UDPNameServerType, Port: nbdns.DefaultDNSPort})
+		}
+
+		nsGroups = append(nsGroups, &nbdns.NameServerGroup{
+			ID:                   routeNSGroupPrefix + string(haID),
+			Name:                 string(r.NetID),
+			NameServers:          nameServers,
+			Domains:              slices.Clone(r.DNSDomains),
+			Enabled:              true,
+			SearchDomainsEnabled: r.DNSSearchDomainsEnabled,
+		})
+	}
+	return nsGroups
+}
+
 // peerIsNameserver returns true if the peer is a nameserver for a nsGroup
 func peerIsNameserver(peer *nbpeer.Peer, nsGroup *nbdns.NameServerGroup) bool {
 	for _, ns := range nsGroup.NameServers {
@@ -457,7 +584,7 @@ func (a *Account) GetPeersCustomZone(ctx context.Context, dnsDomain string) nbdn
 		})
 		sb.Reset()
 
-		for _, extraLabel := range peer.ExtraDNSLabels {
+		for _, extraLabel := range slices.Concat(peer.ExtraDNSLabels, peer.DNSAliases) {
 			sb.Grow(len(extraLabel) + len(domainSuffix))
 			sb.WriteString(extraLabel)
 			sb.WriteString(domainSuffix)
@@ -764,6 +891,9 @@ func (a *Account) GetPeerDNSLabels() LookupMap {
 		if peer.DNSLabel != "" {
 			existingLabels[peer.DNSLabel] = struct{}{}
 		}
+		for _, alias := range peer.DNSAliases {
+			existingLabels[alias] = struct{}{}
+		}
 	}
 	return existingLabels
 }
@@ -831,6 +961,11 @@ func (a *Account) Copy() *Account {
 		networkResources = append(networkResources, resource.Copy())
 	}
 
+	rollouts := []*Rollout{}
+	for _, rollout := range a.Rollouts {
+		rollouts = append(rollouts, rollout.Copy())
+	}
+
 	return &Account{
 		Id:                     a.Id,
 		CreatedBy:              a.CreatedBy,
@@ -852,6 +987,7 @@ func (a *Account) Copy() *Account {
 		Networks:               nets,
 		NetworkRouters:         networkRouters,
 		NetworkResources:       networkResources,
+		Rollouts:               rollouts,
 	}
 }
 
@@ -941,7 +1077,11 @@ func (a *Account) UserGroupsRemoveFromPeers(userID string, groups ...string) map
 //
 // This function returns the list of peers and firewall rules that are applicable to a given peer.
 func (a *Account) GetPeerConnectionResources(ctx context.Context, peerID string, validatedPeersMap map[string]struct{}) ([]*nbpeer.Peer, []*FirewallRule) {
-	generateResources, getAccumulatedResources := a.connResourcesGenerator(ctx)
+	return a.getPeerConnectionResources(ctx, peerID, validatedPeersMap, nil)
+}
+
+func (a *Account) getPeerConnectionResources(ctx context.Context, peerID string, validatedPeersMap map[string]struct{}, cache *NetworkMapCache) ([]*nbpeer.Peer, []*FirewallRule) {
+	generateResources, getAccumulatedResources := a.connResourcesGenerator(ctx, peerID, cache)
 	for _, policy := range a.Policies {
 		if !policy.Enabled {
 			continue
@@ -952,8 +1092,9 @@ func (a *Account) GetPeerConnectionResources(ctx context.Context, peerID string,
 				continue
 			}
 
-			sourcePeers, peerInSources := a.getAllPeersFromGroups(ctx, rule.Sources, peerID, policy.SourcePostureChecks, validatedPeersMap)
-			destinationPeers, peerInDestinations := a.getAllPeersFromGroups(ctx, rule.Destinations, peerID, nil, validatedPeersMap)
+			sourcePeers := a.getAllPeersFromGroups(ctx, rule.Sources, rule.SourceLabels, policy.SourcePostureChecks, validatedPeersMap, cache)
+			destinationPeers := a.getAllPeersFromGroups(ctx, rule.Destinations, rule.DestinationLabels, nil, validatedPeersMap, cache)
+			peerInSources, peerInDestinations := sourcePeers.contains(peerID), destinationPeers.contains(peerID)
 
 			if rule.Bidirectional {
 				if peerInSources {
@@ -982,8 +1123,8 @@ func (a *Account) GetPeerConnectionResources(ctx context.Context, peerID string,
 // The generator function is used to generate the list of peers and firewall rules that are applicable to a given peer.
 // It safe to call the generator function multiple times for same peer and different rules no duplicates will be
 // generated. The accumulator function returns the result of all the generator calls.
-func (a *Account) connResourcesGenerator(ctx context.Context) (func(*PolicyRule, []*nbpeer.Peer, int), func() ([]*nbpeer.Peer, []*FirewallRule)) {
-	rulesExists := make(map[string]struct{})
+func (a *Account) connResourcesGenerator(ctx context.Context, peerID string, cache *NetworkMapCache) (func(*PolicyRule, *peerSet, int), func() ([]*nbpeer.Peer, []*FirewallRule)) {
+	rulesExists := make(map[firewallRuleKey]struct{})
 	peersExists := make(map[string]struct{})
 	rules := make([]*FirewallRule, 0)
 	peers := make([]*nbpeer.Peer, 0)
@@ -994,10 +1135,24 @@ func (a *Account) connResourcesGenerator(ctx context.Context) (func(*PolicyRule,
 		all = &Group{}
 	}
 
-	return func(rule *PolicyRule, groupPeers []*nbpeer.Peer, direction int) {
-			isAll := (len(all.Peers) - 1) == len(groupPeers)
-			for _, peer := range groupPeers {
-				if peer == nil {
+	addRule := func(key firewallRuleKey, rule *PolicyRule, fr FirewallRule) {
+		if _, ok := rulesExists[key]; ok {
+			return
+		}
+		rulesExists[key] = struct{}{}
+		rules = append(rules, expandRulePorts(rule, fr)...)
+	}
+
+	return func(rule *PolicyRule, groupPeers *peerSet, direction int) {
+			otherPeers := len(groupPeers.peers)
+			if groupPeers.contains(peerID) {
+				otherPeers--
+			}
+			isAll := (len(all.Peers) - 1) == otherPeers
+			ports := strings.Join(rule.Ports, ",")
+
+			for _, peer := range groupPeers.peers {
+				if peer == nil || peer.ID == peerID {
 					continue
 				}
 
@@ -1006,34 +1161,50 @@ func (a *Account) connResourcesGenerator(ctx context.Context) (func(*PolicyRule,
 					peersExists[peer.ID] = struct{}{}
 				}
 
-				fr := FirewallRule{
+				if isAll {
+					continue
+				}
+
+				ip := cache.getPeerIP(peer)
+				key := firewallRuleKey{ruleID: rule.ID, peerIP: ip, direction: direction, protocol: string(rule.Protocol), action: string(rule.Action), ports: ports}
+				addRule(key, rule, FirewallRule{
 					PolicyID:  rule.ID,
-					PeerIP:    peer.IP.String(),
+					PeerIP:    ip,
 					Direction: direction,
 					Action:    string(rule.Action),
 					Protocol:  string(rule.Protocol),
-				}
+				})
+			}
 
-				if isAll {
-					fr.PeerIP = "0.0.0.0"
-				}
+			if !isAll || otherPeers == 0 {
+				return
+			}
 
-				ruleID := rule.ID + fr.PeerIP + strconv.Itoa(direction) +
-					fr.Protocol + fr.Action + strings.Join(rule.Ports, ",")
-				if _, ok := rulesExists[ruleID]; ok {
-					continue
-				}
-				rulesExists[ruleID] = struct{}{}
+			// the rules of a group with all the other peers are the same for every peer, they are generated once
+			// and shared by the network maps
+			allRules := cache.getAllPeersRules(allPeersRuleKey{ruleID: rule.ID, direction: direction}, func() []*FirewallRule {
+				return expandRulePorts(rule, FirewallRule{
+					PolicyID:  rule.ID,
+					PeerIP:    "0.0.0.0",
+					Direction: direction,
+					Action:    string(rule.Action),
+					Protocol:  string(rule.Protocol),
+				})
+			})
 
-				if len(rule.Ports) == 0 {
-					rules = append(rules, &fr)
-					continue
+			// the port rules of an IP share the key, they are added if the key wasn't added by a previous call
+			added := make(map[firewallRuleKey]bool, 2)
+			for _, fr := range allRules {
+				key := firewallRuleKey{ruleID: rule.ID, peerIP: fr.PeerIP, direction: direction, protocol: fr.Protocol, action: fr.Action, ports: ports}
+				add, ok := added[key]
+				if !ok {
+					_, exists := rulesExists[key]
+					add = !exists
+					added[key] = add
+					rulesExists[key] = struct{}{}
 				}
-
-				for _, port := range rule.Ports {
-					pr := fr // clone rule and add set new port
-					pr.Port = port
-					rules = append(rules, &pr)
+				if add {
+					rules = append(rules, fr)
 				}
 			}
 		}, func() ([]*nbpeer.Peer, []*FirewallRule) {
@@ -1041,42 +1212,64 @@ func (a *Account) connResourcesGenerator(ctx context.Context) (func(*PolicyRule,
 		}
 }
 
-// getAllPeersFromGroups for given peer ID and list of groups
+// firewallRuleKey identifies the firewall rules generated for a peer IP by a policy rule
+type firewallRuleKey struct {
+	ruleID    string
+	peerIP    string
+	direction int
+	protocol  string
+	action    string
+	ports     string
+}
+
+// expandRulePorts returns the firewall rule for each port of the policy rule, or the rule itself if it has no ports
+func expandRulePorts(rule *PolicyRule, fr FirewallRule) []*FirewallRule {
+	if len(rule.Ports) == 0 {
+		return []*FirewallRule{&fr}
+	}
+
+	rules := make([]*FirewallRule, 0, len(rule.Ports))
+	for _, port := range rule.Ports {
+		pr := fr // clone rule and add set new port
+		pr.Port = port
+		rules = append(rules, &pr)
+	}
+	return rules
+}
+
+// getAllPeersFromGroups for given list of groups
 //
-// Returns a list of peers from specified groups that pass specified posture checks
-// and a boolean indicating if the supplied peer ID exists within these groups.
+// Returns the set of peers from specified groups that pass specified posture checks. The set is shared through the
+// cache and includes the peer the network map is generated for if it is a member of the groups.
 //
 // Important: Posture checks are applicable only to source group peers,
 // for destination group peers, call this method with an empty list of sourcePostureChecksIDs
-func (a *Account) getAllPeersFromGroups(ctx context.Context, groups []string, peerID string, sourcePostureChecksIDs []string, validatedPeersMap map[string]struct{}) ([]*nbpeer.Peer, bool) {
-	peerInGroups := false
-	uniquePeerIDs := a.getUniquePeerIDsFromGroupsIDs(ctx, groups)
-	filteredPeers := make([]*nbpeer.Peer, 0, len(uniquePeerIDs))
-	for _, p := range uniquePeerIDs {
-		peer, ok := a.Peers[p]
-		if !ok || peer == nil {
-			continue
-		}
+func (a *Account) getAllPeersFromGroups(ctx context.Context, groups []string, labelSelector map[string]string, sourcePostureChecksIDs []string, validatedPeersMap map[string]struct{}, cache *NetworkMapCache) *peerSet {
+	key := strings.Join(groups, ",") + "/" + labels.String(labelSelector) + "/" + strings.Join(sourcePostureChecksIDs, ",")
+	return cache.getGroupPeers(key, func() *peerSet {
+		uniquePeerIDs := a.getUniquePeerIDsFromGroupsIDs(ctx, groups)
+		uniquePeerIDs = append(slices.Clip(uniquePeerIDs), a.getPeerIDsWithLabels(labelSelector)...)
+		filteredPeers := newPeerSet(len(uniquePeerIDs))
+		for _, p := range uniquePeerIDs {
+			peer, ok := a.Peers[p]
+			if !ok || peer == nil {
+				continue
+			}
 
-		// validate the peer based on policy posture checks applied
-		isValid := a.validatePostureChecksOnPeer(ctx, sourcePostureChecksIDs, peer.ID)
-		if !isValid {
-			continue
-		}
+			// validate the peer based on policy posture checks applied
+			isValid := a.validatePostureChecksOnPeer(ctx, sourcePostureChecksIDs, peer.ID)
+			if !isValid {
+				continue
+			}
 
-		if _, ok := validatedPeersMap[peer.ID]; !ok {
-			continue
-		}
+			if _, ok := validatedPeersMap[peer.ID]; !ok {
+				continue
+			}
 
-		if peer.ID == peerID {
-			peerInGroups = true
-			continue
+			filteredPeers.add(peer)
 		}
-
-		filteredPeers = append(filteredPeers, peer)
-	}
-
-	return filteredPeers, peerInGroups
+		return filteredPeers
+	})
 }
 
 // validatePostureChecksOnPeer validates the posture checks on a peer
@@ -1120,8 +1313,17 @@ func (a *Account) GetPeerRoutesFirewallRules(ctx context.Context, peerID string,
 
 	enabledRoutes, _ := a.getRoutingPeerRoutes(ctx, peerID)
 	for _, route := range enabledRoutes {
-		// If no access control groups are specified, accept all traffic.
-		if len(route.AccessControlGroups) == 0 {
+		labelPolicies := GetRoutePoliciesFromLabels(a, route.Labels)
+
+		// If no access control groups are specified and no policy selects the route by its labels, accept all traffic,
+		// or the traffic the policies allow to the routing peer when the routing peers filter the forwarded traffic.
+		if len(route.AccessControlGroups) == 0 && len(labelPolicies) == 0 {
+			if a.Settings != nil && a.Settings.RoutingPeerForwardedTrafficFilteringEnabled {
+				rules := a.getRouteFirewallRules(ctx, peerID, a.getPoliciesToPeer(peerID), route, validatedPeersMap, a.getDistributionGroupsPeers(route))
+				routesFirewallRules = append(routesFirewallRules, rules...)
+				continue
+			}
+
 			defaultPermit := getDefaultPermit(route)
 			routesFirewallRules = append(routesFirewallRules, defaultPermit...)
 			continue
@@ -1134,11 +1336,67 @@ func (a *Account) GetPeerRoutesFirewallRules(ctx context.Context, peerID string,
 			rules := a.getRouteFirewallRules(ctx, peerID, policies, route, validatedPeersMap, distributionPeers)
 			routesFirewallRules = append(routesFirewallRules, rules...)
 		}
+
+		rules := a.getRouteFirewallRules(ctx, peerID, labelPolicies, route, validatedPeersMap, distributionPeers)
+		routesFirewallRules = append(routesFirewallRules, rules...)
 	}
 
 	return routesFirewallRules
 }
 
+// getPoliciesToPeer returns the enabled policies with the rules of the traffic to the peer: the rules with the peer in
+// their destinations and the bidirectional rules with the peer in their sources, turned around
+func (a *Account) getPoliciesToPeer(peerID string) []*Policy {
+	peerGroups := a.GetPeerGroups(peerID)
+	peer := a.Peers[peerID]
+	selects := func(groups []string, selector map[string]string) bool {
+		if slices.ContainsFunc(groups, func(groupID string) bool {
+			_, ok := peerGroups[groupID]
+			return ok
+		}) {
+			return true
+		}
+		return peer != nil && len(selector) > 0 && labels.Matches(selector, peer.Labels)
+	}
+
+	var policies []*Policy
+	for _, policy := range a.Policies {
+		if !policy.Enabled {
+			continue
+		}
+
+		var rules, reversedRules []*PolicyRule
+		for _, rule := range policy.Rules {
+			if !rule.Enabled {
+				continue
+			}
+			if selects(rule.Destinations, rule.DestinationLabels) {
+				rules = append(rules, rule)
+			}
+			if rule.Bidirectional && selects(rule.Sources, rule.SourceLabels) {
+				reversed := rule.Copy()
+				reversed.Sources, reversed.Destinations = rule.Destinations, rule.Sources
+				reversed.SourceLabels, reversed.DestinationLabels = rule.DestinationLabels, rule.SourceLabels
+				reversedRules = append(reversedRules, reversed)
+			}
+		}
+
+		if len(rules) > 0 {
+			toPeer := policy.Copy()
+			toPeer.Rules = rules
+			policies = append(policies, toPeer)
+		}
+		// the posture checks apply to the sources of the policy only
+		if len(reversedRules) > 0 {
+			reversed := policy.Copy()
+			reversed.Rules = reversedRules
+			reversed.SourcePostureChecks = nil
+			policies = append(policies, reversed)
+		}
+	}
+	return policies
+}
+
 func (a *Account) getRouteFirewallRules(ctx context.Context, peerID string, policies []*Policy, route *route.Route, validatedPeersMap map[string]struct{}, distributionPeers map[string]struct{}) []*RouteFirewallRule {
 	var fwRules []*RouteFirewallRule
 	for _, policy := range policies {
@@ -1161,13 +1419,8 @@ func (a *Account) getRouteFirewallRules(ctx context.Context, peerID string, poli
 
 func (a *Account) getRulePeers(rule *PolicyRule, postureChecks []string, peerID string, distributionPeers map[string]struct{}, validatedPeersMap map[string]struct{}) []*nbpeer.Peer {
 	distPeersWithPolicy := make(map[string]struct{})
-	for _, id := range rule.Sources {
-		group := a.Groups[id]
-		if group == nil {
-			continue
-		}
-
-		for _, pID := range group.Peers {
+	addPeers := func(peerIDs []string) {
+		for _, pID := range peerIDs {
 			if pID == peerID {
 				continue
 			}
@@ -1179,6 +1432,15 @@ func (a *Account) getRulePeers(rule *PolicyRule, postureChecks []string, peerID
 		}
 	}
 
+	for _, id := range rule.Sources {
+		group := a.Groups[id]
+		if group == nil {
+			continue
+		}
+		addPeers(group.Peers)
+	}
+	addPeers(a.getPeerIDsWithLabels(rule.SourceLabels))
+
 	distributionGroupPeers := make([]*nbpeer.Peer, 0, len(distPeersWithPolicy))
 	for pID := range distPeersWithPolicy {
 		peer := a.Peers[pID]
@@ -1190,7 +1452,10 @@ func (a *Account) getRulePeers(rule *PolicyRule, postureChecks []string, peerID
 	return distributionGroupPeers
 }
 
+// getDistributionGroupsPeers returns the peers the route is distributed to, the peers that exceeded their exit node
+// quota are left out for the exit routes
 func (a *Account) getDistributionGroupsPeers(route *route.Route) map[string]struct{} {
+	exitRoute := route.IsDefault()
 	distPeers := make(map[string]struct{})
 	for _, id := range route.Groups {
 		group := a.Groups[id]
@@ -1199,6 +1464,9 @@ func (a *Account) getDistributionGroupsPeers(route *route.Route) map[string]stru
 		}
 
 		for _, pID := range group.Peers {
+			if peer := a.Peers[pID]; exitRoute && peer != nil && peer.ExitNodeQuotaExceeded {
+				continue
+			}
 			distPeers[pID] = struct{}{}
 		}
 	}
@@ -1259,6 +1527,27 @@ func GetAllRoutePoliciesFromGroups(account *Account, accessControlGroups []strin
 	return routePolicies
 }
 
+// GetRoutePoliciesFromLabels returns the policies with rules selecting the destinations by labels of the route
+func GetRoutePoliciesFromLabels(account *Account, routeLabels map[string]string) []*Policy {
+	if len(routeLabels) == 0 {
+		return nil
+	}
+
+	var routePolicies []*Policy
+	for _, policy := range account.Policies {
+		if !policy.Enabled {
+			continue
+		}
+		if slices.ContainsFunc(policy.Rules, func(rule *PolicyRule) bool {
+			return labels.Matches(rule.DestinationLabels, routeLabels)
+		}) {
+			routePolicies = append(routePolicies, policy)
+		}
+	}
+
+	return routePolicies
+}
+
 // GetPeerNetworkResourceFirewallRules gets the network resources firewall rules associated with a routing peer ID for the account.
 func (a *Account) GetPeerNetworkResourceFirewallRules(ctx context.Context, peer *nbpeer.Peer, validatedPeersMap map[string]struct{}, routes []*route.Route, resourcePolicies map[string][]*Policy) []*RouteFirewallRule {
 	routesFirewallRules := make([]*RouteFirewallRule, 0)
@@ -1268,7 +1557,7 @@ func (a *Account) GetPeerNetworkResourceFirewallRules(ctx context.Context, peer
 			continue
 		}
 		resourceAppliedPolicies := resourcePolicies[route.GetResourceID()]
-		distributionPeers := getPoliciesSourcePeers(resourceAppliedPolicies, a.Groups)
+		distributionPeers := a.getPoliciesSourcePeers(resourceAppliedPolicies)
 
 		rules := a.getRouteFirewallRules(ctx, peer.ID, resourceAppliedPolicies, route, validatedPeersMap, distributionPeers)
 		for _, rule := range rules {
@@ -1305,11 +1594,34 @@ func (a *Account) GetResourcePoliciesMap() map[string][]*Policy {
 		}
 
 		resourceAppliedPolicies := a.GetPoliciesForNetworkResource(resource.ID)
-		resourcePolicies[resource.ID] = resourceAppliedPolicies
+		resourcePolicies[resource.ID] = scopeResourcePolicies(resourceAppliedPolicies, resource.Scope)
 	}
 	return resourcePolicies
 }
 
+// scopeResourcePolicies restricts the rules of the policies to the scope of a port-scoped resource, so the routing
+// peers only allow the protocol and the ports of the resource. The policies without matching rules are left out.
+func scopeResourcePolicies(policies []*Policy, scope resourceTypes.Scope) []*Policy {
+	if scope.IsZero() {
+		return policies
+	}
+
+	scoped := make([]*Policy, 0, len(policies))
+	for _, policy := range policies {
+		c := policy.Copy()
+		c.Rules = c.Rules[:0]
+		for _, rule := range policy.Rules {
+			if r, ok := rule.ScopedTo(PolicyRuleProtocolType(scope.Protocol), scope.StartPort, scope.EndPort); ok {
+				c.Rules = append(c.Rules, r)
+			}
+		}
+		if len(c.Rules) > 0 {
+			scoped = append(scoped, c)
+		}
+	}
+	return scoped
+}
+
 // GetNetworkResourcesRoutesToSync returns network routes for syncing with a specific peer and its ACL peers.
 func (a *Account) GetNetworkResourcesRoutesToSync(ctx context.Context, peerID string, resourcePolicies map[string][]*Policy, routers map[string]map[string]*routerTypes.NetworkRouter) (bool, []*route.Route, map[string]struct{}) {
 	var isRoutingPeer bool
@@ -1333,7 +1645,7 @@ func (a *Account) GetNetworkResourcesRoutesToSync(ctx context.Context, peerID st
 
 		addedResourceRoute := false
 		for _, policy := range resourcePolicies[resource.ID] {
-			peers := a.getUniquePeerIDsFromGroupsIDs(ctx, policy.SourceGroups())
+			peers := a.getPolicySourcePeerIDs(ctx, policy)
 			if addSourcePeers {
 				for _, pID := range a.getPostureValidPeers(peers, policy.SourcePostureChecks) {
 					allSourcePeers[pID] = struct{}{}
@@ -1390,6 +1702,45 @@ func (a *Account) getUniquePeerIDsFromGroupsIDs(ctx context.Context, groups []st
 	return ids
 }
 
+// getPeerIDsWithLabels returns the IDs of the peers having all the labels of the selector, none if it is empty
+func (a *Account) getPeerIDsWithLabels(selector map[string]string) []string {
+	if len(selector) == 0 {
+		return nil
+	}
+
+	var ids []string
+	for id, peer := range a.Peers {
+		if labels.Matches(selector, peer.Labels) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// getPolicySourcePeerIDs returns the unique IDs of the peers of the source groups and of the peers selected by the
+// source labels of the policy rules
+func (a *Account) getPolicySourcePeerIDs(ctx context.Context, policy *Policy) []string {
+	peerIDs := a.getUniquePeerIDsFromGroupsIDs(ctx, policy.SourceGroups())
+
+	var labeled []string
+	for _, rule := range policy.Rules {
+		labeled = append(labeled, a.getPeerIDsWithLabels(rule.SourceLabels)...)
+	}
+	if len(labeled) == 0 {
+		return peerIDs
+	}
+
+	unique := make(map[string]struct{}, len(peerIDs)+len(labeled))
+	ids := make([]string, 0, len(peerIDs)+len(labeled))
+	for _, id := range slices.Concat(peerIDs, labeled) {
+		if _, ok := unique[id]; !ok {
+			unique[id] = struct{}{}
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
 // getNetworkResources filters and returns a list of network resources associated with the given network ID.
 func (a *Account) getNetworkResources(networkID string) []*resourceTypes.NetworkResource {
 	var resources []*resourceTypes.NetworkResource
@@ -1402,13 +1753,21 @@ func (a *Account) getNetworkResources(networkID string) []*resourceTypes.Network
 }
 
 // GetPoliciesForNetworkResource retrieves the list of policies that apply to a specific network resource.
-// A policy is deemed applicable if its destination groups include any of the given network resource groups
-// or if its destination resource explicitly matches the provided resource.
+// A policy is deemed applicable if its destination groups include any of the given network resource groups,
+// if its destination resource explicitly matches the provided resource or if its destination labels select it.
 func (a *Account) GetPoliciesForNetworkResource(resourceId string) []*Policy {
 	var resourceAppliedPolicies []*Policy
 
 	networkResourceGroups := a.getNetworkResourceGroups(resourceId)
 
+	var resourceLabels map[string]string
+	for _, resource := range a.NetworkResources {
+		if resource.ID == resourceId {
+			resourceLabels = resource.Labels
+			break
+		}
+	}
+
 	for _, policy := range a.Policies {
 		if !policy.Enabled {
 			continue
@@ -1419,7 +1778,7 @@ func (a *Account) GetPoliciesForNetworkResource(resourceId string) []*Policy {
 				continue
 			}
 
-			if rule.DestinationResource.ID == resourceId {
+			if rule.DestinationResource.ID == resourceId || labels.Matches(rule.DestinationLabels, resourceLabels) {
 				resourceAppliedPolicies = append(resourceAppliedPolicies, policy)
 				break
 			}
@@ -1501,14 +1860,15 @@ func (a *Account) GetResourceRoutersMap() map[string]map[string]*routerTypes.Net
 	return routers
 }
 
-// getPoliciesSourcePeers collects all unique peers from the source groups defined in the given policies.
-func getPoliciesSourcePeers(policies []*Policy, groups map[string]*Group) map[string]struct{} {
+// getPoliciesSourcePeers collects all unique peers from the source groups and the source labels defined in the given
+// policies.
+func (a *Account) getPoliciesSourcePeers(policies []*Policy) map[string]struct{} {
 	sourcePeers := make(map[string]struct{})
 
 	for _, policy := range policies {
 		for _, rule := range policy.Rules {
 			for _, sourceGroup := range rule.Sources {
-				group := groups[sourceGroup]
+				group := a.Groups[sourceGroup]
 				if group == nil {
 					continue
 				}
@@ -1517,6 +1877,10 @@ func getPoliciesSourcePeers(policies []*Policy, groups map[string]*Group) map[st
 					sourcePeers[peer] = struct{}{}
 				}
 			}
+
+			for _, peer := range a.getPeerIDsWithLabels(rule.SourceLabels) {
+				sourcePeers[peer] = struct{}{}
+			}
 		}
 	}
 
@@ -5,11 +5,13 @@ import (
 	"net"
 	"net/netip"
 	"slices"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	nbdns "github.com/netbirdio/netbird/dns"
 	resourceTypes "github.com/netbirdio/netbird/management/server/networks/resources/types"
 	routerTypes "github.com/netbirdio/netbird/management/server/networks/routers/types"
 	networkTypes "github.com/netbirdio/netbird/management/server/networks/types"
@@ -835,3 +837,196 @@ func Test_NetworksNetMapGenShouldExcludeOtherRouters(t *testing.T) {
 	assert.Len(t, networkResourcesRoutes, 1, "expected network resource route don't match")
 	assert.Len(t, sourcePeers, 2, "expected source peers don't match")
 }
+
+func Test_LabelSelectors(t *testing.T) {
+	account := &Account{
+		Peers: map[string]*nbpeer.Peer{
+			"peer1": {ID: "peer1", IP: net.IP{100, 64, 0, 1}, Labels: map[string]string{"env": "dev"}},
+			"peer2": {ID: "peer2", IP: net.IP{100, 64, 0, 2}, Labels: map[string]string{"env": "prod", "pci": "true"}},
+			"peer3": {ID: "peer3", IP: net.IP{100, 64, 0, 3}, Labels: map[string]string{"env": "prod"}},
+			"peer4": {ID: "peer4", IP: net.IP{100, 64, 0, 4}},
+		},
+		Groups: map[string]*Group{"all": {ID: "all", Name: "All", Peers: []string{"peer1", "peer2", "peer3", "peer4"}}},
+		Policies: []*Policy{
+			{
+				ID:      "policy1",
+				Enabled: true,
+				Rules: []*PolicyRule{
+					{
+						ID:                "rule1",
+						Enabled:           true,
+						Action:            PolicyTrafficActionAccept,
+						Protocol:          PolicyRuleProtocolALL,
+						Bidirectional:     true,
+						SourceLabels:      map[string]string{"env": "dev"},
+						DestinationLabels: map[string]string{"env": "prod", "pci": "true"},
+					},
+				},
+			},
+		},
+		NetworkResources: []*resourceTypes.NetworkResource{
+			{ID: "resource1", Enabled: true, Labels: map[string]string{"env": "prod", "pci": "true"}},
+			{ID: "resource2", Enabled: true, Labels: map[string]string{"env": "prod"}},
+		},
+	}
+	validatedPeers := map[string]struct{}{"peer1": {}, "peer2": {}, "peer3": {}, "peer4": {}}
+
+	peers, _ := account.GetPeerConnectionResources(context.Background(), "peer1", validatedPeers)
+	require.Len(t, peers, 1)
+	assert.Equal(t, "peer2", peers[0].ID, "only the peer with all the destination labels should be selected")
+
+	peers, _ = account.GetPeerConnectionResources(context.Background(), "peer4", validatedPeers)
+	assert.Empty(t, peers, "a peer without labels shouldn't be selected")
+
+	assert.Len(t, account.GetPoliciesForNetworkResource("resource1"), 1)
+	assert.Empty(t, account.GetPoliciesForNetworkResource("resource2"))
+}
+
+func Test_GetRoutesNSGroups(t *testing.T) {
+	newRoute := func(id, peer string) *route.Route {
+		return &route.Route{
+			ID:                      route.ID(id),
+			NetID:                   "office",
+			Network:                 netip.MustParsePrefix("192.168.10.0/24"),
+			NetworkType:             route.IPv4Network,
+			Peer:                    peer,
+			Enabled:                 true,
+			DNSDomains:              []string{"office.example.com"},
+			DNSServers:              []netip.Addr{netip.MustParseAddr("192.168.10.53")},
+			DNSSearchDomainsEnabled: true,
+		}
+	}
+	withoutDNS := newRoute("route3", "peer3")
+	withoutDNS.NetID = "lan"
+	withoutDNS.DNSDomains, withoutDNS.DNSServers = nil, nil
+
+	nsGroups := getRoutesNSGroups([]*route.Route{newRoute("route1", "peer1"), newRoute("route2", "peer2"), withoutDNS})
+	require.Len(t, nsGroups, 1, "the routes of an HA group should share the nameserver group")
+
+	assert.Equal(t, &nbdns.NameServerGroup{
+		ID:   "route:office|192.168.10.0/24",
+		Name: "office",
+		NameServers: []nbdns.NameServer{
+			{IP: netip.MustParseAddr("192.168.10.53"), NSType: nbdns.UDPNameServerType, Port: nbdns.DefaultDNSPort},
+		},
+		Domains:              []string{"office.example.com"},
+		Enabled:              true,
+		SearchDomainsEnabled: true,
+	}, nsGroups[0])
+
+	disabled := newRoute("route1", "peer1")
+	disabled.Enabled = false
+	assert.Empty(t, getRoutesNSGroups([]*route.Route{disabled}))
+}
+
+func Test_GetPeerNSGroupsForwarder(t *testing.T) {
+	router := &nbpeer.Peer{ID: "router", IP: net.ParseIP("100.64.0.10")}
+	account := &Account{
+		Peers: map[string]*nbpeer.Peer{
+			"peer1":  {ID: "peer1", IP: net.ParseIP("100.64.0.1")},
+			"router": router,
+		},
+		Groups: map[string]*Group{
+			"group1": {ID: "group1", Peers: []string{"peer1"}},
+		},
+		NameServerGroups: map[string]*nbdns.NameServerGroup{
+			"corp": {
+				ID:      "corp",
+				Name:    "corp",
+				Groups:  []string{"group1"},
+				Domains: []string{"corp.example.com"},
+				NameServers: []nbdns.NameServer{
+					{IP: netip.MustParseAddr("10.0.0.53"), NSType: nbdns.UDPNameServerType, Port: nbdns.DefaultDNSPort},
+				},
+				Enabled:       true,
+				ForwarderPeer: "router",
+			},
+		},
+	}
+
+	nsGroups := getPeerNSGroups(account, "peer1", []*nbpeer.Peer{router})
+	require.Len(t, nsGroups, 1)
+	assert.Equal(t, []nbdns.NameServer{
+		{IP: netip.MustParseAddr("100.64.0.10"), NSType: nbdns.UDPNameServerType, Port: nbdns.ForwarderDNSPort},
+	}, nsGroups[0].NameServers, "the peers should query the forwarder of the routing peer")
+	assert.Empty(t, nsGroups[0].ForwarderPeer)
+
+	assert.Empty(t, getPeerNSGroups(account, "peer1", nil), "the group should be skipped without access to the forwarder")
+
+	nsGroups = getPeerNSGroups(account, "router", nil)
+	require.Len(t, nsGroups, 1, "the forwarder peer should get the group outside of the distribution groups")
+	assert.Equal(t, account.NameServerGroups["corp"], nsGroups[0])
+}
+
+func Test_GetPeerRoutesFirewallRulesForwardedTrafficFiltering(t *testing.T) {
+	account := &Account{
+		Peers: map[string]*nbpeer.Peer{
+			"router":  {ID: "router", Key: "routerKey", IP: net.IP{100, 64, 0, 1}},
+			"dev":     {ID: "dev", Key: "devKey", IP: net.IP{100, 64, 0, 2}},
+			"ops":     {ID: "ops", Key: "opsKey", IP: net.IP{100, 64, 0, 3}},
+			"visitor": {ID: "visitor", Key: "visitorKey", IP: net.IP{100, 64, 0, 4}},
+		},
+		Groups: map[string]*Group{
+			"all":     {ID: "all", Peers: []string{"router", "dev", "ops", "visitor"}},
+			"routers": {ID: "routers", Peers: []string{"router"}},
+			"devs":    {ID: "devs", Peers: []string{"dev"}},
+			"ops":     {ID: "ops", Peers: []string{"ops"}},
+		},
+		Policies: []*Policy{
+			{
+				ID:      "ssh",
+				Enabled: true,
+				Rules: []*PolicyRule{{
+					ID: "ssh", PolicyID: "ssh", Enabled: true, Action: PolicyTrafficActionAccept, Protocol: PolicyRuleProtocolTCP, Ports: []string{"22"},
+					Sources: []string{"devs"}, Destinations: []string{"routers"},
+				}},
+			},
+			{
+				ID:      "ops",
+				Enabled: true,
+				Rules: []*PolicyRule{{
+					ID: "ops", PolicyID: "ops", Enabled: true, Action: PolicyTrafficActionAccept, Protocol: PolicyRuleProtocolALL, Bidirectional: true,
+					Sources: []string{"routers"}, Destinations: []string{"ops"},
+				}},
+			},
+			{
+				ID:      "visitors",
+				Enabled: true,
+				Rules: []*PolicyRule{{
+					ID: "visitors", PolicyID: "visitors", Enabled: true, Action: PolicyTrafficActionAccept, Protocol: PolicyRuleProtocolALL,
+					Sources: []string{"all"}, Destinations: []string{"devs"},
+				}},
+			},
+		},
+		Routes: map[route.ID]*route.Route{
+			"lan": {
+				ID:      "lan",
+				Network: netip.MustParsePrefix("192.168.0.0/24"),
+				Peer:    "router",
+				Groups:  []string{"all"},
+				Enabled: true,
+			},
+		},
+		Settings: &Settings{},
+	}
+	validatedPeers := map[string]struct{}{"router": {}, "dev": {}, "ops": {}, "visitor": {}}
+
+	rules := account.GetPeerRoutesFirewallRules(context.Background(), "router", validatedPeers)
+	require.Len(t, rules, 1)
+	assert.Equal(t, []string{"0.0.0.0/0"}, rules[0].SourceRanges, "all the forwarded traffic should be accepted by default")
+
+	account.Settings.RoutingPeerForwardedTrafficFilteringEnabled = true
+	rules = account.GetPeerRoutesFirewallRules(context.Background(), "router", validatedPeers)
+	require.Len(t, rules, 2, "only the policies of the traffic to the routing peer should apply")
+	slices.SortFunc(rules, func(a, b *RouteFirewallRule) int { return strings.Compare(a.PolicyID, b.PolicyID) })
+
+	assert.Equal(t, "ops", rules[0].PolicyID)
+	assert.Equal(t, []string{"100.64.0.3/32"}, rules[0].SourceRanges, "the bidirectional rules should apply in reverse")
+	assert.Equal(t, string(PolicyRuleProtocolALL), rules[0].Protocol)
+
+	assert.Equal(t, "ssh", rules[1].PolicyID)
+	assert.Equal(t, []string{"100.64.0.2/32"}, rules[1].SourceRanges)
+	assert.Equal(t, "192.168.0.0/24", rules[1].Destination)
+	assert.Equal(t, string(PolicyRuleProtocolTCP), rules[1].Protocol)
+	assert.Equal(t, uint16(22), rules[1].Port)
+}
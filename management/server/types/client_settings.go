@@ -0,0 +1,55 @@
+package types
+
+import (
+	"fmt"
+)
+
+// FirewallMode is how the clients enforce the access policies on the traffic of their peers
+type FirewallMode string
+
+const (
+	// FirewallModeEnforce filters the traffic of the peers with the firewall of the client
+	FirewallModeEnforce FirewallMode = "enforce"
+	// FirewallModeDisabled leaves the firewall of the host untouched, the traffic of the peers isn't filtered
+	FirewallModeDisabled FirewallMode = "disabled"
+)
+
+// ClientSettings are the defaults of the client settings of the account peers. The clients apply them to the
+// settings not set locally, with the flags, the UI or the device policy, so the fleet doesn't depend on the flags
+// the clients were installed with. The keepalives are set with the connection tuning.
+type ClientSettings struct {
+	// DNSManagementEnabled lets the clients configure the DNS of the host
+	DNSManagementEnabled bool
+	// AcceptRoutes lets the clients install the routes of the account
+	AcceptRoutes bool
+	// FirewallMode is how the clients enforce the access policies
+	FirewallMode FirewallMode
+}
+
+// NewClientSettings returns the client settings matching the client defaults
+func NewClientSettings() *ClientSettings {
+	return &ClientSettings{
+		DNSManagementEnabled: true,
+		AcceptRoutes:         true,
+		FirewallMode:         FirewallModeEnforce,
+	}
+}
+
+// Copy returns a copy of the client settings
+func (s *ClientSettings) Copy() *ClientSettings {
+	if s == nil {
+		return nil
+	}
+	settings := *s
+	return &settings
+}
+
+// Validate checks the firewall mode
+func (s *ClientSettings) Validate() error {
+	switch s.FirewallMode {
+	case FirewallModeEnforce, FirewallModeDisabled:
+		return nil
+	default:
+		return fmt.Errorf("invalid firewall mode %q", s.FirewallMode)
+	}
+}
@@ -3,7 +3,12 @@ package types
 import (
 	"net/netip"
 
+	"github.com/netbirdio/netbird/management/server/activity/bus"
+	"github.com/netbirdio/netbird/management/server/activity/export"
+	"github.com/netbirdio/netbird/management/server/cluster"
 	"github.com/netbirdio/netbird/management/server/idp"
+	"github.com/netbirdio/netbird/management/server/mtls"
+	"github.com/netbirdio/netbird/management/server/notifications"
 	"github.com/netbirdio/netbird/util"
 )
 
@@ -52,6 +57,24 @@ type Config struct {
 	StoreConfig StoreConfig
 
 	ReverseProxy ReverseProxy
+
+	// Notifications defines the channels used to notify account administrators, e.g. about peers pending approval
+	Notifications *notifications.Config
+
+	// EventExport defines the syslog or HTTP collector the activity events are exported to, e.g. a SIEM
+	EventExport *export.Config
+
+	// EventBus defines the Kafka (through a Kafka REST Proxy) or NATS buses the activity events are published to, e.g. to feed
+	// data pipelines
+	EventBus *bus.Config
+
+	// Cluster defines how multiple management servers sharing the store exchange the peer updates and lock the account
+	// writes, so they can serve the sync streams behind a load balancer without sticky sessions
+	Cluster *cluster.Config
+
+	// ClientCertificates enables the client certificates issued to the peers on login for mutual TLS with the
+	// management and signal services, it requires TLS
+	ClientCertificates *mtls.Config
 }
 
 // GetAuthAudiences returns the audience from the http config and device authorization flow config
@@ -73,20 +96,34 @@ func (c Config) GetAuthAudiences() []string {
 type TURNConfig struct {
 	TimeBasedCredentials bool
 	CredentialsTTL       util.Duration
-	Secret               string
-	Turns                []*Host
+	// CredentialsRefreshInterval defines how often the connected peers get new credentials, it has to be shorter
+	// than CredentialsTTL and defaults to 3/4 of it
+	CredentialsRefreshInterval util.Duration
+	Secret                     string
+	Turns                      []*Host
 }
 
 // Relay configuration type
 type Relay struct {
 	Addresses      []string
 	CredentialsTTL util.Duration
-	Secret         string
+	// CredentialsRefreshInterval defines how often the connected peers get new credentials, it has to be shorter
+	// than CredentialsTTL and defaults to 3/4 of it
+	CredentialsRefreshInterval util.Duration
+	Secret                     string
+	// PeerBoundTokens issues relay tokens accepted only for the relay peer ID of the peer and by the relays of
+	// Addresses. The relays older than the management service reject them, enable it once all relays are upgraded.
+	PeerBoundTokens bool
 }
 
 // HttpServerConfig is a config of the HTTP Management service server
 type HttpServerConfig struct {
 	LetsEncryptDomain string
+	// LetsEncryptDNSProvider enables the DNS-01 challenge with the DNS provider, e.g. route53 or rfc2136,
+	// instead of serving the HTTP-01 and TLS-ALPN-01 challenges
+	LetsEncryptDNSProvider string
+	// LetsEncryptEmail is the email address of the Let's Encrypt account registered for the DNS-01 challenge
+	LetsEncryptEmail string
 	// CertFile is the location of the certificate
 	CertFile string
 	// CertKey is the location of the certificate private key
@@ -159,6 +196,30 @@ type ProviderConfig struct {
 // StoreConfig contains Store configuration
 type StoreConfig struct {
 	Engine Engine
+	// MaxOpenConns limits the open database connections, overrides NB_SQL_MAX_OPEN_CONNS when set.
+	// The connection pool settings are applied again when the config is reloaded.
+	MaxOpenConns int
+	// MaxIdleConns limits the idle database connections, overrides NB_SQL_MAX_IDLE_CONNS when set
+	MaxIdleConns int
+	// ConnMaxLifetime closes the database connections after the duration, overrides NB_SQL_CONN_MAX_LIFETIME when set
+	ConnMaxLifetime util.Duration
+	// ConnMaxIdleTime closes the idle database connections after the duration, overrides NB_SQL_CONN_MAX_IDLE_TIME when set
+	ConnMaxIdleTime util.Duration
+	// Encryption encrypts the secrets of the store at rest, they're stored in plaintext when nil
+	Encryption *StoreEncryptionConfig
+}
+
+// StoreEncryptionConfig configures the envelope encryption of the secrets of the store, e.g. the webhook secrets.
+// They're encrypted with data keys stored in the database wrapped with an age identity or a Vault transit key, a copy
+// of the database alone doesn't reveal them. One of the two key encryption keys is set.
+type StoreEncryptionConfig struct {
+	// AgeIdentityFile is the file with the age X25519 identity wrapping the data keys, e.g. created with age-keygen
+	AgeIdentityFile string
+	// VaultAddress is the address of the HashiCorp Vault server wrapping the data keys with its transit engine, the
+	// token is read from the VAULT_TOKEN environment variable
+	VaultAddress string
+	// VaultTransitKey is the name of the transit key wrapping the data keys
+	VaultTransitKey string
 }
 
 // ReverseProxy contains reverse proxy configuration in front of management.
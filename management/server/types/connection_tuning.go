@@ -0,0 +1,125 @@
+package types
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+const (
+	// maxWgKeepalive is the largest persistent keepalive interval supported by WireGuard
+	maxWgKeepalive = 65535 * time.Second
+	// minConnectionTimer prevents timers flooding the peers and the signal service
+	minConnectionTimer = time.Second
+)
+
+// ConnectionTuning overrides the timers of the peer connections, the client defaults apply to the zero durations.
+// It is set in the account settings and on groups, the settings of the groups taking precedence over the account ones.
+type ConnectionTuning struct {
+	// WgKeepalive is the WireGuard persistent keepalive interval
+	WgKeepalive time.Duration
+	// HandshakeRetryMaxInterval is the maximum interval between the connection offers sent while the connection isn't established
+	HandshakeRetryMaxInterval time.Duration
+	// HandshakeRetryTimeout is the period after which no more offers are sent until the next connection change
+	HandshakeRetryTimeout time.Duration
+	// ICEKeepalive is the interval of the ICE keepalive messages
+	ICEKeepalive time.Duration
+	// ICEDisconnectedTimeout is the period without ICE traffic after which the connection is considered disconnected
+	ICEDisconnectedTimeout time.Duration
+	// ICEFailedTimeout is the period after the disconnection after which the ICE connection is considered failed
+	ICEFailedTimeout time.Duration
+}
+
+// Copy returns a copy of the connection tuning
+func (t *ConnectionTuning) Copy() *ConnectionTuning {
+	if t == nil {
+		return nil
+	}
+	tuning := *t
+	return &tuning
+}
+
+// IsEmpty checks if no timer is overridden
+func (t *ConnectionTuning) IsEmpty() bool {
+	return t == nil || *t == ConnectionTuning{}
+}
+
+// Validate checks that the timers are within the allowed limits
+func (t *ConnectionTuning) Validate() error {
+	if t.WgKeepalive > maxWgKeepalive {
+		return fmt.Errorf("WireGuard keepalive can't be larger than %s", maxWgKeepalive)
+	}
+
+	timers := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"WireGuard keepalive", t.WgKeepalive},
+		{"handshake retry max interval", t.HandshakeRetryMaxInterval},
+		{"handshake retry timeout", t.HandshakeRetryTimeout},
+		{"ICE keepalive", t.ICEKeepalive},
+		{"ICE disconnected timeout", t.ICEDisconnectedTimeout},
+		{"ICE failed timeout", t.ICEFailedTimeout},
+	}
+	for _, timer := range timers {
+		if timer.value != 0 && timer.value < minConnectionTimer {
+			return fmt.Errorf("%s can't be smaller than %s", timer.name, minConnectionTimer)
+		}
+	}
+
+	if t.HandshakeRetryTimeout != 0 && t.HandshakeRetryTimeout < t.HandshakeRetryMaxInterval {
+		return fmt.Errorf("handshake retry timeout can't be smaller than the handshake retry max interval")
+	}
+	return nil
+}
+
+// NewPeerConnectionTuning resolves the connection tuning of a peer member of the groups.
+// The timers set on the groups override the account settings, the shortest one applies when several groups set it.
+func NewPeerConnectionTuning(settings *Settings, groups []*Group) ConnectionTuning {
+	var tuning ConnectionTuning
+	for _, group := range groups {
+		if group.ConnectionTuning == nil {
+			continue
+		}
+		tuning.WgKeepalive = minDuration(tuning.WgKeepalive, group.ConnectionTuning.WgKeepalive)
+		tuning.HandshakeRetryMaxInterval = minDuration(tuning.HandshakeRetryMaxInterval, group.ConnectionTuning.HandshakeRetryMaxInterval)
+		tuning.HandshakeRetryTimeout = minDuration(tuning.HandshakeRetryTimeout, group.ConnectionTuning.HandshakeRetryTimeout)
+		tuning.ICEKeepalive = minDuration(tuning.ICEKeepalive, group.ConnectionTuning.ICEKeepalive)
+		tuning.ICEDisconnectedTimeout = minDuration(tuning.ICEDisconnectedTimeout, group.ConnectionTuning.ICEDisconnectedTimeout)
+		tuning.ICEFailedTimeout = minDuration(tuning.ICEFailedTimeout, group.ConnectionTuning.ICEFailedTimeout)
+	}
+
+	if account := settings.ConnectionTuning; account != nil {
+		tuning.WgKeepalive = durationOr(tuning.WgKeepalive, account.WgKeepalive)
+		tuning.HandshakeRetryMaxInterval = durationOr(tuning.HandshakeRetryMaxInterval, account.HandshakeRetryMaxInterval)
+		tuning.HandshakeRetryTimeout = durationOr(tuning.HandshakeRetryTimeout, account.HandshakeRetryTimeout)
+		tuning.ICEKeepalive = durationOr(tuning.ICEKeepalive, account.ICEKeepalive)
+		tuning.ICEDisconnectedTimeout = durationOr(tuning.ICEDisconnectedTimeout, account.ICEDisconnectedTimeout)
+		tuning.ICEFailedTimeout = durationOr(tuning.ICEFailedTimeout, account.ICEFailedTimeout)
+	}
+
+	return tuning
+}
+
+// durationOr returns the duration, or the fallback if it is 0
+func durationOr(d, fallback time.Duration) time.Duration {
+	if d == 0 {
+		return fallback
+	}
+	return d
+}
+
+// getPeerConnectionTuning returns the connection tuning of the peer resolved from the account settings and its groups
+func (a *Account) getPeerConnectionTuning(peerID string) ConnectionTuning {
+	if a.Settings == nil {
+		return ConnectionTuning{}
+	}
+
+	var groups []*Group
+	for _, group := range a.Groups {
+		if group.ConnectionTuning != nil && slices.Contains(group.Peers, peerID) {
+			groups = append(groups, group)
+		}
+	}
+	return NewPeerConnectionTuning(a.Settings, groups)
+}
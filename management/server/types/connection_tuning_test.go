@@ -0,0 +1,63 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConnectionTuning_Validate(t *testing.T) {
+	assert.NoError(t, (&ConnectionTuning{}).Validate())
+	assert.NoError(t, (&ConnectionTuning{WgKeepalive: time.Minute, HandshakeRetryMaxInterval: time.Minute, HandshakeRetryTimeout: time.Hour}).Validate())
+	assert.Error(t, (&ConnectionTuning{WgKeepalive: 70000 * time.Second}).Validate())
+	assert.Error(t, (&ConnectionTuning{ICEKeepalive: 100 * time.Millisecond}).Validate())
+	assert.Error(t, (&ConnectionTuning{ICEFailedTimeout: -time.Second}).Validate())
+	assert.Error(t, (&ConnectionTuning{HandshakeRetryMaxInterval: time.Hour, HandshakeRetryTimeout: time.Minute}).Validate())
+}
+
+func TestNewPeerConnectionTuning(t *testing.T) {
+	settings := &Settings{ConnectionTuning: &ConnectionTuning{WgKeepalive: time.Minute, ICEKeepalive: 10 * time.Second}}
+
+	tests := []struct {
+		name     string
+		settings *Settings
+		groups   []*Group
+		expected ConnectionTuning
+	}{
+		{
+			name:     "client defaults",
+			settings: &Settings{},
+			groups:   []*Group{{ID: "group1"}},
+			expected: ConnectionTuning{},
+		},
+		{
+			name:     "account settings",
+			settings: settings,
+			groups:   []*Group{{ID: "group1"}},
+			expected: ConnectionTuning{WgKeepalive: time.Minute, ICEKeepalive: 10 * time.Second},
+		},
+		{
+			name:     "group overriding the account settings",
+			settings: settings,
+			groups:   []*Group{{ID: "group1", ConnectionTuning: &ConnectionTuning{WgKeepalive: 5 * time.Minute, ICEFailedTimeout: 30 * time.Second}}},
+			expected: ConnectionTuning{WgKeepalive: 5 * time.Minute, ICEKeepalive: 10 * time.Second, ICEFailedTimeout: 30 * time.Second},
+		},
+		{
+			name:     "shortest timers of the groups",
+			settings: settings,
+			groups: []*Group{
+				{ID: "group1", ConnectionTuning: &ConnectionTuning{WgKeepalive: 5 * time.Minute, ICEFailedTimeout: 30 * time.Second}},
+				{ID: "group2", ConnectionTuning: &ConnectionTuning{WgKeepalive: 2 * time.Minute}},
+				{ID: "group3", ConnectionTuning: &ConnectionTuning{ICEFailedTimeout: 20 * time.Second}},
+			},
+			expected: ConnectionTuning{WgKeepalive: 2 * time.Minute, ICEKeepalive: 10 * time.Second, ICEFailedTimeout: 20 * time.Second},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NewPeerConnectionTuning(tt.settings, tt.groups))
+		})
+	}
+}
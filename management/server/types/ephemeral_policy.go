@@ -0,0 +1,78 @@
+package types
+
+import (
+	"fmt"
+	"time"
+
+	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+)
+
+const (
+	minEphemeralCleanupAfter = time.Minute
+	maxEphemeralCleanupAfter = 365 * 24 * time.Hour
+)
+
+// EphemeralPolicy defines after how long the offline peers of a group are deleted.
+// When a peer is part of several groups with a policy, the shortest period applies.
+type EphemeralPolicy struct {
+	// CleanupAfter is the duration a peer has to be offline before it is deleted
+	CleanupAfter time.Duration
+	// IncludeNonEphemeral applies the policy to the peers of the group that weren't registered as ephemeral too
+	IncludeNonEphemeral bool
+}
+
+// Copy returns a copy of the ephemeral policy
+func (p *EphemeralPolicy) Copy() *EphemeralPolicy {
+	if p == nil {
+		return nil
+	}
+	policy := *p
+	return &policy
+}
+
+// Validate checks that the cleanup period of the ephemeral policy is within the allowed limits
+func (p *EphemeralPolicy) Validate() error {
+	if p.CleanupAfter < minEphemeralCleanupAfter || p.CleanupAfter > maxEphemeralCleanupAfter {
+		return fmt.Errorf("cleanup period must be between %s and %s", minEphemeralCleanupAfter, maxEphemeralCleanupAfter)
+	}
+	return nil
+}
+
+// appliesTo checks if the policy applies to the given peer
+func (p *EphemeralPolicy) appliesTo(peer *nbpeer.Peer) bool {
+	return p != nil && (peer.Ephemeral || p.IncludeNonEphemeral)
+}
+
+// GetEphemeralLifetime returns the duration after which the given offline peer is deleted according to the ephemeral
+// policies of its groups. Ephemeral peers without any policy are deleted after the default lifetime.
+// It returns false if the peer is never deleted.
+func GetEphemeralLifetime(peer *nbpeer.Peer, peerGroups []*Group, defaultLifetime time.Duration) (time.Duration, bool) {
+	var lifetime time.Duration
+	for _, group := range peerGroups {
+		if !group.EphemeralPolicy.appliesTo(peer) {
+			continue
+		}
+		if lifetime == 0 || group.EphemeralPolicy.CleanupAfter < lifetime {
+			lifetime = group.EphemeralPolicy.CleanupAfter
+		}
+	}
+
+	if lifetime > 0 {
+		return lifetime, true
+	}
+
+	if peer.Ephemeral {
+		return defaultLifetime, true
+	}
+
+	return 0, false
+}
+
+// ScheduledPeerDeletion is an offline peer that is going to be deleted by the ephemeral peers cleanup
+type ScheduledPeerDeletion struct {
+	Peer *nbpeer.Peer
+	// CleanupAfter is the duration the peer has to be offline before it is deleted
+	CleanupAfter time.Duration
+	// ScheduledAt is the time the peer is going to be deleted at
+	ScheduledAt time.Time
+}
@@ -0,0 +1,96 @@
+package types
+
+import (
+	"context"
+	"errors"
+
+	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+	"github.com/netbirdio/netbird/route"
+)
+
+// ExitNodeQuota limits the traffic the exit nodes forward for the members of a group in a month (UTC). The traffic
+// of the peers of a user adds up, the peers registered with a setup key are limited individually.
+type ExitNodeQuota struct {
+	// MonthlyBytes is the traffic in both directions the exit nodes forward for a user or a peer in a month
+	MonthlyBytes int64
+}
+
+// Copy returns a copy of the exit node quota
+func (q *ExitNodeQuota) Copy() *ExitNodeQuota {
+	if q == nil {
+		return nil
+	}
+	quota := *q
+	return &quota
+}
+
+// Validate checks that the quota allows some traffic
+func (q *ExitNodeQuota) Validate() error {
+	if q.MonthlyBytes <= 0 {
+		return errors.New("the monthly bytes of the exit node quota have to be positive")
+	}
+	return nil
+}
+
+// NewPeerExitNodeQuota resolves the exit node quota of a peer member of the groups, the smallest quota of the groups
+// applies. It returns 0 if the traffic of the peer isn't limited.
+func NewPeerExitNodeQuota(groups []*Group) int64 {
+	var quota int64
+	for _, group := range groups {
+		if group.ExitNodeQuota == nil {
+			continue
+		}
+		if quota == 0 || group.ExitNodeQuota.MonthlyBytes < quota {
+			quota = group.ExitNodeQuota.MonthlyBytes
+		}
+	}
+	return quota
+}
+
+// GetExitNodeQuotas returns the exit node quotas of the peers resolved from their groups by peer ID, the peers with
+// unlimited traffic are left out. The nested groups have to be flattened.
+func (a *Account) GetExitNodeQuotas() map[string]int64 {
+	peerGroups := make(map[string][]*Group)
+	for _, group := range a.Groups {
+		if group.ExitNodeQuota == nil {
+			continue
+		}
+		for _, peerID := range group.Peers {
+			peerGroups[peerID] = append(peerGroups[peerID], group)
+		}
+	}
+
+	quotas := make(map[string]int64, len(peerGroups))
+	for peerID, groups := range peerGroups {
+		quotas[peerID] = NewPeerExitNodeQuota(groups)
+	}
+	return quotas
+}
+
+// IsExitNode checks if the peer serves an enabled default route
+func (a *Account) IsExitNode(ctx context.Context, peerID string) bool {
+	routes, _ := a.getRoutingPeerRoutes(ctx, peerID)
+	for _, r := range routes {
+		if r.IsDefault() {
+			return true
+		}
+	}
+	return false
+}
+
+// filterExceededExitRoutes removes the exit routes served by other peers if the peer exceeded its exit node quota,
+// the routes the peer serves itself are kept
+func filterExceededExitRoutes(peer *nbpeer.Peer, routes []*route.Route) []*route.Route {
+	if !peer.ExitNodeQuotaExceeded {
+		return routes
+	}
+
+	filtered := make([]*route.Route, 0, len(routes))
+	for _, r := range routes {
+		if r.IsDefault() && r.Peer != peer.Key {
+			continue
+		}
+		filtered = append(filtered, r)
+	}
+	return filtered
+}
@@ -0,0 +1,91 @@
+package types
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+	"github.com/netbirdio/netbird/route"
+)
+
+func TestExitNodeQuota_Validate(t *testing.T) {
+	assert.NoError(t, (&ExitNodeQuota{MonthlyBytes: 1 << 30}).Validate())
+	assert.Error(t, (&ExitNodeQuota{}).Validate())
+	assert.Error(t, (&ExitNodeQuota{MonthlyBytes: -1}).Validate())
+}
+
+func TestAccount_GetExitNodeQuotas(t *testing.T) {
+	account := &Account{
+		Groups: map[string]*Group{
+			"group1": {ID: "group1", Peers: []string{"peer1", "peer2"}, ExitNodeQuota: &ExitNodeQuota{MonthlyBytes: 1000}},
+			"group2": {ID: "group2", Peers: []string{"peer2"}, ExitNodeQuota: &ExitNodeQuota{MonthlyBytes: 500}},
+			"group3": {ID: "group3", Peers: []string{"peer1", "peer3"}},
+		},
+	}
+
+	assert.Equal(t, map[string]int64{"peer1": 1000, "peer2": 500}, account.GetExitNodeQuotas())
+}
+
+func TestAccount_ExitNodeQuotaExceeded(t *testing.T) {
+	account := &Account{
+		Id: "account",
+		Peers: map[string]*nbpeer.Peer{
+			"exitNode": {ID: "exitNode", Key: "exitNodeKey", IP: net.IP{100, 64, 0, 1}},
+			"peer1":    {ID: "peer1", Key: "peer1Key", IP: net.IP{100, 64, 0, 2}},
+			"peer2":    {ID: "peer2", Key: "peer2Key", IP: net.IP{100, 64, 0, 3}, ExitNodeQuotaExceeded: true},
+		},
+		Groups: map[string]*Group{
+			"routers": {ID: "routers", Peers: []string{"exitNode"}},
+			"peers":   {ID: "peers", Peers: []string{"peer1", "peer2"}},
+		},
+		Routes: map[route.ID]*route.Route{
+			"exit": {
+				ID:                  "exit",
+				Network:             netip.MustParsePrefix("0.0.0.0/0"),
+				NetID:               "exit",
+				PeerGroups:          []string{"routers"},
+				Groups:              []string{"peers"},
+				AccessControlGroups: []string{"peers"},
+				Enabled:             true,
+			},
+			"lan": {
+				ID:                  "lan",
+				Network:             netip.MustParsePrefix("192.168.0.0/24"),
+				NetID:               "lan",
+				PeerGroups:          []string{"routers"},
+				Groups:              []string{"peers"},
+				AccessControlGroups: []string{"peers"},
+				Enabled:             true,
+			},
+		},
+	}
+	ctx := context.Background()
+
+	assert.True(t, account.IsExitNode(ctx, "exitNode"))
+	assert.False(t, account.IsExitNode(ctx, "peer1"))
+
+	peers := []*nbpeer.Peer{account.Peers["exitNode"]}
+	routeIDs := func(routes []*route.Route) []route.NetID {
+		var ids []route.NetID
+		for _, r := range routes {
+			ids = append(ids, r.NetID)
+		}
+		return ids
+	}
+	assert.ElementsMatch(t, []route.NetID{"exit", "lan"}, routeIDs(filterExceededExitRoutes(account.Peers["peer1"], account.GetRoutesToSync(ctx, "peer1", peers))))
+	assert.ElementsMatch(t, []route.NetID{"lan"}, routeIDs(filterExceededExitRoutes(account.Peers["peer2"], account.GetRoutesToSync(ctx, "peer2", peers))))
+
+	exitRoute, _ := account.getRoutingPeerRoutes(ctx, "exitNode")
+	for _, r := range exitRoute {
+		distributionPeers := account.getDistributionGroupsPeers(r)
+		if r.IsDefault() {
+			assert.Equal(t, map[string]struct{}{"peer1": {}}, distributionPeers)
+		} else {
+			assert.Equal(t, map[string]struct{}{"peer1": {}, "peer2": {}}, distributionPeers)
+		}
+	}
+}
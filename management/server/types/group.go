@@ -1,6 +1,8 @@
 package types
 
 import (
+	"slices"
+
 	"github.com/netbirdio/netbird/management/server/integration_reference"
 	"github.com/netbirdio/netbird/management/server/networks/resources/types"
 )
@@ -31,6 +33,27 @@ type Group struct {
 	// Resources contains a list of resources in that group
 	Resources []Resource `gorm:"serializer:json"`
 
+	// NestedGroups is a list of IDs of the groups whose peers are members of this group too
+	NestedGroups []string `gorm:"serializer:json"`
+
+	// MembershipRule adds the matching peers to the group and removes the others when they register or update, nil if the peers are managed manually
+	MembershipRule *GroupMembershipRule `gorm:"serializer:json"`
+
+	// SessionPolicy overrides the expiration settings of the account for the peers of the group, nil if the account settings apply
+	SessionPolicy *SessionPolicy `gorm:"serializer:json"`
+
+	// EphemeralPolicy deletes the peers of the group after they have been offline for a period, nil if the default cleanup of the ephemeral peers applies
+	EphemeralPolicy *EphemeralPolicy `gorm:"serializer:json"`
+
+	// ConnectionTuning overrides the connection timers of the account settings for the peers of the group, nil if the account settings apply
+	ConnectionTuning *ConnectionTuning `gorm:"serializer:json"`
+
+	// PortPolicy pins the WireGuard listen port and constrains the transports of the peers of the group, nil if the peers aren't constrained
+	PortPolicy *PortPolicy `gorm:"serializer:json"`
+
+	// ExitNodeQuota limits the monthly traffic the exit nodes forward for the peers of the group, nil if the traffic isn't limited
+	ExitNodeQuota *ExitNodeQuota `gorm:"serializer:json"`
+
 	IntegrationReference integration_reference.IntegrationReference `gorm:"embedded;embeddedPrefix:integration_ref_"`
 }
 
@@ -50,10 +73,18 @@ func (g *Group) Copy() *Group {
 		Issued:               g.Issued,
 		Peers:                make([]string, len(g.Peers)),
 		Resources:            make([]Resource, len(g.Resources)),
+		NestedGroups:         make([]string, len(g.NestedGroups)),
+		MembershipRule:       g.MembershipRule.Copy(),
+		SessionPolicy:        g.SessionPolicy.Copy(),
+		EphemeralPolicy:      g.EphemeralPolicy.Copy(),
+		ConnectionTuning:     g.ConnectionTuning.Copy(),
+		PortPolicy:           g.PortPolicy.Copy(),
+		ExitNodeQuota:        g.ExitNodeQuota.Copy(),
 		IntegrationReference: g.IntegrationReference,
 	}
 	copy(group.Peers, g.Peers)
 	copy(group.Resources, g.Resources)
+	copy(group.NestedGroups, g.NestedGroups)
 	return group
 }
 
@@ -121,3 +152,131 @@ func (g *Group) RemoveResource(resource Resource) bool {
 func (g *Group) HasResources() bool {
 	return len(g.Resources) > 0
 }
+
+// HasMembershipRule checks if the peers of the group are managed by a membership rule.
+func (g *Group) HasMembershipRule() bool {
+	return g.MembershipRule != nil
+}
+
+// HasNestedGroups checks if the group contains any other groups.
+func (g *Group) HasNestedGroups() bool {
+	return len(g.NestedGroups) > 0
+}
+
+// FindGroupCycle returns the IDs of the groups forming a cycle of nested groups starting from the group, nil if there is none.
+func FindGroupCycle(groups map[string]*Group, groupID string) []string {
+	var path []string
+	onPath := make(map[string]bool)
+	visited := make(map[string]bool)
+
+	var visit func(id string) []string
+	visit = func(id string) []string {
+		if onPath[id] {
+			start := slices.Index(path, id)
+			return append(slices.Clone(path[start:]), id)
+		}
+		if visited[id] {
+			return nil
+		}
+		visited[id] = true
+
+		group, ok := groups[id]
+		if !ok {
+			return nil
+		}
+
+		path = append(path, id)
+		onPath[id] = true
+		for _, nestedID := range group.NestedGroups {
+			if cycle := visit(nestedID); cycle != nil {
+				return cycle
+			}
+		}
+		onPath[id] = false
+		path = path[:len(path)-1]
+
+		return nil
+	}
+
+	return visit(groupID)
+}
+
+// GetParentGroupIDs returns the IDs of the groups containing any of the given groups, directly or through other nested groups.
+func GetParentGroupIDs(groups map[string]*Group, groupIDs []string) []string {
+	parents := make(map[string][]string)
+	for _, group := range groups {
+		for _, nestedID := range group.NestedGroups {
+			parents[nestedID] = append(parents[nestedID], group.ID)
+		}
+	}
+
+	seen := make(map[string]struct{}, len(groupIDs))
+	for _, id := range groupIDs {
+		seen[id] = struct{}{}
+	}
+
+	var result []string
+	queue := slices.Clone(groupIDs)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, parentID := range parents[id] {
+			if _, ok := seen[parentID]; ok {
+				continue
+			}
+			seen[parentID] = struct{}{}
+			result = append(result, parentID)
+			queue = append(queue, parentID)
+		}
+	}
+
+	return result
+}
+
+// FlattenNestedGroups resolves the peers of the nested groups into the peers of the groups containing them.
+// Every group is resolved once, groups forming a cycle are ignored when reached again.
+func FlattenNestedGroups(groups map[string]*Group) {
+	resolved := make(map[string][]string, len(groups))
+	resolving := make(map[string]bool)
+
+	var resolve func(group *Group) []string
+	resolve = func(group *Group) []string {
+		if peers, ok := resolved[group.ID]; ok {
+			return peers
+		}
+		if !group.HasNestedGroups() || resolving[group.ID] {
+			return group.Peers
+		}
+		resolving[group.ID] = true
+
+		peers := slices.Clone(group.Peers)
+		seen := make(map[string]struct{}, len(peers))
+		for _, peerID := range peers {
+			seen[peerID] = struct{}{}
+		}
+
+		for _, nestedID := range group.NestedGroups {
+			nested, ok := groups[nestedID]
+			if !ok {
+				continue
+			}
+			for _, peerID := range resolve(nested) {
+				if _, ok := seen[peerID]; ok {
+					continue
+				}
+				seen[peerID] = struct{}{}
+				peers = append(peers, peerID)
+			}
+		}
+
+		resolving[group.ID] = false
+		resolved[group.ID] = peers
+		return peers
+	}
+
+	for _, group := range groups {
+		if group.HasNestedGroups() {
+			group.Peers = resolve(group)
+		}
+	}
+}
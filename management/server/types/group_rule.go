@@ -0,0 +1,101 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+	"github.com/netbirdio/netbird/management/server/posture"
+)
+
+// GroupMembershipRule defines the peers that are members of a group based on their attributes.
+// All the criteria that are set have to match, a criterion listing several values matches any of them.
+type GroupMembershipRule struct {
+	// HostnameRegex matches the peers with the hostname matching the regular expression
+	HostnameRegex string
+	// OS matches the peers of the operating system families, e.g. linux, windows or darwin
+	OS []string
+	// SetupKeyIDs matches the peers registered with one of the setup keys
+	SetupKeyIDs []string
+	// PostureCheckIDs matches the peers passing all the posture checks
+	PostureCheckIDs []string
+	// Countries matches the peers connecting from one of the countries, ISO 3166-1 alpha-2 codes
+	Countries []string
+}
+
+// Copy returns a copy of the rule
+func (r *GroupMembershipRule) Copy() *GroupMembershipRule {
+	if r == nil {
+		return nil
+	}
+	return &GroupMembershipRule{
+		HostnameRegex:   r.HostnameRegex,
+		OS:              slices.Clone(r.OS),
+		SetupKeyIDs:     slices.Clone(r.SetupKeyIDs),
+		PostureCheckIDs: slices.Clone(r.PostureCheckIDs),
+		Countries:       slices.Clone(r.Countries),
+	}
+}
+
+// Validate checks that the rule has at least one criterion and that the criteria are valid
+func (r *GroupMembershipRule) Validate() error {
+	if r.HostnameRegex == "" && len(r.OS) == 0 && len(r.SetupKeyIDs) == 0 && len(r.PostureCheckIDs) == 0 && len(r.Countries) == 0 {
+		return fmt.Errorf("membership rule must have at least one criterion")
+	}
+
+	if r.HostnameRegex != "" {
+		if _, err := regexp.Compile(r.HostnameRegex); err != nil {
+			return fmt.Errorf("invalid hostname regex: %w", err)
+		}
+	}
+
+	for _, country := range r.Countries {
+		if len(country) != 2 {
+			return fmt.Errorf("invalid country code %q", country)
+		}
+	}
+
+	return nil
+}
+
+// Matches checks if the peer matches the rule. The posture checks of the rule are looked up in the given map,
+// a posture check missing from it is considered as failed.
+func (r *GroupMembershipRule) Matches(ctx context.Context, peer *nbpeer.Peer, postureChecks map[string]*posture.Checks) bool {
+	if r.HostnameRegex != "" {
+		re, err := regexp.Compile(r.HostnameRegex)
+		if err != nil || !re.MatchString(peer.Meta.Hostname) {
+			return false
+		}
+	}
+
+	if len(r.OS) > 0 && !slices.ContainsFunc(r.OS, func(os string) bool { return strings.EqualFold(os, peer.Meta.GoOS) }) {
+		return false
+	}
+
+	if len(r.SetupKeyIDs) > 0 && !slices.Contains(r.SetupKeyIDs, peer.SetupKeyID) {
+		return false
+	}
+
+	if len(r.Countries) > 0 && !slices.ContainsFunc(r.Countries, func(country string) bool {
+		return strings.EqualFold(country, peer.Location.CountryCode)
+	}) {
+		return false
+	}
+
+	for _, checksID := range r.PostureCheckIDs {
+		checks, ok := postureChecks[checksID]
+		if !ok {
+			return false
+		}
+		for _, check := range checks.GetChecks() {
+			if valid, err := check.Check(ctx, *peer); err != nil || !valid {
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,58 @@
+package types
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+	"github.com/netbirdio/netbird/management/server/posture"
+)
+
+func TestGroupMembershipRule_Validate(t *testing.T) {
+	assert.Error(t, (&GroupMembershipRule{}).Validate())
+	assert.Error(t, (&GroupMembershipRule{HostnameRegex: "web-("}).Validate())
+	assert.Error(t, (&GroupMembershipRule{Countries: []string{"DEU"}}).Validate())
+	assert.NoError(t, (&GroupMembershipRule{HostnameRegex: "^web-[0-9]+$", Countries: []string{"DE"}}).Validate())
+}
+
+func TestGroupMembershipRule_Matches(t *testing.T) {
+	peer := &nbpeer.Peer{
+		ID:         "peer1",
+		SetupKeyID: "key1",
+		Meta:       nbpeer.PeerSystemMeta{Hostname: "web-1", GoOS: "linux", WtVersion: "0.40.0"},
+		Location:   nbpeer.Location{CountryCode: "DE"},
+	}
+
+	postureChecks := map[string]*posture.Checks{
+		"recent": {ID: "recent", Checks: posture.ChecksDefinition{NBVersionCheck: &posture.NBVersionCheck{MinVersion: "0.30.0"}}},
+		"latest": {ID: "latest", Checks: posture.ChecksDefinition{NBVersionCheck: &posture.NBVersionCheck{MinVersion: "1.0.0"}}},
+	}
+
+	tests := []struct {
+		name    string
+		rule    GroupMembershipRule
+		matches bool
+	}{
+		{name: "hostname", rule: GroupMembershipRule{HostnameRegex: "^web-[0-9]+$"}, matches: true},
+		{name: "other hostname", rule: GroupMembershipRule{HostnameRegex: "^db-"}, matches: false},
+		{name: "os", rule: GroupMembershipRule{OS: []string{"windows", "Linux"}}, matches: true},
+		{name: "other os", rule: GroupMembershipRule{OS: []string{"darwin"}}, matches: false},
+		{name: "setup key", rule: GroupMembershipRule{SetupKeyIDs: []string{"key1"}}, matches: true},
+		{name: "other setup key", rule: GroupMembershipRule{SetupKeyIDs: []string{"key2"}}, matches: false},
+		{name: "country", rule: GroupMembershipRule{Countries: []string{"de"}}, matches: true},
+		{name: "other country", rule: GroupMembershipRule{Countries: []string{"FR"}}, matches: false},
+		{name: "passed posture checks", rule: GroupMembershipRule{PostureCheckIDs: []string{"recent"}}, matches: true},
+		{name: "failed posture checks", rule: GroupMembershipRule{PostureCheckIDs: []string{"recent", "latest"}}, matches: false},
+		{name: "unknown posture checks", rule: GroupMembershipRule{PostureCheckIDs: []string{"unknown"}}, matches: false},
+		{name: "all criteria", rule: GroupMembershipRule{HostnameRegex: "^web-", OS: []string{"linux"}, Countries: []string{"DE"}}, matches: true},
+		{name: "one criterion not matching", rule: GroupMembershipRule{HostnameRegex: "^web-", OS: []string{"windows"}}, matches: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.matches, tt.rule.Matches(context.Background(), peer, postureChecks))
+		})
+	}
+}
@@ -88,3 +88,55 @@ func TestRemovePeer(t *testing.T) {
 		assert.Equal(t, 2, len(group.Peers))
 	})
 }
+
+func TestFlattenNestedGroups(t *testing.T) {
+	groups := map[string]*Group{
+		"parent": {ID: "parent", Peers: []string{"peer1"}, NestedGroups: []string{"child", "missing"}},
+		"child":  {ID: "child", Peers: []string{"peer1", "peer2"}, NestedGroups: []string{"leaf"}},
+		"leaf":   {ID: "leaf", Peers: []string{"peer3"}},
+		"other":  {ID: "other", Peers: []string{"peer4"}},
+	}
+
+	FlattenNestedGroups(groups)
+
+	assert.ElementsMatch(t, []string{"peer1", "peer2", "peer3"}, groups["parent"].Peers)
+	assert.ElementsMatch(t, []string{"peer1", "peer2", "peer3"}, groups["child"].Peers)
+	assert.Equal(t, []string{"peer3"}, groups["leaf"].Peers)
+	assert.Equal(t, []string{"peer4"}, groups["other"].Peers)
+}
+
+func TestFlattenNestedGroups_Cycle(t *testing.T) {
+	groups := map[string]*Group{
+		"a": {ID: "a", Peers: []string{"peer1"}, NestedGroups: []string{"b"}},
+		"b": {ID: "b", Peers: []string{"peer2"}, NestedGroups: []string{"a"}},
+	}
+
+	FlattenNestedGroups(groups)
+
+	assert.Contains(t, groups["a"].Peers, "peer2")
+	assert.Contains(t, groups["b"].Peers, "peer1")
+}
+
+func TestFindGroupCycle(t *testing.T) {
+	groups := map[string]*Group{
+		"a": {ID: "a", NestedGroups: []string{"b"}},
+		"b": {ID: "b", NestedGroups: []string{"c"}},
+		"c": {ID: "c"},
+	}
+	assert.Nil(t, FindGroupCycle(groups, "a"))
+
+	groups["c"].NestedGroups = []string{"a"}
+	assert.Equal(t, []string{"a", "b", "c", "a"}, FindGroupCycle(groups, "a"))
+}
+
+func TestGetParentGroupIDs(t *testing.T) {
+	groups := map[string]*Group{
+		"a": {ID: "a", NestedGroups: []string{"b"}},
+		"b": {ID: "b", NestedGroups: []string{"c"}},
+		"c": {ID: "c"},
+		"d": {ID: "d"},
+	}
+
+	assert.ElementsMatch(t, []string{"a", "b"}, GetParentGroupIDs(groups, []string{"c"}))
+	assert.Empty(t, GetParentGroupIDs(groups, []string{"d"}))
+}
@@ -0,0 +1,20 @@
+package types
+
+import "time"
+
+// IdempotencyKey is the record of a management API request made with an Idempotency-Key header. The response of the
+// request is replayed for its retries until the record expires, the record of a request in progress expires shortly
+// so a request abandoned by a stopped management server can be retried.
+type IdempotencyKey struct {
+	// ID is the hash of the key scoped to the account and the user of the request
+	ID        string `gorm:"primaryKey"`
+	AccountID string `gorm:"index"`
+	// Fingerprint identifies the request the key was used for
+	Fingerprint []byte
+	// Completed is false while the request is in progress
+	Completed bool
+	Status    int
+	Header    map[string][]string `gorm:"serializer:json"`
+	Body      []byte
+	ExpiresAt time.Time `gorm:"index"`
+}
@@ -0,0 +1,77 @@
+package types
+
+import (
+	"strings"
+
+	"github.com/netbirdio/netbird/management/server/status"
+)
+
+// JWTGroupsMappingRule maps the groups of a JWT claim onto account groups
+type JWTGroupsMappingRule struct {
+	// Claim is the path of the claim holding the groups. Nested claims are separated by dots, e.g. "realm_access.roles"
+	Claim string `json:"claim"`
+
+	// Prefix filters out the groups not starting with it
+	Prefix string `json:"prefix,omitempty"`
+
+	// StripPrefix removes the prefix from the mapped group names
+	StripPrefix bool `json:"strip_prefix,omitempty"`
+
+	// Rename maps group names, after the prefix is stripped, onto account group names
+	Rename map[string]string `json:"rename,omitempty"`
+}
+
+// Validate checks that the rule is well-formed
+func (r *JWTGroupsMappingRule) Validate() error {
+	if strings.TrimSpace(r.Claim) == "" {
+		return status.Errorf(status.InvalidArgument, "JWT groups mapping rule claim can't be empty")
+	}
+
+	if r.StripPrefix && r.Prefix == "" {
+		return status.Errorf(status.InvalidArgument, "JWT groups mapping rule for claim %s strips an empty prefix", r.Claim)
+	}
+
+	for from, to := range r.Rename {
+		if from == "" || to == "" {
+			return status.Errorf(status.InvalidArgument, "JWT groups mapping rule for claim %s has an empty rename entry", r.Claim)
+		}
+	}
+
+	return nil
+}
+
+// Apply returns the account group names the given claim groups are mapped to
+func (r *JWTGroupsMappingRule) Apply(claimGroups []string) []string {
+	groups := make([]string, 0, len(claimGroups))
+	for _, group := range claimGroups {
+		if r.Prefix != "" && !strings.HasPrefix(group, r.Prefix) {
+			continue
+		}
+
+		if r.StripPrefix {
+			group = strings.TrimPrefix(group, r.Prefix)
+		}
+
+		if renamed, ok := r.Rename[group]; ok {
+			group = renamed
+		}
+
+		if group != "" {
+			groups = append(groups, group)
+		}
+	}
+
+	return groups
+}
+
+// Copy returns a copy of the rule
+func (r *JWTGroupsMappingRule) Copy() *JWTGroupsMappingRule {
+	rule := *r
+	if r.Rename != nil {
+		rule.Rename = make(map[string]string, len(r.Rename))
+		for from, to := range r.Rename {
+			rule.Rename[from] = to
+		}
+	}
+	return &rule
+}
@@ -0,0 +1,54 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJWTGroupsMappingRule_Apply(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     JWTGroupsMappingRule
+		groups   []string
+		expected []string
+	}{
+		{
+			name:     "no filter",
+			rule:     JWTGroupsMappingRule{Claim: "groups"},
+			groups:   []string{"admins", "devs"},
+			expected: []string{"admins", "devs"},
+		},
+		{
+			name:     "prefix filter",
+			rule:     JWTGroupsMappingRule{Claim: "groups", Prefix: "nb-"},
+			groups:   []string{"nb-admins", "devs"},
+			expected: []string{"nb-admins"},
+		},
+		{
+			name:     "strip prefix",
+			rule:     JWTGroupsMappingRule{Claim: "groups", Prefix: "/org/", StripPrefix: true},
+			groups:   []string{"/org/admins", "/org/", "/other/devs"},
+			expected: []string{"admins"},
+		},
+		{
+			name:     "rename",
+			rule:     JWTGroupsMappingRule{Claim: "groups", Prefix: "nb-", StripPrefix: true, Rename: map[string]string{"admins": "Administrators"}},
+			groups:   []string{"nb-admins", "nb-devs"},
+			expected: []string{"Administrators", "devs"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.rule.Apply(tt.groups))
+		})
+	}
+}
+
+func TestJWTGroupsMappingRule_Validate(t *testing.T) {
+	assert.NoError(t, (&JWTGroupsMappingRule{Claim: "groups"}).Validate())
+	assert.Error(t, (&JWTGroupsMappingRule{}).Validate(), "empty claim")
+	assert.Error(t, (&JWTGroupsMappingRule{Claim: "groups", StripPrefix: true}).Validate(), "empty prefix to strip")
+	assert.Error(t, (&JWTGroupsMappingRule{Claim: "groups", Rename: map[string]string{"admins": ""}}).Validate(), "empty rename target")
+}
@@ -1,8 +1,11 @@
 package types
 
 import (
+	"encoding/binary"
 	"math/rand"
 	"net"
+	"slices"
+	"sort"
 	"sync"
 	"time"
 
@@ -26,8 +29,26 @@ const (
 
 	// AllowedIPsFormat generates Wireguard AllowedIPs format (e.g. 100.64.30.1/32)
 	AllowedIPsFormat = "%s/32"
+
+	// minNetworkPrefixLength is the prefix length of the largest network an account can use, e.g. 10.0.0.0/8
+	minNetworkPrefixLength = 8
+	// maxNetworkPrefixLength is the prefix length of the smallest network an account can use
+	maxNetworkPrefixLength = 28
+	// maxEnumeratedNetworkSize is the size of the largest network whose free IPs are listed when allocating a peer IP.
+	// IPs of larger networks are picked randomly.
+	maxEnumeratedNetworkSize = 1 << 16
+	// maxRandomIPAttempts is the number of random IPs tried before falling back to listing the free IPs of a network
+	maxRandomIPAttempts = 1000
 )
 
+// allowedNetworkRanges are the ranges the network of an account has to be part of
+var allowedNetworkRanges = []net.IPNet{
+	{IP: net.IP{10, 0, 0, 0}, Mask: net.CIDRMask(8, 32)},
+	{IP: net.IP{100, 64, 0, 0}, Mask: net.CIDRMask(NetSize, 32)},
+	{IP: net.IP{172, 16, 0, 0}, Mask: net.CIDRMask(12, 32)},
+	{IP: net.IP{192, 168, 0, 0}, Mask: net.CIDRMask(16, 32)},
+}
+
 type NetworkMap struct {
 	Peers               []*nbpeer.Peer
 	Network             *Network
@@ -37,6 +58,24 @@ type NetworkMap struct {
 	FirewallRules       []*FirewallRule
 	RoutesFirewallRules []*RouteFirewallRule
 	ForwardingRules     []*ForwardingRule
+	// LoginExpiresAt is the time the login of the peer expires at, zero if it doesn't expire
+	LoginExpiresAt time.Time
+	// LoginExpirationNotification is the period before LoginExpiresAt at which the user of the peer is notified
+	LoginExpirationNotification time.Duration
+	// LoginExpirationGrace indicates whether the peer stays connected to the remediation policies once its login expired
+	LoginExpirationGrace bool
+	// LoginExpired indicates that the peer is in the login expiration grace period, limited to the remediation policies
+	LoginExpired bool
+	// ConnectionTuning overrides the timers of the peer connections, the client defaults apply to the zero durations
+	ConnectionTuning ConnectionTuning
+	// PortPolicy pins the WireGuard listen port and constrains the transports of the peer
+	PortPolicy PortPolicy
+	// ClientSettings are the defaults of the client settings of the peer, nil if the peer keeps its settings
+	ClientSettings *ClientSettings
+	// DisabledNotifications are the categories of the notifications the client of the peer doesn't show
+	DisabledNotifications []string
+	// FailedPostureChecks are the names of the posture checks of the policies of the peer it fails
+	FailedPostureChecks []string
 }
 
 func (nm *NetworkMap) Merge(other *NetworkMap) {
@@ -109,6 +148,9 @@ type Network struct {
 	Identifier string    `json:"id"`
 	Net        net.IPNet `gorm:"serializer:json"`
 	Dns        string
+	// ReservedRanges are sub-ranges of the network excluded from the automatic allocation of peer IPs.
+	// Their IPs can still be assigned to peers as static IPs.
+	ReservedRanges []string `gorm:"serializer:json"`
 	// Serial is an ID that increments by 1 when any change to the network happened (e.g. new peer has been added).
 	// Used to synchronize state to the client apps.
 	Serial uint64
@@ -150,37 +192,204 @@ func (n *Network) CurrentSerial() uint64 {
 
 func (n *Network) Copy() *Network {
 	return &Network{
-		Identifier: n.Identifier,
-		Net:        n.Net,
-		Dns:        n.Dns,
-		Serial:     n.Serial,
+		Identifier:     n.Identifier,
+		Net:            n.Net,
+		Dns:            n.Dns,
+		ReservedRanges: slices.Clone(n.ReservedRanges),
+		Serial:         n.Serial,
+	}
+}
+
+// GetReservedRanges returns the parsed reserved ranges of the network, skipping the invalid ones
+func (n *Network) GetReservedRanges() []net.IPNet {
+	ranges := make([]net.IPNet, 0, len(n.ReservedRanges))
+	for _, cidr := range n.ReservedRanges {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			continue
+		}
+		ranges = append(ranges, *ipNet)
+	}
+	return ranges
+}
+
+// ValidateNetworkRange checks that the given IPv4 network can be used as the overlay network of an account
+func ValidateNetworkRange(ipNet net.IPNet) error {
+	ones, bits := ipNet.Mask.Size()
+	if ipNet.IP.To4() == nil || bits != 32 {
+		return status.Errorf(status.InvalidArgument, "network range %s must be an IPv4 network", ipNet.String())
+	}
+
+	if ones < minNetworkPrefixLength || ones > maxNetworkPrefixLength {
+		return status.Errorf(status.InvalidArgument, "network range prefix length must be between /%d and /%d", minNetworkPrefixLength, maxNetworkPrefixLength)
+	}
+
+	if !ipNet.IP.Equal(ipNet.IP.Mask(ipNet.Mask)) {
+		return status.Errorf(status.InvalidArgument, "network range %s has host bits set, use %s instead", ipNet.String(), ipNet.IP.Mask(ipNet.Mask))
+	}
+
+	for _, allowed := range allowedNetworkRanges {
+		allowedOnes, _ := allowed.Mask.Size()
+		if allowed.Contains(ipNet.IP) && ones >= allowedOnes {
+			return nil
+		}
+	}
+
+	return status.Errorf(status.InvalidArgument, "network range %s must be part of a private or shared address space", ipNet.String())
+}
+
+// ParseReservedRanges parses the given CIDRs and checks that they are sub-ranges of the network ipNet
+func ParseReservedRanges(ipNet net.IPNet, cidrs []string) ([]net.IPNet, error) {
+	netOnes, _ := ipNet.Mask.Size()
+	ranges := make([]net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, reserved, err := net.ParseCIDR(cidr)
+		if err != nil || reserved.IP.To4() == nil {
+			return nil, status.Errorf(status.InvalidArgument, "invalid reserved range %s", cidr)
+		}
+
+		ones, _ := reserved.Mask.Size()
+		if !ipNet.Contains(reserved.IP) || ones < netOnes {
+			return nil, status.Errorf(status.InvalidArgument, "reserved range %s isn't part of the network range %s", cidr, ipNet.String())
+		}
+		ranges = append(ranges, *reserved)
+	}
+	return ranges, nil
+}
+
+// ValidatePeerIP checks that the given IP can be assigned to a peer of the network ipNet.
+// The network address, the broadcast address and the fake DNS resolver address can't be assigned.
+func ValidatePeerIP(ipNet net.IPNet, ip net.IP) error {
+	ip4 := ip.To4()
+	if ip4 == nil || !ipNet.Contains(ip4) {
+		return status.Errorf(status.InvalidArgument, "IP %s isn't part of the network range %s", ip, ipNet.String())
+	}
+
+	base := binary.BigEndian.Uint32(ipNet.IP.Mask(ipNet.Mask).To4())
+	ones, bits := ipNet.Mask.Size()
+	offset := binary.BigEndian.Uint32(ip4) - base
+	size := uint32(1) << (bits - ones)
+	if offset == 0 || offset >= size-2 {
+		return status.Errorf(status.InvalidArgument, "IP %s is reserved in the network range %s", ip, ipNet.String())
+	}
+
+	return nil
+}
+
+// PeerIPMigration is the change of the IP of a peer required by the change of the network range of its account
+type PeerIPMigration struct {
+	PeerID   string
+	PeerName string
+	OldIP    net.IP
+	NewIP    net.IP
+}
+
+// PlanPeerIPMigration returns the IP changes required to move the given peers to the network range ipNet.
+// Peers whose IP is part of the range keep it, the others get a free IP outside the reserved ranges.
+// It fails if a peer with a static IP isn't part of the range.
+func PlanPeerIPMigration(peers []*nbpeer.Peer, ipNet net.IPNet, reservedRanges []net.IPNet) ([]*PeerIPMigration, error) {
+	takenIPs := make([]net.IP, 0, len(peers))
+	var toMigrate []*nbpeer.Peer
+	for _, peer := range peers {
+		if ValidatePeerIP(ipNet, peer.IP) == nil {
+			takenIPs = append(takenIPs, peer.IP)
+			continue
+		}
+		if peer.StaticIP {
+			return nil, status.Errorf(status.PreconditionFailed, "peer %s has the static IP %s which isn't part of the network range %s", peer.Name, peer.IP, ipNet.String())
+		}
+		toMigrate = append(toMigrate, peer)
 	}
+
+	sort.Slice(toMigrate, func(i, j int) bool {
+		return toMigrate[i].ID < toMigrate[j].ID
+	})
+
+	migrations := make([]*PeerIPMigration, 0, len(toMigrate))
+	for _, peer := range toMigrate {
+		newIP, err := AllocatePeerIP(ipNet, takenIPs, reservedRanges...)
+		if err != nil {
+			return nil, err
+		}
+		takenIPs = append(takenIPs, newIP)
+		migrations = append(migrations, &PeerIPMigration{
+			PeerID:   peer.ID,
+			PeerName: peer.Name,
+			OldIP:    peer.IP,
+			NewIP:    newIP,
+		})
+	}
+
+	return migrations, nil
 }
 
 // AllocatePeerIP pics an available IP from an net.IPNet.
 // This method considers already taken IPs and reuses IPs if there are gaps in takenIps
 // E.g. if ipNet=100.30.0.0/16 and takenIps=[100.30.0.1, 100.30.0.4] then the result would be 100.30.0.2 or 100.30.0.3
-func AllocatePeerIP(ipNet net.IPNet, takenIps []net.IP) (net.IP, error) {
+// IPs that are part of the reservedRanges are never picked.
+func AllocatePeerIP(ipNet net.IPNet, takenIps []net.IP, reservedRanges ...net.IPNet) (net.IP, error) {
 	takenIPMap := make(map[string]struct{})
 	takenIPMap[ipNet.IP.String()] = struct{}{}
 	for _, ip := range takenIps {
 		takenIPMap[ip.String()] = struct{}{}
 	}
 
+	s := rand.NewSource(time.Now().Unix())
+	r := rand.New(s)
+
+	ones, bits := ipNet.Mask.Size()
+	if 1<<(bits-ones) > maxEnumeratedNetworkSize {
+		if ip, ok := pickRandomIP(ipNet, takenIPMap, reservedRanges, r); ok {
+			return ip, nil
+		}
+	}
+
 	ips, _ := generateIPs(&ipNet, takenIPMap)
+	ips = slices.DeleteFunc(ips, func(ip net.IP) bool {
+		return ipInRanges(ip, reservedRanges)
+	})
 
 	if len(ips) == 0 {
 		return nil, status.Errorf(status.PreconditionFailed, "failed allocating new IP for the ipNet %s - network is out of IPs", ipNet.String())
 	}
 
 	// pick a random IP
-	s := rand.NewSource(time.Now().Unix())
-	r := rand.New(s)
 	intn := r.Intn(len(ips))
 
 	return ips[intn], nil
 }
 
+// pickRandomIP tries to pick a random IP of the network that is neither excluded nor part of the reserved ranges.
+// The network address, the fake DNS resolver address and the broadcast address are never picked.
+func pickRandomIP(ipNet net.IPNet, exclusions map[string]struct{}, reservedRanges []net.IPNet, r *rand.Rand) (net.IP, bool) {
+	base := binary.BigEndian.Uint32(ipNet.IP.Mask(ipNet.Mask).To4())
+	ones, bits := ipNet.Mask.Size()
+	size := int64(1) << (bits - ones)
+
+	for i := 0; i < maxRandomIPAttempts; i++ {
+		ip := make(net.IP, net.IPv4len)
+		binary.BigEndian.PutUint32(ip, base+uint32(1+r.Int63n(size-3)))
+		if ip[3] == 0 || ipInRanges(ip, reservedRanges) {
+			continue
+		}
+		if _, ok := exclusions[ip.String()]; ok {
+			continue
+		}
+		return ip, true
+	}
+
+	return nil, false
+}
+
+func ipInRanges(ip net.IP, ranges []net.IPNet) bool {
+	for _, r := range ranges {
+		if r.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
 // generateIPs generates a list of all possible IPs of the given network excluding IPs specified in the exclusion list
 func generateIPs(ipNet *net.IPNet, exclusions map[string]struct{}) ([]net.IP, int) {
 
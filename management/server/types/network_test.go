@@ -5,6 +5,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	nbpeer "github.com/netbirdio/netbird/management/server/peer"
 )
 
 func TestNewNetwork(t *testing.T) {
@@ -49,3 +52,69 @@ func TestGenerateIPs(t *testing.T) {
 		t.Errorf("expected last ip to be: 100.64.0.253, got %s", ips[len(ips)-1].String())
 	}
 }
+
+func TestAllocatePeerIP_ReservedRanges(t *testing.T) {
+	ipNet := net.IPNet{IP: net.IP{100, 64, 0, 0}, Mask: net.CIDRMask(24, 32)}
+	reserved := net.IPNet{IP: net.IP{100, 64, 0, 0}, Mask: net.CIDRMask(25, 32)}
+	for i := 0; i < 50; i++ {
+		ip, err := AllocatePeerIP(ipNet, nil, reserved)
+		require.NoError(t, err)
+		assert.False(t, reserved.Contains(ip), "IP %s shouldn't be part of the reserved range", ip)
+	}
+}
+
+func TestAllocatePeerIP_LargeNetwork(t *testing.T) {
+	ipNet := net.IPNet{IP: net.IP{10, 0, 0, 0}, Mask: net.CIDRMask(8, 32)}
+	ip, err := AllocatePeerIP(ipNet, []net.IP{{10, 0, 0, 1}})
+	require.NoError(t, err)
+	assert.NoError(t, ValidatePeerIP(ipNet, ip))
+}
+
+func TestValidateNetworkRange(t *testing.T) {
+	tests := []struct {
+		cidr  string
+		valid bool
+	}{
+		{"100.64.0.0/16", true},
+		{"10.0.0.0/8", true},
+		{"172.20.0.0/14", true},
+		{"192.168.10.0/24", true},
+		{"100.64.0.0/10", true},
+		{"100.0.0.0/8", false},
+		{"8.8.0.0/16", false},
+		{"10.0.0.0/7", false},
+		{"10.0.0.0/30", false},
+	}
+
+	for _, tt := range tests {
+		_, ipNet, err := net.ParseCIDR(tt.cidr)
+		require.NoError(t, err)
+		err = ValidateNetworkRange(*ipNet)
+		if tt.valid {
+			assert.NoError(t, err, tt.cidr)
+		} else {
+			assert.Error(t, err, tt.cidr)
+		}
+	}
+
+	assert.Error(t, ValidateNetworkRange(net.IPNet{IP: net.IP{10, 0, 0, 1}, Mask: net.CIDRMask(16, 32)}), "host bits shouldn't be allowed")
+}
+
+func TestPlanPeerIPMigration(t *testing.T) {
+	ipNet := net.IPNet{IP: net.IP{10, 10, 0, 0}, Mask: net.CIDRMask(24, 32)}
+	peers := []*nbpeer.Peer{
+		{ID: "kept", IP: net.IP{10, 10, 0, 5}},
+		{ID: "moved", IP: net.IP{100, 64, 0, 5}},
+	}
+
+	migrations, err := PlanPeerIPMigration(peers, ipNet, nil)
+	require.NoError(t, err)
+	require.Len(t, migrations, 1)
+	assert.Equal(t, "moved", migrations[0].PeerID)
+	assert.True(t, ipNet.Contains(migrations[0].NewIP))
+	assert.False(t, migrations[0].NewIP.Equal(peers[0].IP))
+
+	peers[1].StaticIP = true
+	_, err = PlanPeerIPMigration(peers, ipNet, nil)
+	assert.Error(t, err, "static IPs outside of the network range should prevent the migration")
+}
@@ -0,0 +1,132 @@
+package types
+
+import (
+	"sync"
+
+	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+)
+
+// NetworkMapCache shares the fragments of the network maps that don't depend on the peer a map is generated for
+// between the maps generated from the same account state, e.g. by an account peers update. The validated peers of
+// the policy groups are resolved once, the firewall rules of the policy rules applied to all peers are generated
+// once and the peer IP strings are interned, so each additional peer adds the work of its own rules only.
+//
+// The fragments are shared by the generated network maps and must not be modified. The cache is safe for concurrent
+// use and has to be discarded when the account or its validated peers change. A nil cache shares nothing.
+type NetworkMapCache struct {
+	mu              sync.RWMutex
+	groupPeers      map[string]*peerSet
+	allPeersRules   map[allPeersRuleKey][]*FirewallRule
+	peerIPs         map[string]string
+	sessionSettings func(peerID string) SessionSettings
+}
+
+// NewNetworkMapCache creates an empty NetworkMapCache
+func NewNetworkMapCache() *NetworkMapCache {
+	return &NetworkMapCache{
+		groupPeers:    make(map[string]*peerSet),
+		allPeersRules: make(map[allPeersRuleKey][]*FirewallRule),
+		peerIPs:       make(map[string]string),
+	}
+}
+
+// peerSet is the list of the validated peers of policy groups, the list is shared and may contain the peer the
+// network map is generated for
+type peerSet struct {
+	peers   []*nbpeer.Peer
+	members map[string]struct{}
+}
+
+func newPeerSet(capacity int) *peerSet {
+	return &peerSet{
+		peers:   make([]*nbpeer.Peer, 0, capacity),
+		members: make(map[string]struct{}, capacity),
+	}
+}
+
+func (s *peerSet) add(peer *nbpeer.Peer) {
+	if _, ok := s.members[peer.ID]; ok {
+		return
+	}
+	s.members[peer.ID] = struct{}{}
+	s.peers = append(s.peers, peer)
+}
+
+func (s *peerSet) contains(peerID string) bool {
+	_, ok := s.members[peerID]
+	return ok
+}
+
+type allPeersRuleKey struct {
+	ruleID    string
+	direction int
+}
+
+func (c *NetworkMapCache) getGroupPeers(key string, compute func() *peerSet) *peerSet {
+	if c == nil {
+		return compute()
+	}
+	return loadOrCompute(&c.mu, c.groupPeers, key, compute)
+}
+
+func (c *NetworkMapCache) getAllPeersRules(key allPeersRuleKey, compute func() []*FirewallRule) []*FirewallRule {
+	if c == nil {
+		return compute()
+	}
+	return loadOrCompute(&c.mu, c.allPeersRules, key, compute)
+}
+
+// getPeerIP returns the interned IP string of the peer
+func (c *NetworkMapCache) getPeerIP(peer *nbpeer.Peer) string {
+	compute := func() string {
+		return peer.IP.String()
+	}
+	if c == nil {
+		return compute()
+	}
+	return loadOrCompute(&c.mu, c.peerIPs, peer.ID, compute)
+}
+
+// getSessionSettings returns the session settings resolver of the account
+func (c *NetworkMapCache) getSessionSettings(a *Account) func(peerID string) SessionSettings {
+	if c == nil {
+		return NewSessionSettingsResolver(a.Settings, a.Groups)
+	}
+
+	c.mu.RLock()
+	resolver := c.sessionSettings
+	c.mu.RUnlock()
+	if resolver != nil {
+		return resolver
+	}
+
+	resolver = NewSessionSettingsResolver(a.Settings, a.Groups)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.sessionSettings == nil {
+		c.sessionSettings = resolver
+	}
+	return c.sessionSettings
+}
+
+// loadOrCompute returns the cached value of the key, or computes and caches it. The value is computed without
+// holding the lock, concurrent computations of the same key return the value stored first.
+func loadOrCompute[K comparable, V any](mu *sync.RWMutex, values map[K]V, key K, compute func() V) V {
+	mu.RLock()
+	value, ok := values[key]
+	mu.RUnlock()
+	if ok {
+		return value
+	}
+
+	value = compute()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if existing, ok := values[key]; ok {
+		return existing
+	}
+	values[key] = value
+	return value
+}
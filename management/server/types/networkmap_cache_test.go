@@ -0,0 +1,132 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	nbdns "github.com/netbirdio/netbird/dns"
+	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+)
+
+// newLargeTestAccount creates an account with the peers spread over the groups, a policy connecting all the peers
+// and a policy per group pair with ports.
+func newLargeTestAccount(peers, groups int) (*Account, map[string]struct{}) {
+	account := &Account{
+		Id:       "accountID",
+		Peers:    make(map[string]*nbpeer.Peer, peers),
+		Groups:   make(map[string]*Group, groups+1),
+		Network:  &Network{Identifier: "net", Net: net.IPNet{IP: net.ParseIP("100.64.0.0"), Mask: net.CIDRMask(10, 32)}},
+		Settings: &Settings{},
+	}
+	validatedPeers := make(map[string]struct{}, peers)
+
+	all := &Group{ID: "all", Name: "All", Issued: GroupIssuedAPI}
+	for i := 0; i < groups; i++ {
+		id := fmt.Sprintf("group%d", i)
+		account.Groups[id] = &Group{ID: id, Name: id}
+	}
+
+	for i := 0; i < peers; i++ {
+		peer := &nbpeer.Peer{
+			ID:        fmt.Sprintf("peer%d", i),
+			AccountID: "accountID",
+			Key:       fmt.Sprintf("peer%dKey", i),
+			IP:        net.IPv4(100, 64, byte(i>>8), byte(i)),
+			Status:    &nbpeer.PeerStatus{Connected: true},
+		}
+		account.Peers[peer.ID] = peer
+		validatedPeers[peer.ID] = struct{}{}
+		all.Peers = append(all.Peers, peer.ID)
+
+		group := account.Groups[fmt.Sprintf("group%d", i%groups)]
+		group.Peers = append(group.Peers, peer.ID)
+	}
+	account.Groups[all.ID] = all
+
+	account.Policies = append(account.Policies, &Policy{
+		ID:      "all",
+		Enabled: true,
+		Rules: []*PolicyRule{{
+			ID:            "all",
+			Enabled:       true,
+			Action:        PolicyTrafficActionAccept,
+			Protocol:      PolicyRuleProtocolALL,
+			Bidirectional: true,
+			Sources:       []string{"all"},
+			Destinations:  []string{"all"},
+		}},
+	})
+	for i := 0; i < groups; i++ {
+		id := fmt.Sprintf("policy%d", i)
+		account.Policies = append(account.Policies, &Policy{
+			ID:      id,
+			Enabled: true,
+			Rules: []*PolicyRule{{
+				ID:           id,
+				Enabled:      true,
+				Action:       PolicyTrafficActionAccept,
+				Protocol:     PolicyRuleProtocolTCP,
+				Ports:        []string{"22", "443"},
+				Sources:      []string{fmt.Sprintf("group%d", i)},
+				Destinations: []string{fmt.Sprintf("group%d", (i+1)%groups)},
+			}},
+		})
+	}
+
+	return account, validatedPeers
+}
+
+func TestNetworkMapCache_SameNetworkMaps(t *testing.T) {
+	account, validatedPeers := newLargeTestAccount(200, 5)
+	cache := NewNetworkMapCache()
+
+	for peerID := range account.Peers {
+		expected := account.GetPeerNetworkMap(context.Background(), peerID, nbdns.CustomZone{}, validatedPeers, nil, nil, nil)
+		cached := account.GetPeerNetworkMapWithCache(context.Background(), peerID, nbdns.CustomZone{}, validatedPeers, nil, nil, nil, cache)
+
+		assert.ElementsMatch(t, expected.Peers, cached.Peers, "peers of %s", peerID)
+		assert.ElementsMatch(t, expected.FirewallRules, cached.FirewallRules, "firewall rules of %s", peerID)
+	}
+
+	// the peers are connected to all the other peers, so the rules of the all policy are shared
+	nm := account.GetPeerNetworkMapWithCache(context.Background(), "peer1", nbdns.CustomZone{}, validatedPeers, nil, nil, nil, cache)
+	other := account.GetPeerNetworkMapWithCache(context.Background(), "peer2", nbdns.CustomZone{}, validatedPeers, nil, nil, nil, cache)
+	assert.Len(t, nm.Peers, 199)
+	assert.Contains(t, nm.FirewallRules, &FirewallRule{PolicyID: "all", PeerIP: "0.0.0.0", Direction: FirewallRuleDirectionIN, Action: "accept", Protocol: "all"})
+	assert.Same(t, nm.FirewallRules[0], other.FirewallRules[0])
+}
+
+func BenchmarkGetPeerNetworkMap(b *testing.B) {
+	for _, peers := range []int{1000, 5000, 10000} {
+		account, validatedPeers := newLargeTestAccount(peers, 50)
+
+		// the maps of a sample of the peers are generated, the cost per map shows how it grows with the account
+		sample := make([]string, 0, 100)
+		for i := 0; i < 100; i++ {
+			sample = append(sample, fmt.Sprintf("peer%d", i*peers/100))
+		}
+
+		b.Run(fmt.Sprintf("%d peers", peers), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				for _, peerID := range sample {
+					account.GetPeerNetworkMap(context.Background(), peerID, nbdns.CustomZone{}, validatedPeers, nil, nil, nil)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("%d peers cached", peers), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				cache := NewNetworkMapCache()
+				for _, peerID := range sample {
+					account.GetPeerNetworkMapWithCache(context.Background(), peerID, nbdns.CustomZone{}, validatedPeers, nil, nil, nil, cache)
+				}
+			}
+		})
+	}
+}
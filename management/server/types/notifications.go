@@ -0,0 +1,63 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/netbirdio/netbird/management/proto"
+)
+
+// ValidateDisabledNotifications checks the categories of the notifications disabled for the clients
+func ValidateDisabledNotifications(categories []string) error {
+	for _, category := range categories {
+		if !slices.Contains(proto.Notifications, category) {
+			return fmt.Errorf("unknown notification category %q", category)
+		}
+	}
+	return nil
+}
+
+// getPeerFailedPostureChecks returns the names of the posture checks of the enabled policies of the peer it fails.
+// The peer loses the access granted by these policies, the client notifies its user to remediate it.
+func (a *Account) getPeerFailedPostureChecks(ctx context.Context, peerID string) []string {
+	peer, ok := a.Peers[peerID]
+	if !ok || peer == nil {
+		return nil
+	}
+
+	failed := make(map[string]struct{})
+	for _, policy := range a.Policies {
+		if !policy.Enabled || len(policy.SourcePostureChecks) == 0 {
+			continue
+		}
+		if !slices.Contains(a.getPolicySourcePeerIDs(ctx, policy), peerID) {
+			continue
+		}
+
+		for _, postureChecksID := range policy.SourcePostureChecks {
+			postureChecks := a.GetPostureChecks(postureChecksID)
+			if postureChecks == nil {
+				continue
+			}
+			if _, ok := failed[postureChecks.Name]; ok {
+				continue
+			}
+			if !a.validatePostureChecksOnPeer(ctx, []string{postureChecksID}, peerID) {
+				failed[postureChecks.Name] = struct{}{}
+			}
+		}
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(failed))
+	for name := range failed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
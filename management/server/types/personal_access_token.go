@@ -5,6 +5,8 @@ import (
 	b64 "encoding/base64"
 	"fmt"
 	"hash/crc32"
+	"slices"
+	"strings"
 	"time"
 
 	b "github.com/hashicorp/go-secure-stdlib/base62"
@@ -23,6 +25,16 @@ const (
 	PATChecksumLength = 6
 	// PATLength total number of characters used for the token
 	PATLength = 40
+	// PATScopeSeparator separates the module from the operation in a token scope, e.g. peers:read
+	PATScopeSeparator = ":"
+	// PATScopeRead is the operation of a read-only token scope
+	PATScopeRead = "read"
+	// PATScopeWrite is the operation of a token scope allowing changes. It implies read
+	PATScopeWrite = "write"
+	// DefaultPATRotationGracePeriod is how long a rotated token stays valid next to its replacement by default
+	DefaultPATRotationGracePeriod = 24 * time.Hour
+	// MaxPATRotationGracePeriod is the longest a rotated token can stay valid next to its replacement
+	MaxPATRotationGracePeriod = 7 * 24 * time.Hour
 )
 
 // PersonalAccessToken holds all information about a PAT including a hashed version of it for verification
@@ -33,7 +45,10 @@ type PersonalAccessToken struct {
 	Name           string
 	HashedToken    string
 	ExpirationDate *time.Time
-	// scope could be added in future
+	// Scopes restrict the token to the listed module operations, e.g. peers:read. Empty grants the user's permissions
+	Scopes []string `gorm:"serializer:json"`
+	// Groups restrict the peers and groups the token can modify. Empty doesn't restrict them
+	Groups    []string `gorm:"serializer:json"`
 	CreatedBy string
 	CreatedAt time.Time
 	LastUsed  *time.Time
@@ -45,6 +60,8 @@ func (t *PersonalAccessToken) Copy() *PersonalAccessToken {
 		Name:           t.Name,
 		HashedToken:    t.HashedToken,
 		ExpirationDate: t.ExpirationDate,
+		Scopes:         slices.Clone(t.Scopes),
+		Groups:         slices.Clone(t.Groups),
 		CreatedBy:      t.CreatedBy,
 		CreatedAt:      t.CreatedAt,
 		LastUsed:       t.LastUsed,
@@ -67,6 +84,95 @@ func (t *PersonalAccessToken) GetLastUsed() time.Time {
 	return time.Time{}
 }
 
+// IsScoped returns true if the token is restricted to a subset of the user's permissions
+func (t *PersonalAccessToken) IsScoped() bool {
+	return len(t.Scopes) > 0 || len(t.Groups) > 0
+}
+
+// ParsePATScope splits a token scope into its module and operation
+func ParsePATScope(scope string) (string, string, error) {
+	module, operation, found := strings.Cut(scope, PATScopeSeparator)
+	if !found || module == "" {
+		return "", "", fmt.Errorf("invalid token scope %q, expected format module:operation", scope)
+	}
+
+	if operation != PATScopeRead && operation != PATScopeWrite {
+		return "", "", fmt.Errorf("invalid operation %q in token scope %q", operation, scope)
+	}
+
+	return module, operation, nil
+}
+
+// PATScopesAllow returns true if the token scopes allow the operation on the module. Write scopes imply read.
+// Tokens without scopes are allowed everything the user is allowed to do.
+func PATScopesAllow(scopes []string, module, operation string) bool {
+	if len(scopes) == 0 {
+		return true
+	}
+
+	for _, scope := range scopes {
+		scopeModule, scopeOperation, err := ParsePATScope(scope)
+		if err != nil || scopeModule != module {
+			continue
+		}
+
+		if scopeOperation == operation || scopeOperation == PATScopeWrite {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PATRestrictionsWithin returns true if a token with the scopes and groups isn't allowed more than a token with the
+// parent scopes and groups. Tokens without scopes or groups are only within unrestricted parents.
+func PATRestrictionsWithin(scopes, groups, parentScopes, parentGroups []string) bool {
+	if len(parentScopes) > 0 {
+		if len(scopes) == 0 {
+			return false
+		}
+
+		for _, scope := range scopes {
+			module, operation, err := ParsePATScope(scope)
+			if err != nil || !PATScopesAllow(parentScopes, module, operation) {
+				return false
+			}
+		}
+	}
+
+	if len(parentGroups) > 0 {
+		if len(groups) == 0 {
+			return false
+		}
+
+		for _, group := range groups {
+			if !slices.Contains(parentGroups, group) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Rotate generates a token replacing this one with the same name and restrictions.
+// This token stays valid for the grace period, so clients can switch to the new token without downtime.
+func (t *PersonalAccessToken) Rotate(expirationInDays int, gracePeriod time.Duration, rotatedBy string) (*PersonalAccessTokenGenerated, error) {
+	newPAT, err := CreateNewPAT(t.Name, expirationInDays, t.UserID, rotatedBy)
+	if err != nil {
+		return nil, err
+	}
+	newPAT.Scopes = slices.Clone(t.Scopes)
+	newPAT.Groups = slices.Clone(t.Groups)
+
+	graceExpiration := time.Now().Add(gracePeriod)
+	if t.ExpirationDate == nil || t.ExpirationDate.After(graceExpiration) {
+		t.ExpirationDate = &graceExpiration
+	}
+
+	return newPAT, nil
+}
+
 // PersonalAccessTokenGenerated holds the new PersonalAccessToken and the plain text version of it
 type PersonalAccessTokenGenerated struct {
 	PlainToken string
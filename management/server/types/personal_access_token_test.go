@@ -44,3 +44,42 @@ func TestPAT_GenerateToken_Checksum(t *testing.T) {
 	}
 	assert.Equal(t, expectedChecksum, actualChecksum)
 }
+
+func TestPAT_ScopesAllow(t *testing.T) {
+	assert.True(t, PATScopesAllow(nil, "peers", PATScopeWrite), "tokens without scopes are not restricted")
+
+	scopes := []string{"peers:read", "routes:write"}
+	assert.True(t, PATScopesAllow(scopes, "peers", PATScopeRead))
+	assert.False(t, PATScopesAllow(scopes, "peers", PATScopeWrite))
+	assert.True(t, PATScopesAllow(scopes, "routes", PATScopeRead), "write scopes imply read")
+	assert.True(t, PATScopesAllow(scopes, "routes", PATScopeWrite))
+	assert.False(t, PATScopesAllow(scopes, "groups", PATScopeRead))
+}
+
+func TestPAT_RestrictionsWithin(t *testing.T) {
+	assert.True(t, PATRestrictionsWithin(nil, nil, nil, nil), "unrestricted tokens can create unrestricted tokens")
+	assert.True(t, PATRestrictionsWithin([]string{"peers:write"}, []string{"group1"}, nil, nil))
+
+	parentScopes := []string{"peers:read", "users:write"}
+	assert.False(t, PATRestrictionsWithin(nil, nil, parentScopes, nil), "a scoped token can't create an unscoped token")
+	assert.True(t, PATRestrictionsWithin([]string{"users:read", "peers:read"}, nil, parentScopes, nil))
+	assert.False(t, PATRestrictionsWithin([]string{"peers:write"}, nil, parentScopes, nil))
+	assert.False(t, PATRestrictionsWithin([]string{"routes:read"}, nil, parentScopes, nil))
+
+	parentGroups := []string{"group1", "group2"}
+	assert.False(t, PATRestrictionsWithin(nil, nil, nil, parentGroups), "a group restricted token can't create an unrestricted token")
+	assert.True(t, PATRestrictionsWithin(nil, []string{"group2"}, nil, parentGroups))
+	assert.False(t, PATRestrictionsWithin(nil, []string{"group1", "group3"}, nil, parentGroups))
+}
+
+func TestPAT_ParseScope(t *testing.T) {
+	module, operation, err := ParsePATScope("setup_keys:write")
+	assert.NoError(t, err)
+	assert.Equal(t, "setup_keys", module)
+	assert.Equal(t, PATScopeWrite, operation)
+
+	for _, scope := range []string{"peers", ":read", "peers:delete", ""} {
+		_, _, err = ParsePATScope(scope)
+		assert.Error(t, err, scope)
+	}
+}
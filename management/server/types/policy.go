@@ -64,6 +64,10 @@ type Policy struct {
 
 	// SourcePostureChecks are ID references to Posture checks for policy source groups
 	SourcePostureChecks []string `gorm:"serializer:json"`
+
+	// Remediation policies keep applying to the expired peers in the login expiration grace period,
+	// e.g. to let them reach the identity provider
+	Remediation bool
 }
 
 // Copy returns a copy of the policy.
@@ -74,6 +78,7 @@ func (p *Policy) Copy() *Policy {
 		Name:                p.Name,
 		Description:         p.Description,
 		Enabled:             p.Enabled,
+		Remediation:         p.Remediation,
 		Rules:               make([]*PolicyRule, len(p.Rules)),
 		SourcePostureChecks: make([]string, len(p.SourcePostureChecks)),
 	}
@@ -115,6 +120,16 @@ func (p *Policy) RuleGroups() []string {
 	return groups
 }
 
+// HasLabelSelectors checks if any rule of the policy selects its sources or destinations by their labels
+func (p *Policy) HasLabelSelectors() bool {
+	for _, rule := range p.Rules {
+		if len(rule.SourceLabels) > 0 || len(rule.DestinationLabels) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 // SourceGroups returns a slice of all unique source groups referenced in the policy's rules.
 func (p *Policy) SourceGroups() []string {
 	if len(p.Rules) == 1 {
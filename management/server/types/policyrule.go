@@ -1,6 +1,9 @@
 package types
 
 import (
+	"maps"
+	"strconv"
+
 	"github.com/netbirdio/netbird/management/proto"
 )
 
@@ -69,6 +72,13 @@ type PolicyRule struct {
 	// SourceResource policy source resource that the rule is applied to
 	SourceResource Resource `gorm:"serializer:json"`
 
+	// SourceLabels selects the peers having all the labels as sources, in addition to the source groups
+	SourceLabels map[string]string `gorm:"serializer:json"`
+
+	// DestinationLabels selects the peers, the routes and the network resources having all the labels as
+	// destinations, in addition to the destination groups
+	DestinationLabels map[string]string `gorm:"serializer:json"`
+
 	// Bidirectional define if the rule is applicable in both directions, sources, and destinations
 	Bidirectional bool
 
@@ -95,6 +105,8 @@ func (pm *PolicyRule) Copy() *PolicyRule {
 		DestinationResource: pm.DestinationResource,
 		Sources:             make([]string, len(pm.Sources)),
 		SourceResource:      pm.SourceResource,
+		SourceLabels:        maps.Clone(pm.SourceLabels),
+		DestinationLabels:   maps.Clone(pm.DestinationLabels),
 		Bidirectional:       pm.Bidirectional,
 		Protocol:            pm.Protocol,
 		Ports:               make([]string, len(pm.Ports)),
@@ -106,3 +118,57 @@ func (pm *PolicyRule) Copy() *PolicyRule {
 	copy(rule.PortRanges, pm.PortRanges)
 	return rule
 }
+
+// ScopedTo returns a copy of the rule restricted to the protocol and the port range of a port-scoped resource. It
+// returns false if the rule doesn't match any of the ports, e.g. a UDP rule for a TCP resource.
+func (pm *PolicyRule) ScopedTo(protocol PolicyRuleProtocolType, start, end uint16) (*PolicyRule, bool) {
+	if pm.Protocol != PolicyRuleProtocolALL && pm.Protocol != protocol {
+		return nil, false
+	}
+
+	rule := pm.Copy()
+	rule.Protocol = protocol
+	rule.Ports = nil
+	rule.PortRanges = nil
+
+	if len(pm.Ports) == 0 && len(pm.PortRanges) == 0 {
+		if start == end {
+			rule.Ports = []string{strconv.Itoa(int(start))}
+		} else {
+			rule.PortRanges = []RulePortRange{{Start: start, End: end}}
+		}
+		return rule, true
+	}
+
+	var ranges []RulePortRange
+	for _, p := range pm.Ports {
+		port, err := strconv.ParseUint(p, 10, 16)
+		if err != nil || uint16(port) < start || uint16(port) > end {
+			continue
+		}
+		ranges = append(ranges, RulePortRange{Start: uint16(port), End: uint16(port)})
+	}
+	for _, r := range pm.PortRanges {
+		r.Start, r.End = max(r.Start, start), min(r.End, end)
+		if r.Start <= r.End {
+			ranges = append(ranges, r)
+		}
+	}
+	if len(ranges) == 0 {
+		return nil, false
+	}
+
+	// the rules are generated either for the ports or for the port ranges, the ports are kept as such if possible
+	singles := true
+	for _, r := range ranges {
+		singles = singles && r.Start == r.End
+	}
+	if !singles {
+		rule.PortRanges = ranges
+		return rule, true
+	}
+	for _, r := range ranges {
+		rule.Ports = append(rule.Ports, strconv.Itoa(int(r.Start)))
+	}
+	return rule, true
+}
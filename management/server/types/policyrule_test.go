@@ -0,0 +1,80 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicyRule_ScopedTo(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     PolicyRule
+		protocol PolicyRuleProtocolType
+		start    uint16
+		end      uint16
+		expected *PolicyRule
+	}{
+		{
+			name:     "all traffic is restricted to the port",
+			rule:     PolicyRule{Protocol: PolicyRuleProtocolALL},
+			protocol: PolicyRuleProtocolTCP,
+			start:    5432,
+			end:      5432,
+			expected: &PolicyRule{Protocol: PolicyRuleProtocolTCP, Ports: []string{"5432"}},
+		},
+		{
+			name:     "all ports are restricted to the range",
+			rule:     PolicyRule{Protocol: PolicyRuleProtocolUDP},
+			protocol: PolicyRuleProtocolUDP,
+			start:    5000,
+			end:      5100,
+			expected: &PolicyRule{Protocol: PolicyRuleProtocolUDP, PortRanges: []RulePortRange{{Start: 5000, End: 5100}}},
+		},
+		{
+			name:     "ports outside of the range are dropped",
+			rule:     PolicyRule{Protocol: PolicyRuleProtocolTCP, Ports: []string{"22", "5432"}},
+			protocol: PolicyRuleProtocolTCP,
+			start:    5432,
+			end:      5432,
+			expected: &PolicyRule{Protocol: PolicyRuleProtocolTCP, Ports: []string{"5432"}},
+		},
+		{
+			name:     "port ranges are intersected",
+			rule:     PolicyRule{Protocol: PolicyRuleProtocolTCP, PortRanges: []RulePortRange{{Start: 1, End: 5050}, {Start: 6000, End: 7000}}},
+			protocol: PolicyRuleProtocolTCP,
+			start:    5000,
+			end:      5100,
+			expected: &PolicyRule{Protocol: PolicyRuleProtocolTCP, PortRanges: []RulePortRange{{Start: 5000, End: 5050}}},
+		},
+		{
+			name:     "another protocol doesn't match",
+			rule:     PolicyRule{Protocol: PolicyRuleProtocolUDP},
+			protocol: PolicyRuleProtocolTCP,
+			start:    5432,
+			end:      5432,
+		},
+		{
+			name:     "ports outside of the range don't match",
+			rule:     PolicyRule{Protocol: PolicyRuleProtocolTCP, Ports: []string{"22"}, PortRanges: []RulePortRange{{Start: 80, End: 443}}},
+			protocol: PolicyRuleProtocolTCP,
+			start:    5432,
+			end:      5432,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, ok := tt.rule.ScopedTo(tt.protocol, tt.start, tt.end)
+			if tt.expected == nil {
+				assert.False(t, ok)
+				return
+			}
+
+			assert.True(t, ok)
+			assert.Equal(t, tt.expected.Protocol, rule.Protocol)
+			assert.Equal(t, tt.expected.Ports, rule.Ports)
+			assert.Equal(t, tt.expected.PortRanges, rule.PortRanges)
+		})
+	}
+}
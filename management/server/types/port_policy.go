@@ -0,0 +1,117 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const maxPort = 65535
+
+// PortPolicy pins the WireGuard listen port of the peers of a group and constrains the transports of their
+// connections, for peers behind firewalls permitting only known ports
+type PortPolicy struct {
+	// WgPortRangeStart is the first WireGuard listen port the peers can use, 0 if the port isn't pinned
+	WgPortRangeStart int
+	// WgPortRangeEnd is the last WireGuard listen port the peers can use, the port is fixed if it equals the start
+	WgPortRangeEnd int
+	// TCP443Only limits the peers to the connections relayed over TCP port 443, no UDP traffic leaves the peers
+	TCP443Only bool
+}
+
+// Copy returns a copy of the port policy
+func (p *PortPolicy) Copy() *PortPolicy {
+	if p == nil {
+		return nil
+	}
+	policy := *p
+	return &policy
+}
+
+// IsEmpty checks if the policy doesn't constrain the peers
+func (p *PortPolicy) IsEmpty() bool {
+	return p == nil || *p == PortPolicy{}
+}
+
+// HasPortRange checks if the policy pins the WireGuard listen port
+func (p *PortPolicy) HasPortRange() bool {
+	return p.WgPortRangeStart != 0
+}
+
+// Validate checks that the port range is complete, ordered and made of valid ports
+func (p *PortPolicy) Validate() error {
+	for _, port := range []int{p.WgPortRangeStart, p.WgPortRangeEnd} {
+		if port < 0 || port > maxPort {
+			return fmt.Errorf("invalid WireGuard port %d", port)
+		}
+	}
+	if (p.WgPortRangeStart == 0) != (p.WgPortRangeEnd == 0) {
+		return errors.New("both the start and the end of the WireGuard port range have to be set")
+	}
+	if p.WgPortRangeEnd < p.WgPortRangeStart {
+		return errors.New("the end of the WireGuard port range can't be smaller than its start")
+	}
+	return nil
+}
+
+// NewPeerPortPolicy resolves the port policy of a peer member of the groups. The port range is the intersection of
+// the ranges of the groups, the range of the first group by ID applies when they don't overlap. The TCP 443 mode
+// applies when any group sets it.
+func NewPeerPortPolicy(groups []*Group) PortPolicy {
+	groups = slices.Clone(groups)
+	slices.SortFunc(groups, func(a, b *Group) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	var policy PortPolicy
+	var first *PortPolicy
+	for _, group := range groups {
+		if group.PortPolicy == nil {
+			continue
+		}
+		policy.TCP443Only = policy.TCP443Only || group.PortPolicy.TCP443Only
+
+		if !group.PortPolicy.HasPortRange() {
+			continue
+		}
+		if first == nil {
+			first = group.PortPolicy
+			policy.WgPortRangeStart = first.WgPortRangeStart
+			policy.WgPortRangeEnd = first.WgPortRangeEnd
+			continue
+		}
+		policy.WgPortRangeStart = max(policy.WgPortRangeStart, group.PortPolicy.WgPortRangeStart)
+		policy.WgPortRangeEnd = min(policy.WgPortRangeEnd, group.PortPolicy.WgPortRangeEnd)
+	}
+
+	if first != nil && policy.WgPortRangeEnd < policy.WgPortRangeStart {
+		log.Warnf("the WireGuard port ranges of the groups %s don't overlap, using %d-%d",
+			groupIDs(groups), first.WgPortRangeStart, first.WgPortRangeEnd)
+		policy.WgPortRangeStart = first.WgPortRangeStart
+		policy.WgPortRangeEnd = first.WgPortRangeEnd
+	}
+
+	return policy
+}
+
+func groupIDs(groups []*Group) string {
+	ids := make([]string, 0, len(groups))
+	for _, group := range groups {
+		ids = append(ids, group.ID)
+	}
+	return strings.Join(ids, ", ")
+}
+
+// getPeerPortPolicy returns the port policy of the peer resolved from its groups
+func (a *Account) getPeerPortPolicy(peerID string) PortPolicy {
+	var groups []*Group
+	for _, group := range a.Groups {
+		if group.PortPolicy != nil && slices.Contains(group.Peers, peerID) {
+			groups = append(groups, group)
+		}
+	}
+	return NewPeerPortPolicy(groups)
+}
@@ -0,0 +1,61 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPortPolicy_Validate(t *testing.T) {
+	assert.NoError(t, (&PortPolicy{}).Validate())
+	assert.NoError(t, (&PortPolicy{TCP443Only: true}).Validate())
+	assert.NoError(t, (&PortPolicy{WgPortRangeStart: 51820, WgPortRangeEnd: 51820}).Validate())
+	assert.NoError(t, (&PortPolicy{WgPortRangeStart: 51820, WgPortRangeEnd: 51830}).Validate())
+	assert.Error(t, (&PortPolicy{WgPortRangeStart: 51820}).Validate())
+	assert.Error(t, (&PortPolicy{WgPortRangeEnd: 51820}).Validate())
+	assert.Error(t, (&PortPolicy{WgPortRangeStart: 51830, WgPortRangeEnd: 51820}).Validate())
+	assert.Error(t, (&PortPolicy{WgPortRangeStart: 51820, WgPortRangeEnd: 70000}).Validate())
+	assert.Error(t, (&PortPolicy{WgPortRangeStart: -1, WgPortRangeEnd: 51820}).Validate())
+}
+
+func TestNewPeerPortPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		groups   []*Group
+		expected PortPolicy
+	}{
+		{
+			name:     "no policy",
+			groups:   []*Group{{ID: "group1"}},
+			expected: PortPolicy{},
+		},
+		{
+			name:     "fixed port",
+			groups:   []*Group{{ID: "group1", PortPolicy: &PortPolicy{WgPortRangeStart: 51820, WgPortRangeEnd: 51820}}},
+			expected: PortPolicy{WgPortRangeStart: 51820, WgPortRangeEnd: 51820},
+		},
+		{
+			name: "intersection of the ranges",
+			groups: []*Group{
+				{ID: "group1", PortPolicy: &PortPolicy{WgPortRangeStart: 51820, WgPortRangeEnd: 51840}},
+				{ID: "group2", PortPolicy: &PortPolicy{WgPortRangeStart: 51830, WgPortRangeEnd: 51850}},
+				{ID: "group3", PortPolicy: &PortPolicy{TCP443Only: true}},
+			},
+			expected: PortPolicy{WgPortRangeStart: 51830, WgPortRangeEnd: 51840, TCP443Only: true},
+		},
+		{
+			name: "first group by ID when the ranges don't overlap",
+			groups: []*Group{
+				{ID: "group2", PortPolicy: &PortPolicy{WgPortRangeStart: 51830, WgPortRangeEnd: 51830}},
+				{ID: "group1", PortPolicy: &PortPolicy{WgPortRangeStart: 51820, WgPortRangeEnd: 51820}},
+			},
+			expected: PortPolicy{WgPortRangeStart: 51820, WgPortRangeEnd: 51820},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NewPeerPortPolicy(tt.groups))
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package types
+
+import (
+	"context"
+	"slices"
+
+	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+	"github.com/netbirdio/netbird/route"
+)
+
+// peerInLoginExpirationGrace checks if the login of the peer has expired while the grace period is enabled,
+// in which case the peer keeps access to the remediation policies only
+func (a *Account) peerInLoginExpirationGrace(peer *nbpeer.Peer) bool {
+	if a.Settings == nil || !a.Settings.PeerLoginExpirationGraceEnabled || !peer.AddedWithSSOLogin() {
+		return false
+	}
+
+	if peer.Status != nil && peer.Status.LoginExpired {
+		return true
+	}
+
+	var groups []*Group
+	for _, group := range a.Groups {
+		if group.SessionPolicy != nil && slices.Contains(group.Peers, peer.ID) {
+			groups = append(groups, group)
+		}
+	}
+
+	expired, _ := NewSessionSettings(a.Settings, groups).LoginExpired(peer)
+	return expired
+}
+
+// remediationAccount returns a shallow copy of the account limited to the remediation policies.
+// The routes distributed by peer groups are dropped as they are not subject to policies.
+func (a *Account) remediationAccount() *Account {
+	account := *a
+	account.Policies = make([]*Policy, 0)
+	for _, policy := range a.Policies {
+		if policy.Remediation {
+			account.Policies = append(account.Policies, policy)
+		}
+	}
+	account.Routes = make(map[route.ID]*route.Route)
+	return &account
+}
+
+// getRemediationPeers returns the peers in the login expiration grace period connected to the peer by a remediation policy
+func (a *Account) getRemediationPeers(ctx context.Context, peerID string, validatedPeersMap map[string]struct{}, cache *NetworkMapCache) map[string]struct{} {
+	if a.Settings == nil || !a.Settings.PeerLoginExpirationGraceEnabled || !a.hasRemediationPolicies() {
+		return nil
+	}
+
+	aclPeers, _ := a.remediationAccount().getPeerConnectionResources(ctx, peerID, validatedPeersMap, cache)
+	peers := make(map[string]struct{})
+	for _, p := range aclPeers {
+		if a.peerInLoginExpirationGrace(p) {
+			peers[p.ID] = struct{}{}
+		}
+	}
+	return peers
+}
+
+func (a *Account) hasRemediationPolicies() bool {
+	for _, policy := range a.Policies {
+		if policy.Remediation && policy.Enabled {
+			return true
+		}
+	}
+	return false
+}
+
+// remediationResourcePolicies returns the remediation policies applied to the network resources
+func remediationResourcePolicies(resourcePolicies map[string][]*Policy) map[string][]*Policy {
+	policies := make(map[string][]*Policy, len(resourcePolicies))
+	for resourceID, resourceAppliedPolicies := range resourcePolicies {
+		for _, policy := range resourceAppliedPolicies {
+			if policy.Remediation {
+				policies[resourceID] = append(policies[resourceID], policy)
+			}
+		}
+	}
+	return policies
+}
@@ -0,0 +1,105 @@
+package types
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	nbdns "github.com/netbirdio/netbird/dns"
+	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+)
+
+func newRemediationTestAccount(graceEnabled bool) *Account {
+	lastLogin := time.Now().UTC().Add(-48 * time.Hour)
+	peers := map[string]*nbpeer.Peer{
+		"expired": {
+			ID: "expired", IP: net.IP{100, 64, 0, 1}, UserID: "user1", LoginExpirationEnabled: true, LastLogin: &lastLogin,
+			Status: &nbpeer.PeerStatus{Connected: true},
+		},
+		"idp":   {ID: "idp", IP: net.IP{100, 64, 0, 2}, Status: &nbpeer.PeerStatus{Connected: true}},
+		"other": {ID: "other", IP: net.IP{100, 64, 0, 3}, Status: &nbpeer.PeerStatus{Connected: true}},
+	}
+
+	newPolicy := func(id, source, destination string, remediation bool) *Policy {
+		return &Policy{
+			ID:          id,
+			Enabled:     true,
+			Remediation: remediation,
+			Rules: []*PolicyRule{{
+				ID:            id,
+				Enabled:       true,
+				Action:        PolicyTrafficActionAccept,
+				Protocol:      PolicyRuleProtocolALL,
+				Bidirectional: true,
+				Sources:       []string{source},
+				Destinations:  []string{destination},
+			}},
+		}
+	}
+
+	return &Account{
+		Id:      "account1",
+		Network: &Network{Net: net.IPNet{IP: net.IP{100, 64, 0, 0}, Mask: net.IPMask{255, 255, 0, 0}}},
+		Peers:   peers,
+		Groups: map[string]*Group{
+			"expired": {ID: "expired", Peers: []string{"expired"}},
+			"idp":     {ID: "idp", Peers: []string{"idp"}},
+			"other":   {ID: "other", Peers: []string{"other"}},
+		},
+		Policies: []*Policy{
+			newPolicy("remediation", "expired", "idp", true),
+			newPolicy("regular", "expired", "other", false),
+		},
+		Settings: &Settings{
+			PeerLoginExpirationEnabled:      true,
+			PeerLoginExpiration:             24 * time.Hour,
+			PeerLoginExpirationGraceEnabled: graceEnabled,
+		},
+	}
+}
+
+func getRemediationTestNetworkMap(account *Account, peerID string) *NetworkMap {
+	validatedPeers := make(map[string]struct{})
+	for id := range account.Peers {
+		validatedPeers[id] = struct{}{}
+	}
+	return account.GetPeerNetworkMap(context.Background(), peerID, nbdns.CustomZone{}, validatedPeers, account.GetResourcePoliciesMap(), account.GetResourceRoutersMap(), nil)
+}
+
+func networkMapPeerIDs(peers []*nbpeer.Peer) []string {
+	ids := make([]string, 0, len(peers))
+	for _, p := range peers {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
+
+func TestAccount_GetPeerNetworkMap_LoginExpirationGrace(t *testing.T) {
+	account := newRemediationTestAccount(true)
+
+	expiredMap := getRemediationTestNetworkMap(account, "expired")
+	assert.True(t, expiredMap.LoginExpired)
+	assert.ElementsMatch(t, []string{"idp"}, networkMapPeerIDs(expiredMap.Peers), "expired peer should only reach the remediation peers")
+
+	idpMap := getRemediationTestNetworkMap(account, "idp")
+	assert.False(t, idpMap.LoginExpired)
+	assert.ElementsMatch(t, []string{"expired"}, networkMapPeerIDs(idpMap.Peers), "remediation peer should keep the expired peer connected")
+
+	otherMap := getRemediationTestNetworkMap(account, "other")
+	assert.Empty(t, otherMap.Peers)
+	assert.ElementsMatch(t, []string{"expired"}, networkMapPeerIDs(otherMap.OfflinePeers))
+}
+
+func TestAccount_GetPeerNetworkMap_LoginExpirationGraceDisabled(t *testing.T) {
+	account := newRemediationTestAccount(false)
+
+	expiredMap := getRemediationTestNetworkMap(account, "expired")
+	assert.False(t, expiredMap.LoginExpired)
+
+	idpMap := getRemediationTestNetworkMap(account, "idp")
+	assert.Empty(t, idpMap.Peers)
+	assert.ElementsMatch(t, []string{"expired"}, networkMapPeerIDs(idpMap.OfflinePeers))
+}
@@ -0,0 +1,10 @@
+package types
+
+// ResourceRevision is the version of a resource of the management API. It is incremented in the transaction of every
+// write to the resource through the API, the ETag of the resource is derived from it.
+type ResourceRevision struct {
+	AccountID string `gorm:"primaryKey"`
+	// Resource is the API path of the resource, e.g. /api/groups/{groupId}
+	Resource string `gorm:"primaryKey"`
+	Revision uint64
+}
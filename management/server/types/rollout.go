@@ -0,0 +1,295 @@
+package types
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	connectivityTypes "github.com/netbirdio/netbird/management/server/connectivity/types"
+	"github.com/netbirdio/netbird/management/server/http/api"
+	"github.com/netbirdio/netbird/route"
+)
+
+// RolloutKind is the kind of the object changed by a rollout
+type RolloutKind string
+
+const (
+	RolloutKindRoute  RolloutKind = "route"
+	RolloutKindPolicy RolloutKind = "policy"
+)
+
+// RolloutStatus is the stage of a rollout
+type RolloutStatus string
+
+const (
+	// RolloutStatusCanary is a rollout applied to the canary peers and observed
+	RolloutStatusCanary RolloutStatus = "canary"
+	// RolloutStatusReady is a rollout observed for the whole observation period without failure, waiting for the
+	// promotion. It stays applied to the canary peers and observed.
+	RolloutStatusReady RolloutStatus = "ready"
+	// RolloutStatusPromoted is a rollout whose staged version was saved and applied to all peers
+	RolloutStatusPromoted RolloutStatus = "promoted"
+	// RolloutStatusRolledBack is a rollout removed from the canary peers, by a user or because the canary peers
+	// reported unhealthy routes
+	RolloutStatusRolledBack RolloutStatus = "rolled_back"
+	// RolloutStatusCancelled is a rollout whose route or policy was deleted before the promotion
+	RolloutStatusCancelled RolloutStatus = "cancelled"
+)
+
+const (
+	// DefaultRolloutObservationPeriod is the observation period of a rollout if none is set, the peers report their
+	// route health every 15 minutes
+	DefaultRolloutObservationPeriod = 30 * time.Minute
+	// MinRolloutObservationPeriod is the shortest observation period of a rollout
+	MinRolloutObservationPeriod = 5 * time.Minute
+	// MaxRolloutObservationPeriod is the longest observation period of a rollout
+	MaxRolloutObservationPeriod = 7 * 24 * time.Hour
+)
+
+// RolloutSettings are the canary groups and the promotion criteria of a rollout
+type RolloutSettings struct {
+	CanaryGroups      []string
+	ObservationPeriod time.Duration
+	FailureThreshold  float64
+	AutoPromote       bool
+}
+
+// RolloutSettingsFromAPIRequest returns the rollout settings of a route or policy rollout request
+func RolloutSettingsFromAPIRequest(canaryGroups []string, observationPeriod *int, failureThreshold *float64, autoPromote *bool) RolloutSettings {
+	settings := RolloutSettings{
+		CanaryGroups: canaryGroups,
+	}
+	if observationPeriod != nil {
+		settings.ObservationPeriod = time.Duration(*observationPeriod) * time.Second
+	}
+	if failureThreshold != nil {
+		settings.FailureThreshold = *failureThreshold
+	}
+	if autoPromote != nil {
+		settings.AutoPromote = *autoPromote
+	}
+	return settings
+}
+
+// Rollout stages a change of a route or a policy: the staged version applies to the network maps of the peers of the
+// canary groups first, the management service observes the route health they report and promotes the change to all
+// peers or rolls it back. The routing peers of a staged route are canary peers too.
+type Rollout struct {
+	ID        string `gorm:"primaryKey"`
+	AccountID string `gorm:"index"`
+	Kind      RolloutKind
+	// ObjectID is the ID of the route or the policy changed by the rollout
+	ObjectID string `gorm:"index"`
+	// Route is the staged version of the route of a route rollout
+	Route *route.Route `gorm:"serializer:json"`
+	// Policy is the staged version of the policy of a policy rollout
+	Policy *Policy `gorm:"serializer:json"`
+	// CanaryGroups are the groups whose peers receive the staged version first
+	CanaryGroups []string `gorm:"serializer:json"`
+	// ObservationPeriod is the time the canary peers are observed before the rollout is ready for promotion
+	ObservationPeriod time.Duration
+	// FailureThreshold is the share of the reporting canary peers with unhealthy routes above which the rollout is
+	// rolled back, zero rolls back on the first unhealthy canary peer
+	FailureThreshold float64
+	// AutoPromote promotes the rollout once the observation period passed without failure
+	AutoPromote bool
+	Status      RolloutStatus
+	// Reason describes why the rollout was rolled back or cancelled
+	Reason string
+	// ReportedPeers is the number of the canary peers that reported their route health since the rollout started
+	ReportedPeers int
+	// UnhealthyPeers is the number of the reporting canary peers with unhealthy routes
+	UnhealthyPeers int
+	CreatedBy      string
+	StartedAt      time.Time
+	FinishedAt     *time.Time
+}
+
+// TableName returns the table of the rollouts
+func (Rollout) TableName() string {
+	return "rollouts"
+}
+
+// ToAPIResponse returns the API representation of the rollout
+func (r *Rollout) ToAPIResponse() *api.Rollout {
+	resp := &api.Rollout{
+		Id:                r.ID,
+		Kind:              api.RolloutKind(r.Kind),
+		ObjectId:          r.ObjectID,
+		Status:            api.RolloutStatus(r.Status),
+		CanaryGroups:      r.CanaryGroups,
+		ObservationPeriod: int(r.ObservationPeriod.Seconds()),
+		FailureThreshold:  r.FailureThreshold,
+		AutoPromote:       r.AutoPromote,
+		ReportedPeers:     r.ReportedPeers,
+		UnhealthyPeers:    r.UnhealthyPeers,
+		CreatedBy:         r.CreatedBy,
+		StartedAt:         r.StartedAt,
+		FinishedAt:        r.FinishedAt,
+	}
+	if resp.CanaryGroups == nil {
+		resp.CanaryGroups = []string{}
+	}
+	if r.Reason != "" {
+		resp.Reason = &r.Reason
+	}
+	return resp
+}
+
+// IsActive checks if the staged version applies to the canary peers
+func (r *Rollout) IsActive() bool {
+	return r.Status == RolloutStatusCanary || r.Status == RolloutStatusReady
+}
+
+// Copy returns a copy of the rollout
+func (r *Rollout) Copy() *Rollout {
+	rollout := *r
+	if r.Route != nil {
+		rollout.Route = r.Route.Copy()
+	}
+	if r.Policy != nil {
+		rollout.Policy = r.Policy.Copy()
+	}
+	rollout.CanaryGroups = slices.Clone(r.CanaryGroups)
+	if r.FinishedAt != nil {
+		finishedAt := *r.FinishedAt
+		rollout.FinishedAt = &finishedAt
+	}
+	return &rollout
+}
+
+// Finish ends the rollout with the status
+func (r *Rollout) Finish(status RolloutStatus, reason string) {
+	now := time.Now().UTC()
+	r.Status = status
+	r.Reason = reason
+	r.FinishedAt = &now
+}
+
+// ObservationEnded checks if the canary peers were observed for the whole observation period
+func (r *Rollout) ObservationEnded() bool {
+	return time.Since(r.StartedAt) >= r.ObservationPeriod
+}
+
+// Evaluate counts the canary peers that reported their route health since the rollout started and the unhealthy
+// ones. A canary peer of a route rollout is unhealthy if the route is, the peers not using the route aren't counted.
+// A canary peer of a policy rollout is unhealthy if any of its routes is.
+func (r *Rollout) Evaluate(canaryPeers map[string]struct{}, reports []*connectivityTypes.Report) (reported, unhealthy int) {
+	for _, report := range reports {
+		if _, ok := canaryPeers[report.PeerID]; !ok || report.ReportedAt.Before(r.StartedAt) {
+			continue
+		}
+
+		used, healthy := false, true
+		for _, health := range report.Routes {
+			if r.Kind == RolloutKindRoute && !isRouteOf(health.RouteID, r.ObjectID) {
+				continue
+			}
+			used = true
+			healthy = healthy && health.Healthy
+		}
+		if r.Kind == RolloutKindRoute && !used {
+			continue
+		}
+
+		reported++
+		if !healthy {
+			unhealthy++
+		}
+	}
+	return reported, unhealthy
+}
+
+// Failed checks if the share of the unhealthy canary peers exceeds the failure threshold
+func (r *Rollout) Failed() bool {
+	if r.ReportedPeers == 0 {
+		return false
+	}
+	return float64(r.UnhealthyPeers)/float64(r.ReportedPeers) > r.FailureThreshold
+}
+
+// isRouteOf checks if the route of a network map is the route, the routes distributed to peer groups get the ID of
+// the routing peer appended
+func isRouteOf(networkMapRouteID, routeID string) bool {
+	return networkMapRouteID == routeID || strings.HasPrefix(networkMapRouteID, routeID+":")
+}
+
+// GetRolloutCanaryPeers returns the IDs of the canary peers of the rollout
+func (a *Account) GetRolloutCanaryPeers(rollout *Rollout) map[string]struct{} {
+	peers := make(map[string]struct{})
+	for peerID := range a.Peers {
+		if a.isRolloutCanary(rollout, peerID) {
+			peers[peerID] = struct{}{}
+		}
+	}
+	return peers
+}
+
+func (a *Account) isRolloutCanary(rollout *Rollout, peerID string) bool {
+	for _, groupID := range rollout.CanaryGroups {
+		if group := a.Groups[groupID]; group != nil && slices.Contains(group.Peers, peerID) {
+			return true
+		}
+	}
+
+	if rollout.Kind != RolloutKindRoute || rollout.Route == nil {
+		return false
+	}
+	if rollout.Route.Peer == peerID {
+		return true
+	}
+	for _, groupID := range rollout.Route.PeerGroups {
+		if group := a.Groups[groupID]; group != nil && slices.Contains(group.Peers, peerID) {
+			return true
+		}
+	}
+	return false
+}
+
+// canaryAccount returns a shallow copy of the account with the staged routes and policies of the active rollouts
+// the peer is a canary peer of. It returns false if the peer isn't a canary peer of any active rollout.
+func (a *Account) canaryAccount(peerID string) (*Account, bool) {
+	var routes map[route.ID]*route.Route
+	var policies []*Policy
+	for _, rollout := range a.Rollouts {
+		if !rollout.IsActive() || !a.isRolloutCanary(rollout, peerID) {
+			continue
+		}
+
+		switch rollout.Kind {
+		case RolloutKindRoute:
+			routeID := route.ID(rollout.ObjectID)
+			if _, ok := a.Routes[routeID]; !ok || rollout.Route == nil {
+				continue
+			}
+			if routes == nil {
+				routes = maps.Clone(a.Routes)
+			}
+			routes[routeID] = rollout.Route
+		case RolloutKindPolicy:
+			i := slices.IndexFunc(a.Policies, func(p *Policy) bool { return p.ID == rollout.ObjectID })
+			if i < 0 || rollout.Policy == nil {
+				continue
+			}
+			if policies == nil {
+				policies = slices.Clone(a.Policies)
+			}
+			policies[i] = rollout.Policy
+		}
+	}
+
+	if routes == nil && policies == nil {
+		return nil, false
+	}
+
+	account := *a
+	account.Rollouts = nil
+	if routes != nil {
+		account.Routes = routes
+	}
+	if policies != nil {
+		account.Policies = policies
+	}
+	return &account, true
+}
@@ -0,0 +1,144 @@
+package types
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	connectivityTypes "github.com/netbirdio/netbird/management/server/connectivity/types"
+	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+	"github.com/netbirdio/netbird/route"
+)
+
+func newRolloutTestAccount() *Account {
+	return &Account{
+		Id: "accountID",
+		Peers: map[string]*nbpeer.Peer{
+			"canary":  {ID: "canary"},
+			"other":   {ID: "other"},
+			"router1": {ID: "router1"},
+			"router2": {ID: "router2"},
+		},
+		Groups: map[string]*Group{
+			"canaries": {ID: "canaries", Peers: []string{"canary"}},
+			"others":   {ID: "others", Peers: []string{"other"}},
+			"routers":  {ID: "routers", Peers: []string{"router2"}},
+		},
+		Routes: map[route.ID]*route.Route{
+			"route": {
+				ID:      "route",
+				NetID:   "net",
+				Network: netip.MustParsePrefix("10.0.0.0/8"),
+				Peer:    "router1",
+				Groups:  []string{"canaries", "others"},
+				Enabled: true,
+			},
+		},
+		Policies: []*Policy{
+			{ID: "policy", Enabled: true},
+		},
+	}
+}
+
+func TestAccount_canaryAccount(t *testing.T) {
+	account := newRolloutTestAccount()
+
+	staged := account.Routes["route"].Copy()
+	staged.Peer = ""
+	staged.PeerGroups = []string{"routers"}
+
+	account.Rollouts = []*Rollout{
+		{
+			ID:           "routeRollout",
+			Kind:         RolloutKindRoute,
+			ObjectID:     "route",
+			Route:        staged,
+			CanaryGroups: []string{"canaries"},
+			Status:       RolloutStatusCanary,
+		},
+		{
+			ID:           "policyRollout",
+			Kind:         RolloutKindPolicy,
+			ObjectID:     "policy",
+			Policy:       &Policy{ID: "policy", Enabled: false},
+			CanaryGroups: []string{"others"},
+			Status:       RolloutStatusRolledBack,
+		},
+	}
+
+	assert.Equal(t, map[string]struct{}{"canary": {}, "router2": {}}, account.GetRolloutCanaryPeers(account.Rollouts[0]),
+		"the routing peers of the staged route should be canary peers")
+
+	canary, ok := account.canaryAccount("canary")
+	require.True(t, ok)
+	assert.Equal(t, staged, canary.Routes["route"])
+	assert.Empty(t, canary.Rollouts, "the canary account shouldn't apply the rollouts again")
+	assert.Equal(t, "router1", account.Routes["route"].Peer, "the account shouldn't be changed")
+
+	_, ok = account.canaryAccount("router2")
+	assert.True(t, ok)
+
+	_, ok = account.canaryAccount("other")
+	assert.False(t, ok, "finished rollouts shouldn't apply")
+
+	_, ok = account.canaryAccount("router1")
+	assert.False(t, ok, "the routing peers of the current route aren't canary peers")
+
+	account.Rollouts[1].Status = RolloutStatusReady
+	canary, ok = account.canaryAccount("other")
+	require.True(t, ok)
+	assert.False(t, canary.Policies[0].Enabled)
+	assert.True(t, account.Policies[0].Enabled, "the account shouldn't be changed")
+
+	delete(account.Routes, "route")
+	_, ok = account.canaryAccount("canary")
+	assert.False(t, ok, "the staged version of a deleted route shouldn't apply")
+}
+
+func TestRollout_Evaluate(t *testing.T) {
+	startedAt := time.Now().UTC()
+	canaryPeers := map[string]struct{}{"peer1": {}, "peer2": {}, "peer3": {}, "peer4": {}}
+	reports := []*connectivityTypes.Report{
+		{PeerID: "peer1", ReportedAt: startedAt.Add(time.Minute), Routes: []connectivityTypes.RouteHealth{
+			{RouteID: "route:router1", Healthy: true},
+			{RouteID: "other", Healthy: false},
+		}},
+		{PeerID: "peer2", ReportedAt: startedAt.Add(time.Minute), Routes: []connectivityTypes.RouteHealth{
+			{RouteID: "route", Healthy: false},
+		}},
+		{PeerID: "peer3", ReportedAt: startedAt.Add(-time.Minute), Routes: []connectivityTypes.RouteHealth{
+			{RouteID: "route", Healthy: false},
+		}},
+		{PeerID: "peer4", ReportedAt: startedAt.Add(time.Minute)},
+		{PeerID: "other", ReportedAt: startedAt.Add(time.Minute), Routes: []connectivityTypes.RouteHealth{
+			{RouteID: "route", Healthy: false},
+		}},
+	}
+
+	rollout := &Rollout{Kind: RolloutKindRoute, ObjectID: "route", StartedAt: startedAt}
+	reported, unhealthy := rollout.Evaluate(canaryPeers, reports)
+	assert.Equal(t, 2, reported, "only the canary peers using the route since the start should count")
+	assert.Equal(t, 1, unhealthy)
+
+	rollout = &Rollout{Kind: RolloutKindPolicy, ObjectID: "policy", StartedAt: startedAt}
+	reported, unhealthy = rollout.Evaluate(canaryPeers, reports)
+	assert.Equal(t, 3, reported)
+	assert.Equal(t, 2, unhealthy, "any unhealthy route should count for a policy")
+}
+
+func TestRollout_Failed(t *testing.T) {
+	rollout := &Rollout{}
+	assert.False(t, rollout.Failed(), "a rollout without reports shouldn't fail")
+
+	rollout.ReportedPeers, rollout.UnhealthyPeers = 10, 1
+	assert.True(t, rollout.Failed(), "any unhealthy peer should fail the rollout without a threshold")
+
+	rollout.FailureThreshold = 0.1
+	assert.False(t, rollout.Failed())
+
+	rollout.UnhealthyPeers = 2
+	assert.True(t, rollout.Failed())
+}
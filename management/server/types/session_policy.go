@@ -0,0 +1,211 @@
+package types
+
+import (
+	"fmt"
+	"time"
+
+	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+)
+
+const (
+	minPeerLoginExpiration      = time.Hour
+	maxPeerLoginExpiration      = 180 * 24 * time.Hour
+	minPeerInactivityExpiration = time.Minute
+)
+
+// SessionPolicy overrides the login and inactivity expiration settings of the account for the peers of a group.
+// When a peer is part of several groups with a policy, the strictest settings apply.
+type SessionPolicy struct {
+	// LoginExpirationEnabled enables or disables the login expiration of the peers, nil keeps the account setting
+	LoginExpirationEnabled *bool
+	// LoginExpiration is the duration after which the login of the peers expires, 0 keeps the account setting
+	LoginExpiration time.Duration
+	// InactivityExpirationEnabled enables or disables the inactivity expiration of the peers, nil keeps the account setting
+	InactivityExpirationEnabled *bool
+	// InactivityExpiration is the duration of inactivity after which the session of the peers expires, 0 keeps the account setting
+	InactivityExpiration time.Duration
+	// RequireReauth applies the expiration to the peers even if it was disabled on the peer itself
+	RequireReauth bool
+}
+
+// Copy returns a copy of the session policy
+func (p *SessionPolicy) Copy() *SessionPolicy {
+	if p == nil {
+		return nil
+	}
+	policy := *p
+	if p.LoginExpirationEnabled != nil {
+		enabled := *p.LoginExpirationEnabled
+		policy.LoginExpirationEnabled = &enabled
+	}
+	if p.InactivityExpirationEnabled != nil {
+		enabled := *p.InactivityExpirationEnabled
+		policy.InactivityExpirationEnabled = &enabled
+	}
+	return &policy
+}
+
+// Validate checks that the durations of the session policy are within the allowed limits
+func (p *SessionPolicy) Validate() error {
+	if p.LoginExpiration != 0 && (p.LoginExpiration < minPeerLoginExpiration || p.LoginExpiration > maxPeerLoginExpiration) {
+		return fmt.Errorf("login expiration must be between %s and %s", minPeerLoginExpiration, maxPeerLoginExpiration)
+	}
+	if p.InactivityExpiration != 0 && p.InactivityExpiration < minPeerInactivityExpiration {
+		return fmt.Errorf("inactivity expiration can't be smaller than %s", minPeerInactivityExpiration)
+	}
+	return nil
+}
+
+// SessionSettings are the expiration settings applying to a peer, resolved from the account settings and the session
+// policies of the groups of the peer
+type SessionSettings struct {
+	LoginExpirationEnabled      bool
+	LoginExpiration             time.Duration
+	InactivityExpirationEnabled bool
+	InactivityExpiration        time.Duration
+	RequireReauth               bool
+}
+
+// NewSessionSettings resolves the session settings of a peer member of the groups.
+// The policies enabling or disabling an expiration override the account setting, the expiration being enabled if any
+// of them enables it, and the shortest duration applies.
+func NewSessionSettings(settings *Settings, groups []*Group) SessionSettings {
+	s := SessionSettings{
+		LoginExpirationEnabled:      settings.PeerLoginExpirationEnabled,
+		LoginExpiration:             settings.PeerLoginExpiration,
+		InactivityExpirationEnabled: settings.PeerInactivityExpirationEnabled,
+		InactivityExpiration:        settings.PeerInactivityExpiration,
+	}
+
+	var loginEnabled, inactivityEnabled *bool
+	var loginExpiration, inactivityExpiration time.Duration
+	for _, group := range groups {
+		policy := group.SessionPolicy
+		if policy == nil {
+			continue
+		}
+
+		if policy.LoginExpirationEnabled != nil {
+			loginEnabled = orPtr(loginEnabled, *policy.LoginExpirationEnabled)
+		}
+		if policy.InactivityExpirationEnabled != nil {
+			inactivityEnabled = orPtr(inactivityEnabled, *policy.InactivityExpirationEnabled)
+		}
+		loginExpiration = minDuration(loginExpiration, policy.LoginExpiration)
+		inactivityExpiration = minDuration(inactivityExpiration, policy.InactivityExpiration)
+		s.RequireReauth = s.RequireReauth || policy.RequireReauth
+	}
+
+	if loginEnabled != nil {
+		s.LoginExpirationEnabled = *loginEnabled
+	}
+	if inactivityEnabled != nil {
+		s.InactivityExpirationEnabled = *inactivityEnabled
+	}
+	if loginExpiration != 0 {
+		s.LoginExpiration = loginExpiration
+	}
+	if inactivityExpiration != 0 {
+		s.InactivityExpiration = inactivityExpiration
+	}
+
+	return s
+}
+
+func orPtr(current *bool, value bool) *bool {
+	if current != nil {
+		value = value || *current
+	}
+	return &value
+}
+
+// minDuration returns the smallest of the non-zero durations, 0 if both are 0
+func minDuration(a, b time.Duration) time.Duration {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+// expirable checks if the expiration can apply to the peer: only peers added by interactive SSO login expire,
+// and only if the expiration is enabled on the peer or required by a policy
+func (s SessionSettings) expirable(peer *nbpeer.Peer, peerEnabled bool) bool {
+	return peer.AddedWithSSOLogin() && (peerEnabled || s.RequireReauth)
+}
+
+// LoginExpirationApplies checks if the login of the peer can expire
+func (s SessionSettings) LoginExpirationApplies(peer *nbpeer.Peer) bool {
+	return s.LoginExpirationEnabled && s.expirable(peer, peer.LoginExpirationEnabled)
+}
+
+// InactivityExpirationApplies checks if the session of the peer can expire by inactivity
+func (s SessionSettings) InactivityExpirationApplies(peer *nbpeer.Peer) bool {
+	return s.InactivityExpirationEnabled && s.expirable(peer, peer.InactivityExpirationEnabled)
+}
+
+// LoginExpired indicates whether the login of the peer has expired, and the time left to expiration (negative when expired).
+func (s SessionSettings) LoginExpired(peer *nbpeer.Peer) (bool, time.Duration) {
+	if !s.LoginExpirationApplies(peer) {
+		return false, 0
+	}
+	timeLeft := time.Until(peer.GetLastLogin().Add(s.LoginExpiration))
+	return timeLeft <= 0, timeLeft
+}
+
+// SessionExpired indicates whether the session of the disconnected peer has expired by inactivity, and the time left to expiration.
+func (s SessionSettings) SessionExpired(peer *nbpeer.Peer) (bool, time.Duration) {
+	if !s.InactivityExpirationApplies(peer) || peer.Status.Connected {
+		return false, 0
+	}
+	timeLeft := time.Until(peer.Status.LastSeen.Add(s.InactivityExpiration))
+	return timeLeft <= 0, timeLeft
+}
+
+// LoginExpiresAt returns the time the login of the peer expires at, zero if it doesn't expire
+func (s SessionSettings) LoginExpiresAt(peer *nbpeer.Peer) time.Time {
+	if !s.LoginExpirationApplies(peer) {
+		return time.Time{}
+	}
+	return peer.GetLastLogin().Add(s.LoginExpiration)
+}
+
+// InactivityExpiresAt returns the time the session of the disconnected peer expires at by inactivity, zero if it doesn't expire
+func (s SessionSettings) InactivityExpiresAt(peer *nbpeer.Peer) time.Time {
+	if !s.InactivityExpirationApplies(peer) || peer.Status.Connected {
+		return time.Time{}
+	}
+	return peer.Status.LastSeen.Add(s.InactivityExpiration)
+}
+
+// NewSessionSettingsResolver returns a function resolving the session settings of the peers member of the groups.
+// The nested groups have to be flattened for their peers to get the policies of the groups containing them.
+func NewSessionSettingsResolver(settings *Settings, groups map[string]*Group) func(peerID string) SessionSettings {
+	peerGroups := make(map[string][]*Group)
+	for _, group := range groups {
+		if group.SessionPolicy == nil {
+			continue
+		}
+		for _, peerID := range group.Peers {
+			peerGroups[peerID] = append(peerGroups[peerID], group)
+		}
+	}
+
+	defaults := NewSessionSettings(settings, nil)
+	return func(peerID string) SessionSettings {
+		groups, ok := peerGroups[peerID]
+		if !ok {
+			return defaults
+		}
+		return NewSessionSettings(settings, groups)
+	}
+}
+
+// HasSessionPolicies checks if any of the groups has a session policy
+func HasSessionPolicies(groups []*Group) bool {
+	for _, group := range groups {
+		if group.SessionPolicy != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+)
+
+func TestSessionPolicy_Validate(t *testing.T) {
+	assert.NoError(t, (&SessionPolicy{}).Validate())
+	assert.NoError(t, (&SessionPolicy{LoginExpiration: 24 * time.Hour, InactivityExpiration: 10 * time.Minute}).Validate())
+	assert.Error(t, (&SessionPolicy{LoginExpiration: time.Minute}).Validate())
+	assert.Error(t, (&SessionPolicy{LoginExpiration: 200 * 24 * time.Hour}).Validate())
+	assert.Error(t, (&SessionPolicy{InactivityExpiration: time.Second}).Validate())
+}
+
+func TestNewSessionSettings(t *testing.T) {
+	enabled, disabled := true, false
+	settings := &Settings{
+		PeerLoginExpirationEnabled: false,
+		PeerLoginExpiration:        24 * time.Hour,
+		PeerInactivityExpiration:   time.Hour,
+	}
+
+	tests := []struct {
+		name     string
+		groups   []*Group
+		expected SessionSettings
+	}{
+		{
+			name:     "account settings",
+			groups:   []*Group{{ID: "group1"}},
+			expected: SessionSettings{LoginExpiration: 24 * time.Hour, InactivityExpiration: time.Hour},
+		},
+		{
+			name: "policy overriding the account settings",
+			groups: []*Group{{ID: "group1", SessionPolicy: &SessionPolicy{
+				LoginExpirationEnabled: &enabled,
+				LoginExpiration:        8 * time.Hour,
+				RequireReauth:          true,
+			}}},
+			expected: SessionSettings{LoginExpirationEnabled: true, LoginExpiration: 8 * time.Hour, InactivityExpiration: time.Hour, RequireReauth: true},
+		},
+		{
+			name: "strictest policies",
+			groups: []*Group{
+				{ID: "group1", SessionPolicy: &SessionPolicy{LoginExpirationEnabled: &disabled, LoginExpiration: 2 * time.Hour}},
+				{ID: "group2", SessionPolicy: &SessionPolicy{LoginExpirationEnabled: &enabled, LoginExpiration: 12 * time.Hour}},
+				{ID: "group3", SessionPolicy: &SessionPolicy{InactivityExpirationEnabled: &enabled}},
+			},
+			expected: SessionSettings{LoginExpirationEnabled: true, LoginExpiration: 2 * time.Hour, InactivityExpirationEnabled: true, InactivityExpiration: time.Hour},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NewSessionSettings(settings, tt.groups))
+		})
+	}
+}
+
+func TestSessionSettings_LoginExpired(t *testing.T) {
+	lastLogin := time.Now().UTC().Add(-2 * time.Hour)
+	ssoPeer := &nbpeer.Peer{ID: "peer1", UserID: "user1", LastLogin: &lastLogin}
+	keyPeer := &nbpeer.Peer{ID: "peer2", LoginExpirationEnabled: true, LastLogin: &lastLogin}
+
+	settings := SessionSettings{LoginExpirationEnabled: true, LoginExpiration: time.Hour}
+
+	expired, _ := settings.LoginExpired(ssoPeer)
+	assert.False(t, expired, "expiration disabled on the peer shouldn't apply")
+	assert.True(t, settings.LoginExpiresAt(ssoPeer).IsZero())
+
+	settings.RequireReauth = true
+	expired, _ = settings.LoginExpired(ssoPeer)
+	assert.True(t, expired, "expiration required by the policy should apply")
+	assert.Equal(t, lastLogin.Add(time.Hour), settings.LoginExpiresAt(ssoPeer))
+
+	expired, _ = settings.LoginExpired(keyPeer)
+	assert.False(t, expired, "peers added with a setup key shouldn't expire")
+}
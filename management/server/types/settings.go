@@ -1,6 +1,7 @@
 package types
 
 import (
+	"slices"
 	"time"
 )
 
@@ -20,6 +21,14 @@ type Settings struct {
 	// Applies to all peers that have Peer.PeerInactivityExpirationEnabled set to true.
 	PeerInactivityExpiration time.Duration
 
+	// PeerLoginExpirationNotification is the period before the login of a peer expires at which its user is notified.
+	// No notification is sent when 0.
+	PeerLoginExpirationNotification time.Duration
+
+	// PeerLoginExpirationGraceEnabled keeps the expired peers connected with access limited to the remediation policies
+	// until their user logs in again, instead of disconnecting them
+	PeerLoginExpirationGraceEnabled bool
+
 	// RegularUsersViewBlocked allows to block regular users from viewing even their own peers and some UI elements
 	RegularUsersViewBlocked bool
 
@@ -36,9 +45,29 @@ type Settings struct {
 	// JWTAllowGroups list of groups to which users are allowed access
 	JWTAllowGroups []string `gorm:"serializer:json"`
 
+	// JWTGroupsMappingRules list of rules mapping the groups of JWT claims onto account groups.
+	// When set, the rules take precedence over JWTGroupsClaimName.
+	JWTGroupsMappingRules []*JWTGroupsMappingRule `gorm:"serializer:json"`
+
 	// RoutingPeerDNSResolutionEnabled enabled the DNS resolution on the routing peers
 	RoutingPeerDNSResolutionEnabled bool
 
+	// RoutingPeerForwardedTrafficFilteringEnabled makes the routing peers filter the traffic they forward to the
+	// networks of the routes without access control groups with the policies of the traffic to the routing peers,
+	// instead of accepting all of it
+	RoutingPeerForwardedTrafficFilteringEnabled bool
+
+	// ConnectionTuning overrides the timers of the peer connections, nil if the client defaults apply.
+	// The connection tuning of the groups takes precedence.
+	ConnectionTuning *ConnectionTuning `gorm:"serializer:json"`
+
+	// ClientSettings are the defaults of the client settings distributed to the peers, nil if the peers keep the
+	// settings they were installed with. The settings set locally on a client take precedence.
+	ClientSettings *ClientSettings `gorm:"serializer:json"`
+
+	// DisabledNotifications are the categories of the notifications the clients don't show to their users
+	DisabledNotifications []string `gorm:"serializer:json"`
+
 	// Extra is a dictionary of Account settings
 	Extra *ExtraSettings `gorm:"embedded;embeddedPrefix:extra_"`
 }
@@ -57,7 +86,19 @@ func (s *Settings) Copy() *Settings {
 		PeerInactivityExpirationEnabled: s.PeerInactivityExpirationEnabled,
 		PeerInactivityExpiration:        s.PeerInactivityExpiration,
 
-		RoutingPeerDNSResolutionEnabled: s.RoutingPeerDNSResolutionEnabled,
+		PeerLoginExpirationNotification: s.PeerLoginExpirationNotification,
+		PeerLoginExpirationGraceEnabled: s.PeerLoginExpirationGraceEnabled,
+
+		RoutingPeerDNSResolutionEnabled:             s.RoutingPeerDNSResolutionEnabled,
+		RoutingPeerForwardedTrafficFilteringEnabled: s.RoutingPeerForwardedTrafficFilteringEnabled,
+
+		ConnectionTuning: s.ConnectionTuning.Copy(),
+		ClientSettings:   s.ClientSettings.Copy(),
+
+		DisabledNotifications: slices.Clone(s.DisabledNotifications),
+	}
+	for _, rule := range s.JWTGroupsMappingRules {
+		settings.JWTGroupsMappingRules = append(settings.JWTGroupsMappingRules, rule.Copy())
 	}
 	if s.Extra != nil {
 		settings.Extra = s.Extra.Copy()
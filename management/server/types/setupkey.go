@@ -3,7 +3,11 @@ package types
 import (
 	"crypto/sha256"
 	b64 "encoding/base64"
+	"fmt"
 	"hash/fnv"
+	"net"
+	"net/netip"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -25,6 +29,8 @@ const (
 	DefaultSetupKeyName = "Default key"
 	// SetupKeyUnlimitedUsage indicates an unlimited usage of a setup key
 	SetupKeyUnlimitedUsage = 0
+
+	maxPeerNamePrefixLength = 32
 )
 
 // SetupKeyType is the type of setup key
@@ -57,6 +63,74 @@ type SetupKey struct {
 	Ephemeral bool
 	// AllowExtraDNSLabels indicates if the key allows extra DNS labels
 	AllowExtraDNSLabels bool
+	// SetupKeyProvisioning holds the restrictions and resources applied to the peers registered with this key
+	SetupKeyProvisioning `gorm:"embedded"`
+}
+
+// SetupKeyProvisioning defines how peers registering with a setup key are restricted and provisioned
+type SetupKeyProvisioning struct {
+	// AllowedIPRanges is a list of CIDRs the peers are allowed to register from. An empty list allows any source IP.
+	AllowedIPRanges []string `gorm:"serializer:json"`
+	// AutoRoutes is a list of Route IDs the registered peers are added to as routing peers
+	AutoRoutes []string `gorm:"serializer:json"`
+	// AutoPostureChecks is a list of Posture Check IDs the peers have to pass to register with this key
+	AutoPostureChecks []string `gorm:"serializer:json"`
+	// PeerNamePrefix is prepended to the name of the registered peers
+	PeerNamePrefix string
+}
+
+// Copy copies SetupKeyProvisioning to a new object
+func (p SetupKeyProvisioning) Copy() SetupKeyProvisioning {
+	return SetupKeyProvisioning{
+		AllowedIPRanges:   slices.Clone(p.AllowedIPRanges),
+		AutoRoutes:        slices.Clone(p.AutoRoutes),
+		AutoPostureChecks: slices.Clone(p.AutoPostureChecks),
+		PeerNamePrefix:    p.PeerNamePrefix,
+	}
+}
+
+// Validate checks the IP ranges and the peer name prefix of the provisioning settings
+func (p SetupKeyProvisioning) Validate() error {
+	for _, ipRange := range p.AllowedIPRanges {
+		if _, err := netip.ParsePrefix(ipRange); err != nil {
+			return fmt.Errorf("invalid allowed IP range %s: %w", ipRange, err)
+		}
+	}
+
+	if len(p.PeerNamePrefix) > maxPeerNamePrefixLength {
+		return fmt.Errorf("peer name prefix can't be longer than %d characters", maxPeerNamePrefixLength)
+	}
+
+	if strings.TrimSpace(p.PeerNamePrefix) != p.PeerNamePrefix {
+		return fmt.Errorf("peer name prefix can't start or end with whitespaces")
+	}
+
+	return nil
+}
+
+// IsIPAllowed checks if peers are allowed to register from the given IP
+func (p SetupKeyProvisioning) IsIPAllowed(ip net.IP) bool {
+	if len(p.AllowedIPRanges) == 0 {
+		return true
+	}
+
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, ipRange := range p.AllowedIPRanges {
+		prefix, err := netip.ParsePrefix(ipRange)
+		if err != nil {
+			continue
+		}
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
 }
 
 // Copy copies SetupKey to a new object
@@ -67,22 +141,23 @@ func (key *SetupKey) Copy() *SetupKey {
 		key.UpdatedAt = key.CreatedAt
 	}
 	return &SetupKey{
-		Id:                  key.Id,
-		AccountID:           key.AccountID,
-		Key:                 key.Key,
-		KeySecret:           key.KeySecret,
-		Name:                key.Name,
-		Type:                key.Type,
-		CreatedAt:           key.CreatedAt,
-		ExpiresAt:           key.ExpiresAt,
-		UpdatedAt:           key.UpdatedAt,
-		Revoked:             key.Revoked,
-		UsedTimes:           key.UsedTimes,
-		LastUsed:            key.LastUsed,
-		AutoGroups:          autoGroups,
-		UsageLimit:          key.UsageLimit,
-		Ephemeral:           key.Ephemeral,
-		AllowExtraDNSLabels: key.AllowExtraDNSLabels,
+		Id:                   key.Id,
+		AccountID:            key.AccountID,
+		Key:                  key.Key,
+		KeySecret:            key.KeySecret,
+		Name:                 key.Name,
+		Type:                 key.Type,
+		CreatedAt:            key.CreatedAt,
+		ExpiresAt:            key.ExpiresAt,
+		UpdatedAt:            key.UpdatedAt,
+		Revoked:              key.Revoked,
+		UsedTimes:            key.UsedTimes,
+		LastUsed:             key.LastUsed,
+		AutoGroups:           autoGroups,
+		UsageLimit:           key.UsageLimit,
+		Ephemeral:            key.Ephemeral,
+		AllowExtraDNSLabels:  key.AllowExtraDNSLabels,
+		SetupKeyProvisioning: key.SetupKeyProvisioning.Copy(),
 	}
 }
 
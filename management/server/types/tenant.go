@@ -0,0 +1,38 @@
+package types
+
+import (
+	"time"
+)
+
+// Tenant links a child account to the parent account administering it.
+// Admins of the parent account have admin access to the tenant account, e.g. an MSP to its customers' networks.
+type Tenant struct {
+	// AccountID is the ID of the tenant account
+	AccountID string `gorm:"primaryKey"`
+
+	// ParentAccountID is the ID of the account administering the tenant
+	ParentAccountID string `gorm:"index"`
+
+	Name      string
+	CreatedBy string
+	CreatedAt time.Time
+}
+
+// Copy returns a copy of the tenant
+func (t *Tenant) Copy() *Tenant {
+	tenant := *t
+	return &tenant
+}
+
+func (t *Tenant) EventMeta() map[string]any {
+	return map[string]any{"name": t.Name, "tenant_account_id": t.AccountID}
+}
+
+// TenantUsage is the usage of a tenant account rolled up to its parent account for billing
+type TenantUsage struct {
+	AccountID      string
+	Name           string
+	Peers          int
+	ConnectedPeers int
+	Users          int
+}
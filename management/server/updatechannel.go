@@ -176,3 +176,33 @@ func (p *PeersUpdateManager) HasChannel(peerID string) bool {
 
 	return ok
 }
+
+// SendProbeRequest sends the probe request to the peer, false is returned if the peer isn't connected
+func (p *PeersUpdateManager) SendProbeRequest(ctx context.Context, peerID string, request *proto.ProbeRequest) bool {
+	if !p.HasChannel(peerID) {
+		return false
+	}
+
+	p.SendUpdate(ctx, peerID, &UpdateMessage{Update: &proto.SyncResponse{ProbeRequest: request}})
+	return true
+}
+
+// SendDebugBundleRequest sends the debug bundle request to the peer, false is returned if the peer isn't connected
+func (p *PeersUpdateManager) SendDebugBundleRequest(ctx context.Context, peerID string, request *proto.DebugBundleRequest) bool {
+	if !p.HasChannel(peerID) {
+		return false
+	}
+
+	p.SendUpdate(ctx, peerID, &UpdateMessage{Update: &proto.SyncResponse{DebugBundleRequest: request}})
+	return true
+}
+
+// SendPeerAction sends the action to the peer, false is returned if the peer isn't connected
+func (p *PeersUpdateManager) SendPeerAction(ctx context.Context, peerID string, action *proto.PeerAction) bool {
+	if !p.HasChannel(peerID) {
+		return false
+	}
+
+	p.SendUpdate(ctx, peerID, &UpdateMessage{Update: &proto.SyncResponse{PeerAction: action}})
+	return true
+}
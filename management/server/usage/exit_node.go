@@ -0,0 +1,185 @@
+package usage
+
+import (
+	"context"
+	"net/netip"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/management/proto"
+	"github.com/netbirdio/netbird/management/server/account"
+	"github.com/netbirdio/netbird/management/server/activity"
+	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+	"github.com/netbirdio/netbird/management/server/store"
+	nbtypes "github.com/netbirdio/netbird/management/server/types"
+	"github.com/netbirdio/netbird/management/server/usage/types"
+)
+
+// ExitNodeRecorder records the traffic the exit nodes forward for the peers and enforces the exit node quotas of the
+// groups. The peers exceeding their quota are flagged and lose the exit routes until the quotas reset at the start of
+// the next month (UTC).
+type ExitNodeRecorder struct {
+	store          store.Store
+	accountManager account.Manager
+}
+
+// NewExitNodeRecorder creates an ExitNodeRecorder, Start resets the quotas every month
+func NewExitNodeRecorder(store store.Store, accountManager account.Manager) *ExitNodeRecorder {
+	return &ExitNodeRecorder{
+		store:          store,
+		accountManager: accountManager,
+	}
+}
+
+// RecordForwardedTraffic adds the traffic reported by the peer to the exit node usage and enforces the quotas of the
+// account. The traffic is dropped if the peer doesn't serve an exit route, the quotas are enforced on every report so
+// the changes of the quotas apply without traffic too.
+func (r *ExitNodeRecorder) RecordForwardedTraffic(ctx context.Context, exitNode *nbpeer.Peer, traffic []*proto.ForwardedTraffic) error {
+	acc, err := r.store.GetAccount(ctx, exitNode.AccountID)
+	if err != nil {
+		return err
+	}
+	nbtypes.FlattenNestedGroups(acc.Groups)
+
+	if !acc.IsExitNode(ctx, exitNode.ID) {
+		return nil
+	}
+
+	peersByIP := make(map[netip.Addr]*nbpeer.Peer, len(acc.Peers))
+	for _, peer := range acc.Peers {
+		if addr, ok := netip.AddrFromSlice(peer.IP); ok {
+			peersByIP[addr.Unmap()] = peer
+		}
+	}
+
+	now := time.Now().UTC()
+	month := types.Month(now)
+	for _, t := range traffic {
+		addr, err := netip.ParseAddr(t.GetPeerIp())
+		if err != nil {
+			log.WithContext(ctx).Debugf("exit node %s reported traffic of the invalid address %q", exitNode.ID, t.GetPeerIp())
+			continue
+		}
+		peer, ok := peersByIP[addr.Unmap()]
+		if !ok || peer.ID == exitNode.ID {
+			continue
+		}
+
+		err = r.store.AddExitNodeUsage(ctx, &types.ExitNodeUsage{
+			Month:      month,
+			ExitNodeID: exitNode.ID,
+			PeerID:     peer.ID,
+			AccountID:  acc.Id,
+			UserID:     peer.UserID,
+			RxBytes:    int64(t.GetRxBytes()),
+			TxBytes:    int64(t.GetTxBytes()),
+			UpdatedAt:  now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return r.enforceQuotas(ctx, acc, month)
+}
+
+// enforceQuotas flags the peers of the account whose traffic in the month reached their quota and clears the flag of
+// the others, the peers are updated if a flag changed
+func (r *ExitNodeRecorder) enforceQuotas(ctx context.Context, acc *nbtypes.Account, month string) error {
+	quotas := acc.GetExitNodeQuotas()
+
+	var used map[string]int64
+	if len(quotas) > 0 {
+		usage, err := r.store.GetAccountExitNodeUsage(ctx, store.LockingStrengthShare, acc.Id, month)
+		if err != nil {
+			return err
+		}
+		used = usedBytes(acc.Peers, usage)
+	}
+
+	var exceeded, lifted []string
+	for _, peer := range acc.Peers {
+		quota := quotas[peer.ID]
+		isExceeded := quota > 0 && used[consumer(peer.UserID, peer.ID)] >= quota
+		switch {
+		case isExceeded && !peer.ExitNodeQuotaExceeded:
+			exceeded = append(exceeded, peer.ID)
+		case !isExceeded && peer.ExitNodeQuotaExceeded:
+			lifted = append(lifted, peer.ID)
+		}
+	}
+	if len(exceeded) == 0 && len(lifted) == 0 {
+		return nil
+	}
+
+	if err := r.store.SetPeersExitNodeQuotaExceeded(ctx, store.LockingStrengthUpdate, acc.Id, exceeded, true); err != nil {
+		return err
+	}
+	if err := r.store.SetPeersExitNodeQuotaExceeded(ctx, store.LockingStrengthUpdate, acc.Id, lifted, false); err != nil {
+		return err
+	}
+
+	for _, peerID := range exceeded {
+		peer := acc.Peers[peerID]
+		meta := map[string]any{"name": peer.Name, "ip": peer.IP.String(), "quota_bytes": quotas[peerID]}
+		r.accountManager.StoreEvent(ctx, activity.SystemInitiator, peerID, acc.Id, activity.PeerExitNodeQuotaExceeded, meta)
+	}
+
+	r.accountManager.UpdateAccountPeers(ctx, acc.Id)
+
+	return nil
+}
+
+// consumer returns the key the traffic of a peer adds up under, the user of the peer or the peer itself if it was
+// registered with a setup key
+func consumer(userID, peerID string) string {
+	if userID != "" {
+		return "user/" + userID
+	}
+	return "peer/" + peerID
+}
+
+// usedBytes sums the exit node usage per consumer, the traffic of a peer counts for its current user
+func usedBytes(peers map[string]*nbpeer.Peer, usage []*types.ExitNodeUsage) map[string]int64 {
+	used := make(map[string]int64)
+	for _, u := range usage {
+		userID := u.UserID
+		if peer, ok := peers[u.PeerID]; ok {
+			userID = peer.UserID
+		}
+		used[consumer(userID, u.PeerID)] += u.Bytes()
+	}
+	return used
+}
+
+// Start resets the exit node quotas at the start of every month (UTC) until the context is done
+func (r *ExitNodeRecorder) Start(ctx context.Context) {
+	go func() {
+		for {
+			now := time.Now().UTC()
+			nextMonth := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			timer := time.NewTimer(nextMonth.Sub(now))
+
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+				r.resetQuotas(ctx)
+			}
+		}
+	}()
+}
+
+func (r *ExitNodeRecorder) resetQuotas(ctx context.Context) {
+	accountIDs, err := r.store.ResetExitNodeQuotas(ctx)
+	if err != nil {
+		log.WithContext(ctx).Errorf("failed to reset exit node quotas: %v", err)
+		return
+	}
+
+	for _, accountID := range accountIDs {
+		r.accountManager.UpdateAccountPeers(ctx, accountID)
+	}
+}
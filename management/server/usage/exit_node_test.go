@@ -0,0 +1,127 @@
+package usage
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/management/proto"
+	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/mock_server"
+	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+	"github.com/netbirdio/netbird/management/server/permissions"
+	"github.com/netbirdio/netbird/management/server/store"
+	nbtypes "github.com/netbirdio/netbird/management/server/types"
+	"github.com/netbirdio/netbird/management/server/usage/types"
+	"github.com/netbirdio/netbird/route"
+)
+
+const testExitNodeID = "exit-node"
+
+func TestExitNodeRecorder_RecordForwardedTraffic(t *testing.T) {
+	ctx := context.Background()
+
+	s, cleanUp, err := store.NewTestStoreFromSQL(ctx, "../testdata/store.sql", t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(cleanUp)
+
+	exitNode := &nbpeer.Peer{ID: testExitNodeID, AccountID: testAccountID, Key: "exit-node-key", IP: net.IP{100, 64, 0, 10}, Name: "exit", Status: &nbpeer.PeerStatus{}}
+	require.NoError(t, s.AddPeerToAccount(ctx, store.LockingStrengthUpdate, exitNode))
+
+	peer, err := s.GetPeerByID(ctx, store.LockingStrengthShare, testAccountID, testPeerID)
+	require.NoError(t, err)
+	peer.IP = net.IP{100, 64, 0, 20}
+	require.NoError(t, s.SavePeer(ctx, store.LockingStrengthUpdate, testAccountID, peer))
+
+	require.NoError(t, s.SaveGroup(ctx, store.LockingStrengthUpdate, &nbtypes.Group{
+		ID:            "limited",
+		AccountID:     testAccountID,
+		Name:          "limited",
+		Peers:         []string{testPeerID},
+		ExitNodeQuota: &nbtypes.ExitNodeQuota{MonthlyBytes: 1000},
+	}))
+	require.NoError(t, s.SaveRoute(ctx, store.LockingStrengthUpdate, &route.Route{
+		ID:        "exit",
+		AccountID: testAccountID,
+		Network:   netip.MustParsePrefix("0.0.0.0/0"),
+		NetID:     "exit",
+		Peer:      testExitNodeID,
+		Groups:    []string{"limited"},
+		Enabled:   true,
+	}))
+
+	var updates int
+	var events []activity.ActivityDescriber
+	am := &mock_server.MockAccountManager{
+		UpdateAccountPeersFunc: func(ctx context.Context, accountID string) {
+			updates++
+		},
+		StoreEventFunc: func(ctx context.Context, initiatorID, targetID, accountID string, activityID activity.ActivityDescriber, meta map[string]any) {
+			events = append(events, activityID)
+		},
+	}
+	recorder := NewExitNodeRecorder(s, am)
+
+	traffic := []*proto.ForwardedTraffic{
+		{PeerIp: "100.64.0.20", RxBytes: 400, TxBytes: 100},
+		{PeerIp: "100.64.0.99", RxBytes: 5000},
+	}
+	require.NoError(t, recorder.RecordForwardedTraffic(ctx, exitNode, traffic))
+	assert.Equal(t, 0, updates, "peers shouldn't be updated while the quota isn't reached")
+
+	require.NoError(t, recorder.RecordForwardedTraffic(ctx, exitNode, traffic))
+	assert.Equal(t, 1, updates)
+	assert.Equal(t, []activity.ActivityDescriber{activity.PeerExitNodeQuotaExceeded}, events)
+
+	// the other peers aren't exit nodes, their reports are dropped
+	require.NoError(t, recorder.RecordForwardedTraffic(ctx, peer, []*proto.ForwardedTraffic{{PeerIp: "100.64.0.10", RxBytes: 1}}))
+
+	peer, err = s.GetPeerByID(ctx, store.LockingStrengthShare, testAccountID, testPeerID)
+	require.NoError(t, err)
+	assert.True(t, peer.ExitNodeQuotaExceeded)
+
+	manager := NewManager(s, permissions.NewManager(s))
+	usage, err := manager.GetExitNodeUsage(ctx, testAccountID, testAdminID, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, usage, 1)
+	assert.Equal(t, testExitNodeID, usage[0].ExitNodeID)
+	assert.Equal(t, "exit", usage[0].ExitNodeName)
+	assert.Equal(t, testPeerID, usage[0].PeerID)
+	assert.Equal(t, int64(800), usage[0].RxBytes)
+	assert.Equal(t, int64(200), usage[0].TxBytes)
+	assert.True(t, usage[0].QuotaExceeded)
+
+	usage, err = manager.GetExitNodeUsage(ctx, testAccountID, testAdminID, time.Now().AddDate(0, -1, 0))
+	require.NoError(t, err)
+	assert.Empty(t, usage)
+
+	recorder.resetQuotas(ctx)
+	assert.Equal(t, 2, updates)
+	peer, err = s.GetPeerByID(ctx, store.LockingStrengthShare, testAccountID, testPeerID)
+	require.NoError(t, err)
+	assert.False(t, peer.ExitNodeQuotaExceeded)
+}
+
+func TestUsedBytes(t *testing.T) {
+	peers := map[string]*nbpeer.Peer{
+		"peer1": {ID: "peer1", UserID: "user1"},
+		"peer2": {ID: "peer2", UserID: "user1"},
+		"peer3": {ID: "peer3"},
+	}
+	usage := []*types.ExitNodeUsage{
+		{PeerID: "peer1", UserID: "user1", RxBytes: 100, TxBytes: 10},
+		{PeerID: "peer2", UserID: "user2", RxBytes: 200},
+		{PeerID: "peer3", RxBytes: 300},
+		{PeerID: "deleted", UserID: "user1", RxBytes: 400},
+	}
+
+	assert.Equal(t, map[string]int64{
+		"user/user1": 710,
+		"peer/peer3": 300,
+	}, usedBytes(peers, usage))
+}
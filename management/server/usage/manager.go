@@ -0,0 +1,133 @@
+package usage
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+	"github.com/netbirdio/netbird/management/server/permissions"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
+	"github.com/netbirdio/netbird/management/server/usage/types"
+)
+
+const (
+	// DefaultPeriod is the period of the usage returned when the request doesn't define it
+	DefaultPeriod = 30 * 24 * time.Hour
+	// MaxPeriod is the longest period of the usage returned by a request
+	MaxPeriod = 366 * 24 * time.Hour
+)
+
+type Manager interface {
+	// GetUsage returns the daily usage samples of the account between the days, the earliest first. The period ends
+	// today and starts DefaultPeriod before its end if the days are zero.
+	GetUsage(ctx context.Context, accountID, userID string, from, to time.Time) ([]*types.Sample, error)
+	// GetExitNodeUsage returns the exit node usage of the peers of the account in the month of the time, the highest
+	// traffic first. The month is the current one if the time is zero.
+	GetExitNodeUsage(ctx context.Context, accountID, userID string, month time.Time) ([]*types.ExitNodeUsage, error)
+}
+
+type managerImpl struct {
+	store              store.Store
+	permissionsManager permissions.Manager
+}
+
+type mockManager struct {
+}
+
+func NewManager(store store.Store, permissionsManager permissions.Manager) Manager {
+	return &managerImpl{
+		store:              store,
+		permissionsManager: permissionsManager,
+	}
+}
+
+func (m *managerImpl) GetUsage(ctx context.Context, accountID, userID string, from, to time.Time) ([]*types.Sample, error) {
+	if err := m.validatePermissions(ctx, accountID, userID); err != nil {
+		return nil, err
+	}
+
+	if to.IsZero() {
+		to = time.Now()
+	}
+	to = types.Day(to)
+	if from.IsZero() {
+		from = to.Add(-DefaultPeriod)
+	}
+	from = types.Day(from)
+
+	if from.After(to) {
+		return nil, status.Errorf(status.InvalidArgument, "usage period can't start after its end")
+	}
+	if to.Sub(from) > MaxPeriod {
+		return nil, status.Errorf(status.InvalidArgument, "usage period can't exceed %d days", int(MaxPeriod.Hours()/24))
+	}
+
+	return m.store.GetAccountUsage(ctx, store.LockingStrengthShare, accountID, from, to)
+}
+
+func (m *managerImpl) GetExitNodeUsage(ctx context.Context, accountID, userID string, month time.Time) ([]*types.ExitNodeUsage, error) {
+	if err := m.validatePermissions(ctx, accountID, userID); err != nil {
+		return nil, err
+	}
+
+	if month.IsZero() {
+		month = time.Now()
+	}
+
+	usage, err := m.store.GetAccountExitNodeUsage(ctx, store.LockingStrengthShare, accountID, types.Month(month))
+	if err != nil {
+		return nil, err
+	}
+
+	peers, err := m.store.GetAccountPeers(ctx, store.LockingStrengthShare, accountID, "", "")
+	if err != nil {
+		return nil, err
+	}
+	peersByID := make(map[string]*nbpeer.Peer, len(peers))
+	for _, peer := range peers {
+		peersByID[peer.ID] = peer
+	}
+
+	// the usage of the deleted peers is kept, it was forwarded by the exit nodes all the same
+	for _, u := range usage {
+		if exitNode, ok := peersByID[u.ExitNodeID]; ok {
+			u.ExitNodeName = exitNode.Name
+		}
+		if peer, ok := peersByID[u.PeerID]; ok {
+			u.PeerName = peer.Name
+			u.QuotaExceeded = peer.ExitNodeQuotaExceeded
+		}
+	}
+
+	slices.SortStableFunc(usage, func(a, b *types.ExitNodeUsage) int {
+		return cmp.Compare(b.Bytes(), a.Bytes())
+	})
+
+	return usage, nil
+}
+
+func (m *managerImpl) validatePermissions(ctx context.Context, accountID, userID string) error {
+	ok, err := m.permissionsManager.ValidateUserPermissions(ctx, accountID, userID, permissions.Settings, permissions.Read)
+	if err != nil {
+		return status.NewPermissionValidationError(err)
+	}
+	if !ok {
+		return status.NewPermissionDeniedError()
+	}
+	return nil
+}
+
+func NewManagerMock() Manager {
+	return &mockManager{}
+}
+
+func (m *mockManager) GetUsage(ctx context.Context, accountID, userID string, from, to time.Time) ([]*types.Sample, error) {
+	return []*types.Sample{}, nil
+}
+
+func (m *mockManager) GetExitNodeUsage(ctx context.Context, accountID, userID string, month time.Time) ([]*types.ExitNodeUsage, error) {
+	return []*types.ExitNodeUsage{}, nil
+}
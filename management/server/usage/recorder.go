@@ -0,0 +1,225 @@
+package usage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/store"
+	"github.com/netbirdio/netbird/management/server/usage/types"
+	"github.com/netbirdio/netbird/relay/messages"
+)
+
+// SampleInterval is the interval the usage samples are updated at
+const SampleInterval = 5 * time.Minute
+
+// RelayTransfer returns the bytes relayed per peer since the last call, keyed by the relay peer ID, i.e. the hashed
+// WireGuard public key of the peer
+type RelayTransfer interface {
+	TakePeerTransfer() map[string]int64
+}
+
+type accountDay struct {
+	accountID string
+	day       time.Time
+}
+
+// Recorder updates the daily usage samples of the accounts. It counts the activity events as an activity.Sink and
+// samples the peers and the traffic of the embedded relay service.
+// With several management nodes every node adds its events and relayed traffic to the samples.
+type Recorder struct {
+	store store.Store
+
+	mu     sync.Mutex
+	relay  RelayTransfer
+	events map[accountDay]int
+	// relayPeers maps the relay peer IDs to the accounts of the peers
+	relayPeers map[string]string
+}
+
+// NewRecorder creates a Recorder, Start updates the samples
+func NewRecorder(store store.Store) *Recorder {
+	return &Recorder{
+		store:      store,
+		events:     make(map[accountDay]int),
+		relayPeers: make(map[string]string),
+	}
+}
+
+// SetRelayTransfer sets the relay service embedded in the management server, its traffic is added to the samples
+func (r *Recorder) SetRelayTransfer(relay RelayTransfer) {
+	r.mu.Lock()
+	r.relay = relay
+	r.mu.Unlock()
+}
+
+// Publish counts the event in the usage of its account
+func (r *Recorder) Publish(_ context.Context, event *activity.Event) error {
+	if event.AccountID == "" {
+		return nil
+	}
+
+	r.mu.Lock()
+	r.events[accountDay{accountID: event.AccountID, day: types.Day(event.Timestamp)}]++
+	r.mu.Unlock()
+
+	return nil
+}
+
+// Close records the counted events
+func (r *Recorder) Close(ctx context.Context) error {
+	return r.record(ctx, time.Now())
+}
+
+// Start updates the usage samples every SampleInterval until the context is done
+func (r *Recorder) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(SampleInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				if err := r.record(ctx, now); err != nil {
+					log.WithContext(ctx).Errorf("failed to record usage: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// record adds the usage since the last call to the samples of the accounts
+func (r *Recorder) record(ctx context.Context, now time.Time) error {
+	today := types.Day(now)
+
+	r.mu.Lock()
+	events := r.events
+	r.events = make(map[accountDay]int)
+	relay := r.relay
+	r.mu.Unlock()
+
+	counts, err := r.store.CountPeersByAccount(ctx, today)
+	if err != nil {
+		r.restoreEvents(events)
+		return err
+	}
+
+	var relayBytes map[string]int64
+	if relay != nil {
+		relayBytes = r.relayBytesByAccount(ctx, relay.TakePeerTransfer())
+	}
+
+	usage := make(map[accountDay]*types.Sample)
+	sample := func(key accountDay) *types.Sample {
+		if usage[key] == nil {
+			usage[key] = &types.Sample{}
+		}
+		return usage[key]
+	}
+
+	for _, count := range counts {
+		s := sample(accountDay{accountID: count.AccountID, day: today})
+		s.ActivePeers = count.Active
+		s.PeakConnectedPeers = count.Connected
+	}
+	for accountID, bytes := range relayBytes {
+		sample(accountDay{accountID: accountID, day: today}).RelayBytes = bytes
+	}
+	for key, count := range events {
+		sample(key).Events = count
+	}
+
+	for key, delta := range usage {
+		err = r.store.UpdateUsageSample(ctx, key.accountID, key.day, func(sample *types.Sample) {
+			sample.ActivePeers = max(sample.ActivePeers, delta.ActivePeers)
+			sample.PeakConnectedPeers = max(sample.PeakConnectedPeers, delta.PeakConnectedPeers)
+			sample.RelayBytes += delta.RelayBytes
+			sample.Events += delta.Events
+		})
+		if err != nil {
+			log.WithContext(ctx).Errorf("failed to update usage of account %s: %v", key.accountID, err)
+		}
+	}
+
+	return nil
+}
+
+// restoreEvents adds the events that couldn't be recorded back to the counted ones
+func (r *Recorder) restoreEvents(events map[accountDay]int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, count := range events {
+		r.events[key] += count
+	}
+}
+
+// relayBytesByAccount sums the relayed bytes of the peers per account. The relay peer IDs of the peers are
+// reloaded if the traffic of an unknown peer is reported, the traffic of deleted peers is dropped.
+func (r *Recorder) relayBytesByAccount(ctx context.Context, transfer map[string]int64) map[string]int64 {
+	if len(transfer) == 0 {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for relayPeerID := range transfer {
+		if _, ok := r.relayPeers[relayPeerID]; !ok {
+			r.reloadRelayPeers(ctx)
+			break
+		}
+	}
+
+	bytes := make(map[string]int64)
+	for relayPeerID, transferred := range transfer {
+		if accountID, ok := r.relayPeers[relayPeerID]; ok {
+			bytes[accountID] += transferred
+		}
+	}
+
+	return bytes
+}
+
+// RelayPeerAccounts maps the relay peer IDs to the accounts of the peers for the introspection of the embedded relay
+// service, the IDs of deleted peers are left out
+func (r *Recorder) RelayPeerAccounts(ctx context.Context, relayPeerIDs []string) map[string]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, relayPeerID := range relayPeerIDs {
+		if _, ok := r.relayPeers[relayPeerID]; !ok {
+			r.reloadRelayPeers(ctx)
+			break
+		}
+	}
+
+	accounts := make(map[string]string, len(relayPeerIDs))
+	for _, relayPeerID := range relayPeerIDs {
+		if accountID, ok := r.relayPeers[relayPeerID]; ok {
+			accounts[relayPeerID] = accountID
+		}
+	}
+
+	return accounts
+}
+
+func (r *Recorder) reloadRelayPeers(ctx context.Context) {
+	keys, err := r.store.GetAllPeerKeys(ctx, store.LockingStrengthShare)
+	if err != nil {
+		log.WithContext(ctx).Errorf("failed to get peer keys for relay usage: %v", err)
+		return
+	}
+
+	relayPeers := make(map[string]string, len(keys))
+	for _, key := range keys {
+		_, relayPeerID := messages.HashID(key.Key)
+		relayPeers[relayPeerID] = key.AccountID
+	}
+	r.relayPeers = relayPeers
+}
@@ -0,0 +1,108 @@
+package usage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/management/server/activity"
+	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+	"github.com/netbirdio/netbird/management/server/permissions"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
+	"github.com/netbirdio/netbird/relay/messages"
+)
+
+const (
+	testAccountID = "bf1c8084-ba50-4ce7-9439-34653001fc3b"
+	testAdminID   = "edafee4e-63fb-11ec-90d6-0242ac120003"
+	testRegularID = "f4f6d672-63fb-11ec-90d6-0242ac120003"
+	testPeerID    = "ct286bi7qv930dsrrug0"
+	testPeerKey   = "RlSy2vzoG2HyMBTUImXOiVhCBiiBa5qD5xzMxkiFDW4="
+)
+
+type testRelay struct {
+	transfer map[string]int64
+}
+
+func (r *testRelay) TakePeerTransfer() map[string]int64 {
+	transfer := r.transfer
+	r.transfer = nil
+	return transfer
+}
+
+func TestRecorder_Record(t *testing.T) {
+	ctx := context.Background()
+
+	s, cleanUp, err := store.NewTestStoreFromSQL(ctx, "../testdata/store.sql", t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(cleanUp)
+
+	now := time.Now().UTC()
+
+	peer, err := s.GetPeerByID(ctx, store.LockingStrengthShare, testAccountID, testPeerID)
+	require.NoError(t, err)
+	peer.Key = testPeerKey
+	require.NoError(t, s.SavePeer(ctx, store.LockingStrengthUpdate, testAccountID, peer))
+	require.NoError(t, s.SavePeerStatus(ctx, store.LockingStrengthUpdate, testAccountID, testPeerID, nbpeer.PeerStatus{Connected: true, LastSeen: now}))
+
+	_, relayPeerID := messages.HashID(testPeerKey)
+	relay := &testRelay{transfer: map[string]int64{relayPeerID: 1000, "unknown-peer": 500}}
+
+	recorder := NewRecorder(s)
+	recorder.SetRelayTransfer(relay)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, recorder.Publish(ctx, &activity.Event{AccountID: testAccountID, Timestamp: now}))
+	}
+	require.NoError(t, recorder.record(ctx, now))
+
+	relay.transfer = map[string]int64{relayPeerID: 24}
+	require.NoError(t, recorder.Publish(ctx, &activity.Event{AccountID: testAccountID, Timestamp: now}))
+	require.NoError(t, s.SavePeerStatus(ctx, store.LockingStrengthUpdate, testAccountID, testPeerID, nbpeer.PeerStatus{Connected: false, LastSeen: now}))
+	require.NoError(t, recorder.record(ctx, now))
+
+	manager := NewManager(s, permissions.NewManager(s))
+
+	_, err = manager.GetUsage(ctx, testAccountID, testRegularID, time.Time{}, time.Time{})
+	sErr, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, status.PermissionDenied, sErr.Type())
+
+	samples, err := manager.GetUsage(ctx, testAccountID, testAdminID, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, samples, 1)
+
+	sample := samples[0]
+	assert.Equal(t, now.Truncate(24*time.Hour), sample.Date.UTC())
+	assert.Equal(t, 1, sample.ActivePeers)
+	assert.Equal(t, 1, sample.PeakConnectedPeers, "peak should be kept after the peer disconnected")
+	assert.Equal(t, int64(1024), sample.RelayBytes, "traffic of unknown peers should be dropped")
+	assert.Equal(t, 4, sample.Events)
+
+	_, err = manager.GetUsage(ctx, testAccountID, testAdminID, now, now.Add(-48*time.Hour))
+	sErr, ok = status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, status.InvalidArgument, sErr.Type())
+}
+
+func TestRecorder_RelayPeerAccounts(t *testing.T) {
+	ctx := context.Background()
+
+	s, cleanUp, err := store.NewTestStoreFromSQL(ctx, "../testdata/store.sql", t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(cleanUp)
+
+	peer, err := s.GetPeerByID(ctx, store.LockingStrengthShare, testAccountID, testPeerID)
+	require.NoError(t, err)
+	peer.Key = testPeerKey
+	require.NoError(t, s.SavePeer(ctx, store.LockingStrengthUpdate, testAccountID, peer))
+
+	_, relayPeerID := messages.HashID(testPeerKey)
+
+	accounts := NewRecorder(s).RelayPeerAccounts(ctx, []string{relayPeerID, "unknown-peer"})
+	assert.Equal(t, map[string]string{relayPeerID: testAccountID}, accounts)
+}
@@ -0,0 +1,59 @@
+package types
+
+import (
+	"time"
+
+	"github.com/netbirdio/netbird/management/server/http/api"
+)
+
+// MonthFormat is the format of the months of the exit node usage in the API
+const MonthFormat = "2006-01"
+
+// ExitNodeUsage is the traffic an exit node forwarded for a peer in a month (UTC). It is updated with the reports of
+// the exit node, so the usage of the current month covers the traffic so far.
+type ExitNodeUsage struct {
+	Month      string `gorm:"primaryKey"`
+	ExitNodeID string `gorm:"primaryKey"`
+	PeerID     string `gorm:"primaryKey"`
+	AccountID  string `gorm:"index"`
+	// UserID is the user the peer belongs to, empty for the peers registered with a setup key
+	UserID string
+	// RxBytes were sent to the peer, TxBytes were sent by the peer
+	RxBytes   int64
+	TxBytes   int64
+	UpdatedAt time.Time
+	// ExitNodeName, PeerName and QuotaExceeded are set from the peers when the usage is returned, they aren't stored
+	ExitNodeName  string `gorm:"-"`
+	PeerName      string `gorm:"-"`
+	QuotaExceeded bool   `gorm:"-"`
+}
+
+// TableName returns the table of the exit node usage
+func (ExitNodeUsage) TableName() string {
+	return "exit_node_usage"
+}
+
+// Month returns the month (UTC) of the time in the MonthFormat
+func Month(t time.Time) string {
+	return t.UTC().Format(MonthFormat)
+}
+
+// Bytes returns the traffic of the peer in both directions
+func (u *ExitNodeUsage) Bytes() int64 {
+	return u.RxBytes + u.TxBytes
+}
+
+func (u *ExitNodeUsage) ToAPIResponse() *api.ExitNodeUsage {
+	return &api.ExitNodeUsage{
+		Month:          u.Month,
+		ExitNodeId:     u.ExitNodeID,
+		ExitNodeName:   u.ExitNodeName,
+		PeerId:         u.PeerID,
+		PeerName:       u.PeerName,
+		UserId:         u.UserID,
+		RxBytes:        u.RxBytes,
+		TxBytes:        u.TxBytes,
+		QuotaExceeded:  u.QuotaExceeded,
+		LastReportedAt: u.UpdatedAt,
+	}
+}
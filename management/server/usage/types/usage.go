@@ -0,0 +1,59 @@
+package types
+
+import (
+	"time"
+
+	"github.com/netbirdio/netbird/management/server/http/api"
+)
+
+// DateFormat is the format of the days of the usage samples in the API
+const DateFormat = "2006-01-02"
+
+// Sample is the usage of an account on a day (UTC). It is updated through the day, so the sample of the current day
+// covers the usage so far.
+type Sample struct {
+	AccountID string    `gorm:"primaryKey"`
+	Date      time.Time `gorm:"primaryKey"`
+	// ActivePeers is the number of peers seen by the management service on the day
+	ActivePeers int
+	// PeakConnectedPeers is the highest number of peers connected at the same time to the management service, i.e.
+	// the number of their open management streams
+	PeakConnectedPeers int
+	// RelayBytes is the traffic of the peers relayed by the relay service embedded in the management server
+	RelayBytes int64
+	// Events is the number of activity events of the account
+	Events int
+}
+
+// TableName returns the table of the usage samples
+func (Sample) TableName() string {
+	return "usage_samples"
+}
+
+// PeerCounts is the number of active and connected peers of an account
+type PeerCounts struct {
+	AccountID string
+	Active    int
+	Connected int
+}
+
+// PeerKey is the WireGuard public key of a peer of the account
+type PeerKey struct {
+	AccountID string
+	Key       string
+}
+
+// Day returns the start of the day (UTC) of the time
+func Day(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+func (s *Sample) ToAPIResponse() *api.UsageSample {
+	return &api.UsageSample{
+		Date:               s.Date.UTC().Format(DateFormat),
+		ActivePeers:        s.ActivePeers,
+		PeakConnectedPeers: s.PeakConnectedPeers,
+		RelayBytes:         s.RelayBytes,
+		Events:             s.Events,
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -14,6 +15,7 @@ import (
 	nbContext "github.com/netbirdio/netbird/management/server/context"
 	"github.com/netbirdio/netbird/management/server/idp"
 	nbpeer "github.com/netbirdio/netbird/management/server/peer"
+	"github.com/netbirdio/netbird/management/server/permissions"
 	"github.com/netbirdio/netbird/management/server/status"
 	"github.com/netbirdio/netbird/management/server/store"
 	"github.com/netbirdio/netbird/management/server/types"
@@ -330,7 +332,7 @@ func (am *DefaultAccountManager) InviteUser(ctx context.Context, accountID strin
 }
 
 // CreatePAT creates a new PAT for the given user
-func (am *DefaultAccountManager) CreatePAT(ctx context.Context, accountID string, initiatorUserID string, targetUserID string, tokenName string, expiresIn int) (*types.PersonalAccessTokenGenerated, error) {
+func (am *DefaultAccountManager) CreatePAT(ctx context.Context, accountID string, initiatorUserID string, targetUserID string, tokenName string, expiresIn int, scopes []string, groups []string) (*types.PersonalAccessTokenGenerated, error) {
 	unlock := am.Store.AcquireWriteLockByUID(ctx, accountID)
 	defer unlock()
 
@@ -342,6 +344,14 @@ func (am *DefaultAccountManager) CreatePAT(ctx context.Context, accountID string
 		return nil, status.Errorf(status.InvalidArgument, "expiration has to be between 1 and 365")
 	}
 
+	if err := validatePATScopes(scopes); err != nil {
+		return nil, err
+	}
+
+	if err := validateInitiatorPATRestrictions(ctx, scopes, groups); err != nil {
+		return nil, err
+	}
+
 	initiatorUser, err := am.Store.GetUserByUserID(ctx, store.LockingStrengthShare, initiatorUserID)
 	if err != nil {
 		return nil, err
@@ -351,6 +361,19 @@ func (am *DefaultAccountManager) CreatePAT(ctx context.Context, accountID string
 		return nil, err
 	}
 
+	if len(groups) > 0 {
+		accountGroups, err := am.Store.GetGroupsByIDs(ctx, store.LockingStrengthShare, accountID, groups)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, groupID := range groups {
+			if _, ok := accountGroups[groupID]; !ok {
+				return nil, status.Errorf(status.InvalidArgument, "group %s not found", groupID)
+			}
+		}
+	}
+
 	targetUser, err := am.Store.GetUserByUserID(ctx, store.LockingStrengthShare, targetUserID)
 	if err != nil {
 		return nil, err
@@ -364,6 +387,8 @@ func (am *DefaultAccountManager) CreatePAT(ctx context.Context, accountID string
 	if err != nil {
 		return nil, status.Errorf(status.Internal, "failed to create PAT: %v", err)
 	}
+	pat.Scopes = scopes
+	pat.Groups = groups
 
 	if err = am.Store.SavePAT(ctx, store.LockingStrengthUpdate, &pat.PersonalAccessToken); err != nil {
 		return nil, err
@@ -375,6 +400,103 @@ func (am *DefaultAccountManager) CreatePAT(ctx context.Context, accountID string
 	return pat, nil
 }
 
+// RotatePAT replaces a personal access token with a new one keeping its name, scopes and groups.
+// The rotated token stays valid for the grace period, so both tokens are active while clients switch to the new one.
+func (am *DefaultAccountManager) RotatePAT(ctx context.Context, accountID string, initiatorUserID string, targetUserID string, tokenID string, expiresIn int, gracePeriod time.Duration) (*types.PersonalAccessTokenGenerated, error) {
+	unlock := am.Store.AcquireWriteLockByUID(ctx, accountID)
+	defer unlock()
+
+	if expiresIn < 1 || expiresIn > 365 {
+		return nil, status.Errorf(status.InvalidArgument, "expiration has to be between 1 and 365")
+	}
+
+	if gracePeriod < 0 || gracePeriod > types.MaxPATRotationGracePeriod {
+		return nil, status.Errorf(status.InvalidArgument, "grace period has to be between 0 and %s", types.MaxPATRotationGracePeriod)
+	}
+
+	initiatorUser, err := am.Store.GetUserByUserID(ctx, store.LockingStrengthShare, initiatorUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := am.permissionsManager.ValidateAccountAccess(ctx, accountID, initiatorUser, false); err != nil {
+		return nil, err
+	}
+
+	targetUser, err := am.Store.GetUserByUserID(ctx, store.LockingStrengthShare, targetUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if initiatorUserID != targetUserID && !(initiatorUser.HasAdminPower() && targetUser.IsServiceUser) {
+		return nil, status.NewAdminPermissionError()
+	}
+
+	pat, err := am.Store.GetPATByID(ctx, store.LockingStrengthUpdate, targetUserID, tokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	if time.Now().After(pat.GetExpirationDate()) {
+		return nil, status.Errorf(status.PreconditionFailed, "token has expired, create a new one instead")
+	}
+
+	if err := validateInitiatorPATRestrictions(ctx, pat.Scopes, pat.Groups); err != nil {
+		return nil, err
+	}
+
+	newPAT, err := pat.Rotate(expiresIn, gracePeriod, initiatorUser.Id)
+	if err != nil {
+		return nil, status.Errorf(status.Internal, "failed to rotate PAT: %v", err)
+	}
+
+	err = am.Store.ExecuteInTransaction(ctx, func(transaction store.Store) error {
+		if err = transaction.SavePAT(ctx, store.LockingStrengthUpdate, pat); err != nil {
+			return err
+		}
+		return transaction.SavePAT(ctx, store.LockingStrengthUpdate, &newPAT.PersonalAccessToken)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	meta := map[string]any{"name": pat.Name, "is_service_user": targetUser.IsServiceUser, "user_name": targetUser.ServiceUserName, "rotated_token_id": pat.ID}
+	am.StoreEvent(ctx, initiatorUserID, targetUserID, accountID, activity.PersonalAccessTokenRotated, meta)
+
+	return newPAT, nil
+}
+
+// validateInitiatorPATRestrictions rejects tokens allowed more than the token the request is authenticated with, so a
+// restricted token can't create or rotate a token escaping its scopes and groups
+func validateInitiatorPATRestrictions(ctx context.Context, scopes, groups []string) error {
+	userAuth, err := nbContext.GetUserAuthFromContext(ctx)
+	if err != nil || !userAuth.IsPAT {
+		return nil
+	}
+
+	if !types.PATRestrictionsWithin(scopes, groups, userAuth.PATScopes, userAuth.PATGroups) {
+		return status.Errorf(status.PermissionDenied, "the token scopes and groups have to be within the ones of the token the request is authenticated with")
+	}
+
+	return nil
+}
+
+// validatePATScopes checks that the token scopes are in the module:operation format and refer to existing modules
+func validatePATScopes(scopes []string) error {
+	for _, scope := range scopes {
+		module, _, err := types.ParsePATScope(scope)
+		if err != nil {
+			return status.Errorf(status.InvalidArgument, "%s", err)
+		}
+
+		if !slices.Contains(permissions.Modules, permissions.Module(module)) {
+			return status.Errorf(status.InvalidArgument, "unknown module %s in token scope %s", module, scope)
+		}
+	}
+
+	return nil
+}
+
 // DeletePAT deletes a specific PAT from a user
 func (am *DefaultAccountManager) DeletePAT(ctx context.Context, accountID string, initiatorUserID string, targetUserID string, tokenID string) error {
 	unlock := am.Store.AcquireWriteLockByUID(ctx, accountID)
@@ -559,7 +681,7 @@ func (am *DefaultAccountManager) SaveOrAddUsers(ctx context.Context, accountID,
 	}
 
 	if len(peersToExpire) > 0 {
-		if err := am.expireAndUpdatePeers(ctx, accountID, peersToExpire); err != nil {
+		if err := am.expireAndUpdatePeers(ctx, accountID, peersToExpire, false); err != nil {
 			log.WithContext(ctx).Errorf("failed update expired peers: %s", err)
 			return nil, err
 		}
@@ -919,8 +1041,10 @@ func (am *DefaultAccountManager) BuildUserInfosForAccount(ctx context.Context, a
 	return userInfosMap, nil
 }
 
-// expireAndUpdatePeers expires all peers of the given user and updates them in the account
-func (am *DefaultAccountManager) expireAndUpdatePeers(ctx context.Context, accountID string, peers []*nbpeer.Peer) error {
+// expireAndUpdatePeers expires all peers of the given user and updates them in the account.
+// When allowGracePeriod is set and the account has the login expiration grace period enabled, the peers stay connected
+// to the remediation policies instead of being disconnected.
+func (am *DefaultAccountManager) expireAndUpdatePeers(ctx context.Context, accountID string, peers []*nbpeer.Peer, allowGracePeriod bool) error {
 	var peerIDs []string
 	for _, peer := range peers {
 		// nolint:staticcheck
@@ -942,11 +1066,26 @@ func (am *DefaultAccountManager) expireAndUpdatePeers(ctx context.Context, accou
 		)
 	}
 
-	if len(peerIDs) != 0 {
+	if len(peerIDs) == 0 {
+		return nil
+	}
+
+	gracePeriod := false
+	if allowGracePeriod {
+		settings, err := am.Store.GetAccountSettings(ctx, store.LockingStrengthShare, accountID)
+		if err != nil {
+			return err
+		}
+		gracePeriod = settings.PeerLoginExpirationGraceEnabled
+	}
+
+	// in the grace period the expired peers stay connected and get a network map limited to the remediation policies
+	if !gracePeriod {
 		// this will trigger peer disconnect from the management service
-		am.peersUpdateManager.CloseChannels(ctx, peerIDs)
-		am.UpdateAccountPeers(ctx, accountID)
+		am.closePeerChannels(ctx, accountID, peerIDs)
 	}
+	am.UpdateAccountPeers(ctx, accountID)
+
 	return nil
 }
 
@@ -66,7 +66,7 @@ func TestUser_CreatePAT_ForSameUser(t *testing.T) {
 		permissionsManager: permissionsMananagerMock,
 	}
 
-	pat, err := am.CreatePAT(context.Background(), mockAccountID, mockUserID, mockUserID, mockTokenName, mockExpiresIn)
+	pat, err := am.CreatePAT(context.Background(), mockAccountID, mockUserID, mockUserID, mockTokenName, mockExpiresIn, nil, nil)
 	if err != nil {
 		t.Fatalf("Error when adding PAT to user: %s", err)
 	}
@@ -116,7 +116,7 @@ func TestUser_CreatePAT_ForDifferentUser(t *testing.T) {
 		permissionsManager: permissionsMananagerMock,
 	}
 
-	_, err = am.CreatePAT(context.Background(), mockAccountID, mockUserID, mockTargetUserId, mockTokenName, mockExpiresIn)
+	_, err = am.CreatePAT(context.Background(), mockAccountID, mockUserID, mockTargetUserId, mockTokenName, mockExpiresIn, nil, nil)
 	assert.Errorf(t, err, "Creating PAT for different user should thorw error")
 }
 
@@ -144,7 +144,7 @@ func TestUser_CreatePAT_ForServiceUser(t *testing.T) {
 		permissionsManager: permissionsMananagerMock,
 	}
 
-	pat, err := am.CreatePAT(context.Background(), mockAccountID, mockUserID, mockTargetUserId, mockTokenName, mockExpiresIn)
+	pat, err := am.CreatePAT(context.Background(), mockAccountID, mockUserID, mockTargetUserId, mockTokenName, mockExpiresIn, nil, nil)
 	if err != nil {
 		t.Fatalf("Error when adding PAT to user: %s", err)
 	}
@@ -173,7 +173,7 @@ func TestUser_CreatePAT_WithWrongExpiration(t *testing.T) {
 		permissionsManager: permissionsMananagerMock,
 	}
 
-	_, err = am.CreatePAT(context.Background(), mockAccountID, mockUserID, mockUserID, mockTokenName, mockWrongExpiresIn)
+	_, err = am.CreatePAT(context.Background(), mockAccountID, mockUserID, mockUserID, mockTokenName, mockWrongExpiresIn, nil, nil)
 	assert.Errorf(t, err, "Wrong expiration should thorw error")
 }
 
@@ -198,10 +198,108 @@ func TestUser_CreatePAT_WithEmptyName(t *testing.T) {
 		permissionsManager: permissionsMananagerMock,
 	}
 
-	_, err = am.CreatePAT(context.Background(), mockAccountID, mockUserID, mockUserID, mockEmptyTokenName, mockExpiresIn)
+	_, err = am.CreatePAT(context.Background(), mockAccountID, mockUserID, mockUserID, mockEmptyTokenName, mockExpiresIn, nil, nil)
 	assert.Errorf(t, err, "Wrong expiration should thorw error")
 }
 
+func TestUser_RotatePAT(t *testing.T) {
+	s, cleanup, err := store.NewTestStoreFromSQL(context.Background(), "", t.TempDir())
+	if err != nil {
+		t.Fatalf("Error when creating store: %s", err)
+	}
+	t.Cleanup(cleanup)
+
+	account := newAccountWithId(context.Background(), mockAccountID, mockUserID, "")
+	account.Users[mockServiceUserID] = &types.User{
+		Id:              mockServiceUserID,
+		AccountID:       mockAccountID,
+		Role:            types.UserRoleAdmin,
+		IsServiceUser:   true,
+		ServiceUserName: mockServiceUserName,
+	}
+
+	err = s.SaveAccount(context.Background(), account)
+	if err != nil {
+		t.Fatalf("Error when saving account: %s", err)
+	}
+
+	am := DefaultAccountManager{
+		Store:              s,
+		eventStore:         &activity.InMemoryEventStore{},
+		permissionsManager: permissions.NewManagerMock(),
+	}
+
+	pat, err := am.CreatePAT(context.Background(), mockAccountID, mockUserID, mockServiceUserID, mockTokenName, mockExpiresIn, []string{"peers:read"}, nil)
+	require.NoError(t, err)
+
+	_, err = am.RotatePAT(context.Background(), mockAccountID, mockUserID, mockServiceUserID, pat.ID, mockExpiresIn, types.MaxPATRotationGracePeriod+time.Minute)
+	assert.Error(t, err, "grace period longer than the maximum should fail")
+
+	rotated, err := am.RotatePAT(context.Background(), mockAccountID, mockUserID, mockServiceUserID, pat.ID, mockExpiresIn, time.Hour)
+	require.NoError(t, err)
+	assert.NotEqual(t, pat.ID, rotated.ID)
+	assert.Equal(t, pat.Name, rotated.Name)
+	assert.Equal(t, []string{"peers:read"}, rotated.Scopes)
+
+	oldPAT, err := s.GetPATByID(context.Background(), store.LockingStrengthShare, mockServiceUserID, pat.ID)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), oldPAT.GetExpirationDate(), time.Minute, "the rotated token stays valid for the grace period")
+
+	newPAT, err := s.GetPATByID(context.Background(), store.LockingStrengthShare, mockServiceUserID, rotated.ID)
+	require.NoError(t, err)
+	assert.Equal(t, rotated.HashedToken, newPAT.HashedToken)
+}
+
+func TestUser_PATWithinInitiatorToken(t *testing.T) {
+	s, cleanup, err := store.NewTestStoreFromSQL(context.Background(), "", t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(cleanup)
+
+	account := newAccountWithId(context.Background(), mockAccountID, mockUserID, "")
+	account.Users[mockServiceUserID] = &types.User{
+		Id:              mockServiceUserID,
+		AccountID:       mockAccountID,
+		Role:            types.UserRoleAdmin,
+		IsServiceUser:   true,
+		ServiceUserName: mockServiceUserName,
+	}
+	account.Groups["group1"] = &types.Group{ID: "group1", AccountID: mockAccountID, Name: "group1"}
+	account.Groups["group2"] = &types.Group{ID: "group2", AccountID: mockAccountID, Name: "group2"}
+	require.NoError(t, s.SaveAccount(context.Background(), account))
+
+	am := DefaultAccountManager{
+		Store:              s,
+		eventStore:         &activity.InMemoryEventStore{},
+		permissionsManager: permissions.NewManagerMock(),
+	}
+
+	unscoped, err := am.CreatePAT(context.Background(), mockAccountID, mockServiceUserID, mockServiceUserID, mockTokenName, mockExpiresIn, nil, nil)
+	require.NoError(t, err)
+
+	ctx := nbcontext.SetUserAuthInContext(context.Background(), nbcontext.UserAuth{
+		AccountId: mockAccountID,
+		UserId:    mockServiceUserID,
+		IsPAT:     true,
+		PATScopes: []string{"users:write", "peers:read"},
+		PATGroups: []string{"group1"},
+	})
+
+	_, err = am.CreatePAT(ctx, mockAccountID, mockServiceUserID, mockServiceUserID, mockTokenName, mockExpiresIn, nil, nil)
+	assert.Error(t, err, "a restricted token shouldn't create an unrestricted token")
+
+	_, err = am.CreatePAT(ctx, mockAccountID, mockServiceUserID, mockServiceUserID, mockTokenName, mockExpiresIn, []string{"routes:write"}, []string{"group1"})
+	assert.Error(t, err, "a restricted token shouldn't create a token with other scopes")
+
+	_, err = am.CreatePAT(ctx, mockAccountID, mockServiceUserID, mockServiceUserID, mockTokenName, mockExpiresIn, []string{"peers:read"}, []string{"group2"})
+	assert.Error(t, err, "a restricted token shouldn't create a token with other groups")
+
+	_, err = am.CreatePAT(ctx, mockAccountID, mockServiceUserID, mockServiceUserID, mockTokenName, mockExpiresIn, []string{"peers:read"}, []string{"group1"})
+	assert.NoError(t, err, "a restricted token should create a token within its restrictions")
+
+	_, err = am.RotatePAT(ctx, mockAccountID, mockServiceUserID, mockServiceUserID, unscoped.ID, mockExpiresIn, time.Hour)
+	assert.Error(t, err, "a restricted token shouldn't rotate an unrestricted token")
+}
+
 func TestUser_DeletePAT(t *testing.T) {
 	store, cleanup, err := store.NewTestStoreFromSQL(context.Background(), "", t.TempDir())
 	if err != nil {
@@ -0,0 +1,214 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/xid"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/store"
+	"github.com/netbirdio/netbird/management/server/webhooks/types"
+)
+
+const (
+	// SignatureHeader holds the hex encoded HMAC-SHA256 of the request body signed with the webhook secret
+	SignatureHeader = "X-NetBird-Signature"
+	// EventHeader holds the activity code of the event, e.g. peer.user.add
+	EventHeader = "X-NetBird-Event"
+	// DeliveryHeader holds a unique ID of the delivery, the same for all retries
+	DeliveryHeader = "X-NetBird-Delivery"
+
+	defaultRequestTimeout = 10 * time.Second
+	defaultInitialBackoff = time.Second
+	maxBackoff            = time.Minute
+	queueSize             = 1000
+)
+
+// Payload is the JSON body posted to the webhook endpoints
+type Payload struct {
+	ID           uint64         `json:"id"`
+	Timestamp    time.Time      `json:"timestamp"`
+	Activity     string         `json:"activity"`
+	ActivityCode string         `json:"activity_code"`
+	InitiatorID  string         `json:"initiator_id"`
+	TargetID     string         `json:"target_id"`
+	AccountID    string         `json:"account_id"`
+	Meta         map[string]any `json:"meta,omitempty"`
+}
+
+// Dispatcher is an activity.Sink delivering the events to the webhooks of their account in the background.
+// Failed deliveries are retried with exponential backoff up to the retries defined by the webhook.
+type Dispatcher struct {
+	store          store.Store
+	httpClient     *http.Client
+	queue          chan *activity.Event
+	initialBackoff time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewDispatcher creates a Dispatcher and starts processing events
+func NewDispatcher(ctx context.Context, store store.Store) *Dispatcher {
+	ctx, cancel := context.WithCancel(ctx)
+	d := &Dispatcher{
+		store: store,
+		httpClient: &http.Client{
+			Timeout: defaultRequestTimeout,
+		},
+		queue:          make(chan *activity.Event, queueSize),
+		initialBackoff: defaultInitialBackoff,
+		ctx:            ctx,
+		cancel:         cancel,
+	}
+
+	d.wg.Add(1)
+	go d.run()
+
+	return d
+}
+
+// Publish queues the event for delivery. Events are dropped if the queue is full so callers are never blocked.
+func (d *Dispatcher) Publish(_ context.Context, event *activity.Event) error {
+	select {
+	case d.queue <- event:
+	default:
+		log.Warnf("webhook queue is full, dropping event %d of account %s", event.ID, event.AccountID)
+	}
+	return nil
+}
+
+// Close stops processing events and waits for the ongoing deliveries to return
+func (d *Dispatcher) Close(_ context.Context) error {
+	d.cancel()
+	d.wg.Wait()
+	return nil
+}
+
+func (d *Dispatcher) run() {
+	defer d.wg.Done()
+
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case event := <-d.queue:
+			d.dispatch(event)
+		}
+	}
+}
+
+func (d *Dispatcher) dispatch(event *activity.Event) {
+	webhooks, err := d.store.GetAccountWebhooks(d.ctx, store.LockingStrengthShare, event.AccountID)
+	if err != nil {
+		log.WithContext(d.ctx).Errorf("failed to get webhooks of account %s: %v", event.AccountID, err)
+		return
+	}
+
+	code := event.Activity.StringCode()
+	var body []byte
+	for _, webhook := range webhooks {
+		if !webhook.Matches(code) {
+			continue
+		}
+
+		if body == nil {
+			body, err = json.Marshal(toPayload(event))
+			if err != nil {
+				log.WithContext(d.ctx).Errorf("failed to marshal webhook payload of event %d: %v", event.ID, err)
+				return
+			}
+		}
+
+		d.wg.Add(1)
+		go func(webhook *types.Webhook) {
+			defer d.wg.Done()
+			d.deliver(webhook, code, body)
+		}(webhook)
+	}
+}
+
+// deliver posts the body to the webhook retrying with exponential backoff
+func (d *Dispatcher) deliver(webhook *types.Webhook, code string, body []byte) {
+	deliveryID := xid.New().String()
+	backoff := d.initialBackoff
+
+	for attempt := 0; ; attempt++ {
+		err := d.send(webhook, code, deliveryID, body)
+		if err == nil {
+			return
+		}
+
+		if attempt >= webhook.MaxRetries {
+			log.WithContext(d.ctx).Warnf("failed to deliver event %s to webhook %s after %d attempts: %v", code, webhook.ID, attempt+1, err)
+			return
+		}
+
+		log.WithContext(d.ctx).Debugf("failed to deliver event %s to webhook %s, retrying in %s: %v", code, webhook.ID, backoff, err)
+
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+func (d *Dispatcher) send(webhook *types.Webhook, code, deliveryID string, body []byte) error {
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create webhook request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, code)
+	req.Header.Set(DeliveryHeader, deliveryID)
+	if webhook.Secret != "" {
+		req.Header.Set(SignatureHeader, "sha256="+Sign(webhook.Secret, body))
+	}
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("send webhook request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook endpoint responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of the body using the secret
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func toPayload(event *activity.Event) *Payload {
+	return &Payload{
+		ID:           event.ID,
+		Timestamp:    event.Timestamp,
+		Activity:     event.Activity.Message(),
+		ActivityCode: event.Activity.StringCode(),
+		InitiatorID:  event.InitiatorID,
+		TargetID:     event.TargetID,
+		AccountID:    event.AccountID,
+		Meta:         event.Meta,
+	}
+}
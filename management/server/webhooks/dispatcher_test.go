@@ -0,0 +1,66 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/store"
+	"github.com/netbirdio/netbird/management/server/webhooks/types"
+)
+
+func TestDispatcher_DeliversWithRetries(t *testing.T) {
+	var attempts atomic.Int32
+	received := make(chan *http.Request, 1)
+	var body []byte
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ = io.ReadAll(r.Body)
+		received <- r
+	}))
+	defer server.Close()
+
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	err := s.SaveWebhook(ctx, store.LockingStrengthUpdate, types.NewWebhook(testAccountID, "hook", server.URL, "secret", []string{activity.PeerAddedByUser.StringCode()}, 2, true))
+	require.NoError(t, err)
+
+	dispatcher := NewDispatcher(ctx, s)
+	dispatcher.initialBackoff = time.Millisecond
+	eventStore := activity.NewSinkStore(&activity.InMemoryEventStore{}, dispatcher)
+	defer func() {
+		_ = eventStore.Close(ctx)
+	}()
+
+	_, err = eventStore.Save(ctx, &activity.Event{Activity: activity.PolicyAdded, AccountID: testAccountID})
+	require.NoError(t, err)
+	_, err = eventStore.Save(ctx, &activity.Event{Activity: activity.PeerAddedByUser, AccountID: testAccountID, TargetID: "peer1"})
+	require.NoError(t, err)
+
+	select {
+	case r := <-received:
+		assert.Equal(t, activity.PeerAddedByUser.StringCode(), r.Header.Get(EventHeader))
+		assert.Equal(t, "sha256="+Sign("secret", body), r.Header.Get(SignatureHeader))
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not delivered")
+	}
+
+	payload := &Payload{}
+	require.NoError(t, json.Unmarshal(body, payload))
+	assert.Equal(t, "peer1", payload.TargetID)
+	assert.Equal(t, int32(2), attempts.Load(), "the failed delivery is retried and events not subscribed to are skipped")
+}
@@ -0,0 +1,190 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/rs/xid"
+
+	"github.com/netbirdio/netbird/management/server/account"
+	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/permissions"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
+	"github.com/netbirdio/netbird/management/server/webhooks/types"
+)
+
+type Manager interface {
+	GetAllWebhooks(ctx context.Context, accountID, userID string) ([]*types.Webhook, error)
+	CreateWebhook(ctx context.Context, userID string, webhook *types.Webhook) (*types.Webhook, error)
+	GetWebhook(ctx context.Context, accountID, userID, webhookID string) (*types.Webhook, error)
+	UpdateWebhook(ctx context.Context, userID string, webhook *types.Webhook) (*types.Webhook, error)
+	DeleteWebhook(ctx context.Context, accountID, userID, webhookID string) error
+}
+
+type managerImpl struct {
+	store              store.Store
+	permissionsManager permissions.Manager
+	accountManager     account.Manager
+}
+
+type mockManager struct {
+}
+
+func NewManager(store store.Store, permissionsManager permissions.Manager, accountManager account.Manager) Manager {
+	return &managerImpl{
+		store:              store,
+		permissionsManager: permissionsManager,
+		accountManager:     accountManager,
+	}
+}
+
+func (m *managerImpl) GetAllWebhooks(ctx context.Context, accountID, userID string) ([]*types.Webhook, error) {
+	if err := m.validatePermissions(ctx, accountID, userID, permissions.Read); err != nil {
+		return nil, err
+	}
+
+	return m.store.GetAccountWebhooks(ctx, store.LockingStrengthShare, accountID)
+}
+
+func (m *managerImpl) CreateWebhook(ctx context.Context, userID string, webhook *types.Webhook) (*types.Webhook, error) {
+	if err := m.validatePermissions(ctx, webhook.AccountID, userID, permissions.Write); err != nil {
+		return nil, err
+	}
+
+	webhook.ID = xid.New().String()
+
+	if err := validateWebhook(webhook); err != nil {
+		return nil, err
+	}
+
+	if err := m.store.SaveWebhook(ctx, store.LockingStrengthUpdate, webhook); err != nil {
+		return nil, fmt.Errorf("failed to save webhook: %w", err)
+	}
+
+	m.accountManager.StoreEvent(ctx, userID, webhook.ID, webhook.AccountID, activity.WebhookCreated, webhook.EventMeta())
+
+	return webhook, nil
+}
+
+func (m *managerImpl) GetWebhook(ctx context.Context, accountID, userID, webhookID string) (*types.Webhook, error) {
+	if err := m.validatePermissions(ctx, accountID, userID, permissions.Read); err != nil {
+		return nil, err
+	}
+
+	return m.store.GetWebhookByID(ctx, store.LockingStrengthShare, accountID, webhookID)
+}
+
+// UpdateWebhook replaces the webhook. The stored secret is kept if the update doesn't set one.
+func (m *managerImpl) UpdateWebhook(ctx context.Context, userID string, webhook *types.Webhook) (*types.Webhook, error) {
+	if err := m.validatePermissions(ctx, webhook.AccountID, userID, permissions.Write); err != nil {
+		return nil, err
+	}
+
+	unlock := m.store.AcquireWriteLockByUID(ctx, webhook.AccountID)
+	defer unlock()
+
+	existing, err := m.store.GetWebhookByID(ctx, store.LockingStrengthUpdate, webhook.AccountID, webhook.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+
+	if webhook.Secret == "" {
+		webhook.Secret = existing.Secret
+	}
+
+	if err = validateWebhook(webhook); err != nil {
+		return nil, err
+	}
+
+	if err = m.store.SaveWebhook(ctx, store.LockingStrengthUpdate, webhook); err != nil {
+		return nil, fmt.Errorf("failed to save webhook: %w", err)
+	}
+
+	m.accountManager.StoreEvent(ctx, userID, webhook.ID, webhook.AccountID, activity.WebhookUpdated, webhook.EventMeta())
+
+	return webhook, nil
+}
+
+func (m *managerImpl) DeleteWebhook(ctx context.Context, accountID, userID, webhookID string) error {
+	if err := m.validatePermissions(ctx, accountID, userID, permissions.Write); err != nil {
+		return err
+	}
+
+	unlock := m.store.AcquireWriteLockByUID(ctx, accountID)
+	defer unlock()
+
+	webhook, err := m.store.GetWebhookByID(ctx, store.LockingStrengthUpdate, accountID, webhookID)
+	if err != nil {
+		return fmt.Errorf("failed to get webhook: %w", err)
+	}
+
+	if err = m.store.DeleteWebhook(ctx, store.LockingStrengthUpdate, accountID, webhookID); err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	m.accountManager.StoreEvent(ctx, userID, webhookID, accountID, activity.WebhookDeleted, webhook.EventMeta())
+
+	return nil
+}
+
+func (m *managerImpl) validatePermissions(ctx context.Context, accountID, userID string, operation permissions.Operation) error {
+	ok, err := m.permissionsManager.ValidateUserPermissions(ctx, accountID, userID, permissions.Webhooks, operation)
+	if err != nil {
+		return status.NewPermissionValidationError(err)
+	}
+	if !ok {
+		return status.NewPermissionDeniedError()
+	}
+	return nil
+}
+
+// validateWebhook checks that the webhook points to an HTTP endpoint and is fired on known activity events
+func validateWebhook(webhook *types.Webhook) error {
+	if strings.TrimSpace(webhook.Name) == "" {
+		return status.Errorf(status.InvalidArgument, "webhook name can't be empty")
+	}
+
+	u, err := url.Parse(webhook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return status.Errorf(status.InvalidArgument, "webhook url must be a valid http or https URL")
+	}
+
+	if webhook.MaxRetries < 0 || webhook.MaxRetries > types.MaxRetriesLimit {
+		return status.Errorf(status.InvalidArgument, "webhook max retries has to be between 0 and %d", types.MaxRetriesLimit)
+	}
+
+	for _, event := range webhook.Events {
+		if !activity.IsKnownStringCode(event) {
+			return status.Errorf(status.InvalidArgument, "unknown event %s", event)
+		}
+	}
+
+	return nil
+}
+
+func NewManagerMock() Manager {
+	return &mockManager{}
+}
+
+func (m *mockManager) GetAllWebhooks(ctx context.Context, accountID, userID string) ([]*types.Webhook, error) {
+	return []*types.Webhook{}, nil
+}
+
+func (m *mockManager) CreateWebhook(ctx context.Context, userID string, webhook *types.Webhook) (*types.Webhook, error) {
+	return webhook, nil
+}
+
+func (m *mockManager) GetWebhook(ctx context.Context, accountID, userID, webhookID string) (*types.Webhook, error) {
+	return &types.Webhook{}, nil
+}
+
+func (m *mockManager) UpdateWebhook(ctx context.Context, userID string, webhook *types.Webhook) (*types.Webhook, error) {
+	return webhook, nil
+}
+
+func (m *mockManager) DeleteWebhook(ctx context.Context, accountID, userID, webhookID string) error {
+	return nil
+}
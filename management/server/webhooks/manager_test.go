@@ -0,0 +1,62 @@
+package webhooks
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/management/server/mock_server"
+	"github.com/netbirdio/netbird/management/server/permissions"
+	"github.com/netbirdio/netbird/management/server/status"
+	"github.com/netbirdio/netbird/management/server/store"
+	"github.com/netbirdio/netbird/management/server/webhooks/types"
+)
+
+const (
+	testAccountID = "bf1c8084-ba50-4ce7-9439-34653001fc3b"
+	testAdminID   = "edafee4e-63fb-11ec-90d6-0242ac120003"
+	testRegularID = "f4f6d672-63fb-11ec-90d6-0242ac120003"
+)
+
+func newTestStore(t *testing.T) store.Store {
+	t.Helper()
+
+	s, cleanUp, err := store.NewTestStoreFromSQL(context.Background(), "../testdata/extended-store.sql", t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(cleanUp)
+
+	return s
+}
+
+func TestManager_ValidatesWebhooks(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+	manager := NewManager(s, permissions.NewManager(s), &mock_server.MockAccountManager{})
+
+	_, err := manager.CreateWebhook(ctx, testRegularID, types.NewWebhook(testAccountID, "hook", "https://example.com", "", nil, 3, true))
+	sErr, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, status.PermissionDenied, sErr.Type())
+
+	_, err = manager.CreateWebhook(ctx, testAdminID, types.NewWebhook(testAccountID, "hook", "ftp://example.com", "", nil, 3, true))
+	assert.Error(t, err, "only http endpoints are supported")
+
+	_, err = manager.CreateWebhook(ctx, testAdminID, types.NewWebhook(testAccountID, "hook", "https://example.com", "", []string{"unknown.event"}, 3, true))
+	assert.Error(t, err, "events must be known activity codes")
+
+	webhook, err := manager.CreateWebhook(ctx, testAdminID, types.NewWebhook(testAccountID, "hook", "https://example.com", "secret", []string{"peer.user.add"}, 3, true))
+	require.NoError(t, err)
+
+	update := webhook.Copy()
+	update.Secret = ""
+	update.Name = "renamed"
+	_, err = manager.UpdateWebhook(ctx, testAdminID, update)
+	require.NoError(t, err)
+
+	stored, err := manager.GetWebhook(ctx, testAccountID, testAdminID, webhook.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "renamed", stored.Name)
+	assert.Equal(t, "secret", stored.Secret, "the secret is kept when the update doesn't set it")
+}
@@ -0,0 +1,100 @@
+package types
+
+import (
+	"slices"
+
+	"github.com/rs/xid"
+
+	"github.com/netbirdio/netbird/management/server/http/api"
+)
+
+const (
+	// DefaultMaxRetries is the number of delivery retries of a webhook that doesn't define it
+	DefaultMaxRetries = 3
+	// MaxRetriesLimit is the highest number of delivery retries a webhook can define
+	MaxRetriesLimit = 10
+)
+
+// Webhook is an HTTP endpoint receiving the activity events of an account as they happen
+type Webhook struct {
+	ID        string `gorm:"primaryKey"`
+	AccountID string `gorm:"index"`
+	Name      string
+	// URL of the endpoint receiving a POST request per event, encrypted at rest as it often embeds a token
+	URL string `gorm:"serializer:encrypted"`
+	// Secret signs the request body with HMAC-SHA256 so the endpoint can verify its origin
+	Secret string `gorm:"serializer:encrypted"`
+	// Events are the activity codes the webhook is fired on, e.g. peer.user.add. Empty fires on all events
+	Events []string `gorm:"serializer:json"`
+	// MaxRetries is the number of delivery retries with exponential backoff after a failed request
+	MaxRetries int
+	Enabled    bool
+}
+
+func NewWebhook(accountID, name, url, secret string, events []string, maxRetries int, enabled bool) *Webhook {
+	return &Webhook{
+		ID:         xid.New().String(),
+		AccountID:  accountID,
+		Name:       name,
+		URL:        url,
+		Secret:     secret,
+		Events:     events,
+		MaxRetries: maxRetries,
+		Enabled:    enabled,
+	}
+}
+
+// Matches returns true if the webhook is enabled and fired on the activity event code
+func (w *Webhook) Matches(eventCode string) bool {
+	if !w.Enabled {
+		return false
+	}
+
+	return len(w.Events) == 0 || slices.Contains(w.Events, eventCode)
+}
+
+// ToAPIResponse converts the webhook to its API representation. The secret is never returned.
+func (w *Webhook) ToAPIResponse() *api.Webhook {
+	return &api.Webhook{
+		Id:         w.ID,
+		Name:       w.Name,
+		Url:        w.URL,
+		Events:     append([]string{}, w.Events...),
+		MaxRetries: w.MaxRetries,
+		Enabled:    w.Enabled,
+		HasSecret:  w.Secret != "",
+	}
+}
+
+// FromAPIRequest updates the webhook with the request. The secret is kept if the request doesn't set it.
+func (w *Webhook) FromAPIRequest(req *api.WebhookRequest) {
+	w.Name = req.Name
+	w.URL = req.Url
+	w.Enabled = req.Enabled
+
+	if req.Secret != nil {
+		w.Secret = *req.Secret
+	}
+
+	w.Events = nil
+	if req.Events != nil {
+		w.Events = *req.Events
+	}
+
+	w.MaxRetries = DefaultMaxRetries
+	if req.MaxRetries != nil {
+		w.MaxRetries = *req.MaxRetries
+	}
+}
+
+// Copy returns a copy of the webhook
+func (w *Webhook) Copy() *Webhook {
+	webhook := *w
+	webhook.Events = slices.Clone(w.Events)
+	return &webhook
+}
+
+// EventMeta returns the activity event meta of the webhook. The URL is left out as it often embeds a token, e.g. for Slack
+func (w *Webhook) EventMeta() map[string]any {
+	return map[string]any{"name": w.Name}
+}
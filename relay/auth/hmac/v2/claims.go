@@ -0,0 +1,82 @@
+package v2
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// Claims are the payload of the tokens bound to a peer. The payload of the tokens issued by older management
+// services is the expiration time only, they are accepted by any peer on any relay.
+type Claims struct {
+	// ExpiresAt is the expiration time of the token in unix seconds
+	ExpiresAt int64 `json:"exp"`
+	// Audience are the addresses of the relays the token is issued for, the token is accepted by any relay if empty
+	Audience []string `json:"aud,omitempty"`
+	// Subject is the relay peer ID, the hashed WireGuard public key, of the peer the token is issued to. The token
+	// authenticates only this ID, the holder of the token doesn't have to prove it has the key.
+	Subject string `json:"sub"`
+}
+
+// Credentials are the token presented by a peer with the relay peer ID the peer authenticates as and the addresses
+// of the relay the peer connects to, the audience of the token isn't checked without them
+type Credentials struct {
+	PeerID    string
+	Audiences []string
+	Token     []byte
+}
+
+func (c *Claims) marshal() ([]byte, error) {
+	return json.Marshal(c)
+}
+
+// isClaimsPayload tells the payloads of the tokens bound to a peer from the expiration times of the older tokens
+func isClaimsPayload(payload []byte) bool {
+	return bytes.HasPrefix(payload, []byte("{"))
+}
+
+func unmarshalClaims(payload []byte) (*Claims, error) {
+	claims := &Claims{}
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// hasAudience returns whether one of the audiences of the token is one of the addresses of the relay
+func (c *Claims) hasAudience(addresses []string) bool {
+	for _, aud := range c.Audience {
+		for _, addr := range addresses {
+			if NormalizeAddress(aud) == NormalizeAddress(addr) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NormalizeAddress returns the relay address with the default port of its scheme and a lower case host, so the
+// addresses configured in the management service match the instance URLs of the relays
+func NormalizeAddress(address string) string {
+	u, err := url.Parse(address)
+	if err != nil || u.Host == "" {
+		return strings.ToLower(address)
+	}
+
+	port := u.Port()
+	if port == "" {
+		switch u.Scheme {
+		case "rels":
+			port = "443"
+		case "rel":
+			port = "80"
+		}
+	}
+	host := strings.ToLower(u.Hostname())
+	if port != "" {
+		host = net.JoinHostPort(host, port)
+	}
+	return u.Scheme + "://" + host
+}
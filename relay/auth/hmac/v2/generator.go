@@ -43,3 +43,26 @@ func (g *Generator) GenerateToken() (*Token, error) {
 		Payload:   payload,
 	}, nil
 }
+
+// GeneratePeerToken generates a token accepted only for the relay peer ID of a peer by the relays with the
+// addresses
+func (g *Generator) GeneratePeerToken(peerID string, audience []string) (*Token, error) {
+	claims := &Claims{
+		ExpiresAt: time.Now().Add(g.timeToLive).Unix(),
+		Audience:  audience,
+		Subject:   peerID,
+	}
+	payload, err := claims.marshal()
+	if err != nil {
+		return nil, fmt.Errorf("marshal claims: %w", err)
+	}
+
+	h := hmac.New(g.algo, g.secret)
+	h.Write(payload)
+
+	return &Token{
+		AuthAlgo:  g.algoType,
+		Signature: h.Sum(nil),
+		Payload:   payload,
+	}, nil
+}
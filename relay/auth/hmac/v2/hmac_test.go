@@ -108,3 +108,102 @@ func TestInvalidPayload(t *testing.T) {
 		t.Fatalf("expected invalid token due to invalid payload")
 	}
 }
+
+func TestValidatePeerCredentials(t *testing.T) {
+	secret := "supersecret"
+	g, err := NewGenerator(AuthAlgoHMACSHA256, []byte(secret), time.Hour)
+	if err != nil {
+		t.Fatalf("failed to create generator: %v", err)
+	}
+
+	token, err := g.GeneratePeerToken("sha-peer", []string{"rels://Relay.Example.com"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	v := NewValidator([]byte(secret))
+	v.RequirePeerBinding()
+
+	tests := []struct {
+		name        string
+		credentials any
+		valid       bool
+	}{
+		{
+			name:        "bound peer and relay",
+			credentials: Credentials{PeerID: "sha-peer", Audiences: []string{"rels://relay-1.example.com:443", "rels://relay.example.com:443"}, Token: token.Marshal()},
+			valid:       true,
+		},
+		{
+			name:        "relay without audiences",
+			credentials: Credentials{PeerID: "sha-peer", Token: token.Marshal()},
+			valid:       true,
+		},
+		{
+			name:        "another peer",
+			credentials: Credentials{PeerID: "sha-other", Audiences: []string{"rels://relay.example.com:443"}, Token: token.Marshal()},
+		},
+		{
+			name:        "another relay",
+			credentials: Credentials{PeerID: "sha-peer", Audiences: []string{"rels://relay-1.example.com:443"}, Token: token.Marshal()},
+		},
+		{
+			name:        "without peer",
+			credentials: token.Marshal(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.Validate(tt.credentials)
+			if tt.valid && err != nil {
+				t.Fatalf("expected valid token: %s", err)
+			}
+			if !tt.valid && err == nil {
+				t.Fatalf("expected invalid token")
+			}
+		})
+	}
+}
+
+func TestRequirePeerBinding(t *testing.T) {
+	secret := "supersecret"
+	g, err := NewGenerator(AuthAlgoHMACSHA256, []byte(secret), time.Hour)
+	if err != nil {
+		t.Fatalf("failed to create generator: %v", err)
+	}
+
+	token, err := g.GenerateToken()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	v := NewValidator([]byte(secret))
+	credentials := Credentials{PeerID: "sha-peer", Token: token.Marshal()}
+	if err := v.Validate(credentials); err != nil {
+		t.Fatalf("expected valid token without peer binding: %s", err)
+	}
+
+	v.RequirePeerBinding()
+	if err := v.Validate(credentials); err == nil {
+		t.Fatalf("expected invalid token not bound to a peer")
+	}
+}
+
+func TestExpiredPeerToken(t *testing.T) {
+	secret := "supersecret"
+	g, err := NewGenerator(AuthAlgoHMACSHA256, []byte(secret), -time.Hour)
+	if err != nil {
+		t.Fatalf("failed to create generator: %v", err)
+	}
+
+	token, err := g.GeneratePeerToken("sha-peer", nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	v := NewValidator([]byte(secret))
+	if err := v.Validate(Credentials{PeerID: "sha-peer", Token: token.Marshal()}); err == nil {
+		t.Fatalf("expected expired token")
+	}
+}
@@ -12,19 +12,33 @@ const minLengthUnixTimestamp = 10
 
 type Validator struct {
 	secret []byte
+	// requirePeerBinding rejects the tokens not bound to a peer
+	requirePeerBinding bool
 }
 
 func NewValidator(secret []byte) *Validator {
 	return &Validator{secret: secret}
 }
 
+// RequirePeerBinding rejects the tokens issued by older management services, which aren't bound to a peer
+func (v *Validator) RequirePeerBinding() {
+	v.requirePeerBinding = true
+}
+
+// Validate validates the token of the Credentials, or the marshalled token. The tokens bound to a peer are only
+// accepted with the Credentials of the peer, and of a relay of their audience if the Credentials have audiences.
 func (v *Validator) Validate(data any) error {
-	d, ok := data.([]byte)
-	if !ok {
+	var credentials Credentials
+	switch d := data.(type) {
+	case Credentials:
+		credentials = d
+	case []byte:
+		credentials = Credentials{Token: d}
+	default:
 		return fmt.Errorf("invalid data type")
 	}
 
-	token, err := UnmarshalToken(d)
+	token, err := UnmarshalToken(credentials.Token)
 	if err != nil {
 		return fmt.Errorf("unmarshal token: %w", err)
 	}
@@ -46,6 +60,14 @@ func (v *Validator) Validate(data any) error {
 		return errors.New("invalid signature")
 	}
 
+	if isClaimsPayload(token.Payload) {
+		return validateClaims(token.Payload, credentials)
+	}
+
+	if v.requirePeerBinding {
+		return errors.New("token isn't bound to a peer")
+	}
+
 	timestamp, err := strconv.ParseInt(string(token.Payload), 10, 64)
 	if err != nil {
 		return fmt.Errorf("invalid payload: %w", err)
@@ -57,3 +79,24 @@ func (v *Validator) Validate(data any) error {
 
 	return nil
 }
+
+func validateClaims(payload []byte, credentials Credentials) error {
+	claims, err := unmarshalClaims(payload)
+	if err != nil {
+		return fmt.Errorf("invalid payload: %w", err)
+	}
+
+	if time.Now().Unix() > claims.ExpiresAt {
+		return fmt.Errorf("expired token")
+	}
+
+	if claims.Subject == "" || claims.Subject != credentials.PeerID {
+		return fmt.Errorf("token was issued to another peer")
+	}
+
+	if len(claims.Audience) > 0 && len(credentials.Audiences) > 0 && !claims.hasAudience(credentials.Audiences) {
+		return fmt.Errorf("token was issued for other relays: %v", claims.Audience)
+	}
+
+	return nil
+}
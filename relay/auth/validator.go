@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"errors"
 	"time"
 
 	auth "github.com/netbirdio/netbird/relay/auth/hmac"
@@ -26,6 +27,11 @@ func NewTimedHMACValidator(secret []byte, duration time.Duration) *TimedHMACVali
 	}
 }
 
+// RequirePeerBinding rejects the tokens not bound to a peer, the ones issued by older management services
+func (a *TimedHMACValidator) RequirePeerBinding() {
+	a.authenticatorV2.RequirePeerBinding()
+}
+
 func (a *TimedHMACValidator) Validate(credentials any) error {
 	return a.authenticatorV2.Validate(credentials)
 }
@@ -33,3 +39,37 @@ func (a *TimedHMACValidator) Validate(credentials any) error {
 func (a *TimedHMACValidator) ValidateHelloMsgType(credentials any) error {
 	return a.authenticator.Validate(credentials)
 }
+
+// MultiValidator accepts the credentials accepted by any of its validators. While a secret is rotated, the
+// credentials of the previous secret are accepted until the peers got credentials of the new one.
+type MultiValidator struct {
+	validators []Validator
+}
+
+func NewMultiValidator(validators ...Validator) *MultiValidator {
+	return &MultiValidator{validators: validators}
+}
+
+func (m *MultiValidator) Validate(credentials any) error {
+	var errs []error
+	for _, v := range m.validators {
+		err := v.Validate(credentials)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func (m *MultiValidator) ValidateHelloMsgType(credentials any) error {
+	var errs []error
+	for _, v := range m.validators {
+		err := v.ValidateHelloMsgType(credentials)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
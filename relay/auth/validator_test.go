@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	authv2 "github.com/netbirdio/netbird/relay/auth/hmac/v2"
+)
+
+func TestMultiValidator(t *testing.T) {
+	timeToLive := 1 * time.Hour
+	v := NewMultiValidator(NewTimedHMACValidator([]byte("newsecret"), timeToLive), NewTimedHMACValidator([]byte("oldsecret"), timeToLive))
+
+	for _, secret := range []string{"newsecret", "oldsecret"} {
+		g, err := authv2.NewGenerator(authv2.AuthAlgoHMACSHA256, []byte(secret), timeToLive)
+		if err != nil {
+			t.Fatalf("failed to create generator: %v", err)
+		}
+
+		token, err := g.GenerateToken()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if err := v.Validate(token.Marshal()); err != nil {
+			t.Fatalf("expected valid token of %s: %s", secret, err)
+		}
+	}
+
+	g, err := authv2.NewGenerator(authv2.AuthAlgoHMACSHA256, []byte("othersecret"), timeToLive)
+	if err != nil {
+		t.Fatalf("failed to create generator: %v", err)
+	}
+
+	token, err := g.GenerateToken()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := v.Validate(token.Marshal()); err == nil {
+		t.Fatalf("expected token of an unknown secret to be rejected")
+	}
+}
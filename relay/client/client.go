@@ -15,6 +15,7 @@ import (
 	"github.com/netbirdio/netbird/relay/client/dialer/ws"
 	"github.com/netbirdio/netbird/relay/healthcheck"
 	"github.com/netbirdio/netbird/relay/messages"
+	"github.com/netbirdio/netbird/util/faultinject"
 )
 
 const (
@@ -142,6 +143,9 @@ type Client struct {
 
 	onDisconnectListener func(string)
 	listenerMutex        sync.Mutex
+
+	// wsOnly skips the QUIC transport, for the networks permitting only TCP
+	wsOnly bool
 }
 
 // NewClient creates a new client for the relay server. The client is not connected to the server until the Connect
@@ -255,7 +259,11 @@ func (c *Client) Close() error {
 }
 
 func (c *Client) connect() error {
-	rd := dialer.NewRaceDial(c.log, c.connectionURL, quic.Dialer{}, ws.Dialer{})
+	dialers := []dialer.DialeFn{quic.Dialer{}, ws.Dialer{}}
+	if c.wsOnly {
+		dialers = []dialer.DialeFn{ws.Dialer{}}
+	}
+	rd := dialer.NewRaceDial(c.log, c.connectionURL, dialers...)
 	conn, err := rd.Dial()
 	if err != nil {
 		return err
@@ -343,6 +351,13 @@ func (c *Client) readLoop(relayConn net.Conn) {
 			break
 		}
 
+		if faultinject.Hit(faultinject.RelayKill) {
+			c.log.Warnf("killing the connection to the relay server")
+			_ = relayConn.Close()
+			c.bufPool.Put(bufPtr)
+			continue
+		}
+
 		buf = buf[:n]
 
 		_, err := messages.ValidateVersion(buf)
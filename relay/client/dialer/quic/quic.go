@@ -13,6 +13,7 @@ import (
 
 	quictls "github.com/netbirdio/netbird/relay/tls"
 	nbnet "github.com/netbirdio/netbird/util/net"
+	"github.com/netbirdio/netbird/util/pqtls"
 )
 
 type Dialer struct {
@@ -47,7 +48,7 @@ func (d Dialer) Dial(ctx context.Context, address string) (net.Conn, error) {
 		return nil, err
 	}
 
-	session, err := quic.Dial(ctx, udpConn, udpAddr, quictls.ClientQUICTLSConfig(), quicConfig)
+	session, err := quic.Dial(ctx, udpConn, udpAddr, pqtls.Apply(quictls.ClientQUICTLSConfig()), quicConfig)
 	if err != nil {
 		if errors.Is(err, context.Canceled) {
 			return nil, err
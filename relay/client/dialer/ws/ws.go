@@ -17,6 +17,7 @@ import (
 	"github.com/netbirdio/netbird/relay/server/listener/ws"
 	"github.com/netbirdio/netbird/util/embeddedroots"
 	nbnet "github.com/netbirdio/netbird/util/net"
+	"github.com/netbirdio/netbird/util/pqtls"
 )
 
 type Dialer struct {
@@ -79,9 +80,9 @@ func httpClientNbDialer() *http.Client {
 		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
 			return customDialer.DialContext(ctx, network, addr)
 		},
-		TLSClientConfig: &tls.Config{
+		TLSClientConfig: pqtls.Apply(&tls.Config{
 			RootCAs: certPool,
-		},
+		}),
 	}
 
 	return &http.Client{
@@ -97,6 +97,12 @@ func NewManager(ctx context.Context, serverURLs []string, peerID string) *Manage
 	return m
 }
 
+// UseWebSocketOnly makes the relay clients connect with WebSocket only, skipping the QUIC transport over UDP. It has to
+// be called before Serve.
+func (m *Manager) UseWebSocketOnly() {
+	m.serverPicker.WSOnly = true
+}
+
 // Serve starts the manager, attempting to establish a connection with the relay server.
 // If the connection fails, it will keep trying to reconnect in the background.
 // Additionally, it starts a cleanup loop to remove unused relay connections.
@@ -265,6 +271,7 @@ func (m *Manager) openConnVia(serverAddress, peerKey string) (net.Conn, error) {
 	m.relayClientsMutex.Unlock()
 
 	relayClient := NewClient(m.ctx, serverAddress, m.tokenStore, m.peerID)
+	relayClient.wsOnly = m.serverPicker.WSOnly
 	err := relayClient.Connect()
 	if err != nil {
 		rt.err = err
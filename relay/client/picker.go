@@ -30,6 +30,8 @@ type ServerPicker struct {
 	TokenStore *auth.TokenStore
 	ServerURLs atomic.Value
 	PeerID     string
+	// WSOnly makes the clients connect with WebSocket only
+	WSOnly bool
 }
 
 func (sp *ServerPicker) PickServer(parentCtx context.Context) (*Client, error) {
@@ -71,6 +73,7 @@ func (sp *ServerPicker) PickServer(parentCtx context.Context) (*Client, error) {
 func (sp *ServerPicker) startConnection(ctx context.Context, resultChan chan connResult, url string) {
 	log.Infof("try to connecting to relay server: %s", url)
 	relayClient := NewClient(ctx, url, sp.TokenStore, sp.PeerID)
+	relayClient.wsOnly = sp.WSOnly
 	err := relayClient.Connect()
 	resultChan <- connResult{
 		RelayClient: relayClient,
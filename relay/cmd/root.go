@@ -21,6 +21,7 @@ import (
 	"github.com/netbirdio/netbird/relay/server"
 	"github.com/netbirdio/netbird/signal/metrics"
 	"github.com/netbirdio/netbird/util"
+	"github.com/netbirdio/netbird/util/pqtls"
 )
 
 type Config struct {
@@ -35,11 +36,23 @@ type Config struct {
 	// in case of using Route 53 for DNS challenge the credentials should be provided in the environment variables or
 	// in the AWS credentials file
 	LetsencryptAWSRoute53 bool
-	TlsCertFile           string
-	TlsKeyFile            string
-	AuthSecret            string
-	LogLevel              string
-	LogFile               string
+	// LetsencryptDNSProvider enables the DNS-01 challenge with the DNS provider, e.g. route53 or rfc2136.
+	// The credentials of the provider should be provided in the environment variables.
+	LetsencryptDNSProvider string
+	TlsCertFile            string
+	TlsKeyFile             string
+	AuthSecret             string
+	// PreviousAuthSecrets are accepted besides AuthSecret while the secret is rotated, until the peers got
+	// credentials of the new secret from the management server
+	PreviousAuthSecrets []string
+	// AuthAudiences are the addresses of the relay configured in the management service, e.g. the common domain of
+	// the relays in HA setups. The tokens bound to other relays are rejected if set.
+	AuthAudiences []string
+	// AuthRequirePeerBinding rejects the tokens not bound to a peer, the ones issued by older management services or
+	// without Relay.PeerBoundTokens. Disabled by default.
+	AuthRequirePeerBinding bool
+	LogLevel               string
+	LogFile                string
 }
 
 func (c Config) Validate() error {
@@ -77,14 +90,18 @@ func init() {
 	cobraConfig = &Config{}
 	rootCmd.PersistentFlags().StringVarP(&cobraConfig.ListenAddress, "listen-address", "l", ":443", "listen address")
 	rootCmd.PersistentFlags().StringVarP(&cobraConfig.ExposedAddress, "exposed-address", "e", "", "instance domain address (or ip) and port, it will be distributes between peers")
-	rootCmd.PersistentFlags().IntVar(&cobraConfig.MetricsPort, "metrics-port", 9090, "metrics endpoint http port. Metrics are accessible under host:metrics-port/metrics")
+	rootCmd.PersistentFlags().IntVar(&cobraConfig.MetricsPort, "metrics-port", 9090, "metrics endpoint http port. Metrics are accessible under host:metrics-port/metrics, the health and the introspection under host:metrics-port/relay/health and host:metrics-port/relay/introspection")
 	rootCmd.PersistentFlags().StringVarP(&cobraConfig.LetsencryptDataDir, "letsencrypt-data-dir", "d", "", "a directory to store Let's Encrypt data. Required if Let's Encrypt is enabled.")
 	rootCmd.PersistentFlags().StringSliceVarP(&cobraConfig.LetsencryptDomains, "letsencrypt-domains", "a", nil, "list of domains to issue Let's Encrypt certificate for. Enables TLS using Let's Encrypt. Will fetch and renew certificate, and run the server with TLS")
 	rootCmd.PersistentFlags().StringVar(&cobraConfig.LetsencryptEmail, "letsencrypt-email", "", "email address to use for Let's Encrypt certificate registration")
 	rootCmd.PersistentFlags().BoolVar(&cobraConfig.LetsencryptAWSRoute53, "letsencrypt-aws-route53", false, "use AWS Route 53 for Let's Encrypt DNS challenge")
+	rootCmd.PersistentFlags().StringVar(&cobraConfig.LetsencryptDNSProvider, "letsencrypt-dns-provider", "", fmt.Sprintf("use the DNS provider for Let's Encrypt DNS challenge, one of %v. The provider credentials are read from the environment", encryption.DNSProviders()))
 	rootCmd.PersistentFlags().StringVarP(&cobraConfig.TlsCertFile, "tls-cert-file", "c", "", "")
 	rootCmd.PersistentFlags().StringVarP(&cobraConfig.TlsKeyFile, "tls-key-file", "k", "", "")
 	rootCmd.PersistentFlags().StringVarP(&cobraConfig.AuthSecret, "auth-secret", "s", "", "auth secret")
+	rootCmd.PersistentFlags().StringSliceVar(&cobraConfig.PreviousAuthSecrets, "previous-auth-secrets", nil, "previous auth secrets accepted while the auth secret is rotated")
+	rootCmd.PersistentFlags().StringSliceVar(&cobraConfig.AuthAudiences, "auth-audiences", nil, "relay addresses configured in the management service, e.g. rels://relay.example.com:443. If set, the tokens issued for other relays than these and the exposed address are rejected")
+	rootCmd.PersistentFlags().BoolVar(&cobraConfig.AuthRequirePeerBinding, "auth-require-peer-binding", false, "reject the tokens not bound to a peer (default false). Enable once every management service issues bound tokens with Relay.PeerBoundTokens, the tokens of older management services are rejected otherwise")
 	rootCmd.PersistentFlags().StringVar(&cobraConfig.LogLevel, "log-level", "info", "log level")
 	rootCmd.PersistentFlags().StringVar(&cobraConfig.LogFile, "log-file", "console", "log file")
 
@@ -136,17 +153,22 @@ func execute(cmd *cobra.Command, args []string) error {
 		log.Debugf("failed to setup TLS config: %s", err)
 		return fmt.Errorf("failed to setup TLS config: %s", err)
 	}
-	srvListenerCfg.TLSConfig = tlsConfig
+	srvListenerCfg.TLSConfig = pqtls.PreferHybrid(tlsConfig)
 
-	hashedSecret := sha256.Sum256([]byte(cobraConfig.AuthSecret))
-	authenticator := auth.NewTimedHMACValidator(hashedSecret[:], 24*time.Hour)
+	authenticator := newAuthValidator(cobraConfig.AuthSecret, cobraConfig.PreviousAuthSecrets, cobraConfig.AuthRequirePeerBinding)
 
 	srv, err := server.NewServer(metricsServer.Meter, cobraConfig.ExposedAddress, tlsSupport, authenticator)
 	if err != nil {
 		log.Debugf("failed to create relay server: %v", err)
 		return fmt.Errorf("failed to create relay server: %v", err)
 	}
+	srv.SetAudiences(cobraConfig.AuthAudiences)
 	log.Infof("server will be available on: %s", srv.InstanceURL())
+
+	introspection := srv.IntrospectionHandler()
+	metricsServer.Handle(server.HealthPath, introspection)
+	metricsServer.Handle(server.IntrospectionPath, introspection)
+
 	go func() {
 		if err := srv.Listen(srvListenerCfg); err != nil {
 			log.Fatalf("failed to bind server: %s", err)
@@ -172,14 +194,20 @@ func execute(cmd *cobra.Command, args []string) error {
 }
 
 func handleTLSConfig(cfg *Config) (*tls.Config, bool, error) {
-	if cfg.LetsencryptAWSRoute53 {
-		log.Debugf("using Let's Encrypt DNS resolver with Route 53 support")
-		r53 := encryption.Route53TLS{
-			DataDir: cfg.LetsencryptDataDir,
-			Email:   cfg.LetsencryptEmail,
-			Domains: cfg.LetsencryptDomains,
+	dnsProvider := cfg.LetsencryptDNSProvider
+	if dnsProvider == "" && cfg.LetsencryptAWSRoute53 {
+		dnsProvider = encryption.DNSProviderRoute53
+	}
+
+	if dnsProvider != "" {
+		log.Debugf("using Let's Encrypt DNS resolver with %s support", dnsProvider)
+		dns01 := encryption.DNS01TLS{
+			DataDir:  cfg.LetsencryptDataDir,
+			Email:    cfg.LetsencryptEmail,
+			Domains:  cfg.LetsencryptDomains,
+			Provider: dnsProvider,
 		}
-		tlsCfg, err := r53.GetCertificate()
+		tlsCfg, err := dns01.GetCertificate()
 		if err != nil {
 			return nil, false, fmt.Errorf("%s", err)
 		}
@@ -213,3 +241,28 @@ func setupTLSCertManager(letsencryptDataDir string, letsencryptDomains ...string
 	}
 	return certManager.TLSConfig(), nil
 }
+
+// newAuthValidator validates the credentials of the secret, or of a previous secret while the secret is rotated
+func newAuthValidator(secret string, previousSecrets []string, requirePeerBinding bool) auth.Validator {
+	newValidator := func(secret string) *auth.TimedHMACValidator {
+		hashedSecret := sha256.Sum256([]byte(secret))
+		validator := auth.NewTimedHMACValidator(hashedSecret[:], 24*time.Hour)
+		if requirePeerBinding {
+			validator.RequirePeerBinding()
+		}
+		return validator
+	}
+
+	validator := newValidator(secret)
+	if len(previousSecrets) == 0 {
+		return validator
+	}
+
+	validators := []auth.Validator{validator}
+	for _, previous := range previousSecrets {
+		validators = append(validators, newValidator(previous))
+	}
+	log.Infof("accepting credentials of %d previous auth secrets", len(previousSecrets))
+
+	return auth.NewMultiValidator(validators...)
+}
@@ -3,6 +3,7 @@ package metrics
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -16,16 +17,27 @@ const (
 type Metrics struct {
 	metric.Meter
 
-	TransferBytesSent  metric.Int64Counter
-	TransferBytesRecv  metric.Int64Counter
-	AuthenticationTime metric.Float64Histogram
-	PeerStoreTime      metric.Float64Histogram
+	TransferBytesSent      metric.Int64Counter
+	TransferBytesRecv      metric.Int64Counter
+	AuthenticationTime     metric.Float64Histogram
+	AuthenticationFailures metric.Int64Counter
+	PeerStoreTime          metric.Float64Histogram
+	FrameForwardTime       metric.Float64Histogram
 
 	peers            metric.Int64UpDownCounter
 	peerActivityChan chan string
 	peerLastActive   map[string]time.Time
 	mutexActivity    sync.Mutex
 	ctx              context.Context
+
+	// peerTransfer holds the bytes transferred per peer since they were last taken
+	peerTransfer map[string]int64
+	// sessions holds the connected peers with the bytes transferred since they connected
+	sessions   map[string]*session
+	transferMu sync.Mutex
+
+	authFailures atomic.Int64
+	frameTimes   *frameSamples
 }
 
 func NewMetrics(ctx context.Context, meter metric.Meter) (*Metrics, error) {
@@ -72,6 +84,13 @@ func NewMetrics(ctx context.Context, meter metric.Meter) (*Metrics, error) {
 		return nil, err
 	}
 
+	authFailures, err := meter.Int64Counter("relay_peer_authentication_failures_total",
+		metric.WithDescription("Total number of peers failing the authentication"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	peerStoreTime, err := meter.Float64Histogram("relay_peer_store_time_milliseconds",
 		metric.WithExplicitBucketBoundaries(getStandardBucketBoundaries()...),
 		metric.WithDescription("Time taken to store a new peer connection"),
@@ -80,17 +99,30 @@ func NewMetrics(ctx context.Context, meter metric.Meter) (*Metrics, error) {
 		return nil, err
 	}
 
+	frameForwardTime, err := meter.Float64Histogram("relay_frame_forward_time_milliseconds",
+		metric.WithExplicitBucketBoundaries(getFrameBucketBoundaries()...),
+		metric.WithDescription("Time taken to forward a transport frame to the destination peer"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	m := &Metrics{
-		Meter:              meter,
-		TransferBytesSent:  bytesSent,
-		TransferBytesRecv:  bytesRecv,
-		AuthenticationTime: authTime,
-		PeerStoreTime:      peerStoreTime,
-		peers:              peers,
+		Meter:                  meter,
+		TransferBytesSent:      bytesSent,
+		TransferBytesRecv:      bytesRecv,
+		AuthenticationTime:     authTime,
+		AuthenticationFailures: authFailures,
+		PeerStoreTime:          peerStoreTime,
+		FrameForwardTime:       frameForwardTime,
+		peers:                  peers,
 
 		ctx:              ctx,
 		peerActivityChan: make(chan string, 10),
 		peerLastActive:   make(map[string]time.Time),
+		peerTransfer:     make(map[string]int64),
+		sessions:         make(map[string]*session),
+		frameTimes:       newFrameSamples(),
 	}
 
 	_, err = meter.RegisterCallback(
@@ -114,9 +146,12 @@ func NewMetrics(ctx context.Context, meter metric.Meter) (*Metrics, error) {
 func (m *Metrics) PeerConnected(id string) {
 	m.peers.Add(m.ctx, 1)
 	m.mutexActivity.Lock()
-	defer m.mutexActivity.Unlock()
-
 	m.peerLastActive[id] = time.Time{}
+	m.mutexActivity.Unlock()
+
+	m.transferMu.Lock()
+	m.sessions[id] = &session{connectedAt: time.Now()}
+	m.transferMu.Unlock()
 }
 
 // RecordAuthenticationTime measures the time taken for peer authentication
@@ -124,6 +159,18 @@ func (m *Metrics) RecordAuthenticationTime(duration time.Duration) {
 	m.AuthenticationTime.Record(m.ctx, float64(duration.Nanoseconds())/1e6)
 }
 
+// AuthenticationFailed counts a peer failing the authentication
+func (m *Metrics) AuthenticationFailed() {
+	m.AuthenticationFailures.Add(m.ctx, 1)
+	m.authFailures.Add(1)
+}
+
+// RecordFrameForwardTime measures the time taken to forward a transport frame
+func (m *Metrics) RecordFrameForwardTime(duration time.Duration) {
+	m.FrameForwardTime.Record(m.ctx, float64(duration.Nanoseconds())/1e6)
+	m.frameTimes.add(duration)
+}
+
 // RecordPeerStoreTime measures the time to store the peer in map
 func (m *Metrics) RecordPeerStoreTime(duration time.Duration) {
 	m.PeerStoreTime.Record(m.ctx, float64(duration.Nanoseconds())/1e6)
@@ -133,9 +180,12 @@ func (m *Metrics) RecordPeerStoreTime(duration time.Duration) {
 func (m *Metrics) PeerDisconnected(id string) {
 	m.peers.Add(m.ctx, -1)
 	m.mutexActivity.Lock()
-	defer m.mutexActivity.Unlock()
-
 	delete(m.peerLastActive, id)
+	m.mutexActivity.Unlock()
+
+	m.transferMu.Lock()
+	delete(m.sessions, id)
+	m.transferMu.Unlock()
 }
 
 // PeerActivity increases the active connections
@@ -147,6 +197,36 @@ func (m *Metrics) PeerActivity(peerID string) {
 	}
 }
 
+// PeerReceived adds the bytes received from the peer to its transferred bytes
+func (m *Metrics) PeerReceived(peerID string, bytes int64) {
+	m.transferMu.Lock()
+	m.peerTransfer[peerID] += bytes
+	if s, ok := m.sessions[peerID]; ok {
+		s.bytesReceived += bytes
+	}
+	m.transferMu.Unlock()
+}
+
+// PeerSent adds the bytes sent to the peer to its transferred bytes
+func (m *Metrics) PeerSent(peerID string, bytes int64) {
+	m.transferMu.Lock()
+	m.peerTransfer[peerID] += bytes
+	if s, ok := m.sessions[peerID]; ok {
+		s.bytesSent += bytes
+	}
+	m.transferMu.Unlock()
+}
+
+// TakePeerTransfer returns the bytes transferred per peer since the last call, e.g. for usage accounting
+func (m *Metrics) TakePeerTransfer() map[string]int64 {
+	m.transferMu.Lock()
+	defer m.transferMu.Unlock()
+
+	transfer := m.peerTransfer
+	m.peerTransfer = make(map[string]int64, len(transfer))
+	return transfer
+}
+
 func (m *Metrics) calculateActiveIdleConnections() (int64, int64) {
 	active, idle := int64(0), int64(0)
 	m.mutexActivity.Lock()
@@ -175,6 +255,22 @@ func (m *Metrics) readPeerActivity() {
 	}
 }
 
+// getFrameBucketBoundaries returns the buckets of the frame forward time, a frame is usually forwarded in
+// microseconds
+func getFrameBucketBoundaries() []float64 {
+	return []float64{
+		0.01,
+		0.05,
+		0.1,
+		0.5,
+		1,
+		5,
+		10,
+		50,
+		100,
+	}
+}
+
 func getStandardBucketBoundaries() []float64 {
 	return []float64{
 		0.1,
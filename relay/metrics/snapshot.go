@@ -0,0 +1,128 @@
+package metrics
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// frameSampleSize is the number of recent frame forward times the percentiles are calculated from
+	frameSampleSize = 1024
+	// frameSampleRate samples every nth frame, the forward times are recorded on the hot path
+	frameSampleRate = 16
+)
+
+type session struct {
+	connectedAt   time.Time
+	bytesSent     int64
+	bytesReceived int64
+}
+
+// Session is a peer connected to the relay
+type Session struct {
+	PeerID        string    `json:"peerId"`
+	ConnectedAt   time.Time `json:"connectedAt"`
+	LastActive    time.Time `json:"lastActive,omitempty"`
+	BytesSent     int64     `json:"bytesSent"`
+	BytesReceived int64     `json:"bytesReceived"`
+}
+
+// Percentiles are the percentiles of the recent frame forward times in milliseconds
+type Percentiles struct {
+	P50     float64 `json:"p50"`
+	P90     float64 `json:"p90"`
+	P99     float64 `json:"p99"`
+	Samples int     `json:"samples"`
+}
+
+// Snapshot is the state of the relay at a point in time
+type Snapshot struct {
+	Sessions               []Session   `json:"sessions"`
+	AuthenticationFailures int64       `json:"authenticationFailures"`
+	FrameForwardTime       Percentiles `json:"frameForwardTime"`
+}
+
+// Snapshot returns the connected peers, the number of authentication failures and the percentiles of the recent
+// frame forward times
+func (m *Metrics) Snapshot() Snapshot {
+	m.transferMu.Lock()
+	sessions := make([]Session, 0, len(m.sessions))
+	for id, s := range m.sessions {
+		sessions = append(sessions, Session{
+			PeerID:        id,
+			ConnectedAt:   s.connectedAt,
+			BytesSent:     s.bytesSent,
+			BytesReceived: s.bytesReceived,
+		})
+	}
+	m.transferMu.Unlock()
+
+	m.mutexActivity.Lock()
+	for i := range sessions {
+		sessions[i].LastActive = m.peerLastActive[sessions[i].PeerID]
+	}
+	m.mutexActivity.Unlock()
+
+	slices.SortFunc(sessions, func(a, b Session) int {
+		return a.ConnectedAt.Compare(b.ConnectedAt)
+	})
+
+	return Snapshot{
+		Sessions:               sessions,
+		AuthenticationFailures: m.authFailures.Load(),
+		FrameForwardTime:       m.frameTimes.percentiles(),
+	}
+}
+
+// frameSamples keeps a ring of sampled frame forward times
+type frameSamples struct {
+	frames atomic.Uint64
+
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+}
+
+func newFrameSamples() *frameSamples {
+	return &frameSamples{samples: make([]time.Duration, 0, frameSampleSize)}
+}
+
+func (f *frameSamples) add(duration time.Duration) {
+	if f.frames.Add(1)%frameSampleRate != 1 {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.samples) < frameSampleSize {
+		f.samples = append(f.samples, duration)
+		return
+	}
+	f.samples[f.next] = duration
+	f.next = (f.next + 1) % frameSampleSize
+}
+
+func (f *frameSamples) percentiles() Percentiles {
+	f.mu.Lock()
+	samples := slices.Clone(f.samples)
+	f.mu.Unlock()
+
+	if len(samples) == 0 {
+		return Percentiles{}
+	}
+	slices.Sort(samples)
+
+	percentile := func(p int) float64 {
+		i := (len(samples)*p+99)/100 - 1
+		return float64(samples[max(i, 0)].Nanoseconds()) / 1e6
+	}
+	return Percentiles{
+		P50:     percentile(50),
+		P90:     percentile(90),
+		P99:     percentile(99),
+		Samples: len(samples),
+	}
+}
@@ -7,6 +7,7 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/netbirdio/netbird/relay/auth"
+	authv2 "github.com/netbirdio/netbird/relay/auth/hmac/v2"
 	"github.com/netbirdio/netbird/relay/messages"
 	//nolint:staticcheck
 	"github.com/netbirdio/netbird/relay/messages/address"
@@ -14,6 +15,19 @@ import (
 	authmsg "github.com/netbirdio/netbird/relay/messages/auth"
 )
 
+// authError is returned by the handshake when the peer fails the authentication
+type authError struct {
+	err error
+}
+
+func (e authError) Error() string {
+	return e.err.Error()
+}
+
+func (e authError) Unwrap() error {
+	return e.err
+}
+
 // preparedMsg contains the marshalled success response messages
 type preparedMsg struct {
 	responseHelloMsg []byte
@@ -56,6 +70,8 @@ type handshake struct {
 	conn        net.Conn
 	validator   auth.Validator
 	preparedMsg *preparedMsg
+	// audiences are the addresses of the relay, the tokens bound to other relays are rejected
+	audiences []string
 
 	handshakeMethodAuth bool
 	peerID              string
@@ -133,7 +149,7 @@ func (h *handshake) handleHelloMsg(buf []byte) ([]byte, string, error) {
 
 	//nolint:staticcheck
 	if err := h.validator.ValidateHelloMsgType(authMsg.AdditionalData); err != nil {
-		return nil, "", fmt.Errorf("validate %s (%s): %w", peerID, h.conn.RemoteAddr(), err)
+		return nil, "", fmt.Errorf("validate %s (%s): %w", peerID, h.conn.RemoteAddr(), authError{err})
 	}
 
 	return rawPeerID, peerID, nil
@@ -147,8 +163,13 @@ func (h *handshake) handleAuthMsg(buf []byte) ([]byte, string, error) {
 
 	peerID := messages.HashIDToString(rawPeerID)
 
-	if err := h.validator.Validate(authPayload); err != nil {
-		return nil, "", fmt.Errorf("validate %s (%s): %w", peerID, h.conn.RemoteAddr(), err)
+	credentials := authv2.Credentials{
+		PeerID:    peerID,
+		Audiences: h.audiences,
+		Token:     authPayload,
+	}
+	if err := h.validator.Validate(credentials); err != nil {
+		return nil, "", fmt.Errorf("validate %s (%s): %w", peerID, h.conn.RemoteAddr(), authError{err})
 	}
 
 	return rawPeerID, peerID, nil
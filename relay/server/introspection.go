@@ -0,0 +1,150 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/relay/metrics"
+)
+
+const (
+	// HealthPath is the path of the health endpoint, it fails while the relay shuts down
+	HealthPath = "/relay/health"
+	// IntrospectionPath is the path of the introspection endpoint, it returns the sessions, the traffic per peer and
+	// per account, the authentication failures and the frame forward time percentiles
+	IntrospectionPath = "/relay/introspection"
+
+	introspectionTimeout = 10 * time.Second
+)
+
+// AccountResolver maps the relay peer IDs, i.e. the hashed WireGuard public keys, to the accounts of the peers. The
+// relay has no notion of accounts, they are known where the relay is embedded into the management server.
+type AccountResolver interface {
+	RelayPeerAccounts(ctx context.Context, relayPeerIDs []string) map[string]string
+}
+
+// Health is the state returned by the health endpoint
+type Health struct {
+	Status      string `json:"status"`
+	InstanceURL string `json:"instanceUrl"`
+	Sessions    int    `json:"sessions"`
+}
+
+// AccountStats aggregates the sessions of the peers of an account
+type AccountStats struct {
+	AccountID     string `json:"accountId"`
+	Sessions      int    `json:"sessions"`
+	BytesSent     int64  `json:"bytesSent"`
+	BytesReceived int64  `json:"bytesReceived"`
+}
+
+// Introspection is the state returned by the introspection endpoint
+type Introspection struct {
+	metrics.Snapshot
+	InstanceURL string `json:"instanceUrl"`
+	// Accounts is set when the accounts of the peers are known
+	Accounts []AccountStats `json:"accounts,omitempty"`
+}
+
+// SetAccountResolver sets the resolver of the accounts of the peers, the introspection aggregates the sessions per
+// account with it
+func (r *Server) SetAccountResolver(resolver AccountResolver) {
+	r.relay.accountsMu.Lock()
+	r.relay.accounts = resolver
+	r.relay.accountsMu.Unlock()
+}
+
+// IntrospectionHandler returns the handler of the health and the introspection endpoints. The sessions expose the
+// peer IDs and the traffic of the peers, the handler is meant for a private listener, e.g. the metrics one.
+func (r *Server) IntrospectionHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(HealthPath, r.relay.serveHealth)
+	mux.HandleFunc(IntrospectionPath, r.relay.serveIntrospection)
+	return mux
+}
+
+func (r *Relay) serveHealth(w http.ResponseWriter, _ *http.Request) {
+	r.closeMu.RLock()
+	closed := r.closed
+	r.closeMu.RUnlock()
+
+	health := Health{
+		Status:      "ok",
+		InstanceURL: r.instanceURL,
+		Sessions:    len(r.store.Peers()),
+	}
+	status := http.StatusOK
+	if closed {
+		health.Status = "shutting down"
+		status = http.StatusServiceUnavailable
+	}
+
+	writeJSON(w, status, health)
+}
+
+func (r *Relay) serveIntrospection(w http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), introspectionTimeout)
+	defer cancel()
+
+	snapshot := r.metrics.Snapshot()
+
+	r.accountsMu.RLock()
+	resolver := r.accounts
+	r.accountsMu.RUnlock()
+
+	introspection := Introspection{
+		Snapshot:    snapshot,
+		InstanceURL: r.instanceURL,
+	}
+	if resolver != nil {
+		peerIDs := make([]string, 0, len(snapshot.Sessions))
+		for _, s := range snapshot.Sessions {
+			peerIDs = append(peerIDs, s.PeerID)
+		}
+		introspection.Accounts = aggregateAccounts(snapshot.Sessions, resolver.RelayPeerAccounts(ctx, peerIDs))
+	}
+
+	writeJSON(w, http.StatusOK, introspection)
+}
+
+// aggregateAccounts sums the sessions per account, the sessions of unknown peers are left out
+func aggregateAccounts(sessions []metrics.Session, peerAccounts map[string]string) []AccountStats {
+	byAccount := make(map[string]*AccountStats)
+	for _, s := range sessions {
+		accountID, ok := peerAccounts[s.PeerID]
+		if !ok {
+			continue
+		}
+		stats, ok := byAccount[accountID]
+		if !ok {
+			stats = &AccountStats{AccountID: accountID}
+			byAccount[accountID] = stats
+		}
+		stats.Sessions++
+		stats.BytesSent += s.BytesSent
+		stats.BytesReceived += s.BytesReceived
+	}
+
+	accounts := make([]AccountStats, 0, len(byAccount))
+	for _, stats := range byAccount {
+		accounts = append(accounts, *stats)
+	}
+	slices.SortFunc(accounts, func(a, b AccountStats) int {
+		return strings.Compare(a.AccountID, b.AccountID)
+	})
+	return accounts
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Debugf("failed to write the relay introspection response: %v", err)
+	}
+}
@@ -0,0 +1,29 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/netbirdio/netbird/relay/metrics"
+)
+
+func TestAggregateAccounts(t *testing.T) {
+	sessions := []metrics.Session{
+		{PeerID: "peer-a1", BytesSent: 100, BytesReceived: 10},
+		{PeerID: "peer-b1", BytesSent: 5, BytesReceived: 50},
+		{PeerID: "peer-a2", BytesSent: 200, BytesReceived: 20},
+		{PeerID: "peer-unknown", BytesSent: 1000, BytesReceived: 1000},
+	}
+	peerAccounts := map[string]string{
+		"peer-a1": "account-a",
+		"peer-a2": "account-a",
+		"peer-b1": "account-b",
+	}
+
+	expected := []AccountStats{
+		{AccountID: "account-a", Sessions: 2, BytesSent: 300, BytesReceived: 30},
+		{AccountID: "account-b", Sessions: 1, BytesSent: 5, BytesReceived: 50},
+	}
+	assert.Equal(t, expected, aggregateAccounts(sessions, peerAccounts))
+}
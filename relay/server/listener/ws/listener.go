@@ -21,14 +21,28 @@ type Listener struct {
 	// TLSConfig is the TLS configuration for the server.
 	TLSConfig *tls.Config
 
-	server   *http.Server
+	server *http.Server
+}
+
+// Handler accepts the WebSocket connections of an HTTP server, which can be shared with other services
+type Handler struct {
+	// address is the local address of the HTTP server
+	address  string
 	acceptFn func(conn net.Conn)
 }
 
+// NewHandler returns a Handler passing the accepted connections to acceptFn. The address is the local address of
+// the HTTP server serving the handler under URLPath.
+func NewHandler(address string, acceptFn func(conn net.Conn)) *Handler {
+	return &Handler{
+		address:  address,
+		acceptFn: acceptFn,
+	}
+}
+
 func (l *Listener) Listen(acceptFn func(conn net.Conn)) error {
-	l.acceptFn = acceptFn
 	mux := http.NewServeMux()
-	mux.HandleFunc(URLPath, l.onAccept)
+	mux.Handle(URLPath, NewHandler(l.Address, acceptFn))
 
 	l.server = &http.Server{
 		Addr:      l.Address,
@@ -62,7 +76,7 @@ func (l *Listener) Shutdown(ctx context.Context) error {
 	return nil
 }
 
-func (l *Listener) onAccept(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	connRemoteAddr := remoteAddr(r)
 	wsConn, err := websocket.Accept(w, r, nil)
 	if err != nil {
@@ -79,7 +93,7 @@ func (l *Listener) onAccept(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	lAddr, err := net.ResolveTCPAddr("tcp", l.server.Addr)
+	lAddr, err := net.ResolveTCPAddr("tcp", h.address)
 	if err != nil {
 		err = wsConn.Close(websocket.StatusInternalError, "internal error")
 		if err != nil {
@@ -91,7 +105,7 @@ func (l *Listener) onAccept(w http.ResponseWriter, r *http.Request) {
 	log.Infof("WS client connected from: %s", rAddr)
 
 	conn := NewConn(wsConn, lAddr, rAddr)
-	l.acceptFn(conn)
+	h.acceptFn(conn)
 }
 
 func remoteAddr(r *http.Request) string {
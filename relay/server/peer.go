@@ -100,6 +100,7 @@ func (p *Peer) handleMsgType(ctx context.Context, msgType messages.MsgType, hc *
 		hc.OnHCResponse()
 	case messages.MsgTypeTransport:
 		p.metrics.TransferBytesRecv.Add(ctx, int64(n))
+		p.metrics.PeerReceived(p.String(), int64(n))
 		p.metrics.PeerActivity(p.String())
 		p.handleTransportMsg(msg)
 	case messages.MsgTypeClose:
@@ -191,6 +192,7 @@ func (p *Peer) handleHealthcheckEvents(ctx context.Context, hc *healthcheck.Send
 }
 
 func (p *Peer) handleTransportMsg(msg []byte) {
+	start := time.Now()
 	peerID, err := messages.UnmarshalTransportID(msg)
 	if err != nil {
 		p.log.Errorf("failed to unmarshal transport message: %s", err)
@@ -216,4 +218,6 @@ func (p *Peer) handleTransportMsg(msg []byte) {
 		return
 	}
 	p.metrics.TransferBytesSent.Add(context.Background(), int64(n))
+	p.metrics.PeerSent(dp.String(), int64(n))
+	p.metrics.RecordFrameForwardTime(time.Since(start))
 }
@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"net/url"
@@ -29,6 +30,14 @@ type Relay struct {
 
 	closed  bool
 	closeMu sync.RWMutex
+
+	accounts   AccountResolver
+	accountsMu sync.RWMutex
+
+	// audiences are the addresses of the relay accepted in the audience of the tokens, the instance URL and the
+	// addresses the peers get from the management service. The audience isn't checked if they aren't set.
+	audiences   []string
+	audiencesMu sync.RWMutex
 }
 
 // NewRelay creates a new Relay instance
@@ -102,6 +111,19 @@ func getInstanceURL(exposedAddress string, tlsSupported bool) (string, error) {
 	return parsedURL.String(), nil
 }
 
+// SetAudiences sets the addresses of the relay the peers get from the management service, e.g. the common domain of
+// the relays in HA setups. Only the tokens issued for the addresses or for the instance URL are accepted once set.
+func (r *Relay) SetAudiences(addresses []string) {
+	var audiences []string
+	if len(addresses) > 0 {
+		audiences = append([]string{r.instanceURL}, addresses...)
+	}
+
+	r.audiencesMu.Lock()
+	r.audiences = audiences
+	r.audiencesMu.Unlock()
+}
+
 // Accept start to handle a new peer connection
 func (r *Relay) Accept(conn net.Conn) {
 	acceptTime := time.Now()
@@ -111,13 +133,21 @@ func (r *Relay) Accept(conn net.Conn) {
 		return
 	}
 
+	r.audiencesMu.RLock()
+	audiences := r.audiences
+	r.audiencesMu.RUnlock()
+
 	h := handshake{
 		conn:        conn,
 		validator:   r.validator,
 		preparedMsg: r.preparedMsg,
+		audiences:   audiences,
 	}
 	peerID, err := h.handshakeReceive()
 	if err != nil {
+		if errors.As(err, &authError{}) {
+			r.metrics.AuthenticationFailed()
+		}
 		log.Errorf("failed to handshake: %s", err)
 		if cErr := conn.Close(); cErr != nil {
 			log.Errorf("failed to close connection, %s: %s", conn.RemoteAddr(), cErr)
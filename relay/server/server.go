@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"crypto/tls"
+	"net/http"
 	"sync"
 
 	"github.com/hashicorp/go-multierror"
@@ -57,18 +58,40 @@ func (r *Server) Listen(cfg ListenerConfig) error {
 	}
 	r.listeners = append(r.listeners, wSListener)
 
+	r.addQUICListener(cfg)
+
+	return r.listen()
+}
+
+// ListenQUIC starts only the QUIC listener of the relay server. The WebSocket connections are accepted by the
+// handler returned by HTTPHandler, which is served by an HTTP server shared with other services.
+func (r *Server) ListenQUIC(cfg ListenerConfig) error {
+	r.addQUICListener(cfg)
+	return r.listen()
+}
+
+// HTTPHandler returns the handler accepting the WebSocket connections under ws.URLPath on an HTTP server shared
+// with other services. The address is the local address of the shared server.
+func (r *Server) HTTPHandler(address string) http.Handler {
+	return ws.NewHandler(address, r.relay.Accept)
+}
+
+func (r *Server) addQUICListener(cfg ListenerConfig) {
 	tlsConfigQUIC, err := quictls.ServerQUICTLSConfig(cfg.TLSConfig)
 	if err != nil {
 		log.Warnf("Not starting QUIC listener: %v", err)
-	} else {
-		quicListener := &quic.Listener{
-			Address:   cfg.Address,
-			TLSConfig: tlsConfigQUIC,
-		}
+		return
+	}
 
-		r.listeners = append(r.listeners, quicListener)
+	quicListener := &quic.Listener{
+		Address:   cfg.Address,
+		TLSConfig: tlsConfigQUIC,
 	}
 
+	r.listeners = append(r.listeners, quicListener)
+}
+
+func (r *Server) listen() error {
 	errChan := make(chan error, len(r.listeners))
 	wg := sync.WaitGroup{}
 	for _, l := range r.listeners {
@@ -107,3 +130,14 @@ func (r *Server) Shutdown(ctx context.Context) error {
 func (r *Server) InstanceURL() string {
 	return r.relay.instanceURL
 }
+
+// SetAudiences sets the addresses of the relay the peers get from the management service, only the tokens issued
+// for them or for the instance URL are accepted once set
+func (r *Server) SetAudiences(addresses []string) {
+	r.relay.SetAudiences(addresses)
+}
+
+// TakePeerTransfer returns the bytes relayed per peer since the last call, keyed by the hashed peer ID
+func (r *Server) TakePeerTransfer() map[string]int64 {
+	return r.relay.metrics.TakePeerTransfer()
+}
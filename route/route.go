@@ -2,6 +2,7 @@ package route
 
 import (
 	"fmt"
+	"maps"
 	"net/netip"
 	"slices"
 	"strings"
@@ -22,6 +23,8 @@ const (
 	MaxMetric = 9999
 	// MaxNetIDChar Max Network Identifier
 	MaxNetIDChar = 40
+	// MaxDNSServers is the max number of the DNS servers of a route
+	MaxDNSServers = 3
 )
 
 const (
@@ -103,6 +106,14 @@ type Route struct {
 	Enabled             bool
 	Groups              []string `gorm:"serializer:json"`
 	AccessControlGroups []string `gorm:"serializer:json"`
+	// Labels are the key/value labels set by users, the policies can select the routes by their labels
+	Labels map[string]string `gorm:"serializer:json"`
+	// DNSDomains are the domains of the routed network, the clients of the route resolve them with the DNSServers
+	DNSDomains []string `gorm:"serializer:json"`
+	// DNSServers are the nameservers in the routed network resolving the DNSDomains
+	DNSServers []netip.Addr `gorm:"serializer:json"`
+	// DNSSearchDomainsEnabled adds the DNSDomains to the search domains of the clients
+	DNSSearchDomainsEnabled bool
 }
 
 // EventMeta returns activity event meta related to the route
@@ -128,6 +139,11 @@ func (r *Route) Copy() *Route {
 		Enabled:             r.Enabled,
 		Groups:              slices.Clone(r.Groups),
 		AccessControlGroups: slices.Clone(r.AccessControlGroups),
+		Labels:              maps.Clone(r.Labels),
+
+		DNSDomains:              slices.Clone(r.DNSDomains),
+		DNSServers:              slices.Clone(r.DNSServers),
+		DNSSearchDomainsEnabled: r.DNSSearchDomainsEnabled,
 	}
 	return route
 }
@@ -154,7 +170,51 @@ func (r *Route) Equal(other *Route) bool {
 		other.Enabled == r.Enabled &&
 		slices.Equal(r.Groups, other.Groups) &&
 		slices.Equal(r.PeerGroups, other.PeerGroups) &&
-		slices.Equal(r.AccessControlGroups, other.AccessControlGroups)
+		slices.Equal(r.AccessControlGroups, other.AccessControlGroups) &&
+		maps.Equal(r.Labels, other.Labels) &&
+		slices.Equal(r.DNSDomains, other.DNSDomains) &&
+		slices.Equal(r.DNSServers, other.DNSServers) &&
+		other.DNSSearchDomainsEnabled == r.DNSSearchDomainsEnabled
+}
+
+// HasDNS returns true if the route carries the DNS configuration of the routed network
+func (r *Route) HasDNS() bool {
+	return len(r.DNSDomains) > 0 && len(r.DNSServers) > 0
+}
+
+// ValidateDNS checks the DNS configuration of the routed network, the DNS servers have to be in the network so the
+// clients reach them through the route
+func (r *Route) ValidateDNS() error {
+	if len(r.DNSDomains) == 0 && len(r.DNSServers) == 0 {
+		if r.DNSSearchDomainsEnabled {
+			return status.Errorf(status.InvalidArgument, "search domains are enabled but the route has no DNS domains")
+		}
+		return nil
+	}
+
+	if len(r.Domains) > 0 {
+		return status.Errorf(status.InvalidArgument, "DNS domains can't be set for a route of domains")
+	}
+
+	if len(r.DNSDomains) == 0 || len(r.DNSServers) == 0 {
+		return status.Errorf(status.InvalidArgument, "both the DNS domains and the DNS servers of the route should be provided")
+	}
+
+	if len(r.DNSServers) > MaxDNSServers {
+		return status.Errorf(status.InvalidArgument, "the list of DNS servers should be 1 to %d, got %d", MaxDNSServers, len(r.DNSServers))
+	}
+
+	if _, err := domain.ValidateDomainsStrSlice(r.DNSDomains); err != nil {
+		return status.Errorf(status.InvalidArgument, "invalid DNS domains: %v", err)
+	}
+
+	for _, server := range r.DNSServers {
+		if !r.Network.Contains(server) {
+			return status.Errorf(status.InvalidArgument, "DNS server %s isn't in the routed network %s", server, r.Network)
+		}
+	}
+
+	return nil
 }
 
 // IsDynamic returns if the route is dynamic, i.e. has domains
@@ -162,6 +222,11 @@ func (r *Route) IsDynamic() bool {
 	return r.NetworkType == DomainNetwork
 }
 
+// IsDefault returns if the route is a default route, i.e. the route of an exit node
+func (r *Route) IsDefault() bool {
+	return !r.IsDynamic() && r.Network.Bits() == 0
+}
+
 func (r *Route) GetHAUniqueID() HAUniqueID {
 	if r.IsDynamic() {
 		domains, err := r.Domains.String()
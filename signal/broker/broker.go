@@ -0,0 +1,181 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	log "github.com/sirupsen/logrus"
+	gproto "google.golang.org/protobuf/proto"
+
+	"github.com/netbirdio/netbird/signal/proto"
+)
+
+const (
+	channelPrefix = "netbird.signal.peer."
+	pingTimeout   = 5 * time.Second
+)
+
+// Handler delivers a message received from another signal instance to the peer connected to this one
+type Handler func(ctx context.Context, msg *proto.EncryptedMessage)
+
+// Broker forwards the messages between the signal instances, so that peers connected to different instances
+// can exchange offers. Each instance subscribes to the keys of its connected peers.
+type Broker interface {
+	// Subscribe starts receiving the messages addressed to the peer
+	Subscribe(ctx context.Context, peerID string, handler Handler) error
+	// Unsubscribe stops receiving the messages addressed to the peer once all its streams are closed
+	Unsubscribe(ctx context.Context, peerID string) error
+	// Publish sends the message to the instances the remote peer is connected to.
+	// Returns false if the remote peer isn't connected to any instance.
+	Publish(ctx context.Context, msg *proto.EncryptedMessage) (bool, error)
+	// Close stops receiving messages and releases the connection
+	Close() error
+}
+
+// subscription of a peer, a peer may have more than one stream open while it reconnects
+type subscription struct {
+	handler Handler
+	streams int
+}
+
+// RedisBroker forwards the messages through Redis pub/sub using a channel per peer key
+type RedisBroker struct {
+	client *redis.Client
+	pubsub *redis.PubSub
+
+	mu            sync.Mutex
+	subscriptions map[string]*subscription
+}
+
+// NewRedisBroker connects to the Redis server of the address and starts receiving the messages of the subscribed peers.
+// The address follows the Redis URL format, e.g. redis://redis:6379/0
+func NewRedisBroker(ctx context.Context, address string) (*RedisBroker, error) {
+	options, err := redis.ParseURL(address)
+	if err != nil {
+		return nil, fmt.Errorf("parsing redis url: %w", err)
+	}
+
+	client := redis.NewClient(options)
+
+	pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+	if err = client.Ping(pingCtx).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("connecting to redis: %w", err)
+	}
+
+	b := &RedisBroker{
+		client:        client,
+		pubsub:        client.Subscribe(ctx),
+		subscriptions: make(map[string]*subscription),
+	}
+
+	go b.receive(ctx)
+
+	log.Infof("forwarding messages between signal instances through redis at %s", options.Addr)
+
+	return b, nil
+}
+
+func channelName(peerID string) string {
+	return channelPrefix + peerID
+}
+
+// Subscribe starts receiving the messages addressed to the peer
+func (b *RedisBroker) Subscribe(ctx context.Context, peerID string, handler Handler) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub, ok := b.subscriptions[peerID]
+	if ok {
+		sub.handler = handler
+		sub.streams++
+		return nil
+	}
+
+	if err := b.pubsub.Subscribe(ctx, channelName(peerID)); err != nil {
+		return fmt.Errorf("subscribe peer %s: %w", peerID, err)
+	}
+
+	b.subscriptions[peerID] = &subscription{handler: handler, streams: 1}
+
+	return nil
+}
+
+// Unsubscribe stops receiving the messages addressed to the peer once all its streams are closed
+func (b *RedisBroker) Unsubscribe(ctx context.Context, peerID string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub, ok := b.subscriptions[peerID]
+	if !ok {
+		return nil
+	}
+
+	sub.streams--
+	if sub.streams > 0 {
+		return nil
+	}
+
+	delete(b.subscriptions, peerID)
+
+	if err := b.pubsub.Unsubscribe(ctx, channelName(peerID)); err != nil {
+		return fmt.Errorf("unsubscribe peer %s: %w", peerID, err)
+	}
+
+	return nil
+}
+
+// Publish sends the message to the instances the remote peer is connected to
+func (b *RedisBroker) Publish(ctx context.Context, msg *proto.EncryptedMessage) (bool, error) {
+	payload, err := gproto.Marshal(msg)
+	if err != nil {
+		return false, fmt.Errorf("encode message: %w", err)
+	}
+
+	receivers, err := b.client.Publish(ctx, channelName(msg.RemoteKey), payload).Result()
+	if err != nil {
+		return false, fmt.Errorf("publish message: %w", err)
+	}
+
+	return receivers > 0, nil
+}
+
+// receive delivers the messages of the subscribed channels to the handlers of their peers until the broker is closed
+func (b *RedisBroker) receive(ctx context.Context) {
+	for m := range b.pubsub.Channel() {
+		peerID := strings.TrimPrefix(m.Channel, channelPrefix)
+
+		b.mu.Lock()
+		sub, ok := b.subscriptions[peerID]
+		var handler Handler
+		if ok {
+			handler = sub.handler
+		}
+		b.mu.Unlock()
+
+		if handler == nil {
+			continue
+		}
+
+		msg := &proto.EncryptedMessage{}
+		if err := gproto.Unmarshal([]byte(m.Payload), msg); err != nil {
+			log.Errorf("failed to decode message forwarded to peer [%s]: %v", peerID, err)
+			continue
+		}
+
+		handler(ctx, msg)
+	}
+}
+
+// Close stops receiving messages and releases the connection
+func (b *RedisBroker) Close() error {
+	if err := b.pubsub.Close(); err != nil {
+		log.Warnf("failed to close redis subscription: %v", err)
+	}
+	return b.client.Close()
+}
@@ -0,0 +1,97 @@
+package broker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	testcontainersredis "github.com/testcontainers/testcontainers-go/modules/redis"
+
+	"github.com/netbirdio/netbird/signal/proto"
+)
+
+func newTestBrokers(t *testing.T) (*RedisBroker, *RedisBroker) {
+	t.Helper()
+
+	ctx := context.Background()
+	redisContainer, err := testcontainersredis.RunContainer(ctx, testcontainers.WithImage("redis:7"))
+	require.NoError(t, err, "couldn't start redis container")
+	t.Cleanup(func() {
+		if err := redisContainer.Terminate(ctx); err != nil {
+			t.Logf("failed to terminate container: %s", err)
+		}
+	})
+
+	redisURL, err := redisContainer.ConnectionString(ctx)
+	require.NoError(t, err)
+
+	first, err := NewRedisBroker(ctx, redisURL)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = first.Close() })
+
+	second, err := NewRedisBroker(ctx, redisURL)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = second.Close() })
+
+	return first, second
+}
+
+func TestNewRedisBroker_ConnectionFailure(t *testing.T) {
+	_, err := NewRedisBroker(context.Background(), "redis://127.0.0.1:1")
+	require.Error(t, err)
+}
+
+func TestRedisBroker_Forwarding(t *testing.T) {
+	first, second := newTestBrokers(t)
+	ctx := context.Background()
+
+	received := make(chan *proto.EncryptedMessage, 1)
+	handler := func(_ context.Context, msg *proto.EncryptedMessage) {
+		received <- msg
+	}
+
+	require.NoError(t, first.Subscribe(ctx, "peerB", handler))
+
+	msg := &proto.EncryptedMessage{Key: "peerA", RemoteKey: "peerB", Body: []byte("offer")}
+	delivered, err := second.Publish(ctx, msg)
+	require.NoError(t, err)
+	assert.True(t, delivered, "message should be delivered to the subscribed instance")
+
+	select {
+	case got := <-received:
+		assert.Equal(t, msg.Key, got.Key)
+		assert.Equal(t, msg.RemoteKey, got.RemoteKey)
+		assert.Equal(t, msg.Body, got.Body)
+	case <-time.After(5 * time.Second):
+		t.Fatal("message wasn't forwarded")
+	}
+
+	delivered, err = second.Publish(ctx, &proto.EncryptedMessage{Key: "peerA", RemoteKey: "unknown"})
+	require.NoError(t, err)
+	assert.False(t, delivered, "message to a peer that isn't connected shouldn't be delivered")
+}
+
+func TestRedisBroker_UnsubscribeAfterAllStreams(t *testing.T) {
+	first, second := newTestBrokers(t)
+	ctx := context.Background()
+
+	handler := func(_ context.Context, _ *proto.EncryptedMessage) {}
+
+	// a reconnecting peer registers its new stream before the old one is deregistered
+	require.NoError(t, first.Subscribe(ctx, "peerB", handler))
+	require.NoError(t, first.Subscribe(ctx, "peerB", handler))
+
+	require.NoError(t, first.Unsubscribe(ctx, "peerB"))
+	delivered, err := second.Publish(ctx, &proto.EncryptedMessage{Key: "peerA", RemoteKey: "peerB"})
+	require.NoError(t, err)
+	assert.True(t, delivered, "peer with an open stream should stay subscribed")
+
+	require.NoError(t, first.Unsubscribe(ctx, "peerB"))
+	require.Eventually(t, func() bool {
+		delivered, err := second.Publish(ctx, &proto.EncryptedMessage{Key: "peerA", RemoteKey: "peerB"})
+		return err == nil && !delivered
+	}, 5*time.Second, 50*time.Millisecond, "peer without streams should be unsubscribed")
+}
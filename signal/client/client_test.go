@@ -2,6 +2,8 @@ package client
 
 import (
 	"context"
+	"crypto/rand"
+	"errors"
 	"net"
 	"sync"
 	"time"
@@ -16,6 +18,7 @@ import (
 	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/metadata"
 
+	"github.com/netbirdio/netbird/encryption"
 	sigProto "github.com/netbirdio/netbird/signal/proto"
 	"github.com/netbirdio/netbird/signal/server"
 )
@@ -115,6 +118,180 @@ var _ = Describe("GrpcClient", func() {
 		})
 	})
 
+	Describe("Encrypting messages", func() {
+		Context("between peers supporting the ratcheting scheme", func() {
+			It("should ratchet once the remote peer advertised its key", func() {
+				keyA, _ := wgtypes.GeneratePrivateKey()
+				keyB, _ := wgtypes.GeneratePrivateKey()
+				clientA := &GrpcClient{key: keyA, ratchet: encryption.NewRatchet(keyA)}
+				clientB := &GrpcClient{key: keyB, ratchet: encryption.NewRatchet(keyB)}
+
+				offer, err := clientA.encryptMessage(&sigProto.Message{
+					Key:       keyA.PublicKey().String(),
+					RemoteKey: keyB.PublicKey().String(),
+					Body:      &sigProto.Body{Type: sigProto.Body_OFFER, Payload: "offer"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(offer.GetRatchet()).To(BeEmpty())
+
+				// the peers not supporting the scheme decrypt the first message with the Wireguard keys
+				legacyBody := &sigProto.Body{}
+				Expect(encryption.DecryptMessage(keyA.PublicKey(), keyB, offer.GetBody(), legacyBody)).To(Succeed())
+				Expect(legacyBody.GetPayload()).To(Equal("offer"))
+
+				received, err := clientB.decryptMessage(offer)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(received.GetBody().GetPayload()).To(Equal("offer"))
+
+				answer, err := clientB.encryptMessage(&sigProto.Message{
+					Key:       keyB.PublicKey().String(),
+					RemoteKey: keyA.PublicKey().String(),
+					Body:      &sigProto.Body{Type: sigProto.Body_ANSWER, Payload: "answer"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(answer.GetRatchet()).NotTo(BeEmpty())
+				Expect(encryption.DecryptMessage(keyB.PublicKey(), keyA, answer.GetBody(), &sigProto.Body{})).NotTo(Succeed())
+
+				received, err = clientA.decryptMessage(answer)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(received.GetBody().GetPayload()).To(Equal("answer"))
+			})
+		})
+
+		Context("to a peer that lost its ephemeral keys", func() {
+			It("should advertise the current key and resume the ratcheting", func() {
+				keyA, _ := wgtypes.GeneratePrivateKey()
+				keyB, _ := wgtypes.GeneratePrivateKey()
+				clientA := &GrpcClient{key: keyA, ratchet: encryption.NewRatchet(keyA)}
+				clientB := &GrpcClient{key: keyB, ratchet: encryption.NewRatchet(keyB)}
+
+				advertised, err := clientB.encryptMessage(&sigProto.Message{
+					Key:       keyB.PublicKey().String(),
+					RemoteKey: keyA.PublicKey().String(),
+					Body:      &sigProto.Body{Type: sigProto.Body_OFFER, Payload: "offer"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				_, err = clientA.decryptMessage(advertised)
+				Expect(err).NotTo(HaveOccurred())
+
+				// B restarts and erases its ephemeral keys, the messages of A for the previous key can't be decrypted
+				clientB = &GrpcClient{key: keyB, ratchet: encryption.NewRatchet(keyB)}
+				offer, err := clientA.encryptMessage(&sigProto.Message{
+					Key:       keyA.PublicKey().String(),
+					RemoteKey: keyB.PublicKey().String(),
+					Body:      &sigProto.Body{Type: sigProto.Body_ANSWER, Payload: "answer"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(offer.GetRatchet()).NotTo(BeEmpty())
+				_, err = clientB.decryptMessage(offer)
+				Expect(err).To(MatchError(encryption.ErrEphemeralKeyExpired))
+
+				advertised, err = clientB.ratchetKeyAdvertisement(offer)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(advertised).NotTo(BeNil())
+				Expect(advertised.GetRatchet()).To(BeEmpty())
+				Expect(clientB.ratchetKeyAdvertisement(offer)).To(BeNil(), "the key shouldn't be advertised for every failed message")
+
+				received, err := clientA.decryptMessage(advertised)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(received.GetBody().GetType()).To(Equal(sigProto.Body_RATCHET_KEY))
+
+				offer, err = clientA.encryptMessage(&sigProto.Message{
+					Key:       keyA.PublicKey().String(),
+					RemoteKey: keyB.PublicKey().String(),
+					Body:      &sigProto.Body{Type: sigProto.Body_CANDIDATE, Payload: "candidate"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(offer.GetRatchet()).NotTo(BeEmpty())
+				received, err = clientB.decryptMessage(offer)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(received.GetBody().GetPayload()).To(Equal("candidate"))
+			})
+		})
+
+		Context("with a forged ratchet header", func() {
+			It("should keep the established session", func() {
+				keyA, _ := wgtypes.GeneratePrivateKey()
+				keyB, _ := wgtypes.GeneratePrivateKey()
+				clientA := &GrpcClient{key: keyA, ratchet: encryption.NewRatchet(keyA)}
+				clientB := &GrpcClient{key: keyB, ratchet: encryption.NewRatchet(keyB)}
+
+				advertised, err := clientB.encryptMessage(&sigProto.Message{
+					Key:       keyB.PublicKey().String(),
+					RemoteKey: keyA.PublicKey().String(),
+					Body:      &sigProto.Body{Type: sigProto.Body_OFFER, Payload: "offer"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				_, err = clientA.decryptMessage(advertised)
+				Expect(err).NotTo(HaveOccurred())
+
+				// anyone can send a message claiming to come from A for the current key of B through the Signal Exchange
+				ratchetKeyB, err := clientB.ratchet.PublicKey()
+				Expect(err).NotTo(HaveOccurred())
+				forgedHeader := make([]byte, encryption.RatchetHeaderSize)
+				_, err = rand.Read(forgedHeader)
+				Expect(err).NotTo(HaveOccurred())
+				copy(forgedHeader[encryption.RatchetKeySize:], ratchetKeyB)
+				forged := &sigProto.EncryptedMessage{
+					Key:       keyA.PublicKey().String(),
+					RemoteKey: keyB.PublicKey().String(),
+					Body:      []byte("forged"),
+					Ratchet:   forgedHeader,
+				}
+				_, err = clientB.decryptMessage(forged)
+				Expect(err).To(HaveOccurred())
+
+				advertisement, err := clientB.ratchetKeyAdvertisement(forged)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(advertisement.GetRatchet()).To(BeEmpty(), "the advertisement should be authenticated by the Wireguard keys")
+
+				answer, err := clientA.encryptMessage(&sigProto.Message{
+					Key:       keyA.PublicKey().String(),
+					RemoteKey: keyB.PublicKey().String(),
+					Body:      &sigProto.Body{Type: sigProto.Body_ANSWER, Payload: "answer"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(answer.GetRatchet()).NotTo(BeEmpty())
+				received, err := clientB.decryptMessage(answer)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(received.GetBody().GetPayload()).To(Equal("answer"))
+
+				candidate, err := clientB.encryptMessage(&sigProto.Message{
+					Key:       keyB.PublicKey().String(),
+					RemoteKey: keyA.PublicKey().String(),
+					Body:      &sigProto.Body{Type: sigProto.Body_CANDIDATE, Payload: "candidate"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(candidate.GetRatchet()).NotTo(BeEmpty(), "a forged message shouldn't downgrade the session to the Wireguard keys")
+			})
+		})
+
+		Context("to a peer not supporting the ratcheting scheme", func() {
+			It("should keep using the Wireguard keys", func() {
+				keyA, _ := wgtypes.GeneratePrivateKey()
+				keyB, _ := wgtypes.GeneratePrivateKey()
+				clientA := &GrpcClient{key: keyA, ratchet: encryption.NewRatchet(keyA)}
+
+				legacyBody, err := encryption.EncryptMessage(keyA.PublicKey(), keyB, &sigProto.Body{Payload: "offer"})
+				Expect(err).NotTo(HaveOccurred())
+				_, err = clientA.decryptMessage(&sigProto.EncryptedMessage{
+					Key:       keyB.PublicKey().String(),
+					RemoteKey: keyA.PublicKey().String(),
+					Body:      legacyBody,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				answer, err := clientA.encryptMessage(&sigProto.Message{
+					Key:       keyA.PublicKey().String(),
+					RemoteKey: keyB.PublicKey().String(),
+					Body:      &sigProto.Body{Payload: "answer"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(answer.GetRatchet()).To(BeEmpty())
+			})
+		})
+	})
+
 	Describe("Connecting to the Signal stream channel", func() {
 		Context("with a signal client", func() {
 			It("should be successful", func() {
@@ -205,7 +382,8 @@ func startSignal() (*grpc.Server, net.Listener) {
 	}
 	sigProto.RegisterSignalExchangeServer(s, srv)
 	go func() {
-		if err := s.Serve(lis); err != nil {
+		// the specs not exchanging messages may stop the server before it serves
+		if err := s.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
 			log.Fatalf("failed to serve: %v", err)
 		}
 	}()
@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"sync"
@@ -15,6 +16,7 @@ import (
 	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	gproto "google.golang.org/protobuf/proto"
 
 	"github.com/netbirdio/netbird/encryption"
 	"github.com/netbirdio/netbird/management/client"
@@ -22,6 +24,10 @@ import (
 	nbgrpc "github.com/netbirdio/netbird/util/grpc"
 )
 
+// ratchetAdvertiseInterval limits the advertisements of the ephemeral key to a remote peer whose messages can't be
+// decrypted, the messages in flight for an erased key would trigger one each
+const ratchetAdvertiseInterval = 10 * time.Second
+
 // ConnStateNotifier is a wrapper interface of the status recorder
 type ConnStateNotifier interface {
 	MarkSignalDisconnected(error)
@@ -30,7 +36,9 @@ type ConnStateNotifier interface {
 
 // GrpcClient Wraps the Signal Exchange Service gRpc client
 type GrpcClient struct {
-	key        wgtypes.Key
+	key wgtypes.Key
+	// ratchet encrypts the messages to the peers supporting the ratcheting scheme
+	ratchet    *encryption.Ratchet
 	realClient proto.SignalExchangeClient
 	signalConn *grpc.ClientConn
 	ctx        context.Context
@@ -45,6 +53,11 @@ type GrpcClient struct {
 	connStateCallbackLock sync.RWMutex
 
 	onReconnectedListenerFn func()
+
+	// ratchetAdvertisements are the times the ephemeral key was last advertised to the remote peers after a failed
+	// decryption
+	ratchetAdvertisements   map[wgtypes.Key]time.Time
+	ratchetAdvertisementsMu sync.Mutex
 }
 
 func (c *GrpcClient) StreamConnected() bool {
@@ -62,11 +75,17 @@ func (c *GrpcClient) Close() error {
 
 // NewClient creates a new Signal client
 func NewClient(ctx context.Context, addr string, key wgtypes.Key, tlsEnabled bool) (*GrpcClient, error) {
+	return NewClientWithCertificate(ctx, addr, key, tlsEnabled, nil)
+}
+
+// NewClientWithCertificate creates a new Signal client presenting the client certificate issued by the management
+// service, the connection is created without a client certificate if it is nil
+func NewClientWithCertificate(ctx context.Context, addr string, key wgtypes.Key, tlsEnabled bool, clientCert *nbgrpc.ClientCertificate) (*GrpcClient, error) {
 	var conn *grpc.ClientConn
 
 	operation := func() error {
 		var err error
-		conn, err = nbgrpc.CreateConnection(addr, tlsEnabled)
+		conn, err = nbgrpc.CreateConnection(addr, tlsEnabled, clientCert)
 		if err != nil {
 			log.Printf("createConnection error: %v", err)
 			return err
@@ -87,6 +106,7 @@ func NewClient(ctx context.Context, addr string, key wgtypes.Key, tlsEnabled boo
 		ctx:                   ctx,
 		signalConn:            conn,
 		key:                   key,
+		ratchet:               encryption.NewRatchet(key),
 		mux:                   sync.Mutex{},
 		status:                StreamDisconnected,
 		connStateCallbackLock: sync.RWMutex{},
@@ -305,7 +325,8 @@ func (c *GrpcClient) SendToStream(msg *proto.EncryptedMessage) error {
 	return nil
 }
 
-// decryptMessage decrypts the body of the msg using Wireguard private key and Remote peer's public key
+// decryptMessage decrypts the body of the msg with the ratcheting scheme if the msg has a ratchet header, otherwise
+// using Wireguard private key and Remote peer's public key
 func (c *GrpcClient) decryptMessage(msg *proto.EncryptedMessage) (*proto.Message, error) {
 	remoteKey, err := wgtypes.ParseKey(msg.GetKey())
 	if err != nil {
@@ -313,9 +334,19 @@ func (c *GrpcClient) decryptMessage(msg *proto.EncryptedMessage) (*proto.Message
 	}
 
 	body := &proto.Body{}
-	err = encryption.DecryptMessage(remoteKey, c.key, msg.GetBody(), body)
-	if err != nil {
-		return nil, err
+	if len(msg.GetRatchet()) > 0 {
+		if err := c.ratchet.DecryptMessage(remoteKey, msg.GetRatchet(), msg.GetBody(), body); err != nil {
+			return nil, err
+		}
+	} else {
+		err = encryption.DecryptMessage(remoteKey, c.key, msg.GetBody(), body)
+		if err != nil {
+			return nil, err
+		}
+		// the key is authenticated by the Wireguard keys, the peers not advertising one fall back to them
+		if err := c.ratchet.SetRemoteKey(remoteKey, body.GetRatchetKey()); err != nil {
+			log.Warnf("ignoring the ephemeral key of peer %s: %v", msg.GetKey(), err)
+		}
 	}
 
 	return &proto.Message{
@@ -325,7 +356,9 @@ func (c *GrpcClient) decryptMessage(msg *proto.EncryptedMessage) (*proto.Message
 	}, nil
 }
 
-// encryptMessage encrypts the body of the msg using Wireguard private key and Remote peer's public key
+// encryptMessage encrypts the body of the msg with the ratcheting scheme if the remote peer supports it, otherwise
+// using Wireguard private key and Remote peer's public key. The messages encrypted with the Wireguard keys advertise
+// the ephemeral key of the ratcheting scheme.
 func (c *GrpcClient) encryptMessage(msg *proto.Message) (*proto.EncryptedMessage, error) {
 
 	remoteKey, err := wgtypes.ParseKey(msg.RemoteKey)
@@ -333,8 +366,14 @@ func (c *GrpcClient) encryptMessage(msg *proto.Message) (*proto.EncryptedMessage
 		return nil, err
 	}
 
-	encryptedBody, err := encryption.EncryptMessage(remoteKey, c.key, msg.Body)
-	if err != nil {
+	header, encryptedBody, err := c.ratchet.EncryptMessage(remoteKey, msg.Body)
+	switch {
+	case errors.Is(err, encryption.ErrRatchetUnsupported), errors.Is(err, encryption.ErrRemoteKeyExpired):
+		encryptedBody, err = c.encryptStaticMessage(remoteKey, msg.Body)
+		if err != nil {
+			return nil, err
+		}
+	case err != nil:
 		return nil, err
 	}
 
@@ -342,9 +381,64 @@ func (c *GrpcClient) encryptMessage(msg *proto.Message) (*proto.EncryptedMessage
 		Key:       msg.GetKey(),
 		RemoteKey: msg.GetRemoteKey(),
 		Body:      encryptedBody,
+		Ratchet:   header,
 	}, nil
 }
 
+// ratchetKeyAdvertisement returns the message advertising the current ephemeral key to the sender of the msg that
+// couldn't be decrypted, e.g. it was encrypted for an erased ephemeral key. The failed msg isn't authenticated, the
+// session with the sender is kept and the advertisement is always encrypted with the Wireguard keys, the sender
+// authenticates it and moves to the advertised key. It returns nil if the key was advertised to the sender recently.
+func (c *GrpcClient) ratchetKeyAdvertisement(msg *proto.EncryptedMessage) (*proto.EncryptedMessage, error) {
+	remoteKey, err := wgtypes.ParseKey(msg.GetKey())
+	if err != nil {
+		return nil, err
+	}
+
+	c.ratchetAdvertisementsMu.Lock()
+	defer c.ratchetAdvertisementsMu.Unlock()
+
+	now := time.Now()
+	if last, ok := c.ratchetAdvertisements[remoteKey]; ok && now.Sub(last) < ratchetAdvertiseInterval {
+		return nil, nil
+	}
+	if c.ratchetAdvertisements == nil {
+		c.ratchetAdvertisements = make(map[wgtypes.Key]time.Time)
+	}
+	for key, last := range c.ratchetAdvertisements {
+		if now.Sub(last) >= ratchetAdvertiseInterval {
+			delete(c.ratchetAdvertisements, key)
+		}
+	}
+
+	body, err := c.encryptStaticMessage(remoteKey, &proto.Body{Type: proto.Body_RATCHET_KEY})
+	if err != nil {
+		return nil, err
+	}
+	c.ratchetAdvertisements[remoteKey] = now
+
+	return &proto.EncryptedMessage{
+		Key:       c.key.PublicKey().String(),
+		RemoteKey: msg.GetKey(),
+		Body:      body,
+	}, nil
+}
+
+// encryptStaticMessage encrypts the body with the Wireguard keys advertising the ephemeral key of the peer
+func (c *GrpcClient) encryptStaticMessage(remoteKey wgtypes.Key, body *proto.Body) ([]byte, error) {
+	ratchetKey, err := c.ratchet.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	advertised := &proto.Body{}
+	if body != nil {
+		advertised = gproto.Clone(body).(*proto.Body)
+	}
+	advertised.RatchetKey = ratchetKey
+	return encryption.EncryptMessage(remoteKey, c.key, advertised)
+}
+
 // Send sends a message to the remote Peer through the Signal Exchange.
 func (c *GrpcClient) Send(msg *proto.Message) error {
 
@@ -357,6 +451,12 @@ func (c *GrpcClient) Send(msg *proto.Message) error {
 		return err
 	}
 
+	return c.sendEncrypted(encryptedMessage)
+}
+
+// sendEncrypted sends the encrypted message to the remote Peer through the Signal Exchange retrying on failures
+func (c *GrpcClient) sendEncrypted(encryptedMessage *proto.EncryptedMessage) error {
+	var err error
 	attemptTimeout := client.ConnectTimeout
 
 	for attempt := 0; attempt < 4; attempt++ {
@@ -405,6 +505,14 @@ func (c *GrpcClient) receive(stream proto.SignalExchange_ConnectStreamClient,
 		decryptedMessage, err := c.decryptMessage(msg)
 		if err != nil {
 			log.Errorf("failed decrypting message of Peer [key: %s] error: [%s]", msg.Key, err.Error())
+			if len(msg.GetRatchet()) > 0 {
+				c.advertiseRatchetKey(msg)
+			}
+		}
+
+		if decryptedMessage.GetBody().GetType() == proto.Body_RATCHET_KEY {
+			// the ephemeral key of the remote peer was taken from the message, there is nothing else to handle
+			continue
 		}
 
 		err = msgHandler(decryptedMessage)
@@ -416,6 +524,25 @@ func (c *GrpcClient) receive(stream proto.SignalExchange_ConnectStreamClient,
 	}
 }
 
+// advertiseRatchetKey sends the ephemeral key of the peer to the sender of the msg that couldn't be decrypted, the
+// messages of the sender keep failing until it learns the current key
+func (c *GrpcClient) advertiseRatchetKey(msg *proto.EncryptedMessage) {
+	advertisement, err := c.ratchetKeyAdvertisement(msg)
+	if err != nil {
+		log.Warnf("failed to advertise the ephemeral key to peer %s: %v", msg.GetKey(), err)
+		return
+	}
+	if advertisement == nil {
+		return
+	}
+
+	go func() {
+		if err := c.sendEncrypted(advertisement); err != nil {
+			log.Warnf("failed to advertise the ephemeral key to peer %s: %v", msg.GetKey(), err)
+		}
+	}()
+}
+
 func (c *GrpcClient) notifyDisconnected(err error) {
 	c.connStateCallbackLock.RLock()
 	defer c.connStateCallbackLock.RUnlock()
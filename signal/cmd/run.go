@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"flag"
 	"fmt"
@@ -10,18 +11,21 @@ import (
 	"net/http"
 	// nolint:gosec
 	_ "net/http/pprof"
+	"os"
 	"strings"
 	"time"
 
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"golang.org/x/crypto/acme/autocert"
 
+	"github.com/netbirdio/netbird/signal/broker"
 	"github.com/netbirdio/netbird/signal/metrics"
 
 	"github.com/netbirdio/netbird/encryption"
 	"github.com/netbirdio/netbird/signal/proto"
 	"github.com/netbirdio/netbird/signal/server"
 	"github.com/netbirdio/netbird/util"
+	"github.com/netbirdio/netbird/util/pqtls"
 	"github.com/netbirdio/netbird/version"
 
 	log "github.com/sirupsen/logrus"
@@ -35,10 +39,15 @@ var (
 	signalPort              int
 	metricsPort             int
 	signalLetsencryptDomain string
+	signalLetsencryptEmail  string
+	signalDNSProvider       string
 	signalSSLDir            string
 	defaultSignalSSLDir     string
 	signalCertFile          string
 	signalCertKey           string
+	redisAddress            string
+	clientCAFile            string
+	clientCertRequired      bool
 
 	signalKaep = grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
 		MinTime:             5 * time.Second,
@@ -110,12 +119,28 @@ var (
 			if err != nil {
 				return fmt.Errorf("creating signal server: %v", err)
 			}
+
+			var msgBroker *broker.RedisBroker
+			if redisAddress != "" {
+				msgBroker, err = broker.NewRedisBroker(cmd.Context(), redisAddress)
+				if err != nil {
+					return fmt.Errorf("creating message broker: %v", err)
+				}
+				srv.SetBroker(msgBroker)
+			}
+
+			if clientCAFile != "" {
+				srv.SetClientCertificateVerification(clientCertRequired)
+			}
+
 			proto.RegisterSignalExchangeServer(grpcServer, srv)
 
 			grpcRootHandler := grpcHandlerFunc(grpcServer)
 
 			if certManager != nil {
-				startServerWithCertManager(certManager, grpcRootHandler)
+				if err = startServerWithCertManager(certManager, grpcRootHandler); err != nil {
+					return err
+				}
 			}
 
 			var compatListener net.Listener
@@ -159,6 +184,10 @@ var (
 				_ = compatListener.Close()
 				log.Infof("stopped gRPC backward compatibility server")
 			}
+			if msgBroker != nil {
+				_ = msgBroker.Close()
+				log.Infof("stopped message broker")
+			}
 
 			ctx, cancel := context.WithTimeout(cmd.Context(), 5*time.Second)
 			defer cancel()
@@ -191,11 +220,26 @@ func getTLSConfigurations() ([]grpc.ServerOption, *autocert.Manager, error) {
 	)
 
 	if signalLetsencryptDomain == "" && signalCertFile == "" && signalCertKey == "" {
+		if clientCAFile != "" {
+			return nil, nil, errors.New("client-ca-file requires TLS")
+		}
 		log.Infof("running without TLS")
 		return nil, nil, nil
 	}
 
-	if signalLetsencryptDomain != "" {
+	if signalLetsencryptDomain != "" && signalDNSProvider != "" {
+		dns01 := &encryption.DNS01TLS{
+			DataDir:  signalSSLDir,
+			Email:    signalLetsencryptEmail,
+			Domains:  []string{signalLetsencryptDomain},
+			Provider: signalDNSProvider,
+		}
+		tlsConfig, err = dns01.GetCertificate()
+		if err != nil {
+			return nil, certManager, err
+		}
+		log.Infof("setting up TLS with LetsEncrypt DNS challenge.")
+	} else if signalLetsencryptDomain != "" {
 		certManager, err = encryption.CreateCertManager(signalSSLDir, signalLetsencryptDomain)
 		if err != nil {
 			return nil, certManager, err
@@ -216,12 +260,58 @@ func getTLSConfigurations() ([]grpc.ServerOption, *autocert.Manager, error) {
 		log.Infof("setting up TLS with custom certificates.")
 	}
 
-	transportCredentials := credentials.NewTLS(tlsConfig)
+	tlsConfig, err = withClientCertificates(tlsConfig)
+	if err != nil {
+		return nil, certManager, err
+	}
+
+	transportCredentials := credentials.NewTLS(pqtls.PreferHybrid(tlsConfig))
 
 	return []grpc.ServerOption{grpc.Creds(transportCredentials)}, certManager, err
 }
 
-func startServerWithCertManager(certManager *autocert.Manager, grpcRootHandler http.Handler) {
+// withClientCertificates returns a copy of the TLS config verifying the client certificates with the client CA, they
+// are verified if presented only unless the server requires them
+func withClientCertificates(tlsConfig *tls.Config) (*tls.Config, error) {
+	if clientCAFile == "" {
+		return tlsConfig, nil
+	}
+
+	caPEM, err := os.ReadFile(clientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("read client CA: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no certificates found in client CA file %s", clientCAFile)
+	}
+
+	tlsConfig = tlsConfig.Clone()
+	tlsConfig.ClientCAs = pool
+	tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	log.Infof("verifying the client certificates with the client CA %s", clientCAFile)
+
+	return tlsConfig, nil
+}
+
+func startServerWithCertManager(certManager *autocert.Manager, grpcRootHandler http.Handler) error {
+	if signalPort == 443 && clientCAFile != "" {
+		// the listener of the cert manager doesn't request the client certificates, its TLS config answers the
+		// TLS-ALPN challenge on the same port
+		tlsConfig, err := withClientCertificates(certManager.TLSConfig())
+		if err != nil {
+			return err
+		}
+		listener, err := tls.Listen("tcp", fmt.Sprintf(":%d", signalPort), pqtls.PreferHybrid(tlsConfig))
+		if err != nil {
+			return fmt.Errorf("failed creating TLS listener on port %d: %v", signalPort, err)
+		}
+		serveHTTP(listener, certManager.HTTPHandler(grpcRootHandler))
+		log.Infof("running HTTP server (LetsEncrypt challenge handler) and gRPC server on the same port: %s", listener.Addr().String())
+		return nil
+	}
+
 	// a call to certManager.Listener() always creates a new listener so we do it once
 	httpListener := certManager.Listener()
 	if signalPort == 443 {
@@ -233,6 +323,8 @@ func startServerWithCertManager(certManager *autocert.Manager, grpcRootHandler h
 		serveHTTP(httpListener, certManager.HTTPHandler(nil))
 		log.Infof("running HTTP server (LetsEncrypt challenge handler): %s", httpListener.Addr().String())
 	}
+
+	return nil
 }
 
 func grpcHandlerFunc(grpcServer *grpc.Server) http.Handler {
@@ -301,6 +393,11 @@ func init() {
 	runCmd.Flags().IntVar(&metricsPort, "metrics-port", 9090, "metrics endpoint http port. Metrics are accessible under host:metrics-port/metrics")
 	runCmd.Flags().StringVar(&signalSSLDir, "ssl-dir", defaultSignalSSLDir, "server ssl directory location. *Required only for Let's Encrypt certificates.")
 	runCmd.Flags().StringVar(&signalLetsencryptDomain, "letsencrypt-domain", "", "a domain to issue Let's Encrypt certificate for. Enables TLS using Let's Encrypt. Will fetch and renew certificate, and run the server with TLS")
+	runCmd.Flags().StringVar(&signalLetsencryptEmail, "letsencrypt-email", "", "email address to use for Let's Encrypt certificate registration with the DNS challenge")
+	runCmd.Flags().StringVar(&signalDNSProvider, "letsencrypt-dns-provider", "", fmt.Sprintf("use the DNS provider for Let's Encrypt DNS challenge instead of serving the HTTP challenge, one of %v. The provider credentials are read from the environment", encryption.DNSProviders()))
 	runCmd.Flags().StringVar(&signalCertFile, "cert-file", "", "Location of your SSL certificate. Can be used when you have an existing certificate and don't want a new certificate be generated automatically. If letsencrypt-domain is specified this property has no effect")
 	runCmd.Flags().StringVar(&signalCertKey, "cert-key", "", "Location of your SSL certificate private key. Can be used when you have an existing certificate and don't want a new certificate be generated automatically. If letsencrypt-domain is specified this property has no effect")
+	runCmd.Flags().StringVar(&clientCAFile, "client-ca-file", "", "Location of the CA certificate of the client certificates issued by the Management service. Enables the verification of the client certificates of the peers")
+	runCmd.Flags().BoolVar(&clientCertRequired, "client-cert-required", false, "reject the peers without a client certificate issued by the Management service, requires client-ca-file")
+	runCmd.Flags().StringVar(&redisAddress, "redis-address", "", "Redis URL used to forward messages between signal instances, e.g. redis://redis:6379/0. Allows running multiple instances behind a load balancer")
 }
@@ -21,6 +21,7 @@ type Metrics struct {
 	Meter    api.Meter
 	provider *metric.MeterProvider
 	Endpoint string
+	router   *http.ServeMux
 
 	*http.Server
 }
@@ -56,10 +57,16 @@ func NewServer(port int, endpoint string) (*Metrics, error) {
 		Meter:    meter,
 		provider: provider,
 		Endpoint: endpoint,
+		router:   router,
 		Server:   server,
 	}, nil
 }
 
+// Handle serves the handler under the pattern next to the metrics endpoint
+func (m *Metrics) Handle(pattern string, handler http.Handler) {
+	m.router.Handle(pattern, handler)
+}
+
 // Shutdown stops the metrics server
 func (m *Metrics) Shutdown(ctx context.Context) error {
 	if err := m.Server.Shutdown(ctx); err != nil {
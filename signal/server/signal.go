@@ -15,9 +15,11 @@ import (
 	"google.golang.org/grpc/status"
 	gproto "google.golang.org/protobuf/proto"
 
+	"github.com/netbirdio/netbird/signal/broker"
 	"github.com/netbirdio/netbird/signal/metrics"
 	"github.com/netbirdio/netbird/signal/peer"
 	"github.com/netbirdio/netbird/signal/proto"
+	nbgrpc "github.com/netbirdio/netbird/util/grpc"
 )
 
 const (
@@ -25,6 +27,7 @@ const (
 	labelTypeError         = "error"
 	labelTypeNotConnected  = "not_connected"
 	labelTypeNotRegistered = "not_registered"
+	labelTypeBroker        = "broker"
 	labelTypeStream        = "stream"
 	labelTypeMessage       = "message"
 
@@ -32,6 +35,8 @@ const (
 	labelErrorMissingId    = "missing_id"
 	labelErrorMissingMeta  = "missing_meta"
 	labelErrorFailedHeader = "failed_header"
+	labelErrorBroker       = "broker"
+	labelErrorClientCert   = "client_certificate"
 
 	labelRegistrationStatus   = "status"
 	labelRegistrationFound    = "found"
@@ -43,7 +48,14 @@ type Server struct {
 	registry *peer.Registry
 	proto.UnimplementedSignalExchangeServer
 	dispatcher *dispatcher.Dispatcher
-	metrics    *metrics.AppMetrics
+	// broker forwards the messages of the peers connected to other signal instances, if set
+	broker  broker.Broker
+	metrics *metrics.AppMetrics
+
+	// verifyClientCert checks the client certificates issued by the management service belong to the peers,
+	// clientCertRequired rejects the peers without one
+	verifyClientCert   bool
+	clientCertRequired bool
 }
 
 // NewServer creates a new Signal server
@@ -67,16 +79,76 @@ func NewServer(ctx context.Context, meter metric.Meter) (*Server, error) {
 	return s, nil
 }
 
+// SetBroker makes the server forward the messages of the peers connected to other signal instances through the broker.
+// It has to be set before the server starts accepting connections.
+func (s *Server) SetBroker(b broker.Broker) {
+	s.broker = b
+}
+
+// SetClientCertificateVerification makes the server check the client certificate of a peer was issued to it, the
+// TLS config of the server has to verify the certificates with the client CA of the management service. If required
+// is false the peers without a certificate are accepted. It has to be set before the server starts accepting connections.
+func (s *Server) SetClientCertificateVerification(required bool) {
+	s.verifyClientCert = true
+	s.clientCertRequired = required
+}
+
+// checkClientCertificate returns an error if the verified client certificate of the call wasn't issued to the peer
+func (s *Server) checkClientCertificate(ctx context.Context, peerID string) error {
+	if !s.verifyClientCert {
+		return nil
+	}
+
+	commonName, ok := nbgrpc.VerifiedClientCommonName(ctx)
+	if !ok {
+		if s.clientCertRequired {
+			return status.Errorf(codes.Unauthenticated, "client certificate is required")
+		}
+		return nil
+	}
+
+	if commonName != peerID {
+		return status.Errorf(codes.Unauthenticated, "client certificate wasn't issued to the peer")
+	}
+
+	return nil
+}
+
 // Send forwards a message to the signal peer
 func (s *Server) Send(ctx context.Context, msg *proto.EncryptedMessage) (*proto.EncryptedMessage, error) {
 	log.Debugf("received a new message to send from peer [%s] to peer [%s]", msg.Key, msg.RemoteKey)
 
+	if err := s.checkClientCertificate(ctx, msg.Key); err != nil {
+		return nil, err
+	}
+
+	return s.sendMessage(ctx, msg)
+}
+
+// sendMessage delivers the message to the remote peer, either connected to this instance or to another one
+func (s *Server) sendMessage(ctx context.Context, msg *proto.EncryptedMessage) (*proto.EncryptedMessage, error) {
 	if _, found := s.registry.Get(msg.RemoteKey); found {
 		s.forwardMessageToPeer(ctx, msg)
 		return &proto.EncryptedMessage{}, nil
 	}
 
-	return s.dispatcher.SendMessage(ctx, msg)
+	if s.broker == nil {
+		return s.dispatcher.SendMessage(ctx, msg)
+	}
+
+	delivered, err := s.broker.Publish(ctx, msg)
+	if err != nil {
+		s.metrics.MessageForwardFailures.Add(ctx, 1, metric.WithAttributes(attribute.String(labelType, labelTypeBroker)))
+		log.Warnf("error while forwarding message from peer [%s] to peer [%s] through the broker %v", msg.Key, msg.RemoteKey, err)
+		return nil, status.Errorf(codes.Unavailable, "failed forwarding message")
+	}
+
+	if !delivered {
+		s.metrics.MessageForwardFailures.Add(ctx, 1, metric.WithAttributes(attribute.String(labelType, labelTypeNotConnected)))
+		log.Debugf("message from peer [%s] can't be forwarded to peer [%s] because destination peer is not connected", msg.Key, msg.RemoteKey)
+	}
+
+	return &proto.EncryptedMessage{}, nil
 }
 
 // ConnectStream connects to the exchange stream
@@ -114,7 +186,7 @@ func (s *Server) ConnectStream(stream proto.SignalExchange_ConnectStreamServer)
 
 			log.Debugf("Received a response from peer [%s] to peer [%s]", msg.Key, msg.RemoteKey)
 
-			_, err = s.dispatcher.SendMessage(stream.Context(), msg)
+			_, err = s.sendMessage(stream.Context(), msg)
 			if err != nil {
 				log.Debugf("error while sending message from peer [%s] to peer [%s] %v", msg.Key, msg.RemoteKey, err)
 			}
@@ -136,15 +208,36 @@ func (s *Server) RegisterPeer(stream proto.SignalExchange_ConnectStreamServer) (
 		return nil, status.Errorf(codes.FailedPrecondition, "missing connection header: %s", proto.HeaderId)
 	}
 
+	if err := s.checkClientCertificate(stream.Context(), id[0]); err != nil {
+		s.metrics.RegistrationFailures.Add(stream.Context(), 1, metric.WithAttributes(attribute.String(labelError, labelErrorClientCert)))
+		return nil, err
+	}
+
 	p := peer.NewPeer(id[0], stream)
 	s.registry.Register(p)
 	s.dispatcher.ListenForMessages(stream.Context(), p.Id, s.forwardMessageToPeer)
+
+	if s.broker != nil {
+		if err := s.broker.Subscribe(stream.Context(), p.Id, s.forwardMessageToPeer); err != nil {
+			s.registry.Deregister(p)
+			s.metrics.RegistrationFailures.Add(stream.Context(), 1, metric.WithAttributes(attribute.String(labelError, labelErrorBroker)))
+			log.Errorf("failed subscribing peer [%s] to the broker: %v", p.Id, err)
+			return nil, status.Errorf(codes.Unavailable, "failed registering peer")
+		}
+	}
+
 	return p, nil
 }
 
 func (s *Server) DeregisterPeer(p *peer.Peer) {
 	log.Debugf("peer disconnected [%s] [streamID %d] ", p.Id, p.StreamID)
 	s.registry.Deregister(p)
+
+	if s.broker != nil {
+		if err := s.broker.Unsubscribe(context.Background(), p.Id); err != nil {
+			log.Warnf("failed unsubscribing peer [%s] from the broker: %v", p.Id, err)
+		}
+	}
 	s.metrics.PeerConnectionDuration.Record(p.Stream.Context(), int64(time.Since(p.RegisteredAt).Seconds()))
 }
 
@@ -0,0 +1,109 @@
+// Package errcode defines the stable codes of the errors reported by the client and the management service, so the
+// UIs and the automations can react to an error without parsing its message.
+//
+// The daemon API and the management gRPC API carry the code in the ErrorInfo details of the gRPC status, the
+// management REST API in the error_code field of the error responses and the events of the daemon in their
+// errorCode field. The codes are part of the APIs, they are never renamed or reused.
+package errcode
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Code is the stable code of an error
+type Code string
+
+// The generic codes, the errors without a specific code get the one of their gRPC or HTTP status
+const (
+	Unknown            Code = "UNKNOWN"
+	Internal           Code = "INTERNAL"
+	InvalidArgument    Code = "INVALID_ARGUMENT"
+	NotFound           Code = "NOT_FOUND"
+	AlreadyExists      Code = "ALREADY_EXISTS"
+	PermissionDenied   Code = "PERMISSION_DENIED"
+	Unauthenticated    Code = "UNAUTHENTICATED"
+	PreconditionFailed Code = "PRECONDITION_FAILED"
+	Unavailable        Code = "UNAVAILABLE"
+	Unimplemented      Code = "UNIMPLEMENTED"
+	Canceled           Code = "CANCELED"
+	DeadlineExceeded   Code = "DEADLINE_EXCEEDED"
+	Aborted            Code = "ABORTED"
+)
+
+const (
+	// LoginRequired is returned when the peer must log in, with a setup key or SSO, before connecting
+	LoginRequired Code = "LOGIN_REQUIRED"
+	// LoginExpired is returned when the login of the peer expired and the user must log in again
+	LoginExpired Code = "LOGIN_EXPIRED"
+	// PeerNotRegistered is returned by the management service for a peer that isn't registered yet
+	PeerNotRegistered Code = "PEER_NOT_REGISTERED"
+	// RouteConflict is reported for a route colliding with another route or with a local subnet
+	RouteConflict Code = "ROUTE_CONFLICT"
+	// FirewallBackendUnavailable is reported when no firewall backend works on the host
+	FirewallBackendUnavailable Code = "FIREWALL_BACKEND_UNAVAILABLE"
+	// FirewallBackendConflict is reported when the host mixes the rules of two firewall backends
+	FirewallBackendConflict Code = "FIREWALL_BACKEND_CONFLICT"
+	// ManagementUnavailable is reported when the management service can't be reached
+	ManagementUnavailable Code = "MANAGEMENT_UNAVAILABLE"
+	// DNSUpstreamUnavailable is reported when the upstream nameservers of a nameserver group don't respond
+	DNSUpstreamUnavailable Code = "DNS_UPSTREAM_UNAVAILABLE"
+)
+
+// Coder is implemented by the errors having a code of their own
+type Coder interface {
+	ErrorCode() Code
+}
+
+// Error is an error with a code
+type Error struct {
+	Code Code
+	Err  error
+}
+
+// Error returns the message of the wrapped error
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ErrorCode returns the code of the error
+func (e *Error) ErrorCode() Code {
+	return e.Code
+}
+
+// Wrap returns the error with the code, nil if the error is nil
+func Wrap(code Code, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Err: err}
+}
+
+// Errorf formats an error with the code
+func Errorf(code Code, format string, a ...any) error {
+	return &Error{Code: code, Err: fmt.Errorf(format, a...)}
+}
+
+// Of returns the code of the first error in the chain having one, the code of the gRPC status of the error if it
+// has none or Unknown
+func Of(err error) Code {
+	if err == nil {
+		return ""
+	}
+
+	var coder Coder
+	if errors.As(err, &coder) {
+		return coder.ErrorCode()
+	}
+
+	if code, ok := fromGRPCError(err); ok {
+		return code
+	}
+
+	return Unknown
+}
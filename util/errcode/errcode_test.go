@@ -0,0 +1,106 @@
+package errcode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type conflictError struct{}
+
+func (conflictError) Error() string   { return "conflict" }
+func (conflictError) ErrorCode() Code { return FirewallBackendConflict }
+
+func TestOf(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		want Code
+	}{
+		{name: "nil", err: nil, want: ""},
+		{name: "plain error", err: errors.New("failed"), want: Unknown},
+		{name: "coded error", err: Errorf(RouteConflict, "route %s conflicts", "a"), want: RouteConflict},
+		{name: "wrapped coded error", err: fmt.Errorf("apply: %w", Wrap(LoginExpired, errors.New("expired"))), want: LoginExpired},
+		{name: "coder", err: fmt.Errorf("create firewall: %w", conflictError{}), want: FirewallBackendConflict},
+		{name: "grpc status", err: status.Error(codes.NotFound, "not found"), want: NotFound},
+		{name: "grpc status with details", err: Status(codes.PermissionDenied, LoginExpired, "expired").Err(), want: LoginExpired},
+		{name: "wrapped grpc status with details", err: fmt.Errorf("login: %w", Status(codes.PermissionDenied, LoginExpired, "expired").Err()), want: LoginExpired},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Of(tc.err))
+		})
+	}
+}
+
+func TestToGRPC(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		wantGRPC codes.Code
+		wantCode Code
+		wantMsg  string
+	}{
+		{
+			name:     "plain error",
+			err:      errors.New("failed"),
+			wantGRPC: codes.Unknown,
+			wantCode: Unknown,
+			wantMsg:  "failed",
+		},
+		{
+			name:     "coded error",
+			err:      Errorf(FirewallBackendUnavailable, "no firewall"),
+			wantGRPC: codes.Unavailable,
+			wantCode: FirewallBackendUnavailable,
+			wantMsg:  "no firewall",
+		},
+		{
+			name:     "grpc status",
+			err:      status.Error(codes.FailedPrecondition, "call login first"),
+			wantGRPC: codes.FailedPrecondition,
+			wantCode: PreconditionFailed,
+			wantMsg:  "call login first",
+		},
+		{
+			name:     "coded grpc status",
+			err:      Wrap(LoginRequired, status.Error(codes.FailedPrecondition, "call login first")),
+			wantGRPC: codes.FailedPrecondition,
+			wantCode: LoginRequired,
+			wantMsg:  "call login first",
+		},
+		{
+			name:     "context error",
+			err:      fmt.Errorf("wait for the engine: %w", context.DeadlineExceeded),
+			wantGRPC: codes.DeadlineExceeded,
+			wantCode: DeadlineExceeded,
+			wantMsg:  "wait for the engine: context deadline exceeded",
+		},
+		{
+			name:     "grpc status with details",
+			err:      Status(codes.PermissionDenied, LoginExpired, "expired").Err(),
+			wantGRPC: codes.PermissionDenied,
+			wantCode: LoginExpired,
+			wantMsg:  "expired",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ToGRPC(tc.err)
+			st, ok := status.FromError(err)
+			assert.True(t, ok)
+			assert.Equal(t, tc.wantGRPC, st.Code())
+			assert.Equal(t, tc.wantMsg, st.Message())
+			assert.Equal(t, tc.wantCode, Of(err))
+		})
+	}
+
+	assert.NoError(t, ToGRPC(nil))
+}
@@ -0,0 +1,153 @@
+package errcode
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Domain is the domain of the ErrorInfo details carrying the codes in the gRPC statuses
+const Domain = "netbird.io"
+
+// Status returns a gRPC status with the code in its ErrorInfo details
+func Status(grpcCode codes.Code, code Code, msg string) *status.Status {
+	st := status.New(grpcCode, msg)
+	withDetails, err := st.WithDetails(&errdetails.ErrorInfo{Reason: string(code), Domain: Domain})
+	if err != nil {
+		return st
+	}
+	return withDetails
+}
+
+// ToGRPC converts the error to a gRPC status error with the code of the error in its details. A gRPC status error
+// keeps its gRPC code and gets the code derived from it, unless it carries a code already.
+func ToGRPC(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var coder Coder
+	hasCode := errors.As(err, &coder)
+
+	var grpcErr interface {
+		error
+		GRPCStatus() *status.Status
+	}
+	if errors.As(err, &grpcErr) {
+		st := grpcErr.GRPCStatus()
+		// the message of the status is kept unless the error wrapping it adds to it
+		msg := st.Message()
+		if err.Error() != grpcErr.Error() {
+			msg = err.Error()
+		}
+
+		if hasCode {
+			return Status(st.Code(), coder.ErrorCode(), msg).Err()
+		}
+		if code, ok := fromDetails(st); ok {
+			return Status(st.Code(), code, msg).Err()
+		}
+		return Status(st.Code(), FromGRPCCode(st.Code()), msg).Err()
+	}
+
+	code := Unknown
+	switch {
+	case hasCode:
+		code = coder.ErrorCode()
+	case errors.Is(err, context.Canceled):
+		code = Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		code = DeadlineExceeded
+	}
+	return Status(ToGRPCCode(code), code, err.Error()).Err()
+}
+
+// ToGRPCCode returns the gRPC code the errors with the code are returned with
+func ToGRPCCode(code Code) codes.Code {
+	switch code {
+	case Internal:
+		return codes.Internal
+	case InvalidArgument:
+		return codes.InvalidArgument
+	case NotFound:
+		return codes.NotFound
+	case AlreadyExists, RouteConflict:
+		return codes.AlreadyExists
+	case PermissionDenied, LoginExpired, PeerNotRegistered:
+		return codes.PermissionDenied
+	case Unauthenticated:
+		return codes.Unauthenticated
+	case PreconditionFailed, LoginRequired, FirewallBackendConflict:
+		return codes.FailedPrecondition
+	case Unavailable, ManagementUnavailable, DNSUpstreamUnavailable, FirewallBackendUnavailable:
+		return codes.Unavailable
+	case Unimplemented:
+		return codes.Unimplemented
+	case Canceled:
+		return codes.Canceled
+	case DeadlineExceeded:
+		return codes.DeadlineExceeded
+	case Aborted:
+		return codes.Aborted
+	default:
+		return codes.Unknown
+	}
+}
+
+// FromGRPCCode returns the generic code of the gRPC code
+func FromGRPCCode(grpcCode codes.Code) Code {
+	switch grpcCode {
+	case codes.OK:
+		return ""
+	case codes.Internal:
+		return Internal
+	case codes.InvalidArgument, codes.OutOfRange:
+		return InvalidArgument
+	case codes.NotFound:
+		return NotFound
+	case codes.AlreadyExists:
+		return AlreadyExists
+	case codes.PermissionDenied:
+		return PermissionDenied
+	case codes.Unauthenticated:
+		return Unauthenticated
+	case codes.FailedPrecondition:
+		return PreconditionFailed
+	case codes.Unavailable:
+		return Unavailable
+	case codes.Unimplemented:
+		return Unimplemented
+	case codes.Canceled:
+		return Canceled
+	case codes.DeadlineExceeded:
+		return DeadlineExceeded
+	case codes.Aborted:
+		return Aborted
+	default:
+		return Unknown
+	}
+}
+
+// fromGRPCError returns the code carried by the gRPC status of the error or the one derived from its gRPC code
+func fromGRPCError(err error) (Code, bool) {
+	st, ok := status.FromError(err)
+	if !ok {
+		return "", false
+	}
+	if code, ok := fromDetails(st); ok {
+		return code, true
+	}
+	return FromGRPCCode(st.Code()), true
+}
+
+func fromDetails(st *status.Status) (Code, bool) {
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.GetDomain() == Domain {
+			return Code(info.GetReason()), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,82 @@
+// Package faultinject injects failures into the client to test its recovery: the signal messages are dropped, the
+// management syncs delayed, the relay connections killed and the firewall calls of the routing peer failed.
+//
+// The faults are compiled in with the faultinject build tag only, the hooks are no-ops in the other builds:
+//
+//	go build -tags faultinject ./client
+//
+// The faults are set and cleared through the debug API of the daemon, e.g. netbird debug fault set relay-kill.
+package faultinject
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Fault is a failure injected into the client
+type Fault string
+
+const (
+	// SignalDrop drops the messages sent to and received from the signal server
+	SignalDrop Fault = "signal-drop"
+	// ManagementSyncDelay holds the syncs received from the management server back for the delay of the fault
+	ManagementSyncDelay Fault = "management-sync-delay"
+	// RelayKill closes the connections to the relay servers as if the servers dropped them
+	RelayKill Fault = "relay-kill"
+	// FirewallError fails the firewall calls adding and removing the routing rules of the routing peer
+	FirewallError Fault = "firewall-error"
+)
+
+// Faults lists the faults that can be injected
+var Faults = []Fault{SignalDrop, ManagementSyncDelay, RelayKill, FirewallError}
+
+var (
+	// ErrInjected is the error returned by the hooks failing the calls
+	ErrInjected = errors.New("injected fault")
+	// ErrNotCompiled is returned when setting a fault in a build without the faultinject tag
+	ErrNotCompiled = errors.New("fault injection is not compiled in, build the client with the faultinject tag")
+)
+
+// Config controls how often a fault is injected
+type Config struct {
+	// Probability is the chance in (0, 1] the fault is injected each time its hook runs, 0 injects it every time
+	Probability float64
+	// Delay is the time the hooks delaying a call wait for, it's required by ManagementSyncDelay
+	Delay time.Duration
+	// Count is the number of times the fault is injected before it's cleared, 0 injects it until it's cleared
+	Count int
+}
+
+// State is a fault that is set and the number of times it was injected
+type State struct {
+	Fault    Fault
+	Config   Config
+	Injected int
+}
+
+// ParseFault returns the fault with the name
+func ParseFault(name string) (Fault, error) {
+	for _, f := range Faults {
+		if string(f) == name {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown fault %q, supported faults: %v", name, Faults)
+}
+
+func (c Config) validate(fault Fault) error {
+	if c.Probability < 0 || c.Probability > 1 {
+		return fmt.Errorf("probability %v is out of the range [0, 1]", c.Probability)
+	}
+	if c.Delay < 0 {
+		return fmt.Errorf("negative delay %s", c.Delay)
+	}
+	if c.Count < 0 {
+		return fmt.Errorf("negative count %d", c.Count)
+	}
+	if fault == ManagementSyncDelay && c.Delay == 0 {
+		return fmt.Errorf("fault %s requires a delay", fault)
+	}
+	return nil
+}
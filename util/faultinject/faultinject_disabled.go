@@ -0,0 +1,39 @@
+//go:build !faultinject
+
+package faultinject
+
+import "time"
+
+// Enabled is set in the builds with the faultinject tag
+const Enabled = false
+
+// Set returns ErrNotCompiled, the faults are injected in the builds with the faultinject tag only
+func Set(Fault, Config) error {
+	return ErrNotCompiled
+}
+
+// Clear is a no-op without the faultinject tag
+func Clear(Fault) {}
+
+// ClearAll is a no-op without the faultinject tag
+func ClearAll() {}
+
+// List returns no faults without the faultinject tag
+func List() []State {
+	return nil
+}
+
+// Hit never injects the fault without the faultinject tag
+func Hit(Fault) bool {
+	return false
+}
+
+// Delay never delays without the faultinject tag
+func Delay(Fault) time.Duration {
+	return 0
+}
+
+// Err never fails without the faultinject tag
+func Err(Fault, string) error {
+	return nil
+}
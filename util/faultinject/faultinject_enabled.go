@@ -0,0 +1,122 @@
+//go:build faultinject
+
+package faultinject
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Enabled is set in the builds with the faultinject tag
+const Enabled = true
+
+var (
+	mu     sync.Mutex
+	states = make(map[Fault]*State)
+)
+
+// Set injects the fault from now on, replacing the config of the fault if it's already set
+func Set(fault Fault, config Config) error {
+	if _, err := ParseFault(string(fault)); err != nil {
+		return err
+	}
+	if err := config.validate(fault); err != nil {
+		return fmt.Errorf("invalid config of fault %s: %w", fault, err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	states[fault] = &State{Fault: fault, Config: config}
+	log.Warnf("injecting fault %s with probability %v, delay %s and count %d", fault, config.Probability, config.Delay, config.Count)
+	return nil
+}
+
+// Clear stops injecting the fault
+func Clear(fault Fault) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, ok := states[fault]; ok {
+		delete(states, fault)
+		log.Warnf("cleared fault %s", fault)
+	}
+}
+
+// ClearAll stops injecting all the faults
+func ClearAll() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for fault := range states {
+		delete(states, fault)
+		log.Warnf("cleared fault %s", fault)
+	}
+}
+
+// List returns the faults that are set sorted by name
+func List() []State {
+	mu.Lock()
+	defer mu.Unlock()
+
+	list := make([]State, 0, len(states))
+	for _, state := range states {
+		list = append(list, *state)
+	}
+	slices.SortFunc(list, func(a, b State) int {
+		return strings.Compare(string(a.Fault), string(b.Fault))
+	})
+	return list
+}
+
+// Hit reports whether the fault is injected this time, the hooks call it each time they run
+func Hit(fault Fault) bool {
+	_, ok := hit(fault)
+	return ok
+}
+
+// Delay returns the time the hook waits for when the fault is injected, 0 otherwise
+func Delay(fault Fault) time.Duration {
+	config, ok := hit(fault)
+	if !ok {
+		return 0
+	}
+	return config.Delay
+}
+
+// Err returns ErrInjected for the operation when the fault is injected, nil otherwise
+func Err(fault Fault, op string) error {
+	if !Hit(fault) {
+		return nil
+	}
+	return fmt.Errorf("%s: %w", op, ErrInjected)
+}
+
+func hit(fault Fault) (Config, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	state, ok := states[fault]
+	if !ok {
+		return Config{}, false
+	}
+
+	if state.Config.Probability > 0 && rand.Float64() >= state.Config.Probability {
+		return Config{}, false
+	}
+
+	state.Injected++
+	if state.Config.Count > 0 && state.Injected >= state.Config.Count {
+		delete(states, fault)
+		log.Warnf("fault %s was injected %d times, cleared it", fault, state.Injected)
+	}
+
+	log.Debugf("injected fault %s", fault)
+	return state.Config, true
+}
@@ -0,0 +1,90 @@
+//go:build faultinject
+
+package faultinject
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSet(t *testing.T) {
+	t.Cleanup(ClearAll)
+
+	testCases := []struct {
+		name    string
+		fault   Fault
+		config  Config
+		wantErr bool
+	}{
+		{name: "unknown fault", fault: "unknown", wantErr: true},
+		{name: "probability out of range", fault: SignalDrop, config: Config{Probability: 1.5}, wantErr: true},
+		{name: "negative count", fault: RelayKill, config: Config{Count: -1}, wantErr: true},
+		{name: "sync delay without delay", fault: ManagementSyncDelay, wantErr: true},
+		{name: "sync delay", fault: ManagementSyncDelay, config: Config{Delay: time.Second}},
+		{name: "relay kill once", fault: RelayKill, config: Config{Count: 1}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Set(tc.fault, tc.config)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+
+	assert.Equal(t, []State{
+		{Fault: ManagementSyncDelay, Config: Config{Delay: time.Second}},
+		{Fault: RelayKill, Config: Config{Count: 1}},
+	}, List())
+}
+
+func TestHit(t *testing.T) {
+	t.Cleanup(ClearAll)
+
+	assert.False(t, Hit(SignalDrop), "a fault that isn't set shouldn't be injected")
+
+	require.NoError(t, Set(SignalDrop, Config{Count: 2}))
+	assert.True(t, Hit(SignalDrop))
+	assert.Equal(t, []State{{Fault: SignalDrop, Config: Config{Count: 2}, Injected: 1}}, List())
+	assert.True(t, Hit(SignalDrop))
+	assert.False(t, Hit(SignalDrop), "the fault should be cleared after it was injected count times")
+	assert.Empty(t, List())
+
+	require.NoError(t, Set(FirewallError, Config{}))
+	err := Err(FirewallError, "add nat rule")
+	assert.True(t, errors.Is(err, ErrInjected))
+	assert.EqualError(t, err, "add nat rule: injected fault")
+
+	Clear(FirewallError)
+	assert.NoError(t, Err(FirewallError, "add nat rule"))
+}
+
+func TestDelay(t *testing.T) {
+	t.Cleanup(ClearAll)
+
+	assert.Zero(t, Delay(ManagementSyncDelay))
+
+	require.NoError(t, Set(ManagementSyncDelay, Config{Delay: 5 * time.Second}))
+	assert.Equal(t, 5*time.Second, Delay(ManagementSyncDelay))
+}
+
+func TestHitProbability(t *testing.T) {
+	t.Cleanup(ClearAll)
+
+	require.NoError(t, Set(RelayKill, Config{Probability: 0.5}))
+
+	hits := 0
+	for i := 0; i < 1000; i++ {
+		if Hit(RelayKill) {
+			hits++
+		}
+	}
+	assert.InDelta(t, 500, hits, 100, "about half of the hooks should inject the fault")
+}
@@ -0,0 +1,118 @@
+package grpc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// ClientCertificate holds the certificate the client presents to the management and signal services for mutual TLS.
+// It is issued by the management service on login and replaced when it is renewed, the new certificate is used by
+// the following TLS handshakes.
+type ClientCertificate struct {
+	mu          sync.RWMutex
+	certificate *tls.Certificate
+	pendingKey  *ecdsa.PrivateKey
+}
+
+// NewClientCertificate creates an empty ClientCertificate, a certificate is requested on the next login
+func NewClientCertificate() *ClientCertificate {
+	return &ClientCertificate{}
+}
+
+// NeedsRenewal returns true if there is no certificate or less than a third of its validity remains
+func (c *ClientCertificate) NeedsRenewal() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.certificate == nil || c.certificate.Leaf == nil {
+		return true
+	}
+
+	leaf := c.certificate.Leaf
+	remaining := time.Until(leaf.NotAfter)
+	return remaining < leaf.NotAfter.Sub(leaf.NotBefore)/3
+}
+
+// NewRequest generates a new key and returns a certificate request (DER) of it with the common name, which is the
+// WireGuard public key of the peer. The key is used with the certificate passed to Accept.
+func (c *ClientCertificate) NewRequest(commonName string) ([]byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generate client certificate key: %w", err)
+	}
+
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: commonName},
+	}, key)
+	if err != nil {
+		return nil, fmt.Errorf("create certificate request: %w", err)
+	}
+
+	c.mu.Lock()
+	c.pendingKey = key
+	c.mu.Unlock()
+
+	return csr, nil
+}
+
+// Accept replaces the certificate with the one (DER) issued for the last request
+func (c *ClientCertificate) Accept(certDER []byte) error {
+	leaf, err := x509.ParseCertificate(certDER)
+	if err != nil {
+		return fmt.Errorf("parse client certificate: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.pendingKey == nil {
+		return fmt.Errorf("no pending certificate request")
+	}
+
+	c.certificate = &tls.Certificate{
+		Certificate: [][]byte{certDER},
+		PrivateKey:  c.pendingKey,
+		Leaf:        leaf,
+	}
+	c.pendingKey = nil
+
+	return nil
+}
+
+// getClientCertificate returns the current certificate to the TLS handshake, no certificate is sent if there is none
+func (c *ClientCertificate) getClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.certificate == nil {
+		return &tls.Certificate{}, nil
+	}
+	return c.certificate, nil
+}
+
+// VerifiedClientCommonName returns the common name of the client certificate of the gRPC call, if the client
+// presented a certificate verified by the server
+func VerifiedClientCommonName(ctx context.Context) (string, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", false
+	}
+
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return "", false
+	}
+
+	return tlsInfo.State.VerifiedChains[0][0].Subject.CommonName, true
+}
@@ -22,6 +22,7 @@ import (
 
 	"github.com/netbirdio/netbird/util/embeddedroots"
 	nbnet "github.com/netbirdio/netbird/util/net"
+	"github.com/netbirdio/netbird/util/pqtls"
 )
 
 func WithCustomDialer() grpc.DialOption {
@@ -57,7 +58,8 @@ func Backoff(ctx context.Context) backoff.BackOff {
 	return backoff.WithContext(b, ctx)
 }
 
-func CreateConnection(addr string, tlsEnabled bool) (*grpc.ClientConn, error) {
+// CreateConnection dials the gRPC server, the client certificate is presented to the TLS servers if it isn't nil
+func CreateConnection(addr string, tlsEnabled bool, clientCert *ClientCertificate) (*grpc.ClientConn, error) {
 	transportOption := grpc.WithTransportCredentials(insecure.NewCredentials())
 	if tlsEnabled {
 		certPool, err := x509.SystemCertPool()
@@ -66,9 +68,13 @@ func CreateConnection(addr string, tlsEnabled bool) (*grpc.ClientConn, error) {
 			certPool = embeddedroots.Get()
 		}
 
-		transportOption = grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
+		tlsConfig := &tls.Config{
 			RootCAs: certPool,
-		}))
+		}
+		if clientCert != nil {
+			tlsConfig.GetClientCertificate = clientCert.getClientCertificate
+		}
+		transportOption = grpc.WithTransportCredentials(credentials.NewTLS(pqtls.Apply(tlsConfig)))
 	}
 
 	connCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
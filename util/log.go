@@ -12,6 +12,7 @@ import (
 	"gopkg.in/natefinch/lumberjack.v2"
 
 	"github.com/netbirdio/netbird/formatter"
+	"github.com/netbirdio/netbird/formatter/scrub"
 )
 
 const defaultLogSize = 5
@@ -23,6 +24,13 @@ func InitLog(logLevel string, logPath string) error {
 		log.Errorf("Failed parsing log-level %s: %s", logLevel, err)
 		return err
 	}
+
+	scrubOptions, err := scrub.ParseOptions(os.Getenv("NB_LOG_SCRUB"), os.Getenv("NB_LOG_SCRUB_LEVEL"))
+	if err != nil {
+		log.Errorf("Failed parsing log scrubbing options: %s", err)
+		return err
+	}
+
 	customOutputs := []string{"console", "syslog"}
 
 	if logPath != "" && !slices.Contains(customOutputs, logPath) {
@@ -48,6 +56,10 @@ func InitLog(logLevel string, logPath string) error {
 	} else {
 		formatter.SetTextFormatter(log.StandardLogger())
 	}
+	// scrub IPs, domains and emails at write time in privacy-sensitive deployments, e.g. NB_LOG_SCRUB=ip,email
+	if scrubOptions.Enabled() {
+		formatter.SetScrubbing(log.StandardLogger(), scrubOptions)
+	}
 	log.SetLevel(level)
 
 	setGRPCLibLogger()
@@ -1,6 +1,8 @@
 package net
 
 import (
+	"fmt"
+	"math"
 	"math/big"
 	"net"
 
@@ -8,14 +10,80 @@ import (
 )
 
 const (
+	// DefaultFwmark is the default base of the fwmarks used by NetBird
+	DefaultFwmark = 0x1BD00
+	// DefaultRoutingTableID is the default ID of the routing table of the NetBird routes
+	DefaultRoutingTableID = 0x1BD0
+
+	// fwmarkSpan is the number of fwmarks derived from the base fwmark
+	fwmarkSpan = 0x21
+	// fwmarkStep is the distance between the base fwmarks tried when the default one is in use
+	fwmarkStep = 0x100
+	// policyRoutingCandidates is the number of base fwmarks and routing table IDs tried
+	policyRoutingCandidates = 64
+)
+
+// The fwmarks are offsets of the base fwmark, SetFwmark moves them to coexist with the policy routing of other VPNs
+var (
 	// NetbirdFwmark is the fwmark value used by Netbird via wireguard
-	NetbirdFwmark = 0x1BD00
+	NetbirdFwmark uint32 = DefaultFwmark
+
+	PreroutingFwmarkRedirected       uint32 = DefaultFwmark + 0x01
+	PreroutingFwmarkMasquerade       uint32 = DefaultFwmark + 0x11
+	PreroutingFwmarkMasqueradeReturn uint32 = DefaultFwmark + 0x12
+
+	// SplitTunnelFwmark marks the traffic of the processes selected for split tunneling, only this traffic is routed
+	// through the NetBird routes
+	SplitTunnelFwmark uint32 = DefaultFwmark + 0x20
 
-	PreroutingFwmarkRedirected       = 0x1BD01
-	PreroutingFwmarkMasquerade       = 0x1BD11
-	PreroutingFwmarkMasqueradeReturn = 0x1BD12
+	// RoutingTableID is the ID of the routing table of the NetBird routes with the advanced routing
+	RoutingTableID = DefaultRoutingTableID
 )
 
+// SetFwmark sets the base of the fwmarks used by NetBird. It has to be called before the sockets and the rules using
+// the fwmarks are created.
+func SetFwmark(base uint32) {
+	NetbirdFwmark = base
+	PreroutingFwmarkRedirected = base + 0x01
+	PreroutingFwmarkMasquerade = base + 0x11
+	PreroutingFwmarkMasqueradeReturn = base + 0x12
+	SplitTunnelFwmark = base + 0x20
+}
+
+// SetRoutingTableID sets the ID of the routing table of the NetBird routes. It has to be called before the routing is
+// set up.
+func SetRoutingTableID(id int) {
+	RoutingTableID = id
+}
+
+// ValidateFwmark checks that the fwmarks derived from the base fwmark are valid, zero selects the base automatically
+func ValidateFwmark(base uint32) error {
+	if base > math.MaxUint32-fwmarkSpan {
+		return fmt.Errorf("fwmark %#x is too large, the fwmarks up to %#x are used", base, uint64(base)+fwmarkSpan-1)
+	}
+	return nil
+}
+
+// ValidateRoutingTableID checks that the ID isn't one of the reserved routing tables, zero selects the ID automatically
+func ValidateRoutingTableID(id int) error {
+	// 253-255 are the default, main and local tables, 252 is the compat table
+	if id < 0 || int64(id) > math.MaxUint32 || (id >= 252 && id <= 255) {
+		return fmt.Errorf("routing table ID %d is reserved or out of range", id)
+	}
+	return nil
+}
+
+// ProfileFwmark returns the base fwmark of the nth profile running a daemon next to the default one. The fwmarks of
+// the profiles follow the ones selected automatically, so they don't conflict with the default daemon.
+func ProfileFwmark(n int) uint32 {
+	return DefaultFwmark + uint32(policyRoutingCandidates+n)*fwmarkStep
+}
+
+// ProfileRoutingTableID returns the routing table ID of the nth profile running a daemon next to the default one
+func ProfileRoutingTableID(n int) int {
+	return DefaultRoutingTableID + policyRoutingCandidates + n
+}
+
 // ConnectionID provides a globally unique identifier for network connections.
 // It's used to track connections throughout their lifecycle so the close hook can correlate with the dial hook.
 type ConnectionID string
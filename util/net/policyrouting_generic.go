@@ -0,0 +1,14 @@
+//go:build !linux || android
+
+package net
+
+// ConfigurePolicyRouting sets the base fwmark and the routing table ID used by NetBird, the zero values keep the
+// defaults. The values aren't checked for conflicts, the policy routing is only used on Linux.
+func ConfigurePolicyRouting(fwmark uint32, tableID int) {
+	if fwmark != 0 {
+		SetFwmark(fwmark)
+	}
+	if tableID != 0 {
+		SetRoutingTableID(tableID)
+	}
+}
@@ -0,0 +1,149 @@
+//go:build linux && !android
+
+package net
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// netbirdRulePriority is the priority of the rules directing the traffic to the NetBird routing table, the rules
+	// left by a previous run aren't conflicts
+	netbirdRulePriority = 110
+)
+
+// ConfigurePolicyRouting sets the base fwmark and the routing table ID used by NetBird. The zero values are selected
+// automatically: the defaults, unless the ip rules or the routes of other VPNs or of the host policy routing use them.
+// Sharing a fwmark or a routing table with them silently loops the traffic. The configured values are used as is, a
+// conflict is logged.
+func ConfigurePolicyRouting(fwmark uint32, tableID int) {
+	rules, err := netlink.RuleList(netlink.FAMILY_ALL)
+	if err != nil {
+		log.Warnf("failed to list the ip rules, not checking the fwmark and the routing table for conflicts: %v", err)
+		applyPolicyRouting(fwmark, tableID)
+		return
+	}
+	tables, err := routeTables()
+	if err != nil {
+		log.Warnf("failed to list the routes, not checking the routing table for conflicts: %v", err)
+	}
+
+	if fwmark == 0 {
+		fwmark = selectFwmark(rules)
+	} else if rule := conflictingMarkRule(rules, fwmark); rule != nil {
+		log.Warnf("the configured fwmark %#x is matched by the %s, the traffic may loop", fwmark, describeRule(*rule))
+	}
+
+	if tableID == 0 {
+		tableID = selectRoutingTable(rules, tables)
+	} else if isTableUsed(rules, tables, tableID) {
+		log.Warnf("the configured routing table %d is used by another VPN or the host policy routing, the routes may conflict", tableID)
+	}
+
+	applyPolicyRouting(fwmark, tableID)
+}
+
+func applyPolicyRouting(fwmark uint32, tableID int) {
+	if fwmark == 0 {
+		fwmark = DefaultFwmark
+	}
+	if tableID == 0 {
+		tableID = DefaultRoutingTableID
+	}
+	if fwmark != DefaultFwmark || tableID != DefaultRoutingTableID {
+		log.Infof("using the fwmarks %#x-%#x and the routing table %d", fwmark, fwmark+fwmarkSpan-1, tableID)
+	}
+	SetFwmark(fwmark)
+	SetRoutingTableID(tableID)
+}
+
+func selectFwmark(rules []netlink.Rule) uint32 {
+	for i := uint32(0); i < policyRoutingCandidates; i++ {
+		candidate := DefaultFwmark + i*fwmarkStep
+		rule := conflictingMarkRule(rules, candidate)
+		if rule == nil {
+			return candidate
+		}
+		log.Infof("the fwmark %#x is matched by the %s, trying the next one", candidate, describeRule(*rule))
+	}
+	log.Warnf("no free fwmark found, using the default fwmark %#x", DefaultFwmark)
+	return DefaultFwmark
+}
+
+func selectRoutingTable(rules []netlink.Rule, tables map[int]struct{}) int {
+	for i := 0; i < policyRoutingCandidates; i++ {
+		candidate := DefaultRoutingTableID + i
+		if !isTableUsed(rules, tables, candidate) {
+			return candidate
+		}
+		log.Infof("the routing table %d is used by another VPN or the host policy routing, trying the next one", candidate)
+	}
+	log.Warnf("no free routing table found, using the default routing table %d", DefaultRoutingTableID)
+	return DefaultRoutingTableID
+}
+
+// conflictingMarkRule returns the first foreign rule matching one of the fwmarks derived from the base fwmark
+func conflictingMarkRule(rules []netlink.Rule, base uint32) *netlink.Rule {
+	for i, rule := range rules {
+		if rule.Mark < 0 || rule.Priority == netbirdRulePriority {
+			continue
+		}
+		mask := uint32(rule.Mask)
+		if rule.Mask < 0 {
+			mask = 0xffffffff
+		}
+		for mark := base; mark < base+fwmarkSpan; mark++ {
+			if mark&mask == uint32(rule.Mark)&mask {
+				return &rules[i]
+			}
+		}
+	}
+	return nil
+}
+
+// isTableUsed checks if a foreign rule looks up the table, or if the table holds routes and isn't looked up by a
+// NetBird rule left by a previous run
+func isTableUsed(rules []netlink.Rule, tables map[int]struct{}, tableID int) bool {
+	var netbirdRule bool
+	for _, rule := range rules {
+		if rule.Table != tableID {
+			continue
+		}
+		if rule.Priority != netbirdRulePriority {
+			return true
+		}
+		netbirdRule = true
+	}
+
+	_, hasRoutes := tables[tableID]
+	return hasRoutes && !netbirdRule
+}
+
+// routeTables returns the IDs of the routing tables holding routes
+func routeTables() (map[int]struct{}, error) {
+	routes, err := netlink.RouteListFiltered(netlink.FAMILY_ALL, &netlink.Route{Table: unix.RT_TABLE_UNSPEC}, netlink.RT_FILTER_TABLE)
+	if err != nil {
+		return nil, err
+	}
+
+	tables := make(map[int]struct{})
+	for _, route := range routes {
+		tables[route.Table] = struct{}{}
+	}
+	return tables, nil
+}
+
+func describeRule(rule netlink.Rule) string {
+	desc := fmt.Sprintf("ip rule %d", rule.Priority)
+	if rule.Mark >= 0 {
+		desc += fmt.Sprintf(" fwmark %#x", rule.Mark)
+		if rule.Mask >= 0 {
+			desc += fmt.Sprintf("/%#x", rule.Mask)
+		}
+	}
+	return desc + fmt.Sprintf(" lookup %d", rule.Table)
+}
@@ -0,0 +1,9 @@
+//go:build go1.24
+
+package pqtls
+
+import "crypto/tls"
+
+// hybridCurves prefer X25519MLKEM768, the defaults of Go leave it out when the go directive of the module is older
+// than 1.24
+var hybridCurves = []tls.CurveID{tls.X25519MLKEM768, tls.X25519, tls.CurveP256, tls.CurveP384, tls.CurveP521}
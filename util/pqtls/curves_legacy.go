@@ -0,0 +1,8 @@
+//go:build !go1.24
+
+package pqtls
+
+import "crypto/tls"
+
+// hybridCurves keep the defaults of Go, they prefer X25519Kyber768Draft00 in Go 1.23
+var hybridCurves []tls.CurveID
@@ -0,0 +1,12 @@
+//go:build go1.25
+
+package pqtls
+
+import "crypto/tls"
+
+func negotiatedKeyExchange(state tls.ConnectionState) string {
+	if state.CurveID == 0 {
+		return ""
+	}
+	return state.CurveID.String()
+}
@@ -0,0 +1,10 @@
+//go:build !go1.25
+
+package pqtls
+
+import "crypto/tls"
+
+// negotiatedKeyExchange returns no key exchange, it isn't exposed before Go 1.25
+func negotiatedKeyExchange(tls.ConnectionState) string {
+	return ""
+}
@@ -0,0 +1,36 @@
+//go:build go1.25
+
+package pqtls
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApply_RecordsKeyExchange(t *testing.T) {
+	t.Setenv(EnvDisablePQTLS, "")
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = PreferHybrid(&tls.Config{})
+	server.StartTLS()
+	defer server.Close()
+
+	client := server.Client()
+	transport := client.Transport.(*http.Transport)
+	transport.TLSClientConfig.ServerName = "example.com"
+	Apply(transport.TLSClientConfig)
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	keyExchange := KeyExchange("https://example.com:443")
+	assert.Equal(t, tls.X25519MLKEM768.String(), keyExchange)
+	assert.True(t, IsPostQuantum(keyExchange))
+	assert.Equal(t, keyExchange, KeyExchange("example.com:443"))
+}
@@ -0,0 +1,97 @@
+// Package pqtls negotiates hybrid post-quantum key exchanges on the TLS connections between the clients and the
+// management, signal and relay services and keeps track of the key exchanges negotiated by the clients.
+//
+// The hybrid key exchanges are offered first: X25519MLKEM768 from Go 1.24 and X25519Kyber768Draft00 in Go 1.23.
+// They're used with the TLS 1.3 servers supporting them, the classical key exchanges with the others. The
+// negotiated key exchange is known from Go 1.25.
+package pqtls
+
+import (
+	"crypto/tls"
+	"net"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// EnvDisablePQTLS disables the hybrid post-quantum key exchanges, for the peers or middleboxes failing on the larger
+// key shares
+const EnvDisablePQTLS = "NB_DISABLE_PQ_TLS"
+
+var classicalCurves = []tls.CurveID{tls.X25519, tls.CurveP256, tls.CurveP384, tls.CurveP521}
+
+// keyExchanges holds the last key exchange negotiated with a server by its host name
+var keyExchanges sync.Map
+
+// PreferHybrid prefers the hybrid key exchanges in the TLS config, configured preferences are kept
+func PreferHybrid(cfg *tls.Config) *tls.Config {
+	if cfg == nil || len(cfg.CurvePreferences) != 0 {
+		return cfg
+	}
+
+	if disabled() {
+		cfg.CurvePreferences = slices.Clone(classicalCurves)
+	} else {
+		cfg.CurvePreferences = slices.Clone(hybridCurves)
+	}
+	return cfg
+}
+
+// Apply prefers the hybrid key exchanges in the client TLS config and records the negotiated ones
+func Apply(cfg *tls.Config) *tls.Config {
+	PreferHybrid(cfg)
+
+	verify := cfg.VerifyConnection
+	cfg.VerifyConnection = func(state tls.ConnectionState) error {
+		if verify != nil {
+			if err := verify(state); err != nil {
+				return err
+			}
+		}
+		record(state)
+		return nil
+	}
+	return cfg
+}
+
+// KeyExchange returns the key exchange negotiated with the server of the URL, empty if it isn't known
+func KeyExchange(serverURL string) string {
+	host := serverURL
+	if u, err := url.Parse(serverURL); err == nil && u.Host != "" {
+		host = u.Hostname()
+	} else if h, _, err := net.SplitHostPort(serverURL); err == nil {
+		host = h
+	}
+
+	keyExchange, ok := keyExchanges.Load(host)
+	if !ok {
+		return ""
+	}
+	return keyExchange.(string)
+}
+
+// IsPostQuantum checks if the key exchange is a hybrid post-quantum one
+func IsPostQuantum(keyExchange string) bool {
+	return strings.Contains(keyExchange, "MLKEM") || strings.Contains(keyExchange, "Kyber")
+}
+
+func record(state tls.ConnectionState) {
+	keyExchange := negotiatedKeyExchange(state)
+	if state.ServerName == "" || keyExchange == "" {
+		return
+	}
+
+	if previous, loaded := keyExchanges.Swap(state.ServerName, keyExchange); !loaded || previous != keyExchange {
+		log.Debugf("negotiated the %s key exchange with %s", keyExchange, state.ServerName)
+	}
+}
+
+func disabled() bool {
+	disable, err := strconv.ParseBool(os.Getenv(EnvDisablePQTLS))
+	return err == nil && disable
+}
@@ -0,0 +1,25 @@
+package pqtls
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApply_Disabled(t *testing.T) {
+	t.Setenv(EnvDisablePQTLS, "true")
+
+	cfg := Apply(&tls.Config{})
+	assert.Equal(t, classicalCurves, cfg.CurvePreferences)
+
+	cfg = Apply(&tls.Config{CurvePreferences: []tls.CurveID{tls.CurveP256}})
+	assert.Equal(t, []tls.CurveID{tls.CurveP256}, cfg.CurvePreferences, "configured preferences should be kept")
+}
+
+func TestIsPostQuantum(t *testing.T) {
+	assert.True(t, IsPostQuantum("X25519MLKEM768"))
+	assert.True(t, IsPostQuantum("X25519Kyber768Draft00"))
+	assert.False(t, IsPostQuantum("X25519"))
+	assert.False(t, IsPostQuantum(""))
+}